@@ -0,0 +1,99 @@
+// Command extractor-golden writes the golden files of extractor samples: the
+// symbols extracted from each sample source, stored next to it as
+// <sample>.symbols.json and checked by the pkg/treesitter tests.
+//
+// Usage:
+//
+//	go run ./cmd/extractor-golden [-check] [file or directory ...]
+//
+// Without arguments it processes pkg/treesitter/testdata/golden. With -check
+// it writes nothing and exits with status 1 when a golden file is missing or
+// out of date.
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/madeindigio/remembrances-mcp/pkg/treesitter"
+)
+
+const defaultSamplesDir = "pkg/treesitter/testdata/golden"
+
+func main() {
+	check := flag.Bool("check", false, "report missing or outdated golden files instead of writing them")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s [-check] [file or directory ...]\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Writes <sample>.symbols.json next to each sample source (default: %s).\n\n", defaultSamplesDir)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	paths := flag.Args()
+	if len(paths) == 0 {
+		paths = []string{defaultSamplesDir}
+	}
+
+	var samples []string
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(2)
+		}
+		if !info.IsDir() {
+			if !treesitter.IsGoldenSample(path) {
+				fmt.Fprintf(os.Stderr, "error: %s is not a source file of a supported language\n", path)
+				os.Exit(2)
+			}
+			samples = append(samples, path)
+			continue
+		}
+		found, err := treesitter.FindGoldenSamples(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(2)
+		}
+		samples = append(samples, found...)
+	}
+
+	ctx := context.Background()
+	parser := treesitter.NewParser()
+	defer parser.Close()
+
+	failed := false
+	for _, sample := range samples {
+		if !*check {
+			path, err := treesitter.WriteGolden(ctx, parser, sample)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "%s: %v\n", sample, err)
+				failed = true
+				continue
+			}
+			fmt.Println(path)
+			continue
+		}
+
+		golden, err := treesitter.ExtractGolden(ctx, parser, sample)
+		if err == nil {
+			var got, want []byte
+			if got, err = treesitter.MarshalGolden(golden); err == nil {
+				want, err = os.ReadFile(treesitter.GoldenPath(sample))
+				if err == nil && !bytes.Equal(got, want) {
+					err = fmt.Errorf("out of date")
+				}
+			}
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", sample, err)
+			failed = true
+		}
+	}
+
+	if failed {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	"github.com/madeindigio/remembrances-mcp/internal/storage"
+)
+
+// runCompact implements "remembrances-mcp compact": it compacts the files of
+// the embedded database, returning the space of deleted and overwritten
+// records to the file system, and reports how much was reclaimed. It returns
+// the exit code: 0 on success and 2 when the compaction could not run.
+func runCompact() int {
+	asJSON := pflag.Bool("json", false, "compact: print the report as JSON")
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	cfg, store, err := openCommandStorage(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 2
+	}
+	defer store.Close()
+
+	compactor, ok := store.(storage.Compactor)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "compact is not supported by the %s storage backend\n", cfg.GetStorageBackend())
+		return 2
+	}
+	report, err := compactor.Compact(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error compacting storage: %v\n", err)
+		return 2
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			fmt.Fprintf(os.Stderr, "Error encoding report: %v\n", err)
+			return 2
+		}
+		return 0
+	}
+	fmt.Printf("Compacted %s in %s\n", report.Location, report.Duration.Round(time.Millisecond))
+	fmt.Printf("Size before: %s\nSize after:  %s\nReclaimed:   %s\n", formatSize(report.SizeBefore), formatSize(report.SizeAfter), formatSize(report.Reclaimed))
+	return 0
+}
+
+// formatSize formats a byte count with a binary unit.
+func formatSize(n int64) string {
+	const unit = 1024
+	abs := n
+	if abs < 0 {
+		abs = -abs
+	}
+	if abs < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := abs / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/madeindigio/remembrances-mcp/internal/storage"
+)
+
+func TestFormatSize(t *testing.T) {
+	tests := []struct {
+		n    int64
+		want string
+	}{
+		{0, "0 B"},
+		{1023, "1023 B"},
+		{1024, "1.0 KiB"},
+		{1536, "1.5 KiB"},
+		{5 << 20, "5.0 MiB"},
+		{3 << 30, "3.0 GiB"},
+		{-2048, "-2.0 KiB"},
+	}
+	for _, tt := range tests {
+		if got := formatSize(tt.n); got != tt.want {
+			t.Errorf("formatSize(%d) = %q, want %q", tt.n, got, tt.want)
+		}
+	}
+}
+
+// countingCompactor counts compactions, failing the first one.
+type countingCompactor struct {
+	calls atomic.Int32
+}
+
+func (c *countingCompactor) Compact(ctx context.Context) (*storage.CompactionReport, error) {
+	if c.calls.Add(1) == 1 {
+		return nil, errors.New("database busy")
+	}
+	return &storage.CompactionReport{Location: "test", SizeBefore: 2, SizeAfter: 1, Reclaimed: 1}, nil
+}
+
+func TestCompactLoop(t *testing.T) {
+	compactor := &countingCompactor{}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		compactLoop(ctx, compactor, 5*time.Millisecond)
+		close(done)
+	}()
+
+	// A failed compaction does not stop the schedule
+	deadline := time.Now().Add(5 * time.Second)
+	for compactor.calls.Load() < 3 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if n := compactor.calls.Load(); n < 3 {
+		t.Fatalf("compacted %d times, want at least 3", n)
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("compactLoop did not return after the context was cancelled")
+	}
+}
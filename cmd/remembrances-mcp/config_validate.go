@@ -0,0 +1,55 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/pflag"
+
+	"github.com/madeindigio/remembrances-mcp/internal/config"
+)
+
+// runConfig implements "remembrances-mcp config validate": it loads the
+// configuration like the server does, without connecting to anything, and
+// prints the effective value and source of every setting. It returns the exit
+// code: 0 when the configuration is valid, 1 when it is not and 2 on usage
+// errors.
+func runConfig() int {
+	if len(os.Args) < 3 || os.Args[2] != "validate" {
+		fmt.Fprintln(os.Stderr, "Usage: remembrances-mcp config validate [--config file] [--json] [flags]")
+		return 2
+	}
+	asJSON := pflag.Bool("json", false, "config validate: print the effective configuration as JSON")
+
+	os.Args = append(os.Args[:1], os.Args[3:]...)
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Configuration is invalid: %v\n", err)
+		return 1
+	}
+
+	settings := cfg.Effective()
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(settings); err != nil {
+			fmt.Fprintf(os.Stderr, "Error encoding configuration: %v\n", err)
+			return 2
+		}
+		return 0
+	}
+
+	fmt.Println("Configuration is valid. Effective settings:")
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "KEY\tVALUE\tSOURCE")
+	for _, s := range settings {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", s.Key, config.FormatSetting(s), s.Source)
+	}
+	if err := w.Flush(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error printing configuration: %v\n", err)
+		return 2
+	}
+	return 0
+}
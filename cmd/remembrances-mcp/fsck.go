@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"text/tabwriter"
+
+	"github.com/spf13/pflag"
+
+	"github.com/madeindigio/remembrances-mcp/internal/config"
+	"github.com/madeindigio/remembrances-mcp/internal/storage"
+)
+
+// runFsck implements "remembrances-mcp fsck": it checks the configured
+// storage for orphaned code records, drifted user_stats counters and
+// embeddings of the wrong dimension, and repairs them with --repair. It
+// returns the exit code: 0 when no unrepaired issue is left, 1 when some
+// are, and 2 when the check could not run.
+func runFsck() int {
+	repair := pflag.Bool("repair", false, "fsck: repair the issues found")
+	asJSON := pflag.Bool("json", false, "fsck: print the report as JSON")
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	cfg, store, err := openCommandStorage(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 2
+	}
+	defer store.Close()
+
+	checker, ok := store.(storage.IntegrityChecker)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "fsck is not supported by the %s storage backend\n", cfg.GetStorageBackend())
+		return 2
+	}
+	report, err := checker.CheckIntegrity(ctx, *repair)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error checking storage integrity: %v\n", err)
+		return 2
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			fmt.Fprintf(os.Stderr, "Error encoding report: %v\n", err)
+			return 2
+		}
+	} else {
+		printIntegrityReport(report)
+	}
+
+	if len(report.Issues) > report.Repaired {
+		return 1
+	}
+	return 0
+}
+
+// openCommandStorage drops the subcommand from os.Args, loads the
+// configuration from the remaining flags and connects to the configured
+// storage, like the server does on startup.
+func openCommandStorage(ctx context.Context) (*config.Config, storage.FullStorage, error) {
+	os.Args = append(os.Args[:1], os.Args[2:]...)
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if err := cfg.SetupLogging(); err != nil {
+		return nil, nil, fmt.Errorf("failed to set up logging: %w", err)
+	}
+
+	store := newStorage(cfg)
+	if err := store.Connect(ctx); err != nil {
+		return nil, nil, fmt.Errorf("failed to connect to storage: %w", err)
+	}
+	if err := store.InitializeSchema(ctx); err != nil {
+		store.Close()
+		return nil, nil, fmt.Errorf("failed to initialize storage schema: %w", err)
+	}
+	return cfg, store, nil
+}
+
+// printIntegrityReport writes one line per issue followed by a summary.
+func printIntegrityReport(report *storage.IntegrityReport) {
+	if len(report.Issues) == 0 {
+		fmt.Println("No integrity issues found")
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "CHECK\tTABLE\tREF\tRECORDS\tDETAIL\tREPAIRED")
+	for _, issue := range report.Issues {
+		repaired := "no"
+		if issue.Repaired {
+			repaired = "yes"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\t%s\n", issue.Check, issue.Table, issue.Ref, issue.Count, issue.Detail, repaired)
+	}
+	w.Flush()
+	fmt.Printf("\n%d issues found, %d repaired\n", len(report.Issues), report.Repaired)
+}
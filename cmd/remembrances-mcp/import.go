@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	"github.com/madeindigio/remembrances-mcp/internal/bulkimport"
+	"github.com/madeindigio/remembrances-mcp/internal/storage"
+	"github.com/madeindigio/remembrances-mcp/pkg/embedder"
+	"github.com/madeindigio/remembrances-mcp/pkg/redact"
+)
+
+// runImport implements "remembrances-mcp import": it streams the facts or
+// texts of a CSV or JSONL file, or converts the export of another memory
+// system (--from), into the configured storage, embedding texts with the
+// embedder of the vectors route, and prints the records that failed.
+// It returns the exit code: 0 when every record was imported, 1 when some
+// failed and 2 when the import could not run or stopped early.
+func runImport() int {
+	kind := pflag.String("kind", "", "import: what the records are, facts or vectors")
+	from := pflag.String("from", "", "import: memory system the file was exported from, mem0, zep or letta")
+	format := pflag.String("format", "", "import: csv or jsonl (default: the file extension)")
+	userID := pflag.String("user-id", "", "import: user of the records without a user_id")
+	batchSize := pflag.Int("batch-size", bulkimport.DefaultBatchSize, "import: texts embedded per embedder call")
+	asJSON := pflag.Bool("json", false, "import: print the report as JSON")
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	cfg, store, err := openCommandStorage(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 2
+	}
+	defer store.Close()
+
+	if pflag.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "Usage: remembrances-mcp import --kind facts|vectors [--format csv|jsonl] | --from mem0|zep|letta [--user-id id] [--batch-size n] [--json] [flags] file")
+		return 2
+	}
+	path := pflag.Arg(0)
+	if *format == "" {
+		*format = bulkimport.DetectFormat(path)
+	}
+
+	var emb bulkimport.Embedder
+	if *kind == bulkimport.KindVectors || *from != "" {
+		def, err := embedder.NewEmbedderFromMainConfig(cfg)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error creating embedder: %v\n", err)
+			return 2
+		}
+		router, err := embedder.NewRouterFromMainConfig(cfg, def, def)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error creating routed embedders: %v\n", err)
+			return 2
+		}
+		emb = router.For(embedder.RouteVectors)
+	}
+
+	redactor, err := redact.New(cfg.GetRedactionMode(), cfg.GetRedactionRules(), cfg.GetRedactionPatterns())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error configuring redaction: %v\n", err)
+		return 2
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 2
+	}
+	defer f.Close()
+
+	opts := bulkimport.Options{
+		Kind:      *kind,
+		Format:    *format,
+		UserID:    *userID,
+		BatchSize: *batchSize,
+		Redact: func(content string) (string, error) {
+			redacted, _, err := redactor.Apply(content)
+			return redacted, err
+		},
+		Progress: func(p bulkimport.Progress) {
+			fmt.Fprintf(os.Stderr, "\r%d records read, %d imported, %d failed", p.Read, p.Imported, p.Failed)
+		},
+	}
+	ctx = storage.WithoutQueryTimeout(ctx)
+	var report *bulkimport.Report
+	if *from != "" {
+		report, err = bulkimport.ImportExport(ctx, store, emb, f, *from, opts)
+	} else {
+		report, err = bulkimport.Import(ctx, store, emb, f, opts)
+	}
+	fmt.Fprintln(os.Stderr)
+	if report == nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 2
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			fmt.Fprintf(os.Stderr, "Error encoding report: %v\n", err)
+			return 2
+		}
+	} else {
+		printImportReport(report)
+	}
+
+	switch {
+	case err != nil:
+		fmt.Fprintf(os.Stderr, "Import stopped: %v\n", err)
+		return 2
+	case report.Failed > 0:
+		return 1
+	}
+	return 0
+}
+
+// printImportReport writes the failed records followed by a summary.
+func printImportReport(report *bulkimport.Report) {
+	for _, failure := range report.Failures {
+		if failure.Ref != "" {
+			fmt.Printf("%s: %s\n", failure.Ref, failure.Error)
+		} else {
+			fmt.Printf("line %d: %s\n", failure.Line, failure.Error)
+		}
+	}
+	if report.Failed > len(report.Failures) {
+		fmt.Printf("... and %d more failures\n", report.Failed-len(report.Failures))
+	}
+	if len(report.Failures) > 0 {
+		fmt.Println()
+	}
+	fmt.Printf("Imported %d of %d records in %s (%d failed)\n", report.Imported, report.Read, report.Duration.Round(time.Millisecond), report.Failed)
+	for _, kind := range []string{bulkimport.KindFacts, bulkimport.KindVectors, bulkimport.KindEntities, bulkimport.KindRelationships} {
+		if n := report.Counts[kind]; n > 0 {
+			fmt.Printf("  %s: %d\n", kind, n)
+		}
+	}
+}
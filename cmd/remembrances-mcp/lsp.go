@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/madeindigio/remembrances-mcp/internal/lsp"
+	"github.com/madeindigio/remembrances-mcp/pkg/version"
+)
+
+// runLSP implements "remembrances-mcp lsp": it serves the code index over
+// the language server protocol on stdin and stdout, for editors to reuse the
+// symbols the code tools use. It returns the exit code: 0 when the client
+// shut the server down, 1 when it exited without shutdown and 2 when the
+// server could not run.
+func runLSP() int {
+	// stdout carries the protocol; keep logs and any other output off it
+	protocolOut := os.Stdout
+	os.Stdout = os.Stderr
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	_, store, err := openCommandStorage(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 2
+	}
+	defer store.Close()
+
+	err = lsp.NewServer(store, version.Version).Serve(ctx, os.Stdin, protocolOut)
+	switch {
+	case err == nil || ctx.Err() != nil:
+		return 0
+	case errors.Is(err, lsp.ErrExitWithoutShutdown):
+		return 1
+	default:
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 2
+	}
+}
@@ -7,21 +7,29 @@ import (
 	"log/slog"
 	"net/http"
 	"os"
-	"os/exec"
 	"os/signal"
 	"regexp"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
 	"github.com/madeindigio/remembrances-mcp/internal/config"
+	"github.com/madeindigio/remembrances-mcp/internal/drain"
 	"github.com/madeindigio/remembrances-mcp/internal/indexer"
 	"github.com/madeindigio/remembrances-mcp/internal/kb"
 	"github.com/madeindigio/remembrances-mcp/internal/storage"
+	"github.com/madeindigio/remembrances-mcp/internal/supervisor"
+	"github.com/madeindigio/remembrances-mcp/internal/surrealdocker"
 	"github.com/madeindigio/remembrances-mcp/internal/transport"
+	"github.com/madeindigio/remembrances-mcp/internal/watchqueue"
 	_ "github.com/madeindigio/remembrances-mcp/modules/standard"
 	"github.com/madeindigio/remembrances-mcp/pkg/embedder"
+	"github.com/madeindigio/remembrances-mcp/pkg/llm"
 	"github.com/madeindigio/remembrances-mcp/pkg/modules"
+	"github.com/madeindigio/remembrances-mcp/pkg/redact"
+	"github.com/madeindigio/remembrances-mcp/pkg/tenancy"
+	"github.com/madeindigio/remembrances-mcp/pkg/treesitter"
 
 	"github.com/ThinkInAIXYZ/go-mcp/protocol"
 	mcpserver "github.com/ThinkInAIXYZ/go-mcp/server"
@@ -70,37 +78,66 @@ This server provides a comprehensive remembrance system with multiple layers for
    • search_vectors: Find similar content using semantic search
    • update_vector: Update existing content and regenerate embedding
    • delete_vector: Remove semantic content
+   • remembrance_consolidate: Merge similar memories into LLM summaries
 
    KNOWLEDGE GRAPH: Create entities and relationships to model complex connections
    • create_entity: Add people, places, concepts
    • create_relationship: Connect entities with relationships
+   • end_relationship: End a relationship, keeping it as history
    • traverse_graph: Explore connections between entities
    • get_entity: Retrieve entity details
+   • remembrance_list_relationships: List relationships by entity, type or direction
+   • remembrance_graph_stats: Count entities and relationships, and find the most connected
+   • remembrance_find_path: Find how two entities are related
+   • remembrance_find_duplicate_entities: Report entities that are likely the same
+   • remembrance_merge_entities: Merge duplicate entities into one
+   • update_entity: Change the properties of an entity
+   • remembrance_define_entity_type: Define the schema of an entity type's properties
+   • remembrance_describe_entity_type: Show an entity type's schema and property usage
 
    KNOWLEDGE BASE: Store and search documents
    • kb_add_document: Add documents with automatic embedding
+   • kb_add_url: Fetch a web page and add it as a document
    • kb_search_documents: Search documents by semantic similarity
    • kb_get_document: Retrieve document by path
    • kb_delete_document: Remove documents
+   • kb_get_document_history / kb_restore_version: Browse and restore previous document versions
+   • kb_watch_status / kb_sync_now / kb_pause_watch: Inspect and control the knowledge base folder watcher
+   • kb_conflicts: List or resolve documents changed both on disk and in the database
+   • kb_staleness_report: List documents whose source page changed or that predate the current embedding model
 
    CODE INDEXING & SEARCH: Index and search codebases for intelligent code operations, if you are working with code suggest using these tools, and index your projects first if you haven't already:
    • code_index_project: Index a code project for search and analysis
    • code_list_projects: List all indexed code projects
+   • code_touch: Index the files being opened or edited before the rest of a project
+   • code_set_project_alias: Give a project a short alias usable as project_id
+   • code_remap_project: Move a project to a new checkout path without a full re-index
+   • code_configure_project: Pick the embedder and chunk sizes a project is indexed with
    • code_hybrid_search: Search code using natural language and filters
+   • code_search_docs: Search doc comments and signatures for API reference answers
    • code_find_symbol: Find symbols (functions, classes) in indexed code
    • code_search_pattern: Search for text patterns in code
+   • code_grep: Search text across all indexed files of a project
    • code_get_file_symbols: Get all symbols from a specific file
    • code_get_symbols_overview: Get high-level overview of symbols in a file
+   • code_get_repo_map: Outline a project's files and main symbols within a token budget
+   • code_read_file: Read a line range of a file or a symbol with context
    • code_activate_project_watch: Activate file monitoring for a project
    • code_deactivate_project_watch: Stop file monitoring for a project
    • code_reindex_file: Re-index a single file
    • code_get_project_stats: Get statistics for an indexed project
+   • code_index_report: Report parse failures, skipped files and symbols without embeddings
    • code_index_status: Check indexing job status
-   • code_find_references: Find all references to a symbol
+   • code_find_references: Find references to a symbol (file, package or project scope)
+   • code_get_dependencies / code_get_dependents: Follow imports from and to a file
+   • code_export_dependency_graph: Export the project import graph as JSON or DOT
+   • code_find_callers / code_find_callees: Walk the call graph to and from a function
    • code_replace_symbol: Replace source code of a symbol
    • code_insert_after_symbol: Insert code after a symbol
    • code_insert_before_symbol: Insert code before a symbol
    • code_delete_symbol: Delete a symbol from code
+   • code_apply_patch: Apply a unified diff to project files
+   • code_replace_regex: Replace regex matches in a file or symbol
 
    EVENTS: Store and search temporal events with semantic search
    • save_event: Store a temporal event with content and metadata
@@ -110,7 +147,28 @@ This server provides a comprehensive remembrance system with multiple layers for
 
    UNIFIED SEARCH: Combine all layers for comprehensive results
    • hybrid_search: Search across facts, vectors, and graph simultaneously
-   • get_stats: Get overview of all stored remembrances
+   • get_stats: Get overview of all stored remembrances, database size and index health
+   • remembrance_save_search / remembrance_run_saved_search: Save a search under a name and run it by name
+   • remembrance_search_changes: See the new results scheduled saved searches found
+   • working_memory_set / working_memory_promote: Keep session scratch notes, promote what is worth keeping
+   • remembrance_usage_report: See which memories get retrieved most and least
+   • remembrance_subscribe: Watch a memory layer for changes made by other agents
+   • remembrance_batch: Save facts, vectors, entities and relationships in one atomic transaction
+
+   TRASH: Deleted facts, vectors, documents and entities can be recovered
+   • remembrance_trash_list: List deleted items
+   • remembrance_restore: Restore a deleted item
+   • remembrance_pin: Pin a fact, vector or document so it is only deleted with force
+
+   USERS: Manage the user_ids that own stored data
+   • remembrance_list_users: List user_ids with record counts
+   • remembrance_rename_user: Move a user's data to a new user_id
+   • remembrance_delete_user: Permanently delete all data of a user
+   • remembrance_purge_user: Export a user's data to an archive, delete it and record it in the audit log
+   • remembrance_recount_stats: Recount the stored records and repair drifted statistics
+
+   ADMIN: Server diagnostics
+   • remembrance_log_level: Show or change the log level of the server and its modules
 
 Indexed Code Projects: %s
 
@@ -131,6 +189,23 @@ func main() {
 
 // Main is the entry point for embedding in custom builds.
 func Main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "fsck":
+			os.Exit(runFsck())
+		case "compact":
+			os.Exit(runCompact())
+		case "lsp":
+			os.Exit(runLSP())
+		case "import":
+			os.Exit(runImport())
+		case "config":
+			os.Exit(runConfig())
+		case "modules":
+			os.Exit(runModules())
+		}
+	}
+
 	// Load configuration
 	cfg, err := config.Load()
 	if err != nil {
@@ -158,6 +233,14 @@ func Main() {
 	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer stop()
 
+	// Multi-tenant mode: network transports require a tenant API key and serve
+	// each tenant from its own namespace/database
+	tenants, err := tenancy.NewRegistry(cfg.GetTenants())
+	if err != nil {
+		slog.Error("invalid tenants configuration", "error", err)
+		os.Exit(1)
+	}
+
 	// Select primary MCP transport:
 	// - stdio (default)
 	// - MCP Streamable HTTP when --mcp-http is passed (recommended)
@@ -165,7 +248,9 @@ func Main() {
 	// HTTP JSON API (--http) can run alongside any MCP transport
 	var t mcptransport.ServerTransport
 	var httpTransport *transport.HTTPTransport
+	var grpcTransport *transport.GRPCTransport
 	var mcpHTTPTransport mcptransport.ServerTransport
+	var mcpHTTPServer *http.Server
 
 	// Setup MCP Streamable HTTP transport if enabled
 	if cfg.MCPStreamableHTTP || cfg.SSE {
@@ -198,12 +283,29 @@ func Main() {
 
 		addr = normalizeBindAddr(addr, "3000")
 		slog.Info("MCP Streamable HTTP transport enabled", "address", addr, "endpoint", endpoint)
-		mcpHTTPTransport = mcptransport.NewStreamableHTTPServerTransport(
-			addr,
-			mcptransport.WithStreamableHTTPServerTransportOptionLogger(streamableHTTPLogger()),
-			mcptransport.WithStreamableHTTPServerTransportOptionEndpoint(endpoint),
-			mcptransport.WithStreamableHTTPServerTransportOptionStateMode(mcptransport.Stateful),
-		)
+		if tenants.Enabled() {
+			// Serve the MCP endpoint from our own server so every request is
+			// authenticated and carries its tenant
+			var handler *mcptransport.StreamableHTTPHandler
+			mcpHTTPTransport, handler, err = mcptransport.NewStreamableHTTPServerTransportAndHandler(
+				mcptransport.WithStreamableHTTPServerTransportAndHandlerOptionLogger(streamableHTTPLogger()),
+				mcptransport.WithStreamableHTTPServerTransportAndHandlerOptionStateMode(mcptransport.Stateful),
+			)
+			if err != nil {
+				slog.Error("failed to create MCP Streamable HTTP transport", "error", err)
+				os.Exit(1)
+			}
+			mux := http.NewServeMux()
+			mux.Handle(endpoint, handler.HandleMCP())
+			mcpHTTPServer = &http.Server{Addr: addr, Handler: tenants.Middleware(mux)}
+		} else {
+			mcpHTTPTransport = mcptransport.NewStreamableHTTPServerTransport(
+				addr,
+				mcptransport.WithStreamableHTTPServerTransportOptionLogger(streamableHTTPLogger()),
+				mcptransport.WithStreamableHTTPServerTransportOptionEndpoint(endpoint),
+				mcptransport.WithStreamableHTTPServerTransportOptionStateMode(mcptransport.Stateful),
+			)
+		}
 		t = mcpHTTPTransport
 	} else {
 		slog.Info("Starting MCP over stdio (default)")
@@ -211,117 +313,36 @@ func Main() {
 	}
 
 	// Initialize storage early to generate dynamic instructions
-	var storageInstance storage.FullStorage
-	if cfg.SurrealDBURL != "" {
-		// Use remote SurrealDB
-		storageConfig := &storage.ConnectionConfig{
-			URL:             cfg.SurrealDBURL,
-			Username:        cfg.SurrealDBUser,
-			Password:        cfg.SurrealDBPass,
-			Namespace:       cfg.GetSurrealDBNamespace(),
-			Database:        cfg.GetSurrealDBDatabase(),
-			Timeout:         30 * time.Second,
-			UseEmbeddedLibs: cfg.UseEmbeddedLibs,
-			EmbeddedLibsDir: cfg.EmbeddedLibsDir,
-		}
-		storageInstance = storage.NewSurrealDBStorage(storageConfig)
-	} else {
-		// Use embedded SurrealDB
-		storageConfig := &storage.ConnectionConfig{
-			DBPath:          cfg.DbPath,
-			Namespace:       cfg.GetSurrealDBNamespace(),
-			Database:        cfg.GetSurrealDBDatabase(),
-			Timeout:         30 * time.Second,
-			UseEmbeddedLibs: cfg.UseEmbeddedLibs,
-			EmbeddedLibsDir: cfg.EmbeddedLibsDir,
-		}
-		storageInstance = storage.NewSurrealDBStorage(storageConfig)
-	}
+	storageInstance := newStorage(cfg)
 
 	// Connect to storage. If connection fails and a SurrealDB start command is provided
-	// in the configuration, attempt to run it and retry the connection.
-	// Keep track of any process we start so we can shut it down when this app exits.
-	var startedProc *exec.Cmd
-	var procExited chan struct{}
-
-	if err := storageInstance.Connect(ctx); err != nil {
-		slog.Warn("initial connection to SurrealDB failed", "error", err)
+	// in the configuration, run it under a supervisor and retry the connection.
+	// The supervisor restarts the process if it crashes and stops it when this app exits.
+	var surrealProc *supervisor.Process
+
+	if cfg.SurrealDBDocker {
+		// The container is always started by us, replacing any leftover one,
+		// so it is stopped on shutdown
+		var err error
+		surrealProc, err = startSurrealDBDocker(ctx, cfg, storageInstance)
+		if err != nil {
+			slog.Error("failed to start SurrealDB container", "image", cfg.GetSurrealDBDocker().ImageRef(), "error", err)
+			os.Exit(1)
+		}
+	} else if err := storageInstance.Connect(ctx); err != nil {
+		slog.Warn("initial connection to storage failed", "error", err)
 
 		// If a start command is configured, try to run it and retry connecting.
-		if cfg.SurrealDBStartCmd != "" {
-			slog.Info("attempting to start external SurrealDB process", "cmd", cfg.SurrealDBStartCmd)
-
-			// Run the configured command in a separate process.
-			// Use /bin/sh -c so users can provide complex commands or use aliases.
-			startCmd := cfg.SurrealDBStartCmd
-			proc := exec.CommandContext(ctx, "/bin/sh", "-c", startCmd)
-			// Redirect output to the main logger's stdout/stderr so users can see it
-			proc.Stdout = os.Stdout
-			proc.Stderr = os.Stderr
-
-			if err := proc.Start(); err != nil {
-				slog.Error("failed to start external SurrealDB command", "cmd", startCmd, "error", err)
-				os.Exit(1)
-			}
-
-			// keep a reference so we can shut it down on app exit
-			startedProc = proc
-			procExited = make(chan struct{})
-			go func() {
-				if err := proc.Wait(); err != nil {
-					slog.Warn("surrealdb process exited with error", "error", err)
-				} else {
-					slog.Info("surrealdb process exited")
-				}
-				close(procExited)
-			}()
-
-			// Give the process some time to start and then retry connection with backoff
-			retryCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-			defer cancel()
-
-			backoff := 1 * time.Second
-			connected := false
-			for {
-				select {
-				case <-retryCtx.Done():
-					slog.Error("timed out waiting for SurrealDB to become available")
-					// Try one last time with the original context
-					if err := storageInstance.Connect(ctx); err != nil {
-						slog.Error("surrealdb still unreachable after start command", "error", err)
-						os.Exit(1)
-					}
-					connected = true
-				default:
-					time.Sleep(backoff)
-					if err := storageInstance.Connect(ctx); err != nil {
-						slog.Info("surrealdb not ready yet, retrying...", "wait", backoff)
-						if backoff < 5*time.Second {
-							backoff = backoff * 2
-						}
-						// continue retrying
-					} else {
-						connected = true
-					}
-				}
-				if connected {
-					break
-				}
-			}
-
-			if !connected {
-				slog.Error("failed to connect to SurrealDB after running start command")
+		if cfg.SurrealDBStartCmd != "" && cfg.GetStorageBackend() == "surrealdb" {
+			surrealProc, err = startSurrealDB(ctx, cfg, storageInstance)
+			if err != nil {
+				slog.Error("failed to start SurrealDB", "cmd", cfg.SurrealDBStartCmd, "error", err)
 				os.Exit(1)
 			}
-
-			// At this point connection succeeded; ensure Close will be called
-			defer storageInstance.Close()
 		} else {
 			slog.Error("failed to connect to storage", "error", err, "hint", "set --surrealdb-start-cmd or GOMEM_SURREALDB_START_CMD to auto-start a local SurrealDB")
 			os.Exit(1)
 		}
-	} else {
-		defer storageInstance.Close()
 	}
 
 	// Initialize schema
@@ -330,6 +351,23 @@ func Main() {
 		os.Exit(1)
 	}
 
+	if tenants.Enabled() {
+		if err := connectTenants(ctx, storageInstance, tenants); err != nil {
+			slog.Error("failed to connect tenant databases", "error", err)
+			os.Exit(1)
+		}
+	}
+
+	if cfg.RecountStatsOnStartup {
+		if changes, err := storageInstance.RecountUserStats(ctx, ""); err != nil {
+			slog.Warn("failed to recount user stats", "error", err)
+		} else {
+			slog.Info("recounted user stats", "corrected", len(changes))
+		}
+	}
+
+	warnStaleEmbeddings(ctx, storageInstance)
+
 	// Generate dynamic instructions
 	instructions := generateInstructions(storageInstance)
 
@@ -370,6 +408,48 @@ func Main() {
 		slog.Info("Using specialized code embedder for code indexing")
 	}
 
+	// Route each record kind to its embedder; embedding routes may override
+	// the code embedder
+	embedders, err := embedder.NewRouterFromMainConfig(cfg, embedderInstance, codeEmbedderInstance)
+	if err != nil {
+		slog.Error("failed to create routed embedders", "error", err)
+		os.Exit(1)
+	}
+	if err := embedders.RequireDimension(storage.EmbeddingDimension); err != nil {
+		slog.Error("invalid embedding route", "error", err)
+		os.Exit(1)
+	}
+	codeEmbedderInstance = embedders.For(embedder.RouteCode)
+	for kind, model := range embedders.Models() {
+		slog.Debug("embedding route", "kind", kind, "model", model)
+	}
+
+	if cfg.GGUFWarmup {
+		for _, route := range embedders.Embedders() {
+			warmUpEmbedder(ctx, route.Model, route.Embedder)
+		}
+	}
+
+	// Initialize the optional LLM used for memory consolidation
+	llmClient, err := llm.NewClientFromMainConfig(cfg)
+	if err != nil {
+		slog.Error("failed to create LLM client", "error", err)
+		os.Exit(1)
+	}
+	if llmClient == nil {
+		slog.Info("No LLM configured; remembrance_consolidate is limited to dry runs")
+	}
+
+	// Initialize the redaction filter applied to stored content
+	redactor, err := redact.New(cfg.GetRedactionMode(), cfg.GetRedactionRules(), cfg.GetRedactionPatterns())
+	if err != nil {
+		slog.Error("failed to configure redaction", "error", err)
+		os.Exit(1)
+	}
+	if redactor.Enabled() {
+		slog.Info("Redaction enabled", "mode", redactor.Mode())
+	}
+
 	// Knowledge base path validation:
 	// - if configured and missing, attempt to create it (mkdir -p)
 	// - if creation fails (or path is not a directory), disable all KB features
@@ -386,18 +466,54 @@ func Main() {
 		}
 	}
 
+	// Custom symbol extraction rules, used by every AST walker created
+	// afterwards
+	if dir := cfg.CodeIndexingQueriesDir; dir != "" {
+		langs, err := treesitter.LoadQueryFiles(dir)
+		if err != nil {
+			slog.Warn("some tree-sitter query files were not loaded", "dir", dir, "error", err)
+		}
+		if len(langs) > 0 {
+			slog.Info("Loaded tree-sitter query files", "dir", dir, "languages", langs)
+		}
+	}
+
+	// The knowledge base watchers start after the tools are registered
+	kbRoots := buildKBRoots(cfg)
+	var kbWatchers []*kb.Watcher
+
 	// Initialize module manager
 	modManager := modules.NewModuleManager(modules.ModuleConfig{
-		Storage:           storageInstance,
-		Embedder:          embedderInstance,
-		CodeEmbedder:      codeEmbedderInstance,
-		KnowledgeBasePath: cfg.KnowledgeBase,
-		KBChunkSize:       cfg.GetChunkSize(),
-		KBChunkOverlap:    cfg.GetChunkOverlap(),
-		DisableCodeWatch:  cfg.DisableCodeWatch,
-		IndexerConfig:     buildIndexerConfig(cfg),
-		JobManagerConfig:  indexer.DefaultJobManagerConfig(),
-		Logger:            slog.Default(),
+		Storage:                storageInstance,
+		Embedder:               embedderInstance,
+		CodeEmbedder:           codeEmbedderInstance,
+		EmbeddingRouter:        embedders,
+		KnowledgeBasePath:      cfg.KnowledgeBase,
+		KBChunkSize:            cfg.GetChunkSize(),
+		KBChunkOverlap:         cfg.GetChunkOverlap(),
+		KBChunkStrategy:        cfg.GetChunkStrategy(),
+		KBRoots:                kbRoots,
+		KBWatchers:             func() []*kb.Watcher { return kbWatchers },
+		KBRecrawlInterval:      cfg.GetKBRecrawlInterval(),
+		KBURLPrivateNetworks:   cfg.KBURLPrivateNetworks,
+		WorkingMemoryTTL:       cfg.GetWorkingMemoryTTL(),
+		EmbeddingModel:         embedder.ModelName(cfg),
+		DuplicateThreshold:     cfg.GetDuplicateThreshold(),
+		LLM:                    llmClient,
+		ConsolidationThreshold: cfg.GetConsolidationThreshold(),
+		PurgeArchiveDir:        cfg.GetPurgeArchiveDir(),
+		MaxOutputBytes:         cfg.MaxOutputBytes,
+		MinSimilarity:          cfg.MinSimilarity,
+		ScoreNormalization:     cfg.GetScoreNormalization(),
+		Redactor:               redactor,
+		ProgressNotifier:       srv.SendProgressNotification,
+		DisableCodeWatch:       cfg.DisableCodeWatch,
+		CodeCheckCommands:      cfg.GetCodeCheckCommands(),
+		IndexerConfig:          buildIndexerConfig(cfg),
+		JobManagerConfig:       buildJobManagerConfig(cfg),
+		Logger:                 slog.Default(),
+		LogLevels:              cfg.GetLogLevels(),
+		StorageBackend:         cfg.GetStorageBackend(),
 	})
 
 	if err := loadModules(ctx, modManager, cfg); err != nil {
@@ -408,25 +524,43 @@ func Main() {
 	// Allow modules to wrap storage (e.g., db-sync-server wraps with MergedStorage)
 	storageInstance = modManager.WrapStorage(storageInstance)
 
-	// Register tools from modules
-	if err := registerModuleTools(modManager, srv); err != nil {
+	// Register tools from modules. Calls are tracked so that shutdown can
+	// refuse new ones and wait for those in flight.
+	var calls drain.Tracker
+	if err := registerModuleTools(modManager, srv, &calls); err != nil {
 		slog.Error("failed to register module tools", "error", err)
 		os.Exit(1)
 	}
 
-	// Knowledge base watcher
-	var kbWatcher *kb.Watcher
-	if cfg.KnowledgeBase != "" {
-		w, err := kb.StartWatcher(ctx, cfg.KnowledgeBase, storageInstance, embedderInstance, cfg.GetChunkSize(), cfg.GetChunkOverlap())
+	// Knowledge base watchers, one per root, run concurrently
+	for _, root := range kbRoots {
+		// Watchers are stopped by the shutdown sequence, not by the signal,
+		// so a document being synced is not cut short
+		w, err := kb.StartWatcher(context.WithoutCancel(ctx), root, storageInstance, embedders.For(embedder.RouteKnowledgeBase), embedders.Model(embedder.RouteKnowledgeBase), embedder.ChunkStrategy(cfg.GetChunkStrategy()), buildWatchQueueConfig(cfg))
 		if err != nil {
-			slog.Warn("failed to start knowledge base watcher", "error", err)
+			slog.Warn("failed to start knowledge base watcher", "root", root.Label, "path", root.Path, "error", err)
+			continue
+		}
+		kbWatchers = append(kbWatchers, w)
+	}
+
+	// Purge trash entries older than the retention window, at startup and hourly
+	if retention := cfg.GetTrashRetention(); retention > 0 {
+		go purgeTrashLoop(ctx, storageInstance, retention)
+	}
+
+	// Compact the embedded database files on a schedule
+	if interval := cfg.GetCompactInterval(); interval > 0 {
+		if compactor, ok := storageInstance.(storage.Compactor); ok && cfg.GetStorageBackend() == "surrealdb" && cfg.GetSurrealDBURL() == "" && !cfg.Memory {
+			go compactLoop(ctx, compactor, interval)
 		} else {
-			kbWatcher = w
+			slog.Warn("compact-interval-hours ignored; compaction is only supported for the embedded file-backed database")
 		}
 	}
 
-	// If HTTP transport is enabled, set it up now that the server is configured
-	if cfg.HTTP {
+	// If HTTP transport is enabled, set it up now that the server is configured.
+	// The REST API is served by the same transport.
+	if cfg.HTTP || cfg.RestAPIServe {
 		addr := cfg.HTTPAddr
 		if env := os.Getenv("GOMEM_HTTP_ADDR"); env != "" {
 			addr = env
@@ -438,6 +572,11 @@ func Main() {
 			slog.Error("failed to create HTTP transport", "error", err)
 			os.Exit(1)
 		}
+		httpTransport.SetTenants(tenants)
+
+		if cfg.RestAPIServe {
+			httpTransport.RegisterRESTAPI(transport.NewRESTAPI(moduleToolDefinitions(modManager, &calls), version.Version))
+		}
 
 		// Register HTTP routes from modules
 		httpProviders := modManager.GetHTTPEndpointProviders()
@@ -447,70 +586,113 @@ func Main() {
 		}
 	}
 
+	// The gRPC API runs alongside whichever MCP transport is active
+	if cfg.GRPC {
+		addr := cfg.GRPCAddr
+		if env := os.Getenv("GOMEM_GRPC_ADDR"); env != "" {
+			addr = env
+		}
+		addr = normalizeBindAddr(addr, "50051")
+
+		grpcTransport = transport.NewGRPCTransport(addr, storageInstance, embedders, redactor, tenants)
+		go func() {
+			if err := grpcTransport.Start(); err != nil {
+				slog.Error("gRPC transport server error", "error", err)
+			}
+		}()
+	}
+
 	slog.Info("Remembrances-MCP server initialized successfully")
 
-	// Graceful shutdown
+	// Graceful shutdown, in order: refuse new tool calls and wait for those
+	// in flight, stop the transports, let the watchers and indexing jobs
+	// finish their writes, then close storage and the SurrealDB process.
+	shutdownDone := make(chan struct{})
 	go func() {
+		defer close(shutdownDone)
 		<-ctx.Done()
 		slog.Info("Shutdown signal received, starting graceful shutdown")
-		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		timeout := cfg.GetShutdownTimeout()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
 		defer cancel()
 
+		// Ensure the process actually exits even if a step below blocks or
+		// third-party transports don't return promptly. Some test harnesses
+		// (like the stdio client) send SIGINT and expect the process to exit.
+		time.AfterFunc(timeout+3*time.Second, func() {
+			slog.Info("Graceful shutdown timed out; forcing exit")
+			// Use Exit to ensure tests detect the process has terminated.
+			os.Exit(0)
+		})
+
+		if err := calls.Drain(shutdownCtx); err != nil {
+			slog.Warn("tool calls still running at the shutdown timeout", "in_flight", calls.InFlight())
+		}
+
 		// Shutdown HTTP transport if running
 		if httpTransport != nil {
 			_ = httpTransport.Shutdown(shutdownCtx)
 		}
 
-		// Stop knowledge base watcher
-		if kbWatcher != nil {
-			kbWatcher.Stop()
+		// Shutdown the authenticated MCP Streamable HTTP server if running
+		if mcpHTTPServer != nil {
+			_ = mcpHTTPServer.Shutdown(shutdownCtx)
 		}
 
-		// Stop module-managed resources
-		modManager.Cleanup()
+		// Stop the gRPC API if running
+		if grpcTransport != nil {
+			grpcTransport.Stop(shutdownCtx)
+		}
+
+		// Flush knowledge base watchers and module work (code watcher,
+		// indexing jobs) concurrently, within the same deadline
+		var flushed sync.WaitGroup
+		for _, w := range kbWatchers {
+			flushed.Add(1)
+			go func() {
+				defer flushed.Done()
+				if err := w.Shutdown(shutdownCtx); err != nil {
+					slog.Warn("knowledge base watcher did not finish before the shutdown timeout", "error", err)
+				}
+			}()
+		}
+		modManager.Shutdown(shutdownCtx)
+		flushed.Wait()
 
 		_ = srv.Shutdown(shutdownCtx)
 
-		// Ensure the process actually exits even if srv.Shutdown blocks or
-		// third-party transports don't return promptly. Some test harnesses
-		// (like the stdio client) send SIGINT and expect the process to exit
-		// within a few seconds. Start a short timer that forces exit if
-		// shutdown does not complete in time.
-		go func() {
-			select {
-			case <-time.After(3 * time.Second):
-				slog.Info("Graceful shutdown timed out; forcing exit")
-				// Use Exit to ensure tests detect the process has terminated.
-				os.Exit(0)
-			}
-		}()
+		if err := storageInstance.Close(); err != nil {
+			slog.Warn("failed to close storage", "error", err)
+		}
+
 		// If we started a SurrealDB process, try to stop it gracefully.
-		if startedProc != nil && startedProc.Process != nil {
+		if surrealProc != nil {
 			slog.Info("shutting down started SurrealDB process")
-			// First try a polite SIGTERM
-			_ = startedProc.Process.Signal(syscall.SIGTERM)
-
-			// Wait a short while for it to exit
-			select {
-			case <-procExited:
-				slog.Info("started SurrealDB process exited cleanly")
-			case <-time.After(5 * time.Second):
-				slog.Warn("started SurrealDB process did not exit after SIGTERM, killing")
-				_ = startedProc.Process.Kill()
-				// wait for reaper if not already closed
-				select {
-				case <-procExited:
-				case <-time.After(2 * time.Second):
+			surrealProc.Stop(5 * time.Second)
+			if cfg.SurrealDBDocker {
+				if err := surrealdocker.Remove(context.Background(), cfg.SurrealDBDockerContainer); err != nil {
+					slog.Warn("failed to remove SurrealDB container", "error", err)
 				}
 			}
 		}
+		slog.Info("Shutdown complete")
 	}()
 
 	// Run the server (blocking or concurrent based on configuration)
 	slog.Info("Starting Remembrances-MCP server")
 
+	// In multi-tenant mode the MCP Streamable HTTP endpoint has its own server
+	if mcpHTTPServer != nil {
+		go func() {
+			slog.Info("Starting MCP Streamable HTTP server with tenant authentication", "address", mcpHTTPServer.Addr)
+			if err := mcpHTTPServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				slog.Error("MCP Streamable HTTP server error", "error", err)
+			}
+		}()
+	}
+
 	// Determine which transports to run
-	hasHTTP := cfg.HTTP && httpTransport != nil
+	hasHTTP := httpTransport != nil
 	hasMCPHTTP := mcpHTTPTransport != nil
 
 	if hasHTTP && hasMCPHTTP {
@@ -553,18 +735,159 @@ func Main() {
 			os.Exit(1)
 		}
 	}
+
+	// The transport also stops on its own, e.g. when stdin is closed: run the
+	// shutdown sequence then too, and wait for it before exiting
+	stop()
+	<-shutdownDone
+}
+
+// newStorage creates the storage selected by the configuration: Postgres,
+// remote SurrealDB or embedded SurrealDB.
+func newStorage(cfg *config.Config) storage.FullStorage {
+	if cfg.GetStorageBackend() == "postgres" {
+		pg := storage.NewPostgresStorage(cfg.PostgresURL, 30*time.Second)
+		pg.SetEmbeddingDimensionCheck(cfg.StrictEmbeddingDimension, embedder.ModelName(cfg))
+		pg.SetCodeEmbeddingModel(embedder.CodeModelName(cfg))
+		pg.SetTableEmbeddingModels(tableEmbeddingModels(cfg))
+		return pg
+	}
+	if cfg.GetSurrealDBURL() != "" && !cfg.Memory {
+		// Use remote SurrealDB
+		return storage.NewSurrealDBStorage(&storage.ConnectionConfig{
+			URL:                      cfg.GetSurrealDBURL(),
+			Username:                 cfg.SurrealDBUser,
+			Password:                 cfg.SurrealDBPass,
+			Namespace:                cfg.GetSurrealDBNamespace(),
+			Database:                 cfg.GetSurrealDBDatabase(),
+			Timeout:                  30 * time.Second,
+			QueryTimeout:             cfg.GetSurrealDBQueryTimeout(),
+			UseEmbeddedLibs:          cfg.UseEmbeddedLibs,
+			EmbeddedLibsDir:          cfg.EmbeddedLibsDir,
+			EmbeddingFormat:          storage.EmbeddingFormat(cfg.GetEmbeddingStorage()),
+			StrictEmbeddingDimension: cfg.StrictEmbeddingDimension,
+			EmbeddingModel:           embedder.ModelName(cfg),
+			CodeEmbeddingModel:       embedder.CodeModelName(cfg),
+			TableEmbeddingModels:     tableEmbeddingModels(cfg),
+		})
+	}
+	// Use embedded SurrealDB
+	if cfg.Memory {
+		slog.Warn("Running SurrealDB in memory; all data is lost on exit")
+	}
+	return storage.NewSurrealDBStorage(&storage.ConnectionConfig{
+		DBPath:                   cfg.GetDbPath(),
+		Namespace:                cfg.GetSurrealDBNamespace(),
+		Database:                 cfg.GetSurrealDBDatabase(),
+		Timeout:                  30 * time.Second,
+		QueryTimeout:             cfg.GetSurrealDBQueryTimeout(),
+		UseEmbeddedLibs:          cfg.UseEmbeddedLibs,
+		EmbeddedLibsDir:          cfg.EmbeddedLibsDir,
+		EmbeddingFormat:          storage.EmbeddingFormat(cfg.GetEmbeddingStorage()),
+		StrictEmbeddingDimension: cfg.StrictEmbeddingDimension,
+		EmbeddingModel:           embedder.ModelName(cfg),
+		CodeEmbeddingModel:       embedder.CodeModelName(cfg),
+		TableEmbeddingModels:     tableEmbeddingModels(cfg),
+	})
+}
+
+// tableEmbeddingModels returns the model embedding each table according to
+// the embedding routes.
+func tableEmbeddingModels(cfg *config.Config) map[string]string {
+	models, err := embedder.RouteModelNames(cfg)
+	if err != nil {
+		// Validate rejects invalid routes before storage is created
+		return nil
+	}
+	return storage.TableEmbeddingModels(models)
+}
+
+// warnStaleEmbeddings logs the records embedded by another model than the
+// configured one, which searches skip until they are re-embedded.
+func warnStaleEmbeddings(ctx context.Context, store storage.FullStorage) {
+	reporter, ok := store.(storage.EmbeddingModelReporter)
+	if !ok {
+		return
+	}
+	counts, err := reporter.EmbeddingModels(ctx)
+	if err != nil {
+		slog.Warn("failed to check the embedding models of stored records", "error", err)
+		return
+	}
+	for _, c := range counts {
+		if c.Stale {
+			slog.Warn("records embedded by another model are skipped by searches; re-embed them to search them again",
+				"table", c.Table, "model", c.Model, "count", c.Count)
+		}
+	}
 }
 
-func registerModuleTools(modManager *modules.ModuleManager, srv *mcpserver.Server) error {
+// connectTenants opens the database of each tenant on the SurrealDB storage.
+// warmUpEmbedder runs a first embedding with local models, which allocate
+// their compute buffers on first use, so no tool call pays for it. Remote
+// embedders are left alone. A failed warm-up is only logged.
+func warmUpEmbedder(ctx context.Context, name string, emb embedder.Embedder) {
+	w, ok := emb.(interface {
+		WarmUp(context.Context) (time.Duration, error)
+	})
+	if !ok {
+		return
+	}
+	took, err := w.WarmUp(ctx)
+	if err != nil {
+		slog.Warn("embedder warm-up failed", "embedder", name, "error", err)
+		return
+	}
+	slog.Info("embedder warmed up", "embedder", name, "took", took)
+}
+
+func connectTenants(ctx context.Context, store storage.FullStorage, tenants *tenancy.Registry) error {
+	surreal, ok := store.(*storage.SurrealDBStorage)
+	if !ok {
+		return fmt.Errorf("multi-tenant mode is not supported by %T", store)
+	}
+	for _, tenant := range tenants.Tenants() {
+		if err := surreal.ConnectTenant(ctx, tenant); err != nil {
+			return err
+		}
+	}
+	slog.Info("Multi-tenant mode enabled", "tenants", len(tenants.Tenants()))
+	return nil
+}
+
+func registerModuleTools(modManager *modules.ModuleManager, srv *mcpserver.Server, calls *drain.Tracker) error {
+	for _, def := range moduleToolDefinitions(modManager, calls) {
+		if def.Tool == nil {
+			return fmt.Errorf("module tool definition returned nil")
+		}
+		srv.RegisterTool(def.Tool, def.Handler)
+	}
+	return nil
+}
+
+// moduleToolDefinitions returns the tools of every loaded module, with
+// handlers tracked by calls.
+func moduleToolDefinitions(modManager *modules.ModuleManager, calls *drain.Tracker) []modules.ToolDefinition {
+	var defs []modules.ToolDefinition
 	for _, provider := range modManager.GetToolProviders() {
 		for _, def := range provider.Tools() {
-			if def.Tool == nil {
-				return fmt.Errorf("module tool definition returned nil")
-			}
-			srv.RegisterTool(def.Tool, def.Handler)
+			def.Handler = drained(calls, def.Handler)
+			defs = append(defs, def)
 		}
 	}
-	return nil
+	return defs
+}
+
+// drained wraps a tool handler so that it counts as in flight while it runs,
+// and refuses calls once shutdown started.
+func drained(calls *drain.Tracker, handler modules.ToolHandler) modules.ToolHandler {
+	return func(ctx context.Context, request *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+		if err := calls.Begin(); err != nil {
+			return nil, err
+		}
+		defer calls.End()
+		return handler(ctx, request)
+	}
 }
 
 func loadModules(ctx context.Context, modManager *modules.ModuleManager, cfg *config.Config) error {
@@ -581,6 +904,12 @@ func loadModules(ctx context.Context, modManager *modules.ModuleManager, cfg *co
 	if cfg.KnowledgeBase != "" {
 		defaultModules = append(defaultModules, "tools.kb")
 	}
+	// Modules built in with xremembrances that ask to be loaded without config
+	for _, info := range modules.ListModules() {
+		if info.AutoLoad {
+			defaultModules = append(defaultModules, info.ID)
+		}
+	}
 
 	disabled := make(map[string]struct{})
 	for _, id := range cfg.DisableModules {
@@ -594,6 +923,9 @@ func loadModules(ctx context.Context, modManager *modules.ModuleManager, cfg *co
 		if _, isDisabled := disabled[string(id)]; isDisabled {
 			continue
 		}
+		if _, exists := loaded[id]; exists {
+			continue
+		}
 		if hasEntry && !entry.Enabled && len(entry.Config) == 0 {
 			continue
 		}
@@ -657,10 +989,113 @@ func buildIndexerConfig(cfg *config.Config) indexer.IndexerConfig {
 		ic.Scanner.MergeExcludePatterns(userPatterns)
 	}
 
+	// Heuristics for binary, minified and vendored files
+	ic.Scanner.SniffContent = cfg.CodeIndexingSniffContent
+	if !cfg.CodeIndexingVendorHeuristics {
+		ic.Scanner.VendorPatterns = nil
+	}
+
 	// Apply max file size
 	if mfs := cfg.GetCodeIndexingMaxFileSize(); mfs > 0 {
 		ic.Scanner.MaxFileSize = mfs
 	}
 
+	ic.StoreFileContents = cfg.CodeIndexingStoreContents
+	ic.WatchQueue = buildWatchQueueConfig(cfg)
+
+	// Throughput controls, so large projects do not starve the tools
+	ic.MaxFilesPerJob = cfg.GetCodeIndexingMaxFilesPerJob()
+	ic.EmbeddingQPS = cfg.GetCodeIndexingEmbeddingQPS()
+	ic.Background = cfg.CodeIndexingBackground
+
 	return ic
 }
+
+// buildJobManagerConfig creates the JobManagerConfig from the application
+// config.
+func buildJobManagerConfig(cfg *config.Config) indexer.JobManagerConfig {
+	jc := indexer.DefaultJobManagerConfig()
+	jc.MaxConcurrentJobs = cfg.GetCodeIndexingMaxJobs()
+	return jc
+}
+
+// buildWatchQueueConfig creates the debounce and reindex budget shared by the
+// code and knowledge base watchers.
+func buildWatchQueueConfig(cfg *config.Config) watchqueue.Config {
+	wq := watchqueue.DefaultConfig()
+	wq.Debounce = cfg.GetWatcherDebounce()
+	wq.MaxPerMinute = cfg.GetWatcherMaxReindexPerMinute()
+	return wq
+}
+
+// buildKBRoots returns the knowledge base directories to watch: the
+// knowledge-base directory and the configured knowledge-base-roots. Roots
+// whose directory cannot be created are skipped.
+func buildKBRoots(cfg *config.Config) []kb.Root {
+	var roots []kb.Root
+	if cfg.KnowledgeBase != "" {
+		roots = append(roots, kb.Root{
+			Path:         cfg.KnowledgeBase,
+			Label:        storage.DefaultKBRoot,
+			ChunkSize:    cfg.GetChunkSize(),
+			ChunkOverlap: cfg.GetChunkOverlap(),
+			Graph:        cfg.KnowledgeBaseGraph,
+		})
+	}
+	for _, r := range cfg.GetKnowledgeBaseRoots() {
+		if err := os.MkdirAll(r.Path, 0755); err != nil {
+			slog.Warn("knowledge base root disabled; failed to create directory", "root", r.Label, "path", r.Path, "error", err)
+			continue
+		}
+		roots = append(roots, kb.Root{
+			Path:         r.Path,
+			Label:        r.Label,
+			ChunkSize:    r.ChunkSize,
+			ChunkOverlap: r.ChunkOverlap,
+			Include:      r.Include,
+			Exclude:      r.Exclude,
+			Graph:        r.Graph,
+		})
+	}
+	return roots
+}
+
+// purgeTrashLoop permanently deletes trash entries older than retention until ctx is done.
+func purgeTrashLoop(ctx context.Context, st storage.FullStorage, retention time.Duration) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+	for {
+		purged, err := st.PurgeTrash(ctx, time.Now().Add(-retention))
+		if err != nil {
+			slog.Warn("failed to purge trash", "error", err)
+		} else if purged > 0 {
+			slog.Info("Purged expired trash entries", "count", purged, "retention", retention)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// compactLoop compacts the storage files every interval until ctx is done.
+func compactLoop(ctx context.Context, compactor storage.Compactor, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		report, err := compactor.Compact(ctx)
+		if err != nil {
+			slog.Warn("failed to compact storage", "error", err)
+			continue
+		}
+		slog.Info("Compacted storage", "location", report.Location, "size_before", report.SizeBefore, "size_after", report.SizeAfter, "reclaimed", report.Reclaimed, "duration", report.Duration)
+	}
+}
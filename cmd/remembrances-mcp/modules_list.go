@@ -0,0 +1,30 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/madeindigio/remembrances-mcp/pkg/embedder"
+	"github.com/madeindigio/remembrances-mcp/pkg/modules"
+)
+
+// runModules implements "remembrances-mcp modules": it lists the modules
+// built into this binary, including those added with xremembrances, with
+// the hook points each one implements. It returns the exit code.
+func runModules() int {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tVERSION\tAUTOLOAD\tHOOKS\tDESCRIPTION")
+	for _, info := range modules.ListModules() {
+		fmt.Fprintf(w, "%s\t%s\t%t\t%s\t%s\n", info.ID, info.Version, info.AutoLoad, strings.Join(modules.Hooks(info), ","), info.Description)
+	}
+	if err := w.Flush(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error printing modules: %v\n", err)
+		return 1
+	}
+	if backends := embedder.Backends(); len(backends) > 0 {
+		fmt.Printf("\nEmbedder backends: %s\n", strings.Join(backends, ", "))
+	}
+	return 0
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/madeindigio/remembrances-mcp/internal/config"
+	"github.com/madeindigio/remembrances-mcp/internal/storage"
+	"github.com/madeindigio/remembrances-mcp/internal/supervisor"
+	"github.com/madeindigio/remembrances-mcp/internal/surrealdocker"
+)
+
+// surrealConnectTimeout bounds the wait for a started SurrealDB to accept
+// connections, at startup and after every restart.
+const surrealConnectTimeout = 30 * time.Second
+
+// startSurrealDB runs cfg.SurrealDBStartCmd under a supervisor and connects
+// st to it.
+func startSurrealDB(ctx context.Context, cfg *config.Config, st storage.FullStorage) (*supervisor.Process, error) {
+	slog.Info("attempting to start external SurrealDB process", "cmd", cfg.SurrealDBStartCmd)
+	// Use /bin/sh -c so users can provide complex commands or use aliases
+	return superviseSurrealDB(ctx, cfg, st, supervisor.Config{Command: cfg.SurrealDBStartCmd}, nil)
+}
+
+// startSurrealDBDocker runs the SurrealDB container of cfg under a
+// supervisor, replacing any container left with the same name, and connects
+// st to it once it is ready.
+func startSurrealDBDocker(ctx context.Context, cfg *config.Config, st storage.FullStorage) (*supervisor.Process, error) {
+	if err := surrealdocker.CheckDocker(ctx); err != nil {
+		return nil, err
+	}
+	docker := cfg.GetSurrealDBDocker()
+	if err := surrealdocker.EnsureImage(ctx, docker.ImageRef(), os.Stderr); err != nil {
+		return nil, err
+	}
+	if err := surrealdocker.Remove(ctx, docker.Container); err != nil {
+		return nil, err
+	}
+	slog.Info("starting SurrealDB container", "image", docker.ImageRef(), "container", docker.Container, "port", docker.Port, "volume", docker.Volume)
+	if docker.Volume == "" {
+		slog.Warn("surrealdb-docker-volume not set; the container keeps the database in memory and it is lost on exit")
+	}
+	// The container's output goes to stderr, which never carries MCP messages
+	procCfg := supervisor.Config{Command: "docker run " + docker.ImageRef(), Args: surrealdocker.RunArgs(docker), Stdout: os.Stderr}
+	// A container that crashed may linger until docker removes it, and its
+	// name must be free before it is started again
+	removeContainer := func() {
+		if err := surrealdocker.Remove(context.Background(), docker.Container); err != nil {
+			slog.Warn("failed to remove SurrealDB container", "error", err)
+		}
+	}
+	return superviseSurrealDB(ctx, cfg, st, procCfg, removeContainer)
+}
+
+// superviseSurrealDB starts the SurrealDB process of procCfg and connects st
+// to it. When the process crashes mid-session, storage calls are paused,
+// beforeRestart (when not nil) runs, the process is restarted with backoff
+// and st reconnected, so the calls resume instead of failing.
+func superviseSurrealDB(ctx context.Context, cfg *config.Config, st storage.FullStorage, procCfg supervisor.Config, beforeRestart func()) (*supervisor.Process, error) {
+	reconnector, _ := st.(storage.Reconnector)
+	// Show the output of the process alongside ours
+	if procCfg.Stdout == nil {
+		procCfg.Stdout = os.Stdout
+	}
+	procCfg.Stderr = os.Stderr
+	procCfg.MaxRestarts = cfg.SurrealDBMaxRestarts
+	procCfg.InitialBackoff = time.Second
+	procCfg.MaxBackoff = 30 * time.Second
+	procCfg.StableAfter = time.Minute
+	procCfg.OnExit = func(err error) {
+		if reconnector != nil {
+			reconnector.Pause()
+		}
+		if beforeRestart != nil {
+			beforeRestart()
+		}
+	}
+	procCfg.OnRestart = func(ctx context.Context) error {
+		if reconnector == nil {
+			return nil
+		}
+		return retryConnect(ctx, reconnector.Reconnect, surrealConnectTimeout)
+	}
+	procCfg.OnGiveUp = func() {
+		if reconnector != nil {
+			// Let the paused calls fail with storage unavailable errors
+			reconnector.Resume()
+		}
+	}
+
+	proc, err := supervisor.Start(procCfg)
+	if err != nil {
+		return nil, err
+	}
+	if err := retryConnect(ctx, st.Connect, surrealConnectTimeout); err != nil {
+		proc.Stop(5 * time.Second)
+		return nil, fmt.Errorf("surrealdb still unreachable after starting it: %w", err)
+	}
+	return proc, nil
+}
+
+// retryConnect calls connect with exponential backoff, up to 5 seconds
+// between attempts, until it succeeds or timeout has passed.
+func retryConnect(ctx context.Context, connect func(context.Context) error, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	backoff := time.Second
+	for {
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		err := connect(ctx)
+		if err == nil {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return err
+		}
+		slog.Info("surrealdb not ready yet, retrying...", "wait", backoff, "error", err)
+		if backoff < 5*time.Second {
+			backoff *= 2
+		}
+	}
+}
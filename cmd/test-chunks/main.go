@@ -11,6 +11,7 @@ import (
 	"github.com/madeindigio/remembrances-mcp/internal/config"
 	"github.com/madeindigio/remembrances-mcp/internal/kb"
 	"github.com/madeindigio/remembrances-mcp/internal/storage"
+	"github.com/madeindigio/remembrances-mcp/internal/watchqueue"
 	"github.com/madeindigio/remembrances-mcp/pkg/embedder"
 )
 
@@ -44,7 +45,7 @@ func main() {
 	}
 
 	// Start KB watcher
-	watcher, err := kb.StartWatcher(ctx, cfg.GetKBPath(), st, emb, cfg.GetChunkSize(), cfg.GetChunkOverlap())
+	watcher, err := kb.StartWatcher(ctx, kb.Root{Path: cfg.GetKBPath(), ChunkSize: cfg.GetChunkSize(), ChunkOverlap: cfg.GetChunkOverlap()}, st, emb, "", embedder.ChunkStrategy(cfg.GetChunkStrategy()), watchqueue.DefaultConfig())
 	if err != nil {
 		log.Fatalf("Failed to start watcher: %v", err)
 	}
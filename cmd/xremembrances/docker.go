@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// dockerfile runs the composed binary like docker/Dockerfile.cpu: as a
+// non-root user, with the database under the /data volume. Embedded presets
+// carry their libraries in the binary, so nothing else is copied.
+const dockerfile = `FROM debian:bookworm-slim
+
+ARG VERSION=dev
+ARG COMMIT=unknown
+
+LABEL org.opencontainers.image.source="https://github.com/madeindigio/remembrances-mcp"
+LABEL org.opencontainers.image.description="Remembrances-MCP Server built by xremembrances"
+LABEL org.opencontainers.image.licenses="MIT"
+LABEL org.opencontainers.image.version="${VERSION}"
+LABEL org.opencontainers.image.revision="${COMMIT}"
+
+RUN apt-get update && apt-get install -y --no-install-recommends \
+    ca-certificates \
+    libgomp1 \
+    && rm -rf /var/lib/apt/lists/* \
+    && apt-get clean
+
+RUN groupadd -r remembrances && useradd -r -g remembrances remembrances \
+    && mkdir -p /app /data /knowledge-base \
+    && chown -R remembrances:remembrances /app /data /knowledge-base
+
+COPY --chown=remembrances:remembrances remembrances-mcp /app/remembrances-mcp
+
+USER remembrances
+WORKDIR /app
+
+ENV GOMEM_DB_PATH="surrealkv:///data/remembrances.db"
+ENV GOMEM_KNOWLEDGE_BASE="/knowledge-base"
+
+VOLUME ["/data", "/knowledge-base"]
+
+ENTRYPOINT ["/app/remembrances-mcp"]
+`
+
+// dockerTag returns the image tag of t. With several Linux targets every
+// image gets an -arch suffix.
+func dockerTag(tag string, t target, matrix bool) string {
+	if matrix {
+		return tag + "-" + t.Arch
+	}
+	return tag
+}
+
+// buildImage builds an image for t containing binary, from a context
+// directory created under dir.
+func buildImage(dir, binary, tag string, t target, version, commit string) error {
+	ctxDir, err := os.MkdirTemp(dir, "docker-")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(ctxDir, "Dockerfile"), []byte(dockerfile), 0644); err != nil {
+		return err
+	}
+	if err := copyFile(binary, filepath.Join(ctxDir, "remembrances-mcp")); err != nil {
+		return err
+	}
+	return runCmd(ctxDir, nil, "docker", "build",
+		"--platform", t.String(),
+		"--build-arg", "VERSION="+version,
+		"--build-arg", "COMMIT="+commit,
+		"-t", tag, ".")
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0755)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return fmt.Errorf("failed to copy %s: %w", src, err)
+	}
+	return out.Close()
+}
@@ -39,7 +39,7 @@ func main() {
 }
 
 func usage() {
-	fmt.Fprintln(os.Stderr, "Usage: xremembrances build [--with module[@version]]... [--output path]")
+	fmt.Fprintln(os.Stderr, "Usage: xremembrances build [--with module[@version]]... [--output path] [--os list] [--arch list] [--preset cpu|cuda|cuda-portable|metal] [--version v] [--commit c] [--cc compiler] [--docker tag]")
 }
 
 func buildCmd(args []string) error {
@@ -47,22 +47,56 @@ func buildCmd(args []string) error {
 	fs.SetOutput(os.Stderr)
 
 	var with stringSlice
-	var output string
-	var base string
+	var output, base string
+	var oses, arches, presetName string
+	var version, commit, cc, dockerImage string
 
 	fs.Var(&with, "with", "Module to include (repeatable). Example: github.com/remembrances/tools-reasoning@v1.2.0")
 	fs.StringVar(&output, "output", "./remembrances-mcp", "Output binary path")
 	fs.StringVar(&base, "base", "github.com/madeindigio/remembrances-mcp", "Base module path")
+	fs.StringVar(&oses, "os", "", "Comma separated target operating systems (default: host)")
+	fs.StringVar(&arches, "arch", "", "Comma separated target architectures (default: host)")
+	fs.StringVar(&presetName, "preset", "", "Embed the GGUF embedder libraries: cpu, cuda, cuda-portable (linux/amd64) or metal (darwin/arm64)")
+	fs.StringVar(&version, "version", "", "Version stamped into the binary (default: last git tag of the current directory, or dev)")
+	fs.StringVar(&commit, "commit", "", "Commit stamped into the binary (default: git HEAD of the current directory, or unknown)")
+	fs.StringVar(&cc, "cc", "", "C compiler for targets other than the host; {os} and {arch} are replaced")
+	fs.StringVar(&dockerImage, "docker", "", "Also build a Docker image with this tag from the Linux binaries")
 
 	if err := fs.Parse(args); err != nil {
 		return err
 	}
 
+	targets, err := parseTargets(oses, arches)
+	if err != nil {
+		return err
+	}
+	p, err := lookupPreset(presetName, targets)
+	if err != nil {
+		return err
+	}
+	var linuxTargets []target
+	for _, t := range targets {
+		if t.OS == "linux" {
+			linuxTargets = append(linuxTargets, t)
+		}
+	}
+	if dockerImage != "" && len(linuxTargets) == 0 {
+		return fmt.Errorf("--docker needs a linux target, got %s", targets[0])
+	}
+
 	cwd, err := os.Getwd()
 	if err != nil {
 		return err
 	}
 
+	gitVer, gitCommit := gitVersion(cwd)
+	if version == "" {
+		version = gitVer
+	}
+	if commit == "" {
+		commit = gitCommit
+	}
+
 	outputPath := output
 	if !filepath.IsAbs(outputPath) {
 		outputPath = filepath.Join(cwd, outputPath)
@@ -78,32 +112,59 @@ func buildCmd(args []string) error {
 		return err
 	}
 
-	if err := runCmd(tmpDir, "go", "mod", "init", "xremembrances-build"); err != nil {
+	if err := runCmd(tmpDir, nil, "go", "mod", "init", "xremembrances-build"); err != nil {
 		return err
 	}
 
 	baseImport, baseGet := splitModuleVersion(base)
-	_ = baseImport
-	if err := runCmd(tmpDir, "go", "get", baseGet); err != nil {
+	if err := runCmd(tmpDir, nil, "go", "get", baseGet); err != nil {
 		return err
 	}
 
 	for _, mod := range with {
 		_, modGet := splitModuleVersion(mod)
-		if err := runCmd(tmpDir, "go", "get", modGet); err != nil {
+		if err := runCmd(tmpDir, nil, "go", "get", modGet); err != nil {
 			return err
 		}
 	}
 
-	if err := runCmd(tmpDir, "go", "mod", "tidy"); err != nil {
+	if err := runCmd(tmpDir, nil, "go", "mod", "tidy"); err != nil {
 		return err
 	}
 
-	if err := runCmd(tmpDir, "go", "build", "-o", outputPath); err != nil {
-		return err
+	buildArgs := []string{"build", "-ldflags", ldflags(baseImport, version, commit, p.Variant)}
+	if len(p.Tags) > 0 {
+		buildArgs = append(buildArgs, "-tags", strings.Join(p.Tags, " "))
+	}
+	binaries := map[target]string{}
+	for _, t := range targets {
+		// CGO is required by the tree-sitter bindings, so cross builds need a
+		// C compiler for the target.
+		env := []string{"GOOS=" + t.OS, "GOARCH=" + t.Arch, "CGO_ENABLED=1"}
+		if cc != "" && !t.native() {
+			env = append(env, "CC="+crossCC(cc, t))
+		}
+		binary := targetOutput(outputPath, t, len(targets) > 1)
+		if err := runCmd(tmpDir, env, "go", append(buildArgs, "-o", binary)...); err != nil {
+			return fmt.Errorf("%s: %w", t, err)
+		}
+		binaries[t] = binary
+		fmt.Fprintf(os.Stdout, "Built %s (%s)\n", binary, t)
 	}
 
-	fmt.Fprintf(os.Stdout, "Built %s\n", outputPath)
+	if dockerImage == "" {
+		return nil
+	}
+	if err := runCmd(tmpDir, nil, "docker", "version", "--format", "{{.Server.Version}}"); err != nil {
+		return fmt.Errorf("docker is not available: %w", err)
+	}
+	for _, t := range linuxTargets {
+		tag := dockerTag(dockerImage, t, len(linuxTargets) > 1)
+		if err := buildImage(tmpDir, binaries[t], tag, t, version, commit); err != nil {
+			return fmt.Errorf("docker image for %s: %w", t, err)
+		}
+		fmt.Fprintf(os.Stdout, "Built image %s (%s)\n", tag, t)
+	}
 	return nil
 }
 
@@ -131,9 +192,13 @@ func splitModuleVersion(module string) (string, string) {
 	return module, module
 }
 
-func runCmd(dir string, name string, args ...string) error {
+// runCmd runs name in dir, adding env to the environment.
+func runCmd(dir string, env []string, name string, args ...string) error {
 	cmd := exec.Command(name, args...)
 	cmd.Dir = dir
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 	return cmd.Run()
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// target is one GOOS/GOARCH pair of the build matrix.
+type target struct {
+	OS   string
+	Arch string
+}
+
+func (t target) String() string {
+	return t.OS + "/" + t.Arch
+}
+
+func (t target) native() bool {
+	return t.OS == runtime.GOOS && t.Arch == runtime.GOARCH
+}
+
+// parseTargets builds the matrix of every OS with every architecture from
+// comma separated lists. An empty list means the host's OS or architecture.
+func parseTargets(oses, arches string) ([]target, error) {
+	osList := splitList(oses, runtime.GOOS)
+	archList := splitList(arches, runtime.GOARCH)
+	var targets []target
+	seen := map[target]bool{}
+	for _, goos := range osList {
+		for _, goarch := range archList {
+			t := target{OS: goos, Arch: goarch}
+			if !supportedTargets[t] {
+				return nil, fmt.Errorf("unsupported target %s", t)
+			}
+			if !seen[t] {
+				seen[t] = true
+				targets = append(targets, t)
+			}
+		}
+	}
+	return targets, nil
+}
+
+// supportedTargets are the platforms the server is built and released for.
+var supportedTargets = map[target]bool{
+	{"linux", "amd64"}:   true,
+	{"linux", "arm64"}:   true,
+	{"darwin", "amd64"}:  true,
+	{"darwin", "arm64"}:  true,
+	{"windows", "amd64"}: true,
+}
+
+func splitList(list, def string) []string {
+	var items []string
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	if len(items) == 0 {
+		return []string{def}
+	}
+	return items
+}
+
+// preset selects the GGUF embedder libraries embedded into the binary. The
+// tags match the embedded builds of the Makefile.
+type preset struct {
+	Variant string
+	Tags    []string
+	Targets []target
+}
+
+var presets = map[string]preset{
+	"cpu":           {Variant: "cpu", Tags: []string{"embedded", "embedded_cpu"}, Targets: []target{{"linux", "amd64"}}},
+	"cuda":          {Variant: "cuda", Tags: []string{"embedded", "embedded_cuda"}, Targets: []target{{"linux", "amd64"}}},
+	"cuda-portable": {Variant: "cuda-portable", Tags: []string{"embedded", "embedded_cuda_portable"}, Targets: []target{{"linux", "amd64"}}},
+	"metal":         {Variant: "metal", Tags: []string{"embedded", "embedded_metal"}, Targets: []target{{"darwin", "arm64"}}},
+}
+
+// lookupPreset returns the preset called name, checking that every target
+// has its libraries. The empty name builds without embedded libraries, like
+// "make build".
+func lookupPreset(name string, targets []target) (preset, error) {
+	if name == "" {
+		return preset{}, nil
+	}
+	p, ok := presets[name]
+	if !ok {
+		return preset{}, fmt.Errorf("unknown preset %q: expected cpu, cuda, cuda-portable or metal", name)
+	}
+	for _, t := range targets {
+		supported := false
+		for _, pt := range p.Targets {
+			supported = supported || pt == t
+		}
+		if !supported {
+			return preset{}, fmt.Errorf("preset %s has no libraries for %s", name, t)
+		}
+	}
+	return p, nil
+}
+
+// ldflags stamps the version, commit and variant printed by --version.
+func ldflags(base, version, commit, variant string) string {
+	pkg := base + "/pkg/version"
+	flags := []string{
+		fmt.Sprintf("-X %s.Version=%s", pkg, version),
+		fmt.Sprintf("-X %s.CommitHash=%s", pkg, commit),
+	}
+	if variant != "" {
+		flags = append(flags, fmt.Sprintf("-X %s.Variant=%s", pkg, variant))
+	}
+	return strings.Join(flags, " ")
+}
+
+// targetOutput returns the binary path of t. With several targets every
+// binary gets an -os-arch suffix, and Windows binaries end in .exe.
+func targetOutput(output string, t target, matrix bool) string {
+	path := output
+	if filepath.Ext(path) == ".exe" {
+		path = strings.TrimSuffix(path, ".exe")
+	}
+	if matrix {
+		path += "-" + t.OS + "-" + t.Arch
+	}
+	if t.OS == "windows" {
+		path += ".exe"
+	}
+	return path
+}
+
+// crossCC expands the {os} and {arch} placeholders of the C compiler used
+// for targets other than the host.
+func crossCC(cc string, t target) string {
+	return strings.NewReplacer("{os}", t.OS, "{arch}", t.Arch).Replace(cc)
+}
+
+// gitVersion describes the checkout in dir like the Makefile does: the last
+// tag (with -dirty for uncommitted changes) and the short commit hash.
+func gitVersion(dir string) (version, commit string) {
+	version, commit = "dev", "unknown"
+	if out, err := gitOutput(dir, "describe", "--tags", "--abbrev=0"); err == nil {
+		version = out
+		if exec.Command("git", "-C", dir, "diff", "--quiet").Run() != nil {
+			version += "-dirty"
+		}
+	}
+	if out, err := gitOutput(dir, "rev-parse", "--short", "HEAD"); err == nil {
+		commit = out
+	}
+	return version, commit
+}
+
+func gitOutput(dir string, args ...string) (string, error) {
+	out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).Output()
+	return strings.TrimSpace(string(out)), err
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+func TestParseTargets(t *testing.T) {
+	targets, err := parseTargets("linux, darwin", "amd64,arm64,amd64")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []target{{"linux", "amd64"}, {"linux", "arm64"}, {"darwin", "amd64"}, {"darwin", "arm64"}}
+	if !reflect.DeepEqual(targets, want) {
+		t.Errorf("targets = %v, want %v", targets, want)
+	}
+
+	targets, err = parseTargets("", "")
+	if err != nil {
+		t.Skipf("host %s/%s is not a release target", runtime.GOOS, runtime.GOARCH)
+	}
+	if len(targets) != 1 || !targets[0].native() {
+		t.Errorf("default targets = %v, want the host", targets)
+	}
+
+	if _, err := parseTargets("plan9", "amd64"); err == nil {
+		t.Error("expected an error for an unsupported target")
+	}
+}
+
+func TestLookupPreset(t *testing.T) {
+	p, err := lookupPreset("cuda", []target{{"linux", "amd64"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Variant != "cuda" || !reflect.DeepEqual(p.Tags, []string{"embedded", "embedded_cuda"}) {
+		t.Errorf("preset = %+v", p)
+	}
+	if _, err := lookupPreset("metal", []target{{"linux", "amd64"}}); err == nil {
+		t.Error("expected an error for metal on linux")
+	}
+	if _, err := lookupPreset("rocm", nil); err == nil {
+		t.Error("expected an error for an unknown preset")
+	}
+	if p, err := lookupPreset("", []target{{"windows", "amd64"}}); err != nil || p.Variant != "" {
+		t.Errorf("no preset = %+v, %v", p, err)
+	}
+}
+
+func TestLdflags(t *testing.T) {
+	got := ldflags("example.com/rm", "v1.2.0", "abc123", "metal")
+	want := "-X example.com/rm/pkg/version.Version=v1.2.0 -X example.com/rm/pkg/version.CommitHash=abc123 -X example.com/rm/pkg/version.Variant=metal"
+	if got != want {
+		t.Errorf("ldflags = %q, want %q", got, want)
+	}
+}
+
+func TestTargetOutput(t *testing.T) {
+	tests := []struct {
+		output string
+		target target
+		matrix bool
+		want   string
+	}{
+		{"/out/rm", target{"linux", "amd64"}, false, "/out/rm"},
+		{"/out/rm", target{"linux", "arm64"}, true, "/out/rm-linux-arm64"},
+		{"/out/rm", target{"windows", "amd64"}, true, "/out/rm-windows-amd64.exe"},
+		{"/out/rm.exe", target{"windows", "amd64"}, false, "/out/rm.exe"},
+		{"/out/rm.exe", target{"darwin", "arm64"}, true, "/out/rm-darwin-arm64"},
+	}
+	for _, tt := range tests {
+		if got := targetOutput(tt.output, tt.target, tt.matrix); got != tt.want {
+			t.Errorf("targetOutput(%q, %s, %v) = %q, want %q", tt.output, tt.target, tt.matrix, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,459 @@
+// Package bulkimport seeds memory from existing datasets: it streams facts or
+// texts from a CSV or JSONL file, embeds texts in batches and reports the
+// records that could not be imported instead of stopping at the first one.
+package bulkimport
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Kinds of records an import loads.
+const (
+	KindFacts         = "facts"         // user_id, key and value, saved as key-value facts
+	KindVectors       = "vectors"       // user_id, content and metadata, embedded and saved as vectors
+	KindEntities      = "entities"      // entity_type, name and properties; only from exports
+	KindRelationships = "relationships" // from, to, type and properties; only from exports
+)
+
+// Formats of the imported file.
+const (
+	FormatCSV   = "csv"   // A header row naming the columns, then one record per row
+	FormatJSONL = "jsonl" // One JSON object per line
+)
+
+// DefaultBatchSize is the number of texts embedded per embedder call.
+const DefaultBatchSize = 32
+
+// maxFailures bounds the failures listed in a report; the rest are only counted.
+const maxFailures = 100
+
+// maxLineBytes bounds a JSONL line.
+const maxLineBytes = 16 << 20
+
+// Store saves the imported records.
+type Store interface {
+	SaveFact(ctx context.Context, userID, key string, value interface{}) error
+	IndexVector(ctx context.Context, userID, content string, embedding []float32, metadata map[string]interface{}) error
+}
+
+// GraphStore also saves the entities and relationships of exports.
+type GraphStore interface {
+	Store
+	CreateEntity(ctx context.Context, entityType, name string, properties map[string]interface{}) error
+	CreateRelationship(ctx context.Context, fromEntity, toEntity, relationshipType string, properties map[string]interface{}) error
+}
+
+// Embedder embeds the texts of vector records.
+type Embedder interface {
+	EmbedDocuments(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// Options control an import.
+type Options struct {
+	Kind      string // KindFacts or KindVectors; unused by ImportExport
+	Format    string // FormatCSV or FormatJSONL; unused by ImportExport
+	UserID    string // User of the records without a user_id
+	BatchSize int    // Texts per embedder call; 0 uses DefaultBatchSize
+
+	// Redact scrubs fact values and vector contents before they are stored;
+	// an error fails the record. Nil stores them unchanged.
+	Redact func(content string) (string, error)
+
+	// Progress is called after every batch with the counts so far.
+	Progress func(Progress)
+}
+
+// Progress is the state of a running import.
+type Progress struct {
+	Read     int `json:"read"`
+	Imported int `json:"imported"`
+	Failed   int `json:"failed"`
+}
+
+// Failure is a record that was not imported. Records of files are identified
+// by their line, records of exports by their path in the export.
+type Failure struct {
+	Line  int    `json:"line,omitempty"`
+	Ref   string `json:"ref,omitempty"`
+	Error string `json:"error"`
+}
+
+// Report summarizes an import.
+type Report struct {
+	Kind     string         `json:"kind,omitempty"`
+	Format   string         `json:"format"`
+	Read     int            `json:"read"`
+	Imported int            `json:"imported"`
+	Failed   int            `json:"failed"`
+	Counts   map[string]int `json:"counts"`             // Records imported per kind
+	Failures []Failure      `json:"failures,omitempty"` // The first failures, in file order
+	Duration time.Duration  `json:"duration"`
+}
+
+// record is one parsed row, line or export item.
+type record struct {
+	kind       string
+	line       int
+	ref        string
+	userID     string
+	key        string
+	value      interface{}
+	content    string
+	metadata   map[string]interface{}
+	entityType string
+	name       string
+	from       string
+	to         string
+	relation   string
+	properties map[string]interface{}
+	err        error
+}
+
+// DetectFormat returns the format of a file from its extension, or "" when
+// it is neither CSV nor JSONL.
+func DetectFormat(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return FormatCSV
+	case ".jsonl", ".ndjson":
+		return FormatJSONL
+	}
+	return ""
+}
+
+// Import reads records from r and saves them to store. Records that cannot
+// be parsed, embedded or saved are counted as failures and the import goes
+// on; the error is only set when the file cannot be read or ctx is done, in
+// which case the report covers the records handled until then.
+func Import(ctx context.Context, store Store, emb Embedder, r io.Reader, opts Options) (*Report, error) {
+	if opts.Kind != KindFacts && opts.Kind != KindVectors {
+		return nil, fmt.Errorf("invalid kind %q: use %s or %s", opts.Kind, KindFacts, KindVectors)
+	}
+	if opts.Kind == KindVectors && emb == nil {
+		return nil, fmt.Errorf("an embedder is required to import vectors")
+	}
+
+	var next func() (*record, error)
+	switch opts.Format {
+	case FormatCSV:
+		var err error
+		if next, err = csvRecords(r, opts.Kind); err != nil {
+			return nil, err
+		}
+	case FormatJSONL:
+		next = jsonlRecords(r, opts.Kind)
+	default:
+		return nil, fmt.Errorf("invalid format %q: use %s or %s", opts.Format, FormatCSV, FormatJSONL)
+	}
+
+	im := newImporter(store, emb, opts, &Report{Kind: opts.Kind, Format: opts.Format})
+	return im.run(ctx, next)
+}
+
+type importer struct {
+	store     Store
+	emb       Embedder
+	opts      Options
+	batchSize int
+	report    *Report
+}
+
+func newImporter(store Store, emb Embedder, opts Options, report *Report) *importer {
+	batchSize := opts.BatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+	report.Counts = map[string]int{}
+	return &importer{store: store, emb: emb, opts: opts, batchSize: batchSize, report: report}
+}
+
+// run handles the records returned by next until it returns io.EOF. Vectors
+// are embedded in batches; other records are saved as they are read, so
+// entities exist before the relationships that follow them.
+func (im *importer) run(ctx context.Context, next func() (*record, error)) (*Report, error) {
+	started := time.Now()
+	defer func() { im.report.Duration = time.Since(started) }()
+
+	batch := make([]*record, 0, im.batchSize)
+	saved := 0
+	for {
+		if err := ctx.Err(); err != nil {
+			return im.report, err
+		}
+		rec, err := next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return im.report, err
+		}
+		im.report.Read++
+		if rec.err == nil {
+			rec.err = im.prepare(rec)
+		}
+		if rec.err != nil {
+			im.fail(rec, rec.err)
+			continue
+		}
+		if rec.kind != KindVectors {
+			im.save(ctx, rec, nil)
+			if saved++; saved == im.batchSize {
+				im.progress()
+				saved = 0
+			}
+			continue
+		}
+		batch = append(batch, rec)
+		if len(batch) == im.batchSize {
+			im.flush(ctx, batch)
+			im.progress()
+			batch, saved = batch[:0], 0
+		}
+	}
+	if len(batch) > 0 || saved > 0 {
+		im.flush(ctx, batch)
+		im.progress()
+	}
+	return im.report, ctx.Err()
+}
+
+// prepare checks the required fields of rec and redacts its text.
+func (im *importer) prepare(rec *record) error {
+	switch rec.kind {
+	case KindEntities:
+		if rec.name == "" {
+			return errors.New("name is required")
+		}
+		return nil
+	case KindRelationships:
+		if rec.from == "" || rec.to == "" {
+			return errors.New("both entities of the relationship are required")
+		}
+		return nil
+	}
+
+	if rec.userID == "" {
+		rec.userID = im.opts.UserID
+	}
+	if rec.userID == "" {
+		return errors.New("user_id is required")
+	}
+	if rec.kind == KindFacts {
+		if rec.key == "" {
+			return errors.New("key is required")
+		}
+		if value, ok := rec.value.(string); ok && im.opts.Redact != nil {
+			redacted, err := im.opts.Redact(value)
+			if err != nil {
+				return err
+			}
+			rec.value = redacted
+		}
+		return nil
+	}
+	if strings.TrimSpace(rec.content) == "" {
+		return errors.New("content is required")
+	}
+	if im.opts.Redact != nil {
+		redacted, err := im.opts.Redact(rec.content)
+		if err != nil {
+			return err
+		}
+		rec.content = redacted
+	}
+	return nil
+}
+
+// flush saves a batch of vectors, embedding their contents in one call. When
+// the batch call fails the texts are embedded one by one, so only the ones
+// the embedder rejects fail.
+func (im *importer) flush(ctx context.Context, batch []*record) {
+	if len(batch) == 0 {
+		return
+	}
+	texts := make([]string, len(batch))
+	for i, rec := range batch {
+		texts[i] = rec.content
+	}
+	embeddings, err := im.emb.EmbedDocuments(ctx, texts)
+	if err == nil && len(embeddings) != len(batch) {
+		err = fmt.Errorf("embedder returned %d embeddings for %d texts", len(embeddings), len(batch))
+	}
+	if err == nil {
+		for i, rec := range batch {
+			im.save(ctx, rec, embeddings[i])
+		}
+		return
+	}
+
+	for _, rec := range batch {
+		embeddings, err := im.emb.EmbedDocuments(ctx, []string{rec.content})
+		if err == nil && len(embeddings) != 1 {
+			err = fmt.Errorf("embedder returned %d embeddings for 1 text", len(embeddings))
+		}
+		if err != nil {
+			im.fail(rec, fmt.Errorf("failed to embed content: %w", err))
+			continue
+		}
+		im.save(ctx, rec, embeddings[0])
+	}
+}
+
+func (im *importer) save(ctx context.Context, rec *record, embedding []float32) {
+	var err error
+	switch rec.kind {
+	case KindFacts:
+		err = im.store.SaveFact(ctx, rec.userID, rec.key, rec.value)
+	case KindVectors:
+		err = im.store.IndexVector(ctx, rec.userID, rec.content, embedding, rec.metadata)
+	case KindEntities:
+		err = im.store.(GraphStore).CreateEntity(ctx, rec.entityType, rec.name, rec.properties)
+	case KindRelationships:
+		err = im.store.(GraphStore).CreateRelationship(ctx, rec.from, rec.to, rec.relation, rec.properties)
+	}
+	if err != nil {
+		im.fail(rec, err)
+		return
+	}
+	im.report.Imported++
+	im.report.Counts[rec.kind]++
+}
+
+func (im *importer) fail(rec *record, err error) {
+	im.report.Failed++
+	if len(im.report.Failures) < maxFailures {
+		im.report.Failures = append(im.report.Failures, Failure{Line: rec.line, Ref: rec.ref, Error: err.Error()})
+	}
+}
+
+func (im *importer) progress() {
+	if im.opts.Progress != nil {
+		im.opts.Progress(Progress{Read: im.report.Read, Imported: im.report.Imported, Failed: im.report.Failed})
+	}
+}
+
+// jsonlRecords reads one JSON object per line, skipping blank lines. Vector
+// texts are read from "content", or "text" when it is missing.
+func jsonlRecords(r io.Reader, kind string) func() (*record, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineBytes)
+	line := 0
+	return func() (*record, error) {
+		for scanner.Scan() {
+			line++
+			text := strings.TrimSpace(scanner.Text())
+			if text == "" {
+				continue
+			}
+			rec := &record{kind: kind, line: line}
+			var row struct {
+				UserID   string                 `json:"user_id"`
+				Key      string                 `json:"key"`
+				Value    interface{}            `json:"value"`
+				Content  string                 `json:"content"`
+				Text     string                 `json:"text"`
+				Metadata map[string]interface{} `json:"metadata"`
+			}
+			if err := json.Unmarshal([]byte(text), &row); err != nil {
+				rec.err = fmt.Errorf("invalid JSON: %w", err)
+				return rec, nil
+			}
+			rec.userID = row.UserID
+			if kind == KindFacts {
+				rec.key, rec.value = row.Key, row.Value
+			} else {
+				rec.content, rec.metadata = row.Content, row.Metadata
+				if rec.content == "" {
+					rec.content = row.Text
+				}
+			}
+			return rec, nil
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line+1, err)
+		}
+		return nil, io.EOF
+	}
+}
+
+// csvRecords reads rows named by a header row. Facts need key and value
+// columns; vectors a content (or text) column, and their other columns
+// become metadata. A user_id column is optional.
+func csvRecords(r io.Reader, kind string) (func() (*record, error), error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err == io.EOF {
+		return func() (*record, error) { return nil, io.EOF }, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+
+	textColumn := "content"
+	if kind == KindFacts {
+		for _, name := range []string{"key", "value"} {
+			if _, ok := columns[name]; !ok {
+				return nil, fmt.Errorf("CSV header has no %s column", name)
+			}
+		}
+	} else if _, ok := columns["content"]; !ok {
+		if _, ok := columns["text"]; !ok {
+			return nil, fmt.Errorf("CSV header has no content or text column")
+		}
+		textColumn = "text"
+	}
+
+	return func() (*record, error) {
+		row, err := reader.Read()
+		if err == io.EOF {
+			return nil, io.EOF
+		}
+		line, _ := reader.FieldPos(0)
+		rec := &record{kind: kind, line: line}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			rec.err = err
+			return rec, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		field := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(row) {
+				return row[i]
+			}
+			return ""
+		}
+		rec.userID = field("user_id")
+		if kind == KindFacts {
+			rec.key, rec.value = field("key"), field("value")
+			return rec, nil
+		}
+		rec.content = field(textColumn)
+		rec.metadata = map[string]interface{}{}
+		for i, name := range header {
+			name = strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))
+			lower := strings.ToLower(name)
+			if lower == "user_id" || lower == textColumn || i >= len(row) || row[i] == "" {
+				continue
+			}
+			rec.metadata[name] = row[i]
+		}
+		return rec, nil
+	}, nil
+}
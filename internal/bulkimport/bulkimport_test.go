@@ -0,0 +1,136 @@
+package bulkimport
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type fakeStore struct {
+	facts   map[string]interface{}
+	vectors []string
+	meta    []map[string]interface{}
+}
+
+func (s *fakeStore) SaveFact(ctx context.Context, userID, key string, value interface{}) error {
+	if s.facts == nil {
+		s.facts = map[string]interface{}{}
+	}
+	s.facts[userID+"/"+key] = value
+	return nil
+}
+
+func (s *fakeStore) IndexVector(ctx context.Context, userID, content string, embedding []float32, metadata map[string]interface{}) error {
+	if len(embedding) != 1 {
+		return errors.New("missing embedding")
+	}
+	s.vectors = append(s.vectors, userID+"/"+content)
+	s.meta = append(s.meta, metadata)
+	return nil
+}
+
+// fakeEmbedder fails every call containing "bad".
+type fakeEmbedder struct{ calls int }
+
+func (e *fakeEmbedder) EmbedDocuments(ctx context.Context, texts []string) ([][]float32, error) {
+	e.calls++
+	out := make([][]float32, len(texts))
+	for i, text := range texts {
+		if strings.Contains(text, "bad") {
+			return nil, errors.New("rejected")
+		}
+		out[i] = []float32{float32(len(text))}
+	}
+	return out, nil
+}
+
+func TestImportFactsCSV(t *testing.T) {
+	input := "key,value,user_id\ncolor,blue,\nsize,large,bob\n,orphan,\n"
+	store := &fakeStore{}
+	report, err := Import(context.Background(), store, nil, strings.NewReader(input), Options{Kind: KindFacts, Format: FormatCSV, UserID: "alice"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{"alice/color": "blue", "bob/size": "large"}
+	if !reflect.DeepEqual(store.facts, want) {
+		t.Errorf("facts = %v, want %v", store.facts, want)
+	}
+	if report.Read != 3 || report.Imported != 2 || report.Failed != 1 {
+		t.Errorf("report = %+v", report)
+	}
+	if len(report.Failures) != 1 || report.Failures[0].Line != 4 || report.Failures[0].Error != "key is required" {
+		t.Errorf("failures = %+v", report.Failures)
+	}
+}
+
+func TestImportVectorsJSONL(t *testing.T) {
+	input := strings.Join([]string{
+		`{"user_id": "u", "content": "first", "metadata": {"source": "wiki"}}`,
+		``,
+		`{"user_id": "u", "text": "bad text"}`,
+		`not json`,
+		`{"user_id": "u", "content": "second"}`,
+		`{"content": "no user"}`,
+	}, "\n")
+	store := &fakeStore{}
+	emb := &fakeEmbedder{}
+	var progress []Progress
+	report, err := Import(context.Background(), store, emb, strings.NewReader(input), Options{
+		Kind:      KindVectors,
+		Format:    FormatJSONL,
+		BatchSize: 2,
+		Progress:  func(p Progress) { progress = append(progress, p) },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"u/first", "u/second"}; !reflect.DeepEqual(store.vectors, want) {
+		t.Errorf("vectors = %v, want %v", store.vectors, want)
+	}
+	if store.meta[0]["source"] != "wiki" {
+		t.Errorf("metadata = %v", store.meta[0])
+	}
+	if report.Read != 5 || report.Imported != 2 || report.Failed != 3 {
+		t.Errorf("report = %+v", report)
+	}
+	lines := []int{}
+	for _, f := range report.Failures {
+		lines = append(lines, f.Line)
+	}
+	if !reflect.DeepEqual(lines, []int{3, 4, 6}) {
+		t.Errorf("failure lines = %v, want [3 4 6]", lines)
+	}
+	// The first batch fails and is retried text by text; the second holds "second"
+	if emb.calls != 4 {
+		t.Errorf("embedder calls = %d, want 4", emb.calls)
+	}
+	want := []Progress{{Read: 2, Imported: 1, Failed: 1}, {Read: 5, Imported: 2, Failed: 3}}
+	if !reflect.DeepEqual(progress, want) {
+		t.Errorf("progress = %+v", progress)
+	}
+}
+
+func TestImportVectorsCSVMetadata(t *testing.T) {
+	input := "text,topic,user_id\nhello,greeting,u\n"
+	store := &fakeStore{}
+	if _, err := Import(context.Background(), store, &fakeEmbedder{}, strings.NewReader(input), Options{Kind: KindVectors, Format: FormatCSV}); err != nil {
+		t.Fatal(err)
+	}
+	if want := []map[string]interface{}{{"topic": "greeting"}}; !reflect.DeepEqual(store.meta, want) {
+		t.Errorf("metadata = %v, want %v", store.meta, want)
+	}
+}
+
+func TestImportRejectsInvalidOptions(t *testing.T) {
+	if _, err := Import(context.Background(), &fakeStore{}, nil, strings.NewReader(""), Options{Kind: "events", Format: FormatCSV}); err == nil {
+		t.Error("expected an error for an unknown kind")
+	}
+	if _, err := Import(context.Background(), &fakeStore{}, nil, strings.NewReader("key\n"), Options{Kind: KindFacts, Format: FormatCSV}); err == nil {
+		t.Error("expected an error for a header without value")
+	}
+	if DetectFormat("data.NDJSON") != FormatJSONL || DetectFormat("data.txt") != "" {
+		t.Error("unexpected detected format")
+	}
+}
@@ -0,0 +1,371 @@
+package bulkimport
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Memory systems whose exports ImportExport converts.
+const (
+	SourceMem0  = "mem0"  // get_all() output: memories, and relations with graph memory
+	SourceZep   = "zep"   // Graph nodes and edges, and session messages
+	SourceLetta = "letta" // Archival memory passages and core memory blocks
+)
+
+// Sources lists the supported memory systems.
+var Sources = []string{SourceMem0, SourceZep, SourceLetta}
+
+// relationTypeRe matches characters not allowed in relationship types, which
+// are used as table names.
+var relationTypeRe = regexp.MustCompile(`[^a-z0-9_]+`)
+
+// ImportExport converts the JSON export of another memory system into facts,
+// vectors, entities and relationships and saves them like Import. Every
+// record keeps the ID it had in the export in its metadata or properties,
+// along with source set to the memory system. The export is read whole, so
+// Progress starts once it is parsed.
+func ImportExport(ctx context.Context, store GraphStore, emb Embedder, r io.Reader, source string, opts Options) (*Report, error) {
+	var convert func(interface{}, string) ([]*record, error)
+	switch source {
+	case SourceMem0:
+		convert = mem0Records
+	case SourceZep:
+		convert = zepRecords
+	case SourceLetta:
+		convert = lettaRecords
+	default:
+		return nil, fmt.Errorf("invalid source %q: use %s", source, strings.Join(Sources, ", "))
+	}
+	if emb == nil {
+		return nil, fmt.Errorf("an embedder is required to import vectors")
+	}
+
+	var export interface{}
+	if err := json.NewDecoder(r).Decode(&export); err != nil {
+		return nil, fmt.Errorf("invalid %s export: %w", source, err)
+	}
+	records, err := convert(export, opts.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s export: %w", source, err)
+	}
+
+	i := 0
+	next := func() (*record, error) {
+		if i == len(records) {
+			return nil, io.EOF
+		}
+		i++
+		return records[i-1], nil
+	}
+	im := newImporter(store, emb, opts, &Report{Format: source})
+	return im.run(ctx, next)
+}
+
+// mem0Records converts the memories of a mem0 export, either a list or an
+// object with "results" (or "memories") and the "relations" of graph memory.
+func mem0Records(export interface{}, defaultUser string) ([]*record, error) {
+	var memories, relations []interface{}
+	switch v := export.(type) {
+	case []interface{}:
+		memories = v
+	case map[string]interface{}:
+		memories = list(v, "results", "memories")
+		relations = list(v, "relations")
+	default:
+		return nil, fmt.Errorf("expected a list of memories or an object with results")
+	}
+
+	var records []*record
+	for i, item := range memories {
+		m, _ := item.(map[string]interface{})
+		rec := &record{kind: KindVectors, ref: fmt.Sprintf("memories[%d]", i)}
+		if m == nil {
+			rec.err = fmt.Errorf("expected an object")
+			records = append(records, rec)
+			continue
+		}
+		rec.content = firstString(m, "memory", "text", "content")
+		rec.userID = firstString(m, "user_id", "agent_id", "run_id")
+		rec.metadata = copyMap(m, "metadata")
+		rec.metadata["source"] = SourceMem0
+		setIf(rec.metadata, "mem0_id", m["id"])
+		for _, field := range []string{"agent_id", "run_id", "categories", "created_at", "updated_at"} {
+			setIf(rec.metadata, field, m[field])
+		}
+		records = append(records, rec)
+	}
+
+	// Graph memory relations name their entities; each becomes one entity
+	entityTypes := map[string]string{}
+	var rels []*record
+	for i, item := range relations {
+		m, _ := item.(map[string]interface{})
+		rec := &record{kind: KindRelationships, ref: fmt.Sprintf("relations[%d]", i)}
+		if m == nil {
+			rec.err = fmt.Errorf("expected an object")
+			rels = append(rels, rec)
+			continue
+		}
+		rec.from = firstString(m, "source")
+		rec.to = firstString(m, "target", "destination")
+		rec.relation = relationType(firstString(m, "relationship", "relation"))
+		rec.properties = map[string]interface{}{"source": SourceMem0}
+		for name, typ := range map[string]string{rec.from: firstString(m, "source_type"), rec.to: firstString(m, "target_type", "destination_type")} {
+			if name != "" && (entityTypes[name] == "" || entityTypes[name] == "entity") {
+				entityTypes[name] = orDefault(typ, "entity")
+			}
+		}
+		rels = append(rels, rec)
+	}
+	records = append(records, entityRecords(entityTypes, SourceMem0)...)
+	return append(records, rels...), nil
+}
+
+// zepRecords converts a Zep export: an object with the "nodes" and "edges" of
+// a user's graph and the "messages" of its sessions. Edges become
+// relationships between the entities of their nodes, and their facts vectors.
+func zepRecords(export interface{}, defaultUser string) ([]*record, error) {
+	obj, ok := export.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("expected an object with nodes, edges or messages")
+	}
+	userID := orDefault(firstString(obj, "user_id"), defaultUser)
+
+	var records []*record
+	names := map[string]string{}
+	for i, item := range list(obj, "nodes") {
+		m, _ := item.(map[string]interface{})
+		rec := &record{kind: KindEntities, ref: fmt.Sprintf("nodes[%d]", i)}
+		if m == nil {
+			rec.err = fmt.Errorf("expected an object")
+			records = append(records, rec)
+			continue
+		}
+		rec.name = firstString(m, "name")
+		rec.entityType = "entity"
+		if labels, ok := m["labels"].([]interface{}); ok {
+			for _, label := range labels {
+				if s, ok := label.(string); ok && s != "" && s != "Entity" {
+					rec.entityType = strings.ToLower(s)
+					break
+				}
+			}
+		}
+		rec.properties = copyMap(m, "attributes")
+		rec.properties["source"] = SourceZep
+		setIf(rec.properties, "zep_uuid", m["uuid"])
+		setIf(rec.properties, "summary", m["summary"])
+		setIf(rec.properties, "created_at", m["created_at"])
+		if uuid := firstString(m, "uuid"); uuid != "" {
+			names[uuid] = rec.name
+		}
+		records = append(records, rec)
+	}
+
+	for i, item := range list(obj, "edges") {
+		m, _ := item.(map[string]interface{})
+		ref := fmt.Sprintf("edges[%d]", i)
+		rel := &record{kind: KindRelationships, ref: ref}
+		if m == nil {
+			rel.err = fmt.Errorf("expected an object")
+			records = append(records, rel)
+			continue
+		}
+		rel.from = names[firstString(m, "source_node_uuid")]
+		rel.to = names[firstString(m, "target_node_uuid")]
+		if rel.from == "" || rel.to == "" {
+			rel.err = fmt.Errorf("source or target node is not in the export")
+		}
+		rel.relation = relationType(firstString(m, "name"))
+		rel.properties = map[string]interface{}{"source": SourceZep}
+		for _, field := range []string{"fact", "valid_at", "invalid_at", "created_at"} {
+			setIf(rel.properties, field, m[field])
+		}
+		setIf(rel.properties, "zep_uuid", m["uuid"])
+		records = append(records, rel)
+
+		if fact := firstString(m, "fact"); fact != "" {
+			metadata := map[string]interface{}{"source": SourceZep, "relationship": rel.relation}
+			setIf(metadata, "zep_uuid", m["uuid"])
+			for _, field := range []string{"valid_at", "invalid_at", "created_at"} {
+				setIf(metadata, field, m[field])
+			}
+			records = append(records, &record{kind: KindVectors, ref: ref + ".fact", userID: userID, content: fact, metadata: metadata})
+		}
+	}
+
+	for i, item := range list(obj, "messages") {
+		m, _ := item.(map[string]interface{})
+		rec := &record{kind: KindVectors, ref: fmt.Sprintf("messages[%d]", i), userID: userID}
+		if m == nil {
+			rec.err = fmt.Errorf("expected an object")
+			records = append(records, rec)
+			continue
+		}
+		rec.content = firstString(m, "content")
+		rec.metadata = copyMap(m, "metadata")
+		rec.metadata["source"] = SourceZep
+		setIf(rec.metadata, "zep_uuid", m["uuid"])
+		for _, field := range []string{"role", "role_type", "session_id", "created_at"} {
+			setIf(rec.metadata, field, m[field])
+		}
+		records = append(records, rec)
+	}
+	return records, nil
+}
+
+// lettaRecords converts Letta (MemGPT) memory: archival memory passages,
+// either a list or under "passages" or "archival_memory", and core memory
+// blocks under "blocks", "core_memory" or "memory_blocks", which become facts
+// keyed by their label. Agent files nest them under "agents".
+func lettaRecords(export interface{}, defaultUser string) ([]*record, error) {
+	switch v := export.(type) {
+	case []interface{}:
+		return lettaAgentRecords(map[string]interface{}{"passages": v}, "", defaultUser), nil
+	case map[string]interface{}:
+		agents := list(v, "agents")
+		if len(agents) == 0 {
+			return lettaAgentRecords(v, "", defaultUser), nil
+		}
+		var records []*record
+		for i, agent := range agents {
+			m, _ := agent.(map[string]interface{})
+			if m == nil {
+				records = append(records, &record{kind: KindFacts, ref: fmt.Sprintf("agents[%d]", i), err: fmt.Errorf("expected an object")})
+				continue
+			}
+			records = append(records, lettaAgentRecords(m, fmt.Sprintf("agents[%d].", i), defaultUser)...)
+		}
+		// Blocks and passages shared by the agents of an agent file
+		shared := map[string]interface{}{"blocks": v["blocks"], "passages": v["passages"]}
+		return append(records, lettaAgentRecords(shared, "", defaultUser)...), nil
+	}
+	return nil, fmt.Errorf("expected a list of passages or an object with passages, blocks or agents")
+}
+
+func lettaAgentRecords(agent map[string]interface{}, prefix, defaultUser string) []*record {
+	userID := orDefault(defaultUser, firstString(agent, "name", "id"))
+
+	var records []*record
+	blocks := list(agent, "blocks", "core_memory", "memory_blocks")
+	if memory, ok := agent["memory"].(map[string]interface{}); ok && len(blocks) == 0 {
+		blocks = list(memory, "blocks")
+	}
+	for i, item := range blocks {
+		m, _ := item.(map[string]interface{})
+		rec := &record{kind: KindFacts, ref: fmt.Sprintf("%sblocks[%d]", prefix, i), userID: userID}
+		if m == nil {
+			rec.err = fmt.Errorf("expected an object")
+		} else {
+			rec.key = firstString(m, "label")
+			rec.value = m["value"]
+		}
+		records = append(records, rec)
+	}
+
+	for i, item := range list(agent, "passages", "archival_memory") {
+		m, _ := item.(map[string]interface{})
+		rec := &record{kind: KindVectors, ref: fmt.Sprintf("%spassages[%d]", prefix, i)}
+		if m == nil {
+			rec.err = fmt.Errorf("expected an object")
+			records = append(records, rec)
+			continue
+		}
+		rec.content = firstString(m, "text", "content")
+		rec.userID = orDefault(userID, firstString(m, "agent_id"))
+		rec.metadata = copyMap(m, "metadata", "metadata_")
+		rec.metadata["source"] = SourceLetta
+		setIf(rec.metadata, "letta_id", m["id"])
+		for _, field := range []string{"agent_id", "created_at"} {
+			setIf(rec.metadata, field, m[field])
+		}
+		records = append(records, rec)
+	}
+	return records
+}
+
+// entityRecords creates one entity per name, in name order.
+func entityRecords(types map[string]string, source string) []*record {
+	names := make([]string, 0, len(types))
+	for name := range types {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	records := make([]*record, 0, len(names))
+	for _, name := range names {
+		records = append(records, &record{
+			kind:       KindEntities,
+			ref:        "entities." + name,
+			name:       name,
+			entityType: types[name],
+			properties: map[string]interface{}{"source": source},
+		})
+	}
+	return records
+}
+
+// relationType turns a relationship name such as "WORKS AT" into a type
+// usable as a table name, such as "works_at".
+func relationType(name string) string {
+	t := strings.Trim(relationTypeRe.ReplaceAllString(strings.ToLower(name), "_"), "_")
+	if t == "" {
+		return "related_to"
+	}
+	if t[0] >= '0' && t[0] <= '9' {
+		t = "_" + t
+	}
+	return t
+}
+
+// list returns the first of the keys of m holding a list.
+func list(m map[string]interface{}, keys ...string) []interface{} {
+	for _, key := range keys {
+		if l, ok := m[key].([]interface{}); ok {
+			return l
+		}
+	}
+	return nil
+}
+
+// firstString returns the first of the keys of m holding a non-empty string.
+func firstString(m map[string]interface{}, keys ...string) string {
+	for _, key := range keys {
+		if s, ok := m[key].(string); ok && s != "" {
+			return s
+		}
+	}
+	return ""
+}
+
+// copyMap returns a copy of the first of the keys of m holding an object, or
+// an empty map.
+func copyMap(m map[string]interface{}, keys ...string) map[string]interface{} {
+	out := map[string]interface{}{}
+	for _, key := range keys {
+		if inner, ok := m[key].(map[string]interface{}); ok {
+			for k, v := range inner {
+				out[k] = v
+			}
+			break
+		}
+	}
+	return out
+}
+
+func setIf(m map[string]interface{}, key string, value interface{}) {
+	if value != nil && value != "" {
+		m[key] = value
+	}
+}
+
+func orDefault(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
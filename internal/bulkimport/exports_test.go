@@ -0,0 +1,124 @@
+package bulkimport
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type fakeGraphStore struct {
+	fakeStore
+	entities      []string
+	relationships []string
+}
+
+func (s *fakeGraphStore) CreateEntity(ctx context.Context, entityType, name string, properties map[string]interface{}) error {
+	s.entities = append(s.entities, entityType+":"+name)
+	return nil
+}
+
+func (s *fakeGraphStore) CreateRelationship(ctx context.Context, fromEntity, toEntity, relationshipType string, properties map[string]interface{}) error {
+	s.relationships = append(s.relationships, fromEntity+"-"+relationshipType+"->"+toEntity)
+	return nil
+}
+
+func TestImportMem0Export(t *testing.T) {
+	export := `{
+		"results": [
+			{"id": "m1", "memory": "Likes pizza", "user_id": "alice", "categories": ["food"], "metadata": {"mood": "happy"}},
+			{"id": "m2", "memory": "", "user_id": "alice"}
+		],
+		"relations": [
+			{"source": "alice", "source_type": "person", "relationship": "LIKES", "target": "pizza"}
+		]
+	}`
+	store := &fakeGraphStore{}
+	report, err := ImportExport(context.Background(), store, &fakeEmbedder{}, strings.NewReader(export), SourceMem0, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"alice/Likes pizza"}; !reflect.DeepEqual(store.vectors, want) {
+		t.Errorf("vectors = %v, want %v", store.vectors, want)
+	}
+	meta := store.meta[0]
+	if meta["source"] != "mem0" || meta["mem0_id"] != "m1" || meta["mood"] != "happy" {
+		t.Errorf("metadata = %v", meta)
+	}
+	if want := []string{"person:alice", "entity:pizza"}; !reflect.DeepEqual(store.entities, want) {
+		t.Errorf("entities = %v, want %v", store.entities, want)
+	}
+	if want := []string{"alice-likes->pizza"}; !reflect.DeepEqual(store.relationships, want) {
+		t.Errorf("relationships = %v, want %v", store.relationships, want)
+	}
+	if report.Failed != 1 || report.Failures[0].Ref != "memories[1]" {
+		t.Errorf("report = %+v", report)
+	}
+	if want := map[string]int{"vectors": 1, "entities": 2, "relationships": 1}; !reflect.DeepEqual(report.Counts, want) {
+		t.Errorf("counts = %v, want %v", report.Counts, want)
+	}
+}
+
+func TestImportZepExport(t *testing.T) {
+	export := `{
+		"user_id": "bob",
+		"nodes": [
+			{"uuid": "n1", "name": "Bob", "labels": ["Entity", "Person"], "summary": "A user"},
+			{"uuid": "n2", "name": "Acme", "labels": ["Entity"]}
+		],
+		"edges": [
+			{"uuid": "e1", "name": "WORKS_AT", "fact": "Bob works at Acme", "source_node_uuid": "n1", "target_node_uuid": "n2"},
+			{"uuid": "e2", "name": "KNOWS", "source_node_uuid": "n1", "target_node_uuid": "n9"}
+		],
+		"messages": [{"role": "user", "content": "Hi"}]
+	}`
+	store := &fakeGraphStore{}
+	report, err := ImportExport(context.Background(), store, &fakeEmbedder{}, strings.NewReader(export), SourceZep, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"person:Bob", "entity:Acme"}; !reflect.DeepEqual(store.entities, want) {
+		t.Errorf("entities = %v, want %v", store.entities, want)
+	}
+	if want := []string{"Bob-works_at->Acme"}; !reflect.DeepEqual(store.relationships, want) {
+		t.Errorf("relationships = %v, want %v", store.relationships, want)
+	}
+	if want := []string{"bob/Bob works at Acme", "bob/Hi"}; !reflect.DeepEqual(store.vectors, want) {
+		t.Errorf("vectors = %v, want %v", store.vectors, want)
+	}
+	if report.Failed != 1 || report.Failures[0].Ref != "edges[1]" {
+		t.Errorf("report = %+v", report)
+	}
+}
+
+func TestImportLettaExport(t *testing.T) {
+	export := `{
+		"agents": [{
+			"name": "helper",
+			"memory": {"blocks": [{"label": "human", "value": "Name: Carol"}]},
+			"passages": [{"id": "p1", "text": "Carol prefers tea"}]
+		}]
+	}`
+	store := &fakeGraphStore{}
+	report, err := ImportExport(context.Background(), store, &fakeEmbedder{}, strings.NewReader(export), SourceLetta, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]interface{}{"helper/human": "Name: Carol"}; !reflect.DeepEqual(store.facts, want) {
+		t.Errorf("facts = %v, want %v", store.facts, want)
+	}
+	if want := []string{"helper/Carol prefers tea"}; !reflect.DeepEqual(store.vectors, want) {
+		t.Errorf("vectors = %v, want %v", store.vectors, want)
+	}
+	if store.meta[0]["letta_id"] != "p1" || report.Failed != 0 {
+		t.Errorf("metadata = %v, report = %+v", store.meta[0], report)
+	}
+}
+
+func TestRelationType(t *testing.T) {
+	for in, want := range map[string]string{"WORKS AT": "works_at", "is-a": "is_a", "": "related_to", "2nd": "_2nd"} {
+		if got := relationType(in); got != want {
+			t.Errorf("relationType(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
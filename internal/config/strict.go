@@ -0,0 +1,219 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/agnivade/levenshtein"
+	"github.com/spf13/pflag"
+)
+
+// Sources of a configuration value, from highest to lowest precedence.
+const (
+	SourceFlag    = "flag"
+	SourceEnv     = "env"
+	SourceFile    = "file"
+	SourceDefault = "default"
+)
+
+// secretKeys are printed masked by Effective.
+var secretKeys = map[string]bool{"openai-key": true, "llm-api-key": true, "surrealdb-pass": true}
+
+// configKeys returns the top-level keys of config files: the mapstructure
+// tags of Config and the flags that are not stored in it (config, version).
+func configKeys() map[string]bool {
+	keys := map[string]bool{}
+	t := reflect.TypeOf(Config{})
+	for i := 0; i < t.NumField(); i++ {
+		if tag := t.Field(i).Tag.Get("mapstructure"); tag != "" {
+			keys[tag] = true
+		}
+	}
+	pflag.CommandLine.VisitAll(func(f *pflag.Flag) { keys[f.Name] = true })
+	return keys
+}
+
+// unknownKeys returns an error naming the keys of a config file that are
+// not configuration keys, with the closest known key as a suggestion.
+// Nested keys are checked by their top-level segment only.
+func unknownKeys(path string, fileKeys []string, known map[string]bool) error {
+	var problems []string
+	seen := map[string]bool{}
+	for _, key := range fileKeys {
+		top, _, _ := strings.Cut(key, ".")
+		if known[top] || seen[top] {
+			continue
+		}
+		seen[top] = true
+		msg := fmt.Sprintf("%q", top)
+		if suggestion := closestKey(top, known); suggestion != "" {
+			msg += fmt.Sprintf(" (did you mean %q?)", suggestion)
+		}
+		problems = append(problems, msg)
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+	sort.Strings(problems)
+	return fmt.Errorf("unknown configuration keys in %s: %s", path, strings.Join(problems, ", "))
+}
+
+// closestKey returns the known key within a third of key's length in edits,
+// or "" when none is that close.
+func closestKey(key string, known map[string]bool) string {
+	best, bestDist := "", len(key)/3+1
+	for k := range known {
+		if d := levenshtein.ComputeDistance(key, k); d < bestDist || (d == bestDist && best != "" && k < best) {
+			best, bestDist = k, d
+		}
+	}
+	return best
+}
+
+// configSources records where the value of every configuration key came
+// from, following viper's precedence: flags, then GOMEM_ environment
+// variables, then the config file, then flag defaults.
+func configSources(known map[string]bool, fileKeys []string) map[string]string {
+	inFile := map[string]bool{}
+	for _, key := range fileKeys {
+		top, _, _ := strings.Cut(key, ".")
+		inFile[top] = true
+	}
+	sources := make(map[string]string, len(known))
+	for key := range known {
+		switch {
+		case pflag.CommandLine.Changed(key):
+			sources[key] = SourceFlag
+		case envSet(key):
+			sources[key] = SourceEnv
+		case inFile[key]:
+			sources[key] = SourceFile
+		default:
+			sources[key] = SourceDefault
+		}
+	}
+	return sources
+}
+
+// envSet reports whether the GOMEM_ environment variable of key is set.
+func envSet(key string) bool {
+	name := "GOMEM_" + strings.ToUpper(strings.ReplaceAll(key, "-", "_"))
+	if _, ok := os.LookupEnv(name); ok {
+		return true
+	}
+	if key == "memory" {
+		_, ok := os.LookupEnv("GOMEM_EPHEMERAL")
+		return ok
+	}
+	return false
+}
+
+// explicit reports whether key was set by a flag, environment variable or
+// config file rather than left at its default. Configurations that were not
+// loaded by Load have no sources, and every key counts as a default.
+func (c *Config) explicit(key string) bool {
+	source, ok := c.sources[key]
+	return ok && source != SourceDefault
+}
+
+// validateExclusive rejects explicitly set options that cancel each other out.
+func (c *Config) validateExclusive() error {
+	if c.explicit("db-path") && c.explicit("surrealdb-url") && c.SurrealDBURL != "" {
+		return fmt.Errorf("db-path (%s) and surrealdb-url (%s) are mutually exclusive: set db-path for the embedded database or surrealdb-url for a remote server", c.sources["db-path"], c.sources["surrealdb-url"])
+	}
+	return nil
+}
+
+// validateModelFiles checks that the configured GGUF model files exist.
+func (c *Config) validateModelFiles() error {
+	paths := []struct{ key, path string }{
+		{"gguf-model-path", c.GGUFModelPath},
+		{"code-gguf-model-path", c.CodeGGUFModelPath},
+	}
+	for i, e := range c.Embedders {
+		paths = append(paths, struct{ key, path string }{fmt.Sprintf("embedders[%d].gguf-model-path", i), e.GGUFModelPath})
+	}
+	for _, p := range paths {
+		if p.path == "" {
+			continue
+		}
+		info, err := os.Stat(p.path)
+		switch {
+		case err != nil:
+			return fmt.Errorf("%s %q: model file not found; download the GGUF model or fix the path", p.key, p.path)
+		case info.IsDir():
+			return fmt.Errorf("%s %q is a directory, expected a .gguf model file", p.key, p.path)
+		}
+	}
+	return nil
+}
+
+// Setting is one resolved configuration value and where it came from.
+type Setting struct {
+	Key    string `json:"key"`
+	Value  any    `json:"value"`
+	Source string `json:"source"`
+}
+
+// Effective returns every configuration value after flags, environment
+// variables, the config file and defaults were merged, sorted by key.
+// Passwords and API keys are masked.
+func (c *Config) Effective() []Setting {
+	v := reflect.ValueOf(*c)
+	t := v.Type()
+	settings := make([]Setting, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		key := t.Field(i).Tag.Get("mapstructure")
+		if key == "" {
+			continue
+		}
+		source := c.sources[key]
+		if source == "" {
+			source = SourceDefault
+		}
+		settings = append(settings, Setting{Key: key, Value: maskSetting(key, v.Field(i).Interface()), Source: source})
+	}
+	sort.Slice(settings, func(i, j int) bool { return settings[i].Key < settings[j].Key })
+	return settings
+}
+
+// maskSetting hides the secrets in the value of key.
+func maskSetting(key string, value any) any {
+	switch {
+	case secretKeys[key]:
+		if value == "" {
+			return value
+		}
+		return "********"
+	case key == "postgres-url":
+		if u, err := url.Parse(value.(string)); err == nil && u.User != nil {
+			return u.Redacted()
+		}
+	case key == "tenants":
+		tenants := append([]Tenant(nil), value.([]Tenant)...)
+		for i := range tenants {
+			if tenants[i].Key != "" {
+				tenants[i].Key = "********"
+			}
+		}
+		return tenants
+	}
+	return value
+}
+
+// FormatSetting renders the value of a setting on one line, as JSON for
+// lists and maps.
+func FormatSetting(s Setting) string {
+	switch reflect.ValueOf(s.Value).Kind() {
+	case reflect.Slice, reflect.Map, reflect.Struct:
+		if b, err := json.Marshal(s.Value); err == nil {
+			return string(b)
+		}
+	}
+	return fmt.Sprint(s.Value)
+}
@@ -0,0 +1,73 @@
+// Package drain tracks the requests a server is handling, so that shutting
+// down can refuse new requests and wait for the ones in flight.
+package drain
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrShuttingDown is returned by Begin once Drain was called.
+var ErrShuttingDown = errors.New("server is shutting down")
+
+// Tracker counts the requests in flight. The zero value is ready to use.
+type Tracker struct {
+	mu       sync.Mutex
+	draining bool
+	inFlight int
+	idle     chan struct{} // Closed when draining and no request is left
+}
+
+// Begin records the start of a request, or returns ErrShuttingDown when the
+// tracker is draining. Every successful Begin must be followed by End.
+func (t *Tracker) Begin() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.draining {
+		return ErrShuttingDown
+	}
+	t.inFlight++
+	return nil
+}
+
+// End records the end of a request started with Begin.
+func (t *Tracker) End() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.inFlight--
+	if t.draining && t.inFlight == 0 && t.idle != nil {
+		close(t.idle)
+		t.idle = nil
+	}
+}
+
+// InFlight returns the number of requests in flight.
+func (t *Tracker) InFlight() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.inFlight
+}
+
+// Drain refuses new requests and waits until those in flight end or ctx is
+// done, returning ctx's error in that case.
+func (t *Tracker) Drain(ctx context.Context) error {
+	t.mu.Lock()
+	t.draining = true
+	if t.inFlight == 0 {
+		t.mu.Unlock()
+		return nil
+	}
+	if t.idle == nil {
+		t.idle = make(chan struct{})
+	}
+	idle := t.idle
+	t.mu.Unlock()
+
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
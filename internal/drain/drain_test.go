@@ -0,0 +1,69 @@
+package drain
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTracker_DrainWaitsForInFlight(t *testing.T) {
+	var tr Tracker
+	if err := tr.Begin(); err != nil {
+		t.Fatal(err)
+	}
+
+	drained := make(chan error, 1)
+	go func() { drained <- tr.Drain(context.Background()) }()
+
+	// New requests are refused as soon as draining starts
+	deadline := time.Now().Add(time.Second)
+	for tr.Begin() == nil {
+		tr.End()
+		if time.Now().After(deadline) {
+			t.Fatal("Begin still accepted requests while draining")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if err := tr.Begin(); !errors.Is(err, ErrShuttingDown) {
+		t.Fatalf("Begin() while draining = %v, want ErrShuttingDown", err)
+	}
+
+	select {
+	case err := <-drained:
+		t.Fatalf("Drain returned %v with a request in flight", err)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	tr.End()
+	select {
+	case err := <-drained:
+		if err != nil {
+			t.Fatalf("Drain() = %v, want nil", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Drain did not return after the last request ended")
+	}
+}
+
+func TestTracker_DrainTimeout(t *testing.T) {
+	var tr Tracker
+	if err := tr.Begin(); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := tr.Drain(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Drain() = %v, want DeadlineExceeded", err)
+	}
+	if n := tr.InFlight(); n != 1 {
+		t.Fatalf("InFlight() = %d, want 1", n)
+	}
+}
+
+func TestTracker_DrainIdle(t *testing.T) {
+	var tr Tracker
+	if err := tr.Drain(context.Background()); err != nil {
+		t.Fatalf("Drain() with nothing in flight = %v", err)
+	}
+}
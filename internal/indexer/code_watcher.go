@@ -15,6 +15,7 @@ import (
 
 	"github.com/fsnotify/fsnotify"
 	"github.com/madeindigio/remembrances-mcp/internal/storage"
+	"github.com/madeindigio/remembrances-mcp/internal/watchqueue"
 	"github.com/madeindigio/remembrances-mcp/pkg/treesitter"
 )
 
@@ -25,8 +26,16 @@ type CodeWatcher struct {
 	indexer   *Indexer
 	storage   storage.FullStorage
 	watcher   *fsnotify.Watcher
+	queue     *watchqueue.Queue
+	ctx       context.Context
 	cancel    context.CancelFunc
 	once      sync.Once
+
+	// Shutdown closes stopping and waits for the event loop and the
+	// reindexing of outdated files
+	stopping chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
 }
 
 // StartCodeWatcher creates and starts a new code watcher for a project.
@@ -49,14 +58,20 @@ func StartCodeWatcher(parentCtx context.Context, project *storage.CodeProject, i
 		return nil, err
 	}
 
-	ctx, cancel := context.WithCancel(parentCtx)
+	// The watcher outlives the call that started it (a tool call or the
+	// startup): only Stop and Shutdown end it, so a cancelled parent cannot
+	// interrupt a file being reindexed.
+	ctx, cancel := context.WithCancel(context.WithoutCancel(parentCtx))
 	w := &CodeWatcher{
 		projectID: project.ProjectID,
 		rootPath:  project.RootPath,
 		indexer:   indexer,
 		storage:   st,
 		watcher:   fw,
+		queue:     watchqueue.New(indexer.config.WatchQueue),
+		ctx:       ctx,
 		cancel:    cancel,
+		stopping:  make(chan struct{}),
 	}
 
 	// Add the root directory (fsnotify is not recursive)
@@ -89,7 +104,11 @@ func StartCodeWatcher(parentCtx context.Context, project *storage.CodeProject, i
 	}
 
 	// Start event loop
-	go w.run(ctx)
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		w.run(ctx)
+	}()
 
 	slog.Info("code watcher started", "project_id", project.ProjectID, "path", project.RootPath)
 	return w, nil
@@ -107,6 +126,56 @@ func (w *CodeWatcher) Stop() {
 	})
 }
 
+// Shutdown stops the watcher once the file being reindexed is written and
+// the changes still queued are reindexed. When ctx is done first, the write
+// in progress is cancelled like Stop does; the files left are found outdated
+// when the project is watched again.
+func (w *CodeWatcher) Shutdown(ctx context.Context) error {
+	if w == nil {
+		return nil
+	}
+	stopCancel := context.AfterFunc(ctx, w.cancel)
+	defer stopCancel()
+
+	w.stopOnce.Do(func() { close(w.stopping) })
+	w.wg.Wait()
+
+	events := w.queue.Drain()
+	for i, e := range events {
+		if w.ctx.Err() != nil {
+			slog.Warn("code changes not reindexed before shutdown", "project_id", w.projectID, "files", len(events)-i)
+			break
+		}
+		w.handle(w.ctx, e)
+	}
+
+	err := ctx.Err()
+	w.Stop()
+	return err
+}
+
+// isStopping reports whether Shutdown was called.
+func (w *CodeWatcher) isStopping() bool {
+	select {
+	case <-w.stopping:
+		return true
+	default:
+		return false
+	}
+}
+
+// ProcessOutdatedFilesInBackground reindexes files found by
+// ScanOutdatedFiles until they are done or the watcher stops.
+func (w *CodeWatcher) ProcessOutdatedFilesInBackground(files []OutdatedFile) {
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		if err := w.ProcessOutdatedFiles(w.ctx, files); err != nil {
+			slog.Warn("failed to process outdated files", "project_id", w.projectID, "error", err)
+		}
+	}()
+}
+
 // GetProjectID returns the project ID being watched.
 func (w *CodeWatcher) GetProjectID() string {
 	if w == nil {
@@ -115,16 +184,20 @@ func (w *CodeWatcher) GetProjectID() string {
 	return w.projectID
 }
 
-// run processes watcher events and debounces rapid successive writes.
+// run queues watcher events and processes them once settled. Events for the
+// same file are coalesced and the queue paces reindexing, so bursts of
+// changes do not trigger one reindex per event.
 func (w *CodeWatcher) run(ctx context.Context) {
-	debounce := make(map[string]time.Time)
-	ticker := time.NewTicker(500 * time.Millisecond)
+	ticker := time.NewTicker(250 * time.Millisecond)
 	defer ticker.Stop()
+	throttled := false
 
 	for {
 		select {
 		case <-ctx.Done():
 			return
+		case <-w.stopping:
+			return
 		case evt, ok := <-w.watcher.Events:
 			if !ok {
 				return
@@ -154,18 +227,15 @@ func (w *CodeWatcher) run(ctx context.Context) {
 
 			// Delete/Rename events -> remove from index
 			if evt.Op&(fsnotify.Remove|fsnotify.Rename) != 0 {
-				rel := w.relativePath(evt.Name)
-				if err := w.storage.DeleteCodeFile(ctx, w.projectID, rel); err != nil {
-					slog.Warn("failed to delete code file after removal", "file", rel, "error", err)
-				} else {
-					slog.Info("code file removed from index", "file", rel)
-				}
+				w.queue.Add(evt.Name, watchqueue.OpRemove, time.Now())
 				continue
 			}
 
-			// Create or Write => schedule for debounced processing
+			// Create or Write => reindex, and index first if the project
+			// is being indexed
 			if evt.Op&(fsnotify.Create|fsnotify.Write) != 0 {
-				debounce[evt.Name] = time.Now()
+				w.indexer.Touch(w.projectID, w.relativePath(evt.Name))
+				w.queue.Add(evt.Name, watchqueue.OpChange, time.Now())
 			}
 
 		case err, ok := <-w.watcher.Errors:
@@ -175,16 +245,59 @@ func (w *CodeWatcher) run(ctx context.Context) {
 			slog.Warn("code watcher error", "error", err)
 
 		case now := <-ticker.C:
-			for file, t := range debounce {
-				if now.Sub(t) > 300*time.Millisecond {
-					w.processFile(ctx, file)
-					delete(debounce, file)
+			ready := w.queue.Ready(now)
+			for i, e := range ready {
+				if ctx.Err() != nil {
+					return
+				}
+				if w.isStopping() {
+					// Leave the rest to the flush of Shutdown
+					for _, rest := range ready[i:] {
+						w.queue.Add(rest.Path, rest.Op, now)
+					}
+					return
+				}
+				w.handle(ctx, e)
+			}
+
+			if w.queue.Throttled() != throttled {
+				throttled = !throttled
+				if throttled {
+					slog.Warn("code watcher reindex budget reached, deferring changes", "project_id", w.projectID, "pending", w.queue.Pending())
+				} else {
+					slog.Info("code watcher resumed deferred changes", "project_id", w.projectID)
 				}
 			}
 		}
 	}
 }
 
+// handle reindexes or removes a file of a settled event.
+func (w *CodeWatcher) handle(ctx context.Context, e watchqueue.Event) {
+	if e.Op == watchqueue.OpRemove {
+		w.removeFile(ctx, e.Path)
+	} else {
+		w.processFile(ctx, e.Path)
+	}
+}
+
+// removeFile removes a deleted file from the index.
+func (w *CodeWatcher) removeFile(ctx context.Context, fullPath string) {
+	rel := w.relativePath(fullPath)
+
+	// The file may have been recreated, e.g. by an editor saving atomically
+	if _, err := os.Stat(fullPath); err == nil {
+		w.processFile(ctx, fullPath)
+		return
+	}
+
+	if err := w.storage.DeleteCodeFile(ctx, w.projectID, rel); err != nil {
+		slog.Warn("failed to delete code file after removal", "file", rel, "error", err)
+	} else {
+		slog.Info("code file removed from index", "file", rel)
+	}
+}
+
 // processFile reindexes a single file.
 func (w *CodeWatcher) processFile(ctx context.Context, fullPath string) {
 	rel := w.relativePath(fullPath)
@@ -353,6 +466,10 @@ func (w *CodeWatcher) ProcessOutdatedFiles(ctx context.Context, files []Outdated
 			return ctx.Err()
 		default:
 		}
+		if w.isStopping() {
+			// The files left are found outdated again on the next activation
+			return nil
+		}
 
 		switch f.Reason {
 		case "deleted":
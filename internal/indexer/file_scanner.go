@@ -9,6 +9,7 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/madeindigio/remembrances-mcp/pkg/treesitter"
@@ -19,6 +20,13 @@ type FileScanner struct {
 	// Patterns to exclude from scanning (e.g., "node_modules", ".git")
 	ExcludePatterns []string
 
+	// Well-known paths of vendored code, lockfiles and bundles, excluded
+	// like ExcludePatterns (nil disables these heuristics)
+	VendorPatterns []string
+
+	// Whether to read the start of files to skip binary and minified ones
+	SniffContent bool
+
 	// Maximum file size to index (in bytes)
 	MaxFileSize int64
 
@@ -63,6 +71,31 @@ type ScanResult struct {
 	TotalSize     int64
 	SkippedFiles  int
 	SkippedReason map[string]int
+
+	// Skipped files and directories, except those with unsupported
+	// extensions, in walk order
+	Skipped []SkippedFile
+}
+
+// SkippedFile is a file or directory a scan did not index, and why.
+// Directories have a trailing slash.
+type SkippedFile struct {
+	RelPath string
+	Reason  string
+}
+
+// skip counts a skipped file and, unless its extension is not supported,
+// records it.
+func (r *ScanResult) skip(relPath, reason string, isDir bool) {
+	r.SkippedReason[reason]++
+	if reason == "unsupported_extension" {
+		return
+	}
+	relPath = filepath.ToSlash(relPath)
+	if isDir {
+		relPath += "/"
+	}
+	r.Skipped = append(r.Skipped, SkippedFile{RelPath: relPath, Reason: reason})
 }
 
 // DefaultExcludePatterns returns common patterns to exclude
@@ -197,6 +230,8 @@ func DefaultExcludePatterns() []string {
 func NewFileScanner() *FileScanner {
 	return &FileScanner{
 		ExcludePatterns: DefaultExcludePatterns(),
+		VendorPatterns:  DefaultVendorPatterns(),
+		SniffContent:    true,
 		MaxFileSize:     1024 * 1024, // 1MB default
 	}
 }
@@ -245,7 +280,7 @@ func (s *FileScanner) Scan(rootPath string) (*ScanResult, error) {
 
 		// Check if path should be excluded
 		if s.shouldExclude(path, relPath, d.IsDir()) {
-			result.SkippedReason["excluded"]++
+			result.skip(relPath, "excluded", d.IsDir())
 			if d.IsDir() {
 				return filepath.SkipDir
 			}
@@ -264,14 +299,14 @@ func (s *FileScanner) Scan(rootPath string) (*ScanResult, error) {
 		lang, ok := treesitter.GetLanguageByExtension(ext)
 		if !ok {
 			result.SkippedFiles++
-			result.SkippedReason["unsupported_extension"]++
+			result.skip(relPath, "unsupported_extension", false)
 			return nil
 		}
 
 		// Check if language is in include list
 		if len(s.IncludeLanguages) > 0 && !s.containsLanguage(lang) {
 			result.SkippedFiles++
-			result.SkippedReason["language_filtered"]++
+			result.skip(relPath, "language_filtered", false)
 			return nil
 		}
 
@@ -285,7 +320,19 @@ func (s *FileScanner) Scan(rootPath string) (*ScanResult, error) {
 		// Check file size
 		if info.Size() > s.MaxFileSize {
 			result.SkippedFiles++
-			result.SkippedReason["too_large"]++
+			result.skip(relPath, "too_large", false)
+			return nil
+		}
+
+		// Skip binary files and minified bundles with a source extension
+		reason, err := s.SkipReason(path)
+		if err != nil {
+			result.Errors = append(result.Errors, err)
+			return nil
+		}
+		if reason != "" {
+			result.SkippedFiles++
+			result.skip(relPath, reason, false)
 			return nil
 		}
 
@@ -315,7 +362,8 @@ func (s *FileScanner) Scan(rootPath string) (*ScanResult, error) {
 	return result, err
 }
 
-// ShouldExclude checks if a path should be excluded based on patterns.
+// ShouldExclude checks if a path should be excluded based on patterns,
+// including VendorPatterns.
 // It is exported so that CodeWatcher can reuse the same exclusion logic.
 func (s *FileScanner) ShouldExclude(absPath, relPath string, isDir bool) bool {
 	return s.shouldExclude(absPath, relPath, isDir)
@@ -328,7 +376,7 @@ func (s *FileScanner) shouldExclude(absPath, relPath string, isDir bool) bool {
 	nameUnix := filepath.ToSlash(name)
 	relPathUnix := filepath.ToSlash(filepath.Clean(relPath))
 
-	for _, pattern := range s.ExcludePatterns {
+	for _, pattern := range slices.Concat(s.ExcludePatterns, s.VendorPatterns) {
 		pattern = strings.TrimSpace(pattern)
 		if pattern == "" {
 			continue
@@ -0,0 +1,91 @@
+package indexer
+
+import (
+	"bytes"
+	"io"
+	"os"
+)
+
+// Content sniffing of scanned files.
+const (
+	// sniffSize is how much of a file is read to tell binary and minified
+	// files apart, like git does to detect binary files
+	sniffSize = 8000
+	// minifiedMinSize is the smallest sample judged as minified, so short
+	// files with a long line are kept
+	minifiedMinSize = 1024
+	// minifiedLineLength is the average line length, in bytes, above which
+	// a file is judged minified
+	minifiedLineLength = 300
+)
+
+// Reasons a file is skipped after sniffing its content.
+const (
+	skipBinary   = "binary"
+	skipMinified = "minified"
+)
+
+// DefaultVendorPatterns returns well-known paths of vendored code, lockfiles
+// and minified bundles that DefaultExcludePatterns does not already cover.
+func DefaultVendorPatterns() []string {
+	return []string{
+		// Vendored dependencies
+		"third_party",
+		"third-party",
+		"thirdparty",
+		"3rdparty",
+		"vendored",
+		"web_modules",
+
+		// Lock files
+		"npm-shrinkwrap.json",
+		"bun.lockb",
+		"go.work.sum",
+		"packages.lock.json",
+		"*.lockfile",
+
+		// Minified and bundled files
+		"*.min.mjs",
+		"*.min.cjs",
+		"*-min.js",
+		"*.chunk.js",
+		"*.bundle.mjs",
+	}
+}
+
+// SkipReason reads the start of a file and returns why it should not be
+// indexed: "binary" when it has a NUL byte, "minified" when its lines are
+// very long on average. It returns "" for files to index, and always when
+// SniffContent is off.
+func (s *FileScanner) SkipReason(absPath string) (string, error) {
+	if !s.SniffContent {
+		return "", nil
+	}
+	f, err := os.Open(absPath)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	sample := make([]byte, sniffSize)
+	n, err := io.ReadFull(f, sample)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return "", err
+	}
+	return sniffContent(sample[:n]), nil
+}
+
+// sniffContent returns why a file starting with sample should not be
+// indexed, or "".
+func sniffContent(sample []byte) string {
+	if bytes.IndexByte(sample, 0) >= 0 {
+		return skipBinary
+	}
+	if len(sample) >= minifiedMinSize {
+		lines := bytes.Count(sample, []byte{'\n'}) + 1
+		if len(sample)/lines > minifiedLineLength {
+			return skipMinified
+		}
+	}
+	return ""
+}
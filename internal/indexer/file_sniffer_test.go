@@ -0,0 +1,78 @@
+package indexer
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestSniffContent(t *testing.T) {
+	cases := []struct {
+		name   string
+		sample string
+		want   string
+	}{
+		{"source", strings.Repeat("func f() {\n\treturn\n}\n", 100), ""},
+		{"binary", "package main\x00\x01\x02", skipBinary},
+		{"minified", strings.Repeat("var a=function(){return 1};", 100), skipMinified},
+		{"short long line", strings.Repeat("x", 500), ""},
+		{"empty", "", ""},
+	}
+	for _, tc := range cases {
+		if got := sniffContent([]byte(tc.sample)); got != tc.want {
+			t.Errorf("%s: sniffContent = %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestScanSkipsJunkFiles(t *testing.T) {
+	root := t.TempDir()
+	write := func(rel, content string) {
+		t.Helper()
+		path := filepath.Join(root, rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("main.go", "package main\n\nfunc main() {}\n")
+	write("blob.go", "package main\x00\x00")
+	write("app.js", strings.Repeat("var a=function(){return 1};", 100))
+	write("third_party/lib/lib.go", "package lib\n")
+	write("web/app.chunk.js", "export const a = 1\n")
+
+	result, err := NewFileScanner().Scan(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Files) != 1 || result.Files[0].RelPath != "main.go" {
+		t.Fatalf("expected only main.go to be indexed, got %+v", result.Files)
+	}
+	if result.SkippedReason[skipBinary] != 1 || result.SkippedReason[skipMinified] != 1 {
+		t.Errorf("expected one binary and one minified file skipped, got %v", result.SkippedReason)
+	}
+	wantSkipped := []SkippedFile{
+		{"app.js", skipMinified},
+		{"blob.go", skipBinary},
+		{"third_party/", "excluded"},
+		{"web/app.chunk.js", "excluded"},
+	}
+	if !slices.Equal(result.Skipped, wantSkipped) {
+		t.Errorf("skipped %+v, want %+v", result.Skipped, wantSkipped)
+	}
+
+	scanner := NewFileScanner()
+	scanner.SniffContent = false
+	scanner.VendorPatterns = nil
+	result, err = scanner.Scan(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Files) != 5 {
+		t.Errorf("expected all 5 files without the heuristics, got %d", len(result.Files))
+	}
+}
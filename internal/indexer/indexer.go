@@ -4,6 +4,12 @@
 // Embedding generation is in indexer_embeddings.go
 // Progress tracking is in indexer_progress.go
 // Chunking is in indexer_chunks.go
+// Dependency extraction is in indexer_dependencies.go
+// Call graph extraction is in indexer_calls.go
+// Root path remapping is in indexer_remap.go
+// Per-project settings are in indexer_settings.go
+// Throughput controls are in indexer_throttle.go
+// Priority of touched files is in indexer_priority.go
 package indexer
 
 import (
@@ -29,9 +35,24 @@ type Indexer struct {
 	parser   *treesitter.Parser
 	walker   *treesitter.ASTWalker
 
+	// Named embedders projects can pick instead of embedder
+	embedders *embedder.Router
+
+	// Paces the embedding calls of all jobs
+	pacer *embeddingPacer
+
+	// Touched files indexed first, by project and relative path
+	hotMu sync.Mutex
+	hot   map[string]map[string]time.Time
+
 	// For progress tracking
 	mu       sync.RWMutex
 	progress map[string]*IndexingProgress
+
+	// Files that failed to index or had syntax errors, by project and
+	// relative path
+	issuesMu sync.Mutex
+	issues   map[string]map[string]FileIssue
 }
 
 // NewIndexer creates a new indexer instance
@@ -47,10 +68,26 @@ func NewIndexer(storage storage.FullStorage, embedder embedder.Embedder, config
 		embedder: embedder,
 		parser:   treesitter.NewParser(),
 		walker:   treesitter.NewASTWalker(walkerConfig),
+		pacer:    newEmbeddingPacer(config.EmbeddingQPS),
 		progress: make(map[string]*IndexingProgress),
 	}
 }
 
+// Limits returns the effective limits of the indexing jobs of the indexer;
+// MaxConcurrentJobs is left to the job manager.
+func (idx *Indexer) Limits() IndexingLimits {
+	limits := IndexingLimits{
+		Workers:        idx.workers(),
+		MaxFilesPerJob: idx.config.MaxFilesPerJob,
+		EmbeddingQPS:   idx.config.EmbeddingQPS,
+		Background:     idx.config.Background,
+	}
+	if idx.config.Scanner != nil {
+		limits.MaxFileSize = idx.config.Scanner.MaxFileSize
+	}
+	return limits
+}
+
 // IndexProject indexes a code project
 func (idx *Indexer) IndexProject(ctx context.Context, projectPath string, projectName string) (string, error) {
 	// Normalize path
@@ -92,6 +129,18 @@ func (idx *Indexer) IndexProject(ctx context.Context, projectPath string, projec
 		return "", fmt.Errorf("failed to create project: %w", err)
 	}
 
+	// Apply the settings of the project, if it was configured
+	stored, err := idx.storage.GetCodeProject(ctx, projectID)
+	if err != nil {
+		return projectID, fmt.Errorf("failed to get project: %w", err)
+	}
+	pi, err := idx.indexingFor(stored)
+	if err != nil {
+		idx.setError(projectID, err)
+		idx.storage.UpdateProjectStatus(ctx, projectID, treesitter.IndexingStatusFailed)
+		return projectID, err
+	}
+
 	// Scan for files
 	slog.Info("Scanning project", "path", absPath)
 	scanResult, err := idx.config.Scanner.Scan(absPath)
@@ -100,13 +149,20 @@ func (idx *Indexer) IndexProject(ctx context.Context, projectPath string, projec
 		return projectID, fmt.Errorf("failed to scan project: %w", err)
 	}
 
-	slog.Info("Found files to index", "count", scanResult.TotalFiles)
+	slog.Info("Found files to index", "count", scanResult.TotalFiles, "skipped", scanResult.SkippedReason)
+	idx.keepFileIssues(projectID, scanResult.Files)
+	if limit := idx.config.MaxFilesPerJob; limit > 0 && scanResult.TotalFiles > limit {
+		err := fmt.Errorf("project has %d files to index, more than the limit of %d per job; exclude directories with code-indexing-exclude-patterns or raise code-indexing-max-files-per-job", scanResult.TotalFiles, limit)
+		idx.setError(projectID, err)
+		idx.storage.UpdateProjectStatus(ctx, projectID, treesitter.IndexingStatusFailed)
+		return projectID, err
+	}
 	idx.updateProgress(projectID, func(p *IndexingProgress) {
 		p.FilesTotal = scanResult.TotalFiles
 	})
 
 	// Process files
-	if err := idx.processFiles(ctx, projectID, absPath, scanResult.Files); err != nil {
+	if err := idx.processFiles(ctx, pi, projectID, absPath, scanResult.Files); err != nil {
 		idx.setError(projectID, err)
 		idx.storage.UpdateProjectStatus(ctx, projectID, treesitter.IndexingStatusFailed)
 		return projectID, fmt.Errorf("indexing failed: %w", err)
@@ -137,20 +193,26 @@ func (idx *Indexer) IndexProject(ctx context.Context, projectPath string, projec
 }
 
 // processFiles processes all discovered files
-func (idx *Indexer) processFiles(ctx context.Context, projectID, rootPath string, files []ScannedFile) error {
-	// Create work channel
-	fileChan := make(chan ScannedFile, len(files))
-	errChan := make(chan error, idx.config.Concurrency)
+func (idx *Indexer) processFiles(ctx context.Context, pi *projectIndexing, projectID, rootPath string, files []ScannedFile) error {
+	// Touched files are handed out first
+	queue := newFileQueue(idx, projectID, files)
+	workers := idx.workers()
+	errChan := make(chan error, len(files)) // Collected once all workers are done
 	var wg sync.WaitGroup
 
 	// Start workers - each with its own parser to avoid tree-sitter thread-safety issues
-	for i := 0; i < idx.config.Concurrency; i++ {
+	for i := 0; i < workers; i++ {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
+			idx.startWorker()
 			// Create a parser per worker - tree-sitter parsers are NOT thread-safe
 			workerParser := treesitter.NewParser()
-			for file := range fileChan {
+			for ctx.Err() == nil {
+				file, hot, ok := queue.pop()
+				if !ok {
+					return
+				}
 				// Recover from panics to prevent one file from crashing the entire process
 				func() {
 					defer func() {
@@ -158,35 +220,34 @@ func (idx *Indexer) processFiles(ctx context.Context, projectID, rootPath string
 							slog.Error("PANIC recovered while processing file",
 								"file", file.RelPath,
 								"panic", r)
+							idx.recordFileIssue(projectID, file.RelPath, FileIssueFailed, fmt.Sprintf("panic: %v", r))
 							errChan <- fmt.Errorf("panic processing %s: %v", file.RelPath, r)
 						}
 					}()
 
-					if err := idx.processFileWithParser(ctx, projectID, rootPath, file, workerParser); err != nil {
+					if err := idx.processFileWithParser(ctx, pi, projectID, rootPath, file, workerParser); err != nil {
 						slog.Warn("Error processing file, continuing with next",
 							"file", file.RelPath,
 							"error", err)
 						errChan <- err
+						return
+					}
+					if hot {
+						idx.updateProgress(projectID, func(p *IndexingProgress) {
+							p.FilesPrioritized++
+						})
 					}
 				}()
 			}
 		}()
 	}
 
-	// Send files to workers
-	for _, file := range files {
-		select {
-		case <-ctx.Done():
-			close(fileChan)
-			return ctx.Err()
-		case fileChan <- file:
-		}
-	}
-	close(fileChan)
-
 	// Wait for workers to finish
 	wg.Wait()
 	close(errChan)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 
 	// Collect errors
 	var errors []error
@@ -220,12 +281,24 @@ func (idx *Indexer) processFiles(ctx context.Context, projectID, rootPath string
 }
 
 // processFile processes a single source file using the shared parser (for single-threaded use)
-func (idx *Indexer) processFile(ctx context.Context, projectID, rootPath string, file ScannedFile) error {
-	return idx.processFileWithParser(ctx, projectID, rootPath, file, idx.parser)
+func (idx *Indexer) processFile(ctx context.Context, pi *projectIndexing, projectID, rootPath string, file ScannedFile) error {
+	return idx.processFileWithParser(ctx, pi, projectID, rootPath, file, idx.parser)
 }
 
-// processFileWithParser processes a single source file with a specific parser instance
-func (idx *Indexer) processFileWithParser(ctx context.Context, projectID, rootPath string, file ScannedFile, parser *treesitter.Parser) error {
+// processFileWithParser processes a single source file with a specific parser
+// instance, recording the file as failed when it cannot be indexed
+func (idx *Indexer) processFileWithParser(ctx context.Context, pi *projectIndexing, projectID, rootPath string, file ScannedFile, parser *treesitter.Parser) error {
+	err := idx.indexFile(ctx, pi, projectID, rootPath, file, parser)
+	if err != nil && ctx.Err() == nil {
+		idx.recordFileIssue(projectID, file.RelPath, FileIssueFailed, err.Error())
+	}
+	return err
+}
+
+// indexFile parses a source file and saves its symbols, unless it has not
+// changed since it was indexed
+func (idx *Indexer) indexFile(ctx context.Context, pi *projectIndexing, projectID, rootPath string, file ScannedFile, parser *treesitter.Parser) error {
+	ctx = pi.withModel(ctx)
 	idx.updateProgress(projectID, func(p *IndexingProgress) {
 		p.CurrentFile = file.RelPath
 	})
@@ -236,7 +309,7 @@ func (idx *Indexer) processFileWithParser(ctx context.Context, projectID, rootPa
 		return fmt.Errorf("failed to check existing file: %w", err)
 	}
 
-	if existingFile != nil && existingFile.FileHash == file.Hash {
+	if existingFile != nil && existingFile.FileHash == file.Hash && (!idx.config.StoreFileContents || existingFile.HasContent) {
 		// File hasn't changed, skip
 		idx.updateProgress(projectID, func(p *IndexingProgress) {
 			p.FilesIndexed++
@@ -255,32 +328,64 @@ func (idx *Indexer) processFileWithParser(ctx context.Context, projectID, rootPa
 	if err != nil {
 		return fmt.Errorf("failed to parse file: %w", err)
 	}
+	idx.recordSyntaxErrors(projectID, file.RelPath, treesitter.FindSyntaxErrors(tree, content))
 
 	// Extract symbols
-	symbols, err := idx.walker.ExtractSymbols(tree, content, lang, file.RelPath, projectID)
+	symbols, err := pi.walker.ExtractSymbols(tree, content, lang, file.RelPath, projectID)
 	if err != nil {
 		return fmt.Errorf("failed to extract symbols: %w", err)
 	}
 
-	// Delete old symbols for this file
-	if existingFile != nil {
-		if err := idx.storage.DeleteSymbolsByFile(ctx, projectID, file.RelPath); err != nil {
-			slog.Warn("failed to delete old symbols", "error", err)
+	// A file re-indexed on its own, e.g. on save, only updates the symbols
+	// that changed. Files whose hash was cleared, because the project's
+	// settings changed, are embedded again entirely.
+	var diff *symbolDiff
+	if pi.incremental && existingFile != nil && existingFile.FileHash != "" {
+		stored, err := idx.storage.FindSymbolsByFile(ctx, projectID, file.RelPath)
+		if err != nil {
+			return fmt.Errorf("failed to get stored symbols: %w", err)
+		}
+		diff = diffSymbols(stored, symbols)
+		if err := idx.saveSymbolDiff(ctx, pi, projectID, file.RelPath, diff); err != nil {
+			return err
+		}
+	} else {
+		// Delete old symbols for this file
+		if existingFile != nil {
+			if err := idx.storage.DeleteSymbolsByFile(ctx, projectID, file.RelPath); err != nil {
+				slog.Warn("failed to delete old symbols", "error", err)
+			}
+		}
+
+		// Generate embeddings for symbols (with error recovery)
+		if err := idx.generateEmbeddings(ctx, pi.embedder, symbols); err != nil {
+			slog.Warn("Failed to generate embeddings for some/all symbols, saving without embeddings",
+				"file", file.RelPath,
+				"error", err,
+				"symbol_count", len(symbols))
+			// Continue without embeddings - symbols will be saved without embedding vectors
+		}
+
+		// Save symbols
+		if err := idx.storage.SaveCodeSymbols(ctx, symbols); err != nil {
+			return fmt.Errorf("failed to save symbols: %w", err)
 		}
 	}
 
-	// Generate embeddings for symbols (with error recovery)
-	if err := idx.generateEmbeddings(ctx, symbols); err != nil {
-		slog.Warn("Failed to generate embeddings for some/all symbols, saving without embeddings",
+	// Save imports (with error recovery)
+	if err := idx.saveDependencies(ctx, projectID, rootPath, file.RelPath, tree, content, lang); err != nil {
+		slog.Warn("Failed to save file dependencies",
 			"file", file.RelPath,
-			"error", err,
-			"symbol_count", len(symbols))
-		// Continue without embeddings - symbols will be saved without embedding vectors
+			"error", err)
+		// Continue without dependencies - symbols are already saved
 	}
 
-	// Save symbols
-	if err := idx.storage.SaveCodeSymbols(ctx, symbols); err != nil {
-		return fmt.Errorf("failed to save symbols: %w", err)
+	// Save call graph edges (with error recovery)
+	if err := idx.saveCalls(ctx, projectID, file.RelPath, tree, content, lang, symbols); err != nil {
+		slog.Warn("Failed to save file calls",
+			"file", file.RelPath,
+			"error", err)
+		// Continue without calls - symbols are already saved
 	}
 
 	// Save file record
@@ -292,13 +397,21 @@ func (idx *Indexer) processFileWithParser(ctx context.Context, projectID, rootPa
 		SymbolsCount: len(symbols),
 		IndexedAt:    time.Now(),
 	}
+	if idx.config.StoreFileContents {
+		codeFile.Content = content
+	}
 
 	if err := idx.storage.SaveCodeFile(ctx, codeFile); err != nil {
 		return fmt.Errorf("failed to save file record: %w", err)
 	}
 
 	// Process large symbols for chunking (with error recovery)
-	if err := idx.processLargeSymbols(ctx, projectID, file.RelPath, symbols); err != nil {
+	if diff != nil {
+		err = idx.updateSymbolChunks(ctx, pi, projectID, file.RelPath, diff)
+	} else {
+		err = idx.processLargeSymbols(ctx, pi, projectID, file.RelPath, symbols)
+	}
+	if err != nil {
 		slog.Warn("Failed to process large symbols for chunking, skipping chunk generation",
 			"file", file.RelPath,
 			"error", err)
@@ -340,6 +453,12 @@ func (idx *Indexer) ReindexFile(ctx context.Context, projectID, filePath string)
 		return fmt.Errorf("project not found: %s", projectID)
 	}
 
+	pi, err := idx.indexingFor(project)
+	if err != nil {
+		return err
+	}
+	pi.incremental = true
+
 	absPath := filepath.Join(project.RootPath, filePath)
 
 	// Check file exists
@@ -355,6 +474,15 @@ func (idx *Indexer) ReindexFile(ctx context.Context, projectID, filePath string)
 		return fmt.Errorf("unsupported file extension: %s", ext)
 	}
 
+	// Binary files and minified bundles are not indexed, as in a full scan
+	reason, err := idx.config.Scanner.SkipReason(absPath)
+	if err != nil {
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+	if reason != "" {
+		return fmt.Errorf("skipped %s file: %s", reason, filePath)
+	}
+
 	// Calculate hash
 	hash, err := idx.config.Scanner.calculateHash(absPath)
 	if err != nil {
@@ -369,7 +497,7 @@ func (idx *Indexer) ReindexFile(ctx context.Context, projectID, filePath string)
 		Hash:     hash,
 	}
 
-	return idx.processFile(ctx, projectID, project.RootPath, file)
+	return idx.processFile(ctx, pi, projectID, project.RootPath, file)
 }
 
 // GetScanner returns the file scanner used by this indexer.
@@ -379,5 +507,8 @@ func (idx *Indexer) GetScanner() *FileScanner {
 
 // DeleteProject removes a project and all its data
 func (idx *Indexer) DeleteProject(ctx context.Context, projectID string) error {
+	idx.issuesMu.Lock()
+	delete(idx.issues, projectID)
+	idx.issuesMu.Unlock()
 	return idx.storage.DeleteCodeProject(ctx, projectID)
 }
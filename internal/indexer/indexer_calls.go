@@ -0,0 +1,34 @@
+// Package indexer provides the main indexing service for code projects.
+// This file contains call graph extraction.
+package indexer
+
+import (
+	"context"
+
+	sitter "github.com/madeindigio/go-tree-sitter"
+
+	"github.com/madeindigio/remembrances-mcp/internal/storage"
+	"github.com/madeindigio/remembrances-mcp/pkg/treesitter"
+)
+
+// saveCalls extracts the calls made in a parsed file, attributed to the
+// file's symbols, and stores them as call graph edges
+func (idx *Indexer) saveCalls(ctx context.Context, projectID, relPath string, tree *sitter.Tree, content []byte, lang treesitter.Language, symbols []*treesitter.CodeSymbol) error {
+	extracted := treesitter.ExtractCalls(tree, content, symbols)
+
+	calls := make([]storage.CodeCall, 0, len(extracted))
+	for _, call := range extracted {
+		calls = append(calls, storage.CodeCall{
+			ProjectID:      projectID,
+			FilePath:       relPath,
+			CallerNamePath: call.CallerNamePath,
+			CallerName:     call.CallerName,
+			CalleeName:     call.CalleeName,
+			CalleeExpr:     call.CalleeExpr,
+			Line:           call.Line,
+			Language:       lang,
+		})
+	}
+
+	return idx.storage.SaveCodeCalls(ctx, projectID, relPath, calls)
+}
@@ -27,21 +27,26 @@ const (
 )
 
 // processLargeSymbols creates chunks for symbols larger than the threshold
-func (idx *Indexer) processLargeSymbols(ctx context.Context, projectID, filePath string, symbols []*treesitter.CodeSymbol) error {
+func (idx *Indexer) processLargeSymbols(ctx context.Context, pi *projectIndexing, projectID, filePath string, symbols []*treesitter.CodeSymbol) error {
 	// First, delete existing chunks for this file
 	if err := idx.storage.DeleteChunksByFile(ctx, projectID, filePath); err != nil {
 		slog.Warn("failed to delete existing chunks", "error", err)
 	}
+	return idx.saveSymbolChunks(ctx, pi, projectID, filePath, symbols)
+}
 
+// saveSymbolChunks creates, embeds and saves the chunks of the symbols
+// larger than the threshold
+func (idx *Indexer) saveSymbolChunks(ctx context.Context, pi *projectIndexing, projectID, filePath string, symbols []*treesitter.CodeSymbol) error {
 	var allChunks []*storage.CodeChunk
 
 	for _, sym := range symbols {
-		if sym.SourceCode == "" || len(sym.SourceCode) < ChunkThreshold {
+		if sym.SourceCode == "" || len(sym.SourceCode) < pi.chunkThreshold {
 			continue
 		}
 
 		// Create chunks for this symbol
-		chunks := idx.createSymbolChunks(sym, projectID, filePath)
+		chunks := idx.createSymbolChunks(sym, projectID, filePath, pi.chunkSize, pi.chunkOverlap)
 		allChunks = append(allChunks, chunks...)
 	}
 
@@ -50,7 +55,7 @@ func (idx *Indexer) processLargeSymbols(ctx context.Context, projectID, filePath
 	}
 
 	// Generate embeddings for chunks
-	if err := idx.generateChunkEmbeddings(ctx, allChunks); err != nil {
+	if err := idx.generateChunkEmbeddings(ctx, pi.embedder, allChunks); err != nil {
 		return fmt.Errorf("failed to generate chunk embeddings: %w", err)
 	}
 
@@ -63,17 +68,17 @@ func (idx *Indexer) processLargeSymbols(ctx context.Context, projectID, filePath
 	return nil
 }
 
-// createSymbolChunks splits a large symbol into chunks
-func (idx *Indexer) createSymbolChunks(sym *treesitter.CodeSymbol, projectID, filePath string) []*storage.CodeChunk {
+// createSymbolChunks splits a large symbol into chunks of size characters
+// overlapping by overlap
+func (idx *Indexer) createSymbolChunks(sym *treesitter.CodeSymbol, projectID, filePath string, size, overlap int) []*storage.CodeChunk {
 	sourceCode := sym.SourceCode
-	chunks := embedder.ChunkText(sourceCode, ChunkSize, ChunkOverlap)
+	chunks := embedder.ChunkText(sourceCode, size, overlap)
 
 	if len(chunks) <= 1 {
 		return nil // No need to chunk if only one piece
 	}
 
-	// Generate a unique symbol ID (project:file:name_path)
-	symbolID := fmt.Sprintf("%s:%s:%s", projectID, filePath, sym.NamePath)
+	symbolID := chunkSymbolID(projectID, filePath, sym.NamePath)
 
 	result := make([]*storage.CodeChunk, len(chunks))
 	offset := 0
@@ -102,7 +107,7 @@ func (idx *Indexer) createSymbolChunks(sym *treesitter.CodeSymbol, projectID, fi
 			Language:    string(sym.Language),
 		}
 
-		offset = endOffset - ChunkOverlap
+		offset = endOffset - overlap
 		if offset < 0 {
 			offset = 0
 		}
@@ -110,3 +115,9 @@ func (idx *Indexer) createSymbolChunks(sym *treesitter.CodeSymbol, projectID, fi
 
 	return result
 }
+
+// chunkSymbolID returns the symbol ID the chunks of a symbol are stored
+// with (project:file:name_path)
+func chunkSymbolID(projectID, filePath, namePath string) string {
+	return fmt.Sprintf("%s:%s:%s", projectID, filePath, namePath)
+}
@@ -0,0 +1,160 @@
+// Package indexer provides the main indexing service for code projects.
+// This file contains import extraction and resolution of imports to project files.
+package indexer
+
+import (
+	"bufio"
+	"context"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	sitter "github.com/madeindigio/go-tree-sitter"
+
+	"github.com/madeindigio/remembrances-mcp/internal/storage"
+	"github.com/madeindigio/remembrances-mcp/pkg/treesitter"
+)
+
+// scriptExtensions are tried, in order, when a JavaScript-family import omits the extension
+var scriptExtensions = []string{".ts", ".tsx", ".js", ".jsx", ".mjs", ".cjs", ".svelte", ".vue", ".json"}
+
+// saveDependencies extracts the imports of a parsed file and stores them with
+// the project file each one resolves to
+func (idx *Indexer) saveDependencies(ctx context.Context, projectID, rootPath, relPath string, tree *sitter.Tree, content []byte, lang treesitter.Language) error {
+	imports := treesitter.ExtractImports(tree, content, lang)
+
+	deps := make([]storage.CodeDependency, 0, len(imports))
+	for _, imp := range imports {
+		dep := storage.CodeDependency{
+			ProjectID: projectID,
+			FilePath:  relPath,
+			Module:    imp.Module,
+			Line:      imp.Line,
+			Language:  lang,
+		}
+		if target := resolveImport(rootPath, relPath, imp.Module, lang); target != "" {
+			dep.TargetPath = &target
+		}
+		deps = append(deps, dep)
+	}
+
+	return idx.storage.SaveCodeDependencies(ctx, projectID, relPath, deps)
+}
+
+// resolveImport returns the project-relative path an import refers to, or an
+// empty string for external modules. Go imports resolve to the package directory.
+func resolveImport(rootPath, relPath, module string, lang treesitter.Language) string {
+	dir := filepath.Dir(relPath)
+
+	switch lang {
+	case treesitter.LanguageGo:
+		modulePath := goModulePath(rootPath)
+		if modulePath == "" || (module != modulePath && !strings.HasPrefix(module, modulePath+"/")) {
+			return ""
+		}
+		pkgDir := filepath.FromSlash(strings.TrimPrefix(strings.TrimPrefix(module, modulePath), "/"))
+		if pkgDir == "" {
+			pkgDir = "."
+		}
+		if info, err := os.Stat(filepath.Join(rootPath, pkgDir)); err == nil && info.IsDir() {
+			return pkgDir
+		}
+
+	case treesitter.LanguageTypeScript, treesitter.LanguageTSX, treesitter.LanguageJavaScript,
+		treesitter.LanguageSvelte, treesitter.LanguageVue:
+		if !isRelativeImport(module) {
+			return ""
+		}
+		base := filepath.Join(dir, filepath.FromSlash(module))
+		candidates := []string{base}
+		for _, ext := range scriptExtensions {
+			candidates = append(candidates, base+ext)
+		}
+		for _, ext := range scriptExtensions {
+			candidates = append(candidates, filepath.Join(base, "index"+ext))
+		}
+		return firstExisting(rootPath, candidates)
+
+	case treesitter.LanguagePython:
+		base := pythonModulePath(dir, module)
+		return firstExisting(rootPath, []string{base + ".py", filepath.Join(base, "__init__.py")})
+
+	case treesitter.LanguageRuby:
+		if !isRelativeImport(module) {
+			return firstExisting(rootPath, []string{filepath.Join("lib", filepath.FromSlash(module)+".rb")})
+		}
+		base := filepath.Join(dir, filepath.FromSlash(module))
+		return firstExisting(rootPath, []string{base, base + ".rb"})
+
+	case treesitter.LanguageLua:
+		base := filepath.FromSlash(strings.ReplaceAll(module, ".", "/"))
+		return firstExisting(rootPath, []string{base + ".lua", filepath.Join(base, "init.lua")})
+
+	case treesitter.LanguageC, treesitter.LanguageCPP, treesitter.LanguageObjectiveC,
+		treesitter.LanguagePHP, treesitter.LanguageProtobuf:
+		// Paths are relative to the including file or to an include root
+		module = filepath.FromSlash(strings.TrimPrefix(module, "/"))
+		return firstExisting(rootPath, []string{
+			filepath.Join(dir, module),
+			module,
+			filepath.Join("include", module),
+			filepath.Join("src", module),
+		})
+	}
+
+	return ""
+}
+
+// isRelativeImport reports whether an import is a path relative to the importing file
+func isRelativeImport(module string) bool {
+	return module == "." || module == ".." || strings.HasPrefix(module, "./") || strings.HasPrefix(module, "../")
+}
+
+// pythonModulePath converts a dotted module name to a path without extension.
+// Leading dots make it relative to the importing package.
+func pythonModulePath(dir, module string) string {
+	dots := len(module) - len(strings.TrimLeft(module, "."))
+	rest := filepath.FromSlash(strings.ReplaceAll(module[dots:], ".", "/"))
+	if dots == 0 {
+		return rest
+	}
+
+	base := dir
+	for i := 1; i < dots; i++ {
+		base = filepath.Dir(base)
+	}
+	return filepath.Join(base, rest)
+}
+
+// firstExisting returns the first candidate that is a file inside the project
+func firstExisting(rootPath string, candidates []string) string {
+	for _, candidate := range candidates {
+		candidate = filepath.Clean(candidate)
+		if candidate == "." || strings.HasPrefix(candidate, ".."+string(filepath.Separator)) || candidate == ".." {
+			continue
+		}
+		if info, err := os.Stat(filepath.Join(rootPath, candidate)); err == nil && !info.IsDir() {
+			return candidate
+		}
+	}
+	return ""
+}
+
+// goModulePath returns the module path declared in the project's go.mod
+func goModulePath(rootPath string) string {
+	f, err := os.Open(filepath.Join(rootPath, "go.mod"))
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "module ") {
+			return path.Clean(strings.Trim(strings.TrimSpace(strings.TrimPrefix(line, "module ")), `"`))
+		}
+	}
+	return ""
+}
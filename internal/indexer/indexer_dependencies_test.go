@@ -0,0 +1,57 @@
+package indexer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/madeindigio/remembrances-mcp/pkg/treesitter"
+)
+
+func TestResolveImport(t *testing.T) {
+	root := t.TempDir()
+
+	files := []string{
+		"go.mod",
+		"pkg/util/util.go",
+		"web/src/api.ts",
+		"web/src/components/index.tsx",
+		"app/__init__.py",
+		"app/models.py",
+		"app/views/home.py",
+		"include/config.h",
+	}
+	for _, f := range files {
+		path := filepath.Join(root, filepath.FromSlash(f))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("module example.com/app\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cases := []struct {
+		file   string
+		module string
+		lang   treesitter.Language
+		want   string
+	}{
+		{"main.go", "example.com/app/pkg/util", treesitter.LanguageGo, "pkg/util"},
+		{"main.go", "fmt", treesitter.LanguageGo, ""},
+		{"web/src/main.ts", "./api", treesitter.LanguageTypeScript, "web/src/api.ts"},
+		{"web/src/main.ts", "./components", treesitter.LanguageTypeScript, "web/src/components/index.tsx"},
+		{"web/src/main.ts", "react", treesitter.LanguageTypeScript, ""},
+		{"app/views/home.py", "..models", treesitter.LanguagePython, "app/models.py"},
+		{"main.py", "app", treesitter.LanguagePython, "app/__init__.py"},
+		{"src/main.c", "config.h", treesitter.LanguageC, "include/config.h"},
+		{"src/main.c", "stdio.h", treesitter.LanguageC, ""},
+	}
+
+	for _, tc := range cases {
+		got := resolveImport(root, filepath.FromSlash(tc.file), tc.module, tc.lang)
+		if got != filepath.FromSlash(tc.want) {
+			t.Errorf("resolveImport(%q, %q) = %q; want %q", tc.file, tc.module, got, tc.want)
+		}
+	}
+}
@@ -14,7 +14,7 @@ import (
 
 // generateEmbeddings generates embeddings for symbols in batches
 // This function continues processing even if some embeddings fail
-func (idx *Indexer) generateEmbeddings(ctx context.Context, symbols []*treesitter.CodeSymbol) error {
+func (idx *Indexer) generateEmbeddings(ctx context.Context, emb embedder.Embedder, symbols []*treesitter.CodeSymbol) error {
 	if len(symbols) == 0 {
 		return nil
 	}
@@ -24,7 +24,7 @@ func (idx *Indexer) generateEmbeddings(ctx context.Context, symbols []*treesitte
 	symbolIdxs := make([]int, 0, len(symbols))
 
 	for i, sym := range symbols {
-		text := idx.prepareSymbolText(sym)
+		text := idx.prepareSymbolText(emb, sym)
 		if text != "" {
 			texts = append(texts, text)
 			symbolIdxs = append(symbolIdxs, i)
@@ -46,7 +46,10 @@ func (idx *Indexer) generateEmbeddings(ctx context.Context, symbols []*treesitte
 		}
 
 		batch := texts[i:end]
-		embeddings, err := idx.embedder.EmbedDocuments(ctx, batch)
+		if err := idx.pacer.wait(ctx); err != nil {
+			return err
+		}
+		embeddings, err := emb.EmbedDocuments(ctx, batch)
 		if err != nil {
 			// Log error but continue with next batch
 			slog.Warn("Failed to generate embeddings for batch, skipping",
@@ -92,10 +95,10 @@ func (idx *Indexer) generateEmbeddings(ctx context.Context, symbols []*treesitte
 }
 
 // prepareSymbolText creates the text representation for embedding
-func (idx *Indexer) prepareSymbolText(sym *treesitter.CodeSymbol) string {
+func (idx *Indexer) prepareSymbolText(emb embedder.Embedder, sym *treesitter.CodeSymbol) string {
 	// Get dynamic limits from embedder (falls back to safe defaults)
 	maxTextLength := 900 // Fallback default
-	if ggufEmb, ok := idx.embedder.(*embedder.GGUFEmbedder); ok {
+	if ggufEmb, ok := emb.(*embedder.GGUFEmbedder); ok {
 		maxTextLength = ggufEmb.MaxChars()
 	}
 
@@ -145,14 +148,14 @@ func (idx *Indexer) prepareSymbolText(sym *treesitter.CodeSymbol) string {
 
 // generateChunkEmbeddings generates embeddings for chunks in batches
 // This function continues processing even if some embeddings fail
-func (idx *Indexer) generateChunkEmbeddings(ctx context.Context, chunks []*storage.CodeChunk) error {
+func (idx *Indexer) generateChunkEmbeddings(ctx context.Context, emb embedder.Embedder, chunks []*storage.CodeChunk) error {
 	if len(chunks) == 0 {
 		return nil
 	}
 
 	// Get dynamic limits from embedder (falls back to safe defaults)
 	maxTextLength := 900 // Fallback default
-	if ggufEmb, ok := idx.embedder.(*embedder.GGUFEmbedder); ok {
+	if ggufEmb, ok := emb.(*embedder.GGUFEmbedder); ok {
 		maxTextLength = ggufEmb.MaxChars()
 	}
 
@@ -193,7 +196,10 @@ func (idx *Indexer) generateChunkEmbeddings(ctx context.Context, chunks []*stora
 		}
 
 		batch := texts[i:end]
-		embeddings, err := idx.embedder.EmbedDocuments(ctx, batch)
+		if err := idx.pacer.wait(ctx); err != nil {
+			return err
+		}
+		embeddings, err := emb.EmbedDocuments(ctx, batch)
 		if err != nil {
 			// Log error but continue with next batch
 			slog.Warn("Failed to generate chunk embeddings for batch, skipping",
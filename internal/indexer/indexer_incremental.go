@@ -0,0 +1,130 @@
+// Package indexer provides the main indexing service for code projects.
+// This file contains the incremental update of the symbols of a re-indexed
+// file: only the symbols that changed are written and re-embedded.
+package indexer
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+
+	"github.com/madeindigio/remembrances-mcp/internal/storage"
+	"github.com/madeindigio/remembrances-mcp/pkg/treesitter"
+)
+
+// symbolDiff is how the symbols extracted from a file differ from the
+// symbols stored for it, matched by name path.
+type symbolDiff struct {
+	changed   []*treesitter.CodeSymbol // New or edited: replaced and re-embedded
+	moved     []*treesitter.CodeSymbol // Same text at another position: embedding kept
+	removed   []string                 // Name paths of stored symbols no longer in the file
+	unchanged int
+}
+
+// stale returns the name paths of the stored symbols the diff replaces or
+// removes.
+func (d *symbolDiff) stale() []string {
+	paths := slices.Clone(d.removed)
+	for _, sym := range d.changed {
+		paths = append(paths, sym.NamePath)
+	}
+	return paths
+}
+
+// diffSymbols compares the symbols extracted from a file with the symbols
+// stored for it. A symbol is unchanged when its text, signature, doc string
+// and type are, and it has an embedding. Overloads and other symbols sharing
+// a name path are told apart by their ordinals (name#2, name#3...), so each
+// is compared with the stored symbol of the same ordinal; extracted symbols
+// that still share a name path are replaced, since storage keeps one per
+// name path.
+func diffSymbols(stored []storage.CodeSymbol, extracted []*treesitter.CodeSymbol) *symbolDiff {
+	byPath := make(map[string]storage.CodeSymbol, len(stored))
+	for _, s := range stored {
+		byPath[s.NamePath] = s
+	}
+	counts := make(map[string]int, len(extracted))
+	for _, sym := range extracted {
+		counts[sym.NamePath]++
+	}
+
+	diff := &symbolDiff{}
+	for _, sym := range extracted {
+		old, ok := byPath[sym.NamePath]
+		switch {
+		case !ok || counts[sym.NamePath] > 1 || !sameSymbolText(old, sym):
+			diff.changed = append(diff.changed, sym)
+		case old.StartLine != sym.StartLine || old.EndLine != sym.EndLine ||
+			old.StartByte != sym.StartByte || old.EndByte != sym.EndByte:
+			diff.moved = append(diff.moved, sym)
+		default:
+			diff.unchanged++
+		}
+	}
+	for path := range byPath {
+		if counts[path] == 0 {
+			diff.removed = append(diff.removed, path)
+		}
+	}
+	slices.Sort(diff.removed)
+	return diff
+}
+
+// sameSymbolText reports whether an extracted symbol has the text of a
+// stored one, whose embedding therefore still applies.
+func sameSymbolText(old storage.CodeSymbol, sym *treesitter.CodeSymbol) bool {
+	return len(old.Embedding) > 0 &&
+		sym.Revision != "" && old.Revision != nil && *old.Revision == sym.Revision &&
+		old.SymbolType == sym.SymbolType &&
+		valueOf(old.Signature) == sym.Signature &&
+		valueOf(old.DocString) == sym.DocString
+}
+
+// valueOf returns the string s points to, or "" for nil.
+func valueOf(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// saveSymbolDiff applies diff to the stored symbols of a file: changed
+// symbols are re-embedded and replaced, rather than updated so that fields
+// they lost are not kept, moved ones get their new position and keep their
+// embedding, and removed ones are deleted.
+func (idx *Indexer) saveSymbolDiff(ctx context.Context, pi *projectIndexing, projectID, filePath string, diff *symbolDiff) error {
+	if err := idx.storage.DeleteCodeSymbols(ctx, projectID, filePath, diff.stale()); err != nil {
+		return fmt.Errorf("failed to delete changed symbols: %w", err)
+	}
+
+	if err := idx.generateEmbeddings(ctx, pi.embedder, diff.changed); err != nil {
+		slog.Warn("Failed to generate embeddings for some/all changed symbols, saving without embeddings",
+			"file", filePath,
+			"error", err,
+			"symbol_count", len(diff.changed))
+	}
+
+	if err := idx.storage.SaveCodeSymbols(ctx, slices.Concat(diff.changed, diff.moved)); err != nil {
+		return fmt.Errorf("failed to save symbols: %w", err)
+	}
+
+	slog.Debug("Symbols updated incrementally",
+		"file", filePath,
+		"changed", len(diff.changed),
+		"moved", len(diff.moved),
+		"removed", len(diff.removed),
+		"unchanged", diff.unchanged)
+	return nil
+}
+
+// updateSymbolChunks replaces the chunks of the changed and removed symbols
+// of diff. Chunks are relative to their symbol, so moved symbols keep theirs.
+func (idx *Indexer) updateSymbolChunks(ctx context.Context, pi *projectIndexing, projectID, filePath string, diff *symbolDiff) error {
+	for _, namePath := range diff.stale() {
+		if err := idx.storage.DeleteChunksBySymbol(ctx, chunkSymbolID(projectID, filePath, namePath)); err != nil {
+			slog.Warn("failed to delete existing chunks", "symbol", namePath, "error", err)
+		}
+	}
+	return idx.saveSymbolChunks(ctx, pi, projectID, filePath, diff.changed)
+}
@@ -0,0 +1,82 @@
+package indexer
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/madeindigio/remembrances-mcp/internal/storage"
+	"github.com/madeindigio/remembrances-mcp/pkg/treesitter"
+)
+
+func TestDiffSymbols(t *testing.T) {
+	str := func(s string) *string { return &s }
+	storedSymbol := func(namePath, revision string, line int) storage.CodeSymbol {
+		return storage.CodeSymbol{
+			NamePath:   namePath,
+			SymbolType: treesitter.SymbolTypeFunction,
+			StartLine:  line,
+			EndLine:    line + 2,
+			Revision:   str(revision),
+			Signature:  str("func " + namePath + "()"),
+			Embedding:  []float32{0.1},
+		}
+	}
+	extracted := func(namePath, revision string, line int) *treesitter.CodeSymbol {
+		return &treesitter.CodeSymbol{
+			NamePath:   namePath,
+			SymbolType: treesitter.SymbolTypeFunction,
+			StartLine:  line,
+			EndLine:    line + 2,
+			Revision:   revision,
+			Signature:  "func " + namePath + "()",
+		}
+	}
+
+	noEmbedding := storedSymbol("NoEmbedding", "r5", 40)
+	noEmbedding.Embedding = nil
+	stored := []storage.CodeSymbol{
+		storedSymbol("Same", "r1", 1),
+		storedSymbol("Moved", "r2", 10),
+		storedSymbol("Edited", "r3", 20),
+		storedSymbol("Gone", "r4", 30),
+		noEmbedding,
+		storedSymbol("Overload", "r6", 50),
+	}
+	documented := extracted("Same2", "r7", 60)
+	documented.DocString = "Same2 does things."
+	stored = append(stored, storedSymbol("Same2", "r7", 60))
+
+	diff := diffSymbols(stored, []*treesitter.CodeSymbol{
+		extracted("Same", "r1", 1),
+		extracted("Moved", "r2", 12),
+		extracted("Edited", "r3b", 22),
+		extracted("NoEmbedding", "r5", 40),
+		extracted("Overload", "r6", 50),
+		extracted("Overload", "r6b", 55),
+		documented,
+		extracted("New", "r8", 70),
+	})
+
+	var changed, moved []string
+	for _, sym := range diff.changed {
+		changed = append(changed, sym.NamePath)
+	}
+	for _, sym := range diff.moved {
+		moved = append(moved, sym.NamePath)
+	}
+	if want := []string{"Edited", "NoEmbedding", "Overload", "Overload", "Same2", "New"}; !reflect.DeepEqual(changed, want) {
+		t.Errorf("changed = %v, want %v", changed, want)
+	}
+	if want := []string{"Moved"}; !reflect.DeepEqual(moved, want) {
+		t.Errorf("moved = %v, want %v", moved, want)
+	}
+	if want := []string{"Gone"}; !reflect.DeepEqual(diff.removed, want) {
+		t.Errorf("removed = %v, want %v", diff.removed, want)
+	}
+	if diff.unchanged != 1 {
+		t.Errorf("unchanged = %d, want 1", diff.unchanged)
+	}
+	if want := []string{"Gone", "Edited", "NoEmbedding", "Overload", "Overload", "Same2", "New"}; !reflect.DeepEqual(diff.stale(), want) {
+		t.Errorf("stale = %v, want %v", diff.stale(), want)
+	}
+}
@@ -0,0 +1,121 @@
+// Package indexer provides the main indexing service for code projects.
+// This file contains the priority of touched files: files the user is
+// working on are indexed before the rest of the project.
+package indexer
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// hotFileTTL is how long a touched file keeps its priority.
+const hotFileTTL = time.Hour
+
+// Touch marks files of a project, by path relative to its root, as being
+// worked on. The running indexing job of the project, or the next one,
+// indexes them before its other files.
+func (idx *Indexer) Touch(projectID string, relPaths ...string) {
+	idx.hotMu.Lock()
+	defer idx.hotMu.Unlock()
+
+	if idx.hot == nil {
+		idx.hot = make(map[string]map[string]time.Time)
+	}
+	files := idx.hot[projectID]
+	if files == nil {
+		files = make(map[string]time.Time)
+		idx.hot[projectID] = files
+	}
+	now := time.Now()
+	for _, p := range relPaths {
+		p = filepath.ToSlash(filepath.Clean(strings.TrimPrefix(p, "./")))
+		if p != "." && !strings.HasPrefix(p, "../") {
+			files[p] = now
+		}
+	}
+}
+
+// hotFiles returns the files of a project touched within hotFileTTL, most
+// recently touched first. Older ones are forgotten.
+func (idx *Indexer) hotFiles(projectID string) []string {
+	idx.hotMu.Lock()
+	defer idx.hotMu.Unlock()
+
+	files := idx.hot[projectID]
+	if len(files) == 0 {
+		return nil
+	}
+	cutoff := time.Now().Add(-hotFileTTL)
+	paths := make([]string, 0, len(files))
+	for p, touched := range files {
+		if touched.Before(cutoff) {
+			delete(files, p)
+			continue
+		}
+		paths = append(paths, p)
+	}
+	if len(files) == 0 {
+		delete(idx.hot, projectID)
+	}
+	sort.Slice(paths, func(i, j int) bool {
+		return files[paths[i]].After(files[paths[j]])
+	})
+	return paths
+}
+
+// fileQueue hands the files of an indexing job to its workers in scan
+// order, except that files touched while the job runs, or shortly before,
+// are handed out first. It is safe for concurrent use.
+type fileQueue struct {
+	idx       *Indexer
+	projectID string
+
+	mu     sync.Mutex
+	files  []ScannedFile
+	byPath map[string]int // Index in files, by relative path
+	taken  []bool
+	next   int // First file of files that may not be taken yet
+}
+
+// newFileQueue returns the queue of the files of a job of projectID.
+func newFileQueue(idx *Indexer, projectID string, files []ScannedFile) *fileQueue {
+	q := &fileQueue{
+		idx:       idx,
+		projectID: projectID,
+		files:     files,
+		byPath:    make(map[string]int, len(files)),
+		taken:     make([]bool, len(files)),
+	}
+	for i, f := range files {
+		q.byPath[filepath.ToSlash(f.RelPath)] = i
+	}
+	return q
+}
+
+// pop returns the next file to index and whether it was touched, or false
+// once every file was handed out.
+func (q *fileQueue) pop() (file ScannedFile, hot bool, ok bool) {
+	hotFiles := q.idx.hotFiles(q.projectID)
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for _, p := range hotFiles {
+		if i, found := q.byPath[p]; found && !q.taken[i] {
+			q.taken[i] = true
+			return q.files[i], true, true
+		}
+	}
+	for q.next < len(q.files) {
+		i := q.next
+		q.next++
+		if !q.taken[i] {
+			q.taken[i] = true
+			return q.files[i], false, true
+		}
+	}
+	return ScannedFile{}, false, false
+}
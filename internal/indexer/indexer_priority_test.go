@@ -0,0 +1,59 @@
+package indexer
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFileQueueTouchedFirst(t *testing.T) {
+	idx := &Indexer{}
+	files := []ScannedFile{{RelPath: "a.go"}, {RelPath: "b.go"}, {RelPath: "pkg/c.go"}, {RelPath: "d.go"}}
+
+	idx.Touch("p", "./pkg/c.go", "missing.go")
+	idx.Touch("other", "b.go")
+	q := newFileQueue(idx, "p", files)
+
+	pop := func() (string, bool) {
+		t.Helper()
+		file, hot, ok := q.pop()
+		if !ok {
+			t.Fatal("queue exhausted early")
+		}
+		return file.RelPath, hot
+	}
+
+	if path, hot := pop(); path != "pkg/c.go" || !hot {
+		t.Errorf("first = %s (hot %v), want the touched pkg/c.go", path, hot)
+	}
+	if path, hot := pop(); path != "a.go" || hot {
+		t.Errorf("second = %s (hot %v), want a.go in scan order", path, hot)
+	}
+
+	// Touched while the job runs, most recent first; a.go is already taken
+	idx.Touch("p", "d.go")
+	time.Sleep(time.Millisecond)
+	idx.Touch("p", "a.go", "b.go")
+	if path, hot := pop(); path != "b.go" || !hot {
+		t.Errorf("third = %s (hot %v), want the last touched b.go", path, hot)
+	}
+	if path, hot := pop(); path != "d.go" || !hot {
+		t.Errorf("fourth = %s (hot %v), want the touched d.go", path, hot)
+	}
+	if file, _, ok := q.pop(); ok {
+		t.Errorf("queue handed out %s twice", file.RelPath)
+	}
+}
+
+func TestHotFilesExpire(t *testing.T) {
+	idx := &Indexer{}
+	idx.Touch("p", "a.go", "../outside.go")
+	idx.hot["p"]["old.go"] = time.Now().Add(-2 * hotFileTTL)
+
+	hot := idx.hotFiles("p")
+	if len(hot) != 1 || hot[0] != "a.go" {
+		t.Errorf("hotFiles = %v, want [a.go]", hot)
+	}
+	if _, ok := idx.hot["p"]["old.go"]; ok {
+		t.Error("expired file was kept")
+	}
+}
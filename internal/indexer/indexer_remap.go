@@ -0,0 +1,120 @@
+// Package indexer provides the main indexing service for code projects.
+// This file contains the remapping of a project to a new root path.
+package indexer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/madeindigio/remembrances-mcp/internal/storage"
+)
+
+// ErrInvalidRoot is returned by RemapProject when the new root path is not a
+// directory or does not hold the project.
+var ErrInvalidRoot = errors.New("invalid root path")
+
+// RemapProject moves a project to newRoot, as after its checkout was moved
+// or cloned elsewhere. The files found there are matched to the indexed ones
+// by relative path and hash: unchanged files are kept as they are, modified
+// and new files are indexed and files no longer there are removed, so that a
+// full re-index is not needed. The project ID does not change.
+//
+// Nothing is changed when none of the indexed files is found under newRoot,
+// which usually means the path is wrong.
+func (idx *Indexer) RemapProject(ctx context.Context, projectID, newRoot string) (*RemapResult, error) {
+	project, err := idx.storage.GetCodeProject(ctx, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get project: %w", err)
+	}
+	if project == nil {
+		return nil, fmt.Errorf("project %s %w", projectID, storage.ErrNotFound)
+	}
+
+	pi, err := idx.indexingFor(project)
+	if err != nil {
+		return nil, err
+	}
+
+	absRoot, err := filepath.Abs(newRoot)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRoot, err)
+	}
+	info, err := os.Stat(absRoot)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRoot, err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%w: %s is not a directory", ErrInvalidRoot, absRoot)
+	}
+
+	indexedFiles, err := idx.storage.ListCodeFiles(ctx, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list indexed files: %w", err)
+	}
+	indexed := make(map[string]string, len(indexedFiles)) // path -> hash
+	for _, f := range indexedFiles {
+		indexed[f.FilePath] = f.FileHash
+	}
+
+	scanResult, err := idx.config.Scanner.Scan(absRoot)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan root path: %w", err)
+	}
+
+	result := &RemapResult{ProjectID: projectID, OldRoot: project.RootPath, NewRoot: absRoot}
+	var outdated []ScannedFile
+	onDisk := make(map[string]bool, len(scanResult.Files))
+	for _, file := range scanResult.Files {
+		onDisk[file.RelPath] = true
+		hash, ok := indexed[file.RelPath]
+		switch {
+		case !ok:
+			result.Added = append(result.Added, file.RelPath)
+			outdated = append(outdated, file)
+		case hash != file.Hash:
+			result.Modified = append(result.Modified, file.RelPath)
+			outdated = append(outdated, file)
+		default:
+			result.Unchanged++
+		}
+	}
+	for path := range indexed {
+		if !onDisk[path] {
+			result.Removed = append(result.Removed, path)
+		}
+	}
+	sort.Strings(result.Removed)
+
+	if len(indexed) > 0 && len(result.Removed) == len(indexed) {
+		return nil, fmt.Errorf("%w: none of the %d indexed files of project %s is under %s", ErrInvalidRoot, len(indexed), projectID, absRoot)
+	}
+
+	if err := idx.storage.UpdateCodeProjectRoot(ctx, projectID, absRoot); err != nil {
+		return nil, fmt.Errorf("failed to update root path: %w", err)
+	}
+	slog.Info("Remapping project", "project_id", projectID, "from", project.RootPath, "to", absRoot,
+		"unchanged", result.Unchanged, "modified", len(result.Modified), "new", len(result.Added), "removed", len(result.Removed))
+
+	for _, path := range result.Removed {
+		if err := idx.storage.DeleteCodeFile(ctx, projectID, path); err != nil {
+			slog.Warn("failed to delete file from index", "file", path, "error", err)
+			result.Failed = append(result.Failed, path)
+		}
+	}
+	for _, file := range outdated {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		if err := idx.processFile(ctx, pi, projectID, absRoot, file); err != nil {
+			slog.Warn("failed to reindex file", "file", file.RelPath, "error", err)
+			result.Failed = append(result.Failed, file.RelPath)
+		}
+	}
+
+	return result, nil
+}
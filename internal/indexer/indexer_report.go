@@ -0,0 +1,93 @@
+// Package indexer provides the files of a project that could not be indexed.
+package indexer
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/madeindigio/remembrances-mcp/pkg/treesitter"
+)
+
+// Kinds of file issues.
+const (
+	// FileIssueFailed is a file that could not be indexed
+	FileIssueFailed = "failed"
+	// FileIssueSyntaxErrors is a file indexed with syntax errors, whose
+	// symbols in the regions that did not parse are missing
+	FileIssueSyntaxErrors = "syntax_errors"
+)
+
+// FileIssue is a file of a project that was not indexed, or only partly, the
+// last time it was processed by this indexer.
+type FileIssue struct {
+	Path  string    `json:"file_path"`
+	Kind  string    `json:"kind"`
+	Error string    `json:"error"`
+	At    time.Time `json:"at"`
+}
+
+// recordFileIssue records the issue of a file, or clears it when kind is "".
+func (idx *Indexer) recordFileIssue(projectID, relPath, kind, message string) {
+	idx.issuesMu.Lock()
+	defer idx.issuesMu.Unlock()
+
+	if kind == "" {
+		delete(idx.issues[projectID], relPath)
+		return
+	}
+	if idx.issues == nil {
+		idx.issues = make(map[string]map[string]FileIssue)
+	}
+	if idx.issues[projectID] == nil {
+		idx.issues[projectID] = make(map[string]FileIssue)
+	}
+	idx.issues[projectID][relPath] = FileIssue{Path: relPath, Kind: kind, Error: message, At: time.Now()}
+}
+
+// recordSyntaxErrors records the syntax errors of a parsed file, or clears
+// its issue when it has none.
+func (idx *Indexer) recordSyntaxErrors(projectID, relPath string, errs []treesitter.SyntaxError) {
+	if len(errs) == 0 {
+		idx.recordFileIssue(projectID, relPath, "", "")
+		return
+	}
+	first := errs[0]
+	idx.recordFileIssue(projectID, relPath, FileIssueSyntaxErrors,
+		fmt.Sprintf("%d syntax error(s), first at line %d: %s", len(errs), first.Line, first.Message))
+}
+
+// keepFileIssues forgets the issues of the files of a project that are not
+// in files, because they were deleted or are now skipped.
+func (idx *Indexer) keepFileIssues(projectID string, files []ScannedFile) {
+	idx.issuesMu.Lock()
+	defer idx.issuesMu.Unlock()
+
+	issues := idx.issues[projectID]
+	if len(issues) == 0 {
+		return
+	}
+	scanned := make(map[string]bool, len(files))
+	for _, f := range files {
+		scanned[f.RelPath] = true
+	}
+	for path := range issues {
+		if !scanned[path] {
+			delete(issues, path)
+		}
+	}
+}
+
+// FileIssues returns the files of a project that failed to index or had
+// syntax errors since the server started, by path.
+func (idx *Indexer) FileIssues(projectID string) []FileIssue {
+	idx.issuesMu.Lock()
+	defer idx.issuesMu.Unlock()
+
+	issues := make([]FileIssue, 0, len(idx.issues[projectID]))
+	for _, issue := range idx.issues[projectID] {
+		issues = append(issues, issue)
+	}
+	sort.Slice(issues, func(i, j int) bool { return issues[i].Path < issues[j].Path })
+	return issues
+}
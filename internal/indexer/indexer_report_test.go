@@ -0,0 +1,33 @@
+package indexer
+
+import (
+	"testing"
+
+	"github.com/madeindigio/remembrances-mcp/pkg/treesitter"
+)
+
+func TestFileIssues(t *testing.T) {
+	idx := &Indexer{}
+	idx.recordFileIssue("app", "b.go", FileIssueFailed, "failed to parse file")
+	idx.recordSyntaxErrors("app", "a.go", []treesitter.SyntaxError{{Line: 3, Column: 1, Message: `missing ")"`}})
+	idx.recordSyntaxErrors("app", "c.go", nil)
+	idx.recordFileIssue("other", "a.go", FileIssueFailed, "boom")
+
+	issues := idx.FileIssues("app")
+	if len(issues) != 2 || issues[0].Path != "a.go" || issues[0].Kind != FileIssueSyntaxErrors || issues[1].Kind != FileIssueFailed {
+		t.Fatalf("unexpected issues %+v", issues)
+	}
+	if want := `1 syntax error(s), first at line 3: missing ")"`; issues[0].Error != want {
+		t.Errorf("syntax error issue = %q, want %q", issues[0].Error, want)
+	}
+
+	// Parsing a.go again without errors clears its issue, and b.go is gone
+	idx.recordSyntaxErrors("app", "a.go", nil)
+	idx.keepFileIssues("app", []ScannedFile{{RelPath: "a.go"}})
+	if issues := idx.FileIssues("app"); len(issues) != 0 {
+		t.Errorf("expected no issues left, got %+v", issues)
+	}
+	if issues := idx.FileIssues("other"); len(issues) != 1 {
+		t.Errorf("expected the issue of the other project to be kept, got %+v", issues)
+	}
+}
@@ -0,0 +1,82 @@
+// Package indexer provides the main indexing service for code projects.
+// This file contains the indexing settings code projects can override.
+package indexer
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/madeindigio/remembrances-mcp/internal/storage"
+	"github.com/madeindigio/remembrances-mcp/pkg/embedder"
+	"github.com/madeindigio/remembrances-mcp/pkg/treesitter"
+)
+
+// projectIndexing is how the files of a project are indexed: the indexer
+// configuration with the overrides of the project's settings.
+type projectIndexing struct {
+	embedder       embedder.Embedder
+	model          string // Model of embedder; empty for the indexer's own
+	walker         *treesitter.ASTWalker
+	chunkThreshold int // Symbols this long or longer are chunked
+	chunkSize      int
+	chunkOverlap   int
+	incremental    bool // Update only the symbols that changed in indexed files
+}
+
+// SetEmbeddingRouter configures the named embedders code projects can pick
+// with their embedder setting.
+func (idx *Indexer) SetEmbeddingRouter(router *embedder.Router) {
+	idx.embedders = router
+}
+
+// indexingFor returns how the files of project are indexed. A nil project
+// uses the indexer configuration.
+func (idx *Indexer) indexingFor(project *storage.CodeProject) (*projectIndexing, error) {
+	pi := &projectIndexing{
+		embedder:       idx.embedder,
+		walker:         idx.walker,
+		chunkThreshold: ChunkThreshold,
+		chunkSize:      ChunkSize,
+		chunkOverlap:   ChunkOverlap,
+	}
+	if project == nil {
+		return pi, nil
+	}
+	settings := project.CodeProjectSettings
+
+	if settings.Embedder != "" {
+		if idx.embedders == nil {
+			return nil, fmt.Errorf("project %s uses embedder %q but no named embedders are configured", project.ProjectID, settings.Embedder)
+		}
+		route, err := idx.embedders.Named(settings.Embedder)
+		if err != nil {
+			return nil, fmt.Errorf("project %s: %w", project.ProjectID, err)
+		}
+		pi.embedder, pi.model = route.Embedder, route.Model
+	}
+	if settings.MaxSymbolSize > 0 {
+		pi.walker = treesitter.NewASTWalker(treesitter.WalkerConfig{
+			IncludeSourceCode: idx.config.StoreSourceCode,
+			MaxSymbolSize:     settings.MaxSymbolSize,
+		})
+	}
+	if settings.ChunkSize > 0 {
+		pi.chunkThreshold, pi.chunkSize = settings.ChunkSize, settings.ChunkSize
+		if pi.chunkOverlap >= pi.chunkSize {
+			pi.chunkOverlap = pi.chunkSize / 8
+		}
+	}
+	if settings.ChunkOverlap > 0 && settings.ChunkOverlap < pi.chunkSize {
+		pi.chunkOverlap = settings.ChunkOverlap
+	}
+	return pi, nil
+}
+
+// withModel returns a context in which the symbols and chunks stored are
+// recorded as embedded by the model of the project.
+func (pi *projectIndexing) withModel(ctx context.Context) context.Context {
+	if pi.model == "" {
+		return ctx
+	}
+	return storage.WithCodeEmbeddingModel(ctx, pi.model)
+}
@@ -0,0 +1,55 @@
+package indexer
+
+import (
+	"testing"
+
+	"github.com/madeindigio/remembrances-mcp/internal/storage"
+)
+
+func TestIndexingFor(t *testing.T) {
+	idx := &Indexer{config: DefaultIndexerConfig()}
+
+	cases := []struct {
+		name                        string
+		settings                    storage.CodeProjectSettings
+		threshold, size, overlap    int
+		customWalker, expectFailure bool
+	}{
+		{name: "defaults", threshold: ChunkThreshold, size: ChunkSize, overlap: ChunkOverlap},
+		{name: "bigger chunks", settings: storage.CodeProjectSettings{ChunkSize: 2000}, threshold: 2000, size: 2000, overlap: ChunkOverlap},
+		{name: "smaller chunks shrink the default overlap", settings: storage.CodeProjectSettings{ChunkSize: 80}, threshold: 80, size: 80, overlap: 10},
+		{name: "overlap", settings: storage.CodeProjectSettings{ChunkSize: 2000, ChunkOverlap: 300}, threshold: 2000, size: 2000, overlap: 300},
+		{name: "overlap not below the size is ignored", settings: storage.CodeProjectSettings{ChunkOverlap: 900}, threshold: ChunkThreshold, size: ChunkSize, overlap: ChunkOverlap},
+		{name: "max symbol size", settings: storage.CodeProjectSettings{MaxSymbolSize: 5000}, threshold: ChunkThreshold, size: ChunkSize, overlap: ChunkOverlap, customWalker: true},
+		{name: "embedder without router", settings: storage.CodeProjectSettings{Embedder: "small"}, expectFailure: true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			project := &storage.CodeProject{ProjectID: "p", CodeProjectSettings: tc.settings}
+			pi, err := idx.indexingFor(project)
+			if tc.expectFailure {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if pi.chunkThreshold != tc.threshold || pi.chunkSize != tc.size || pi.chunkOverlap != tc.overlap {
+				t.Errorf("chunking = %d/%d/%d, want %d/%d/%d", pi.chunkThreshold, pi.chunkSize, pi.chunkOverlap, tc.threshold, tc.size, tc.overlap)
+			}
+			if (pi.walker != idx.walker) != tc.customWalker {
+				t.Errorf("custom walker = %v, want %v", pi.walker != idx.walker, tc.customWalker)
+			}
+			if pi.model != "" {
+				t.Errorf("model = %q, want the indexer's", pi.model)
+			}
+		})
+	}
+
+	pi, err := idx.indexingFor(nil)
+	if err != nil || pi.chunkSize != ChunkSize {
+		t.Errorf("indexingFor(nil) = %+v, %v", pi, err)
+	}
+}
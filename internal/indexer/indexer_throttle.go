@@ -0,0 +1,84 @@
+// Package indexer provides the main indexing service for code projects.
+// This file contains the throughput controls of indexing: the pacing of
+// embedding calls and the background mode of workers.
+package indexer
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// backgroundNice is the nice value of indexing worker threads in background
+// mode, the lowest priority
+const backgroundNice = 19
+
+// embeddingPacer spaces the embedding calls of indexing so that together they
+// stay under a rate. It is safe for concurrent use.
+type embeddingPacer struct {
+	mu       sync.Mutex
+	interval time.Duration // Between two calls; 0 is unpaced
+	next     time.Time     // Earliest start of the next call
+}
+
+// newEmbeddingPacer returns a pacer allowing qps calls per second, or none
+// limiting them when qps is not positive.
+func newEmbeddingPacer(qps float64) *embeddingPacer {
+	p := &embeddingPacer{}
+	if qps > 0 {
+		p.interval = time.Duration(float64(time.Second) / qps)
+	}
+	return p
+}
+
+// wait blocks until the caller may make an embedding call, or ctx is done.
+// A nil pacer does not limit calls.
+func (p *embeddingPacer) wait(ctx context.Context) error {
+	if p == nil || p.interval <= 0 {
+		return ctx.Err()
+	}
+	p.mu.Lock()
+	now := time.Now()
+	start := p.next
+	if start.Before(now) {
+		start = now
+	}
+	p.next = start.Add(p.interval)
+	p.mu.Unlock()
+
+	delay := time.Until(start)
+	if delay <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// workers returns the number of file workers of an indexing job. Background
+// mode uses a single one where worker threads cannot be deprioritized.
+func (idx *Indexer) workers() int {
+	if idx.config.Background && !canLowerThreadPriority {
+		return 1
+	}
+	return max(idx.config.Concurrency, 1)
+}
+
+// startWorker prepares the goroutine of an indexing worker. In background
+// mode it gets an OS thread of its own at the lowest priority, which the
+// tree-sitter and local embedding calls it makes run on. The thread is never
+// unlocked, so the runtime discards it when the worker ends instead of
+// handing the lowered priority to other goroutines.
+func (idx *Indexer) startWorker() {
+	if !idx.config.Background || !canLowerThreadPriority {
+		return
+	}
+	runtime.LockOSThread()
+	lowerThreadPriority(backgroundNice)
+}
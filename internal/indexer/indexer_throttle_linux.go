@@ -0,0 +1,19 @@
+//go:build linux
+
+package indexer
+
+import (
+	"log/slog"
+	"syscall"
+)
+
+// canLowerThreadPriority reports whether the priority of a single thread can
+// be lowered: on Linux, setpriority applies to a thread ID.
+const canLowerThreadPriority = true
+
+// lowerThreadPriority sets the nice value of the calling OS thread.
+func lowerThreadPriority(nice int) {
+	if err := syscall.Setpriority(syscall.PRIO_PROCESS, syscall.Gettid(), nice); err != nil {
+		slog.Warn("failed to lower the priority of an indexing worker", "error", err)
+	}
+}
@@ -0,0 +1,10 @@
+//go:build !linux
+
+package indexer
+
+// canLowerThreadPriority reports whether the priority of a single thread can
+// be lowered, which elsewhere would apply to the whole process.
+const canLowerThreadPriority = false
+
+// lowerThreadPriority does nothing where thread priorities are not supported.
+func lowerThreadPriority(int) {}
@@ -0,0 +1,49 @@
+package indexer
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestEmbeddingPacer(t *testing.T) {
+	ctx := context.Background()
+
+	unpaced := newEmbeddingPacer(0)
+	start := time.Now()
+	for i := 0; i < 100; i++ {
+		if err := unpaced.wait(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+		t.Errorf("unpaced calls took %v", elapsed)
+	}
+
+	paced := newEmbeddingPacer(100)
+	start = time.Now()
+	for i := 0; i < 5; i++ {
+		if err := paced.wait(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// The first call is immediate, the next four 10ms apart
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+		t.Errorf("5 calls at 100 per second took %v, want at least 40ms", elapsed)
+	}
+
+	slow := newEmbeddingPacer(0.1)
+	if err := slow.wait(ctx); err != nil {
+		t.Fatal(err)
+	}
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if err := slow.wait(cancelled); err == nil {
+		t.Error("wait returned no error for a cancelled context")
+	}
+
+	var none *embeddingPacer
+	if err := none.wait(ctx); err != nil {
+		t.Errorf("nil pacer: %v", err)
+	}
+}
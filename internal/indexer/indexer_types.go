@@ -4,6 +4,7 @@ package indexer
 import (
 	"time"
 
+	"github.com/madeindigio/remembrances-mcp/internal/watchqueue"
 	"github.com/madeindigio/remembrances-mcp/pkg/treesitter"
 )
 
@@ -21,8 +22,27 @@ type IndexerConfig struct {
 	// Maximum source code length to store
 	MaxSourceCodeLength int
 
+	// Whether to store compressed file contents, so search and read tools
+	// work without access to the project checkout
+	StoreFileContents bool
+
 	// Scanner configuration
 	Scanner *FileScanner
+
+	// Debounce and budget of the code watcher
+	WatchQueue watchqueue.Config
+
+	// Maximum number of files of a project an indexing job indexes; larger
+	// projects fail before any file is processed. 0 is unlimited
+	MaxFilesPerJob int
+
+	// Embedding calls per second made by indexing, across all jobs. 0 is
+	// unlimited
+	EmbeddingQPS float64
+
+	// Whether indexing workers run at the lowest CPU priority, so the tools
+	// stay responsive while a large project is indexed
+	Background bool
 }
 
 // DefaultIndexerConfig returns sensible defaults
@@ -33,18 +53,45 @@ func DefaultIndexerConfig() IndexerConfig {
 		StoreSourceCode:     true,
 		MaxSourceCodeLength: 10000,
 		Scanner:             NewFileScanner(),
+		WatchQueue:          watchqueue.DefaultConfig(),
 	}
 }
 
+// IndexingLimits are the effective throughput controls and resource limits
+// of indexing jobs. Zero limits are unlimited.
+type IndexingLimits struct {
+	Workers           int     `json:"workers"`
+	MaxConcurrentJobs int     `json:"max_concurrent_jobs"`
+	MaxFileSize       int64   `json:"max_file_size"`
+	MaxFilesPerJob    int     `json:"max_files_per_job"`
+	EmbeddingQPS      float64 `json:"embedding_qps"`
+	Background        bool    `json:"background"`
+}
+
+// RemapResult reports what remapping a project to a new root path changed.
+// Unchanged files keep their symbols and embeddings; the others are listed
+// by path relative to the root.
+type RemapResult struct {
+	ProjectID string   `json:"project_id"`
+	OldRoot   string   `json:"old_root_path"`
+	NewRoot   string   `json:"new_root_path"`
+	Unchanged int      `json:"unchanged"`
+	Modified  []string `json:"modified,omitempty"`
+	Added     []string `json:"added,omitempty"`
+	Removed   []string `json:"removed,omitempty"`
+	Failed    []string `json:"failed,omitempty"`
+}
+
 // IndexingProgress tracks the progress of an indexing operation
 type IndexingProgress struct {
-	ProjectID    string
-	Status       treesitter.IndexingStatus
-	FilesTotal   int
-	FilesIndexed int
-	SymbolsFound int
-	CurrentFile  string
-	StartedAt    time.Time
-	UpdatedAt    time.Time
-	Error        *string
+	ProjectID        string
+	Status           treesitter.IndexingStatus
+	FilesTotal       int
+	FilesIndexed     int
+	FilesPrioritized int // Indexed ahead of the others because they were touched
+	SymbolsFound     int
+	CurrentFile      string
+	StartedAt        time.Time
+	UpdatedAt        time.Time
+	Error            *string
 }
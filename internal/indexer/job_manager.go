@@ -10,6 +10,7 @@ import (
 
 	"github.com/madeindigio/remembrances-mcp/internal/storage"
 	"github.com/madeindigio/remembrances-mcp/pkg/embedder"
+	"github.com/madeindigio/remembrances-mcp/pkg/tenancy"
 	"github.com/madeindigio/remembrances-mcp/pkg/treesitter"
 )
 
@@ -26,6 +27,7 @@ type JobManager struct {
 	// Job queue
 	jobQueue chan *Job
 	quit     chan struct{}
+	quitOnce sync.Once
 	wg       sync.WaitGroup
 
 	// Configuration
@@ -34,19 +36,46 @@ type JobManager struct {
 
 // Job represents an indexing job
 type Job struct {
-	ID           string
-	ProjectID    string
-	ProjectPath  string
-	ProjectName  string
-	Status       treesitter.IndexingStatus
-	Progress     float64
-	FilesTotal   int
-	FilesIndexed int
-	SymbolsFound int
-	StartedAt    time.Time
-	CompletedAt  *time.Time
-	Error        *string
-	CreatedAt    time.Time
+	ID               string
+	ProjectID        string
+	ProjectPath      string
+	ProjectName      string
+	Status           treesitter.IndexingStatus
+	Progress         float64
+	FilesTotal       int
+	FilesIndexed     int
+	FilesPrioritized int
+	SymbolsFound     int
+	StartedAt        time.Time
+	CompletedAt      *time.Time
+	Error            *string
+	CreatedAt        time.Time
+	Limits           IndexingLimits // Effective limits the job runs with
+	// Tenant whose database the job indexes into, nil for the default one.
+	// Only calls of the same tenant see the job.
+	Tenant *tenancy.Tenant
+}
+
+// tenantContext returns ctx carrying t, or ctx itself when t is nil.
+func tenantContext(ctx context.Context, t *tenancy.Tenant) context.Context {
+	if t == nil {
+		return ctx
+	}
+	return tenancy.WithTenant(ctx, t)
+}
+
+// tenantOf returns the tenant of ctx, nil when it has none.
+func tenantOf(ctx context.Context) *tenancy.Tenant {
+	t, _ := tenancy.FromContext(ctx)
+	return t
+}
+
+// sameTenant reports whether a and b are the same tenant, or both nil.
+func sameTenant(a, b *tenancy.Tenant) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.ID == b.ID
 }
 
 // JobManagerConfig holds configuration for the job manager
@@ -86,8 +115,9 @@ func NewJobManager(storage storage.FullStorage, embedder embedder.Embedder, inde
 	return jm
 }
 
-// SubmitJob submits a new indexing job
-func (jm *JobManager) SubmitJob(projectPath, projectName string) (*Job, error) {
+// SubmitJob submits a new indexing job, which indexes into the database of
+// the tenant of ctx
+func (jm *JobManager) SubmitJob(ctx context.Context, projectPath, projectName string) (*Job, error) {
 	// Generate job ID
 	jobID := fmt.Sprintf("job_%d", time.Now().UnixNano())
 
@@ -97,6 +127,8 @@ func (jm *JobManager) SubmitJob(projectPath, projectName string) (*Job, error) {
 		ProjectName: projectName,
 		Status:      treesitter.IndexingStatusPending,
 		CreatedAt:   time.Now(),
+		Limits:      jm.Limits(),
+		Tenant:      tenantOf(ctx),
 	}
 
 	jm.mu.Lock()
@@ -125,6 +157,12 @@ func (jm *JobManager) worker() {
 		case <-jm.quit:
 			return
 		case job := <-jm.jobQueue:
+			// Both may be ready: do not start a job once stopping
+			select {
+			case <-jm.quit:
+				return
+			default:
+			}
 			jm.processJob(job)
 		}
 	}
@@ -132,7 +170,7 @@ func (jm *JobManager) worker() {
 
 // processJob executes an indexing job
 func (jm *JobManager) processJob(job *Job) {
-	ctx, cancel := context.WithCancel(context.Background())
+	ctx, cancel := context.WithCancel(tenantContext(context.Background(), job.Tenant))
 
 	jm.mu.Lock()
 	jm.running[job.ID] = cancel
@@ -171,6 +209,7 @@ func (jm *JobManager) processJob(job *Job) {
 		if progress := jm.indexer.GetProgress(projectID); progress != nil {
 			job.FilesTotal = progress.FilesTotal
 			job.FilesIndexed = progress.FilesIndexed
+			job.FilesPrioritized = progress.FilesPrioritized
 			job.SymbolsFound = progress.SymbolsFound
 		}
 
@@ -208,15 +247,15 @@ func (jm *JobManager) GetJob(jobID string) *Job {
 	return nil
 }
 
-// GetJobStatus returns the current status of a job
-func (jm *JobManager) GetJobStatus(jobID string) (*Job, error) {
+// GetJobStatus returns the current status of a job of the tenant of ctx
+func (jm *JobManager) GetJobStatus(ctx context.Context, jobID string) (*Job, error) {
 	jm.mu.RLock()
 	job, exists := jm.jobs[jobID]
 	jm.mu.RUnlock()
 
-	if !exists {
+	if !exists || !sameTenant(job.Tenant, tenantOf(ctx)) {
 		// Try to find in database
-		dbJob, err := jm.storage.GetIndexingJob(context.Background(), jobID)
+		dbJob, err := jm.storage.GetIndexingJob(ctx, jobID)
 		if err != nil {
 			return nil, fmt.Errorf("failed to get job: %w", err)
 		}
@@ -243,6 +282,7 @@ func (jm *JobManager) GetJobStatus(jobID string) (*Job, error) {
 		if progress := jm.indexer.GetProgress(job.ProjectID); progress != nil {
 			job.FilesTotal = progress.FilesTotal
 			job.FilesIndexed = progress.FilesIndexed
+			job.FilesPrioritized = progress.FilesPrioritized
 			job.SymbolsFound = progress.SymbolsFound
 			if progress.FilesTotal > 0 {
 				job.Progress = float64(progress.FilesIndexed) / float64(progress.FilesTotal) * 100
@@ -254,13 +294,17 @@ func (jm *JobManager) GetJobStatus(jobID string) (*Job, error) {
 	return &copy, nil
 }
 
-// ListActiveJobs returns all currently active jobs
-func (jm *JobManager) ListActiveJobs() []*Job {
+// ListActiveJobs returns the currently active jobs of the tenant of ctx
+func (jm *JobManager) ListActiveJobs(ctx context.Context) []*Job {
 	jm.mu.RLock()
 	defer jm.mu.RUnlock()
 
+	tenant := tenantOf(ctx)
 	jobs := make([]*Job, 0)
 	for _, job := range jm.jobs {
+		if !sameTenant(job.Tenant, tenant) {
+			continue
+		}
 		if job.Status == treesitter.IndexingStatusPending ||
 			job.Status == treesitter.IndexingStatusInProgress {
 			copy := *job
@@ -309,18 +353,46 @@ func (jm *JobManager) CancelJob(jobID string) error {
 
 // Stop gracefully stops the job manager
 func (jm *JobManager) Stop() {
-	close(jm.quit)
+	jm.quitOnce.Do(func() { close(jm.quit) })
+	jm.cancelRunning()
+
+	// Wait for workers to finish
+	jm.wg.Wait()
+	slog.Info("Job manager stopped")
+}
+
+// Shutdown stops starting queued jobs and waits for the running ones to
+// finish, cancelling them like Stop when ctx is done first. Queued jobs are
+// dropped.
+func (jm *JobManager) Shutdown(ctx context.Context) error {
+	jm.quitOnce.Do(func() { close(jm.quit) })
 
-	// Cancel all running jobs
+	done := make(chan struct{})
+	go func() {
+		jm.wg.Wait()
+		close(done)
+	}()
+
+	var err error
+	select {
+	case <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+		slog.Warn("Cancelling indexing jobs still running at shutdown")
+		jm.cancelRunning()
+		<-done
+	}
+	slog.Info("Job manager stopped")
+	return err
+}
+
+// cancelRunning cancels the jobs being processed.
+func (jm *JobManager) cancelRunning() {
 	jm.mu.Lock()
+	defer jm.mu.Unlock()
 	for _, cancel := range jm.running {
 		cancel()
 	}
-	jm.mu.Unlock()
-
-	// Wait for workers to finish
-	jm.wg.Wait()
-	slog.Info("Job manager stopped")
 }
 
 // CleanupOldJobs removes completed jobs older than the specified duration
@@ -350,6 +422,13 @@ func (jm *JobManager) GetIndexer() *Indexer {
 	return jm.indexer
 }
 
+// Limits returns the effective limits of the indexing jobs.
+func (jm *JobManager) Limits() IndexingLimits {
+	limits := jm.indexer.Limits()
+	limits.MaxConcurrentJobs = jm.maxConcurrentJobs
+	return limits
+}
+
 // ReindexProject queues a project for re-indexing
 func (jm *JobManager) ReindexProject(ctx context.Context, projectID string) (*Job, error) {
 	// Get project info
@@ -362,5 +441,5 @@ func (jm *JobManager) ReindexProject(ctx context.Context, projectID string) (*Jo
 	}
 
 	// Submit new job
-	return jm.SubmitJob(project.RootPath, project.Name)
+	return jm.SubmitJob(ctx, project.RootPath, project.Name)
 }
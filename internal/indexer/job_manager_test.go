@@ -0,0 +1,41 @@
+package indexer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/madeindigio/remembrances-mcp/internal/storage"
+	"github.com/madeindigio/remembrances-mcp/pkg/tenancy"
+)
+
+func TestJobManager_TenantJobs(t *testing.T) {
+	// No workers, so the jobs stay queued
+	jm := NewJobManager(&storage.SurrealDBStorage{}, nil, DefaultIndexerConfig(), JobManagerConfig{QueueSize: 2})
+	t.Cleanup(jm.Stop)
+
+	teamA := &tenancy.Tenant{ID: "team-a"}
+	job, err := jm.SubmitJob(tenancy.WithTenant(context.Background(), teamA), t.TempDir(), "app")
+	if err != nil {
+		t.Fatalf("SubmitJob failed: %v", err)
+	}
+	if job.Tenant != teamA {
+		t.Errorf("job tenant = %v, want team-a", job.Tenant)
+	}
+	if _, err := jm.SubmitJob(context.Background(), t.TempDir(), "default"); err != nil {
+		t.Fatalf("SubmitJob failed: %v", err)
+	}
+
+	for _, tt := range []struct {
+		name    string
+		ctx     context.Context
+		project string
+	}{
+		{"team-a", tenancy.WithTenant(context.Background(), &tenancy.Tenant{ID: "team-a"}), "app"},
+		{"default", context.Background(), "default"},
+	} {
+		jobs := jm.ListActiveJobs(tt.ctx)
+		if len(jobs) != 1 || jobs[0].ProjectName != tt.project {
+			t.Errorf("%s active jobs = %+v, want only the %s job", tt.name, jobs, tt.project)
+		}
+	}
+}
@@ -7,20 +7,33 @@ import (
 	"log/slog"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/agnivade/levenshtein"
 	"github.com/madeindigio/remembrances-mcp/internal/storage"
+	"github.com/madeindigio/remembrances-mcp/pkg/tenancy"
 )
 
 // WatcherManager manages a single active code watcher.
 // Only ONE code project can be actively monitored at a time.
 // This is a resource constraint to prevent system overload.
+// The watcher indexes into the database of the tenant that activated it,
+// and only calls of that tenant see it as active.
 type WatcherManager struct {
 	mu            sync.RWMutex
 	activeWatcher *CodeWatcher
 	activeProject string
+	activeTenant  *tenancy.Tenant
 	indexer       *Indexer
 	storage       storage.FullStorage
+	resumed       map[string]resumeResult
+}
+
+// resumeResult records the outcome of resuming a project's watcher at startup.
+type resumeResult struct {
+	at       time.Time
+	outdated int
+	err      string
 }
 
 // NewWatcherManager creates a new watcher manager.
@@ -28,6 +41,7 @@ func NewWatcherManager(indexer *Indexer, storage storage.FullStorage) *WatcherMa
 	return &WatcherManager{
 		indexer: indexer,
 		storage: storage,
+		resumed: make(map[string]resumeResult),
 	}
 }
 
@@ -100,7 +114,7 @@ func (wm *WatcherManager) ActivateProject(ctx context.Context, projectID string)
 	var previousProject string
 
 	// Check if the same project is already active
-	if wm.activeProject == projectID && wm.activeWatcher != nil {
+	if wm.isActive(ctx, projectID) && wm.activeWatcher != nil {
 		return 0, "", nil // Already active
 	}
 
@@ -138,15 +152,16 @@ func (wm *WatcherManager) ActivateProject(ctx context.Context, projectID string)
 	}
 
 	// Deactivate current watcher if different project
-	if wm.activeWatcher != nil && wm.activeProject != projectID {
+	if wm.activeWatcher != nil && !wm.isActive(ctx, projectID) {
 		previousProject = wm.activeProject
 		wm.activeWatcher.Stop()
-		// Update the previous project's watcher status
-		if err := wm.storage.UpdateProjectWatcher(ctx, wm.activeProject, false); err != nil {
+		// Update the previous project's watcher status, in its tenant's database
+		if err := wm.storage.UpdateProjectWatcher(tenantContext(context.WithoutCancel(ctx), wm.activeTenant), wm.activeProject, false); err != nil {
 			slog.Warn("failed to update previous project watcher status", "project_id", wm.activeProject, "error", err)
 		}
 		wm.activeWatcher = nil
 		wm.activeProject = ""
+		wm.activeTenant = nil
 	}
 
 	// Start new watcher
@@ -157,6 +172,9 @@ func (wm *WatcherManager) ActivateProject(ctx context.Context, projectID string)
 
 	wm.activeWatcher = watcher
 	wm.activeProject = projectID
+	wm.activeTenant = tenantOf(ctx)
+	// A manual activation supersedes any startup resume result
+	delete(wm.resumed, projectID)
 
 	// Update project watcher status in storage
 	if err := wm.storage.UpdateProjectWatcher(ctx, projectID, true); err != nil {
@@ -172,11 +190,7 @@ func (wm *WatcherManager) ActivateProject(ctx context.Context, projectID string)
 
 	// Process outdated files in background
 	if len(outdatedFiles) > 0 {
-		go func() {
-			if err := watcher.ProcessOutdatedFiles(context.Background(), outdatedFiles); err != nil {
-				slog.Warn("failed to process outdated files", "project_id", projectID, "error", err)
-			}
-		}()
+		watcher.ProcessOutdatedFilesInBackground(outdatedFiles)
 	}
 
 	slog.Info("project watch activated",
@@ -194,7 +208,7 @@ func (wm *WatcherManager) DeactivateProject(ctx context.Context, projectID strin
 	defer wm.mu.Unlock()
 
 	// If no project specified, deactivate current
-	if projectID == "" {
+	if projectID == "" && sameTenant(wm.activeTenant, tenantOf(ctx)) {
 		projectID = wm.activeProject
 	}
 
@@ -203,7 +217,7 @@ func (wm *WatcherManager) DeactivateProject(ctx context.Context, projectID strin
 	}
 
 	// Check if this is the active project
-	if wm.activeProject != projectID {
+	if !wm.isActive(ctx, projectID) {
 		return "", fmt.Errorf("project %s is not being watched", projectID)
 	}
 
@@ -215,6 +229,7 @@ func (wm *WatcherManager) DeactivateProject(ctx context.Context, projectID strin
 		wm.activeWatcher = nil
 	}
 	wm.activeProject = ""
+	wm.activeTenant = nil
 
 	// Update project watcher status in storage
 	if err := wm.storage.UpdateProjectWatcher(ctx, deactivatedProject, false); err != nil {
@@ -231,18 +246,29 @@ func (wm *WatcherManager) DeactivateCurrent(ctx context.Context) (string, error)
 	return wm.DeactivateProject(ctx, "")
 }
 
-// GetActiveProject returns the ID of the currently watched project.
-func (wm *WatcherManager) GetActiveProject() string {
+// GetActiveProject returns the ID of the project watched for the tenant of
+// ctx, empty when it has none.
+func (wm *WatcherManager) GetActiveProject(ctx context.Context) string {
 	wm.mu.RLock()
 	defer wm.mu.RUnlock()
+	if !sameTenant(wm.activeTenant, tenantOf(ctx)) {
+		return ""
+	}
 	return wm.activeProject
 }
 
-// IsProjectActive returns true if the specified project is currently being watched.
-func (wm *WatcherManager) IsProjectActive(projectID string) bool {
+// IsProjectActive returns true if the specified project of the tenant of ctx
+// is currently being watched.
+func (wm *WatcherManager) IsProjectActive(ctx context.Context, projectID string) bool {
 	wm.mu.RLock()
 	defer wm.mu.RUnlock()
-	return wm.activeProject == projectID
+	return wm.isActive(ctx, projectID)
+}
+
+// isActive reports whether projectID of the tenant of ctx is the watched
+// project. Callers must hold wm.mu.
+func (wm *WatcherManager) isActive(ctx context.Context, projectID string) bool {
+	return wm.activeProject == projectID && sameTenant(wm.activeTenant, tenantOf(ctx))
 }
 
 // Stop stops any active watcher and cleans up resources.
@@ -256,43 +282,118 @@ func (wm *WatcherManager) Stop() error {
 		wm.activeWatcher = nil
 	}
 	wm.activeProject = ""
+	wm.activeTenant = nil
 
 	slog.Info("watcher manager stopped")
 	return nil
 }
 
-// AutoActivateOnStartup looks for any project with WatcherEnabled=true
-// and activates monitoring for it. Should be called at application startup.
+// Shutdown stops the active watcher like Stop, letting it finish the file
+// being reindexed and the changes it has queued (see CodeWatcher.Shutdown).
+func (wm *WatcherManager) Shutdown(ctx context.Context) error {
+	wm.mu.Lock()
+	defer wm.mu.Unlock()
+
+	var err error
+	if wm.activeWatcher != nil {
+		err = wm.activeWatcher.Shutdown(ctx)
+		wm.activeWatcher = nil
+	}
+	wm.activeProject = ""
+	wm.activeTenant = nil
+
+	slog.Info("watcher manager stopped")
+	return err
+}
+
+// AutoActivateOnStartup resumes monitoring for the projects stored with
+// WatcherEnabled=true. Should be called at application startup.
+// Only one project can be watched, so the first one that starts is kept and
+// the others are reported as not resumed. Projects that fail to resume keep
+// WatcherEnabled so they are retried on the next startup.
 func (wm *WatcherManager) AutoActivateOnStartup(ctx context.Context) error {
 	projects, err := wm.storage.ListCodeProjects(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to list projects: %w", err)
 	}
 
+	resumed := ""
 	for _, project := range projects {
-		if project.WatcherEnabled {
-			slog.Info("auto-activating watcher for project", "project_id", project.ProjectID)
-			_, _, err := wm.ActivateProject(ctx, project.ProjectID)
-			if err != nil {
-				slog.Warn("failed to auto-activate watcher",
-					"project_id", project.ProjectID,
-					"error", err)
-				// Continue, don't fail startup for this
-				continue
-			}
-			// Only activate one project (the first one found with WatcherEnabled)
-			break
+		if !project.WatcherEnabled {
+			continue
+		}
+
+		if resumed != "" {
+			slog.Warn("not resuming watcher, only one project can be watched",
+				"project_id", project.ProjectID,
+				"watching", resumed)
+			wm.recordResume(project.ProjectID, 0, fmt.Errorf("only one project can be watched; resumed %s", resumed))
+			continue
 		}
+
+		outdated, _, err := wm.ActivateProject(ctx, project.ProjectID)
+		wm.recordResume(project.ProjectID, outdated, err)
+		if err != nil {
+			slog.Warn("failed to resume watcher",
+				"project_id", project.ProjectID,
+				"error", err)
+			// Continue, don't fail startup for this
+			continue
+		}
+
+		slog.Info("resumed watcher for project",
+			"project_id", project.ProjectID,
+			"path", project.RootPath,
+			"outdated_files", outdated)
+		resumed = project.ProjectID
 	}
 
 	return nil
 }
 
+// recordResume stores the outcome of resuming a project's watcher.
+func (wm *WatcherManager) recordResume(projectID string, outdated int, err error) {
+	result := resumeResult{at: time.Now(), outdated: outdated}
+	if err != nil {
+		result.err = err.Error()
+	}
+
+	wm.mu.Lock()
+	wm.resumed[projectID] = result
+	wm.mu.Unlock()
+}
+
 // GetWatchStatus returns the watch status for a project or all projects.
+// The resume fields are set for projects whose watcher was resumed, or
+// failed to resume, when the server started.
 type WatchStatus struct {
-	ProjectID      string `json:"project_id"`
-	WatcherEnabled bool   `json:"watcher_enabled"`
-	IsActive       bool   `json:"is_active"`
+	ProjectID       string     `json:"project_id"`
+	WatcherEnabled  bool       `json:"watcher_enabled"`
+	IsActive        bool       `json:"is_active"`
+	ResumedAt       *time.Time `json:"resumed_at,omitempty"`
+	ResumedOutdated int        `json:"resumed_outdated_files,omitempty"`
+	ResumeError     string     `json:"resume_error,omitempty"`
+}
+
+// newWatchStatus builds the status of a project of the tenant of ctx.
+// Callers must hold wm.mu.
+func (wm *WatcherManager) newWatchStatus(ctx context.Context, project storage.CodeProject) WatchStatus {
+	status := WatchStatus{
+		ProjectID:      project.ProjectID,
+		WatcherEnabled: project.WatcherEnabled,
+		IsActive:       wm.isActive(ctx, project.ProjectID),
+	}
+	// Watchers are only resumed for projects of the default database
+	if result, ok := wm.resumed[project.ProjectID]; ok && tenantOf(ctx) == nil {
+		if result.err != "" {
+			status.ResumeError = result.err
+		} else {
+			at := result.at
+			status.ResumedAt = &at
+			status.ResumedOutdated = result.outdated
+		}
+	}
+	return status
 }
 
 // GetProjectWatchStatus returns the watch status for a specific project.
@@ -306,14 +407,10 @@ func (wm *WatcherManager) GetProjectWatchStatus(ctx context.Context, projectID s
 	}
 
 	wm.mu.RLock()
-	isActive := wm.activeProject == projectID
+	status := wm.newWatchStatus(ctx, *project)
 	wm.mu.RUnlock()
 
-	return &WatchStatus{
-		ProjectID:      project.ProjectID,
-		WatcherEnabled: project.WatcherEnabled,
-		IsActive:       isActive,
-	}, nil
+	return &status, nil
 }
 
 // GetAllWatchStatus returns the watch status for all projects.
@@ -324,16 +421,11 @@ func (wm *WatcherManager) GetAllWatchStatus(ctx context.Context) ([]WatchStatus,
 	}
 
 	wm.mu.RLock()
-	activeProject := wm.activeProject
-	wm.mu.RUnlock()
+	defer wm.mu.RUnlock()
 
 	statuses := make([]WatchStatus, len(projects))
 	for i, project := range projects {
-		statuses[i] = WatchStatus{
-			ProjectID:      project.ProjectID,
-			WatcherEnabled: project.WatcherEnabled,
-			IsActive:       project.ProjectID == activeProject,
-		}
+		statuses[i] = wm.newWatchStatus(ctx, project)
 	}
 
 	return statuses, nil
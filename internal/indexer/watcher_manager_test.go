@@ -0,0 +1,133 @@
+package indexer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/madeindigio/remembrances-mcp/internal/storage"
+	"github.com/madeindigio/remembrances-mcp/pkg/tenancy"
+)
+
+func TestWatcherManager_AutoActivateOnStartup_ResumesEnabledProject(t *testing.T) {
+	tempDir := t.TempDir()
+	mustWriteWatcherFile(t, filepath.Join(tempDir, "main.go"), "package main\nfunc main() {}\n")
+
+	st := &resumeTestStorage{
+		SurrealDBStorage: &storage.SurrealDBStorage{},
+		projects: []storage.CodeProject{
+			{ProjectID: "idle", RootPath: tempDir},
+			{ProjectID: "missing", RootPath: filepath.Join(tempDir, "gone"), WatcherEnabled: true},
+			{ProjectID: "watched", RootPath: tempDir, WatcherEnabled: true},
+			{ProjectID: "extra", RootPath: tempDir, WatcherEnabled: true},
+		},
+	}
+	wm := NewWatcherManager(NewIndexer(st, nil, DefaultIndexerConfig()), st)
+	t.Cleanup(func() { _ = wm.Stop() })
+
+	if err := wm.AutoActivateOnStartup(context.Background()); err != nil {
+		t.Fatalf("AutoActivateOnStartup failed: %v", err)
+	}
+
+	if got := wm.GetActiveProject(context.Background()); got != "watched" {
+		t.Fatalf("active project = %q, want %q", got, "watched")
+	}
+
+	statuses, err := wm.GetAllWatchStatus(context.Background())
+	if err != nil {
+		t.Fatalf("GetAllWatchStatus failed: %v", err)
+	}
+	byID := make(map[string]WatchStatus)
+	for _, status := range statuses {
+		byID[status.ProjectID] = status
+	}
+
+	if s := byID["watched"]; s.ResumedAt == nil || s.ResumedOutdated != 1 || s.ResumeError != "" {
+		t.Fatalf("watched status = %+v, want resumed with 1 outdated file", s)
+	}
+	if s := byID["missing"]; s.ResumeError == "" || s.ResumedAt != nil {
+		t.Fatalf("missing status = %+v, want a resume error", s)
+	}
+	if s := byID["extra"]; s.ResumeError == "" {
+		t.Fatalf("extra status = %+v, want a resume error", s)
+	}
+	if s := byID["idle"]; s.ResumedAt != nil || s.ResumeError != "" {
+		t.Fatalf("idle status = %+v, want no resume fields", s)
+	}
+}
+
+func TestWatcherManager_TenantWatcher(t *testing.T) {
+	tempDir := t.TempDir()
+	mustWriteWatcherFile(t, filepath.Join(tempDir, "main.go"), "package main\nfunc main() {}\n")
+
+	st := &resumeTestStorage{
+		SurrealDBStorage: &storage.SurrealDBStorage{},
+		projects:         []storage.CodeProject{{ProjectID: "app", RootPath: tempDir}},
+	}
+	wm := NewWatcherManager(NewIndexer(st, nil, DefaultIndexerConfig()), st)
+	t.Cleanup(func() { _ = wm.Stop() })
+
+	teamA := tenancy.WithTenant(context.Background(), &tenancy.Tenant{ID: "team-a"})
+	if _, _, err := wm.ActivateProject(teamA, "app"); err != nil {
+		t.Fatalf("ActivateProject failed: %v", err)
+	}
+	if !wm.IsProjectActive(teamA, "app") || wm.GetActiveProject(teamA) != "app" {
+		t.Errorf("team-a does not see its watcher")
+	}
+	if wm.IsProjectActive(context.Background(), "app") || wm.GetActiveProject(context.Background()) != "" {
+		t.Errorf("the default database sees the watcher of team-a")
+	}
+	if _, err := wm.DeactivateProject(context.Background(), "app"); err == nil {
+		t.Errorf("the default database deactivated the watcher of team-a")
+	}
+
+	// Taking the watcher over clears the watcher status in team-a's database
+	if _, previous, err := wm.ActivateProject(context.Background(), "app"); err != nil || previous != "app" {
+		t.Fatalf("ActivateProject = previous %q, %v; want the project of team-a", previous, err)
+	}
+	want := []string{"team-a app true", "team-a app false", " app true"}
+	if !reflect.DeepEqual(st.updates, want) {
+		t.Errorf("watcher status updates = %q, want %q", st.updates, want)
+	}
+}
+
+type resumeTestStorage struct {
+	*storage.SurrealDBStorage
+	projects []storage.CodeProject
+	// updates records the watcher status updates as "<tenant> <project> <enabled>"
+	updates []string
+}
+
+func (s *resumeTestStorage) ListCodeProjects(ctx context.Context) ([]storage.CodeProject, error) {
+	return s.projects, nil
+}
+
+func (s *resumeTestStorage) GetCodeProject(ctx context.Context, projectID string) (*storage.CodeProject, error) {
+	for i := range s.projects {
+		if s.projects[i].ProjectID == projectID {
+			return &s.projects[i], nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *resumeTestStorage) UpdateProjectWatcher(ctx context.Context, projectID string, enabled bool) error {
+	tenantID := ""
+	if tenant, ok := tenancy.FromContext(ctx); ok {
+		tenantID = tenant.ID
+	}
+	s.updates = append(s.updates, fmt.Sprintf("%s %s %v", tenantID, projectID, enabled))
+	return nil
+}
+
+func (s *resumeTestStorage) ListCodeFiles(ctx context.Context, projectID string) ([]storage.CodeFile, error) {
+	return nil, nil
+}
+
+// GetCodeFile fails so the background reindex of outdated files stops early.
+func (s *resumeTestStorage) GetCodeFile(ctx context.Context, projectID, filePath string) (*storage.CodeFile, error) {
+	return nil, errors.New("not available in tests")
+}
@@ -0,0 +1,189 @@
+package kb
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/madeindigio/remembrances-mcp/internal/storage"
+)
+
+// Document metadata keys used to tell which side of the sync changed.
+const (
+	// MetaContentHash is the hash of the content stored in the database.
+	MetaContentHash = "content_hash"
+	// MetaSyncedHash is the hash of the content the last time the file on
+	// disk and the stored document matched.
+	MetaSyncedHash = "synced_hash"
+)
+
+// ErrConflict is returned when a file and its stored document both changed
+// since they were last in sync.
+var ErrConflict = errors.New("file and stored document both changed since the last sync")
+
+// SyncState is how a file on disk relates to its stored document.
+type SyncState string
+
+const (
+	// SyncUnknown means the document was stored without content hashes and
+	// only modification times can be compared.
+	SyncUnknown SyncState = "unknown"
+	// SyncInSync means the file and the document have the same content.
+	SyncInSync SyncState = "in_sync"
+	// SyncDiskChanged means only the file changed since the last sync.
+	SyncDiskChanged SyncState = "disk_changed"
+	// SyncDBChanged means only the stored document changed since the last sync.
+	SyncDBChanged SyncState = "db_changed"
+	// SyncConflict means both sides changed.
+	SyncConflict SyncState = "conflict"
+)
+
+// ContentHash returns the hash used to compare file and document contents.
+func ContentHash(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+// CompareSync classifies a file, given the hash of its content, against the
+// metadata of its stored document.
+func CompareSync(diskHash string, metadata map[string]interface{}) SyncState {
+	dbHash, _ := metadata[MetaContentHash].(string)
+	if dbHash == "" {
+		return SyncUnknown
+	}
+	if diskHash == dbHash {
+		return SyncInSync
+	}
+
+	// Without a synced hash the two sides never matched, e.g. a document
+	// added by a tool while an unsynced file with other content existed
+	syncedHash, _ := metadata[MetaSyncedHash].(string)
+	switch {
+	case syncedHash == "":
+		return SyncConflict
+	case dbHash == syncedHash:
+		return SyncDiskChanged
+	case diskHash == syncedHash:
+		return SyncDBChanged
+	default:
+		return SyncConflict
+	}
+}
+
+// Conflict describes a knowledge base file whose content and stored document
+// both changed since they were last in sync.
+type Conflict struct {
+	FilePath     string    `json:"file_path"`
+	DiskModified time.Time `json:"disk_modified"`
+	DBUpdated    time.Time `json:"db_updated"`
+	DiskHash     string    `json:"disk_hash"`
+	DBHash       string    `json:"db_hash"`
+	SyncedHash   string    `json:"synced_hash,omitempty"`
+	DiskContent  string    `json:"disk_content,omitempty"`
+	DBContent    string    `json:"db_content,omitempty"`
+}
+
+// CheckConflict compares a file, given relative to root, with its stored
+// document. As with documents added through tools, the file name gets a .md
+// extension when rel has none while the document path keeps rel as given.
+// It returns nil when the file or the document is missing or when they are
+// not in conflict.
+func CheckConflict(ctx context.Context, root Root, st storage.Storage, rel string) (*Conflict, error) {
+	filePath := root.DocumentPath(rel)
+	if !strings.HasSuffix(rel, ".md") {
+		rel += ".md"
+	}
+	fullPath := filepath.Join(root.Path, filepath.FromSlash(rel))
+	info, err := os.Stat(fullPath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat file: %w", err)
+	}
+	content, err := os.ReadFile(fullPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	doc, err := st.GetDocument(ctx, filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get document: %w", err)
+	}
+	if doc == nil {
+		return nil, nil
+	}
+
+	diskHash := ContentHash(string(content))
+	if CompareSync(diskHash, doc.Metadata) != SyncConflict {
+		return nil, nil
+	}
+
+	conflict := &Conflict{
+		FilePath:     filePath,
+		DiskModified: info.ModTime(),
+		DBUpdated:    doc.UpdatedAt,
+		DiskHash:     diskHash,
+		DiskContent:  string(content),
+	}
+	conflict.DBHash, _ = doc.Metadata[MetaContentHash].(string)
+	conflict.SyncedHash, _ = doc.Metadata[MetaSyncedHash].(string)
+	return conflict, nil
+}
+
+// FindConflicts checks every markdown file synced from root and returns
+// those in conflict with their stored documents, sorted by path. Files are
+// matched with documents stored both with and without the .md extension.
+func FindConflicts(ctx context.Context, root Root, st storage.Storage) ([]Conflict, error) {
+	var files []string
+	err := filepath.WalkDir(root.Path, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		rel, err := filepath.Rel(root.Path, path)
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			if path != root.Path && !root.Matches(rel, true) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if strings.HasSuffix(strings.ToLower(d.Name()), ".md") && root.Matches(rel, false) {
+			files = append(files, filepath.ToSlash(rel))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	conflicts := make([]Conflict, 0)
+	for _, rel := range files {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		candidates := []string{rel}
+		if trimmed := strings.TrimSuffix(rel, ".md"); trimmed != rel {
+			candidates = append(candidates, trimmed)
+		}
+		for _, candidate := range candidates {
+			conflict, err := CheckConflict(ctx, root, st, candidate)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", root.DocumentPath(candidate), err)
+			}
+			if conflict != nil {
+				conflicts = append(conflicts, *conflict)
+			}
+		}
+	}
+	return conflicts, nil
+}
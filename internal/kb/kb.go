@@ -5,6 +5,7 @@ package kb
 import (
 	"context"
 	"errors"
+	"fmt"
 	"io/fs"
 	"log/slog"
 	"os"
@@ -15,23 +16,47 @@ import (
 
 	"github.com/fsnotify/fsnotify"
 	"github.com/madeindigio/remembrances-mcp/internal/storage"
+	"github.com/madeindigio/remembrances-mcp/internal/watchqueue"
 	"github.com/madeindigio/remembrances-mcp/pkg/embedder"
 )
 
+// MetaEmbeddingModel is the document metadata key holding the model the
+// document was embedded with, as returned by embedder.ModelName.
+const MetaEmbeddingModel = "embedding_model"
+
 // Watcher controls monitoring of the knowledge base directory.
 type Watcher struct {
-	path         string
-	storage      storage.Storage
-	embedder     embedder.Embedder
-	watcher      *fsnotify.Watcher
-	cancel       context.CancelFunc
-	once         sync.Once
-	chunkSize    int
-	chunkOverlap int
+	root     Root
+	storage  storage.Storage
+	embedder embedder.Embedder
+	model    string
+	watcher  *fsnotify.Watcher
+	queue    *watchqueue.Queue
+	ctx      context.Context
+	cancel   context.CancelFunc
+	once     sync.Once
+	strategy embedder.ChunkStrategy
+
+	// Shutdown closes stopping and waits for the scan and event loop
+	stopping chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+
+	// Sync state reported by Status
+	mu            sync.Mutex
+	paused        bool
+	scanRemaining int
+	lastSync      time.Time
+	synced        int
+	forced        map[string]bool
+	errors        map[string]FileError
 }
 
-// StartWatcher starts a watcher if path is non-empty and exists. Returns nil if path is empty.
-func StartWatcher(parentCtx context.Context, path string, st storage.Storage, emb embedder.Embedder, chunkSize, chunkOverlap int) (*Watcher, error) {
+// StartWatcher starts a watcher if the root path is non-empty and exists. Returns nil if the path is empty.
+// model identifies emb in document metadata (see embedder.ModelName) and
+// queue sets the debounce and processing budget applied to file events.
+func StartWatcher(parentCtx context.Context, root Root, st storage.Storage, emb embedder.Embedder, model string, strategy embedder.ChunkStrategy, queue watchqueue.Config) (*Watcher, error) {
+	path := root.Path
 	if path == "" {
 		return nil, nil
 	}
@@ -54,13 +79,18 @@ func StartWatcher(parentCtx context.Context, path string, st storage.Storage, em
 
 	ctx, cancel := context.WithCancel(parentCtx)
 	w := &Watcher{
-		path:         path,
-		storage:      st,
-		embedder:     emb,
-		watcher:      fw,
-		cancel:       cancel,
-		chunkSize:    chunkSize,
-		chunkOverlap: chunkOverlap,
+		root:     root,
+		storage:  st,
+		embedder: emb,
+		model:    model,
+		watcher:  fw,
+		queue:    watchqueue.New(queue),
+		ctx:      ctx,
+		cancel:   cancel,
+		strategy: strategy,
+		stopping: make(chan struct{}),
+		forced:   make(map[string]bool),
+		errors:   make(map[string]FileError),
 	}
 
 	// Add only the root directory (fsnotify is not recursive). We will dynamically add subdirectories
@@ -70,11 +100,18 @@ func StartWatcher(parentCtx context.Context, path string, st storage.Storage, em
 		return nil, err
 	}
 
+	w.wg.Add(2)
 	// Indexación inicial de ficheros ya presentes
-	go w.initialScan(ctx)
+	go func() {
+		defer w.wg.Done()
+		w.initialScan(ctx)
+	}()
 	// Bucle de eventos
-	go w.run(ctx)
-	slog.Info("knowledge base watcher started", "path", path)
+	go func() {
+		defer w.wg.Done()
+		w.run(ctx)
+	}()
+	slog.Info("knowledge base watcher started", "path", path, "root", root.LabelOrDefault())
 	return w, nil
 }
 
@@ -86,65 +123,118 @@ func (w *Watcher) Stop() {
 	w.once.Do(func() {
 		w.cancel()
 		_ = w.watcher.Close()
-		slog.Info("knowledge base watcher stopped", "path", w.path)
+		slog.Info("knowledge base watcher stopped", "path", w.root.Path)
 	})
 }
 
+// Shutdown stops the watcher without cutting a document write short: the
+// file being synced is finished, then the changes still queued are synced
+// unless the watcher is paused. When ctx is done first, the write in
+// progress is cancelled like Stop does and the remaining files are synced by
+// the initial scan of the next start.
+func (w *Watcher) Shutdown(ctx context.Context) error {
+	if w == nil {
+		return nil
+	}
+	stopCancel := context.AfterFunc(ctx, w.cancel)
+	defer stopCancel()
+
+	w.stopOnce.Do(func() { close(w.stopping) })
+	w.wg.Wait()
+
+	if !w.isPaused() {
+		events := w.queue.Drain()
+		for i, e := range events {
+			if w.ctx.Err() != nil {
+				slog.Warn("knowledge base changes not synced before shutdown", "path", w.root.Path, "files", len(events)-i)
+				break
+			}
+			w.handle(w.ctx, e)
+		}
+	}
+
+	err := ctx.Err()
+	w.Stop()
+	return err
+}
+
+// isStopping reports whether Shutdown was called.
+func (w *Watcher) isStopping() bool {
+	select {
+	case <-w.stopping:
+		return true
+	default:
+		return false
+	}
+}
+
 // initialScan processes all existing .md files with concurrency control.
 func (w *Watcher) initialScan(ctx context.Context) {
-	slog.Info("starting initial knowledge base scan", "path", w.path)
+	slog.Info("starting initial knowledge base scan", "path", w.root.Path)
 
 	// Collect all files first
 	var files []string
-	filepath.WalkDir(w.path, func(path string, d fs.DirEntry, err error) error {
+	filepath.WalkDir(w.root.Path, func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
 			slog.Warn("initial scan error", "path", path, "error", err)
 			return nil
 		}
 		if d.IsDir() {
 			// Add each subdirectory to watcher for recursive behavior.
-			if path != w.path {
+			if path != w.root.Path {
+				if !w.root.Matches(w.relativePath(path), true) {
+					return filepath.SkipDir
+				}
 				if err := w.watcher.Add(path); err != nil {
 					slog.Warn("failed to watch subdirectory", "path", path, "error", err)
 				}
 			}
 			return nil
 		}
-		if strings.HasSuffix(strings.ToLower(d.Name()), ".md") {
+		if w.isMarkdownFile(path) {
 			files = append(files, path)
 		}
 		return nil
 	})
 
 	slog.Info("initial scan found files", "count", len(files))
+	w.setScanRemaining(len(files))
+	defer w.setScanRemaining(0)
 
 	// Process files SEQUENTIALLY to avoid memory exhaustion with GGUF models
 	// GGUF models can consume significant memory, especially with multiple concurrent operations
 	for i, file := range files {
-		select {
-		case <-ctx.Done():
+		if ctx.Err() != nil || w.isStopping() {
+			slog.Info("initial scan cancelled", "processed", i, "total", len(files))
+			return
+		}
+
+		if !w.waitWhilePaused(ctx) {
 			slog.Info("initial scan cancelled", "processed", i, "total", len(files))
 			return
-		default:
 		}
 
 		slog.Debug("processing kb file during initial scan", "file", file, "progress", i+1, "total", len(files))
-		w.processFile(ctx, file)
+		w.syncFile(ctx, file, false)
+		w.setScanRemaining(len(files) - i - 1)
 	}
 
 	slog.Info("initial knowledge base scan completed", "files_processed", len(files))
 }
 
-// run processes watcher events and debounces rapid successive writes.
+// run queues watcher events and processes them once settled, coalescing
+// events per file and pacing how many documents are embedded per minute.
 func (w *Watcher) run(ctx context.Context) {
-	debounce := make(map[string]time.Time)
-	ticker := time.NewTicker(500 * time.Millisecond)
+	ticker := time.NewTicker(250 * time.Millisecond)
 	defer ticker.Stop()
+	throttled := false
 
 	for {
 		select {
 		case <-ctx.Done():
 			return
+		case <-w.stopping:
+			return
 		case evt, ok := <-w.watcher.Events:
 			if !ok {
 				return
@@ -153,28 +243,26 @@ func (w *Watcher) run(ctx context.Context) {
 			if evt.Op&fsnotify.Create == fsnotify.Create {
 				info, err := os.Stat(evt.Name)
 				if err == nil && info.IsDir() {
+					if !w.root.Matches(w.relativePath(evt.Name), true) {
+						continue
+					}
 					if err := w.watcher.Add(evt.Name); err != nil {
 						slog.Warn("failed to add new directory to watcher", "dir", evt.Name, "error", err)
 					}
 					continue
 				}
 			}
-			if !strings.HasSuffix(strings.ToLower(evt.Name), ".md") {
+			if !w.isMarkdownFile(evt.Name) {
 				continue
 			}
 			// Delete / rename events -> remove from DB
 			if evt.Op&(fsnotify.Remove|fsnotify.Rename) != 0 {
-				rel := w.relativePath(evt.Name)
-				if err := w.storage.DeleteDocument(ctx, rel); err != nil {
-					slog.Warn("failed to delete document after file removal", "file", rel, "error", err)
-				} else {
-					slog.Info("document deleted after file removal", "file", rel)
-				}
+				w.queue.Add(evt.Name, watchqueue.OpRemove, time.Now())
 				continue
 			}
-			// Create or Write => schedule for debounced processing
+			// Create or Write => embed and save
 			if evt.Op&(fsnotify.Create|fsnotify.Write) != 0 {
-				debounce[evt.Name] = time.Now()
+				w.queue.Add(evt.Name, watchqueue.OpChange, time.Now())
 			}
 		case err, ok := <-w.watcher.Errors:
 			if !ok {
@@ -182,19 +270,78 @@ func (w *Watcher) run(ctx context.Context) {
 			}
 			slog.Warn("watcher error", "error", err)
 		case now := <-ticker.C:
-			for file, t := range debounce {
-				if now.Sub(t) > 300*time.Millisecond {
-					w.processFile(ctx, file)
-					delete(debounce, file)
+			if w.isPaused() {
+				continue
+			}
+			ready := w.queue.Ready(now)
+			for i, e := range ready {
+				if ctx.Err() != nil {
+					return
+				}
+				if w.isStopping() {
+					// Leave the rest to the flush of Shutdown
+					for _, rest := range ready[i:] {
+						w.queue.Add(rest.Path, rest.Op, now)
+					}
+					return
+				}
+				w.handle(ctx, e)
+			}
+
+			if w.queue.Throttled() != throttled {
+				throttled = !throttled
+				if throttled {
+					slog.Warn("knowledge base watcher budget reached, deferring changes", "pending", w.queue.Pending())
+				} else {
+					slog.Info("knowledge base watcher resumed deferred changes")
 				}
 			}
 		}
 	}
 }
 
+// handle syncs or removes the document of a settled file event.
+func (w *Watcher) handle(ctx context.Context, e watchqueue.Event) {
+	if e.Op == watchqueue.OpRemove {
+		w.removeFile(ctx, e.Path)
+	} else {
+		w.syncFile(ctx, e.Path, w.takeForced(e.Path))
+	}
+}
+
+// removeFile deletes the document of a removed file.
+func (w *Watcher) removeFile(ctx context.Context, fullPath string) {
+	// The file may have been recreated, e.g. by an editor saving atomically
+	if _, err := os.Stat(fullPath); err == nil {
+		w.syncFile(ctx, fullPath, w.takeForced(fullPath))
+		return
+	}
+
+	rel := w.documentPath(fullPath)
+	if err := w.storage.DeleteDocument(ctx, rel); errors.Is(err, storage.ErrPinned) {
+		slog.Info("kept pinned document after file removal", "file", rel)
+		w.recordResult(rel, false, nil)
+		return
+	} else if err != nil {
+		slog.Warn("failed to delete document after file removal", "file", rel, "error", err)
+		w.recordResult(rel, false, fmt.Errorf("failed to delete document: %w", err))
+	} else {
+		slog.Info("document deleted after file removal", "file", rel)
+		w.recordResult(rel, false, nil)
+	}
+
+	if w.root.Graph {
+		if err := DeleteNoteGraph(ctx, w.root, w.storage, rel); err != nil {
+			slog.Warn("failed to remove note from graph", "file", rel, "error", err)
+		}
+	}
+}
+
 // processFile reads the file, generates an embedding and upserts the document.
-func (w *Watcher) processFile(ctx context.Context, fullPath string) {
-	rel := w.relativePath(fullPath)
+// Files not modified since they were last synced are skipped unless force is
+// set; synced reports whether the document was written.
+func (w *Watcher) processFile(ctx context.Context, fullPath string, force bool) (synced bool, err error) {
+	rel := w.documentPath(fullPath)
 
 	// Add timeout to prevent hanging on large files
 	processingCtx, cancel := context.WithTimeout(ctx, 5*time.Minute)
@@ -207,47 +354,14 @@ func (w *Watcher) processFile(ctx context.Context, fullPath string) {
 	fileInfo, err := os.Stat(fullPath)
 	if err != nil {
 		slog.Warn("failed to stat kb file", "file", rel, "error", err)
-		return
+		return false, fmt.Errorf("failed to stat file: %w", err)
 	}
 	fileModTime := fileInfo.ModTime()
 
-	// Check if document already exists and compare modification times
-	existing, err := w.storage.GetDocument(processingCtx, rel)
-	if err != nil {
-		slog.Info("DEBUG: error getting existing document (will process)", "file", rel, "error", err)
-	} else if existing == nil {
-		slog.Info("DEBUG: document not found in database (will process)", "file", rel)
-	} else {
-		slog.Info("DEBUG: document found in database", "file", rel, "has_metadata", existing.Metadata != nil, "metadata", existing.Metadata)
-		// Document exists, check if file has been modified since last processing
-		if lastModStr, ok := existing.Metadata["last_modified"].(string); ok {
-			if lastModTime, err := time.Parse(time.RFC3339, lastModStr); err == nil {
-				// Truncate both times to seconds for comparison (RFC3339 doesn't preserve nanoseconds)
-				fileModTimeTrunc := fileModTime.Truncate(time.Second)
-				lastModTimeTrunc := lastModTime.Truncate(time.Second)
-
-				// If file hasn't been modified since last processing, skip
-				if !fileModTimeTrunc.After(lastModTimeTrunc) {
-					slog.Debug("kb file not modified since last processing, skipping", "file", rel,
-						"file_mtime", fileModTimeTrunc.Format(time.RFC3339),
-						"db_mtime", lastModTimeTrunc.Format(time.RFC3339))
-					return
-				}
-				slog.Info("kb file modified, reprocessing", "file", rel,
-					"file_mtime", fileModTimeTrunc.Format(time.RFC3339),
-					"db_mtime", lastModTimeTrunc.Format(time.RFC3339))
-			} else {
-				slog.Info("DEBUG: failed to parse last_modified timestamp (will process)", "file", rel, "error", err, "last_modified", lastModStr)
-			}
-		} else {
-			slog.Info("DEBUG: document has no last_modified in metadata (will process)", "file", rel, "metadata_keys", getMetadataKeys(existing.Metadata))
-		}
-	}
-
 	content, err := os.ReadFile(fullPath)
 	if err != nil {
 		slog.Warn("failed reading kb file", "file", rel, "error", err)
-		return
+		return false, fmt.Errorf("failed to read file: %w", err)
 	}
 
 	contentSize := len(content)
@@ -257,39 +371,140 @@ func (w *Watcher) processFile(ctx context.Context, fullPath string) {
 	const maxFileSize = 500 * 1024 // 500KB limit
 	if contentSize > maxFileSize {
 		slog.Warn("skipping large file", "file", rel, "bytes", contentSize, "max", maxFileSize)
-		return
+		return false, fmt.Errorf("file is %d bytes, over the %d byte limit", contentSize, maxFileSize)
 	}
 
 	// Skip empty files
 	contentStr := string(content)
 	if len(strings.TrimSpace(contentStr)) == 0 {
 		slog.Debug("skipping empty file", "file", rel)
-		return
+		return false, nil
+	}
+	contentHash := ContentHash(contentStr)
+
+	// Check if document already exists and compare content hashes, or
+	// modification times for documents stored before hashes were kept
+	existing, err := w.storage.GetDocument(processingCtx, rel)
+	if err != nil {
+		slog.Info("DEBUG: error getting existing document (will process)", "file", rel, "error", err)
+	} else if existing == nil {
+		slog.Info("DEBUG: document not found in database (will process)", "file", rel)
+	} else {
+		switch CompareSync(contentHash, existing.Metadata) {
+		case SyncInSync:
+			if !force {
+				slog.Debug("kb file matches stored document, skipping", "file", rel)
+				return false, nil
+			}
+		case SyncDBChanged:
+			// The document was updated through a tool and the file was not
+			return w.writeStoredDocument(processingCtx, rel, fullPath)
+		case SyncConflict:
+			slog.Warn("kb file and stored document both changed, not syncing", "file", rel)
+			return false, fmt.Errorf("%w; resolve it with kb_conflicts", ErrConflict)
+		case SyncDiskChanged:
+			slog.Info("kb file modified, reprocessing", "file", rel)
+		case SyncUnknown:
+			slog.Info("DEBUG: document found in database", "file", rel, "has_metadata", existing.Metadata != nil, "metadata", existing.Metadata)
+			// Document exists, check if file has been modified since last processing
+			if lastModStr, ok := existing.Metadata["last_modified"].(string); ok {
+				if lastModTime, err := time.Parse(time.RFC3339, lastModStr); err == nil {
+					// Truncate both times to seconds for comparison (RFC3339 doesn't preserve nanoseconds)
+					fileModTimeTrunc := fileModTime.Truncate(time.Second)
+					lastModTimeTrunc := lastModTime.Truncate(time.Second)
+
+					// If file hasn't been modified since last processing, skip
+					if !force && !fileModTimeTrunc.After(lastModTimeTrunc) {
+						slog.Debug("kb file not modified since last processing, skipping", "file", rel,
+							"file_mtime", fileModTimeTrunc.Format(time.RFC3339),
+							"db_mtime", lastModTimeTrunc.Format(time.RFC3339))
+						return false, nil
+					}
+					slog.Info("kb file modified, reprocessing", "file", rel,
+						"file_mtime", fileModTimeTrunc.Format(time.RFC3339),
+						"db_mtime", lastModTimeTrunc.Format(time.RFC3339))
+				} else {
+					slog.Info("DEBUG: failed to parse last_modified timestamp (will process)", "file", rel, "error", err, "last_modified", lastModStr)
+				}
+			} else {
+				slog.Info("DEBUG: document has no last_modified in metadata (will process)", "file", rel, "metadata_keys", getMetadataKeys(existing.Metadata))
+			}
+		}
 	}
 
 	// Chunk the text and generate individual embeddings for each chunk
 	// This allows for more precise retrieval compared to averaged embeddings
-	chunks, embeddings, err := embedder.EmbedTextChunksWithOverlap(processingCtx, w.embedder, contentStr, w.chunkSize, w.chunkOverlap)
+	chunks, embeddings, err := EmbedMarkdown(processingCtx, w.embedder, contentStr, w.strategy, w.root.ChunkSize, w.root.ChunkOverlap)
 	if err != nil {
 		slog.Warn("failed embedding kb file", "file", rel, "error", err, "duration", time.Since(startTime))
-		return
+		return false, fmt.Errorf("failed to generate embeddings: %w", err)
 	}
 
 	slog.Debug("chunks and embeddings generated", "file", rel, "chunks", len(chunks), "duration", time.Since(startTime))
 
 	// Save each chunk as a separate document with its own embedding
 	metadata := map[string]interface{}{
-		"source":        "watcher",
-		"total_size":    contentSize,
-		"last_modified": fileModTime.Format(time.RFC3339),
+		"source":         "watcher",
+		MetaRoot:         w.root.LabelOrDefault(),
+		"chunk_strategy": string(w.strategy),
+		"total_size":     contentSize,
+		"last_modified":  fileModTime.Format(time.RFC3339),
+		MetaContentHash:  contentHash,
+		MetaSyncedHash:   contentHash,
+	}
+	if w.model != "" {
+		metadata[MetaEmbeddingModel] = w.model
+	}
+	if fm, _ := ParseFrontmatter(contentStr); fm != nil {
+		for k, v := range FrontmatterMetadata(fm) {
+			metadata[k] = v
+		}
 	}
 
 	if err := w.storage.SaveDocumentChunks(processingCtx, rel, chunks, embeddings, metadata); err != nil {
 		slog.Warn("failed saving kb document chunks", "file", rel, "error", err)
-		return
+		return false, fmt.Errorf("failed to save document: %w", err)
+	}
+
+	if w.root.Graph {
+		if err := SyncNoteGraph(processingCtx, w.root, w.storage, rel, contentStr); err != nil {
+			slog.Warn("failed to update note graph", "file", rel, "error", err)
+		}
 	}
 
 	slog.Info("kb document synced", "file", rel, "bytes", contentSize, "chunks", len(chunks), "duration", time.Since(startTime))
+	return true, nil
+}
+
+// writeStoredDocument writes the stored content of a document over its file,
+// for documents changed through tools while the file was not.
+func (w *Watcher) writeStoredDocument(ctx context.Context, rel, fullPath string) (bool, error) {
+	content, err := w.storage.GetDocumentContent(ctx, rel)
+	if err != nil {
+		return false, err
+	}
+	if strings.TrimSpace(content) == "" {
+		return false, fmt.Errorf("stored document %s is empty", rel)
+	}
+
+	if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
+		return false, fmt.Errorf("failed to write file: %w", err)
+	}
+
+	hash := ContentHash(content)
+	metadata := map[string]interface{}{
+		MetaContentHash: hash,
+		MetaSyncedHash:  hash,
+	}
+	if info, err := os.Stat(fullPath); err == nil {
+		metadata["last_modified"] = info.ModTime().Format(time.RFC3339)
+	}
+	if err := w.storage.MergeDocumentMetadata(ctx, rel, metadata); err != nil {
+		return false, err
+	}
+
+	slog.Info("kb file updated from stored document", "file", rel, "bytes", len(content))
+	return true, nil
 }
 
 func getMetadataKeys(metadata map[string]interface{}) []string {
@@ -303,8 +518,18 @@ func getMetadataKeys(metadata map[string]interface{}) []string {
 	return keys
 }
 
+// documentPath returns the document path of a file of the watched root.
+func (w *Watcher) documentPath(full string) string {
+	return w.root.DocumentPath(w.relativePath(full))
+}
+
+// isMarkdownFile reports whether a file is markdown and passes the root filters.
+func (w *Watcher) isMarkdownFile(full string) bool {
+	return strings.HasSuffix(strings.ToLower(full), ".md") && w.root.Matches(w.relativePath(full), false)
+}
+
 func (w *Watcher) relativePath(full string) string {
-	rel, err := filepath.Rel(w.path, full)
+	rel, err := filepath.Rel(w.root.Path, full)
 	if err != nil {
 		return filepath.Base(full)
 	}
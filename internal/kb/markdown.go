@@ -0,0 +1,293 @@
+package kb
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/madeindigio/remembrances-mcp/internal/storage"
+	"github.com/madeindigio/remembrances-mcp/pkg/embedder"
+)
+
+// Document metadata keys filled from the YAML frontmatter of markdown files.
+const (
+	// MetaFrontmatter holds the whole frontmatter.
+	MetaFrontmatter = "frontmatter"
+	// MetaTitle is the title field.
+	MetaTitle = "title"
+	// MetaTags holds the tags or tag field, lowercase without "#".
+	MetaTags = "tags"
+	// MetaAliases holds the aliases or alias field.
+	MetaAliases = "aliases"
+	// MetaDate is the date or created field as a YYYY-MM-DD date.
+	MetaDate = "date"
+	// MetaUpdated is the updated or modified field as a YYYY-MM-DD date.
+	MetaUpdated = "updated"
+)
+
+// ParseFrontmatter splits a leading YAML frontmatter block, delimited by
+// "---" lines, from markdown content. It returns nil and the content as is
+// when there is no frontmatter or it is not a YAML mapping.
+func ParseFrontmatter(content string) (map[string]interface{}, string) {
+	text := strings.TrimPrefix(content, "\ufeff")
+	if !strings.HasPrefix(text, "---\n") && !strings.HasPrefix(text, "---\r\n") {
+		return nil, content
+	}
+	_, rest, _ := strings.Cut(text, "\n")
+
+	var block strings.Builder
+	for {
+		line, after, found := strings.Cut(rest, "\n")
+		if trimmed := strings.TrimRight(line, "\r \t"); trimmed == "---" || trimmed == "..." {
+			var fm map[string]interface{}
+			if err := yaml.Unmarshal([]byte(block.String()), &fm); err != nil {
+				return nil, content
+			}
+			if fm == nil {
+				fm = map[string]interface{}{}
+			}
+			return normalizeYAML(fm).(map[string]interface{}), after
+		}
+		if !found {
+			return nil, content
+		}
+		block.WriteString(line)
+		block.WriteByte('\n')
+		rest = after
+	}
+}
+
+// normalizeYAML converts decoded YAML values to types that can be stored
+// as JSON, e.g. dates to RFC 3339 strings.
+func normalizeYAML(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for k, item := range val {
+			val[k] = normalizeYAML(item)
+		}
+		return val
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(val))
+		for k, item := range val {
+			if key, ok := k.(string); ok {
+				m[key] = normalizeYAML(item)
+			}
+		}
+		return m
+	case []interface{}:
+		for i, item := range val {
+			val[i] = normalizeYAML(item)
+		}
+		return val
+	case time.Time:
+		if val.Equal(val.Truncate(24*time.Hour)) && val.Location() == time.UTC {
+			return val.Format(time.DateOnly)
+		}
+		return val.Format(time.RFC3339)
+	default:
+		return v
+	}
+}
+
+// FrontmatterMetadata returns the document metadata of a markdown file with
+// the given frontmatter: the frontmatter itself plus its title, tags,
+// aliases and dates in the forms kb_search_documents filters on.
+func FrontmatterMetadata(fm map[string]interface{}) map[string]interface{} {
+	metadata := map[string]interface{}{MetaFrontmatter: fm}
+	if title, ok := fm["title"].(string); ok && strings.TrimSpace(title) != "" {
+		metadata[MetaTitle] = strings.TrimSpace(title)
+	}
+
+	var tags []string
+	for _, tag := range frontmatterList(fm, "tags", "tag") {
+		tag = strings.ToLower(strings.TrimPrefix(tag, "#"))
+		if tag != "" && !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	if len(tags) > 0 {
+		metadata[MetaTags] = tags
+	}
+	if aliases := frontmatterList(fm, "aliases", "alias"); len(aliases) > 0 {
+		metadata[MetaAliases] = aliases
+	}
+
+	if date := frontmatterDate(fm, "date", "created"); date != "" {
+		metadata[MetaDate] = date
+	}
+	if date := frontmatterDate(fm, "updated", "modified"); date != "" {
+		metadata[MetaUpdated] = date
+	}
+	return metadata
+}
+
+// frontmatterList returns the first of the given fields that is set, as a
+// list of strings. Fields may be YAML lists or comma-separated strings.
+func frontmatterList(fm map[string]interface{}, keys ...string) []string {
+	for _, key := range keys {
+		var items []string
+		switch val := fm[key].(type) {
+		case string:
+			items = strings.Split(val, ",")
+		case []interface{}:
+			for _, item := range val {
+				if item != nil {
+					items = append(items, fmt.Sprint(item))
+				}
+			}
+		default:
+			continue
+		}
+
+		list := make([]string, 0, len(items))
+		for _, item := range items {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+		if len(list) > 0 {
+			return list
+		}
+	}
+	return nil
+}
+
+var datePrefix = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}`)
+
+// frontmatterDate returns the date of the first of the given fields that
+// starts with a YYYY-MM-DD date.
+func frontmatterDate(fm map[string]interface{}, keys ...string) string {
+	for _, key := range keys {
+		if val, ok := fm[key].(string); ok {
+			if date := datePrefix.FindString(strings.TrimSpace(val)); date != "" {
+				return date
+			}
+		}
+	}
+	return ""
+}
+
+// EmbedMarkdown chunks and embeds markdown content without its frontmatter,
+// which is stored as metadata instead. The raw frontmatter is kept at the
+// start of the first chunk so the stored document still holds the whole
+// file.
+func EmbedMarkdown(ctx context.Context, emb embedder.Embedder, content string, strategy embedder.ChunkStrategy, chunkSize, chunkOverlap int) ([]string, [][]float32, error) {
+	fm, body := ParseFrontmatter(content)
+	if fm == nil || strings.TrimSpace(body) == "" {
+		return embedder.EmbedTextChunksWithStrategy(ctx, emb, content, strategy, chunkSize, chunkOverlap)
+	}
+
+	chunks, embeddings, err := embedder.EmbedTextChunksWithStrategy(ctx, emb, body, strategy, chunkSize, chunkOverlap)
+	if err != nil || len(chunks) == 0 {
+		return chunks, embeddings, err
+	}
+	chunks[0] = content[:len(content)-len(body)] + chunks[0]
+	return chunks, embeddings, nil
+}
+
+var (
+	wikilinkPattern  = regexp.MustCompile(`\[\[([^\[\]\n]+)\]\]`)
+	codeFencePattern = regexp.MustCompile("(?ms)^[ \t]*(```|~~~).*?^[ \t]*(```|~~~)[ \t]*$")
+	inlineCode       = regexp.MustCompile("`[^`\n]*`")
+)
+
+// ParseWikilinks returns the [[wikilinks]] to other notes in markdown
+// content, in order and without duplicates. Targets are note names without
+// folders or the .md extension; links to headings of the same note, to
+// attachments and links inside code are skipped.
+func ParseWikilinks(content string) []storage.NoteLink {
+	content = codeFencePattern.ReplaceAllString(content, "")
+	content = inlineCode.ReplaceAllString(content, "")
+
+	var links []storage.NoteLink
+	seen := map[storage.NoteLink]bool{}
+	for _, m := range wikilinkPattern.FindAllStringSubmatch(content, -1) {
+		target, alias, _ := strings.Cut(m[1], "|")
+		target, heading, _ := strings.Cut(target, "#")
+		target = strings.TrimSpace(target)
+		if target == "" {
+			continue
+		}
+		if ext := path.Ext(target); ext != "" && !strings.EqualFold(ext, ".md") {
+			continue
+		}
+		link := storage.NoteLink{
+			Target:  NoteName(target),
+			Heading: strings.TrimSpace(heading),
+			Alias:   strings.TrimSpace(alias),
+		}
+		if !seen[link] {
+			seen[link] = true
+			links = append(links, link)
+		}
+	}
+	return links
+}
+
+// NoteName returns the name a note is linked by: its file name without
+// folders or the .md extension.
+func NoteName(filePath string) string {
+	name := path.Base(strings.ReplaceAll(filePath, "\\", "/"))
+	if strings.EqualFold(path.Ext(name), ".md") {
+		name = name[:len(name)-3]
+	}
+	return name
+}
+
+// NoteEntityName returns the graph entity name of a note of the root. As
+// with document paths, notes of other roots than the default one are
+// prefixed with the root label.
+func (r Root) NoteEntityName(note string) string {
+	if r.IsDefault() {
+		return note
+	}
+	return r.Label + "/" + note
+}
+
+// SyncNoteGraph stores a markdown document of the root as a note entity
+// linked to the notes its wikilinks point to. docPath is the document path
+// and content the full file content, frontmatter included.
+func SyncNoteGraph(ctx context.Context, root Root, st storage.Storage, docPath, content string) error {
+	rel := docPath
+	if !root.IsDefault() {
+		rel = strings.TrimPrefix(docPath, root.Label+"/")
+	}
+	fm, body := ParseFrontmatter(content)
+
+	properties := map[string]interface{}{
+		"file_path": docPath,
+		MetaRoot:    root.LabelOrDefault(),
+		"source":    "kb",
+	}
+	if fm != nil {
+		for k, v := range FrontmatterMetadata(fm) {
+			properties[k] = v
+		}
+	}
+
+	links := ParseWikilinks(body)
+	for i := range links {
+		links[i].Target = root.NoteEntityName(links[i].Target)
+	}
+
+	return st.SaveNoteGraph(ctx, storage.NoteGraph{
+		Name:       root.NoteEntityName(NoteName(rel)),
+		Properties: properties,
+		Links:      links,
+	})
+}
+
+// DeleteNoteGraph removes the note entity and links of a document of the root.
+func DeleteNoteGraph(ctx context.Context, root Root, st storage.Storage, docPath string) error {
+	rel := docPath
+	if !root.IsDefault() {
+		rel = strings.TrimPrefix(docPath, root.Label+"/")
+	}
+	return st.DeleteNoteGraph(ctx, root.NoteEntityName(NoteName(rel)))
+}
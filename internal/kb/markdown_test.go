@@ -0,0 +1,144 @@
+package kb
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/madeindigio/remembrances-mcp/internal/storage"
+)
+
+func TestParseFrontmatter(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		fm      map[string]interface{} // nil when there is no frontmatter
+		body    string
+	}{
+		{
+			name:    "mapping",
+			content: "---\ntitle: Go\ndate: 2024-01-15\n---\n# Go\n",
+			fm:      map[string]interface{}{"title": "Go", "date": "2024-01-15"},
+			body:    "# Go\n",
+		},
+		{
+			name:    "crlf and dots terminator",
+			content: "---\r\ntitle: Go\r\n...\r\nbody",
+			fm:      map[string]interface{}{"title": "Go"},
+			body:    "body",
+		},
+		{
+			name:    "empty block",
+			content: "---\n---\nbody",
+			fm:      map[string]interface{}{},
+			body:    "body",
+		},
+		{name: "none", content: "# Go\n---\n", body: "# Go\n---\n"},
+		{name: "not closed", content: "---\ntitle: Go\n# Go\n", body: "---\ntitle: Go\n# Go\n"},
+		{name: "not a mapping", content: "---\n- a\n- b\n---\nbody", body: "---\n- a\n- b\n---\nbody"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fm, body := ParseFrontmatter(tt.content)
+			if !reflect.DeepEqual(fm, tt.fm) || body != tt.body {
+				t.Errorf("ParseFrontmatter(%q) = %v, %q; want %v, %q", tt.content, fm, body, tt.fm, tt.body)
+			}
+		})
+	}
+}
+
+func TestFrontmatterMetadata(t *testing.T) {
+	fm, _ := ParseFrontmatter("---\ntitle: \" Go notes \"\ntags: [\"#Go\", go, Tooling]\nalias: gonotes, golang\ncreated: 2024-01-15T10:00:00Z\nmodified: not a date\n---\n")
+	got := FrontmatterMetadata(fm)
+
+	want := map[string]interface{}{
+		MetaFrontmatter: fm,
+		MetaTitle:       "Go notes",
+		MetaTags:        []string{"go", "tooling"},
+		MetaAliases:     []string{"gonotes", "golang"},
+		MetaDate:        "2024-01-15",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FrontmatterMetadata = %v, want %v", got, want)
+	}
+}
+
+func TestParseWikilinks(t *testing.T) {
+	content := "See [[Folder/Go.md#Install|setup]] and [[Rust]].\n" +
+		"Again [[Rust]], a heading [[#Intro]] and a picture [[diagram.png]].\n" +
+		"Inline `[[Code]]` is skipped.\n" +
+		"```\n[[Fenced]]\n```\n" +
+		"Windows [[notes\\Python]]\n"
+
+	got := ParseWikilinks(content)
+	want := []storage.NoteLink{
+		{Target: "Go", Heading: "Install", Alias: "setup"},
+		{Target: "Rust"},
+		{Target: "Python"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseWikilinks = %+v, want %+v", got, want)
+	}
+}
+
+// noteStorage records the note graphs stored by SyncNoteGraph.
+type noteStorage struct {
+	storage.Storage
+	saved   []storage.NoteGraph
+	deleted []string
+}
+
+func (s *noteStorage) SaveNoteGraph(ctx context.Context, note storage.NoteGraph) error {
+	s.saved = append(s.saved, note)
+	return nil
+}
+
+func (s *noteStorage) DeleteNoteGraph(ctx context.Context, name string) error {
+	s.deleted = append(s.deleted, name)
+	return nil
+}
+
+func TestSyncNoteGraph(t *testing.T) {
+	content := "---\ntitle: Go\ntags: go\n---\nLinks to [[Rust|the crab]].\n"
+	tests := []struct {
+		name    string
+		root    Root
+		docPath string
+		note    string
+		target  string
+	}{
+		{name: "default root", root: Root{}, docPath: "lang/Go.md", note: "Go", target: "Rust"},
+		{name: "labeled root", root: Root{Label: "vault"}, docPath: "vault/lang/Go.md", note: "vault/Go", target: "vault/Rust"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st := &noteStorage{}
+			if err := SyncNoteGraph(context.Background(), tt.root, st, tt.docPath, content); err != nil {
+				t.Fatalf("SyncNoteGraph: %v", err)
+			}
+			if len(st.saved) != 1 {
+				t.Fatalf("saved %d note graphs, want 1", len(st.saved))
+			}
+			note := st.saved[0]
+			if note.Name != tt.note {
+				t.Errorf("note name = %q, want %q", note.Name, tt.note)
+			}
+			links := []storage.NoteLink{{Target: tt.target, Alias: "the crab"}}
+			if !reflect.DeepEqual(note.Links, links) {
+				t.Errorf("links = %+v, want %+v", note.Links, links)
+			}
+			props := note.Properties
+			if props["file_path"] != tt.docPath || props[MetaRoot] != tt.root.LabelOrDefault() || props["source"] != "kb" ||
+				props[MetaTitle] != "Go" || !reflect.DeepEqual(props[MetaTags], []string{"go"}) {
+				t.Errorf("properties = %v, want the document path, root, source and frontmatter", props)
+			}
+
+			if err := DeleteNoteGraph(context.Background(), tt.root, st, tt.docPath); err != nil {
+				t.Fatalf("DeleteNoteGraph: %v", err)
+			}
+			if !reflect.DeepEqual(st.deleted, []string{tt.note}) {
+				t.Errorf("deleted = %v, want %q", st.deleted, tt.note)
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package kb
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/madeindigio/remembrances-mcp/internal/indexer"
+	"github.com/madeindigio/remembrances-mcp/internal/storage"
+)
+
+// MetaRoot is the document metadata key holding the label of the root a
+// document was synced from. Documents without it belong to the default root.
+const MetaRoot = "kb_root"
+
+// Root is a knowledge base directory and the settings used to sync it.
+type Root struct {
+	Path  string
+	Label string // storage.DefaultKBRoot for the --knowledge-base directory
+
+	ChunkSize    int
+	ChunkOverlap int
+
+	// Include and Exclude filter the markdown files synced, with the same
+	// pattern syntax as code indexing exclude patterns. An empty Include
+	// syncs every markdown file.
+	Include []string
+	Exclude []string
+
+	// Graph stores notes as knowledge graph entities linked by their
+	// [[wikilinks]], as in an Obsidian vault.
+	Graph bool
+}
+
+// IsDefault reports whether r is the --knowledge-base directory, whose
+// documents are stored by their plain relative path.
+func (r Root) IsDefault() bool {
+	return r.Label == "" || r.Label == storage.DefaultKBRoot
+}
+
+// DocumentPath returns the document path of a file given relative to the
+// root. Files of other roots than the default one are prefixed with the
+// root label, so the same relative path can exist in several roots.
+func (r Root) DocumentPath(rel string) string {
+	rel = filepath.ToSlash(rel)
+	if r.IsDefault() {
+		return rel
+	}
+	return r.Label + "/" + rel
+}
+
+// LabelOrDefault returns the label stored in document metadata.
+func (r Root) LabelOrDefault() string {
+	if r.IsDefault() {
+		return storage.DefaultKBRoot
+	}
+	return r.Label
+}
+
+// Matches reports whether a path relative to the root passes its filters.
+// Directories are only checked against Exclude, so included files in
+// subdirectories are still found.
+func (r Root) Matches(rel string, isDir bool) bool {
+	abs := filepath.Join(r.Path, rel)
+	if len(r.Exclude) > 0 && (&indexer.FileScanner{ExcludePatterns: r.Exclude}).ShouldExclude(abs, rel, isDir) {
+		return false
+	}
+	if isDir || len(r.Include) == 0 {
+		return true
+	}
+	return (&indexer.FileScanner{ExcludePatterns: r.Include}).ShouldExclude(abs, rel, false)
+}
+
+// ResolveRoot returns the root a document path belongs to and the path
+// relative to that root. Paths starting with the label of a root other than
+// the default one belong to it; any other path belongs to the default root.
+// ok is false when no root matches.
+func ResolveRoot(roots []Root, docPath string) (root Root, rel string, ok bool) {
+	docPath = filepath.ToSlash(docPath)
+	for _, r := range roots {
+		if !r.IsDefault() && strings.HasPrefix(docPath, r.Label+"/") {
+			return r, strings.TrimPrefix(docPath, r.Label+"/"), true
+		}
+	}
+	for _, r := range roots {
+		if r.IsDefault() {
+			return r, docPath, true
+		}
+	}
+	return Root{}, "", false
+}
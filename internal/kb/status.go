@@ -0,0 +1,214 @@
+package kb
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/madeindigio/remembrances-mcp/internal/watchqueue"
+)
+
+// FileError is the last failure to sync a knowledge base file.
+type FileError struct {
+	File  string    `json:"file"`
+	Error string    `json:"error"`
+	At    time.Time `json:"at"`
+}
+
+// Status describes the knowledge base watcher.
+type Status struct {
+	Root                 string      `json:"root"`
+	Path                 string      `json:"path"`
+	Paused               bool        `json:"paused"`
+	InitialScanRemaining int         `json:"initial_scan_remaining,omitempty"`
+	LastSync             *time.Time  `json:"last_sync,omitempty"`
+	FilesSynced          int         `json:"files_synced"`
+	PendingFiles         []string    `json:"pending_files"`
+	Throttled            bool        `json:"throttled,omitempty"`
+	Errors               []FileError `json:"errors"`
+}
+
+// Status returns the current state of the watcher: pending files, the last
+// successful sync and the files whose last sync failed.
+func (w *Watcher) Status() Status {
+	pending := make([]string, 0)
+	for _, path := range w.queue.PendingPaths() {
+		pending = append(pending, w.documentPath(path))
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	status := Status{
+		Root:                 w.root.LabelOrDefault(),
+		Path:                 w.root.Path,
+		Paused:               w.paused,
+		InitialScanRemaining: w.scanRemaining,
+		FilesSynced:          w.synced,
+		PendingFiles:         pending,
+		Throttled:            w.queue.Throttled(),
+		Errors:               make([]FileError, 0, len(w.errors)),
+	}
+	if !w.lastSync.IsZero() {
+		lastSync := w.lastSync
+		status.LastSync = &lastSync
+	}
+	for _, fileErr := range w.errors {
+		status.Errors = append(status.Errors, fileErr)
+	}
+	sort.Slice(status.Errors, func(i, j int) bool {
+		return status.Errors[i].File < status.Errors[j].File
+	})
+	return status
+}
+
+// Root returns the knowledge base root the watcher syncs.
+func (w *Watcher) Root() Root {
+	return w.root
+}
+
+// Pause stops processing file changes. Changes keep being collected and
+// are processed once the watcher is resumed.
+func (w *Watcher) Pause() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.paused = true
+}
+
+// Resume restarts processing after Pause.
+func (w *Watcher) Resume() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.paused = false
+}
+
+// SyncFile syncs one file, given relative to the watched root, right away. A missing file has its document deleted. With force the file
+// is re-embedded even when it was not modified since the last sync.
+func (w *Watcher) SyncFile(ctx context.Context, relPath string, force bool) (synced bool, err error) {
+	fullPath, err := w.fullPath(relPath)
+	if err != nil {
+		return false, err
+	}
+	if _, err := os.Stat(fullPath); os.IsNotExist(err) {
+		w.removeFile(ctx, fullPath)
+		return false, nil
+	}
+	return w.syncFile(ctx, fullPath, force)
+}
+
+// SyncAll queues every markdown file of the knowledge base for syncing and
+// returns how many were queued. Queued files are processed by the watcher,
+// within its reindex budget; with force they are re-embedded even when not
+// modified since the last sync.
+func (w *Watcher) SyncAll(force bool) (int, error) {
+	var files []string
+	err := filepath.WalkDir(w.root.Path, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			if path != w.root.Path && !w.root.Matches(w.relativePath(path), true) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if w.isMarkdownFile(path) {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	for _, file := range files {
+		if force {
+			w.mu.Lock()
+			w.forced[file] = true
+			w.mu.Unlock()
+		}
+		w.queue.Add(file, watchqueue.OpChange, now)
+	}
+	return len(files), nil
+}
+
+// syncFile processes a file and records the outcome for Status.
+func (w *Watcher) syncFile(ctx context.Context, fullPath string, force bool) (bool, error) {
+	synced, err := w.processFile(ctx, fullPath, force)
+	w.recordResult(w.documentPath(fullPath), synced, err)
+	return synced, err
+}
+
+// recordResult updates the sync state after a file was processed.
+func (w *Watcher) recordResult(rel string, synced bool, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if err != nil {
+		w.errors[rel] = FileError{File: rel, Error: err.Error(), At: time.Now()}
+		return
+	}
+	delete(w.errors, rel)
+	if synced {
+		w.synced++
+		w.lastSync = time.Now()
+	}
+}
+
+// takeForced reports whether a queued file was forced and clears the flag.
+func (w *Watcher) takeForced(fullPath string) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	forced := w.forced[fullPath]
+	delete(w.forced, fullPath)
+	return forced
+}
+
+func (w *Watcher) isPaused() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.paused
+}
+
+// waitWhilePaused blocks while the watcher is paused. It returns false when
+// ctx is cancelled or the watcher is shutting down.
+func (w *Watcher) waitWhilePaused(ctx context.Context) bool {
+	for w.isPaused() {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-w.stopping:
+			return false
+		case <-time.After(500 * time.Millisecond):
+		}
+	}
+	return ctx.Err() == nil
+}
+
+func (w *Watcher) setScanRemaining(n int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.scanRemaining = n
+}
+
+// fullPath resolves a path relative to the knowledge base directory,
+// rejecting paths outside it and files that are not markdown.
+func (w *Watcher) fullPath(relPath string) (string, error) {
+	clean := filepath.Clean(filepath.FromSlash(relPath))
+	if filepath.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("path is outside the knowledge base: %s", relPath)
+	}
+	if !strings.HasSuffix(strings.ToLower(clean), ".md") {
+		return "", fmt.Errorf("only markdown (.md) files are synced: %s", relPath)
+	}
+	if !w.root.Matches(clean, false) {
+		return "", fmt.Errorf("file is excluded by the filters of knowledge base root %q: %s", w.root.LabelOrDefault(), relPath)
+	}
+	return filepath.Join(w.root.Path, clean), nil
+}
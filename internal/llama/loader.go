@@ -3,6 +3,7 @@ package llama
 import (
 	"context"
 	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -117,6 +118,13 @@ func dlopenInOrder(files map[string]string, ext string) (*loadedLibs, error) {
 		}
 
 		h, err := purego.Dlopen(path, purego.RTLD_NOW|purego.RTLD_GLOBAL)
+		if err != nil && isGPUBackend(name, ext) && files["libggml-cpu"+ext] != "" {
+			// A GPU backend whose driver or runtime is missing: keep going with
+			// the CPU backend instead of failing to load llama.cpp at all.
+			slog.Warn("failed to load GPU backend, falling back to CPU", "library", name, "error", err)
+			variant = "cpu"
+			continue
+		}
 		if err != nil {
 			// If we are loading from an explicit path next to the binary, we can
 			// opportunistically try to load a missing dependency from the same
@@ -167,6 +175,11 @@ func dlopenInOrder(files map[string]string, ext string) (*loadedLibs, error) {
 	return nil, fmt.Errorf("libllama_shim%s not loaded", ext)
 }
 
+// isGPUBackend reports whether name is the CUDA or Metal ggml backend.
+func isGPUBackend(name, ext string) bool {
+	return name == "libggml-cuda"+ext || name == "libggml-metal"+ext
+}
+
 func missingKnownDependency(err error, ext string) string {
 	if err == nil {
 		return ""
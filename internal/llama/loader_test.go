@@ -45,3 +45,16 @@ func TestMissingKnownDependency(t *testing.T) {
 type fakeErr string
 
 func (e fakeErr) Error() string { return string(e) }
+
+func TestIsGPUBackend(t *testing.T) {
+	for name, want := range map[string]bool{
+		"libggml-cuda.so":  true,
+		"libggml-metal.so": true,
+		"libggml-cpu.so":   false,
+		"libggml.so":       false,
+	} {
+		if got := isGPUBackend(name, ".so"); got != want {
+			t.Errorf("isGPUBackend(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log/slog"
 	"runtime"
 	"sync"
 	"unsafe"
@@ -54,6 +55,10 @@ type Options struct {
 	Pooling   PoolingType
 	Attention AttentionType
 	Normalize int32 // 0 = none, 2 = L2
+
+	// RoPE overrides; 0 keeps the values stored in the model.
+	RopeFreqBase  float32
+	RopeFreqScale float32
 }
 
 func (o *Options) withDefaults() Options {
@@ -121,10 +126,15 @@ type api struct {
 	backendInit func()
 	backendFree func()
 
+	// variant is the ggml backend library that was loaded (cpu, cuda or metal).
+	variant string
+	// supportsGPUOffload is nil for shim builds that predate it.
+	supportsGPUOffload func() bool
+
 	modelLoad func(path string, nGPULayers int32, useMMap bool, useMLock bool) unsafe.Pointer
 	modelFree func(model unsafe.Pointer)
 
-	ctxInit func(model unsafe.Pointer, nCtx uint32, nBatch uint32, nUBatch uint32, nThreads int32, nThreadsBatch int32, poolingType int32, attentionType int32, embeddings bool) unsafe.Pointer
+	ctxInit func(model unsafe.Pointer, nCtx uint32, nBatch uint32, nUBatch uint32, nThreads int32, nThreadsBatch int32, poolingType int32, attentionType int32, ropeFreqBase float32, ropeFreqScale float32, embeddings bool) unsafe.Pointer
 	ctxFree func(ctx unsafe.Pointer)
 
 	modelNEmb func(model unsafe.Pointer) int32
@@ -138,6 +148,30 @@ var (
 	apiErr  error
 )
 
+// gpuOffload reports whether layers can be offloaded to a GPU. Shim builds
+// without the check are trusted when a GPU backend library was loaded.
+func (a *api) gpuOffload() bool {
+	if a.supportsGPUOffload != nil {
+		return a.supportsGPUOffload()
+	}
+	return a.variant == "cuda" || a.variant == "metal"
+}
+
+// Backend describes the ggml backend the llama libraries run on.
+type Backend struct {
+	Library    string // Backend library that was loaded: cpu, cuda or metal
+	GPUOffload bool   // Whether layers can be offloaded to a GPU
+}
+
+// BackendInfo loads the llama libraries if needed and reports their backend.
+func BackendInfo(ctx context.Context) (Backend, error) {
+	a, err := ensureAPI(ctx)
+	if err != nil {
+		return Backend{}, err
+	}
+	return Backend{Library: a.variant, GPUOffload: a.gpuOffload()}, nil
+}
+
 func ensureAPI(ctx context.Context) (*api, error) {
 	apiOnce.Do(func() {
 		if runtime.GOARCH == "386" {
@@ -160,6 +194,10 @@ func ensureAPI(ctx context.Context) (*api, error) {
 		purego.RegisterLibFunc(&a.ctxFree, libs.llamaShim, "rm_llama_free")
 		purego.RegisterLibFunc(&a.modelNEmb, libs.llamaShim, "rm_llama_model_n_embd")
 		purego.RegisterLibFunc(&a.embedText, libs.llamaShim, "rm_llama_embed_text")
+		if _, err := purego.Dlsym(libs.llamaShim, "rm_llama_supports_gpu_offload"); err == nil {
+			purego.RegisterLibFunc(&a.supportsGPUOffload, libs.llamaShim, "rm_llama_supports_gpu_offload")
+		}
+		a.variant = libs.variant
 
 		// Must be called once per process.
 		a.backendInit()
@@ -180,6 +218,9 @@ type Model struct {
 	batchSize   uint32
 	ubatchSize  uint32
 
+	// GPU layers actually offloaded, 0 after a CPU fallback
+	gpuLayers int
+
 	mu sync.Mutex
 }
 
@@ -195,12 +236,27 @@ func LoadModel(ctx context.Context, modelPath string, opts Options) (*Model, err
 
 	opts = opts.withDefaults()
 
-	model := a.modelLoad(modelPath, int32(opts.GPULayers), opts.UseMMap, opts.UseMLock)
+	gpuLayers := opts.GPULayers
+	if gpuLayers != 0 && !a.gpuOffload() {
+		slog.Warn("GPU layers requested but no usable GPU backend was found, running on CPU",
+			"gpu_layers", gpuLayers, "backend", a.variant)
+		gpuLayers = 0
+	}
+
+	model := a.modelLoad(modelPath, int32(gpuLayers), opts.UseMMap, opts.UseMLock)
+	if model == nil && gpuLayers != 0 {
+		// The GPU may be present but unusable (driver mismatch, out of memory);
+		// retry on CPU before giving up.
+		slog.Warn("failed to load model with GPU layers, retrying on CPU",
+			"gpu_layers", gpuLayers, "backend", a.variant, "model", modelPath)
+		gpuLayers = 0
+		model = a.modelLoad(modelPath, 0, opts.UseMMap, opts.UseMLock)
+	}
 	if model == nil {
 		return nil, fmt.Errorf("failed to load model: %s", modelPath)
 	}
 
-	ctxPtr := a.ctxInit(model, opts.ContextSize, opts.BatchSize, opts.UBatchSize, int32(opts.Threads), int32(opts.ThreadsBatch), llamaPoolingValue(opts.Pooling), llamaAttentionValue(opts.Attention), true)
+	ctxPtr := a.ctxInit(model, opts.ContextSize, opts.BatchSize, opts.UBatchSize, int32(opts.Threads), int32(opts.ThreadsBatch), llamaPoolingValue(opts.Pooling), llamaAttentionValue(opts.Attention), opts.RopeFreqBase, opts.RopeFreqScale, true)
 	if ctxPtr == nil {
 		a.modelFree(model)
 		return nil, fmt.Errorf("failed to create context")
@@ -214,9 +270,31 @@ func LoadModel(ctx context.Context, modelPath string, opts Options) (*Model, err
 		contextSize: opts.ContextSize,
 		batchSize:   opts.BatchSize,
 		ubatchSize:  opts.UBatchSize,
+		gpuLayers:   gpuLayers,
 	}, nil
 }
 
+// GPULayers returns the number of layers offloaded to the GPU, which is 0
+// when the model fell back to the CPU.
+func (m *Model) GPULayers() int {
+	if m == nil {
+		return 0
+	}
+	return m.gpuLayers
+}
+
+// Backend returns the backend the model runs on: cpu, or the GPU backend
+// library (cuda or metal) when layers are offloaded.
+func (m *Model) Backend() string {
+	if m == nil || m.a == nil {
+		return ""
+	}
+	if m.gpuLayers == 0 {
+		return "cpu"
+	}
+	return m.a.variant
+}
+
 func (m *Model) Dimension() int {
 	if m == nil || m.model == nil {
 		return 0
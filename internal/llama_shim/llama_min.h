@@ -180,6 +180,8 @@ struct llama_batch {
 void llama_backend_init(void);
 void llama_backend_free(void);
 
+bool llama_supports_gpu_offload(void);
+
 struct llama_model_params   llama_model_default_params(void);
 struct llama_context_params llama_context_default_params(void);
 
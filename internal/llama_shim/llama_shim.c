@@ -44,6 +44,10 @@ void rm_llama_backend_free(void) {
     llama_backend_free();
 }
 
+bool rm_llama_supports_gpu_offload(void) {
+    return llama_supports_gpu_offload();
+}
+
 struct llama_model * rm_llama_model_load_from_file(const char * path_model, int32_t n_gpu_layers, bool use_mmap, bool use_mlock) {
     struct llama_model_params params = llama_model_default_params();
     params.n_gpu_layers = n_gpu_layers;
@@ -73,6 +77,8 @@ struct llama_context * rm_llama_context_init(
     int32_t n_threads_batch,
     int32_t pooling_type,
     int32_t attention_type,
+    float rope_freq_base,
+    float rope_freq_scale,
     bool embeddings) {
 
     if (model == NULL) {
@@ -98,6 +104,14 @@ struct llama_context * rm_llama_context_init(
         params.attention_type = (enum llama_attention_type) attention_type;
     }
 
+    // 0 keeps the RoPE settings stored in the model.
+    if (rope_freq_base != 0.0f) {
+        params.rope_freq_base = rope_freq_base;
+    }
+    if (rope_freq_scale != 0.0f) {
+        params.rope_freq_scale = rope_freq_scale;
+    }
+
     return llama_init_from_model(model, params);
 }
 
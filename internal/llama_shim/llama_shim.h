@@ -20,6 +20,9 @@ struct llama_context;
 void rm_llama_backend_init(void);
 void rm_llama_backend_free(void);
 
+// Reports whether the loaded ggml backends can offload layers to a GPU.
+bool rm_llama_supports_gpu_offload(void);
+
 struct llama_model * rm_llama_model_load_from_file(const char * path_model, int32_t n_gpu_layers, bool use_mmap, bool use_mlock);
 void rm_llama_model_free(struct llama_model * model);
 
@@ -32,6 +35,8 @@ struct llama_context * rm_llama_context_init(
     int32_t n_threads_batch,
     int32_t pooling_type,
     int32_t attention_type,
+    float rope_freq_base,
+    float rope_freq_scale,
     bool embeddings);
 
 void rm_llama_free(struct llama_context * ctx);
@@ -0,0 +1,225 @@
+// Package logging provides the slog handler of the server: text or JSON
+// output, a level per module that can be changed at runtime, and log files
+// rotated by size and age.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// Modules whose level can be set apart from the default level.
+const (
+	ModuleStorage  = "storage"  // internal/storage and the embedded SurrealDB
+	ModuleEmbedder = "embedder" // pkg/embedder and the llama.cpp bindings
+	ModuleIndexer  = "indexer"  // code indexing and tree-sitter parsing
+	ModuleWatcher  = "watcher"  // code and knowledge base watchers
+	ModuleMCP      = "mcp"      // MCP tools, modules and transports
+)
+
+// Modules lists the modules in a stable order.
+var Modules = []string{ModuleStorage, ModuleEmbedder, ModuleIndexer, ModuleWatcher, ModuleMCP}
+
+// IsModule reports whether name is a module with its own level.
+func IsModule(name string) bool {
+	for _, m := range Modules {
+		if m == name {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseLevel parses debug, info, warn (or warning) and error.
+func ParseLevel(name string) (slog.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "", "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	}
+	return 0, fmt.Errorf("invalid log level %q: expected debug, info, warn or error", name)
+}
+
+// LevelName returns the name ParseLevel accepts for level.
+func LevelName(level slog.Level) string {
+	return strings.ToLower(level.String())
+}
+
+// Levels holds the default log level and the levels of the modules that
+// override it. It is safe for concurrent use and changes apply to records
+// logged afterwards.
+type Levels struct {
+	mu      sync.RWMutex
+	def     slog.Level
+	modules map[string]slog.Level
+}
+
+// NewLevels creates the levels from their names; modules maps a module to
+// its level.
+func NewLevels(def string, modules map[string]string) (*Levels, error) {
+	level, err := ParseLevel(def)
+	if err != nil {
+		return nil, err
+	}
+	l := &Levels{def: level, modules: map[string]slog.Level{}}
+	for module, name := range modules {
+		if err := l.Set(module, name); err != nil {
+			return nil, err
+		}
+	}
+	return l, nil
+}
+
+// Set changes the level of a module, or the default level when module is
+// empty or "default". An empty level makes a module follow the default again.
+func (l *Levels) Set(module, name string) error {
+	module = strings.ToLower(strings.TrimSpace(module))
+	if module != "" && module != "default" && !IsModule(module) {
+		return fmt.Errorf("invalid log module %q: expected %s", module, strings.Join(Modules, ", "))
+	}
+	if module != "" && module != "default" && strings.TrimSpace(name) == "" {
+		l.mu.Lock()
+		delete(l.modules, module)
+		l.mu.Unlock()
+		return nil
+	}
+	level, err := ParseLevel(name)
+	if err != nil {
+		return err
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if module == "" || module == "default" {
+		l.def = level
+	} else {
+		l.modules[module] = level
+	}
+	return nil
+}
+
+// Level returns the level of a module; modules without their own level, and
+// records that belong to no module, use the default level.
+func (l *Levels) Level(module string) slog.Level {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	if level, ok := l.modules[module]; ok {
+		return level
+	}
+	return l.def
+}
+
+// min returns the lowest level any module logs at.
+func (l *Levels) min() slog.Level {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	lowest := l.def
+	for _, level := range l.modules {
+		if level < lowest {
+			lowest = level
+		}
+	}
+	return lowest
+}
+
+// Snapshot returns the level names: "default" and every module.
+func (l *Levels) Snapshot() map[string]string {
+	levels := map[string]string{"default": LevelName(l.Level(""))}
+	for _, m := range Modules {
+		levels[m] = LevelName(l.Level(m))
+	}
+	return levels
+}
+
+// NewHandler returns a handler writing records to w as text or json, keeping
+// those at or above the level of the module that logged them.
+func NewHandler(w io.Writer, format string, levels *Levels) (slog.Handler, error) {
+	opts := &slog.HandlerOptions{Level: slog.LevelDebug}
+	var inner slog.Handler
+	switch strings.ToLower(strings.TrimSpace(format)) {
+	case "", "text":
+		inner = slog.NewTextHandler(w, opts)
+	case "json":
+		inner = slog.NewJSONHandler(w, opts)
+	default:
+		return nil, fmt.Errorf("invalid log format %q: expected text or json", format)
+	}
+	return &moduleHandler{inner: inner, levels: levels}, nil
+}
+
+// moduleHandler filters records by the level of the module whose code
+// logged them, found from the package of the record's caller.
+type moduleHandler struct {
+	inner  slog.Handler
+	levels *Levels
+}
+
+func (h *moduleHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= h.levels.min()
+}
+
+func (h *moduleHandler) Handle(ctx context.Context, r slog.Record) error {
+	if r.Level < h.levels.Level(moduleOf(r.PC)) {
+		return nil
+	}
+	return h.inner.Handle(ctx, r)
+}
+
+func (h *moduleHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &moduleHandler{inner: h.inner.WithAttrs(attrs), levels: h.levels}
+}
+
+func (h *moduleHandler) WithGroup(name string) slog.Handler {
+	return &moduleHandler{inner: h.inner.WithGroup(name), levels: h.levels}
+}
+
+// moduleOf returns the module of the code at pc, or "" when it belongs to
+// none.
+func moduleOf(pc uintptr) string {
+	if pc == 0 {
+		return ""
+	}
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	return moduleOfFrame(frame.Function, frame.File)
+}
+
+// moduleOfFrame maps the function and file of a caller to its module.
+func moduleOfFrame(function, file string) string {
+	// The import path of the function's package, e.g.
+	// github.com/madeindigio/remembrances-mcp/internal/storage
+	pkg := function
+	if slash := strings.LastIndex(pkg, "/"); slash >= 0 {
+		if dot := strings.Index(pkg[slash:], "."); dot >= 0 {
+			pkg = pkg[:slash+dot]
+		}
+	}
+	switch {
+	case strings.HasSuffix(pkg, "/internal/storage"), strings.HasSuffix(pkg, "/internal/surrealembedded"):
+		return ModuleStorage
+	case strings.HasSuffix(pkg, "/pkg/embedder"), strings.HasSuffix(pkg, "/internal/llama"):
+		return ModuleEmbedder
+	case strings.HasSuffix(pkg, "/internal/kb"), strings.HasSuffix(pkg, "/internal/watchqueue"):
+		return ModuleWatcher
+	case strings.HasSuffix(pkg, "/internal/indexer"):
+		base := file[strings.LastIndex(file, "/")+1:]
+		if strings.HasPrefix(base, "watcher") || strings.HasPrefix(base, "code_watcher") {
+			return ModuleWatcher
+		}
+		return ModuleIndexer
+	case strings.HasSuffix(pkg, "/pkg/treesitter"):
+		return ModuleIndexer
+	case strings.HasSuffix(pkg, "/pkg/mcp_tools"), strings.HasSuffix(pkg, "/pkg/modules"),
+		strings.HasSuffix(pkg, "/internal/transport"), strings.Contains(pkg, "/modules/"):
+		return ModuleMCP
+	}
+	return ""
+}
@@ -0,0 +1,110 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestLevelsSet(t *testing.T) {
+	levels, err := NewLevels("warn", map[string]string{"embedder": "debug"})
+	if err != nil {
+		t.Fatalf("NewLevels() error = %v", err)
+	}
+	if got := levels.Level(ModuleEmbedder); got != slog.LevelDebug {
+		t.Errorf("embedder level = %v, want debug", got)
+	}
+	if got := levels.Level(ModuleStorage); got != slog.LevelWarn {
+		t.Errorf("storage level = %v, want the default warn", got)
+	}
+
+	if err := levels.Set("default", "error"); err != nil {
+		t.Fatal(err)
+	}
+	if err := levels.Set(ModuleEmbedder, ""); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"default": "error", "storage": "error", "embedder": "error", "indexer": "error", "watcher": "error", "mcp": "error"}
+	got := levels.Snapshot()
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("Snapshot()[%q] = %q, want %q", k, got[k], v)
+		}
+	}
+
+	if err := levels.Set("database", "debug"); err == nil {
+		t.Error("Set() accepted an unknown module")
+	}
+	if err := levels.Set(ModuleMCP, "verbose"); err == nil {
+		t.Error("Set() accepted an unknown level")
+	}
+	if _, err := NewLevels("info", map[string]string{"indexer": "loud"}); err == nil {
+		t.Error("NewLevels() accepted an unknown module level")
+	}
+}
+
+func TestModuleOfFrame(t *testing.T) {
+	const repo = "github.com/madeindigio/remembrances-mcp"
+	tests := []struct {
+		function, file, want string
+	}{
+		{repo + "/internal/storage.(*SurrealDBStorage).SaveFact", "/src/internal/storage/surrealdb.go", ModuleStorage},
+		{repo + "/pkg/embedder.(*GGUFEmbedder).EmbedQuery", "/src/pkg/embedder/gguf.go", ModuleEmbedder},
+		{repo + "/internal/llama.LoadModel", "/src/internal/llama/shim.go", ModuleEmbedder},
+		{repo + "/internal/indexer.(*Indexer).IndexProject", "/src/internal/indexer/indexer.go", ModuleIndexer},
+		{repo + "/internal/indexer.(*ProjectWatcher).run", "/src/internal/indexer/watcher.go", ModuleWatcher},
+		{repo + "/internal/kb.(*Watcher).Start.func1", "/src/internal/kb/watcher.go", ModuleWatcher},
+		{repo + "/pkg/mcp_tools.(*ToolManager).saveFactHandler", "/src/pkg/mcp_tools/fact_tools.go", ModuleMCP},
+		{repo + "/modules/standard.(*CoreTools).Register", "/src/modules/standard/core_tools.go", ModuleMCP},
+		{"main.main", "/src/cmd/remembrances-mcp/main.go", ""},
+		{repo + "/internal/config.(*Config).SetupLogging", "/src/internal/config/config.go", ""},
+	}
+	for _, tt := range tests {
+		if got := moduleOfFrame(tt.function, tt.file); got != tt.want {
+			t.Errorf("moduleOfFrame(%q) = %q, want %q", tt.function, got, tt.want)
+		}
+	}
+}
+
+func TestHandlerFiltersByLevel(t *testing.T) {
+	levels, err := NewLevels("warn", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	handler, err := NewHandler(&buf, "json", levels)
+	if err != nil {
+		t.Fatalf("NewHandler() error = %v", err)
+	}
+	logger := slog.New(handler)
+
+	logger.Info("hidden")
+	logger.Warn("shown", "key", "value")
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("got %d records, want 1: %q", len(lines), buf.String())
+	}
+	var record map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &record); err != nil {
+		t.Fatalf("record is not JSON: %v", err)
+	}
+	if record["msg"] != "shown" || record["key"] != "value" {
+		t.Errorf("record = %v", record)
+	}
+
+	// Lowering the default level at runtime applies to the next records
+	buf.Reset()
+	if err := levels.Set("", "debug"); err != nil {
+		t.Fatal(err)
+	}
+	logger.Debug("now shown")
+	if !strings.Contains(buf.String(), "now shown") {
+		t.Errorf("debug record missing after lowering the level: %q", buf.String())
+	}
+
+	if _, err := NewHandler(&buf, "xml", levels); err == nil {
+		t.Error("NewHandler() accepted an unknown format")
+	}
+}
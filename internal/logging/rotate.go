@@ -0,0 +1,137 @@
+package logging
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// backupTimeFormat stamps rotated log files, e.g. server.log.20250101T100000.
+const backupTimeFormat = "20060102T150405"
+
+// RotateConfig bounds a log file. Zero values disable the corresponding limit.
+type RotateConfig struct {
+	MaxBytes   int64         // Size at which the file is rotated
+	MaxAge     time.Duration // Rotated files older than this are deleted
+	MaxBackups int           // Rotated files kept, the newest first
+}
+
+// RotatingFile is an io.WriteCloser appending to a log file that is renamed
+// with a timestamp suffix once it reaches MaxBytes, pruning old backups.
+type RotatingFile struct {
+	path string
+	cfg  RotateConfig
+	now  func() time.Time
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+// OpenRotatingFile opens path for appending, creating it when missing.
+func OpenRotatingFile(path string, cfg RotateConfig) (*RotatingFile, error) {
+	r := &RotatingFile{path: path, cfg: cfg, now: time.Now}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	r.prune()
+	return r, nil
+}
+
+func (r *RotatingFile) open() error {
+	file, err := os.OpenFile(r.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open log file %s: %w", r.path, err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to stat log file %s: %w", r.path, err)
+	}
+	r.file, r.size = file, info.Size()
+	return nil
+}
+
+// Write appends p, rotating the file first when p would take it past
+// MaxBytes. A record larger than MaxBytes is still written whole.
+func (r *RotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.cfg.MaxBytes > 0 && r.size > 0 && r.size+int64(len(p)) > r.cfg.MaxBytes {
+		if err := r.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// Close closes the current file.
+func (r *RotatingFile) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.file.Close()
+}
+
+// rotate renames the current file to a timestamped backup and starts a new one.
+func (r *RotatingFile) rotate() error {
+	if err := r.file.Close(); err != nil {
+		return fmt.Errorf("failed to close log file %s: %w", r.path, err)
+	}
+	backup := r.path + "." + r.now().Format(backupTimeFormat)
+	for i := 1; fileExists(backup); i++ {
+		backup = fmt.Sprintf("%s.%s.%d", r.path, r.now().Format(backupTimeFormat), i)
+	}
+	if err := os.Rename(r.path, backup); err != nil {
+		return fmt.Errorf("failed to rotate log file %s: %w", r.path, err)
+	}
+	if err := r.open(); err != nil {
+		return err
+	}
+	r.prune()
+	return nil
+}
+
+// prune deletes the backups beyond MaxBackups or older than MaxAge.
+// Failures are ignored: a leftover backup must not stop logging.
+func (r *RotatingFile) prune() {
+	if r.cfg.MaxBackups <= 0 && r.cfg.MaxAge <= 0 {
+		return
+	}
+	backups, _ := filepath.Glob(r.path + ".*")
+	type backup struct {
+		path    string
+		modTime time.Time
+	}
+	var found []backup
+	for _, path := range backups {
+		stamp := strings.TrimPrefix(path, r.path+".")
+		if len(stamp) < len(backupTimeFormat) {
+			continue
+		}
+		if _, err := time.Parse(backupTimeFormat, stamp[:len(backupTimeFormat)]); err != nil {
+			continue
+		}
+		if info, err := os.Stat(path); err == nil {
+			found = append(found, backup{path: path, modTime: info.ModTime()})
+		}
+	}
+	sort.Slice(found, func(i, j int) bool { return found[i].modTime.After(found[j].modTime) })
+	for i, b := range found {
+		tooMany := r.cfg.MaxBackups > 0 && i >= r.cfg.MaxBackups
+		tooOld := r.cfg.MaxAge > 0 && r.now().Sub(b.modTime) > r.cfg.MaxAge
+		if tooMany || tooOld {
+			os.Remove(b.path)
+		}
+	}
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,75 @@
+package logging
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRotatingFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "server.log")
+	r, err := OpenRotatingFile(path, RotateConfig{MaxBytes: 10, MaxBackups: 2})
+	if err != nil {
+		t.Fatalf("OpenRotatingFile() error = %v", err)
+	}
+	defer r.Close()
+	now := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	r.now = func() time.Time { return now }
+
+	for i := 0; i < 4; i++ {
+		if _, err := r.Write([]byte("0123456789")); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+		now = now.Add(time.Second)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "0123456789" {
+		t.Errorf("current file = %q, want only the last record", data)
+	}
+	backups, _ := filepath.Glob(path + ".*")
+	if len(backups) != 2 {
+		t.Fatalf("got %d backups, want 2 (MaxBackups): %v", len(backups), backups)
+	}
+	for _, b := range backups {
+		if !strings.HasPrefix(filepath.Base(b), "server.log.20250101T1000") {
+			t.Errorf("unexpected backup name %s", b)
+		}
+	}
+}
+
+func TestRotatingFilePrunesOldBackups(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "server.log")
+	old := path + ".20240101T000000"
+	if err := os.WriteFile(old, []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	stamp := time.Now().Add(-48 * time.Hour)
+	if err := os.Chtimes(old, stamp, stamp); err != nil {
+		t.Fatal(err)
+	}
+	unrelated := path + ".bak"
+	if err := os.WriteFile(unrelated, []byte("keep"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := OpenRotatingFile(path, RotateConfig{MaxAge: 24 * time.Hour})
+	if err != nil {
+		t.Fatalf("OpenRotatingFile() error = %v", err)
+	}
+	defer r.Close()
+
+	if fileExists(old) {
+		t.Error("backup older than MaxAge was not deleted")
+	}
+	if !fileExists(unrelated) {
+		t.Error("a file that is not a backup was deleted")
+	}
+}
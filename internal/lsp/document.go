@@ -0,0 +1,133 @@
+package lsp
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// document is the text of a file with the offsets of its lines, to convert
+// between byte offsets and protocol positions.
+type document struct {
+	text       string
+	lineStarts []int
+}
+
+func newDocument(text string) *document {
+	d := &document{text: text, lineStarts: []int{0}}
+	for i := 0; i < len(text); i++ {
+		if text[i] == '\n' {
+			d.lineStarts = append(d.lineStarts, i+1)
+		}
+	}
+	return d
+}
+
+// position returns the position of a byte offset, clamped to the text.
+func (d *document) position(offset int) Position {
+	offset = min(max(offset, 0), len(d.text))
+	line := sort.Search(len(d.lineStarts), func(i int) bool { return d.lineStarts[i] > offset }) - 1
+	return Position{Line: line, Character: utf16Len(d.text[d.lineStarts[line]:offset])}
+}
+
+// offset returns the byte offset of a position, clamped to its line.
+func (d *document) offset(pos Position) int {
+	if pos.Line < 0 {
+		return 0
+	}
+	if pos.Line >= len(d.lineStarts) {
+		return len(d.text)
+	}
+	start := d.lineStarts[pos.Line]
+	end := len(d.text)
+	if pos.Line+1 < len(d.lineStarts) {
+		end = d.lineStarts[pos.Line+1] - 1
+	}
+	units := 0
+	for i, r := range d.text[start:end] {
+		if units >= pos.Character {
+			return start + i
+		}
+		units += utf16.RuneLen(r)
+	}
+	return end
+}
+
+// rangeOf returns the range of the bytes start to end.
+func (d *document) rangeOf(start, end int) Range {
+	return Range{Start: d.position(start), End: d.position(end)}
+}
+
+// line returns the byte offsets of a zero-based line, without its newline,
+// or false when the document has no such line.
+func (d *document) line(n int) (int, int, bool) {
+	if n < 0 || n >= len(d.lineStarts) {
+		return 0, 0, false
+	}
+	end := len(d.text)
+	if n+1 < len(d.lineStarts) {
+		end = d.lineStarts[n+1] - 1
+	}
+	return d.lineStarts[n], end, true
+}
+
+// findWord returns the byte offset of the first occurrence of word as a
+// whole identifier between start and end, or -1.
+func (d *document) findWord(word string, start, end int) int {
+	if word == "" || start < 0 || end > len(d.text) || start >= end {
+		return -1
+	}
+	for from := start; from < end; {
+		i := strings.Index(d.text[from:end], word)
+		if i < 0 {
+			return -1
+		}
+		i += from
+		before, _ := utf8.DecodeLastRuneInString(d.text[:i])
+		after, _ := utf8.DecodeRuneInString(d.text[i+len(word):])
+		if (i == 0 || !isIdentRune(before)) && (i+len(word) == len(d.text) || !isIdentRune(after)) {
+			return i
+		}
+		from = i + 1
+	}
+	return -1
+}
+
+// wordAt returns the identifier at a position, or "".
+func (d *document) wordAt(pos Position) string {
+	offset := d.offset(pos)
+	start, end := offset, offset
+	for start > 0 {
+		r, size := utf8.DecodeLastRuneInString(d.text[:start])
+		if !isIdentRune(r) {
+			break
+		}
+		start -= size
+	}
+	for end < len(d.text) {
+		r, size := utf8.DecodeRuneInString(d.text[end:])
+		if !isIdentRune(r) {
+			break
+		}
+		end += size
+	}
+	return d.text[start:end]
+}
+
+// isIdentRune reports whether r can be part of an identifier in the indexed
+// languages.
+func isIdentRune(r rune) bool {
+	return r == '_' || r == '$' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// utf16Len returns the length of s in UTF-16 code units, the unit of
+// protocol character offsets.
+func utf16Len(s string) int {
+	n := 0
+	for _, r := range s {
+		n += utf16.RuneLen(r)
+	}
+	return n
+}
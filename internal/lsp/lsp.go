@@ -0,0 +1,603 @@
+// Package lsp serves the code index over the language server protocol, so
+// that editors get document and workspace symbols, definitions and references
+// from the same storage the code tools use, without indexing the code again.
+package lsp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/madeindigio/remembrances-mcp/internal/storage"
+	"github.com/madeindigio/remembrances-mcp/pkg/treesitter"
+)
+
+// Limits of the symbols and locations returned.
+const (
+	maxWorkspaceSymbols = 500
+	maxDefinitions      = 50
+	maxReferences       = 500
+)
+
+// ErrExitWithoutShutdown is returned by Serve when the client sent exit
+// before shutdown, which the protocol treats as an abnormal exit.
+var ErrExitWithoutShutdown = errors.New("exit received before shutdown")
+
+// Store is the code index the server reads.
+type Store interface {
+	ListCodeProjects(ctx context.Context) ([]storage.CodeProject, error)
+	ListCodeSymbols(ctx context.Context, projectID string) ([]storage.CodeSymbol, error)
+	FindSymbolsByFile(ctx context.Context, projectID, filePath string) ([]storage.CodeSymbol, error)
+	FindSymbolsByName(ctx context.Context, projectID, name string, symbolTypes []treesitter.SymbolType, limit int) ([]storage.CodeSymbol, error)
+	FindCodeCallers(ctx context.Context, projectID, calleeName string, limit int) ([]storage.CodeCall, error)
+}
+
+// Server answers the requests of one client. It handles them one at a time,
+// in the order they arrive.
+type Server struct {
+	store   Store
+	version string
+
+	initialized bool
+	shutdown    bool
+	// roots are the workspace folders of the client
+	roots []string
+	// projects are the indexed projects, listed again when a file is in none
+	projects []storage.CodeProject
+	// open are the documents open in the client by URI, with their unsaved
+	// changes
+	open map[string]*document
+}
+
+// NewServer returns a server of the code index of store. version is
+// reported to clients.
+func NewServer(store Store, version string) *Server {
+	return &Server{store: store, version: version, open: map[string]*document{}}
+}
+
+// Serve reads requests from r and writes their responses to w until the
+// client exits, r is closed or ctx is done.
+func (s *Server) Serve(ctx context.Context, r io.Reader, w io.Writer) error {
+	type read struct {
+		body []byte
+		err  error
+	}
+	messages := make(chan read)
+	go func() {
+		br := bufio.NewReader(r)
+		for {
+			body, err := readMessage(br)
+			select {
+			case messages <- read{body, err}:
+			case <-ctx.Done():
+				return
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	for {
+		var m read
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case m = <-messages:
+		}
+		if errors.Is(m.err, io.EOF) {
+			return nil
+		}
+		if m.err != nil {
+			return m.err
+		}
+
+		var req request
+		if err := json.Unmarshal(m.body, &req); err != nil {
+			if err := writeMessage(w, &response{JSONRPC: "2.0", Error: &responseError{Code: codeParseError, Message: err.Error()}}); err != nil {
+				return err
+			}
+			continue
+		}
+		if req.Method == "exit" {
+			if !s.shutdown {
+				return ErrExitWithoutShutdown
+			}
+			return nil
+		}
+
+		result, err := s.handle(ctx, &req)
+		if req.ID == nil {
+			if err != nil {
+				slog.Debug("LSP notification failed", "method", req.Method, "error", err)
+			}
+			continue
+		}
+		resp := &response{JSONRPC: "2.0", ID: req.ID}
+		if err != nil {
+			var respErr *responseError
+			if !errors.As(err, &respErr) {
+				respErr = &responseError{Code: codeInternalError, Message: err.Error()}
+			}
+			resp.Error = respErr
+		} else if resp.Result, err = json.Marshal(result); err != nil {
+			return fmt.Errorf("failed to encode %s result: %w", req.Method, err)
+		}
+		if err := writeMessage(w, resp); err != nil {
+			return err
+		}
+	}
+}
+
+// textDocumentParams are the parameters of requests on a document.
+type textDocumentParams struct {
+	TextDocument struct {
+		URI  string `json:"uri"`
+		Text string `json:"text"`
+	} `json:"textDocument"`
+	Position       Position `json:"position"`
+	ContentChanges []struct {
+		Text string `json:"text"`
+	} `json:"contentChanges"`
+	Context struct {
+		IncludeDeclaration bool `json:"includeDeclaration"`
+	} `json:"context"`
+}
+
+// handle runs a request or notification and returns its result.
+func (s *Server) handle(ctx context.Context, req *request) (any, error) {
+	if s.shutdown {
+		return nil, &responseError{Code: codeInvalidRequest, Message: "server is shut down"}
+	}
+	if !s.initialized && req.Method != "initialize" {
+		return nil, &responseError{Code: codeServerNotInitialized, Message: "server is not initialized"}
+	}
+
+	switch req.Method {
+	case "initialize":
+		return s.initialize(req.Params)
+	case "initialized", "textDocument/didSave", "$/cancelRequest", "$/setTrace":
+		return nil, nil
+	case "shutdown":
+		s.shutdown = true
+		return nil, nil
+	case "workspace/symbol":
+		var params struct {
+			Query string `json:"query"`
+		}
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, &responseError{Code: codeInvalidParams, Message: err.Error()}
+		}
+		return s.workspaceSymbols(ctx, params.Query)
+	case "textDocument/didOpen", "textDocument/didChange", "textDocument/didClose",
+		"textDocument/documentSymbol", "textDocument/definition", "textDocument/references":
+		return s.handleDocument(ctx, req)
+	}
+	return nil, &responseError{Code: codeMethodNotFound, Message: "method not supported: " + req.Method}
+}
+
+// handleDocument runs a request or notification on a document.
+func (s *Server) handleDocument(ctx context.Context, req *request) (any, error) {
+	var params textDocumentParams
+	if err := json.Unmarshal(req.Params, &params); err != nil {
+		return nil, &responseError{Code: codeInvalidParams, Message: err.Error()}
+	}
+	uri := params.TextDocument.URI
+	switch req.Method {
+	case "textDocument/didOpen":
+		s.open[uri] = newDocument(params.TextDocument.Text)
+	case "textDocument/didChange":
+		// Full synchronization: the last change is the whole text
+		if n := len(params.ContentChanges); n > 0 {
+			s.open[uri] = newDocument(params.ContentChanges[n-1].Text)
+		}
+	case "textDocument/didClose":
+		delete(s.open, uri)
+	case "textDocument/documentSymbol":
+		return s.documentSymbols(ctx, uri)
+	case "textDocument/definition":
+		return s.definition(ctx, uri, params.Position)
+	case "textDocument/references":
+		return s.references(ctx, uri, params.Position, params.Context.IncludeDeclaration)
+	}
+	return nil, nil
+}
+
+// initialize records the workspace folders of the client and returns the
+// capabilities of the server.
+func (s *Server) initialize(raw json.RawMessage) (any, error) {
+	var params struct {
+		RootURI          string `json:"rootUri"`
+		RootPath         string `json:"rootPath"`
+		WorkspaceFolders []struct {
+			URI string `json:"uri"`
+		} `json:"workspaceFolders"`
+	}
+	if err := json.Unmarshal(raw, &params); err != nil {
+		return nil, &responseError{Code: codeInvalidParams, Message: err.Error()}
+	}
+
+	s.roots = nil
+	for _, folder := range params.WorkspaceFolders {
+		if root, err := uriToPath(folder.URI); err == nil {
+			s.roots = append(s.roots, root)
+		}
+	}
+	if len(s.roots) == 0 {
+		if root, err := uriToPath(params.RootURI); err == nil {
+			s.roots = append(s.roots, root)
+		} else if params.RootPath != "" {
+			s.roots = append(s.roots, params.RootPath)
+		}
+	}
+	s.initialized = true
+
+	return map[string]any{
+		"capabilities": map[string]any{
+			"textDocumentSync":        map[string]any{"openClose": true, "change": 1},
+			"documentSymbolProvider":  true,
+			"workspaceSymbolProvider": true,
+			"definitionProvider":      true,
+			"referencesProvider":      true,
+		},
+		"serverInfo": map[string]any{"name": "remembrances-mcp", "version": s.version},
+	}, nil
+}
+
+// indexedFile is a file of an indexed project.
+type indexedFile struct {
+	project *storage.CodeProject
+	// path is relative to the project root, with forward slashes, as stored
+	path string
+}
+
+// fileOf returns the indexed project of the file of a URI, the one with the
+// deepest root when projects are nested, or false when no project has it.
+func (s *Server) fileOf(ctx context.Context, uri string) (indexedFile, bool, error) {
+	abs, err := uriToPath(uri)
+	if err != nil {
+		return indexedFile{}, false, &responseError{Code: codeInvalidParams, Message: err.Error()}
+	}
+	for attempt := 0; attempt < 2; attempt++ {
+		if attempt > 0 || s.projects == nil {
+			if s.projects, err = s.store.ListCodeProjects(ctx); err != nil {
+				return indexedFile{}, false, fmt.Errorf("failed to list projects: %w", err)
+			}
+		}
+		var file indexedFile
+		for i := range s.projects {
+			p := &s.projects[i]
+			rel, ok := relativeTo(p.RootPath, abs)
+			if ok && (file.project == nil || len(p.RootPath) > len(file.project.RootPath)) {
+				file = indexedFile{project: p, path: rel}
+			}
+		}
+		if file.project != nil {
+			return file, true, nil
+		}
+	}
+	return indexedFile{}, false, nil
+}
+
+// relativeTo returns the path of abs relative to root with forward slashes,
+// or false when it is not under root.
+func relativeTo(root, abs string) (string, bool) {
+	if root == "" {
+		return "", false
+	}
+	rel, err := filepath.Rel(root, abs)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return filepath.ToSlash(rel), true
+}
+
+// fileTexts reads the files of a project once per request. Stored symbol
+// offsets are into the indexed file on disk, not into unsaved changes.
+type fileTexts map[string]*document
+
+// get returns the text of a project file, or nil when it cannot be read.
+func (t fileTexts) get(project *storage.CodeProject, filePath string) *document {
+	key := project.ProjectID + "\x00" + filePath
+	if doc, ok := t[key]; ok {
+		return doc
+	}
+	var doc *document
+	if data, err := os.ReadFile(filepath.Join(project.RootPath, filepath.FromSlash(filePath))); err == nil {
+		doc = newDocument(string(data))
+	}
+	t[key] = doc
+	return doc
+}
+
+// symbolRanges returns the range of a symbol and of its name. Without the
+// file text, or when the file changed since it was indexed, they are the
+// lines of the symbol.
+func symbolRanges(doc *document, sym *storage.CodeSymbol) (Range, Range) {
+	if doc == nil || sym.StartByte < 0 || sym.EndByte > len(doc.text) || sym.StartByte >= sym.EndByte {
+		r := Range{
+			Start: Position{Line: max(sym.StartLine-1, 0)},
+			End:   Position{Line: max(sym.EndLine, sym.StartLine)},
+		}
+		return r, Range{Start: r.Start, End: r.Start}
+	}
+	full := doc.rangeOf(sym.StartByte, sym.EndByte)
+	if i := doc.findWord(sym.Name, sym.StartByte, sym.EndByte); i >= 0 {
+		return full, doc.rangeOf(i, i+len(sym.Name))
+	}
+	return full, Range{Start: full.Start, End: full.Start}
+}
+
+// documentSymbols returns the symbols of a document, nested by name path.
+func (s *Server) documentSymbols(ctx context.Context, uri string) ([]DocumentSymbol, error) {
+	file, ok, err := s.fileOf(ctx, uri)
+	if err != nil || !ok {
+		return []DocumentSymbol{}, err
+	}
+	symbols, err := s.store.FindSymbolsByFile(ctx, file.project.ProjectID, file.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get symbols of %s: %w", file.path, err)
+	}
+	doc := fileTexts{}.get(file.project, file.path)
+	return nestSymbols(doc, symbols), nil
+}
+
+// nestSymbols converts symbols to document symbols, each in the symbol of
+// the parent of its name path, sorted by position.
+func nestSymbols(doc *document, symbols []storage.CodeSymbol) []DocumentSymbol {
+	sort.SliceStable(symbols, func(i, j int) bool {
+		if symbols[i].StartLine != symbols[j].StartLine {
+			return symbols[i].StartLine < symbols[j].StartLine
+		}
+		return symbols[i].StartByte < symbols[j].StartByte
+	})
+	paths := make(map[string]bool, len(symbols))
+	for _, sym := range symbols {
+		paths[sym.NamePath] = true
+	}
+	children := map[string][]int{}
+	var roots []int
+	for i, sym := range symbols {
+		parent := path.Dir(sym.NamePath)
+		if parent != sym.NamePath && paths[parent] {
+			children[parent] = append(children[parent], i)
+		} else {
+			roots = append(roots, i)
+		}
+	}
+
+	var build func(indexes []int) []DocumentSymbol
+	build = func(indexes []int) []DocumentSymbol {
+		result := make([]DocumentSymbol, 0, len(indexes))
+		for _, i := range indexes {
+			sym := &symbols[i]
+			full, name := symbolRanges(doc, sym)
+			ds := DocumentSymbol{
+				Name:           sym.Name,
+				Kind:           symbolKind(sym.SymbolType),
+				Range:          full,
+				SelectionRange: name,
+			}
+			if sym.Signature != nil {
+				ds.Detail = *sym.Signature
+			}
+			// Name paths repeat for overloads; only the first gets the children
+			if kids, ok := children[sym.NamePath]; ok {
+				delete(children, sym.NamePath)
+				ds.Children = build(kids)
+			}
+			result = append(result, ds)
+		}
+		return result
+	}
+	return build(roots)
+}
+
+// workspaceSymbols returns the symbols of the projects of the workspace
+// whose names match query, best matches first.
+func (s *Server) workspaceSymbols(ctx context.Context, query string) ([]SymbolInformation, error) {
+	projects, err := s.store.ListCodeProjects(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list projects: %w", err)
+	}
+	s.projects = projects
+	if inWorkspace := s.workspaceProjects(projects); len(inWorkspace) > 0 {
+		projects = inWorkspace
+	}
+
+	type match struct {
+		info  SymbolInformation
+		score int
+	}
+	var matches []match
+	for i := range projects {
+		p := &projects[i]
+		symbols, err := s.store.ListCodeSymbols(ctx, p.ProjectID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list symbols of %s: %w", p.ProjectID, err)
+		}
+		for j := range symbols {
+			sym := &symbols[j]
+			score, ok := queryMatch(query, sym.Name)
+			if !ok {
+				continue
+			}
+			full, _ := symbolRanges(nil, sym)
+			info := SymbolInformation{
+				Name:     sym.Name,
+				Kind:     symbolKind(sym.SymbolType),
+				Location: Location{URI: projectFileURI(p, sym.FilePath), Range: full},
+			}
+			if parent := path.Dir(sym.NamePath); parent != "/" && parent != "." && parent != sym.NamePath {
+				info.ContainerName = path.Base(parent)
+			}
+			matches = append(matches, match{info: info, score: score})
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].score != matches[j].score {
+			return matches[i].score > matches[j].score
+		}
+		return len(matches[i].info.Name) < len(matches[j].info.Name)
+	})
+	result := make([]SymbolInformation, 0, min(len(matches), maxWorkspaceSymbols))
+	for _, m := range matches[:min(len(matches), maxWorkspaceSymbols)] {
+		result = append(result, m.info)
+	}
+	return result, nil
+}
+
+// workspaceProjects returns the projects inside a workspace folder or
+// containing one.
+func (s *Server) workspaceProjects(projects []storage.CodeProject) []storage.CodeProject {
+	var result []storage.CodeProject
+	for _, p := range projects {
+		for _, root := range s.roots {
+			_, inRoot := relativeTo(root, p.RootPath)
+			_, hasRoot := relativeTo(p.RootPath, root)
+			if inRoot || hasRoot {
+				result = append(result, p)
+				break
+			}
+		}
+	}
+	return result
+}
+
+// queryMatch reports whether the characters of query appear in name in
+// order, ignoring case, and scores the match: 3 for the same name, 2 for a
+// prefix, 1 for a substring and 0 otherwise. An empty query matches
+// everything.
+func queryMatch(query, name string) (int, bool) {
+	q, n := strings.ToLower(query), strings.ToLower(name)
+	switch {
+	case q == n:
+		return 3, true
+	case strings.HasPrefix(n, q):
+		return 2, true
+	case strings.Contains(n, q):
+		return 1, true
+	}
+	rest := []rune(q)
+	for _, r := range n {
+		if len(rest) > 0 && r == rest[0] {
+			rest = rest[1:]
+		}
+	}
+	return 0, len(rest) == 0
+}
+
+// identifierAt returns the indexed file of a URI and the identifier at a
+// position in its open or saved text.
+func (s *Server) identifierAt(ctx context.Context, uri string, pos Position) (indexedFile, string, error) {
+	file, ok, err := s.fileOf(ctx, uri)
+	if err != nil || !ok {
+		return indexedFile{}, "", err
+	}
+	doc := s.open[uri]
+	if doc == nil {
+		if doc = (fileTexts{}).get(file.project, file.path); doc == nil {
+			return indexedFile{}, "", nil
+		}
+	}
+	return file, doc.wordAt(pos), nil
+}
+
+// definitionLocations returns the name locations of the symbols of a project
+// named name, those in the file first.
+func (s *Server) definitionLocations(ctx context.Context, file indexedFile, name string, texts fileTexts) ([]Location, error) {
+	// FindSymbolsByName matches substrings, so keep exact names only
+	symbols, err := s.store.FindSymbolsByName(ctx, file.project.ProjectID, name, nil, maxDefinitions*4)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find symbols named %s: %w", name, err)
+	}
+	var definitions []storage.CodeSymbol
+	for _, sym := range symbols {
+		if sym.Name == name {
+			definitions = append(definitions, sym)
+		}
+	}
+	sort.SliceStable(definitions, func(i, j int) bool {
+		inI, inJ := definitions[i].FilePath == file.path, definitions[j].FilePath == file.path
+		if inI != inJ {
+			return inI
+		}
+		if definitions[i].FilePath != definitions[j].FilePath {
+			return definitions[i].FilePath < definitions[j].FilePath
+		}
+		return definitions[i].StartLine < definitions[j].StartLine
+	})
+
+	locations := make([]Location, 0, min(len(definitions), maxDefinitions))
+	for i := range definitions[:min(len(definitions), maxDefinitions)] {
+		sym := &definitions[i]
+		_, nameRange := symbolRanges(texts.get(file.project, sym.FilePath), sym)
+		locations = append(locations, Location{URI: projectFileURI(file.project, sym.FilePath), Range: nameRange})
+	}
+	return locations, nil
+}
+
+// definition returns the symbols named like the identifier at a position.
+func (s *Server) definition(ctx context.Context, uri string, pos Position) ([]Location, error) {
+	file, name, err := s.identifierAt(ctx, uri, pos)
+	if err != nil || name == "" {
+		return []Location{}, err
+	}
+	return s.definitionLocations(ctx, file, name, fileTexts{})
+}
+
+// references returns the calls to the functions and methods named like the
+// identifier at a position, from the call graph of its project, and with
+// includeDeclaration their definitions.
+func (s *Server) references(ctx context.Context, uri string, pos Position, includeDeclaration bool) ([]Location, error) {
+	file, name, err := s.identifierAt(ctx, uri, pos)
+	if err != nil || name == "" {
+		return []Location{}, err
+	}
+
+	texts := fileTexts{}
+	locations := []Location{}
+	if includeDeclaration {
+		if locations, err = s.definitionLocations(ctx, file, name, texts); err != nil {
+			return nil, err
+		}
+	}
+	calls, err := s.store.FindCodeCallers(ctx, file.project.ProjectID, name, maxReferences)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find calls to %s: %w", name, err)
+	}
+	sort.SliceStable(calls, func(i, j int) bool {
+		if calls[i].FilePath != calls[j].FilePath {
+			return calls[i].FilePath < calls[j].FilePath
+		}
+		return calls[i].Line < calls[j].Line
+	})
+	for _, call := range calls {
+		r := Range{Start: Position{Line: max(call.Line-1, 0)}}
+		r.End = r.Start
+		if doc := texts.get(file.project, call.FilePath); doc != nil {
+			if start, end, ok := doc.line(call.Line - 1); ok {
+				if i := doc.findWord(name, start, end); i >= 0 {
+					r = doc.rangeOf(i, i+len(name))
+				}
+			}
+		}
+		locations = append(locations, Location{URI: projectFileURI(file.project, call.FilePath), Range: r})
+	}
+	return locations, nil
+}
+
+// projectFileURI returns the URI of a file of a project.
+func projectFileURI(project *storage.CodeProject, filePath string) string {
+	return pathToURI(filepath.Join(project.RootPath, filepath.FromSlash(filePath)))
+}
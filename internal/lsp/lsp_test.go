@@ -0,0 +1,211 @@
+package lsp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/madeindigio/remembrances-mcp/internal/storage"
+	"github.com/madeindigio/remembrances-mcp/pkg/treesitter"
+)
+
+const userSource = `package app
+
+type UserService struct{}
+
+func (s *UserService) Create(name string) error {
+	return validate(name)
+}
+
+func validate(name string) error { return nil }
+`
+
+// fakeStore is a code index of one project.
+type fakeStore struct {
+	project storage.CodeProject
+	symbols []storage.CodeSymbol
+	calls   []storage.CodeCall
+}
+
+func (f *fakeStore) ListCodeProjects(ctx context.Context) ([]storage.CodeProject, error) {
+	return []storage.CodeProject{f.project}, nil
+}
+
+func (f *fakeStore) ListCodeSymbols(ctx context.Context, projectID string) ([]storage.CodeSymbol, error) {
+	return f.symbols, nil
+}
+
+func (f *fakeStore) FindSymbolsByFile(ctx context.Context, projectID, filePath string) ([]storage.CodeSymbol, error) {
+	var found []storage.CodeSymbol
+	for _, sym := range f.symbols {
+		if sym.FilePath == filePath {
+			found = append(found, sym)
+		}
+	}
+	return found, nil
+}
+
+func (f *fakeStore) FindSymbolsByName(ctx context.Context, projectID, name string, symbolTypes []treesitter.SymbolType, limit int) ([]storage.CodeSymbol, error) {
+	var found []storage.CodeSymbol
+	for _, sym := range f.symbols {
+		if strings.Contains(sym.Name, name) {
+			found = append(found, sym)
+		}
+	}
+	return found, nil
+}
+
+func (f *fakeStore) FindCodeCallers(ctx context.Context, projectID, calleeName string, limit int) ([]storage.CodeCall, error) {
+	var found []storage.CodeCall
+	for _, call := range f.calls {
+		if call.CalleeName == calleeName {
+			found = append(found, call)
+		}
+	}
+	return found, nil
+}
+
+// symbolAt returns a stored symbol spanning from the first occurrence of
+// start to the end of the first occurrence of end after it.
+func symbolAt(t *testing.T, name, namePath string, symbolType treesitter.SymbolType, start, end string) storage.CodeSymbol {
+	t.Helper()
+	from := strings.Index(userSource, start)
+	to := strings.Index(userSource[from:], end) + from + len(end)
+	if from < 0 || to < from {
+		t.Fatalf("symbol %s not found in source", name)
+	}
+	return storage.CodeSymbol{
+		ProjectID:  "app",
+		FilePath:   "user.go",
+		SymbolType: symbolType,
+		Name:       name,
+		NamePath:   namePath,
+		StartLine:  strings.Count(userSource[:from], "\n") + 1,
+		EndLine:    strings.Count(userSource[:to], "\n") + 1,
+		StartByte:  from,
+		EndByte:    to,
+	}
+}
+
+// session runs requests against a server and returns the responses by ID.
+func session(t *testing.T, store Store, requests ...string) map[int]response {
+	t.Helper()
+	var in bytes.Buffer
+	for _, req := range requests {
+		fmt.Fprintf(&in, "Content-Length: %d\r\n\r\n%s", len(req), req)
+	}
+	var out bytes.Buffer
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := NewServer(store, "test").Serve(ctx, &in, &out); err != nil {
+		t.Fatalf("Serve: %v", err)
+	}
+
+	responses := map[int]response{}
+	r := bufio.NewReader(&out)
+	for {
+		body, err := readMessage(r)
+		if err != nil {
+			break
+		}
+		var resp response
+		if err := json.Unmarshal(body, &resp); err != nil {
+			t.Fatalf("invalid response %s: %v", body, err)
+		}
+		var id int
+		if resp.ID != nil {
+			json.Unmarshal(*resp.ID, &id)
+		}
+		responses[id] = resp
+	}
+	return responses
+}
+
+func TestServer(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "user.go"), []byte(userSource), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	store := &fakeStore{
+		project: storage.CodeProject{ProjectID: "app", RootPath: root},
+		symbols: []storage.CodeSymbol{
+			symbolAt(t, "UserService", "/UserService", treesitter.SymbolTypeStruct, "type UserService", "{}"),
+			symbolAt(t, "Create", "/UserService/Create", treesitter.SymbolTypeMethod, "func (s *UserService)", "}\n"),
+			symbolAt(t, "validate", "/validate", treesitter.SymbolTypeFunction, "func validate", "nil }"),
+		},
+		calls: []storage.CodeCall{{FilePath: "user.go", CallerNamePath: "/UserService/Create", CalleeName: "validate", Line: 6}},
+	}
+	uri := pathToURI(filepath.Join(root, "user.go"))
+
+	responses := session(t, store,
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"rootUri":"`+pathToURI(root)+`"}}`,
+		`{"jsonrpc":"2.0","method":"initialized","params":{}}`,
+		`{"jsonrpc":"2.0","id":2,"method":"textDocument/documentSymbol","params":{"textDocument":{"uri":"`+uri+`"}}}`,
+		`{"jsonrpc":"2.0","id":3,"method":"workspace/symbol","params":{"query":"usrsvc"}}`,
+		`{"jsonrpc":"2.0","id":4,"method":"textDocument/definition","params":{"textDocument":{"uri":"`+uri+`"},"position":{"line":5,"character":10}}}`,
+		`{"jsonrpc":"2.0","id":5,"method":"textDocument/references","params":{"textDocument":{"uri":"`+uri+`"},"position":{"line":8,"character":6},"context":{"includeDeclaration":true}}}`,
+		`{"jsonrpc":"2.0","id":6,"method":"textDocument/hover","params":{}}`,
+		`{"jsonrpc":"2.0","id":7,"method":"shutdown"}`,
+		`{"jsonrpc":"2.0","method":"exit"}`,
+	)
+
+	var symbols []DocumentSymbol
+	json.Unmarshal(responses[2].Result, &symbols)
+	if len(symbols) != 2 || symbols[0].Name != "UserService" || symbols[1].Name != "validate" {
+		t.Fatalf("documentSymbol = %+v, want UserService and validate at the top level", symbols)
+	}
+	if len(symbols[0].Children) != 1 || symbols[0].Children[0].Name != "Create" || symbols[0].Children[0].Kind != SymbolKindMethod {
+		t.Errorf("UserService children = %+v, want the Create method", symbols[0].Children)
+	}
+	if got := symbols[1].SelectionRange; got != (Range{Start: Position{8, 5}, End: Position{8, 13}}) {
+		t.Errorf("validate selection range = %+v", got)
+	}
+
+	var found []SymbolInformation
+	json.Unmarshal(responses[3].Result, &found)
+	if len(found) != 1 || found[0].Name != "UserService" || found[0].Location.URI != uri {
+		t.Errorf("workspace/symbol = %+v, want UserService", found)
+	}
+
+	var definitions []Location
+	json.Unmarshal(responses[4].Result, &definitions)
+	if len(definitions) != 1 || definitions[0].Range.Start != (Position{8, 5}) {
+		t.Errorf("definition = %+v, want validate on line 8", definitions)
+	}
+
+	var references []Location
+	json.Unmarshal(responses[5].Result, &references)
+	if len(references) != 2 || references[1].Range != (Range{Start: Position{5, 8}, End: Position{5, 16}}) {
+		t.Errorf("references = %+v, want the declaration and the call on line 5", references)
+	}
+
+	if responses[6].Error == nil || responses[6].Error.Code != codeMethodNotFound {
+		t.Errorf("hover response = %+v, want method not found", responses[6])
+	}
+	if string(responses[7].Result) != "null" {
+		t.Errorf("shutdown result = %s, want null", responses[7].Result)
+	}
+}
+
+func TestDocumentPositions(t *testing.T) {
+	doc := newDocument("a := \"é😀\"\nx := y\n")
+	emoji := strings.Index(doc.text, "😀")
+	if got := doc.position(emoji); got != (Position{0, 7}) {
+		t.Errorf("position of the emoji = %+v, want 0:7", got)
+	}
+	if got := doc.offset(Position{0, 7}); got != emoji {
+		t.Errorf("offset of 0:7 = %d, want %d", got, emoji)
+	}
+	if got := doc.wordAt(Position{1, 5}); got != "y" {
+		t.Errorf("wordAt 1:5 = %q, want y", got)
+	}
+	if got := doc.findWord("x", 0, len(doc.text)); got != strings.Index(doc.text, "x") {
+		t.Errorf("findWord x = %d", got)
+	}
+}
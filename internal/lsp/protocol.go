@@ -0,0 +1,225 @@
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/madeindigio/remembrances-mcp/pkg/treesitter"
+)
+
+// JSON-RPC error codes used by the server.
+const (
+	codeParseError     = -32700
+	codeInvalidRequest = -32600
+	codeInvalidParams  = -32602
+	codeMethodNotFound = -32601
+	codeInternalError  = -32603
+	// codeServerNotInitialized is returned to requests before initialize
+	codeServerNotInitialized = -32002
+)
+
+// maxMessageBytes bounds the content of a message.
+const maxMessageBytes = 64 << 20
+
+// request is a JSON-RPC request, or a notification when it has no ID.
+type request struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id,omitempty"`
+	Method  string           `json:"method"`
+	Params  json.RawMessage  `json:"params,omitempty"`
+}
+
+// response is the response to a request: its result, which may be null, or
+// its error.
+type response struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id"`
+	Result  json.RawMessage  `json:"result,omitempty"`
+	Error   *responseError   `json:"error,omitempty"`
+}
+
+// responseError is the error of a failed request.
+type responseError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *responseError) Error() string {
+	return e.Message
+}
+
+// readMessage reads a message framed by a Content-Length header.
+func readMessage(r *bufio.Reader) ([]byte, error) {
+	length := -1
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			if errors.Is(err, io.EOF) && line == "" && length < 0 {
+				return nil, io.EOF
+			}
+			return nil, fmt.Errorf("failed to read header: %w", err)
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			break
+		}
+		name, value, ok := strings.Cut(line, ":")
+		if !ok {
+			return nil, fmt.Errorf("malformed header %q", line)
+		}
+		if strings.EqualFold(strings.TrimSpace(name), "Content-Length") {
+			length, err = strconv.Atoi(strings.TrimSpace(value))
+			if err != nil || length < 0 || length > maxMessageBytes {
+				return nil, fmt.Errorf("invalid Content-Length %q", value)
+			}
+		}
+	}
+	if length < 0 {
+		return nil, errors.New("missing Content-Length header")
+	}
+	body := make([]byte, length)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, fmt.Errorf("failed to read message: %w", err)
+	}
+	return body, nil
+}
+
+// writeMessage writes msg framed by a Content-Length header.
+func writeMessage(w io.Writer, msg any) error {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "Content-Length: %d\r\n\r\n", len(body)); err != nil {
+		return err
+	}
+	_, err = w.Write(body)
+	return err
+}
+
+// Position is a zero-based line and UTF-16 character offset in a document.
+type Position struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+// Range is a range of a document, its end exclusive.
+type Range struct {
+	Start Position `json:"start"`
+	End   Position `json:"end"`
+}
+
+// Location is a range of a document.
+type Location struct {
+	URI   string `json:"uri"`
+	Range Range  `json:"range"`
+}
+
+// DocumentSymbol is a symbol of a document with the symbols it contains.
+type DocumentSymbol struct {
+	Name           string           `json:"name"`
+	Detail         string           `json:"detail,omitempty"`
+	Kind           SymbolKind       `json:"kind"`
+	Range          Range            `json:"range"`
+	SelectionRange Range            `json:"selectionRange"`
+	Children       []DocumentSymbol `json:"children,omitempty"`
+}
+
+// SymbolInformation is a symbol found by workspace/symbol.
+type SymbolInformation struct {
+	Name          string     `json:"name"`
+	Kind          SymbolKind `json:"kind"`
+	Location      Location   `json:"location"`
+	ContainerName string     `json:"containerName,omitempty"`
+}
+
+// SymbolKind is the kind of a symbol, as numbered by the protocol.
+type SymbolKind int
+
+// Symbol kinds the indexed symbol types map to.
+const (
+	SymbolKindModule      SymbolKind = 2
+	SymbolKindNamespace   SymbolKind = 3
+	SymbolKindPackage     SymbolKind = 4
+	SymbolKindClass       SymbolKind = 5
+	SymbolKindMethod      SymbolKind = 6
+	SymbolKindProperty    SymbolKind = 7
+	SymbolKindField       SymbolKind = 8
+	SymbolKindConstructor SymbolKind = 9
+	SymbolKindEnum        SymbolKind = 10
+	SymbolKindInterface   SymbolKind = 11
+	SymbolKindFunction    SymbolKind = 12
+	SymbolKindVariable    SymbolKind = 13
+	SymbolKindConstant    SymbolKind = 14
+	SymbolKindEnumMember  SymbolKind = 22
+	SymbolKindStruct      SymbolKind = 23
+)
+
+// symbolKind returns the protocol kind of an indexed symbol type.
+func symbolKind(t treesitter.SymbolType) SymbolKind {
+	switch t {
+	case treesitter.SymbolTypeClass, treesitter.SymbolTypeComponent, treesitter.SymbolTypeTypeAlias:
+		return SymbolKindClass
+	case treesitter.SymbolTypeStruct, treesitter.SymbolTypeTable, treesitter.SymbolTypeView,
+		treesitter.SymbolTypeMessage:
+		return SymbolKindStruct
+	case treesitter.SymbolTypeInterface, treesitter.SymbolTypeTrait, treesitter.SymbolTypeService:
+		return SymbolKindInterface
+	case treesitter.SymbolTypeMethod:
+		return SymbolKindMethod
+	case treesitter.SymbolTypeConstructor:
+		return SymbolKindConstructor
+	case treesitter.SymbolTypeProperty:
+		return SymbolKindProperty
+	case treesitter.SymbolTypeField:
+		return SymbolKindField
+	case treesitter.SymbolTypeConstant:
+		return SymbolKindConstant
+	case treesitter.SymbolTypeEnum:
+		return SymbolKindEnum
+	case treesitter.SymbolTypeEnumMember:
+		return SymbolKindEnumMember
+	case treesitter.SymbolTypeNamespace:
+		return SymbolKindNamespace
+	case treesitter.SymbolTypeModule:
+		return SymbolKindModule
+	case treesitter.SymbolTypePackage:
+		return SymbolKindPackage
+	case treesitter.SymbolTypeVariable:
+		return SymbolKindVariable
+	}
+	return SymbolKindFunction
+}
+
+// uriToPath returns the file system path of a file URI.
+func uriToPath(uri string) (string, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return "", fmt.Errorf("invalid URI %q: %w", uri, err)
+	}
+	if u.Scheme != "file" {
+		return "", fmt.Errorf("unsupported URI scheme %q", u.Scheme)
+	}
+	path := u.Path
+	// file:///C:/dir on Windows
+	if len(path) >= 3 && path[0] == '/' && path[2] == ':' {
+		path = path[1:]
+	}
+	return filepath.FromSlash(path), nil
+}
+
+// pathToURI returns the file URI of an absolute path.
+func pathToURI(path string) string {
+	path = filepath.ToSlash(path)
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	return (&url.URL{Scheme: "file", Path: path}).String()
+}
@@ -0,0 +1,32 @@
+package storage
+
+import "slices"
+
+// chunkWindow returns the range of chunk indexes read to give each of indexes
+// radius neighbors on either side.
+func chunkWindow(indexes []int, radius int) (from, to int) {
+	if len(indexes) == 0 {
+		return 0, -1
+	}
+	return max(slices.Min(indexes)-radius, 0), slices.Max(indexes) + radius
+}
+
+// chunkContext merges, for each of indexes, the chunks from index-radius to
+// index+radius found in chunks into one excerpt. Indexes whose chunk is not
+// in chunks are left out.
+func chunkContext(chunks map[int]string, indexes []int, radius int) map[int]string {
+	out := make(map[int]string, len(indexes))
+	for _, index := range indexes {
+		if _, ok := chunks[index]; !ok {
+			continue
+		}
+		var window []string
+		for i := index - radius; i <= index+radius; i++ {
+			if chunk, ok := chunks[i]; ok {
+				window = append(window, chunk)
+			}
+		}
+		out[index] = MergeChunkContents(window)
+	}
+	return out
+}
@@ -0,0 +1,31 @@
+package storage
+
+import "testing"
+
+func TestChunkWindow(t *testing.T) {
+	if from, to := chunkWindow([]int{4, 1, 7}, 2); from != 0 || to != 9 {
+		t.Errorf("chunkWindow() = %d, %d; want 0, 9", from, to)
+	}
+	if from, to := chunkWindow(nil, 2); from <= to {
+		t.Errorf("chunkWindow(nil) = %d, %d; want an empty range", from, to)
+	}
+}
+
+func TestChunkContext(t *testing.T) {
+	chunks := map[int]string{
+		0: "First paragraph.",
+		1: "Second paragraph.",
+		2: "Third paragraph.",
+		4: "Fifth paragraph.",
+	}
+	got := chunkContext(chunks, []int{0, 3, 4}, 1)
+	if want := "First paragraph.\nSecond paragraph."; got[0] != want {
+		t.Errorf("chunk 0 = %q, want %q", got[0], want)
+	}
+	if want := "Fifth paragraph."; got[4] != want {
+		t.Errorf("chunk 4 = %q, want %q", got[4], want)
+	}
+	if _, ok := got[3]; ok {
+		t.Error("chunk 3 is missing from chunks but has context")
+	}
+}
@@ -0,0 +1,32 @@
+package storage
+
+import "log/slog"
+
+// fitEmbeddingDimension returns embedding with the schema dimension. A nil
+// embedding becomes a zero vector. Other lengths are padded with zeros or
+// truncated, or rejected with an *EmbeddingDimensionError when strict.
+func fitEmbeddingDimension(table string, embedding []float32, strict bool, model string) ([]float32, error) {
+	if embedding == nil {
+		return make([]float32, defaultMtreeDim), nil
+	}
+	if len(embedding) == defaultMtreeDim {
+		return embedding, nil
+	}
+	if strict {
+		return nil, &EmbeddingDimensionError{Table: table, Expected: defaultMtreeDim, Got: len(embedding), Model: model}
+	}
+	slog.Debug("resizing embedding to the schema dimension", "table", table, "got", len(embedding), "expected", defaultMtreeDim)
+	norm := make([]float32, defaultMtreeDim)
+	copy(norm, embedding)
+	return norm, nil
+}
+
+// fitEmbedding checks and normalizes an embedding written to table according
+// to the configured dimension checking.
+func (s *SurrealDBStorage) fitEmbedding(table string, embedding []float32) ([]float32, error) {
+	strict, model := false, ""
+	if s.config != nil {
+		strict, model = s.config.StrictEmbeddingDimension, s.embeddingModels().active(table)
+	}
+	return fitEmbeddingDimension(table, embedding, strict, model)
+}
@@ -0,0 +1,220 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+)
+
+// embeddingModelTables are the tables whose records carry an embedding, and
+// with it the embedding_model and embedding_dim of the model that made it.
+var embeddingModelTables = []string{"vector_memories", "knowledge_base", "kb_document_versions", "events", "code_symbols", "code_chunks"}
+
+// EmbeddingRouteTables maps the record kinds embedders are routed to
+// (vectors, knowledge_base, events and code) to the tables holding them.
+var EmbeddingRouteTables = map[string][]string{
+	"vectors":        {"vector_memories"},
+	"knowledge_base": {"knowledge_base", "kb_document_versions"},
+	"events":         {"events"},
+	"code":           {"code_symbols", "code_chunks"},
+}
+
+// TableEmbeddingModels maps every table to the model of its record kind, for
+// ConnectionConfig.TableEmbeddingModels. Kinds without a model are left out.
+func TableEmbeddingModels(kindModels map[string]string) map[string]string {
+	tables := map[string]string{}
+	for kind, model := range kindModels {
+		if model == "" {
+			continue
+		}
+		for _, table := range EmbeddingRouteTables[kind] {
+			tables[table] = model
+		}
+	}
+	return tables
+}
+
+// isCodeEmbeddingTable reports whether table is embedded by the code
+// embedding model.
+func isCodeEmbeddingTable(table string) bool {
+	return table == "code_symbols" || table == "code_chunks"
+}
+
+// embeddingModelRoutes names the model embedding the records of each table.
+type embeddingModelRoutes struct {
+	model     string            // Main embedding model
+	codeModel string            // Model of the code tables, when configured
+	tables    map[string]string // Model routed to a table, overriding both
+}
+
+type codeEmbeddingModelKey struct{}
+
+// WithCodeEmbeddingModel returns a context in which code symbols and chunks
+// are written and searched as embedded by model, the embedding model of
+// their project, instead of the configured code model.
+func WithCodeEmbeddingModel(ctx context.Context, model string) context.Context {
+	return context.WithValue(ctx, codeEmbeddingModelKey{}, model)
+}
+
+// in returns the routes with the code tables routed to the model set by
+// WithCodeEmbeddingModel on ctx, if any.
+func (r embeddingModelRoutes) in(ctx context.Context) embeddingModelRoutes {
+	model, _ := ctx.Value(codeEmbeddingModelKey{}).(string)
+	if model == "" {
+		return r
+	}
+	tables := make(map[string]string, len(r.tables)+2)
+	for table, routed := range r.tables {
+		tables[table] = routed
+	}
+	for _, table := range EmbeddingRouteTables["code"] {
+		tables[table] = model
+	}
+	r.tables = tables
+	return r
+}
+
+// active returns the model embedding new records of table: the model routed
+// to it, the code model for code tables when one is configured, or the main
+// model.
+func (r embeddingModelRoutes) active(table string) string {
+	if routed := r.tables[table]; routed != "" {
+		return routed
+	}
+	if isCodeEmbeddingTable(table) && r.codeModel != "" {
+		return r.codeModel
+	}
+	return r.model
+}
+
+// of returns the model recorded for an embedding of dim components written
+// to table; embeddings that are missing have none.
+func (r embeddingModelRoutes) of(table string, dim int) string {
+	if dim == 0 {
+		return ""
+	}
+	return r.active(table)
+}
+
+// staleEmbeddingModel reports whether records embedded by recorded are
+// skipped by searches while active is the configured model. Records of an
+// unknown model, written before models were recorded, are still searched.
+func staleEmbeddingModel(recorded, active string) bool {
+	return recorded != "" && active != "" && recorded != active
+}
+
+func (s *SurrealDBStorage) embeddingModels() embeddingModelRoutes {
+	if s.config == nil {
+		return embeddingModelRoutes{}
+	}
+	return embeddingModelRoutes{
+		model:     s.config.EmbeddingModel,
+		codeModel: s.config.CodeEmbeddingModel,
+		tables:    s.config.TableEmbeddingModels,
+	}
+}
+
+// bindEmbeddingModel binds $embedding_model and $embedding_dim for a record
+// of table whose embedding had dim components before it was fitted to the
+// schema dimension.
+func (s *SurrealDBStorage) bindEmbeddingModel(ctx context.Context, params map[string]interface{}, table string, dim int) {
+	params["embedding_model"] = s.embeddingModels().in(ctx).of(table, dim)
+	params["embedding_dim"] = dim
+}
+
+// embeddingModelClause returns the WHERE fragment keeping the records of
+// table embedded by the active model, or by an unknown one, and binds
+// $embedding_model. It is empty when no model is configured.
+func (s *SurrealDBStorage) embeddingModelClause(ctx context.Context, table string, params map[string]interface{}) string {
+	active := s.embeddingModels().in(ctx).active(table)
+	if active == "" {
+		return ""
+	}
+	params["embedding_model"] = active
+	return " AND (embedding_model ?? '') IN ['', $embedding_model]"
+}
+
+// EmbeddingModels counts the embedded records of every table by the model
+// that embedded them.
+func (s *SurrealDBStorage) EmbeddingModels(ctx context.Context) ([]EmbeddingModelCount, error) {
+	routes := s.embeddingModels()
+	var counts []EmbeddingModelCount
+	for _, table := range embeddingModelTables {
+		query := fmt.Sprintf("SELECT embedding_model, count() AS count FROM %s WHERE embedding != NONE GROUP BY embedding_model", table)
+		result, err := s.query(ctx, query, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count embedding models of %s: %w", table, err)
+		}
+		if result == nil || len(*result) == 0 {
+			continue
+		}
+		active := routes.active(table)
+		for _, row := range (*result)[0].Result {
+			recorded := getString(row, "embedding_model")
+			counts = append(counts, EmbeddingModelCount{
+				Table: table,
+				Model: recorded,
+				Count: convertToInt(row["count"]),
+				Stale: staleEmbeddingModel(recorded, active),
+			})
+		}
+	}
+	return counts, nil
+}
+
+// SetCodeEmbeddingModel names the model embedding code symbols and chunks
+// when it differs from the main embedding model.
+func (p *PostgresStorage) SetCodeEmbeddingModel(model string) {
+	p.codeEmbeddingModel = model
+}
+
+// SetTableEmbeddingModels names the model routed to each table, overriding
+// the main and code embedding models (see TableEmbeddingModels).
+func (p *PostgresStorage) SetTableEmbeddingModels(models map[string]string) {
+	p.tableEmbeddingModels = models
+}
+
+func (p *PostgresStorage) embeddingModels() embeddingModelRoutes {
+	return embeddingModelRoutes{model: p.embeddingModel, codeModel: p.codeEmbeddingModel, tables: p.tableEmbeddingModels}
+}
+
+// embeddingModelOf returns the model recorded for an embedding written to
+// table.
+func (p *PostgresStorage) embeddingModelOf(ctx context.Context, table string, embedding []float32) string {
+	return p.embeddingModels().in(ctx).of(table, len(embedding))
+}
+
+// embeddingModelClause returns the WHERE fragment keeping the records of
+// table embedded by the active model, or by an unknown one. It is empty when
+// no model is configured.
+func (p *PostgresStorage) embeddingModelClause(ctx context.Context, table string, args *pgArgs) string {
+	active := p.embeddingModels().in(ctx).active(table)
+	if active == "" {
+		return ""
+	}
+	return fmt.Sprintf(" AND coalesce(embedding_model, '') IN ('', %s)", args.add(active))
+}
+
+// EmbeddingModels counts the embedded records of every table by the model
+// that embedded them.
+func (p *PostgresStorage) EmbeddingModels(ctx context.Context) ([]EmbeddingModelCount, error) {
+	routes := p.embeddingModels()
+	var counts []EmbeddingModelCount
+	for _, table := range embeddingModelTables {
+		query := fmt.Sprintf("SELECT coalesce(embedding_model, '') AS embedding_model, count(*) AS count FROM %s WHERE embedding IS NOT NULL GROUP BY 1 ORDER BY 1", table)
+		rows, err := p.rows(ctx, query)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count embedding models of %s: %w", table, err)
+		}
+		active := routes.active(table)
+		for _, row := range rows {
+			recorded := getString(row, "embedding_model")
+			counts = append(counts, EmbeddingModelCount{
+				Table: table,
+				Model: recorded,
+				Count: convertToInt(row["count"]),
+				Stale: staleEmbeddingModel(recorded, active),
+			})
+		}
+	}
+	return counts, nil
+}
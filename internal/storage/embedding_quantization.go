@@ -0,0 +1,269 @@
+package storage
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// EmbeddingFormat controls how embedding vectors are persisted in SurrealDB.
+type EmbeddingFormat string
+
+const (
+	// EmbeddingFormatFloat32 stores embeddings with full precision (default).
+	EmbeddingFormatFloat32 EmbeddingFormat = "float32"
+	// EmbeddingFormatFloat16 packs the embeddings of code symbols and chunks
+	// into their embedding_f16 field as IEEE-754 half precision values, 2
+	// bytes per component instead of the 8 SurrealDB spends on a float.
+	// SurrealDB cannot index or compare packed vectors, so code searches,
+	// which scan every embedding of a project anyway, score them in Go; the
+	// other tables are searched through their vector indexes and keep float32.
+	EmbeddingFormatFloat16 EmbeddingFormat = "float16"
+	// EmbeddingFormatInt8 stores symmetric per-vector quantized integers in
+	// [-127, 127], which SurrealDB encodes as variable-length integers instead
+	// of 8-byte floats. The vector indexes use DIST COSINE (migration v35), and
+	// cosine similarity ignores the per-vector scale, so ranking only changes
+	// by the rounding error.
+	EmbeddingFormatInt8 EmbeddingFormat = "int8"
+)
+
+// int8QuantizationLevels is the largest magnitude produced by int8 quantization.
+const int8QuantizationLevels = 127
+
+// ParseEmbeddingFormat validates a configured embedding storage format.
+// An empty string selects the default float32 format.
+func ParseEmbeddingFormat(value string) (EmbeddingFormat, error) {
+	switch EmbeddingFormat(strings.ToLower(strings.TrimSpace(value))) {
+	case "", EmbeddingFormatFloat32:
+		return EmbeddingFormatFloat32, nil
+	case EmbeddingFormatFloat16:
+		return EmbeddingFormatFloat16, nil
+	case EmbeddingFormatInt8:
+		return EmbeddingFormatInt8, nil
+	default:
+		return "", fmt.Errorf("unsupported embedding storage format %q (expected float32, float16 or int8)", value)
+	}
+}
+
+// embeddingFormat returns the configured embedding format, falling back to float32.
+func (s *SurrealDBStorage) embeddingFormat() EmbeddingFormat {
+	if s.config == nil {
+		return EmbeddingFormatFloat32
+	}
+	format, err := ParseEmbeddingFormat(string(s.config.EmbeddingFormat))
+	if err != nil {
+		return EmbeddingFormatFloat32
+	}
+	return format
+}
+
+// storedEmbedding converts an embedding into the value persisted for the configured
+// format. Callers are responsible for normalizing the dimension beforehand.
+func (s *SurrealDBStorage) storedEmbedding(embedding []float32) interface{} {
+	return encodeEmbedding(s.embeddingFormat(), embedding)
+}
+
+// bindCodeEmbedding binds a fitted code symbol or chunk embedding to
+// $embedding, or packed to $embedding_f16 for the float16 format. The other
+// parameter is left unbound, so it reads as NONE in statements setting both.
+func (s *SurrealDBStorage) bindCodeEmbedding(params map[string]interface{}, embedding []float32) {
+	if s.embeddingFormat() == EmbeddingFormatFloat16 {
+		delete(params, "embedding")
+		params["embedding_f16"] = packFloat16(embedding)
+		return
+	}
+	params["embedding"] = s.storedEmbedding(embedding)
+}
+
+// unpackEmbeddings replaces the embedding_f16 field of code symbol and chunk
+// rows with the embedding it packs, so they decode like unpacked rows.
+func unpackEmbeddings(rows []map[string]interface{}) {
+	for _, row := range rows {
+		if packed, ok := row["embedding_f16"].([]byte); ok {
+			row["embedding"] = unpackFloat16(packed)
+			delete(row, "embedding_f16")
+		}
+	}
+}
+
+// decodeStoredEmbedding converts a raw embedding field back into float32 components,
+// dequantizing int8 data. Dequantized vectors keep their direction but not their norm.
+func (s *SurrealDBStorage) decodeStoredEmbedding(raw interface{}) []float32 {
+	values, ok := raw.([]interface{})
+	if !ok {
+		return nil
+	}
+
+	embedding := make([]float32, len(values))
+	for i, v := range values {
+		switch n := v.(type) {
+		case float64:
+			embedding[i] = float32(n)
+		case float32:
+			embedding[i] = n
+		case int:
+			embedding[i] = float32(n)
+		case int64:
+			embedding[i] = float32(n)
+		case uint64:
+			embedding[i] = float32(n)
+		}
+	}
+
+	if s.embeddingFormat() == EmbeddingFormatInt8 {
+		return dequantizeInt8(embedding)
+	}
+	return embedding
+}
+
+// encodeEmbedding converts an embedding to the representation used by format.
+func encodeEmbedding(format EmbeddingFormat, embedding []float32) interface{} {
+	if format == EmbeddingFormatInt8 {
+		return quantizeInt8(embedding)
+	}
+	emb64 := make([]float64, len(embedding))
+	for i, v := range embedding {
+		emb64[i] = float64(v)
+	}
+	return emb64
+}
+
+// quantizeInt8 maps the largest component magnitude to 127 and rounds the rest.
+func quantizeInt8(embedding []float32) []int {
+	quantized := make([]int, len(embedding))
+	for i, v := range scaleToInt8Range(embedding) {
+		quantized[i] = int(math.Round(float64(v)))
+	}
+	return quantized
+}
+
+// dequantizeInt8 maps quantized components back to the [-1, 1] range.
+func dequantizeInt8(quantized []float32) []float32 {
+	embedding := make([]float32, len(quantized))
+	for i, v := range quantized {
+		embedding[i] = v / int8QuantizationLevels
+	}
+	return embedding
+}
+
+// scaleToInt8Range rescales an embedding so its largest magnitude equals 127.
+func scaleToInt8Range(embedding []float32) []float32 {
+	var maxAbs float64
+	for _, v := range embedding {
+		if a := math.Abs(float64(v)); a > maxAbs {
+			maxAbs = a
+		}
+	}
+
+	scaled := make([]float32, len(embedding))
+	if maxAbs == 0 {
+		return scaled
+	}
+
+	factor := int8QuantizationLevels / maxAbs
+	for i, v := range embedding {
+		scaled[i] = float32(float64(v) * factor)
+	}
+	return scaled
+}
+
+// cosineSimilarity computes the similarity vector::similarity::cosine ranks
+// by, for embeddings SurrealDB cannot compare. Vectors of different lengths
+// or with a zero norm have no similarity.
+func cosineSimilarity(a, b []float32) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+	var dot, na, nb float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		na += float64(a[i]) * float64(a[i])
+		nb += float64(b[i]) * float64(b[i])
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / math.Sqrt(na*nb)
+}
+
+// packFloat16 encodes an embedding as little-endian IEEE-754 half precision
+// values.
+func packFloat16(embedding []float32) []byte {
+	packed := make([]byte, 2*len(embedding))
+	for i, v := range embedding {
+		binary.LittleEndian.PutUint16(packed[2*i:], float32ToFloat16(v))
+	}
+	return packed
+}
+
+// unpackFloat16 decodes an embedding encoded by packFloat16.
+func unpackFloat16(packed []byte) []float32 {
+	embedding := make([]float32, len(packed)/2)
+	for i := range embedding {
+		embedding[i] = float16ToFloat32(binary.LittleEndian.Uint16(packed[2*i:]))
+	}
+	return embedding
+}
+
+// float32ToFloat16 converts a float32 to IEEE-754 half precision bits,
+// rounding to nearest.
+func float32ToFloat16(f float32) uint16 {
+	bits := math.Float32bits(f)
+	sign := uint16((bits >> 16) & 0x8000)
+	rawExp := (bits >> 23) & 0xff
+	mant := bits & 0x7fffff
+
+	if rawExp == 0xff {
+		if mant != 0 {
+			return sign | 0x7e00 // NaN
+		}
+		return sign | 0x7c00 // Inf
+	}
+
+	exp := int32(rawExp) - 127 + 15
+	switch {
+	case exp >= 0x1f:
+		return sign | 0x7c00 // Overflow to Inf
+	case exp <= 0:
+		if exp < -10 {
+			return sign // Underflow to signed zero
+		}
+		mant |= 0x800000
+		shift := uint32(14 - exp)
+		half := uint16(mant >> shift)
+		if (mant>>(shift-1))&1 != 0 {
+			half++
+		}
+		return sign | half
+	}
+
+	half := sign | uint16(exp)<<10 | uint16(mant>>13)
+	if mant&0x1000 != 0 {
+		half++ // A carry into the exponent is still the correctly rounded value
+	}
+	return half
+}
+
+// float16ToFloat32 converts IEEE-754 half precision bits to float32.
+func float16ToFloat32(h uint16) float32 {
+	sign := uint32(h&0x8000) << 16
+	exp := uint32(h>>10) & 0x1f
+	mant := uint32(h & 0x3ff)
+
+	switch exp {
+	case 0:
+		if mant == 0 {
+			return math.Float32frombits(sign)
+		}
+		v := float32(mant) / (1 << 24)
+		if sign != 0 {
+			return -v
+		}
+		return v
+	case 0x1f:
+		return math.Float32frombits(sign | 0x7f800000 | mant<<13)
+	}
+
+	return math.Float32frombits(sign | (exp-15+127)<<23 | mant<<13)
+}
@@ -1,6 +1,12 @@
 package storage
 
 import (
+	"context"
+	"errors"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
 	"testing"
 )
 
@@ -48,3 +54,309 @@ func TestConvertEmbeddingToFloat64_Truncate(t *testing.T) {
 		}
 	}
 }
+
+func TestFitEmbeddingDimension(t *testing.T) {
+	exact := make([]float32, defaultMtreeDim)
+	got, err := fitEmbeddingDimension("vector_memories", exact, true, "ollama:nomic-embed-text")
+	if err != nil || len(got) != defaultMtreeDim {
+		t.Fatalf("exact dimension: got len %d, err %v", len(got), err)
+	}
+
+	got, err = fitEmbeddingDimension("vector_memories", nil, true, "")
+	if err != nil || len(got) != defaultMtreeDim {
+		t.Fatalf("nil embedding: got len %d, err %v", len(got), err)
+	}
+
+	got, err = fitEmbeddingDimension("vector_memories", make([]float32, 1024), false, "")
+	if err != nil || len(got) != defaultMtreeDim {
+		t.Fatalf("lenient mismatch: got len %d, err %v", len(got), err)
+	}
+
+	_, err = fitEmbeddingDimension("knowledge_base", make([]float32, 1024), true, "ollama:mxbai-embed-large")
+	var dimErr *EmbeddingDimensionError
+	if !errors.As(err, &dimErr) {
+		t.Fatalf("strict mismatch: expected *EmbeddingDimensionError, got %v", err)
+	}
+	if dimErr.Expected != defaultMtreeDim || dimErr.Got != 1024 || dimErr.Table != "knowledge_base" {
+		t.Errorf("unexpected error fields: %+v", dimErr)
+	}
+	if !strings.Contains(err.Error(), "ollama:mxbai-embed-large") {
+		t.Errorf("expected the model in the error message, got %q", err.Error())
+	}
+}
+
+func TestParseEmbeddingFormat(t *testing.T) {
+	cases := map[string]EmbeddingFormat{
+		"":        EmbeddingFormatFloat32,
+		"float32": EmbeddingFormatFloat32,
+		"FLOAT16": EmbeddingFormatFloat16,
+		" int8 ":  EmbeddingFormatInt8,
+	}
+	for input, want := range cases {
+		got, err := ParseEmbeddingFormat(input)
+		if err != nil {
+			t.Fatalf("ParseEmbeddingFormat(%q) returned error: %v", input, err)
+		}
+		if got != want {
+			t.Fatalf("ParseEmbeddingFormat(%q) = %q, want %q", input, got, want)
+		}
+	}
+
+	if _, err := ParseEmbeddingFormat("bfloat16"); err == nil {
+		t.Fatal("expected error for unsupported format")
+	}
+}
+
+func TestPackFloat16(t *testing.T) {
+	embedding := []float32{0, 1, -1, 0.5, 65504, 0.1, -0.333, 6.1035156e-05}
+	packed := packFloat16(embedding)
+	if len(packed) != 2*len(embedding) {
+		t.Fatalf("packed %d components into %d bytes, want 2 per component", len(embedding), len(packed))
+	}
+	got := unpackFloat16(packed)
+	for i, v := range embedding {
+		if diff := math.Abs(float64(got[i] - v)); diff > math.Abs(float64(v))*0.001 {
+			t.Errorf("component %d = %v after packing, want %v", i, got[i], v)
+		}
+	}
+}
+
+func TestBindCodeEmbedding(t *testing.T) {
+	embedding := []float32{0.5, -0.25}
+
+	s := NewSurrealDBStorage(&ConnectionConfig{EmbeddingFormat: EmbeddingFormatFloat16})
+	params := map[string]interface{}{"embedding": embedding}
+	s.bindCodeEmbedding(params, embedding)
+	if _, ok := params["embedding"]; ok {
+		t.Errorf("float16 left $embedding bound: %v", params)
+	}
+	row := map[string]interface{}{"embedding_f16": params["embedding_f16"]}
+	unpackEmbeddings([]map[string]interface{}{row})
+	if got, ok := row["embedding"].([]float32); !ok || len(got) != 2 || got[0] != 0.5 || got[1] != -0.25 {
+		t.Errorf("unpacked row = %v, want the embedding back", row)
+	}
+
+	s = NewSurrealDBStorage(&ConnectionConfig{})
+	params = map[string]interface{}{}
+	s.bindCodeEmbedding(params, embedding)
+	if _, ok := params["embedding_f16"]; ok || params["embedding"] == nil {
+		t.Errorf("float32 params = %v, want only $embedding", params)
+	}
+}
+
+func TestQuantizeInt8(t *testing.T) {
+	src := []float32{0.5, -0.25, 0, 0.125}
+	got := quantizeInt8(src)
+	want := []int{127, -64, 0, 32}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("quantizeInt8()[%d] = %d, want %d", i, got[i], want[i])
+		}
+	}
+
+	zeros := quantizeInt8(make([]float32, 3))
+	for i, v := range zeros {
+		if v != 0 {
+			t.Fatalf("expected zero vector at index %d, got %d", i, v)
+		}
+	}
+}
+
+func TestQuantizeInt8KeepsRanking(t *testing.T) {
+	// Candidates at decreasing cosine similarity to the query, with norms
+	// that would reorder them under euclidean distance
+	query := []float32{0.6, -0.2, 0.3, 0.1, -0.5, 0.4}
+	candidates := [][]float32{
+		{0.58, -0.21, 0.33, 0.08, -0.49, 0.41},
+		{3.1, -0.4, 1.2, 1.5, -2.2, 1.1},
+		{0.02, -0.01, 0.03, 0.04, -0.01, 0.01},
+		{-0.3, 0.5, 0.1, -0.2, 0.4, 0.2},
+		{-6, 2.4, -2.5, -1.2, 4.8, -3.9},
+	}
+
+	rank := func(vectors [][]float32) []int {
+		order := make([]int, len(vectors))
+		for i := range order {
+			order[i] = i
+		}
+		sort.SliceStable(order, func(a, b int) bool {
+			return cosineSimilarity(query, vectors[order[a]]) > cosineSimilarity(query, vectors[order[b]])
+		})
+		return order
+	}
+
+	quantized := make([][]float32, len(candidates))
+	for i, c := range candidates {
+		for _, v := range quantizeInt8(c) {
+			quantized[i] = append(quantized[i], float32(v))
+		}
+	}
+	want, got := rank(candidates), rank(quantized)
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("ranking after int8 quantization = %v, want %v", got, want)
+		}
+	}
+	for i, order := range want {
+		if order != i {
+			t.Fatalf("test candidates are not in cosine order: %v", want)
+		}
+	}
+}
+
+func TestDecodeStoredEmbeddingInt8(t *testing.T) {
+	s := NewSurrealDBStorage(&ConnectionConfig{EmbeddingFormat: EmbeddingFormatInt8})
+	got := s.decodeStoredEmbedding([]interface{}{float64(127), float64(-64), uint64(0)})
+	if len(got) != 3 {
+		t.Fatalf("expected 3 components, got %d", len(got))
+	}
+	if got[0] != 1 || got[2] != 0 {
+		t.Fatalf("unexpected dequantized values: %v", got)
+	}
+	if got[1] > -0.5 || got[1] < -0.51 {
+		t.Fatalf("unexpected dequantized value at index 1: %v", got[1])
+	}
+}
+
+func TestKNNOperator(t *testing.T) {
+	cases := []struct {
+		opts VectorSearchOptions
+		want string
+	}{
+		{VectorSearchOptions{}, "<|10|>"},
+		{VectorSearchOptions{Limit: 5}, "<|5|>"},
+		// MTREE indexes take no candidate list size: <|K,EF|> is HNSW syntax
+		{VectorSearchOptions{Limit: 5, EfSearch: 40}, "<|5|>"},
+		{VectorSearchOptions{Limit: 5, EfSearch: 40, Accuracy: SearchAccuracyExact}, "<|5,COSINE|>"},
+	}
+	for _, c := range cases {
+		got, err := knnOperator(c.opts)
+		if err != nil {
+			t.Fatalf("knnOperator(%+v) returned error: %v", c.opts, err)
+		}
+		if got != c.want {
+			t.Fatalf("knnOperator(%+v) = %q, want %q", c.opts, got, c.want)
+		}
+	}
+
+	if _, err := knnOperator(VectorSearchOptions{Accuracy: "fuzzy"}); err == nil {
+		t.Fatal("expected error for invalid accuracy")
+	}
+}
+
+// TestKNNOperatorMatchesIndexes checks that knnOperator only emits the KNN
+// forms SurrealDB accepts for the MTREE indexes the schema defines: <|K|> and
+// <|K,DISTANCE|>.
+func TestKNNOperatorMatchesIndexes(t *testing.T) {
+	s := NewSurrealDBStorage(&ConnectionConfig{})
+	indexes := 0
+	for _, stmt := range s.getMigrationV35Statements() {
+		if !strings.HasPrefix(stmt, "DEFINE INDEX") {
+			continue
+		}
+		indexes++
+		if !strings.Contains(stmt, " MTREE ") || !strings.HasSuffix(stmt, "DIST COSINE;") {
+			t.Fatalf("embedding index is not an MTREE index with cosine distance: %s", stmt)
+		}
+	}
+	if indexes == 0 {
+		t.Fatal("no embedding index definitions found")
+	}
+
+	mtreeKNN := regexp.MustCompile(`^<\|[0-9]+(,COSINE)?\|>$`)
+	for _, opts := range []VectorSearchOptions{
+		{},
+		{Limit: 3, EfSearch: 64},
+		{Limit: 3, EfSearch: 64, Accuracy: SearchAccuracyApproximate},
+		{Limit: 3, Accuracy: SearchAccuracyExact},
+	} {
+		got, err := knnOperator(opts)
+		if err != nil {
+			t.Fatalf("knnOperator(%+v) returned error: %v", opts, err)
+		}
+		if !mtreeKNN.MatchString(got) {
+			t.Errorf("knnOperator(%+v) = %q, not an MTREE KNN operator", opts, got)
+		}
+	}
+}
+
+func TestEmbeddingModelOf(t *testing.T) {
+	routes := embeddingModelRoutes{model: "ollama:nomic-embed-text", codeModel: "gguf:coderank.gguf"}
+	if got := routes.of("vector_memories", 768); got != "ollama:nomic-embed-text" {
+		t.Errorf("vector model = %q", got)
+	}
+	if got := routes.of("code_symbols", 768); got != "gguf:coderank.gguf" {
+		t.Errorf("code model = %q", got)
+	}
+	noCode := embeddingModelRoutes{model: "ollama:nomic-embed-text"}
+	if got := noCode.of("code_chunks", 768); got != "ollama:nomic-embed-text" {
+		t.Errorf("code model without a code embedder = %q", got)
+	}
+	if got := noCode.of("code_symbols", 0); got != "" {
+		t.Errorf("missing embedding should have no model, got %q", got)
+	}
+
+	routes.tables = TableEmbeddingModels(map[string]string{"knowledge_base": "openai:text-embedding-3-small", "code": "gguf:jina-code.gguf"})
+	if got := routes.of("kb_document_versions", 1536); got != "openai:text-embedding-3-small" {
+		t.Errorf("routed knowledge base model = %q", got)
+	}
+	if got := routes.of("code_chunks", 768); got != "gguf:jina-code.gguf" {
+		t.Errorf("routed code model = %q", got)
+	}
+	if got := routes.of("events", 768); got != "ollama:nomic-embed-text" {
+		t.Errorf("unrouted events model = %q", got)
+	}
+}
+
+func TestStaleEmbeddingModel(t *testing.T) {
+	cases := []struct {
+		recorded, active string
+		want             bool
+	}{
+		{"ollama:nomic-embed-text", "ollama:nomic-embed-text", false},
+		{"ollama:nomic-embed-text", "openai:text-embedding-3-large", true},
+		{"", "openai:text-embedding-3-large", false},
+		{"ollama:nomic-embed-text", "", false},
+	}
+	for _, c := range cases {
+		if got := staleEmbeddingModel(c.recorded, c.active); got != c.want {
+			t.Errorf("staleEmbeddingModel(%q, %q) = %v, want %v", c.recorded, c.active, got, c.want)
+		}
+	}
+}
+
+func TestEmbeddingModelClause(t *testing.T) {
+	s := &SurrealDBStorage{config: &ConnectionConfig{EmbeddingModel: "ollama:nomic-embed-text"}}
+	params := map[string]interface{}{}
+	if clause := s.embeddingModelClause(context.Background(), "vector_memories", params); !strings.Contains(clause, "$embedding_model") {
+		t.Errorf("expected a model filter, got %q", clause)
+	}
+	if params["embedding_model"] != "ollama:nomic-embed-text" {
+		t.Errorf("expected $embedding_model to be bound, got %v", params)
+	}
+
+	unconfigured := &SurrealDBStorage{config: &ConnectionConfig{}}
+	if clause := unconfigured.embeddingModelClause(context.Background(), "vector_memories", map[string]interface{}{}); clause != "" {
+		t.Errorf("expected no filter without a configured model, got %q", clause)
+	}
+
+	p := &PostgresStorage{embeddingModel: "ollama:nomic-embed-text", codeEmbeddingModel: "gguf:coderank.gguf"}
+	args := pgArgs{"project"}
+	if clause := p.embeddingModelClause(context.Background(), "code_chunks", &args); clause != " AND coalesce(embedding_model, '') IN ('', $2)" {
+		t.Errorf("unexpected postgres filter %q", clause)
+	}
+	if len(args) != 2 || args[1] != "gguf:coderank.gguf" {
+		t.Errorf("expected the code model as $2, got %v", args)
+	}
+
+	// The model of a code project overrides the code model, for code only
+	ctx := WithCodeEmbeddingModel(context.Background(), "ollama:small-code")
+	args = pgArgs{"project"}
+	p.embeddingModelClause(ctx, "code_chunks", &args)
+	if len(args) != 2 || args[1] != "ollama:small-code" {
+		t.Errorf("expected the project model as $2, got %v", args)
+	}
+	if got := p.embeddingModelOf(ctx, "vector_memories", []float32{1}); got != "ollama:nomic-embed-text" {
+		t.Errorf("vector model in a project context = %q", got)
+	}
+}
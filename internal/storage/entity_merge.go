@@ -0,0 +1,113 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+)
+
+// AliasesProperty is the entity property listing the other names of an
+// entity, such as those of the duplicates merged into it. Entities are also
+// found by their aliases.
+const AliasesProperty = "aliases"
+
+// EntityMerge reports what MergeEntities did.
+type EntityMerge struct {
+	Survivor                 *Entity  `json:"survivor"`
+	Merged                   []string `json:"merged"`
+	Aliases                  []string `json:"aliases"`
+	RelationshipsRewired     int      `json:"relationships_rewired"`
+	SelfRelationshipsDropped int      `json:"self_relationships_dropped"`
+	HitsMoved                int      `json:"hits_moved"`
+}
+
+// entityAliases returns the aliases of properties.
+func entityAliases(properties map[string]interface{}) []string {
+	var aliases []string
+	switch v := properties[AliasesProperty].(type) {
+	case []string:
+		aliases = append(aliases, v...)
+	case []interface{}:
+		for _, alias := range v {
+			if s, ok := alias.(string); ok {
+				aliases = append(aliases, s)
+			}
+		}
+	case string:
+		aliases = append(aliases, v)
+	}
+	return aliases
+}
+
+// mergeEntityProperties returns the properties of survivor once duplicates
+// are merged into it: the properties survivor lacks are taken from the
+// duplicates, in order, and the names and aliases of the duplicates become
+// aliases of survivor.
+func mergeEntityProperties(survivor Entity, duplicates []Entity) (map[string]interface{}, []string) {
+	properties := make(map[string]interface{}, len(survivor.Properties)+1)
+	for k, v := range survivor.Properties {
+		properties[k] = v
+	}
+
+	seen := map[string]bool{survivor.Name: true}
+	var aliases []string
+	addAlias := func(alias string) {
+		if alias != "" && !seen[alias] {
+			seen[alias] = true
+			aliases = append(aliases, alias)
+		}
+	}
+	for _, alias := range entityAliases(survivor.Properties) {
+		addAlias(alias)
+	}
+	for _, duplicate := range duplicates {
+		addAlias(duplicate.Name)
+		for _, alias := range entityAliases(duplicate.Properties) {
+			addAlias(alias)
+		}
+		for k, v := range duplicate.Properties {
+			if _, ok := properties[k]; !ok && k != AliasesProperty {
+				properties[k] = v
+			}
+		}
+	}
+	if len(aliases) > 0 {
+		properties[AliasesProperty] = aliases
+	}
+	return properties, aliases
+}
+
+// mergeTargets looks up the survivor and the duplicates of a merge, dropping
+// the duplicates named twice. It fails when one does not exist or when the
+// survivor is among the duplicates.
+func mergeTargets(ctx context.Context, getEntity func(context.Context, string) (*Entity, error), survivor string, duplicates []string) (*Entity, []Entity, error) {
+	keep, err := getEntity(ctx, survivor)
+	if err != nil {
+		return nil, nil, err
+	}
+	if keep == nil {
+		return nil, nil, fmt.Errorf("entity %w: %s", ErrNotFound, survivor)
+	}
+
+	seen := map[string]bool{}
+	var merged []Entity
+	for _, name := range duplicates {
+		duplicate, err := getEntity(ctx, name)
+		if err != nil {
+			return nil, nil, err
+		}
+		if duplicate == nil {
+			return nil, nil, fmt.Errorf("entity %w: %s", ErrNotFound, name)
+		}
+		if duplicate.ID == keep.ID {
+			return nil, nil, fmt.Errorf("cannot merge entity %s into itself", name)
+		}
+		if !seen[duplicate.ID] {
+			seen[duplicate.ID] = true
+			merged = append(merged, *duplicate)
+		}
+	}
+	if len(merged) == 0 {
+		return nil, nil, fmt.Errorf("no duplicates to merge into %s", survivor)
+	}
+	return keep, merged, nil
+}
@@ -0,0 +1,28 @@
+package storage
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMergeEntityProperties(t *testing.T) {
+	survivor := Entity{Name: "Robert Smith", Properties: map[string]interface{}{
+		"role":          "engineer",
+		AliasesProperty: []interface{}{"Rob"},
+	}}
+	duplicates := []Entity{
+		{Name: "Bob Smith", Properties: map[string]interface{}{"role": "manager", "email": "bob@example.com"}},
+		{Name: "Rob", Properties: map[string]interface{}{AliasesProperty: []interface{}{"Robert Smith", "Bobby"}}},
+	}
+
+	properties, aliases := mergeEntityProperties(survivor, duplicates)
+	if want := []string{"Rob", "Bob Smith", "Bobby"}; !reflect.DeepEqual(aliases, want) {
+		t.Errorf("aliases = %v, want %v", aliases, want)
+	}
+	if properties["role"] != "engineer" || properties["email"] != "bob@example.com" {
+		t.Errorf("properties = %v, want the survivor role and the duplicate email", properties)
+	}
+	if len(entityAliases(survivor.Properties)) != 1 {
+		t.Error("mergeEntityProperties changed the survivor properties")
+	}
+}
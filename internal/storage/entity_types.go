@@ -0,0 +1,378 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// EntityType is an entity type with the JSON schema the properties of its
+// entities must match. Entities of types without one are not checked.
+//
+// Schemas support the JSON Schema keywords type, properties, required,
+// additionalProperties, items, enum, minimum, maximum, minLength, maxLength
+// and pattern; others, such as description, are kept but not checked.
+type EntityType struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description,omitempty"`
+	Schema      map[string]interface{} `json:"schema"`
+	CreatedAt   time.Time              `json:"created_at"`
+	UpdatedAt   time.Time              `json:"updated_at"`
+}
+
+// PropertySchemaError is returned when the properties of an entity do not
+// match the schema of its type.
+type PropertySchemaError struct {
+	EntityType string
+	Problems   []string
+}
+
+func (e *PropertySchemaError) Error() string {
+	return fmt.Sprintf("properties do not match the schema of entity type %q: %s; use remembrance_describe_entity_type to see it",
+		e.EntityType, strings.Join(e.Problems, "; "))
+}
+
+// schemaTypes are the values of the type keyword.
+var schemaTypes = map[string]bool{
+	"object": true, "array": true, "string": true, "number": true,
+	"integer": true, "boolean": true, "null": true,
+}
+
+// CheckPropertySchema reports the first problem of a schema, or nil when it
+// can validate properties: an object schema using the supported keywords
+// with values of the right kind.
+func CheckPropertySchema(schema map[string]interface{}) error {
+	if types := schemaTypeList(schema["type"]); len(types) > 0 && (len(types) != 1 || types[0] != "object") {
+		return fmt.Errorf("schema type must be \"object\"")
+	}
+	return checkSchemaNode(schema, "schema")
+}
+
+func checkSchemaNode(schema map[string]interface{}, path string) error {
+	if raw, ok := schema["type"]; ok {
+		types := schemaTypeList(raw)
+		if len(types) == 0 {
+			return fmt.Errorf("%s.type must be a type name or a list of them", path)
+		}
+		for _, t := range types {
+			if !schemaTypes[t] {
+				return fmt.Errorf("%s.type %q is not one of object, array, string, number, integer, boolean or null", path, t)
+			}
+		}
+	}
+	if raw, ok := schema["properties"]; ok {
+		properties, ok := raw.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s.properties must be an object", path)
+		}
+		for name, sub := range properties {
+			node, ok := sub.(map[string]interface{})
+			if !ok {
+				return fmt.Errorf("%s.properties.%s must be a schema object", path, name)
+			}
+			if err := checkSchemaNode(node, path+".properties."+name); err != nil {
+				return err
+			}
+		}
+	}
+	if raw, ok := schema["required"]; ok {
+		if _, ok := stringList(raw); !ok {
+			return fmt.Errorf("%s.required must be a list of property names", path)
+		}
+	}
+	if raw, ok := schema["additionalProperties"]; ok {
+		switch v := raw.(type) {
+		case bool:
+		case map[string]interface{}:
+			if err := checkSchemaNode(v, path+".additionalProperties"); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("%s.additionalProperties must be a boolean or a schema object", path)
+		}
+	}
+	if raw, ok := schema["items"]; ok {
+		node, ok := raw.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s.items must be a schema object", path)
+		}
+		if err := checkSchemaNode(node, path+".items"); err != nil {
+			return err
+		}
+	}
+	if raw, ok := schema["enum"]; ok {
+		if values, ok := raw.([]interface{}); !ok || len(values) == 0 {
+			return fmt.Errorf("%s.enum must be a non-empty list", path)
+		}
+	}
+	for _, keyword := range []string{"minimum", "maximum", "minLength", "maxLength"} {
+		if raw, ok := schema[keyword]; ok {
+			if _, ok := toFloat(raw); !ok {
+				return fmt.Errorf("%s.%s must be a number", path, keyword)
+			}
+		}
+	}
+	if raw, ok := schema["pattern"]; ok {
+		pattern, ok := raw.(string)
+		if !ok {
+			return fmt.Errorf("%s.pattern must be a string", path)
+		}
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("%s.pattern is not a valid regular expression: %v", path, err)
+		}
+	}
+	return nil
+}
+
+// Validate returns the problems of properties against the schema of t,
+// sorted, or none when they match it.
+func (t *EntityType) Validate(properties map[string]interface{}) []string {
+	if len(t.Schema) == 0 {
+		return nil
+	}
+	if properties == nil {
+		properties = map[string]interface{}{}
+	}
+	var problems []string
+	validateValue(t.Schema, properties, "properties", &problems)
+	sort.Strings(problems)
+	return problems
+}
+
+func validateValue(schema map[string]interface{}, value interface{}, path string, problems *[]string) {
+	if types := schemaTypeList(schema["type"]); len(types) > 0 {
+		matched := false
+		for _, t := range types {
+			if valueHasType(value, t) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			*problems = append(*problems, fmt.Sprintf("%s must be %s", path, strings.Join(types, " or ")))
+			return
+		}
+	}
+
+	if raw, ok := schema["enum"].([]interface{}); ok {
+		found := false
+		for _, allowed := range raw {
+			if schemaValuesEqual(allowed, value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			*problems = append(*problems, fmt.Sprintf("%s must be one of %v", path, raw))
+		}
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		validateObject(schema, v, path, problems)
+	case []interface{}:
+		if items, ok := schema["items"].(map[string]interface{}); ok {
+			for i, item := range v {
+				validateValue(items, item, fmt.Sprintf("%s[%d]", path, i), problems)
+			}
+		}
+	case string:
+		length := float64(len([]rune(v)))
+		if min, ok := toFloat(schema["minLength"]); ok && length < min {
+			*problems = append(*problems, fmt.Sprintf("%s must have at least %g characters", path, min))
+		}
+		if max, ok := toFloat(schema["maxLength"]); ok && length > max {
+			*problems = append(*problems, fmt.Sprintf("%s must have at most %g characters", path, max))
+		}
+		if pattern, ok := schema["pattern"].(string); ok {
+			if re, err := regexp.Compile(pattern); err == nil && !re.MatchString(v) {
+				*problems = append(*problems, fmt.Sprintf("%s must match %q", path, pattern))
+			}
+		}
+	default:
+		if n, ok := toFloat(value); ok {
+			if min, ok := toFloat(schema["minimum"]); ok && n < min {
+				*problems = append(*problems, fmt.Sprintf("%s must be at least %g", path, min))
+			}
+			if max, ok := toFloat(schema["maximum"]); ok && n > max {
+				*problems = append(*problems, fmt.Sprintf("%s must be at most %g", path, max))
+			}
+		}
+	}
+}
+
+func validateObject(schema map[string]interface{}, object map[string]interface{}, path string, problems *[]string) {
+	required, _ := stringList(schema["required"])
+	for _, name := range required {
+		if _, ok := object[name]; !ok {
+			*problems = append(*problems, fmt.Sprintf("%s.%s is required", path, name))
+		}
+	}
+
+	properties, _ := schema["properties"].(map[string]interface{})
+	for name, value := range object {
+		if sub, ok := properties[name].(map[string]interface{}); ok {
+			validateValue(sub, value, path+"."+name, problems)
+			continue
+		}
+		// Aliases are recorded by MergeEntities whatever the schema
+		if path == "properties" && name == AliasesProperty {
+			continue
+		}
+		switch extra := schema["additionalProperties"].(type) {
+		case bool:
+			if !extra {
+				*problems = append(*problems, fmt.Sprintf("%s.%s is not an allowed property", path, name))
+			}
+		case map[string]interface{}:
+			validateValue(extra, value, path+"."+name, problems)
+		}
+	}
+}
+
+// schemaTypeList returns the type names of a type keyword.
+func schemaTypeList(raw interface{}) []string {
+	if t, ok := raw.(string); ok {
+		return []string{t}
+	}
+	types, _ := stringList(raw)
+	return types
+}
+
+// stringList returns raw as a list of strings.
+func stringList(raw interface{}) ([]string, bool) {
+	switch v := raw.(type) {
+	case []string:
+		return v, true
+	case []interface{}:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return nil, false
+			}
+			values = append(values, s)
+		}
+		return values, true
+	}
+	return nil, false
+}
+
+// valueHasType reports whether a decoded JSON value has a schema type.
+func valueHasType(value interface{}, schemaType string) bool {
+	switch schemaType {
+	case "object":
+		_, ok := value.(map[string]interface{})
+		return ok
+	case "array":
+		_, ok := value.([]interface{})
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "null":
+		return value == nil
+	case "number":
+		_, ok := toFloat(value)
+		return ok
+	case "integer":
+		n, ok := toFloat(value)
+		return ok && n == math.Trunc(n)
+	}
+	return false
+}
+
+// schemaValuesEqual compares an enum value with a property value, numbers
+// by value.
+func schemaValuesEqual(a, b interface{}) bool {
+	if x, ok := toFloat(a); ok {
+		y, ok := toFloat(b)
+		return ok && x == y
+	}
+	return fmt.Sprint(a) == fmt.Sprint(b)
+}
+
+// toFloat returns a numeric value as a float64.
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case uint32:
+		return float64(n), true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// checkEntityProperties validates the properties of an entity of
+// entityType against its schema, if it has one.
+func checkEntityProperties(ctx context.Context, getType func(context.Context, string) (*EntityType, error), entityType string, properties map[string]interface{}) error {
+	t, err := getType(ctx, entityType)
+	if err != nil {
+		return fmt.Errorf("failed to get schema of entity type %q: %w", entityType, err)
+	}
+	if t == nil {
+		return nil
+	}
+	if problems := t.Validate(properties); len(problems) > 0 {
+		return &PropertySchemaError{EntityType: entityType, Problems: problems}
+	}
+	return nil
+}
+
+// updatedEntityProperties returns the properties of an entity updated with
+// properties: they replace all the current ones when replace is set, but
+// for the aliases unless given, and otherwise are merged into them, a null
+// value removing its property.
+func updatedEntityProperties(current, properties map[string]interface{}, replace bool) map[string]interface{} {
+	updated := map[string]interface{}{}
+	if replace {
+		if aliases, ok := current[AliasesProperty]; ok {
+			updated[AliasesProperty] = aliases
+		}
+	} else {
+		for k, v := range current {
+			updated[k] = v
+		}
+	}
+	for k, v := range properties {
+		if v == nil {
+			delete(updated, k)
+		} else {
+			updated[k] = v
+		}
+	}
+	return updated
+}
+
+// checkBatchEntity validates the properties of the create_entity operation
+// at index i of a batch, reporting a mismatch as a BatchError.
+func checkBatchEntity(ctx context.Context, getType func(context.Context, string) (*EntityType, error), i int, op BatchOperation) error {
+	err := checkEntityProperties(ctx, getType, op.EntityType, op.Properties)
+	var schemaErr *PropertySchemaError
+	if errors.As(err, &schemaErr) {
+		return &BatchError{Index: i, Message: err.Error()}
+	}
+	return err
+}
@@ -0,0 +1,95 @@
+package storage
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCheckPropertySchema(t *testing.T) {
+	valid := map[string]interface{}{
+		"type":     "object",
+		"required": []interface{}{"email"},
+		"properties": map[string]interface{}{
+			"email": map[string]interface{}{"type": "string", "pattern": "^[^@]+@[^@]+$"},
+			"tags":  map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}},
+		},
+	}
+	if err := CheckPropertySchema(valid); err != nil {
+		t.Errorf("CheckPropertySchema(valid) = %v", err)
+	}
+
+	for name, schema := range map[string]map[string]interface{}{
+		"array root":     {"type": "array"},
+		"unknown type":   {"properties": map[string]interface{}{"age": map[string]interface{}{"type": "int"}}},
+		"bad pattern":    {"properties": map[string]interface{}{"id": map[string]interface{}{"pattern": "("}}},
+		"bad required":   {"required": "email"},
+		"bad properties": {"properties": []interface{}{"email"}},
+	} {
+		if err := CheckPropertySchema(schema); err == nil {
+			t.Errorf("CheckPropertySchema(%s) = nil, want an error", name)
+		}
+	}
+}
+
+func TestEntityTypeValidate(t *testing.T) {
+	person := EntityType{Name: "person", Schema: map[string]interface{}{
+		"type":                 "object",
+		"required":             []interface{}{"email"},
+		"additionalProperties": false,
+		"properties": map[string]interface{}{
+			"email": map[string]interface{}{"type": "string", "pattern": "@"},
+			"age":   map[string]interface{}{"type": "integer", "minimum": 0.0},
+			"role":  map[string]interface{}{"enum": []interface{}{"developer", "manager"}},
+		},
+	}}
+
+	if problems := person.Validate(map[string]interface{}{
+		"email":         "alice@example.com",
+		"age":           json.Number("34"),
+		"role":          "developer",
+		AliasesProperty: []interface{}{"Al"},
+	}); len(problems) != 0 {
+		t.Errorf("Validate(valid) = %v, want no problems", problems)
+	}
+
+	problems := person.Validate(map[string]interface{}{
+		"email":    "alice",
+		"age":      -1.5,
+		"role":     "intern",
+		"nickname": "Al",
+	})
+	for _, want := range []string{"email", "age", "role", "nickname"} {
+		found := false
+		for _, p := range problems {
+			found = found || strings.Contains(p, want)
+		}
+		if !found {
+			t.Errorf("Validate(invalid) = %v, missing a problem with %q", problems, want)
+		}
+	}
+
+	if problems := person.Validate(nil); len(problems) != 1 || !strings.Contains(problems[0], "email") {
+		t.Errorf("Validate(nil) = %v, want email required", problems)
+	}
+}
+
+func TestUpdatedEntityProperties(t *testing.T) {
+	current := map[string]interface{}{"role": "developer", "email": "a@example.com", AliasesProperty: []interface{}{"Al"}}
+
+	merged := updatedEntityProperties(current, map[string]interface{}{"role": "lead", "email": nil}, false)
+	want := map[string]interface{}{"role": "lead", AliasesProperty: []interface{}{"Al"}}
+	if !reflect.DeepEqual(merged, want) {
+		t.Errorf("merge = %v, want %v", merged, want)
+	}
+
+	replaced := updatedEntityProperties(current, map[string]interface{}{"team": "core"}, true)
+	want = map[string]interface{}{"team": "core", AliasesProperty: []interface{}{"Al"}}
+	if !reflect.DeepEqual(replaced, want) {
+		t.Errorf("replace = %v, want %v", replaced, want)
+	}
+	if current["role"] != "developer" {
+		t.Error("updatedEntityProperties modified the current properties")
+	}
+}
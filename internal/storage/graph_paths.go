@@ -0,0 +1,185 @@
+package storage
+
+import (
+	"context"
+	"sort"
+)
+
+// GraphPath is a chain of relationships connecting two entities. Entities
+// runs from the first entity to the last, and Relationships[i] connects
+// Entities[i] and Entities[i+1] in either direction.
+type GraphPath struct {
+	Hops          int            `json:"hops"`
+	Entities      []PathEntity   `json:"entities"`
+	Relationships []Relationship `json:"relationships"`
+}
+
+// PathEntity is an entity on a GraphPath.
+type PathEntity struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// pathGraph is the view of a backend findPaths searches.
+type pathGraph struct {
+	// relationshipsOf returns the current relationships from or to any of
+	// the given entities.
+	relationshipsOf func(ctx context.Context, entityIDs []string) ([]Relationship, error)
+	// entities returns the given entities by ID.
+	entities func(ctx context.Context, entityIDs []string) (map[string]PathEntity, error)
+}
+
+// findPaths returns up to limit simple paths of at most maxHops
+// relationships from fromID to toID, shortest first. It loads the
+// relationships within maxHops of fromID a hop at a time, then enumerates
+// the paths of each length, pruning the entities too far from toID.
+func findPaths(ctx context.Context, g pathGraph, fromID, toID string, maxHops, limit int) ([]GraphPath, error) {
+	adjacency := map[string][]Relationship{}
+	loaded := map[string]bool{}
+	seen := map[string]bool{fromID: true}
+	frontier := []string{fromID}
+	for hop := 0; hop < maxHops && len(frontier) > 0; hop++ {
+		relationships, err := g.relationshipsOf(ctx, frontier)
+		if err != nil {
+			return nil, err
+		}
+		var next []string
+		for _, r := range relationships {
+			if loaded[r.ID] {
+				continue
+			}
+			loaded[r.ID] = true
+			adjacency[r.From] = append(adjacency[r.From], r)
+			if r.To != r.From {
+				adjacency[r.To] = append(adjacency[r.To], r)
+			}
+			for _, id := range []string{r.From, r.To} {
+				if !seen[id] {
+					seen[id] = true
+					next = append(next, id)
+				}
+			}
+		}
+		frontier = next
+	}
+	for _, relationships := range adjacency {
+		sort.Slice(relationships, func(i, j int) bool {
+			if relationships[i].Type != relationships[j].Type {
+				return relationships[i].Type < relationships[j].Type
+			}
+			return relationships[i].ID < relationships[j].ID
+		})
+	}
+
+	paths := enumeratePaths(adjacency, fromID, toID, maxHops, limit)
+	if len(paths) == 0 {
+		return paths, nil
+	}
+
+	ids := map[string]bool{}
+	for _, path := range paths {
+		for _, entity := range path.Entities {
+			ids[entity.ID] = true
+		}
+	}
+	idList := make([]string, 0, len(ids))
+	for id := range ids {
+		idList = append(idList, id)
+	}
+	entities, err := g.entities(ctx, idList)
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range paths {
+		for i, entity := range path.Entities {
+			if e, ok := entities[entity.ID]; ok {
+				path.Entities[i] = e
+			}
+		}
+		for i := range path.Relationships {
+			path.Relationships[i].FromName = entities[path.Relationships[i].From].Name
+			path.Relationships[i].ToName = entities[path.Relationships[i].To].Name
+		}
+	}
+	return paths, nil
+}
+
+// enumeratePaths returns up to limit simple paths of at most maxHops
+// relationships of adjacency from fromID to toID, shortest first. Only the
+// IDs of the path entities are set.
+func enumeratePaths(adjacency map[string][]Relationship, fromID, toID string, maxHops, limit int) []GraphPath {
+	paths := []GraphPath{}
+	if fromID == toID || limit <= 0 {
+		return paths
+	}
+
+	// Distance of each entity to toID, to prune the walks that cannot reach
+	// it in the hops left
+	distance := map[string]int{toID: 0}
+	queue := []string{toID}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		for _, r := range adjacency[id] {
+			other := otherEntity(r, id)
+			if _, ok := distance[other]; !ok {
+				distance[other] = distance[id] + 1
+				queue = append(queue, other)
+			}
+		}
+	}
+	shortest, ok := distance[fromID]
+	if !ok {
+		return paths
+	}
+
+	entities := []string{fromID}
+	var relationships []Relationship
+	onPath := map[string]bool{fromID: true}
+	var walk func(id string, left int)
+	walk = func(id string, left int) {
+		if len(paths) >= limit {
+			return
+		}
+		if id == toID {
+			if left == 0 {
+				path := GraphPath{
+					Hops:          len(relationships),
+					Entities:      make([]PathEntity, len(entities)),
+					Relationships: append([]Relationship(nil), relationships...),
+				}
+				for i, entityID := range entities {
+					path.Entities[i] = PathEntity{ID: entityID}
+				}
+				paths = append(paths, path)
+			}
+			return
+		}
+		for _, r := range adjacency[id] {
+			other := otherEntity(r, id)
+			if d, ok := distance[other]; !ok || d > left-1 || onPath[other] {
+				continue
+			}
+			onPath[other] = true
+			entities = append(entities, other)
+			relationships = append(relationships, r)
+			walk(other, left-1)
+			entities = entities[:len(entities)-1]
+			relationships = relationships[:len(relationships)-1]
+			delete(onPath, other)
+		}
+	}
+	for hops := shortest; hops <= maxHops && len(paths) < limit; hops++ {
+		walk(fromID, hops)
+	}
+	return paths
+}
+
+// otherEntity returns the entity r connects to id.
+func otherEntity(r Relationship, id string) string {
+	if r.From == id {
+		return r.To
+	}
+	return r.From
+}
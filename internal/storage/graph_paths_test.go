@@ -0,0 +1,57 @@
+package storage
+
+import (
+	"reflect"
+	"testing"
+)
+
+func pathIDs(paths []GraphPath) [][]string {
+	var ids [][]string
+	for _, path := range paths {
+		var entities []string
+		for _, e := range path.Entities {
+			entities = append(entities, e.ID)
+		}
+		ids = append(ids, entities)
+	}
+	return ids
+}
+
+func TestEnumeratePaths(t *testing.T) {
+	// a - b - d, a - c - d, a - d, d - e, and b - c
+	adjacency := map[string][]Relationship{}
+	for _, r := range []Relationship{
+		{ID: "1", From: "a", To: "b", Type: "knows"},
+		{ID: "2", From: "c", To: "a", Type: "knows"},
+		{ID: "3", From: "b", To: "d", Type: "knows"},
+		{ID: "4", From: "c", To: "d", Type: "knows"},
+		{ID: "5", From: "a", To: "d", Type: "manages"},
+		{ID: "6", From: "d", To: "e", Type: "knows"},
+		{ID: "7", From: "b", To: "c", Type: "knows"},
+	} {
+		adjacency[r.From] = append(adjacency[r.From], r)
+		adjacency[r.To] = append(adjacency[r.To], r)
+	}
+
+	got := pathIDs(enumeratePaths(adjacency, "a", "d", 3, 10))
+	want := [][]string{
+		{"a", "d"},
+		{"a", "b", "d"},
+		{"a", "c", "d"},
+		{"a", "b", "c", "d"},
+		{"a", "c", "b", "d"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("enumeratePaths(a, d, 3) = %v, want %v", got, want)
+	}
+
+	if got := pathIDs(enumeratePaths(adjacency, "a", "d", 3, 2)); len(got) != 2 || len(got[0]) != 2 {
+		t.Errorf("enumeratePaths(a, d, limit 2) = %v, want the two shortest", got)
+	}
+	if got := enumeratePaths(adjacency, "a", "e", 1, 10); len(got) != 0 {
+		t.Errorf("enumeratePaths(a, e, 1 hop) = %v, want none", pathIDs(got))
+	}
+	if got := enumeratePaths(adjacency, "a", "x", 6, 10); len(got) != 0 {
+		t.Errorf("enumeratePaths to an unconnected entity = %v, want none", pathIDs(got))
+	}
+}
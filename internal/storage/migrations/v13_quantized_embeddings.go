@@ -0,0 +1,62 @@
+package migrations
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/surrealdb/surrealdb.go"
+)
+
+// V13QuantizedEmbeddings relaxes embedding field types so quantized (integer)
+// components can be stored when embedding-storage is float16 or int8.
+type V13QuantizedEmbeddings struct {
+	*MigrationBase
+}
+
+// NewV13QuantizedEmbeddings creates a new V13 migration
+func NewV13QuantizedEmbeddings(db *surrealdb.DB) Migration {
+	return &V13QuantizedEmbeddings{
+		MigrationBase: NewMigrationBase(db),
+	}
+}
+
+// Version returns the migration version
+func (m *V13QuantizedEmbeddings) Version() int {
+	return 13
+}
+
+// Description returns the migration description
+func (m *V13QuantizedEmbeddings) Description() string {
+	return "Allowing quantized numeric components in embedding fields"
+}
+
+// Apply executes the migration
+func (m *V13QuantizedEmbeddings) Apply(ctx context.Context, db *surrealdb.DB) error {
+	slog.Info("Applying migration v13: Allowing quantized numeric components in embedding fields")
+
+	// The field must be removed first so the new TYPE replaces the old definition
+	removeStatements := []string{
+		`REMOVE FIELD embedding ON vector_memories;`,
+		`REMOVE FIELD embedding ON knowledge_base;`,
+		`REMOVE FIELD embedding ON events;`,
+		`REMOVE FIELD embedding ON code_symbols;`,
+		`REMOVE FIELD embedding ON code_chunks;`,
+	}
+
+	for _, stmt := range removeStatements {
+		slog.Debug("Removing old field definition", "stmt", stmt)
+		if _, err := surrealdb.Query[[]map[string]interface{}](ctx, db, stmt, nil); err != nil {
+			slog.Debug("Could not remove field (may not exist)", "error", err)
+		}
+	}
+
+	elements := []SchemaElement{
+		{Type: "field", Statement: `DEFINE FIELD embedding ON vector_memories TYPE array<number>;`, OnTable: "vector_memories"},
+		{Type: "field", Statement: `DEFINE FIELD embedding ON knowledge_base TYPE array<number>;`, OnTable: "knowledge_base"},
+		{Type: "field", Statement: `DEFINE FIELD embedding ON events TYPE array<number>;`, OnTable: "events"},
+		{Type: "field", Statement: `DEFINE FIELD embedding ON code_symbols TYPE option<array<number>>;`, OnTable: "code_symbols"},
+		{Type: "field", Statement: `DEFINE FIELD embedding ON code_chunks TYPE option<array<number>>;`, OnTable: "code_chunks"},
+	}
+
+	return m.ApplyElements(ctx, elements)
+}
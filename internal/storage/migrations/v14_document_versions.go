@@ -0,0 +1,58 @@
+package migrations
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/surrealdb/surrealdb.go"
+)
+
+// V14DocumentVersions adds the kb_document_versions table that keeps prior
+// revisions of knowledge base documents when they are re-saved.
+type V14DocumentVersions struct {
+	*MigrationBase
+}
+
+// NewV14DocumentVersions creates a new V14 migration
+func NewV14DocumentVersions(db *surrealdb.DB) Migration {
+	return &V14DocumentVersions{
+		MigrationBase: NewMigrationBase(db),
+	}
+}
+
+// Version returns the migration version
+func (m *V14DocumentVersions) Version() int {
+	return 14
+}
+
+// Description returns the migration description
+func (m *V14DocumentVersions) Description() string {
+	return "Creating kb_document_versions table for document history"
+}
+
+// Apply executes the migration
+func (m *V14DocumentVersions) Apply(ctx context.Context, db *surrealdb.DB) error {
+	slog.Info("Applying migration v14: Creating kb_document_versions table")
+
+	elements := []SchemaElement{
+		{Type: "table", Statement: `DEFINE TABLE kb_document_versions SCHEMAFULL;`},
+
+		{Type: "field", Statement: `DEFINE FIELD file_path ON kb_document_versions TYPE string;`, OnTable: "kb_document_versions"},
+		{Type: "field", Statement: `DEFINE FIELD version ON kb_document_versions TYPE int;`, OnTable: "kb_document_versions"},
+		{Type: "field", Statement: `DEFINE FIELD content ON kb_document_versions TYPE string;`, OnTable: "kb_document_versions"},
+
+		// Line diff from this version to the revision that replaced it
+		{Type: "field", Statement: `DEFINE FIELD diff ON kb_document_versions TYPE string DEFAULT "";`, OnTable: "kb_document_versions"},
+		{Type: "field", Statement: `DEFINE FIELD chunk_count ON kb_document_versions TYPE int DEFAULT 0;`, OnTable: "kb_document_versions"},
+
+		// Mean of the archived chunk embeddings, used when searches include archived versions
+		{Type: "field", Statement: `DEFINE FIELD embedding ON kb_document_versions TYPE array<number>;`, OnTable: "kb_document_versions"},
+		{Type: "field", Statement: `DEFINE FIELD metadata ON kb_document_versions FLEXIBLE TYPE object DEFAULT {};`, OnTable: "kb_document_versions"},
+		{Type: "field", Statement: `DEFINE FIELD created_at ON kb_document_versions TYPE datetime DEFAULT time::now();`, OnTable: "kb_document_versions"},
+
+		{Type: "index", Statement: `DEFINE INDEX idx_kb_versions_file_version ON kb_document_versions FIELDS file_path, version UNIQUE;`, OnTable: "kb_document_versions"},
+		{Type: "index", Statement: `DEFINE INDEX idx_kb_versions_embedding ON kb_document_versions FIELDS embedding MTREE DIMENSION 768;`, OnTable: "kb_document_versions"},
+	}
+
+	return m.ApplyElements(ctx, elements)
+}
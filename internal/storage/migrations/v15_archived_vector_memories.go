@@ -0,0 +1,56 @@
+package migrations
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/surrealdb/surrealdb.go"
+)
+
+// V15ArchivedVectorMemories adds the archived_vector_memories table that keeps
+// vector memories replaced by a consolidated summary.
+type V15ArchivedVectorMemories struct {
+	*MigrationBase
+}
+
+// NewV15ArchivedVectorMemories creates a new V15 migration
+func NewV15ArchivedVectorMemories(db *surrealdb.DB) Migration {
+	return &V15ArchivedVectorMemories{
+		MigrationBase: NewMigrationBase(db),
+	}
+}
+
+// Version returns the migration version
+func (m *V15ArchivedVectorMemories) Version() int {
+	return 15
+}
+
+// Description returns the migration description
+func (m *V15ArchivedVectorMemories) Description() string {
+	return "Creating archived_vector_memories table for memory consolidation"
+}
+
+// Apply executes the migration
+func (m *V15ArchivedVectorMemories) Apply(ctx context.Context, db *surrealdb.DB) error {
+	slog.Info("Applying migration v15: Creating archived_vector_memories table")
+
+	elements := []SchemaElement{
+		{Type: "table", Statement: `DEFINE TABLE archived_vector_memories SCHEMAFULL;`},
+
+		{Type: "field", Statement: `DEFINE FIELD user_id ON archived_vector_memories TYPE option<string>;`, OnTable: "archived_vector_memories"},
+		{Type: "field", Statement: `DEFINE FIELD content ON archived_vector_memories TYPE string;`, OnTable: "archived_vector_memories"},
+		{Type: "field", Statement: `DEFINE FIELD embedding ON archived_vector_memories TYPE array<number>;`, OnTable: "archived_vector_memories"},
+		{Type: "field", Statement: `DEFINE FIELD metadata ON archived_vector_memories FLEXIBLE TYPE object DEFAULT {};`, OnTable: "archived_vector_memories"},
+
+		// Id of the original memory and of the summary memory that replaced it
+		{Type: "field", Statement: `DEFINE FIELD original_id ON archived_vector_memories TYPE string;`, OnTable: "archived_vector_memories"},
+		{Type: "field", Statement: `DEFINE FIELD consolidated_into ON archived_vector_memories TYPE string;`, OnTable: "archived_vector_memories"},
+		{Type: "field", Statement: `DEFINE FIELD created_at ON archived_vector_memories TYPE option<datetime>;`, OnTable: "archived_vector_memories"},
+		{Type: "field", Statement: `DEFINE FIELD archived_at ON archived_vector_memories TYPE datetime DEFAULT time::now();`, OnTable: "archived_vector_memories"},
+
+		{Type: "index", Statement: `DEFINE INDEX idx_archived_vectors_user ON archived_vector_memories FIELDS user_id;`, OnTable: "archived_vector_memories"},
+		{Type: "index", Statement: `DEFINE INDEX idx_archived_vectors_summary ON archived_vector_memories FIELDS consolidated_into;`, OnTable: "archived_vector_memories"},
+	}
+
+	return m.ApplyElements(ctx, elements)
+}
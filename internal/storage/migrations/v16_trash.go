@@ -0,0 +1,56 @@
+package migrations
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/surrealdb/surrealdb.go"
+)
+
+// V16Trash adds the trash table that holds soft-deleted facts, vectors,
+// documents and entities until they are restored or purged.
+type V16Trash struct {
+	*MigrationBase
+}
+
+// NewV16Trash creates a new V16 migration
+func NewV16Trash(db *surrealdb.DB) Migration {
+	return &V16Trash{
+		MigrationBase: NewMigrationBase(db),
+	}
+}
+
+// Version returns the migration version
+func (m *V16Trash) Version() int {
+	return 16
+}
+
+// Description returns the migration description
+func (m *V16Trash) Description() string {
+	return "Creating trash table for soft-deleted records"
+}
+
+// Apply executes the migration
+func (m *V16Trash) Apply(ctx context.Context, db *surrealdb.DB) error {
+	slog.Info("Applying migration v16: Creating trash table")
+
+	elements := []SchemaElement{
+		{Type: "table", Statement: `DEFINE TABLE trash SCHEMAFULL;`},
+
+		// kind is fact, vector, document or entity; source_table is where records are restored to
+		{Type: "field", Statement: `DEFINE FIELD kind ON trash TYPE string;`, OnTable: "trash"},
+		{Type: "field", Statement: `DEFINE FIELD source_table ON trash TYPE string;`, OnTable: "trash"},
+		{Type: "field", Statement: `DEFINE FIELD user_id ON trash TYPE string DEFAULT "";`, OnTable: "trash"},
+		{Type: "field", Statement: `DEFINE FIELD label ON trash TYPE string DEFAULT "";`, OnTable: "trash"},
+		{Type: "field", Statement: `DEFINE FIELD content ON trash TYPE string DEFAULT "";`, OnTable: "trash"},
+
+		// The deleted rows, ids included, so a restore recreates them unchanged
+		{Type: "field", Statement: `DEFINE FIELD records ON trash FLEXIBLE TYPE array<object> DEFAULT [];`, OnTable: "trash"},
+		{Type: "field", Statement: `DEFINE FIELD deleted_at ON trash TYPE datetime DEFAULT time::now();`, OnTable: "trash"},
+
+		{Type: "index", Statement: `DEFINE INDEX idx_trash_user ON trash FIELDS user_id;`, OnTable: "trash"},
+		{Type: "index", Statement: `DEFINE INDEX idx_trash_deleted_at ON trash FIELDS deleted_at;`, OnTable: "trash"},
+	}
+
+	return m.ApplyElements(ctx, elements)
+}
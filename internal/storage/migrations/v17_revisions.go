@@ -0,0 +1,51 @@
+package migrations
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/surrealdb/surrealdb.go"
+)
+
+// V17Revisions adds revision fields used for optimistic concurrency checks:
+// an incrementing counter on vector_memories and a source hash on code_symbols.
+type V17Revisions struct {
+	*MigrationBase
+}
+
+// NewV17Revisions creates a new V17 migration
+func NewV17Revisions(db *surrealdb.DB) Migration {
+	return &V17Revisions{
+		MigrationBase: NewMigrationBase(db),
+	}
+}
+
+// Version returns the migration version
+func (m *V17Revisions) Version() int {
+	return 17
+}
+
+// Description returns the migration description
+func (m *V17Revisions) Description() string {
+	return "Adding revision fields to vector_memories and code_symbols"
+}
+
+// Apply executes the migration
+func (m *V17Revisions) Apply(ctx context.Context, db *surrealdb.DB) error {
+	slog.Info("Applying migration v17: Adding revision fields")
+
+	elements := []SchemaElement{
+		{Type: "field", Statement: `DEFINE FIELD revision ON vector_memories TYPE int DEFAULT 1;`, OnTable: "vector_memories"},
+		{Type: "field", Statement: `DEFINE FIELD revision ON code_symbols TYPE option<string>;`, OnTable: "code_symbols"},
+	}
+	if err := m.ApplyElements(ctx, elements); err != nil {
+		return err
+	}
+
+	// Existing memories start at revision 1
+	backfill := `UPDATE vector_memories SET revision = 1 WHERE revision IS NONE RETURN NONE;`
+	if _, err := surrealdb.Query[[]map[string]interface{}](ctx, db, backfill, nil); err != nil {
+		slog.Warn("Could not backfill vector revisions", "error", err)
+	}
+	return nil
+}
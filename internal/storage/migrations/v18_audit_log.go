@@ -0,0 +1,49 @@
+package migrations
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/surrealdb/surrealdb.go"
+)
+
+// V18AuditLog adds the audit_log table that records destructive
+// administrative actions such as user data purges.
+type V18AuditLog struct {
+	*MigrationBase
+}
+
+// NewV18AuditLog creates a new V18 migration
+func NewV18AuditLog(db *surrealdb.DB) Migration {
+	return &V18AuditLog{
+		MigrationBase: NewMigrationBase(db),
+	}
+}
+
+// Version returns the migration version
+func (m *V18AuditLog) Version() int {
+	return 18
+}
+
+// Description returns the migration description
+func (m *V18AuditLog) Description() string {
+	return "Creating audit_log table"
+}
+
+// Apply executes the migration
+func (m *V18AuditLog) Apply(ctx context.Context, db *surrealdb.DB) error {
+	slog.Info("Applying migration v18: Creating audit_log table")
+
+	elements := []SchemaElement{
+		{Type: "table", Statement: `DEFINE TABLE audit_log SCHEMAFULL;`},
+		{Type: "field", Statement: `DEFINE FIELD action ON audit_log TYPE string;`, OnTable: "audit_log"},
+		{Type: "field", Statement: `DEFINE FIELD subject ON audit_log TYPE string DEFAULT "";`, OnTable: "audit_log"},
+		{Type: "field", Statement: `DEFINE FIELD details ON audit_log FLEXIBLE TYPE object DEFAULT {};`, OnTable: "audit_log"},
+		{Type: "field", Statement: `DEFINE FIELD created_at ON audit_log TYPE datetime DEFAULT time::now();`, OnTable: "audit_log"},
+
+		{Type: "index", Statement: `DEFINE INDEX idx_audit_log_action ON audit_log FIELDS action;`, OnTable: "audit_log"},
+		{Type: "index", Statement: `DEFINE INDEX idx_audit_log_subject ON audit_log FIELDS subject;`, OnTable: "audit_log"},
+	}
+
+	return m.ApplyElements(ctx, elements)
+}
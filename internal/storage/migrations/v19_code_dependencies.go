@@ -0,0 +1,54 @@
+package migrations
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/surrealdb/surrealdb.go"
+)
+
+// V19CodeDependencies adds the code_dependencies table that stores the
+// imports of each indexed file and, when it is part of the project, the file
+// each import resolves to.
+type V19CodeDependencies struct {
+	*MigrationBase
+}
+
+// NewV19CodeDependencies creates a new V19 migration
+func NewV19CodeDependencies(db *surrealdb.DB) Migration {
+	return &V19CodeDependencies{
+		MigrationBase: NewMigrationBase(db),
+	}
+}
+
+// Version returns the migration version
+func (m *V19CodeDependencies) Version() int {
+	return 19
+}
+
+// Description returns the migration description
+func (m *V19CodeDependencies) Description() string {
+	return "Creating code_dependencies table"
+}
+
+// Apply executes the migration
+func (m *V19CodeDependencies) Apply(ctx context.Context, db *surrealdb.DB) error {
+	slog.Info("Applying migration v19: Creating code_dependencies table")
+
+	elements := []SchemaElement{
+		{Type: "table", Statement: `DEFINE TABLE code_dependencies SCHEMAFULL;`},
+		{Type: "field", Statement: `DEFINE FIELD project_id ON code_dependencies TYPE string;`, OnTable: "code_dependencies"},
+		{Type: "field", Statement: `DEFINE FIELD file_path ON code_dependencies TYPE string;`, OnTable: "code_dependencies"},
+		{Type: "field", Statement: `DEFINE FIELD module ON code_dependencies TYPE string;`, OnTable: "code_dependencies"},
+		{Type: "field", Statement: `DEFINE FIELD target_path ON code_dependencies TYPE option<string>;`, OnTable: "code_dependencies"},
+		{Type: "field", Statement: `DEFINE FIELD line ON code_dependencies TYPE int DEFAULT 0;`, OnTable: "code_dependencies"},
+		{Type: "field", Statement: `DEFINE FIELD language ON code_dependencies TYPE string DEFAULT "";`, OnTable: "code_dependencies"},
+		{Type: "field", Statement: `DEFINE FIELD created_at ON code_dependencies TYPE datetime DEFAULT time::now();`, OnTable: "code_dependencies"},
+
+		{Type: "index", Statement: `DEFINE INDEX idx_code_dependencies_file ON code_dependencies FIELDS project_id, file_path;`, OnTable: "code_dependencies"},
+		{Type: "index", Statement: `DEFINE INDEX idx_code_dependencies_target ON code_dependencies FIELDS project_id, target_path;`, OnTable: "code_dependencies"},
+		{Type: "index", Statement: `DEFINE INDEX idx_code_dependencies_module ON code_dependencies FIELDS project_id, module;`, OnTable: "code_dependencies"},
+	}
+
+	return m.ApplyElements(ctx, elements)
+}
@@ -0,0 +1,55 @@
+package migrations
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/surrealdb/surrealdb.go"
+)
+
+// V20CodeCalls adds the code_calls table that stores the call graph of
+// indexed files as caller to callee edges.
+type V20CodeCalls struct {
+	*MigrationBase
+}
+
+// NewV20CodeCalls creates a new V20 migration
+func NewV20CodeCalls(db *surrealdb.DB) Migration {
+	return &V20CodeCalls{
+		MigrationBase: NewMigrationBase(db),
+	}
+}
+
+// Version returns the migration version
+func (m *V20CodeCalls) Version() int {
+	return 20
+}
+
+// Description returns the migration description
+func (m *V20CodeCalls) Description() string {
+	return "Creating code_calls table"
+}
+
+// Apply executes the migration
+func (m *V20CodeCalls) Apply(ctx context.Context, db *surrealdb.DB) error {
+	slog.Info("Applying migration v20: Creating code_calls table")
+
+	elements := []SchemaElement{
+		{Type: "table", Statement: `DEFINE TABLE code_calls SCHEMAFULL;`},
+		{Type: "field", Statement: `DEFINE FIELD project_id ON code_calls TYPE string;`, OnTable: "code_calls"},
+		{Type: "field", Statement: `DEFINE FIELD file_path ON code_calls TYPE string;`, OnTable: "code_calls"},
+		{Type: "field", Statement: `DEFINE FIELD caller_name_path ON code_calls TYPE string DEFAULT "";`, OnTable: "code_calls"},
+		{Type: "field", Statement: `DEFINE FIELD caller_name ON code_calls TYPE string DEFAULT "";`, OnTable: "code_calls"},
+		{Type: "field", Statement: `DEFINE FIELD callee_name ON code_calls TYPE string;`, OnTable: "code_calls"},
+		{Type: "field", Statement: `DEFINE FIELD callee_expr ON code_calls TYPE string DEFAULT "";`, OnTable: "code_calls"},
+		{Type: "field", Statement: `DEFINE FIELD line ON code_calls TYPE int DEFAULT 0;`, OnTable: "code_calls"},
+		{Type: "field", Statement: `DEFINE FIELD language ON code_calls TYPE string DEFAULT "";`, OnTable: "code_calls"},
+		{Type: "field", Statement: `DEFINE FIELD created_at ON code_calls TYPE datetime DEFAULT time::now();`, OnTable: "code_calls"},
+
+		{Type: "index", Statement: `DEFINE INDEX idx_code_calls_file ON code_calls FIELDS project_id, file_path;`, OnTable: "code_calls"},
+		{Type: "index", Statement: `DEFINE INDEX idx_code_calls_callee ON code_calls FIELDS project_id, callee_name;`, OnTable: "code_calls"},
+		{Type: "index", Statement: `DEFINE INDEX idx_code_calls_caller ON code_calls FIELDS project_id, caller_name;`, OnTable: "code_calls"},
+	}
+
+	return m.ApplyElements(ctx, elements)
+}
@@ -0,0 +1,42 @@
+package migrations
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/surrealdb/surrealdb.go"
+)
+
+// V21CodeFileContents adds the optional content_gz field to code_files, which
+// holds the gzip-compressed, base64-encoded file contents when enabled.
+type V21CodeFileContents struct {
+	*MigrationBase
+}
+
+// NewV21CodeFileContents creates a new V21 migration
+func NewV21CodeFileContents(db *surrealdb.DB) Migration {
+	return &V21CodeFileContents{
+		MigrationBase: NewMigrationBase(db),
+	}
+}
+
+// Version returns the migration version
+func (m *V21CodeFileContents) Version() int {
+	return 21
+}
+
+// Description returns the migration description
+func (m *V21CodeFileContents) Description() string {
+	return "Adding content_gz field to code_files table"
+}
+
+// Apply executes the migration
+func (m *V21CodeFileContents) Apply(ctx context.Context, db *surrealdb.DB) error {
+	slog.Info("Applying migration v21: Adding content_gz field to code_files")
+
+	elements := []SchemaElement{
+		{Type: "field", Statement: `DEFINE FIELD content_gz ON code_files TYPE option<string>;`, OnTable: "code_files"},
+	}
+
+	return m.ApplyElements(ctx, elements)
+}
@@ -0,0 +1,50 @@
+package migrations
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/surrealdb/surrealdb.go"
+)
+
+// V22MemoryHits adds the memory_hits table that records every time a fact,
+// vector or document is returned by a retrieval tool.
+type V22MemoryHits struct {
+	*MigrationBase
+}
+
+// NewV22MemoryHits creates a new V22 migration
+func NewV22MemoryHits(db *surrealdb.DB) Migration {
+	return &V22MemoryHits{
+		MigrationBase: NewMigrationBase(db),
+	}
+}
+
+// Version returns the migration version
+func (m *V22MemoryHits) Version() int {
+	return 22
+}
+
+// Description returns the migration description
+func (m *V22MemoryHits) Description() string {
+	return "Creating memory_hits table"
+}
+
+// Apply executes the migration
+func (m *V22MemoryHits) Apply(ctx context.Context, db *surrealdb.DB) error {
+	slog.Info("Applying migration v22: Creating memory_hits table")
+
+	elements := []SchemaElement{
+		{Type: "table", Statement: `DEFINE TABLE memory_hits SCHEMAFULL;`},
+		{Type: "field", Statement: `DEFINE FIELD kind ON memory_hits TYPE string;`, OnTable: "memory_hits"},
+		{Type: "field", Statement: `DEFINE FIELD ref ON memory_hits TYPE string;`, OnTable: "memory_hits"},
+		{Type: "field", Statement: `DEFINE FIELD user_id ON memory_hits TYPE string DEFAULT "";`, OnTable: "memory_hits"},
+		{Type: "field", Statement: `DEFINE FIELD tool ON memory_hits TYPE string DEFAULT "";`, OnTable: "memory_hits"},
+		{Type: "field", Statement: `DEFINE FIELD created_at ON memory_hits TYPE datetime DEFAULT time::now();`, OnTable: "memory_hits"},
+
+		{Type: "index", Statement: `DEFINE INDEX idx_memory_hits_ref ON memory_hits FIELDS kind, ref;`, OnTable: "memory_hits"},
+		{Type: "index", Statement: `DEFINE INDEX idx_memory_hits_user ON memory_hits FIELDS user_id, created_at;`, OnTable: "memory_hits"},
+	}
+
+	return m.ApplyElements(ctx, elements)
+}
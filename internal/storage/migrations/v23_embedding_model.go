@@ -0,0 +1,53 @@
+package migrations
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/surrealdb/surrealdb.go"
+)
+
+// V23EmbeddingModel records the model and original dimension of every
+// embedding, so searches can skip records embedded by another model.
+type V23EmbeddingModel struct {
+	*MigrationBase
+}
+
+// NewV23EmbeddingModel creates a new V23 migration
+func NewV23EmbeddingModel(db *surrealdb.DB) Migration {
+	return &V23EmbeddingModel{
+		MigrationBase: NewMigrationBase(db),
+	}
+}
+
+// Version returns the migration version
+func (m *V23EmbeddingModel) Version() int {
+	return 23
+}
+
+// Description returns the migration description
+func (m *V23EmbeddingModel) Description() string {
+	return "Adding embedding_model and embedding_dim fields to embedded tables"
+}
+
+// Apply executes the migration
+func (m *V23EmbeddingModel) Apply(ctx context.Context, db *surrealdb.DB) error {
+	slog.Info("Applying migration v23: Adding embedding_model and embedding_dim fields")
+
+	elements := []SchemaElement{
+		{Type: "field", Statement: `DEFINE FIELD embedding_model ON vector_memories TYPE option<string>;`, OnTable: "vector_memories"},
+		{Type: "field", Statement: `DEFINE FIELD embedding_dim ON vector_memories TYPE option<int>;`, OnTable: "vector_memories"},
+		{Type: "field", Statement: `DEFINE FIELD embedding_model ON knowledge_base TYPE option<string>;`, OnTable: "knowledge_base"},
+		{Type: "field", Statement: `DEFINE FIELD embedding_dim ON knowledge_base TYPE option<int>;`, OnTable: "knowledge_base"},
+		{Type: "field", Statement: `DEFINE FIELD embedding_model ON kb_document_versions TYPE option<string>;`, OnTable: "kb_document_versions"},
+		{Type: "field", Statement: `DEFINE FIELD embedding_dim ON kb_document_versions TYPE option<int>;`, OnTable: "kb_document_versions"},
+		{Type: "field", Statement: `DEFINE FIELD embedding_model ON events TYPE option<string>;`, OnTable: "events"},
+		{Type: "field", Statement: `DEFINE FIELD embedding_dim ON events TYPE option<int>;`, OnTable: "events"},
+		{Type: "field", Statement: `DEFINE FIELD embedding_model ON code_symbols TYPE option<string>;`, OnTable: "code_symbols"},
+		{Type: "field", Statement: `DEFINE FIELD embedding_dim ON code_symbols TYPE option<int>;`, OnTable: "code_symbols"},
+		{Type: "field", Statement: `DEFINE FIELD embedding_model ON code_chunks TYPE option<string>;`, OnTable: "code_chunks"},
+		{Type: "field", Statement: `DEFINE FIELD embedding_dim ON code_chunks TYPE option<int>;`, OnTable: "code_chunks"},
+	}
+
+	return m.ApplyElements(ctx, elements)
+}
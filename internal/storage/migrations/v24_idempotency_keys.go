@@ -0,0 +1,50 @@
+package migrations
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/surrealdb/surrealdb.go"
+)
+
+// V24IdempotencyKeys adds the idempotency_keys table that stores the result
+// of write tool calls sent with an idempotency key, so retries return it
+// instead of writing again.
+type V24IdempotencyKeys struct {
+	*MigrationBase
+}
+
+// NewV24IdempotencyKeys creates a new V24 migration
+func NewV24IdempotencyKeys(db *surrealdb.DB) Migration {
+	return &V24IdempotencyKeys{
+		MigrationBase: NewMigrationBase(db),
+	}
+}
+
+// Version returns the migration version
+func (m *V24IdempotencyKeys) Version() int {
+	return 24
+}
+
+// Description returns the migration description
+func (m *V24IdempotencyKeys) Description() string {
+	return "Creating idempotency_keys table"
+}
+
+// Apply executes the migration
+func (m *V24IdempotencyKeys) Apply(ctx context.Context, db *surrealdb.DB) error {
+	slog.Info("Applying migration v24: Creating idempotency_keys table")
+
+	elements := []SchemaElement{
+		{Type: "table", Statement: `DEFINE TABLE idempotency_keys SCHEMAFULL;`},
+		{Type: "field", Statement: `DEFINE FIELD tool ON idempotency_keys TYPE string;`, OnTable: "idempotency_keys"},
+		{Type: "field", Statement: `DEFINE FIELD key ON idempotency_keys TYPE string;`, OnTable: "idempotency_keys"},
+		{Type: "field", Statement: `DEFINE FIELD result ON idempotency_keys TYPE string;`, OnTable: "idempotency_keys"},
+		{Type: "field", Statement: `DEFINE FIELD created_at ON idempotency_keys TYPE datetime DEFAULT time::now();`, OnTable: "idempotency_keys"},
+
+		{Type: "index", Statement: `DEFINE INDEX idx_idempotency_keys_key ON idempotency_keys FIELDS tool, key UNIQUE;`, OnTable: "idempotency_keys"},
+		{Type: "index", Statement: `DEFINE INDEX idx_idempotency_keys_created ON idempotency_keys FIELDS created_at;`, OnTable: "idempotency_keys"},
+	}
+
+	return m.ApplyElements(ctx, elements)
+}
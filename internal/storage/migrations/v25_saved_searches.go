@@ -0,0 +1,51 @@
+package migrations
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/surrealdb/surrealdb.go"
+)
+
+// V25SavedSearches adds the saved_searches table that stores search tool
+// calls a user saved under a name, so they can be run again by name.
+type V25SavedSearches struct {
+	*MigrationBase
+}
+
+// NewV25SavedSearches creates a new V25 migration
+func NewV25SavedSearches(db *surrealdb.DB) Migration {
+	return &V25SavedSearches{
+		MigrationBase: NewMigrationBase(db),
+	}
+}
+
+// Version returns the migration version
+func (m *V25SavedSearches) Version() int {
+	return 25
+}
+
+// Description returns the migration description
+func (m *V25SavedSearches) Description() string {
+	return "Creating saved_searches table"
+}
+
+// Apply executes the migration
+func (m *V25SavedSearches) Apply(ctx context.Context, db *surrealdb.DB) error {
+	slog.Info("Applying migration v25: Creating saved_searches table")
+
+	elements := []SchemaElement{
+		{Type: "table", Statement: `DEFINE TABLE saved_searches SCHEMAFULL;`},
+		{Type: "field", Statement: `DEFINE FIELD user_id ON saved_searches TYPE string;`, OnTable: "saved_searches"},
+		{Type: "field", Statement: `DEFINE FIELD name ON saved_searches TYPE string;`, OnTable: "saved_searches"},
+		{Type: "field", Statement: `DEFINE FIELD tool ON saved_searches TYPE string;`, OnTable: "saved_searches"},
+		{Type: "field", Statement: `DEFINE FIELD arguments ON saved_searches TYPE string;`, OnTable: "saved_searches"},
+		{Type: "field", Statement: `DEFINE FIELD description ON saved_searches TYPE string DEFAULT "";`, OnTable: "saved_searches"},
+		{Type: "field", Statement: `DEFINE FIELD created_at ON saved_searches TYPE datetime DEFAULT time::now();`, OnTable: "saved_searches"},
+		{Type: "field", Statement: `DEFINE FIELD updated_at ON saved_searches TYPE datetime DEFAULT time::now();`, OnTable: "saved_searches"},
+
+		{Type: "index", Statement: `DEFINE INDEX idx_saved_searches_name ON saved_searches FIELDS user_id, name UNIQUE;`, OnTable: "saved_searches"},
+	}
+
+	return m.ApplyElements(ctx, elements)
+}
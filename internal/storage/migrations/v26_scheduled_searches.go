@@ -0,0 +1,55 @@
+package migrations
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/surrealdb/surrealdb.go"
+)
+
+// V26ScheduledSearches adds the schedule and last run of saved searches, and
+// the saved_search_changes table that stores the new results each scheduled
+// run found.
+type V26ScheduledSearches struct {
+	*MigrationBase
+}
+
+// NewV26ScheduledSearches creates a new V26 migration
+func NewV26ScheduledSearches(db *surrealdb.DB) Migration {
+	return &V26ScheduledSearches{
+		MigrationBase: NewMigrationBase(db),
+	}
+}
+
+// Version returns the migration version
+func (m *V26ScheduledSearches) Version() int {
+	return 26
+}
+
+// Description returns the migration description
+func (m *V26ScheduledSearches) Description() string {
+	return "Scheduling saved searches and creating saved_search_changes table"
+}
+
+// Apply executes the migration
+func (m *V26ScheduledSearches) Apply(ctx context.Context, db *surrealdb.DB) error {
+	slog.Info("Applying migration v26: Scheduling saved searches")
+
+	elements := []SchemaElement{
+		{Type: "field", Statement: `DEFINE FIELD interval_seconds ON saved_searches TYPE int DEFAULT 0;`, OnTable: "saved_searches"},
+		{Type: "field", Statement: `DEFINE FIELD last_run_at ON saved_searches TYPE option<datetime>;`, OnTable: "saved_searches"},
+		{Type: "field", Statement: `DEFINE FIELD last_results ON saved_searches TYPE string DEFAULT "[]";`, OnTable: "saved_searches"},
+
+		{Type: "table", Statement: `DEFINE TABLE saved_search_changes SCHEMAFULL;`},
+		{Type: "field", Statement: `DEFINE FIELD user_id ON saved_search_changes TYPE string;`, OnTable: "saved_search_changes"},
+		{Type: "field", Statement: `DEFINE FIELD name ON saved_search_changes TYPE string;`, OnTable: "saved_search_changes"},
+		{Type: "field", Statement: `DEFINE FIELD tool ON saved_search_changes TYPE string;`, OnTable: "saved_search_changes"},
+		{Type: "field", Statement: `DEFINE FIELD items ON saved_search_changes TYPE string;`, OnTable: "saved_search_changes"},
+		{Type: "field", Statement: `DEFINE FIELD run_at ON saved_search_changes TYPE datetime DEFAULT time::now();`, OnTable: "saved_search_changes"},
+
+		{Type: "index", Statement: `DEFINE INDEX idx_saved_search_changes_user ON saved_search_changes FIELDS user_id, name, run_at;`, OnTable: "saved_search_changes"},
+		{Type: "index", Statement: `DEFINE INDEX idx_saved_search_changes_run ON saved_search_changes FIELDS run_at;`, OnTable: "saved_search_changes"},
+	}
+
+	return m.ApplyElements(ctx, elements)
+}
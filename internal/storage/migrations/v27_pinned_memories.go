@@ -0,0 +1,48 @@
+package migrations
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/surrealdb/surrealdb.go"
+)
+
+// V27PinnedMemories adds the pinned_memories table listing the facts, vectors
+// and documents that are protected from deletion.
+type V27PinnedMemories struct {
+	*MigrationBase
+}
+
+// NewV27PinnedMemories creates a new V27 migration
+func NewV27PinnedMemories(db *surrealdb.DB) Migration {
+	return &V27PinnedMemories{
+		MigrationBase: NewMigrationBase(db),
+	}
+}
+
+// Version returns the migration version
+func (m *V27PinnedMemories) Version() int {
+	return 27
+}
+
+// Description returns the migration description
+func (m *V27PinnedMemories) Description() string {
+	return "Creating pinned_memories table"
+}
+
+// Apply executes the migration
+func (m *V27PinnedMemories) Apply(ctx context.Context, db *surrealdb.DB) error {
+	slog.Info("Applying migration v27: Creating pinned_memories table")
+
+	elements := []SchemaElement{
+		{Type: "table", Statement: `DEFINE TABLE pinned_memories SCHEMAFULL;`},
+		{Type: "field", Statement: `DEFINE FIELD kind ON pinned_memories TYPE string;`, OnTable: "pinned_memories"},
+		{Type: "field", Statement: `DEFINE FIELD user_id ON pinned_memories TYPE string DEFAULT "";`, OnTable: "pinned_memories"},
+		{Type: "field", Statement: `DEFINE FIELD ref ON pinned_memories TYPE string;`, OnTable: "pinned_memories"},
+		{Type: "field", Statement: `DEFINE FIELD pinned_at ON pinned_memories TYPE datetime DEFAULT time::now();`, OnTable: "pinned_memories"},
+
+		{Type: "index", Statement: `DEFINE INDEX idx_pinned_memories_ref ON pinned_memories FIELDS kind, user_id, ref UNIQUE;`, OnTable: "pinned_memories"},
+	}
+
+	return m.ApplyElements(ctx, elements)
+}
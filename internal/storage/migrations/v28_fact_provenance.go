@@ -0,0 +1,42 @@
+package migrations
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/surrealdb/surrealdb.go"
+)
+
+// V28FactProvenance adds the provenance field to kv_memories. Vectors,
+// documents and events keep their provenance in metadata.
+type V28FactProvenance struct {
+	*MigrationBase
+}
+
+// NewV28FactProvenance creates a new V28 migration
+func NewV28FactProvenance(db *surrealdb.DB) Migration {
+	return &V28FactProvenance{
+		MigrationBase: NewMigrationBase(db),
+	}
+}
+
+// Version returns the migration version
+func (m *V28FactProvenance) Version() int {
+	return 28
+}
+
+// Description returns the migration description
+func (m *V28FactProvenance) Description() string {
+	return "Adding provenance field to kv_memories"
+}
+
+// Apply executes the migration
+func (m *V28FactProvenance) Apply(ctx context.Context, db *surrealdb.DB) error {
+	slog.Info("Applying migration v28: Adding provenance field to kv_memories")
+
+	elements := []SchemaElement{
+		{Type: "field", Statement: `DEFINE FIELD provenance ON kv_memories FLEXIBLE TYPE option<object>;`, OnTable: "kv_memories"},
+	}
+
+	return m.ApplyElements(ctx, elements)
+}
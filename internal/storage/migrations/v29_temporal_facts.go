@@ -0,0 +1,54 @@
+package migrations
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/surrealdb/surrealdb.go"
+)
+
+// V29TemporalFacts adds the validity interval of kv_memories and the
+// kv_memory_history table keeping the values facts held before they were
+// updated or deleted. Relationship tables are schemaless and need no change.
+type V29TemporalFacts struct {
+	*MigrationBase
+}
+
+// NewV29TemporalFacts creates a new V29 migration
+func NewV29TemporalFacts(db *surrealdb.DB) Migration {
+	return &V29TemporalFacts{
+		MigrationBase: NewMigrationBase(db),
+	}
+}
+
+// Version returns the migration version
+func (m *V29TemporalFacts) Version() int {
+	return 29
+}
+
+// Description returns the migration description
+func (m *V29TemporalFacts) Description() string {
+	return "Adding fact validity intervals and kv_memory_history table"
+}
+
+// Apply executes the migration
+func (m *V29TemporalFacts) Apply(ctx context.Context, db *surrealdb.DB) error {
+	slog.Info("Applying migration v29: Adding fact validity intervals and kv_memory_history table")
+
+	elements := []SchemaElement{
+		{Type: "field", Statement: `DEFINE FIELD valid_from ON kv_memories TYPE option<datetime>;`, OnTable: "kv_memories"},
+		{Type: "field", Statement: `DEFINE FIELD valid_until ON kv_memories TYPE option<datetime>;`, OnTable: "kv_memories"},
+
+		{Type: "table", Statement: `DEFINE TABLE kv_memory_history SCHEMAFULL;`},
+		{Type: "field", Statement: `DEFINE FIELD user_id ON kv_memory_history TYPE string;`, OnTable: "kv_memory_history"},
+		{Type: "field", Statement: `DEFINE FIELD key ON kv_memory_history TYPE string;`, OnTable: "kv_memory_history"},
+		{Type: "field", Statement: `DEFINE FIELD value ON kv_memory_history FLEXIBLE TYPE option<string | int | float | bool | object | array>;`, OnTable: "kv_memory_history"},
+		{Type: "field", Statement: `DEFINE FIELD provenance ON kv_memory_history FLEXIBLE TYPE option<object>;`, OnTable: "kv_memory_history"},
+		{Type: "field", Statement: `DEFINE FIELD valid_from ON kv_memory_history TYPE datetime;`, OnTable: "kv_memory_history"},
+		{Type: "field", Statement: `DEFINE FIELD valid_until ON kv_memory_history TYPE datetime;`, OnTable: "kv_memory_history"},
+
+		{Type: "index", Statement: `DEFINE INDEX idx_kv_memory_history_key ON kv_memory_history FIELDS user_id, key, valid_from;`, OnTable: "kv_memory_history"},
+	}
+
+	return m.ApplyElements(ctx, elements)
+}
@@ -0,0 +1,49 @@
+package migrations
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/surrealdb/surrealdb.go"
+)
+
+// V30EntityTypes adds the entity_types table holding the JSON schema the
+// properties of the entities of each type must match.
+type V30EntityTypes struct {
+	*MigrationBase
+}
+
+// NewV30EntityTypes creates a new V30 migration
+func NewV30EntityTypes(db *surrealdb.DB) Migration {
+	return &V30EntityTypes{
+		MigrationBase: NewMigrationBase(db),
+	}
+}
+
+// Version returns the migration version
+func (m *V30EntityTypes) Version() int {
+	return 30
+}
+
+// Description returns the migration description
+func (m *V30EntityTypes) Description() string {
+	return "Creating entity_types table"
+}
+
+// Apply executes the migration
+func (m *V30EntityTypes) Apply(ctx context.Context, db *surrealdb.DB) error {
+	slog.Info("Applying migration v30: Creating entity_types table")
+
+	elements := []SchemaElement{
+		{Type: "table", Statement: `DEFINE TABLE entity_types SCHEMAFULL;`},
+		{Type: "field", Statement: `DEFINE FIELD name ON entity_types TYPE string;`, OnTable: "entity_types"},
+		{Type: "field", Statement: `DEFINE FIELD description ON entity_types TYPE string DEFAULT "";`, OnTable: "entity_types"},
+		{Type: "field", Statement: `DEFINE FIELD properties_schema ON entity_types FLEXIBLE TYPE object DEFAULT {};`, OnTable: "entity_types"},
+		{Type: "field", Statement: `DEFINE FIELD created_at ON entity_types TYPE datetime DEFAULT time::now();`, OnTable: "entity_types"},
+		{Type: "field", Statement: `DEFINE FIELD updated_at ON entity_types TYPE datetime DEFAULT time::now();`, OnTable: "entity_types"},
+
+		{Type: "index", Statement: `DEFINE INDEX idx_entity_types_name ON entity_types FIELDS name UNIQUE;`, OnTable: "entity_types"},
+	}
+
+	return m.ApplyElements(ctx, elements)
+}
@@ -0,0 +1,46 @@
+package migrations
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/surrealdb/surrealdb.go"
+)
+
+// V31CodeSymbolDocs adds BM25 full-text indexes on the doc strings and
+// signatures of code symbols, which code_search_docs searches.
+type V31CodeSymbolDocs struct {
+	*MigrationBase
+}
+
+// NewV31CodeSymbolDocs creates a new V31 migration
+func NewV31CodeSymbolDocs(db *surrealdb.DB) Migration {
+	return &V31CodeSymbolDocs{
+		MigrationBase: NewMigrationBase(db),
+	}
+}
+
+// Version returns the migration version
+func (m *V31CodeSymbolDocs) Version() int {
+	return 31
+}
+
+// Description returns the migration description
+func (m *V31CodeSymbolDocs) Description() string {
+	return "Adding full-text indexes on code symbol docs"
+}
+
+// Apply executes the migration
+func (m *V31CodeSymbolDocs) Apply(ctx context.Context, db *surrealdb.DB) error {
+	slog.Info("Applying migration v31: Adding full-text indexes on code symbol docs")
+
+	elements := []SchemaElement{
+		// Identifiers are split on case and punctuation so that "parseConfig"
+		// matches a query for "parse config"
+		{Type: "index", Statement: `DEFINE ANALYZER code_docs_analyzer TOKENIZERS blank, class, camel, punct FILTERS lowercase, snowball(english);`, OnTable: "code_symbols"},
+		{Type: "index", Statement: `DEFINE INDEX idx_code_symbols_doc_string ON code_symbols FIELDS doc_string SEARCH ANALYZER code_docs_analyzer BM25;`, OnTable: "code_symbols"},
+		{Type: "index", Statement: `DEFINE INDEX idx_code_symbols_signature ON code_symbols FIELDS signature SEARCH ANALYZER code_docs_analyzer BM25;`, OnTable: "code_symbols"},
+	}
+
+	return m.ApplyElements(ctx, elements)
+}
@@ -0,0 +1,43 @@
+package migrations
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/surrealdb/surrealdb.go"
+)
+
+// V32CodeProjectAliases adds the alias field of code projects, a friendly
+// name the code tools accept in place of a project ID.
+type V32CodeProjectAliases struct {
+	*MigrationBase
+}
+
+// NewV32CodeProjectAliases creates a new V32 migration
+func NewV32CodeProjectAliases(db *surrealdb.DB) Migration {
+	return &V32CodeProjectAliases{
+		MigrationBase: NewMigrationBase(db),
+	}
+}
+
+// Version returns the migration version
+func (m *V32CodeProjectAliases) Version() int {
+	return 32
+}
+
+// Description returns the migration description
+func (m *V32CodeProjectAliases) Description() string {
+	return "Adding aliases of code projects"
+}
+
+// Apply executes the migration
+func (m *V32CodeProjectAliases) Apply(ctx context.Context, db *surrealdb.DB) error {
+	slog.Info("Applying migration v32: Adding aliases of code projects")
+
+	elements := []SchemaElement{
+		{Type: "field", Statement: `DEFINE FIELD alias ON code_projects TYPE option<string>;`, OnTable: "code_projects"},
+		{Type: "index", Statement: `DEFINE INDEX idx_code_projects_alias ON code_projects FIELDS alias;`, OnTable: "code_projects"},
+	}
+
+	return m.ApplyElements(ctx, elements)
+}
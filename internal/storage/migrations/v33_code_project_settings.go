@@ -0,0 +1,46 @@
+package migrations
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/surrealdb/surrealdb.go"
+)
+
+// V33CodeProjectSettings adds the indexing settings a code project can
+// override: its embedder, the chunking of large symbols and the size of the
+// symbol source code stored.
+type V33CodeProjectSettings struct {
+	*MigrationBase
+}
+
+// NewV33CodeProjectSettings creates a new V33 migration
+func NewV33CodeProjectSettings(db *surrealdb.DB) Migration {
+	return &V33CodeProjectSettings{
+		MigrationBase: NewMigrationBase(db),
+	}
+}
+
+// Version returns the migration version
+func (m *V33CodeProjectSettings) Version() int {
+	return 33
+}
+
+// Description returns the migration description
+func (m *V33CodeProjectSettings) Description() string {
+	return "Adding indexing settings of code projects"
+}
+
+// Apply executes the migration
+func (m *V33CodeProjectSettings) Apply(ctx context.Context, db *surrealdb.DB) error {
+	slog.Info("Applying migration v33: Adding indexing settings of code projects")
+
+	elements := []SchemaElement{
+		{Type: "field", Statement: `DEFINE FIELD embedder ON code_projects TYPE string DEFAULT '';`, OnTable: "code_projects"},
+		{Type: "field", Statement: `DEFINE FIELD chunk_size ON code_projects TYPE int DEFAULT 0;`, OnTable: "code_projects"},
+		{Type: "field", Statement: `DEFINE FIELD chunk_overlap ON code_projects TYPE int DEFAULT 0;`, OnTable: "code_projects"},
+		{Type: "field", Statement: `DEFINE FIELD max_symbol_size ON code_projects TYPE int DEFAULT 0;`, OnTable: "code_projects"},
+	}
+
+	return m.ApplyElements(ctx, elements)
+}
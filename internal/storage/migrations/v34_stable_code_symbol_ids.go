@@ -0,0 +1,57 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/surrealdb/surrealdb.go"
+)
+
+// V34StableCodeSymbolIDs moves code symbols to record IDs derived from their
+// project, file and name path, as treesitter.SymbolID does, so that they keep
+// them when they are indexed again. Parents are referenced by those IDs,
+// derived from the name path without its last segment. Name paths are unique
+// per file rather than per project, so files may define the same names.
+type V34StableCodeSymbolIDs struct {
+	*MigrationBase
+}
+
+// NewV34StableCodeSymbolIDs creates a new V34 migration
+func NewV34StableCodeSymbolIDs(db *surrealdb.DB) Migration {
+	return &V34StableCodeSymbolIDs{
+		MigrationBase: NewMigrationBase(db),
+	}
+}
+
+// Version returns the migration version
+func (m *V34StableCodeSymbolIDs) Version() int {
+	return 34
+}
+
+// Description returns the migration description
+func (m *V34StableCodeSymbolIDs) Description() string {
+	return "Deriving code symbol IDs from their project, file and name path"
+}
+
+// Apply executes the migration
+func (m *V34StableCodeSymbolIDs) Apply(ctx context.Context, db *surrealdb.DB) error {
+	slog.Info("Applying migration v34: Deriving code symbol IDs from their project, file and name path")
+
+	stmt := `
+		BEGIN TRANSACTION;
+		REMOVE INDEX idx_code_symbol_name_path ON code_symbols;
+		DEFINE INDEX idx_code_symbol_name_path ON code_symbols FIELDS project_id, file_path, name_path UNIQUE;
+		LET $symbols = (SELECT *,
+			type::thing("code_symbols", string::slice(crypto::sha256(project_id + ":" + file_path + ":" + name_path), 0, 32)) AS id,
+			(IF parent_id != NONE THEN "code_symbols:" + string::slice(crypto::sha256(project_id + ":" + file_path + ":" + array::join(array::slice(string::split(name_path, "/"), 0, -1), "/")), 0, 32) END) AS parent_id
+			FROM code_symbols);
+		DELETE code_symbols;
+		INSERT INTO code_symbols $symbols;
+		COMMIT TRANSACTION;
+	`
+	if _, err := surrealdb.Query[[]map[string]interface{}](ctx, db, stmt, nil); err != nil {
+		return fmt.Errorf("failed to move code symbols to stable IDs: %w", err)
+	}
+	return nil
+}
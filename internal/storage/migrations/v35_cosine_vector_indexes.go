@@ -0,0 +1,49 @@
+package migrations
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/surrealdb/surrealdb.go"
+)
+
+// V35CosineVectorIndexes defines every embedding index with cosine distance.
+// The kb_document_versions index used the euclidean default, which ranks int8
+// quantized embeddings by their per-vector scale instead of their direction.
+type V35CosineVectorIndexes struct {
+	*MigrationBase
+}
+
+// NewV35CosineVectorIndexes creates a new V35 migration
+func NewV35CosineVectorIndexes(db *surrealdb.DB) Migration {
+	return &V35CosineVectorIndexes{
+		MigrationBase: NewMigrationBase(db),
+	}
+}
+
+// Version returns the migration version
+func (m *V35CosineVectorIndexes) Version() int {
+	return 35
+}
+
+// Description returns the migration description
+func (m *V35CosineVectorIndexes) Description() string {
+	return "Defining embedding indexes with cosine distance"
+}
+
+// Apply executes the migration
+func (m *V35CosineVectorIndexes) Apply(ctx context.Context, db *surrealdb.DB) error {
+	slog.Info("Applying migration v35: Defining embedding indexes with cosine distance")
+
+	// The index must be removed first so the new DIST replaces the old definition
+	stmt := `REMOVE INDEX idx_kb_versions_embedding ON kb_document_versions;`
+	if _, err := surrealdb.Query[[]map[string]interface{}](ctx, db, stmt, nil); err != nil {
+		slog.Debug("Could not remove index (may not exist)", "error", err)
+	}
+
+	elements := []SchemaElement{
+		{Type: "index", Statement: `DEFINE INDEX idx_kb_versions_embedding ON kb_document_versions FIELDS embedding MTREE DIMENSION 768 DIST COSINE;`, OnTable: "kb_document_versions"},
+	}
+
+	return m.ApplyElements(ctx, elements)
+}
@@ -0,0 +1,44 @@
+package migrations
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/surrealdb/surrealdb.go"
+)
+
+// V36PackedCodeEmbeddings adds the embedding_f16 field holding the code
+// symbol and chunk embeddings packed as half precision values when
+// embedding-storage is float16.
+type V36PackedCodeEmbeddings struct {
+	*MigrationBase
+}
+
+// NewV36PackedCodeEmbeddings creates a new V36 migration
+func NewV36PackedCodeEmbeddings(db *surrealdb.DB) Migration {
+	return &V36PackedCodeEmbeddings{
+		MigrationBase: NewMigrationBase(db),
+	}
+}
+
+// Version returns the migration version
+func (m *V36PackedCodeEmbeddings) Version() int {
+	return 36
+}
+
+// Description returns the migration description
+func (m *V36PackedCodeEmbeddings) Description() string {
+	return "Adding packed float16 code embeddings"
+}
+
+// Apply executes the migration
+func (m *V36PackedCodeEmbeddings) Apply(ctx context.Context, db *surrealdb.DB) error {
+	slog.Info("Applying migration v36: Adding packed float16 code embeddings")
+
+	elements := []SchemaElement{
+		{Type: "field", Statement: `DEFINE FIELD embedding_f16 ON code_symbols TYPE option<bytes>;`, OnTable: "code_symbols"},
+		{Type: "field", Statement: `DEFINE FIELD embedding_f16 ON code_chunks TYPE option<bytes>;`, OnTable: "code_chunks"},
+	}
+
+	return m.ApplyElements(ctx, elements)
+}
@@ -0,0 +1,42 @@
+package migrations
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/surrealdb/surrealdb.go"
+)
+
+// V37TrashRelationships adds the relationships of a deleted entity to its
+// trash entry, grouped by table, so restoring the entity restores them.
+type V37TrashRelationships struct {
+	*MigrationBase
+}
+
+// NewV37TrashRelationships creates a new V37 migration
+func NewV37TrashRelationships(db *surrealdb.DB) Migration {
+	return &V37TrashRelationships{
+		MigrationBase: NewMigrationBase(db),
+	}
+}
+
+// Version returns the migration version
+func (m *V37TrashRelationships) Version() int {
+	return 37
+}
+
+// Description returns the migration description
+func (m *V37TrashRelationships) Description() string {
+	return "Adding the relationships of deleted entities to the trash"
+}
+
+// Apply executes the migration
+func (m *V37TrashRelationships) Apply(ctx context.Context, db *surrealdb.DB) error {
+	slog.Info("Applying migration v37: Adding the relationships of deleted entities to the trash")
+
+	elements := []SchemaElement{
+		{Type: "field", Statement: `DEFINE FIELD relationships ON trash FLEXIBLE TYPE array<object> DEFAULT [];`, OnTable: "trash"},
+	}
+
+	return m.ApplyElements(ctx, elements)
+}
@@ -0,0 +1,964 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+)
+
+// moveToTrash copies the rows of the kind's table that match where into a new
+// trash entry and then deletes them, in one transaction. where references args
+// as $1, $2 and so on.
+func (p *PostgresStorage) moveToTrash(ctx context.Context, kind, userID, label, content, where string, args ...interface{}) error {
+	target, ok := trashTables[kind]
+	if !ok {
+		return fmt.Errorf("unknown trash kind %q", kind)
+	}
+
+	return p.withTx(ctx, func(ctx context.Context) error {
+		if err := p.checkPinned(ctx, kind, userID, label); err != nil {
+			return err
+		}
+
+		insertArgs := append(pgArgs{}, args...)
+		insertQuery := fmt.Sprintf(`
+			INSERT INTO trash (kind, source_table, user_id, label, content, records)
+			SELECT %s, %s, %s, %s, %s, coalesce(jsonb_agg(to_jsonb(t)), '[]')
+			FROM %s t WHERE %s
+		`, insertArgs.add(kind), insertArgs.add(target.table), insertArgs.add(userID), insertArgs.add(label), insertArgs.add(content),
+			target.table, where)
+		if _, err := p.exec(ctx, insertQuery, insertArgs...); err != nil {
+			return fmt.Errorf("failed to move %s to trash: %w", kind, err)
+		}
+
+		if _, err := p.exec(ctx, fmt.Sprintf("DELETE FROM %s WHERE %s", target.table, where), args...); err != nil {
+			return fmt.Errorf("failed to delete %s: %w", kind, err)
+		}
+		if pinnable(kind) {
+			if _, err := p.exec(ctx, "DELETE FROM pinned_memories WHERE "+pgPinWhere, pgPinArgs(kind, userID, label)...); err != nil {
+				return fmt.Errorf("failed to drop pin of %s: %w", kind, err)
+			}
+		}
+		return nil
+	})
+}
+
+const pgTrashFields = "id, kind, user_id, label, content, jsonb_array_length(records) AS record_count, deleted_at"
+
+// ListTrash lists soft-deleted items, newest first. Empty userID or kind match all.
+func (p *PostgresStorage) ListTrash(ctx context.Context, userID, kind string, limit int) ([]TrashItem, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+
+	query := `SELECT ` + pgTrashFields + ` FROM trash
+		WHERE ($1 = '' OR user_id = $1) AND ($2 = '' OR kind = $2)
+		ORDER BY deleted_at DESC
+		LIMIT $3`
+	rows, err := p.rows(ctx, query, userID, kind, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list trash: %w", err)
+	}
+
+	items := pgTrashItems(rows)
+	for i := range items {
+		items[i].Content = trashPreview(items[i].Content)
+	}
+	return items, nil
+}
+
+// RestoreFromTrash recreates the records of a trash entry with their original ids
+// and removes the entry. It fails when a live record would conflict with them.
+func (p *PostgresStorage) RestoreFromTrash(ctx context.Context, trashID string) (*TrashItem, error) {
+	rows, err := p.rows(ctx, "SELECT "+pgTrashFields+" FROM trash WHERE id = $1", trashID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read trash entry: %w", err)
+	}
+	items := pgTrashItems(rows)
+	if len(items) == 0 {
+		return nil, nil
+	}
+	item := items[0]
+
+	target, ok := trashTables[item.Kind]
+	if !ok {
+		return nil, fmt.Errorf("unknown trash kind %q", item.Kind)
+	}
+	if err := p.checkRestoreConflict(ctx, item); err != nil {
+		return nil, err
+	}
+
+	err = p.withTx(ctx, func(ctx context.Context) error {
+		insertQuery := fmt.Sprintf(`
+			INSERT INTO %[1]s
+			SELECT r.* FROM trash, jsonb_populate_recordset(NULL::%[1]s, trash.records) r
+			WHERE trash.id = $1
+		`, target.table)
+		if _, err := p.exec(ctx, insertQuery, trashID); err != nil {
+			return fmt.Errorf("failed to restore %s: %w", item.Kind, err)
+		}
+		if _, err := p.exec(ctx, "DELETE FROM trash WHERE id = $1", trashID); err != nil {
+			return fmt.Errorf("failed to remove trash entry: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// checkRestoreConflict rejects restoring a fact or document whose key or path
+// has been reused since it was deleted.
+func (p *PostgresStorage) checkRestoreConflict(ctx context.Context, item TrashItem) error {
+	switch item.Kind {
+	case TrashKindFact:
+		existing, err := p.GetFact(ctx, item.UserID, item.Label)
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			return fmt.Errorf("cannot restore fact %q: a fact with the same key exists", item.Label)
+		}
+	case TrashKindDocument:
+		existing, err := p.GetDocument(ctx, item.Label)
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			return fmt.Errorf("cannot restore document %q: a document with the same path exists", item.Label)
+		}
+	}
+	return nil
+}
+
+// PurgeTrash permanently deletes trash entries deleted before the given time
+// and returns how many were removed.
+func (p *PostgresStorage) PurgeTrash(ctx context.Context, before time.Time) (int, error) {
+	count, err := p.exec(ctx, "DELETE FROM trash WHERE deleted_at < $1", before.UTC())
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge trash: %w", err)
+	}
+	return count, nil
+}
+
+func pgTrashItems(rows []map[string]interface{}) []TrashItem {
+	var items []TrashItem
+	for _, row := range rows {
+		items = append(items, TrashItem{
+			ID:          getString(row, "id"),
+			Kind:        getString(row, "kind"),
+			UserID:      getString(row, "user_id"),
+			Label:       getString(row, "label"),
+			Content:     getString(row, "content"),
+			RecordCount: convertToInt(row["record_count"]),
+			DeletedAt:   getTime(row, "deleted_at"),
+		})
+	}
+	return items
+}
+
+// pgUserTables are the tables whose records are owned by a user_id. The
+// relationships attached to the user's entities are selected by
+// pgUserRelationships and handled first.
+var pgUserTables = []string{
+	"kv_memories",
+	"kv_memory_history",
+	"vector_memories",
+	"archived_vector_memories",
+	"events",
+	"entities",
+	"trash",
+	"memory_hits",
+	"saved_searches",
+	"saved_search_changes",
+	"pinned_memories",
+}
+
+const pgUserRelationships = "user_id = $1 OR from_entity IN (SELECT id FROM entities WHERE user_id = $1) OR to_entity IN (SELECT id FROM entities WHERE user_id = $1)"
+
+// pgUserScopes lists the user's records table by table, binding the user to
+// $1. withDocuments adds the knowledge base chunks the user contributed.
+func pgUserScopes(withDocuments bool) []userScope {
+	scopes := []userScope{{"relationships", pgUserRelationships}}
+	if withDocuments {
+		scopes = append(scopes, userScope{"knowledge_base", "user_id = $1"})
+	}
+	for _, table := range pgUserTables {
+		scopes = append(scopes, userScope{table, "user_id = $1"})
+	}
+	return scopes
+}
+
+// ListUsers returns every user_id that owns facts, vectors, events or entities,
+// with per-layer record counts, sorted by user_id
+func (p *PostgresStorage) ListUsers(ctx context.Context) ([]UserSummary, error) {
+	summaries := map[string]*UserSummary{}
+	layers := []struct {
+		table string
+		set   func(*UserSummary, int)
+	}{
+		{"kv_memories", func(u *UserSummary, n int) { u.FactCount = n }},
+		{"vector_memories", func(u *UserSummary, n int) { u.VectorCount = n }},
+		{"events", func(u *UserSummary, n int) { u.EventCount = n }},
+		{"entities", func(u *UserSummary, n int) { u.EntityCount = n }},
+	}
+
+	for _, layer := range layers {
+		counts, err := p.CountByUserID(ctx, layer.table)
+		if err != nil {
+			return nil, err
+		}
+		for userID, count := range counts {
+			summary, ok := summaries[userID]
+			if !ok {
+				summary = &UserSummary{UserID: userID}
+				summaries[userID] = summary
+			}
+			layer.set(summary, count)
+		}
+	}
+
+	users := make([]UserSummary, 0, len(summaries))
+	for _, summary := range summaries {
+		users = append(users, *summary)
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].UserID < users[j].UserID })
+	return users, nil
+}
+
+// DeleteUser permanently removes every record owned by userID, including its
+// trash and the relationships attached to its entities. It returns the number
+// of records deleted per table.
+func (p *PostgresStorage) DeleteUser(ctx context.Context, userID string) (map[string]int, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("user_id is required")
+	}
+	deleted := map[string]int{}
+	err := p.withTx(ctx, func(ctx context.Context) error {
+		return p.deleteUserRecords(ctx, pgUserScopes(false), userID, deleted)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return deleted, nil
+}
+
+// deleteUserRecords deletes the records of each scope, counting them in deleted.
+func (p *PostgresStorage) deleteUserRecords(ctx context.Context, scopes []userScope, userID string, deleted map[string]int) error {
+	for _, scope := range scopes {
+		n, err := p.exec(ctx, fmt.Sprintf("DELETE FROM %s WHERE %s", scope.table, scope.where), userID)
+		if err != nil {
+			return fmt.Errorf("failed to delete user records from %s: %w", scope.table, err)
+		}
+		if n > 0 {
+			deleted[scope.table] = n
+		}
+	}
+	return nil
+}
+
+// ExportUser returns every record owned by userID, including knowledge base
+// chunks it contributed and the relationships attached to its entities
+func (p *PostgresStorage) ExportUser(ctx context.Context, userID string) (*UserExport, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("user_id is required")
+	}
+
+	export := &UserExport{
+		UserID:     userID,
+		ExportedAt: time.Now().UTC(),
+		Tables:     map[string][]map[string]interface{}{},
+	}
+	for _, scope := range pgUserScopes(true) {
+		query := fmt.Sprintf("SELECT to_jsonb(t) - 'embedding' AS record FROM %s t WHERE %s", scope.table, scope.where)
+		rows, err := p.rows(ctx, query, userID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to export %s: %w", scope.table, err)
+		}
+		for _, row := range rows {
+			export.Tables[scope.table] = append(export.Tables[scope.table], getMap(row, "record"))
+		}
+	}
+	return export, nil
+}
+
+// PurgeUser deletes every record owned by userID, including knowledge base
+// chunks it contributed, in a single transaction that also records the purge
+// in the audit log together with archivePath. It returns the number of records
+// deleted per table.
+func (p *PostgresStorage) PurgeUser(ctx context.Context, userID, archivePath string) (map[string]int, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("user_id is required")
+	}
+
+	deleted := map[string]int{}
+	err := p.withTx(ctx, func(ctx context.Context) error {
+		if err := p.deleteUserRecords(ctx, pgUserScopes(true), userID, deleted); err != nil {
+			return err
+		}
+		details := map[string]interface{}{"archive": archivePath, "deleted": deleted}
+		_, err := p.exec(ctx, "INSERT INTO audit_log (action, subject, details) VALUES ('purge_user', $1, $2::jsonb)", userID, jsonParam(details))
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to purge user %s: %w", userID, err)
+	}
+
+	slog.Info("purged user data", "user_id", userID, "archive", archivePath)
+	return deleted, nil
+}
+
+// RenameUser moves every record owned by oldUserID to newUserID and returns the
+// number of records updated per table. The rename is refused when newUserID
+// already owns records, so that facts with the same key are never merged silently.
+func (p *PostgresStorage) RenameUser(ctx context.Context, oldUserID, newUserID string) (map[string]int, error) {
+	if oldUserID == "" || newUserID == "" {
+		return nil, fmt.Errorf("both the current and the new user_id are required")
+	}
+	if oldUserID == newUserID {
+		return nil, fmt.Errorf("the new user_id is the same as the current one")
+	}
+
+	tables := append([]string{"relationships"}, pgUserTables...)
+	for _, table := range tables {
+		count, err := p.count(ctx, fmt.Sprintf("SELECT count(*) AS count FROM %s WHERE user_id = $1", table), newUserID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check user records in %s: %w", table, err)
+		}
+		if count > 0 {
+			return nil, fmt.Errorf("user_id %q already has records in %s; delete it first or choose another name", newUserID, table)
+		}
+	}
+
+	renamed := map[string]int{}
+	err := p.withTx(ctx, func(ctx context.Context) error {
+		for _, table := range tables {
+			n, err := p.exec(ctx, fmt.Sprintf("UPDATE %s SET user_id = $2 WHERE user_id = $1", table), oldUserID, newUserID)
+			if err != nil {
+				return fmt.Errorf("failed to rename user in %s: %w", table, err)
+			}
+			if n > 0 {
+				renamed[table] = n
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return renamed, nil
+}
+
+// RecordMemoryHits stores the retrieval of the given facts, vectors and documents.
+func (p *PostgresStorage) RecordMemoryHits(ctx context.Context, hits []MemoryHit) error {
+	if len(hits) == 0 {
+		return nil
+	}
+	query := `
+		INSERT INTO memory_hits (kind, ref, user_id, tool)
+		SELECT kind, ref, coalesce(user_id, ''), coalesce(tool, '')
+		FROM jsonb_to_recordset($1::jsonb) AS h(kind TEXT, ref TEXT, user_id TEXT, tool TEXT)
+	`
+	if _, err := p.exec(ctx, query, jsonParam(hits)); err != nil {
+		return fmt.Errorf("failed to record memory hits: %w", err)
+	}
+	return nil
+}
+
+// CountMemoryHits returns how many times each fact and vector of userID, and
+// each document, was retrieved since the given time. Memories without hits
+// are left out.
+func (p *PostgresStorage) CountMemoryHits(ctx context.Context, userID string, since time.Time) ([]MemoryHitCount, error) {
+	query := `
+		SELECT kind, ref, count(*) AS hits, max(created_at) AS last_hit
+		FROM memory_hits
+		WHERE created_at >= $1 AND (user_id = $2 OR kind = $3)
+		GROUP BY kind, ref
+	`
+	rows, err := p.rows(ctx, query, since.UTC(), userID, TrashKindDocument)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count memory hits: %w", err)
+	}
+
+	var counts []MemoryHitCount
+	for _, row := range rows {
+		counts = append(counts, MemoryHitCount{
+			Kind:    getString(row, "kind"),
+			Ref:     getString(row, "ref"),
+			Hits:    convertToInt(row["hits"]),
+			LastHit: getTime(row, "last_hit"),
+		})
+	}
+	return counts, nil
+}
+
+// GetIdempotencyResult returns the result saved for key by tool since the
+// given time, and whether there is one.
+func (p *PostgresStorage) GetIdempotencyResult(ctx context.Context, tool, key string, since time.Time) (string, bool, error) {
+	row, err := p.row(ctx, "SELECT result FROM idempotency_keys WHERE tool = $1 AND key = $2 AND created_at >= $3", tool, key, since.UTC())
+	if err != nil {
+		return "", false, fmt.Errorf("failed to get idempotency key: %w", err)
+	}
+	if row == nil {
+		return "", false, nil
+	}
+	return getString(row, "result"), true, nil
+}
+
+// SaveIdempotencyResult saves the result of tool for key, replacing an
+// expired one, and drops the results saved before expireBefore.
+func (p *PostgresStorage) SaveIdempotencyResult(ctx context.Context, tool, key, result string, expireBefore time.Time) error {
+	err := p.withTx(ctx, func(ctx context.Context) error {
+		if _, err := p.exec(ctx, "DELETE FROM idempotency_keys WHERE created_at < $1", expireBefore.UTC()); err != nil {
+			return err
+		}
+		_, err := p.exec(ctx, `
+			INSERT INTO idempotency_keys (tool, key, result) VALUES ($1, $2, $3)
+			ON CONFLICT (tool, key) DO UPDATE SET result = EXCLUDED.result, created_at = now()
+		`, tool, key, result)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save idempotency key: %w", err)
+	}
+	return nil
+}
+
+// GetStats returns statistics about stored memories by counting current records.
+func (p *PostgresStorage) GetStats(ctx context.Context, userID string) (*MemoryStats, error) {
+	scoped := userID != "" && userID != "global"
+
+	userFilter := ""
+	var args []interface{}
+	if scoped {
+		userFilter = " WHERE user_id = $1"
+		args = []interface{}{userID}
+	}
+
+	// Documents are counted once, not per chunk, and like entities and
+	// relationships they are shared by all users
+	query := fmt.Sprintf(`
+		SELECT
+			(SELECT count(*) FROM kv_memories%[1]s) AS key_value_count,
+			(SELECT count(*) FROM vector_memories%[1]s) AS vector_count,
+			(SELECT count(*) FROM entities) AS entity_count,
+			(SELECT count(*) FROM relationships) AS relationship_count,
+			(SELECT count(DISTINCT coalesce(source_file, file_path)) FROM knowledge_base) AS document_count,
+			(SELECT count(*) FROM events%[1]s) AS event_count
+	`, userFilter)
+	row, err := p.row(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stats: %w", err)
+	}
+
+	var sizeQuery string
+	if scoped {
+		sizeQuery = `
+			SELECT coalesce((SELECT sum(octet_length(content)) FROM vector_memories WHERE user_id = $1), 0)
+			     + coalesce((SELECT sum(octet_length(value::text)) FROM kv_memories WHERE user_id = $1), 0)
+			     + coalesce((SELECT sum(octet_length(content)) FROM knowledge_base WHERE user_id = $1), 0) AS count
+		`
+	} else {
+		sizeQuery = `
+			SELECT coalesce((SELECT sum(octet_length(name)) FROM entities), 0)
+			     + coalesce((SELECT sum(octet_length(relationship_type)) FROM relationships), 0)
+			     + coalesce((SELECT sum(octet_length(content)) FROM knowledge_base), 0) AS count
+		`
+	}
+	totalSize, err := p.count(ctx, sizeQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stats: %w", err)
+	}
+
+	return &MemoryStats{
+		KeyValueCount:     convertToInt(row["key_value_count"]),
+		VectorCount:       convertToInt(row["vector_count"]),
+		EntityCount:       convertToInt(row["entity_count"]),
+		RelationshipCount: convertToInt(row["relationship_count"]),
+		DocumentCount:     convertToInt(row["document_count"]),
+		EventCount:        convertToInt(row["event_count"]),
+		TotalSize:         int64(totalSize),
+	}, nil
+}
+
+// RecountUserStats has nothing to repair: GetStats counts the current records
+// on every call, so there are no stored counters that could drift.
+func (p *PostgresStorage) RecountUserStats(ctx context.Context, userID string) ([]UserStatChange, error) {
+	return []UserStatChange{}, nil
+}
+
+// CountByUserID returns counts grouped by user_id for a given table
+func (p *PostgresStorage) CountByUserID(ctx context.Context, tableName string) (map[string]int, error) {
+	counts := make(map[string]int)
+	if tableName == "" {
+		return counts, fmt.Errorf("table name is required")
+	}
+	table, err := pgTable(tableName)
+	if err != nil {
+		return counts, err
+	}
+
+	query := fmt.Sprintf("SELECT user_id, count(*) AS count FROM %s WHERE user_id IS NOT NULL GROUP BY user_id ORDER BY count DESC", table)
+	rows, err := p.rows(ctx, query)
+	if err != nil {
+		return counts, fmt.Errorf("failed to count user_ids for table %s: %w", tableName, err)
+	}
+	for _, row := range rows {
+		counts[getString(row, "user_id")] = convertToInt(row["count"])
+	}
+	return counts, nil
+}
+
+// GetDatabaseInfo returns the server location, schema version and vector
+// index health.
+func (p *PostgresStorage) GetDatabaseInfo(ctx context.Context) (*DatabaseInfo, error) {
+	version, err := p.schemaVersion(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get schema version: %w", err)
+	}
+	info := &DatabaseInfo{
+		Mode:                "remote",
+		Location:            redactedURL(p.url),
+		SchemaVersion:       version,
+		LatestSchemaVersion: postgresSchemaVersion,
+	}
+
+	defined, err := p.textColumn(ctx, "SELECT tablename || '.' || indexname AS value FROM pg_indexes WHERE schemaname = current_schema()")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list indexes: %w", err)
+	}
+	present := map[string]bool{}
+	for _, name := range defined {
+		present[name] = true
+	}
+
+	info.Indexes = make([]IndexHealth, 0, len(vectorIndexes))
+	for _, idx := range vectorIndexes {
+		idx.Defined = present[idx.Table+"."+idx.Index]
+		info.Indexes = append(info.Indexes, idx)
+	}
+	return info, nil
+}
+
+// ExecuteBatch applies ops inside a single transaction. Either every operation
+// is committed and one BatchResult is returned per operation, in order, or
+// nothing is written and a *BatchError describes the failure. Relationships may
+// reference entities created earlier in the same batch by name.
+func (p *PostgresStorage) ExecuteBatch(ctx context.Context, ops []BatchOperation) ([]BatchResult, error) {
+	if len(ops) == 0 {
+		return nil, nil
+	}
+
+	// Validate everything before opening the transaction
+	for i, op := range ops {
+		switch op.Op {
+		case BatchOpSaveFact:
+			if op.UserID == "" || op.Key == "" {
+				return nil, &BatchError{Index: i, Message: "save_fact requires user_id and key"}
+			}
+		case BatchOpAddVector:
+			if op.Content == "" {
+				return nil, &BatchError{Index: i, Message: "add_vector requires content"}
+			}
+		case BatchOpCreateEntity:
+			if op.EntityType == "" || op.Name == "" {
+				return nil, &BatchError{Index: i, Message: "create_entity requires entity_type and name"}
+			}
+			if err := checkBatchEntity(ctx, p.GetEntityType, i, op); err != nil {
+				return nil, err
+			}
+		case BatchOpCreateRelationship:
+			if op.From == "" || op.To == "" {
+				return nil, &BatchError{Index: i, Message: "create_relationship requires from_entity and to_entity"}
+			}
+			if !relationshipTableRe.MatchString(op.RelationshipType) {
+				return nil, &BatchError{Index: i, Message: fmt.Sprintf("invalid relationship_type %q: use letters, digits and underscores", op.RelationshipType)}
+			}
+		default:
+			return nil, &BatchError{Index: i, Message: fmt.Sprintf("unknown operation %q", op.Op)}
+		}
+	}
+
+	results := make([]BatchResult, len(ops))
+	err := p.withTx(ctx, func(ctx context.Context) error {
+		for i, op := range ops {
+			id, err := p.batchOperation(ctx, op)
+			if err != nil {
+				return &BatchError{Index: i, Message: fmt.Sprintf("batch operation %d: %v", i, err)}
+			}
+			results[i] = BatchResult{Index: i, Op: op.Op, ID: id}
+		}
+		return nil
+	})
+	if err != nil {
+		if batchErr, ok := err.(*BatchError); ok {
+			return nil, batchErr
+		}
+		return nil, batchErrorFrom(err.Error())
+	}
+	return results, nil
+}
+
+// batchOperation applies a single validated batch operation and returns the
+// ID of the record it wrote.
+func (p *PostgresStorage) batchOperation(ctx context.Context, op BatchOperation) (string, error) {
+	switch op.Op {
+	case BatchOpSaveFact:
+		return p.saveFact(ctx, op.UserID, op.Key, op.Value)
+
+	case BatchOpAddVector:
+		metadata := op.Metadata
+		if metadata == nil {
+			metadata = map[string]interface{}{}
+		}
+		metadata = withProvenance(ctx, metadata)
+		if err := p.checkEmbedding("vector_memories", op.Embedding); err != nil {
+			return "", err
+		}
+		row, err := p.row(ctx, "INSERT INTO vector_memories (user_id, content, embedding, metadata, embedding_model, embedding_dim) VALUES (nullif($1, ''), $2, $3::vector, $4::jsonb, $5, $6) RETURNING id",
+			op.UserID, op.Content, vectorParam(op.Embedding), jsonParam(metadata), p.embeddingModelOf(ctx, "vector_memories", op.Embedding), len(op.Embedding))
+		if err != nil {
+			return "", err
+		}
+		return getString(row, "id"), nil
+
+	case BatchOpCreateEntity:
+		return p.createEntity(ctx, op.EntityType, op.Name, op.Properties)
+
+	default:
+		return p.createRelationship(ctx, op.From, op.To, op.RelationshipType, op.Properties)
+	}
+}
+
+// HybridSearch performs a combined search across vector, graph, and key-value stores
+func (p *PostgresStorage) HybridSearch(ctx context.Context, userID string, queryEmbedding []float32, entities []string, limit int) (*HybridSearchResult, error) {
+	start := time.Now()
+
+	vectorResults, err := p.SearchSimilar(ctx, userID, queryEmbedding, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed vector search: %w", err)
+	}
+
+	var graphResults []GraphResult
+	for _, entity := range entities {
+		results, err := p.TraverseGraph(ctx, entity, "", 2)
+		if err != nil {
+			slog.Warn("failed to traverse graph for entity", "entity", entity, "error", err)
+			continue
+		}
+		graphResults = append(graphResults, results...)
+	}
+
+	facts, err := p.ListFacts(ctx, userID)
+	if err != nil {
+		slog.Warn("failed to get facts", "error", err)
+		facts = make(map[string]interface{})
+	}
+
+	return &HybridSearchResult{
+		VectorResults: vectorResults,
+		GraphResults:  graphResults,
+		Facts:         facts,
+		TotalResults:  len(vectorResults) + len(graphResults) + len(facts),
+		QueryTime:     time.Since(start),
+	}, nil
+}
+
+// WatchChanges streams the records created, updated and deleted in table,
+// only those of userID when set, until ctx is done. Postgres has no live
+// queries here, so the table is polled every changePollInterval. The channel
+// is closed when watching stops.
+func (p *PostgresStorage) WatchChanges(ctx context.Context, table, userID string) (<-chan ChangeEvent, error) {
+	if !changeFeedTables[table] {
+		return nil, fmt.Errorf("table %q does not support change notifications", table)
+	}
+	previous, err := p.changeSnapshot(ctx, table, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make(chan ChangeEvent, 16)
+	go func() {
+		defer close(changes)
+		ticker := time.NewTicker(changePollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			current, err := p.changeSnapshot(ctx, table, userID)
+			if err != nil {
+				if ctx.Err() == nil {
+					slog.Warn("failed to poll for changes", "table", table, "error", err)
+				}
+				continue
+			}
+			for _, change := range p.diffSnapshots(ctx, table, previous, current) {
+				select {
+				case changes <- change:
+				case <-ctx.Done():
+					return
+				}
+			}
+			previous = current
+		}
+	}()
+	return changes, nil
+}
+
+// changeSnapshot returns the last change time of every record of table, by ID.
+func (p *PostgresStorage) changeSnapshot(ctx context.Context, table, userID string) (map[string]time.Time, error) {
+	changed := "updated_at"
+	if table == "events" {
+		changed = "created_at"
+	}
+	query := fmt.Sprintf("SELECT id, %s AS changed_at FROM %s", changed, table)
+	var args []interface{}
+	if userID != "" {
+		query += " WHERE user_id = $1"
+		args = append(args, userID)
+	}
+	rows, err := p.rows(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", table, err)
+	}
+
+	snapshot := make(map[string]time.Time, len(rows))
+	for _, row := range rows {
+		snapshot[getString(row, "id")] = getTime(row, "changed_at")
+	}
+	return snapshot, nil
+}
+
+// diffSnapshots returns the changes between two snapshots, loading the
+// records that were created or updated.
+func (p *PostgresStorage) diffSnapshots(ctx context.Context, table string, previous, current map[string]time.Time) []ChangeEvent {
+	now := time.Now().UTC()
+	var changes []ChangeEvent
+	for id, changed := range current {
+		before, existed := previous[id]
+		if existed && before.Equal(changed) {
+			continue
+		}
+		change := ChangeEvent{Action: ChangeUpdate, Table: table, ID: id, At: now}
+		if !existed {
+			change.Action = ChangeCreate
+		}
+		if row, err := p.row(ctx, fmt.Sprintf("SELECT to_jsonb(t) - 'embedding' AS record FROM %s t WHERE id = $1", table), id); err == nil && row != nil {
+			change.Record = getMap(row, "record")
+		}
+		changes = append(changes, change)
+	}
+	for id := range previous {
+		if _, ok := current[id]; !ok {
+			changes = append(changes, ChangeEvent{Action: ChangeDelete, Table: table, ID: id, At: now})
+		}
+	}
+	return changes
+}
+
+// SaveSearch stores search under its user and name, replacing the search
+// saved before with that name. Replacing a search forgets its last run, so
+// its next scheduled run starts a new baseline.
+func (p *PostgresStorage) SaveSearch(ctx context.Context, search SavedSearch) error {
+	_, err := p.exec(ctx, `
+		INSERT INTO saved_searches (user_id, name, tool, arguments, description, interval_seconds) VALUES ($1, $2, $3, $4::jsonb, $5, $6)
+		ON CONFLICT (user_id, name) DO UPDATE SET tool = EXCLUDED.tool, arguments = EXCLUDED.arguments,
+			description = EXCLUDED.description, interval_seconds = EXCLUDED.interval_seconds,
+			last_run_at = NULL, last_results = '[]', updated_at = now()
+	`, search.UserID, search.Name, search.Tool, jsonParam(search.Arguments), search.Description, search.IntervalSeconds)
+	if err != nil {
+		return fmt.Errorf("failed to save search: %w", err)
+	}
+	return nil
+}
+
+// GetSavedSearch returns the search saved by userID under name, or nil when
+// there is none.
+func (p *PostgresStorage) GetSavedSearch(ctx context.Context, userID, name string) (*SavedSearch, error) {
+	row, err := p.row(ctx, "SELECT * FROM saved_searches WHERE user_id = $1 AND name = $2", userID, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get saved search: %w", err)
+	}
+	if row == nil {
+		return nil, nil
+	}
+	search := savedSearchFromRow(row)
+	return &search, nil
+}
+
+// ListSavedSearches returns the searches saved by userID, or by every user
+// when userID is empty, sorted by name.
+func (p *PostgresStorage) ListSavedSearches(ctx context.Context, userID string) ([]SavedSearch, error) {
+	rows, err := p.rows(ctx, "SELECT * FROM saved_searches WHERE $1 = '' OR user_id = $1 ORDER BY name", userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list saved searches: %w", err)
+	}
+	searches := make([]SavedSearch, 0, len(rows))
+	for _, row := range rows {
+		searches = append(searches, savedSearchFromRow(row))
+	}
+	return searches, nil
+}
+
+// DeleteSavedSearch removes the search saved by userID under name and
+// reports whether there was one.
+func (p *PostgresStorage) DeleteSavedSearch(ctx context.Context, userID, name string) (bool, error) {
+	n, err := p.exec(ctx, "DELETE FROM saved_searches WHERE user_id = $1 AND name = $2", userID, name)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete saved search: %w", err)
+	}
+	return n > 0, nil
+}
+
+// RecordSavedSearchRun stores the results of a scheduled run of a saved
+// search and, when it found new results, the change.
+func (p *PostgresStorage) RecordSavedSearchRun(ctx context.Context, userID, name string, runAt time.Time, results []string, change *SearchChange) error {
+	if results == nil {
+		results = []string{}
+	}
+	err := p.withTx(ctx, func(ctx context.Context) error {
+		if _, err := p.exec(ctx, "UPDATE saved_searches SET last_run_at = $1, last_results = $2::jsonb WHERE user_id = $3 AND name = $4", runAt.UTC(), jsonParam(results), userID, name); err != nil {
+			return err
+		}
+		if change == nil {
+			return nil
+		}
+		_, err := p.exec(ctx, "INSERT INTO saved_search_changes (user_id, name, tool, items, run_at) VALUES ($1, $2, $3, $4::jsonb, $5)", userID, name, change.Tool, jsonParam(change.Items), runAt.UTC())
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record saved search run: %w", err)
+	}
+	return nil
+}
+
+// ListSearchChanges returns the changes found by the scheduled runs of the
+// searches of userID since the given time, newest first. An empty name
+// selects every search of the user.
+func (p *PostgresStorage) ListSearchChanges(ctx context.Context, userID, name string, since time.Time, limit int) ([]SearchChange, error) {
+	rows, err := p.rows(ctx, `
+		SELECT * FROM saved_search_changes
+		WHERE user_id = $1 AND ($2 = '' OR name = $2) AND run_at >= $3
+		ORDER BY run_at DESC LIMIT $4`, userID, name, since.UTC(), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list saved search changes: %w", err)
+	}
+	changes := make([]SearchChange, 0, len(rows))
+	for _, row := range rows {
+		changes = append(changes, searchChangeFromRow(row))
+	}
+	return changes, nil
+}
+
+// PurgeSearchChanges deletes the changes found before the given time and
+// returns how many were removed.
+func (p *PostgresStorage) PurgeSearchChanges(ctx context.Context, before time.Time) (int, error) {
+	count, err := p.exec(ctx, "DELETE FROM saved_search_changes WHERE run_at < $1", before.UTC())
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge saved search changes: %w", err)
+	}
+	return count, nil
+}
+
+// pgPinWhere selects the pin of an item bound by pgPinArgs. Vector IDs and
+// document paths are unique, so only fact pins are matched by user.
+const pgPinWhere = "kind = $1 AND ref = $2 AND (kind <> 'fact' OR user_id = $3)"
+
+func pgPinArgs(kind, userID, ref string) []interface{} {
+	if kind == TrashKindDocument {
+		userID = ""
+	}
+	return []interface{}{kind, ref, userID}
+}
+
+// SetPinned pins or unpins a fact, vector or document and reports whether
+// its pin changed.
+func (p *PostgresStorage) SetPinned(ctx context.Context, kind, userID, ref string, pinned bool) (bool, error) {
+	if !pinnable(kind) {
+		return false, fmt.Errorf("cannot pin %s items", kind)
+	}
+	current, err := p.isPinned(ctx, kind, userID, ref)
+	if err != nil {
+		return false, err
+	}
+	if current == pinned {
+		return false, nil
+	}
+
+	query := "DELETE FROM pinned_memories WHERE " + pgPinWhere
+	if pinned {
+		query = "INSERT INTO pinned_memories (kind, ref, user_id) VALUES ($1, $2, $3) ON CONFLICT DO NOTHING"
+	}
+	if _, err := p.exec(ctx, query, pgPinArgs(kind, userID, ref)...); err != nil {
+		return false, fmt.Errorf("failed to set pin of %s %q: %w", kind, ref, err)
+	}
+	return true, nil
+}
+
+// ListPinned returns the pinned facts and vectors of userID, or of every user
+// when userID is empty, and the pinned documents. An empty kind lists every
+// kind.
+func (p *PostgresStorage) ListPinned(ctx context.Context, userID, kind string) ([]PinnedItem, error) {
+	rows, err := p.rows(ctx, `
+		SELECT kind, user_id, ref, pinned_at FROM pinned_memories
+		WHERE ($1 = '' OR user_id = $1 OR kind = 'document') AND ($2 = '' OR kind = $2)
+		ORDER BY kind, ref`, userID, kind)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pinned items: %w", err)
+	}
+	items := make([]PinnedItem, 0, len(rows))
+	for _, row := range rows {
+		items = append(items, pinnedItemFromRow(row))
+	}
+	return items, nil
+}
+
+func (p *PostgresStorage) isPinned(ctx context.Context, kind, userID, ref string) (bool, error) {
+	row, err := p.row(ctx, "SELECT 1 AS pinned FROM pinned_memories WHERE "+pgPinWhere+" LIMIT 1", pgPinArgs(kind, userID, ref)...)
+	if err != nil {
+		return false, fmt.Errorf("failed to check pin of %s %q: %w", kind, ref, err)
+	}
+	return row != nil, nil
+}
+
+// checkPinned fails with ErrPinned when the item is pinned, unless ctx comes
+// from WithForce.
+func (p *PostgresStorage) checkPinned(ctx context.Context, kind, userID, ref string) error {
+	if !pinnable(kind) || forced(ctx) {
+		return nil
+	}
+	pinned, err := p.isPinned(ctx, kind, userID, ref)
+	if err != nil {
+		return err
+	}
+	if pinned {
+		return pinnedError(kind, ref)
+	}
+	return nil
+}
+
+// checkPinnedVectors fails with ErrPinned when any of the vectors is pinned,
+// unless ctx comes from WithForce. Forced, it drops their pins.
+func (p *PostgresStorage) checkPinnedVectors(ctx context.Context, ids []string) error {
+	if forced(ctx) {
+		if _, err := p.exec(ctx, "DELETE FROM pinned_memories WHERE kind = 'vector' AND ref = ANY($1)", ids); err != nil {
+			return fmt.Errorf("failed to drop pins: %w", err)
+		}
+		return nil
+	}
+	row, err := p.row(ctx, "SELECT ref FROM pinned_memories WHERE kind = 'vector' AND ref = ANY($1) LIMIT 1", ids)
+	if err != nil {
+		return fmt.Errorf("failed to check pins: %w", err)
+	}
+	if row != nil {
+		return pinnedError(TrashKindVector, getString(row, "ref"))
+	}
+	return nil
+}
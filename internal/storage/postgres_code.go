@@ -0,0 +1,776 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/madeindigio/remembrances-mcp/pkg/treesitter"
+)
+
+// ===== PROJECT OPERATIONS =====
+
+// CreateCodeProject creates or updates a code project, keeping its watcher setting
+func (p *PostgresStorage) CreateCodeProject(ctx context.Context, project *treesitter.CodeProject) error {
+	langStats := make(map[string]interface{})
+	for lang, count := range project.LanguageStats {
+		langStats[string(lang)] = count
+	}
+
+	query := `
+		INSERT INTO code_projects (project_id, name, root_path, language_stats, indexing_status, last_indexed_at)
+		VALUES ($1, $2, $3, $4::jsonb, $5, $6)
+		ON CONFLICT (project_id) DO UPDATE SET
+			name = EXCLUDED.name,
+			root_path = EXCLUDED.root_path,
+			language_stats = EXCLUDED.language_stats,
+			indexing_status = EXCLUDED.indexing_status,
+			last_indexed_at = coalesce(EXCLUDED.last_indexed_at, code_projects.last_indexed_at),
+			updated_at = now()
+	`
+	if _, err := p.exec(ctx, query, project.ProjectID, project.Name, project.RootPath, jsonParam(langStats),
+		string(project.IndexingStatus), project.LastIndexedAt); err != nil {
+		return fmt.Errorf("failed to upsert project: %w", err)
+	}
+	return nil
+}
+
+// GetCodeProject retrieves a code project by ID
+func (p *PostgresStorage) GetCodeProject(ctx context.Context, projectID string) (*CodeProject, error) {
+	projects, err := pgDecode[CodeProject](ctx, p, "SELECT * FROM code_projects WHERE project_id = $1", projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get project: %w", err)
+	}
+	if len(projects) == 0 {
+		return nil, nil
+	}
+	return &projects[0], nil
+}
+
+// ListCodeProjects lists all code projects
+func (p *PostgresStorage) ListCodeProjects(ctx context.Context) ([]CodeProject, error) {
+	projects, err := pgDecode[CodeProject](ctx, p, "SELECT * FROM code_projects ORDER BY name ASC")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list projects: %w", err)
+	}
+	return projects, nil
+}
+
+// UpdateProjectStatus updates the indexing status of a project
+func (p *PostgresStorage) UpdateProjectStatus(ctx context.Context, projectID string, status treesitter.IndexingStatus) error {
+	_, err := p.exec(ctx, "UPDATE code_projects SET indexing_status = $2, updated_at = now() WHERE project_id = $1", projectID, string(status))
+	return err
+}
+
+// UpdateProjectWatcher enables or disables the file watcher of a project
+func (p *PostgresStorage) UpdateProjectWatcher(ctx context.Context, projectID string, enabled bool) error {
+	_, err := p.exec(ctx, "UPDATE code_projects SET watcher_enabled = $2, updated_at = now() WHERE project_id = $1", projectID, enabled)
+	return err
+}
+
+// UpdateCodeProjectRoot updates the root path of a project
+func (p *PostgresStorage) UpdateCodeProjectRoot(ctx context.Context, projectID, rootPath string) error {
+	_, err := p.exec(ctx, "UPDATE code_projects SET root_path = $2, updated_at = now() WHERE project_id = $1", projectID, rootPath)
+	return err
+}
+
+// SetCodeProjectAlias sets the alias of a project, or removes it when alias
+// is empty
+func (p *PostgresStorage) SetCodeProjectAlias(ctx context.Context, projectID, alias string) error {
+	_, err := p.exec(ctx, "UPDATE code_projects SET alias = nullif($2::text, ''), updated_at = now() WHERE project_id = $1", projectID, alias)
+	return err
+}
+
+// UpdateCodeProjectSettings replaces the indexing settings of a project
+func (p *PostgresStorage) UpdateCodeProjectSettings(ctx context.Context, projectID string, settings CodeProjectSettings) error {
+	_, err := p.exec(ctx, `UPDATE code_projects SET embedder = $2, chunk_size = $3, chunk_overlap = $4, max_symbol_size = $5,
+		updated_at = now() WHERE project_id = $1`,
+		projectID, settings.Embedder, settings.ChunkSize, settings.ChunkOverlap, settings.MaxSymbolSize)
+	return err
+}
+
+// GetCodeProjectByAlias retrieves a code project by alias
+func (p *PostgresStorage) GetCodeProjectByAlias(ctx context.Context, alias string) (*CodeProject, error) {
+	projects, err := pgDecode[CodeProject](ctx, p, "SELECT * FROM code_projects WHERE alias = $1", alias)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get project by alias: %w", err)
+	}
+	if len(projects) == 0 {
+		return nil, nil
+	}
+	return &projects[0], nil
+}
+
+// DeleteCodeProject deletes a project and all its files, symbols, chunks, dependencies, calls and jobs
+func (p *PostgresStorage) DeleteCodeProject(ctx context.Context, projectID string) error {
+	tables := []string{"code_chunks", "code_symbols", "code_dependencies", "code_calls", "code_files", "code_indexing_jobs", "code_projects"}
+	return p.withTx(ctx, func(ctx context.Context) error {
+		for _, table := range tables {
+			if _, err := p.exec(ctx, fmt.Sprintf("DELETE FROM %s WHERE project_id = $1", table), projectID); err != nil {
+				return fmt.Errorf("failed to delete project resources: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// ===== FILE OPERATIONS =====
+
+const pgCodeFileFields = "id, project_id, file_path, language, file_hash, symbols_count, indexed_at, content_gz IS NOT NULL AS has_content"
+
+// SaveCodeFile saves or updates a code file
+func (p *PostgresStorage) SaveCodeFile(ctx context.Context, file *treesitter.CodeFile) error {
+	var contentGz interface{}
+	if file.Content != nil {
+		encoded, err := compressContent(file.Content)
+		if err != nil {
+			return fmt.Errorf("failed to compress file contents: %w", err)
+		}
+		contentGz = encoded
+	}
+
+	query := `
+		INSERT INTO code_files (project_id, file_path, language, file_hash, symbols_count, content_gz)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (project_id, file_path) DO UPDATE SET
+			language = EXCLUDED.language,
+			file_hash = EXCLUDED.file_hash,
+			symbols_count = EXCLUDED.symbols_count,
+			content_gz = EXCLUDED.content_gz,
+			indexed_at = now()
+	`
+	if _, err := p.exec(ctx, query, file.ProjectID, file.FilePath, string(file.Language), file.FileHash, file.SymbolsCount, contentGz); err != nil {
+		return fmt.Errorf("failed to save file: %w", err)
+	}
+	return nil
+}
+
+// GetCodeFile retrieves a code file by project and path
+func (p *PostgresStorage) GetCodeFile(ctx context.Context, projectID, filePath string) (*CodeFile, error) {
+	files, err := pgDecode[CodeFile](ctx, p, "SELECT "+pgCodeFileFields+" FROM code_files WHERE project_id = $1 AND file_path = $2", projectID, filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file: %w", err)
+	}
+	if len(files) == 0 {
+		return nil, nil
+	}
+	return &files[0], nil
+}
+
+// ListCodeFiles lists all files in a project
+func (p *PostgresStorage) ListCodeFiles(ctx context.Context, projectID string) ([]CodeFile, error) {
+	files, err := pgDecode[CodeFile](ctx, p, "SELECT "+pgCodeFileFields+" FROM code_files WHERE project_id = $1 ORDER BY file_path ASC", projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files: %w", err)
+	}
+	return files, nil
+}
+
+// GetCodeFileContent returns the stored contents of an indexed file, or nil
+// when the file is unknown or was indexed without storing its contents
+func (p *PostgresStorage) GetCodeFileContent(ctx context.Context, projectID, filePath string) ([]byte, error) {
+	row, err := p.row(ctx, "SELECT content_gz FROM code_files WHERE project_id = $1 AND file_path = $2", projectID, filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file contents: %w", err)
+	}
+	encoded := getString(row, "content_gz")
+	if encoded == "" {
+		return nil, nil
+	}
+	content, err := decompressContent(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress file contents: %w", err)
+	}
+	return content, nil
+}
+
+// DeleteCodeFile deletes a file and all its symbols, chunks, dependencies and calls
+func (p *PostgresStorage) DeleteCodeFile(ctx context.Context, projectID, filePath string) error {
+	tables := []string{"code_chunks", "code_symbols", "code_dependencies", "code_calls", "code_files"}
+	return p.withTx(ctx, func(ctx context.Context) error {
+		for _, table := range tables {
+			if _, err := p.exec(ctx, fmt.Sprintf("DELETE FROM %s WHERE project_id = $1 AND file_path = $2", table), projectID, filePath); err != nil {
+				return fmt.Errorf("failed to delete file: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// ClearCodeFileHashes forgets the content hashes of the files of a project,
+// so that indexing it again processes every file
+func (p *PostgresStorage) ClearCodeFileHashes(ctx context.Context, projectID string) error {
+	_, err := p.exec(ctx, "UPDATE code_files SET file_hash = '' WHERE project_id = $1", projectID)
+	return err
+}
+
+// ===== SYMBOL OPERATIONS =====
+
+const pgCodeSymbolFields = `id, project_id, file_path, language, symbol_type, name, name_path,
+	start_line, end_line, start_byte, end_byte, source_code, revision, signature, doc_string,
+	embedding::text AS embedding, parent_id, metadata, created_at, updated_at`
+
+// SaveCodeSymbol saves or updates a code symbol
+func (p *PostgresStorage) SaveCodeSymbol(ctx context.Context, symbol *treesitter.CodeSymbol) error {
+	if err := p.checkEmbedding("code_symbols", symbol.Embedding); err != nil {
+		return err
+	}
+	var metadata interface{}
+	if symbol.Metadata != nil {
+		metadata = jsonParam(symbol.Metadata)
+	}
+	var parentID interface{}
+	if symbol.ParentID != nil && *symbol.ParentID != "" {
+		parentID = codeSymbolRecordID(*symbol.ParentID)
+	}
+
+	query := `
+		INSERT INTO code_symbols (project_id, file_path, language, symbol_type, name, name_path,
+			start_line, end_line, start_byte, end_byte, source_code, revision, signature, doc_string,
+			embedding, parent_id, metadata, embedding_model, embedding_dim, id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, nullif($11, ''), nullif($12, ''), nullif($13, ''), nullif($14, ''),
+			$15::vector, $16, $17::jsonb, nullif($18, ''), nullif($19, 0), $20)
+		ON CONFLICT (project_id, file_path, name_path) DO UPDATE SET
+			language = EXCLUDED.language,
+			symbol_type = EXCLUDED.symbol_type,
+			name = EXCLUDED.name,
+			start_line = EXCLUDED.start_line,
+			end_line = EXCLUDED.end_line,
+			start_byte = EXCLUDED.start_byte,
+			end_byte = EXCLUDED.end_byte,
+			source_code = coalesce(EXCLUDED.source_code, code_symbols.source_code),
+			revision = coalesce(EXCLUDED.revision, code_symbols.revision),
+			signature = coalesce(EXCLUDED.signature, code_symbols.signature),
+			doc_string = coalesce(EXCLUDED.doc_string, code_symbols.doc_string),
+			embedding = coalesce(EXCLUDED.embedding, code_symbols.embedding),
+			embedding_model = coalesce(EXCLUDED.embedding_model, code_symbols.embedding_model),
+			embedding_dim = coalesce(EXCLUDED.embedding_dim, code_symbols.embedding_dim),
+			parent_id = coalesce(EXCLUDED.parent_id, code_symbols.parent_id),
+			metadata = coalesce(EXCLUDED.metadata, code_symbols.metadata),
+			updated_at = now()
+	`
+	_, err := p.exec(ctx, query, symbol.ProjectID, symbol.FilePath, string(symbol.Language), string(symbol.SymbolType),
+		symbol.Name, symbol.NamePath, symbol.StartLine, symbol.EndLine, symbol.StartByte, symbol.EndByte,
+		symbol.SourceCode, symbol.Revision, symbol.Signature, symbol.DocString,
+		vectorParam(symbol.Embedding), parentID, metadata,
+		p.embeddingModelOf(ctx, "code_symbols", symbol.Embedding), len(symbol.Embedding),
+		codeSymbolRecordID(codeSymbolKey(symbol)))
+	if err != nil {
+		return fmt.Errorf("failed to save symbol: %w", err)
+	}
+	return nil
+}
+
+// SaveCodeSymbols saves multiple symbols in batch
+func (p *PostgresStorage) SaveCodeSymbols(ctx context.Context, symbols []*treesitter.CodeSymbol) error {
+	return p.withTx(ctx, func(ctx context.Context) error {
+		for _, symbol := range symbols {
+			if err := p.SaveCodeSymbol(ctx, symbol); err != nil {
+				return fmt.Errorf("failed to save symbol %s: %w", symbol.NamePath, err)
+			}
+		}
+		return nil
+	})
+}
+
+// GetCodeSymbol retrieves a symbol by project and name path
+func (p *PostgresStorage) GetCodeSymbol(ctx context.Context, projectID, namePath string) (*CodeSymbol, error) {
+	symbols, err := pgDecode[CodeSymbol](ctx, p, "SELECT "+pgCodeSymbolFields+" FROM code_symbols WHERE project_id = $1 AND name_path = $2", projectID, namePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get symbol: %w", err)
+	}
+	if len(symbols) == 0 {
+		return nil, nil
+	}
+	return &symbols[0], nil
+}
+
+// FindSymbolsByName finds symbols by name (partial or exact match)
+func (p *PostgresStorage) FindSymbolsByName(ctx context.Context, projectID, name string, symbolTypes []treesitter.SymbolType, limit int) ([]CodeSymbol, error) {
+	args := pgArgs{projectID, name}
+	query := "SELECT " + pgCodeSymbolFields + " FROM code_symbols WHERE project_id = $1 AND strpos(name, $2) > 0"
+	if len(symbolTypes) > 0 {
+		query += " AND symbol_type = ANY(" + args.add(symbolTypeNames(symbolTypes)) + ")"
+	}
+	query += " LIMIT " + args.add(limit)
+
+	symbols, err := pgDecode[CodeSymbol](ctx, p, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find symbols: %w", err)
+	}
+	return symbols, nil
+}
+
+// FindSymbolsByFile retrieves all symbols in a file
+func (p *PostgresStorage) FindSymbolsByFile(ctx context.Context, projectID, filePath string) ([]CodeSymbol, error) {
+	symbols, err := pgDecode[CodeSymbol](ctx, p, "SELECT "+pgCodeSymbolFields+" FROM code_symbols WHERE project_id = $1 AND file_path = $2 ORDER BY start_line ASC", projectID, filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find symbols: %w", err)
+	}
+	return symbols, nil
+}
+
+// pgCodeSymbolOutlineFields are the symbol fields without source code and
+// embedding
+const pgCodeSymbolOutlineFields = `id, project_id, file_path, language, symbol_type, name, name_path,
+	start_line, end_line, start_byte, end_byte, revision, signature, doc_string, parent_id, metadata, created_at, updated_at`
+
+// ListCodeSymbols retrieves all symbols of a project, without their source
+// code and embeddings, by file and line
+func (p *PostgresStorage) ListCodeSymbols(ctx context.Context, projectID string) ([]CodeSymbol, error) {
+	symbols, err := pgDecode[CodeSymbol](ctx, p, "SELECT "+pgCodeSymbolOutlineFields+" FROM code_symbols WHERE project_id = $1 ORDER BY file_path ASC, start_line ASC", projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list symbols: %w", err)
+	}
+	return symbols, nil
+}
+
+// ListCodeSymbolsByName retrieves the symbols of a project selected by
+// filter, without their source code and embeddings, shortest names first
+func (p *PostgresStorage) ListCodeSymbolsByName(ctx context.Context, projectID string, filter CodeSymbolNameFilter) ([]CodeSymbol, error) {
+	args := pgArgs{projectID}
+	var names []string
+	if filter.Contains != "" {
+		if filter.Exact {
+			names = append(names, "lower(name) = "+args.add(filter.Contains))
+		} else {
+			names = append(names, "strpos(lower(name), "+args.add(filter.Contains)+") > 0")
+		}
+	}
+	if len(filter.AllOf) > 0 {
+		all := make([]string, len(filter.AllOf))
+		for i, text := range filter.AllOf {
+			all[i] = "strpos(lower(name), " + args.add(text) + ") > 0"
+		}
+		names = append(names, "("+strings.Join(all, " AND ")+")")
+	}
+	for _, text := range filter.AnyOf {
+		names = append(names, "strpos(lower(name), "+args.add(text)+") > 0")
+	}
+
+	query := "SELECT " + pgCodeSymbolOutlineFields + " FROM code_symbols WHERE project_id = $1"
+	if len(names) > 0 {
+		query += " AND (" + strings.Join(names, " OR ") + ")"
+	}
+	switch {
+	case filter.Path == "":
+	case strings.HasSuffix(filter.Path, "/"):
+		query += " AND strpos(file_path, " + args.add(filter.Path) + ") > 0"
+	default:
+		query += " AND file_path = " + args.add(filter.Path)
+	}
+	if len(filter.Types) > 0 {
+		query += " AND symbol_type = ANY(" + args.add(filter.Types) + ")"
+	}
+	if len(filter.ExcludeTypes) > 0 {
+		query += " AND NOT (symbol_type = ANY(" + args.add(filter.ExcludeTypes) + "))"
+	}
+	query += " ORDER BY length(name) ASC, file_path ASC, start_line ASC"
+	if filter.Limit > 0 {
+		query += " LIMIT " + args.add(filter.Limit)
+	}
+
+	symbols, err := pgDecode[CodeSymbol](ctx, p, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list symbols by name: %w", err)
+	}
+	return symbols, nil
+}
+
+// ListUnembeddedCodeSymbols retrieves the symbols of a project that have no
+// embedding, without their source code, by file and line
+func (p *PostgresStorage) ListUnembeddedCodeSymbols(ctx context.Context, projectID string) ([]CodeSymbol, error) {
+	symbols, err := pgDecode[CodeSymbol](ctx, p, "SELECT "+pgCodeSymbolOutlineFields+" FROM code_symbols WHERE project_id = $1 AND embedding IS NULL ORDER BY file_path ASC, start_line ASC", projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list symbols without embeddings: %w", err)
+	}
+	return symbols, nil
+}
+
+// FindChildSymbols retrieves child symbols of a parent
+func (p *PostgresStorage) FindChildSymbols(ctx context.Context, projectID, parentID string) ([]CodeSymbol, error) {
+	symbols, err := pgDecode[CodeSymbol](ctx, p, "SELECT "+pgCodeSymbolFields+" FROM code_symbols WHERE project_id = $1 AND parent_id = $2 ORDER BY start_line ASC", projectID, parentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find child symbols: %w", err)
+	}
+	return symbols, nil
+}
+
+// SearchSymbolsBySimilarity performs semantic search on code symbols
+func (p *PostgresStorage) SearchSymbolsBySimilarity(ctx context.Context, projectID string, queryEmbedding []float32, symbolTypes []treesitter.SymbolType, limit int) ([]CodeSymbolSearchResult, error) {
+	args := pgArgs{projectID}
+	q := args.add(vectorParam(queryEmbedding))
+	query := fmt.Sprintf("SELECT %s, 1 - (embedding <=> %s::vector) AS similarity FROM code_symbols WHERE project_id = $1 AND embedding IS NOT NULL", pgCodeSymbolFields, q)
+	if len(symbolTypes) > 0 {
+		query += " AND symbol_type = ANY(" + args.add(symbolTypeNames(symbolTypes)) + ")"
+	}
+	query += p.embeddingModelClause(ctx, "code_symbols", &args)
+	query += fmt.Sprintf(" ORDER BY embedding <=> %s::vector LIMIT %s", q, args.add(limit))
+
+	type symbolWithSimilarity struct {
+		CodeSymbol
+		Similarity float64 `json:"similarity"`
+	}
+	symbols, err := pgDecode[symbolWithSimilarity](ctx, p, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search symbols: %w", err)
+	}
+
+	results := make([]CodeSymbolSearchResult, len(symbols))
+	for i, s := range symbols {
+		sym := s.CodeSymbol
+		results[i] = CodeSymbolSearchResult{Symbol: &sym, Similarity: s.Similarity}
+	}
+	return results, nil
+}
+
+// pgSymbolDocText is the text of a code symbol that SearchSymbolDocs searches
+const pgSymbolDocText = "to_tsvector('simple', coalesce(doc_string, '') || ' ' || coalesce(signature, ''))"
+
+// SearchSymbolDocs performs full-text search on the doc strings and
+// signatures of code symbols
+func (p *PostgresStorage) SearchSymbolDocs(ctx context.Context, projectID, query string, symbolTypes []treesitter.SymbolType, limit int) ([]CodeSymbolTextResult, error) {
+	args := pgArgs{projectID}
+	q := args.add(query)
+	sql := fmt.Sprintf("SELECT %s, ts_rank(%s, plainto_tsquery('simple', %s), 32)::float8 AS score FROM code_symbols WHERE project_id = $1 AND %s @@ plainto_tsquery('simple', %s)",
+		pgCodeSymbolFields, pgSymbolDocText, q, pgSymbolDocText, q)
+	if len(symbolTypes) > 0 {
+		sql += " AND symbol_type = ANY(" + args.add(symbolTypeNames(symbolTypes)) + ")"
+	}
+	sql += " ORDER BY score DESC LIMIT " + args.add(limit)
+
+	type symbolWithScore struct {
+		CodeSymbol
+		Score float64 `json:"score"`
+	}
+	symbols, err := pgDecode[symbolWithScore](ctx, p, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search symbol docs: %w", err)
+	}
+
+	results := make([]CodeSymbolTextResult, len(symbols))
+	for i, s := range symbols {
+		sym := s.CodeSymbol
+		results[i] = CodeSymbolTextResult{Symbol: &sym, Score: s.Score}
+	}
+	return results, nil
+}
+
+// DeleteCodeSymbols deletes the symbols of a file with the given name paths
+func (p *PostgresStorage) DeleteCodeSymbols(ctx context.Context, projectID, filePath string, namePaths []string) error {
+	if len(namePaths) == 0 {
+		return nil
+	}
+	_, err := p.exec(ctx, "DELETE FROM code_symbols WHERE project_id = $1 AND file_path = $2 AND name_path = ANY($3)", projectID, filePath, namePaths)
+	return err
+}
+
+// DeleteSymbolsByFile deletes all symbols in a file
+func (p *PostgresStorage) DeleteSymbolsByFile(ctx context.Context, projectID, filePath string) error {
+	_, err := p.exec(ctx, "DELETE FROM code_symbols WHERE project_id = $1 AND file_path = $2", projectID, filePath)
+	return err
+}
+
+func symbolTypeNames(symbolTypes []treesitter.SymbolType) []string {
+	names := make([]string, len(symbolTypes))
+	for i, t := range symbolTypes {
+		names[i] = string(t)
+	}
+	return names
+}
+
+// ===== CHUNK OPERATIONS =====
+
+const pgCodeChunkFields = `id, symbol_id, project_id, file_path, chunk_index, chunk_count, content,
+	start_offset, end_offset, embedding::text AS embedding, symbol_name, symbol_type, language, created_at`
+
+// SaveCodeChunk saves or updates a single code chunk
+func (p *PostgresStorage) SaveCodeChunk(ctx context.Context, chunk *CodeChunk) error {
+	if err := p.checkEmbedding("code_chunks", chunk.Embedding); err != nil {
+		return err
+	}
+	query := `
+		INSERT INTO code_chunks (symbol_id, project_id, file_path, chunk_index, chunk_count, content,
+			start_offset, end_offset, embedding, symbol_name, symbol_type, language, embedding_model, embedding_dim)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9::vector, $10, $11, $12, $13, $14)
+		ON CONFLICT (symbol_id, chunk_index) DO UPDATE SET
+			project_id = EXCLUDED.project_id,
+			file_path = EXCLUDED.file_path,
+			chunk_count = EXCLUDED.chunk_count,
+			content = EXCLUDED.content,
+			start_offset = EXCLUDED.start_offset,
+			end_offset = EXCLUDED.end_offset,
+			embedding = EXCLUDED.embedding,
+			embedding_model = EXCLUDED.embedding_model,
+			embedding_dim = EXCLUDED.embedding_dim,
+			symbol_name = EXCLUDED.symbol_name,
+			symbol_type = EXCLUDED.symbol_type,
+			language = EXCLUDED.language
+	`
+	_, err := p.exec(ctx, query, chunk.SymbolID, chunk.ProjectID, chunk.FilePath, chunk.ChunkIndex, chunk.ChunkCount, chunk.Content,
+		chunk.StartOffset, chunk.EndOffset, vectorParam(chunk.Embedding), chunk.SymbolName, chunk.SymbolType, chunk.Language,
+		p.embeddingModelOf(ctx, "code_chunks", chunk.Embedding), len(chunk.Embedding))
+	if err != nil {
+		return fmt.Errorf("failed to save chunk: %w", err)
+	}
+	return nil
+}
+
+// SaveCodeChunks saves multiple code chunks in a batch
+func (p *PostgresStorage) SaveCodeChunks(ctx context.Context, chunks []*CodeChunk) error {
+	return p.withTx(ctx, func(ctx context.Context) error {
+		for _, chunk := range chunks {
+			if err := p.SaveCodeChunk(ctx, chunk); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// DeleteChunksBySymbol deletes all chunks for a symbol
+func (p *PostgresStorage) DeleteChunksBySymbol(ctx context.Context, symbolID string) error {
+	_, err := p.exec(ctx, "DELETE FROM code_chunks WHERE symbol_id = $1", symbolID)
+	return err
+}
+
+// DeleteChunksByFile deletes all chunks for a file
+func (p *PostgresStorage) DeleteChunksByFile(ctx context.Context, projectID, filePath string) error {
+	_, err := p.exec(ctx, "DELETE FROM code_chunks WHERE project_id = $1 AND file_path = $2", projectID, filePath)
+	return err
+}
+
+// CountCodeChunksBySymbol returns the number of chunks of each chunked symbol
+// of a project, by symbol ID
+func (p *PostgresStorage) CountCodeChunksBySymbol(ctx context.Context, projectID string) (map[string]int, error) {
+	rows, err := p.rows(ctx, "SELECT symbol_id, count(*) AS count FROM code_chunks WHERE project_id = $1 GROUP BY symbol_id", projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count chunks: %w", err)
+	}
+
+	counts := make(map[string]int, len(rows))
+	for _, row := range rows {
+		if symbolID, ok := row["symbol_id"].(string); ok {
+			counts[symbolID] = convertToInt(row["count"])
+		}
+	}
+	return counts, nil
+}
+
+// GetChunksBySymbol retrieves all chunks for a symbol
+func (p *PostgresStorage) GetChunksBySymbol(ctx context.Context, symbolID string) ([]CodeChunk, error) {
+	chunks, err := pgDecode[CodeChunk](ctx, p, "SELECT "+pgCodeChunkFields+" FROM code_chunks WHERE symbol_id = $1 ORDER BY chunk_index ASC", symbolID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chunks: %w", err)
+	}
+	return chunks, nil
+}
+
+// SearchChunksBySimilarity performs semantic search on code chunks
+func (p *PostgresStorage) SearchChunksBySimilarity(ctx context.Context, projectID string, queryEmbedding []float32, limit int) ([]CodeChunkSearchResult, error) {
+	args := pgArgs{projectID, vectorParam(queryEmbedding), limit}
+	query := "SELECT " + pgCodeChunkFields + `, 1 - (embedding <=> $2::vector) AS similarity
+		FROM code_chunks
+		WHERE project_id = $1 AND embedding IS NOT NULL` + p.embeddingModelClause(ctx, "code_chunks", &args) + `
+		ORDER BY embedding <=> $2::vector
+		LIMIT $3`
+
+	type chunkWithSimilarity struct {
+		CodeChunk
+		Similarity float64 `json:"similarity"`
+	}
+	chunks, err := pgDecode[chunkWithSimilarity](ctx, p, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search chunks: %w", err)
+	}
+
+	results := make([]CodeChunkSearchResult, len(chunks))
+	for i, c := range chunks {
+		chunk := c.CodeChunk
+		results[i] = CodeChunkSearchResult{Chunk: &chunk, Similarity: c.Similarity}
+	}
+	return results, nil
+}
+
+// ===== DEPENDENCY AND CALL OPERATIONS =====
+
+// SaveCodeDependencies replaces the stored imports of a file
+func (p *PostgresStorage) SaveCodeDependencies(ctx context.Context, projectID, filePath string, deps []CodeDependency) error {
+	return p.withTx(ctx, func(ctx context.Context) error {
+		if _, err := p.exec(ctx, "DELETE FROM code_dependencies WHERE project_id = $1 AND file_path = $2", projectID, filePath); err != nil {
+			return fmt.Errorf("failed to delete old dependencies: %w", err)
+		}
+		for _, dep := range deps {
+			query := "INSERT INTO code_dependencies (project_id, file_path, module, target_path, line, language) VALUES ($1, $2, $3, $4, $5, $6)"
+			if _, err := p.exec(ctx, query, projectID, filePath, dep.Module, dep.TargetPath, dep.Line, string(dep.Language)); err != nil {
+				return fmt.Errorf("failed to save dependency %s: %w", dep.Module, err)
+			}
+		}
+		return nil
+	})
+}
+
+// GetCodeDependencies returns the imports of a file
+func (p *PostgresStorage) GetCodeDependencies(ctx context.Context, projectID, filePath string) ([]CodeDependency, error) {
+	deps, err := pgDecode[CodeDependency](ctx, p, "SELECT * FROM code_dependencies WHERE project_id = $1 AND file_path = $2 ORDER BY line ASC", projectID, filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dependencies: %w", err)
+	}
+	return deps, nil
+}
+
+// GetCodeDependents returns the imports that resolve to one of targets, by
+// project file path or module name
+func (p *PostgresStorage) GetCodeDependents(ctx context.Context, projectID string, targets []string) ([]CodeDependency, error) {
+	if len(targets) == 0 {
+		return nil, nil
+	}
+	query := `
+		SELECT * FROM code_dependencies
+		WHERE project_id = $1 AND (target_path = ANY($2) OR module = ANY($2))
+		ORDER BY file_path ASC, line ASC
+	`
+	deps, err := pgDecode[CodeDependency](ctx, p, query, projectID, targets)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dependents: %w", err)
+	}
+	return deps, nil
+}
+
+// ListCodeDependencies returns every import in a project
+func (p *PostgresStorage) ListCodeDependencies(ctx context.Context, projectID string) ([]CodeDependency, error) {
+	deps, err := pgDecode[CodeDependency](ctx, p, "SELECT * FROM code_dependencies WHERE project_id = $1 ORDER BY file_path ASC, line ASC", projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list dependencies: %w", err)
+	}
+	return deps, nil
+}
+
+// SaveCodeCalls replaces the stored call edges of a file
+func (p *PostgresStorage) SaveCodeCalls(ctx context.Context, projectID, filePath string, calls []CodeCall) error {
+	return p.withTx(ctx, func(ctx context.Context) error {
+		if _, err := p.exec(ctx, "DELETE FROM code_calls WHERE project_id = $1 AND file_path = $2", projectID, filePath); err != nil {
+			return fmt.Errorf("failed to delete old calls: %w", err)
+		}
+		for _, call := range calls {
+			query := `
+				INSERT INTO code_calls (project_id, file_path, caller_name_path, caller_name, callee_name, callee_expr, line, language)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			`
+			if _, err := p.exec(ctx, query, projectID, filePath, call.CallerNamePath, call.CallerName, call.CalleeName,
+				call.CalleeExpr, call.Line, string(call.Language)); err != nil {
+				return fmt.Errorf("failed to save call to %s: %w", call.CalleeName, err)
+			}
+		}
+		return nil
+	})
+}
+
+// FindCodeCallers returns the calls made to calleeName
+func (p *PostgresStorage) FindCodeCallers(ctx context.Context, projectID, calleeName string, limit int) ([]CodeCall, error) {
+	query := "SELECT * FROM code_calls WHERE project_id = $1 AND callee_name = $2 ORDER BY file_path ASC, line ASC LIMIT $3"
+	calls, err := pgDecode[CodeCall](ctx, p, query, projectID, calleeName, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find callers: %w", err)
+	}
+	return calls, nil
+}
+
+// FindCodeCallees returns the calls made by caller, optionally only in filePath
+func (p *PostgresStorage) FindCodeCallees(ctx context.Context, projectID, caller, filePath string, limit int) ([]CodeCall, error) {
+	args := pgArgs{projectID, caller}
+	query := "SELECT * FROM code_calls WHERE project_id = $1 AND (caller_name_path = $2 OR caller_name = $2)"
+	if filePath != "" {
+		query += " AND file_path = " + args.add(filePath)
+	}
+	query += " ORDER BY file_path ASC, line ASC LIMIT " + args.add(limit)
+
+	calls, err := pgDecode[CodeCall](ctx, p, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find callees: %w", err)
+	}
+	return calls, nil
+}
+
+// ===== INDEXING JOB OPERATIONS =====
+
+// CreateIndexingJob creates a new indexing job and returns its ID
+func (p *PostgresStorage) CreateIndexingJob(ctx context.Context, job *treesitter.IndexingJob) (string, error) {
+	query := `
+		INSERT INTO code_indexing_jobs (project_id, project_path, status, progress, files_total, files_indexed)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id
+	`
+	row, err := p.row(ctx, query, job.ProjectID, job.ProjectPath, string(job.Status), job.Progress, job.FilesTotal, job.FilesIndexed)
+	if err != nil {
+		return "", fmt.Errorf("failed to create job: %w", err)
+	}
+	if row == nil {
+		return "", fmt.Errorf("failed to get created job ID")
+	}
+	return getString(row, "id"), nil
+}
+
+// UpdateIndexingJob updates the progress of an indexing job
+func (p *PostgresStorage) UpdateIndexingJob(ctx context.Context, jobID string, status treesitter.IndexingStatus, progress float64, filesIndexed int, err *string) error {
+	query := "UPDATE code_indexing_jobs SET status = $2, progress = $3, files_indexed = $4, error = $5"
+	if status == treesitter.IndexingStatusCompleted || status == treesitter.IndexingStatusFailed {
+		query += ", completed_at = now()"
+	}
+	query += " WHERE id = $1"
+
+	_, queryErr := p.exec(ctx, query, jobID, string(status), progress, filesIndexed, err)
+	return queryErr
+}
+
+// GetIndexingJob retrieves an indexing job by ID
+func (p *PostgresStorage) GetIndexingJob(ctx context.Context, jobID string) (*CodeIndexingJob, error) {
+	jobs, err := pgDecode[CodeIndexingJob](ctx, p, "SELECT * FROM code_indexing_jobs WHERE id = $1", jobID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get job: %w", err)
+	}
+	if len(jobs) == 0 {
+		return nil, nil
+	}
+	return &jobs[0], nil
+}
+
+// ListActiveIndexingJobs lists pending and in-progress indexing jobs
+func (p *PostgresStorage) ListActiveIndexingJobs(ctx context.Context) ([]CodeIndexingJob, error) {
+	jobs, err := pgDecode[CodeIndexingJob](ctx, p, "SELECT * FROM code_indexing_jobs WHERE status IN ('pending', 'in_progress') ORDER BY started_at DESC")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list jobs: %w", err)
+	}
+	return jobs, nil
+}
+
+// GetCodeProjectStats returns file and symbol counts for a project
+func (p *PostgresStorage) GetCodeProjectStats(ctx context.Context, projectID string) (map[string]interface{}, error) {
+	stats := make(map[string]interface{})
+
+	if count, err := p.count(ctx, "SELECT count(*) AS count FROM code_files WHERE project_id = $1", projectID); err == nil {
+		stats["files_count"] = count
+	}
+	if count, err := p.count(ctx, "SELECT count(*) AS count FROM code_symbols WHERE project_id = $1", projectID); err == nil {
+		stats["symbols_count"] = count
+	}
+	if rows, err := p.rows(ctx, "SELECT symbol_type, count(*) AS count FROM code_symbols WHERE project_id = $1 GROUP BY symbol_type", projectID); err == nil && len(rows) > 0 {
+		stats["symbols_by_type"] = rows
+	}
+	if rows, err := p.rows(ctx, "SELECT language, count(*) AS count FROM code_files WHERE project_id = $1 GROUP BY language", projectID); err == nil && len(rows) > 0 {
+		stats["files_by_language"] = rows
+	}
+
+	slog.Debug("Project stats retrieved", "project_id", projectID, "stats", stats)
+	return stats, nil
+}
+
+// pgDecode runs a query and decodes its rows into typed values.
+func pgDecode[T any](ctx context.Context, p *PostgresStorage, sql string, args ...interface{}) ([]T, error) {
+	rows, err := p.rows(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	return decodeRows[T](rows)
+}
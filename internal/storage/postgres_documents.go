@@ -0,0 +1,387 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+// pgDocumentWhere selects every chunk of a document by its path.
+const pgDocumentWhere = "(source_file = $1 OR file_path = $1)"
+
+// SaveDocument saves a knowledge base document
+func (p *PostgresStorage) SaveDocument(ctx context.Context, filePath, content string, embedding []float32, metadata map[string]interface{}) error {
+	if metadata == nil {
+		metadata = map[string]interface{}{}
+	}
+	metadata = withProvenance(ctx, metadata)
+	if err := p.checkEmbedding("knowledge_base", embedding); err != nil {
+		return err
+	}
+	if err := p.archiveDocument(ctx, filePath, content); err != nil {
+		slog.Warn("failed to archive previous document version", "file_path", filePath, "error", err)
+	}
+
+	query := `
+		INSERT INTO knowledge_base (file_path, content, embedding, metadata, embedding_model, embedding_dim) VALUES ($1, $2, $3::vector, $4::jsonb, $5, $6)
+		ON CONFLICT (file_path) DO UPDATE SET
+			content = EXCLUDED.content,
+			embedding = EXCLUDED.embedding,
+			metadata = EXCLUDED.metadata,
+			embedding_model = EXCLUDED.embedding_model,
+			embedding_dim = EXCLUDED.embedding_dim,
+			updated_at = now()
+	`
+	if _, err := p.exec(ctx, query, filePath, content, vectorParam(embedding), jsonParam(metadata), p.embeddingModelOf(ctx, "knowledge_base", embedding), len(embedding)); err != nil {
+		return fmt.Errorf("failed to save document: %w", err)
+	}
+	return nil
+}
+
+// SaveDocumentChunks saves a document as individual chunks with embeddings
+func (p *PostgresStorage) SaveDocumentChunks(ctx context.Context, filePath string, chunks []string, embeddings [][]float32, metadata map[string]interface{}) error {
+	if len(chunks) == 0 {
+		return fmt.Errorf("no chunks provided")
+	}
+	if len(chunks) != len(embeddings) {
+		return fmt.Errorf("chunks and embeddings length mismatch: %d chunks vs %d embeddings", len(chunks), len(embeddings))
+	}
+	if metadata == nil {
+		metadata = map[string]interface{}{}
+	}
+	metadata = withProvenance(ctx, metadata)
+	for i, embedding := range embeddings {
+		if err := p.checkEmbedding("knowledge_base", embedding); err != nil {
+			return fmt.Errorf("chunk %d: %w", i, err)
+		}
+	}
+
+	// Keep the current revision in kb_document_versions before replacing it
+	if err := p.archiveDocument(ctx, filePath, MergeChunkContents(chunks)); err != nil {
+		slog.Warn("failed to archive previous document version", "file_path", filePath, "error", err)
+	}
+
+	return p.withTx(ctx, func(ctx context.Context) error {
+		if _, err := p.exec(ctx, "DELETE FROM knowledge_base WHERE "+pgDocumentWhere, filePath); err != nil {
+			return fmt.Errorf("failed to delete existing chunks: %w", err)
+		}
+
+		query := `
+			INSERT INTO knowledge_base (file_path, content, embedding, metadata, chunk_index, chunk_count, source_file, embedding_model, embedding_dim)
+			VALUES ($1, $2, $3::vector, $4::jsonb, $5, $6, $7, $8, $9)
+		`
+		for i, chunk := range chunks {
+			chunkMetadata := make(map[string]interface{}, len(metadata)+2)
+			for k, v := range metadata {
+				chunkMetadata[k] = v
+			}
+			chunkMetadata["chunk_index"] = i
+			chunkMetadata["chunk_count"] = len(chunks)
+
+			chunkFilePath := fmt.Sprintf("%s#chunk%d", filePath, i)
+			if _, err := p.exec(ctx, query, chunkFilePath, chunk, vectorParam(embeddings[i]), jsonParam(chunkMetadata), i, len(chunks), filePath,
+				p.embeddingModelOf(ctx, "knowledge_base", embeddings[i]), len(embeddings[i])); err != nil {
+				return fmt.Errorf("failed to create chunk %d: %w", i, err)
+			}
+		}
+		return nil
+	})
+}
+
+// SearchDocuments performs similarity search on knowledge base documents
+func (p *PostgresStorage) SearchDocuments(ctx context.Context, queryEmbedding []float32, limit int) ([]DocumentResult, error) {
+	return p.SearchDocumentsWithOptions(ctx, queryEmbedding, VectorSearchOptions{Limit: limit})
+}
+
+// SearchDocumentsWithOptions performs similarity search on knowledge base documents
+// with HNSW tuning, a similarity threshold, root and frontmatter filters
+func (p *PostgresStorage) SearchDocumentsWithOptions(ctx context.Context, queryEmbedding []float32, opts VectorSearchOptions) ([]DocumentResult, error) {
+	args := pgArgs{}
+	q := args.add(vectorParam(queryEmbedding))
+	query := fmt.Sprintf(`
+		SELECT id, file_path, content, metadata, created_at, updated_at,
+		       1 - (embedding <=> %[1]s::vector) AS similarity
+		FROM knowledge_base
+		WHERE embedding IS NOT NULL%[2]s%[3]s
+		ORDER BY embedding <=> %[1]s::vector
+	`, q, pgDocumentFilters(opts, q, &args), p.embeddingModelClause(ctx, "knowledge_base", &args))
+
+	rows, err := p.searchRows(ctx, opts, query, args)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search documents: %w", err)
+	}
+
+	results := make([]DocumentResult, 0, len(rows))
+	for _, row := range rows {
+		similarity := getFloat64(row, "similarity")
+		results = append(results, DocumentResult{
+			Document:   pgDocument(row),
+			Similarity: similarity,
+			Score:      similarity,
+		})
+	}
+	return results, nil
+}
+
+// pgDocumentFilters returns the WHERE fragment enforcing the similarity
+// threshold, knowledge base roots and frontmatter filters of opts.
+func pgDocumentFilters(opts VectorSearchOptions, q string, args *pgArgs) string {
+	var b strings.Builder
+	b.WriteString(pgMinSimilarity(opts, q, args))
+	if len(opts.KBRoots) > 0 {
+		fmt.Fprintf(&b, " AND coalesce(metadata->>'kb_root', %s) = ANY(%s)", args.add(DefaultKBRoot), args.add(opts.KBRoots))
+	}
+
+	f := opts.Frontmatter
+	if len(f.Tags) > 0 {
+		fmt.Fprintf(&b, " AND coalesce(metadata->'tags', '[]') @> %s::jsonb", args.add(jsonParam(f.Tags)))
+	}
+	if f.Title != "" {
+		fmt.Fprintf(&b, " AND strpos(lower(coalesce(metadata->>'title', '')), %s) > 0", args.add(strings.ToLower(f.Title)))
+	}
+	if f.Alias != "" {
+		fmt.Fprintf(&b, " AND coalesce(metadata->'aliases', '[]') @> %s::jsonb", args.add(jsonParam([]string{f.Alias})))
+	}
+	if f.DateFrom != "" {
+		fmt.Fprintf(&b, " AND coalesce(metadata->>'date', '') >= %s", args.add(f.DateFrom))
+	}
+	if f.DateTo != "" {
+		fmt.Fprintf(&b, " AND metadata->>'date' IS NOT NULL AND metadata->>'date' <= %s", args.add(f.DateTo))
+	}
+	return b.String()
+}
+
+// DeleteDocument moves a knowledge base document and all its chunks to the trash
+func (p *PostgresStorage) DeleteDocument(ctx context.Context, filePath string) error {
+	chunks, err := p.documentChunks(ctx, filePath)
+	if err != nil {
+		return fmt.Errorf("failed to delete document: %w", err)
+	}
+	if len(chunks) == 0 {
+		return nil
+	}
+	return p.moveToTrash(ctx, TrashKindDocument, "", filePath, MergeChunkContents(chunks), pgDocumentWhere, filePath)
+}
+
+// MergeDocumentMetadata merges metadata into every chunk of an existing document
+func (p *PostgresStorage) MergeDocumentMetadata(ctx context.Context, filePath string, metadata map[string]interface{}) error {
+	query := "UPDATE knowledge_base SET metadata = metadata || $2::jsonb, updated_at = now() WHERE " + pgDocumentWhere
+	if _, err := p.exec(ctx, query, filePath, jsonParam(metadata)); err != nil {
+		return fmt.Errorf("failed to merge document metadata: %w", err)
+	}
+	return nil
+}
+
+// ListDocuments returns every knowledge base document with its metadata and
+// timestamps, taken from its first chunk. Content and embeddings are left out.
+func (p *PostgresStorage) ListDocuments(ctx context.Context) ([]Document, error) {
+	query := `
+		SELECT coalesce(source_file, file_path) AS doc_path, metadata, created_at, updated_at
+		FROM knowledge_base
+		WHERE coalesce(chunk_index, 0) = 0
+		ORDER BY doc_path ASC
+	`
+	rows, err := p.rows(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list documents: %w", err)
+	}
+
+	var documents []Document
+	for _, row := range rows {
+		documents = append(documents, Document{
+			FilePath:  getString(row, "doc_path"),
+			Metadata:  getMap(row, "metadata"),
+			CreatedAt: getTime(row, "created_at"),
+			UpdatedAt: getTime(row, "updated_at"),
+		})
+	}
+	return documents, nil
+}
+
+// GetDocument retrieves the first chunk of a knowledge base document by file path
+func (p *PostgresStorage) GetDocument(ctx context.Context, filePath string) (*Document, error) {
+	query := "SELECT id, file_path, content, embedding::text AS embedding, metadata, created_at, updated_at FROM knowledge_base WHERE " +
+		pgDocumentWhere + " ORDER BY chunk_index ASC NULLS FIRST LIMIT 1"
+	row, err := p.row(ctx, query, filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get document: %w", err)
+	}
+	if row == nil {
+		return nil, nil
+	}
+	return pgDocument(row), nil
+}
+
+// GetDocumentContent returns the full content of a knowledge base document,
+// rebuilt from its chunks. It returns an empty string when the document does
+// not exist.
+func (p *PostgresStorage) GetDocumentContent(ctx context.Context, filePath string) (string, error) {
+	chunks, err := p.documentChunks(ctx, filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to get document content: %w", err)
+	}
+	return MergeChunkContents(chunks), nil
+}
+
+// GetDocumentChunkContext returns, for each chunk index of the chunked
+// document filePath, that chunk merged with the radius chunks before and after
+// it. All the chunks are read with a single query.
+func (p *PostgresStorage) GetDocumentChunkContext(ctx context.Context, filePath string, indexes []int, radius int) (map[int]string, error) {
+	from, to := chunkWindow(indexes, radius)
+	rows, err := p.rows(ctx, "SELECT content, chunk_index FROM knowledge_base WHERE source_file = $1 AND chunk_index BETWEEN $2 AND $3", filePath, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get document chunks: %w", err)
+	}
+	chunks := make(map[int]string, len(rows))
+	for _, row := range rows {
+		chunks[convertToInt(row["chunk_index"])] = getString(row, "content")
+	}
+	return chunkContext(chunks, indexes, radius), nil
+}
+
+// documentChunks returns the content of the chunks of a document, in order.
+func (p *PostgresStorage) documentChunks(ctx context.Context, filePath string) ([]string, error) {
+	return p.textColumn(ctx, "SELECT content AS value FROM knowledge_base WHERE "+pgDocumentWhere+" ORDER BY chunk_index ASC NULLS FIRST", filePath)
+}
+
+func pgDocument(row map[string]interface{}) *Document {
+	embedding, _ := row["embedding"].([]float32)
+	return &Document{
+		ID:        getString(row, "id"),
+		FilePath:  getString(row, "file_path"),
+		Content:   getString(row, "content"),
+		Embedding: embedding,
+		Metadata:  getMap(row, "metadata"),
+		CreatedAt: getTime(row, "created_at"),
+		UpdatedAt: getTime(row, "updated_at"),
+	}
+}
+
+// archiveDocument stores the current content of filePath as a new row in
+// kb_document_versions before it is overwritten with newContent. Nothing is
+// archived when the document does not exist yet or its content is unchanged.
+func (p *PostgresStorage) archiveDocument(ctx context.Context, filePath, newContent string) error {
+	query := "SELECT content, embedding::text AS embedding, metadata, embedding_model, embedding_dim FROM knowledge_base WHERE " + pgDocumentWhere + " ORDER BY chunk_index ASC NULLS FIRST"
+	rows, err := p.rows(ctx, query, filePath)
+	if err != nil {
+		return fmt.Errorf("failed to read current document: %w", err)
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+
+	chunks := make([]string, 0, len(rows))
+	embeddings := make([][]float32, 0, len(rows))
+	for _, row := range rows {
+		chunks = append(chunks, getString(row, "content"))
+		if emb, ok := row["embedding"].([]float32); ok && len(emb) > 0 {
+			embeddings = append(embeddings, emb)
+		}
+	}
+
+	oldContent := MergeChunkContents(chunks)
+	if oldContent == strings.TrimSpace(newContent) {
+		return nil
+	}
+
+	metadata := getMap(rows[0], "metadata")
+	delete(metadata, "chunk_index")
+	delete(metadata, "chunk_count")
+
+	insertQuery := `
+		INSERT INTO kb_document_versions (file_path, version, content, diff, chunk_count, embedding, metadata, embedding_model, embedding_dim)
+		SELECT $1, coalesce(max(version), 0) + 1, $2, $3, $4, $5::vector, $6::jsonb, nullif($7, ''), nullif($8, 0)
+		FROM kb_document_versions WHERE file_path = $1
+	`
+	// The version keeps the model of the chunks it was averaged from
+	if _, err := p.exec(ctx, insertQuery, filePath, oldContent, documentDiff(oldContent, strings.TrimSpace(newContent)),
+		len(rows), vectorParam(averageEmbedding(embeddings)), jsonParam(metadata),
+		getString(rows[0], "embedding_model"), convertToInt(rows[0]["embedding_dim"])); err != nil {
+		return fmt.Errorf("failed to archive document version: %w", err)
+	}
+
+	slog.Debug("archived kb document version", "file_path", filePath)
+	return nil
+}
+
+const pgVersionFields = "id, file_path, version, content, diff, chunk_count, metadata, created_at"
+
+// GetDocumentHistory lists the archived versions of a document, newest first
+func (p *PostgresStorage) GetDocumentHistory(ctx context.Context, filePath string) ([]DocumentVersion, error) {
+	rows, err := p.rows(ctx, "SELECT "+pgVersionFields+" FROM kb_document_versions WHERE file_path = $1 ORDER BY version DESC", filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get document history: %w", err)
+	}
+	return pgDocumentVersions(rows), nil
+}
+
+// GetDocumentVersion retrieves a single archived version of a document
+func (p *PostgresStorage) GetDocumentVersion(ctx context.Context, filePath string, version int) (*DocumentVersion, error) {
+	rows, err := p.rows(ctx, "SELECT "+pgVersionFields+" FROM kb_document_versions WHERE file_path = $1 AND version = $2", filePath, version)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get document version: %w", err)
+	}
+	versions := pgDocumentVersions(rows)
+	if len(versions) == 0 {
+		return nil, nil
+	}
+	return &versions[0], nil
+}
+
+// SearchDocumentVersions performs similarity search over archived document versions
+func (p *PostgresStorage) SearchDocumentVersions(ctx context.Context, queryEmbedding []float32, opts VectorSearchOptions) ([]DocumentResult, error) {
+	args := pgArgs{}
+	q := args.add(vectorParam(queryEmbedding))
+	query := fmt.Sprintf(`
+		SELECT id, file_path, version, content, metadata, created_at,
+		       1 - (embedding <=> %[1]s::vector) AS similarity
+		FROM kb_document_versions
+		WHERE embedding IS NOT NULL%[2]s%[3]s
+		ORDER BY embedding <=> %[1]s::vector
+	`, q, pgDocumentFilters(opts, q, &args), p.embeddingModelClause(ctx, "kb_document_versions", &args))
+
+	rows, err := p.searchRows(ctx, opts, query, args)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search document versions: %w", err)
+	}
+
+	var results []DocumentResult
+	for _, row := range rows {
+		metadata := getMap(row, "metadata")
+		metadata["archived"] = true
+		metadata["version"] = convertToInt(row["version"])
+
+		similarity := getFloat64(row, "similarity")
+		results = append(results, DocumentResult{
+			Document: &Document{
+				ID:        getString(row, "id"),
+				FilePath:  getString(row, "file_path"),
+				Content:   getString(row, "content"),
+				Metadata:  metadata,
+				CreatedAt: getTime(row, "created_at"),
+				UpdatedAt: getTime(row, "created_at"),
+			},
+			Similarity: similarity,
+			Score:      similarity,
+		})
+	}
+	return results, nil
+}
+
+func pgDocumentVersions(rows []map[string]interface{}) []DocumentVersion {
+	var versions []DocumentVersion
+	for _, row := range rows {
+		versions = append(versions, DocumentVersion{
+			ID:         getString(row, "id"),
+			FilePath:   getString(row, "file_path"),
+			Version:    convertToInt(row["version"]),
+			Content:    getString(row, "content"),
+			Diff:       getString(row, "diff"),
+			ChunkCount: convertToInt(row["chunk_count"]),
+			Metadata:   getMap(row, "metadata"),
+			ArchivedAt: getTime(row, "created_at"),
+		})
+	}
+	return versions
+}
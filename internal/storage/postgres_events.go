@@ -0,0 +1,175 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// SaveEvent stores a new event with embedding for semantic search
+func (p *PostgresStorage) SaveEvent(ctx context.Context, userID, subject, content string, embedding []float32, metadata map[string]interface{}) (string, time.Time, error) {
+	if metadata == nil {
+		metadata = map[string]interface{}{}
+	}
+	metadata = withProvenance(ctx, metadata)
+	if err := p.checkEmbedding("events", embedding); err != nil {
+		return "", time.Time{}, err
+	}
+
+	query := `
+		INSERT INTO events (user_id, subject, content, embedding, metadata, embedding_model, embedding_dim)
+		VALUES ($1, $2, $3, $4::vector, $5::jsonb, $6, $7)
+		RETURNING id, created_at
+	`
+	row, err := p.row(ctx, query, userID, subject, content, vectorParam(embedding), jsonParam(metadata),
+		p.embeddingModelOf(ctx, "events", embedding), len(embedding))
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to save event: %w", err)
+	}
+	if row == nil {
+		return "", time.Time{}, fmt.Errorf("failed to save event: no result returned")
+	}
+
+	eventID := getString(row, "id")
+	slog.Debug("Event saved", "id", eventID, "subject", subject, "user_id", userID)
+	return eventID, getTime(row, "created_at"), nil
+}
+
+// SearchEvents performs hybrid search on events with temporal filtering
+func (p *PostgresStorage) SearchEvents(ctx context.Context, params EventSearchParams) ([]EventSearchResult, error) {
+	if params.UserID == "" {
+		return nil, fmt.Errorf("user_id is required")
+	}
+	if params.Limit <= 0 {
+		params.Limit = 50
+	}
+
+	// Calculate date filters from relative time offsets
+	var fromDate, toDate *time.Time
+	now := time.Now()
+	if params.LastHours != nil && *params.LastHours > 0 {
+		t := now.Add(-time.Duration(*params.LastHours) * time.Hour)
+		fromDate = &t
+	} else if params.LastDays != nil && *params.LastDays > 0 {
+		t := now.AddDate(0, 0, -*params.LastDays)
+		fromDate = &t
+	} else if params.LastMonths != nil && *params.LastMonths > 0 {
+		t := now.AddDate(0, -*params.LastMonths, 0)
+		fromDate = &t
+	}
+	if params.FromDate != nil {
+		fromDate = params.FromDate
+	}
+	if params.ToDate != nil {
+		toDate = params.ToDate
+	}
+
+	args := pgArgs{}
+	conditions := []string{"user_id = " + args.add(params.UserID)}
+	if params.Subject != "" {
+		conditions = append(conditions, "subject = "+args.add(params.Subject))
+	}
+	if fromDate != nil {
+		conditions = append(conditions, "created_at >= "+args.add(fromDate.UTC()))
+	}
+	if toDate != nil {
+		conditions = append(conditions, "created_at <= "+args.add(toDate.UTC()))
+	}
+	if params.Embedding != nil {
+		if clause := p.embeddingModelClause(ctx, "events", &args); clause != "" {
+			conditions = append(conditions, strings.TrimPrefix(clause, " AND "))
+		}
+	}
+
+	var relevance, order string
+	switch {
+	case params.Query != "" && params.Embedding != nil:
+		// Hybrid search: combine full-text rank and vector similarity
+		text := fmt.Sprintf("to_tsvector('simple', content) @@ plainto_tsquery('simple', %s)", args.add(params.Query))
+		rank := fmt.Sprintf("ts_rank(to_tsvector('simple', content), plainto_tsquery('simple', %s), 32)", args.add(params.Query))
+		cosine := fmt.Sprintf("coalesce(1 - (embedding <=> %s::vector), 0)", args.add(vectorParam(params.Embedding)))
+		conditions = append(conditions, fmt.Sprintf("(%s OR %s > 0.3)", text, cosine))
+		relevance = fmt.Sprintf("%s * 0.5 + %s * 0.5", rank, cosine)
+		order = "relevance DESC"
+	case params.Query != "":
+		q := args.add(params.Query)
+		conditions = append(conditions, fmt.Sprintf("to_tsvector('simple', content) @@ plainto_tsquery('simple', %s)", q))
+		relevance = fmt.Sprintf("ts_rank(to_tsvector('simple', content), plainto_tsquery('simple', %s), 32)", q)
+		order = "relevance DESC"
+	case params.Embedding != nil:
+		relevance = fmt.Sprintf("coalesce(1 - (embedding <=> %s::vector), 0)", args.add(vectorParam(params.Embedding)))
+		order = "relevance DESC"
+	default:
+		// No search query - just filter and order by recency
+		relevance = "1.0"
+		order = "created_at DESC"
+	}
+
+	query := fmt.Sprintf(`
+		SELECT id, user_id, subject, content, metadata, created_at, (%s)::float8 AS relevance
+		FROM events
+		WHERE %s
+		ORDER BY %s
+		LIMIT %s
+	`, relevance, strings.Join(conditions, " AND "), order, args.add(params.Limit))
+
+	rows, err := p.rows(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search events: %w", err)
+	}
+
+	results := make([]EventSearchResult, 0, len(rows))
+	for _, row := range rows {
+		results = append(results, EventSearchResult{
+			Event:     pgEvent(row),
+			Relevance: getFloat64(row, "relevance"),
+		})
+	}
+	return results, nil
+}
+
+// DeleteEvent deletes an event by ID
+func (p *PostgresStorage) DeleteEvent(ctx context.Context, eventID, userID string) error {
+	if _, err := p.exec(ctx, "DELETE FROM events WHERE id = $1 AND user_id = $2", eventID, userID); err != nil {
+		return fmt.Errorf("failed to delete event: %w", err)
+	}
+	return nil
+}
+
+// GetEventsBySubject retrieves all events for a subject (useful for conversation history)
+func (p *PostgresStorage) GetEventsBySubject(ctx context.Context, userID, subject string, limit int) ([]Event, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+
+	query := `
+		SELECT id, user_id, subject, content, metadata, created_at
+		FROM events
+		WHERE user_id = $1 AND subject = $2
+		ORDER BY created_at ASC
+		LIMIT $3
+	`
+	rows, err := p.rows(ctx, query, userID, subject, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get events by subject: %w", err)
+	}
+
+	events := make([]Event, 0, len(rows))
+	for _, row := range rows {
+		events = append(events, pgEvent(row))
+	}
+	return events, nil
+}
+
+func pgEvent(row map[string]interface{}) Event {
+	return Event{
+		ID:        getString(row, "id"),
+		UserID:    getString(row, "user_id"),
+		Subject:   getString(row, "subject"),
+		Content:   getString(row, "content"),
+		Metadata:  getMap(row, "metadata"),
+		CreatedAt: getTime(row, "created_at"),
+	}
+}
@@ -0,0 +1,736 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+)
+
+// CreateEntity creates a new entity in the graph. Its properties must match
+// the schema of its type, if it has one.
+func (p *PostgresStorage) CreateEntity(ctx context.Context, entityType, name string, properties map[string]interface{}) error {
+	if err := checkEntityProperties(ctx, p.GetEntityType, entityType, properties); err != nil {
+		return err
+	}
+	_, err := p.createEntity(ctx, entityType, name, properties)
+	return err
+}
+
+func (p *PostgresStorage) createEntity(ctx context.Context, entityType, name string, properties map[string]interface{}) (string, error) {
+	if properties == nil {
+		properties = map[string]interface{}{}
+	}
+	row, err := p.row(ctx, "INSERT INTO entities (entity_type, name, properties) VALUES ($1, $2, $3::jsonb) RETURNING id",
+		entityType, name, jsonParam(properties))
+	if err != nil || row == nil {
+		return "", fmt.Errorf("failed to create entity: %w", err)
+	}
+	return getString(row, "id"), nil
+}
+
+// resolveEntityID resolves an entity ID, name or alias to its ID
+func (p *PostgresStorage) resolveEntityID(ctx context.Context, entityNameOrID string) (string, error) {
+	row, err := p.row(ctx, "SELECT id FROM entities WHERE "+pgEntityMatch+" LIMIT 1", entityNameOrID)
+	if err != nil {
+		return "", fmt.Errorf("failed to query entity by name: %w", err)
+	}
+	if row == nil {
+		return "", fmt.Errorf("entity %w: %s", ErrNotFound, entityNameOrID)
+	}
+	return getString(row, "id"), nil
+}
+
+// CreateRelationship creates a relationship between two entities, valid from
+// now unless ctx comes from WithValidity. The current relationship of the same
+// type between them, if any, is ended when the new one starts so that its
+// history is kept.
+func (p *PostgresStorage) CreateRelationship(ctx context.Context, fromEntity, toEntity, relationshipType string, properties map[string]interface{}) error {
+	_, err := p.createRelationship(ctx, fromEntity, toEntity, relationshipType, properties)
+	return err
+}
+
+func (p *PostgresStorage) createRelationship(ctx context.Context, fromEntity, toEntity, relationshipType string, properties map[string]interface{}) (string, error) {
+	fromEntityID, err := p.resolveEntityID(ctx, fromEntity)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve from entity '%s': %w", fromEntity, err)
+	}
+	toEntityID, err := p.resolveEntityID(ctx, toEntity)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve to entity '%s': %w", toEntity, err)
+	}
+	if properties == nil {
+		properties = map[string]interface{}{}
+	}
+
+	from, until, _ := validityFromContext(ctx)
+	var id string
+	err = p.withTx(ctx, func(ctx context.Context) error {
+		if _, err := p.exec(ctx, pgEndRelationships, fromEntityID, toEntityID, relationshipType, from); err != nil {
+			return fmt.Errorf("failed to end replaced relationship: %w", err)
+		}
+		query := "INSERT INTO relationships (from_entity, to_entity, relationship_type, properties, valid_from, valid_until) VALUES ($1, $2, $3, $4::jsonb, $5, $6) RETURNING id"
+		row, err := p.row(ctx, query, fromEntityID, toEntityID, relationshipType, jsonParam(properties), from, until)
+		if err != nil || row == nil {
+			return fmt.Errorf("failed to create relationship: %w", err)
+		}
+		id = getString(row, "id")
+		return nil
+	})
+	return id, err
+}
+
+// pgEndRelationships ends the relationships of type $3 from $1 to $2 that
+// hold at $4.
+const pgEndRelationships = `
+	UPDATE relationships SET valid_until = $4
+	WHERE from_entity = $1 AND to_entity = $2 AND relationship_type = $3
+		AND coalesce(valid_from, created_at) < $4 AND (valid_until IS NULL OR valid_until > $4)
+`
+
+// EndRelationships ends the relationships of a type between two entities
+// that still hold at the given time, keeping them as history, and returns
+// how many were ended.
+func (p *PostgresStorage) EndRelationships(ctx context.Context, fromEntity, toEntity, relationshipType string, at time.Time) (int, error) {
+	fromEntityID, err := p.resolveEntityID(ctx, fromEntity)
+	if err != nil {
+		return 0, fmt.Errorf("failed to resolve from entity '%s': %w", fromEntity, err)
+	}
+	toEntityID, err := p.resolveEntityID(ctx, toEntity)
+	if err != nil {
+		return 0, fmt.Errorf("failed to resolve to entity '%s': %w", toEntity, err)
+	}
+	n, err := p.exec(ctx, pgEndRelationships, fromEntityID, toEntityID, relationshipType, at.UTC())
+	if err != nil {
+		return 0, fmt.Errorf("failed to end relationships: %w", err)
+	}
+	return n, nil
+}
+
+// TraverseGraph returns the entities reachable from startEntity within depth
+// hops, following relationships in both directions, optionally only those of
+// relationshipType. Each entity is reported once, at its shortest distance,
+// with the relationship that reached it. Relationships that were ended are
+// not followed.
+func (p *PostgresStorage) TraverseGraph(ctx context.Context, startEntity, relationshipType string, depth int) ([]GraphResult, error) {
+	return p.TraverseGraphAsOf(ctx, startEntity, relationshipType, depth, time.Time{})
+}
+
+// TraverseGraphAsOf traverses the graph like TraverseGraph, following only
+// the relationships valid at a point in time. A zero time follows those that
+// were not ended.
+func (p *PostgresStorage) TraverseGraphAsOf(ctx context.Context, startEntity, relationshipType string, depth int, at time.Time) ([]GraphResult, error) {
+	startEntityID, err := p.resolveEntityID(ctx, startEntity)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve start entity '%s': %w", startEntity, err)
+	}
+	if depth <= 0 {
+		depth = 1
+	}
+
+	query := `
+		WITH RECURSIVE walk (entity_id, rel_id, path, hops) AS (
+			SELECT $1::text, NULL::text, ARRAY[$1::text], 0
+			UNION ALL
+			SELECT nb.entity_id, r.id, w.path || nb.entity_id, w.hops + 1
+			FROM walk w
+			JOIN relationships r ON r.from_entity = w.entity_id OR r.to_entity = w.entity_id
+			CROSS JOIN LATERAL (
+				SELECT CASE WHEN r.from_entity = w.entity_id THEN r.to_entity ELSE r.from_entity END AS entity_id
+			) nb
+			WHERE w.hops < $2 AND ($3 = '' OR r.relationship_type = $3) AND NOT nb.entity_id = ANY(w.path)
+				AND CASE WHEN $4::timestamptz IS NULL THEN r.valid_until IS NULL OR r.valid_until > now()
+					ELSE coalesce(r.valid_from, r.created_at) <= $4 AND (r.valid_until IS NULL OR r.valid_until > $4) END
+		)
+		SELECT DISTINCT ON (w.entity_id)
+			w.entity_id, w.path, w.hops AS depth,
+			e.entity_type, e.name, e.properties, e.created_at, e.updated_at,
+			r.id AS rel_id, r.from_entity, r.to_entity, r.relationship_type,
+			r.properties AS rel_properties, r.created_at AS rel_created_at,
+			r.valid_from AS rel_valid_from, r.valid_until AS rel_valid_until
+		FROM walk w
+		JOIN entities e ON e.id = w.entity_id
+		LEFT JOIN relationships r ON r.id = w.rel_id
+		WHERE w.hops > 0
+		ORDER BY w.entity_id, w.hops
+	`
+	var asOf interface{}
+	if !at.IsZero() {
+		asOf = at.UTC()
+	}
+	rows, err := p.rows(ctx, query, startEntityID, depth, relationshipType, asOf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to traverse graph: %w", err)
+	}
+
+	results := make([]GraphResult, 0, len(rows))
+	for _, row := range rows {
+		var path []string
+		if values, ok := row["path"].([]interface{}); ok {
+			for _, v := range values {
+				path = append(path, fmt.Sprint(v))
+			}
+		}
+		result := GraphResult{
+			Entity: &Entity{
+				ID:         getString(row, "entity_id"),
+				Type:       getString(row, "entity_type"),
+				Name:       getString(row, "name"),
+				Properties: getMap(row, "properties"),
+				CreatedAt:  getTime(row, "created_at"),
+				UpdatedAt:  getTime(row, "updated_at"),
+			},
+			Path:  path,
+			Depth: convertToInt(row["depth"]),
+		}
+		if relID := getString(row, "rel_id"); relID != "" {
+			result.Relationship = &Relationship{
+				ID:         relID,
+				From:       getString(row, "from_entity"),
+				To:         getString(row, "to_entity"),
+				Type:       getString(row, "relationship_type"),
+				Properties: getMap(row, "rel_properties"),
+				Timestamp:  getTime(row, "rel_created_at"),
+			}
+			if validFrom := getTime(row, "rel_valid_from"); !validFrom.IsZero() {
+				result.Relationship.ValidFrom = &validFrom
+			}
+			if validUntil := getTime(row, "rel_valid_until"); !validUntil.IsZero() {
+				result.Relationship.ValidUntil = &validUntil
+			}
+		}
+		results = append(results, result)
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Depth != results[j].Depth {
+			return results[i].Depth < results[j].Depth
+		}
+		return results[i].Entity.Name < results[j].Entity.Name
+	})
+	return results, nil
+}
+
+// ListRelationships returns a page of the relationships matching filter,
+// newest first, and how many match in all.
+func (p *PostgresStorage) ListRelationships(ctx context.Context, filter RelationshipFilter) ([]Relationship, int, error) {
+	var args pgArgs
+	where := "TRUE"
+	if filter.Entity != "" {
+		entityID, err := p.resolveEntityID(ctx, filter.Entity)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to resolve entity '%s': %w", filter.Entity, err)
+		}
+		ph := args.add(entityID)
+		switch filter.Direction {
+		case RelationshipsOutgoing:
+			where += " AND r.from_entity = " + ph
+		case RelationshipsIncoming:
+			where += " AND r.to_entity = " + ph
+		default:
+			where += fmt.Sprintf(" AND (r.from_entity = %[1]s OR r.to_entity = %[1]s)", ph)
+		}
+	}
+	if filter.Type != "" {
+		where += " AND r.relationship_type = " + args.add(filter.Type)
+	}
+	if !filter.IncludeEnded {
+		where += " AND (r.valid_until IS NULL OR r.valid_until > now())"
+	}
+
+	total, err := p.count(ctx, "SELECT count(*) AS count FROM relationships r WHERE "+where, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count relationships: %w", err)
+	}
+
+	query := `
+		SELECT r.id, r.from_entity, r.to_entity, r.relationship_type, r.properties, r.created_at,
+			r.valid_from, r.valid_until, f.name AS from_name, t.name AS to_name
+		FROM relationships r
+		LEFT JOIN entities f ON f.id = r.from_entity
+		LEFT JOIN entities t ON t.id = r.to_entity
+		WHERE ` + where + `
+		ORDER BY coalesce(r.valid_from, r.created_at) DESC, r.id`
+	if filter.Limit > 0 {
+		query += " LIMIT " + args.add(filter.Limit)
+	}
+	if filter.Offset > 0 {
+		query += " OFFSET " + args.add(filter.Offset)
+	}
+	rows, err := p.rows(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list relationships: %w", err)
+	}
+
+	relationships := make([]Relationship, 0, len(rows))
+	for _, row := range rows {
+		relationships = append(relationships, pgRelationship(row))
+	}
+	return relationships, total, nil
+}
+
+// pgRelationship converts a row of the relationships table, with the names
+// of its entities when the row has from_name and to_name.
+func pgRelationship(row map[string]interface{}) Relationship {
+	relationship := Relationship{
+		ID:         getString(row, "id"),
+		From:       getString(row, "from_entity"),
+		To:         getString(row, "to_entity"),
+		Type:       getString(row, "relationship_type"),
+		Properties: getMap(row, "properties"),
+		Timestamp:  getTime(row, "created_at"),
+		FromName:   getString(row, "from_name"),
+		ToName:     getString(row, "to_name"),
+	}
+	if validFrom := getTime(row, "valid_from"); !validFrom.IsZero() {
+		relationship.ValidFrom = &validFrom
+	}
+	if validUntil := getTime(row, "valid_until"); !validUntil.IsZero() {
+		relationship.ValidUntil = &validUntil
+	}
+	return relationship
+}
+
+// FindPaths returns up to limit paths of at most maxHops current
+// relationships, followed in either direction, connecting two entities,
+// shortest first.
+func (p *PostgresStorage) FindPaths(ctx context.Context, fromEntity, toEntity string, maxHops, limit int) ([]GraphPath, error) {
+	fromID, err := p.resolveEntityID(ctx, fromEntity)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve entity '%s': %w", fromEntity, err)
+	}
+	toID, err := p.resolveEntityID(ctx, toEntity)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve entity '%s': %w", toEntity, err)
+	}
+	return findPaths(ctx, pathGraph{
+		relationshipsOf: p.currentRelationshipsOf,
+		entities:        p.pathEntities,
+	}, fromID, toID, maxHops, limit)
+}
+
+// currentRelationshipsOf returns the current relationships from or to any
+// of the given entities.
+func (p *PostgresStorage) currentRelationshipsOf(ctx context.Context, entityIDs []string) ([]Relationship, error) {
+	query := `
+		SELECT id, from_entity, to_entity, relationship_type, properties, created_at, valid_from, valid_until
+		FROM relationships
+		WHERE (from_entity = ANY($1) OR to_entity = ANY($1)) AND (valid_until IS NULL OR valid_until > now())`
+	rows, err := p.rows(ctx, query, entityIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get relationships: %w", err)
+	}
+	relationships := make([]Relationship, 0, len(rows))
+	for _, row := range rows {
+		relationships = append(relationships, pgRelationship(row))
+	}
+	return relationships, nil
+}
+
+// pathEntities returns the given entities by ID.
+func (p *PostgresStorage) pathEntities(ctx context.Context, entityIDs []string) (map[string]PathEntity, error) {
+	rows, err := p.rows(ctx, "SELECT id, name, entity_type FROM entities WHERE id = ANY($1)", entityIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get entities: %w", err)
+	}
+	entities := make(map[string]PathEntity, len(rows))
+	for _, row := range rows {
+		id := getString(row, "id")
+		entities[id] = PathEntity{ID: id, Name: getString(row, "name"), Type: getString(row, "entity_type")}
+	}
+	return entities, nil
+}
+
+// GetGraphStats counts the entities and current relationships by type and
+// returns the top entities with the most relationships.
+func (p *PostgresStorage) GetGraphStats(ctx context.Context, top int) (*GraphStats, error) {
+	stats := &GraphStats{
+		EntitiesByType:      map[string]int{},
+		RelationshipsByType: map[string]int{},
+	}
+
+	rows, err := p.rows(ctx, "SELECT entity_type, count(*) AS count FROM entities GROUP BY entity_type")
+	if err != nil {
+		return nil, fmt.Errorf("failed to count entities: %w", err)
+	}
+	for _, row := range rows {
+		count := convertToInt(row["count"])
+		stats.EntitiesByType[getString(row, "entity_type")] = count
+		stats.EntityCount += count
+	}
+
+	rows, err = p.rows(ctx, "SELECT relationship_type, count(*) AS count FROM relationships WHERE valid_until IS NULL OR valid_until > now() GROUP BY relationship_type")
+	if err != nil {
+		return nil, fmt.Errorf("failed to count relationships: %w", err)
+	}
+	for _, row := range rows {
+		count := convertToInt(row["count"])
+		stats.RelationshipsByType[getString(row, "relationship_type")] = count
+		stats.RelationshipCount += count
+	}
+
+	query := `
+		SELECT e.id, e.name, e.entity_type, d.degree
+		FROM (
+			SELECT entity_id, count(*) AS degree
+			FROM (
+				SELECT from_entity AS entity_id FROM relationships WHERE valid_until IS NULL OR valid_until > now()
+				UNION ALL
+				SELECT to_entity FROM relationships WHERE valid_until IS NULL OR valid_until > now()
+			) ends
+			GROUP BY entity_id
+		) d
+		JOIN entities e ON e.id = d.entity_id
+		ORDER BY d.degree DESC, e.name
+		LIMIT $1`
+	var limit interface{}
+	if top >= 0 {
+		limit = top
+	}
+	rows, err = p.rows(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to rank entities by degree: %w", err)
+	}
+	degrees := make([]EntityDegree, 0, len(rows))
+	for _, row := range rows {
+		degrees = append(degrees, EntityDegree{
+			ID:     getString(row, "id"),
+			Name:   getString(row, "name"),
+			Type:   getString(row, "entity_type"),
+			Degree: convertToInt(row["degree"]),
+		})
+	}
+	stats.TopEntities = topEntityDegrees(degrees, top)
+	return stats, nil
+}
+
+// pgEntityMatch matches the entities whose ID, name or alias is $1, the ID
+// first, then the name, then the oldest.
+const pgEntityMatch = "(id = $1 OR name = $1 OR properties->'" + AliasesProperty + "' ? $1) ORDER BY (id = $1) DESC, (name = $1) DESC, created_at ASC"
+
+// GetEntity retrieves an entity by ID, name or alias, or nil when it does
+// not exist
+func (p *PostgresStorage) GetEntity(ctx context.Context, entityID string) (*Entity, error) {
+	query := "SELECT id, entity_type, name, properties, created_at, updated_at FROM entities WHERE " + pgEntityMatch + " LIMIT 1"
+	row, err := p.row(ctx, query, entityID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get entity: %w", err)
+	}
+	if row == nil {
+		return nil, nil
+	}
+	entity := pgEntity(row)
+	return &entity, nil
+}
+
+// pgEntity converts a row of the entities table.
+func pgEntity(row map[string]interface{}) Entity {
+	return Entity{
+		ID:         getString(row, "id"),
+		Type:       getString(row, "entity_type"),
+		Name:       getString(row, "name"),
+		Properties: getMap(row, "properties"),
+		CreatedAt:  getTime(row, "created_at"),
+		UpdatedAt:  getTime(row, "updated_at"),
+	}
+}
+
+// ListEntities returns up to limit entities, of entityType unless it is
+// empty, by name.
+func (p *PostgresStorage) ListEntities(ctx context.Context, entityType string, limit int) ([]Entity, error) {
+	query := "SELECT id, entity_type, name, properties, created_at, updated_at FROM entities WHERE ($1 = '' OR entity_type = $1) ORDER BY name, id LIMIT $2"
+	rows, err := p.rows(ctx, query, entityType, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list entities: %w", err)
+	}
+	entities := make([]Entity, 0, len(rows))
+	for _, row := range rows {
+		entities = append(entities, pgEntity(row))
+	}
+	return entities, nil
+}
+
+// MergeEntities merges duplicate entities into survivor: their relationships
+// and usage hits move to survivor, relationships between the merged entities
+// are dropped, their names become aliases of survivor, the properties
+// survivor lacks are copied, and the duplicates go to the trash.
+func (p *PostgresStorage) MergeEntities(ctx context.Context, survivor string, duplicates []string) (*EntityMerge, error) {
+	keep, merged, err := mergeTargets(ctx, p.GetEntity, survivor, duplicates)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, len(merged))
+	for i, e := range merged {
+		ids[i] = e.ID
+	}
+	properties, aliases := mergeEntityProperties(*keep, merged)
+	if err := checkEntityProperties(ctx, p.GetEntityType, keep.Type, properties); err != nil {
+		return nil, err
+	}
+	result := &EntityMerge{Merged: ids, Aliases: aliases}
+
+	err = p.withTx(ctx, func(ctx context.Context) error {
+		var err error
+		result.SelfRelationshipsDropped, err = p.exec(ctx, `
+			DELETE FROM relationships
+			WHERE (from_entity = ANY($1) OR from_entity = $2) AND (to_entity = ANY($1) OR to_entity = $2)`, ids, keep.ID)
+		if err != nil {
+			return fmt.Errorf("failed to drop relationships between merged entities: %w", err)
+		}
+		for _, column := range []string{"from_entity", "to_entity"} {
+			n, err := p.exec(ctx, fmt.Sprintf("UPDATE relationships SET %[1]s = $2 WHERE %[1]s = ANY($1)", column), ids, keep.ID)
+			if err != nil {
+				return fmt.Errorf("failed to rewire relationships: %w", err)
+			}
+			result.RelationshipsRewired += n
+		}
+		if result.HitsMoved, err = p.exec(ctx, "UPDATE memory_hits SET ref = $2 WHERE kind = $3 AND ref = ANY($1)", ids, keep.ID, TrashKindEntity); err != nil {
+			return fmt.Errorf("failed to move usage hits: %w", err)
+		}
+		if _, err := p.exec(ctx, "UPDATE entities SET properties = $2::jsonb, updated_at = now() WHERE id = $1", keep.ID, jsonParam(properties)); err != nil {
+			return fmt.Errorf("failed to update merged entity: %w", err)
+		}
+		for _, id := range ids {
+			if err := p.DeleteEntity(ctx, id); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if result.Survivor, err = p.GetEntity(ctx, keep.ID); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// UpdateEntity updates the properties of an entity, merging them into the
+// current ones unless replace is set, and returns the entity updated. The
+// result must match the schema of the entity type, if it has one.
+func (p *PostgresStorage) UpdateEntity(ctx context.Context, entity string, properties map[string]interface{}, replace bool) (*Entity, error) {
+	current, err := p.GetEntity(ctx, entity)
+	if err != nil {
+		return nil, err
+	}
+	if current == nil {
+		return nil, fmt.Errorf("entity %w: %s", ErrNotFound, entity)
+	}
+	updated := updatedEntityProperties(current.Properties, properties, replace)
+	if err := checkEntityProperties(ctx, p.GetEntityType, current.Type, updated); err != nil {
+		return nil, err
+	}
+	if _, err := p.exec(ctx, "UPDATE entities SET properties = $2::jsonb, updated_at = now() WHERE id = $1", current.ID, jsonParam(updated)); err != nil {
+		return nil, fmt.Errorf("failed to update entity: %w", err)
+	}
+	return p.GetEntity(ctx, current.ID)
+}
+
+// SaveEntityType stores the schema of an entity type, replacing the one it
+// had. Existing entities are not checked against it.
+func (p *PostgresStorage) SaveEntityType(ctx context.Context, entityType EntityType) error {
+	if err := CheckPropertySchema(entityType.Schema); err != nil {
+		return err
+	}
+	schema := entityType.Schema
+	if schema == nil {
+		schema = map[string]interface{}{}
+	}
+	query := `
+		INSERT INTO entity_types (name, description, properties_schema) VALUES ($1, $2, $3::jsonb)
+		ON CONFLICT (name) DO UPDATE SET description = EXCLUDED.description,
+			properties_schema = EXCLUDED.properties_schema, updated_at = now()`
+	if _, err := p.exec(ctx, query, entityType.Name, entityType.Description, jsonParam(schema)); err != nil {
+		return fmt.Errorf("failed to save entity type: %w", err)
+	}
+	return nil
+}
+
+// GetEntityType returns the schema of an entity type, or nil when it has
+// none.
+func (p *PostgresStorage) GetEntityType(ctx context.Context, name string) (*EntityType, error) {
+	row, err := p.row(ctx, "SELECT name, description, properties_schema, created_at, updated_at FROM entity_types WHERE name = $1", name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get entity type: %w", err)
+	}
+	if row == nil {
+		return nil, nil
+	}
+	entityType := entityTypeFromRow(row)
+	return &entityType, nil
+}
+
+// ListEntityTypes returns the entity types with a schema, by name.
+func (p *PostgresStorage) ListEntityTypes(ctx context.Context) ([]EntityType, error) {
+	rows, err := p.rows(ctx, "SELECT name, description, properties_schema, created_at, updated_at FROM entity_types ORDER BY name")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list entity types: %w", err)
+	}
+	types := make([]EntityType, 0, len(rows))
+	for _, row := range rows {
+		types = append(types, entityTypeFromRow(row))
+	}
+	return types, nil
+}
+
+// DeleteEntityType removes the schema of an entity type and reports whether
+// it had one. Its entities are kept.
+func (p *PostgresStorage) DeleteEntityType(ctx context.Context, name string) (bool, error) {
+	n, err := p.exec(ctx, "DELETE FROM entity_types WHERE name = $1", name)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete entity type: %w", err)
+	}
+	return n > 0, nil
+}
+
+// DeleteEntity moves an entity to the trash
+func (p *PostgresStorage) DeleteEntity(ctx context.Context, entityID string) error {
+	row, err := p.row(ctx, "SELECT name, entity_type FROM entities WHERE id = $1", entityID)
+	if err != nil {
+		return fmt.Errorf("failed to delete entity: %w", err)
+	}
+	if row == nil {
+		return nil
+	}
+	content := fmt.Sprintf("%s (%s)", getString(row, "name"), getString(row, "entity_type"))
+	return p.moveToTrash(ctx, TrashKindEntity, "", entityID, content, "id = $1", entityID)
+}
+
+// SaveNoteGraph creates or updates the entity of a note and replaces its
+// outgoing links. Linked notes that were not synced yet get an entity with
+// the "unresolved" property, which is dropped once nothing links to them.
+func (p *PostgresStorage) SaveNoteGraph(ctx context.Context, note NoteGraph) error {
+	properties := map[string]interface{}{}
+	for k, v := range note.Properties {
+		properties[k] = v
+	}
+
+	return p.withTx(ctx, func(ctx context.Context) error {
+		noteID, err := p.upsertNoteEntity(ctx, note.Name, properties, true)
+		if err != nil {
+			return err
+		}
+		previous, err := p.removeNoteLinks(ctx, noteID)
+		if err != nil {
+			return err
+		}
+
+		for _, link := range note.Links {
+			if link.Target == note.Name {
+				continue
+			}
+			targetID, err := p.upsertNoteEntity(ctx, link.Target, map[string]interface{}{"unresolved": true}, false)
+			if err != nil {
+				return err
+			}
+			props := map[string]interface{}{"source": "kb"}
+			if link.Heading != "" {
+				props["heading"] = link.Heading
+			}
+			if link.Alias != "" {
+				props["alias"] = link.Alias
+			}
+			if _, err := p.createRelationship(ctx, noteID, targetID, NoteLinkRelationship, props); err != nil {
+				return fmt.Errorf("failed to link note '%s' to '%s': %w", note.Name, link.Target, err)
+			}
+		}
+
+		return p.pruneUnresolvedNotes(ctx, previous)
+	})
+}
+
+// DeleteNoteGraph removes the links of a note. The note entity is removed as
+// well unless other notes still link to it, in which case it is kept as an
+// unresolved link target.
+func (p *PostgresStorage) DeleteNoteGraph(ctx context.Context, name string) error {
+	return p.withTx(ctx, func(ctx context.Context) error {
+		noteID, err := p.findNoteEntity(ctx, name)
+		if err != nil || noteID == "" {
+			return err
+		}
+		previous, err := p.removeNoteLinks(ctx, noteID)
+		if err != nil {
+			return err
+		}
+
+		backlinks, err := p.countNoteBacklinks(ctx, noteID)
+		if err != nil {
+			return err
+		}
+		if backlinks > 0 {
+			if _, err := p.upsertNoteEntity(ctx, name, map[string]interface{}{"unresolved": true}, true); err != nil {
+				return err
+			}
+		} else if _, err := p.exec(ctx, "DELETE FROM entities WHERE id = $1", noteID); err != nil {
+			return fmt.Errorf("failed to delete note entity: %w", err)
+		}
+
+		return p.pruneUnresolvedNotes(ctx, previous)
+	})
+}
+
+// findNoteEntity returns the ID of a note entity, or "" when missing.
+func (p *PostgresStorage) findNoteEntity(ctx context.Context, name string) (string, error) {
+	row, err := p.row(ctx, "SELECT id FROM entities WHERE name = $1 AND entity_type = $2 ORDER BY created_at ASC LIMIT 1", name, NoteEntityType)
+	if err != nil {
+		return "", fmt.Errorf("failed to find note entity: %w", err)
+	}
+	return getString(row, "id"), nil
+}
+
+// upsertNoteEntity returns the ID of a note entity, creating it with
+// properties when missing. Existing entities only get their properties
+// replaced when overwrite is set.
+func (p *PostgresStorage) upsertNoteEntity(ctx context.Context, name string, properties map[string]interface{}, overwrite bool) (string, error) {
+	id, err := p.findNoteEntity(ctx, name)
+	if err != nil {
+		return "", err
+	}
+	if id == "" {
+		return p.createEntity(ctx, NoteEntityType, name, properties)
+	}
+	if overwrite {
+		if _, err := p.exec(ctx, "UPDATE entities SET properties = $2::jsonb, updated_at = now() WHERE id = $1", id, jsonParam(properties)); err != nil {
+			return "", fmt.Errorf("failed to update note entity: %w", err)
+		}
+	}
+	return id, nil
+}
+
+// removeNoteLinks deletes the outgoing links of a note and returns the IDs
+// of the notes they pointed to.
+func (p *PostgresStorage) removeNoteLinks(ctx context.Context, noteID string) ([]string, error) {
+	targets, err := p.textColumn(ctx, "DELETE FROM relationships WHERE from_entity = $1 AND relationship_type = $2 RETURNING to_entity AS value", noteID, NoteLinkRelationship)
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete note links: %w", err)
+	}
+	return targets, nil
+}
+
+// countNoteBacklinks returns the number of links pointing to a note.
+func (p *PostgresStorage) countNoteBacklinks(ctx context.Context, noteID string) (int, error) {
+	return p.count(ctx, "SELECT count(*) AS count FROM relationships WHERE to_entity = $1 AND relationship_type = $2", noteID, NoteLinkRelationship)
+}
+
+// pruneUnresolvedNotes deletes the given unresolved note entities that no
+// note links to anymore.
+func (p *PostgresStorage) pruneUnresolvedNotes(ctx context.Context, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	query := `
+		DELETE FROM entities e
+		WHERE e.id = ANY($1) AND e.entity_type = $2 AND e.properties->>'unresolved' = 'true'
+			AND NOT EXISTS (SELECT 1 FROM relationships r WHERE r.to_entity = e.id AND r.relationship_type = $3)
+	`
+	n, err := p.exec(ctx, query, ids, NoteEntityType, NoteLinkRelationship)
+	if err != nil {
+		return fmt.Errorf("failed to delete unresolved note entities: %w", err)
+	}
+	if n > 0 {
+		slog.Debug("pruned unresolved note entities", "count", n)
+	}
+	return nil
+}
@@ -0,0 +1,486 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// pgArchiveFact keeps the current value of fact $1/$2 in kv_memory_history,
+// valid until $4 or its own end if earlier, when it differs from $3::jsonb or
+// $5 is set.
+const pgArchiveFact = `
+	INSERT INTO kv_memory_history (user_id, key, value, provenance, valid_from, valid_until)
+	SELECT user_id, key, value, provenance, coalesce(valid_from, created_at), least(valid_until, $4)
+	FROM kv_memories
+	WHERE user_id = $1 AND key = $2 AND (value IS DISTINCT FROM $3::jsonb OR $5)
+`
+
+// pgSaveFact upserts fact $1/$2 with value $3 and provenance $4, valid from
+// $5 until $6. Saving the same value again without an explicit validity, $7,
+// keeps the time it has held since.
+const pgSaveFact = `
+	INSERT INTO kv_memories (user_id, key, value, provenance, valid_from, valid_until)
+	VALUES ($1, $2, $3::jsonb, nullif($4::jsonb, '{}'), $5, $6)
+	ON CONFLICT (user_id, key) DO UPDATE SET value = EXCLUDED.value, provenance = EXCLUDED.provenance, updated_at = now(),
+		valid_from = CASE WHEN kv_memories.value IS NOT DISTINCT FROM EXCLUDED.value AND NOT $7
+			THEN coalesce(kv_memories.valid_from, kv_memories.created_at) ELSE EXCLUDED.valid_from END,
+		valid_until = EXCLUDED.valid_until
+	RETURNING id
+`
+
+// SaveFact saves a key-value fact for a user, replacing the previous value,
+// which is kept in kv_memory_history
+func (p *PostgresStorage) SaveFact(ctx context.Context, userID, key string, value interface{}) error {
+	if _, err := p.saveFact(ctx, userID, key, value); err != nil {
+		return fmt.Errorf("failed to save fact: %w", err)
+	}
+	return nil
+}
+
+// saveFact archives the current value of a fact and upserts the new one,
+// returning its ID.
+func (p *PostgresStorage) saveFact(ctx context.Context, userID, key string, value interface{}) (string, error) {
+	from, until, explicit := validityFromContext(ctx)
+	var id string
+	err := p.withTx(ctx, func(ctx context.Context) error {
+		if _, err := p.exec(ctx, pgArchiveFact, userID, key, jsonParam(value), from, explicit); err != nil {
+			return fmt.Errorf("failed to keep fact history: %w", err)
+		}
+		row, err := p.row(ctx, pgSaveFact, userID, key, jsonParam(value), jsonParam(factProvenance(ctx)), from, until, explicit)
+		if err != nil {
+			return err
+		}
+		id = getString(row, "id")
+		return nil
+	})
+	return id, err
+}
+
+// GetFact retrieves a key-value fact for a user, or nil when it does not exist
+func (p *PostgresStorage) GetFact(ctx context.Context, userID, key string) (interface{}, error) {
+	row, err := p.row(ctx, "SELECT value FROM kv_memories WHERE user_id = $1 AND key = $2", userID, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get fact: %w", err)
+	}
+	if row == nil {
+		return nil, nil
+	}
+	return row["value"], nil
+}
+
+// UpdateFact updates an existing key-value fact, keeping the previous value
+// in kv_memory_history
+func (p *PostgresStorage) UpdateFact(ctx context.Context, userID, key string, value interface{}) error {
+	return p.withTx(ctx, func(ctx context.Context) error {
+		n, err := p.count(ctx, "SELECT count(*) AS count FROM kv_memories WHERE user_id = $1 AND key = $2", userID, key)
+		if err != nil {
+			return fmt.Errorf("failed to update fact: %w", err)
+		}
+		if n == 0 {
+			return fmt.Errorf("fact %w for user %s and key %s", ErrNotFound, userID, key)
+		}
+		if _, err := p.saveFact(ctx, userID, key, value); err != nil {
+			return fmt.Errorf("failed to update fact: %w", err)
+		}
+		return nil
+	})
+}
+
+// DeleteFact moves a key-value fact to the trash. Its value is kept in
+// kv_memory_history as held until the deletion.
+func (p *PostgresStorage) DeleteFact(ctx context.Context, userID, key string) error {
+	value, err := p.GetFact(ctx, userID, key)
+	if err != nil {
+		return fmt.Errorf("failed to delete fact: %w", err)
+	}
+	if value == nil {
+		return nil
+	}
+	return p.withTx(ctx, func(ctx context.Context) error {
+		if _, err := p.exec(ctx, pgArchiveFact, userID, key, nil, time.Now().UTC(), true); err != nil {
+			return fmt.Errorf("failed to keep fact history: %w", err)
+		}
+		return p.moveToTrash(ctx, TrashKindFact, userID, key, trashContent(value), "user_id = $1 AND key = $2", userID, key)
+	})
+}
+
+// ListFacts returns all facts of a user, by key
+func (p *PostgresStorage) ListFacts(ctx context.Context, userID string) (map[string]interface{}, error) {
+	rows, err := p.rows(ctx, "SELECT key, value FROM kv_memories WHERE user_id = $1", userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list facts: %w", err)
+	}
+	facts := make(map[string]interface{}, len(rows))
+	for _, row := range rows {
+		facts[getString(row, "key")] = row["value"]
+	}
+	return facts, nil
+}
+
+// ListFactProvenance returns the provenance of the facts of a user that were
+// saved with one, by key.
+func (p *PostgresStorage) ListFactProvenance(ctx context.Context, userID string) (map[string]Provenance, error) {
+	rows, err := p.rows(ctx, "SELECT key, provenance FROM kv_memories WHERE user_id = $1 AND provenance IS NOT NULL", userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list fact provenance: %w", err)
+	}
+	provenance := make(map[string]Provenance, len(rows))
+	for _, row := range rows {
+		if prov, ok := ProvenanceFromMap(row["provenance"]); ok {
+			provenance[getString(row, "key")] = prov
+		}
+	}
+	return provenance, nil
+}
+
+// GetFactHistory returns the current value of a fact and the values it held
+// before, newest first.
+func (p *PostgresStorage) GetFactHistory(ctx context.Context, userID, key string) ([]FactVersion, error) {
+	versions, err := p.factVersions(ctx, "user_id = $1 AND key = $2", userID, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get fact history: %w", err)
+	}
+	sortFactVersions(versions)
+	return versions, nil
+}
+
+// ListFactsAsOf returns the versions of the facts of a user valid at a point
+// in time, sorted by key.
+func (p *PostgresStorage) ListFactsAsOf(ctx context.Context, userID string, at time.Time) ([]FactVersion, error) {
+	versions, err := p.factVersions(ctx, "user_id = $1", userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list facts as of %s: %w", at.Format(time.RFC3339), err)
+	}
+	return factsAsOf(versions, at), nil
+}
+
+// factVersions returns the current and past versions of the facts matching
+// where.
+func (p *PostgresStorage) factVersions(ctx context.Context, where string, args ...interface{}) ([]FactVersion, error) {
+	var versions []FactVersion
+	for _, table := range []string{"kv_memories", "kv_memory_history"} {
+		columns := "key, value, provenance, valid_from, valid_until"
+		if table == "kv_memories" {
+			columns += ", created_at"
+		}
+		rows, err := p.rows(ctx, "SELECT "+columns+" FROM "+table+" WHERE "+where, args...)
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range rows {
+			versions = append(versions, factVersionFromRow(row, table == "kv_memories"))
+		}
+	}
+	return versions, nil
+}
+
+// ListFactKeys returns distinct fact keys for a user
+func (p *PostgresStorage) ListFactKeys(ctx context.Context, userID string) ([]string, error) {
+	keys, err := p.textColumn(ctx, "SELECT DISTINCT key AS value FROM kv_memories WHERE user_id = $1 ORDER BY 1", userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list fact keys: %w", err)
+	}
+	return keys, nil
+}
+
+// ListUserIDs returns distinct user IDs for a table
+func (p *PostgresStorage) ListUserIDs(ctx context.Context, table string) ([]string, error) {
+	if table == "" {
+		return []string{}, fmt.Errorf("table name is required")
+	}
+	name, err := pgTable(table)
+	if err != nil {
+		return []string{}, err
+	}
+	values, err := p.textColumn(ctx, fmt.Sprintf("SELECT DISTINCT user_id AS value FROM %s WHERE user_id IS NOT NULL", name))
+	if err != nil {
+		return []string{}, fmt.Errorf("failed to list user ids for %s: %w", table, err)
+	}
+	sort.Strings(values)
+	return values, nil
+}
+
+// ListEntityIDs returns distinct entity IDs
+func (p *PostgresStorage) ListEntityIDs(ctx context.Context) ([]string, error) {
+	ids, err := p.textColumn(ctx, "SELECT id AS value FROM entities ORDER BY 1")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list entity ids: %w", err)
+	}
+	return ids, nil
+}
+
+// ListDocumentPaths returns distinct document file paths
+func (p *PostgresStorage) ListDocumentPaths(ctx context.Context) ([]string, error) {
+	paths, err := p.textColumn(ctx, "SELECT DISTINCT coalesce(source_file, file_path) AS value FROM knowledge_base ORDER BY 1")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list document paths: %w", err)
+	}
+	return paths, nil
+}
+
+// textColumn runs a query returning a single "value" text column.
+func (p *PostgresStorage) textColumn(ctx context.Context, sql string, args ...interface{}) ([]string, error) {
+	rows, err := p.rows(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	values := make([]string, 0, len(rows))
+	for _, row := range rows {
+		values = append(values, getString(row, "value"))
+	}
+	return values, nil
+}
+
+// IndexVector stores a vector embedding with content and metadata
+func (p *PostgresStorage) IndexVector(ctx context.Context, userID, content string, embedding []float32, metadata map[string]interface{}) error {
+	if metadata == nil {
+		metadata = map[string]interface{}{}
+	}
+	metadata = withProvenance(ctx, metadata)
+	if err := p.checkEmbedding("vector_memories", embedding); err != nil {
+		return err
+	}
+	query := "INSERT INTO vector_memories (user_id, content, embedding, metadata, embedding_model, embedding_dim) VALUES (nullif($1, ''), $2, $3::vector, $4::jsonb, $5, $6)"
+	if _, err := p.exec(ctx, query, userID, content, vectorParam(embedding), jsonParam(metadata), p.embeddingModelOf(ctx, "vector_memories", embedding), len(embedding)); err != nil {
+		return fmt.Errorf("failed to index vector: %w", err)
+	}
+	return nil
+}
+
+// SearchSimilar performs vector similarity search
+func (p *PostgresStorage) SearchSimilar(ctx context.Context, userID string, queryEmbedding []float32, limit int) ([]VectorResult, error) {
+	return p.SearchSimilarWithOptions(ctx, userID, queryEmbedding, VectorSearchOptions{Limit: limit})
+}
+
+// SearchSimilarWithOptions performs vector similarity search with HNSW tuning and a similarity threshold
+func (p *PostgresStorage) SearchSimilarWithOptions(ctx context.Context, userID string, queryEmbedding []float32, opts VectorSearchOptions) ([]VectorResult, error) {
+	args := pgArgs{}
+	user := args.add(userID)
+	q := args.add(vectorParam(queryEmbedding))
+	query := fmt.Sprintf(`
+		SELECT id, content, 1 - (embedding <=> %[1]s::vector) AS similarity, metadata, revision, created_at, updated_at
+		FROM vector_memories
+		WHERE user_id = %[2]s AND embedding IS NOT NULL%[3]s%[4]s%[5]s
+		ORDER BY embedding <=> %[1]s::vector
+	`, q, user, pgMinSimilarity(opts, q, &args), pgMinConfidence(opts, &args), p.embeddingModelClause(ctx, "vector_memories", &args))
+
+	rows, err := p.searchRows(ctx, opts, query, args)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search similar vectors: %w", err)
+	}
+
+	results := make([]VectorResult, 0, len(rows))
+	for _, row := range rows {
+		results = append(results, VectorResult{
+			ID:         getString(row, "id"),
+			Content:    getString(row, "content"),
+			Similarity: getFloat64(row, "similarity"),
+			Score:      getFloat64(row, "similarity"),
+			Metadata:   getMap(row, "metadata"),
+			Revision:   convertToInt(row["revision"]),
+			CreatedAt:  getTime(row, "created_at"),
+			UpdatedAt:  getTime(row, "updated_at"),
+		})
+	}
+	return results, nil
+}
+
+// UpdateVector updates an existing vector memory and returns its new revision.
+// When expectedRevision > 0 the update is applied only if the stored revision
+// still matches; otherwise a *RevisionConflictError is returned.
+func (p *PostgresStorage) UpdateVector(ctx context.Context, id, userID, content string, embedding []float32, metadata map[string]interface{}, expectedRevision int) (int, error) {
+	if metadata == nil {
+		metadata = map[string]interface{}{}
+	}
+	metadata = withProvenance(ctx, metadata)
+	if err := p.checkEmbedding("vector_memories", embedding); err != nil {
+		return 0, err
+	}
+
+	query := `
+		UPDATE vector_memories SET
+			content = $3,
+			embedding = $4::vector,
+			metadata = $5::jsonb,
+			embedding_model = $7,
+			embedding_dim = $8,
+			revision = revision + 1,
+			updated_at = now()
+		WHERE id = $1 AND ($2 = '' OR user_id = $2) AND ($6::int = 0 OR revision = $6::int)
+		RETURNING revision
+	`
+	row, err := p.row(ctx, query, id, userID, content, vectorParam(embedding), jsonParam(metadata), expectedRevision,
+		p.embeddingModelOf(ctx, "vector_memories", embedding), len(embedding))
+	if err != nil {
+		return 0, fmt.Errorf("failed to update vector: %w", err)
+	}
+	if row != nil {
+		return convertToInt(row["revision"]), nil
+	}
+
+	// Nothing was updated: either the record is gone or its revision moved on
+	current, err := p.row(ctx, "SELECT revision FROM vector_memories WHERE id = $1 AND ($2 = '' OR user_id = $2)", id, userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read vector revision: %w", err)
+	}
+	if current == nil {
+		return 0, fmt.Errorf("vector %s %w", id, ErrNotFound)
+	}
+	return 0, &RevisionConflictError{
+		ID:       id,
+		Expected: expectedRevision,
+		Current:  convertToInt(current["revision"]),
+	}
+}
+
+// DeleteVector moves a vector memory to the trash
+func (p *PostgresStorage) DeleteVector(ctx context.Context, id, userID string) error {
+	row, err := p.row(ctx, "SELECT content FROM vector_memories WHERE id = $1", id)
+	if err != nil {
+		return fmt.Errorf("failed to delete vector: %w", err)
+	}
+	if row == nil {
+		return nil
+	}
+	return p.moveToTrash(ctx, TrashKindVector, userID, id, getString(row, "content"), "id = $1", id)
+}
+
+// MergeVectorMetadata merges metadata into an existing vector memory, keeping keys
+// that are not present in the new metadata
+func (p *PostgresStorage) MergeVectorMetadata(ctx context.Context, id string, metadata map[string]interface{}) error {
+	query := "UPDATE vector_memories SET metadata = metadata || $2::jsonb, updated_at = now() WHERE id = $1"
+	if _, err := p.exec(ctx, query, id, jsonParam(metadata)); err != nil {
+		return fmt.Errorf("failed to merge vector metadata: %w", err)
+	}
+	return nil
+}
+
+// ListVectorMemories returns up to limit vector memories of a user together with
+// their embeddings, oldest first. A limit <= 0 returns all memories.
+func (p *PostgresStorage) ListVectorMemories(ctx context.Context, userID string, limit int) ([]VectorMemory, error) {
+	query := "SELECT id, content, embedding::text AS embedding, metadata, created_at FROM vector_memories WHERE user_id = $1 ORDER BY created_at ASC"
+	args := []interface{}{userID}
+	if limit > 0 {
+		query += " LIMIT $2"
+		args = append(args, limit)
+	}
+
+	rows, err := p.rows(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list vector memories: %w", err)
+	}
+
+	var memories []VectorMemory
+	for _, row := range rows {
+		embedding, _ := row["embedding"].([]float32)
+		memories = append(memories, VectorMemory{
+			ID:        getString(row, "id"),
+			Content:   getString(row, "content"),
+			Embedding: embedding,
+			Metadata:  getMap(row, "metadata"),
+			CreatedAt: getTime(row, "created_at"),
+		})
+	}
+	return memories, nil
+}
+
+// ConsolidateVectors stores summary as a new vector memory and moves the memories
+// in ids to archived_vector_memories, linked to the summary through consolidated_into.
+// It returns the id of the summary memory.
+func (p *PostgresStorage) ConsolidateVectors(ctx context.Context, userID, summary string, embedding []float32, metadata map[string]interface{}, ids []string) (string, error) {
+	if len(ids) == 0 {
+		return "", fmt.Errorf("no memories to consolidate")
+	}
+	if metadata == nil {
+		metadata = map[string]interface{}{}
+	}
+	if err := p.checkEmbedding("vector_memories", embedding); err != nil {
+		return "", err
+	}
+
+	var summaryID string
+	err := p.withTx(ctx, func(ctx context.Context) error {
+		if err := p.checkPinnedVectors(ctx, ids); err != nil {
+			return err
+		}
+		row, err := p.row(ctx, "INSERT INTO vector_memories (user_id, content, embedding, metadata, embedding_model, embedding_dim) VALUES (nullif($1, ''), $2, $3::vector, $4::jsonb, $5, $6) RETURNING id",
+			userID, summary, vectorParam(embedding), jsonParam(metadata), p.embeddingModelOf(ctx, "vector_memories", embedding), len(embedding))
+		if err != nil || row == nil {
+			return fmt.Errorf("failed to store consolidated memory: %w", err)
+		}
+		summaryID = getString(row, "id")
+
+		archiveQuery := `
+			INSERT INTO archived_vector_memories (original_id, consolidated_into, user_id, content, embedding, metadata, created_at)
+			SELECT id, $2, user_id, content, embedding, metadata, created_at FROM vector_memories WHERE id = ANY($1)
+		`
+		if _, err := p.exec(ctx, archiveQuery, ids, summaryID); err != nil {
+			return fmt.Errorf("failed to archive consolidated memories: %w", err)
+		}
+		if _, err := p.exec(ctx, "DELETE FROM vector_memories WHERE id = ANY($1)", ids); err != nil {
+			return fmt.Errorf("failed to delete consolidated memories: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return summaryID, nil
+}
+
+// searchRows runs a nearest-neighbour query limited to opts.Limit rows. The
+// HNSW candidate list size and exact scans are set for the query only.
+func (p *PostgresStorage) searchRows(ctx context.Context, opts VectorSearchOptions, query string, args pgArgs) ([]map[string]interface{}, error) {
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = 10
+	}
+	var settings []string
+	switch opts.Accuracy {
+	case "", SearchAccuracyApproximate:
+		if opts.EfSearch > 0 {
+			settings = append(settings, fmt.Sprintf("SET LOCAL hnsw.ef_search = %d", opts.EfSearch))
+		}
+	case SearchAccuracyExact:
+		settings = append(settings, "SET LOCAL enable_indexscan = off")
+	default:
+		return nil, fmt.Errorf("invalid search accuracy %q: expected %q or %q", opts.Accuracy, SearchAccuracyApproximate, SearchAccuracyExact)
+	}
+	query += " LIMIT " + args.add(limit)
+
+	if len(settings) == 0 {
+		return p.rows(ctx, query, args...)
+	}
+	var rows []map[string]interface{}
+	err := p.withTx(ctx, func(ctx context.Context) error {
+		for _, setting := range settings {
+			if _, err := p.exec(ctx, setting); err != nil {
+				return err
+			}
+		}
+		var err error
+		rows, err = p.rows(ctx, query, args...)
+		return err
+	})
+	return rows, err
+}
+
+// pgMinConfidence returns the WHERE fragment enforcing opts.MinConfidence
+// against the provenance in metadata.
+func pgMinConfidence(opts VectorSearchOptions, args *pgArgs) string {
+	if opts.MinConfidence <= 0 {
+		return ""
+	}
+	return fmt.Sprintf(" AND (metadata->'provenance'->>'confidence')::float8 >= %s", args.add(opts.MinConfidence))
+}
+
+// pgMinSimilarity returns the WHERE fragment enforcing opts.MinSimilarity
+// against the query embedding placeholder q.
+func pgMinSimilarity(opts VectorSearchOptions, q string, args *pgArgs) string {
+	if opts.MinSimilarity <= 0 {
+		return ""
+	}
+	return fmt.Sprintf(" AND 1 - (embedding <=> %s::vector) >= %s", q, args.add(opts.MinSimilarity))
+}
@@ -0,0 +1,369 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+// postgresSchemaVersion is the version of postgresSchema. Bump it and add an
+// idempotent statement when the schema changes.
+const postgresSchemaVersion = 13
+
+// pgID returns the default of an id column: "table:" followed by a random key.
+func pgID(table string) string {
+	return fmt.Sprintf("id TEXT PRIMARY KEY DEFAULT ('%s:' || replace(gen_random_uuid()::text, '-', ''))", table)
+}
+
+// postgresSchema creates the tables and indexes, mirroring the SurrealDB
+// schema. Every statement is idempotent.
+var postgresSchema = []string{
+	`CREATE EXTENSION IF NOT EXISTS vector`,
+	`CREATE TABLE IF NOT EXISTS schema_version (version INT NOT NULL, applied_at TIMESTAMPTZ NOT NULL DEFAULT now())`,
+
+	`CREATE TABLE IF NOT EXISTS kv_memories (` + pgID("kv_memories") + `,
+		user_id TEXT NOT NULL,
+		key TEXT NOT NULL,
+		value JSONB,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+		updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+		UNIQUE (user_id, key))`,
+
+	`CREATE TABLE IF NOT EXISTS vector_memories (` + pgID("vector_memories") + `,
+		user_id TEXT,
+		content TEXT NOT NULL,
+		embedding vector(768),
+		metadata JSONB NOT NULL DEFAULT '{}',
+		revision INT NOT NULL DEFAULT 1,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+		updated_at TIMESTAMPTZ NOT NULL DEFAULT now())`,
+	`CREATE INDEX IF NOT EXISTS idx_vector_user ON vector_memories (user_id)`,
+	`CREATE INDEX IF NOT EXISTS idx_vector_embedding ON vector_memories USING hnsw (embedding vector_cosine_ops)`,
+
+	`CREATE TABLE IF NOT EXISTS archived_vector_memories (` + pgID("archived_vector_memories") + `,
+		original_id TEXT NOT NULL,
+		consolidated_into TEXT NOT NULL,
+		user_id TEXT,
+		content TEXT NOT NULL,
+		embedding vector(768),
+		metadata JSONB NOT NULL DEFAULT '{}',
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+		archived_at TIMESTAMPTZ NOT NULL DEFAULT now())`,
+
+	`CREATE TABLE IF NOT EXISTS entities (` + pgID("entities") + `,
+		user_id TEXT,
+		entity_type TEXT NOT NULL,
+		name TEXT NOT NULL,
+		properties JSONB NOT NULL DEFAULT '{}',
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+		updated_at TIMESTAMPTZ NOT NULL DEFAULT now())`,
+	`CREATE INDEX IF NOT EXISTS idx_entities_name ON entities (name)`,
+
+	`CREATE TABLE IF NOT EXISTS relationships (` + pgID("relationships") + `,
+		user_id TEXT,
+		from_entity TEXT NOT NULL,
+		to_entity TEXT NOT NULL,
+		relationship_type TEXT NOT NULL,
+		properties JSONB NOT NULL DEFAULT '{}',
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now())`,
+	`CREATE INDEX IF NOT EXISTS idx_relationships_from ON relationships (from_entity, relationship_type)`,
+	`CREATE INDEX IF NOT EXISTS idx_relationships_to ON relationships (to_entity, relationship_type)`,
+
+	`CREATE TABLE IF NOT EXISTS knowledge_base (` + pgID("knowledge_base") + `,
+		user_id TEXT,
+		file_path TEXT NOT NULL UNIQUE,
+		content TEXT NOT NULL,
+		embedding vector(768),
+		metadata JSONB NOT NULL DEFAULT '{}',
+		source_file TEXT,
+		chunk_index INT,
+		chunk_count INT,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+		updated_at TIMESTAMPTZ NOT NULL DEFAULT now())`,
+	`CREATE INDEX IF NOT EXISTS idx_kb_source_file ON knowledge_base (source_file)`,
+	`CREATE INDEX IF NOT EXISTS idx_kb_embedding ON knowledge_base USING hnsw (embedding vector_cosine_ops)`,
+
+	`CREATE TABLE IF NOT EXISTS kb_document_versions (` + pgID("kb_document_versions") + `,
+		file_path TEXT NOT NULL,
+		version INT NOT NULL,
+		content TEXT NOT NULL,
+		diff TEXT NOT NULL DEFAULT '',
+		chunk_count INT NOT NULL DEFAULT 1,
+		embedding vector(768),
+		metadata JSONB NOT NULL DEFAULT '{}',
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+		UNIQUE (file_path, version))`,
+	`CREATE INDEX IF NOT EXISTS idx_kb_versions_embedding ON kb_document_versions USING hnsw (embedding vector_cosine_ops)`,
+
+	`CREATE TABLE IF NOT EXISTS events (` + pgID("events") + `,
+		user_id TEXT NOT NULL,
+		subject TEXT NOT NULL,
+		content TEXT NOT NULL,
+		embedding vector(768),
+		metadata JSONB NOT NULL DEFAULT '{}',
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now())`,
+	`CREATE INDEX IF NOT EXISTS idx_events_user_subject ON events (user_id, subject, created_at)`,
+	`CREATE INDEX IF NOT EXISTS idx_events_content ON events USING gin (to_tsvector('simple', content))`,
+	`CREATE INDEX IF NOT EXISTS idx_events_embedding ON events USING hnsw (embedding vector_cosine_ops)`,
+
+	`CREATE TABLE IF NOT EXISTS trash (` + pgID("trash") + `,
+		kind TEXT NOT NULL,
+		source_table TEXT NOT NULL,
+		user_id TEXT NOT NULL DEFAULT '',
+		label TEXT NOT NULL,
+		content TEXT NOT NULL DEFAULT '',
+		records JSONB NOT NULL DEFAULT '[]',
+		deleted_at TIMESTAMPTZ NOT NULL DEFAULT now())`,
+
+	`CREATE TABLE IF NOT EXISTS memory_hits (` + pgID("memory_hits") + `,
+		kind TEXT NOT NULL,
+		ref TEXT NOT NULL,
+		user_id TEXT NOT NULL DEFAULT '',
+		tool TEXT NOT NULL DEFAULT '',
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now())`,
+	`CREATE INDEX IF NOT EXISTS idx_memory_hits_created ON memory_hits (created_at)`,
+
+	`CREATE TABLE IF NOT EXISTS audit_log (` + pgID("audit_log") + `,
+		action TEXT NOT NULL,
+		subject TEXT NOT NULL,
+		details JSONB NOT NULL DEFAULT '{}',
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now())`,
+
+	`CREATE TABLE IF NOT EXISTS code_projects (` + pgID("code_projects") + `,
+		project_id TEXT NOT NULL UNIQUE,
+		name TEXT NOT NULL,
+		root_path TEXT NOT NULL,
+		language_stats JSONB NOT NULL DEFAULT '{}',
+		last_indexed_at TIMESTAMPTZ,
+		indexing_status TEXT NOT NULL DEFAULT 'pending',
+		watcher_enabled BOOLEAN NOT NULL DEFAULT false,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+		updated_at TIMESTAMPTZ NOT NULL DEFAULT now())`,
+
+	`CREATE TABLE IF NOT EXISTS code_files (` + pgID("code_files") + `,
+		project_id TEXT NOT NULL,
+		file_path TEXT NOT NULL,
+		language TEXT NOT NULL,
+		file_hash TEXT NOT NULL,
+		symbols_count INT NOT NULL DEFAULT 0,
+		content_gz TEXT,
+		indexed_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+		UNIQUE (project_id, file_path))`,
+
+	`CREATE TABLE IF NOT EXISTS code_symbols (` + pgID("code_symbols") + `,
+		project_id TEXT NOT NULL,
+		file_path TEXT NOT NULL,
+		language TEXT NOT NULL,
+		symbol_type TEXT NOT NULL,
+		name TEXT NOT NULL,
+		name_path TEXT NOT NULL,
+		start_line INT NOT NULL,
+		end_line INT NOT NULL,
+		start_byte INT NOT NULL,
+		end_byte INT NOT NULL,
+		source_code TEXT,
+		revision TEXT,
+		signature TEXT,
+		doc_string TEXT,
+		embedding vector(768),
+		parent_id TEXT,
+		metadata JSONB,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+		updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+		UNIQUE (project_id, name_path))`,
+	`CREATE INDEX IF NOT EXISTS idx_code_symbols_file ON code_symbols (project_id, file_path)`,
+	`CREATE INDEX IF NOT EXISTS idx_code_symbols_name ON code_symbols (project_id, name)`,
+	`CREATE INDEX IF NOT EXISTS idx_code_symbol_embedding ON code_symbols USING hnsw (embedding vector_cosine_ops)`,
+
+	`CREATE TABLE IF NOT EXISTS code_chunks (` + pgID("code_chunks") + `,
+		symbol_id TEXT NOT NULL,
+		project_id TEXT NOT NULL,
+		file_path TEXT NOT NULL,
+		chunk_index INT NOT NULL,
+		chunk_count INT NOT NULL,
+		content TEXT NOT NULL,
+		start_offset INT NOT NULL,
+		end_offset INT NOT NULL,
+		embedding vector(768),
+		symbol_name TEXT NOT NULL DEFAULT '',
+		symbol_type TEXT NOT NULL DEFAULT '',
+		language TEXT NOT NULL DEFAULT '',
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+		UNIQUE (symbol_id, chunk_index))`,
+	`CREATE INDEX IF NOT EXISTS idx_code_chunks_file ON code_chunks (project_id, file_path)`,
+	`CREATE INDEX IF NOT EXISTS idx_code_chunk_embedding ON code_chunks USING hnsw (embedding vector_cosine_ops)`,
+
+	`CREATE TABLE IF NOT EXISTS code_dependencies (` + pgID("code_dependencies") + `,
+		project_id TEXT NOT NULL,
+		file_path TEXT NOT NULL,
+		module TEXT NOT NULL,
+		target_path TEXT,
+		line INT NOT NULL,
+		language TEXT NOT NULL,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now())`,
+	`CREATE INDEX IF NOT EXISTS idx_code_dependencies_file ON code_dependencies (project_id, file_path)`,
+
+	`CREATE TABLE IF NOT EXISTS code_calls (` + pgID("code_calls") + `,
+		project_id TEXT NOT NULL,
+		file_path TEXT NOT NULL,
+		caller_name_path TEXT NOT NULL DEFAULT '',
+		caller_name TEXT NOT NULL DEFAULT '',
+		callee_name TEXT NOT NULL,
+		callee_expr TEXT NOT NULL DEFAULT '',
+		line INT NOT NULL,
+		language TEXT NOT NULL,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now())`,
+	`CREATE INDEX IF NOT EXISTS idx_code_calls_callee ON code_calls (project_id, callee_name)`,
+	`CREATE INDEX IF NOT EXISTS idx_code_calls_file ON code_calls (project_id, file_path)`,
+
+	`CREATE TABLE IF NOT EXISTS code_indexing_jobs (` + pgID("code_indexing_jobs") + `,
+		project_id TEXT NOT NULL,
+		project_path TEXT NOT NULL,
+		status TEXT NOT NULL,
+		progress DOUBLE PRECISION NOT NULL DEFAULT 0,
+		files_total INT NOT NULL DEFAULT 0,
+		files_indexed INT NOT NULL DEFAULT 0,
+		started_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+		completed_at TIMESTAMPTZ,
+		error TEXT)`,
+
+	// v2: the model and original dimension of every embedding
+	`ALTER TABLE vector_memories ADD COLUMN IF NOT EXISTS embedding_model TEXT, ADD COLUMN IF NOT EXISTS embedding_dim INT`,
+	`ALTER TABLE knowledge_base ADD COLUMN IF NOT EXISTS embedding_model TEXT, ADD COLUMN IF NOT EXISTS embedding_dim INT`,
+	`ALTER TABLE kb_document_versions ADD COLUMN IF NOT EXISTS embedding_model TEXT, ADD COLUMN IF NOT EXISTS embedding_dim INT`,
+	`ALTER TABLE events ADD COLUMN IF NOT EXISTS embedding_model TEXT, ADD COLUMN IF NOT EXISTS embedding_dim INT`,
+	`ALTER TABLE code_symbols ADD COLUMN IF NOT EXISTS embedding_model TEXT, ADD COLUMN IF NOT EXISTS embedding_dim INT`,
+	`ALTER TABLE code_chunks ADD COLUMN IF NOT EXISTS embedding_model TEXT, ADD COLUMN IF NOT EXISTS embedding_dim INT`,
+
+	// v3: results of write tool calls sent with an idempotency key
+	`CREATE TABLE IF NOT EXISTS idempotency_keys (
+		tool TEXT NOT NULL,
+		key TEXT NOT NULL,
+		result TEXT NOT NULL,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+		PRIMARY KEY (tool, key))`,
+	`CREATE INDEX IF NOT EXISTS idx_idempotency_keys_created ON idempotency_keys (created_at)`,
+
+	// v4: search tool calls saved by name
+	`CREATE TABLE IF NOT EXISTS saved_searches (` + pgID("saved_searches") + `,
+		user_id TEXT NOT NULL,
+		name TEXT NOT NULL,
+		tool TEXT NOT NULL,
+		arguments JSONB NOT NULL DEFAULT '{}',
+		description TEXT NOT NULL DEFAULT '',
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+		updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+		UNIQUE (user_id, name))`,
+
+	// v5: schedules of saved searches and the new results their runs found
+	`ALTER TABLE saved_searches ADD COLUMN IF NOT EXISTS interval_seconds INT NOT NULL DEFAULT 0,
+		ADD COLUMN IF NOT EXISTS last_run_at TIMESTAMPTZ,
+		ADD COLUMN IF NOT EXISTS last_results JSONB NOT NULL DEFAULT '[]'`,
+	`CREATE TABLE IF NOT EXISTS saved_search_changes (` + pgID("saved_search_changes") + `,
+		user_id TEXT NOT NULL,
+		name TEXT NOT NULL,
+		tool TEXT NOT NULL,
+		items JSONB NOT NULL DEFAULT '[]',
+		run_at TIMESTAMPTZ NOT NULL DEFAULT now())`,
+	`CREATE INDEX IF NOT EXISTS idx_saved_search_changes_user ON saved_search_changes (user_id, name, run_at)`,
+
+	// v6: pins protecting facts, vectors and documents from deletion
+	`CREATE TABLE IF NOT EXISTS pinned_memories (` + pgID("pinned_memories") + `,
+		kind TEXT NOT NULL,
+		user_id TEXT NOT NULL DEFAULT '',
+		ref TEXT NOT NULL,
+		pinned_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+		UNIQUE (kind, user_id, ref))`,
+
+	// v7: provenance of facts; vectors, documents and events keep it in metadata
+	`ALTER TABLE kv_memories ADD COLUMN IF NOT EXISTS provenance JSONB`,
+
+	// v8: validity intervals of facts and relationships, and past fact values
+	`ALTER TABLE kv_memories ADD COLUMN IF NOT EXISTS valid_from TIMESTAMPTZ,
+		ADD COLUMN IF NOT EXISTS valid_until TIMESTAMPTZ`,
+	`ALTER TABLE relationships ADD COLUMN IF NOT EXISTS valid_from TIMESTAMPTZ,
+		ADD COLUMN IF NOT EXISTS valid_until TIMESTAMPTZ`,
+	`CREATE TABLE IF NOT EXISTS kv_memory_history (` + pgID("kv_memory_history") + `,
+		user_id TEXT NOT NULL,
+		key TEXT NOT NULL,
+		value JSONB,
+		provenance JSONB,
+		valid_from TIMESTAMPTZ NOT NULL,
+		valid_until TIMESTAMPTZ NOT NULL)`,
+	`CREATE INDEX IF NOT EXISTS idx_kv_memory_history_key ON kv_memory_history (user_id, key, valid_from)`,
+
+	// v9: property schemas of entity types
+	`CREATE TABLE IF NOT EXISTS entity_types (` + pgID("entity_types") + `,
+		name TEXT NOT NULL UNIQUE,
+		description TEXT NOT NULL DEFAULT '',
+		properties_schema JSONB NOT NULL DEFAULT '{}',
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+		updated_at TIMESTAMPTZ NOT NULL DEFAULT now())`,
+
+	// v10: full-text search of code symbol doc strings and signatures
+	`CREATE INDEX IF NOT EXISTS idx_code_symbols_docs ON code_symbols
+		USING gin (to_tsvector('simple', coalesce(doc_string, '') || ' ' || coalesce(signature, '')))`,
+
+	// v11: aliases of code projects
+	`ALTER TABLE code_projects ADD COLUMN IF NOT EXISTS alias TEXT`,
+	`CREATE UNIQUE INDEX IF NOT EXISTS idx_code_projects_alias ON code_projects (alias) WHERE alias IS NOT NULL`,
+
+	// v12: indexing settings of code projects
+	`ALTER TABLE code_projects ADD COLUMN IF NOT EXISTS embedder TEXT NOT NULL DEFAULT '',
+		ADD COLUMN IF NOT EXISTS chunk_size INT NOT NULL DEFAULT 0,
+		ADD COLUMN IF NOT EXISTS chunk_overlap INT NOT NULL DEFAULT 0,
+		ADD COLUMN IF NOT EXISTS max_symbol_size INT NOT NULL DEFAULT 0`,
+
+	// v13: code symbol IDs derived from the project, file and name path, as
+	// treesitter.SymbolID does, and parents referenced by those IDs. Name
+	// paths are unique per file, so files may define the same names.
+	`ALTER TABLE code_symbols DROP CONSTRAINT IF EXISTS code_symbols_project_id_name_path_key`,
+	`CREATE UNIQUE INDEX IF NOT EXISTS idx_code_symbols_name_path ON code_symbols (project_id, file_path, name_path)`,
+	`UPDATE code_symbols SET id = 'code_symbols:' || ` + pgSymbolKey("name_path") + `
+		WHERE id <> 'code_symbols:' || ` + pgSymbolKey("name_path"),
+	`UPDATE code_symbols SET parent_id = 'code_symbols:' || ` + pgSymbolKey(`regexp_replace(name_path, '/[^/]*$', '')`) + `
+		WHERE parent_id IS NOT NULL`,
+}
+
+// pgSymbolKey returns the SQL of the key treesitter.SymbolID derives for a
+// name path expression of a code_symbols row.
+func pgSymbolKey(namePath string) string {
+	return fmt.Sprintf("left(encode(sha256(convert_to(project_id || ':' || file_path || ':' || %s, 'UTF8')), 'hex'), 32)", namePath)
+}
+
+// InitializeSchema creates the pgvector extension, tables and indexes
+func (p *PostgresStorage) InitializeSchema(ctx context.Context) error {
+	current, err := p.schemaVersion(ctx)
+	if err == nil && current >= postgresSchemaVersion {
+		slog.Info("Postgres schema is up to date", "version", current)
+		return nil
+	}
+
+	return p.withTx(ctx, func(ctx context.Context) error {
+		for _, stmt := range postgresSchema {
+			if _, err := p.exec(ctx, stmt); err != nil {
+				return fmt.Errorf("failed to initialize schema: %s: %w", firstLine(stmt), err)
+			}
+		}
+		if _, err := p.exec(ctx, "INSERT INTO schema_version (version) VALUES ($1)", postgresSchemaVersion); err != nil {
+			return fmt.Errorf("failed to record schema version: %w", err)
+		}
+		slog.Info("Postgres schema initialized", "version", postgresSchemaVersion)
+		return nil
+	})
+}
+
+// schemaVersion returns the latest applied schema version.
+func (p *PostgresStorage) schemaVersion(ctx context.Context) (int, error) {
+	row, err := p.row(ctx, "SELECT coalesce(max(version), 0) AS version FROM schema_version")
+	if err != nil || row == nil {
+		return 0, err
+	}
+	return convertToInt(row["version"]), nil
+}
+
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(s, "\n")
+	return line
+}
@@ -0,0 +1,45 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+func TestWithProvenance(t *testing.T) {
+	metadata := map[string]interface{}{"topic": "deploy"}
+	if got := withProvenance(context.Background(), metadata); len(got) != 1 {
+		t.Errorf("withProvenance() without provenance = %v, want metadata unchanged", got)
+	}
+
+	confidence := 0.9
+	ctx := WithProvenance(context.Background(), Provenance{SourceTool: "save_fact", Model: "m1", Confidence: &confidence})
+	got := withProvenance(ctx, metadata)
+	if _, ok := metadata[ProvenanceMetadataKey]; ok {
+		t.Error("withProvenance() modified the metadata it was given")
+	}
+	if c, ok := MetadataConfidence(got); !ok || c != 0.9 {
+		t.Errorf("MetadataConfidence() = %v, %v; want 0.9", c, ok)
+	}
+	p, ok := ProvenanceFromMap(got[ProvenanceMetadataKey])
+	if !ok || p.SourceTool != "save_fact" || p.Model != "m1" || p.ConversationID != "" {
+		t.Errorf("ProvenanceFromMap() = %+v, %v", p, ok)
+	}
+
+	kept := map[string]interface{}{ProvenanceMetadataKey: map[string]interface{}{"source_tool": "import"}}
+	if p, _ := ProvenanceFromMap(withProvenance(ctx, kept)[ProvenanceMetadataKey]); p.SourceTool != "import" {
+		t.Errorf("withProvenance() replaced an existing provenance with %+v", p)
+	}
+}
+
+func TestProvenanceFromMapNumbers(t *testing.T) {
+	for _, v := range []interface{}{0.5, float32(0.5), json.Number("0.5")} {
+		p, ok := ProvenanceFromMap(map[string]interface{}{"confidence": v})
+		if !ok || p.Confidence == nil || *p.Confidence != 0.5 {
+			t.Errorf("ProvenanceFromMap(%T) = %+v, %v; want confidence 0.5", v, p, ok)
+		}
+	}
+	if _, ok := ProvenanceFromMap(map[string]interface{}{}); ok {
+		t.Error("ProvenanceFromMap() of an empty object reported a provenance")
+	}
+}
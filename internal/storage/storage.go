@@ -3,6 +3,13 @@ package storage
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"syscall"
 	"time"
 
 	"github.com/madeindigio/remembrances-mcp/pkg/treesitter"
@@ -26,30 +33,100 @@ type Storage interface {
 	UpdateFact(ctx context.Context, userID, key string, value interface{}) error
 	DeleteFact(ctx context.Context, userID, key string) error
 	ListFacts(ctx context.Context, userID string) (map[string]interface{}, error)
+	ListFactProvenance(ctx context.Context, userID string) (map[string]Provenance, error)
+	GetFactHistory(ctx context.Context, userID, key string) ([]FactVersion, error)
+	ListFactsAsOf(ctx context.Context, userID string, at time.Time) ([]FactVersion, error)
 	ListFactKeys(ctx context.Context, userID string) ([]string, error)
 	ListUserIDs(ctx context.Context, table string) ([]string, error)
 
 	// Vector operations for semantic/RAG storage
 	IndexVector(ctx context.Context, userID, content string, embedding []float32, metadata map[string]interface{}) error
 	SearchSimilar(ctx context.Context, userID string, queryEmbedding []float32, limit int) ([]VectorResult, error)
-	UpdateVector(ctx context.Context, id, userID, content string, embedding []float32, metadata map[string]interface{}) error
+	SearchSimilarWithOptions(ctx context.Context, userID string, queryEmbedding []float32, opts VectorSearchOptions) ([]VectorResult, error)
+	UpdateVector(ctx context.Context, id, userID, content string, embedding []float32, metadata map[string]interface{}, expectedRevision int) (int, error)
 	DeleteVector(ctx context.Context, id, userID string) error
+	MergeVectorMetadata(ctx context.Context, id string, metadata map[string]interface{}) error
+	ListVectorMemories(ctx context.Context, userID string, limit int) ([]VectorMemory, error)
+	ConsolidateVectors(ctx context.Context, userID, summary string, embedding []float32, metadata map[string]interface{}, ids []string) (string, error)
 
 	// Graph operations for entities and relationships
 	CreateEntity(ctx context.Context, entityType, name string, properties map[string]interface{}) error
 	CreateRelationship(ctx context.Context, fromEntity, toEntity, relationshipType string, properties map[string]interface{}) error
 	TraverseGraph(ctx context.Context, startEntity, relationshipType string, depth int) ([]GraphResult, error)
+	TraverseGraphAsOf(ctx context.Context, startEntity, relationshipType string, depth int, at time.Time) ([]GraphResult, error)
+	EndRelationships(ctx context.Context, fromEntity, toEntity, relationshipType string, at time.Time) (int, error)
+	ListRelationships(ctx context.Context, filter RelationshipFilter) ([]Relationship, int, error)
+	GetGraphStats(ctx context.Context, top int) (*GraphStats, error)
+	FindPaths(ctx context.Context, fromEntity, toEntity string, maxHops, limit int) ([]GraphPath, error)
+	ListEntities(ctx context.Context, entityType string, limit int) ([]Entity, error)
+	MergeEntities(ctx context.Context, survivor string, duplicates []string) (*EntityMerge, error)
+	UpdateEntity(ctx context.Context, entity string, properties map[string]interface{}, replace bool) (*Entity, error)
+	SaveEntityType(ctx context.Context, entityType EntityType) error
+	GetEntityType(ctx context.Context, name string) (*EntityType, error)
+	ListEntityTypes(ctx context.Context) ([]EntityType, error)
+	DeleteEntityType(ctx context.Context, name string) (bool, error)
 	GetEntity(ctx context.Context, entityID string) (*Entity, error)
 	DeleteEntity(ctx context.Context, entityID string) error
 	ListEntityIDs(ctx context.Context) ([]string, error)
+	SaveNoteGraph(ctx context.Context, note NoteGraph) error
+	DeleteNoteGraph(ctx context.Context, name string) error
 
 	// Knowledge base operations for markdown documents
 	SaveDocument(ctx context.Context, filePath, content string, embedding []float32, metadata map[string]interface{}) error
 	SaveDocumentChunks(ctx context.Context, filePath string, chunks []string, embeddings [][]float32, metadata map[string]interface{}) error
 	SearchDocuments(ctx context.Context, queryEmbedding []float32, limit int) ([]DocumentResult, error)
+	SearchDocumentsWithOptions(ctx context.Context, queryEmbedding []float32, opts VectorSearchOptions) ([]DocumentResult, error)
 	DeleteDocument(ctx context.Context, filePath string) error
+	MergeDocumentMetadata(ctx context.Context, filePath string, metadata map[string]interface{}) error
 	GetDocument(ctx context.Context, filePath string) (*Document, error)
+	GetDocumentContent(ctx context.Context, filePath string) (string, error)
+	GetDocumentChunkContext(ctx context.Context, filePath string, indexes []int, radius int) (map[int]string, error)
 	ListDocumentPaths(ctx context.Context) ([]string, error)
+	ListDocuments(ctx context.Context) ([]Document, error)
+	GetDocumentHistory(ctx context.Context, filePath string) ([]DocumentVersion, error)
+	GetDocumentVersion(ctx context.Context, filePath string, version int) (*DocumentVersion, error)
+	SearchDocumentVersions(ctx context.Context, queryEmbedding []float32, opts VectorSearchOptions) ([]DocumentResult, error)
+
+	// Trash holding facts, vectors, documents and entities removed by the Delete* methods
+	ListTrash(ctx context.Context, userID, kind string, limit int) ([]TrashItem, error)
+	RestoreFromTrash(ctx context.Context, trashID string) (*TrashItem, error)
+	PurgeTrash(ctx context.Context, before time.Time) (int, error)
+
+	// User management across every table keyed by user_id
+	ListUsers(ctx context.Context) ([]UserSummary, error)
+	DeleteUser(ctx context.Context, userID string) (map[string]int, error)
+	RenameUser(ctx context.Context, oldUserID, newUserID string) (map[string]int, error)
+	ExportUser(ctx context.Context, userID string) (*UserExport, error)
+	PurgeUser(ctx context.Context, userID, archivePath string) (map[string]int, error)
+
+	// Retrieval hits of facts, vectors and documents, for usage reports
+	RecordMemoryHits(ctx context.Context, hits []MemoryHit) error
+	CountMemoryHits(ctx context.Context, userID string, since time.Time) ([]MemoryHitCount, error)
+
+	// Results of write tool calls keyed by the idempotency key sent with them.
+	// Results saved before since are ignored; saving drops those saved before expireBefore.
+	GetIdempotencyResult(ctx context.Context, tool, key string, since time.Time) (string, bool, error)
+	SaveIdempotencyResult(ctx context.Context, tool, key, result string, expireBefore time.Time) error
+
+	// Search tool calls saved by users under a name
+	SaveSearch(ctx context.Context, search SavedSearch) error
+	GetSavedSearch(ctx context.Context, userID, name string) (*SavedSearch, error)
+	ListSavedSearches(ctx context.Context, userID string) ([]SavedSearch, error)
+	DeleteSavedSearch(ctx context.Context, userID, name string) (bool, error)
+	RecordSavedSearchRun(ctx context.Context, userID, name string, runAt time.Time, results []string, change *SearchChange) error
+	ListSearchChanges(ctx context.Context, userID, name string, since time.Time, limit int) ([]SearchChange, error)
+	PurgeSearchChanges(ctx context.Context, before time.Time) (int, error)
+
+	// Pins protecting facts, vectors and documents from deletion. Deleting a
+	// pinned item fails with ErrPinned unless ctx comes from WithForce.
+	SetPinned(ctx context.Context, kind, userID, ref string, pinned bool) (bool, error)
+	ListPinned(ctx context.Context, userID, kind string) ([]PinnedItem, error)
+
+	// Change notifications of a table, until ctx is done
+	WatchChanges(ctx context.Context, table, userID string) (<-chan ChangeEvent, error)
+
+	// Batch writes applied atomically in a single transaction
+	ExecuteBatch(ctx context.Context, ops []BatchOperation) ([]BatchResult, error)
 
 	// Hybrid search combining vector, key-value, and graph queries
 	HybridSearch(ctx context.Context, userID string, queryEmbedding []float32, entities []string, limit int) (*HybridSearchResult, error)
@@ -61,17 +138,557 @@ type Storage interface {
 	GetEventsBySubject(ctx context.Context, userID, subject string, limit int) ([]Event, error)
 }
 
+// Search accuracy modes for the SurrealQL KNN operator
+const (
+	// SearchAccuracyApproximate uses the vector index (default)
+	SearchAccuracyApproximate = "approximate"
+	// SearchAccuracyExact bypasses the index and performs a brute force cosine scan
+	SearchAccuracyExact = "exact"
+)
+
+// VectorSearchOptions tunes a nearest-neighbour search
+type VectorSearchOptions struct {
+	Limit         int      // Number of neighbours (K)
+	EfSearch      int      // Optional: candidate list size for HNSW indexes (Postgres only)
+	Accuracy      string   // Optional: "approximate" (default) or "exact"
+	MinSimilarity float64  // Optional: drop results below this cosine similarity
+	MinConfidence float64  // Optional: only memories whose provenance has at least this confidence
+	KBRoots       []string // Optional: only knowledge base documents from these roots
+	Frontmatter   FrontmatterFilter
+}
+
+// FrontmatterFilter narrows knowledge base searches to documents whose
+// frontmatter fields, stored in metadata, match. Empty fields match any
+// document.
+type FrontmatterFilter struct {
+	Tags     []string // Documents having every tag, lowercase without "#"
+	Title    string   // Case-insensitive substring of the title
+	Alias    string   // One of the aliases
+	DateFrom string   // Documents dated on or after this YYYY-MM-DD date
+	DateTo   string   // Documents dated on or before this YYYY-MM-DD date
+}
+
+// IsZero reports whether the filter matches every document.
+func (f FrontmatterFilter) IsZero() bool {
+	return len(f.Tags) == 0 && f.Title == "" && f.Alias == "" && f.DateFrom == "" && f.DateTo == ""
+}
+
+// DefaultKBRoot is the root label of knowledge base documents synced from the
+// --knowledge-base directory, and of documents stored without a root label
+const DefaultKBRoot = "default"
+
 // VectorResult represents a result from vector similarity search
 type VectorResult struct {
 	ID         string                 `json:"id"`
 	UserID     *string                `json:"user_id,omitempty"`
 	Content    string                 `json:"content"`
 	Similarity float64                `json:"similarity"`
+	Score      float64                `json:"score"` // Similarity, or its normalization when score-normalization is set
 	Metadata   map[string]interface{} `json:"metadata"`
+	Revision   int                    `json:"revision"`
 	CreatedAt  time.Time              `json:"created_at"`
 	UpdatedAt  time.Time              `json:"updated_at"`
 }
 
+// ErrNotFound is wrapped by the errors returned for records that do not exist
+var ErrNotFound = errors.New("not found")
+
+// ErrPinned is wrapped by the errors returned when deleting a pinned fact,
+// vector or document without WithForce
+var ErrPinned = errors.New("pinned")
+
+// forceKey marks a context whose deletions override pins.
+type forceKey struct{}
+
+// WithForce returns a context whose Delete* and ConsolidateVectors calls
+// delete pinned items too, dropping their pins.
+func WithForce(ctx context.Context) context.Context {
+	return context.WithValue(ctx, forceKey{}, true)
+}
+
+// forced reports whether ctx comes from WithForce.
+func forced(ctx context.Context) bool {
+	force, _ := ctx.Value(forceKey{}).(bool)
+	return force
+}
+
+// pinnable reports whether items of a trash kind can be pinned.
+func pinnable(kind string) bool {
+	return kind == TrashKindFact || kind == TrashKindVector || kind == TrashKindDocument
+}
+
+// pinnedError reports that a pinned item was not deleted.
+func pinnedError(kind, ref string) error {
+	return fmt.Errorf("%w: %s %q is pinned; unpin it or pass force to delete it", ErrPinned, kind, ref)
+}
+
+// ProvenanceMetadataKey is the metadata field holding the Provenance of
+// vectors, documents and events. Facts keep theirs in a provenance field.
+const ProvenanceMetadataKey = "provenance"
+
+// Provenance records where a memory came from: the tool that wrote it, the
+// conversation and model behind the call, and how confident the writer was,
+// from 0 to 1.
+type Provenance struct {
+	SourceTool     string   `json:"source_tool,omitempty"`
+	ConversationID string   `json:"conversation_id,omitempty"`
+	Model          string   `json:"model,omitempty"`
+	Confidence     *float64 `json:"confidence,omitempty"`
+}
+
+// provenanceKey carries the Provenance of the writes made with a context.
+type provenanceKey struct{}
+
+// WithProvenance returns a context whose fact, vector, document and event
+// writes record p.
+func WithProvenance(ctx context.Context, p Provenance) context.Context {
+	return context.WithValue(ctx, provenanceKey{}, p)
+}
+
+// ProvenanceFromContext returns the provenance set by WithProvenance.
+func ProvenanceFromContext(ctx context.Context) (Provenance, bool) {
+	p, ok := ctx.Value(provenanceKey{}).(Provenance)
+	return p, ok
+}
+
+// asMap returns the provenance as stored: only the fields that are set.
+func (p Provenance) asMap() map[string]interface{} {
+	m := map[string]interface{}{}
+	if p.SourceTool != "" {
+		m["source_tool"] = p.SourceTool
+	}
+	if p.ConversationID != "" {
+		m["conversation_id"] = p.ConversationID
+	}
+	if p.Model != "" {
+		m["model"] = p.Model
+	}
+	if p.Confidence != nil {
+		m["confidence"] = *p.Confidence
+	}
+	return m
+}
+
+// ProvenanceFromMap reads a provenance stored by a write, such as the
+// ProvenanceMetadataKey field of metadata.
+func ProvenanceFromMap(v interface{}) (Provenance, bool) {
+	m, ok := v.(map[string]interface{})
+	if !ok || len(m) == 0 {
+		return Provenance{}, false
+	}
+	p := Provenance{
+		SourceTool:     getString(m, "source_tool"),
+		ConversationID: getString(m, "conversation_id"),
+		Model:          getString(m, "model"),
+	}
+	if confidence, ok := numberValue(m["confidence"]); ok {
+		p.Confidence = &confidence
+	}
+	return p, true
+}
+
+// MetadataConfidence returns the confidence of the provenance stored in
+// metadata, if it has one.
+func MetadataConfidence(metadata map[string]interface{}) (float64, bool) {
+	p, ok := ProvenanceFromMap(metadata[ProvenanceMetadataKey])
+	if !ok || p.Confidence == nil {
+		return 0, false
+	}
+	return *p.Confidence, true
+}
+
+// withProvenance returns metadata with the provenance of ctx added under
+// ProvenanceMetadataKey. Metadata that already has a provenance, and writes
+// without one, keep metadata as is.
+func withProvenance(ctx context.Context, metadata map[string]interface{}) map[string]interface{} {
+	p, ok := ProvenanceFromContext(ctx)
+	if !ok {
+		return metadata
+	}
+	if _, exists := metadata[ProvenanceMetadataKey]; exists {
+		return metadata
+	}
+	out := make(map[string]interface{}, len(metadata)+1)
+	for k, v := range metadata {
+		out[k] = v
+	}
+	out[ProvenanceMetadataKey] = p.asMap()
+	return out
+}
+
+// factProvenance returns the provenance of ctx as stored with a fact. Facts
+// written without one store an empty object.
+func factProvenance(ctx context.Context) map[string]interface{} {
+	p, _ := ProvenanceFromContext(ctx)
+	return p.asMap()
+}
+
+// numberValue converts a number decoded from either database to a float64.
+func numberValue(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// Validity is the interval over which a fact or relationship holds. A nil
+// From means from the time it is written, a nil Until that it still holds.
+type Validity struct {
+	From  *time.Time
+	Until *time.Time
+}
+
+// validityKey carries the Validity of the writes made with a context.
+type validityKey struct{}
+
+// WithValidity returns a context whose SaveFact, UpdateFact and
+// CreateRelationship calls write v instead of holding from now on.
+func WithValidity(ctx context.Context, v Validity) context.Context {
+	return context.WithValue(ctx, validityKey{}, v)
+}
+
+// validityFromContext returns the validity set by WithValidity, with From
+// defaulting to now.
+func validityFromContext(ctx context.Context) (from time.Time, until *time.Time, explicit bool) {
+	v, _ := ctx.Value(validityKey{}).(Validity)
+	from = time.Now().UTC()
+	if v.From != nil {
+		from = v.From.UTC()
+	}
+	if v.Until != nil {
+		u := v.Until.UTC()
+		until = &u
+	}
+	return from, until, v.From != nil
+}
+
+// FactVersion is a value a fact held over an interval. Updating or deleting
+// a fact keeps its value as a past version ending when it was replaced.
+type FactVersion struct {
+	Key        string      `json:"key"`
+	Value      interface{} `json:"value"`
+	ValidFrom  time.Time   `json:"valid_from"`
+	ValidUntil *time.Time  `json:"valid_until,omitempty"`
+	Provenance *Provenance `json:"provenance,omitempty"`
+	Current    bool        `json:"current"`
+}
+
+// HoldsAt reports whether the version was valid at t.
+func (v FactVersion) HoldsAt(t time.Time) bool {
+	return !t.Before(v.ValidFrom) && (v.ValidUntil == nil || t.Before(*v.ValidUntil))
+}
+
+// FactAt returns the version valid at t, the latest to start when several
+// are, or nil when none is.
+func FactAt(versions []FactVersion, t time.Time) *FactVersion {
+	var found *FactVersion
+	for i := range versions {
+		v := &versions[i]
+		if !v.HoldsAt(t) {
+			continue
+		}
+		if found == nil || v.ValidFrom.After(found.ValidFrom) || (v.ValidFrom.Equal(found.ValidFrom) && v.Current) {
+			found = v
+		}
+	}
+	return found
+}
+
+// factsAsOf returns the versions of the facts of versions valid at t, sorted
+// by key.
+func factsAsOf(versions []FactVersion, t time.Time) []FactVersion {
+	byKey := map[string][]FactVersion{}
+	for _, v := range versions {
+		byKey[v.Key] = append(byKey[v.Key], v)
+	}
+	facts := []FactVersion{}
+	for _, kv := range byKey {
+		if v := FactAt(kv, t); v != nil {
+			facts = append(facts, *v)
+		}
+	}
+	sort.Slice(facts, func(i, j int) bool { return facts[i].Key < facts[j].Key })
+	return facts
+}
+
+// sortFactVersions orders versions newest first, the current one first
+// among those starting together.
+func sortFactVersions(versions []FactVersion) {
+	sort.SliceStable(versions, func(i, j int) bool {
+		if !versions[i].ValidFrom.Equal(versions[j].ValidFrom) {
+			return versions[i].ValidFrom.After(versions[j].ValidFrom)
+		}
+		return versions[i].Current && !versions[j].Current
+	})
+}
+
+// sameFactValue reports whether two fact values are equal once encoded, so
+// that numbers decoded by either database compare equal to those written.
+func sameFactValue(a, b interface{}) bool {
+	ja, errA := json.Marshal(a)
+	jb, errB := json.Marshal(b)
+	return errA == nil && errB == nil && string(ja) == string(jb)
+}
+
+// factVersionFromRow decodes a row of kv_memories, the current version of a
+// fact, or of kv_memory_history. Facts saved before they had a validity
+// interval hold from their creation.
+func factVersionFromRow(row map[string]interface{}, current bool) FactVersion {
+	v := FactVersion{
+		Key:       getString(row, "key"),
+		Value:     row["value"],
+		ValidFrom: getTime(row, "valid_from"),
+		Current:   current,
+	}
+	if v.ValidFrom.IsZero() {
+		v.ValidFrom = getTime(row, "created_at")
+	}
+	if until := getTime(row, "valid_until"); !until.IsZero() {
+		v.ValidUntil = &until
+	}
+	if p, ok := ProvenanceFromMap(row["provenance"]); ok {
+		v.Provenance = &p
+	}
+	return v
+}
+
+// ErrUnavailable is wrapped by the errors returned when the database is not
+// connected or cannot be reached
+var ErrUnavailable = errors.New("storage unavailable")
+
+// unavailable wraps connection failures with ErrUnavailable so callers can
+// tell them apart from failing queries.
+func unavailable(err error) error {
+	var netErr net.Error
+	if err == nil || errors.Is(err, ErrUnavailable) {
+		return err
+	}
+	if errors.As(err, &netErr) || errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return fmt.Errorf("%w: %w", ErrUnavailable, err)
+	}
+	return err
+}
+
+// RevisionConflictError is returned when an update supplies a revision that no
+// longer matches the stored record, meaning it was changed concurrently
+type RevisionConflictError struct {
+	ID       string
+	Expected int
+	Current  int
+}
+
+func (e *RevisionConflictError) Error() string {
+	return fmt.Sprintf("revision conflict on %s: expected revision %d, current revision is %d", e.ID, e.Expected, e.Current)
+}
+
+// VectorMemory is a stored vector memory including its embedding
+type VectorMemory struct {
+	ID        string                 `json:"id"`
+	Content   string                 `json:"content"`
+	Embedding []float32              `json:"embedding,omitempty"`
+	Metadata  map[string]interface{} `json:"metadata"`
+	CreatedAt time.Time              `json:"created_at"`
+}
+
+// UserSummary lists how many records a user_id owns in each memory layer
+type UserSummary struct {
+	UserID      string `json:"user_id"`
+	FactCount   int    `json:"fact_count"`
+	VectorCount int    `json:"vector_count"`
+	EventCount  int    `json:"event_count"`
+	EntityCount int    `json:"entity_count"`
+}
+
+// UserExport holds every record owned by a user, grouped by table. Embeddings
+// are left out since they are derived from the exported content
+type UserExport struct {
+	UserID     string                              `json:"user_id"`
+	ExportedAt time.Time                           `json:"exported_at"`
+	Tables     map[string][]map[string]interface{} `json:"tables"`
+}
+
+// Operation kinds accepted by ExecuteBatch
+const (
+	BatchOpSaveFact           = "save_fact"
+	BatchOpAddVector          = "add_vector"
+	BatchOpCreateEntity       = "create_entity"
+	BatchOpCreateRelationship = "create_relationship"
+)
+
+// BatchOperation is a single write in a batch. Only the fields used by Op are read:
+// save_fact uses UserID, Key and Value; add_vector uses UserID, Content, Embedding
+// and Metadata; create_entity uses EntityType, Name and Properties; and
+// create_relationship uses From, To, RelationshipType and Properties
+type BatchOperation struct {
+	Op               string
+	UserID           string
+	Key              string
+	Value            interface{}
+	Content          string
+	Embedding        []float32
+	Metadata         map[string]interface{}
+	EntityType       string
+	Name             string
+	Properties       map[string]interface{}
+	From             string
+	To               string
+	RelationshipType string
+}
+
+// BatchResult reports the record written by one operation of a committed batch
+type BatchResult struct {
+	Index int    `json:"index"`
+	Op    string `json:"op"`
+	ID    string `json:"id"`
+}
+
+// BatchError is returned when a batch transaction is rolled back. Index is the
+// operation that failed, or -1 when the failure cannot be attributed to one
+type BatchError struct {
+	Index   int
+	Message string
+}
+
+func (e *BatchError) Error() string {
+	if e.Index < 0 {
+		return "batch rolled back: " + e.Message
+	}
+	return fmt.Sprintf("batch rolled back: operation %d failed: %s", e.Index, e.Message)
+}
+
+// EmbeddingDimensionError is returned by write operations when an embedding
+// does not have the schema dimension and strict dimension checking is enabled.
+type EmbeddingDimensionError struct {
+	Table    string
+	Expected int
+	Got      int
+	Model    string
+}
+
+func (e *EmbeddingDimensionError) Error() string {
+	model := e.Model
+	if model == "" {
+		model = "unknown"
+	}
+	return fmt.Sprintf("embedding for %s has %d dimensions but the schema expects %d (configured model: %s); use a model that produces %d-dimensional embeddings",
+		e.Table, e.Got, e.Expected, model, e.Expected)
+}
+
+// Kinds of soft-deleted items kept in the trash
+const (
+	TrashKindFact     = "fact"
+	TrashKindVector   = "vector"
+	TrashKindDocument = "document"
+	TrashKindEntity   = "entity"
+)
+
+// MemoryHit records that a retrieval tool returned a fact, vector or document.
+// Kind is one of the TrashKind* values; Ref is the fact key, the vector record
+// ID or the document file path. Documents are shared, so their hits may have
+// no UserID.
+type MemoryHit struct {
+	Kind   string `json:"kind"`
+	Ref    string `json:"ref"`
+	UserID string `json:"user_id"`
+	Tool   string `json:"tool"`
+}
+
+// PinnedItem is a fact, vector or document protected from deletion. Kind is
+// one of TrashKindFact, TrashKindVector or TrashKindDocument and Ref the fact
+// key, the vector record ID or the document file path. Documents are shared,
+// so their pins have no UserID.
+type PinnedItem struct {
+	Kind     string    `json:"kind"`
+	Ref      string    `json:"ref"`
+	UserID   string    `json:"user_id,omitempty"`
+	PinnedAt time.Time `json:"pinned_at"`
+}
+
+// SavedSearch is a search tool call a user saved under a name, with the
+// arguments to call it with. Searches with an IntervalSeconds are run on that
+// schedule; LastResults are the refs their last run returned.
+type SavedSearch struct {
+	UserID          string                 `json:"user_id"`
+	Name            string                 `json:"name"`
+	Tool            string                 `json:"tool"`
+	Arguments       map[string]interface{} `json:"arguments"`
+	Description     string                 `json:"description,omitempty"`
+	IntervalSeconds int                    `json:"interval_seconds,omitempty"`
+	LastRunAt       *time.Time             `json:"last_run_at,omitempty"`
+	LastResults     []string               `json:"-"`
+	CreatedAt       time.Time              `json:"created_at"`
+	UpdatedAt       time.Time              `json:"updated_at"`
+}
+
+// SearchChange lists the results a scheduled run of a saved search returned
+// that its previous run had not.
+type SearchChange struct {
+	UserID string             `json:"user_id"`
+	Name   string             `json:"name"`
+	Tool   string             `json:"tool"`
+	RunAt  time.Time          `json:"run_at"`
+	Items  []SearchChangeItem `json:"items"`
+}
+
+// SearchChangeItem is a search result identified by its kind and ref: a
+// document chunk path, a vector, event or entity ID, or a fact key.
+type SearchChangeItem struct {
+	Kind    string  `json:"kind"`
+	Ref     string  `json:"ref"`
+	Preview string  `json:"preview,omitempty"`
+	Score   float64 `json:"score,omitempty"`
+}
+
+// MemoryHitCount is how many times a memory was retrieved since a point in time
+type MemoryHitCount struct {
+	Kind    string    `json:"kind"`
+	Ref     string    `json:"ref"`
+	Hits    int       `json:"hits"`
+	LastHit time.Time `json:"last_hit"`
+}
+
+// Change actions reported by WatchChanges
+const (
+	ChangeCreate = "CREATE"
+	ChangeUpdate = "UPDATE"
+	ChangeDelete = "DELETE"
+)
+
+// ChangeEvent is a record created, updated or deleted in a watched table.
+// Record holds the record without its embedding, as it was before the change
+// for deletes; polled deletes only know the ID.
+type ChangeEvent struct {
+	Action string                 `json:"action"`
+	Table  string                 `json:"table"`
+	ID     string                 `json:"id"`
+	Record map[string]interface{} `json:"record,omitempty"`
+	At     time.Time              `json:"at"`
+}
+
+// TrashItem is a soft-deleted fact, vector, document or entity that can be
+// restored until the trash retention window purges it
+type TrashItem struct {
+	ID          string    `json:"id"`
+	Kind        string    `json:"kind"`
+	UserID      string    `json:"user_id,omitempty"`
+	Label       string    `json:"label"`
+	Content     string    `json:"content,omitempty"`
+	RecordCount int       `json:"record_count"`
+	DeletedAt   time.Time `json:"deleted_at"`
+}
+
 // Entity represents a graph node
 type Entity struct {
 	ID         string                 `json:"id"`
@@ -92,6 +709,85 @@ type Relationship struct {
 	Type       string                 `json:"type"`
 	Properties map[string]interface{} `json:"properties"`
 	Timestamp  time.Time              `json:"timestamp"`
+	ValidFrom  *time.Time             `json:"valid_from,omitempty"`
+	ValidUntil *time.Time             `json:"valid_until,omitempty"`
+	FromName   string                 `json:"from_name,omitempty"`
+	ToName     string                 `json:"to_name,omitempty"`
+}
+
+// Directions of the relationships of an entity listed by ListRelationships
+const (
+	RelationshipsOutgoing = "out"
+	RelationshipsIncoming = "in"
+	RelationshipsBoth     = "both"
+)
+
+// RelationshipFilter selects the relationships ListRelationships returns.
+// Entity is a name or ID; an empty Entity or Type matches any. Limit and
+// Offset page through the matches, newest first.
+type RelationshipFilter struct {
+	Entity       string
+	Type         string
+	Direction    string
+	IncludeEnded bool
+	Limit        int
+	Offset       int
+}
+
+// GraphStats describes the shape of the knowledge graph. Relationships that
+// were ended are not counted.
+type GraphStats struct {
+	EntityCount         int            `json:"entity_count"`
+	RelationshipCount   int            `json:"relationship_count"`
+	EntitiesByType      map[string]int `json:"entities_by_type"`
+	RelationshipsByType map[string]int `json:"relationships_by_type"`
+	TopEntities         []EntityDegree `json:"top_entities"`
+}
+
+// EntityDegree is an entity with the number of relationships it takes part
+// in, in either direction.
+type EntityDegree struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Type   string `json:"type"`
+	Degree int    `json:"degree"`
+}
+
+// topEntityDegrees returns the top entities of degrees, highest degree
+// first, then by name.
+func topEntityDegrees(degrees []EntityDegree, top int) []EntityDegree {
+	sort.Slice(degrees, func(i, j int) bool {
+		if degrees[i].Degree != degrees[j].Degree {
+			return degrees[i].Degree > degrees[j].Degree
+		}
+		return degrees[i].Name < degrees[j].Name
+	})
+	if top >= 0 && len(degrees) > top {
+		degrees = degrees[:top]
+	}
+	return degrees
+}
+
+// Entity and relationship types of the graph built from knowledge base notes
+const (
+	NoteEntityType       = "note"
+	NoteLinkRelationship = "links_to"
+)
+
+// NoteGraph is a knowledge base note and the notes it links to. Notes are
+// stored as entities of type NoteEntityType named after the note, and links
+// as NoteLinkRelationship relationships.
+type NoteGraph struct {
+	Name       string
+	Properties map[string]interface{}
+	Links      []NoteLink
+}
+
+// NoteLink is a link from a note to another one, by name.
+type NoteLink struct {
+	Target  string `json:"target"`
+	Heading string `json:"heading,omitempty"`
+	Alias   string `json:"alias,omitempty"`
 }
 
 // GraphResult represents a result from graph traversal
@@ -114,11 +810,24 @@ type Document struct {
 	UpdatedAt time.Time              `json:"updated_at"`
 }
 
+// DocumentVersion is an archived revision of a knowledge base document,
+// kept when the document at the same file path is re-saved
+type DocumentVersion struct {
+	ID         string                 `json:"id,omitempty"`
+	FilePath   string                 `json:"file_path"`
+	Version    int                    `json:"version"`
+	Content    string                 `json:"content"`
+	Diff       string                 `json:"diff"` // Line diff from this version to the one that replaced it
+	ChunkCount int                    `json:"chunk_count"`
+	Metadata   map[string]interface{} `json:"metadata"`
+	ArchivedAt time.Time              `json:"archived_at"`
+}
+
 // DocumentResult represents a result from document search
 type DocumentResult struct {
 	Document   *Document `json:"document"`
 	Similarity float64   `json:"similarity"`
-	Score      float64   `json:"score"`
+	Score      float64   `json:"score"` // Similarity, or its normalization when score-normalization is set
 }
 
 // HybridSearchResult combines results from multiple search types
@@ -146,6 +855,28 @@ type ConnectionConfig struct {
 	Namespace string        `json:"namespace"`
 	Database  string        `json:"database"`
 	Timeout   time.Duration `json:"timeout"`
+	// QueryTimeout bounds each read query; 0 disables it. Embedded reads that
+	// outlive it keep running in the background, but the caller gets an error
+	// wrapping ErrUnavailable. Writes are not bounded, so a write never
+	// commits after its caller was told it failed.
+	QueryTimeout time.Duration `json:"query_timeout"`
+
+	// EmbeddingFormat selects how embeddings are persisted (float32, float16 or int8)
+	EmbeddingFormat EmbeddingFormat `json:"embedding_format"`
+
+	// StrictEmbeddingDimension rejects embeddings whose length is not the
+	// schema dimension instead of padding or truncating them
+	StrictEmbeddingDimension bool `json:"strict_embedding_dimension"`
+	// EmbeddingModel names the configured embedding model. It is recorded with
+	// every embedded record, and searches skip records embedded by another
+	// model. It also names the model in dimension errors.
+	EmbeddingModel string `json:"embedding_model"`
+	// CodeEmbeddingModel names the model embedding code symbols and chunks
+	// when it differs from EmbeddingModel
+	CodeEmbeddingModel string `json:"code_embedding_model"`
+	// TableEmbeddingModels names the model of the tables routed to another
+	// embedder, overriding EmbeddingModel and CodeEmbeddingModel
+	TableEmbeddingModels map[string]string `json:"table_embedding_models,omitempty"`
 }
 
 // MemoryStats provides statistics about stored memories
@@ -159,10 +890,104 @@ type MemoryStats struct {
 	TotalSize         int64 `json:"total_size_bytes"`
 }
 
+// DatabaseInfo describes the database behind the storage
+type DatabaseInfo struct {
+	Mode                string        `json:"mode"`     // "embedded" or "remote"
+	Location            string        `json:"location"` // Embedded DB path or remote URL without credentials
+	SizeBytes           int64         `json:"size_bytes,omitempty"`
+	SchemaVersion       int           `json:"schema_version"`
+	LatestSchemaVersion int           `json:"latest_schema_version"`
+	Indexes             []IndexHealth `json:"indexes"`
+}
+
+// IndexHealth tells whether a vector index is defined on its table
+type IndexHealth struct {
+	Table   string `json:"table"`
+	Index   string `json:"index"`
+	Defined bool   `json:"defined"`
+}
+
+// Checks reported by CheckIntegrity
+const (
+	IntegrityOrphanFile   = "orphan_file"   // Code files of a project that does not exist
+	IntegrityOrphanSymbol = "orphan_symbol" // Code symbols of a file that is not indexed
+	IntegrityOrphanChunk  = "orphan_chunk"  // Code chunks of a file without symbols
+	IntegrityStatsDrift   = "stats_drift"   // user_stats counter that differs from the records
+	IntegrityEmbeddingDim = "embedding_dim" // Embedding whose length is not the schema dimension
+)
+
+// IntegrityIssue is an inconsistency found by CheckIntegrity. Ref names the
+// affected record, project file, project or user_stats counter; Count is the
+// number of records involved.
+type IntegrityIssue struct {
+	Check    string `json:"check"`
+	Table    string `json:"table"`
+	Ref      string `json:"ref"`
+	Detail   string `json:"detail"`
+	Count    int    `json:"count"`
+	Repaired bool   `json:"repaired"`
+}
+
+// IntegrityReport lists the issues found by CheckIntegrity
+type IntegrityReport struct {
+	Issues   []IntegrityIssue `json:"issues"`
+	Repaired int              `json:"repaired"`
+}
+
+// IntegrityChecker is implemented by storages that can check, and optionally
+// repair, referential integrity after crashes or partial failures
+type IntegrityChecker interface {
+	CheckIntegrity(ctx context.Context, repair bool) (*IntegrityReport, error)
+}
+
+// EmbeddingModelCount counts the records of a table embedded by one model
+type EmbeddingModelCount struct {
+	Table string `json:"table"`
+	Model string `json:"model"` // "" for records embedded before models were recorded
+	Count int    `json:"count"`
+	Stale bool   `json:"stale"` // Embedded by another model than the configured one, so searches skip them
+}
+
+// EmbeddingModelReporter is implemented by storages that record the model of
+// every embedding
+type EmbeddingModelReporter interface {
+	EmbeddingModels(ctx context.Context) ([]EmbeddingModelCount, error)
+}
+
+// CompactionReport is the on-disk size of the storage files before and
+// after a compaction
+type CompactionReport struct {
+	Location   string        `json:"location"`
+	SizeBefore int64         `json:"size_before"`
+	SizeAfter  int64         `json:"size_after"`
+	Reclaimed  int64         `json:"reclaimed"`
+	Duration   time.Duration `json:"duration"`
+}
+
+// Compactor is implemented by storages whose files keep the space of deleted
+// and overwritten records until they are compacted
+type Compactor interface {
+	Compact(ctx context.Context) (*CompactionReport, error)
+}
+
+// UserStatChange is a user_stats counter corrected by RecountUserStats
+type UserStatChange struct {
+	UserID string `json:"user_id"`
+	Stat   string `json:"stat"`
+	Before int    `json:"before"`
+	After  int    `json:"after"`
+}
+
 // GetStats returns statistics about stored memories
 type StatsProvider interface {
 	GetStats(ctx context.Context, userID string) (*MemoryStats, error)
 	CountByUserID(ctx context.Context, tableName string) (map[string]int, error)
+	// GetDatabaseInfo returns the database mode, on-disk size of embedded
+	// databases, schema version and vector index health.
+	GetDatabaseInfo(ctx context.Context) (*DatabaseInfo, error)
+	// RecountUserStats recounts the records behind the user_stats counters of
+	// a user, or of every user when userID is empty, and rewrites them atomically.
+	RecountUserStats(ctx context.Context, userID string) ([]UserStatChange, error)
 }
 
 // CodeStorage provides code indexing storage operations
@@ -173,6 +998,10 @@ type CodeStorage interface {
 	ListCodeProjects(ctx context.Context) ([]CodeProject, error)
 	UpdateProjectStatus(ctx context.Context, projectID string, status treesitter.IndexingStatus) error
 	UpdateProjectWatcher(ctx context.Context, projectID string, enabled bool) error
+	UpdateCodeProjectRoot(ctx context.Context, projectID, rootPath string) error
+	SetCodeProjectAlias(ctx context.Context, projectID, alias string) error
+	GetCodeProjectByAlias(ctx context.Context, alias string) (*CodeProject, error)
+	UpdateCodeProjectSettings(ctx context.Context, projectID string, settings CodeProjectSettings) error
 	DeleteCodeProject(ctx context.Context, projectID string) error
 
 	// File operations
@@ -180,6 +1009,7 @@ type CodeStorage interface {
 	GetCodeFile(ctx context.Context, projectID, filePath string) (*CodeFile, error)
 	ListCodeFiles(ctx context.Context, projectID string) ([]CodeFile, error)
 	DeleteCodeFile(ctx context.Context, projectID, filePath string) error
+	ClearCodeFileHashes(ctx context.Context, projectID string) error
 
 	// Symbol operations
 	SaveCodeSymbol(ctx context.Context, symbol *treesitter.CodeSymbol) error
@@ -188,8 +1018,24 @@ type CodeStorage interface {
 	FindSymbolsByName(ctx context.Context, projectID, name string, symbolTypes []treesitter.SymbolType, limit int) ([]CodeSymbol, error)
 	FindSymbolsByFile(ctx context.Context, projectID, filePath string) ([]CodeSymbol, error)
 	FindChildSymbols(ctx context.Context, projectID, parentID string) ([]CodeSymbol, error)
+	ListCodeSymbols(ctx context.Context, projectID string) ([]CodeSymbol, error)
+	ListCodeSymbolsByName(ctx context.Context, projectID string, filter CodeSymbolNameFilter) ([]CodeSymbol, error)
+	ListUnembeddedCodeSymbols(ctx context.Context, projectID string) ([]CodeSymbol, error)
 	SearchSymbolsBySimilarity(ctx context.Context, projectID string, queryEmbedding []float32, symbolTypes []treesitter.SymbolType, limit int) ([]CodeSymbolSearchResult, error)
+	SearchSymbolDocs(ctx context.Context, projectID, query string, symbolTypes []treesitter.SymbolType, limit int) ([]CodeSymbolTextResult, error)
 	DeleteSymbolsByFile(ctx context.Context, projectID, filePath string) error
+	DeleteCodeSymbols(ctx context.Context, projectID, filePath string, namePaths []string) error
+
+	// Dependency operations
+	SaveCodeDependencies(ctx context.Context, projectID, filePath string, deps []CodeDependency) error
+	GetCodeDependencies(ctx context.Context, projectID, filePath string) ([]CodeDependency, error)
+	GetCodeDependents(ctx context.Context, projectID string, targets []string) ([]CodeDependency, error)
+	ListCodeDependencies(ctx context.Context, projectID string) ([]CodeDependency, error)
+
+	// Call graph operations
+	SaveCodeCalls(ctx context.Context, projectID, filePath string, calls []CodeCall) error
+	FindCodeCallers(ctx context.Context, projectID, calleeName string, limit int) ([]CodeCall, error)
+	FindCodeCallees(ctx context.Context, projectID, caller, filePath string, limit int) ([]CodeCall, error)
 
 	// Indexing job operations
 	CreateIndexingJob(ctx context.Context, job *treesitter.IndexingJob) (string, error)
@@ -204,6 +1050,7 @@ type CodeStorage interface {
 	DeleteChunksBySymbol(ctx context.Context, symbolID string) error
 	DeleteChunksByFile(ctx context.Context, projectID, filePath string) error
 	GetChunksBySymbol(ctx context.Context, symbolID string) ([]CodeChunk, error)
+	CountCodeChunksBySymbol(ctx context.Context, projectID string) (map[string]int, error)
 	SearchChunksBySimilarity(ctx context.Context, projectID string, queryEmbedding []float32, limit int) ([]CodeChunkSearchResult, error)
 }
 
@@ -9,6 +9,7 @@ import (
 	"os"
 	"runtime"
 	"strings"
+	"sync"
 	"time"
 
 	embeddedlibs "github.com/madeindigio/remembrances-mcp/internal/embedded"
@@ -28,6 +29,17 @@ type SurrealDBStorage struct {
 
 	embeddedLoader *embeddedlibs.Loader
 	embeddedLibs   *embeddedlibs.ExtractResult
+
+	// Multi-tenant mode: connections to each tenant's namespace/database,
+	// by tenant ID. tenantID is set on those connections themselves.
+	tenantsMu sync.RWMutex
+	tenants   map[string]*SurrealDBStorage
+	tenantID  string
+
+	// While the remote server restarts, resumed is a channel closed once
+	// calls may continue (see Pause). connMu also guards swapping db.
+	connMu  sync.Mutex
+	resumed chan struct{}
 }
 
 // NewSurrealDBStorage creates a new SurrealDB storage instance
@@ -110,24 +122,13 @@ func (s *SurrealDBStorage) Connect(ctx context.Context) error {
 	} else if s.config.URL != "" {
 		// Use remote SurrealDB
 		slog.Info("Connecting to remote SurrealDB", "url", s.config.URL)
-		s.db, err = ConnectRemoteSurrealDB(ctx, s.config.URL)
+		db, err := s.dialRemote(ctx)
 		if err != nil {
-			return fmt.Errorf("failed to connect to remote SurrealDB: %w", err)
-		}
-
-		if s.config.Username != "" && s.config.Password != "" {
-			_, err = s.db.SignIn(ctx, map[string]interface{}{
-				"user": s.config.Username,
-				"pass": s.config.Password,
-			})
-			if err != nil {
-				return fmt.Errorf("failed to authenticate with SurrealDB: %w", err)
-			}
-		}
-
-		if err = s.db.Use(ctx, s.config.Namespace, s.config.Database); err != nil {
-			return fmt.Errorf("failed to use namespace/database: %w", err)
+			return err
 		}
+		s.connMu.Lock()
+		s.db = db
+		s.connMu.Unlock()
 
 		s.useEmbedded = false
 		slog.Info("Successfully connected to remote SurrealDB")
@@ -138,9 +139,35 @@ func (s *SurrealDBStorage) Connect(ctx context.Context) error {
 	return nil
 }
 
+// dialRemote opens an authenticated connection to the namespace and database
+// of the remote server.
+func (s *SurrealDBStorage) dialRemote(ctx context.Context) (*surrealdb.DB, error) {
+	db, err := ConnectRemoteSurrealDB(ctx, s.config.URL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to remote SurrealDB: %w", err)
+	}
+
+	if s.config.Username != "" && s.config.Password != "" {
+		_, err = db.SignIn(ctx, map[string]interface{}{
+			"user": s.config.Username,
+			"pass": s.config.Password,
+		})
+		if err != nil {
+			_ = db.Close(ctx)
+			return nil, fmt.Errorf("failed to authenticate with SurrealDB: %w", err)
+		}
+	}
+
+	if err = db.Use(ctx, s.config.Namespace, s.config.Database); err != nil {
+		_ = db.Close(ctx)
+		return nil, fmt.Errorf("failed to use namespace/database: %w", err)
+	}
+	return db, nil
+}
+
 // Close closes the database connection
 func (s *SurrealDBStorage) Close() error {
-	var errs []error
+	errs := s.closeTenants()
 
 	if s.useEmbedded {
 		if s.embeddedDB != nil {
@@ -149,8 +176,8 @@ func (s *SurrealDBStorage) Close() error {
 			}
 		}
 	} else {
-		if s.db != nil {
-			if err := s.db.Close(context.Background()); err != nil {
+		if db := s.conn(); db != nil {
+			if err := db.Close(context.Background()); err != nil {
 				errs = append(errs, err)
 			}
 		}
@@ -209,16 +236,29 @@ func ConnectRemoteSurrealDB(ctx context.Context, connectionURL string) (*surreal
 func (s *SurrealDBStorage) Ping(ctx context.Context) error {
 	if s.useEmbedded {
 		if s.embeddedDB == nil {
-			return fmt.Errorf("database connection not established")
+			return fmt.Errorf("database connection not established: %w", ErrUnavailable)
 		}
 		// Execute a simple query to check connection
-		_, err := s.embeddedDB.Query("SELECT 1", nil)
-		return err
+		callCtx, cancel := s.queryContext(ctx, false)
+		defer cancel()
+		_, err := callEmbedded(callCtx, func() ([]interface{}, error) {
+			return s.embeddedDB.Query("SELECT 1", nil)
+		})
+		if err != nil {
+			return s.callError(ctx, callCtx, err)
+		}
+		return nil
 	} else {
-		if s.db == nil {
-			return fmt.Errorf("database connection not established")
+		db, err := s.remoteDB(ctx)
+		if err != nil {
+			return err
+		}
+		callCtx, cancel := s.queryContext(ctx, false)
+		defer cancel()
+		_, err = surrealdb.Query[[]map[string]interface{}](callCtx, db, "SELECT 1", nil)
+		if err != nil {
+			return s.callError(ctx, callCtx, err)
 		}
-		_, err := surrealdb.Query[[]map[string]interface{}](ctx, s.db, "SELECT 1", nil)
-		return err
+		return nil
 	}
 }
@@ -0,0 +1,237 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// relationshipTableRe restricts relationship types to names that are safe to use as table identifiers.
+var relationshipTableRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// batchOpErrorRe extracts the operation index from errors thrown inside a batch transaction.
+var batchOpErrorRe = regexp.MustCompile(`batch operation (\d+):`)
+
+// ExecuteBatch applies ops inside a single transaction. Either every operation
+// is committed and one BatchResult is returned per operation, in order, or
+// nothing is written and a *BatchError describes the failure. Relationships may
+// reference entities created earlier in the same batch by name.
+func (s *SurrealDBStorage) ExecuteBatch(ctx context.Context, ops []BatchOperation) ([]BatchResult, error) {
+	if len(ops) == 0 {
+		return nil, nil
+	}
+
+	tx := newSurrealTx()
+	params := map[string]interface{}{}
+	relationshipTables := map[string]bool{}
+	// batchFacts are the facts written by earlier operations, as stored
+	batchFacts := map[string]map[string]interface{}{}
+
+	for i, op := range ops {
+		p := func(name string) string { return fmt.Sprintf("%s_%d", name, i) }
+
+		switch op.Op {
+		case BatchOpSaveFact:
+			if op.UserID == "" || op.Key == "" {
+				return nil, &BatchError{Index: i, Message: "save_fact requires user_id and key"}
+			}
+			// The replaced value is kept in kv_memory_history, as SaveFact does
+			from, until, explicit := validityFromContext(ctx)
+			factKey := op.UserID + "\x00" + op.Key
+			current, seen := batchFacts[factKey]
+			if !seen {
+				var err error
+				if current, err = s.currentFact(ctx, op.UserID, op.Key); err != nil {
+					return nil, fmt.Errorf("failed to check existing fact: %w", err)
+				}
+			}
+			if current != nil {
+				if !explicit && sameFactValue(current["value"], op.Value) {
+					from = factVersionFromRow(current, true).ValidFrom
+				} else {
+					params[p("version")] = factHistoryContent(op.UserID, op.Key, current, from)
+					tx.add(factHistoryStatement(p("version")), params)
+				}
+			}
+			batchFacts[factKey] = map[string]interface{}{"value": op.Value, "provenance": factProvenance(ctx), "valid_from": from}
+			if until != nil {
+				batchFacts[factKey]["valid_until"] = *until
+			}
+
+			params[p("user_id")] = op.UserID
+			params[p("key")] = op.Key
+			params[p("value")] = op.Value
+			params[p("provenance")] = factProvenance(ctx)
+			params[p("valid_from")] = from.Format(time.RFC3339Nano)
+			validUntil := ""
+			if until != nil {
+				params[p("valid_until")] = until.Format(time.RFC3339Nano)
+				validUntil = fmt.Sprintf(", valid_until: <datetime>$%s", p("valid_until"))
+			}
+			tx.add(fmt.Sprintf("DELETE FROM kv_memories WHERE user_id = $%s AND key = $%s", p("user_id"), p("key")), params)
+			tx.add(fmt.Sprintf("LET $r_%d = (CREATE kv_memories CONTENT { user_id: $%s, key: $%s, value: $%s, provenance: $%s, valid_from: <datetime>$%s%s } RETURN id)[0].id",
+				i, p("user_id"), p("key"), p("value"), p("provenance"), p("valid_from"), validUntil), params)
+
+		case BatchOpAddVector:
+			if op.Content == "" {
+				return nil, &BatchError{Index: i, Message: "add_vector requires content"}
+			}
+			metadata := op.Metadata
+			if metadata == nil {
+				metadata = map[string]interface{}{}
+			}
+			metadata = withProvenance(ctx, metadata)
+			embedding, err := s.fitEmbedding("vector_memories", op.Embedding)
+			if err != nil {
+				return nil, &BatchError{Index: i, Message: err.Error()}
+			}
+
+			model := map[string]interface{}{}
+			s.bindEmbeddingModel(ctx, model, "vector_memories", len(op.Embedding))
+
+			params[p("content")] = op.Content
+			params[p("embedding")] = s.storedEmbedding(embedding)
+			params[p("embedding_model")] = model["embedding_model"]
+			params[p("embedding_dim")] = model["embedding_dim"]
+			params[p("metadata")] = metadata
+			userField := ""
+			if op.UserID != "" {
+				params[p("user_id")] = op.UserID
+				userField = fmt.Sprintf(", user_id: $%s", p("user_id"))
+			}
+			tx.add(fmt.Sprintf("LET $r_%d = (CREATE vector_memories CONTENT { content: $%s, embedding: $%s, embedding_model: $%s, embedding_dim: $%s, metadata: $%s, created_at: time::now(), updated_at: time::now()%s } RETURN id)[0].id",
+				i, p("content"), p("embedding"), p("embedding_model"), p("embedding_dim"), p("metadata"), userField), params)
+
+		case BatchOpCreateEntity:
+			if op.EntityType == "" || op.Name == "" {
+				return nil, &BatchError{Index: i, Message: "create_entity requires entity_type and name"}
+			}
+			if err := checkBatchEntity(ctx, s.GetEntityType, i, op); err != nil {
+				return nil, err
+			}
+			properties := op.Properties
+			if properties == nil {
+				properties = map[string]interface{}{}
+			}
+			params[p("entity_type")] = op.EntityType
+			params[p("name")] = op.Name
+			params[p("properties")] = properties
+			tx.add(fmt.Sprintf("LET $r_%d = (CREATE entities CONTENT { entity_type: $%s, name: $%s, properties: $%s } RETURN id)[0].id",
+				i, p("entity_type"), p("name"), p("properties")), params)
+
+		case BatchOpCreateRelationship:
+			if op.From == "" || op.To == "" {
+				return nil, &BatchError{Index: i, Message: "create_relationship requires from_entity and to_entity"}
+			}
+			if !relationshipTableRe.MatchString(op.RelationshipType) {
+				return nil, &BatchError{Index: i, Message: fmt.Sprintf("invalid relationship_type %q: use letters, digits and underscores", op.RelationshipType)}
+			}
+			properties := op.Properties
+			if properties == nil {
+				properties = map[string]interface{}{}
+			}
+			// The relationship table is defined in the transaction, before its first use
+			if !relationshipTables[op.RelationshipType] {
+				relationshipTables[op.RelationshipType] = true
+				tx.add(fmt.Sprintf("DEFINE TABLE IF NOT EXISTS %s SCHEMALESS", op.RelationshipType), nil)
+			}
+			params[p("from")] = op.From
+			params[p("to")] = op.To
+			params[p("relationship_type")] = op.RelationshipType
+			params[p("properties")] = properties
+
+			// Entities are resolved by name or record ID, including those created earlier in the batch
+			for _, side := range []string{"from", "to"} {
+				tx.add(fmt.Sprintf("LET $%s_id_%d = (SELECT VALUE <string>id FROM entities WHERE name = $%s OR <string>id = $%s LIMIT 1)[0]",
+					side, i, p(side), p(side)), params)
+				tx.add(fmt.Sprintf("IF $%s_id_%d IS NONE { THROW \"batch operation %d: %s entity '\" + $%s + \"' not found\" }",
+					side, i, i, side, p(side)), params)
+			}
+			// A new relationship ends the one it replaces, as CreateRelationship does
+			from, until, _ := validityFromContext(ctx)
+			params[p("valid_from")] = from.Format(time.RFC3339Nano)
+			validUntil := ""
+			if until != nil {
+				params[p("valid_until")] = until.Format(time.RFC3339Nano)
+				validUntil = fmt.Sprintf(", valid_until: <datetime>$%s", p("valid_until"))
+			}
+			tx.add(endRelationshipsStatement(op.RelationshipType, fmt.Sprintf("from_id_%d", i), fmt.Sprintf("to_id_%d", i), p("valid_from"))+" RETURN NONE", params)
+			tx.add(fmt.Sprintf("LET $r_%d = (CREATE %s CONTENT { from_entity: $from_id_%d, to_entity: $to_id_%d, relationship_type: $%s, properties: $%s, valid_from: <datetime>$%s%s } RETURN id)[0].id",
+				i, op.RelationshipType, i, i, p("relationship_type"), p("properties"), p("valid_from"), validUntil), params)
+
+		default:
+			return nil, &BatchError{Index: i, Message: fmt.Sprintf("unknown operation %q", op.Op)}
+		}
+	}
+
+	rows := make([]string, len(ops))
+	for i := range ops {
+		rows[i] = fmt.Sprintf("{ batch_index: %d, id: $r_%d }", i, i)
+	}
+	tx.add(fmt.Sprintf("SELECT * FROM [%s]", strings.Join(rows, ", ")), nil)
+
+	result, err := s.runTx(ctx, tx)
+	if err != nil {
+		return nil, batchErrorFrom(err.Error())
+	}
+
+	ids := map[int]string{}
+	if result != nil {
+		for _, qr := range *result {
+			for _, row := range qr.Result {
+				if idx, ok := row["batch_index"]; ok {
+					ids[convertToInt(idx)] = extractRecordID(row["id"])
+				}
+			}
+		}
+	}
+
+	results := make([]BatchResult, len(ops))
+	for i, op := range ops {
+		results[i] = BatchResult{Index: i, Op: op.Op, ID: ids[i]}
+	}
+
+	s.updateBatchStats(ctx, ops)
+	return results, nil
+}
+
+// batchErrorFrom converts a transaction error into a BatchError, attributing it
+// to an operation when the message comes from a THROW in the batch script.
+func batchErrorFrom(message string) *BatchError {
+	if m := batchOpErrorRe.FindStringSubmatch(message); m != nil {
+		if idx, err := strconv.Atoi(m[1]); err == nil {
+			return &BatchError{Index: idx, Message: message}
+		}
+	}
+	return &BatchError{Index: -1, Message: message}
+}
+
+// updateBatchStats recounts the user stats affected by a committed batch.
+func (s *SurrealDBStorage) updateBatchStats(ctx context.Context, ops []BatchOperation) {
+	type stat struct{ userID, name string }
+	seen := map[stat]bool{}
+	for _, op := range ops {
+		var st stat
+		switch op.Op {
+		case BatchOpSaveFact:
+			st = stat{op.UserID, "key_value_count"}
+		case BatchOpAddVector:
+			st = stat{op.UserID, "vector_count"}
+		case BatchOpCreateEntity:
+			st = stat{"global", "entity_count"}
+		case BatchOpCreateRelationship:
+			st = stat{"global", "relationship_count"}
+		}
+		if seen[st] {
+			continue
+		}
+		seen[st] = true
+		if err := s.updateUserStat(ctx, st.userID, st.name, 0); err != nil {
+			slog.Warn("failed to update stat after batch", "user_id", st.userID, "stat", st.name, "error", err)
+		}
+	}
+}
@@ -0,0 +1,233 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/surrealdb/surrealdb.go"
+	"github.com/surrealdb/surrealdb.go/pkg/connection"
+	"github.com/surrealdb/surrealdb.go/pkg/models"
+)
+
+// changePollInterval is how often embedded databases, which have no live
+// queries, are polled for changes.
+const changePollInterval = 2 * time.Second
+
+// changeFeedTables are the tables WatchChanges accepts.
+var changeFeedTables = map[string]bool{
+	"kv_memories":     true,
+	"vector_memories": true,
+	"knowledge_base":  true,
+	"events":          true,
+	"entities":        true,
+}
+
+// WatchChanges streams the records created, updated and deleted in table,
+// only those of userID when set, until ctx is done. Remote databases use a
+// LIVE query; embedded databases, and remote ones reached over HTTP, fall
+// back to polling. The channel is closed when watching stops.
+func (s *SurrealDBStorage) WatchChanges(ctx context.Context, table, userID string) (<-chan ChangeEvent, error) {
+	if !changeFeedTables[table] {
+		return nil, fmt.Errorf("table %q does not support change notifications", table)
+	}
+	if !s.useEmbedded {
+		changes, err := s.watchLive(ctx, table, userID)
+		if err == nil {
+			return changes, nil
+		}
+		slog.Warn("live query unavailable, polling for changes", "table", table, "error", err)
+	}
+	return s.watchPoll(ctx, table, userID)
+}
+
+// watchLive relays the notifications of a LIVE query on table.
+func (s *SurrealDBStorage) watchLive(ctx context.Context, table, userID string) (<-chan ChangeEvent, error) {
+	db, err := s.remoteDB(ctx)
+	if err != nil {
+		return nil, err
+	}
+	liveID, err := surrealdb.Live(ctx, db, models.Table(table), false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start live query: %w", err)
+	}
+	notifications, err := db.LiveNotifications(liveID.String())
+	if err != nil {
+		_ = surrealdb.Kill(context.Background(), db, liveID.String())
+		return nil, fmt.Errorf("failed to receive live notifications: %w", err)
+	}
+
+	changes := make(chan ChangeEvent, 16)
+	go func() {
+		defer close(changes)
+		defer func() {
+			if err := surrealdb.Kill(context.Background(), db, liveID.String()); err != nil {
+				slog.Warn("failed to kill live query", "table", table, "error", err)
+			}
+		}()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case n, ok := <-notifications:
+				if !ok {
+					return
+				}
+				record, _ := normalizeSurrealDBDatetimes(n.Result).(map[string]interface{})
+				if userID != "" && getString(record, "user_id") != userID {
+					continue
+				}
+				change := ChangeEvent{
+					Action: liveAction(n.Action),
+					Table:  table,
+					ID:     extractRecordID(record["id"]),
+					Record: withoutEmbedding(record),
+					At:     time.Now().UTC(),
+				}
+				select {
+				case changes <- change:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return changes, nil
+}
+
+func liveAction(action connection.Action) string {
+	switch action {
+	case connection.CreateAction:
+		return ChangeCreate
+	case connection.DeleteAction:
+		return ChangeDelete
+	default:
+		return ChangeUpdate
+	}
+}
+
+// watchPoll compares snapshots of the record timestamps of table every
+// changePollInterval, reporting new, changed and missing records.
+func (s *SurrealDBStorage) watchPoll(ctx context.Context, table, userID string) (<-chan ChangeEvent, error) {
+	previous, err := s.changeSnapshot(ctx, table, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make(chan ChangeEvent, 16)
+	go func() {
+		defer close(changes)
+		ticker := time.NewTicker(changePollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			current, err := s.changeSnapshot(ctx, table, userID)
+			if err != nil {
+				if ctx.Err() == nil {
+					slog.Warn("failed to poll for changes", "table", table, "error", err)
+				}
+				continue
+			}
+			for _, change := range s.diffSnapshots(ctx, table, previous, current) {
+				select {
+				case changes <- change:
+				case <-ctx.Done():
+					return
+				}
+			}
+			previous = current
+		}
+	}()
+	return changes, nil
+}
+
+// changeSnapshot returns the last change time of every record of table, by ID.
+func (s *SurrealDBStorage) changeSnapshot(ctx context.Context, table, userID string) (map[string]time.Time, error) {
+	query := "SELECT id, created_at, updated_at FROM type::table($table)"
+	params := map[string]interface{}{"table": table}
+	if userID != "" {
+		query += " WHERE user_id = $user_id"
+		params["user_id"] = userID
+	}
+	result, err := s.query(ctx, query, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", table, err)
+	}
+
+	snapshot := map[string]time.Time{}
+	if result == nil || len(*result) == 0 {
+		return snapshot, nil
+	}
+	for _, row := range (*result)[0].Result {
+		changed := getTime(row, "updated_at")
+		if changed.IsZero() {
+			changed = getTime(row, "created_at")
+		}
+		snapshot[extractRecordID(row["id"])] = changed
+	}
+	return snapshot, nil
+}
+
+// diffSnapshots returns the changes between two snapshots, loading the
+// records that were created or updated.
+func (s *SurrealDBStorage) diffSnapshots(ctx context.Context, table string, previous, current map[string]time.Time) []ChangeEvent {
+	now := time.Now().UTC()
+	var changes []ChangeEvent
+	for id, changed := range current {
+		before, existed := previous[id]
+		if existed && before.Equal(changed) {
+			continue
+		}
+		change := ChangeEvent{Action: ChangeUpdate, Table: table, ID: id, At: now}
+		if !existed {
+			change.Action = ChangeCreate
+		}
+		change.Record = s.changedRecord(ctx, id)
+		changes = append(changes, change)
+	}
+	for id := range previous {
+		if _, ok := current[id]; !ok {
+			changes = append(changes, ChangeEvent{Action: ChangeDelete, Table: table, ID: id, At: now})
+		}
+	}
+	return changes
+}
+
+// changedRecord loads a record for a change notification, or nil when it
+// cannot be read.
+func (s *SurrealDBStorage) changedRecord(ctx context.Context, id string) map[string]interface{} {
+	table, key, err := splitRecordID(id)
+	if err != nil {
+		return nil
+	}
+	result, err := s.query(ctx, "SELECT * FROM type::thing($table, $key)", map[string]interface{}{"table": table, "key": key})
+	if err != nil || result == nil || len(*result) == 0 || len((*result)[0].Result) == 0 {
+		return nil
+	}
+	record, _ := normalizeSurrealDBDatetimes((*result)[0].Result[0]).(map[string]interface{})
+	return withoutEmbedding(record)
+}
+
+// withoutEmbedding returns record without its embedding, which is too large
+// to be useful in notifications.
+func withoutEmbedding(record map[string]interface{}) map[string]interface{} {
+	if record == nil {
+		return nil
+	}
+	out := make(map[string]interface{}, len(record))
+	for k, v := range record {
+		if k != "embedding" {
+			out[k] = v
+		}
+	}
+	if id, ok := out["id"]; ok {
+		out["id"] = extractRecordID(id)
+	}
+	return out
+}
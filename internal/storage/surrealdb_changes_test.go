@@ -0,0 +1,64 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestDiffSnapshots(t *testing.T) {
+	s := &SurrealDBStorage{}
+	t0 := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	previous := map[string]time.Time{
+		"vector_memories:a": t0,
+		"vector_memories:b": t0,
+		"vector_memories:c": t0,
+	}
+	current := map[string]time.Time{
+		"vector_memories:a": t0,
+		"vector_memories:b": t0.Add(time.Minute),
+		"vector_memories:d": t0.Add(time.Minute),
+	}
+
+	actions := map[string]string{}
+	for _, change := range s.diffSnapshots(context.Background(), "vector_memories", previous, current) {
+		if change.Table != "vector_memories" {
+			t.Errorf("change table = %q", change.Table)
+		}
+		actions[change.ID] = change.Action
+	}
+	want := map[string]string{
+		"vector_memories:b": ChangeUpdate,
+		"vector_memories:c": ChangeDelete,
+		"vector_memories:d": ChangeCreate,
+	}
+	if len(actions) != len(want) {
+		t.Fatalf("changes = %v, want %v", actions, want)
+	}
+	for id, action := range want {
+		if actions[id] != action {
+			t.Errorf("%s action = %q, want %q", id, actions[id], action)
+		}
+	}
+}
+
+func TestWithoutEmbedding(t *testing.T) {
+	record := map[string]interface{}{"id": "kv_memories:x", "embedding": []float32{1, 2}, "value": "v"}
+	got := withoutEmbedding(record)
+	if _, ok := got["embedding"]; ok {
+		t.Error("withoutEmbedding() kept the embedding")
+	}
+	if got["value"] != "v" || got["id"] != "kv_memories:x" {
+		t.Errorf("withoutEmbedding() = %v", got)
+	}
+	if _, ok := record["embedding"]; !ok {
+		t.Error("withoutEmbedding() modified its input")
+	}
+}
+
+func TestWatchChangesRejectsUnknownTables(t *testing.T) {
+	s := &SurrealDBStorage{}
+	if _, err := s.WatchChanges(context.Background(), "memory_hits", ""); err == nil {
+		t.Error("WatchChanges() accepted a table without change notifications")
+	}
+}
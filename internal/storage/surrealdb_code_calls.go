@@ -0,0 +1,116 @@
+// Package storage provides code indexing storage operations for SurrealDB.
+// This file contains call graph operations for code indexing.
+package storage
+
+import (
+	"context"
+	"fmt"
+)
+
+// ===== CALL GRAPH OPERATIONS =====
+
+// SaveCodeCalls replaces the calls recorded for a file
+func (s *SurrealDBStorage) SaveCodeCalls(ctx context.Context, projectID, filePath string, calls []CodeCall) error {
+	if err := s.DeleteCallsByFile(ctx, projectID, filePath); err != nil {
+		return fmt.Errorf("failed to delete old calls: %w", err)
+	}
+
+	query := `
+		CREATE code_calls CONTENT {
+			project_id: $project_id,
+			file_path: $file_path,
+			caller_name_path: $caller_name_path,
+			caller_name: $caller_name,
+			callee_name: $callee_name,
+			callee_expr: $callee_expr,
+			line: $line,
+			language: $language,
+			created_at: time::now()
+		}
+	`
+
+	for _, call := range calls {
+		params := map[string]interface{}{
+			"project_id":       projectID,
+			"file_path":        filePath,
+			"caller_name_path": call.CallerNamePath,
+			"caller_name":      call.CallerName,
+			"callee_name":      call.CalleeName,
+			"callee_expr":      call.CalleeExpr,
+			"line":             call.Line,
+			"language":         string(call.Language),
+		}
+
+		err := s.withTxnRetry(ctx, func(ctx context.Context) error {
+			_, err := s.query(ctx, query, params)
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("failed to save call to %s: %w", call.CalleeName, err)
+		}
+	}
+
+	return nil
+}
+
+// FindCodeCallers retrieves the calls made to functions or methods with the given name
+func (s *SurrealDBStorage) FindCodeCallers(ctx context.Context, projectID, calleeName string, limit int) ([]CodeCall, error) {
+	query := fmt.Sprintf(`
+		SELECT * FROM code_calls
+		WHERE project_id = $project_id AND callee_name = $callee_name
+		ORDER BY file_path ASC, line ASC
+		LIMIT %d;
+	`, limit)
+	params := map[string]interface{}{
+		"project_id":  projectID,
+		"callee_name": calleeName,
+	}
+
+	result, err := s.query(ctx, query, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find callers: %w", err)
+	}
+
+	return decodeResult[CodeCall](result)
+}
+
+// FindCodeCallees retrieves the calls made by a symbol, matched by name path
+// or by name. An empty filePath searches the whole project.
+func (s *SurrealDBStorage) FindCodeCallees(ctx context.Context, projectID, caller, filePath string, limit int) ([]CodeCall, error) {
+	query := `
+		SELECT * FROM code_calls
+		WHERE project_id = $project_id AND (caller_name_path = $caller OR caller_name = $caller)
+	`
+	params := map[string]interface{}{
+		"project_id": projectID,
+		"caller":     caller,
+	}
+
+	if filePath != "" {
+		query += ` AND file_path = $file_path`
+		params["file_path"] = filePath
+	}
+
+	query += fmt.Sprintf(` ORDER BY file_path ASC, line ASC LIMIT %d;`, limit)
+
+	result, err := s.query(ctx, query, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find callees: %w", err)
+	}
+
+	return decodeResult[CodeCall](result)
+}
+
+// DeleteCallsByFile deletes all calls recorded for a file
+func (s *SurrealDBStorage) DeleteCallsByFile(ctx context.Context, projectID, filePath string) error {
+	query := `DELETE FROM code_calls WHERE project_id = $project_id AND file_path = $file_path;`
+	params := map[string]interface{}{
+		"project_id": projectID,
+		"file_path":  filePath,
+	}
+
+	return s.withTxnRetry(ctx, func(ctx context.Context) error {
+		_, err := s.query(ctx, query, params)
+		return err
+	})
+}
@@ -5,6 +5,7 @@ package storage
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 )
@@ -53,6 +54,14 @@ func (s *SurrealDBStorage) saveCodeChunkAttempt(ctx context.Context, chunk *Code
 		"symbol_type":  chunk.SymbolType,
 		"language":     chunk.Language,
 	}
+	if len(chunk.Embedding) > 0 {
+		embedding, err := s.fitEmbedding("code_chunks", chunk.Embedding)
+		if err != nil {
+			return err
+		}
+		s.bindCodeEmbedding(params, embedding)
+	}
+	s.bindEmbeddingModel(ctx, params, "code_chunks", len(chunk.Embedding))
 
 	if isNewChunk {
 		query := `
@@ -66,6 +75,9 @@ func (s *SurrealDBStorage) saveCodeChunkAttempt(ctx context.Context, chunk *Code
 				start_offset: $start_offset,
 				end_offset: $end_offset,
 				embedding: $embedding,
+				embedding_f16: $embedding_f16,
+				embedding_model: $embedding_model,
+				embedding_dim: $embedding_dim,
 				symbol_name: $symbol_name,
 				symbol_type: $symbol_type,
 				language: $language
@@ -84,6 +96,9 @@ func (s *SurrealDBStorage) saveCodeChunkAttempt(ctx context.Context, chunk *Code
 				start_offset = $start_offset,
 				end_offset = $end_offset,
 				embedding = $embedding,
+				embedding_f16 = $embedding_f16,
+				embedding_model = $embedding_model,
+				embedding_dim = $embedding_dim,
 				symbol_name = $symbol_name,
 				symbol_type = $symbol_type,
 				language = $language
@@ -150,6 +165,28 @@ func (s *SurrealDBStorage) withTxnRetry(ctx context.Context, fn func(context.Con
 	return fmt.Errorf("operation failed after %d attempts", maxRetries)
 }
 
+// CountCodeChunksBySymbol returns the number of chunks of each chunked symbol
+// of a project, by symbol ID
+func (s *SurrealDBStorage) CountCodeChunksBySymbol(ctx context.Context, projectID string) (map[string]int, error) {
+	query := `SELECT symbol_id, count() AS count FROM code_chunks WHERE project_id = $project_id GROUP BY symbol_id;`
+	result, err := s.query(ctx, query, map[string]interface{}{"project_id": projectID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to count chunks: %w", err)
+	}
+	rows, err := decodeResult[map[string]interface{}](result)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int, len(rows))
+	for _, row := range rows {
+		if symbolID, ok := row["symbol_id"].(string); ok {
+			counts[symbolID] = convertToInt(row["count"])
+		}
+	}
+	return counts, nil
+}
+
 // GetChunksBySymbol retrieves all chunks for a symbol
 func (s *SurrealDBStorage) GetChunksBySymbol(ctx context.Context, symbolID string) ([]CodeChunk, error) {
 	query := `SELECT * FROM code_chunks WHERE symbol_id = $symbol_id ORDER BY chunk_index ASC;`
@@ -162,20 +199,22 @@ func (s *SurrealDBStorage) GetChunksBySymbol(ctx context.Context, symbolID strin
 
 // SearchChunksBySimilarity performs semantic search on code chunks
 func (s *SurrealDBStorage) SearchChunksBySimilarity(ctx context.Context, projectID string, queryEmbedding []float32, limit int) ([]CodeChunkSearchResult, error) {
-	query := `
-		SELECT *, vector::similarity::cosine(embedding, $embedding) AS similarity 
-		FROM code_chunks 
-		WHERE project_id = $project_id 
-		AND embedding != NONE
-		ORDER BY similarity DESC
-		LIMIT $limit;
-	`
-
 	params := map[string]interface{}{
 		"project_id": projectID,
 		"embedding":  queryEmbedding,
 		"limit":      limit,
 	}
+	// SurrealDB cannot compare packed float16 embeddings, so the second
+	// statement returns them to be scored here
+	query := fmt.Sprintf(`
+		SELECT *, vector::similarity::cosine(embedding, $embedding) AS similarity 
+		FROM code_chunks 
+		WHERE project_id = $project_id 
+		AND embedding != NONE%[1]s
+		ORDER BY similarity DESC
+		LIMIT $limit;
+		SELECT * FROM code_chunks WHERE project_id = $project_id AND embedding_f16 != NONE%[1]s;
+	`, s.embeddingModelClause(ctx, "code_chunks", params))
 
 	result, err := s.query(ctx, query, params)
 	if err != nil {
@@ -192,6 +231,20 @@ func (s *SurrealDBStorage) SearchChunksBySimilarity(ctx context.Context, project
 	if err != nil {
 		return nil, fmt.Errorf("failed to decode search results: %w", err)
 	}
+	if result != nil && len(*result) > 1 {
+		packed, err := decodeResult[searchResult](&[]QueryResult{(*result)[1]})
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode search results: %w", err)
+		}
+		for i := range packed {
+			packed[i].Similarity = cosineSimilarity(queryEmbedding, packed[i].Embedding)
+		}
+		results = append(results, packed...)
+		sort.SliceStable(results, func(a, b int) bool { return results[a].Similarity > results[b].Similarity })
+		if limit > 0 && len(results) > limit {
+			results = results[:limit]
+		}
+	}
 
 	// Convert to CodeChunkSearchResult
 	searchResults := make([]CodeChunkSearchResult, len(results))
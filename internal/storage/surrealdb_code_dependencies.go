@@ -0,0 +1,121 @@
+// Package storage provides code indexing storage operations for SurrealDB.
+// This file contains dependency-related operations for code indexing.
+package storage
+
+import (
+	"context"
+	"fmt"
+)
+
+// ===== DEPENDENCY OPERATIONS =====
+
+// SaveCodeDependencies replaces the dependencies recorded for a file
+func (s *SurrealDBStorage) SaveCodeDependencies(ctx context.Context, projectID, filePath string, deps []CodeDependency) error {
+	if err := s.DeleteDependenciesByFile(ctx, projectID, filePath); err != nil {
+		return fmt.Errorf("failed to delete old dependencies: %w", err)
+	}
+
+	query := `
+		CREATE code_dependencies CONTENT {
+			project_id: $project_id,
+			file_path: $file_path,
+			module: $module,
+			target_path: $target_path,
+			line: $line,
+			language: $language,
+			created_at: time::now()
+		}
+	`
+
+	for _, dep := range deps {
+		params := map[string]interface{}{
+			"project_id":  projectID,
+			"file_path":   filePath,
+			"module":      dep.Module,
+			"target_path": nil,
+			"line":        dep.Line,
+			"language":    string(dep.Language),
+		}
+		if dep.TargetPath != nil {
+			params["target_path"] = *dep.TargetPath
+		}
+
+		err := s.withTxnRetry(ctx, func(ctx context.Context) error {
+			_, err := s.query(ctx, query, params)
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("failed to save dependency %s: %w", dep.Module, err)
+		}
+	}
+
+	return nil
+}
+
+// GetCodeDependencies retrieves the imports of a file
+func (s *SurrealDBStorage) GetCodeDependencies(ctx context.Context, projectID, filePath string) ([]CodeDependency, error) {
+	query := `SELECT * FROM code_dependencies WHERE project_id = $project_id AND file_path = $file_path ORDER BY line ASC;`
+	params := map[string]interface{}{
+		"project_id": projectID,
+		"file_path":  filePath,
+	}
+
+	result, err := s.query(ctx, query, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dependencies: %w", err)
+	}
+
+	return decodeResult[CodeDependency](result)
+}
+
+// GetCodeDependents retrieves the imports that resolve to, or are written as,
+// any of the given targets
+func (s *SurrealDBStorage) GetCodeDependents(ctx context.Context, projectID string, targets []string) ([]CodeDependency, error) {
+	if len(targets) == 0 {
+		return nil, nil
+	}
+
+	query := `
+		SELECT * FROM code_dependencies
+		WHERE project_id = $project_id AND (target_path IN $targets OR module IN $targets)
+		ORDER BY file_path ASC, line ASC;
+	`
+	params := map[string]interface{}{
+		"project_id": projectID,
+		"targets":    targets,
+	}
+
+	result, err := s.query(ctx, query, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dependents: %w", err)
+	}
+
+	return decodeResult[CodeDependency](result)
+}
+
+// ListCodeDependencies lists every dependency recorded in a project
+func (s *SurrealDBStorage) ListCodeDependencies(ctx context.Context, projectID string) ([]CodeDependency, error) {
+	query := `SELECT * FROM code_dependencies WHERE project_id = $project_id ORDER BY file_path ASC, line ASC;`
+	params := map[string]interface{}{"project_id": projectID}
+
+	result, err := s.query(ctx, query, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list dependencies: %w", err)
+	}
+
+	return decodeResult[CodeDependency](result)
+}
+
+// DeleteDependenciesByFile deletes all dependencies recorded for a file
+func (s *SurrealDBStorage) DeleteDependenciesByFile(ctx context.Context, projectID, filePath string) error {
+	query := `DELETE FROM code_dependencies WHERE project_id = $project_id AND file_path = $file_path;`
+	params := map[string]interface{}{
+		"project_id": projectID,
+		"file_path":  filePath,
+	}
+
+	return s.withTxnRetry(ctx, func(ctx context.Context) error {
+		_, err := s.query(ctx, query, params)
+		return err
+	})
+}
@@ -3,14 +3,49 @@
 package storage
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
+	"encoding/base64"
 	"fmt"
+	"io"
 
 	"github.com/madeindigio/remembrances-mcp/pkg/treesitter"
 )
 
 // ===== FILE OPERATIONS =====
 
+// codeFileFields lists the code_files fields decoded into CodeFile, leaving
+// out the stored contents so listing files stays cheap
+const codeFileFields = "id, project_id, file_path, language, file_hash, symbols_count, indexed_at, !!content_gz AS has_content"
+
+// compressContent gzips content and encodes it as base64 for storage
+func compressContent(content []byte) (string, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(content); err != nil {
+		return "", err
+	}
+	if err := zw.Close(); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+// decompressContent reverses compressContent
+func decompressContent(encoded string) ([]byte, error) {
+	raw, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, err
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(raw))
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	return io.ReadAll(zr)
+}
+
 // SaveCodeFile saves or updates a code file
 func (s *SurrealDBStorage) SaveCodeFile(ctx context.Context, file *treesitter.CodeFile) error {
 	// Check if file exists (same pattern as SaveDocument)
@@ -38,6 +73,14 @@ func (s *SurrealDBStorage) SaveCodeFile(ctx context.Context, file *treesitter.Co
 		"language":      string(file.Language),
 		"file_hash":     file.FileHash,
 		"symbols_count": file.SymbolsCount,
+		"content_gz":    nil,
+	}
+	if file.Content != nil {
+		encoded, err := compressContent(file.Content)
+		if err != nil {
+			return fmt.Errorf("failed to compress file contents: %w", err)
+		}
+		params["content_gz"] = encoded
 	}
 
 	if isNewFile {
@@ -48,6 +91,7 @@ func (s *SurrealDBStorage) SaveCodeFile(ctx context.Context, file *treesitter.Co
 				language: $language,
 				file_hash: $file_hash,
 				symbols_count: $symbols_count,
+				content_gz: $content_gz,
 				indexed_at: time::now()
 			}
 		`
@@ -60,6 +104,7 @@ func (s *SurrealDBStorage) SaveCodeFile(ctx context.Context, file *treesitter.Co
 				language = $language,
 				file_hash = $file_hash,
 				symbols_count = $symbols_count,
+				content_gz = $content_gz,
 				indexed_at = time::now()
 			WHERE project_id = $project_id AND file_path = $file_path
 		`
@@ -73,7 +118,7 @@ func (s *SurrealDBStorage) SaveCodeFile(ctx context.Context, file *treesitter.Co
 
 // GetCodeFile retrieves a code file by project and path
 func (s *SurrealDBStorage) GetCodeFile(ctx context.Context, projectID, filePath string) (*CodeFile, error) {
-	query := `SELECT * FROM code_files WHERE project_id = $project_id AND file_path = $file_path LIMIT 1;`
+	query := `SELECT ` + codeFileFields + ` FROM code_files WHERE project_id = $project_id AND file_path = $file_path LIMIT 1;`
 	params := map[string]interface{}{
 		"project_id": projectID,
 		"file_path":  filePath,
@@ -98,7 +143,7 @@ func (s *SurrealDBStorage) GetCodeFile(ctx context.Context, projectID, filePath
 
 // ListCodeFiles lists all files in a project
 func (s *SurrealDBStorage) ListCodeFiles(ctx context.Context, projectID string) ([]CodeFile, error) {
-	query := `SELECT * FROM code_files WHERE project_id = $project_id ORDER BY file_path ASC;`
+	query := `SELECT ` + codeFileFields + ` FROM code_files WHERE project_id = $project_id ORDER BY file_path ASC;`
 	params := map[string]interface{}{"project_id": projectID}
 
 	result, err := s.query(ctx, query, params)
@@ -109,11 +154,44 @@ func (s *SurrealDBStorage) ListCodeFiles(ctx context.Context, projectID string)
 	return decodeResult[CodeFile](result)
 }
 
-// DeleteCodeFile deletes a file and all its symbols
+// GetCodeFileContent returns the stored contents of an indexed file, or nil
+// when the file is unknown or was indexed without storing its contents
+func (s *SurrealDBStorage) GetCodeFileContent(ctx context.Context, projectID, filePath string) ([]byte, error) {
+	query := `SELECT content_gz FROM code_files WHERE project_id = $project_id AND file_path = $file_path LIMIT 1;`
+	params := map[string]interface{}{
+		"project_id": projectID,
+		"file_path":  filePath,
+	}
+
+	result, err := s.query(ctx, query, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file contents: %w", err)
+	}
+
+	rows, err := decodeResult[struct {
+		ContentGz *string `json:"content_gz"`
+	}](result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode file contents: %w", err)
+	}
+	if len(rows) == 0 || rows[0].ContentGz == nil || *rows[0].ContentGz == "" {
+		return nil, nil
+	}
+
+	content, err := decompressContent(*rows[0].ContentGz)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress file contents: %w", err)
+	}
+	return content, nil
+}
+
+// DeleteCodeFile deletes a file and all its symbols, dependencies and calls
 func (s *SurrealDBStorage) DeleteCodeFile(ctx context.Context, projectID, filePath string) error {
-	// Delete symbols first, then file
+	// Delete symbols, dependencies and calls first, then file
 	queries := []string{
 		`DELETE FROM code_symbols WHERE project_id = $project_id AND file_path = $file_path;`,
+		`DELETE FROM code_dependencies WHERE project_id = $project_id AND file_path = $file_path;`,
+		`DELETE FROM code_calls WHERE project_id = $project_id AND file_path = $file_path;`,
 		`DELETE FROM code_files WHERE project_id = $project_id AND file_path = $file_path;`,
 	}
 	params := map[string]interface{}{
@@ -129,3 +207,13 @@ func (s *SurrealDBStorage) DeleteCodeFile(ctx context.Context, projectID, filePa
 
 	return nil
 }
+
+// ClearCodeFileHashes forgets the content hashes of the files of a project,
+// so that indexing it again processes every file
+func (s *SurrealDBStorage) ClearCodeFileHashes(ctx context.Context, projectID string) error {
+	query := `UPDATE code_files SET file_hash = '' WHERE project_id = $project_id;`
+	params := map[string]interface{}{"project_id": projectID}
+
+	_, err := s.query(ctx, query, params)
+	return err
+}
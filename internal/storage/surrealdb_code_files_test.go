@@ -0,0 +1,26 @@
+package storage
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestCompressContentRoundTrip(t *testing.T) {
+	content := bytes.Repeat([]byte("package main\n\nfunc main() {}\n"), 50)
+
+	encoded, err := compressContent(content)
+	if err != nil {
+		t.Fatalf("compressContent() error = %v", err)
+	}
+	if len(encoded) >= len(content) {
+		t.Errorf("compressed size %d is not smaller than %d", len(encoded), len(content))
+	}
+
+	decoded, err := decompressContent(encoded)
+	if err != nil {
+		t.Fatalf("decompressContent() error = %v", err)
+	}
+	if !bytes.Equal(decoded, content) {
+		t.Errorf("decompressContent() did not return the original content")
+	}
+}
@@ -158,11 +158,102 @@ func (s *SurrealDBStorage) UpdateProjectWatcher(ctx context.Context, projectID s
 	return err
 }
 
+// UpdateCodeProjectRoot updates the root path of a project
+func (s *SurrealDBStorage) UpdateCodeProjectRoot(ctx context.Context, projectID, rootPath string) error {
+	query := `
+		UPDATE code_projects SET
+			root_path = $root_path,
+			updated_at = time::now()
+		WHERE project_id = $project_id;
+	`
+	params := map[string]interface{}{
+		"project_id": projectID,
+		"root_path":  rootPath,
+	}
+
+	_, err := s.query(ctx, query, params)
+	return err
+}
+
+// SetCodeProjectAlias sets the alias of a project, or removes it when alias
+// is empty
+func (s *SurrealDBStorage) SetCodeProjectAlias(ctx context.Context, projectID, alias string) error {
+	query := `
+		UPDATE code_projects SET
+			alias = $alias,
+			updated_at = time::now()
+		WHERE project_id = $project_id;
+	`
+	params := map[string]interface{}{
+		"project_id": projectID,
+		"alias":      alias,
+	}
+	if alias == "" {
+		query = `
+			UPDATE code_projects SET
+				alias = NONE,
+				updated_at = time::now()
+			WHERE project_id = $project_id;
+		`
+		delete(params, "alias")
+	}
+
+	_, err := s.query(ctx, query, params)
+	return err
+}
+
+// UpdateCodeProjectSettings replaces the indexing settings of a project
+func (s *SurrealDBStorage) UpdateCodeProjectSettings(ctx context.Context, projectID string, settings CodeProjectSettings) error {
+	query := `
+		UPDATE code_projects SET
+			embedder = $embedder,
+			chunk_size = $chunk_size,
+			chunk_overlap = $chunk_overlap,
+			max_symbol_size = $max_symbol_size,
+			updated_at = time::now()
+		WHERE project_id = $project_id;
+	`
+	params := map[string]interface{}{
+		"project_id":      projectID,
+		"embedder":        settings.Embedder,
+		"chunk_size":      settings.ChunkSize,
+		"chunk_overlap":   settings.ChunkOverlap,
+		"max_symbol_size": settings.MaxSymbolSize,
+	}
+
+	_, err := s.query(ctx, query, params)
+	return err
+}
+
+// GetCodeProjectByAlias retrieves a code project by alias
+func (s *SurrealDBStorage) GetCodeProjectByAlias(ctx context.Context, alias string) (*CodeProject, error) {
+	query := `SELECT * FROM code_projects WHERE alias = $alias LIMIT 1;`
+	params := map[string]interface{}{"alias": alias}
+
+	result, err := s.query(ctx, query, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get project by alias: %w", err)
+	}
+
+	projects, err := decodeResult[CodeProject](result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode project: %w", err)
+	}
+
+	if len(projects) == 0 {
+		return nil, nil
+	}
+
+	return &projects[0], nil
+}
+
 // DeleteCodeProject deletes a project and all its files and symbols
 func (s *SurrealDBStorage) DeleteCodeProject(ctx context.Context, projectID string) error {
-	// Delete in order: symbols, files, project
+	// Delete in order: symbols, dependencies, calls, files, project
 	queries := []string{
 		`DELETE FROM code_symbols WHERE project_id = $project_id;`,
+		`DELETE FROM code_dependencies WHERE project_id = $project_id;`,
+		`DELETE FROM code_calls WHERE project_id = $project_id;`,
 		`DELETE FROM code_files WHERE project_id = $project_id;`,
 		`DELETE FROM code_indexing_jobs WHERE project_id = $project_id;`,
 		`DELETE FROM code_projects WHERE project_id = $project_id;`,
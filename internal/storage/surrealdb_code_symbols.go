@@ -5,6 +5,8 @@ package storage
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/madeindigio/remembrances-mcp/pkg/treesitter"
 )
@@ -20,9 +22,10 @@ func (s *SurrealDBStorage) SaveCodeSymbol(ctx context.Context, symbol *treesitte
 
 func (s *SurrealDBStorage) saveCodeSymbolAttempt(ctx context.Context, symbol *treesitter.CodeSymbol) error {
 	// Check if symbol exists (same pattern as SaveDocument)
-	existsQuery := "SELECT id FROM code_symbols WHERE project_id = $project_id AND name_path = $name_path"
+	existsQuery := "SELECT id FROM code_symbols WHERE project_id = $project_id AND file_path = $file_path AND name_path = $name_path"
 	existsResult, err := s.query(ctx, existsQuery, map[string]interface{}{
 		"project_id": symbol.ProjectID,
+		"file_path":  symbol.FilePath,
 		"name_path":  symbol.NamePath,
 	})
 
@@ -56,6 +59,9 @@ func (s *SurrealDBStorage) saveCodeSymbolAttempt(ctx context.Context, symbol *tr
 	if symbol.SourceCode != "" {
 		params["source_code"] = symbol.SourceCode
 	}
+	if symbol.Revision != "" {
+		params["revision"] = symbol.Revision
+	}
 	if symbol.Signature != "" {
 		params["signature"] = symbol.Signature
 	}
@@ -63,10 +69,15 @@ func (s *SurrealDBStorage) saveCodeSymbolAttempt(ctx context.Context, symbol *tr
 		params["doc_string"] = symbol.DocString
 	}
 	if len(symbol.Embedding) > 0 {
-		params["embedding"] = symbol.Embedding
+		embedding, err := s.fitEmbedding("code_symbols", symbol.Embedding)
+		if err != nil {
+			return err
+		}
+		s.bindCodeEmbedding(params, embedding)
+		s.bindEmbeddingModel(ctx, params, "code_symbols", len(symbol.Embedding))
 	}
 	if symbol.ParentID != nil && *symbol.ParentID != "" {
-		params["parent_id"] = *symbol.ParentID
+		params["parent_id"] = codeSymbolRecordID(*symbol.ParentID)
 	}
 	if symbol.Metadata != nil {
 		params["metadata"] = symbol.Metadata
@@ -79,8 +90,9 @@ func (s *SurrealDBStorage) saveCodeSymbolAttempt(ctx context.Context, symbol *tr
 			content[k] = v
 		}
 
-		query := `CREATE code_symbols CONTENT $content`
+		query := `CREATE type::thing("code_symbols", $key) CONTENT $content`
 		queryParams := map[string]interface{}{
+			"key":     codeSymbolKey(symbol),
 			"content": content,
 		}
 
@@ -88,11 +100,11 @@ func (s *SurrealDBStorage) saveCodeSymbolAttempt(ctx context.Context, symbol *tr
 			return fmt.Errorf("failed to create symbol: %w", err)
 		}
 	} else {
-		// Build update fields dynamically, excluding project_id and name_path
+		// Build update fields dynamically, excluding project_id, file_path and name_path
 		updateFields := ""
 		first := true
 		for k := range params {
-			if k == "project_id" || k == "name_path" {
+			if k == "project_id" || k == "file_path" || k == "name_path" {
 				continue
 			}
 			if !first {
@@ -101,12 +113,18 @@ func (s *SurrealDBStorage) saveCodeSymbolAttempt(ctx context.Context, symbol *tr
 			updateFields += fmt.Sprintf("%s = $%s", k, k)
 			first = false
 		}
+		// An embedding saved in another format is replaced, not kept beside it
+		if _, ok := params["embedding_f16"]; ok {
+			updateFields += ", embedding = NONE"
+		} else if _, ok := params["embedding"]; ok {
+			updateFields += ", embedding_f16 = NONE"
+		}
 
 		query := fmt.Sprintf(`
 			UPDATE code_symbols SET
 				%s,
 				updated_at = time::now()
-			WHERE project_id = $project_id AND name_path = $name_path
+			WHERE project_id = $project_id AND file_path = $file_path AND name_path = $name_path
 		`, updateFields)
 
 		if _, err := s.query(ctx, query, params); err != nil {
@@ -200,6 +218,94 @@ func (s *SurrealDBStorage) FindSymbolsByFile(ctx context.Context, projectID, fil
 	return decodeResult[CodeSymbol](result)
 }
 
+// ListCodeSymbols retrieves all symbols of a project, without their source
+// code and embeddings, by file and line
+func (s *SurrealDBStorage) ListCodeSymbols(ctx context.Context, projectID string) ([]CodeSymbol, error) {
+	query := `SELECT * OMIT source_code, embedding, embedding_f16 FROM code_symbols WHERE project_id = $project_id ORDER BY file_path ASC, start_line ASC;`
+	params := map[string]interface{}{"project_id": projectID}
+
+	result, err := s.query(ctx, query, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list symbols: %w", err)
+	}
+
+	return decodeResult[CodeSymbol](result)
+}
+
+// ListCodeSymbolsByName retrieves the symbols of a project selected by
+// filter, without their source code and embeddings, shortest names first
+func (s *SurrealDBStorage) ListCodeSymbolsByName(ctx context.Context, projectID string, filter CodeSymbolNameFilter) ([]CodeSymbol, error) {
+	params := map[string]interface{}{"project_id": projectID}
+	var names []string
+	if filter.Contains != "" {
+		params["name"] = filter.Contains
+		if filter.Exact {
+			names = append(names, "string::lowercase(name) = $name")
+		} else {
+			names = append(names, "string::lowercase(name) CONTAINS $name")
+		}
+	}
+	if len(filter.AllOf) > 0 {
+		all := make([]string, len(filter.AllOf))
+		for i, text := range filter.AllOf {
+			params[fmt.Sprintf("all%d", i)] = text
+			all[i] = fmt.Sprintf("string::lowercase(name) CONTAINS $all%d", i)
+		}
+		names = append(names, "("+strings.Join(all, " AND ")+")")
+	}
+	for i, text := range filter.AnyOf {
+		params[fmt.Sprintf("any%d", i)] = text
+		names = append(names, fmt.Sprintf("string::lowercase(name) CONTAINS $any%d", i))
+	}
+
+	query := `SELECT *, string::len(name) AS name_length OMIT source_code, embedding, embedding_f16 FROM code_symbols WHERE project_id = $project_id`
+	if len(names) > 0 {
+		query += " AND (" + strings.Join(names, " OR ") + ")"
+	}
+	switch {
+	case filter.Path == "":
+	case strings.HasSuffix(filter.Path, "/"):
+		query += " AND file_path CONTAINS $path"
+		params["path"] = filter.Path
+	default:
+		query += " AND file_path = $path"
+		params["path"] = filter.Path
+	}
+	if len(filter.Types) > 0 {
+		query += " AND symbol_type IN $types"
+		params["types"] = filter.Types
+	}
+	if len(filter.ExcludeTypes) > 0 {
+		query += " AND symbol_type NOT IN $exclude_types"
+		params["exclude_types"] = filter.ExcludeTypes
+	}
+	query += " ORDER BY name_length ASC, file_path ASC, start_line ASC"
+	if filter.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", filter.Limit)
+	}
+
+	result, err := s.query(ctx, query, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list symbols by name: %w", err)
+	}
+
+	return decodeResult[CodeSymbol](result)
+}
+
+// ListUnembeddedCodeSymbols retrieves the symbols of a project that have no
+// embedding, without their source code, by file and line
+func (s *SurrealDBStorage) ListUnembeddedCodeSymbols(ctx context.Context, projectID string) ([]CodeSymbol, error) {
+	query := `SELECT * OMIT source_code, embedding, embedding_f16 FROM code_symbols WHERE project_id = $project_id AND embedding = NONE AND embedding_f16 = NONE ORDER BY file_path ASC, start_line ASC;`
+	params := map[string]interface{}{"project_id": projectID}
+
+	result, err := s.query(ctx, query, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list symbols without embeddings: %w", err)
+	}
+
+	return decodeResult[CodeSymbol](result)
+}
+
 // FindChildSymbols retrieves child symbols of a parent
 func (s *SurrealDBStorage) FindChildSymbols(ctx context.Context, projectID, parentID string) ([]CodeSymbol, error) {
 	query := `SELECT * FROM code_symbols WHERE project_id = $project_id AND parent_id = $parent_id ORDER BY start_line ASC;`
@@ -218,28 +324,31 @@ func (s *SurrealDBStorage) FindChildSymbols(ctx context.Context, projectID, pare
 
 // SearchSymbolsBySimilarity performs semantic search on code symbols
 func (s *SurrealDBStorage) SearchSymbolsBySimilarity(ctx context.Context, projectID string, queryEmbedding []float32, symbolTypes []treesitter.SymbolType, limit int) ([]CodeSymbolSearchResult, error) {
-	query := `
-		SELECT *, vector::similarity::cosine(embedding, $embedding) AS similarity
-		FROM code_symbols
-		WHERE project_id = $project_id
-		AND embedding != NONE
-	`
-
 	params := map[string]interface{}{
 		"project_id": projectID,
 		"embedding":  queryEmbedding,
 	}
+	filter := s.embeddingModelClause(ctx, "code_symbols", params)
 
 	if len(symbolTypes) > 0 {
 		types := make([]string, len(symbolTypes))
 		for i, t := range symbolTypes {
 			types[i] = string(t)
 		}
-		query += ` AND symbol_type IN $types`
+		filter += ` AND symbol_type IN $types`
 		params["types"] = types
 	}
 
-	query += fmt.Sprintf(` ORDER BY similarity DESC LIMIT %d;`, limit)
+	// SurrealDB cannot compare packed float16 embeddings, so the second
+	// statement returns them to be scored here
+	query := fmt.Sprintf(`
+		SELECT *, vector::similarity::cosine(embedding, $embedding) AS similarity
+		FROM code_symbols
+		WHERE project_id = $project_id
+		AND embedding != NONE%[1]s
+		ORDER BY similarity DESC LIMIT %[2]d;
+		SELECT * FROM code_symbols WHERE project_id = $project_id AND embedding_f16 != NONE%[1]s;
+	`, filter, limit)
 
 	result, err := s.query(ctx, query, params)
 	if err != nil {
@@ -256,6 +365,20 @@ func (s *SurrealDBStorage) SearchSymbolsBySimilarity(ctx context.Context, projec
 	if err != nil {
 		return nil, fmt.Errorf("failed to decode search results: %w", err)
 	}
+	if result != nil && len(*result) > 1 {
+		packed, err := decodeResult[symbolWithSimilarity](&[]QueryResult{(*result)[1]})
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode search results: %w", err)
+		}
+		for i := range packed {
+			packed[i].Similarity = cosineSimilarity(queryEmbedding, packed[i].Embedding)
+		}
+		symbols = append(symbols, packed...)
+		sort.SliceStable(symbols, func(a, b int) bool { return symbols[a].Similarity > symbols[b].Similarity })
+		if limit > 0 && len(symbols) > limit {
+			symbols = symbols[:limit]
+		}
+	}
 
 	results := make([]CodeSymbolSearchResult, len(symbols))
 	for i, s := range symbols {
@@ -269,6 +392,67 @@ func (s *SurrealDBStorage) SearchSymbolsBySimilarity(ctx context.Context, projec
 	return results, nil
 }
 
+// SearchSymbolDocs performs BM25 full-text search on the doc strings and
+// signatures of code symbols
+func (s *SurrealDBStorage) SearchSymbolDocs(ctx context.Context, projectID, query string, symbolTypes []treesitter.SymbolType, limit int) ([]CodeSymbolTextResult, error) {
+	sql := `
+		SELECT *, search::score(1) + search::score(2) AS score
+		FROM code_symbols
+		WHERE project_id = $project_id
+		AND (doc_string @1@ $query OR signature @2@ $query)
+	`
+	params := map[string]interface{}{
+		"project_id": projectID,
+		"query":      query,
+	}
+
+	if len(symbolTypes) > 0 {
+		sql += ` AND symbol_type IN $types`
+		params["types"] = symbolTypeNames(symbolTypes)
+	}
+
+	sql += fmt.Sprintf(` ORDER BY score DESC LIMIT %d;`, limit)
+
+	result, err := s.query(ctx, sql, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search symbol docs: %w", err)
+	}
+
+	type symbolWithScore struct {
+		CodeSymbol
+		Score float64 `json:"score"`
+	}
+
+	symbols, err := decodeResult[symbolWithScore](result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode search results: %w", err)
+	}
+
+	results := make([]CodeSymbolTextResult, len(symbols))
+	for i, s := range symbols {
+		sym := s.CodeSymbol
+		results[i] = CodeSymbolTextResult{Symbol: &sym, Score: s.Score}
+	}
+
+	return results, nil
+}
+
+// DeleteCodeSymbols deletes the symbols of a file with the given name paths
+func (s *SurrealDBStorage) DeleteCodeSymbols(ctx context.Context, projectID, filePath string, namePaths []string) error {
+	if len(namePaths) == 0 {
+		return nil
+	}
+	query := `DELETE FROM code_symbols WHERE project_id = $project_id AND file_path = $file_path AND name_path IN $name_paths;`
+	params := map[string]interface{}{
+		"project_id": projectID,
+		"file_path":  filePath,
+		"name_paths": namePaths,
+	}
+
+	_, err := s.query(ctx, query, params)
+	return err
+}
+
 // DeleteSymbolsByFile deletes all symbols in a file
 func (s *SurrealDBStorage) DeleteSymbolsByFile(ctx context.Context, projectID, filePath string) error {
 	query := `DELETE FROM code_symbols WHERE project_id = $project_id AND file_path = $file_path;`
@@ -0,0 +1,61 @@
+package storage
+
+import (
+	"context"
+	"testing"
+
+	"github.com/madeindigio/remembrances-mcp/pkg/treesitter"
+)
+
+func TestFloat16CodeSymbolEmbeddings(t *testing.T) {
+	s := newTestSurrealDB(t)
+	ctx := context.Background()
+
+	axis := func(i int) []float32 {
+		embedding := make([]float32, defaultMtreeDim)
+		embedding[i] = 1
+		return embedding
+	}
+	save := func(namePath string, embedding []float32) {
+		t.Helper()
+		if err := s.SaveCodeSymbol(ctx, &treesitter.CodeSymbol{
+			ProjectID:  "app",
+			FilePath:   "main.go",
+			Language:   treesitter.LanguageGo,
+			SymbolType: treesitter.SymbolTypeFunction,
+			Name:       namePath[1:],
+			NamePath:   namePath,
+			Embedding:  embedding,
+		}); err != nil {
+			t.Fatalf("save %s: %v", namePath, err)
+		}
+	}
+
+	s.config.EmbeddingFormat = EmbeddingFormatFloat16
+	save("/near", axis(0))
+	save("/far", axis(1))
+
+	sym, err := s.GetCodeSymbol(ctx, "app", "/near")
+	if err != nil || sym == nil || len(sym.Embedding) != defaultMtreeDim || sym.Embedding[0] != 1 {
+		t.Fatalf("GetCodeSymbol = %+v, %v; want the unpacked embedding", sym, err)
+	}
+
+	// Packed and float32 embeddings are ranked together
+	s.config.EmbeddingFormat = EmbeddingFormatFloat32
+	save("/far", axis(1))
+	results, err := s.SearchSymbolsBySimilarity(ctx, "app", axis(0), nil, 2)
+	if err != nil {
+		t.Fatalf("search: %v", err)
+	}
+	if len(results) != 2 || results[0].Symbol.NamePath != "/near" || results[0].Similarity < 0.99 || results[1].Symbol.NamePath != "/far" {
+		t.Errorf("search results = %+v, want /near then /far", results)
+	}
+
+	result, err := s.query(ctx, "SELECT embedding_f16 FROM code_symbols WHERE name_path = '/far'", nil)
+	if err != nil {
+		t.Fatalf("select /far: %v", err)
+	}
+	if rows := (*result)[0].Result; len(rows) != 1 || rows[0]["embedding_f16"] != nil {
+		t.Errorf("/far saved as float32 = %v, want its packed embedding removed", rows)
+	}
+}
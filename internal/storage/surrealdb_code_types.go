@@ -13,13 +13,24 @@ type CodeProject struct {
 	ID             string                      `json:"id"`
 	ProjectID      string                      `json:"project_id"`
 	Name           string                      `json:"name"`
+	Alias          string                      `json:"alias,omitempty"`
 	RootPath       string                      `json:"root_path"`
 	LanguageStats  map[treesitter.Language]int `json:"language_stats"`
 	LastIndexedAt  *time.Time                  `json:"last_indexed_at"`
 	IndexingStatus treesitter.IndexingStatus   `json:"indexing_status"`
 	WatcherEnabled bool                        `json:"watcher_enabled"`
-	CreatedAt      time.Time                   `json:"created_at"`
-	UpdatedAt      time.Time                   `json:"updated_at"`
+	CodeProjectSettings
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// CodeProjectSettings overrides, for one project, how its code is indexed.
+// Zero values keep the server configuration.
+type CodeProjectSettings struct {
+	Embedder      string `json:"embedder,omitempty"`        // Named embedder of its symbols and chunks
+	ChunkSize     int    `json:"chunk_size,omitempty"`      // Characters per chunk of large symbols
+	ChunkOverlap  int    `json:"chunk_overlap,omitempty"`   // Characters shared by consecutive chunks
+	MaxSymbolSize int    `json:"max_symbol_size,omitempty"` // Bytes of source code stored per symbol
 }
 
 // CodeFile represents a stored code file
@@ -31,6 +42,35 @@ type CodeFile struct {
 	FileHash     string              `json:"file_hash"`
 	SymbolsCount int                 `json:"symbols_count"`
 	IndexedAt    time.Time           `json:"indexed_at"`
+	HasContent   bool                `json:"has_content"`
+}
+
+// CodeDependency represents a stored import of a code file. TargetPath is the
+// project file the import resolves to, or nil for external modules.
+type CodeDependency struct {
+	ID         string              `json:"id"`
+	ProjectID  string              `json:"project_id"`
+	FilePath   string              `json:"file_path"`
+	Module     string              `json:"module"`
+	TargetPath *string             `json:"target_path,omitempty"`
+	Line       int                 `json:"line"`
+	Language   treesitter.Language `json:"language"`
+	CreatedAt  time.Time           `json:"created_at"`
+}
+
+// CodeCall represents a stored call edge from a symbol to a callee name.
+// CallerNamePath is empty for calls made at file level.
+type CodeCall struct {
+	ID             string              `json:"id"`
+	ProjectID      string              `json:"project_id"`
+	FilePath       string              `json:"file_path"`
+	CallerNamePath string              `json:"caller_name_path"`
+	CallerName     string              `json:"caller_name"`
+	CalleeName     string              `json:"callee_name"`
+	CalleeExpr     string              `json:"callee_expr"`
+	Line           int                 `json:"line"`
+	Language       treesitter.Language `json:"language"`
+	CreatedAt      time.Time           `json:"created_at"`
 }
 
 // CodeSymbol represents a stored code symbol
@@ -47,6 +87,7 @@ type CodeSymbol struct {
 	StartByte  int                    `json:"start_byte"`
 	EndByte    int                    `json:"end_byte"`
 	SourceCode *string                `json:"source_code,omitempty"`
+	Revision   *string                `json:"revision,omitempty"`
 	Signature  *string                `json:"signature,omitempty"`
 	DocString  *string                `json:"doc_string,omitempty"`
 	Embedding  []float32              `json:"embedding,omitempty"`
@@ -56,12 +97,57 @@ type CodeSymbol struct {
 	UpdatedAt  time.Time              `json:"updated_at"`
 }
 
+// codeSymbolKey returns the record key of a symbol: its extracted ID, which
+// is derived from its project, file and name path so that it survives
+// reindexing.
+func codeSymbolKey(symbol *treesitter.CodeSymbol) string {
+	if symbol.ID != "" {
+		return symbol.ID
+	}
+	return treesitter.SymbolID(symbol.ProjectID, symbol.FilePath, symbol.NamePath)
+}
+
+// codeSymbolRecordID returns the stored ID of the symbol with a record key,
+// which parent_id references too.
+func codeSymbolRecordID(key string) string {
+	return "code_symbols:" + key
+}
+
 // CodeSymbolSearchResult represents a symbol search result with similarity
 type CodeSymbolSearchResult struct {
 	Symbol     *CodeSymbol `json:"symbol"`
 	Similarity float64     `json:"similarity"`
 }
 
+// CodeSymbolTextResult represents a symbol found by full-text search of its
+// doc string and signature, with its text relevance
+type CodeSymbolTextResult struct {
+	Symbol *CodeSymbol `json:"symbol"`
+	Score  float64     `json:"score"`
+}
+
+// CodeSymbolNameFilter selects the symbols of a project whose names may
+// match a pattern, for callers that rank them by name. Names are compared
+// in lower case, so the texts must be lower case too.
+type CodeSymbolNameFilter struct {
+	// Contains is a text the name contains, or is when Exact is set
+	Contains string
+	Exact    bool
+	// AllOf are texts the name contains, in any order; AnyOf are texts the
+	// name contains at least one of. Symbols matching either are selected
+	// too, for fuzzy matching.
+	AllOf []string
+	AnyOf []string
+	// Path restricts the symbols to a file or, with a trailing slash, to the
+	// files whose path contains it
+	Path string
+	// Types and ExcludeTypes restrict the symbol types
+	Types        []string
+	ExcludeTypes []string
+	// Limit caps the symbols returned, shortest names first (0 for no limit)
+	Limit int
+}
+
 // CodeIndexingJob represents a stored indexing job
 type CodeIndexingJob struct {
 	ID           string                    `json:"id"`
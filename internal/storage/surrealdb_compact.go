@@ -0,0 +1,43 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Compact rewrites the files of an embedded database so that the space of
+// deleted and overwritten records is returned to the file system, and reports
+// the size before and after. Remote and in-memory databases are not supported:
+// the former are maintained by their server and the latter have no files.
+func (s *SurrealDBStorage) Compact(ctx context.Context) (*CompactionReport, error) {
+	if !s.useEmbedded {
+		return nil, fmt.Errorf("compaction is only supported for the embedded database; compact a remote SurrealDB on its server")
+	}
+	path := embeddedDBPath(s.config.DBPath)
+	if path == "" {
+		return nil, fmt.Errorf("compaction is not supported for in-memory databases")
+	}
+
+	before, err := diskUsage(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get database size: %w", err)
+	}
+	start := time.Now()
+	if _, err := s.query(WithoutQueryTimeout(ctx), "ALTER SYSTEM COMPACT;", nil); err != nil {
+		return nil, fmt.Errorf("failed to compact database: %w", err)
+	}
+	duration := time.Since(start)
+	after, err := diskUsage(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get database size: %w", err)
+	}
+
+	return &CompactionReport{
+		Location:   s.config.DBPath,
+		SizeBefore: before,
+		SizeAfter:  after,
+		Reclaimed:  before - after,
+		Duration:   duration,
+	}, nil
+}
@@ -0,0 +1,68 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCompactUnsupported(t *testing.T) {
+	remote := NewSurrealDBStorage(&ConnectionConfig{URL: "ws://localhost:8000"})
+	if _, err := remote.Compact(context.Background()); err == nil || !strings.Contains(err.Error(), "only supported for the embedded database") {
+		t.Errorf("Compact() of a remote database = %v, want an unsupported error", err)
+	}
+
+	for _, path := range []string{"memory", "memory://", "mem://"} {
+		s := NewSurrealDBStorage(&ConnectionConfig{DBPath: path})
+		s.useEmbedded = true
+		if _, err := s.Compact(context.Background()); err == nil || !strings.Contains(err.Error(), "in-memory") {
+			t.Errorf("Compact() of %s = %v, want an in-memory error", path, err)
+		}
+	}
+}
+
+func TestCompactEmbedded(t *testing.T) {
+	dbPath := "surrealkv://" + filepath.Join(t.TempDir(), "data")
+	s := NewSurrealDBStorage(&ConnectionConfig{DBPath: dbPath, Namespace: "test", Database: "test", Timeout: 30 * time.Second})
+	ctx := context.Background()
+	if err := s.Connect(ctx); err != nil {
+		t.Skipf("embedded SurrealDB unavailable: %v", err)
+	}
+	defer s.Close()
+	if err := s.InitializeSchema(ctx); err != nil {
+		t.Fatalf("initialize schema: %v", err)
+	}
+
+	// Overwritten and deleted records leave space to reclaim
+	for i := 0; i < 200; i++ {
+		key := fmt.Sprintf("key%d", i)
+		if err := s.SaveFact(ctx, "alice", key, strings.Repeat("x", 1024)); err != nil {
+			t.Fatalf("save fact: %v", err)
+		}
+		if err := s.SaveFact(ctx, "alice", key, strings.Repeat("y", 1024)); err != nil {
+			t.Fatalf("overwrite fact: %v", err)
+		}
+		if err := s.DeleteFact(ctx, "alice", key); err != nil {
+			t.Fatalf("delete fact: %v", err)
+		}
+	}
+
+	report, err := s.Compact(ctx)
+	if err != nil {
+		t.Fatalf("Compact() error = %v", err)
+	}
+	if report.Location != dbPath || report.SizeBefore <= 0 || report.SizeAfter <= 0 || report.Reclaimed != report.SizeBefore-report.SizeAfter {
+		t.Errorf("Compact() = %+v, want the sizes of %s", report, dbPath)
+	}
+
+	// The database is still usable after compaction
+	if err := s.SaveFact(ctx, "alice", "editor", "vim"); err != nil {
+		t.Fatalf("save fact after compaction: %v", err)
+	}
+	if value, err := s.GetFact(ctx, "alice", "editor"); err != nil || value != "vim" {
+		t.Errorf("fact after compaction = %v, %v; want vim", value, err)
+	}
+}
@@ -0,0 +1,127 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+// ListVectorMemories returns up to limit vector memories of a user together with
+// their embeddings, oldest first. A limit <= 0 returns all memories.
+func (s *SurrealDBStorage) ListVectorMemories(ctx context.Context, userID string, limit int) ([]VectorMemory, error) {
+	query := "SELECT id, content, embedding, metadata, created_at FROM vector_memories WHERE user_id = $user_id ORDER BY created_at ASC"
+	params := map[string]interface{}{
+		"user_id": userID,
+	}
+	if limit > 0 {
+		query += " LIMIT $limit"
+		params["limit"] = limit
+	}
+
+	result, err := s.query(ctx, query, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list vector memories: %w", err)
+	}
+
+	var memories []VectorMemory
+	if result == nil || len(*result) == 0 || (*result)[0].Status != "OK" {
+		return memories, nil
+	}
+	for _, row := range (*result)[0].Result {
+		memories = append(memories, VectorMemory{
+			ID:        extractRecordID(row["id"]),
+			Content:   getString(row, "content"),
+			Embedding: s.decodeStoredEmbedding(row["embedding"]),
+			Metadata:  getMap(row, "metadata"),
+			CreatedAt: getTime(row, "created_at"),
+		})
+	}
+	return memories, nil
+}
+
+// ConsolidateVectors stores summary as a new vector memory and moves the memories
+// in ids to archived_vector_memories, linked to the summary through consolidated_into.
+// It returns the id of the summary memory.
+func (s *SurrealDBStorage) ConsolidateVectors(ctx context.Context, userID, summary string, embedding []float32, metadata map[string]interface{}, ids []string) (string, error) {
+	if len(ids) == 0 {
+		return "", fmt.Errorf("no memories to consolidate")
+	}
+	if metadata == nil {
+		metadata = map[string]interface{}{}
+	}
+	if err := s.checkPinnedVectors(ctx, ids); err != nil {
+		return "", err
+	}
+	dim := len(embedding)
+	embedding, err := s.fitEmbedding("vector_memories", embedding)
+	if err != nil {
+		return "", err
+	}
+
+	summaryKey := newRecordKey()
+	summaryID := "vector_memories:" + summaryKey
+	params := map[string]interface{}{
+		"summary_key": summaryKey,
+		"user_id":     userID,
+		"content":     summary,
+		"embedding":   s.storedEmbedding(embedding),
+		"metadata":    metadata,
+	}
+	s.bindEmbeddingModel(ctx, params, "vector_memories", dim)
+	recordParams := map[string]interface{}{"summary_id": summaryID}
+	records, err := recordListExpr(ids, recordParams)
+	if err != nil {
+		return "", err
+	}
+
+	// The summary, the archived copies and the deletion of the originals
+	// commit together, so a failure never leaves memories both archived and
+	// live or a summary without its sources
+	tx := newSurrealTx()
+	tx.add(`
+		CREATE type::thing("vector_memories", $summary_key) CONTENT {
+			user_id: $user_id,
+			content: $content,
+			embedding: $embedding,
+			embedding_model: $embedding_model,
+			embedding_dim: $embedding_dim,
+			metadata: $metadata,
+			created_at: time::now(),
+			updated_at: time::now()
+		} RETURN NONE
+	`, params)
+	tx.add(fmt.Sprintf(`
+		INSERT INTO archived_vector_memories (
+			SELECT user_id, content, embedding, metadata, created_at,
+			       type::string(id) AS original_id, $summary_id AS consolidated_into
+			FROM %s
+		) RETURN NONE
+	`, records), recordParams)
+	tx.add(fmt.Sprintf("DELETE %s", records), recordParams)
+	if err := s.commitTx(ctx, tx); err != nil {
+		return "", fmt.Errorf("failed to consolidate memories: %w", err)
+	}
+
+	if err := s.updateUserStat(ctx, userID, "vector_count", 0); err != nil {
+		slog.Warn("failed to update vector_count stat", "user_id", userID, "error", err)
+	}
+
+	return summaryID, nil
+}
+
+// recordListExpr turns "table:key" ids into a SurrealQL array of record ids,
+// binding each table and key as a query parameter.
+func recordListExpr(ids []string, params map[string]interface{}) (string, error) {
+	things := make([]string, len(ids))
+	for i, id := range ids {
+		table, key, err := splitRecordID(id)
+		if err != nil {
+			return "", err
+		}
+		params[fmt.Sprintf("t%d", i)] = table
+		params[fmt.Sprintf("k%d", i)] = key
+		things[i] = fmt.Sprintf("type::thing($t%d, $k%d)", i, i)
+	}
+	return "[" + strings.Join(things, ", ") + "]", nil
+}
@@ -0,0 +1,105 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// vectorIndexes lists the vector indexes defined by the schema, by table.
+var vectorIndexes = []IndexHealth{
+	{Table: "vector_memories", Index: "idx_vector_embedding"},
+	{Table: "knowledge_base", Index: "idx_kb_embedding"},
+	{Table: "kb_document_versions", Index: "idx_kb_versions_embedding"},
+	{Table: "events", Index: "idx_events_embedding"},
+	{Table: "code_symbols", Index: "idx_code_symbol_embedding"},
+	{Table: "code_chunks", Index: "idx_code_chunk_embedding"},
+}
+
+// GetDatabaseInfo returns the database mode, on-disk size of embedded
+// databases, schema version and vector index health.
+func (s *SurrealDBStorage) GetDatabaseInfo(ctx context.Context) (*DatabaseInfo, error) {
+	info := &DatabaseInfo{
+		Mode:                "remote",
+		LatestSchemaVersion: schemaVersion,
+	}
+	if s.useEmbedded {
+		info.Mode = "embedded"
+		info.Location = s.config.DBPath
+		if path := embeddedDBPath(s.config.DBPath); path != "" {
+			size, err := diskUsage(path)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get database size: %w", err)
+			}
+			info.SizeBytes = size
+		}
+	} else if u, err := url.Parse(s.config.URL); err == nil {
+		info.Location = u.Redacted()
+	}
+
+	version, err := s.getCurrentSchemaVersion(ctx)
+	if err != nil {
+		return nil, err
+	}
+	info.SchemaVersion = version
+
+	info.Indexes = make([]IndexHealth, 0, len(vectorIndexes))
+	for _, idx := range vectorIndexes {
+		idx.Defined = s.indexDefined(ctx, idx.Table, idx.Index)
+		info.Indexes = append(info.Indexes, idx)
+	}
+	return info, nil
+}
+
+// indexDefined reports whether an index is defined on a table.
+func (s *SurrealDBStorage) indexDefined(ctx context.Context, table, index string) bool {
+	result, err := s.query(ctx, fmt.Sprintf("INFO FOR TABLE %s;", table), nil)
+	if err != nil || result == nil || len(*result) == 0 || len((*result)[0].Result) == 0 {
+		return false
+	}
+	indexes, _ := (*result)[0].Result[0]["indexes"].(map[string]interface{})
+	_, ok := indexes[index]
+	return ok
+}
+
+// embeddedDBPath returns the file system path of an embedded database URL
+// such as surrealkv:///path or a plain path, or "" for in-memory databases.
+func embeddedDBPath(dbURL string) string {
+	path := strings.TrimSpace(dbURL)
+	if path == "memory" {
+		return ""
+	}
+	if scheme, rest, ok := strings.Cut(path, "://"); ok {
+		if scheme == "memory" || scheme == "mem" {
+			return ""
+		}
+		path = rest
+	}
+	return path
+}
+
+// diskUsage returns the size of a file, or of all files under a directory.
+func diskUsage(path string) (int64, error) {
+	var size int64
+	err := filepath.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if d.Type().IsRegular() {
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			size += info.Size()
+		}
+		return nil
+	})
+	return size, err
+}
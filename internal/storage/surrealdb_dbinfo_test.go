@@ -0,0 +1,48 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestEmbeddedDBPath(t *testing.T) {
+	tests := map[string]string{
+		"memory":                          "",
+		"memory://":                       "",
+		"./remembrances.db":               "./remembrances.db",
+		"surrealkv://./remembrances.db":   "./remembrances.db",
+		"rocksdb:///var/lib/remembrances": "/var/lib/remembrances",
+	}
+	for in, want := range tests {
+		if got := embeddedDBPath(in); got != want {
+			t.Errorf("embeddedDBPath(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestDiskUsage(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "a"), make([]byte, 100), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "sub"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "sub", "b"), make([]byte, 50), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	size, err := diskUsage(dir)
+	if err != nil {
+		t.Fatalf("diskUsage() error = %v", err)
+	}
+	if size != 150 {
+		t.Errorf("diskUsage() = %d, want 150", size)
+	}
+
+	size, err = diskUsage(filepath.Join(dir, "missing"))
+	if err != nil || size != 0 {
+		t.Errorf("diskUsage() of a missing path = %d, %v, want 0, nil", size, err)
+	}
+}
@@ -0,0 +1,323 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+const (
+	// maxDiffCells bounds the LCS table used by documentDiff (old lines x new lines).
+	maxDiffCells = 4_000_000
+	// minChunkOverlap is the shortest repeated prefix MergeChunkContents treats as chunk overlap.
+	minChunkOverlap = 8
+)
+
+// archiveDocument stores the current content of filePath as a new row in
+// kb_document_versions before it is overwritten with newContent. Nothing is
+// archived when the document does not exist yet or its content is unchanged.
+func (s *SurrealDBStorage) archiveDocument(ctx context.Context, filePath, newContent string) error {
+	query := "SELECT content, embedding, embedding_model, embedding_dim, metadata, chunk_index FROM knowledge_base WHERE source_file = $file_path OR file_path = $file_path ORDER BY chunk_index ASC"
+	result, err := s.query(ctx, query, map[string]interface{}{
+		"file_path": filePath,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to read current document: %w", err)
+	}
+	if result == nil || len(*result) == 0 {
+		return nil
+	}
+	rows := (*result)[0].Result
+	if (*result)[0].Status != "OK" || len(rows) == 0 {
+		return nil
+	}
+
+	chunks := make([]string, 0, len(rows))
+	embeddings := make([][]float32, 0, len(rows))
+	for _, row := range rows {
+		chunks = append(chunks, getString(row, "content"))
+		if emb := s.decodeStoredEmbedding(row["embedding"]); len(emb) > 0 {
+			embeddings = append(embeddings, emb)
+		}
+	}
+
+	oldContent := MergeChunkContents(chunks)
+	if oldContent == strings.TrimSpace(newContent) {
+		return nil
+	}
+
+	metadata := getMap(rows[0], "metadata")
+	delete(metadata, "chunk_index")
+	delete(metadata, "chunk_count")
+
+	version, err := s.nextDocumentVersion(ctx, filePath)
+	if err != nil {
+		return err
+	}
+
+	embedding := averageEmbedding(embeddings)
+	if len(embedding) != defaultMtreeDim {
+		norm := make([]float32, defaultMtreeDim)
+		copy(norm, embedding)
+		embedding = norm
+	}
+
+	createQuery := `
+		CREATE kb_document_versions CONTENT {
+			file_path: $file_path,
+			version: $version,
+			content: $content,
+			diff: $diff,
+			chunk_count: $chunk_count,
+			embedding: $embedding,
+			embedding_model: $embedding_model,
+			embedding_dim: $embedding_dim,
+			metadata: $metadata
+		}
+	`
+	// The version keeps the model of the chunks it was averaged from
+	params := map[string]interface{}{
+		"file_path":       filePath,
+		"version":         version,
+		"content":         oldContent,
+		"diff":            documentDiff(oldContent, strings.TrimSpace(newContent)),
+		"chunk_count":     len(rows),
+		"embedding":       s.storedEmbedding(embedding),
+		"embedding_model": getString(rows[0], "embedding_model"),
+		"embedding_dim":   convertToInt(rows[0]["embedding_dim"]),
+		"metadata":        metadata,
+	}
+	if _, err := s.query(ctx, createQuery, params); err != nil {
+		return fmt.Errorf("failed to archive document version: %w", err)
+	}
+
+	slog.Debug("archived kb document version", "file_path", filePath, "version", version)
+	return nil
+}
+
+func (s *SurrealDBStorage) nextDocumentVersion(ctx context.Context, filePath string) (int, error) {
+	query := "SELECT math::max(version) AS latest FROM kb_document_versions WHERE file_path = $file_path GROUP ALL"
+	result, err := s.query(ctx, query, map[string]interface{}{
+		"file_path": filePath,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to read document versions: %w", err)
+	}
+	if result == nil || len(*result) == 0 || len((*result)[0].Result) == 0 {
+		return 1, nil
+	}
+	return convertToInt((*result)[0].Result[0]["latest"]) + 1, nil
+}
+
+// GetDocumentHistory lists the archived versions of a document, newest first
+func (s *SurrealDBStorage) GetDocumentHistory(ctx context.Context, filePath string) ([]DocumentVersion, error) {
+	query := "SELECT id, file_path, version, content, diff, chunk_count, metadata, created_at FROM kb_document_versions WHERE file_path = $file_path ORDER BY version DESC"
+	result, err := s.query(ctx, query, map[string]interface{}{
+		"file_path": filePath,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get document history: %w", err)
+	}
+
+	return parseDocumentVersions(result), nil
+}
+
+// GetDocumentVersion retrieves a single archived version of a document
+func (s *SurrealDBStorage) GetDocumentVersion(ctx context.Context, filePath string, version int) (*DocumentVersion, error) {
+	query := "SELECT id, file_path, version, content, diff, chunk_count, metadata, created_at FROM kb_document_versions WHERE file_path = $file_path AND version = $version LIMIT 1"
+	result, err := s.query(ctx, query, map[string]interface{}{
+		"file_path": filePath,
+		"version":   version,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get document version: %w", err)
+	}
+
+	versions := parseDocumentVersions(result)
+	if len(versions) == 0 {
+		return nil, nil
+	}
+	return &versions[0], nil
+}
+
+// SearchDocumentVersions performs similarity search over archived document versions
+func (s *SurrealDBStorage) SearchDocumentVersions(ctx context.Context, queryEmbedding []float32, opts VectorSearchOptions) ([]DocumentResult, error) {
+	knn, err := knnOperator(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	params := map[string]interface{}{
+		"query_embedding": queryEmbedding,
+	}
+	query := fmt.Sprintf(`
+		SELECT id, file_path, version, content, metadata, created_at,
+		       vector::similarity::cosine(embedding, $query_embedding) AS similarity
+		FROM kb_document_versions
+		WHERE embedding %s $query_embedding%s%s%s%s
+		ORDER BY similarity DESC
+	`, knn, minSimilarityClause(opts), kbRootClause(opts), frontmatterClause(opts), s.embeddingModelClause(ctx, "kb_document_versions", params))
+	if opts.MinSimilarity > 0 {
+		params["min_similarity"] = opts.MinSimilarity
+	}
+	if len(opts.KBRoots) > 0 {
+		params["kb_roots"] = opts.KBRoots
+		params["default_kb_root"] = DefaultKBRoot
+	}
+	bindFrontmatterFilter(opts, params)
+
+	result, err := s.query(ctx, query, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search document versions: %w", err)
+	}
+
+	var results []DocumentResult
+	if result == nil || len(*result) == 0 || (*result)[0].Status != "OK" {
+		return results, nil
+	}
+	for _, row := range (*result)[0].Result {
+		metadata := getMap(row, "metadata")
+		metadata["archived"] = true
+		metadata["version"] = convertToInt(row["version"])
+
+		similarity := getFloat64(row, "similarity")
+		results = append(results, DocumentResult{
+			Document: &Document{
+				ID:        getString(row, "id"),
+				FilePath:  getString(row, "file_path"),
+				Content:   getString(row, "content"),
+				Metadata:  metadata,
+				CreatedAt: getTime(row, "created_at"),
+				UpdatedAt: getTime(row, "created_at"),
+			},
+			Similarity: similarity,
+			Score:      similarity,
+		})
+	}
+
+	return results, nil
+}
+
+func parseDocumentVersions(result *[]QueryResult) []DocumentVersion {
+	var versions []DocumentVersion
+	if result == nil || len(*result) == 0 || (*result)[0].Status != "OK" {
+		return versions
+	}
+
+	for _, row := range (*result)[0].Result {
+		versions = append(versions, DocumentVersion{
+			ID:         getString(row, "id"),
+			FilePath:   getString(row, "file_path"),
+			Version:    convertToInt(row["version"]),
+			Content:    getString(row, "content"),
+			Diff:       getString(row, "diff"),
+			ChunkCount: convertToInt(row["chunk_count"]),
+			Metadata:   getMap(row, "metadata"),
+			ArchivedAt: getTime(row, "created_at"),
+		})
+	}
+	return versions
+}
+
+// MergeChunkContents rebuilds a document from its stored chunks, collapsing the
+// overlap that chunking repeats at the start of each chunk.
+func MergeChunkContents(chunks []string) string {
+	if len(chunks) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString(strings.TrimSpace(chunks[0]))
+	for _, chunk := range chunks[1:] {
+		chunk = strings.TrimSpace(chunk)
+		merged := b.String()
+
+		overlap := 0
+		for k := min(len(merged), len(chunk)); k >= minChunkOverlap; k-- {
+			if strings.HasSuffix(merged, chunk[:k]) {
+				overlap = k
+				break
+			}
+		}
+
+		if overlap > 0 {
+			b.WriteString(chunk[overlap:])
+		} else {
+			b.WriteString("\n")
+			b.WriteString(chunk)
+		}
+	}
+	return b.String()
+}
+
+// documentDiff returns a line diff from oldText to newText. Removed lines are
+// prefixed with "-", added lines with "+", and each hunk starts with an
+// "@@ -a +b @@" header giving the 1-based line numbers in the old and new text.
+func documentDiff(oldText, newText string) string {
+	oldLines := strings.Split(oldText, "\n")
+	newLines := strings.Split(newText, "\n")
+
+	if len(oldLines)*len(newLines) > maxDiffCells {
+		return fmt.Sprintf("@@ -1 +1 @@\n(document too large to diff: %d -> %d lines)", len(oldLines), len(newLines))
+	}
+
+	// lcs[i][j] is the LCS length of oldLines[i:] and newLines[j:]
+	lcs := make([][]int, len(oldLines)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(newLines)+1)
+	}
+	for i := len(oldLines) - 1; i >= 0; i-- {
+		for j := len(newLines) - 1; j >= 0; j-- {
+			if oldLines[i] == newLines[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var b strings.Builder
+	inHunk := false
+	i, j := 0, 0
+	for i < len(oldLines) || j < len(newLines) {
+		switch {
+		case i < len(oldLines) && j < len(newLines) && oldLines[i] == newLines[j]:
+			inHunk = false
+			i++
+			j++
+		case j < len(newLines) && (i == len(oldLines) || lcs[i][j+1] >= lcs[i+1][j]):
+			if !inHunk {
+				fmt.Fprintf(&b, "@@ -%d +%d @@\n", i+1, j+1)
+				inHunk = true
+			}
+			b.WriteString("+" + newLines[j] + "\n")
+			j++
+		default:
+			if !inHunk {
+				fmt.Fprintf(&b, "@@ -%d +%d @@\n", i+1, j+1)
+				inHunk = true
+			}
+			b.WriteString("-" + oldLines[i] + "\n")
+			i++
+		}
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// averageEmbedding returns the element-wise mean of embeddings.
+func averageEmbedding(embeddings [][]float32) []float32 {
+	if len(embeddings) == 0 {
+		return nil
+	}
+	avg := make([]float32, len(embeddings[0]))
+	for _, emb := range embeddings {
+		for i := 0; i < len(avg) && i < len(emb); i++ {
+			avg[i] += emb[i]
+		}
+	}
+	for i := range avg {
+		avg[i] /= float32(len(embeddings))
+	}
+	return avg
+}
@@ -0,0 +1,42 @@
+package storage
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMergeChunkContents(t *testing.T) {
+	chunks := []string{
+		"The quick brown fox jumps over",
+		"jumps over the lazy dog.",
+		"A second paragraph.",
+	}
+	got := MergeChunkContents(chunks)
+	want := "The quick brown fox jumps over the lazy dog.\nA second paragraph."
+	if got != want {
+		t.Fatalf("MergeChunkContents() = %q, want %q", got, want)
+	}
+
+	if MergeChunkContents(nil) != "" {
+		t.Fatal("expected empty content for no chunks")
+	}
+}
+
+func TestDocumentDiff(t *testing.T) {
+	oldText := "line one\nline two\nline three"
+	newText := "line one\nline 2\nline three\nline four"
+
+	diff := documentDiff(oldText, newText)
+	for _, want := range []string{"@@ -2 +2 @@", "-line two", "+line 2", "@@ -4 +4 @@", "+line four"} {
+		if !strings.Contains(diff, want) {
+			t.Errorf("diff missing %q:\n%s", want, diff)
+		}
+	}
+	if strings.Contains(diff, "line one") {
+		t.Errorf("diff should not include unchanged lines:\n%s", diff)
+	}
+
+	if documentDiff(oldText, oldText) != "" {
+		t.Error("expected empty diff for identical text")
+	}
+}
@@ -11,19 +11,15 @@ func (s *SurrealDBStorage) SaveDocument(ctx context.Context, filePath, content s
 	if metadata == nil {
 		metadata = map[string]interface{}{}
 	}
+	metadata = withProvenance(ctx, metadata)
 
-	if embedding == nil {
-		embedding = make([]float32, defaultMtreeDim)
-	} else if len(embedding) != defaultMtreeDim {
-		norm := make([]float32, defaultMtreeDim)
-		copy(norm, embedding)
-		embedding = norm
+	dim := len(embedding)
+	embedding, err := s.fitEmbedding("knowledge_base", embedding)
+	if err != nil {
+		return err
 	}
 
-	emb64 := make([]float64, len(embedding))
-	for i, v := range embedding {
-		emb64[i] = float64(v)
-	}
+	storedEmb := s.storedEmbedding(embedding)
 
 	existsQuery := "SELECT id FROM knowledge_base WHERE file_path = $file_path"
 	existsResult, err := s.query(ctx, existsQuery, map[string]interface{}{
@@ -41,13 +37,25 @@ func (s *SurrealDBStorage) SaveDocument(ctx context.Context, filePath, content s
 			isNewDocument = false
 		}
 	}
+	if isNewDocument {
+		if err := s.checkQuota(ctx, "knowledge_base", 1); err != nil {
+			return err
+		}
+	}
 
 	params := map[string]interface{}{
 		"file_path": filePath,
 		"content":   content,
-		"embedding": emb64,
+		"embedding": storedEmb,
 		"metadata":  metadata,
 	}
+	s.bindEmbeddingModel(ctx, params, "knowledge_base", dim)
+
+	if !isNewDocument {
+		if err := s.archiveDocument(ctx, filePath, content); err != nil {
+			slog.Warn("failed to archive previous document version", "file_path", filePath, "error", err)
+		}
+	}
 
 	if isNewDocument {
 		query := `
@@ -55,6 +63,8 @@ func (s *SurrealDBStorage) SaveDocument(ctx context.Context, filePath, content s
                 file_path: $file_path,
                 content: $content,
                 embedding: $embedding,
+                embedding_model: $embedding_model,
+                embedding_dim: $embedding_dim,
                 metadata: $metadata
             }
         `
@@ -66,6 +76,8 @@ func (s *SurrealDBStorage) SaveDocument(ctx context.Context, filePath, content s
             UPDATE knowledge_base
             SET content = $content,
                 embedding = $embedding,
+                embedding_model = $embedding_model,
+                embedding_dim = $embedding_dim,
                 metadata = $metadata,
                 updated_at = time::now()
             WHERE file_path = $file_path
@@ -86,17 +98,35 @@ func (s *SurrealDBStorage) SaveDocument(ctx context.Context, filePath, content s
 
 // SearchDocuments performs similarity search on knowledge base documents
 func (s *SurrealDBStorage) SearchDocuments(ctx context.Context, queryEmbedding []float32, limit int) ([]DocumentResult, error) {
+	return s.SearchDocumentsWithOptions(ctx, queryEmbedding, VectorSearchOptions{Limit: limit})
+}
+
+// SearchDocumentsWithOptions performs similarity search on knowledge base documents
+// with KNN tuning and a similarity threshold
+func (s *SurrealDBStorage) SearchDocumentsWithOptions(ctx context.Context, queryEmbedding []float32, opts VectorSearchOptions) ([]DocumentResult, error) {
+	knn, err := knnOperator(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	params := map[string]interface{}{
+		"query_embedding": queryEmbedding,
+	}
 	query := fmt.Sprintf(`
         SELECT id, file_path, content, embedding, metadata, created_at, updated_at,
                vector::similarity::cosine(embedding, $query_embedding) AS similarity
         FROM knowledge_base
-        WHERE embedding <|%d|> $query_embedding
+        WHERE embedding %s $query_embedding%s%s%s%s
         ORDER BY similarity DESC
-    `, limit)
-
-	params := map[string]interface{}{
-		"query_embedding": queryEmbedding,
+    `, knn, minSimilarityClause(opts), kbRootClause(opts), frontmatterClause(opts), s.embeddingModelClause(ctx, "knowledge_base", params))
+	if opts.MinSimilarity > 0 {
+		params["min_similarity"] = opts.MinSimilarity
+	}
+	if len(opts.KBRoots) > 0 {
+		params["kb_roots"] = opts.KBRoots
+		params["default_kb_root"] = DefaultKBRoot
 	}
+	bindFrontmatterFilter(opts, params)
 
 	result, err := s.query(ctx, query, params)
 	if err != nil {
@@ -106,26 +136,70 @@ func (s *SurrealDBStorage) SearchDocuments(ctx context.Context, queryEmbedding [
 	return s.parseDocumentResults(result)
 }
 
-// DeleteDocument deletes a knowledge base document and all its chunks
+// DeleteDocument moves a knowledge base document and all its chunks to the trash
 func (s *SurrealDBStorage) DeleteDocument(ctx context.Context, filePath string) error {
-	// Delete both the source file and all its chunks
-	query := "DELETE FROM knowledge_base WHERE source_file = $file_path OR file_path = $file_path"
 	params := map[string]interface{}{
 		"file_path": filePath,
 	}
+	where := "source_file = $file_path OR file_path = $file_path"
 
-	_, err := s.query(ctx, query, params)
+	result, err := s.query(ctx, "SELECT content, chunk_index FROM knowledge_base WHERE "+where+" ORDER BY chunk_index ASC", params)
 	if err != nil {
 		return fmt.Errorf("failed to delete document: %w", err)
 	}
+	if result == nil || len(*result) == 0 || len((*result)[0].Result) == 0 {
+		return nil
+	}
+	chunks := make([]string, 0, len((*result)[0].Result))
+	for _, row := range (*result)[0].Result {
+		chunks = append(chunks, getString(row, "content"))
+	}
 
-	if err := s.updateUserStat(ctx, "global", "document_count", -1); err != nil {
-		slog.Warn("failed to update document_count stat", "error", err)
+	return s.moveToTrash(ctx, TrashKindDocument, "", filePath, MergeChunkContents(chunks), where, params)
+}
+
+// MergeDocumentMetadata merges metadata into every chunk of an existing document
+func (s *SurrealDBStorage) MergeDocumentMetadata(ctx context.Context, filePath string, metadata map[string]interface{}) error {
+	query := `UPDATE knowledge_base MERGE { metadata: $metadata, updated_at: time::now() } WHERE source_file = $file_path OR file_path = $file_path`
+	params := map[string]interface{}{
+		"file_path": filePath,
+		"metadata":  metadata,
 	}
 
+	if _, err := s.query(ctx, query, params); err != nil {
+		return fmt.Errorf("failed to merge document metadata: %w", err)
+	}
 	return nil
 }
 
+// ListDocuments returns every knowledge base document with its metadata and
+// timestamps, taken from its first chunk. Content and embeddings are left out.
+func (s *SurrealDBStorage) ListDocuments(ctx context.Context) ([]Document, error) {
+	query := `
+		SELECT (source_file ?? file_path) AS doc_path, metadata, created_at, updated_at
+		FROM knowledge_base
+		WHERE (chunk_index ?? 0) = 0
+		ORDER BY doc_path ASC
+	`
+	result, err := s.query(ctx, query, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list documents: %w", err)
+	}
+
+	var documents []Document
+	if result != nil && len(*result) > 0 && (*result)[0].Status == "OK" {
+		for _, row := range (*result)[0].Result {
+			documents = append(documents, Document{
+				FilePath:  getString(row, "doc_path"),
+				Metadata:  getMap(row, "metadata"),
+				CreatedAt: getTime(row, "created_at"),
+				UpdatedAt: getTime(row, "updated_at"),
+			})
+		}
+	}
+	return documents, nil
+}
+
 // GetDocument retrieves a knowledge base document by file path
 func (s *SurrealDBStorage) GetDocument(ctx context.Context, filePath string) (*Document, error) {
 	// Try to find by source_file first (for chunked documents), then by file_path
@@ -151,17 +225,7 @@ func (s *SurrealDBStorage) GetDocument(ctx context.Context, filePath string) (*D
 
 	resultMap := queryResult.Result[0]
 
-	var embedding []float32
-	if embeddingSlice, ok := resultMap["embedding"].([]interface{}); ok {
-		embedding = make([]float32, len(embeddingSlice))
-		for i, v := range embeddingSlice {
-			if f, ok := v.(float64); ok {
-				embedding[i] = float32(f)
-			} else if f, ok := v.(float32); ok {
-				embedding[i] = f
-			}
-		}
-	}
+	embedding := s.decodeStoredEmbedding(resultMap["embedding"])
 
 	document := &Document{
 		ID:        getString(resultMap, "id"),
@@ -175,6 +239,51 @@ func (s *SurrealDBStorage) GetDocument(ctx context.Context, filePath string) (*D
 	return document, nil
 }
 
+// GetDocumentContent returns the full content of a knowledge base document,
+// rebuilt from its chunks. It returns an empty string when the document does
+// not exist.
+func (s *SurrealDBStorage) GetDocumentContent(ctx context.Context, filePath string) (string, error) {
+	query := "SELECT content, chunk_index FROM knowledge_base WHERE source_file = $file_path OR file_path = $file_path ORDER BY chunk_index ASC"
+	result, err := s.query(ctx, query, map[string]interface{}{
+		"file_path": filePath,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to get document content: %w", err)
+	}
+	if result == nil || len(*result) == 0 || (*result)[0].Status != "OK" {
+		return "", nil
+	}
+
+	chunks := make([]string, 0, len((*result)[0].Result))
+	for _, row := range (*result)[0].Result {
+		chunks = append(chunks, getString(row, "content"))
+	}
+	return MergeChunkContents(chunks), nil
+}
+
+// GetDocumentChunkContext returns, for each chunk index of the chunked
+// document filePath, that chunk merged with the radius chunks before and after
+// it. All the chunks are read with a single query.
+func (s *SurrealDBStorage) GetDocumentChunkContext(ctx context.Context, filePath string, indexes []int, radius int) (map[int]string, error) {
+	from, to := chunkWindow(indexes, radius)
+	query := "SELECT content, chunk_index FROM knowledge_base WHERE source_file = $file_path AND chunk_index >= $from AND chunk_index <= $to ORDER BY chunk_index ASC"
+	result, err := s.query(ctx, query, map[string]interface{}{
+		"file_path": filePath,
+		"from":      from,
+		"to":        to,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get document chunks: %w", err)
+	}
+	chunks := map[int]string{}
+	if result != nil && len(*result) > 0 && (*result)[0].Status == "OK" {
+		for _, row := range (*result)[0].Result {
+			chunks[convertToInt(row["chunk_index"])] = getString(row, "content")
+		}
+	}
+	return chunkContext(chunks, indexes, radius), nil
+}
+
 func (s *SurrealDBStorage) parseDocumentResults(result *[]QueryResult) ([]DocumentResult, error) {
 	var results []DocumentResult
 
@@ -183,17 +292,7 @@ func (s *SurrealDBStorage) parseDocumentResults(result *[]QueryResult) ([]Docume
 		if queryResult.Status == "OK" && queryResult.Result != nil {
 			resultSlice := queryResult.Result
 			for _, itemMap := range resultSlice {
-				var embedding []float32
-				if embeddingSlice, ok := itemMap["embedding"].([]interface{}); ok {
-					embedding = make([]float32, len(embeddingSlice))
-					for i, v := range embeddingSlice {
-						if f, ok := v.(float64); ok {
-							embedding[i] = float32(f)
-						} else if f, ok := v.(float32); ok {
-							embedding[i] = f
-						}
-					}
-				}
+				embedding := s.decodeStoredEmbedding(itemMap["embedding"])
 
 				document := &Document{
 					ID:        getString(itemMap, "id"),
@@ -233,36 +332,39 @@ func (s *SurrealDBStorage) SaveDocumentChunks(ctx context.Context, filePath stri
 	if metadata == nil {
 		metadata = map[string]interface{}{}
 	}
+	metadata = withProvenance(ctx, metadata)
+
+	// Check every embedding before the current chunks are replaced
+	fitted := make([][]float32, len(embeddings))
+	for i, embedding := range embeddings {
+		var err error
+		if fitted[i], err = s.fitEmbedding("knowledge_base", embedding); err != nil {
+			return fmt.Errorf("chunk %d: %w", i, err)
+		}
+	}
 
-	// First, delete any existing chunks for this file
-	deleteQuery := "DELETE FROM knowledge_base WHERE source_file = $file_path OR file_path = $file_path"
-	if err := s.withTxnRetry(ctx, func(ctx context.Context) error {
-		_, err := s.query(ctx, deleteQuery, map[string]interface{}{
-			"file_path": filePath,
-		})
+	if err := s.checkDocumentQuota(ctx, filePath, len(chunks)); err != nil {
 		return err
-	}); err != nil {
-		return fmt.Errorf("failed to delete existing chunks: %w", err)
 	}
 
+	// Keep the current revision in kb_document_versions before replacing it
+	if err := s.archiveDocument(ctx, filePath, MergeChunkContents(chunks)); err != nil {
+		slog.Warn("failed to archive previous document version", "file_path", filePath, "error", err)
+	}
+
+	// The old chunks are deleted and the new ones created in one transaction,
+	// so a crash part way never leaves the document missing or half written
+	tx := newSurrealTx()
+	tx.add("DELETE FROM knowledge_base WHERE source_file = $file_path OR file_path = $file_path RETURN NONE", map[string]interface{}{
+		"file_path": filePath,
+	})
+
 	chunkCount := len(chunks)
 
 	// Insert each chunk as a separate document
 	for i, chunk := range chunks {
-		embedding := embeddings[i]
-
-		// Normalize embedding to defaultMtreeDim
-		if len(embedding) != defaultMtreeDim {
-			norm := make([]float32, defaultMtreeDim)
-			copy(norm, embedding)
-			embedding = norm
-		}
-
-		// Convert to float64 for SurrealDB
-		emb64 := make([]float64, len(embedding))
-		for j, v := range embedding {
-			emb64[j] = float64(v)
-		}
+		// Convert to the configured storage format for SurrealDB
+		storedEmb := s.storedEmbedding(fitted[i])
 
 		// Create unique file_path for each chunk
 		chunkFilePath := fmt.Sprintf("%s#chunk%d", filePath, i)
@@ -278,29 +380,31 @@ func (s *SurrealDBStorage) SaveDocumentChunks(ctx context.Context, filePath stri
 		params := map[string]interface{}{
 			"file_path":   chunkFilePath,
 			"content":     chunk,
-			"embedding":   emb64,
+			"embedding":   storedEmb,
 			"metadata":    chunkMetadata,
 			"chunk_index": i,
 			"chunk_count": chunkCount,
 			"source_file": filePath,
 		}
+		s.bindEmbeddingModel(ctx, params, "knowledge_base", len(embeddings[i]))
 
-		query := `
+		tx.add(`
 			CREATE knowledge_base CONTENT {
 				file_path: $file_path,
 				content: $content,
 				embedding: $embedding,
+				embedding_model: $embedding_model,
+				embedding_dim: $embedding_dim,
 				metadata: $metadata,
 				chunk_index: $chunk_index,
 				chunk_count: $chunk_count,
 				source_file: $source_file
-			}
-		`
+			} RETURN NONE
+		`, params)
+	}
 
-		_, err := s.query(ctx, query, params)
-		if err != nil {
-			return fmt.Errorf("failed to create chunk %d: %w", i, err)
-		}
+	if err := s.commitTx(ctx, tx); err != nil {
+		return fmt.Errorf("failed to replace chunks of %s: %w", filePath, err)
 	}
 
 	// Update document count stat (count by source_file, not by chunks)
@@ -5,10 +5,15 @@ import (
 	"fmt"
 	"log/slog"
 	"strings"
+	"time"
 )
 
-// CreateEntity creates a new entity in the graph
+// CreateEntity creates a new entity in the graph. Its properties must match
+// the schema of its type, if it has one.
 func (s *SurrealDBStorage) CreateEntity(ctx context.Context, entityType, name string, properties map[string]interface{}) error {
+	if err := checkEntityProperties(ctx, s.GetEntityType, entityType, properties); err != nil {
+		return err
+	}
 	if properties == nil {
 		properties = map[string]interface{}{}
 	}
@@ -45,7 +50,7 @@ func (s *SurrealDBStorage) CreateEntity(ctx context.Context, entityType, name st
 	return fmt.Errorf("failed to create entity")
 }
 
-// resolveEntityID resolves an entity name to its SurrealDB record ID
+// resolveEntityID resolves an entity name or alias to its SurrealDB record ID
 func (s *SurrealDBStorage) resolveEntityID(ctx context.Context, entityNameOrID string) (string, error) {
 	if strings.Contains(entityNameOrID, ":") {
 		query := "SELECT * FROM " + entityNameOrID
@@ -58,31 +63,44 @@ func (s *SurrealDBStorage) resolveEntityID(ctx context.Context, entityNameOrID s
 		}
 	}
 
-	query := "SELECT * FROM entities WHERE name = $name"
-	result, err := s.query(ctx, query, map[string]interface{}{"name": entityNameOrID})
+	resultMap, err := s.findEntityByName(ctx, entityNameOrID)
 	if err != nil {
 		return "", fmt.Errorf("failed to query entity by name: %w", err)
 	}
-
-	if result == nil || len(*result) == 0 {
-		return "", fmt.Errorf("entity not found: %s", entityNameOrID)
+	if resultMap == nil {
+		return "", fmt.Errorf("entity %w: %s", ErrNotFound, entityNameOrID)
 	}
 
-	queryResult := (*result)[0]
-	if queryResult.Status != "OK" || queryResult.Result == nil || len(queryResult.Result) == 0 {
-		return "", fmt.Errorf("entity not found: %s", entityNameOrID)
-	}
-
-	resultMap := queryResult.Result[0]
 	entityID := extractRecordID(resultMap["id"])
 	if entityID == "" {
-		return "", fmt.Errorf("entity ID not found for name: %s", entityNameOrID)
+		return "", fmt.Errorf("entity ID %w for name: %s", ErrNotFound, entityNameOrID)
 	}
 
 	return entityID, nil
 }
 
-// CreateRelationship creates a relationship between two entities
+// findEntityByName returns the first entity named name or, failing that,
+// the first with name among its aliases, or nil.
+func (s *SurrealDBStorage) findEntityByName(ctx context.Context, name string) (map[string]interface{}, error) {
+	for _, query := range []string{
+		"SELECT * FROM entities WHERE name = $name",
+		"SELECT * FROM entities WHERE properties." + AliasesProperty + " CONTAINS $name",
+	} {
+		result, err := s.query(ctx, query, map[string]interface{}{"name": name})
+		if err != nil {
+			return nil, err
+		}
+		if result != nil && len(*result) > 0 && (*result)[0].Status == "OK" && len((*result)[0].Result) > 0 {
+			return (*result)[0].Result[0], nil
+		}
+	}
+	return nil, nil
+}
+
+// CreateRelationship creates a relationship between two entities, valid from
+// now unless ctx comes from WithValidity. The current relationship of the same
+// type between them, if any, is ended when the new one starts so that its
+// history is kept.
 func (s *SurrealDBStorage) CreateRelationship(ctx context.Context, fromEntity, toEntity, relationshipType string, properties map[string]interface{}) error {
 	fromEntityID, err := s.resolveEntityID(ctx, fromEntity)
 	if err != nil {
@@ -102,22 +120,34 @@ func (s *SurrealDBStorage) CreateRelationship(ctx context.Context, fromEntity, t
 		// Table might already exist; SurrealDB returns an error we can ignore here.
 	}
 
-	query := fmt.Sprintf(`
-        INSERT INTO %s {
-            from_entity: $from,
-            to_entity: $to,
-            relationship_type: $relationshipType,
-            properties: $properties
-        }
-    `, tableName)
-
+	from, until, _ := validityFromContext(ctx)
 	params := map[string]interface{}{
 		"from":             fromEntityID,
 		"to":               toEntityID,
 		"relationshipType": relationshipType,
 		"properties":       properties,
+		"valid_from":       from.Format(time.RFC3339Nano),
+	}
+	validUntil := ""
+	if until != nil {
+		params["valid_until"] = until.Format(time.RFC3339Nano)
+		validUntil = ",\n            valid_until: <datetime>$valid_until"
 	}
 
+	if _, err := s.query(ctx, endRelationshipsStatement(tableName, "from", "to", "valid_from")+" RETURN NONE", params); err != nil {
+		return fmt.Errorf("failed to end replaced relationship: %w", err)
+	}
+
+	query := fmt.Sprintf(`
+        INSERT INTO %s {
+            from_entity: $from,
+            to_entity: $to,
+            relationship_type: $relationshipType,
+            properties: $properties,
+            valid_from: <datetime>$valid_from%s
+        }
+    `, tableName, validUntil)
+
 	result, err := s.query(ctx, query, params)
 	if err != nil {
 		return fmt.Errorf("failed to create relationship: %w", err)
@@ -136,8 +166,52 @@ func (s *SurrealDBStorage) CreateRelationship(ctx context.Context, fromEntity, t
 	return fmt.Errorf("failed to create relationship")
 }
 
+// EndRelationships ends the relationships of a type between two entities
+// that still hold at the given time, keeping them as history, and returns
+// how many were ended.
+func (s *SurrealDBStorage) EndRelationships(ctx context.Context, fromEntity, toEntity, relationshipType string, at time.Time) (int, error) {
+	if !relationshipTableRe.MatchString(relationshipType) {
+		return 0, fmt.Errorf("invalid relationship_type %q: use letters, digits and underscores", relationshipType)
+	}
+	fromEntityID, err := s.resolveEntityID(ctx, fromEntity)
+	if err != nil {
+		return 0, fmt.Errorf("failed to resolve from entity '%s': %w", fromEntity, err)
+	}
+	toEntityID, err := s.resolveEntityID(ctx, toEntity)
+	if err != nil {
+		return 0, fmt.Errorf("failed to resolve to entity '%s': %w", toEntity, err)
+	}
+
+	result, err := s.query(ctx, endRelationshipsStatement(relationshipType, "from", "to", "at")+" RETURN id", map[string]interface{}{
+		"from": fromEntityID,
+		"to":   toEntityID,
+		"at":   at.UTC().Format(time.RFC3339Nano),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to end relationships: %w", err)
+	}
+	if result == nil || len(*result) == 0 {
+		return 0, nil
+	}
+	return len((*result)[0].Result), nil
+}
+
+// endRelationshipsStatement ends the relationships of table between the
+// entities bound to the from and to params that hold at the time bound to
+// the at param.
+func endRelationshipsStatement(table, from, to, at string) string {
+	return fmt.Sprintf("UPDATE %[1]s SET valid_until = <datetime>$%[4]s WHERE from_entity = $%[2]s AND to_entity = $%[3]s AND (valid_from = NONE OR valid_from < <datetime>$%[4]s) AND (valid_until = NONE OR valid_until > <datetime>$%[4]s)", table, from, to, at)
+}
+
 // TraverseGraph traverses the graph starting from an entity
 func (s *SurrealDBStorage) TraverseGraph(ctx context.Context, startEntity, relationshipType string, depth int) ([]GraphResult, error) {
+	return s.TraverseGraphAsOf(ctx, startEntity, relationshipType, depth, time.Time{})
+}
+
+// TraverseGraphAsOf traverses the graph as it was at a point in time. Like
+// the relationship type and depth, the time does not narrow the SurrealDB
+// traversal yet, which lists the entities.
+func (s *SurrealDBStorage) TraverseGraphAsOf(ctx context.Context, startEntity, relationshipType string, depth int, at time.Time) ([]GraphResult, error) {
 	// Resolve the start entity name to its ID
 	startEntityID, err := s.resolveEntityID(ctx, startEntity)
 	if err != nil {
@@ -159,54 +233,189 @@ func (s *SurrealDBStorage) TraverseGraph(ctx context.Context, startEntity, relat
 	return s.parseGraphResults(result)
 }
 
-// GetEntity retrieves an entity by ID or name
+// GetEntity retrieves an entity by ID, name or alias
 func (s *SurrealDBStorage) GetEntity(ctx context.Context, entityID string) (*Entity, error) {
-	query := "SELECT * FROM " + entityID
-	result, err := s.query(ctx, query, nil)
-	if err != nil {
-		query = "SELECT * FROM entities WHERE name = $name"
-		result, err = s.query(ctx, query, map[string]interface{}{"name": entityID})
-		if err != nil {
-			return nil, fmt.Errorf("failed to get entity: %w", err)
+	var resultMap map[string]interface{}
+	if strings.HasPrefix(entityID, "entities:") {
+		result, err := s.query(ctx, "SELECT * FROM "+entityID, nil)
+		if err == nil && result != nil && len(*result) > 0 && (*result)[0].Status == "OK" && len((*result)[0].Result) > 0 {
+			resultMap = (*result)[0].Result[0]
 		}
 	}
-
-	if result == nil || len(*result) == 0 {
-		return nil, nil
+	if resultMap == nil {
+		var err error
+		if resultMap, err = s.findEntityByName(ctx, entityID); err != nil {
+			return nil, fmt.Errorf("failed to get entity: %w", err)
+		}
 	}
-
-	queryResult := (*result)[0]
-	if queryResult.Status != "OK" || queryResult.Result == nil || len(queryResult.Result) == 0 {
+	if resultMap == nil {
 		return nil, nil
 	}
 
-	resultMap := queryResult.Result[0]
 	entity := &Entity{
-		ID:         getString(resultMap, "id"),
+		ID:         extractRecordID(resultMap["id"]),
 		Type:       getString(resultMap, "type"),
 		Name:       getString(resultMap, "name"),
 		Properties: getMap(resultMap, "properties"),
 		CreatedAt:  getTime(resultMap, "created_at"),
 		UpdatedAt:  getTime(resultMap, "updated_at"),
 	}
+	if entity.Type == "" {
+		entity.Type = getString(resultMap, "entity_type")
+	}
 	return entity, nil
 }
 
-// DeleteEntity deletes an entity and its relationships
+// UpdateEntity updates the properties of an entity, merging them into the
+// current ones unless replace is set, and returns the entity updated. The
+// result must match the schema of the entity type, if it has one.
+func (s *SurrealDBStorage) UpdateEntity(ctx context.Context, entity string, properties map[string]interface{}, replace bool) (*Entity, error) {
+	current, err := s.GetEntity(ctx, entity)
+	if err != nil {
+		return nil, err
+	}
+	if current == nil {
+		return nil, fmt.Errorf("entity %w: %s", ErrNotFound, entity)
+	}
+	updated := updatedEntityProperties(current.Properties, properties, replace)
+	if err := checkEntityProperties(ctx, s.GetEntityType, current.Type, updated); err != nil {
+		return nil, err
+	}
+	table, key, err := splitRecordID(current.ID)
+	if err != nil {
+		return nil, err
+	}
+	_, err = s.query(ctx, "UPDATE type::thing($rec_table, $rec_key) SET properties = $properties, updated_at = time::now() RETURN NONE", map[string]interface{}{
+		"rec_table":  table,
+		"rec_key":    key,
+		"properties": updated,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update entity: %w", err)
+	}
+	return s.GetEntity(ctx, current.ID)
+}
+
+// ListEntities returns up to limit entities, of entityType unless it is
+// empty, by name.
+func (s *SurrealDBStorage) ListEntities(ctx context.Context, entityType string, limit int) ([]Entity, error) {
+	query := "SELECT * FROM entities"
+	if entityType != "" {
+		query += " WHERE entity_type = $type OR type = $type"
+	}
+	query += " ORDER BY name LIMIT $limit"
+	result, err := s.query(ctx, query, map[string]interface{}{"type": entityType, "limit": limit})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list entities: %w", err)
+	}
+	var entities []Entity
+	if result != nil && len(*result) > 0 {
+		for _, row := range (*result)[0].Result {
+			entity := Entity{
+				ID:         extractRecordID(row["id"]),
+				Type:       getString(row, "entity_type"),
+				Name:       getString(row, "name"),
+				Properties: getMap(row, "properties"),
+				CreatedAt:  getTime(row, "created_at"),
+				UpdatedAt:  getTime(row, "updated_at"),
+			}
+			if entity.Type == "" {
+				entity.Type = getString(row, "type")
+			}
+			entities = append(entities, entity)
+		}
+	}
+	return entities, nil
+}
+
+// MergeEntities merges duplicate entities into survivor: their relationships
+// and usage hits move to survivor, relationships between the merged entities
+// are dropped, their names become aliases of survivor, the properties
+// survivor lacks are copied, and the duplicates go to the trash.
+func (s *SurrealDBStorage) MergeEntities(ctx context.Context, survivor string, duplicates []string) (*EntityMerge, error) {
+	keep, merged, err := mergeTargets(ctx, s.GetEntity, survivor, duplicates)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, len(merged))
+	for i, e := range merged {
+		ids[i] = e.ID
+	}
+	properties, aliases := mergeEntityProperties(*keep, merged)
+	if err := checkEntityProperties(ctx, s.GetEntityType, keep.Type, properties); err != nil {
+		return nil, err
+	}
+	result := &EntityMerge{Merged: ids, Aliases: aliases}
+
+	tables, err := s.getRelationshipTables(ctx)
+	if err != nil {
+		return nil, err
+	}
+	params := map[string]interface{}{"ids": ids, "keep": keep.ID}
+	between := "(from_entity IN $ids OR from_entity = $keep) AND (to_entity IN $ids OR to_entity = $keep)"
+	tx := newSurrealTx()
+	for _, table := range tables {
+		result.SelfRelationshipsDropped += s.getCount(ctx, fmt.Sprintf("SELECT count() AS count FROM %s WHERE from_entity != NONE AND %s GROUP ALL", table, between), params)
+		result.RelationshipsRewired += s.getCount(ctx, fmt.Sprintf("SELECT count() AS count FROM %s WHERE (from_entity IN $ids OR to_entity IN $ids) AND NOT (%s) GROUP ALL", table, between), params)
+		tx.add(fmt.Sprintf("DELETE FROM %s WHERE from_entity != NONE AND %s", table, between), params)
+		tx.add(fmt.Sprintf("UPDATE %s SET from_entity = $keep WHERE from_entity IN $ids", table), params)
+		tx.add(fmt.Sprintf("UPDATE %s SET to_entity = $keep WHERE to_entity IN $ids", table), params)
+	}
+
+	hitParams := map[string]interface{}{"ids": ids, "keep": keep.ID, "kind": TrashKindEntity}
+	result.HitsMoved = s.getCount(ctx, "SELECT count() AS count FROM memory_hits WHERE kind = $kind AND ref IN $ids GROUP ALL", hitParams)
+	tx.add("UPDATE memory_hits SET ref = $keep WHERE kind = $kind AND ref IN $ids", hitParams)
+
+	table, key, err := splitRecordID(keep.ID)
+	if err != nil {
+		return nil, err
+	}
+	tx.add("UPDATE type::thing($rec_table, $rec_key) SET properties = $properties", map[string]interface{}{
+		"rec_table":  table,
+		"rec_key":    key,
+		"properties": properties,
+	})
+	if err := s.commitTx(ctx, tx); err != nil {
+		return nil, fmt.Errorf("failed to merge entities: %w", err)
+	}
+
+	for _, id := range ids {
+		if err := s.DeleteEntity(ctx, id); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.updateUserStat(ctx, "global", "relationship_count", 0); err != nil {
+		slog.Warn("failed to update relationship_count stat", "error", err)
+	}
+
+	if result.Survivor, err = s.GetEntity(ctx, keep.ID); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// DeleteEntity moves an entity to the trash
 func (s *SurrealDBStorage) DeleteEntity(ctx context.Context, entityID string) error {
-	// Use DELETE FROM WHERE to avoid deserialization issues with newlines
-	deleteQuery := `DELETE FROM entities WHERE id = $id`
-	params := map[string]interface{}{"id": entityID}
-	_, err := s.query(ctx, deleteQuery, params)
+	table, key, err := splitRecordID(entityID)
 	if err != nil {
-		return fmt.Errorf("failed to delete entity: %w", err)
+		return err
+	}
+	params := map[string]interface{}{
+		"rec_table": table,
+		"rec_key":   key,
 	}
 
-	if err := s.updateUserStat(ctx, "global", "entity_count", -1); err != nil {
-		slog.Warn("failed to update entity_count stat", "error", err)
+	result, err := s.query(ctx, "SELECT name, type FROM type::thing($rec_table, $rec_key)", params)
+	if err != nil {
+		return fmt.Errorf("failed to delete entity: %w", err)
+	}
+	if result == nil || len(*result) == 0 || len((*result)[0].Result) == 0 {
+		return nil
 	}
+	row := (*result)[0].Result[0]
+	content := fmt.Sprintf("%s (%s)", getString(row, "name"), getString(row, "type"))
 
-	return nil
+	return s.moveToTrash(ctx, TrashKindEntity, "", entityID, content, "id = type::thing($rec_table, $rec_key)", params)
 }
 
 func (s *SurrealDBStorage) parseGraphResults(result *[]QueryResult) ([]GraphResult, error) {
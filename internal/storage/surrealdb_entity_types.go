@@ -0,0 +1,84 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+)
+
+// SaveEntityType stores the schema of an entity type, replacing the one it
+// had. Existing entities are not checked against it.
+func (s *SurrealDBStorage) SaveEntityType(ctx context.Context, entityType EntityType) error {
+	if err := CheckPropertySchema(entityType.Schema); err != nil {
+		return err
+	}
+	query := `
+		INSERT INTO entity_types {
+			name: $name,
+			description: $description,
+			properties_schema: $schema
+		}
+		ON DUPLICATE KEY UPDATE
+			description = $input.description,
+			properties_schema = $input.properties_schema,
+			updated_at = time::now()
+		RETURN NONE`
+	_, err := s.query(ctx, query, map[string]interface{}{
+		"name":        entityType.Name,
+		"description": entityType.Description,
+		"schema":      entityType.Schema,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save entity type: %w", err)
+	}
+	return nil
+}
+
+// GetEntityType returns the schema of an entity type, or nil when it has
+// none.
+func (s *SurrealDBStorage) GetEntityType(ctx context.Context, name string) (*EntityType, error) {
+	result, err := s.query(ctx, "SELECT * FROM entity_types WHERE name = $name LIMIT 1", map[string]interface{}{"name": name})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get entity type: %w", err)
+	}
+	if result == nil || len(*result) == 0 || (*result)[0].Status != "OK" || len((*result)[0].Result) == 0 {
+		return nil, nil
+	}
+	entityType := entityTypeFromRow((*result)[0].Result[0])
+	return &entityType, nil
+}
+
+// ListEntityTypes returns the entity types with a schema, by name.
+func (s *SurrealDBStorage) ListEntityTypes(ctx context.Context) ([]EntityType, error) {
+	result, err := s.query(ctx, "SELECT * FROM entity_types ORDER BY name ASC", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list entity types: %w", err)
+	}
+	types := []EntityType{}
+	if result != nil && len(*result) > 0 && (*result)[0].Status == "OK" {
+		for _, row := range (*result)[0].Result {
+			types = append(types, entityTypeFromRow(row))
+		}
+	}
+	return types, nil
+}
+
+// DeleteEntityType removes the schema of an entity type and reports whether
+// it had one. Its entities are kept.
+func (s *SurrealDBStorage) DeleteEntityType(ctx context.Context, name string) (bool, error) {
+	result, err := s.query(ctx, "DELETE FROM entity_types WHERE name = $name RETURN BEFORE", map[string]interface{}{"name": name})
+	if err != nil {
+		return false, fmt.Errorf("failed to delete entity type: %w", err)
+	}
+	return result != nil && len(*result) > 0 && len((*result)[0].Result) > 0, nil
+}
+
+// entityTypeFromRow converts a row of the entity_types table.
+func entityTypeFromRow(row map[string]interface{}) EntityType {
+	return EntityType{
+		Name:        getString(row, "name"),
+		Description: getString(row, "description"),
+		Schema:      getMap(row, "properties_schema"),
+		CreatedAt:   getTime(row, "created_at"),
+		UpdatedAt:   getTime(row, "updated_at"),
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"strings"
 	"time"
 )
 
@@ -40,24 +41,24 @@ type EventSearchResult struct {
 
 // SaveEvent stores a new event with embedding for semantic search
 func (s *SurrealDBStorage) SaveEvent(ctx context.Context, userID, subject, content string, embedding []float32, metadata map[string]interface{}) (string, time.Time, error) {
+	if err := s.checkQuota(ctx, "events", 1); err != nil {
+		return "", time.Time{}, err
+	}
+
 	if metadata == nil {
 		metadata = map[string]interface{}{}
 	}
+	metadata = withProvenance(ctx, metadata)
 
 	// Normalize embedding length to the MTREE dimension
-	if embedding == nil {
-		embedding = make([]float32, defaultMtreeDim)
-	} else if len(embedding) != defaultMtreeDim {
-		norm := make([]float32, defaultMtreeDim)
-		copy(norm, embedding)
-		embedding = norm
+	dim := len(embedding)
+	embedding, err := s.fitEmbedding("events", embedding)
+	if err != nil {
+		return "", time.Time{}, err
 	}
 
-	// Convert embedding to []float64 for SurrealDB JSON consistency
-	emb64 := make([]float64, len(embedding))
-	for i, v := range embedding {
-		emb64[i] = float64(v)
-	}
+	// Convert embedding to the configured storage format for SurrealDB
+	storedEmb := s.storedEmbedding(embedding)
 
 	query := `
 		INSERT INTO events {
@@ -65,6 +66,8 @@ func (s *SurrealDBStorage) SaveEvent(ctx context.Context, userID, subject, conte
 			subject: $subject,
 			content: $content,
 			embedding: $embedding,
+			embedding_model: $embedding_model,
+			embedding_dim: $embedding_dim,
 			metadata: $metadata,
 			created_at: time::now()
 		} RETURN id, created_at
@@ -73,9 +76,10 @@ func (s *SurrealDBStorage) SaveEvent(ctx context.Context, userID, subject, conte
 		"user_id":   userID,
 		"subject":   subject,
 		"content":   content,
-		"embedding": emb64,
+		"embedding": storedEmb,
 		"metadata":  metadata,
 	}
+	s.bindEmbeddingModel(ctx, params, "events", dim)
 
 	result, err := s.query(ctx, query, params)
 	if err != nil {
@@ -173,6 +177,12 @@ func (s *SurrealDBStorage) SearchEvents(ctx context.Context, params EventSearchP
 		queryParams["to_date"] = toDate.UTC().Truncate(time.Second).Format(time.RFC3339)
 	}
 
+	if params.Embedding != nil {
+		if clause := s.embeddingModelClause(ctx, "events", queryParams); clause != "" {
+			conditions = append(conditions, strings.TrimPrefix(clause, " AND "))
+		}
+	}
+
 	whereClause := ""
 	for i, cond := range conditions {
 		if i == 0 {
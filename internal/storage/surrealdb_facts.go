@@ -4,42 +4,23 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"time"
 )
 
-// SaveFact saves a key-value fact for a user
+// SaveFact saves a key-value fact for a user. The value it replaces is kept
+// in kv_memory_history.
 func (s *SurrealDBStorage) SaveFact(ctx context.Context, userID, key string, value interface{}) error {
-	existingID, err := s.findFactRecordID(ctx, userID, key)
+	current, err := s.currentFact(ctx, userID, key)
 	if err != nil {
 		return fmt.Errorf("failed to check existing fact: %w", err)
 	}
-
-	if existingID != "" {
-		// Fact exists - use DELETE FROM WHERE strategy to avoid response deserialization
-		// DELETE FROM doesn't return the deleted records by default
-		deleteQuery := `DELETE FROM kv_memories WHERE user_id = $user_id AND key = $key`
-		params := map[string]interface{}{
-			"user_id": userID,
-			"key":     key,
-		}
-		if _, err := s.query(ctx, deleteQuery, params); err != nil {
-			return fmt.Errorf("failed to delete existing fact: %w", err)
+	if current == nil {
+		if err := s.checkQuota(ctx, "kv_memories", 1); err != nil {
+			return err
 		}
 	}
 
-	// Create (or recreate) the fact using query syntax
-	query := `
-		CREATE kv_memories CONTENT {
-			user_id: $user_id,
-			key: $key,
-			value: $value
-		}
-	`
-	params := map[string]interface{}{
-		"user_id": userID,
-		"key":     key,
-		"value":   value,
-	}
-	if _, err := s.query(ctx, query, params); err != nil {
+	if err := s.replaceFact(ctx, userID, key, value, current); err != nil {
 		return fmt.Errorf("failed to save fact: %w", err)
 	}
 
@@ -80,64 +61,45 @@ func (s *SurrealDBStorage) GetFact(ctx context.Context, userID, key string) (int
 	return factData["value"], nil
 }
 
-// UpdateFact updates a key-value fact for a user
+// UpdateFact updates a key-value fact for a user. The previous value is kept
+// in kv_memory_history.
 func (s *SurrealDBStorage) UpdateFact(ctx context.Context, userID, key string, value interface{}) error {
-	recordID, err := s.findFactRecordID(ctx, userID, key)
+	current, err := s.currentFact(ctx, userID, key)
 	if err != nil {
 		return fmt.Errorf("failed to check existing fact: %w", err)
 	}
-	if recordID == "" {
-		return fmt.Errorf("fact not found for user %s and key %s", userID, key)
-	}
-
-	// Use DELETE FROM WHERE + CREATE strategy to avoid response deserialization issues
-	deleteQuery := `DELETE FROM kv_memories WHERE user_id = $user_id AND key = $key`
-	params := map[string]interface{}{
-		"user_id": userID,
-		"key":     key,
-	}
-	if _, err := s.query(ctx, deleteQuery, params); err != nil {
-		return fmt.Errorf("failed to delete existing fact: %w", err)
+	if current == nil {
+		return fmt.Errorf("fact %w for user %s and key %s", ErrNotFound, userID, key)
 	}
 
-	// Recreate with new value
-	query := `
-		CREATE kv_memories CONTENT {
-			user_id: $user_id,
-			key: $key,
-			value: $value
-		}
-	`
-	params = map[string]interface{}{
-		"user_id": userID,
-		"key":     key,
-		"value":   value,
+	if err := s.replaceFact(ctx, userID, key, value, current); err != nil {
+		return fmt.Errorf("failed to update fact: %w", err)
 	}
-	if _, err := s.query(ctx, query, params); err != nil {
-		return fmt.Errorf("failed to recreate fact: %w", err)
-	}
-
 	return nil
 }
 
-// DeleteFact deletes a key-value fact for a user
+// DeleteFact moves a key-value fact for a user to the trash. Its value is
+// kept in kv_memory_history as held until the deletion.
 func (s *SurrealDBStorage) DeleteFact(ctx context.Context, userID, key string) error {
+	current, err := s.currentFact(ctx, userID, key)
+	if err != nil {
+		return fmt.Errorf("failed to delete fact: %w", err)
+	}
+	if current == nil || current["value"] == nil {
+		return nil
+	}
+
 	params := map[string]interface{}{
 		"user_id": userID,
 		"key":     key,
 	}
-
-	// Use DELETE FROM WHERE without RETURN to avoid deserialization issues
-	deleteQuery := "DELETE FROM kv_memories WHERE user_id = $user_id AND key = $key"
-	_, err := s.query(ctx, deleteQuery, params)
-	if err != nil {
-		return fmt.Errorf("failed to delete fact: %w", err)
+	if err := s.moveToTrash(ctx, TrashKindFact, userID, key, trashContent(current["value"]), "user_id = $user_id AND key = $key", params); err != nil {
+		return err
 	}
-
-	if err := s.updateUserStat(ctx, userID, "key_value_count", -1); err != nil {
-		slog.Warn("failed to update key_value_count stat", "user_id", userID, "error", err)
+	// The deleted value stays in the history, held until now
+	if err := s.archiveFact(ctx, userID, key, current, time.Now().UTC()); err != nil {
+		slog.Warn("failed to keep deleted fact in history", "user_id", userID, "key", key, "error", err)
 	}
-
 	return nil
 }
 
@@ -176,26 +138,161 @@ func (s *SurrealDBStorage) ListFacts(ctx context.Context, userID string) (map[st
 	return facts, nil
 }
 
-// findFactRecordID returns the SurrealDB record ID for a fact or an empty string if it does not exist.
-func (s *SurrealDBStorage) findFactRecordID(ctx context.Context, userID, key string) (string, error) {
-	query := "SELECT id FROM kv_memories WHERE user_id = $user_id AND key = $key LIMIT 1"
-	params := map[string]interface{}{
+// ListFactProvenance returns the provenance of the facts of a user that were
+// saved with one, by key.
+func (s *SurrealDBStorage) ListFactProvenance(ctx context.Context, userID string) (map[string]Provenance, error) {
+	result, err := s.query(ctx, "SELECT key, provenance FROM kv_memories WHERE user_id = $user_id", map[string]interface{}{
+		"user_id": userID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list fact provenance: %w", err)
+	}
+
+	provenance := map[string]Provenance{}
+	if result != nil && len(*result) > 0 && (*result)[0].Status == "OK" {
+		for _, row := range (*result)[0].Result {
+			if p, ok := ProvenanceFromMap(row["provenance"]); ok {
+				provenance[getString(row, "key")] = p
+			}
+		}
+	}
+	return provenance, nil
+}
+
+// GetFactHistory returns the current value of a fact and the values it held
+// before, newest first.
+func (s *SurrealDBStorage) GetFactHistory(ctx context.Context, userID, key string) ([]FactVersion, error) {
+	versions, err := s.factVersions(ctx, "user_id = $user_id AND key = $key", map[string]interface{}{
 		"user_id": userID,
 		"key":     key,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get fact history: %w", err)
+	}
+	sortFactVersions(versions)
+	return versions, nil
+}
+
+// ListFactsAsOf returns the versions of the facts of a user valid at a point
+// in time, sorted by key.
+func (s *SurrealDBStorage) ListFactsAsOf(ctx context.Context, userID string, at time.Time) ([]FactVersion, error) {
+	versions, err := s.factVersions(ctx, "user_id = $user_id", map[string]interface{}{
+		"user_id": userID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list facts as of %s: %w", at.Format(time.RFC3339), err)
 	}
+	return factsAsOf(versions, at), nil
+}
 
-	result, err := s.query(ctx, query, params)
+// factVersions returns the current and past versions of the facts matching
+// where.
+func (s *SurrealDBStorage) factVersions(ctx context.Context, where string, params map[string]interface{}) ([]FactVersion, error) {
+	var versions []FactVersion
+	for _, table := range []string{"kv_memories", "kv_memory_history"} {
+		result, err := s.query(ctx, "SELECT * FROM "+table+" WHERE "+where, params)
+		if err != nil {
+			return nil, err
+		}
+		if result == nil || len(*result) == 0 || (*result)[0].Status != "OK" {
+			continue
+		}
+		for _, row := range (*result)[0].Result {
+			versions = append(versions, factVersionFromRow(row, table == "kv_memories"))
+		}
+	}
+	return versions, nil
+}
+
+// currentFact returns the kv_memories row of a fact, or nil when it does not
+// exist.
+func (s *SurrealDBStorage) currentFact(ctx context.Context, userID, key string) (map[string]interface{}, error) {
+	query := "SELECT value, provenance, valid_from, valid_until, created_at FROM kv_memories WHERE user_id = $user_id AND key = $key LIMIT 1"
+	result, err := s.query(ctx, query, map[string]interface{}{
+		"user_id": userID,
+		"key":     key,
+	})
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	if result == nil || len(*result) == 0 {
-		return "", nil
+	if result == nil || len(*result) == 0 || (*result)[0].Status != "OK" || len((*result)[0].Result) == 0 {
+		return nil, nil
 	}
+	return (*result)[0].Result[0], nil
+}
 
-	queryResult := (*result)[0]
-	if queryResult.Status != "OK" || queryResult.Result == nil || len(queryResult.Result) == 0 {
-		return "", nil
+// replaceFact writes the value of a fact over current, its existing row if
+// any. A changed value, or one given an explicit validity with
+// WithValidity, moves current to kv_memory_history; saving the same value
+// again keeps the time it has held since.
+func (s *SurrealDBStorage) replaceFact(ctx context.Context, userID, key string, value interface{}, current map[string]interface{}) error {
+	from, until, explicit := validityFromContext(ctx)
+	params := map[string]interface{}{
+		"user_id": userID,
+		"key":     key,
+	}
+
+	if current != nil {
+		if !explicit && sameFactValue(current["value"], value) {
+			from = factVersionFromRow(current, true).ValidFrom
+		} else if err := s.archiveFact(ctx, userID, key, current, from); err != nil {
+			return err
+		}
+
+		// Use DELETE FROM WHERE + CREATE strategy to avoid response deserialization issues
+		if _, err := s.query(ctx, `DELETE FROM kv_memories WHERE user_id = $user_id AND key = $key`, params); err != nil {
+			return fmt.Errorf("failed to delete existing fact: %w", err)
+		}
+	}
+
+	params["value"] = value
+	params["provenance"] = factProvenance(ctx)
+	params["valid_from"] = from.Format(time.RFC3339Nano)
+	validUntil := ""
+	if until != nil {
+		params["valid_until"] = until.Format(time.RFC3339Nano)
+		validUntil = ", valid_until: <datetime>$valid_until"
+	}
+	query := `CREATE kv_memories CONTENT { user_id: $user_id, key: $key, value: $value, provenance: $provenance, valid_from: <datetime>$valid_from` + validUntil + ` }`
+	_, err := s.query(ctx, query, params)
+	return err
+}
+
+// archiveFact keeps the kv_memories row of a fact in kv_memory_history as a
+// version valid until the given time, or its own end if earlier.
+func (s *SurrealDBStorage) archiveFact(ctx context.Context, userID, key string, row map[string]interface{}, until time.Time) error {
+	params := map[string]interface{}{
+		"version": factHistoryContent(userID, key, row, until),
 	}
+	if _, err := s.query(ctx, factHistoryStatement("version"), params); err != nil {
+		return fmt.Errorf("failed to keep fact history: %w", err)
+	}
+	return nil
+}
+
+// factHistoryContent returns the kv_memory_history record of the past
+// version of a fact held in row, for factHistoryStatement.
+func factHistoryContent(userID, key string, row map[string]interface{}, until time.Time) map[string]interface{} {
+	version := factVersionFromRow(row, true)
+	if version.ValidUntil != nil && version.ValidUntil.Before(until) {
+		until = *version.ValidUntil
+	}
+	provenance, _ := row["provenance"].(map[string]interface{})
+	if provenance == nil {
+		provenance = map[string]interface{}{}
+	}
+	return map[string]interface{}{
+		"user_id":     userID,
+		"key":         key,
+		"value":       row["value"],
+		"provenance":  provenance,
+		"valid_from":  version.ValidFrom.UTC().Format(time.RFC3339Nano),
+		"valid_until": until.UTC().Format(time.RFC3339Nano),
+	}
+}
 
-	return extractRecordID(queryResult.Result[0]["id"]), nil
+// factHistoryStatement creates the kv_memory_history record bound to the
+// param of the given name.
+func factHistoryStatement(param string) string {
+	return fmt.Sprintf("CREATE kv_memory_history CONTENT { user_id: $%[1]s.user_id, key: $%[1]s.key, value: $%[1]s.value, provenance: $%[1]s.provenance, valid_from: <datetime>$%[1]s.valid_from, valid_until: <datetime>$%[1]s.valid_until } RETURN NONE", param)
 }
@@ -33,6 +33,8 @@ func decodeResult[T any](result *[]QueryResult) ([]T, error) {
 		}
 	}
 
+	unpackEmbeddings(queryResult.Result)
+
 	// Pre-process results to convert SurrealDB datetime objects to ISO strings
 	processedResult := normalizeSurrealDBDatetimes(queryResult.Result)
 
@@ -257,6 +259,16 @@ func extractRecordID(id interface{}) string {
 	return idStr
 }
 
+// splitRecordID splits a "table:key" record id into its table and key, removing
+// the ⟨⟩ or backtick escaping SurrealDB adds around complex keys.
+func splitRecordID(id string) (string, string, error) {
+	table, key, ok := strings.Cut(id, ":")
+	if !ok || table == "" || key == "" {
+		return "", "", fmt.Errorf("invalid record id %q", id)
+	}
+	return table, strings.Trim(key, "⟨⟩`"), nil
+}
+
 func getMapKeys(m map[string]interface{}) []string {
 	keys := make([]string, 0, len(m))
 	for k := range m {
@@ -335,13 +347,7 @@ func getTime(m map[string]interface{}, key string) time.Time {
 }
 
 func convertEmbeddingToFloat64(embedding []float32) []float64 {
-	if embedding == nil {
-		embedding = make([]float32, defaultMtreeDim)
-	} else if len(embedding) != defaultMtreeDim {
-		norm := make([]float32, defaultMtreeDim)
-		copy(norm, embedding)
-		embedding = norm
-	}
+	embedding, _ = fitEmbeddingDimension("", embedding, false, "")
 
 	emb64 := make([]float64, len(embedding))
 	for i, v := range embedding {
@@ -0,0 +1,61 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// RecordMemoryHits stores the retrieval of the given facts, vectors and documents.
+func (s *SurrealDBStorage) RecordMemoryHits(ctx context.Context, hits []MemoryHit) error {
+	if len(hits) == 0 {
+		return nil
+	}
+	rows := make([]map[string]interface{}, 0, len(hits))
+	for _, hit := range hits {
+		rows = append(rows, map[string]interface{}{
+			"kind":    hit.Kind,
+			"ref":     hit.Ref,
+			"user_id": hit.UserID,
+			"tool":    hit.Tool,
+		})
+	}
+	if _, err := s.query(ctx, "INSERT INTO memory_hits $hits RETURN NONE", map[string]interface{}{"hits": rows}); err != nil {
+		return fmt.Errorf("failed to record memory hits: %w", err)
+	}
+	return nil
+}
+
+// CountMemoryHits returns how many times each fact and vector of userID, and
+// each document, was retrieved since the given time. Memories without hits
+// are left out.
+func (s *SurrealDBStorage) CountMemoryHits(ctx context.Context, userID string, since time.Time) ([]MemoryHitCount, error) {
+	query := `
+		SELECT kind, ref, count() AS hits, math::max(created_at) AS last_hit
+		FROM memory_hits
+		WHERE created_at >= <datetime>$since AND (user_id = $user_id OR kind = $document)
+		GROUP BY kind, ref
+	`
+	result, err := s.query(ctx, query, map[string]interface{}{
+		"since":    since.UTC().Truncate(time.Second).Format(time.RFC3339),
+		"user_id":  userID,
+		"document": TrashKindDocument,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to count memory hits: %w", err)
+	}
+
+	var counts []MemoryHitCount
+	if result == nil || len(*result) == 0 || (*result)[0].Status != "OK" {
+		return counts, nil
+	}
+	for _, row := range (*result)[0].Result {
+		counts = append(counts, MemoryHitCount{
+			Kind:    getString(row, "kind"),
+			Ref:     getString(row, "ref"),
+			Hits:    convertToInt(row["hits"]),
+			LastHit: getTime(row, "last_hit"),
+		})
+	}
+	return counts, nil
+}
@@ -0,0 +1,45 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// GetIdempotencyResult returns the result saved for key by tool since the
+// given time, and whether there is one.
+func (s *SurrealDBStorage) GetIdempotencyResult(ctx context.Context, tool, key string, since time.Time) (string, bool, error) {
+	query := "SELECT result FROM idempotency_keys WHERE tool = $tool AND key = $key AND created_at >= <datetime>$since LIMIT 1"
+	result, err := s.query(ctx, query, map[string]interface{}{
+		"tool":  tool,
+		"key":   key,
+		"since": since.UTC().Truncate(time.Second).Format(time.RFC3339),
+	})
+	if err != nil {
+		return "", false, fmt.Errorf("failed to get idempotency key: %w", err)
+	}
+	if result == nil || len(*result) == 0 || (*result)[0].Status != "OK" || len((*result)[0].Result) == 0 {
+		return "", false, nil
+	}
+	return getString((*result)[0].Result[0], "result"), true, nil
+}
+
+// SaveIdempotencyResult saves the result of tool for key, replacing an
+// expired one, and drops the results saved before expireBefore.
+func (s *SurrealDBStorage) SaveIdempotencyResult(ctx context.Context, tool, key, result string, expireBefore time.Time) error {
+	tx := newSurrealTx()
+	tx.add("DELETE FROM idempotency_keys WHERE created_at < <datetime>$before OR (tool = $tool AND key = $key) RETURN NONE", map[string]interface{}{
+		"before": expireBefore.UTC().Truncate(time.Second).Format(time.RFC3339),
+		"tool":   tool,
+		"key":    key,
+	})
+	tx.add("CREATE idempotency_keys CONTENT { tool: $tool, key: $key, result: $result } RETURN NONE", map[string]interface{}{
+		"tool":   tool,
+		"key":    key,
+		"result": result,
+	})
+	if err := s.commitTx(ctx, tx); err != nil {
+		return fmt.Errorf("failed to save idempotency key: %w", err)
+	}
+	return nil
+}
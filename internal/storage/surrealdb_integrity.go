@@ -0,0 +1,253 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+)
+
+// embeddingTables are the tables whose embedding field must have the schema
+// dimension. Archived vectors keep whatever dimension they had.
+var embeddingTables = []string{
+	"vector_memories",
+	"knowledge_base",
+	"kb_document_versions",
+	"events",
+	"code_symbols",
+	"code_chunks",
+}
+
+// CheckIntegrity looks for code files of deleted projects, code symbols and
+// chunks left behind by files that are no longer indexed, user_stats counters
+// that drifted from the records they count, and embeddings whose length does
+// not match the schema dimension. With repair, orphans are deleted, counters
+// recounted and embeddings padded or truncated like on write.
+func (s *SurrealDBStorage) CheckIntegrity(ctx context.Context, repair bool) (*IntegrityReport, error) {
+	// The checks scan whole tables, which can take longer than a query
+	ctx = WithoutQueryTimeout(ctx)
+	report := &IntegrityReport{Issues: []IntegrityIssue{}}
+	checks := []func(context.Context, bool, *IntegrityReport) error{
+		s.checkOrphanCodeFiles,
+		s.checkOrphanCodeSymbols,
+		s.checkOrphanCodeChunks,
+		s.checkStatsCounters,
+		s.checkEmbeddingDimensions,
+	}
+	for _, check := range checks {
+		if err := check(ctx, repair, report); err != nil {
+			return report, err
+		}
+	}
+	for _, issue := range report.Issues {
+		if issue.Repaired {
+			report.Repaired++
+		}
+	}
+	return report, nil
+}
+
+// fileKey identifies an indexed file of a project.
+type fileKey struct {
+	projectID string
+	filePath  string
+}
+
+// groupedFiles returns the number of records per project file of a code table.
+func (s *SurrealDBStorage) groupedFiles(ctx context.Context, table string) (map[fileKey]int, error) {
+	query := fmt.Sprintf("SELECT project_id, file_path, count() AS count FROM %s GROUP BY project_id, file_path", table)
+	rows, err := s.Query(ctx, query, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w", table, err)
+	}
+	files := make(map[fileKey]int, len(rows))
+	for _, row := range rows {
+		files[fileKey{getString(row, "project_id"), getString(row, "file_path")}] = convertToInt(row["count"])
+	}
+	return files, nil
+}
+
+// checkOrphanCodeFiles reports code files whose project no longer exists.
+func (s *SurrealDBStorage) checkOrphanCodeFiles(ctx context.Context, repair bool, report *IntegrityReport) error {
+	rows, err := s.Query(ctx, "SELECT project_id FROM code_projects", nil)
+	if err != nil {
+		return fmt.Errorf("failed to list code projects: %w", err)
+	}
+	projects := make(map[string]bool, len(rows))
+	for _, row := range rows {
+		projects[getString(row, "project_id")] = true
+	}
+
+	rows, err = s.Query(ctx, "SELECT project_id, count() AS count FROM code_files GROUP BY project_id", nil)
+	if err != nil {
+		return fmt.Errorf("failed to list code files: %w", err)
+	}
+	for _, row := range rows {
+		projectID := getString(row, "project_id")
+		if projects[projectID] {
+			continue
+		}
+		issue := IntegrityIssue{
+			Check:  IntegrityOrphanFile,
+			Table:  "code_files",
+			Ref:    projectID,
+			Detail: "files of a project that does not exist",
+			Count:  convertToInt(row["count"]),
+		}
+		if repair {
+			issue.Repaired = s.repair(ctx, "DELETE FROM code_files WHERE project_id = $project_id", map[string]interface{}{"project_id": projectID})
+		}
+		report.Issues = append(report.Issues, issue)
+	}
+	return nil
+}
+
+// checkOrphanCodeSymbols reports symbols of files that are not indexed.
+func (s *SurrealDBStorage) checkOrphanCodeSymbols(ctx context.Context, repair bool, report *IntegrityReport) error {
+	files, err := s.groupedFiles(ctx, "code_files")
+	if err != nil {
+		return err
+	}
+	symbols, err := s.groupedFiles(ctx, "code_symbols")
+	if err != nil {
+		return err
+	}
+	s.reportOrphans(ctx, repair, report, IntegrityOrphanSymbol, "code_symbols", "symbols of a file that is not indexed", symbols, files)
+	return nil
+}
+
+// checkOrphanCodeChunks reports chunks of files that have no symbols left.
+func (s *SurrealDBStorage) checkOrphanCodeChunks(ctx context.Context, repair bool, report *IntegrityReport) error {
+	symbols, err := s.groupedFiles(ctx, "code_symbols")
+	if err != nil {
+		return err
+	}
+	chunks, err := s.groupedFiles(ctx, "code_chunks")
+	if err != nil {
+		return err
+	}
+	s.reportOrphans(ctx, repair, report, IntegrityOrphanChunk, "code_chunks", "chunks of a file without symbols", chunks, symbols)
+	return nil
+}
+
+// reportOrphans adds an issue for each file of children missing from parents.
+func (s *SurrealDBStorage) reportOrphans(ctx context.Context, repair bool, report *IntegrityReport, check, table, detail string, children, parents map[fileKey]int) {
+	for file, count := range children {
+		if _, ok := parents[file]; ok {
+			continue
+		}
+		issue := IntegrityIssue{
+			Check:  check,
+			Table:  table,
+			Ref:    file.projectID + ":" + file.filePath,
+			Detail: detail,
+			Count:  count,
+		}
+		if repair {
+			query := fmt.Sprintf("DELETE FROM %s WHERE project_id = $project_id AND file_path = $file_path", table)
+			issue.Repaired = s.repair(ctx, query, map[string]interface{}{"project_id": file.projectID, "file_path": file.filePath})
+		}
+		report.Issues = append(report.Issues, issue)
+	}
+}
+
+// checkStatsCounters compares user_stats counters with the records they
+// count, and recounts the rows of users whose counters drifted.
+func (s *SurrealDBStorage) checkStatsCounters(ctx context.Context, repair bool, report *IntegrityReport) error {
+	rows, err := s.Query(ctx, "SELECT * FROM user_stats", nil)
+	if err != nil {
+		return fmt.Errorf("failed to list user_stats: %w", err)
+	}
+	expected, err := s.expectedUserStats(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to count records: %w", err)
+	}
+
+	for _, row := range rows {
+		userID := getString(row, "user_id")
+		start := len(report.Issues)
+		for _, stat := range userStatFields {
+			got, want := convertToInt(row[stat]), expected[userID][stat]
+			if got == want {
+				continue
+			}
+			report.Issues = append(report.Issues, IntegrityIssue{
+				Check:  IntegrityStatsDrift,
+				Table:  "user_stats",
+				Ref:    userID + "." + stat,
+				Detail: fmt.Sprintf("counter is %d but %d records exist", got, want),
+				Count:  1,
+			})
+		}
+		if !repair || len(report.Issues) == start {
+			continue
+		}
+		if _, err := s.RecountUserStats(ctx, userID); err != nil {
+			slog.Warn("failed to recount user stats", "user_id", userID, "error", err)
+			continue
+		}
+		for i := start; i < len(report.Issues); i++ {
+			report.Issues[i].Repaired = true
+		}
+	}
+	return nil
+}
+
+// checkEmbeddingDimensions reports records whose embedding length differs
+// from the schema dimension.
+func (s *SurrealDBStorage) checkEmbeddingDimensions(ctx context.Context, repair bool, report *IntegrityReport) error {
+	params := map[string]interface{}{"dim": defaultMtreeDim}
+	for _, table := range embeddingTables {
+		query := fmt.Sprintf("SELECT id, embedding FROM %s WHERE embedding != NONE AND array::len(embedding) != $dim", table)
+		rows, err := s.Query(ctx, query, params)
+		if err != nil {
+			return fmt.Errorf("failed to check embeddings of %s: %w", table, err)
+		}
+		for _, row := range rows {
+			values, _ := row["embedding"].([]interface{})
+			id := extractRecordID(row["id"])
+			issue := IntegrityIssue{
+				Check:  IntegrityEmbeddingDim,
+				Table:  table,
+				Ref:    id,
+				Detail: fmt.Sprintf("embedding has %d dimensions, expected %d", len(values), defaultMtreeDim),
+				Count:  1,
+			}
+			if repair {
+				issue.Repaired = s.resizeEmbedding(ctx, id, values)
+			}
+			report.Issues = append(report.Issues, issue)
+		}
+	}
+	return nil
+}
+
+// resizeEmbedding pads a stored embedding with zeros or truncates it to the
+// schema dimension.
+func (s *SurrealDBStorage) resizeEmbedding(ctx context.Context, id string, values []interface{}) bool {
+	table, key, err := splitRecordID(id)
+	if err != nil {
+		slog.Warn("failed to repair embedding", "id", id, "error", err)
+		return false
+	}
+	resized := make([]interface{}, defaultMtreeDim)
+	for i := range resized {
+		resized[i] = 0.0
+		if i < len(values) {
+			resized[i] = values[i]
+		}
+	}
+	return s.repair(ctx, "UPDATE type::thing($rec_table, $rec_key) SET embedding = $embedding RETURN NONE", map[string]interface{}{
+		"rec_table": table,
+		"rec_key":   key,
+		"embedding": resized,
+	})
+}
+
+// repair runs a repair statement and reports whether it succeeded.
+func (s *SurrealDBStorage) repair(ctx context.Context, query string, params map[string]interface{}) bool {
+	if _, err := s.query(ctx, query, params); err != nil {
+		slog.Warn("integrity repair failed", "query", query, "error", err)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,116 @@
+package storage
+
+import (
+	"context"
+	"testing"
+
+	"github.com/madeindigio/remembrances-mcp/pkg/treesitter"
+)
+
+func TestCheckIntegrityRepair(t *testing.T) {
+	s := newTestSurrealDB(t)
+	ctx := context.Background()
+
+	// Project app indexes main.go; gone.go and removed.go were deleted from
+	// it without their symbols and chunks, and project old was deleted
+	// without its files.
+	if err := s.CreateCodeProject(ctx, &treesitter.CodeProject{ProjectID: "app", Name: "app", RootPath: "/src/app"}); err != nil {
+		t.Fatalf("create project: %v", err)
+	}
+	for _, file := range []*treesitter.CodeFile{
+		{ProjectID: "app", FilePath: "main.go", Language: treesitter.LanguageGo, FileHash: "a"},
+		{ProjectID: "old", FilePath: "lib.go", Language: treesitter.LanguageGo, FileHash: "b"},
+	} {
+		if err := s.SaveCodeFile(ctx, file); err != nil {
+			t.Fatalf("save file %s: %v", file.FilePath, err)
+		}
+	}
+	for _, sym := range []*treesitter.CodeSymbol{
+		{ProjectID: "app", FilePath: "main.go", Language: treesitter.LanguageGo, SymbolType: treesitter.SymbolTypeFunction, Name: "main", NamePath: "/main"},
+		{ProjectID: "app", FilePath: "gone.go", Language: treesitter.LanguageGo, SymbolType: treesitter.SymbolTypeFunction, Name: "gone", NamePath: "/gone"},
+	} {
+		if err := s.SaveCodeSymbol(ctx, sym); err != nil {
+			t.Fatalf("save symbol %s: %v", sym.NamePath, err)
+		}
+	}
+	for _, file := range []string{"main.go", "removed.go"} {
+		if err := s.SaveCodeChunk(ctx, &CodeChunk{SymbolID: file, ProjectID: "app", FilePath: file, ChunkCount: 1, Content: "func f() {}", SymbolName: "f", SymbolType: "function", Language: "go"}); err != nil {
+			t.Fatalf("save chunk of %s: %v", file, err)
+		}
+	}
+
+	// A counter that drifted after a crash
+	if err := s.SaveFact(ctx, "alice", "editor", "vim"); err != nil {
+		t.Fatalf("save fact: %v", err)
+	}
+	if _, err := s.query(ctx, "UPDATE user_stats SET key_value_count = 7 WHERE user_id = 'alice'", nil); err != nil {
+		t.Fatalf("corrupt stats: %v", err)
+	}
+
+	// An embedding written before the dimension was enforced
+	for _, stmt := range []string{
+		"REMOVE INDEX idx_kb_versions_embedding ON kb_document_versions",
+		"REMOVE FIELD embedding ON kb_document_versions",
+		"DEFINE FIELD embedding ON kb_document_versions TYPE array<number>",
+		"CREATE kb_document_versions:old SET file_path = 'old.md', version = 1, content = 'old', embedding = [1, 2, 3]",
+	} {
+		if _, err := s.query(ctx, stmt, nil); err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+
+	report, err := s.CheckIntegrity(ctx, false)
+	if err != nil {
+		t.Fatalf("check: %v", err)
+	}
+	found := map[string]IntegrityIssue{}
+	for _, issue := range report.Issues {
+		found[issue.Check+" "+issue.Ref] = issue
+	}
+	for _, want := range []string{
+		IntegrityOrphanFile + " old",
+		IntegrityOrphanSymbol + " app:gone.go",
+		IntegrityOrphanChunk + " app:removed.go",
+		IntegrityStatsDrift + " alice.key_value_count",
+		IntegrityEmbeddingDim + " kb_document_versions:old",
+	} {
+		issue, ok := found[want]
+		if !ok {
+			t.Errorf("issues = %+v, want %s", report.Issues, want)
+			continue
+		}
+		if issue.Repaired {
+			t.Errorf("%s repaired without repair", want)
+		}
+	}
+	for key := range found {
+		if key == IntegrityOrphanSymbol+" app:main.go" || key == IntegrityOrphanChunk+" app:main.go" {
+			t.Errorf("reported %s, whose file is indexed", key)
+		}
+	}
+	if report.Repaired != 0 {
+		t.Errorf("repaired = %d without repair, want 0", report.Repaired)
+	}
+
+	report, err = s.CheckIntegrity(ctx, true)
+	if err != nil {
+		t.Fatalf("repair: %v", err)
+	}
+	if len(report.Issues) == 0 || report.Repaired != len(report.Issues) {
+		t.Errorf("repair report = %+v, want every issue repaired", report)
+	}
+
+	report, err = s.CheckIntegrity(ctx, false)
+	if err != nil {
+		t.Fatalf("check after repair: %v", err)
+	}
+	if len(report.Issues) != 0 {
+		t.Errorf("issues after repair = %+v, want none", report.Issues)
+	}
+	if value, err := s.GetFact(ctx, "alice", "editor"); err != nil || value != "vim" {
+		t.Errorf("fact after repair = %v, %v; want it kept", value, err)
+	}
+	if sym, err := s.GetCodeSymbol(ctx, "app", "/main"); err != nil || sym == nil {
+		t.Errorf("symbol /main after repair = %+v, %v; want it kept", sym, err)
+	}
+}
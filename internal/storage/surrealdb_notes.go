@@ -0,0 +1,215 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+)
+
+// SaveNoteGraph creates or updates the entity of a note and replaces its
+// outgoing links. Linked notes that were not synced yet get an entity with
+// the "unresolved" property, which is dropped once nothing links to them.
+func (s *SurrealDBStorage) SaveNoteGraph(ctx context.Context, note NoteGraph) error {
+	properties := map[string]interface{}{}
+	for k, v := range note.Properties {
+		properties[k] = v
+	}
+
+	noteID, err := s.upsertNoteEntity(ctx, note.Name, properties, true)
+	if err != nil {
+		return err
+	}
+
+	previous, err := s.removeNoteLinks(ctx, noteID)
+	if err != nil {
+		return err
+	}
+
+	for _, link := range note.Links {
+		if link.Target == note.Name {
+			continue
+		}
+		targetID, err := s.upsertNoteEntity(ctx, link.Target, map[string]interface{}{"unresolved": true}, false)
+		if err != nil {
+			return err
+		}
+		props := map[string]interface{}{"source": "kb"}
+		if link.Heading != "" {
+			props["heading"] = link.Heading
+		}
+		if link.Alias != "" {
+			props["alias"] = link.Alias
+		}
+		if err := s.CreateRelationship(ctx, noteID, targetID, NoteLinkRelationship, props); err != nil {
+			return fmt.Errorf("failed to link note '%s' to '%s': %w", note.Name, link.Target, err)
+		}
+	}
+
+	s.pruneUnresolvedNotes(ctx, previous)
+	if err := s.updateUserStat(ctx, "global", "relationship_count", 0); err != nil {
+		slog.Warn("failed to update relationship_count stat", "error", err)
+	}
+	return nil
+}
+
+// DeleteNoteGraph removes the links of a note. The note entity is removed as
+// well unless other notes still link to it, in which case it is kept as an
+// unresolved link target.
+func (s *SurrealDBStorage) DeleteNoteGraph(ctx context.Context, name string) error {
+	noteID, err := s.findNoteEntity(ctx, name)
+	if err != nil || noteID == "" {
+		return err
+	}
+
+	previous, err := s.removeNoteLinks(ctx, noteID)
+	if err != nil {
+		return err
+	}
+
+	if s.countNoteBacklinks(ctx, noteID) > 0 {
+		if _, err := s.upsertNoteEntity(ctx, name, map[string]interface{}{"unresolved": true}, true); err != nil {
+			return err
+		}
+	} else {
+		previous = append(previous, noteID)
+		if err := s.deleteNoteEntity(ctx, noteID); err != nil {
+			return err
+		}
+	}
+
+	s.pruneUnresolvedNotes(ctx, previous)
+	if err := s.updateUserStat(ctx, "global", "relationship_count", 0); err != nil {
+		slog.Warn("failed to update relationship_count stat", "error", err)
+	}
+	return nil
+}
+
+// findNoteEntity returns the record ID of a note entity, or "" when missing.
+func (s *SurrealDBStorage) findNoteEntity(ctx context.Context, name string) (string, error) {
+	query := "SELECT id FROM entities WHERE name = $name AND entity_type = $entity_type LIMIT 1"
+	result, err := s.query(ctx, query, map[string]interface{}{
+		"name":        name,
+		"entity_type": NoteEntityType,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to find note entity: %w", err)
+	}
+	if result == nil || len(*result) == 0 || len((*result)[0].Result) == 0 {
+		return "", nil
+	}
+	return extractRecordID((*result)[0].Result[0]["id"]), nil
+}
+
+// upsertNoteEntity returns the record ID of a note entity, creating it with
+// properties when missing. Existing entities only get their properties
+// replaced when overwrite is set.
+func (s *SurrealDBStorage) upsertNoteEntity(ctx context.Context, name string, properties map[string]interface{}, overwrite bool) (string, error) {
+	id, err := s.findNoteEntity(ctx, name)
+	if err != nil {
+		return "", err
+	}
+	if id == "" {
+		if err := s.CreateEntity(ctx, NoteEntityType, name, properties); err != nil {
+			return "", err
+		}
+		id, err = s.findNoteEntity(ctx, name)
+		if err != nil {
+			return "", err
+		}
+		if id == "" {
+			return "", fmt.Errorf("note entity '%s' not found after creation", name)
+		}
+		return id, nil
+	}
+
+	if overwrite {
+		table, key, err := splitRecordID(id)
+		if err != nil {
+			return "", err
+		}
+		query := "UPDATE type::thing($rec_table, $rec_key) SET properties = $properties RETURN NONE"
+		if _, err := s.query(ctx, query, map[string]interface{}{
+			"rec_table":  table,
+			"rec_key":    key,
+			"properties": properties,
+		}); err != nil {
+			return "", fmt.Errorf("failed to update note entity: %w", err)
+		}
+	}
+	return id, nil
+}
+
+// removeNoteLinks deletes the outgoing links of a note and returns the
+// record IDs of the notes they pointed to.
+func (s *SurrealDBStorage) removeNoteLinks(ctx context.Context, noteID string) ([]string, error) {
+	// The table is created with the first link; see CreateRelationship
+	_, _ = s.query(ctx, fmt.Sprintf("DEFINE TABLE %s SCHEMALESS", NoteLinkRelationship), nil)
+
+	params := map[string]interface{}{"from": noteID}
+	result, err := s.query(ctx, fmt.Sprintf("SELECT to_entity FROM %s WHERE from_entity = $from", NoteLinkRelationship), params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list note links: %w", err)
+	}
+	var targets []string
+	if result != nil && len(*result) > 0 {
+		for _, row := range (*result)[0].Result {
+			if to := extractRecordID(row["to_entity"]); to != "" {
+				targets = append(targets, to)
+			}
+		}
+	}
+
+	if _, err := s.query(ctx, fmt.Sprintf("DELETE FROM %s WHERE from_entity = $from RETURN NONE", NoteLinkRelationship), params); err != nil {
+		return nil, fmt.Errorf("failed to delete note links: %w", err)
+	}
+	return targets, nil
+}
+
+// countNoteBacklinks returns the number of links pointing to a note.
+func (s *SurrealDBStorage) countNoteBacklinks(ctx context.Context, noteID string) int {
+	query := fmt.Sprintf("SELECT count() AS count FROM %s WHERE to_entity = $to GROUP ALL", NoteLinkRelationship)
+	return s.getCount(ctx, query, map[string]interface{}{"to": noteID})
+}
+
+// pruneUnresolvedNotes deletes the given unresolved note entities that no
+// note links to anymore.
+func (s *SurrealDBStorage) pruneUnresolvedNotes(ctx context.Context, ids []string) {
+	seen := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		entity, err := s.GetEntity(ctx, id)
+		if err != nil || entity == nil {
+			continue
+		}
+		if unresolved, _ := entity.Properties["unresolved"].(bool); !unresolved {
+			continue
+		}
+		if s.countNoteBacklinks(ctx, id) > 0 {
+			continue
+		}
+		if err := s.deleteNoteEntity(ctx, id); err != nil {
+			slog.Warn("failed to delete unresolved note entity", "id", id, "error", err)
+		}
+	}
+}
+
+// deleteNoteEntity deletes a note entity. Unlike DeleteEntity it does not
+// go through the trash, as the entity is rebuilt from its file on sync.
+func (s *SurrealDBStorage) deleteNoteEntity(ctx context.Context, id string) error {
+	table, key, err := splitRecordID(id)
+	if err != nil {
+		return err
+	}
+	query := "DELETE type::thing($rec_table, $rec_key) RETURN NONE"
+	if _, err := s.query(ctx, query, map[string]interface{}{"rec_table": table, "rec_key": key}); err != nil {
+		return fmt.Errorf("failed to delete note entity: %w", err)
+	}
+	if err := s.updateUserStat(ctx, "global", "entity_count", 0); err != nil {
+		slog.Warn("failed to update entity_count stat", "error", err)
+	}
+	return nil
+}
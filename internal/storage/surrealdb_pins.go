@@ -0,0 +1,125 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+)
+
+// surrealPinWhere selects the pin of an item. Vector IDs and document paths
+// are unique, so only fact pins are matched by user.
+const surrealPinWhere = `kind = $pin_kind AND ref = $pin_ref AND (kind != "fact" OR user_id = $pin_user_id)`
+
+// pinParams binds the item surrealPinWhere selects.
+func pinParams(kind, userID, ref string) map[string]interface{} {
+	if kind == TrashKindDocument {
+		userID = ""
+	}
+	return map[string]interface{}{
+		"pin_kind":    kind,
+		"pin_user_id": userID,
+		"pin_ref":     ref,
+	}
+}
+
+// SetPinned pins or unpins a fact, vector or document and reports whether
+// its pin changed.
+func (s *SurrealDBStorage) SetPinned(ctx context.Context, kind, userID, ref string, pinned bool) (bool, error) {
+	if !pinnable(kind) {
+		return false, fmt.Errorf("cannot pin %s items", kind)
+	}
+	current, err := s.isPinned(ctx, kind, userID, ref)
+	if err != nil {
+		return false, err
+	}
+	if current == pinned {
+		return false, nil
+	}
+
+	params := pinParams(kind, userID, ref)
+	query := "DELETE FROM pinned_memories WHERE " + surrealPinWhere + " RETURN NONE"
+	if pinned {
+		query = "CREATE pinned_memories CONTENT { kind: $pin_kind, user_id: $pin_user_id, ref: $pin_ref } RETURN NONE"
+	}
+	if _, err := s.query(ctx, query, params); err != nil {
+		return false, fmt.Errorf("failed to set pin of %s %q: %w", kind, ref, err)
+	}
+	return true, nil
+}
+
+// ListPinned returns the pinned facts and vectors of userID, or of every user
+// when userID is empty, and the pinned documents. An empty kind lists every
+// kind.
+func (s *SurrealDBStorage) ListPinned(ctx context.Context, userID, kind string) ([]PinnedItem, error) {
+	query := `
+		SELECT kind, user_id, ref, pinned_at FROM pinned_memories
+		WHERE ($user_id = "" OR user_id = $user_id OR kind = "document") AND ($kind = "" OR kind = $kind)
+		ORDER BY kind ASC, ref ASC
+	`
+	result, err := s.query(ctx, query, map[string]interface{}{
+		"user_id": userID,
+		"kind":    kind,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pinned items: %w", err)
+	}
+	items := []PinnedItem{}
+	if result != nil && len(*result) > 0 && (*result)[0].Status == "OK" {
+		for _, row := range (*result)[0].Result {
+			items = append(items, pinnedItemFromRow(row))
+		}
+	}
+	return items, nil
+}
+
+func (s *SurrealDBStorage) isPinned(ctx context.Context, kind, userID, ref string) (bool, error) {
+	result, err := s.query(ctx, "SELECT id FROM pinned_memories WHERE "+surrealPinWhere+" LIMIT 1", pinParams(kind, userID, ref))
+	if err != nil {
+		return false, fmt.Errorf("failed to check pin of %s %q: %w", kind, ref, err)
+	}
+	return result != nil && len(*result) > 0 && len((*result)[0].Result) > 0, nil
+}
+
+// checkPinned fails with ErrPinned when the item is pinned, unless ctx comes
+// from WithForce.
+func (s *SurrealDBStorage) checkPinned(ctx context.Context, kind, userID, ref string) error {
+	if !pinnable(kind) || forced(ctx) {
+		return nil
+	}
+	pinned, err := s.isPinned(ctx, kind, userID, ref)
+	if err != nil {
+		return err
+	}
+	if pinned {
+		return pinnedError(kind, ref)
+	}
+	return nil
+}
+
+// checkPinnedVectors fails with ErrPinned when any of the vectors is pinned,
+// unless ctx comes from WithForce. Forced, it drops their pins.
+func (s *SurrealDBStorage) checkPinnedVectors(ctx context.Context, ids []string) error {
+	params := map[string]interface{}{"ids": ids}
+	if forced(ctx) {
+		if _, err := s.query(ctx, `DELETE FROM pinned_memories WHERE kind = "vector" AND ref IN $ids RETURN NONE`, params); err != nil {
+			return fmt.Errorf("failed to drop pins: %w", err)
+		}
+		return nil
+	}
+	result, err := s.query(ctx, `SELECT ref FROM pinned_memories WHERE kind = "vector" AND ref IN $ids LIMIT 1`, params)
+	if err != nil {
+		return fmt.Errorf("failed to check pins: %w", err)
+	}
+	if result != nil && len(*result) > 0 && len((*result)[0].Result) > 0 {
+		return pinnedError(TrashKindVector, getString((*result)[0].Result[0], "ref"))
+	}
+	return nil
+}
+
+func pinnedItemFromRow(row map[string]interface{}) PinnedItem {
+	return PinnedItem{
+		Kind:     getString(row, "kind"),
+		Ref:      getString(row, "ref"),
+		UserID:   getString(row, "user_id"),
+		PinnedAt: getTime(row, "pinned_at"),
+	}
+}
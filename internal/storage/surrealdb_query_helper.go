@@ -3,7 +3,10 @@ package storage
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"regexp"
+	"strings"
 
 	"github.com/surrealdb/surrealdb.go"
 )
@@ -52,12 +55,19 @@ func (s *SurrealDBStorage) query(ctx context.Context, query string, params map[s
 // queryEmbedded executes a query on the embedded backend
 func (s *SurrealDBStorage) queryEmbedded(ctx context.Context, query string, params map[string]interface{}) (*[]QueryResult, error) {
 	if s.embeddedDB == nil {
-		return nil, fmt.Errorf("embedded database not initialized")
+		return nil, fmt.Errorf("embedded database not initialized: %w", ErrUnavailable)
+	}
+	if err := checkTenantEmbedded(ctx); err != nil {
+		return nil, err
 	}
 
-	results, err := s.embeddedDB.Query(query, params)
+	callCtx, cancel := s.queryContext(ctx, isWriteQuery(query))
+	defer cancel()
+	results, err := callEmbedded(callCtx, func() ([]interface{}, error) {
+		return s.embeddedDB.Query(query, params)
+	})
 	if err != nil {
-		return nil, err
+		return nil, s.callError(ctx, callCtx, err)
 	}
 
 	// Convert embedded results to QueryResult format
@@ -135,13 +145,16 @@ func (s *SurrealDBStorage) queryEmbedded(ctx context.Context, query string, para
 
 // queryRemote executes a query on the remote backend
 func (s *SurrealDBStorage) queryRemote(ctx context.Context, query string, params map[string]interface{}) (*[]QueryResult, error) {
-	if s.db == nil {
-		return nil, fmt.Errorf("remote database not initialized")
+	db, err := s.remoteDB(ctx)
+	if err != nil {
+		return nil, err
 	}
 
-	result, err := surrealdb.Query[[]map[string]interface{}](ctx, s.db, query, params)
+	callCtx, cancel := s.queryContext(ctx, isWriteQuery(query))
+	defer cancel()
+	result, err := surrealdb.Query[[]map[string]interface{}](callCtx, db, query, params)
 	if err != nil {
-		return nil, err
+		return nil, s.callError(ctx, callCtx, err)
 	}
 
 	// Convert surrealdb.QueryResult to our QueryResult format
@@ -163,45 +176,177 @@ func (s *SurrealDBStorage) queryRemote(ctx context.Context, query string, params
 func (s *SurrealDBStorage) create(ctx context.Context, resource string, data interface{}) (interface{}, error) {
 	if s.useEmbedded {
 		if s.embeddedDB == nil {
-			return nil, fmt.Errorf("embedded database not initialized")
+			return nil, fmt.Errorf("embedded database not initialized: %w", ErrUnavailable)
 		}
-		return s.embeddedDB.Create(resource, data)
+		if err := checkTenantEmbedded(ctx); err != nil {
+			return nil, err
+		}
+		callCtx, cancel := s.queryContext(ctx, true)
+		defer cancel()
+		result, err := callEmbedded(callCtx, func() (interface{}, error) {
+			return s.embeddedDB.Create(resource, data)
+		})
+		if err != nil {
+			return nil, s.callError(ctx, callCtx, err)
+		}
+		return result, nil
 	}
 
-	if s.db == nil {
-		return nil, fmt.Errorf("remote database not initialized")
+	db, err := s.remoteDB(ctx)
+	if err != nil {
+		return nil, err
+	}
+	callCtx, cancel := s.queryContext(ctx, true)
+	defer cancel()
+	result, err := surrealdb.Create[map[string]interface{}](callCtx, db, resource, data)
+	if err != nil {
+		return nil, s.callError(ctx, callCtx, err)
 	}
-	return surrealdb.Create[map[string]interface{}](ctx, s.db, resource, data)
+	return result, nil
 }
 
 // update updates a record on either embedded or remote backend
 func (s *SurrealDBStorage) update(ctx context.Context, resource string, data interface{}) (interface{}, error) {
 	if s.useEmbedded {
 		if s.embeddedDB == nil {
-			return nil, fmt.Errorf("embedded database not initialized")
+			return nil, fmt.Errorf("embedded database not initialized: %w", ErrUnavailable)
+		}
+		if err := checkTenantEmbedded(ctx); err != nil {
+			return nil, err
+		}
+		callCtx, cancel := s.queryContext(ctx, true)
+		defer cancel()
+		result, err := callEmbedded(callCtx, func() (interface{}, error) {
+			return s.embeddedDB.Update(resource, data)
+		})
+		if err != nil {
+			return nil, s.callError(ctx, callCtx, err)
 		}
-		return s.embeddedDB.Update(resource, data)
+		return result, nil
 	}
 
-	if s.db == nil {
-		return nil, fmt.Errorf("remote database not initialized")
+	db, err := s.remoteDB(ctx)
+	if err != nil {
+		return nil, err
 	}
-	return surrealdb.Update[map[string]interface{}](ctx, s.db, resource, data)
+	callCtx, cancel := s.queryContext(ctx, true)
+	defer cancel()
+	result, err := surrealdb.Update[map[string]interface{}](callCtx, db, resource, data)
+	if err != nil {
+		return nil, s.callError(ctx, callCtx, err)
+	}
+	return result, nil
 }
 
 // delete deletes a record on either embedded or remote backend
 func (s *SurrealDBStorage) delete(ctx context.Context, resource string) (interface{}, error) {
 	if s.useEmbedded {
 		if s.embeddedDB == nil {
-			return nil, fmt.Errorf("embedded database not initialized")
+			return nil, fmt.Errorf("embedded database not initialized: %w", ErrUnavailable)
+		}
+		if err := checkTenantEmbedded(ctx); err != nil {
+			return nil, err
 		}
-		return s.embeddedDB.Delete(resource)
+		callCtx, cancel := s.queryContext(ctx, true)
+		defer cancel()
+		result, err := callEmbedded(callCtx, func() (interface{}, error) {
+			return s.embeddedDB.Delete(resource)
+		})
+		if err != nil {
+			return nil, s.callError(ctx, callCtx, err)
+		}
+		return result, nil
 	}
 
-	if s.db == nil {
-		return nil, fmt.Errorf("remote database not initialized")
+	db, err := s.remoteDB(ctx)
+	if err != nil {
+		return nil, err
+	}
+	callCtx, cancel := s.queryContext(ctx, true)
+	defer cancel()
+	result, err := surrealdb.Delete[map[string]interface{}](callCtx, db, resource)
+	if err != nil {
+		return nil, s.callError(ctx, callCtx, err)
+	}
+	return result, nil
+}
+
+// noQueryTimeoutKey marks a context whose calls are not bounded by the query
+// timeout.
+type noQueryTimeoutKey struct{}
+
+// WithoutQueryTimeout returns a context whose SurrealDB calls are not bounded
+// by the query timeout, for work expected to take long such as migrations,
+// compaction, repairs and imports. The deadline of ctx itself still applies.
+func WithoutQueryTimeout(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noQueryTimeoutKey{}, true)
+}
+
+// queryContext bounds ctx by the query timeout, unless it is disabled or ctx
+// comes from WithoutQueryTimeout. Writes are not bounded and not cancelled
+// either: the database commits a write whether or not its caller is still
+// waiting, so giving up on one would only make a retry write it twice.
+func (s *SurrealDBStorage) queryContext(ctx context.Context, write bool) (context.Context, context.CancelFunc) {
+	if write {
+		return context.WithoutCancel(ctx), func() {}
+	}
+	if unbounded, _ := ctx.Value(noQueryTimeoutKey{}).(bool); unbounded || s.config.QueryTimeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, s.config.QueryTimeout)
+}
+
+// readStatementRe matches the statements that never write.
+var readStatementRe = regexp.MustCompile(`(?i)^(SELECT|INFO)\b`)
+
+// isWriteQuery reports whether query may write: anything but a list of
+// SELECT and INFO statements, including transactions. Statements are split
+// on semicolons, so a semicolon inside a string literal only makes a read
+// count as a write.
+func isWriteQuery(query string) bool {
+	for _, stmt := range strings.Split(query, ";") {
+		stmt = strings.TrimSpace(stmt)
+		if stmt != "" && !readStatementRe.MatchString(stmt) {
+			return true
+		}
+	}
+	return false
+}
+
+// callError returns the error of a call made with callCtx, derived from ctx
+// by queryContext. Calls ended by the query timeout report it, wrapping
+// ErrUnavailable; connection failures wrap ErrUnavailable too.
+func (s *SurrealDBStorage) callError(ctx, callCtx context.Context, err error) error {
+	if ctx.Err() == nil && errors.Is(callCtx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("database did not answer within %s: %w", s.config.QueryTimeout,
+			errors.Join(ErrUnavailable, context.DeadlineExceeded))
+	}
+	return unavailable(err)
+}
+
+// callEmbedded runs a call to the embedded database until it returns or ctx
+// ends. Calls into the library cannot be interrupted, so one outliving ctx
+// is left to finish in the background and its result is dropped.
+func callEmbedded[T any](ctx context.Context, call func() (T, error)) (T, error) {
+	if ctx.Done() == nil {
+		return call()
+	}
+	type result struct {
+		value T
+		err   error
+	}
+	done := make(chan result, 1)
+	go func() {
+		value, err := call()
+		done <- result{value, err}
+	}()
+	select {
+	case r := <-done:
+		return r.value, r.err
+	case <-ctx.Done():
+		var zero T
+		return zero, ctx.Err()
 	}
-	return surrealdb.Delete[map[string]interface{}](ctx, s.db, resource)
 }
 
 // unmarshalResult helps unmarshal results consistently
@@ -0,0 +1,91 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSurrealDBStorageQueryTimeout(t *testing.T) {
+	s := NewSurrealDBStorage(&ConnectionConfig{DBPath: "memory", QueryTimeout: 20 * time.Millisecond})
+	hung := make(chan struct{})
+	defer close(hung)
+
+	ctx := context.Background()
+	callCtx, cancel := s.queryContext(ctx, false)
+	defer cancel()
+	_, err := callEmbedded(callCtx, func() (interface{}, error) {
+		<-hung
+		return nil, nil
+	})
+	if err = s.callError(ctx, callCtx, err); !errors.Is(err, ErrUnavailable) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("hung call error = %v, want ErrUnavailable and DeadlineExceeded", err)
+	}
+
+	callCtx, cancel = s.queryContext(WithoutQueryTimeout(ctx), false)
+	defer cancel()
+	if _, ok := callCtx.Deadline(); ok {
+		t.Errorf("context from WithoutQueryTimeout has a deadline")
+	}
+
+	got, err := callEmbedded(callCtx, func() (int, error) { return 42, nil })
+	if got != 42 || err != nil {
+		t.Errorf("callEmbedded() = %d, %v, want 42, nil", got, err)
+	}
+}
+
+func TestSurrealDBStorageQueryCancelled(t *testing.T) {
+	s := NewSurrealDBStorage(&ConnectionConfig{DBPath: "memory", QueryTimeout: time.Minute})
+	hung := make(chan struct{})
+	defer close(hung)
+
+	ctx, cancelCaller := context.WithCancel(context.Background())
+	callCtx, cancel := s.queryContext(ctx, false)
+	defer cancel()
+	go cancelCaller()
+	_, err := callEmbedded(callCtx, func() (interface{}, error) {
+		<-hung
+		return nil, nil
+	})
+	if err = s.callError(ctx, callCtx, err); !errors.Is(err, context.Canceled) || errors.Is(err, ErrUnavailable) {
+		t.Fatalf("cancelled call error = %v, want context.Canceled only", err)
+	}
+}
+
+func TestSurrealDBStorageWritesOutliveTimeout(t *testing.T) {
+	s := NewSurrealDBStorage(&ConnectionConfig{DBPath: "memory", QueryTimeout: time.Millisecond})
+
+	ctx, cancelCaller := context.WithCancel(context.Background())
+	callCtx, cancel := s.queryContext(ctx, true)
+	defer cancel()
+	cancelCaller()
+	if _, ok := callCtx.Deadline(); ok {
+		t.Error("write context has a deadline")
+	}
+	got, err := callEmbedded(callCtx, func() (int, error) {
+		time.Sleep(10 * time.Millisecond)
+		return 42, nil
+	})
+	if got != 42 || err != nil {
+		t.Errorf("write after the caller gave up = %d, %v, want 42, nil", got, err)
+	}
+}
+
+func TestIsWriteQuery(t *testing.T) {
+	cases := map[string]bool{
+		"SELECT * FROM vector_memories WHERE user_id = $user_id": false,
+		"  select count() FROM events GROUP ALL;":                false,
+		"INFO FOR DB; SELECT 1;":                                 false,
+		"CREATE user_stats CONTENT $row":                         true,
+		"SELECT 1; DELETE user_stats":                            true,
+		"BEGIN TRANSACTION;\nSELECT 1;\nCOMMIT TRANSACTION;":     true,
+		"LET $r = (CREATE entities CONTENT $e RETURN id)[0].id":  true,
+		"UPSERT kv_memories SET value = $value":                  true,
+	}
+	for query, want := range cases {
+		if got := isWriteQuery(query); got != want {
+			t.Errorf("isWriteQuery(%q) = %v, want %v", query, got, want)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/surrealdb/surrealdb.go"
+)
+
+// Reconnector is implemented by storages whose database server can be
+// restarted under them. Pause makes calls wait instead of failing, Reconnect
+// connects again and lets the waiting calls continue, and Resume lets them
+// continue (to fail) when the server does not come back.
+type Reconnector interface {
+	Pause()
+	Resume()
+	Reconnect(ctx context.Context) error
+}
+
+// Pause holds calls to the remote server until Reconnect or Resume, or until
+// they have waited for the connection timeout.
+func (s *SurrealDBStorage) Pause() {
+	s.connMu.Lock()
+	defer s.connMu.Unlock()
+	if s.resumed == nil {
+		s.resumed = make(chan struct{})
+		slog.Warn("SurrealDB storage paused until the server is reconnected")
+	}
+}
+
+// Resume lets the calls held by Pause continue.
+func (s *SurrealDBStorage) Resume() {
+	s.connMu.Lock()
+	defer s.connMu.Unlock()
+	if s.resumed != nil {
+		close(s.resumed)
+		s.resumed = nil
+	}
+}
+
+// Reconnect replaces the connections to the remote server, including those
+// of the tenants, and resumes paused calls. On failure the storage stays
+// paused so the caller can retry.
+func (s *SurrealDBStorage) Reconnect(ctx context.Context) error {
+	if s.useEmbedded {
+		return errors.New("embedded SurrealDB cannot be reconnected")
+	}
+	db, err := s.dialRemote(ctx)
+	if err != nil {
+		return unavailable(err)
+	}
+
+	s.tenantsMu.RLock()
+	tenants := make(map[string]*SurrealDBStorage, len(s.tenants))
+	for id, ts := range s.tenants {
+		tenants[id] = ts
+	}
+	s.tenantsMu.RUnlock()
+	for id, ts := range tenants {
+		if err := ts.Reconnect(ctx); err != nil {
+			_ = db.Close(ctx)
+			return fmt.Errorf("tenant %q: %w", id, err)
+		}
+	}
+
+	s.connMu.Lock()
+	old := s.db
+	s.db = db
+	s.connMu.Unlock()
+	if old != nil {
+		// The old connection belongs to the server that went away; closing it
+		// may block until its socket times out
+		go func() { _ = old.Close(context.Background()) }()
+	}
+
+	s.Resume()
+	if s.tenantID == "" {
+		slog.Info("Reconnected to remote SurrealDB", "url", s.config.URL, "tenants", len(tenants))
+	}
+	return nil
+}
+
+// conn returns the current remote connection.
+func (s *SurrealDBStorage) conn() *surrealdb.DB {
+	s.connMu.Lock()
+	defer s.connMu.Unlock()
+	return s.db
+}
+
+// waitResumed blocks while the storage is paused, up to the connection
+// timeout.
+func (s *SurrealDBStorage) waitResumed(ctx context.Context) error {
+	s.connMu.Lock()
+	resumed := s.resumed
+	s.connMu.Unlock()
+	if resumed == nil {
+		return nil
+	}
+
+	timer := time.NewTimer(s.config.Timeout)
+	defer timer.Stop()
+	select {
+	case <-resumed:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("database is restarting: %w", errors.Join(ErrUnavailable, ctx.Err()))
+	case <-timer.C:
+		return fmt.Errorf("database is restarting: %w", ErrUnavailable)
+	}
+}
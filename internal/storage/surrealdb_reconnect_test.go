@@ -0,0 +1,29 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSurrealDBStoragePauseResume(t *testing.T) {
+	s := NewSurrealDBStorage(&ConnectionConfig{URL: "ws://localhost:8000", Timeout: 50 * time.Millisecond})
+
+	if err := s.waitResumed(context.Background()); err != nil {
+		t.Fatalf("waitResumed() without Pause error = %v", err)
+	}
+
+	s.Pause()
+	if err := s.waitResumed(context.Background()); !errors.Is(err, ErrUnavailable) {
+		t.Fatalf("waitResumed() after the timeout error = %v, want ErrUnavailable", err)
+	}
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		s.Resume()
+	}()
+	if err := s.waitResumed(context.Background()); err != nil {
+		t.Fatalf("waitResumed() after Resume error = %v", err)
+	}
+}
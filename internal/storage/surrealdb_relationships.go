@@ -0,0 +1,271 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// currentRelationshipCondition matches the relationships that were not ended.
+const currentRelationshipCondition = "(valid_until = NONE OR valid_until > time::now())"
+
+// ListRelationships returns a page of the relationships matching filter,
+// newest first, and how many match in all.
+func (s *SurrealDBStorage) ListRelationships(ctx context.Context, filter RelationshipFilter) ([]Relationship, int, error) {
+	tables, err := s.relationshipTablesOf(ctx, filter.Type)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	conditions := "from_entity != NONE"
+	params := map[string]interface{}{}
+	if filter.Entity != "" {
+		entityID, err := s.resolveEntityID(ctx, filter.Entity)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to resolve entity '%s': %w", filter.Entity, err)
+		}
+		params["entity"] = entityID
+		switch filter.Direction {
+		case RelationshipsOutgoing:
+			conditions += " AND from_entity = $entity"
+		case RelationshipsIncoming:
+			conditions += " AND to_entity = $entity"
+		default:
+			conditions += " AND (from_entity = $entity OR to_entity = $entity)"
+		}
+	}
+	if !filter.IncludeEnded {
+		conditions += " AND " + currentRelationshipCondition
+	}
+
+	var relationships []Relationship
+	for _, table := range tables {
+		query := fmt.Sprintf("SELECT id, from_entity, to_entity, relationship_type, properties, valid_from, valid_until FROM %s WHERE %s", table, conditions)
+		result, err := s.query(ctx, query, params)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to list %s relationships: %w", table, err)
+		}
+		if result == nil || len(*result) == 0 {
+			continue
+		}
+		for _, row := range (*result)[0].Result {
+			relationships = append(relationships, surrealRelationship(row, table))
+		}
+	}
+
+	sort.SliceStable(relationships, func(i, j int) bool {
+		ti, tj := relationshipStart(relationships[i]), relationshipStart(relationships[j])
+		if !ti.Equal(tj) {
+			return ti.After(tj)
+		}
+		return relationships[i].ID < relationships[j].ID
+	})
+	total := len(relationships)
+	relationships = pageOf(relationships, filter.Offset, filter.Limit)
+
+	if len(relationships) > 0 {
+		entities, err := s.entityDegrees(ctx)
+		if err != nil {
+			return nil, 0, err
+		}
+		for i := range relationships {
+			relationships[i].FromName = entities[relationships[i].From].Name
+			relationships[i].ToName = entities[relationships[i].To].Name
+		}
+	}
+	return relationships, total, nil
+}
+
+// GetGraphStats counts the entities and current relationships by type and
+// returns the top entities with the most relationships.
+func (s *SurrealDBStorage) GetGraphStats(ctx context.Context, top int) (*GraphStats, error) {
+	entities, err := s.entityDegrees(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tables, err := s.getRelationshipTables(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &GraphStats{
+		EntityCount:         len(entities),
+		EntitiesByType:      map[string]int{},
+		RelationshipsByType: map[string]int{},
+	}
+	for _, entity := range entities {
+		stats.EntitiesByType[entity.Type]++
+	}
+	for _, table := range tables {
+		query := fmt.Sprintf("SELECT from_entity, to_entity FROM %s WHERE from_entity != NONE AND %s", table, currentRelationshipCondition)
+		result, err := s.query(ctx, query, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count %s relationships: %w", table, err)
+		}
+		if result == nil || len(*result) == 0 || len((*result)[0].Result) == 0 {
+			continue
+		}
+		rows := (*result)[0].Result
+		stats.RelationshipsByType[table] += len(rows)
+		stats.RelationshipCount += len(rows)
+		for _, row := range rows {
+			for _, field := range []string{"from_entity", "to_entity"} {
+				if entity, ok := entities[getString(row, field)]; ok {
+					entity.Degree++
+				}
+			}
+		}
+	}
+
+	degrees := make([]EntityDegree, 0, len(entities))
+	for _, entity := range entities {
+		if entity.Degree > 0 {
+			degrees = append(degrees, *entity)
+		}
+	}
+	stats.TopEntities = topEntityDegrees(degrees, top)
+	return stats, nil
+}
+
+// FindPaths returns up to limit paths of at most maxHops current
+// relationships, followed in either direction, connecting two entities,
+// shortest first.
+func (s *SurrealDBStorage) FindPaths(ctx context.Context, fromEntity, toEntity string, maxHops, limit int) ([]GraphPath, error) {
+	fromID, err := s.resolveEntityID(ctx, fromEntity)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve entity '%s': %w", fromEntity, err)
+	}
+	toID, err := s.resolveEntityID(ctx, toEntity)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve entity '%s': %w", toEntity, err)
+	}
+	tables, err := s.getRelationshipTables(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return findPaths(ctx, pathGraph{
+		relationshipsOf: func(ctx context.Context, entityIDs []string) ([]Relationship, error) {
+			var relationships []Relationship
+			for _, table := range tables {
+				query := fmt.Sprintf("SELECT id, from_entity, to_entity, relationship_type, properties, valid_from, valid_until FROM %s WHERE from_entity != NONE AND (from_entity IN $ids OR to_entity IN $ids) AND %s", table, currentRelationshipCondition)
+				result, err := s.query(ctx, query, map[string]interface{}{"ids": entityIDs})
+				if err != nil {
+					return nil, fmt.Errorf("failed to get %s relationships: %w", table, err)
+				}
+				if result == nil || len(*result) == 0 {
+					continue
+				}
+				for _, row := range (*result)[0].Result {
+					relationships = append(relationships, surrealRelationship(row, table))
+				}
+			}
+			return relationships, nil
+		},
+		entities: func(ctx context.Context, entityIDs []string) (map[string]PathEntity, error) {
+			all, err := s.entityDegrees(ctx)
+			if err != nil {
+				return nil, err
+			}
+			entities := make(map[string]PathEntity, len(entityIDs))
+			for _, id := range entityIDs {
+				if e, ok := all[id]; ok {
+					entities[id] = PathEntity{ID: e.ID, Name: e.Name, Type: e.Type}
+				}
+			}
+			return entities, nil
+		},
+	}, fromID, toID, maxHops, limit)
+}
+
+// relationshipTablesOf returns the table of relationshipType, or all the
+// relationship tables when it is empty.
+func (s *SurrealDBStorage) relationshipTablesOf(ctx context.Context, relationshipType string) ([]string, error) {
+	if relationshipType == "" {
+		return s.getRelationshipTables(ctx)
+	}
+	if !relationshipTableRe.MatchString(relationshipType) {
+		return nil, fmt.Errorf("invalid relationship_type %q: use letters, digits and underscores", relationshipType)
+	}
+	tables, err := s.getRelationshipTables(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, table := range tables {
+		if table == relationshipType {
+			return []string{table}, nil
+		}
+	}
+	return nil, nil
+}
+
+// entityDegrees returns all the entities by ID, with a zero degree.
+func (s *SurrealDBStorage) entityDegrees(ctx context.Context) (map[string]*EntityDegree, error) {
+	result, err := s.query(ctx, "SELECT id, name, entity_type, type FROM entities", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list entities: %w", err)
+	}
+	entities := map[string]*EntityDegree{}
+	if result == nil || len(*result) == 0 {
+		return entities, nil
+	}
+	for _, row := range (*result)[0].Result {
+		entity := &EntityDegree{
+			ID:   extractRecordID(row["id"]),
+			Name: getString(row, "name"),
+			Type: getString(row, "entity_type"),
+		}
+		if entity.Type == "" {
+			entity.Type = getString(row, "type")
+		}
+		entities[entity.ID] = entity
+	}
+	return entities, nil
+}
+
+// surrealRelationship converts a row of a relationship table.
+func surrealRelationship(row map[string]interface{}, table string) Relationship {
+	relationship := Relationship{
+		ID:         extractRecordID(row["id"]),
+		From:       getString(row, "from_entity"),
+		To:         getString(row, "to_entity"),
+		Type:       getString(row, "relationship_type"),
+		Properties: getMap(row, "properties"),
+	}
+	if relationship.Type == "" {
+		relationship.Type = table
+	}
+	if validFrom := getTime(row, "valid_from"); !validFrom.IsZero() {
+		relationship.ValidFrom = &validFrom
+		relationship.Timestamp = validFrom
+	}
+	if validUntil := getTime(row, "valid_until"); !validUntil.IsZero() {
+		relationship.ValidUntil = &validUntil
+	}
+	return relationship
+}
+
+// relationshipStart returns when a relationship started to hold.
+func relationshipStart(r Relationship) time.Time {
+	if r.ValidFrom != nil {
+		return *r.ValidFrom
+	}
+	return r.Timestamp
+}
+
+// pageOf returns the items of a page of limit items starting at offset. A
+// limit of zero or less returns all the items after offset.
+func pageOf[T any](items []T, offset, limit int) []T {
+	if offset < 0 {
+		offset = 0
+	}
+	if offset >= len(items) {
+		return items[:0]
+	}
+	items = items[offset:]
+	if limit > 0 && len(items) > limit {
+		items = items[:limit]
+	}
+	return items
+}
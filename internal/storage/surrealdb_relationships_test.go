@@ -0,0 +1,44 @@
+package storage
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTopEntityDegrees(t *testing.T) {
+	degrees := []EntityDegree{
+		{Name: "carol", Degree: 1},
+		{Name: "bob", Degree: 3},
+		{Name: "alice", Degree: 3},
+		{Name: "dave", Degree: 2},
+	}
+	var names []string
+	for _, d := range topEntityDegrees(degrees, 3) {
+		names = append(names, d.Name)
+	}
+	if want := []string{"alice", "bob", "dave"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("topEntityDegrees() = %v, want %v", names, want)
+	}
+	if got := topEntityDegrees(degrees, -1); len(got) != 4 {
+		t.Errorf("topEntityDegrees(-1) kept %d entities, want all 4", len(got))
+	}
+}
+
+func TestPageOf(t *testing.T) {
+	items := []int{1, 2, 3, 4, 5}
+	for _, tc := range []struct {
+		offset, limit int
+		want          []int
+	}{
+		{0, 2, []int{1, 2}},
+		{2, 2, []int{3, 4}},
+		{4, 2, []int{5}},
+		{5, 2, []int{}},
+		{1, 0, []int{2, 3, 4, 5}},
+		{-1, 1, []int{1}},
+	} {
+		if got := pageOf(items, tc.offset, tc.limit); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("pageOf(%d, %d) = %v, want %v", tc.offset, tc.limit, got, tc.want)
+		}
+	}
+}
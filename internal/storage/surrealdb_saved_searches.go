@@ -0,0 +1,229 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// SaveSearch stores search under its user and name, replacing the search
+// saved before with that name. Replacing a search forgets its last run, so
+// its next scheduled run starts a new baseline.
+func (s *SurrealDBStorage) SaveSearch(ctx context.Context, search SavedSearch) error {
+	arguments, err := json.Marshal(search.Arguments)
+	if err != nil {
+		return fmt.Errorf("failed to encode saved search arguments: %w", err)
+	}
+	query := `
+		INSERT INTO saved_searches {
+			user_id: $user_id,
+			name: $name,
+			tool: $tool,
+			arguments: $arguments,
+			description: $description,
+			interval_seconds: $interval_seconds
+		}
+		ON DUPLICATE KEY UPDATE
+			tool = $input.tool,
+			arguments = $input.arguments,
+			description = $input.description,
+			interval_seconds = $input.interval_seconds,
+			last_run_at = NONE,
+			last_results = "[]",
+			updated_at = time::now()
+		RETURN NONE`
+	_, err = s.query(ctx, query, map[string]interface{}{
+		"user_id":          search.UserID,
+		"name":             search.Name,
+		"tool":             search.Tool,
+		"arguments":        string(arguments),
+		"description":      search.Description,
+		"interval_seconds": search.IntervalSeconds,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save search: %w", err)
+	}
+	return nil
+}
+
+// GetSavedSearch returns the search saved by userID under name, or nil when
+// there is none.
+func (s *SurrealDBStorage) GetSavedSearch(ctx context.Context, userID, name string) (*SavedSearch, error) {
+	query := "SELECT * FROM saved_searches WHERE user_id = $user_id AND name = $name LIMIT 1"
+	result, err := s.query(ctx, query, map[string]interface{}{
+		"user_id": userID,
+		"name":    name,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get saved search: %w", err)
+	}
+	if result == nil || len(*result) == 0 || (*result)[0].Status != "OK" || len((*result)[0].Result) == 0 {
+		return nil, nil
+	}
+	search := savedSearchFromRow((*result)[0].Result[0])
+	return &search, nil
+}
+
+// ListSavedSearches returns the searches saved by userID, or by every user
+// when userID is empty, sorted by name.
+func (s *SurrealDBStorage) ListSavedSearches(ctx context.Context, userID string) ([]SavedSearch, error) {
+	query := "SELECT * FROM saved_searches WHERE $user_id = '' OR user_id = $user_id ORDER BY name ASC"
+	result, err := s.query(ctx, query, map[string]interface{}{"user_id": userID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list saved searches: %w", err)
+	}
+	searches := []SavedSearch{}
+	if result != nil && len(*result) > 0 && (*result)[0].Status == "OK" {
+		for _, row := range (*result)[0].Result {
+			searches = append(searches, savedSearchFromRow(row))
+		}
+	}
+	return searches, nil
+}
+
+// DeleteSavedSearch removes the search saved by userID under name and
+// reports whether there was one.
+func (s *SurrealDBStorage) DeleteSavedSearch(ctx context.Context, userID, name string) (bool, error) {
+	query := "DELETE FROM saved_searches WHERE user_id = $user_id AND name = $name RETURN BEFORE"
+	result, err := s.query(ctx, query, map[string]interface{}{
+		"user_id": userID,
+		"name":    name,
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to delete saved search: %w", err)
+	}
+	return result != nil && len(*result) > 0 && len((*result)[0].Result) > 0, nil
+}
+
+// RecordSavedSearchRun stores the results of a scheduled run of a saved
+// search and, when it found new results, the change.
+func (s *SurrealDBStorage) RecordSavedSearchRun(ctx context.Context, userID, name string, runAt time.Time, results []string, change *SearchChange) error {
+	if results == nil {
+		results = []string{}
+	}
+	encoded, err := json.Marshal(results)
+	if err != nil {
+		return fmt.Errorf("failed to encode saved search results: %w", err)
+	}
+	runAtText := runAt.UTC().Format(time.RFC3339Nano)
+
+	tx := newSurrealTx()
+	tx.add("UPDATE saved_searches SET last_run_at = <datetime>$run_at, last_results = $results WHERE user_id = $user_id AND name = $name RETURN NONE", map[string]interface{}{
+		"run_at":  runAtText,
+		"results": string(encoded),
+		"user_id": userID,
+		"name":    name,
+	})
+	if change != nil {
+		items, err := json.Marshal(change.Items)
+		if err != nil {
+			return fmt.Errorf("failed to encode saved search changes: %w", err)
+		}
+		tx.add("CREATE saved_search_changes CONTENT { user_id: $user_id, name: $name, tool: $tool, items: $items, run_at: <datetime>$run_at } RETURN NONE", map[string]interface{}{
+			"user_id": userID,
+			"name":    name,
+			"tool":    change.Tool,
+			"items":   string(items),
+			"run_at":  runAtText,
+		})
+	}
+	if err := s.commitTx(ctx, tx); err != nil {
+		return fmt.Errorf("failed to record saved search run: %w", err)
+	}
+	return nil
+}
+
+// ListSearchChanges returns the changes found by the scheduled runs of the
+// searches of userID since the given time, newest first. An empty name
+// selects every search of the user.
+func (s *SurrealDBStorage) ListSearchChanges(ctx context.Context, userID, name string, since time.Time, limit int) ([]SearchChange, error) {
+	query := `
+		SELECT * FROM saved_search_changes
+		WHERE user_id = $user_id AND ($name = '' OR name = $name) AND run_at >= <datetime>$since
+		ORDER BY run_at DESC LIMIT $limit`
+	result, err := s.query(ctx, query, map[string]interface{}{
+		"user_id": userID,
+		"name":    name,
+		"since":   since.UTC().Format(time.RFC3339Nano),
+		"limit":   limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list saved search changes: %w", err)
+	}
+	changes := []SearchChange{}
+	if result != nil && len(*result) > 0 && (*result)[0].Status == "OK" {
+		for _, row := range (*result)[0].Result {
+			changes = append(changes, searchChangeFromRow(row))
+		}
+	}
+	return changes, nil
+}
+
+// PurgeSearchChanges deletes the changes found before the given time and
+// returns how many were removed.
+func (s *SurrealDBStorage) PurgeSearchChanges(ctx context.Context, before time.Time) (int, error) {
+	params := map[string]interface{}{
+		"before": before.UTC().Format(time.RFC3339),
+	}
+	count := s.getCount(ctx, "SELECT count() AS count FROM saved_search_changes WHERE run_at < <datetime>$before GROUP ALL", params)
+	if count == 0 {
+		return 0, nil
+	}
+	if _, err := s.query(ctx, "DELETE FROM saved_search_changes WHERE run_at < <datetime>$before", params); err != nil {
+		return 0, fmt.Errorf("failed to purge saved search changes: %w", err)
+	}
+	return count, nil
+}
+
+// savedSearchFromRow decodes a saved_searches row.
+func savedSearchFromRow(row map[string]interface{}) SavedSearch {
+	search := SavedSearch{
+		UserID:      getString(row, "user_id"),
+		Name:        getString(row, "name"),
+		Tool:        getString(row, "tool"),
+		Description: getString(row, "description"),
+		CreatedAt:   getTime(row, "created_at"),
+		UpdatedAt:   getTime(row, "updated_at"),
+	}
+	decodeJSONColumn(row["arguments"], &search.Arguments)
+	if search.Arguments == nil {
+		search.Arguments = map[string]interface{}{}
+	}
+	search.IntervalSeconds = convertToInt(row["interval_seconds"])
+	if lastRun := getTime(row, "last_run_at"); !lastRun.IsZero() {
+		search.LastRunAt = &lastRun
+	}
+	decodeJSONColumn(row["last_results"], &search.LastResults)
+	return search
+}
+
+// searchChangeFromRow decodes a saved_search_changes row.
+func searchChangeFromRow(row map[string]interface{}) SearchChange {
+	change := SearchChange{
+		UserID: getString(row, "user_id"),
+		Name:   getString(row, "name"),
+		Tool:   getString(row, "tool"),
+		RunAt:  getTime(row, "run_at"),
+	}
+	decodeJSONColumn(row["items"], &change.Items)
+	return change
+}
+
+// decodeJSONColumn decodes a column holding JSON: text in SurrealDB, already
+// decoded values in Postgres.
+func decodeJSONColumn(value interface{}, out interface{}) {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		return
+	case string:
+		data = []byte(v)
+	default:
+		var err error
+		if data, err = json.Marshal(v); err != nil {
+			return
+		}
+	}
+	_ = json.Unmarshal(data, out)
+}
@@ -16,9 +16,18 @@ import (
 // Default MTREE embedding dimension used in schema. Keep in sync with schema statements.
 const defaultMtreeDim = 768
 
+// EmbeddingDimension is the dimension the storage schemas index embeddings
+// at. Embedders routed to a record kind must produce vectors of this size.
+const EmbeddingDimension = defaultMtreeDim
+
+// schemaVersion is the version migrations bring the schema to.
+const schemaVersion = 37 // v37: relationships of deleted entities in the trash
+
 // InitializeSchema creates all required tables and indexes
 func (s *SurrealDBStorage) InitializeSchema(ctx context.Context) error {
 	slog.Info("Initializing SurrealDB schema...")
+	// Migrations rewrite whole tables, which can take longer than a query
+	ctx = WithoutQueryTimeout(ctx)
 
 	// First, ensure schema_version table exists to track migrations
 	err := s.ensureSchemaVersionTable(ctx)
@@ -33,7 +42,7 @@ func (s *SurrealDBStorage) InitializeSchema(ctx context.Context) error {
 	}
 
 	// Run migrations if needed
-	targetVersion := 12 // v12: watcher_enabled field for code_projects
+	targetVersion := schemaVersion
 	if currentVersion < targetVersion {
 		slog.Info("Running schema migrations", "from", currentVersion, "to", targetVersion)
 		err = s.runMigrations(ctx, currentVersion, targetVersion)
@@ -189,6 +198,56 @@ func (s *SurrealDBStorage) applyMigration(ctx context.Context, version int) erro
 		migration = migrations.NewV11Events(s.db)
 	case 12:
 		migration = migrations.NewV12CodeProjectsWatcher(s.db)
+	case 13:
+		migration = migrations.NewV13QuantizedEmbeddings(s.db)
+	case 14:
+		migration = migrations.NewV14DocumentVersions(s.db)
+	case 15:
+		migration = migrations.NewV15ArchivedVectorMemories(s.db)
+	case 16:
+		migration = migrations.NewV16Trash(s.db)
+	case 17:
+		migration = migrations.NewV17Revisions(s.db)
+	case 18:
+		migration = migrations.NewV18AuditLog(s.db)
+	case 19:
+		migration = migrations.NewV19CodeDependencies(s.db)
+	case 20:
+		migration = migrations.NewV20CodeCalls(s.db)
+	case 21:
+		migration = migrations.NewV21CodeFileContents(s.db)
+	case 22:
+		migration = migrations.NewV22MemoryHits(s.db)
+	case 23:
+		migration = migrations.NewV23EmbeddingModel(s.db)
+	case 24:
+		migration = migrations.NewV24IdempotencyKeys(s.db)
+	case 25:
+		migration = migrations.NewV25SavedSearches(s.db)
+	case 26:
+		migration = migrations.NewV26ScheduledSearches(s.db)
+	case 27:
+		migration = migrations.NewV27PinnedMemories(s.db)
+	case 28:
+		migration = migrations.NewV28FactProvenance(s.db)
+	case 29:
+		migration = migrations.NewV29TemporalFacts(s.db)
+	case 30:
+		migration = migrations.NewV30EntityTypes(s.db)
+	case 31:
+		migration = migrations.NewV31CodeSymbolDocs(s.db)
+	case 32:
+		migration = migrations.NewV32CodeProjectAliases(s.db)
+	case 33:
+		migration = migrations.NewV33CodeProjectSettings(s.db)
+	case 34:
+		migration = migrations.NewV34StableCodeSymbolIDs(s.db)
+	case 35:
+		migration = migrations.NewV35CosineVectorIndexes(s.db)
+	case 36:
+		migration = migrations.NewV36PackedCodeEmbeddings(s.db)
+	case 37:
+		migration = migrations.NewV37TrashRelationships(s.db)
 	default:
 		return fmt.Errorf("unknown migration version: %d", version)
 	}
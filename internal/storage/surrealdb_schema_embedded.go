@@ -62,6 +62,56 @@ func (s *SurrealDBStorage) getEmbeddedMigrationStatements(version int) []string
 		return s.getMigrationV11Statements()
 	case 12:
 		return s.getMigrationV12Statements()
+	case 13:
+		return s.getMigrationV13Statements()
+	case 14:
+		return s.getMigrationV14Statements()
+	case 15:
+		return s.getMigrationV15Statements()
+	case 16:
+		return s.getMigrationV16Statements()
+	case 17:
+		return s.getMigrationV17Statements()
+	case 18:
+		return s.getMigrationV18Statements()
+	case 19:
+		return s.getMigrationV19Statements()
+	case 20:
+		return s.getMigrationV20Statements()
+	case 21:
+		return s.getMigrationV21Statements()
+	case 22:
+		return s.getMigrationV22Statements()
+	case 23:
+		return s.getMigrationV23Statements()
+	case 24:
+		return s.getMigrationV24Statements()
+	case 25:
+		return s.getMigrationV25Statements()
+	case 26:
+		return s.getMigrationV26Statements()
+	case 27:
+		return s.getMigrationV27Statements()
+	case 28:
+		return s.getMigrationV28Statements()
+	case 29:
+		return s.getMigrationV29Statements()
+	case 30:
+		return s.getMigrationV30Statements()
+	case 31:
+		return s.getMigrationV31Statements()
+	case 32:
+		return s.getMigrationV32Statements()
+	case 33:
+		return s.getMigrationV33Statements()
+	case 34:
+		return s.getMigrationV34Statements()
+	case 35:
+		return s.getMigrationV35Statements()
+	case 36:
+		return s.getMigrationV36Statements()
+	case 37:
+		return s.getMigrationV37Statements()
 	default:
 		return nil
 	}
@@ -294,3 +344,363 @@ func (s *SurrealDBStorage) getMigrationV12Statements() []string {
 		`DEFINE FIELD watcher_enabled ON code_projects TYPE bool DEFAULT false;`,
 	}
 }
+
+// getMigrationV13Statements returns V13 migration statements (quantized embeddings)
+func (s *SurrealDBStorage) getMigrationV13Statements() []string {
+	slog.Debug("Migration V13: Allowing quantized numeric components in embedding fields")
+	return []string{
+		`REMOVE FIELD embedding ON vector_memories;`,
+		`REMOVE FIELD embedding ON knowledge_base;`,
+		`REMOVE FIELD embedding ON events;`,
+		`REMOVE FIELD embedding ON code_symbols;`,
+		`REMOVE FIELD embedding ON code_chunks;`,
+		fmt.Sprintf(`DEFINE FIELD embedding ON vector_memories TYPE array<number, %d>;`, defaultMtreeDim),
+		fmt.Sprintf(`DEFINE FIELD embedding ON knowledge_base TYPE array<number, %d>;`, defaultMtreeDim),
+		fmt.Sprintf(`DEFINE FIELD embedding ON events TYPE array<number, %d>;`, defaultMtreeDim),
+		fmt.Sprintf(`DEFINE FIELD embedding ON code_symbols TYPE option<array<number, %d>>;`, defaultMtreeDim),
+		fmt.Sprintf(`DEFINE FIELD embedding ON code_chunks TYPE option<array<number, %d>>;`, defaultMtreeDim),
+	}
+}
+
+// getMigrationV14Statements returns V14 migration statements (kb document versions)
+func (s *SurrealDBStorage) getMigrationV14Statements() []string {
+	slog.Debug("Migration V14: Creating kb_document_versions table")
+	return []string{
+		`DEFINE TABLE kb_document_versions SCHEMAFULL;`,
+		`DEFINE FIELD file_path ON kb_document_versions TYPE string;`,
+		`DEFINE FIELD version ON kb_document_versions TYPE int;`,
+		`DEFINE FIELD content ON kb_document_versions TYPE string;`,
+		`DEFINE FIELD diff ON kb_document_versions TYPE string DEFAULT "";`,
+		`DEFINE FIELD chunk_count ON kb_document_versions TYPE int DEFAULT 0;`,
+		fmt.Sprintf(`DEFINE FIELD embedding ON kb_document_versions TYPE array<number, %d>;`, defaultMtreeDim),
+		`DEFINE FIELD metadata ON kb_document_versions FLEXIBLE TYPE object DEFAULT {};`,
+		`DEFINE FIELD created_at ON kb_document_versions TYPE datetime DEFAULT time::now();`,
+		`DEFINE INDEX idx_kb_versions_file_version ON kb_document_versions FIELDS file_path, version UNIQUE;`,
+		fmt.Sprintf(`DEFINE INDEX idx_kb_versions_embedding ON kb_document_versions FIELDS embedding MTREE DIMENSION %d;`, defaultMtreeDim),
+	}
+}
+
+// getMigrationV15Statements returns V15 migration statements (archived vector memories)
+func (s *SurrealDBStorage) getMigrationV15Statements() []string {
+	slog.Debug("Migration V15: Creating archived_vector_memories table")
+	return []string{
+		`DEFINE TABLE archived_vector_memories SCHEMAFULL;`,
+		`DEFINE FIELD user_id ON archived_vector_memories TYPE option<string>;`,
+		`DEFINE FIELD content ON archived_vector_memories TYPE string;`,
+		`DEFINE FIELD embedding ON archived_vector_memories TYPE array<number>;`,
+		`DEFINE FIELD metadata ON archived_vector_memories FLEXIBLE TYPE object DEFAULT {};`,
+		`DEFINE FIELD original_id ON archived_vector_memories TYPE string;`,
+		`DEFINE FIELD consolidated_into ON archived_vector_memories TYPE string;`,
+		`DEFINE FIELD created_at ON archived_vector_memories TYPE option<datetime>;`,
+		`DEFINE FIELD archived_at ON archived_vector_memories TYPE datetime DEFAULT time::now();`,
+		`DEFINE INDEX idx_archived_vectors_user ON archived_vector_memories FIELDS user_id;`,
+		`DEFINE INDEX idx_archived_vectors_summary ON archived_vector_memories FIELDS consolidated_into;`,
+	}
+}
+
+// getMigrationV16Statements returns V16 migration statements (trash for soft deletes)
+func (s *SurrealDBStorage) getMigrationV16Statements() []string {
+	slog.Debug("Migration V16: Creating trash table")
+	return []string{
+		`DEFINE TABLE trash SCHEMAFULL;`,
+		`DEFINE FIELD kind ON trash TYPE string;`,
+		`DEFINE FIELD source_table ON trash TYPE string;`,
+		`DEFINE FIELD user_id ON trash TYPE string DEFAULT "";`,
+		`DEFINE FIELD label ON trash TYPE string DEFAULT "";`,
+		`DEFINE FIELD content ON trash TYPE string DEFAULT "";`,
+		`DEFINE FIELD records ON trash FLEXIBLE TYPE array<object> DEFAULT [];`,
+		`DEFINE FIELD deleted_at ON trash TYPE datetime DEFAULT time::now();`,
+		`DEFINE INDEX idx_trash_user ON trash FIELDS user_id;`,
+		`DEFINE INDEX idx_trash_deleted_at ON trash FIELDS deleted_at;`,
+	}
+}
+
+// getMigrationV17Statements returns V17 migration statements (revision fields)
+func (s *SurrealDBStorage) getMigrationV17Statements() []string {
+	slog.Debug("Migration V17: Adding revision fields")
+	return []string{
+		`DEFINE FIELD revision ON vector_memories TYPE int DEFAULT 1;`,
+		`DEFINE FIELD revision ON code_symbols TYPE option<string>;`,
+		`UPDATE vector_memories SET revision = 1 WHERE revision IS NONE RETURN NONE;`,
+	}
+}
+
+// getMigrationV18Statements returns V18 migration statements (audit log)
+func (s *SurrealDBStorage) getMigrationV18Statements() []string {
+	slog.Debug("Migration V18: Creating audit_log table")
+	return []string{
+		`DEFINE TABLE audit_log SCHEMAFULL;`,
+		`DEFINE FIELD action ON audit_log TYPE string;`,
+		`DEFINE FIELD subject ON audit_log TYPE string DEFAULT "";`,
+		`DEFINE FIELD details ON audit_log FLEXIBLE TYPE object DEFAULT {};`,
+		`DEFINE FIELD created_at ON audit_log TYPE datetime DEFAULT time::now();`,
+		`DEFINE INDEX idx_audit_log_action ON audit_log FIELDS action;`,
+		`DEFINE INDEX idx_audit_log_subject ON audit_log FIELDS subject;`,
+	}
+}
+
+// getMigrationV19Statements returns V19 migration statements (code dependencies)
+func (s *SurrealDBStorage) getMigrationV19Statements() []string {
+	slog.Debug("Migration V19: Creating code_dependencies table")
+	return []string{
+		`DEFINE TABLE code_dependencies SCHEMAFULL;`,
+		`DEFINE FIELD project_id ON code_dependencies TYPE string;`,
+		`DEFINE FIELD file_path ON code_dependencies TYPE string;`,
+		`DEFINE FIELD module ON code_dependencies TYPE string;`,
+		`DEFINE FIELD target_path ON code_dependencies TYPE option<string>;`,
+		`DEFINE FIELD line ON code_dependencies TYPE int DEFAULT 0;`,
+		`DEFINE FIELD language ON code_dependencies TYPE string DEFAULT "";`,
+		`DEFINE FIELD created_at ON code_dependencies TYPE datetime DEFAULT time::now();`,
+		`DEFINE INDEX idx_code_dependencies_file ON code_dependencies FIELDS project_id, file_path;`,
+		`DEFINE INDEX idx_code_dependencies_target ON code_dependencies FIELDS project_id, target_path;`,
+		`DEFINE INDEX idx_code_dependencies_module ON code_dependencies FIELDS project_id, module;`,
+	}
+}
+
+// getMigrationV20Statements returns V20 migration statements (code calls)
+func (s *SurrealDBStorage) getMigrationV20Statements() []string {
+	slog.Debug("Migration V20: Creating code_calls table")
+	return []string{
+		`DEFINE TABLE code_calls SCHEMAFULL;`,
+		`DEFINE FIELD project_id ON code_calls TYPE string;`,
+		`DEFINE FIELD file_path ON code_calls TYPE string;`,
+		`DEFINE FIELD caller_name_path ON code_calls TYPE string DEFAULT "";`,
+		`DEFINE FIELD caller_name ON code_calls TYPE string DEFAULT "";`,
+		`DEFINE FIELD callee_name ON code_calls TYPE string;`,
+		`DEFINE FIELD callee_expr ON code_calls TYPE string DEFAULT "";`,
+		`DEFINE FIELD line ON code_calls TYPE int DEFAULT 0;`,
+		`DEFINE FIELD language ON code_calls TYPE string DEFAULT "";`,
+		`DEFINE FIELD created_at ON code_calls TYPE datetime DEFAULT time::now();`,
+		`DEFINE INDEX idx_code_calls_file ON code_calls FIELDS project_id, file_path;`,
+		`DEFINE INDEX idx_code_calls_callee ON code_calls FIELDS project_id, callee_name;`,
+		`DEFINE INDEX idx_code_calls_caller ON code_calls FIELDS project_id, caller_name;`,
+	}
+}
+
+// getMigrationV21Statements returns V21 migration statements (code file contents)
+func (s *SurrealDBStorage) getMigrationV21Statements() []string {
+	slog.Debug("Migration V21: Adding content_gz field to code_files")
+	return []string{
+		`DEFINE FIELD content_gz ON code_files TYPE option<string>;`,
+	}
+}
+
+// getMigrationV22Statements returns V22 migration statements (memory retrieval hits)
+func (s *SurrealDBStorage) getMigrationV22Statements() []string {
+	slog.Debug("Migration V22: Creating memory_hits table")
+	return []string{
+		`DEFINE TABLE memory_hits SCHEMAFULL;`,
+		`DEFINE FIELD kind ON memory_hits TYPE string;`,
+		`DEFINE FIELD ref ON memory_hits TYPE string;`,
+		`DEFINE FIELD user_id ON memory_hits TYPE string DEFAULT "";`,
+		`DEFINE FIELD tool ON memory_hits TYPE string DEFAULT "";`,
+		`DEFINE FIELD created_at ON memory_hits TYPE datetime DEFAULT time::now();`,
+		`DEFINE INDEX idx_memory_hits_ref ON memory_hits FIELDS kind, ref;`,
+		`DEFINE INDEX idx_memory_hits_user ON memory_hits FIELDS user_id, created_at;`,
+	}
+}
+
+// getMigrationV23Statements returns V23 migration statements (embedding model per record)
+func (s *SurrealDBStorage) getMigrationV23Statements() []string {
+	slog.Debug("Migration V23: Adding embedding_model and embedding_dim fields to embedded tables")
+	return []string{
+		`DEFINE FIELD embedding_model ON vector_memories TYPE option<string>;`,
+		`DEFINE FIELD embedding_dim ON vector_memories TYPE option<int>;`,
+		`DEFINE FIELD embedding_model ON knowledge_base TYPE option<string>;`,
+		`DEFINE FIELD embedding_dim ON knowledge_base TYPE option<int>;`,
+		`DEFINE FIELD embedding_model ON kb_document_versions TYPE option<string>;`,
+		`DEFINE FIELD embedding_dim ON kb_document_versions TYPE option<int>;`,
+		`DEFINE FIELD embedding_model ON events TYPE option<string>;`,
+		`DEFINE FIELD embedding_dim ON events TYPE option<int>;`,
+		`DEFINE FIELD embedding_model ON code_symbols TYPE option<string>;`,
+		`DEFINE FIELD embedding_dim ON code_symbols TYPE option<int>;`,
+		`DEFINE FIELD embedding_model ON code_chunks TYPE option<string>;`,
+		`DEFINE FIELD embedding_dim ON code_chunks TYPE option<int>;`,
+	}
+}
+
+// getMigrationV24Statements returns V24 migration statements (idempotency keys)
+func (s *SurrealDBStorage) getMigrationV24Statements() []string {
+	slog.Debug("Migration V24: Creating idempotency_keys table")
+	return []string{
+		`DEFINE TABLE idempotency_keys SCHEMAFULL;`,
+		`DEFINE FIELD tool ON idempotency_keys TYPE string;`,
+		`DEFINE FIELD key ON idempotency_keys TYPE string;`,
+		`DEFINE FIELD result ON idempotency_keys TYPE string;`,
+		`DEFINE FIELD created_at ON idempotency_keys TYPE datetime DEFAULT time::now();`,
+		`DEFINE INDEX idx_idempotency_keys_key ON idempotency_keys FIELDS tool, key UNIQUE;`,
+		`DEFINE INDEX idx_idempotency_keys_created ON idempotency_keys FIELDS created_at;`,
+	}
+}
+
+// getMigrationV25Statements returns V25 migration statements (saved searches)
+func (s *SurrealDBStorage) getMigrationV25Statements() []string {
+	slog.Debug("Migration V25: Creating saved_searches table")
+	return []string{
+		`DEFINE TABLE saved_searches SCHEMAFULL;`,
+		`DEFINE FIELD user_id ON saved_searches TYPE string;`,
+		`DEFINE FIELD name ON saved_searches TYPE string;`,
+		`DEFINE FIELD tool ON saved_searches TYPE string;`,
+		`DEFINE FIELD arguments ON saved_searches TYPE string;`,
+		`DEFINE FIELD description ON saved_searches TYPE string DEFAULT "";`,
+		`DEFINE FIELD created_at ON saved_searches TYPE datetime DEFAULT time::now();`,
+		`DEFINE FIELD updated_at ON saved_searches TYPE datetime DEFAULT time::now();`,
+		`DEFINE INDEX idx_saved_searches_name ON saved_searches FIELDS user_id, name UNIQUE;`,
+	}
+}
+
+// getMigrationV26Statements returns V26 migration statements (scheduled saved searches)
+func (s *SurrealDBStorage) getMigrationV26Statements() []string {
+	slog.Debug("Migration V26: Scheduling saved searches")
+	return []string{
+		`DEFINE FIELD interval_seconds ON saved_searches TYPE int DEFAULT 0;`,
+		`DEFINE FIELD last_run_at ON saved_searches TYPE option<datetime>;`,
+		`DEFINE FIELD last_results ON saved_searches TYPE string DEFAULT "[]";`,
+		`DEFINE TABLE saved_search_changes SCHEMAFULL;`,
+		`DEFINE FIELD user_id ON saved_search_changes TYPE string;`,
+		`DEFINE FIELD name ON saved_search_changes TYPE string;`,
+		`DEFINE FIELD tool ON saved_search_changes TYPE string;`,
+		`DEFINE FIELD items ON saved_search_changes TYPE string;`,
+		`DEFINE FIELD run_at ON saved_search_changes TYPE datetime DEFAULT time::now();`,
+		`DEFINE INDEX idx_saved_search_changes_user ON saved_search_changes FIELDS user_id, name, run_at;`,
+		`DEFINE INDEX idx_saved_search_changes_run ON saved_search_changes FIELDS run_at;`,
+	}
+}
+
+// getMigrationV27Statements returns V27 migration statements (pinned memories)
+func (s *SurrealDBStorage) getMigrationV27Statements() []string {
+	slog.Debug("Migration V27: Creating pinned_memories table")
+	return []string{
+		`DEFINE TABLE pinned_memories SCHEMAFULL;`,
+		`DEFINE FIELD kind ON pinned_memories TYPE string;`,
+		`DEFINE FIELD user_id ON pinned_memories TYPE string DEFAULT "";`,
+		`DEFINE FIELD ref ON pinned_memories TYPE string;`,
+		`DEFINE FIELD pinned_at ON pinned_memories TYPE datetime DEFAULT time::now();`,
+		`DEFINE INDEX idx_pinned_memories_ref ON pinned_memories FIELDS kind, user_id, ref UNIQUE;`,
+	}
+}
+
+// getMigrationV28Statements returns V28 migration statements (fact provenance)
+func (s *SurrealDBStorage) getMigrationV28Statements() []string {
+	slog.Debug("Migration V28: Adding provenance field to kv_memories")
+	return []string{
+		`DEFINE FIELD provenance ON kv_memories FLEXIBLE TYPE option<object>;`,
+	}
+}
+
+// getMigrationV29Statements returns V29 migration statements (temporal facts)
+func (s *SurrealDBStorage) getMigrationV29Statements() []string {
+	slog.Debug("Migration V29: Adding fact validity intervals and kv_memory_history table")
+	return []string{
+		`DEFINE FIELD valid_from ON kv_memories TYPE option<datetime>;`,
+		`DEFINE FIELD valid_until ON kv_memories TYPE option<datetime>;`,
+		`DEFINE TABLE kv_memory_history SCHEMAFULL;`,
+		`DEFINE FIELD user_id ON kv_memory_history TYPE string;`,
+		`DEFINE FIELD key ON kv_memory_history TYPE string;`,
+		`DEFINE FIELD value ON kv_memory_history FLEXIBLE TYPE option<string | int | float | bool | object | array>;`,
+		`DEFINE FIELD provenance ON kv_memory_history FLEXIBLE TYPE option<object>;`,
+		`DEFINE FIELD valid_from ON kv_memory_history TYPE datetime;`,
+		`DEFINE FIELD valid_until ON kv_memory_history TYPE datetime;`,
+		`DEFINE INDEX idx_kv_memory_history_key ON kv_memory_history FIELDS user_id, key, valid_from;`,
+	}
+}
+
+// getMigrationV30Statements returns V30 migration statements (entity type schemas)
+func (s *SurrealDBStorage) getMigrationV30Statements() []string {
+	slog.Debug("Migration V30: Creating entity_types table")
+	return []string{
+		`DEFINE TABLE entity_types SCHEMAFULL;`,
+		`DEFINE FIELD name ON entity_types TYPE string;`,
+		`DEFINE FIELD description ON entity_types TYPE string DEFAULT "";`,
+		`DEFINE FIELD properties_schema ON entity_types FLEXIBLE TYPE object DEFAULT {};`,
+		`DEFINE FIELD created_at ON entity_types TYPE datetime DEFAULT time::now();`,
+		`DEFINE FIELD updated_at ON entity_types TYPE datetime DEFAULT time::now();`,
+		`DEFINE INDEX idx_entity_types_name ON entity_types FIELDS name UNIQUE;`,
+	}
+}
+
+// getMigrationV31Statements returns V31 migration statements (code symbol doc search)
+func (s *SurrealDBStorage) getMigrationV31Statements() []string {
+	slog.Debug("Migration V31: Adding full-text indexes on code symbol docs")
+	return []string{
+		`DEFINE ANALYZER code_docs_analyzer TOKENIZERS blank, class, camel, punct FILTERS lowercase, snowball(english);`,
+		`DEFINE INDEX idx_code_symbols_doc_string ON code_symbols FIELDS doc_string SEARCH ANALYZER code_docs_analyzer BM25;`,
+		`DEFINE INDEX idx_code_symbols_signature ON code_symbols FIELDS signature SEARCH ANALYZER code_docs_analyzer BM25;`,
+	}
+}
+
+// getMigrationV32Statements returns V32 migration statements (code project aliases)
+func (s *SurrealDBStorage) getMigrationV32Statements() []string {
+	slog.Debug("Migration V32: Adding aliases of code projects")
+	return []string{
+		`DEFINE FIELD alias ON code_projects TYPE option<string>;`,
+		`DEFINE INDEX idx_code_projects_alias ON code_projects FIELDS alias;`,
+	}
+}
+
+// getMigrationV33Statements returns V33 migration statements (code project indexing settings)
+func (s *SurrealDBStorage) getMigrationV33Statements() []string {
+	slog.Debug("Migration V33: Adding indexing settings of code projects")
+	return []string{
+		`DEFINE FIELD embedder ON code_projects TYPE string DEFAULT '';`,
+		`DEFINE FIELD chunk_size ON code_projects TYPE int DEFAULT 0;`,
+		`DEFINE FIELD chunk_overlap ON code_projects TYPE int DEFAULT 0;`,
+		`DEFINE FIELD max_symbol_size ON code_projects TYPE int DEFAULT 0;`,
+	}
+}
+
+// getMigrationV34Statements returns V34 migration statements (stable code symbol IDs)
+func (s *SurrealDBStorage) getMigrationV34Statements() []string {
+	slog.Debug("Migration V34: Deriving code symbol IDs from their project, file and name path")
+	return []string{
+		`REMOVE INDEX idx_code_symbol_name_path ON code_symbols;`,
+		`DEFINE INDEX idx_code_symbol_name_path ON code_symbols FIELDS project_id, file_path, name_path UNIQUE;`,
+		`BEGIN TRANSACTION;
+		LET $symbols = (SELECT *,
+			type::thing("code_symbols", string::slice(crypto::sha256(project_id + ":" + file_path + ":" + name_path), 0, 32)) AS id,
+			(IF parent_id != NONE THEN "code_symbols:" + string::slice(crypto::sha256(project_id + ":" + file_path + ":" + array::join(array::slice(string::split(name_path, "/"), 0, -1), "/")), 0, 32) END) AS parent_id
+			FROM code_symbols);
+		DELETE code_symbols;
+		INSERT INTO code_symbols $symbols;
+		COMMIT TRANSACTION;`,
+	}
+}
+
+// getMigrationV35Statements returns V35 migration statements (cosine distance
+// embedding indexes). The embedded schema defined most embedding indexes with
+// the euclidean default, which ranks int8 quantized embeddings by their
+// per-vector scale instead of their direction.
+func (s *SurrealDBStorage) getMigrationV35Statements() []string {
+	slog.Debug("Migration V35: Defining embedding indexes with cosine distance")
+	return []string{
+		`REMOVE INDEX idx_vector_embedding ON vector_memories;`,
+		fmt.Sprintf(`DEFINE INDEX idx_vector_embedding ON vector_memories FIELDS embedding MTREE DIMENSION %d DIST COSINE;`, defaultMtreeDim),
+		`REMOVE INDEX idx_kb_embedding ON knowledge_base;`,
+		fmt.Sprintf(`DEFINE INDEX idx_kb_embedding ON knowledge_base FIELDS embedding MTREE DIMENSION %d DIST COSINE;`, defaultMtreeDim),
+		`REMOVE INDEX idx_code_symbol_embedding ON code_symbols;`,
+		fmt.Sprintf(`DEFINE INDEX idx_code_symbol_embedding ON code_symbols FIELDS embedding MTREE DIMENSION %d DIST COSINE;`, defaultMtreeDim),
+		`REMOVE INDEX idx_code_chunk_embedding ON code_chunks;`,
+		fmt.Sprintf(`DEFINE INDEX idx_code_chunk_embedding ON code_chunks FIELDS embedding MTREE DIMENSION %d DIST COSINE;`, defaultMtreeDim),
+		`REMOVE INDEX idx_kb_versions_embedding ON kb_document_versions;`,
+		fmt.Sprintf(`DEFINE INDEX idx_kb_versions_embedding ON kb_document_versions FIELDS embedding MTREE DIMENSION %d DIST COSINE;`, defaultMtreeDim),
+	}
+}
+
+// getMigrationV36Statements returns V36 migration statements (packed float16
+// code embeddings)
+func (s *SurrealDBStorage) getMigrationV36Statements() []string {
+	slog.Debug("Migration V36: Adding packed float16 code embeddings")
+	return []string{
+		`DEFINE FIELD embedding_f16 ON code_symbols TYPE option<bytes>;`,
+		`DEFINE FIELD embedding_f16 ON code_chunks TYPE option<bytes>;`,
+	}
+}
+
+// getMigrationV37Statements returns V37 migration statements (relationships
+// of deleted entities in the trash)
+func (s *SurrealDBStorage) getMigrationV37Statements() []string {
+	slog.Debug("Migration V37: Adding the relationships of deleted entities to the trash")
+	return []string{
+		`DEFINE FIELD relationships ON trash FLEXIBLE TYPE array<object> DEFAULT [];`,
+	}
+}
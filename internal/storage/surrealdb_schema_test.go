@@ -0,0 +1,57 @@
+package storage
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/madeindigio/remembrances-mcp/internal/storage/migrations"
+	"github.com/madeindigio/remembrances-mcp/pkg/treesitter"
+)
+
+func TestMigrationV34StableCodeSymbolIDs(t *testing.T) {
+	s := newTestSurrealDB(t)
+	ctx := context.Background()
+
+	// Symbols as stored before v34, with random IDs; both files define main
+	for _, sym := range []map[string]interface{}{
+		{"file_path": "cmd/server/main.go", "name": "main", "name_path": "/main"},
+		{"file_path": "cmd/worker/main.go", "name": "main", "name_path": "/main"},
+		{"file_path": "cmd/worker/main.go", "name": "run", "name_path": "/main/run", "parent_id": "code_symbols:old"},
+	} {
+		sym["project_id"] = "app"
+		sym["language"] = "go"
+		sym["symbol_type"] = "function"
+		sym["start_line"], sym["end_line"], sym["start_byte"], sym["end_byte"] = 1, 1, 0, 1
+		if _, err := s.query(ctx, "CREATE code_symbols CONTENT $sym", map[string]interface{}{"sym": sym}); err != nil {
+			t.Fatalf("create symbol %v: %v", sym, err)
+		}
+	}
+
+	if err := migrations.NewV34StableCodeSymbolIDs(s.db).Apply(ctx, s.db); err != nil {
+		t.Fatalf("apply v34: %v", err)
+	}
+
+	result, err := s.query(ctx, "SELECT meta::id(id) AS key, file_path, name_path, parent_id FROM code_symbols WHERE project_id = 'app'", nil)
+	if err != nil {
+		t.Fatalf("select symbols: %v", err)
+	}
+	type symbol struct{ key, parentID string }
+	got := map[string]symbol{}
+	for _, qr := range *result {
+		for _, row := range qr.Result {
+			parentID, _ := row["parent_id"].(string)
+			got[row["file_path"].(string)+row["name_path"].(string)] = symbol{row["key"].(string), parentID}
+		}
+	}
+
+	workerMain := treesitter.SymbolID("app", "cmd/worker/main.go", "/main")
+	want := map[string]symbol{
+		"cmd/server/main.go/main":     {treesitter.SymbolID("app", "cmd/server/main.go", "/main"), ""},
+		"cmd/worker/main.go/main":     {workerMain, ""},
+		"cmd/worker/main.go/main/run": {treesitter.SymbolID("app", "cmd/worker/main.go", "/main/run"), codeSymbolRecordID(workerMain)},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("symbols after v34 =\n%v\nwant\n%v", got, want)
+	}
+}
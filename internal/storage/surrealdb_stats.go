@@ -3,6 +3,7 @@ package storage
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strconv"
 )
 
@@ -312,7 +313,7 @@ func (s *SurrealDBStorage) getRelationshipTables(ctx context.Context) ([]string,
 	if queryResult.Status == "OK" && len(queryResult.Result) > 0 {
 		for _, row := range queryResult.Result {
 			if tbl, ok := row["name"].(string); ok {
-				if tbl != "entities" && tbl != "vector_memories" && tbl != "kv_memories" && tbl != "knowledge_base" && tbl != "user_stats" && tbl != "schema_version" {
+				if tbl != "entities" && tbl != "vector_memories" && tbl != "kv_memories" && tbl != "kv_memory_history" && tbl != "knowledge_base" && tbl != "user_stats" && tbl != "schema_version" && tbl != "memory_hits" && tbl != "idempotency_keys" && tbl != "saved_searches" && tbl != "saved_search_changes" && tbl != "pinned_memories" && tbl != "entity_types" {
 					tables = append(tables, tbl)
 				}
 			}
@@ -320,3 +321,112 @@ func (s *SurrealDBStorage) getRelationshipTables(ctx context.Context) ([]string,
 	}
 	return tables, nil
 }
+
+// userStatFields are the counters of a user_stats row. The last three are
+// only counted on the "global" row.
+var userStatFields = []string{"key_value_count", "vector_count", "entity_count", "relationship_count", "document_count"}
+
+// expectedUserStats counts the records each user_stats row should report:
+// facts and vectors per user, and entities, relationships and documents on
+// the global row.
+func (s *SurrealDBStorage) expectedUserStats(ctx context.Context) (map[string]map[string]int, error) {
+	expected := map[string]map[string]int{}
+	for table, stat := range map[string]string{"kv_memories": "key_value_count", "vector_memories": "vector_count"} {
+		counts, err := s.CountByUserID(ctx, table)
+		if err != nil {
+			return nil, err
+		}
+		for userID, count := range counts {
+			if expected[userID] == nil {
+				expected[userID] = map[string]int{}
+			}
+			expected[userID][stat] = count
+		}
+	}
+
+	global := map[string]int{
+		"entity_count":   s.getCount(ctx, "SELECT count() AS count FROM entities GROUP ALL", nil),
+		"document_count": s.getDistinctDocumentCount(ctx),
+	}
+	relTables, err := s.getRelationshipTables(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, tbl := range relTables {
+		global["relationship_count"] += s.getCount(ctx, "SELECT count() AS count FROM "+tbl+" GROUP ALL", nil)
+	}
+	for stat, count := range expected["global"] {
+		global[stat] = count
+	}
+	expected["global"] = global
+	return expected, nil
+}
+
+// RecountUserStats recounts the records behind the user_stats counters of
+// userID, or of every user when userID is empty, and rewrites the rows in a
+// single transaction. It returns the counters that changed.
+func (s *SurrealDBStorage) RecountUserStats(ctx context.Context, userID string) ([]UserStatChange, error) {
+	// Counting every table can take longer than a query
+	ctx = WithoutQueryTimeout(ctx)
+	expected, err := s.expectedUserStats(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count records: %w", err)
+	}
+
+	query := "SELECT * FROM user_stats"
+	var params map[string]interface{}
+	if userID != "" {
+		query += " WHERE user_id = $user_id"
+		params = map[string]interface{}{"user_id": userID}
+	}
+	rows, err := s.Query(ctx, query, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read user_stats: %w", err)
+	}
+	current := map[string]map[string]int{}
+	for _, row := range rows {
+		counters := map[string]int{}
+		for _, stat := range userStatFields {
+			counters[stat] = convertToInt(row[stat])
+		}
+		current[getString(row, "user_id")] = counters
+	}
+
+	users := map[string]bool{}
+	for uid := range current {
+		users[uid] = true
+	}
+	for uid := range expected {
+		if userID == "" || uid == userID {
+			users[uid] = true
+		}
+	}
+	if len(users) == 0 {
+		return []UserStatChange{}, nil
+	}
+	sorted := make([]string, 0, len(users))
+	for uid := range users {
+		sorted = append(sorted, uid)
+	}
+	sort.Strings(sorted)
+
+	tx := newSurrealTx()
+	changes := []UserStatChange{}
+	for _, uid := range sorted {
+		row := map[string]interface{}{"user_id": uid}
+		for _, stat := range userStatFields {
+			after := expected[uid][stat]
+			row[stat] = after
+			if before := current[uid][stat]; before != after {
+				changes = append(changes, UserStatChange{UserID: uid, Stat: stat, Before: before, After: after})
+			}
+		}
+		tx.add("DELETE user_stats WHERE user_id = $user_id", map[string]interface{}{"user_id": uid})
+		tx.add("CREATE user_stats CONTENT $row RETURN NONE", map[string]interface{}{"row": row})
+	}
+
+	if err := s.commitTx(ctx, tx); err != nil {
+		return nil, fmt.Errorf("failed to rewrite user_stats: %w", err)
+	}
+	return changes, nil
+}
@@ -0,0 +1,195 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/madeindigio/remembrances-mcp/pkg/tenancy"
+	"github.com/surrealdb/surrealdb.go"
+)
+
+// errTenantEmbedded is returned when a tenant is used with embedded SurrealDB,
+// which holds a single namespace/database per process.
+var errTenantEmbedded = errors.New("multi-tenant mode requires a remote SurrealDB server")
+
+// ConnectTenant opens a connection to the namespace and database of a tenant
+// and initializes its schema. Calls whose context carries the tenant (see
+// tenancy.WithTenant) then run against that database.
+func (s *SurrealDBStorage) ConnectTenant(ctx context.Context, tenant *tenancy.Tenant) error {
+	if s.useEmbedded {
+		return errTenantEmbedded
+	}
+
+	config := *s.config
+	config.Namespace = tenant.Namespace
+	config.Database = tenant.Database
+	config.UseEmbeddedLibs = false
+	ts := NewSurrealDBStorage(&config)
+	ts.tenantID = tenant.ID
+	if err := ts.Connect(ctx); err != nil {
+		return fmt.Errorf("tenant %q: %w", tenant.ID, err)
+	}
+	if err := ts.InitializeSchema(ctx); err != nil {
+		_ = ts.Close()
+		return fmt.Errorf("tenant %q: %w", tenant.ID, err)
+	}
+
+	s.tenantsMu.Lock()
+	defer s.tenantsMu.Unlock()
+	if s.tenants == nil {
+		s.tenants = map[string]*SurrealDBStorage{}
+	}
+	if old, ok := s.tenants[tenant.ID]; ok {
+		_ = old.Close()
+	}
+	s.tenants[tenant.ID] = ts
+	slog.Info("Connected tenant database", "tenant", tenant.ID, "namespace", tenant.Namespace, "database", tenant.Database)
+	return nil
+}
+
+// remoteDB returns the remote connection for ctx: the tenant's connection when
+// ctx carries a tenant, the default one otherwise. A tenant without a
+// connection is an error; it never falls back to the default database.
+func (s *SurrealDBStorage) remoteDB(ctx context.Context) (*surrealdb.DB, error) {
+	if err := s.waitResumed(ctx); err != nil {
+		return nil, err
+	}
+	db := s.conn()
+	if tenant, ok := tenancy.FromContext(ctx); ok && s.tenantID == "" {
+		s.tenantsMu.RLock()
+		ts, connected := s.tenants[tenant.ID]
+		s.tenantsMu.RUnlock()
+		if !connected {
+			return nil, fmt.Errorf("tenant %q is not connected", tenant.ID)
+		}
+		db = ts.conn()
+	}
+	if db == nil {
+		return nil, fmt.Errorf("remote database not initialized: %w", ErrUnavailable)
+	}
+	return db, nil
+}
+
+// checkTenantEmbedded refuses tenant calls on embedded SurrealDB, which would
+// otherwise read and write the default database.
+func checkTenantEmbedded(ctx context.Context) error {
+	if _, ok := tenancy.FromContext(ctx); ok {
+		return errTenantEmbedded
+	}
+	return nil
+}
+
+// closeTenants closes the tenant connections.
+func (s *SurrealDBStorage) closeTenants() []error {
+	s.tenantsMu.Lock()
+	defer s.tenantsMu.Unlock()
+	var errs []error
+	for id, ts := range s.tenants {
+		if err := ts.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("tenant %q: %w", id, err))
+		}
+	}
+	s.tenants = nil
+	return errs
+}
+
+// quotaLimit returns the quota of a table, zero when it is unlimited.
+func quotaLimit(q tenancy.Quotas, table string) int {
+	switch table {
+	case "kv_memories":
+		return q.MaxFacts
+	case "vector_memories":
+		return q.MaxVectors
+	case "knowledge_base":
+		return q.MaxDocumentChunks
+	case "events":
+		return q.MaxEvents
+	}
+	return 0
+}
+
+// checkQuota returns an error when storing adding more records in table would
+// take the tenant in ctx over its quota. Calls without a tenant, or without a quota, are unlimited.
+func (s *SurrealDBStorage) checkQuota(ctx context.Context, table string, adding int) error {
+	tenant, ok := tenancy.FromContext(ctx)
+	if !ok {
+		return nil
+	}
+	limit := quotaLimit(tenant.Quotas, table)
+	if limit <= 0 {
+		return nil
+	}
+	used, err := s.countTable(ctx, table)
+	if err != nil {
+		return fmt.Errorf("failed to check quota: %w", err)
+	}
+	if used+adding > limit {
+		return fmt.Errorf("tenant %q reached its quota of %d records in %s", tenant.ID, limit, table)
+	}
+	return nil
+}
+
+// checkDocumentQuota checks the chunk quota of the tenant in ctx before the
+// chunks of filePath are replaced by chunks new ones.
+func (s *SurrealDBStorage) checkDocumentQuota(ctx context.Context, filePath string, chunks int) error {
+	tenant, ok := tenancy.FromContext(ctx)
+	if !ok || tenant.Quotas.MaxDocumentChunks <= 0 {
+		return nil
+	}
+	result, err := s.query(ctx, "SELECT count() AS count FROM knowledge_base WHERE source_file = $file_path OR file_path = $file_path GROUP ALL", map[string]interface{}{"file_path": filePath})
+	if err != nil {
+		return fmt.Errorf("failed to check quota: %w", err)
+	}
+	existing := 0
+	if result != nil && len(*result) > 0 && len((*result)[0].Result) > 0 {
+		existing = convertToInt((*result)[0].Result[0]["count"])
+	}
+	return s.checkQuota(ctx, "knowledge_base", chunks-existing)
+}
+
+// countTable returns the number of records in table.
+func (s *SurrealDBStorage) countTable(ctx context.Context, table string) (int, error) {
+	result, err := s.query(ctx, "SELECT count() AS count FROM type::table($table) GROUP ALL", map[string]interface{}{"table": table})
+	if err != nil {
+		return 0, err
+	}
+	if result == nil || len(*result) == 0 || len((*result)[0].Result) == 0 {
+		return 0, nil
+	}
+	return convertToInt((*result)[0].Result[0]["count"]), nil
+}
+
+// TenantUsage returns the quotas of the tenant in ctx with the records it
+// stores per layer, or nil when ctx carries no tenant.
+func (s *SurrealDBStorage) TenantUsage(ctx context.Context) (map[string]interface{}, error) {
+	tenant, ok := tenancy.FromContext(ctx)
+	if !ok {
+		return nil, nil
+	}
+	layers := map[string]string{
+		"facts":           "kv_memories",
+		"vectors":         "vector_memories",
+		"document_chunks": "knowledge_base",
+		"events":          "events",
+	}
+	quotas := map[string]interface{}{}
+	for name, table := range layers {
+		used, err := s.countTable(ctx, table)
+		if err != nil {
+			return nil, err
+		}
+		quota := map[string]interface{}{"used": used}
+		if limit := quotaLimit(tenant.Quotas, table); limit > 0 {
+			quota["max"] = limit
+		}
+		quotas[name] = quota
+	}
+	return map[string]interface{}{
+		"id":        tenant.ID,
+		"namespace": tenant.Namespace,
+		"database":  tenant.Database,
+		"quotas":    quotas,
+	}, nil
+}
@@ -0,0 +1,50 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+)
+
+// newTestSurrealDB connects to the SurrealDB server at
+// REMEMBRANCES_TEST_SURREALDB_URL (such as ws://localhost:8000) with a
+// database of its own holding the current schema, removed when the test
+// ends. The test is skipped when the variable is not set.
+func newTestSurrealDB(t *testing.T) *SurrealDBStorage {
+	t.Helper()
+	url := os.Getenv("REMEMBRANCES_TEST_SURREALDB_URL")
+	if url == "" {
+		t.Skip("REMEMBRANCES_TEST_SURREALDB_URL not set")
+	}
+
+	user, pass := os.Getenv("REMEMBRANCES_TEST_SURREALDB_USER"), os.Getenv("REMEMBRANCES_TEST_SURREALDB_PASS")
+	if user == "" {
+		user, pass = "root", "root"
+	}
+	database := fmt.Sprintf("test_%d", time.Now().UnixNano())
+	s := NewSurrealDBStorage(&ConnectionConfig{
+		URL:       url,
+		Username:  user,
+		Password:  pass,
+		Namespace: "test",
+		Database:  database,
+		Timeout:   30 * time.Second,
+	})
+
+	ctx := context.Background()
+	if err := s.Connect(ctx); err != nil {
+		t.Fatalf("connect to %s: %v", url, err)
+	}
+	t.Cleanup(func() {
+		if _, err := s.query(ctx, "REMOVE DATABASE "+database, nil); err != nil {
+			t.Logf("remove database %s: %v", database, err)
+		}
+		s.Close()
+	})
+	if err := s.InitializeSchema(ctx); err != nil {
+		t.Fatalf("initialize schema: %v", err)
+	}
+	return s
+}
@@ -0,0 +1,289 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// maxTrashPreview bounds the characters of content returned for each item by
+// ListTrash.
+const maxTrashPreview = 200
+
+// trashTables maps each trash kind to the table its records are restored to,
+// and to the user stat recounted afterwards.
+var trashTables = map[string]struct{ table, stat string }{
+	TrashKindFact:     {"kv_memories", "key_value_count"},
+	TrashKindVector:   {"vector_memories", "vector_count"},
+	TrashKindDocument: {"knowledge_base", "document_count"},
+	TrashKindEntity:   {"entities", "entity_count"},
+}
+
+// moveToTrash copies the rows of the kind's table that match where into a new
+// trash entry and then deletes them, in one transaction. content is kept on
+// the entry so the deleted item can be previewed and, for documents,
+// rewritten on restore. An entity takes its relationships to the trash with
+// it. Pinned facts, vectors and documents are refused with ErrPinned unless
+// ctx comes from WithForce; forced deletions drop the pin.
+func (s *SurrealDBStorage) moveToTrash(ctx context.Context, kind, userID, label, content, where string, params map[string]interface{}) error {
+	target, ok := trashTables[kind]
+	if !ok {
+		return fmt.Errorf("unknown trash kind %q", kind)
+	}
+	if err := s.checkPinned(ctx, kind, userID, label); err != nil {
+		return err
+	}
+
+	var relTables []string
+	if kind == TrashKindEntity {
+		var err error
+		if relTables, err = s.entityRelationshipTables(ctx, label); err != nil {
+			return err
+		}
+	}
+
+	params["trash_kind"] = kind
+	params["trash_table"] = target.table
+	params["trash_user_id"] = userID
+	params["trash_label"] = label
+	params["trash_content"] = content
+	params["trash_entity"] = label
+
+	relationships := make([]string, len(relTables))
+	for i, table := range relTables {
+		params[fmt.Sprintf("trash_rel_table_%d", i)] = table
+		relationships[i] = fmt.Sprintf("{ table: $trash_rel_table_%d, records: (SELECT * FROM %s WHERE %s) }", i, table, entityRelationshipWhere)
+	}
+
+	tx := newSurrealTx()
+	tx.add(fmt.Sprintf(`
+		CREATE trash CONTENT {
+			kind: $trash_kind,
+			source_table: $trash_table,
+			user_id: $trash_user_id,
+			label: $trash_label,
+			content: $trash_content,
+			records: (SELECT * FROM %[1]s WHERE %[2]s),
+			relationships: [%[3]s],
+			deleted_at: time::now()
+		} RETURN NONE
+	`, target.table, where, strings.Join(relationships, ", ")), params)
+	tx.add(fmt.Sprintf("DELETE FROM %s WHERE %s RETURN NONE", target.table, where), params)
+	for _, table := range relTables {
+		tx.add(fmt.Sprintf("DELETE FROM %s WHERE %s RETURN NONE", table, entityRelationshipWhere), params)
+	}
+	if pinnable(kind) {
+		tx.add("DELETE FROM pinned_memories WHERE "+surrealPinWhere+" RETURN NONE", pinParams(kind, userID, label))
+	}
+	if err := s.commitTx(ctx, tx); err != nil {
+		return fmt.Errorf("failed to move %s to trash: %w", kind, err)
+	}
+
+	if err := s.updateUserStat(ctx, statUserID(kind, userID), target.stat, 0); err != nil {
+		slog.Warn("failed to update stat after delete", "stat", target.stat, "user_id", userID, "error", err)
+	}
+	if len(relTables) > 0 {
+		if err := s.updateUserStat(ctx, "global", "relationship_count", 0); err != nil {
+			slog.Warn("failed to update stat after delete", "stat", "relationship_count", "error", err)
+		}
+	}
+	return nil
+}
+
+// entityRelationshipWhere matches the relationships from or to $trash_entity,
+// whether they reference it by record ID or by its string form.
+const entityRelationshipWhere = "from_entity != NONE AND (<string>from_entity = $trash_entity OR <string>to_entity = $trash_entity)"
+
+// entityRelationshipTables returns the relationship tables holding
+// relationships from or to entityID.
+func (s *SurrealDBStorage) entityRelationshipTables(ctx context.Context, entityID string) ([]string, error) {
+	tables, err := s.getRelationshipTables(ctx)
+	if err != nil {
+		return nil, err
+	}
+	params := map[string]interface{}{"trash_entity": entityID}
+	var found []string
+	for _, table := range tables {
+		if s.getCount(ctx, fmt.Sprintf("SELECT count() AS count FROM %s WHERE %s GROUP ALL", table, entityRelationshipWhere), params) > 0 {
+			found = append(found, table)
+		}
+	}
+	return found, nil
+}
+
+// ListTrash lists soft-deleted items, newest first. Empty userID or kind match all.
+func (s *SurrealDBStorage) ListTrash(ctx context.Context, userID, kind string, limit int) ([]TrashItem, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+
+	query := `
+		SELECT id, kind, user_id, label, content, array::len(records) AS record_count, deleted_at
+		FROM trash
+		WHERE ($user_id = "" OR user_id = $user_id) AND ($kind = "" OR kind = $kind)
+		ORDER BY deleted_at DESC
+		LIMIT $limit
+	`
+	result, err := s.query(ctx, query, map[string]interface{}{
+		"user_id": userID,
+		"kind":    kind,
+		"limit":   limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list trash: %w", err)
+	}
+
+	items := parseTrashItems(result)
+	for i := range items {
+		items[i].Content = trashPreview(items[i].Content)
+	}
+	return items, nil
+}
+
+// RestoreFromTrash recreates the records of a trash entry with their original ids
+// and removes the entry. It fails when a live record would conflict with them.
+func (s *SurrealDBStorage) RestoreFromTrash(ctx context.Context, trashID string) (*TrashItem, error) {
+	_, key, err := splitRecordID(trashID)
+	if err != nil {
+		return nil, err
+	}
+	params := map[string]interface{}{"key": key}
+
+	query := `SELECT id, kind, user_id, label, content, array::len(records) AS record_count, relationships.table AS relationship_tables, deleted_at FROM type::thing("trash", $key)`
+	result, err := s.query(ctx, query, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read trash entry: %w", err)
+	}
+	items := parseTrashItems(result)
+	if len(items) == 0 {
+		return nil, nil
+	}
+	item := items[0]
+	relTables, _ := (*result)[0].Result[0]["relationship_tables"].([]interface{})
+	for _, table := range relTables {
+		if name, ok := table.(string); !ok || !relationshipTableRe.MatchString(name) {
+			return nil, fmt.Errorf("trash entry %s has an invalid relationship table %v", trashID, table)
+		}
+	}
+
+	target, ok := trashTables[item.Kind]
+	if !ok {
+		return nil, fmt.Errorf("unknown trash kind %q", item.Kind)
+	}
+	if err := s.checkRestoreConflict(ctx, item); err != nil {
+		return nil, err
+	}
+
+	tx := newSurrealTx()
+	tx.add(fmt.Sprintf(`INSERT INTO %s (SELECT VALUE records FROM ONLY type::thing("trash", $key)) RETURN NONE`, target.table), params)
+	for i, table := range relTables {
+		tx.add(fmt.Sprintf(`INSERT INTO %s (SELECT VALUE relationships[%d].records FROM ONLY type::thing("trash", $key)) RETURN NONE`, table.(string), i), params)
+	}
+	tx.add(`DELETE type::thing("trash", $key) RETURN NONE`, params)
+	if err := s.commitTx(ctx, tx); err != nil {
+		return nil, fmt.Errorf("failed to restore %s: %w", item.Kind, err)
+	}
+
+	if err := s.updateUserStat(ctx, statUserID(item.Kind, item.UserID), target.stat, 0); err != nil {
+		slog.Warn("failed to update stat after restore", "stat", target.stat, "user_id", item.UserID, "error", err)
+	}
+	if len(relTables) > 0 {
+		if err := s.updateUserStat(ctx, "global", "relationship_count", 0); err != nil {
+			slog.Warn("failed to update stat after restore", "stat", "relationship_count", "error", err)
+		}
+	}
+	return &item, nil
+}
+
+// checkRestoreConflict rejects restoring a fact or document whose key or path
+// has been reused since it was deleted.
+func (s *SurrealDBStorage) checkRestoreConflict(ctx context.Context, item TrashItem) error {
+	switch item.Kind {
+	case TrashKindFact:
+		existing, err := s.GetFact(ctx, item.UserID, item.Label)
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			return fmt.Errorf("cannot restore fact %q: a fact with the same key exists", item.Label)
+		}
+	case TrashKindDocument:
+		existing, err := s.GetDocument(ctx, item.Label)
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			return fmt.Errorf("cannot restore document %q: a document with the same path exists", item.Label)
+		}
+	}
+	return nil
+}
+
+// PurgeTrash permanently deletes trash entries deleted before the given time
+// and returns how many were removed.
+func (s *SurrealDBStorage) PurgeTrash(ctx context.Context, before time.Time) (int, error) {
+	params := map[string]interface{}{
+		"before": before.UTC().Format(time.RFC3339),
+	}
+	count := s.getCount(ctx, "SELECT count() AS count FROM trash WHERE deleted_at < <datetime>$before GROUP ALL", params)
+	if count == 0 {
+		return 0, nil
+	}
+	if _, err := s.query(ctx, "DELETE FROM trash WHERE deleted_at < <datetime>$before", params); err != nil {
+		return 0, fmt.Errorf("failed to purge trash: %w", err)
+	}
+	return count, nil
+}
+
+func parseTrashItems(result *[]QueryResult) []TrashItem {
+	var items []TrashItem
+	if result == nil || len(*result) == 0 || (*result)[0].Status != "OK" {
+		return items
+	}
+	for _, row := range (*result)[0].Result {
+		items = append(items, TrashItem{
+			ID:          extractRecordID(row["id"]),
+			Kind:        getString(row, "kind"),
+			UserID:      getString(row, "user_id"),
+			Label:       getString(row, "label"),
+			Content:     getString(row, "content"),
+			RecordCount: convertToInt(row["record_count"]),
+			DeletedAt:   getTime(row, "deleted_at"),
+		})
+	}
+	return items
+}
+
+// statUserID returns the user_stats row a kind is counted under; documents and
+// entities are tracked globally.
+func statUserID(kind, userID string) string {
+	if kind == TrashKindDocument || kind == TrashKindEntity {
+		return "global"
+	}
+	return userID
+}
+
+// trashContent renders a fact value as text for the trash preview.
+func trashContent(value interface{}) string {
+	if str, ok := value.(string); ok {
+		return str
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(data)
+}
+
+// trashPreview shortens the content of a trash entry to maxTrashPreview
+// characters, cutting between runes.
+func trashPreview(content string) string {
+	if utf8.RuneCountInString(content) <= maxTrashPreview {
+		return content
+	}
+	return string([]rune(content)[:maxTrashPreview]) + "..."
+}
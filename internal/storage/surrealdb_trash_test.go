@@ -0,0 +1,91 @@
+package storage
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTrashPreview(t *testing.T) {
+	if got := trashPreview("short"); got != "short" {
+		t.Errorf("trashPreview(short) = %q", got)
+	}
+
+	long := strings.Repeat("é", maxTrashPreview+1)
+	got := trashPreview(long)
+	if !utf8.ValidString(got) || got != strings.Repeat("é", maxTrashPreview)+"..." {
+		t.Errorf("trashPreview cut %d two-byte runes to %q, want %d runes and an ellipsis", maxTrashPreview+1, got, maxTrashPreview)
+	}
+}
+
+func TestTrashRestoreRoundTrip(t *testing.T) {
+	s := newTestSurrealDB(t)
+	ctx := context.Background()
+
+	if err := s.SaveFact(ctx, "alice", "editor", "vim"); err != nil {
+		t.Fatalf("save fact: %v", err)
+	}
+	for _, name := range []string{"Ada", "Charles"} {
+		if err := s.CreateEntity(ctx, "person", name, nil); err != nil {
+			t.Fatalf("create entity %s: %v", name, err)
+		}
+	}
+	if err := s.CreateRelationship(ctx, "Ada", "Charles", "knows", nil); err != nil {
+		t.Fatalf("create relationship: %v", err)
+	}
+	ada, err := s.resolveEntityID(ctx, "Ada")
+	if err != nil {
+		t.Fatalf("resolve Ada: %v", err)
+	}
+	relationships := func() int {
+		t.Helper()
+		rels, _, err := s.ListRelationships(ctx, RelationshipFilter{Type: "knows"})
+		if err != nil {
+			t.Fatalf("list relationships: %v", err)
+		}
+		return len(rels)
+	}
+
+	if err := s.DeleteFact(ctx, "alice", "editor"); err != nil {
+		t.Fatalf("delete fact: %v", err)
+	}
+	if err := s.DeleteEntity(ctx, ada); err != nil {
+		t.Fatalf("delete entity: %v", err)
+	}
+	if value, _ := s.GetFact(ctx, "alice", "editor"); value != nil {
+		t.Errorf("deleted fact = %v, want none", value)
+	}
+	if entity, _ := s.GetEntity(ctx, ada); entity != nil {
+		t.Errorf("deleted entity = %+v, want none", entity)
+	}
+	if n := relationships(); n != 0 {
+		t.Errorf("%d relationships left after deleting Ada, want 0", n)
+	}
+
+	items, err := s.ListTrash(ctx, "", "", 0)
+	if err != nil {
+		t.Fatalf("list trash: %v", err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("trash = %+v, want the fact and the entity", items)
+	}
+	for _, item := range items {
+		if _, err := s.RestoreFromTrash(ctx, item.ID); err != nil {
+			t.Fatalf("restore %s: %v", item.ID, err)
+		}
+	}
+
+	if value, err := s.GetFact(ctx, "alice", "editor"); err != nil || value != "vim" {
+		t.Errorf("restored fact = %v, %v; want vim", value, err)
+	}
+	if entity, err := s.GetEntity(ctx, ada); err != nil || entity == nil || entity.Name != "Ada" {
+		t.Errorf("restored entity = %+v, %v; want Ada", entity, err)
+	}
+	if n := relationships(); n != 1 {
+		t.Errorf("%d relationships after restoring Ada, want 1", n)
+	}
+	if items, _ := s.ListTrash(ctx, "", "", 0); len(items) != 0 {
+		t.Errorf("trash after restoring = %+v, want empty", items)
+	}
+}
@@ -0,0 +1,96 @@
+package storage
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// txParamRe matches the $name parameters of a SurrealQL statement.
+var txParamRe = regexp.MustCompile(`\$([A-Za-z_][A-Za-z0-9_]*)`)
+
+// surrealTx collects the statements of a multi-step write so they run as one
+// SurrealDB transaction: a crash or error part way leaves the previous data
+// untouched instead of half replaced.
+type surrealTx struct {
+	b      strings.Builder
+	params map[string]interface{}
+	n      int
+}
+
+func newSurrealTx() *surrealTx {
+	return &surrealTx{params: map[string]interface{}{}}
+}
+
+// add appends stmt. Its parameters are renamed with the statement index so
+// statements reusing a name do not overwrite each other's values; names not
+// in params (such as variables set with LET) are left alone. Only the
+// parameters stmt refers to are bound, so statements can share one map.
+func (t *surrealTx) add(stmt string, params map[string]interface{}) {
+	i := t.n
+	t.n++
+	stmt = txParamRe.ReplaceAllStringFunc(stmt, func(m string) string {
+		value, ok := params[m[1:]]
+		if !ok {
+			return m
+		}
+		renamed := fmt.Sprintf("%s_%d", m[1:], i)
+		t.params[renamed] = value
+		return "$" + renamed
+	})
+	t.b.WriteString(strings.TrimSpace(stmt))
+	if !strings.HasSuffix(t.b.String(), ";") {
+		t.b.WriteString(";")
+	}
+	t.b.WriteString("\n")
+}
+
+// newRecordKey returns a random record key, for records a transaction creates
+// and the caller refers to once it commits.
+func newRecordKey() string {
+	b := make([]byte, 10)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// script returns the statements wrapped in BEGIN/COMMIT.
+func (t *surrealTx) script() string {
+	return "BEGIN TRANSACTION;\n" + t.b.String() + "COMMIT TRANSACTION;"
+}
+
+// commitTx runs tx, retrying on read/write conflicts. Nothing is written
+// unless every statement succeeds.
+func (s *SurrealDBStorage) commitTx(ctx context.Context, tx *surrealTx) error {
+	_, err := s.runTx(ctx, tx)
+	return err
+}
+
+// runTx runs tx like commitTx and returns the results of its statements, for
+// transactions ending with a statement that reports what they wrote.
+func (s *SurrealDBStorage) runTx(ctx context.Context, tx *surrealTx) (*[]QueryResult, error) {
+	if tx.n == 0 {
+		return nil, nil
+	}
+	var result *[]QueryResult
+	err := s.withTxnRetry(ctx, func(ctx context.Context) error {
+		var err error
+		if result, err = s.query(ctx, tx.script(), tx.params); err != nil {
+			return err
+		}
+		if result != nil {
+			for _, qr := range *result {
+				if qr.Status != "" && qr.Status != "OK" {
+					return fmt.Errorf("transaction status %s", qr.Status)
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,27 @@
+package storage
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSurrealTxScript(t *testing.T) {
+	tx := newSurrealTx()
+	tx.add("DELETE FROM knowledge_base WHERE file_path = $file_path", map[string]interface{}{"file_path": "a.md", "unused": 1})
+	tx.add("LET $n = 1;", nil)
+	tx.add("CREATE knowledge_base CONTENT { file_path: $file_path, chunk_index: $n }", map[string]interface{}{"file_path": "a.md#chunk0"})
+
+	want := strings.Join([]string{
+		"BEGIN TRANSACTION;",
+		"DELETE FROM knowledge_base WHERE file_path = $file_path_0;",
+		"LET $n = 1;",
+		"CREATE knowledge_base CONTENT { file_path: $file_path_2, chunk_index: $n };",
+		"COMMIT TRANSACTION;",
+	}, "\n")
+	if got := tx.script(); got != want {
+		t.Errorf("script =\n%s\nwant\n%s", got, want)
+	}
+	if len(tx.params) != 2 || tx.params["file_path_0"] != "a.md" || tx.params["file_path_2"] != "a.md#chunk0" {
+		t.Errorf("params = %v", tx.params)
+	}
+}
@@ -0,0 +1,309 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+)
+
+// userTables are the tables whose records are owned by a user_id, in the order
+// they are deleted or renamed. user_stats goes last so counts stay readable on failure.
+var userTables = []string{
+	"kv_memories",
+	"kv_memory_history",
+	"vector_memories",
+	"archived_vector_memories",
+	"events",
+	"entities",
+	"trash",
+	"memory_hits",
+	"saved_searches",
+	"saved_search_changes",
+	"pinned_memories",
+	"user_stats",
+}
+
+// userScope selects the records of one table that belong to the user bound to $user_id.
+type userScope struct {
+	table string
+	where string
+}
+
+// ListUsers returns every user_id that owns facts, vectors, events or entities,
+// with per-layer record counts, sorted by user_id
+func (s *SurrealDBStorage) ListUsers(ctx context.Context) ([]UserSummary, error) {
+	summaries := map[string]*UserSummary{}
+	layers := []struct {
+		table string
+		set   func(*UserSummary, int)
+	}{
+		{"kv_memories", func(u *UserSummary, n int) { u.FactCount = n }},
+		{"vector_memories", func(u *UserSummary, n int) { u.VectorCount = n }},
+		{"events", func(u *UserSummary, n int) { u.EventCount = n }},
+		{"entities", func(u *UserSummary, n int) { u.EntityCount = n }},
+	}
+
+	for _, layer := range layers {
+		counts, err := s.CountByUserID(ctx, layer.table)
+		if err != nil {
+			return nil, err
+		}
+		for userID, count := range counts {
+			summary, ok := summaries[userID]
+			if !ok {
+				summary = &UserSummary{UserID: userID}
+				summaries[userID] = summary
+			}
+			layer.set(summary, count)
+		}
+	}
+
+	users := make([]UserSummary, 0, len(summaries))
+	for _, summary := range summaries {
+		users = append(users, *summary)
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].UserID < users[j].UserID })
+	return users, nil
+}
+
+// DeleteUser permanently removes every record owned by userID, including its
+// trash, stats and the relationships attached to its entities. It returns the
+// number of records deleted per table.
+func (s *SurrealDBStorage) DeleteUser(ctx context.Context, userID string) (map[string]int, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("user_id is required")
+	}
+	scopes, err := s.userScopes(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+
+	params := map[string]interface{}{"user_id": userID}
+	deleted := s.countUserRecords(ctx, scopes, params)
+	for _, scope := range scopes {
+		if deleted[scope.table] == 0 {
+			continue
+		}
+		query := fmt.Sprintf("DELETE FROM %s WHERE %s RETURN NONE", scope.table, scope.where)
+		if _, err := s.query(ctx, query, params); err != nil {
+			return deleted, fmt.Errorf("failed to delete user records from %s: %w", scope.table, err)
+		}
+	}
+
+	s.updateStatsAfterUserDelete(ctx, scopes, deleted)
+	return deleted, nil
+}
+
+// ExportUser returns every record owned by userID, including knowledge base
+// chunks it contributed and the relationships attached to its entities
+func (s *SurrealDBStorage) ExportUser(ctx context.Context, userID string) (*UserExport, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("user_id is required")
+	}
+	scopes, err := s.userScopes(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+
+	export := &UserExport{
+		UserID:     userID,
+		ExportedAt: time.Now().UTC(),
+		Tables:     map[string][]map[string]interface{}{},
+	}
+	params := map[string]interface{}{"user_id": userID}
+	for _, scope := range scopes {
+		query := fmt.Sprintf("SELECT * OMIT embedding FROM %s WHERE %s", scope.table, scope.where)
+		result, err := s.query(ctx, query, params)
+		if err != nil {
+			return nil, fmt.Errorf("failed to export %s: %w", scope.table, err)
+		}
+		if result == nil || len(*result) == 0 || (*result)[0].Status != "OK" || len((*result)[0].Result) == 0 {
+			continue
+		}
+
+		rows := (*result)[0].Result
+		for _, row := range rows {
+			row["id"] = extractRecordID(row["id"])
+		}
+		if normalized, ok := normalizeSurrealDBDatetimes(rows).([]map[string]interface{}); ok {
+			rows = normalized
+		}
+		export.Tables[scope.table] = rows
+	}
+
+	return export, nil
+}
+
+// PurgeUser deletes every record owned by userID, including knowledge base
+// chunks it contributed, in a single transaction that also records the purge
+// in the audit log together with archivePath. It returns the number of records
+// deleted per table.
+func (s *SurrealDBStorage) PurgeUser(ctx context.Context, userID, archivePath string) (map[string]int, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("user_id is required")
+	}
+	// Counting and deleting the records of a large user can take longer than
+	// a query
+	ctx = WithoutQueryTimeout(ctx)
+	scopes, err := s.userScopes(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+
+	params := map[string]interface{}{"user_id": userID}
+	deleted := s.countUserRecords(ctx, scopes, params)
+
+	tx := newSurrealTx()
+	for _, scope := range scopes {
+		tx.add(fmt.Sprintf("DELETE FROM %s WHERE %s RETURN NONE", scope.table, scope.where), params)
+	}
+	tx.add(`CREATE audit_log CONTENT { action: "purge_user", subject: $user_id, details: { archive: $archive, deleted: $deleted } } RETURN NONE`, map[string]interface{}{
+		"user_id": userID,
+		"archive": archivePath,
+		"deleted": deleted,
+	})
+	if err := s.commitTx(ctx, tx); err != nil {
+		return nil, fmt.Errorf("failed to purge user %s: %w", userID, err)
+	}
+
+	s.updateStatsAfterUserDelete(ctx, scopes, deleted)
+	slog.Info("purged user data", "user_id", userID, "archive", archivePath)
+	return deleted, nil
+}
+
+func (s *SurrealDBStorage) countUserRecords(ctx context.Context, scopes []userScope, params map[string]interface{}) map[string]int {
+	counts := map[string]int{}
+	for _, scope := range scopes {
+		query := fmt.Sprintf("SELECT count() AS count FROM %s WHERE %s GROUP ALL", scope.table, scope.where)
+		if count := s.getCount(ctx, query, params); count > 0 {
+			counts[scope.table] = count
+		}
+	}
+	return counts
+}
+
+// updateStatsAfterUserDelete recounts the global stats for entities,
+// relationships and documents, which are not tracked per user.
+func (s *SurrealDBStorage) updateStatsAfterUserDelete(ctx context.Context, scopes []userScope, deleted map[string]int) {
+	recount := map[string]bool{}
+	for _, scope := range scopes {
+		if deleted[scope.table] == 0 {
+			continue
+		}
+		switch scope.table {
+		case "entities":
+			recount["entity_count"] = true
+		case "knowledge_base":
+			recount["document_count"] = true
+		case "kv_memories", "kv_memory_history", "vector_memories", "archived_vector_memories", "events", "trash", "user_stats":
+		default:
+			recount["relationship_count"] = true
+		}
+	}
+	for stat := range recount {
+		if err := s.updateUserStat(ctx, "global", stat, 0); err != nil {
+			slog.Warn("failed to update stat", "stat", stat, "error", err)
+		}
+	}
+}
+
+// RenameUser moves every record owned by oldUserID to newUserID and returns the
+// number of records updated per table. The rename is refused when newUserID
+// already owns records, so that facts with the same key are never merged silently.
+func (s *SurrealDBStorage) RenameUser(ctx context.Context, oldUserID, newUserID string) (map[string]int, error) {
+	if oldUserID == "" || newUserID == "" {
+		return nil, fmt.Errorf("both the current and the new user_id are required")
+	}
+	if oldUserID == newUserID {
+		return nil, fmt.Errorf("the new user_id is the same as the current one")
+	}
+
+	tables := append([]string{}, userTables...)
+	relTables, err := s.userRelationshipTables(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tables = append(tables, relTables...)
+
+	for _, table := range tables {
+		if table == "user_stats" {
+			continue
+		}
+		query := fmt.Sprintf("SELECT count() AS count FROM %s WHERE user_id = $user_id GROUP ALL", table)
+		if s.getCount(ctx, query, map[string]interface{}{"user_id": newUserID}) > 0 {
+			return nil, fmt.Errorf("user_id %q already has records in %s; delete it first or choose another name", newUserID, table)
+		}
+	}
+
+	params := map[string]interface{}{
+		"old_user_id": oldUserID,
+		"new_user_id": newUserID,
+	}
+	renamed := map[string]int{}
+	for _, table := range tables {
+		count := s.getCount(ctx, fmt.Sprintf("SELECT count() AS count FROM %s WHERE user_id = $old_user_id GROUP ALL", table), params)
+		if count == 0 {
+			continue
+		}
+		if table == "user_stats" {
+			// Stale stats for the new id would otherwise shadow the renamed row
+			if _, err := s.query(ctx, "DELETE FROM user_stats WHERE user_id = $new_user_id RETURN NONE", params); err != nil {
+				return renamed, fmt.Errorf("failed to clear stats for %s: %w", newUserID, err)
+			}
+		}
+		query := fmt.Sprintf("UPDATE %s SET user_id = $new_user_id WHERE user_id = $old_user_id RETURN NONE", table)
+		if _, err := s.query(ctx, query, params); err != nil {
+			return renamed, fmt.Errorf("failed to rename user in %s: %w", table, err)
+		}
+		renamed[table] = count
+	}
+
+	return renamed, nil
+}
+
+// userScopes lists the user's records table by table: relationships first,
+// while the user's entity ids can still be looked up, then userTables.
+// withDocuments adds the knowledge base chunks the user contributed.
+func (s *SurrealDBStorage) userScopes(ctx context.Context, withDocuments bool) ([]userScope, error) {
+	relTables, err := s.userRelationshipTables(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	entityIDs := "(SELECT VALUE <string>id FROM entities WHERE user_id = $user_id)"
+	relWhere := fmt.Sprintf("user_id = $user_id OR <string>from_entity IN %[1]s OR <string>to_entity IN %[1]s", entityIDs)
+
+	var scopes []userScope
+	for _, table := range relTables {
+		scopes = append(scopes, userScope{table, relWhere})
+	}
+	if withDocuments {
+		scopes = append(scopes, userScope{"knowledge_base", "user_id = $user_id"})
+	}
+	for _, table := range userTables {
+		scopes = append(scopes, userScope{table, "user_id = $user_id"})
+	}
+	return scopes, nil
+}
+
+// userRelationshipTables returns the relationship tables, excluding the tables
+// already handled through userTables and the audit log.
+func (s *SurrealDBStorage) userRelationshipTables(ctx context.Context) ([]string, error) {
+	all, err := s.getRelationshipTables(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list relationship tables: %w", err)
+	}
+	skip := map[string]bool{"audit_log": true}
+	for _, table := range userTables {
+		skip[table] = true
+	}
+	var tables []string
+	for _, table := range all {
+		if !skip[table] {
+			tables = append(tables, table)
+		}
+	}
+	return tables, nil
+}
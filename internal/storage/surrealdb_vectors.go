@@ -4,35 +4,38 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"strings"
 )
 
 // IndexVector stores a vector embedding with content and metadata
 func (s *SurrealDBStorage) IndexVector(ctx context.Context, userID, content string, embedding []float32, metadata map[string]interface{}) error {
+	if err := s.checkQuota(ctx, "vector_memories", 1); err != nil {
+		return err
+	}
+
 	// SurrealDB schema defines `metadata` as an object. Ensure we never send NULL.
 	if metadata == nil {
 		metadata = map[string]interface{}{}
 	}
+	metadata = withProvenance(ctx, metadata)
 
 	// Normalize embedding length to the MTREE dimension (pad with zeros or truncate)
-	if embedding == nil {
-		embedding = make([]float32, defaultMtreeDim)
-	} else if len(embedding) != defaultMtreeDim {
-		norm := make([]float32, defaultMtreeDim)
-		copy(norm, embedding)
-		embedding = norm
+	dim := len(embedding)
+	embedding, err := s.fitEmbedding("vector_memories", embedding)
+	if err != nil {
+		return err
 	}
 
-	// Convert embedding to []float64 as other methods do (SurrealDB JSON numeric consistency)
-	emb64 := make([]float64, len(embedding))
-	for i, v := range embedding {
-		emb64[i] = float64(v)
-	}
+	// Convert embedding to the configured storage format (float32 or int8)
+	storedEmb := s.storedEmbedding(embedding)
 
 	// Si userID es vacío, no incluir el campo en el insert
 	query := `
 	       INSERT INTO vector_memories {
 		       content: $content,
 		       embedding: $embedding,
+		       embedding_model: $embedding_model,
+		       embedding_dim: $embedding_dim,
 		       metadata: $metadata,
 		       created_at: time::now(),
 		       updated_at: time::now()` + func() string {
@@ -45,12 +48,13 @@ func (s *SurrealDBStorage) IndexVector(ctx context.Context, userID, content stri
        `
 	params := map[string]interface{}{
 		"content":   content,
-		"embedding": emb64,
+		"embedding": storedEmb,
 		"metadata":  metadata,
 	}
 	if userID != "" {
 		params["user_id"] = userID
 	}
+	s.bindEmbeddingModel(ctx, params, "vector_memories", dim)
 
 	result, err := s.query(ctx, query, params)
 	if err != nil {
@@ -75,17 +79,32 @@ func (s *SurrealDBStorage) IndexVector(ctx context.Context, userID, content stri
 
 // SearchSimilar performs vector similarity search
 func (s *SurrealDBStorage) SearchSimilar(ctx context.Context, userID string, queryEmbedding []float32, limit int) ([]VectorResult, error) {
-	query := fmt.Sprintf(`
-		SELECT id, content, vector::similarity::cosine(embedding, $query_embedding) AS similarity, metadata, created_at, updated_at
-		FROM vector_memories
-		WHERE user_id = $user_id AND embedding <|%d|> $query_embedding
-		ORDER BY similarity DESC
-	`, limit)
+	return s.SearchSimilarWithOptions(ctx, userID, queryEmbedding, VectorSearchOptions{Limit: limit})
+}
+
+// SearchSimilarWithOptions performs vector similarity search with KNN tuning and a similarity threshold
+func (s *SurrealDBStorage) SearchSimilarWithOptions(ctx context.Context, userID string, queryEmbedding []float32, opts VectorSearchOptions) ([]VectorResult, error) {
+	knn, err := knnOperator(opts)
+	if err != nil {
+		return nil, err
+	}
 
 	params := map[string]interface{}{
 		"user_id":         userID,
 		"query_embedding": queryEmbedding,
 	}
+	query := fmt.Sprintf(`
+		SELECT id, content, vector::similarity::cosine(embedding, $query_embedding) AS similarity, metadata, (revision OR 1) AS revision, created_at, updated_at
+		FROM vector_memories
+		WHERE user_id = $user_id AND embedding %s $query_embedding%s%s%s
+		ORDER BY similarity DESC
+	`, knn, minSimilarityClause(opts), minConfidenceClause(opts), s.embeddingModelClause(ctx, "vector_memories", params))
+	if opts.MinSimilarity > 0 {
+		params["min_similarity"] = opts.MinSimilarity
+	}
+	if opts.MinConfidence > 0 {
+		params["min_confidence"] = opts.MinConfidence
+	}
 
 	result, err := s.query(ctx, query, params)
 	if err != nil {
@@ -95,75 +114,96 @@ func (s *SurrealDBStorage) SearchSimilar(ctx context.Context, userID string, que
 	return s.parseVectorResults(result)
 }
 
-// UpdateVector updates an existing vector memory
-func (s *SurrealDBStorage) UpdateVector(ctx context.Context, id, userID, content string, embedding []float32, metadata map[string]interface{}) error {
-	if metadata == nil {
-		metadata = map[string]interface{}{}
-	}
-
-	if embedding == nil {
-		embedding = make([]float32, defaultMtreeDim)
-	} else if len(embedding) != defaultMtreeDim {
-		norm := make([]float32, defaultMtreeDim)
-		copy(norm, embedding)
-		embedding = norm
+// UpdateVector updates an existing vector memory and returns its new revision.
+// When expectedRevision > 0 the update is applied only if the stored revision
+// still matches; otherwise a *RevisionConflictError is returned.
+func (s *SurrealDBStorage) UpdateVector(ctx context.Context, id, userID, content string, embedding []float32, metadata map[string]interface{}, expectedRevision int) (int, error) {
+	table, key, err := splitRecordID(id)
+	if err != nil {
+		return 0, err
 	}
 
-	emb64 := make([]float64, len(embedding))
-	for i, v := range embedding {
-		emb64[i] = float64(v)
+	if metadata == nil {
+		metadata = map[string]interface{}{}
 	}
+	metadata = withProvenance(ctx, metadata)
 
-	// Use DELETE FROM WHERE + CREATE to avoid deserialization issues with newlines
-	// First delete the existing record
-	deleteQuery := `DELETE FROM semantic_memories WHERE id = $id`
-	deleteParams := map[string]interface{}{"id": id}
-	if _, err := s.query(ctx, deleteQuery, deleteParams); err != nil {
-		return fmt.Errorf("failed to delete existing vector: %w", err)
+	dim := len(embedding)
+	embedding, err = s.fitEmbedding("vector_memories", embedding)
+	if err != nil {
+		return 0, err
 	}
 
-	// Recreate with updated values
-	createQuery := `
-		CREATE semantic_memories CONTENT {
-			id: $id,
-			user_id: $user_id,
-			content: $content,
-			embedding: $embedding,
-			metadata: $metadata
-		}
+	// Records written before revisions existed count as revision 1.
+	// RETURN only the revision to avoid deserialization issues with newlines.
+	query := `
+		UPDATE type::thing($rec_table, $rec_key) SET
+			content = $content,
+			embedding = $embedding,
+			embedding_model = $embedding_model,
+			embedding_dim = $embedding_dim,
+			metadata = $metadata,
+			revision = (revision OR 1) + 1,
+			updated_at = time::now()
+		WHERE ($user_id = "" OR user_id = $user_id)
+			AND ($expected_revision = 0 OR (revision OR 1) = $expected_revision)
+		RETURN revision
 	`
 	params := map[string]interface{}{
-		"id":        id,
-		"user_id":   userID,
-		"content":   content,
-		"embedding": emb64,
-		"metadata":  metadata,
+		"rec_table":         table,
+		"rec_key":           key,
+		"user_id":           userID,
+		"content":           content,
+		"embedding":         s.storedEmbedding(embedding),
+		"metadata":          metadata,
+		"expected_revision": expectedRevision,
 	}
+	s.bindEmbeddingModel(ctx, params, "vector_memories", dim)
 
-	if _, err := s.query(ctx, createQuery, params); err != nil {
-		return fmt.Errorf("failed to recreate vector: %w", err)
+	result, err := s.query(ctx, query, params)
+	if err != nil {
+		return 0, fmt.Errorf("failed to update vector: %w", err)
+	}
+	if result != nil && len(*result) > 0 && len((*result)[0].Result) > 0 {
+		return convertToInt((*result)[0].Result[0]["revision"]), nil
 	}
 
-	return nil
+	// Nothing was updated: either the record is gone or its revision moved on
+	current, err := s.query(ctx, `SELECT (revision OR 1) AS revision FROM type::thing($rec_table, $rec_key) WHERE $user_id = "" OR user_id = $user_id`, params)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read vector revision: %w", err)
+	}
+	if current == nil || len(*current) == 0 || len((*current)[0].Result) == 0 {
+		return 0, fmt.Errorf("vector %s %w", id, ErrNotFound)
+	}
+	return 0, &RevisionConflictError{
+		ID:       id,
+		Expected: expectedRevision,
+		Current:  convertToInt((*current)[0].Result[0]["revision"]),
+	}
 }
 
-// DeleteVector deletes a vector memory
+// DeleteVector moves a vector memory to the trash
 func (s *SurrealDBStorage) DeleteVector(ctx context.Context, id, userID string) error {
-	// Use DELETE FROM WHERE to avoid deserialization issues with newlines
-	deleteQuery := `DELETE FROM semantic_memories WHERE id = $id`
-	params := map[string]interface{}{"id": id}
-	_, err := s.query(ctx, deleteQuery, params)
+	table, key, err := splitRecordID(id)
 	if err != nil {
-		return fmt.Errorf("failed to delete vector: %w", err)
+		return err
+	}
+	params := map[string]interface{}{
+		"rec_table": table,
+		"rec_key":   key,
 	}
 
-	// Update user statistics
-	if err := s.updateUserStat(ctx, userID, "vector_count", 0); err != nil {
-		// Log the error but don't fail the operation
-		slog.Warn("failed to update vector_count stat", "user_id", userID, "error", err)
+	result, err := s.query(ctx, "SELECT content FROM type::thing($rec_table, $rec_key)", params)
+	if err != nil {
+		return fmt.Errorf("failed to delete vector: %w", err)
+	}
+	if result == nil || len(*result) == 0 || len((*result)[0].Result) == 0 {
+		return nil
 	}
+	content := getString((*result)[0].Result[0], "content")
 
-	return nil
+	return s.moveToTrash(ctx, TrashKindVector, userID, id, content, "id = type::thing($rec_table, $rec_key)", params)
 }
 
 func (s *SurrealDBStorage) parseVectorResults(result *[]QueryResult) ([]VectorResult, error) {
@@ -175,10 +215,12 @@ func (s *SurrealDBStorage) parseVectorResults(result *[]QueryResult) ([]VectorRe
 			resultSlice := queryResult.Result
 			for _, itemMap := range resultSlice {
 				vectorResult := VectorResult{
-					ID:         getString(itemMap, "id"),
+					ID:         extractRecordID(itemMap["id"]),
 					Content:    getString(itemMap, "content"),
 					Similarity: getFloat64(itemMap, "similarity"),
+					Score:      getFloat64(itemMap, "similarity"),
 					Metadata:   getMap(itemMap, "metadata"),
+					Revision:   convertToInt(itemMap["revision"]),
 					CreatedAt:  getTime(itemMap, "created_at"),
 					UpdatedAt:  getTime(itemMap, "updated_at"),
 				}
@@ -189,3 +231,114 @@ func (s *SurrealDBStorage) parseVectorResults(result *[]QueryResult) ([]VectorRe
 
 	return results, nil
 }
+
+// MergeVectorMetadata merges metadata into an existing vector memory, keeping keys
+// that are not present in the new metadata
+func (s *SurrealDBStorage) MergeVectorMetadata(ctx context.Context, id string, metadata map[string]interface{}) error {
+	table, key, err := splitRecordID(id)
+	if err != nil {
+		return err
+	}
+
+	query := `UPDATE type::thing($table, $key) MERGE { metadata: $metadata, updated_at: time::now() }`
+	params := map[string]interface{}{
+		"table":    table,
+		"key":      key,
+		"metadata": metadata,
+	}
+
+	if _, err := s.query(ctx, query, params); err != nil {
+		return fmt.Errorf("failed to merge vector metadata: %w", err)
+	}
+	return nil
+}
+
+// knnOperator builds the SurrealQL KNN operator for the given options:
+// <|K|> uses the MTREE vector index and <|K,COSINE|> forces an exact brute
+// force scan. The <|K,EF|> form only applies to HNSW indexes, which the
+// schema does not define, so opts.EfSearch is ignored.
+func knnOperator(opts VectorSearchOptions) (string, error) {
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = 10
+	}
+
+	switch opts.Accuracy {
+	case "", SearchAccuracyApproximate:
+		return fmt.Sprintf("<|%d|>", limit), nil
+	case SearchAccuracyExact:
+		return fmt.Sprintf("<|%d,COSINE|>", limit), nil
+	default:
+		return "", fmt.Errorf("invalid search accuracy %q: expected %q or %q", opts.Accuracy, SearchAccuracyApproximate, SearchAccuracyExact)
+	}
+}
+
+// kbRootClause returns the WHERE fragment enforcing opts.KBRoots. The caller
+// must bind $kb_roots and $default_kb_root when the fragment is not empty.
+func kbRootClause(opts VectorSearchOptions) string {
+	if len(opts.KBRoots) == 0 {
+		return ""
+	}
+	return " AND (metadata.kb_root ?? $default_kb_root) IN $kb_roots"
+}
+
+// frontmatterClause returns the WHERE fragment enforcing opts.Frontmatter.
+// The caller must bind its parameters with bindFrontmatterFilter.
+func frontmatterClause(opts VectorSearchOptions) string {
+	f := opts.Frontmatter
+	var b strings.Builder
+	if len(f.Tags) > 0 {
+		b.WriteString(" AND (metadata.tags ?? []) CONTAINSALL $fm_tags")
+	}
+	if f.Title != "" {
+		b.WriteString(" AND string::lowercase(metadata.title ?? '') CONTAINS $fm_title")
+	}
+	if f.Alias != "" {
+		b.WriteString(" AND $fm_alias IN (metadata.aliases ?? [])")
+	}
+	if f.DateFrom != "" {
+		b.WriteString(" AND (metadata.date ?? '') >= $fm_date_from")
+	}
+	if f.DateTo != "" {
+		b.WriteString(" AND metadata.date != NONE AND metadata.date <= $fm_date_to")
+	}
+	return b.String()
+}
+
+// bindFrontmatterFilter binds the parameters of frontmatterClause.
+func bindFrontmatterFilter(opts VectorSearchOptions, params map[string]interface{}) {
+	f := opts.Frontmatter
+	if len(f.Tags) > 0 {
+		params["fm_tags"] = f.Tags
+	}
+	if f.Title != "" {
+		params["fm_title"] = strings.ToLower(f.Title)
+	}
+	if f.Alias != "" {
+		params["fm_alias"] = f.Alias
+	}
+	if f.DateFrom != "" {
+		params["fm_date_from"] = f.DateFrom
+	}
+	if f.DateTo != "" {
+		params["fm_date_to"] = f.DateTo
+	}
+}
+
+// minSimilarityClause returns the WHERE fragment enforcing opts.MinSimilarity.
+// The caller must bind $min_similarity when the fragment is not empty.
+func minSimilarityClause(opts VectorSearchOptions) string {
+	if opts.MinSimilarity <= 0 {
+		return ""
+	}
+	return " AND vector::similarity::cosine(embedding, $query_embedding) >= $min_similarity"
+}
+
+// minConfidenceClause returns the WHERE fragment enforcing opts.MinConfidence.
+// The caller must bind $min_confidence when the fragment is not empty.
+func minConfidenceClause(opts VectorSearchOptions) string {
+	if opts.MinConfidence <= 0 {
+		return ""
+	}
+	return " AND metadata.provenance.confidence >= $min_confidence"
+}
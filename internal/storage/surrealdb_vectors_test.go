@@ -0,0 +1,47 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestUpdateVectorRevision(t *testing.T) {
+	s := newTestSurrealDB(t)
+	ctx := context.Background()
+
+	embedding := make([]float32, defaultMtreeDim)
+	embedding[0] = 1
+	if err := s.IndexVector(ctx, "alice", "first draft", embedding, nil); err != nil {
+		t.Fatalf("index vector: %v", err)
+	}
+	results, err := s.SearchSimilar(ctx, "alice", embedding, 1)
+	if err != nil || len(results) != 1 {
+		t.Fatalf("search = %+v, %v; want the vector", results, err)
+	}
+	id := results[0].ID
+	if results[0].Revision != 1 {
+		t.Errorf("new vector revision = %d, want 1", results[0].Revision)
+	}
+
+	revision, err := s.UpdateVector(ctx, id, "alice", "second draft", embedding, nil, 1)
+	if err != nil || revision != 2 {
+		t.Fatalf("update at revision 1 = %d, %v; want revision 2", revision, err)
+	}
+
+	// A writer still holding revision 1 is rejected
+	_, err = s.UpdateVector(ctx, id, "alice", "stale draft", embedding, nil, 1)
+	var conflict *RevisionConflictError
+	if !errors.As(err, &conflict) || conflict.Expected != 1 || conflict.Current != 2 {
+		t.Errorf("stale update = %v, want a conflict from revision 1 to 2", err)
+	}
+
+	// No revision skips the check
+	if revision, err := s.UpdateVector(ctx, id, "alice", "final draft", embedding, nil, 0); err != nil || revision != 3 {
+		t.Errorf("unchecked update = %d, %v; want revision 3", revision, err)
+	}
+	results, _ = s.SearchSimilar(ctx, "alice", embedding, 1)
+	if len(results) != 1 || results[0].Content != "final draft" || results[0].Revision != 3 {
+		t.Errorf("vector after updates = %+v, want the final draft at revision 3", results)
+	}
+}
@@ -0,0 +1,93 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestFactAt(t *testing.T) {
+	jan := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	mar := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	jun := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	versions := []FactVersion{
+		{Key: "city", Value: "Madrid", ValidFrom: mar, Current: true},
+		{Key: "city", Value: "Lisbon", ValidFrom: jan, ValidUntil: &mar},
+	}
+
+	for _, tc := range []struct {
+		at   time.Time
+		want interface{}
+	}{
+		{jan.Add(-time.Hour), nil},
+		{jan, "Lisbon"},
+		{mar.Add(-time.Second), "Lisbon"},
+		{mar, "Madrid"},
+		{jun, "Madrid"},
+	} {
+		var got interface{}
+		if v := FactAt(versions, tc.at); v != nil {
+			got = v.Value
+		}
+		if got != tc.want {
+			t.Errorf("FactAt(%s) = %v, want %v", tc.at.Format(time.RFC3339), got, tc.want)
+		}
+	}
+
+	// An explicit valid_from overlapping an older version takes precedence
+	versions = append(versions, FactVersion{Key: "city", Value: "Porto", ValidFrom: jan.AddDate(0, 1, 0), ValidUntil: &jun})
+	if v := FactAt(versions, jun.AddDate(0, -1, 0)); v == nil || v.Value != "Madrid" {
+		t.Errorf("FactAt() with overlapping versions = %+v, want the latest to start", v)
+	}
+}
+
+func TestFactsAsOf(t *testing.T) {
+	jan := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	feb := jan.AddDate(0, 1, 0)
+	versions := []FactVersion{
+		{Key: "role", Value: "lead", ValidFrom: feb, Current: true},
+		{Key: "role", Value: "dev", ValidFrom: jan, ValidUntil: &feb},
+		{Key: "team", Value: "core", ValidFrom: jan, ValidUntil: &feb},
+		{Key: "desk", Value: 4, ValidFrom: feb, Current: true},
+	}
+
+	got := factsAsOf(versions, jan.AddDate(0, 0, 10))
+	if len(got) != 2 || got[0].Key != "role" || got[0].Value != "dev" || got[1].Key != "team" {
+		t.Errorf("factsAsOf(january) = %+v, want role dev and team", got)
+	}
+	got = factsAsOf(versions, feb)
+	if len(got) != 2 || got[0].Key != "desk" || got[1].Value != "lead" {
+		t.Errorf("factsAsOf(february) = %+v, want desk and role lead", got)
+	}
+
+	sortFactVersions(versions)
+	if !versions[0].Current || !versions[0].ValidFrom.Equal(feb) || !versions[len(versions)-1].ValidFrom.Equal(jan) {
+		t.Errorf("sortFactVersions() = %+v, want newest first", versions)
+	}
+}
+
+func TestValidityFromContext(t *testing.T) {
+	from, until, explicit := validityFromContext(context.Background())
+	if explicit || until != nil || time.Since(from) > time.Minute {
+		t.Errorf("validityFromContext() without validity = %v, %v, %v; want now", from, until, explicit)
+	}
+
+	start := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(1, 0, 0)
+	from, until, explicit = validityFromContext(WithValidity(context.Background(), Validity{From: &start, Until: &end}))
+	if !explicit || !from.Equal(start) || until == nil || !until.Equal(end) {
+		t.Errorf("validityFromContext() = %v, %v, %v; want %v until %v", from, until, explicit, start, end)
+	}
+}
+
+func TestSameFactValue(t *testing.T) {
+	if !sameFactValue(float64(3), uint64(3)) {
+		t.Error("sameFactValue() should match numbers decoded as different types")
+	}
+	if !sameFactValue(map[string]interface{}{"a": 1, "b": "x"}, map[string]interface{}{"b": "x", "a": 1}) {
+		t.Error("sameFactValue() should match equal objects")
+	}
+	if sameFactValue("3", 3) {
+		t.Error("sameFactValue() matched a string and a number")
+	}
+}
@@ -0,0 +1,240 @@
+// Package supervisor runs an external process, such as the SurrealDB server
+// started with surrealdb-start-cmd, and restarts it with backoff when it exits
+// while it is still needed.
+package supervisor
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"os/exec"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// errStopped is returned when a restart is attempted after Stop.
+var errStopped = errors.New("supervisor stopped")
+
+// Config describes the supervised process and how it is restarted.
+type Config struct {
+	// Command is run with /bin/sh -c, so it may use pipes and aliases
+	Command string
+	// Args, when set, is run directly instead of Command, which then only
+	// names the process in logs
+	Args []string
+	// Stdout and Stderr receive the output of the process
+	Stdout, Stderr io.Writer
+
+	// MaxRestarts is the number of consecutive restarts tried before giving
+	// up; 0 disables restarts
+	MaxRestarts int
+	// InitialBackoff is the wait before the first restart, doubled after
+	// every failed one up to MaxBackoff
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	// StableAfter is how long a process must run for its exit to count as a
+	// new crash rather than another failed restart
+	StableAfter time.Duration
+
+	// OnExit is called when the process exits unexpectedly, before it is
+	// restarted
+	OnExit func(err error)
+	// OnRestart is called after the process was restarted, to reconnect to
+	// it. An error counts as a failed restart: the process is killed and
+	// started again.
+	OnRestart func(ctx context.Context) error
+	// OnGiveUp is called when MaxRestarts restarts failed in a row
+	OnGiveUp func()
+}
+
+// Process is a supervised process.
+type Process struct {
+	cfg Config
+	// ctx is cancelled by Stop, ending supervision and pending reconnections
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	mu        sync.Mutex
+	cmd       *exec.Cmd
+	exited    chan struct{}
+	exitErr   error
+	startedAt time.Time
+	stopping  bool
+	restarts  int
+}
+
+// Start starts the process and supervises it until Stop is called.
+func Start(cfg Config) (*Process, error) {
+	if cfg.InitialBackoff <= 0 {
+		cfg.InitialBackoff = time.Second
+	}
+	if cfg.MaxBackoff < cfg.InitialBackoff {
+		cfg.MaxBackoff = cfg.InitialBackoff
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &Process{cfg: cfg, ctx: ctx, cancel: cancel, done: make(chan struct{})}
+	if err := p.start(); err != nil {
+		cancel()
+		return nil, err
+	}
+	go p.supervise()
+	return p, nil
+}
+
+// start runs the command and reaps it in the background.
+func (p *Process) start() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.stopping {
+		return errStopped
+	}
+	cmd := exec.Command("/bin/sh", "-c", p.cfg.Command)
+	if len(p.cfg.Args) > 0 {
+		cmd = exec.Command(p.cfg.Args[0], p.cfg.Args[1:]...)
+	}
+	cmd.Stdout, cmd.Stderr = p.cfg.Stdout, p.cfg.Stderr
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	exited := make(chan struct{})
+	p.cmd, p.exited, p.exitErr, p.startedAt = cmd, exited, nil, time.Now()
+	go func() {
+		err := cmd.Wait()
+		p.mu.Lock()
+		p.exitErr = err
+		p.mu.Unlock()
+		close(exited)
+	}()
+	return nil
+}
+
+// supervise waits for the process to exit and restarts it, until Stop is
+// called or MaxRestarts restarts failed in a row.
+func (p *Process) supervise() {
+	defer close(p.done)
+	failures := 0
+	backoff := p.cfg.InitialBackoff
+	for {
+		p.mu.Lock()
+		exited, startedAt := p.exited, p.startedAt
+		p.mu.Unlock()
+		select {
+		case <-exited:
+		case <-p.ctx.Done():
+			return
+		}
+		if p.isStopping() {
+			return
+		}
+
+		p.mu.Lock()
+		exitErr := p.exitErr
+		p.mu.Unlock()
+		if time.Since(startedAt) >= p.cfg.StableAfter {
+			failures, backoff = 0, p.cfg.InitialBackoff
+		}
+		slog.Warn("supervised process exited unexpectedly", "cmd", p.cfg.Command, "error", exitErr, "uptime", time.Since(startedAt).Round(time.Second))
+		if p.cfg.OnExit != nil {
+			p.cfg.OnExit(exitErr)
+		}
+
+		for {
+			if failures >= p.cfg.MaxRestarts {
+				slog.Error("giving up restarting supervised process", "cmd", p.cfg.Command, "restarts", failures)
+				if p.cfg.OnGiveUp != nil {
+					p.cfg.OnGiveUp()
+				}
+				return
+			}
+			failures++
+			slog.Info("restarting supervised process", "cmd", p.cfg.Command, "attempt", failures, "wait", backoff)
+			select {
+			case <-time.After(backoff):
+			case <-p.ctx.Done():
+				return
+			}
+			backoff = min(backoff*2, p.cfg.MaxBackoff)
+
+			if err := p.start(); err != nil {
+				if errors.Is(err, errStopped) {
+					return
+				}
+				slog.Warn("failed to restart supervised process", "cmd", p.cfg.Command, "error", err)
+				continue
+			}
+			p.mu.Lock()
+			p.restarts++
+			p.mu.Unlock()
+			if p.cfg.OnRestart != nil {
+				if err := p.cfg.OnRestart(p.ctx); err != nil {
+					p.kill()
+					if p.isStopping() {
+						return
+					}
+					slog.Warn("supervised process restarted but is not usable, killed it", "cmd", p.cfg.Command, "error", err)
+					continue
+				}
+			}
+			slog.Info("supervised process restarted", "cmd", p.cfg.Command, "attempt", failures)
+			break
+		}
+	}
+}
+
+// kill kills the current process and waits for it to be reaped.
+func (p *Process) kill() {
+	p.mu.Lock()
+	cmd, exited := p.cmd, p.exited
+	p.mu.Unlock()
+	_ = cmd.Process.Kill()
+	<-exited
+}
+
+func (p *Process) isStopping() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.stopping
+}
+
+// Restarts returns how many times the process was restarted.
+func (p *Process) Restarts() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.restarts
+}
+
+// Stop ends supervision and terminates the process: SIGTERM first, then
+// SIGKILL when it is still running after timeout.
+func (p *Process) Stop(timeout time.Duration) {
+	p.mu.Lock()
+	if p.stopping {
+		p.mu.Unlock()
+		return
+	}
+	p.stopping = true
+	cmd, exited := p.cmd, p.exited
+	p.mu.Unlock()
+	p.cancel()
+	<-p.done
+
+	select {
+	case <-exited:
+		return
+	default:
+	}
+	_ = cmd.Process.Signal(syscall.SIGTERM)
+	select {
+	case <-exited:
+		slog.Info("supervised process exited cleanly", "cmd", p.cfg.Command)
+	case <-time.After(timeout):
+		slog.Warn("supervised process did not exit after SIGTERM, killing", "cmd", p.cfg.Command)
+		_ = cmd.Process.Kill()
+		select {
+		case <-exited:
+		case <-time.After(2 * time.Second):
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package supervisor
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestProcessRestartsAfterCrash(t *testing.T) {
+	var exits, reconnects atomic.Int32
+	restarted := make(chan struct{}, 1)
+	p, err := Start(Config{
+		Command:        "sleep 60",
+		MaxRestarts:    3,
+		InitialBackoff: 10 * time.Millisecond,
+		StableAfter:    time.Minute,
+		OnExit:         func(error) { exits.Add(1) },
+		OnRestart: func(ctx context.Context) error {
+			reconnects.Add(1)
+			restarted <- struct{}{}
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer p.Stop(time.Second)
+
+	p.kill()
+	select {
+	case <-restarted:
+	case <-time.After(5 * time.Second):
+		t.Fatal("process was not restarted")
+	}
+	if exits.Load() != 1 || reconnects.Load() != 1 || p.Restarts() != 1 {
+		t.Errorf("exits = %d, reconnects = %d, restarts = %d, want 1 each", exits.Load(), reconnects.Load(), p.Restarts())
+	}
+}
+
+func TestProcessGivesUp(t *testing.T) {
+	gaveUp := make(chan struct{})
+	var reconnects atomic.Int32
+	p, err := Start(Config{
+		Command:        "exit 1",
+		MaxRestarts:    2,
+		InitialBackoff: 10 * time.Millisecond,
+		StableAfter:    time.Minute,
+		OnRestart: func(ctx context.Context) error {
+			reconnects.Add(1)
+			return errors.New("connection refused")
+		},
+		OnGiveUp: func() { close(gaveUp) },
+	})
+	if err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer p.Stop(time.Second)
+
+	select {
+	case <-gaveUp:
+	case <-time.After(5 * time.Second):
+		t.Fatal("supervisor did not give up")
+	}
+	if got := p.Restarts(); got != 2 {
+		t.Errorf("Restarts() = %d, want MaxRestarts 2", got)
+	}
+	if got := reconnects.Load(); got > 2 {
+		t.Errorf("OnRestart called %d times, want at most 2", got)
+	}
+}
+
+func TestProcessStopIsNotACrash(t *testing.T) {
+	var exits atomic.Int32
+	p, err := Start(Config{
+		Command:     "sleep 60",
+		MaxRestarts: 3,
+		OnExit:      func(error) { exits.Add(1) },
+	})
+	if err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		p.Stop(time.Second)
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Stop() did not return")
+	}
+	if exits.Load() != 0 || p.Restarts() != 0 {
+		t.Errorf("stopping the process was handled as a crash")
+	}
+}
+
+func TestProcessRunsArgs(t *testing.T) {
+	p, err := Start(Config{Command: "sleep", Args: []string{"sleep", "60"}})
+	if err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer p.Stop(time.Second)
+	if got := p.cmd.Args; len(got) != 2 || got[0] != "sleep" || got[1] != "60" {
+		t.Errorf("process args = %v, want [sleep 60]", got)
+	}
+}
@@ -0,0 +1,101 @@
+// Package surrealdocker runs SurrealDB as a Docker container through the
+// docker CLI, as an alternative to starting it with a shell command.
+package surrealdocker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// dataDir is where the volume is mounted inside the container.
+const dataDir = "/data"
+
+// Config describes the container.
+type Config struct {
+	Image     string // e.g. surrealdb/surrealdb
+	Tag       string // e.g. latest or v2.3.7
+	Container string // container name, replaced when it already exists
+	Port      int    // host port published for the container's port 8000
+	// Volume is a host directory or named volume holding the database; when
+	// empty the database lives in memory and is lost when the container stops
+	Volume string
+	User   string
+	Pass   string
+}
+
+// URL returns the WebSocket URL of the container's RPC endpoint.
+func (c Config) URL() string {
+	return fmt.Sprintf("ws://127.0.0.1:%d/rpc", c.Port)
+}
+
+// ImageRef returns the image reference with its tag.
+func (c Config) ImageRef() string {
+	if c.Tag == "" {
+		return c.Image
+	}
+	return c.Image + ":" + c.Tag
+}
+
+// RunArgs returns the docker command line that runs the container in the
+// foreground, so it can be supervised and its output followed. The
+// container is removed when it stops.
+func RunArgs(c Config) []string {
+	args := []string{"docker", "run", "--rm", "--name", c.Container,
+		"-p", fmt.Sprintf("127.0.0.1:%d:8000", c.Port)}
+	path := "memory"
+	if c.Volume != "" {
+		args = append(args, "-v", c.Volume+":"+dataDir)
+		path = "rocksdb:" + dataDir + "/remembrances.db"
+	}
+	args = append(args, c.ImageRef(), "start", "--log", "info", "--bind", "0.0.0.0:8000")
+	if c.User != "" {
+		args = append(args, "--user", c.User, "--pass", c.Pass)
+	}
+	return append(args, path)
+}
+
+// CheckDocker returns an error when the docker CLI is missing or cannot reach
+// the Docker daemon.
+func CheckDocker(ctx context.Context) error {
+	if _, err := exec.LookPath("docker"); err != nil {
+		return errors.New("docker not found in PATH: install Docker or use surrealdb-start-cmd")
+	}
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	if out, err := exec.CommandContext(ctx, "docker", "version", "--format", "{{.Server.Version}}").CombinedOutput(); err != nil {
+		return fmt.Errorf("docker daemon not reachable: %s", strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// EnsureImage pulls the image unless it is already present, so the time a
+// first pull takes does not count against the wait for readiness. The pull
+// progress is written to w.
+func EnsureImage(ctx context.Context, ref string, w io.Writer) error {
+	if err := exec.CommandContext(ctx, "docker", "image", "inspect", ref).Run(); err == nil {
+		return nil
+	}
+	pull := exec.CommandContext(ctx, "docker", "pull", ref)
+	pull.Stdout, pull.Stderr = w, w
+	if err := pull.Run(); err != nil {
+		return fmt.Errorf("failed to pull %s: %w", ref, err)
+	}
+	return nil
+}
+
+// Remove force-removes the container if it exists, such as one left behind
+// by a server that was killed.
+func Remove(ctx context.Context, name string) error {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, "docker", "rm", "--force", name).CombinedOutput()
+	if err != nil && !strings.Contains(string(out), "No such container") {
+		return fmt.Errorf("failed to remove container %s: %s", name, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
@@ -0,0 +1,26 @@
+package surrealdocker
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRunArgs(t *testing.T) {
+	cfg := Config{Image: "surrealdb/surrealdb", Tag: "v2.3.7", Container: "remembrances-surrealdb", Port: 8001, User: "root", Pass: "secret"}
+
+	want := []string{"docker", "run", "--rm", "--name", "remembrances-surrealdb", "-p", "127.0.0.1:8001:8000",
+		"surrealdb/surrealdb:v2.3.7", "start", "--log", "info", "--bind", "0.0.0.0:8000", "--user", "root", "--pass", "secret", "memory"}
+	if got := RunArgs(cfg); !reflect.DeepEqual(got, want) {
+		t.Errorf("RunArgs() without volume = %v, want %v", got, want)
+	}
+
+	cfg.Volume = "/var/lib/remembrances"
+	got := RunArgs(cfg)
+	if got[7] != "-v" || got[8] != "/var/lib/remembrances:/data" || got[len(got)-1] != "rocksdb:/data/remembrances.db" {
+		t.Errorf("RunArgs() with volume = %v", got)
+	}
+
+	if url := cfg.URL(); url != "ws://127.0.0.1:8001/rpc" {
+		t.Errorf("URL() = %q", url)
+	}
+}
@@ -0,0 +1,560 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/madeindigio/remembrances-mcp/internal/storage"
+	"github.com/madeindigio/remembrances-mcp/pkg/embedder"
+	pb "github.com/madeindigio/remembrances-mcp/pkg/grpcapi/remembrancesv1"
+	"github.com/madeindigio/remembrances-mcp/pkg/redact"
+	"github.com/madeindigio/remembrances-mcp/pkg/tenancy"
+)
+
+const (
+	// defaultGRPCSearchLimit is used when a search request sets no limit.
+	defaultGRPCSearchLimit = 10
+	// maxGRPCMessageBytes limits requests, which may hold whole documents.
+	maxGRPCMessageBytes = 32 << 20
+)
+
+// GRPCTransport serves the storage and search layers over gRPC for
+// integrations that need lower overhead than MCP or the REST API. It uses
+// the same storage, embedder and redaction filter as the MCP tools.
+type GRPCTransport struct {
+	pb.UnimplementedRemembrancesServiceServer
+
+	addr      string
+	server    *grpc.Server
+	storage   storage.FullStorage
+	embedders *embedder.Router
+	redactor  *redact.Redactor
+}
+
+// NewGRPCTransport creates a gRPC transport listening on addr, embedding
+// each record kind with its embedder in embedders. When tenants
+// is enabled, calls must carry the API key of a tenant in the authorization
+// ("Bearer <key>") or x-api-key metadata and are served from its database.
+func NewGRPCTransport(addr string, store storage.FullStorage, embedders *embedder.Router, redactor *redact.Redactor, tenants *tenancy.Registry) *GRPCTransport {
+	opts := []grpc.ServerOption{grpc.MaxRecvMsgSize(maxGRPCMessageBytes)}
+	if tenants.Enabled() {
+		opts = append(opts,
+			grpc.UnaryInterceptor(tenantUnaryInterceptor(tenants)),
+			grpc.StreamInterceptor(tenantStreamInterceptor(tenants)),
+		)
+	}
+	g := &GRPCTransport{
+		addr:      addr,
+		storage:   store,
+		embedders: embedders,
+		redactor:  redactor,
+		server:    grpc.NewServer(opts...),
+	}
+	pb.RegisterRemembrancesServiceServer(g.server, g)
+	return g
+}
+
+// Start listens on the configured address and serves until Stop is called.
+func (g *GRPCTransport) Start() error {
+	lis, err := net.Listen("tcp", g.addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", g.addr, err)
+	}
+	slog.Info("Starting gRPC transport server", "address", g.addr)
+	return g.server.Serve(lis)
+}
+
+// Stop waits for in-flight calls to finish, or cancels them when ctx is done.
+func (g *GRPCTransport) Stop(ctx context.Context) {
+	slog.Info("Shutting down gRPC transport server")
+	done := make(chan struct{})
+	go func() {
+		g.server.GracefulStop()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		g.server.Stop()
+	}
+}
+
+// tenantContext returns ctx with the tenant owning the API key in the call
+// metadata, or an Unauthenticated error.
+func tenantContext(ctx context.Context, tenants *tenancy.Registry) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	header := http.Header{
+		tenancy.HeaderAuthorization: md.Get("authorization"),
+		tenancy.HeaderAPIKey:        md.Get("x-api-key"),
+	}
+	tenant, ok := tenants.Lookup(tenancy.KeyFromHeader(header))
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "missing or unknown API key")
+	}
+	return tenancy.WithTenant(ctx, tenant), nil
+}
+
+func tenantUnaryInterceptor(tenants *tenancy.Registry) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := tenantContext(ctx, tenants)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+func tenantStreamInterceptor(tenants *tenancy.Registry) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := tenantContext(ss.Context(), tenants)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &tenantStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// tenantStream is a server stream whose context carries the caller's tenant.
+type tenantStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *tenantStream) Context() context.Context {
+	return s.ctx
+}
+
+// SaveFact stores a key-value fact, redacting string values.
+func (g *GRPCTransport) SaveFact(ctx context.Context, req *pb.SaveFactRequest) (*pb.SaveFactResponse, error) {
+	if req.GetUserId() == "" || req.GetKey() == "" {
+		return nil, status.Error(codes.InvalidArgument, "user_id and key are required")
+	}
+	value := req.GetValue().AsInterface()
+	if s, ok := value.(string); ok {
+		redacted, err := g.redactContent("SaveFact", s)
+		if err != nil {
+			return nil, err
+		}
+		value = redacted
+	}
+	if err := g.storage.SaveFact(ctx, req.GetUserId(), req.GetKey(), value); err != nil {
+		return nil, internalError("failed to save fact", err)
+	}
+	return &pb.SaveFactResponse{}, nil
+}
+
+// GetFact returns a fact, with found unset when it does not exist.
+func (g *GRPCTransport) GetFact(ctx context.Context, req *pb.GetFactRequest) (*pb.GetFactResponse, error) {
+	value, err := g.storage.GetFact(ctx, req.GetUserId(), req.GetKey())
+	if err != nil {
+		return nil, internalError("failed to get fact", err)
+	}
+	if value == nil {
+		return &pb.GetFactResponse{}, nil
+	}
+	v, err := structpb.NewValue(jsonCompatible(value))
+	if err != nil {
+		return nil, internalError("failed to encode fact", err)
+	}
+	g.recordHits(ctx, "GetFact", []storage.MemoryHit{{Kind: storage.TrashKindFact, Ref: req.GetKey(), UserID: req.GetUserId()}})
+	return &pb.GetFactResponse{Found: true, Value: v}, nil
+}
+
+// DeleteFact deletes a fact.
+func (g *GRPCTransport) DeleteFact(ctx context.Context, req *pb.DeleteFactRequest) (*pb.DeleteFactResponse, error) {
+	if err := g.storage.DeleteFact(ctx, req.GetUserId(), req.GetKey()); err != nil {
+		return nil, deleteError("failed to delete fact", err)
+	}
+	return &pb.DeleteFactResponse{}, nil
+}
+
+// ListFacts returns every fact of a user.
+func (g *GRPCTransport) ListFacts(ctx context.Context, req *pb.ListFactsRequest) (*pb.ListFactsResponse, error) {
+	facts, err := g.storage.ListFacts(ctx, req.GetUserId())
+	if err != nil {
+		return nil, internalError("failed to list facts", err)
+	}
+	resp := &pb.ListFactsResponse{Facts: make(map[string]*structpb.Value, len(facts))}
+	for key, value := range facts {
+		v, err := structpb.NewValue(jsonCompatible(value))
+		if err != nil {
+			return nil, internalError("failed to encode fact "+key, err)
+		}
+		resp.Facts[key] = v
+	}
+	return resp, nil
+}
+
+// AddVector stores a semantic memory, embedding its content unless the
+// request carries an embedding.
+func (g *GRPCTransport) AddVector(ctx context.Context, req *pb.AddVectorRequest) (*pb.AddVectorResponse, error) {
+	if req.GetUserId() == "" || req.GetContent() == "" {
+		return nil, status.Error(codes.InvalidArgument, "user_id and content are required")
+	}
+	content, err := g.redactContent("AddVector", req.GetContent())
+	if err != nil {
+		return nil, err
+	}
+	embedding := req.GetEmbedding()
+	if len(embedding) == 0 {
+		if embedding, err = g.embedders.For(embedder.RouteVectors).EmbedQuery(ctx, content); err != nil {
+			return nil, internalError("failed to generate embedding", err)
+		}
+	}
+	if err := g.storage.IndexVector(ctx, req.GetUserId(), content, embedding, req.GetMetadata().AsMap()); err != nil {
+		return nil, internalError("failed to add vector", err)
+	}
+	return &pb.AddVectorResponse{}, nil
+}
+
+// DeleteVector deletes a semantic memory.
+func (g *GRPCTransport) DeleteVector(ctx context.Context, req *pb.DeleteVectorRequest) (*pb.DeleteVectorResponse, error) {
+	if err := g.storage.DeleteVector(ctx, req.GetId(), req.GetUserId()); err != nil {
+		return nil, deleteError("failed to delete vector", err)
+	}
+	return &pb.DeleteVectorResponse{}, nil
+}
+
+// SearchVectors streams the semantic memories of a user closest to the query.
+func (g *GRPCTransport) SearchVectors(req *pb.SearchRequest, stream grpc.ServerStreamingServer[pb.VectorResult]) error {
+	ctx := stream.Context()
+	embedding, err := g.queryEmbedding(ctx, embedder.RouteVectors, req.GetQuery(), req.GetEmbedding())
+	if err != nil {
+		return err
+	}
+	results, err := g.storage.SearchSimilarWithOptions(ctx, req.GetUserId(), embedding, searchOptions(req))
+	if err != nil {
+		return internalError("failed to search vectors", err)
+	}
+	if len(results) > 0 {
+		hits := make([]storage.MemoryHit, 0, len(results))
+		for _, r := range results {
+			hits = append(hits, storage.MemoryHit{Kind: storage.TrashKindVector, Ref: r.ID, UserID: req.GetUserId()})
+		}
+		g.recordHits(ctx, "SearchVectors", hits)
+	}
+	for _, r := range results {
+		metadata, err := toStruct(r.Metadata)
+		if err != nil {
+			return internalError("failed to encode metadata", err)
+		}
+		if err := stream.Send(&pb.VectorResult{
+			Id:         r.ID,
+			Content:    r.Content,
+			Similarity: r.Similarity,
+			Metadata:   metadata,
+			CreatedAt:  toTimestamp(r.CreatedAt),
+			UpdatedAt:  toTimestamp(r.UpdatedAt),
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetDocument returns a knowledge base document by path.
+func (g *GRPCTransport) GetDocument(ctx context.Context, req *pb.GetDocumentRequest) (*pb.Document, error) {
+	doc, err := g.storage.GetDocument(ctx, req.GetFilePath())
+	if err != nil {
+		return nil, internalError("failed to get document", err)
+	}
+	if doc == nil {
+		return nil, status.Errorf(codes.NotFound, "document %q not found", req.GetFilePath())
+	}
+	g.recordHits(ctx, "GetDocument", []storage.MemoryHit{{Kind: storage.TrashKindDocument, Ref: doc.FilePath}})
+	return toDocument(doc)
+}
+
+// SearchDocuments streams the knowledge base chunks closest to the query.
+func (g *GRPCTransport) SearchDocuments(req *pb.SearchRequest, stream grpc.ServerStreamingServer[pb.DocumentResult]) error {
+	ctx := stream.Context()
+	embedding, err := g.queryEmbedding(ctx, embedder.RouteKnowledgeBase, req.GetQuery(), req.GetEmbedding())
+	if err != nil {
+		return err
+	}
+	results, err := g.storage.SearchDocumentsWithOptions(ctx, embedding, searchOptions(req))
+	if err != nil {
+		return internalError("failed to search documents", err)
+	}
+	for _, r := range results {
+		if r.Document == nil {
+			continue
+		}
+		doc, err := toDocument(r.Document)
+		if err != nil {
+			return err
+		}
+		if err := stream.Send(&pb.DocumentResult{Document: doc, Similarity: r.Similarity}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SaveEvent stores a timestamped event.
+func (g *GRPCTransport) SaveEvent(ctx context.Context, req *pb.SaveEventRequest) (*pb.SaveEventResponse, error) {
+	if req.GetUserId() == "" || req.GetSubject() == "" || req.GetContent() == "" {
+		return nil, status.Error(codes.InvalidArgument, "user_id, subject and content are required")
+	}
+	embeddings, err := g.embedders.For(embedder.RouteEvents).EmbedDocuments(ctx, []string{req.GetContent()})
+	if err != nil {
+		return nil, internalError("failed to generate embedding", err)
+	}
+	id, createdAt, err := g.storage.SaveEvent(ctx, req.GetUserId(), req.GetSubject(), req.GetContent(), embeddings[0], req.GetMetadata().AsMap())
+	if err != nil {
+		return nil, internalError("failed to save event", err)
+	}
+	return &pb.SaveEventResponse{Id: id, CreatedAt: toTimestamp(createdAt)}, nil
+}
+
+// SearchEvents streams the events of a user matching the filters, most
+// relevant first when a query is given.
+func (g *GRPCTransport) SearchEvents(req *pb.SearchEventsRequest, stream grpc.ServerStreamingServer[pb.Event]) error {
+	ctx := stream.Context()
+	params := storage.EventSearchParams{
+		UserID:  req.GetUserId(),
+		Subject: req.GetSubject(),
+		Query:   req.GetQuery(),
+		Limit:   int(req.GetLimit()),
+	}
+	if req.GetFrom() != nil {
+		from := req.GetFrom().AsTime().UTC().Truncate(time.Second)
+		params.FromDate = &from
+	}
+	if req.GetTo() != nil {
+		to := req.GetTo().AsTime().UTC().Truncate(time.Second)
+		params.ToDate = &to
+	}
+	if params.Query != "" {
+		embedding, err := g.embedders.For(embedder.RouteEvents).EmbedQuery(ctx, params.Query)
+		if err != nil {
+			return internalError("failed to generate query embedding", err)
+		}
+		params.Embedding = embedding
+	}
+
+	results, err := g.storage.SearchEvents(ctx, params)
+	if err != nil {
+		return internalError("failed to search events", err)
+	}
+	for _, r := range results {
+		metadata, err := toStruct(r.Event.Metadata)
+		if err != nil {
+			return internalError("failed to encode metadata", err)
+		}
+		if err := stream.Send(&pb.Event{
+			Id:        r.Event.ID,
+			UserId:    r.Event.UserID,
+			Subject:   r.Event.Subject,
+			Content:   r.Event.Content,
+			Metadata:  metadata,
+			CreatedAt: toTimestamp(r.Event.CreatedAt),
+			Relevance: r.Relevance,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetEntity returns a graph entity by ID.
+func (g *GRPCTransport) GetEntity(ctx context.Context, req *pb.GetEntityRequest) (*pb.Entity, error) {
+	entity, err := g.storage.GetEntity(ctx, req.GetId())
+	if err != nil {
+		return nil, internalError("failed to get entity", err)
+	}
+	if entity == nil {
+		return nil, status.Errorf(codes.NotFound, "entity %q not found", req.GetId())
+	}
+	return toEntity(entity)
+}
+
+// TraverseGraph streams the entities reachable from the start entity.
+func (g *GRPCTransport) TraverseGraph(req *pb.TraverseGraphRequest, stream grpc.ServerStreamingServer[pb.GraphResult]) error {
+	depth := int(req.GetDepth())
+	if depth <= 0 {
+		depth = 1
+	}
+	results, err := g.storage.TraverseGraph(stream.Context(), req.GetStartEntity(), req.GetRelationshipType(), depth)
+	if err != nil {
+		return internalError("failed to traverse graph", err)
+	}
+	for _, r := range results {
+		result := &pb.GraphResult{Depth: int32(r.Depth), Path: r.Path}
+		if r.Entity != nil {
+			if result.Entity, err = toEntity(r.Entity); err != nil {
+				return err
+			}
+		}
+		if err := stream.Send(result); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetStats returns memory counts for a user.
+func (g *GRPCTransport) GetStats(ctx context.Context, req *pb.GetStatsRequest) (*pb.Stats, error) {
+	stats, err := g.storage.GetStats(ctx, req.GetUserId())
+	if err != nil {
+		return nil, internalError("failed to get stats", err)
+	}
+	return &pb.Stats{
+		FactCount:         int64(stats.KeyValueCount),
+		VectorCount:       int64(stats.VectorCount),
+		EntityCount:       int64(stats.EntityCount),
+		RelationshipCount: int64(stats.RelationshipCount),
+		DocumentCount:     int64(stats.DocumentCount),
+		EventCount:        int64(stats.EventCount),
+		TotalSizeBytes:    stats.TotalSize,
+	}, nil
+}
+
+// redactContent runs content through the redaction filter, as the MCP tools
+// do before storing it. Rejected content is an InvalidArgument error.
+func (g *GRPCTransport) redactContent(method, content string) (string, error) {
+	redacted, findings, err := g.redactor.Apply(content)
+	if len(findings) > 0 {
+		slog.Warn("sensitive content detected", "method", method, "mode", g.redactor.Mode(), "findings", findings)
+	}
+	var rejected *redact.RejectedError
+	if errors.As(err, &rejected) {
+		return "", status.Error(codes.InvalidArgument, err.Error())
+	}
+	return redacted, err
+}
+
+// recordHits stores retrievals for usage reports; failures are only logged.
+func (g *GRPCTransport) recordHits(ctx context.Context, method string, hits []storage.MemoryHit) {
+	for i := range hits {
+		hits[i].Tool = "grpc:" + method
+	}
+	if err := g.storage.RecordMemoryHits(ctx, hits); err != nil {
+		slog.Warn("failed to record memory hits", "method", method, "error", err)
+	}
+}
+
+// queryEmbedding returns embedding when set, or the embedding of query by
+// the embedder of a record kind.
+func (g *GRPCTransport) queryEmbedding(ctx context.Context, kind, query string, embedding []float32) ([]float32, error) {
+	if len(embedding) > 0 {
+		return embedding, nil
+	}
+	if query == "" {
+		return nil, status.Error(codes.InvalidArgument, "query or embedding is required")
+	}
+	embedding, err := g.embedders.For(kind).EmbedQuery(ctx, query)
+	if err != nil {
+		return nil, internalError("failed to generate query embedding", err)
+	}
+	return embedding, nil
+}
+
+func searchOptions(req *pb.SearchRequest) storage.VectorSearchOptions {
+	limit := int(req.GetLimit())
+	if limit <= 0 {
+		limit = defaultGRPCSearchLimit
+	}
+	return storage.VectorSearchOptions{Limit: limit, MinSimilarity: req.GetMinSimilarity()}
+}
+
+func toDocument(doc *storage.Document) (*pb.Document, error) {
+	metadata, err := toStruct(doc.Metadata)
+	if err != nil {
+		return nil, internalError("failed to encode metadata", err)
+	}
+	return &pb.Document{
+		FilePath:  doc.FilePath,
+		Content:   doc.Content,
+		Metadata:  metadata,
+		CreatedAt: toTimestamp(doc.CreatedAt),
+		UpdatedAt: toTimestamp(doc.UpdatedAt),
+	}, nil
+}
+
+func toEntity(entity *storage.Entity) (*pb.Entity, error) {
+	properties, err := toStruct(entity.Properties)
+	if err != nil {
+		return nil, internalError("failed to encode properties", err)
+	}
+	return &pb.Entity{
+		Id:         entity.ID,
+		Name:       entity.Name,
+		Type:       entity.Type,
+		Properties: properties,
+		CreatedAt:  toTimestamp(entity.CreatedAt),
+		UpdatedAt:  toTimestamp(entity.UpdatedAt),
+	}, nil
+}
+
+// toStruct converts a metadata map, which may hold database-decoded values
+// such as int64 or time.Time, to a protobuf Struct.
+func toStruct(m map[string]interface{}) (*structpb.Struct, error) {
+	if len(m) == 0 {
+		return nil, nil
+	}
+	compatible, _ := jsonCompatible(m).(map[string]interface{})
+	return structpb.NewStruct(compatible)
+}
+
+// jsonCompatible converts values to the types structpb accepts.
+func jsonCompatible(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(val))
+		for k, item := range val {
+			out[k] = jsonCompatible(item)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(val))
+		for i, item := range val {
+			out[i] = jsonCompatible(item)
+		}
+		return out
+	case []string:
+		out := make([]interface{}, len(val))
+		for i, item := range val {
+			out[i] = item
+		}
+		return out
+	case time.Time:
+		return val.UTC().Format(time.RFC3339Nano)
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64, bool, string, nil:
+		return val
+	default:
+		return fmt.Sprint(val)
+	}
+}
+
+func toTimestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
+
+func internalError(msg string, err error) error {
+	return status.Errorf(codes.Internal, "%s: %v", msg, err)
+}
+
+// deleteError reports a failed delete, as a failed precondition when the
+// memory is pinned.
+func deleteError(msg string, err error) error {
+	if errors.Is(err, storage.ErrPinned) {
+		return status.Errorf(codes.FailedPrecondition, "%s: %v", msg, err)
+	}
+	return internalError(msg, err)
+}
@@ -0,0 +1,223 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"github.com/madeindigio/remembrances-mcp/internal/storage"
+	"github.com/madeindigio/remembrances-mcp/pkg/embedder"
+	pb "github.com/madeindigio/remembrances-mcp/pkg/grpcapi/remembrancesv1"
+	"github.com/madeindigio/remembrances-mcp/pkg/redact"
+	"github.com/madeindigio/remembrances-mcp/pkg/tenancy"
+)
+
+// unitEmbedder embeds every text as the same vector.
+type unitEmbedder struct{}
+
+func (unitEmbedder) EmbedDocuments(ctx context.Context, texts []string) ([][]float32, error) {
+	embeddings := make([][]float32, len(texts))
+	for i := range texts {
+		embeddings[i] = []float32{1, 0}
+	}
+	return embeddings, nil
+}
+
+func (unitEmbedder) EmbedQuery(ctx context.Context, text string) ([]float32, error) {
+	return []float32{1, 0}, nil
+}
+
+func (unitEmbedder) Dimension() int { return 2 }
+
+// grpcStorage keeps facts and vectors in memory and records the tenant and
+// memory hits of the calls it serves.
+type grpcStorage struct {
+	storage.FullStorage
+	facts   map[string]interface{}
+	vectors []storage.VectorResult
+	hits    []storage.MemoryHit
+	tenant  string
+}
+
+func (s *grpcStorage) SaveFact(ctx context.Context, userID, key string, value interface{}) error {
+	if t, ok := tenancy.FromContext(ctx); ok {
+		s.tenant = t.ID
+	}
+	s.facts[userID+"/"+key] = value
+	return nil
+}
+
+func (s *grpcStorage) GetFact(ctx context.Context, userID, key string) (interface{}, error) {
+	return s.facts[userID+"/"+key], nil
+}
+
+func (s *grpcStorage) DeleteFact(ctx context.Context, userID, key string) error {
+	if key == "pinned" {
+		return storage.ErrPinned
+	}
+	delete(s.facts, userID+"/"+key)
+	return nil
+}
+
+func (s *grpcStorage) IndexVector(ctx context.Context, userID, content string, embedding []float32, metadata map[string]interface{}) error {
+	s.vectors = append(s.vectors, storage.VectorResult{ID: "vector_memories:" + content, Content: content, Metadata: metadata, Similarity: 1})
+	return nil
+}
+
+func (s *grpcStorage) SearchSimilarWithOptions(ctx context.Context, userID string, queryEmbedding []float32, opts storage.VectorSearchOptions) ([]storage.VectorResult, error) {
+	if len(s.vectors) > opts.Limit {
+		return s.vectors[:opts.Limit], nil
+	}
+	return s.vectors, nil
+}
+
+func (s *grpcStorage) RecordMemoryHits(ctx context.Context, hits []storage.MemoryHit) error {
+	s.hits = append(s.hits, hits...)
+	return nil
+}
+
+// newTestGRPC serves a gRPC transport over an in-memory connection and
+// returns a client for it.
+func newTestGRPC(t *testing.T, st *grpcStorage, redactor *redact.Redactor, tenants *tenancy.Registry) pb.RemembrancesServiceClient {
+	t.Helper()
+	g := NewGRPCTransport("", st, embedder.NewRouter(unitEmbedder{}, "test"), redactor, tenants)
+	lis := bufconn.Listen(1 << 20)
+	go func() { _ = g.server.Serve(lis) }()
+	t.Cleanup(func() { g.Stop(context.Background()) })
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return pb.NewRemembrancesServiceClient(conn)
+}
+
+func TestGRPCFactRoundTrip(t *testing.T) {
+	st := &grpcStorage{facts: map[string]interface{}{}}
+	client := newTestGRPC(t, st, nil, nil)
+	ctx := context.Background()
+
+	if _, err := client.SaveFact(ctx, &pb.SaveFactRequest{UserId: "alice", Key: "editor", Value: structpb.NewStringValue("vim")}); err != nil {
+		t.Fatalf("SaveFact: %v", err)
+	}
+	got, err := client.GetFact(ctx, &pb.GetFactRequest{UserId: "alice", Key: "editor"})
+	if err != nil || !got.GetFound() || got.GetValue().GetStringValue() != "vim" {
+		t.Errorf("GetFact = %v, %v; want vim", got, err)
+	}
+	if len(st.hits) != 1 || st.hits[0].Tool != "grpc:GetFact" || st.hits[0].Ref != "editor" {
+		t.Errorf("hits = %+v, want the GetFact retrieval", st.hits)
+	}
+	if got, err := client.GetFact(ctx, &pb.GetFactRequest{UserId: "alice", Key: "shell"}); err != nil || got.GetFound() {
+		t.Errorf("GetFact of a missing key = %v, %v; want not found", got, err)
+	}
+
+	if _, err := client.SaveFact(ctx, &pb.SaveFactRequest{UserId: "alice"}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("SaveFact without a key = %v, want InvalidArgument", err)
+	}
+	if _, err := client.DeleteFact(ctx, &pb.DeleteFactRequest{UserId: "alice", Key: "pinned"}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("DeleteFact of a pinned fact = %v, want FailedPrecondition", err)
+	}
+}
+
+func TestGRPCSearchVectorsStream(t *testing.T) {
+	st := &grpcStorage{}
+	client := newTestGRPC(t, st, nil, nil)
+	ctx := context.Background()
+
+	meta, _ := structpb.NewStruct(map[string]interface{}{"topic": "go"})
+	for _, content := range []string{"first", "second", "third"} {
+		if _, err := client.AddVector(ctx, &pb.AddVectorRequest{UserId: "alice", Content: content, Metadata: meta}); err != nil {
+			t.Fatalf("AddVector %s: %v", content, err)
+		}
+	}
+
+	stream, err := client.SearchVectors(ctx, &pb.SearchRequest{UserId: "alice", Query: "anything", Limit: 2})
+	if err != nil {
+		t.Fatalf("SearchVectors: %v", err)
+	}
+	var contents []string
+	for {
+		r, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Recv: %v", err)
+		}
+		if r.GetMetadata().AsMap()["topic"] != "go" {
+			t.Errorf("result %s metadata = %v, want topic go", r.GetId(), r.GetMetadata())
+		}
+		contents = append(contents, r.GetContent())
+	}
+	if len(contents) != 2 || contents[0] != "first" || contents[1] != "second" {
+		t.Errorf("streamed %v, want the first two memories", contents)
+	}
+
+	// Errors are reported when receiving from a stream
+	stream, err = client.SearchVectors(ctx, &pb.SearchRequest{UserId: "alice"})
+	if err == nil {
+		_, err = stream.Recv()
+	}
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("SearchVectors without a query = %v, want InvalidArgument", err)
+	}
+}
+
+func TestGRPCRedaction(t *testing.T) {
+	redactor, err := redact.New(redact.ModeReject, []string{redact.RuleEmail}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	st := &grpcStorage{}
+	client := newTestGRPC(t, st, redactor, nil)
+
+	_, err = client.AddVector(context.Background(), &pb.AddVectorRequest{UserId: "alice", Content: "mail alice@example.com"})
+	if status.Code(err) != codes.InvalidArgument || len(st.vectors) != 0 {
+		t.Errorf("AddVector with an email = %v (%d stored), want InvalidArgument and nothing stored", err, len(st.vectors))
+	}
+}
+
+func TestGRPCTenantAuth(t *testing.T) {
+	tenants, err := tenancy.NewRegistry([]tenancy.Tenant{{ID: "team", Key: "secret-key", Namespace: "ns", Database: "db"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	st := &grpcStorage{facts: map[string]interface{}{}}
+	client := newTestGRPC(t, st, nil, tenants)
+	req := &pb.SaveFactRequest{UserId: "alice", Key: "editor", Value: structpb.NewStringValue("vim")}
+
+	for _, md := range []metadata.MD{nil, metadata.Pairs("x-api-key", "wrong")} {
+		ctx := metadata.NewOutgoingContext(context.Background(), md)
+		if _, err := client.SaveFact(ctx, req); status.Code(err) != codes.Unauthenticated {
+			t.Errorf("SaveFact with metadata %v = %v, want Unauthenticated", md, err)
+		}
+	}
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer secret-key")
+	if _, err := client.SaveFact(ctx, req); err != nil || st.tenant != "team" {
+		t.Errorf("SaveFact with a bearer key = %v (tenant %q), want it stored for team", err, st.tenant)
+	}
+
+	// Streams are authenticated as well
+	stream, err := client.SearchVectors(context.Background(), &pb.SearchRequest{UserId: "alice", Query: "x"})
+	if err == nil {
+		_, err = stream.Recv()
+	}
+	if status.Code(err) != codes.Unauthenticated {
+		t.Errorf("SearchVectors without a key = %v, want Unauthenticated", err)
+	}
+}
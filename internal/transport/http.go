@@ -12,6 +12,7 @@ import (
 	"github.com/ThinkInAIXYZ/go-mcp/protocol"
 	mcpserver "github.com/ThinkInAIXYZ/go-mcp/server"
 	"github.com/madeindigio/remembrances-mcp/pkg/modules"
+	"github.com/madeindigio/remembrances-mcp/pkg/tenancy"
 )
 
 const (
@@ -22,7 +23,7 @@ const (
 	headerCORSHeaders = "Access-Control-Allow-Headers"
 	corsMethods       = "GET, POST, OPTIONS"
 	corsOrigin        = "*"
-	corsHeaders       = "Content-Type"
+	corsHeaders       = "Content-Type, Authorization, X-API-Key"
 )
 
 // HTTPTransport implements a simple HTTP JSON API transport for MCP
@@ -49,6 +50,14 @@ func NewHTTPTransport(addr string) *HTTPTransport {
 	return transport
 }
 
+// SetTenants requires the API key of a tenant on every route but /health and
+// serves each request from that tenant's database.
+func (h *HTTPTransport) SetTenants(tenants *tenancy.Registry) {
+	if tenants.Enabled() {
+		h.server.Handler = tenants.Middleware(h.mux, "/health")
+	}
+}
+
 // SetupMCPRoutes configures the HTTP routes for MCP protocol
 func (h *HTTPTransport) SetupMCPRoutes(mcpServer *mcpserver.Server) error {
 	h.mux.HandleFunc("/health", h.handleHealth)
@@ -70,7 +79,7 @@ func (h *HTTPTransport) handleListTools(mcpServer *mcpserver.Server) http.Handle
 			return
 		}
 
-		h.setCORSHeaders(w)
+		setCORSHeaders(w)
 		w.Header().Set(headerContentType, contentTypeJSON)
 
 		// For now, we'll return a basic tools list since we can't directly access the server's tools
@@ -98,7 +107,7 @@ func (h *HTTPTransport) handleListTools(mcpServer *mcpserver.Server) http.Handle
 func (h *HTTPTransport) handleCallTool(mcpServer *mcpserver.Server) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if r.Method == http.MethodOptions {
-			h.setCORSHeaders(w)
+			setCORSHeaders(w)
 			w.WriteHeader(http.StatusOK)
 			return
 		}
@@ -108,7 +117,7 @@ func (h *HTTPTransport) handleCallTool(mcpServer *mcpserver.Server) http.Handler
 			return
 		}
 
-		h.setCORSHeaders(w)
+		setCORSHeaders(w)
 		w.Header().Set(headerContentType, contentTypeJSON)
 
 		var callReq protocol.CallToolRequest
@@ -137,7 +146,7 @@ func (h *HTTPTransport) handleCallTool(mcpServer *mcpserver.Server) http.Handler
 	}
 }
 
-func (h *HTTPTransport) setCORSHeaders(w http.ResponseWriter) {
+func setCORSHeaders(w http.ResponseWriter) {
 	w.Header().Set(headerCORSOrigin, corsOrigin)
 	w.Header().Set(headerCORSMethods, corsMethods)
 	w.Header().Set(headerCORSHeaders, corsHeaders)
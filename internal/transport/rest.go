@@ -0,0 +1,265 @@
+package transport
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/ThinkInAIXYZ/go-mcp/protocol"
+	"github.com/madeindigio/remembrances-mcp/pkg/mcp_tools"
+	"github.com/madeindigio/remembrances-mcp/pkg/modules"
+)
+
+const (
+	// restBasePath prefixes every REST API route.
+	restBasePath = "/api/v1"
+	// maxRESTBodyBytes limits tool arguments, which may hold whole documents.
+	maxRESTBodyBytes = 32 << 20
+)
+
+// toolErrorStatus maps the code of a failed tool result to the HTTP status
+// of the response. Failed results with no known code are a 500.
+var toolErrorStatus = map[string]int{
+	mcp_tools.ErrCodeValidation:         http.StatusBadRequest,
+	mcp_tools.ErrCodeNotFound:           http.StatusNotFound,
+	mcp_tools.ErrCodeConflict:           http.StatusConflict,
+	mcp_tools.ErrCodePinned:             http.StatusConflict,
+	mcp_tools.ErrCodeStorageUnavailable: http.StatusServiceUnavailable,
+	mcp_tools.ErrCodeEmbedderFailed:     http.StatusBadGateway,
+	mcp_tools.ErrCodeInternal:           http.StatusInternalServerError,
+}
+
+// RESTAPI serves the MCP tools as REST/JSON endpoints for clients that do
+// not speak MCP, such as scripts, cron jobs and web apps. Calls go to the
+// same handlers the MCP server uses:
+//
+//	GET  /api/v1/tools          lists the tools and their input schemas
+//	POST /api/v1/tools/{name}   calls a tool with its arguments as the JSON body
+//	GET  /api/v1/openapi.json   OpenAPI 3 spec generated from the tool input schemas
+type RESTAPI struct {
+	tools   map[string]modules.ToolDefinition
+	names   []string
+	version string
+}
+
+// NewRESTAPI creates a REST API for the given tools. version is reported in
+// the OpenAPI spec.
+func NewRESTAPI(defs []modules.ToolDefinition, version string) *RESTAPI {
+	api := &RESTAPI{
+		tools:   make(map[string]modules.ToolDefinition, len(defs)),
+		version: version,
+	}
+	for _, def := range defs {
+		if def.Tool == nil || def.Handler == nil {
+			continue
+		}
+		if _, ok := api.tools[def.Tool.Name]; !ok {
+			api.names = append(api.names, def.Tool.Name)
+		}
+		api.tools[def.Tool.Name] = def
+	}
+	sort.Strings(api.names)
+	return api
+}
+
+// RegisterRESTAPI serves the REST API routes on the HTTP transport.
+func (h *HTTPTransport) RegisterRESTAPI(api *RESTAPI) {
+	h.mux.HandleFunc("GET "+restBasePath+"/tools", api.handleListTools)
+	h.mux.HandleFunc("POST "+restBasePath+"/tools/{name}", api.handleCallTool)
+	h.mux.HandleFunc("OPTIONS "+restBasePath+"/tools/{name}", api.handleOptions)
+	h.mux.HandleFunc("GET "+restBasePath+"/openapi.json", api.handleOpenAPI)
+	slog.Info("Registered REST API routes", "base_path", restBasePath, "tools", len(api.names))
+}
+
+func (a *RESTAPI) handleListTools(w http.ResponseWriter, r *http.Request) {
+	tools := make([]*protocol.Tool, 0, len(a.names))
+	for _, name := range a.names {
+		tools = append(tools, a.tools[name].Tool)
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"tools": tools})
+}
+
+func (a *RESTAPI) handleOptions(w http.ResponseWriter, r *http.Request) {
+	setCORSHeaders(w)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (a *RESTAPI) handleCallTool(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	def, ok := a.tools[name]
+	if !ok {
+		writeJSONError(w, http.StatusNotFound, fmt.Sprintf("unknown tool %q", name))
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxRESTBodyBytes))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			writeJSONError(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("request body exceeds %d bytes", tooLarge.Limit))
+			return
+		}
+		writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("failed to read request body: %v", err))
+		return
+	}
+	if len(strings.TrimSpace(string(body))) == 0 {
+		body = []byte("{}")
+	}
+
+	var args map[string]interface{}
+	if err := json.Unmarshal(body, &args); err != nil {
+		writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("request body must be a JSON object with the tool arguments: %v", err))
+		return
+	}
+
+	req := &protocol.CallToolRequest{
+		Name:         name,
+		Arguments:    args,
+		RawArguments: body,
+	}
+	result, err := def.Handler(r.Context(), req)
+	if err != nil {
+		slog.Warn("REST API tool call failed", "tool", name, "error", err)
+		writeJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, toolResultStatus(result), result)
+}
+
+// toolResultStatus returns the HTTP status for a tool result: 200 when it
+// succeeded, otherwise the status of its error code.
+func toolResultStatus(result *protocol.CallToolResult) int {
+	if result == nil || !result.IsError {
+		return http.StatusOK
+	}
+	if status, ok := toolErrorStatus[mcp_tools.ResultErrorCode(result)]; ok {
+		return status
+	}
+	return http.StatusInternalServerError
+}
+
+func (a *RESTAPI) handleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, a.OpenAPISpec())
+}
+
+// OpenAPISpec returns an OpenAPI 3 document describing one POST operation
+// per tool, with the tool input schema as the request body.
+func (a *RESTAPI) OpenAPISpec() map[string]interface{} {
+	// Requests the API rejects get an Error; calls the tool rejects get the
+	// failed ToolResult, whose code selects the status
+	response := func(description string, schemas ...string) map[string]interface{} {
+		refs := make([]interface{}, 0, len(schemas))
+		for _, schema := range schemas {
+			refs = append(refs, map[string]interface{}{"$ref": "#/components/schemas/" + schema})
+		}
+		schema := refs[0]
+		if len(refs) > 1 {
+			schema = map[string]interface{}{"oneOf": refs}
+		}
+		return map[string]interface{}{
+			"description": description,
+			"content": map[string]interface{}{
+				contentTypeJSON: map[string]interface{}{"schema": schema},
+			},
+		}
+	}
+
+	paths := map[string]interface{}{
+		restBasePath + "/tools": map[string]interface{}{
+			"get": map[string]interface{}{
+				"operationId": "listTools",
+				"summary":     "List the available tools and their input schemas",
+				"responses": map[string]interface{}{
+					"200": map[string]interface{}{"description": "Available tools"},
+				},
+			},
+		},
+	}
+
+	for _, name := range a.names {
+		tool := a.tools[name].Tool
+		var schema interface{} = tool.InputSchema
+		if len(tool.RawInputSchema) > 0 {
+			schema = tool.RawInputSchema
+		}
+		summary, _, _ := strings.Cut(tool.Description, ". ")
+		paths[restBasePath+"/tools/"+name] = map[string]interface{}{
+			"post": map[string]interface{}{
+				"operationId": name,
+				"summary":     strings.TrimSuffix(summary, "."),
+				"description": tool.Description,
+				"requestBody": map[string]interface{}{
+					"required": true,
+					"content": map[string]interface{}{
+						contentTypeJSON: map[string]interface{}{"schema": schema},
+					},
+				},
+				"responses": map[string]interface{}{
+					"200": response("Tool result; text content is TOON encoded as in MCP responses", "ToolResult"),
+					"400": response("Request body is not a JSON object, or the tool rejected the arguments (VALIDATION)", "Error", "ToolResult"),
+					"404": response("Unknown tool, or the referenced record does not exist (NOT_FOUND)", "Error", "ToolResult"),
+					"409": response("The record changed concurrently or is pinned (CONFLICT, PINNED)", "ToolResult"),
+					"500": response("The tool failed (INTERNAL)", "Error", "ToolResult"),
+					"502": response("The embedding model failed (EMBEDDER_FAILED)", "ToolResult"),
+					"503": response("The database is unavailable (STORAGE_UNAVAILABLE)", "ToolResult"),
+				},
+			},
+		}
+	}
+
+	return map[string]interface{}{
+		"openapi": "3.0.3",
+		"info": map[string]interface{}{
+			"title":       "Remembrances MCP REST API",
+			"description": "REST/JSON access to the Remembrances MCP tools. Each tool is called with a POST of its arguments.",
+			"version":     a.version,
+		},
+		"paths": paths,
+		"components": map[string]interface{}{
+			"schemas": map[string]interface{}{
+				"ToolResult": map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"content": map[string]interface{}{
+							"type": "array",
+							"items": map[string]interface{}{
+								"type": "object",
+								"properties": map[string]interface{}{
+									"type": map[string]interface{}{"type": "string"},
+									"text": map[string]interface{}{"type": "string"},
+								},
+							},
+						},
+						"isError": map[string]interface{}{"type": "boolean"},
+					},
+				},
+				"Error": map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"error": map[string]interface{}{"type": "string"},
+					},
+				},
+			},
+		},
+	}
+}
+
+// writeJSON writes v as a JSON response with CORS headers.
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	setCORSHeaders(w)
+	w.Header().Set(headerContentType, contentTypeJSON)
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.Error("failed to encode REST API response", "error", err)
+	}
+}
+
+// writeJSONError writes an {"error": message} JSON response.
+func writeJSONError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
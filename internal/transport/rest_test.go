@@ -0,0 +1,180 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ThinkInAIXYZ/go-mcp/protocol"
+	"github.com/madeindigio/remembrances-mcp/pkg/mcp_tools"
+	"github.com/madeindigio/remembrances-mcp/pkg/modules"
+)
+
+type echoInput struct {
+	Text string `json:"text" description:"Text to echo" required:"true"`
+}
+
+func newTestRESTAPI(t *testing.T) (*RESTAPI, *httptest.Server) {
+	t.Helper()
+	echo, err := protocol.NewTool("echo", "Echo the text. Used by tests.", echoInput{})
+	if err != nil {
+		t.Fatalf("NewTool: %v", err)
+	}
+	textResult := func(text string, isError bool) *protocol.CallToolResult {
+		return protocol.NewCallToolResult([]protocol.Content{&protocol.TextContent{Type: "text", Text: text}}, isError)
+	}
+
+	api := NewRESTAPI([]modules.ToolDefinition{
+		{Tool: echo, Handler: func(ctx context.Context, req *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+			return textResult(fmt.Sprint(req.Arguments["text"]), false), nil
+		}},
+		// Fails with the code in its arguments, like handlers wrapped by the tool manager
+		{Tool: protocol.NewToolWithRawSchema("fail", "Fail with a code.", json.RawMessage(`{"type":"object","properties":{"code":{"type":"string"}}}`)),
+			Handler: func(ctx context.Context, req *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+				code, _ := req.Arguments["code"].(string)
+				return textResult(mcp_tools.MarshalTOON(map[string]interface{}{"code": code, "message": "it failed"}), true), nil
+			}},
+		{Tool: &protocol.Tool{Name: "no_handler"}},
+	}, "test")
+
+	h := NewHTTPTransport("")
+	h.RegisterRESTAPI(api)
+	server := httptest.NewServer(h.mux)
+	t.Cleanup(server.Close)
+	return api, server
+}
+
+func postTool(t *testing.T, server *httptest.Server, name, body string) (int, map[string]interface{}) {
+	t.Helper()
+	resp, err := http.Post(server.URL+restBasePath+"/tools/"+name, contentTypeJSON, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("POST %s: %v", name, err)
+	}
+	defer resp.Body.Close()
+	var decoded map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&decoded); err != nil {
+		t.Fatalf("decode %s response: %v", name, err)
+	}
+	return resp.StatusCode, decoded
+}
+
+func TestRESTAPICallTool(t *testing.T) {
+	_, server := newTestRESTAPI(t)
+
+	status, body := postTool(t, server, "echo", `{"text": "hello"}`)
+	content, _ := body["content"].([]interface{})
+	if status != http.StatusOK || len(content) != 1 || content[0].(map[string]interface{})["text"] != "hello" {
+		t.Errorf("echo = %d %v, want 200 with hello", status, body)
+	}
+
+	for _, name := range []string{"missing", "no_handler"} {
+		if status, body := postTool(t, server, name, `{}`); status != http.StatusNotFound || body["error"] == nil {
+			t.Errorf("%s = %d %v, want 404 with an error", name, status, body)
+		}
+	}
+}
+
+func TestRESTAPIMalformedBody(t *testing.T) {
+	_, server := newTestRESTAPI(t)
+
+	for _, body := range []string{`{"text": `, `["hello"]`, `"hello"`} {
+		status, decoded := postTool(t, server, "echo", body)
+		message, _ := decoded["error"].(string)
+		if status != http.StatusBadRequest || !strings.Contains(message, "must be a JSON object") {
+			t.Errorf("body %s = %d %v, want 400 with a JSON object error", body, status, decoded)
+		}
+	}
+
+	// An empty body calls the tool with no arguments
+	if status, _ := postTool(t, server, "echo", ""); status != http.StatusOK {
+		t.Errorf("empty body = %d, want 200", status)
+	}
+}
+
+func TestRESTAPIErrorCodeStatus(t *testing.T) {
+	_, server := newTestRESTAPI(t)
+
+	tests := []struct {
+		code   string
+		status int
+	}{
+		{mcp_tools.ErrCodeValidation, http.StatusBadRequest},
+		{mcp_tools.ErrCodeNotFound, http.StatusNotFound},
+		{mcp_tools.ErrCodeConflict, http.StatusConflict},
+		{mcp_tools.ErrCodePinned, http.StatusConflict},
+		{mcp_tools.ErrCodeStorageUnavailable, http.StatusServiceUnavailable},
+		{mcp_tools.ErrCodeEmbedderFailed, http.StatusBadGateway},
+		{mcp_tools.ErrCodeInternal, http.StatusInternalServerError},
+		{"", http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		status, body := postTool(t, server, "fail", fmt.Sprintf(`{"code": %q}`, tt.code))
+		if status != tt.status || body["isError"] != true {
+			t.Errorf("code %q = %d %v, want %d with the failed result", tt.code, status, body, tt.status)
+		}
+	}
+}
+
+func TestRESTAPIOpenAPISpec(t *testing.T) {
+	api, server := newTestRESTAPI(t)
+
+	resp, err := http.Get(server.URL + restBasePath + "/openapi.json")
+	if err != nil {
+		t.Fatalf("GET openapi.json: %v", err)
+	}
+	defer resp.Body.Close()
+	var spec struct {
+		Paths map[string]struct {
+			Post *struct {
+				RequestBody struct {
+					Content map[string]struct {
+						Schema interface{} `json:"schema"`
+					} `json:"content"`
+				} `json:"requestBody"`
+			} `json:"post"`
+		} `json:"paths"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&spec); err != nil {
+		t.Fatalf("decode spec: %v", err)
+	}
+
+	var operations []string
+	for path, item := range spec.Paths {
+		if item.Post != nil {
+			operations = append(operations, strings.TrimPrefix(path, restBasePath+"/tools/"))
+		}
+	}
+	if len(operations) != len(api.names) {
+		t.Errorf("spec has operations %v, want one per tool %v", operations, api.names)
+	}
+
+	for _, name := range api.names {
+		item, ok := spec.Paths[restBasePath+"/tools/"+name]
+		if !ok || item.Post == nil {
+			t.Errorf("spec has no operation for %s", name)
+			continue
+		}
+		tool := api.tools[name].Tool
+		var schema interface{} = tool.InputSchema
+		if len(tool.RawInputSchema) > 0 {
+			schema = tool.RawInputSchema
+		}
+		raw, _ := json.Marshal(schema)
+		var want interface{}
+		_ = json.Unmarshal(raw, &want)
+		if got := item.Post.RequestBody.Content[contentTypeJSON].Schema; !reflect.DeepEqual(got, want) {
+			t.Errorf("%s request schema = %v, want %v", name, got, want)
+		}
+	}
+	if item := spec.Paths[restBasePath+"/tools/echo"]; item.Post != nil {
+		schema, _ := item.Post.RequestBody.Content[contentTypeJSON].Schema.(map[string]interface{})
+		if props, _ := schema["properties"].(map[string]interface{}); props["text"] == nil {
+			t.Errorf("echo request schema = %v, want its text property", schema)
+		}
+	}
+}
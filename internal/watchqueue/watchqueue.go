@@ -0,0 +1,191 @@
+// Package watchqueue coalesces file watcher events and paces their processing,
+// so bursts of changes (branch switches, dependency installs, formatters run
+// over a whole tree) do not trigger one reindex per event.
+package watchqueue
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Op is the pending operation for a file.
+type Op int
+
+const (
+	// OpChange means the file was created or written and must be reindexed.
+	OpChange Op = iota
+	// OpRemove means the file was removed or renamed away.
+	OpRemove
+)
+
+// Config controls debouncing and the processing budget.
+type Config struct {
+	// Debounce is the quiet period a file must have before it is processed.
+	Debounce time.Duration
+
+	// MaxWait bounds the delay since the first event of a file, so files
+	// written continuously are still processed.
+	MaxWait time.Duration
+
+	// MaxPerMinute is the number of files processed per minute; files over
+	// the budget stay queued. 0 disables the limit.
+	MaxPerMinute int
+}
+
+// DefaultConfig returns the settings used when none are configured.
+func DefaultConfig() Config {
+	return Config{
+		Debounce:     500 * time.Millisecond,
+		MaxWait:      10 * time.Second,
+		MaxPerMinute: 120,
+	}
+}
+
+// Event is a coalesced file event ready to be processed.
+type Event struct {
+	Path string
+	Op   Op
+}
+
+type pending struct {
+	op    Op
+	first time.Time
+	last  time.Time
+}
+
+// Queue collects file events and releases them once they have settled.
+// It is safe for concurrent use.
+type Queue struct {
+	mu        sync.Mutex
+	cfg       Config
+	pending   map[string]*pending
+	processed []time.Time
+	throttled bool
+}
+
+// New creates a queue. Zero Debounce and MaxWait take their defaults.
+func New(cfg Config) *Queue {
+	defaults := DefaultConfig()
+	if cfg.Debounce <= 0 {
+		cfg.Debounce = defaults.Debounce
+	}
+	if cfg.MaxWait < cfg.Debounce {
+		cfg.MaxWait = max(defaults.MaxWait, cfg.Debounce)
+	}
+	if cfg.MaxPerMinute < 0 {
+		cfg.MaxPerMinute = 0
+	}
+	return &Queue{
+		cfg:     cfg,
+		pending: make(map[string]*pending),
+	}
+}
+
+// Add records an event for path. Repeated events for the same file are
+// merged and the latest operation wins.
+func (q *Queue) Add(path string, op Op, now time.Time) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if p, ok := q.pending[path]; ok {
+		p.op = op
+		p.last = now
+		return
+	}
+	q.pending[path] = &pending{op: op, first: now, last: now}
+}
+
+// Ready removes and returns the events that have settled, in path order,
+// within the remaining budget for the current minute. Settled events over
+// the budget stay queued for a later call.
+func (q *Queue) Ready(now time.Time) []Event {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var due []string
+	for path, p := range q.pending {
+		if now.Sub(p.last) >= q.cfg.Debounce || now.Sub(p.first) >= q.cfg.MaxWait {
+			due = append(due, path)
+		}
+	}
+	if len(due) == 0 {
+		return nil
+	}
+	sort.Strings(due)
+
+	budget := len(due)
+	if q.cfg.MaxPerMinute > 0 {
+		q.expire(now)
+		budget = min(budget, q.cfg.MaxPerMinute-len(q.processed))
+	}
+	q.throttled = budget < len(due)
+
+	events := make([]Event, 0, budget)
+	for _, path := range due[:budget] {
+		events = append(events, Event{Path: path, Op: q.pending[path].op})
+		delete(q.pending, path)
+		if q.cfg.MaxPerMinute > 0 {
+			q.processed = append(q.processed, now)
+		}
+	}
+	return events
+}
+
+// expire drops processing times older than a minute.
+func (q *Queue) expire(now time.Time) {
+	cutoff := now.Add(-time.Minute)
+	i := 0
+	for i < len(q.processed) && !q.processed[i].After(cutoff) {
+		i++
+	}
+	q.processed = q.processed[i:]
+}
+
+// Drain removes and returns every pending event, in path order, whether it
+// has settled or not and regardless of the budget. It is used to flush the
+// queue before stopping.
+func (q *Queue) Drain() []Event {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	paths := make([]string, 0, len(q.pending))
+	for path := range q.pending {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	events := make([]Event, 0, len(paths))
+	for _, path := range paths {
+		events = append(events, Event{Path: path, Op: q.pending[path].op})
+	}
+	q.pending = make(map[string]*pending)
+	return events
+}
+
+// Pending returns the number of files waiting to be processed.
+func (q *Queue) Pending() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.pending)
+}
+
+// PendingPaths returns the files waiting to be processed, sorted.
+func (q *Queue) PendingPaths() []string {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	paths := make([]string, 0, len(q.pending))
+	for path := range q.pending {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// Throttled reports whether the last call to Ready held back settled
+// events because the per-minute budget was spent.
+func (q *Queue) Throttled() bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.throttled
+}
@@ -0,0 +1,98 @@
+package watchqueue
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestQueue_CoalescesAndDebounces(t *testing.T) {
+	q := New(Config{Debounce: time.Second, MaxWait: 5 * time.Second})
+	start := time.Now()
+
+	q.Add("a.go", OpChange, start)
+	q.Add("a.go", OpChange, start.Add(500*time.Millisecond))
+	q.Add("b.go", OpChange, start)
+	q.Add("b.go", OpRemove, start.Add(100*time.Millisecond))
+
+	if got := q.Ready(start.Add(900 * time.Millisecond)); len(got) != 0 {
+		t.Fatalf("Ready() before debounce = %v, want none", got)
+	}
+
+	got := q.Ready(start.Add(1200 * time.Millisecond))
+	want := []Event{{Path: "b.go", Op: OpRemove}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Ready() = %v, want %v", got, want)
+	}
+
+	got = q.Ready(start.Add(1600 * time.Millisecond))
+	want = []Event{{Path: "a.go", Op: OpChange}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Ready() = %v, want %v", got, want)
+	}
+	if q.Pending() != 0 {
+		t.Fatalf("Pending() = %d, want 0", q.Pending())
+	}
+}
+
+func TestQueue_MaxWait(t *testing.T) {
+	q := New(Config{Debounce: time.Second, MaxWait: 3 * time.Second})
+	start := time.Now()
+
+	// A file written every half second never settles but is still released
+	for i := 0; i <= 6; i++ {
+		q.Add("log.go", OpChange, start.Add(time.Duration(i)*500*time.Millisecond))
+	}
+	if got := q.Ready(start.Add(3 * time.Second)); len(got) != 1 {
+		t.Fatalf("Ready() at MaxWait = %v, want one event", got)
+	}
+}
+
+func TestQueue_Budget(t *testing.T) {
+	q := New(Config{Debounce: time.Millisecond, MaxPerMinute: 2})
+	start := time.Now()
+
+	for _, path := range []string{"c.go", "a.go", "b.go"} {
+		q.Add(path, OpChange, start)
+	}
+
+	now := start.Add(time.Second)
+	got := q.Ready(now)
+	want := []Event{{Path: "a.go", Op: OpChange}, {Path: "b.go", Op: OpChange}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Ready() = %v, want %v", got, want)
+	}
+	if !q.Throttled() {
+		t.Fatalf("Throttled() = false, want true")
+	}
+
+	if got := q.Ready(now.Add(30 * time.Second)); len(got) != 0 {
+		t.Fatalf("Ready() within the minute = %v, want none", got)
+	}
+
+	got = q.Ready(now.Add(61 * time.Second))
+	want = []Event{{Path: "c.go", Op: OpChange}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Ready() after the minute = %v, want %v", got, want)
+	}
+	if q.Throttled() {
+		t.Fatalf("Throttled() = true after the queue drained")
+	}
+}
+
+func TestQueue_Drain(t *testing.T) {
+	q := New(Config{Debounce: time.Minute, MaxPerMinute: 1})
+	start := time.Now()
+
+	q.Add("b.go", OpChange, start)
+	q.Add("a.go", OpRemove, start)
+
+	got := q.Drain()
+	want := []Event{{Path: "a.go", Op: OpRemove}, {Path: "b.go", Op: OpChange}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Drain() = %v, want %v", got, want)
+	}
+	if q.Pending() != 0 {
+		t.Fatalf("Pending() after Drain = %d, want 0", q.Pending())
+	}
+}
@@ -57,6 +57,14 @@ func (m *CodeIndexingToolsModule) Provision(ctx context.Context, cfg modules.Mod
 		cfg.KnowledgeBasePath,
 	)
 	baseManager.SetKBChunking(cfg.KBChunkSize, cfg.KBChunkOverlap)
+	baseManager.SetKBChunkStrategy(cfg.KBChunkStrategy)
+	baseManager.SetDuplicateThreshold(cfg.DuplicateThreshold)
+	baseManager.SetLLM(cfg.LLM)
+	baseManager.SetConsolidationThreshold(cfg.ConsolidationThreshold)
+	baseManager.SetPurgeArchiveDir(cfg.PurgeArchiveDir)
+	baseManager.SetRedactor(cfg.Redactor)
+	baseManager.SetKBRoots(cfg.KBRoots)
+	baseManager.SetEmbeddingRouter(cfg.EmbeddingRouter)
 
 	indexerConfig := cfg.IndexerConfig
 	if indexerConfig == (indexer.IndexerConfig{}) {
@@ -108,6 +116,21 @@ func (m *CodeIndexingToolsModule) Cleanup() error {
 	return nil
 }
 
+// Shutdown lets the watcher and the running indexing jobs finish the files
+// they are writing, within ctx, then stops them.
+func (m *CodeIndexingToolsModule) Shutdown(ctx context.Context) error {
+	var err error
+	if m.watcherManager != nil {
+		err = m.watcherManager.Shutdown(ctx)
+	}
+	if m.jobManager != nil {
+		if jobErr := m.jobManager.Shutdown(ctx); err == nil {
+			err = jobErr
+		}
+	}
+	return err
+}
+
 // Tools returns the tool definitions.
 func (m *CodeIndexingToolsModule) Tools() []modules.ToolDefinition {
 	return m.tools
@@ -47,6 +47,8 @@ func (m *CodeManipulationToolsModule) Provision(ctx context.Context, cfg modules
 	}
 
 	m.toolManager = mcp_tools.NewCodeManipulationToolManager(cfg.Storage, codeEmbedder)
+	m.toolManager.SetEmbeddingRouter(cfg.EmbeddingRouter)
+	m.toolManager.SetCheckCommands(cfg.CodeCheckCommands)
 
 	var tools []modules.ToolDefinition
 	reg := func(name string, tool *protocol.Tool, handler func(context.Context, *protocol.CallToolRequest) (*protocol.CallToolResult, error)) error {
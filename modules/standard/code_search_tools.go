@@ -47,6 +47,9 @@ func (m *CodeSearchToolsModule) Provision(ctx context.Context, cfg modules.Modul
 	}
 
 	m.toolManager = mcp_tools.NewCodeSearchToolManager(cfg.Storage, codeEmbedder)
+	m.toolManager.SetEmbeddingRouter(cfg.EmbeddingRouter)
+	m.toolManager.SetMaxOutputBytes(cfg.MaxOutputBytes)
+	m.toolManager.SetScoring(cfg.MinSimilarity, cfg.ScoreNormalization)
 
 	var tools []modules.ToolDefinition
 	reg := func(name string, tool *protocol.Tool, handler func(context.Context, *protocol.CallToolRequest) (*protocol.CallToolResult, error)) error {
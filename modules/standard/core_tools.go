@@ -17,6 +17,7 @@ func init() {
 type CoreToolsModule struct {
 	toolManager *mcp_tools.ToolManager
 	tools       []modules.ToolDefinition
+	cancel      context.CancelFunc
 }
 
 // ModuleInfo returns module metadata.
@@ -48,6 +49,30 @@ func (m *CoreToolsModule) Provision(ctx context.Context, cfg modules.ModuleConfi
 		cfg.KnowledgeBasePath,
 	)
 	m.toolManager.SetKBChunking(cfg.KBChunkSize, cfg.KBChunkOverlap)
+	m.toolManager.SetKBChunkStrategy(cfg.KBChunkStrategy)
+	m.toolManager.SetDuplicateThreshold(cfg.DuplicateThreshold)
+	m.toolManager.SetLLM(cfg.LLM)
+	m.toolManager.SetConsolidationThreshold(cfg.ConsolidationThreshold)
+	m.toolManager.SetPurgeArchiveDir(cfg.PurgeArchiveDir)
+	m.toolManager.SetRedactor(cfg.Redactor)
+	m.toolManager.SetEmbeddingRouter(cfg.EmbeddingRouter)
+	m.toolManager.SetKBRoots(cfg.KBRoots)
+	m.toolManager.SetKBWatchers(cfg.KBWatchers)
+	m.toolManager.SetEmbeddingModel(cfg.EmbeddingModel)
+	m.toolManager.SetProgressNotifier(cfg.ProgressNotifier)
+	m.toolManager.SetScoring(cfg.MinSimilarity, cfg.ScoreNormalization)
+	m.toolManager.SetLogLevels(cfg.LogLevels)
+	m.toolManager.SetWorkingMemoryTTL(cfg.WorkingMemoryTTL)
+	m.toolManager.SetSystemInfo(cfg.StorageBackend, func() []string {
+		if cfg.LoadedModules == nil {
+			return nil
+		}
+		var toolsets []string
+		for _, id := range cfg.LoadedModules() {
+			toolsets = append(toolsets, string(id))
+		}
+		return toolsets
+	})
 
 	var tools []modules.ToolDefinition
 	reg := func(name string, tool *protocol.Tool, handler func(context.Context, *protocol.CallToolRequest) (*protocol.CallToolResult, error)) error {
@@ -63,6 +88,19 @@ func (m *CoreToolsModule) Provision(ctx context.Context, cfg modules.ModuleConfi
 	}
 
 	m.tools = tools
+
+	schedulerCtx, cancel := context.WithCancel(context.Background())
+	m.cancel = cancel
+	m.toolManager.StartSavedSearchScheduler(schedulerCtx)
+	m.toolManager.StartWorkingMemoryJanitor(schedulerCtx)
+	return nil
+}
+
+// Cleanup stops the scheduled saved searches and the expiry of working memory.
+func (m *CoreToolsModule) Cleanup() error {
+	if m.cancel != nil {
+		m.cancel()
+	}
 	return nil
 }
 
@@ -48,6 +48,14 @@ func (m *FactToolsModule) Provision(ctx context.Context, cfg modules.ModuleConfi
 		cfg.KnowledgeBasePath,
 	)
 	m.toolManager.SetKBChunking(cfg.KBChunkSize, cfg.KBChunkOverlap)
+	m.toolManager.SetKBChunkStrategy(cfg.KBChunkStrategy)
+	m.toolManager.SetDuplicateThreshold(cfg.DuplicateThreshold)
+	m.toolManager.SetLLM(cfg.LLM)
+	m.toolManager.SetConsolidationThreshold(cfg.ConsolidationThreshold)
+	m.toolManager.SetPurgeArchiveDir(cfg.PurgeArchiveDir)
+	m.toolManager.SetRedactor(cfg.Redactor)
+	m.toolManager.SetEmbeddingRouter(cfg.EmbeddingRouter)
+	m.toolManager.SetKBRoots(cfg.KBRoots)
 
 	var tools []modules.ToolDefinition
 	reg := func(name string, tool *protocol.Tool, handler func(context.Context, *protocol.CallToolRequest) (*protocol.CallToolResult, error)) error {
@@ -17,6 +17,7 @@ func init() {
 type KBToolsModule struct {
 	toolManager *mcp_tools.ToolManager
 	tools       []modules.ToolDefinition
+	cancel      context.CancelFunc
 }
 
 // ModuleInfo returns module metadata.
@@ -48,6 +49,19 @@ func (m *KBToolsModule) Provision(ctx context.Context, cfg modules.ModuleConfig)
 		cfg.KnowledgeBasePath,
 	)
 	m.toolManager.SetKBChunking(cfg.KBChunkSize, cfg.KBChunkOverlap)
+	m.toolManager.SetKBChunkStrategy(cfg.KBChunkStrategy)
+	m.toolManager.SetDuplicateThreshold(cfg.DuplicateThreshold)
+	m.toolManager.SetLLM(cfg.LLM)
+	m.toolManager.SetConsolidationThreshold(cfg.ConsolidationThreshold)
+	m.toolManager.SetPurgeArchiveDir(cfg.PurgeArchiveDir)
+	m.toolManager.SetRedactor(cfg.Redactor)
+	m.toolManager.SetEmbeddingRouter(cfg.EmbeddingRouter)
+	m.toolManager.SetKBRoots(cfg.KBRoots)
+	m.toolManager.SetKBWatchers(cfg.KBWatchers)
+	m.toolManager.SetURLPrivateNetworks(cfg.KBURLPrivateNetworks)
+	m.toolManager.SetEmbeddingModel(cfg.EmbeddingModel)
+	m.toolManager.SetMaxOutputBytes(cfg.MaxOutputBytes)
+	m.toolManager.SetScoring(cfg.MinSimilarity, cfg.ScoreNormalization)
 
 	var tools []modules.ToolDefinition
 	reg := func(name string, tool *protocol.Tool, handler func(context.Context, *protocol.CallToolRequest) (*protocol.CallToolResult, error)) error {
@@ -63,6 +77,20 @@ func (m *KBToolsModule) Provision(ctx context.Context, cfg modules.ModuleConfig)
 	}
 
 	m.tools = tools
+
+	if cfg.KBRecrawlInterval > 0 {
+		recrawlCtx, cancel := context.WithCancel(context.Background())
+		m.cancel = cancel
+		m.toolManager.StartKBRecrawl(recrawlCtx, cfg.KBRecrawlInterval)
+	}
+	return nil
+}
+
+// Cleanup stops the scheduled re-crawl of URL documents.
+func (m *KBToolsModule) Cleanup() error {
+	if m.cancel != nil {
+		m.cancel()
+	}
 	return nil
 }
 
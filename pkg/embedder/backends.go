@@ -0,0 +1,72 @@
+package embedder
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// BackendFactory creates an embedder of a backend added by a module, from
+// the options of the named embedder using it.
+type BackendFactory func(options map[string]any) (Embedder, error)
+
+// builtinBackends are configured with their own keys and cannot be
+// registered by modules.
+var builtinBackends = map[string]bool{"gguf": true, "ollama": true, "openai": true}
+
+var (
+	backendsMu sync.RWMutex
+	backends   = map[string]BackendFactory{}
+)
+
+// RegisterBackend makes a backend available to named embedders, which select
+// it with backend: name. Modules register their backends at init time; it
+// panics when the name is taken.
+func RegisterBackend(name string, factory BackendFactory) {
+	if name == "" || factory == nil {
+		panic("embedder backend name and factory are required")
+	}
+	if builtinBackends[name] {
+		panic(fmt.Sprintf("embedder backend %q is built in", name))
+	}
+	backendsMu.Lock()
+	defer backendsMu.Unlock()
+	if _, exists := backends[name]; exists {
+		panic(fmt.Sprintf("embedder backend already registered: %s", name))
+	}
+	backends[name] = factory
+}
+
+// Backends returns the names of the registered backends, sorted.
+func Backends() []string {
+	backendsMu.RLock()
+	defer backendsMu.RUnlock()
+	names := make([]string, 0, len(backends))
+	for name := range backends {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// backendFactory returns the factory of a registered backend.
+func backendFactory(name string) (BackendFactory, bool) {
+	backendsMu.RLock()
+	defer backendsMu.RUnlock()
+	factory, ok := backends[name]
+	return factory, ok
+}
+
+// newBackendEmbedder creates the embedder of a named embedder using a
+// registered backend.
+func newBackendEmbedder(named NamedConfig) (Embedder, error) {
+	factory, ok := backendFactory(named.Backend)
+	if !ok {
+		return nil, fmt.Errorf("embedder %q: unknown backend %q", named.Name, named.Backend)
+	}
+	options := named.Options
+	if options == nil {
+		options = map[string]any{}
+	}
+	return factory(options)
+}
@@ -0,0 +1,81 @@
+package embedder
+
+import (
+	"context"
+	"testing"
+)
+
+// optionsEmbedder is created by the "test-backend" backend and keeps the
+// options it was created with
+type optionsEmbedder struct {
+	options map[string]any
+}
+
+func (e *optionsEmbedder) EmbedDocuments(ctx context.Context, texts []string) ([][]float32, error) {
+	return make([][]float32, len(texts)), nil
+}
+func (e *optionsEmbedder) EmbedQuery(ctx context.Context, text string) ([]float32, error) {
+	return nil, nil
+}
+func (e *optionsEmbedder) Dimension() int { return 384 }
+
+func init() {
+	RegisterBackend("test-backend", func(options map[string]any) (Embedder, error) {
+		return &optionsEmbedder{options: options}, nil
+	})
+}
+
+func TestRegisterBackendRejectsTakenNames(t *testing.T) {
+	for _, name := range []string{"ollama", "test-backend"} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("RegisterBackend(%q) did not panic", name)
+				}
+			}()
+			RegisterBackend(name, func(map[string]any) (Embedder, error) { return nil, nil })
+		}()
+	}
+}
+
+func TestRouterUsesRegisteredBackend(t *testing.T) {
+	cfg := newMockRoutingConfig(map[string]string{RouteEvents: "tei"})
+	cfg.embedders = append(cfg.embedders, NamedConfig{
+		Name:    "tei",
+		Backend: "test-backend",
+		Options: map[string]any{"url": "http://localhost:8080", "model": "bge-small-en"},
+	})
+
+	models, err := RouteModelNames(cfg)
+	if err != nil {
+		t.Fatalf("RouteModelNames() error = %v", err)
+	}
+	if got := models[RouteEvents]; got != "test-backend:bge-small-en" {
+		t.Errorf("events model = %q, want test-backend:bge-small-en", got)
+	}
+
+	def, err := NewOllamaEmbedder("http://localhost:11434", "nomic-embed-text")
+	if err != nil {
+		t.Fatalf("NewOllamaEmbedder() error = %v", err)
+	}
+	router, err := NewRouterFromMainConfig(cfg, def, nil)
+	if err != nil {
+		t.Fatalf("NewRouterFromMainConfig() error = %v", err)
+	}
+	emb, ok := router.For(RouteEvents).(*optionsEmbedder)
+	if !ok {
+		t.Fatalf("events embedder = %T, want the backend's embedder", router.For(RouteEvents))
+	}
+	if emb.options["url"] != "http://localhost:8080" {
+		t.Errorf("backend options = %v", emb.options)
+	}
+
+	unknown := NamedConfig{Name: "x", Backend: "missing"}
+	if err := unknown.Validate(); err == nil {
+		t.Error("Validate() with an unregistered backend succeeded, want error")
+	}
+	both := NamedConfig{Name: "y", Backend: "test-backend", OllamaModel: "m"}
+	if err := both.Validate(); err == nil {
+		t.Error("Validate() with a backend and a model succeeded, want error")
+	}
+}
@@ -0,0 +1,330 @@
+package embedder
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"unicode"
+)
+
+// ChunkStrategy selects how a document is split into chunks before embedding.
+type ChunkStrategy string
+
+const (
+	// ChunkStrategyFixed splits text into fixed-size windows with overlap (see ChunkText).
+	ChunkStrategyFixed ChunkStrategy = "fixed"
+	// ChunkStrategyMarkdown splits on markdown headers so each chunk stays within one section.
+	ChunkStrategyMarkdown ChunkStrategy = "markdown"
+	// ChunkStrategySentence packs whole paragraphs and sentences into chunks.
+	ChunkStrategySentence ChunkStrategy = "sentence"
+	// ChunkStrategySemantic starts a new chunk where consecutive sentences diverge in meaning.
+	ChunkStrategySemantic ChunkStrategy = "semantic"
+
+	// DefaultSemanticThreshold is the cosine similarity between consecutive sentences
+	// below which semantic chunking starts a new chunk.
+	DefaultSemanticThreshold = 0.75
+)
+
+// ParseChunkStrategy validates a chunking strategy name. An empty name selects ChunkStrategyFixed.
+func ParseChunkStrategy(name string) (ChunkStrategy, error) {
+	switch ChunkStrategy(strings.ToLower(strings.TrimSpace(name))) {
+	case "", ChunkStrategyFixed:
+		return ChunkStrategyFixed, nil
+	case ChunkStrategyMarkdown:
+		return ChunkStrategyMarkdown, nil
+	case ChunkStrategySentence:
+		return ChunkStrategySentence, nil
+	case ChunkStrategySemantic:
+		return ChunkStrategySemantic, nil
+	default:
+		return "", fmt.Errorf("invalid chunk strategy %q: expected fixed, markdown, sentence or semantic", name)
+	}
+}
+
+// ChunkTextWithStrategy splits text using the given strategy. Semantic chunking needs
+// an embedder, so here it degrades to sentence chunking; use EmbedTextChunksWithStrategy instead.
+func ChunkTextWithStrategy(text string, strategy ChunkStrategy, maxChunkSize, overlap int) []string {
+	if maxChunkSize <= 0 {
+		maxChunkSize = DefaultMaxChunkSize
+	}
+	if overlap < 0 {
+		overlap = DefaultChunkOverlap
+	}
+	if overlap >= maxChunkSize {
+		overlap = maxChunkSize / 4
+	}
+
+	switch strategy {
+	case ChunkStrategyMarkdown:
+		return chunkMarkdown(text, maxChunkSize, overlap)
+	case ChunkStrategySentence, ChunkStrategySemantic:
+		return chunkSentences(text, maxChunkSize, overlap)
+	default:
+		return ChunkText(text, maxChunkSize, overlap)
+	}
+}
+
+// EmbedTextChunksWithStrategy chunks text with the given strategy and returns the chunks
+// together with their individual embeddings.
+func EmbedTextChunksWithStrategy(ctx context.Context, embedder Embedder, text string, strategy ChunkStrategy, maxChunkSize, overlap int) ([]string, [][]float32, error) {
+	switch strategy {
+	case "", ChunkStrategyFixed:
+		return EmbedTextChunksWithOverlap(ctx, embedder, text, maxChunkSize, overlap)
+	case ChunkStrategySemantic:
+		if maxChunkSize <= 0 {
+			maxChunkSize = DefaultMaxChunkSize
+		}
+		chunks, err := chunkSemantic(ctx, embedder, text, maxChunkSize, DefaultSemanticThreshold)
+		if err != nil {
+			return nil, nil, err
+		}
+		return embedChunks(ctx, embedder, text, chunks)
+	default:
+		return embedChunks(ctx, embedder, text, ChunkTextWithStrategy(text, strategy, maxChunkSize, overlap))
+	}
+}
+
+// embedChunks embeds each chunk, falling back to the whole text when there are no chunks.
+func embedChunks(ctx context.Context, embedder Embedder, text string, chunks []string) ([]string, [][]float32, error) {
+	if len(chunks) == 0 {
+		emb, err := embedder.EmbedQuery(ctx, text)
+		if err != nil {
+			return nil, nil, err
+		}
+		return []string{text}, [][]float32{emb}, nil
+	}
+
+	embeddings := make([][]float32, len(chunks))
+	for i, chunk := range chunks {
+		emb, err := embedder.EmbedQuery(ctx, chunk)
+		if err != nil {
+			return nil, nil, err
+		}
+		embeddings[i] = emb
+	}
+
+	return chunks, embeddings, nil
+}
+
+// chunkMarkdown splits text into header-delimited sections. Sections larger than
+// maxChunkSize are split further and each piece is prefixed with its header line.
+func chunkMarkdown(text string, maxChunkSize, overlap int) []string {
+	var chunks []string
+	for _, section := range splitMarkdownSections(text) {
+		section = strings.TrimSpace(section)
+		if section == "" {
+			continue
+		}
+		if len(section) <= maxChunkSize {
+			chunks = append(chunks, section)
+			continue
+		}
+
+		header := ""
+		body := section
+		if strings.HasPrefix(section, "#") {
+			if idx := strings.IndexByte(section, '\n'); idx > 0 {
+				header = section[:idx]
+				body = section[idx+1:]
+			}
+		}
+
+		budget := maxChunkSize
+		if header != "" && len(header)+1 < maxChunkSize/2 {
+			budget = maxChunkSize - len(header) - 1
+		} else {
+			header = ""
+			body = section
+		}
+
+		for _, piece := range chunkSentences(body, budget, overlap) {
+			if header != "" {
+				piece = header + "\n" + piece
+			}
+			chunks = append(chunks, piece)
+		}
+	}
+	return chunks
+}
+
+// splitMarkdownSections splits text at ATX header lines (# ...), ignoring
+// headers inside fenced code blocks.
+func splitMarkdownSections(text string) []string {
+	var sections []string
+	var current strings.Builder
+	inFence := false
+
+	for _, line := range strings.SplitAfter(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+		}
+		if !inFence && isMarkdownHeader(trimmed) && current.Len() > 0 {
+			sections = append(sections, current.String())
+			current.Reset()
+		}
+		current.WriteString(line)
+	}
+	if current.Len() > 0 {
+		sections = append(sections, current.String())
+	}
+	return sections
+}
+
+func isMarkdownHeader(line string) bool {
+	level := 0
+	for level < len(line) && line[level] == '#' {
+		level++
+	}
+	return level > 0 && level <= 6 && (level == len(line) || line[level] == ' ')
+}
+
+// chunkSentences packs paragraphs into chunks of at most maxChunkSize characters.
+// Paragraphs that do not fit are packed sentence by sentence, and sentences that
+// still do not fit fall back to ChunkText. Overlap is made of whole trailing units.
+func chunkSentences(text string, maxChunkSize, overlap int) []string {
+	var units []string
+	for _, para := range splitParagraphs(text) {
+		if len(para) <= maxChunkSize {
+			units = append(units, para)
+			continue
+		}
+		units = append(units, sentenceUnits(para, maxChunkSize)...)
+	}
+	return packUnits(units, maxChunkSize, overlap)
+}
+
+// packUnits greedily joins units into chunks no longer than maxChunkSize. Each new
+// chunk repeats the trailing units of the previous one that fit within overlap.
+func packUnits(units []string, maxChunkSize, overlap int) []string {
+	var chunks []string
+	var current []string
+	size := 0
+
+	for _, unit := range units {
+		if len(current) > 0 && size+1+len(unit) > maxChunkSize {
+			chunks = append(chunks, strings.Join(current, "\n"))
+
+			var carried []string
+			carriedSize := 0
+			for i := len(current) - 1; i >= 0; i-- {
+				next := carriedSize + len(current[i]) + 1
+				if next > overlap || next+len(unit) > maxChunkSize {
+					break
+				}
+				carried = append([]string{current[i]}, carried...)
+				carriedSize = next
+			}
+			current = carried
+			size = carriedSize
+		}
+		if len(current) > 0 {
+			size++
+		}
+		current = append(current, unit)
+		size += len(unit)
+	}
+	if len(current) > 0 {
+		chunks = append(chunks, strings.Join(current, "\n"))
+	}
+	return chunks
+}
+
+// splitParagraphs splits text on blank lines, dropping empty paragraphs.
+func splitParagraphs(text string) []string {
+	var paragraphs []string
+	for _, para := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n\n") {
+		if para = strings.TrimSpace(para); para != "" {
+			paragraphs = append(paragraphs, para)
+		}
+	}
+	return paragraphs
+}
+
+// splitSentences splits text after sentence terminators (. ! ?) followed by whitespace.
+func splitSentences(text string) []string {
+	var sentences []string
+	start := 0
+	for i := 0; i < len(text); i++ {
+		ch := text[i]
+		if ch != '.' && ch != '!' && ch != '?' {
+			continue
+		}
+		if i+1 < len(text) && !unicode.IsSpace(rune(text[i+1])) {
+			continue
+		}
+		if s := strings.TrimSpace(text[start : i+1]); s != "" {
+			sentences = append(sentences, s)
+		}
+		start = i + 1
+	}
+	if s := strings.TrimSpace(text[start:]); s != "" {
+		sentences = append(sentences, s)
+	}
+	return sentences
+}
+
+// sentenceUnits splits text into paragraphs and then sentences, breaking any
+// sentence longer than maxChunkSize with ChunkText.
+func sentenceUnits(text string, maxChunkSize int) []string {
+	var units []string
+	for _, para := range splitParagraphs(text) {
+		for _, sentence := range splitSentences(para) {
+			if len(sentence) <= maxChunkSize {
+				units = append(units, sentence)
+				continue
+			}
+			units = append(units, ChunkText(sentence, maxChunkSize, 0)...)
+		}
+	}
+	return units
+}
+
+// chunkSemantic groups consecutive sentences while their embeddings stay similar,
+// starting a new chunk when similarity drops below threshold or maxChunkSize is reached.
+func chunkSemantic(ctx context.Context, embedder Embedder, text string, maxChunkSize int, threshold float64) ([]string, error) {
+	units := sentenceUnits(text, maxChunkSize)
+	if len(units) <= 1 {
+		return units, nil
+	}
+
+	embeddings, err := embedder.EmbedDocuments(ctx, units)
+	if err != nil {
+		return nil, err
+	}
+	if len(embeddings) != len(units) {
+		return nil, fmt.Errorf("semantic chunking: got %d embeddings for %d sentences", len(embeddings), len(units))
+	}
+
+	var chunks []string
+	current := units[0]
+	for i := 1; i < len(units); i++ {
+		similar := CosineSimilarity(embeddings[i-1], embeddings[i]) >= threshold
+		if similar && len(current)+1+len(units[i]) <= maxChunkSize {
+			current += " " + units[i]
+			continue
+		}
+		chunks = append(chunks, current)
+		current = units[i]
+	}
+	chunks = append(chunks, current)
+	return chunks, nil
+}
+
+// CosineSimilarity returns the cosine similarity of a and b, or 0 when their lengths differ.
+func CosineSimilarity(a, b []float32) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	var dot, na, nb float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		na += float64(a[i]) * float64(a[i])
+		nb += float64(b[i]) * float64(b[i])
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(na) * math.Sqrt(nb))
+}
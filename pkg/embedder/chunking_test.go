@@ -28,3 +28,41 @@ func TestChunkText_NormalText(t *testing.T) {
 		t.Error("Expected at least one chunk")
 	}
 }
+
+func TestParseChunkStrategy(t *testing.T) {
+	for _, name := range []string{"", "fixed", "Markdown", "sentence", "semantic"} {
+		if _, err := ParseChunkStrategy(name); err != nil {
+			t.Errorf("ParseChunkStrategy(%q) returned error: %v", name, err)
+		}
+	}
+	if _, err := ParseChunkStrategy("words"); err == nil {
+		t.Error("expected error for unknown strategy")
+	}
+}
+
+func TestChunkTextWithStrategy_Markdown(t *testing.T) {
+	text := "# Intro\nSome intro text.\n\n## Setup\nInstall it.\n\n```sh\n# not a header\n```\n\n## Usage\nRun it."
+	chunks := ChunkTextWithStrategy(text, ChunkStrategyMarkdown, 400, 0)
+
+	if len(chunks) != 3 {
+		t.Fatalf("expected 3 sections, got %d: %q", len(chunks), chunks)
+	}
+	if !strings.HasPrefix(chunks[1], "## Setup") || !strings.Contains(chunks[1], "# not a header") {
+		t.Errorf("unexpected setup section: %q", chunks[1])
+	}
+}
+
+func TestChunkTextWithStrategy_Sentence(t *testing.T) {
+	text := strings.Repeat("This is a sentence. ", 30) + "\n\n" + strings.Repeat("Another one here! ", 30)
+	chunks := ChunkTextWithStrategy(text, ChunkStrategySentence, 200, 40)
+
+	for _, c := range chunks {
+		if len(c) > 200 {
+			t.Errorf("chunk exceeds max size: %d", len(c))
+		}
+		last := c[len(c)-1]
+		if last != '.' && last != '!' {
+			t.Errorf("chunk does not end on a sentence boundary: %q", c)
+		}
+	}
+}
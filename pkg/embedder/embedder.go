@@ -21,3 +21,9 @@ type Embedder interface {
 	// Es crucial para configurar dinámicamente los índices vectoriales.
 	Dimension() int
 }
+
+// BackendReporter is implemented by embedders that run a model locally and
+// can report the compute backend it runs on (cpu, cuda or metal).
+type BackendReporter interface {
+	Backend() string
+}
@@ -3,6 +3,7 @@ package embedder
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 )
 
@@ -21,6 +22,10 @@ type Config struct {
 	GGUFModelPath string
 	GGUFThreads   int
 	GGUFGPULayers int
+	GGUFOptions   GGUFOptions
+
+	// Guard bounds the calls made to Ollama and OpenAI
+	Guard GuardConfig
 }
 
 // NewEmbedderFromConfig crea una instancia de Embedder basada en la configuración disponible.
@@ -33,7 +38,12 @@ func NewEmbedderFromConfig(cfg *Config) (Embedder, error) {
 
 	// Prioridad 1: GGUF (modelo local - más eficiente)
 	if cfg.GGUFModelPath != "" {
-		return NewGGUFEmbedder(cfg.GGUFModelPath, cfg.GGUFThreads, cfg.GGUFGPULayers)
+		return NewGGUFEmbedderFromConfig(GGUFConfig{
+			ModelPath:   cfg.GGUFModelPath,
+			Threads:     cfg.GGUFThreads,
+			GPULayers:   cfg.GGUFGPULayers,
+			GGUFOptions: cfg.GGUFOptions,
+		})
 	}
 
 	// Prioridad 2: Ollama (si URL está disponible)
@@ -41,7 +51,11 @@ func NewEmbedderFromConfig(cfg *Config) (Embedder, error) {
 		if cfg.OllamaModel == "" {
 			return nil, fmt.Errorf("ollama URL provided but model is missing")
 		}
-		return NewOllamaEmbedder(cfg.OllamaURL, cfg.OllamaModel)
+		emb, err := NewOllamaEmbedder(cfg.OllamaURL, cfg.OllamaModel)
+		if err != nil {
+			return nil, err
+		}
+		return NewGuardedEmbedder(emb, "ollama:"+cfg.OllamaModel, cfg.Guard), nil
 	}
 
 	// Prioridad 3: OpenAI (si API key está disponible)
@@ -50,7 +64,11 @@ func NewEmbedderFromConfig(cfg *Config) (Embedder, error) {
 			// Usar modelo por defecto si no se especifica
 			cfg.OpenAIModel = "text-embedding-3-large"
 		}
-		return NewOpenAIEmbedder(cfg.OpenAIKey, cfg.OpenAIBaseURL, cfg.OpenAIModel)
+		emb, err := NewOpenAIEmbedder(cfg.OpenAIKey, cfg.OpenAIBaseURL, cfg.OpenAIModel)
+		if err != nil {
+			return nil, err
+		}
+		return NewGuardedEmbedder(emb, "openai:"+cfg.OpenAIModel, cfg.Guard), nil
 	}
 
 	return nil, fmt.Errorf("no valid embedder configuration found: either GGUF_MODEL_PATH, OLLAMA_URL, or OPENAI_API_KEY must be provided")
@@ -186,6 +204,20 @@ type MainConfig interface {
 	GetOpenAIModel() string
 }
 
+// GGUFMainConfig is implemented by main configurations that tune how GGUF
+// models are loaded; the defaults are used for the others.
+type GGUFMainConfig interface {
+	GetGGUFOptions() GGUFOptions
+}
+
+// ggufOptionsOf returns the GGUF options of a main configuration.
+func ggufOptionsOf(mainCfg MainConfig) GGUFOptions {
+	if c, ok := mainCfg.(GGUFMainConfig); ok {
+		return c.GetGGUFOptions()
+	}
+	return GGUFOptions{}
+}
+
 // CodeMainConfig extends MainConfig with code-specific embedding model getters.
 // This interface allows specialized code embedding models (e.g., CodeRankEmbed,
 // Jina-code-embeddings) to be used for code indexing while using a different
@@ -208,16 +240,59 @@ func NewEmbedderFromMainConfig(mainCfg MainConfig) (Embedder, error) {
 		GGUFModelPath: mainCfg.GetGGUFModelPath(),
 		GGUFThreads:   mainCfg.GetGGUFThreads(),
 		GGUFGPULayers: mainCfg.GetGGUFGPULayers(),
+		GGUFOptions:   ggufOptionsOf(mainCfg),
 		OllamaURL:     mainCfg.GetOllamaURL(),
 		OllamaModel:   mainCfg.GetOllamaModel(),
 		OpenAIKey:     mainCfg.GetOpenAIKey(),
 		OpenAIBaseURL: mainCfg.GetOpenAIURL(),
 		OpenAIModel:   mainCfg.GetOpenAIModel(),
+		Guard:         guardOf(mainCfg),
 	}
 
 	return NewEmbedderFromConfig(cfg)
 }
 
+// ModelName identifies the model NewEmbedderFromMainConfig uses, e.g.
+// "ollama:nomic-embed-text", so embeddings made with another model can be
+// told apart. It returns "" when no embedder is configured.
+func ModelName(mainCfg MainConfig) string {
+	switch {
+	case mainCfg.GetGGUFModelPath() != "":
+		return "gguf:" + filepath.Base(mainCfg.GetGGUFModelPath())
+	case mainCfg.GetOllamaURL() != "":
+		return "ollama:" + mainCfg.GetOllamaModel()
+	case mainCfg.GetOpenAIKey() != "":
+		model := mainCfg.GetOpenAIModel()
+		if model == "" {
+			model = "text-embedding-3-large"
+		}
+		return "openai:" + model
+	}
+	return ""
+}
+
+// CodeModelName identifies the model NewCodeEmbedderFromMainConfig uses, or
+// the ModelName of the default embedder when no code-specific model is
+// configured.
+func CodeModelName(mainCfg CodeMainConfig) string {
+	if !mainCfg.HasCodeSpecificEmbedder() {
+		return ModelName(mainCfg)
+	}
+	switch {
+	case mainCfg.GetCodeGGUFModelPath() != "":
+		return "gguf:" + filepath.Base(mainCfg.GetCodeGGUFModelPath())
+	case mainCfg.GetOllamaURL() != "":
+		return "ollama:" + mainCfg.GetCodeOllamaModel()
+	case mainCfg.GetOpenAIKey() != "":
+		model := mainCfg.GetCodeOpenAIModel()
+		if model == "" {
+			model = "text-embedding-3-large"
+		}
+		return "openai:" + model
+	}
+	return ""
+}
+
 // NewCodeEmbedderFromMainConfig creates an embedder specifically for code indexing.
 // If a code-specific model is configured (code-gguf-model-path, code-ollama-model,
 // or code-openai-model), it will use that model. Otherwise, it returns nil,
@@ -243,11 +318,13 @@ func NewCodeEmbedderFromMainConfig(mainCfg CodeMainConfig) (Embedder, error) {
 		GGUFModelPath: mainCfg.GetCodeGGUFModelPath(),
 		GGUFThreads:   mainCfg.GetGGUFThreads(),
 		GGUFGPULayers: mainCfg.GetGGUFGPULayers(),
+		GGUFOptions:   ggufOptionsOf(mainCfg),
 		OllamaURL:     mainCfg.GetOllamaURL(),
 		OllamaModel:   mainCfg.GetCodeOllamaModel(),
 		OpenAIKey:     mainCfg.GetOpenAIKey(),
 		OpenAIBaseURL: mainCfg.GetOpenAIURL(),
 		OpenAIModel:   mainCfg.GetCodeOpenAIModel(),
+		Guard:         guardOf(mainCfg),
 	}
 
 	return NewEmbedderFromConfig(cfg)
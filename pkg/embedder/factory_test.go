@@ -118,3 +118,20 @@ func TestGetEmbedderType_Extended(t *testing.T) {
 		})
 	}
 }
+
+func TestModelName(t *testing.T) {
+	tests := []struct {
+		cfg  mockMainConfig
+		want string
+	}{
+		{mockMainConfig{ggufPath: "/models/nomic-embed.gguf", ollamaURL: "http://localhost:11434"}, "gguf:nomic-embed.gguf"},
+		{mockMainConfig{ollamaURL: "http://localhost:11434", ollamaM: "nomic-embed-text"}, "ollama:nomic-embed-text"},
+		{mockMainConfig{openaiK: "key"}, "openai:text-embedding-3-large"},
+		{mockMainConfig{}, ""},
+	}
+	for _, tc := range tests {
+		if got := ModelName(&tc.cfg); got != tc.want {
+			t.Errorf("ModelName(%+v) = %q, want %q", tc.cfg, got, tc.want)
+		}
+	}
+}
@@ -1,11 +1,14 @@
 package embedder
 
 import (
+	"cmp"
 	"context"
 	"fmt"
 	"log/slog"
 	"runtime/debug"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/madeindigio/remembrances-mcp/internal/llama"
 )
@@ -31,10 +34,46 @@ type GGUFConfig struct {
 	ModelPath string // Path to the GGUF model file
 	Threads   int    // Threads to use (<=0 uses a reasonable default)
 	GPULayers int    // GPU layers to offload (0 = CPU only)
+	GGUFOptions
 }
 
-// NewGGUFEmbedder creates a new GGUFEmbedder.
+// GGUFOptions tunes how a GGUF model is loaded and run. The zero value keeps
+// the defaults.
+type GGUFOptions struct {
+	Pooling       string  // Token pooling: mean (default), cls or last
+	ContextSize   int     // Context window in tokens (0 = 384)
+	BatchTokens   int     // Tokens decoded at once, which bounds the input length (0 = ContextSize)
+	RopeFreqBase  float64 // RoPE base frequency (0 = model default)
+	RopeFreqScale float64 // RoPE frequency scale (0 = model default)
+	MMap          bool    // Memory-map the model file instead of reading it
+	MLock         bool    // Lock the model in RAM so it is never swapped out
+}
+
+// defaultGGUFContextSize is small on purpose: embedding inputs are chunks of
+// a few hundred characters, and a larger context costs memory for nothing.
+const defaultGGUFContextSize = 384
+
+// GGUFPooling parses a pooling type name. The empty name is mean pooling.
+func GGUFPooling(name string) (llama.PoolingType, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", "mean":
+		return llama.PoolingMean, nil
+	case "cls":
+		return llama.PoolingCLS, nil
+	case "last":
+		return llama.PoolingLast, nil
+	}
+	return llama.PoolingDefault, fmt.Errorf("invalid GGUF pooling %q: expected mean, cls or last", name)
+}
+
+// NewGGUFEmbedder creates a new GGUFEmbedder with the default options.
 func NewGGUFEmbedder(modelPath string, threads, gpuLayers int) (*GGUFEmbedder, error) {
+	return NewGGUFEmbedderFromConfig(GGUFConfig{ModelPath: modelPath, Threads: threads, GPULayers: gpuLayers})
+}
+
+// NewGGUFEmbedderFromConfig creates a GGUF embedder from a config struct.
+func NewGGUFEmbedderFromConfig(cfg GGUFConfig) (*GGUFEmbedder, error) {
+	modelPath, threads, gpuLayers := cfg.ModelPath, cfg.Threads, cfg.GPULayers
 	if modelPath == "" {
 		return nil, fmt.Errorf("model path is required")
 	}
@@ -43,16 +82,33 @@ func NewGGUFEmbedder(modelPath string, threads, gpuLayers int) (*GGUFEmbedder, e
 		threads = 8
 	}
 
+	pooling, err := GGUFPooling(cfg.Pooling)
+	if err != nil {
+		return nil, err
+	}
+	contextSize := cfg.ContextSize
+	if contextSize <= 0 {
+		contextSize = defaultGGUFContextSize
+	}
+	batchTokens := cfg.BatchTokens
+	if batchTokens <= 0 || batchTokens > contextSize {
+		batchTokens = contextSize
+	}
+
 	model, err := llama.LoadModel(context.Background(), modelPath, llama.Options{
-		Threads:      threads,
-		ThreadsBatch: threads,
-		GPULayers:    gpuLayers,
-		ContextSize:  384, // Reduced from 512 for safety
-		BatchSize:    384, // Reduced from 512 for safety
-		UBatchSize:   384, // CRITICAL: Must be >= n_tokens in any single call
-		Pooling:      llama.PoolingMean,
-		Attention:    llama.AttentionNonCausal,
-		Normalize:    2,
+		Threads:       threads,
+		ThreadsBatch:  threads,
+		GPULayers:     gpuLayers,
+		ContextSize:   uint32(contextSize),
+		BatchSize:     uint32(batchTokens),
+		UBatchSize:    uint32(batchTokens), // CRITICAL: Must be >= n_tokens in any single call
+		UseMMap:       cfg.MMap,
+		UseMLock:      cfg.MLock,
+		Pooling:       pooling,
+		Attention:     llama.AttentionNonCausal,
+		Normalize:     2,
+		RopeFreqBase:  float32(cfg.RopeFreqBase),
+		RopeFreqScale: float32(cfg.RopeFreqScale),
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to load GGUF model from %s: %w", modelPath, err)
@@ -61,7 +117,7 @@ func NewGGUFEmbedder(modelPath string, threads, gpuLayers int) (*GGUFEmbedder, e
 	// Get dynamic limits from the model
 	ubatchSize := model.UBatchSize()
 	if ubatchSize == 0 {
-		ubatchSize = 384 // Fallback to safe default (reduced from 512)
+		ubatchSize = defaultGGUFContextSize // Fallback to safe default
 	}
 
 	// ULTRA conservative char-to-token ratio for code and special characters
@@ -77,11 +133,8 @@ func NewGGUFEmbedder(modelPath string, threads, gpuLayers int) (*GGUFEmbedder, e
 	}
 
 	// Calculate max chars based on conservative ratio
-	// For 384 ubatch: 270 tokens * 1.5 chars/token = 405 chars max
+	// For 384 ubatch: 269 tokens * 1.5 chars/token = 403 chars max
 	maxChars := int(float64(maxTokens) * 1.5)
-	if maxChars > 450 {
-		maxChars = 450 // Hard cap for extreme safety
-	}
 
 	embedder := &GGUFEmbedder{
 		model:         model,
@@ -96,18 +149,30 @@ func NewGGUFEmbedder(modelPath string, threads, gpuLayers int) (*GGUFEmbedder, e
 
 	// Log the dynamic limits for debugging
 	slog.Info("GGUF embedder initialized with dynamic limits",
+		"context_size", contextSize,
 		"ubatch_size", ubatchSize,
 		"max_tokens", maxTokens,
 		"max_chars", maxChars,
 		"chars_per_token", charsPerToken,
+		"pooling", cmp.Or(cfg.Pooling, "mean"),
+		"mmap", cfg.MMap,
+		"mlock", cfg.MLock,
+		"backend", model.Backend(),
+		"gpu_layers", model.GPULayers(),
 		"model_path", modelPath)
 
 	return embedder, nil
 }
 
-// NewGGUFEmbedderFromConfig creates a GGUF embedder from a config struct.
-func NewGGUFEmbedderFromConfig(cfg GGUFConfig) (*GGUFEmbedder, error) {
-	return NewGGUFEmbedder(cfg.ModelPath, cfg.Threads, cfg.GPULayers)
+// WarmUp embeds a short text so the backend allocates its compute buffers
+// and pages the model in now rather than on the first real request. It
+// returns how long the embedding took.
+func (g *GGUFEmbedder) WarmUp(ctx context.Context) (time.Duration, error) {
+	start := time.Now()
+	if _, err := g.embedSingle(ctx, "warm-up"); err != nil {
+		return 0, err
+	}
+	return time.Since(start), nil
 }
 
 // EmbedDocuments generates embeddings for a batch of texts.
@@ -335,3 +400,14 @@ func (g *GGUFEmbedder) Threads() int {
 func (g *GGUFEmbedder) GPULayers() int {
 	return g.gpuLayers
 }
+
+// ActiveGPULayers returns the number of layers offloaded to the GPU, which
+// is 0 when no usable GPU was found and the model fell back to the CPU.
+func (g *GGUFEmbedder) ActiveGPULayers() int {
+	return g.model.GPULayers()
+}
+
+// Backend returns the backend the model runs on: cpu, cuda or metal.
+func (g *GGUFEmbedder) Backend() string {
+	return g.model.Backend()
+}
@@ -5,6 +5,8 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+
+	"github.com/madeindigio/remembrances-mcp/internal/llama"
 )
 
 // TestGGUFEmbedder tests the GGUF embedder with a real model file.
@@ -187,6 +189,30 @@ func TestGGUFEmbedderEmptyPath(t *testing.T) {
 	t.Logf("Correctly rejected empty path: %v", err)
 }
 
+// TestGGUFPooling tests parsing of the pooling type names.
+func TestGGUFPooling(t *testing.T) {
+	cases := map[string]llama.PoolingType{
+		"":      llama.PoolingMean,
+		"mean":  llama.PoolingMean,
+		" CLS ": llama.PoolingCLS,
+		"last":  llama.PoolingLast,
+	}
+	for name, want := range cases {
+		got, err := GGUFPooling(name)
+		if err != nil {
+			t.Fatalf("GGUFPooling(%q) failed: %v", name, err)
+		}
+		if got != want {
+			t.Errorf("GGUFPooling(%q) = %v, want %v", name, got, want)
+		}
+	}
+	for _, name := range []string{"none", "rank", "max"} {
+		if _, err := GGUFPooling(name); err == nil {
+			t.Errorf("GGUFPooling(%q) succeeded, want error", name)
+		}
+	}
+}
+
 // BenchmarkGGUFEmbedder benchmarks the GGUF embedder performance.
 func BenchmarkGGUFEmbedder(b *testing.B) {
 	modelPath := os.Getenv("GGUF_TEST_MODEL_PATH")
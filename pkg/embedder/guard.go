@@ -0,0 +1,181 @@
+package embedder
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned, wrapped, while a guarded embedder's circuit
+// breaker is open and calls fail fast instead of reaching the backend.
+var ErrCircuitOpen = errors.New("embedder circuit breaker is open")
+
+// GuardConfig bounds the calls made to a remote embedding backend. Zero
+// values disable the corresponding protection.
+type GuardConfig struct {
+	Timeout          time.Duration // Limit of one call, including the wait for a free slot
+	MaxConcurrent    int           // Calls in flight at once; the others queue
+	FailureThreshold int           // Consecutive failures that open the circuit breaker
+	Cooldown         time.Duration // How long the breaker stays open before a call is tried again
+}
+
+// Enabled reports whether any protection is configured.
+func (g GuardConfig) Enabled() bool {
+	return g.Timeout > 0 || g.MaxConcurrent > 0 || g.FailureThreshold > 0
+}
+
+// GuardMainConfig is implemented by main configurations that bound the calls
+// made to Ollama and OpenAI; calls are unbounded for the others.
+type GuardMainConfig interface {
+	GetEmbedderGuard() GuardConfig
+}
+
+// guardOf returns the guard configuration of a main configuration.
+func guardOf(mainCfg MainConfig) GuardConfig {
+	if c, ok := mainCfg.(GuardMainConfig); ok {
+		return c.GetEmbedderGuard()
+	}
+	return GuardConfig{}
+}
+
+// GuardedEmbedder wraps an embedder with a per-call timeout, a queue limiting
+// concurrent calls and a circuit breaker that fails fast while the backend
+// keeps failing, so a hung Ollama or OpenAI endpoint cannot stall every tool.
+type GuardedEmbedder struct {
+	inner Embedder
+	name  string
+	cfg   GuardConfig
+	slots chan struct{}
+	now   func() time.Time
+
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+}
+
+// NewGuardedEmbedder wraps inner, named name in errors and logs, with the
+// protections of cfg. It returns inner unchanged when cfg enables none.
+func NewGuardedEmbedder(inner Embedder, name string, cfg GuardConfig) Embedder {
+	if !cfg.Enabled() {
+		return inner
+	}
+	g := &GuardedEmbedder{inner: inner, name: name, cfg: cfg, now: time.Now}
+	if cfg.MaxConcurrent > 0 {
+		g.slots = make(chan struct{}, cfg.MaxConcurrent)
+	}
+	return g
+}
+
+// Unwrap returns the wrapped embedder.
+func (g *GuardedEmbedder) Unwrap() Embedder {
+	return g.inner
+}
+
+// EmbedDocuments embeds texts with the wrapped embedder.
+func (g *GuardedEmbedder) EmbedDocuments(ctx context.Context, texts []string) ([][]float32, error) {
+	var out [][]float32
+	err := g.call(ctx, func(ctx context.Context) (err error) {
+		out, err = g.inner.EmbedDocuments(ctx, texts)
+		return err
+	})
+	return out, err
+}
+
+// EmbedQuery embeds text with the wrapped embedder.
+func (g *GuardedEmbedder) EmbedQuery(ctx context.Context, text string) ([]float32, error) {
+	var out []float32
+	err := g.call(ctx, func(ctx context.Context) (err error) {
+		out, err = g.inner.EmbedQuery(ctx, text)
+		return err
+	})
+	return out, err
+}
+
+// Dimension returns the dimension of the wrapped embedder.
+func (g *GuardedEmbedder) Dimension() int {
+	return g.inner.Dimension()
+}
+
+// CircuitOpen reports whether calls currently fail fast.
+func (g *GuardedEmbedder) CircuitOpen() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.now().Before(g.openUntil)
+}
+
+// call runs fn within the timeout once a slot is free and the breaker is
+// closed, and records whether it failed.
+func (g *GuardedEmbedder) call(ctx context.Context, fn func(context.Context) error) error {
+	if err := g.allow(); err != nil {
+		return err
+	}
+
+	callCtx := ctx
+	if g.cfg.Timeout > 0 {
+		var cancel context.CancelFunc
+		callCtx, cancel = context.WithTimeout(ctx, g.cfg.Timeout)
+		defer cancel()
+	}
+
+	if g.slots != nil {
+		select {
+		case g.slots <- struct{}{}:
+			defer func() { <-g.slots }()
+		case <-callCtx.Done():
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			// Queue timeouts are a symptom of a slow backend too.
+			g.record(callCtx.Err())
+			return fmt.Errorf("embedder %s: timed out after %s waiting for one of %d slots", g.name, g.cfg.Timeout, g.cfg.MaxConcurrent)
+		}
+	}
+
+	err := fn(callCtx)
+	if err != nil && ctx.Err() != nil {
+		// Cancelled by the caller; says nothing about the backend.
+		return err
+	}
+	g.record(err)
+	if err != nil && errors.Is(callCtx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("embedder %s: no response within %s: %w", g.name, g.cfg.Timeout, err)
+	}
+	return err
+}
+
+// allow returns an error wrapping ErrCircuitOpen while the breaker is open.
+func (g *GuardedEmbedder) allow() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if now := g.now(); now.Before(g.openUntil) {
+		return fmt.Errorf("embedder %s is unavailable after %d consecutive failures, retrying in %s: %w",
+			g.name, g.failures, g.openUntil.Sub(now).Round(time.Second), ErrCircuitOpen)
+	}
+	return nil
+}
+
+// record counts consecutive failures, opening the breaker at the threshold.
+// Once the cooldown is over one failed call opens it again.
+func (g *GuardedEmbedder) record(err error) {
+	if g.cfg.FailureThreshold <= 0 {
+		return
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if err == nil {
+		if g.failures >= g.cfg.FailureThreshold {
+			slog.Info("embedder recovered, circuit breaker closed", "embedder", g.name)
+		}
+		g.failures = 0
+		return
+	}
+	g.failures++
+	if g.failures >= g.cfg.FailureThreshold {
+		g.openUntil = g.now().Add(g.cfg.Cooldown)
+		slog.Warn("embedder keeps failing, circuit breaker opened",
+			"embedder", g.name, "failures", g.failures, "cooldown", g.cfg.Cooldown, "error", err)
+	}
+}
@@ -0,0 +1,132 @@
+package embedder
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// stubEmbedder fails while err is set and blocks until release is closed when
+// it is not nil.
+type stubEmbedder struct {
+	calls   atomic.Int32
+	err     error
+	release chan struct{}
+}
+
+func (s *stubEmbedder) EmbedDocuments(ctx context.Context, texts []string) ([][]float32, error) {
+	v, err := s.EmbedQuery(ctx, "")
+	return [][]float32{v}, err
+}
+
+func (s *stubEmbedder) EmbedQuery(ctx context.Context, text string) ([]float32, error) {
+	s.calls.Add(1)
+	if s.release != nil {
+		select {
+		case <-s.release:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	if s.err != nil {
+		return nil, s.err
+	}
+	return []float32{1}, nil
+}
+
+func (s *stubEmbedder) Dimension() int { return 1 }
+
+func TestNewGuardedEmbedderDisabled(t *testing.T) {
+	inner := &stubEmbedder{}
+	if got := NewGuardedEmbedder(inner, "stub", GuardConfig{}); got != Embedder(inner) {
+		t.Error("a zero GuardConfig should return the embedder unchanged")
+	}
+}
+
+func TestGuardedEmbedderTimeout(t *testing.T) {
+	inner := &stubEmbedder{release: make(chan struct{})}
+	defer close(inner.release)
+	g := NewGuardedEmbedder(inner, "stub", GuardConfig{Timeout: 20 * time.Millisecond})
+
+	_, err := g.EmbedQuery(context.Background(), "hello")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("EmbedQuery() error = %v, want deadline exceeded", err)
+	}
+}
+
+func TestGuardedEmbedderCircuitBreaker(t *testing.T) {
+	inner := &stubEmbedder{err: errors.New("connection refused")}
+	g := NewGuardedEmbedder(inner, "stub", GuardConfig{FailureThreshold: 2, Cooldown: time.Minute}).(*GuardedEmbedder)
+	now := time.Now()
+	g.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		if _, err := g.EmbedQuery(context.Background(), "hello"); errors.Is(err, ErrCircuitOpen) {
+			t.Fatalf("call %d failed fast before the threshold", i)
+		}
+	}
+	if !g.CircuitOpen() {
+		t.Fatal("breaker should open after 2 consecutive failures")
+	}
+	if _, err := g.EmbedQuery(context.Background(), "hello"); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("EmbedQuery() error = %v, want ErrCircuitOpen", err)
+	}
+	if got := inner.calls.Load(); got != 2 {
+		t.Errorf("backend called %d times, want 2", got)
+	}
+
+	// After the cooldown one call is let through; success closes the breaker
+	now = now.Add(time.Minute)
+	inner.err = nil
+	if _, err := g.EmbedQuery(context.Background(), "hello"); err != nil {
+		t.Fatalf("EmbedQuery() after cooldown error = %v", err)
+	}
+	if g.CircuitOpen() || g.failures != 0 {
+		t.Error("a successful call should close the breaker")
+	}
+}
+
+func TestGuardedEmbedderCallerCancelDoesNotTrip(t *testing.T) {
+	inner := &stubEmbedder{release: make(chan struct{})}
+	defer close(inner.release)
+	g := NewGuardedEmbedder(inner, "stub", GuardConfig{FailureThreshold: 1, Cooldown: time.Minute}).(*GuardedEmbedder)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := g.EmbedQuery(ctx, "hello"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("EmbedQuery() error = %v, want canceled", err)
+	}
+	if g.CircuitOpen() {
+		t.Error("a call cancelled by the caller should not open the breaker")
+	}
+}
+
+func TestGuardedEmbedderMaxConcurrent(t *testing.T) {
+	inner := &stubEmbedder{release: make(chan struct{})}
+	g := NewGuardedEmbedder(inner, "stub", GuardConfig{MaxConcurrent: 2, Timeout: time.Second})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			g.EmbedQuery(context.Background(), "hello")
+		}()
+	}
+	deadline := time.Now().Add(time.Second)
+	for inner.calls.Load() < 2 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(20 * time.Millisecond)
+	if got := inner.calls.Load(); got != 2 {
+		t.Errorf("%d calls reached the backend, want 2 with the third queued", got)
+	}
+	close(inner.release)
+	wg.Wait()
+	if got := inner.calls.Load(); got != 3 {
+		t.Errorf("%d calls reached the backend, want 3 once slots freed", got)
+	}
+}
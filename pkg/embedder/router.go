@@ -0,0 +1,364 @@
+package embedder
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// Record kinds an embedder can be routed to. Each kind is embedded by one
+// model, both when it is stored and when it is searched.
+const (
+	RouteVectors       = "vectors"        // add_vector, search_vectors, hybrid_search, consolidation
+	RouteKnowledgeBase = "knowledge_base" // kb_* tools and the knowledge base watchers
+	RouteEvents        = "events"         // save_event, search_events
+	RouteCode          = "code"           // code indexing and code_* tools
+)
+
+// RouteKinds lists the record kinds in a stable order.
+var RouteKinds = []string{RouteVectors, RouteKnowledgeBase, RouteEvents, RouteCode}
+
+// DefaultEmbedderName names the default embedder in embedding routes.
+const DefaultEmbedderName = "default"
+
+// IsRouteKind reports whether kind is a record kind embedders can be routed to.
+func IsRouteKind(kind string) bool {
+	for _, k := range RouteKinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// NamedConfig describes an additional embedder that embedding routes refer
+// to by name. It sets exactly one model, or the backend a module registered
+// (see RegisterBackend) with its options; the Ollama URL, OpenAI key and GGUF
+// settings are shared with the default embedder.
+type NamedConfig struct {
+	Name          string
+	GGUFModelPath string
+	OllamaModel   string
+	OpenAIModel   string
+	Backend       string
+	Options       map[string]any
+}
+
+// Validate checks that a named embedder sets exactly one model or a
+// registered backend.
+func (n NamedConfig) Validate() error {
+	set := 0
+	for _, model := range []string{n.GGUFModelPath, n.OllamaModel, n.OpenAIModel, n.Backend} {
+		if model != "" {
+			set++
+		}
+	}
+	if set != 1 {
+		return fmt.Errorf("embedder %q must set exactly one of gguf-model-path, ollama-model, openai-model or backend", n.Name)
+	}
+	if n.Backend != "" {
+		if _, ok := backendFactory(n.Backend); !ok {
+			return fmt.Errorf("embedder %q: unknown backend %q; backends of this binary: %v (add modules with xremembrances build --with)", n.Name, n.Backend, Backends())
+		}
+	}
+	return nil
+}
+
+// RoutingMainConfig is implemented by main configurations with named
+// embedders and the routes sending record kinds to them.
+type RoutingMainConfig interface {
+	GetEmbedders() []NamedConfig
+	GetEmbeddingRoutes() map[string]string
+}
+
+// Route is the embedder of a record kind and the name of its model.
+type Route struct {
+	Embedder Embedder
+	Model    string
+}
+
+// Router picks the embedder of each record kind. Kinds without a route are
+// embedded by the default embedder. The named embedders of the configuration
+// are also available by name (see Named), for records that pick their own
+// model, like code projects.
+type Router struct {
+	def    Route
+	routes map[string]Route
+
+	mainCfg MainConfig
+	named   map[string]NamedConfig
+
+	mu        sync.Mutex
+	created   map[string]Route // named embedders by name
+	dimension int              // required dimension of named embedders, 0 for any
+}
+
+// NewRouter creates a router sending every record kind to def, whose model
+// is named model.
+func NewRouter(def Embedder, model string) *Router {
+	return &Router{def: Route{Embedder: def, Model: model}, routes: map[string]Route{}, created: map[string]Route{}}
+}
+
+// Set routes a record kind to another embedder.
+func (r *Router) Set(kind string, route Route) {
+	r.routes[kind] = route
+}
+
+// Route returns the embedder of a record kind and its model.
+func (r *Router) Route(kind string) Route {
+	if route, ok := r.routes[kind]; ok {
+		return route
+	}
+	return r.def
+}
+
+// For returns the embedder of a record kind.
+func (r *Router) For(kind string) Embedder {
+	return r.Route(kind).Embedder
+}
+
+// Model returns the model name of a record kind's embedder.
+func (r *Router) Model(kind string) string {
+	return r.Route(kind).Model
+}
+
+// Models returns the model name of every record kind.
+func (r *Router) Models() map[string]string {
+	models := make(map[string]string, len(RouteKinds))
+	for _, kind := range RouteKinds {
+		models[kind] = r.Model(kind)
+	}
+	return models
+}
+
+// Names returns the names of the configured named embedders, sorted.
+func (r *Router) Names() []string {
+	names := make([]string, 0, len(r.named))
+	for name := range r.named {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Named returns the named embedder called name and its model, creating it on
+// first use. DefaultEmbedderName is the default embedder.
+func (r *Router) Named(name string) (Route, error) {
+	if name == DefaultEmbedderName {
+		return r.def, nil
+	}
+	named, ok := r.named[name]
+	if !ok {
+		return Route{}, fmt.Errorf("unknown embedder %q; configured embedders: %v", name, r.Names())
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if route, ok := r.created[name]; ok {
+		return route, nil
+	}
+	if err := named.Validate(); err != nil {
+		return Route{}, err
+	}
+	var emb Embedder
+	var err error
+	if named.Backend != "" {
+		emb, err = newBackendEmbedder(named)
+	} else {
+		emb, err = NewEmbedderFromConfig(namedEmbedderConfig(r.mainCfg, named))
+	}
+	if err != nil {
+		return Route{}, fmt.Errorf("failed to create embedder %q: %w", name, err)
+	}
+	route := Route{Embedder: emb, Model: NamedModelName(named)}
+	if err := checkDimension(name, route, r.dimension); err != nil {
+		return Route{}, err
+	}
+	r.created[name] = route
+	return route, nil
+}
+
+// RequireDimension makes the router reject named embedders whose vectors do
+// not have dim dimensions, the dimension the storage schema indexes. It
+// checks the embedders already created for the embedding routes, so calling
+// it at startup fails on a misconfigured route instead of storing resized
+// vectors, and the ones Named creates later, like the embedders picked by
+// code projects.
+func (r *Router) RequireDimension(dim int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, name := range sortedRouteNames(r.created) {
+		if err := checkDimension(name, r.created[name], dim); err != nil {
+			return err
+		}
+	}
+	r.dimension = dim
+	return nil
+}
+
+// checkDimension returns an error when the embedder of route does not
+// produce vectors of dim dimensions. Embedders that do not know their
+// dimension (0) and a dim of 0 pass.
+func checkDimension(name string, route Route, dim int) error {
+	got := route.Embedder.Dimension()
+	if dim <= 0 || got <= 0 || got == dim {
+		return nil
+	}
+	return fmt.Errorf("embedder %q (%s) produces %d-dimensional vectors but the schema indexes %d dimensions; route it to a %d-dimensional model", name, route.Model, got, dim, dim)
+}
+
+// sortedRouteNames returns the keys of routes, sorted.
+func sortedRouteNames(routes map[string]Route) []string {
+	names := make([]string, 0, len(routes))
+	for name := range routes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Embedders returns the distinct embedders the router uses with their
+// models, the default one first.
+func (r *Router) Embedders() []Route {
+	routes := []Route{r.def}
+	seen := map[Embedder]bool{r.def.Embedder: true}
+	for _, kind := range RouteKinds {
+		route, ok := r.routes[kind]
+		if ok && !seen[route.Embedder] {
+			seen[route.Embedder] = true
+			routes = append(routes, route)
+		}
+	}
+	return routes
+}
+
+// namedEmbedderConfig builds the embedder configuration of a named embedder,
+// filling only the provider of the model it sets.
+func namedEmbedderConfig(mainCfg MainConfig, named NamedConfig) *Config {
+	switch {
+	case named.GGUFModelPath != "":
+		return &Config{
+			GGUFModelPath: named.GGUFModelPath,
+			GGUFThreads:   mainCfg.GetGGUFThreads(),
+			GGUFGPULayers: mainCfg.GetGGUFGPULayers(),
+			GGUFOptions:   ggufOptionsOf(mainCfg),
+		}
+	case named.OllamaModel != "":
+		return &Config{OllamaURL: mainCfg.GetOllamaURL(), OllamaModel: named.OllamaModel, Guard: guardOf(mainCfg)}
+	default:
+		return &Config{OpenAIKey: mainCfg.GetOpenAIKey(), OpenAIBaseURL: mainCfg.GetOpenAIURL(), OpenAIModel: named.OpenAIModel, Guard: guardOf(mainCfg)}
+	}
+}
+
+// NamedModelName identifies the model of a named embedder like ModelName.
+func NamedModelName(named NamedConfig) string {
+	switch {
+	case named.GGUFModelPath != "":
+		return "gguf:" + filepath.Base(named.GGUFModelPath)
+	case named.OllamaModel != "":
+		return "ollama:" + named.OllamaModel
+	case named.OpenAIModel != "":
+		return "openai:" + named.OpenAIModel
+	case named.Backend != "":
+		// Backends name their model with the conventional model option
+		if model, ok := named.Options["model"].(string); ok && model != "" {
+			return named.Backend + ":" + model
+		}
+		return named.Backend + ":" + named.Name
+	}
+	return ""
+}
+
+// configuredRoutes returns the named embedder each routed record kind is
+// sent to. Kinds routed to the default embedder map to a NamedConfig named
+// DefaultEmbedderName.
+func configuredRoutes(mainCfg MainConfig) (map[string]NamedConfig, error) {
+	rc, ok := mainCfg.(RoutingMainConfig)
+	if !ok {
+		return nil, nil
+	}
+	named := map[string]NamedConfig{}
+	for _, n := range rc.GetEmbedders() {
+		named[n.Name] = n
+	}
+	routes := map[string]NamedConfig{}
+	for kind, name := range rc.GetEmbeddingRoutes() {
+		if !IsRouteKind(kind) {
+			return nil, fmt.Errorf("invalid embedding route %q: expected vectors, knowledge_base, events or code", kind)
+		}
+		if name == DefaultEmbedderName {
+			routes[kind] = NamedConfig{Name: DefaultEmbedderName}
+			continue
+		}
+		n, ok := named[name]
+		if !ok {
+			return nil, fmt.Errorf("embedding route %q refers to unknown embedder %q", kind, name)
+		}
+		routes[kind] = n
+	}
+	return routes, nil
+}
+
+// RouteModelNames returns the model name of every record kind according to
+// the configuration, without creating any embedder: the code model for code
+// when one is configured, the routed models, and ModelName for the rest.
+func RouteModelNames(mainCfg CodeMainConfig) (map[string]string, error) {
+	routes, err := configuredRoutes(mainCfg)
+	if err != nil {
+		return nil, err
+	}
+	models := make(map[string]string, len(RouteKinds))
+	for _, kind := range RouteKinds {
+		models[kind] = ModelName(mainCfg)
+	}
+	models[RouteCode] = CodeModelName(mainCfg)
+	for kind, named := range routes {
+		if named.Name == DefaultEmbedderName {
+			models[kind] = ModelName(mainCfg)
+		} else {
+			models[kind] = NamedModelName(named)
+		}
+	}
+	return models, nil
+}
+
+// NewRouterFromMainConfig routes every record kind to def, code to code when
+// it is a separate embedder, and the kinds of the configured embedding routes
+// to their named embedders, which are created here. Routes override the
+// code-specific model. The other named embedders are created by Named when
+// first used.
+func NewRouterFromMainConfig(mainCfg CodeMainConfig, def, code Embedder) (*Router, error) {
+	router := NewRouter(def, ModelName(mainCfg))
+	if code != nil && code != def {
+		router.Set(RouteCode, Route{Embedder: code, Model: CodeModelName(mainCfg)})
+	}
+
+	routes, err := configuredRoutes(mainCfg)
+	if err != nil {
+		return nil, err
+	}
+	router.mainCfg = mainCfg
+	router.named = map[string]NamedConfig{}
+	if rc, ok := mainCfg.(RoutingMainConfig); ok {
+		for _, n := range rc.GetEmbedders() {
+			router.named[n.Name] = n
+		}
+	}
+	for _, kind := range RouteKinds {
+		named, ok := routes[kind]
+		if !ok {
+			continue
+		}
+		if named.Name == DefaultEmbedderName {
+			router.Set(kind, router.def)
+			continue
+		}
+		route, err := router.Named(named.Name)
+		if err != nil {
+			return nil, err
+		}
+		router.Set(kind, route)
+	}
+	return router, nil
+}
@@ -0,0 +1,174 @@
+package embedder
+
+import (
+	"strings"
+	"testing"
+)
+
+// mockRoutingConfig extends mockCodeMainConfig with named embedders and routes
+type mockRoutingConfig struct {
+	mockCodeMainConfig
+	embedders []NamedConfig
+	routes    map[string]string
+}
+
+func (m *mockRoutingConfig) GetEmbedders() []NamedConfig           { return m.embedders }
+func (m *mockRoutingConfig) GetEmbeddingRoutes() map[string]string { return m.routes }
+
+func newMockRoutingConfig(routes map[string]string) *mockRoutingConfig {
+	return &mockRoutingConfig{
+		mockCodeMainConfig: mockCodeMainConfig{
+			mockMainConfig: mockMainConfig{ollamaURL: "http://localhost:11434", ollamaM: "nomic-embed-text"},
+			codeOllama:     "jina/jina-embeddings-v2-base-code",
+		},
+		embedders: []NamedConfig{
+			{Name: "docs", OllamaModel: "mxbai-embed-large"},
+			{Name: "big", OpenAIModel: "text-embedding-3-small"},
+		},
+		routes: routes,
+	}
+}
+
+func TestRouteModelNames(t *testing.T) {
+	cfg := newMockRoutingConfig(map[string]string{RouteKnowledgeBase: "docs"})
+	models, err := RouteModelNames(cfg)
+	if err != nil {
+		t.Fatalf("RouteModelNames() error = %v", err)
+	}
+	want := map[string]string{
+		RouteVectors:       "ollama:nomic-embed-text",
+		RouteKnowledgeBase: "ollama:mxbai-embed-large",
+		RouteEvents:        "ollama:nomic-embed-text",
+		RouteCode:          "ollama:jina/jina-embeddings-v2-base-code",
+	}
+	for kind, model := range want {
+		if models[kind] != model {
+			t.Errorf("RouteModelNames()[%q] = %q, want %q", kind, models[kind], model)
+		}
+	}
+
+	// A route to the default embedder overrides the code-specific model
+	cfg.routes = map[string]string{RouteCode: DefaultEmbedderName}
+	if models, _ := RouteModelNames(cfg); models[RouteCode] != "ollama:nomic-embed-text" {
+		t.Errorf("code routed to default = %q", models[RouteCode])
+	}
+
+	for _, routes := range []map[string]string{{"facts": "docs"}, {RouteEvents: "missing"}} {
+		if _, err := RouteModelNames(newMockRoutingConfig(routes)); err == nil {
+			t.Errorf("RouteModelNames(%v) succeeded, want error", routes)
+		}
+	}
+}
+
+func TestNewRouterFromMainConfig(t *testing.T) {
+	cfg := newMockRoutingConfig(map[string]string{RouteKnowledgeBase: "docs", RouteEvents: "docs"})
+	def, err := NewOllamaEmbedder("http://localhost:11434", "nomic-embed-text")
+	if err != nil {
+		t.Fatalf("NewOllamaEmbedder() error = %v", err)
+	}
+	code, err := NewOllamaEmbedder("http://localhost:11434", "jina/jina-embeddings-v2-base-code")
+	if err != nil {
+		t.Fatalf("NewOllamaEmbedder() error = %v", err)
+	}
+
+	router, err := NewRouterFromMainConfig(cfg, def, code)
+	if err != nil {
+		t.Fatalf("NewRouterFromMainConfig() error = %v", err)
+	}
+	if router.For(RouteVectors) != def {
+		t.Error("vectors should use the default embedder")
+	}
+	if router.For(RouteCode) != code {
+		t.Error("code should use the code embedder")
+	}
+	docs := router.For(RouteKnowledgeBase)
+	if docs == def || docs == code {
+		t.Error("knowledge_base should use the docs embedder")
+	}
+	if router.For(RouteEvents) != docs {
+		t.Error("kinds routed to the same embedder should share one instance")
+	}
+	if got := docs.Dimension(); got != 1024 {
+		t.Errorf("docs embedder dimension = %d, want 1024", got)
+	}
+	if got := len(router.Embedders()); got != 3 {
+		t.Errorf("Embedders() returned %d embedders, want 3", got)
+	}
+}
+
+func TestRouterNamed(t *testing.T) {
+	cfg := newMockRoutingConfig(nil)
+	def, err := NewOllamaEmbedder("http://localhost:11434", "nomic-embed-text")
+	if err != nil {
+		t.Fatalf("NewOllamaEmbedder() error = %v", err)
+	}
+	router, err := NewRouterFromMainConfig(cfg, def, nil)
+	if err != nil {
+		t.Fatalf("NewRouterFromMainConfig() error = %v", err)
+	}
+
+	// Embedders no kind is routed to are created on first use, once
+	docs, err := router.Named("docs")
+	if err != nil {
+		t.Fatalf("Named(docs) error = %v", err)
+	}
+	if docs.Embedder == def || docs.Model != "ollama:mxbai-embed-large" {
+		t.Errorf("Named(docs) = %+v, want the docs embedder", docs)
+	}
+	if again, _ := router.Named("docs"); again.Embedder != docs.Embedder {
+		t.Error("Named(docs) created a second instance")
+	}
+
+	if route, err := router.Named(DefaultEmbedderName); err != nil || route.Embedder != def {
+		t.Errorf("Named(default) = %+v, %v, want the default embedder", route, err)
+	}
+	if _, err := router.Named("missing"); err == nil {
+		t.Error("Named(missing) succeeded, want error")
+	}
+	if got := router.Names(); len(got) != 2 || got[0] != "big" || got[1] != "docs" {
+		t.Errorf("Names() = %v", got)
+	}
+}
+
+func TestRouterRequireDimension(t *testing.T) {
+	def, err := NewOllamaEmbedder("http://localhost:11434", "nomic-embed-text")
+	if err != nil {
+		t.Fatalf("NewOllamaEmbedder() error = %v", err)
+	}
+
+	// A route to a 1024-dimensional model is rejected
+	router, err := NewRouterFromMainConfig(newMockRoutingConfig(map[string]string{RouteKnowledgeBase: "docs"}), def, nil)
+	if err != nil {
+		t.Fatalf("NewRouterFromMainConfig() error = %v", err)
+	}
+	if err := router.RequireDimension(768); err == nil || !strings.Contains(err.Error(), "1024") {
+		t.Errorf("RequireDimension(768) error = %v, want a 1024 dimension mismatch", err)
+	}
+
+	// Without mismatching routes the check passes, and embedders created
+	// later are checked too
+	router, err = NewRouterFromMainConfig(newMockRoutingConfig(nil), def, nil)
+	if err != nil {
+		t.Fatalf("NewRouterFromMainConfig() error = %v", err)
+	}
+	if err := router.RequireDimension(768); err != nil {
+		t.Fatalf("RequireDimension(768) error = %v", err)
+	}
+	if _, err := router.Named("docs"); err == nil || !strings.Contains(err.Error(), "1024") {
+		t.Errorf("Named(docs) error = %v, want a 1024 dimension mismatch", err)
+	}
+	if _, err := router.Named(DefaultEmbedderName); err != nil {
+		t.Errorf("Named(default) error = %v", err)
+	}
+}
+
+func TestNamedConfigValidate(t *testing.T) {
+	if err := (NamedConfig{Name: "a", OllamaModel: "m"}).Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+	for _, n := range []NamedConfig{{Name: "none"}, {Name: "two", OllamaModel: "m", OpenAIModel: "o"}} {
+		if err := n.Validate(); err == nil {
+			t.Errorf("Validate(%+v) succeeded, want error", n)
+		}
+	}
+}
@@ -0,0 +1,650 @@
+// gRPC API of Remembrances MCP, for integrations that need lower overhead than
+// MCP or the REST API. It exposes the storage and search layers behind the
+// MCP tools. Regenerate the Go code with `make proto`.
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v5.29.3
+// source: remembrances/v1/remembrances.proto
+
+package remembrancesv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	RemembrancesService_SaveFact_FullMethodName        = "/remembrances.v1.RemembrancesService/SaveFact"
+	RemembrancesService_GetFact_FullMethodName         = "/remembrances.v1.RemembrancesService/GetFact"
+	RemembrancesService_DeleteFact_FullMethodName      = "/remembrances.v1.RemembrancesService/DeleteFact"
+	RemembrancesService_ListFacts_FullMethodName       = "/remembrances.v1.RemembrancesService/ListFacts"
+	RemembrancesService_AddVector_FullMethodName       = "/remembrances.v1.RemembrancesService/AddVector"
+	RemembrancesService_DeleteVector_FullMethodName    = "/remembrances.v1.RemembrancesService/DeleteVector"
+	RemembrancesService_SearchVectors_FullMethodName   = "/remembrances.v1.RemembrancesService/SearchVectors"
+	RemembrancesService_GetDocument_FullMethodName     = "/remembrances.v1.RemembrancesService/GetDocument"
+	RemembrancesService_SearchDocuments_FullMethodName = "/remembrances.v1.RemembrancesService/SearchDocuments"
+	RemembrancesService_SaveEvent_FullMethodName       = "/remembrances.v1.RemembrancesService/SaveEvent"
+	RemembrancesService_SearchEvents_FullMethodName    = "/remembrances.v1.RemembrancesService/SearchEvents"
+	RemembrancesService_GetEntity_FullMethodName       = "/remembrances.v1.RemembrancesService/GetEntity"
+	RemembrancesService_TraverseGraph_FullMethodName   = "/remembrances.v1.RemembrancesService/TraverseGraph"
+	RemembrancesService_GetStats_FullMethodName        = "/remembrances.v1.RemembrancesService/GetStats"
+)
+
+// RemembrancesServiceClient is the client API for RemembrancesService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// RemembrancesService reads and writes facts, vectors, documents, events and
+// graph entities. Search methods stream their results in relevance order.
+type RemembrancesServiceClient interface {
+	// Facts: key-value memories of a user
+	SaveFact(ctx context.Context, in *SaveFactRequest, opts ...grpc.CallOption) (*SaveFactResponse, error)
+	GetFact(ctx context.Context, in *GetFactRequest, opts ...grpc.CallOption) (*GetFactResponse, error)
+	DeleteFact(ctx context.Context, in *DeleteFactRequest, opts ...grpc.CallOption) (*DeleteFactResponse, error)
+	ListFacts(ctx context.Context, in *ListFactsRequest, opts ...grpc.CallOption) (*ListFactsResponse, error)
+	// Vectors: semantic memories of a user
+	AddVector(ctx context.Context, in *AddVectorRequest, opts ...grpc.CallOption) (*AddVectorResponse, error)
+	DeleteVector(ctx context.Context, in *DeleteVectorRequest, opts ...grpc.CallOption) (*DeleteVectorResponse, error)
+	SearchVectors(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[VectorResult], error)
+	// Knowledge base documents
+	GetDocument(ctx context.Context, in *GetDocumentRequest, opts ...grpc.CallOption) (*Document, error)
+	SearchDocuments(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[DocumentResult], error)
+	// Events: timestamped memories of a user
+	SaveEvent(ctx context.Context, in *SaveEventRequest, opts ...grpc.CallOption) (*SaveEventResponse, error)
+	SearchEvents(ctx context.Context, in *SearchEventsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Event], error)
+	// Knowledge graph
+	GetEntity(ctx context.Context, in *GetEntityRequest, opts ...grpc.CallOption) (*Entity, error)
+	TraverseGraph(ctx context.Context, in *TraverseGraphRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[GraphResult], error)
+	// Statistics
+	GetStats(ctx context.Context, in *GetStatsRequest, opts ...grpc.CallOption) (*Stats, error)
+}
+
+type remembrancesServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewRemembrancesServiceClient(cc grpc.ClientConnInterface) RemembrancesServiceClient {
+	return &remembrancesServiceClient{cc}
+}
+
+func (c *remembrancesServiceClient) SaveFact(ctx context.Context, in *SaveFactRequest, opts ...grpc.CallOption) (*SaveFactResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SaveFactResponse)
+	err := c.cc.Invoke(ctx, RemembrancesService_SaveFact_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *remembrancesServiceClient) GetFact(ctx context.Context, in *GetFactRequest, opts ...grpc.CallOption) (*GetFactResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetFactResponse)
+	err := c.cc.Invoke(ctx, RemembrancesService_GetFact_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *remembrancesServiceClient) DeleteFact(ctx context.Context, in *DeleteFactRequest, opts ...grpc.CallOption) (*DeleteFactResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteFactResponse)
+	err := c.cc.Invoke(ctx, RemembrancesService_DeleteFact_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *remembrancesServiceClient) ListFacts(ctx context.Context, in *ListFactsRequest, opts ...grpc.CallOption) (*ListFactsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListFactsResponse)
+	err := c.cc.Invoke(ctx, RemembrancesService_ListFacts_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *remembrancesServiceClient) AddVector(ctx context.Context, in *AddVectorRequest, opts ...grpc.CallOption) (*AddVectorResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AddVectorResponse)
+	err := c.cc.Invoke(ctx, RemembrancesService_AddVector_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *remembrancesServiceClient) DeleteVector(ctx context.Context, in *DeleteVectorRequest, opts ...grpc.CallOption) (*DeleteVectorResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteVectorResponse)
+	err := c.cc.Invoke(ctx, RemembrancesService_DeleteVector_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *remembrancesServiceClient) SearchVectors(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[VectorResult], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &RemembrancesService_ServiceDesc.Streams[0], RemembrancesService_SearchVectors_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[SearchRequest, VectorResult]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type RemembrancesService_SearchVectorsClient = grpc.ServerStreamingClient[VectorResult]
+
+func (c *remembrancesServiceClient) GetDocument(ctx context.Context, in *GetDocumentRequest, opts ...grpc.CallOption) (*Document, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Document)
+	err := c.cc.Invoke(ctx, RemembrancesService_GetDocument_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *remembrancesServiceClient) SearchDocuments(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[DocumentResult], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &RemembrancesService_ServiceDesc.Streams[1], RemembrancesService_SearchDocuments_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[SearchRequest, DocumentResult]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type RemembrancesService_SearchDocumentsClient = grpc.ServerStreamingClient[DocumentResult]
+
+func (c *remembrancesServiceClient) SaveEvent(ctx context.Context, in *SaveEventRequest, opts ...grpc.CallOption) (*SaveEventResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SaveEventResponse)
+	err := c.cc.Invoke(ctx, RemembrancesService_SaveEvent_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *remembrancesServiceClient) SearchEvents(ctx context.Context, in *SearchEventsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Event], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &RemembrancesService_ServiceDesc.Streams[2], RemembrancesService_SearchEvents_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[SearchEventsRequest, Event]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type RemembrancesService_SearchEventsClient = grpc.ServerStreamingClient[Event]
+
+func (c *remembrancesServiceClient) GetEntity(ctx context.Context, in *GetEntityRequest, opts ...grpc.CallOption) (*Entity, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Entity)
+	err := c.cc.Invoke(ctx, RemembrancesService_GetEntity_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *remembrancesServiceClient) TraverseGraph(ctx context.Context, in *TraverseGraphRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[GraphResult], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &RemembrancesService_ServiceDesc.Streams[3], RemembrancesService_TraverseGraph_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[TraverseGraphRequest, GraphResult]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type RemembrancesService_TraverseGraphClient = grpc.ServerStreamingClient[GraphResult]
+
+func (c *remembrancesServiceClient) GetStats(ctx context.Context, in *GetStatsRequest, opts ...grpc.CallOption) (*Stats, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Stats)
+	err := c.cc.Invoke(ctx, RemembrancesService_GetStats_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// RemembrancesServiceServer is the server API for RemembrancesService service.
+// All implementations must embed UnimplementedRemembrancesServiceServer
+// for forward compatibility.
+//
+// RemembrancesService reads and writes facts, vectors, documents, events and
+// graph entities. Search methods stream their results in relevance order.
+type RemembrancesServiceServer interface {
+	// Facts: key-value memories of a user
+	SaveFact(context.Context, *SaveFactRequest) (*SaveFactResponse, error)
+	GetFact(context.Context, *GetFactRequest) (*GetFactResponse, error)
+	DeleteFact(context.Context, *DeleteFactRequest) (*DeleteFactResponse, error)
+	ListFacts(context.Context, *ListFactsRequest) (*ListFactsResponse, error)
+	// Vectors: semantic memories of a user
+	AddVector(context.Context, *AddVectorRequest) (*AddVectorResponse, error)
+	DeleteVector(context.Context, *DeleteVectorRequest) (*DeleteVectorResponse, error)
+	SearchVectors(*SearchRequest, grpc.ServerStreamingServer[VectorResult]) error
+	// Knowledge base documents
+	GetDocument(context.Context, *GetDocumentRequest) (*Document, error)
+	SearchDocuments(*SearchRequest, grpc.ServerStreamingServer[DocumentResult]) error
+	// Events: timestamped memories of a user
+	SaveEvent(context.Context, *SaveEventRequest) (*SaveEventResponse, error)
+	SearchEvents(*SearchEventsRequest, grpc.ServerStreamingServer[Event]) error
+	// Knowledge graph
+	GetEntity(context.Context, *GetEntityRequest) (*Entity, error)
+	TraverseGraph(*TraverseGraphRequest, grpc.ServerStreamingServer[GraphResult]) error
+	// Statistics
+	GetStats(context.Context, *GetStatsRequest) (*Stats, error)
+	mustEmbedUnimplementedRemembrancesServiceServer()
+}
+
+// UnimplementedRemembrancesServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedRemembrancesServiceServer struct{}
+
+func (UnimplementedRemembrancesServiceServer) SaveFact(context.Context, *SaveFactRequest) (*SaveFactResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SaveFact not implemented")
+}
+func (UnimplementedRemembrancesServiceServer) GetFact(context.Context, *GetFactRequest) (*GetFactResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetFact not implemented")
+}
+func (UnimplementedRemembrancesServiceServer) DeleteFact(context.Context, *DeleteFactRequest) (*DeleteFactResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteFact not implemented")
+}
+func (UnimplementedRemembrancesServiceServer) ListFacts(context.Context, *ListFactsRequest) (*ListFactsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListFacts not implemented")
+}
+func (UnimplementedRemembrancesServiceServer) AddVector(context.Context, *AddVectorRequest) (*AddVectorResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddVector not implemented")
+}
+func (UnimplementedRemembrancesServiceServer) DeleteVector(context.Context, *DeleteVectorRequest) (*DeleteVectorResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteVector not implemented")
+}
+func (UnimplementedRemembrancesServiceServer) SearchVectors(*SearchRequest, grpc.ServerStreamingServer[VectorResult]) error {
+	return status.Errorf(codes.Unimplemented, "method SearchVectors not implemented")
+}
+func (UnimplementedRemembrancesServiceServer) GetDocument(context.Context, *GetDocumentRequest) (*Document, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetDocument not implemented")
+}
+func (UnimplementedRemembrancesServiceServer) SearchDocuments(*SearchRequest, grpc.ServerStreamingServer[DocumentResult]) error {
+	return status.Errorf(codes.Unimplemented, "method SearchDocuments not implemented")
+}
+func (UnimplementedRemembrancesServiceServer) SaveEvent(context.Context, *SaveEventRequest) (*SaveEventResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SaveEvent not implemented")
+}
+func (UnimplementedRemembrancesServiceServer) SearchEvents(*SearchEventsRequest, grpc.ServerStreamingServer[Event]) error {
+	return status.Errorf(codes.Unimplemented, "method SearchEvents not implemented")
+}
+func (UnimplementedRemembrancesServiceServer) GetEntity(context.Context, *GetEntityRequest) (*Entity, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetEntity not implemented")
+}
+func (UnimplementedRemembrancesServiceServer) TraverseGraph(*TraverseGraphRequest, grpc.ServerStreamingServer[GraphResult]) error {
+	return status.Errorf(codes.Unimplemented, "method TraverseGraph not implemented")
+}
+func (UnimplementedRemembrancesServiceServer) GetStats(context.Context, *GetStatsRequest) (*Stats, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetStats not implemented")
+}
+func (UnimplementedRemembrancesServiceServer) mustEmbedUnimplementedRemembrancesServiceServer() {}
+func (UnimplementedRemembrancesServiceServer) testEmbeddedByValue()                             {}
+
+// UnsafeRemembrancesServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to RemembrancesServiceServer will
+// result in compilation errors.
+type UnsafeRemembrancesServiceServer interface {
+	mustEmbedUnimplementedRemembrancesServiceServer()
+}
+
+func RegisterRemembrancesServiceServer(s grpc.ServiceRegistrar, srv RemembrancesServiceServer) {
+	// If the following call pancis, it indicates UnimplementedRemembrancesServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&RemembrancesService_ServiceDesc, srv)
+}
+
+func _RemembrancesService_SaveFact_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SaveFactRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RemembrancesServiceServer).SaveFact(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RemembrancesService_SaveFact_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RemembrancesServiceServer).SaveFact(ctx, req.(*SaveFactRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RemembrancesService_GetFact_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetFactRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RemembrancesServiceServer).GetFact(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RemembrancesService_GetFact_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RemembrancesServiceServer).GetFact(ctx, req.(*GetFactRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RemembrancesService_DeleteFact_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteFactRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RemembrancesServiceServer).DeleteFact(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RemembrancesService_DeleteFact_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RemembrancesServiceServer).DeleteFact(ctx, req.(*DeleteFactRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RemembrancesService_ListFacts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListFactsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RemembrancesServiceServer).ListFacts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RemembrancesService_ListFacts_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RemembrancesServiceServer).ListFacts(ctx, req.(*ListFactsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RemembrancesService_AddVector_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddVectorRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RemembrancesServiceServer).AddVector(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RemembrancesService_AddVector_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RemembrancesServiceServer).AddVector(ctx, req.(*AddVectorRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RemembrancesService_DeleteVector_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteVectorRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RemembrancesServiceServer).DeleteVector(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RemembrancesService_DeleteVector_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RemembrancesServiceServer).DeleteVector(ctx, req.(*DeleteVectorRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RemembrancesService_SearchVectors_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SearchRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(RemembrancesServiceServer).SearchVectors(m, &grpc.GenericServerStream[SearchRequest, VectorResult]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type RemembrancesService_SearchVectorsServer = grpc.ServerStreamingServer[VectorResult]
+
+func _RemembrancesService_GetDocument_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetDocumentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RemembrancesServiceServer).GetDocument(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RemembrancesService_GetDocument_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RemembrancesServiceServer).GetDocument(ctx, req.(*GetDocumentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RemembrancesService_SearchDocuments_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SearchRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(RemembrancesServiceServer).SearchDocuments(m, &grpc.GenericServerStream[SearchRequest, DocumentResult]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type RemembrancesService_SearchDocumentsServer = grpc.ServerStreamingServer[DocumentResult]
+
+func _RemembrancesService_SaveEvent_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SaveEventRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RemembrancesServiceServer).SaveEvent(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RemembrancesService_SaveEvent_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RemembrancesServiceServer).SaveEvent(ctx, req.(*SaveEventRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RemembrancesService_SearchEvents_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SearchEventsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(RemembrancesServiceServer).SearchEvents(m, &grpc.GenericServerStream[SearchEventsRequest, Event]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type RemembrancesService_SearchEventsServer = grpc.ServerStreamingServer[Event]
+
+func _RemembrancesService_GetEntity_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetEntityRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RemembrancesServiceServer).GetEntity(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RemembrancesService_GetEntity_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RemembrancesServiceServer).GetEntity(ctx, req.(*GetEntityRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RemembrancesService_TraverseGraph_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(TraverseGraphRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(RemembrancesServiceServer).TraverseGraph(m, &grpc.GenericServerStream[TraverseGraphRequest, GraphResult]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type RemembrancesService_TraverseGraphServer = grpc.ServerStreamingServer[GraphResult]
+
+func _RemembrancesService_GetStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RemembrancesServiceServer).GetStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RemembrancesService_GetStats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RemembrancesServiceServer).GetStats(ctx, req.(*GetStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// RemembrancesService_ServiceDesc is the grpc.ServiceDesc for RemembrancesService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var RemembrancesService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "remembrances.v1.RemembrancesService",
+	HandlerType: (*RemembrancesServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "SaveFact",
+			Handler:    _RemembrancesService_SaveFact_Handler,
+		},
+		{
+			MethodName: "GetFact",
+			Handler:    _RemembrancesService_GetFact_Handler,
+		},
+		{
+			MethodName: "DeleteFact",
+			Handler:    _RemembrancesService_DeleteFact_Handler,
+		},
+		{
+			MethodName: "ListFacts",
+			Handler:    _RemembrancesService_ListFacts_Handler,
+		},
+		{
+			MethodName: "AddVector",
+			Handler:    _RemembrancesService_AddVector_Handler,
+		},
+		{
+			MethodName: "DeleteVector",
+			Handler:    _RemembrancesService_DeleteVector_Handler,
+		},
+		{
+			MethodName: "GetDocument",
+			Handler:    _RemembrancesService_GetDocument_Handler,
+		},
+		{
+			MethodName: "SaveEvent",
+			Handler:    _RemembrancesService_SaveEvent_Handler,
+		},
+		{
+			MethodName: "GetEntity",
+			Handler:    _RemembrancesService_GetEntity_Handler,
+		},
+		{
+			MethodName: "GetStats",
+			Handler:    _RemembrancesService_GetStats_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "SearchVectors",
+			Handler:       _RemembrancesService_SearchVectors_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "SearchDocuments",
+			Handler:       _RemembrancesService_SearchDocuments_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "SearchEvents",
+			Handler:       _RemembrancesService_SearchEvents_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "TraverseGraph",
+			Handler:       _RemembrancesService_TraverseGraph_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "remembrances/v1/remembrances.proto",
+}
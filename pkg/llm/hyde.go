@@ -0,0 +1,37 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+const hypotheticalPrompt = `You help search an assistant's long-term memory and documents.
+Write a short passage (two to four sentences) that would answer the following
+search query, as it might appear in a note or document. It does not need to be
+correct; it is only used to find similar text. Reply with the passage only,
+without preamble.
+
+Query: %s`
+
+// HypotheticalDocument asks client for a passage answering query, whose
+// embedding is searched alongside the query's (HyDE, hypothetical document
+// embeddings). Terse queries often match stored text better this way.
+func HypotheticalDocument(ctx context.Context, client Client, query string) (string, error) {
+	if client == nil {
+		return "", fmt.Errorf("no llm client configured")
+	}
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return "", fmt.Errorf("query is empty")
+	}
+
+	passage, err := client.Complete(ctx, fmt.Sprintf(hypotheticalPrompt, query))
+	if err != nil {
+		return "", err
+	}
+	if passage == "" {
+		return "", fmt.Errorf("llm returned an empty passage")
+	}
+	return passage, nil
+}
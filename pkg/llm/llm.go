@@ -0,0 +1,113 @@
+// Package llm provides a pluggable text-generation client used for features
+// such as memory consolidation that need an LLM rather than an embedder.
+package llm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/tmc/langchaingo/llms"
+	"github.com/tmc/langchaingo/llms/ollama"
+	"github.com/tmc/langchaingo/llms/openai"
+)
+
+// Supported LLM providers
+const (
+	ProviderOpenAI = "openai" // OpenAI or any OpenAI-compatible chat completions API
+	ProviderOllama = "ollama"
+)
+
+// Client generates text from a prompt.
+type Client interface {
+	Complete(ctx context.Context, prompt string) (string, error)
+}
+
+// Config selects and configures the LLM endpoint.
+type Config struct {
+	Provider string
+	URL      string
+	APIKey   string
+	Model    string
+}
+
+// MainConfig is implemented by the application configuration.
+type MainConfig interface {
+	GetLLMProvider() string
+	GetLLMURL() string
+	GetLLMAPIKey() string
+	GetLLMModel() string
+}
+
+// NewClientFromMainConfig creates a client from the application configuration.
+// It returns (nil, nil) when no provider is configured.
+func NewClientFromMainConfig(mainCfg MainConfig) (Client, error) {
+	if mainCfg == nil {
+		return nil, fmt.Errorf("main configuration is required")
+	}
+	return NewClient(Config{
+		Provider: mainCfg.GetLLMProvider(),
+		URL:      mainCfg.GetLLMURL(),
+		APIKey:   mainCfg.GetLLMAPIKey(),
+		Model:    mainCfg.GetLLMModel(),
+	})
+}
+
+// NewClient creates a client for the configured provider.
+// It returns (nil, nil) when cfg.Provider is empty.
+func NewClient(cfg Config) (Client, error) {
+	provider := strings.ToLower(strings.TrimSpace(cfg.Provider))
+	if provider == "" {
+		return nil, nil
+	}
+	if cfg.Model == "" {
+		return nil, fmt.Errorf("llm model is required for provider %q", provider)
+	}
+
+	switch provider {
+	case ProviderOpenAI:
+		if cfg.APIKey == "" {
+			return nil, fmt.Errorf("llm API key is required for provider %q", provider)
+		}
+		opts := []openai.Option{
+			openai.WithToken(cfg.APIKey),
+			openai.WithModel(cfg.Model),
+		}
+		if cfg.URL != "" {
+			opts = append(opts, openai.WithBaseURL(cfg.URL))
+		}
+		model, err := openai.New(opts...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create OpenAI LLM client: %w", err)
+		}
+		return &langchainClient{model: model}, nil
+	case ProviderOllama:
+		if cfg.URL == "" {
+			return nil, fmt.Errorf("llm URL is required for provider %q", provider)
+		}
+		model, err := ollama.New(
+			ollama.WithServerURL(cfg.URL),
+			ollama.WithModel(cfg.Model),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create Ollama LLM client: %w", err)
+		}
+		return &langchainClient{model: model}, nil
+	default:
+		return nil, fmt.Errorf("unsupported llm provider %q: expected openai or ollama", cfg.Provider)
+	}
+}
+
+// langchainClient adapts a langchaingo model to Client.
+type langchainClient struct {
+	model llms.Model
+}
+
+// Complete sends prompt as a single user message and returns the reply.
+func (c *langchainClient) Complete(ctx context.Context, prompt string) (string, error) {
+	out, err := llms.GenerateFromSinglePrompt(ctx, c.model, prompt, llms.WithTemperature(0.2))
+	if err != nil {
+		return "", fmt.Errorf("llm completion failed: %w", err)
+	}
+	return strings.TrimSpace(out), nil
+}
@@ -0,0 +1,47 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// maxSummaryInputChars bounds the prompt size sent for a single summary.
+const maxSummaryInputChars = 24000
+
+const summarizePrompt = `You consolidate an assistant's long-term memory.
+The following notes are related memories about the same topic. Merge them into
+one concise memory that keeps every distinct fact, decision, preference, name,
+number and date. Drop repetition. Do not add information that is not in the notes.
+Reply with the consolidated memory only, without preamble.
+
+Notes:
+%s`
+
+// SummarizeMemories asks client to merge related memory texts into one.
+func SummarizeMemories(ctx context.Context, client Client, texts []string) (string, error) {
+	if client == nil {
+		return "", fmt.Errorf("no llm client configured")
+	}
+	if len(texts) == 0 {
+		return "", fmt.Errorf("no memories to summarize")
+	}
+
+	var b strings.Builder
+	for i, text := range texts {
+		entry := fmt.Sprintf("%d. %s\n", i+1, strings.TrimSpace(text))
+		if b.Len()+len(entry) > maxSummaryInputChars {
+			break
+		}
+		b.WriteString(entry)
+	}
+
+	summary, err := client.Complete(ctx, fmt.Sprintf(summarizePrompt, b.String()))
+	if err != nil {
+		return "", err
+	}
+	if summary == "" {
+		return "", fmt.Errorf("llm returned an empty summary")
+	}
+	return summary, nil
+}
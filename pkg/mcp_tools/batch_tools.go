@@ -0,0 +1,114 @@
+package mcp_tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/ThinkInAIXYZ/go-mcp/protocol"
+	"github.com/madeindigio/remembrances-mcp/internal/storage"
+	"github.com/madeindigio/remembrances-mcp/pkg/embedder"
+)
+
+// maxBatchOperations bounds the size of a single remembrance_batch call.
+const maxBatchOperations = 100
+
+// Batch tool definition
+func (tm *ToolManager) batchTool() *protocol.Tool {
+	tool, err := protocol.NewTool("remembrance_batch", `Save facts, remembrances, entities and relationships atomically in one transaction. Use how_to_use("remembrance_batch") for details.`, BatchInput{})
+	if err != nil {
+		slog.Error("failed to create tool", "name", "remembrance_batch", "err", err)
+		return nil
+	}
+	return tool
+}
+
+// Batch tool handler
+func (tm *ToolManager) batchHandler(ctx context.Context, request *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+	var input BatchInput
+	if err := json.Unmarshal(request.RawArguments, &input); err != nil {
+		return nil, fmt.Errorf(errParseArgs, err)
+	}
+
+	if len(input.Operations) == 0 {
+		return nil, validationErrorf("operations is required")
+	}
+	if len(input.Operations) > maxBatchOperations {
+		return nil, fmt.Errorf("too many operations: %d (max %d)", len(input.Operations), maxBatchOperations)
+	}
+
+	ops := make([]storage.BatchOperation, len(input.Operations))
+	for i, in := range input.Operations {
+		var err error
+		if in.Value, err = tm.redactContent("remembrance_batch", in.Value); err != nil {
+			return nil, fmt.Errorf("operation %d: %w", i, err)
+		}
+		if in.Content, err = tm.redactContent("remembrance_batch", in.Content); err != nil {
+			return nil, fmt.Errorf("operation %d: %w", i, err)
+		}
+
+		op := storage.BatchOperation{
+			Op:               in.Op,
+			UserID:           in.UserID,
+			Key:              in.Key,
+			Value:            in.Value,
+			Content:          in.Content,
+			Metadata:         in.Metadata.AsMap(),
+			EntityType:       in.EntityType,
+			Name:             in.Name,
+			Properties:       in.Properties.AsMap(),
+			From:             in.FromEntity,
+			To:               in.ToEntity,
+			RelationshipType: in.RelationshipType,
+		}
+		if in.Op == storage.BatchOpAddVector && in.Content != "" {
+			embedding, err := tm.embedderFor(embedder.RouteVectors).EmbedQuery(ctx, in.Content)
+			if err != nil {
+				return nil, embedderErrorf("failed to generate embedding for operation %d: %w", i, err)
+			}
+			op.Embedding = embedding
+		}
+		ops[i] = op
+	}
+
+	results, err := tm.storage.ExecuteBatch(ctx, ops)
+	if err != nil {
+		var batchErr *storage.BatchError
+		if !errors.As(err, &batchErr) {
+			return nil, fmt.Errorf("failed to execute batch: %w", err)
+		}
+
+		opResults := make([]map[string]interface{}, len(ops))
+		for i, op := range ops {
+			opResults[i] = map[string]interface{}{"index": i, "op": op.Op, "status": "rolled_back"}
+			if i == batchErr.Index {
+				opResults[i]["status"] = "failed"
+				opResults[i]["error"] = batchErr.Message
+			}
+		}
+		response := map[string]interface{}{
+			"code":      errorCode(err),
+			"committed": false,
+			"message":   batchErr.Error(),
+			"results":   opResults,
+		}
+		return protocol.NewCallToolResult([]protocol.Content{
+			&protocol.TextContent{Type: "text", Text: MarshalTOON(response)},
+		}, true), nil
+	}
+
+	opResults := make([]map[string]interface{}, len(results))
+	for i, r := range results {
+		opResults[i] = map[string]interface{}{"index": r.Index, "op": r.Op, "status": "ok", "id": r.ID}
+	}
+	response := map[string]interface{}{
+		"committed": true,
+		"message":   fmt.Sprintf("Committed %d operations", len(results)),
+		"results":   opResults,
+	}
+	return protocol.NewCallToolResult([]protocol.Content{
+		&protocol.TextContent{Type: "text", Text: MarshalTOON(response)},
+	}, false), nil
+}
@@ -0,0 +1,174 @@
+package mcp_tools
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/madeindigio/remembrances-mcp/internal/storage"
+)
+
+// Output modes of the search tools. toon is the structured response every
+// tool returns; markdown renders the results as numbered excerpts followed by
+// the sources they cite, ready to paste into an answer.
+const (
+	outputTOON     = "toon"
+	outputMarkdown = "markdown"
+)
+
+// parseOutputMode validates the output argument of a search tool.
+func parseOutputMode(output string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(output)) {
+	case "", outputTOON:
+		return outputTOON, nil
+	case outputMarkdown:
+		return outputMarkdown, nil
+	}
+	return "", validationErrorf("invalid output %q; use toon or markdown", output)
+}
+
+// citation is one numbered source of a markdown answer.
+type citation struct {
+	title   string
+	source  string
+	details []string
+	content string
+}
+
+// documentCitationsMarkdown renders knowledge base search results as markdown
+// citing each chunk's file path, chunk index and similarity.
+func documentCitationsMarkdown(query string, results []storage.DocumentResult) string {
+	citations := make([]citation, 0, len(results))
+	for _, r := range results {
+		if r.Document == nil {
+			continue
+		}
+		path, index, chunked := splitChunkPath(r.Document.FilePath)
+		c := citation{title: path, source: "`" + r.Document.FilePath + "`", content: r.Document.Content}
+		if chunked {
+			chunk := fmt.Sprintf("chunk %d", index)
+			if count, ok := metadataInt(r.Document.Metadata, "chunk_count"); ok {
+				chunk += fmt.Sprintf(" of %d", count)
+			}
+			c.details = append(c.details, chunk)
+		}
+		if version, ok := metadataInt(r.Document.Metadata, "version"); ok && r.Document.Metadata["archived"] == true {
+			c.details = append(c.details, fmt.Sprintf("archived version %d", version))
+		}
+		c.details = append(c.details, similarityDetails(r.Similarity, r.Score)...)
+		citations = append(citations, c)
+	}
+	return citationsMarkdown(query, citations)
+}
+
+// hybridCitationsMarkdown renders hybrid search results as markdown citing
+// the memories, graph entities and facts they came from.
+func hybridCitationsMarkdown(query string, results *storage.HybridSearchResult) string {
+	var citations []citation
+	for _, r := range results.VectorResults {
+		citations = append(citations, citation{
+			title:   "Memory " + r.ID,
+			source:  "memory `" + r.ID + "`",
+			details: similarityDetails(r.Similarity, r.Score),
+			content: r.Content,
+		})
+	}
+	for _, r := range results.GraphResults {
+		if r.Entity == nil {
+			continue
+		}
+		c := citation{
+			title:   fmt.Sprintf("%s (%s)", r.Entity.Name, r.Entity.Type),
+			source:  "entity `" + r.Entity.ID + "`",
+			details: []string{fmt.Sprintf("depth %d", r.Depth)},
+			content: r.Entity.Name,
+		}
+		if r.Relationship != nil {
+			c.details = append(c.details, "via "+r.Relationship.Type)
+		}
+		if len(r.Path) > 0 {
+			c.content = strings.Join(r.Path, " -> ")
+		}
+		citations = append(citations, c)
+	}
+	keys := make([]string, 0, len(results.Facts))
+	for key := range results.Facts {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		citations = append(citations, citation{
+			title:   "Fact " + key,
+			source:  "fact `" + key + "`",
+			content: fmt.Sprintf("%s: %v", key, results.Facts[key]),
+		})
+	}
+	return citationsMarkdown(query, citations)
+}
+
+// citationsMarkdown writes each citation as a numbered excerpt, then the
+// numbered list of sources.
+func citationsMarkdown(query string, citations []citation) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "## Results for %q\n", query)
+	for i, c := range citations {
+		fmt.Fprintf(&b, "\n### [%d] %s\n\n", i+1, c.title)
+		for _, line := range strings.Split(strings.TrimSpace(c.content), "\n") {
+			if line = strings.TrimRight(line, " \t\r"); line == "" {
+				b.WriteString(">\n")
+			} else {
+				b.WriteString("> " + line + "\n")
+			}
+		}
+	}
+	b.WriteString("\n## Sources\n\n")
+	for i, c := range citations {
+		fmt.Fprintf(&b, "[%d] %s", i+1, c.source)
+		if len(c.details) > 0 {
+			b.WriteString(" (" + strings.Join(c.details, ", ") + ")")
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// similarityDetails describes a result's similarity, and its score when
+// score normalization made it differ.
+func similarityDetails(similarity, score float64) []string {
+	details := []string{fmt.Sprintf("similarity %.3f", similarity)}
+	if score != 0 && fmt.Sprintf("%.3f", score) != fmt.Sprintf("%.3f", similarity) {
+		details = append(details, fmt.Sprintf("score %.3f", score))
+	}
+	return details
+}
+
+// splitChunkPath splits the file path of a document chunk, "path#chunkN",
+// into the document path and the chunk index.
+func splitChunkPath(filePath string) (string, int, bool) {
+	i := strings.LastIndex(filePath, "#chunk")
+	if i < 0 {
+		return filePath, 0, false
+	}
+	index, err := strconv.Atoi(filePath[i+len("#chunk"):])
+	if err != nil {
+		return filePath, 0, false
+	}
+	return filePath[:i], index, true
+}
+
+// metadataInt returns a numeric metadata value, whichever number type the
+// storage decoded it as.
+func metadataInt(metadata map[string]interface{}, key string) (int, bool) {
+	switch v := metadata[key].(type) {
+	case int:
+		return v, true
+	case int64:
+		return int(v), true
+	case uint64:
+		return int(v), true
+	case float64:
+		return int(v), true
+	}
+	return 0, false
+}
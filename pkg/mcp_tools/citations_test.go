@@ -0,0 +1,97 @@
+package mcp_tools
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/madeindigio/remembrances-mcp/internal/storage"
+)
+
+func TestParseOutputMode(t *testing.T) {
+	for input, want := range map[string]string{"": outputTOON, "toon": outputTOON, "Markdown": outputMarkdown} {
+		got, err := parseOutputMode(input)
+		if err != nil || got != want {
+			t.Errorf("parseOutputMode(%q) = %q, %v; want %q", input, got, err, want)
+		}
+	}
+	if _, err := parseOutputMode("html"); err == nil {
+		t.Error("parseOutputMode(html) succeeded, want a validation error")
+	}
+}
+
+func TestDocumentCitationsMarkdown(t *testing.T) {
+	results := []storage.DocumentResult{
+		{
+			Document: &storage.Document{
+				FilePath: "docs/auth.md#chunk2",
+				Content:  "Set AUTH_TOKEN.\n\nRestart the server.",
+				Metadata: map[string]interface{}{"chunk_index": 2, "chunk_count": float64(5)},
+			},
+			Similarity: 0.8721,
+			Score:      0.8721,
+		},
+		{
+			Document: &storage.Document{
+				FilePath: "notes.md",
+				Content:  "Tokens expire daily.",
+				Metadata: map[string]interface{}{"archived": true, "version": 3},
+			},
+			Similarity: 0.61,
+			Score:      1,
+		},
+	}
+	want := `## Results for "auth"
+
+### [1] docs/auth.md
+
+> Set AUTH_TOKEN.
+>
+> Restart the server.
+
+### [2] notes.md
+
+> Tokens expire daily.
+
+## Sources
+
+[1] ` + "`docs/auth.md#chunk2`" + ` (chunk 2 of 5, similarity 0.872)
+[2] ` + "`notes.md`" + ` (archived version 3, similarity 0.610, score 1.000)
+`
+	if got := documentCitationsMarkdown("auth", results); got != want {
+		t.Errorf("documentCitationsMarkdown() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestHybridCitationsMarkdown(t *testing.T) {
+	results := &storage.HybridSearchResult{
+		VectorResults: []storage.VectorResult{{ID: "vector_memories:a", Content: "Alice owns the billing service.", Similarity: 0.9, Score: 0.9}},
+		GraphResults: []storage.GraphResult{{
+			Entity: &storage.Entity{ID: "entities:alice", Name: "Alice", Type: "person"},
+			Path:   []string{"Alice", "billing"},
+			Depth:  1,
+		}},
+		Facts: map[string]interface{}{"team": "payments", "oncall": "alice"},
+	}
+	got := hybridCitationsMarkdown("billing owner", results)
+	for _, want := range []string{
+		"### [1] Memory vector_memories:a\n\n> Alice owns the billing service.\n",
+		"### [2] Alice (person)\n\n> Alice -> billing\n",
+		"### [3] Fact oncall\n",
+		"[1] memory `vector_memories:a` (similarity 0.900)\n",
+		"[2] entity `entities:alice` (depth 1)\n",
+		"[4] fact `team`\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("hybridCitationsMarkdown() missing %q in\n%s", want, got)
+		}
+	}
+}
+
+func TestSplitChunkPath(t *testing.T) {
+	if path, index, ok := splitChunkPath("a/b.md#chunk12"); path != "a/b.md" || index != 12 || !ok {
+		t.Errorf("splitChunkPath(chunk) = %q, %d, %v", path, index, ok)
+	}
+	if path, _, ok := splitChunkPath("a/b.md#chunkx"); path != "a/b.md#chunkx" || ok {
+		t.Errorf("splitChunkPath(invalid) = %q, %v", path, ok)
+	}
+}
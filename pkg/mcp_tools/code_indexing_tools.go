@@ -9,10 +9,13 @@ import (
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"path/filepath"
+	"strings"
 
 	"github.com/ThinkInAIXYZ/go-mcp/protocol"
 	"github.com/madeindigio/remembrances-mcp/internal/indexer"
 	"github.com/madeindigio/remembrances-mcp/internal/storage"
+	"github.com/madeindigio/remembrances-mcp/pkg/treesitter"
 )
 
 // ====== Tool Manager ======
@@ -35,6 +38,7 @@ func NewCodeToolManager(tm *ToolManager, jm *indexer.JobManager, wm *indexer.Wat
 
 // RegisterCodeTools registers all code indexing tools
 func (ctm *CodeToolManager) RegisterCodeTools(reg func(string, *protocol.Tool, func(context.Context, *protocol.CallToolRequest) (*protocol.CallToolResult, error)) error) error {
+	reg = withProjectAliases(ctm.storage, withErrorCodes(reg))
 	if err := reg("code_index_project", ctm.codeIndexProjectTool(), ctm.codeIndexProjectHandler); err != nil {
 		return err
 	}
@@ -50,12 +54,27 @@ func (ctm *CodeToolManager) RegisterCodeTools(reg func(string, *protocol.Tool, f
 	if err := reg("code_reindex_file", ctm.codeReindexFileTool(), ctm.codeReindexFileHandler); err != nil {
 		return err
 	}
+	if err := reg("code_touch", ctm.codeTouchTool(), ctm.codeTouchHandler); err != nil {
+		return err
+	}
 	if err := reg("code_get_project_stats", ctm.codeGetProjectStatsTool(), ctm.codeGetProjectStatsHandler); err != nil {
 		return err
 	}
 	if err := reg("code_get_file_symbols", ctm.codeGetFileSymbolsTool(), ctm.codeGetFileSymbolsHandler); err != nil {
 		return err
 	}
+	if err := reg("code_index_report", ctm.codeIndexReportTool(), ctm.codeIndexReportHandler); err != nil {
+		return err
+	}
+	if err := reg("code_set_project_alias", ctm.codeSetProjectAliasTool(), ctm.codeSetProjectAliasHandler); err != nil {
+		return err
+	}
+	if err := reg("code_remap_project", ctm.codeRemapProjectTool(), ctm.codeRemapProjectHandler); err != nil {
+		return err
+	}
+	if err := reg("code_configure_project", ctm.codeConfigureProjectTool(), ctm.codeConfigureProjectHandler); err != nil {
+		return err
+	}
 	// Register watch tools only if watcher manager is available
 	if ctm.watcherManager != nil {
 		if err := reg("code_activate_project_watch", ctm.codeActivateProjectWatchTool(), ctm.codeActivateProjectWatchHandler); err != nil {
@@ -121,6 +140,15 @@ func (ctm *CodeToolManager) codeReindexFileTool() *protocol.Tool {
 	return tool
 }
 
+func (ctm *CodeToolManager) codeTouchTool() *protocol.Tool {
+	tool, err := protocol.NewTool("code_touch", `Report files being opened or edited so indexing processes them before the rest of the project. Use how_to_use("code_touch") for details.`, CodeTouchInput{})
+	if err != nil {
+		slog.Error("failed to create tool", "name", "code_touch", "err", err)
+		return nil
+	}
+	return tool
+}
+
 func (ctm *CodeToolManager) codeGetProjectStatsTool() *protocol.Tool {
 	tool, err := protocol.NewTool("code_get_project_stats", `Get project statistics. Use how_to_use("code_get_project_stats") for details.`, CodeGetProjectStatsInput{})
 	if err != nil {
@@ -148,12 +176,12 @@ func (ctm *CodeToolManager) codeIndexProjectHandler(ctx context.Context, req *pr
 	}
 
 	if input.ProjectPath == "" {
-		return nil, fmt.Errorf("project_path is required")
+		return nil, validationErrorf("project_path is required")
 	}
 
 	// TODO: Handle languages filter when implemented in JobManager
 
-	job, err := ctm.jobManager.SubmitJob(input.ProjectPath, input.ProjectName)
+	job, err := ctm.jobManager.SubmitJob(ctx, input.ProjectPath, input.ProjectName)
 	if err != nil {
 		return nil, fmt.Errorf("failed to start indexing: %w", err)
 	}
@@ -180,29 +208,34 @@ func (ctm *CodeToolManager) codeIndexStatusHandler(ctx context.Context, req *pro
 
 	if input.JobID != "" {
 		// Get specific job status
-		job, err := ctm.jobManager.GetJobStatus(input.JobID)
+		job, err := ctm.jobManager.GetJobStatus(ctx, input.JobID)
 		if err != nil {
 			return nil, err
 		}
 
 		result = map[string]interface{}{
-			"job_id":        job.ID,
-			"project_id":    job.ProjectID,
-			"project_path":  job.ProjectPath,
-			"status":        string(job.Status),
-			"progress":      job.Progress,
-			"files_total":   job.FilesTotal,
-			"files_indexed": job.FilesIndexed,
-			"symbols_found": job.SymbolsFound,
-			"started_at":    job.StartedAt.Format("2006-01-02T15:04:05Z07:00"),
-			"error":         job.Error,
+			"job_id":            job.ID,
+			"project_id":        job.ProjectID,
+			"project_path":      job.ProjectPath,
+			"status":            string(job.Status),
+			"progress":          job.Progress,
+			"files_total":       job.FilesTotal,
+			"files_indexed":     job.FilesIndexed,
+			"symbols_found":     job.SymbolsFound,
+			"files_prioritized": job.FilesPrioritized,
+			"started_at":        job.StartedAt.Format("2006-01-02T15:04:05Z07:00"),
+			"error":             job.Error,
+		}
+		// Jobs read back from storage after a restart have no limits
+		if job.Limits != (indexer.IndexingLimits{}) {
+			result.(map[string]interface{})["limits"] = job.Limits
 		}
 		if job.CompletedAt != nil {
 			result.(map[string]interface{})["completed_at"] = job.CompletedAt.Format("2006-01-02T15:04:05Z07:00")
 		}
 	} else {
 		// List all active jobs
-		jobs := ctm.jobManager.ListActiveJobs()
+		jobs := ctm.jobManager.ListActiveJobs(ctx)
 		jobList := make([]map[string]interface{}, 0, len(jobs))
 
 		for _, job := range jobs {
@@ -220,6 +253,7 @@ func (ctm *CodeToolManager) codeIndexStatusHandler(ctx context.Context, req *pro
 		result = map[string]interface{}{
 			"active_jobs": jobList,
 			"count":       len(jobList),
+			"limits":      ctm.jobManager.Limits(),
 		}
 	}
 
@@ -261,7 +295,7 @@ func (ctm *CodeToolManager) codeDeleteProjectHandler(ctx context.Context, req *p
 	}
 
 	if input.ProjectID == "" {
-		return nil, fmt.Errorf("project_id is required")
+		return nil, validationErrorf("project_id is required")
 	}
 
 	// Use indexer to delete project
@@ -286,7 +320,7 @@ func (ctm *CodeToolManager) codeReindexFileHandler(ctx context.Context, req *pro
 	}
 
 	if input.ProjectID == "" || input.FilePath == "" {
-		return nil, fmt.Errorf("project_id and file_path are required")
+		return nil, validationErrorf("project_id and file_path are required")
 	}
 
 	if err := ctm.jobManager.GetIndexer().ReindexFile(ctx, input.ProjectID, input.FilePath); err != nil {
@@ -304,6 +338,63 @@ func (ctm *CodeToolManager) codeReindexFileHandler(ctx context.Context, req *pro
 	}, false), nil
 }
 
+func (ctm *CodeToolManager) codeTouchHandler(ctx context.Context, req *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+	var input CodeTouchInput
+	if err := json.Unmarshal(req.RawArguments, &input); err != nil {
+		return nil, fmt.Errorf("failed to parse input: %w", err)
+	}
+
+	if input.ProjectID == "" || len(input.FilePaths) == 0 {
+		return nil, validationErrorf("project_id and file_paths are required")
+	}
+
+	codeStorage, ok := ctm.storage.(interface {
+		GetCodeProject(ctx context.Context, projectID string) (*storage.CodeProject, error)
+	})
+	if !ok {
+		return nil, fmt.Errorf("storage does not support code operations")
+	}
+	project, err := codeStorage.GetCodeProject(ctx, input.ProjectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get project: %w", err)
+	}
+	if project == nil {
+		return nil, notFoundErrorf("project %s not found", input.ProjectID)
+	}
+
+	paths := make([]string, 0, len(input.FilePaths))
+	for _, p := range input.FilePaths {
+		if filepath.IsAbs(p) {
+			rel, err := filepath.Rel(project.RootPath, p)
+			if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+				return nil, validationErrorf("file %s is outside the project root %s", p, project.RootPath)
+			}
+			p = rel
+		}
+		paths = append(paths, filepath.ToSlash(p))
+	}
+
+	idx := ctm.jobManager.GetIndexer()
+	idx.Touch(project.ProjectID, paths...)
+
+	progress := idx.GetProgress(project.ProjectID)
+	indexing := progress != nil && progress.Status == treesitter.IndexingStatusInProgress
+	result := map[string]interface{}{
+		"project_id": project.ProjectID,
+		"touched":    paths,
+		"indexing":   indexing,
+	}
+	if indexing {
+		result["message"] = "The running indexing job of the project processes these files next"
+	} else {
+		result["message"] = "No indexing job is running for the project; one started within the hour processes these files first. Use code_reindex_file to update a file now."
+	}
+
+	return protocol.NewCallToolResult([]protocol.Content{
+		&protocol.TextContent{Type: "text", Text: MarshalTOON(result)},
+	}, false), nil
+}
+
 func (ctm *CodeToolManager) codeGetProjectStatsHandler(ctx context.Context, req *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
 	var input CodeGetProjectStatsInput
 	if err := json.Unmarshal(req.RawArguments, &input); err != nil {
@@ -311,7 +402,7 @@ func (ctm *CodeToolManager) codeGetProjectStatsHandler(ctx context.Context, req
 	}
 
 	if input.ProjectID == "" {
-		return nil, fmt.Errorf("project_id is required")
+		return nil, validationErrorf("project_id is required")
 	}
 
 	// Get the code storage interface
@@ -329,7 +420,7 @@ func (ctm *CodeToolManager) codeGetProjectStatsHandler(ctx context.Context, req
 		return nil, fmt.Errorf("failed to get project: %w", err)
 	}
 	if project == nil {
-		return nil, fmt.Errorf("project not found: %s", input.ProjectID)
+		return nil, notFoundErrorf("project not found: %s", input.ProjectID)
 	}
 
 	// Get stats
@@ -367,7 +458,7 @@ func (ctm *CodeToolManager) codeGetFileSymbolsHandler(ctx context.Context, req *
 	}
 
 	if input.ProjectID == "" || input.RelativePath == "" {
-		return nil, fmt.Errorf("project_id and relative_path are required")
+		return nil, validationErrorf("project_id and relative_path are required")
 	}
 
 	// Get the code storage interface
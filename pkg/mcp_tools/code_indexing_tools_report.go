@@ -0,0 +1,289 @@
+// Package mcp_tools provides code indexing MCP tools.
+// This file contains code_index_report, which explains what the index of a
+// project is missing.
+package mcp_tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"sort"
+
+	"github.com/ThinkInAIXYZ/go-mcp/protocol"
+	"github.com/madeindigio/remembrances-mcp/internal/indexer"
+	"github.com/madeindigio/remembrances-mcp/internal/storage"
+	"github.com/madeindigio/remembrances-mcp/pkg/treesitter"
+)
+
+// defaultIndexReportLimit is the number of files and symbols listed per
+// section of code_index_report.
+const defaultIndexReportLimit = 50
+
+// fileIssueNotIndexed is a file found in the project with no index record
+// and no recorded failure: it failed before the server started, or has not
+// been indexed yet.
+const fileIssueNotIndexed = "not_indexed"
+
+// codeIndexReport is the result of code_index_report.
+type codeIndexReport struct {
+	ProjectID                string                 `json:"project_id"`
+	RootPath                 string                 `json:"root_path"`
+	IndexingStatus           string                 `json:"indexing_status"`
+	LastIndexedAt            string                 `json:"last_indexed_at,omitempty"`
+	ScanError                string                 `json:"scan_error,omitempty"`
+	Files                    codeReportFiles        `json:"files"`
+	LanguageCoverage         []codeLanguageCoverage `json:"language_coverage,omitempty"`
+	ParseFailures            codeReportIssues       `json:"parse_failures"`
+	Skipped                  codeReportSkipped      `json:"skipped"`
+	SymbolsWithoutEmbeddings codeReportSymbols      `json:"symbols_without_embeddings"`
+	ChunkedSymbols           codeReportSymbols      `json:"chunked_symbols"`
+}
+
+// codeReportFiles counts the files of a project.
+type codeReportFiles struct {
+	Found      int `json:"found"`
+	Indexed    int `json:"indexed"`
+	NotIndexed int `json:"not_indexed"`
+	Skipped    int `json:"skipped"`
+}
+
+// codeLanguageCoverage is the share of the files of a language found in the
+// project that are indexed.
+type codeLanguageCoverage struct {
+	Language        treesitter.Language `json:"language"`
+	Found           int                 `json:"found"`
+	Indexed         int                 `json:"indexed"`
+	CoveragePercent float64             `json:"coverage_percent"`
+}
+
+// codeReportIssues lists the files that failed to index, are missing from
+// the index or had syntax errors.
+type codeReportIssues struct {
+	Count int                 `json:"count"`
+	Files []indexer.FileIssue `json:"files,omitempty"`
+}
+
+// codeReportSkipped lists the files the scanner skipped, and why.
+type codeReportSkipped struct {
+	Count    int              `json:"count"`
+	ByReason map[string]int   `json:"by_reason,omitempty"`
+	Files    []codeReportSkip `json:"files,omitempty"`
+}
+
+// codeReportSkip is a skipped file or directory.
+type codeReportSkip struct {
+	FilePath string `json:"file_path"`
+	Reason   string `json:"reason"`
+}
+
+// codeReportSymbols lists indexed symbols.
+type codeReportSymbols struct {
+	Count   int                `json:"count"`
+	Symbols []codeReportSymbol `json:"symbols,omitempty"`
+}
+
+// codeReportSymbol is a symbol listed in a report.
+type codeReportSymbol struct {
+	NamePath   string                `json:"name_path"`
+	FilePath   string                `json:"file_path"`
+	SymbolType treesitter.SymbolType `json:"symbol_type"`
+	StartLine  int                   `json:"start_line"`
+	EndLine    int                   `json:"end_line"`
+	Chunks     int                   `json:"chunks,omitempty"`
+}
+
+func (ctm *CodeToolManager) codeIndexReportTool() *protocol.Tool {
+	tool, err := protocol.NewTool("code_index_report", `Report why code searches may miss things: parse failures, skipped files, symbols without embeddings, chunked symbols and language coverage. Use how_to_use("code_index_report") for details.`, CodeIndexReportInput{})
+	if err != nil {
+		slog.Error("failed to create tool", "name", "code_index_report", "err", err)
+		return nil
+	}
+	return tool
+}
+
+func (ctm *CodeToolManager) codeIndexReportHandler(ctx context.Context, req *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+	var input CodeIndexReportInput
+	if err := json.Unmarshal(req.RawArguments, &input); err != nil {
+		return nil, fmt.Errorf("failed to parse input: %w", err)
+	}
+
+	if input.ProjectID == "" {
+		return nil, validationErrorf("project_id is required")
+	}
+	if input.Limit <= 0 {
+		input.Limit = defaultIndexReportLimit
+	}
+
+	codeStorage, ok := ctm.storage.(interface {
+		GetCodeProject(ctx context.Context, projectID string) (*storage.CodeProject, error)
+		ListCodeFiles(ctx context.Context, projectID string) ([]storage.CodeFile, error)
+		ListCodeSymbols(ctx context.Context, projectID string) ([]storage.CodeSymbol, error)
+		ListUnembeddedCodeSymbols(ctx context.Context, projectID string) ([]storage.CodeSymbol, error)
+		CountCodeChunksBySymbol(ctx context.Context, projectID string) (map[string]int, error)
+	})
+	if !ok {
+		return nil, fmt.Errorf("storage does not support code operations")
+	}
+
+	project, err := codeStorage.GetCodeProject(ctx, input.ProjectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get project: %w", err)
+	}
+	if project == nil {
+		return nil, notFoundErrorf("project not found: %s", input.ProjectID)
+	}
+
+	report := codeIndexReport{
+		ProjectID:      project.ProjectID,
+		RootPath:       project.RootPath,
+		IndexingStatus: string(project.IndexingStatus),
+	}
+	if project.LastIndexedAt != nil {
+		report.LastIndexedAt = project.LastIndexedAt.Format("2006-01-02T15:04:05Z07:00")
+	}
+
+	storedFiles, err := codeStorage.ListCodeFiles(ctx, input.ProjectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files: %w", err)
+	}
+	report.Files.Indexed = len(storedFiles)
+
+	// The files the indexer would index now, and those it skips
+	idx := ctm.jobManager.GetIndexer()
+	issues := idx.FileIssues(input.ProjectID)
+	scan, err := idx.GetScanner().Scan(project.RootPath)
+	if err != nil {
+		report.ScanError = err.Error()
+	} else {
+		report.Files.Found = scan.TotalFiles
+		report.Files.Skipped = len(scan.Skipped) + scan.SkippedReason["unsupported_extension"]
+		report.LanguageCoverage = languageCoverage(scan.Files, storedFiles)
+		issues = append(issues, notIndexedFiles(scan.Files, storedFiles, issues)...)
+		sort.SliceStable(issues, func(i, j int) bool { return issues[i].Path < issues[j].Path })
+
+		report.Skipped = codeReportSkipped{Count: report.Files.Skipped, ByReason: scan.SkippedReason}
+		for _, skipped := range scan.Skipped[:min(len(scan.Skipped), input.Limit)] {
+			report.Skipped.Files = append(report.Skipped.Files, codeReportSkip{FilePath: skipped.RelPath, Reason: skipped.Reason})
+		}
+	}
+	for _, issue := range issues {
+		if issue.Kind != indexer.FileIssueSyntaxErrors {
+			report.Files.NotIndexed++
+		}
+	}
+	report.ParseFailures = codeReportIssues{Count: len(issues), Files: issues[:min(len(issues), input.Limit)]}
+
+	unembedded, err := codeStorage.ListUnembeddedCodeSymbols(ctx, input.ProjectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list symbols without embeddings: %w", err)
+	}
+	report.SymbolsWithoutEmbeddings.Count = len(unembedded)
+	for _, sym := range unembedded[:min(len(unembedded), input.Limit)] {
+		report.SymbolsWithoutEmbeddings.Symbols = append(report.SymbolsWithoutEmbeddings.Symbols, reportSymbol(sym, 0))
+	}
+
+	chunkCounts, err := codeStorage.CountCodeChunksBySymbol(ctx, input.ProjectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count chunks: %w", err)
+	}
+	if len(chunkCounts) > 0 {
+		symbols, err := codeStorage.ListCodeSymbols(ctx, input.ProjectID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list symbols: %w", err)
+		}
+		report.ChunkedSymbols = chunkedSymbols(input.ProjectID, symbols, chunkCounts, input.Limit)
+	}
+
+	return protocol.NewCallToolResult([]protocol.Content{
+		&protocol.TextContent{Type: "text", Text: MarshalTOON(report)},
+	}, false), nil
+}
+
+// languageCoverage returns, for each language of the files found in a
+// project, how many are indexed, the languages with most files first.
+func languageCoverage(found []indexer.ScannedFile, stored []storage.CodeFile) []codeLanguageCoverage {
+	indexed := make(map[string]bool, len(stored))
+	for _, f := range stored {
+		indexed[f.FilePath] = true
+	}
+
+	byLanguage := map[treesitter.Language]*codeLanguageCoverage{}
+	for _, f := range found {
+		c := byLanguage[f.Language]
+		if c == nil {
+			c = &codeLanguageCoverage{Language: f.Language}
+			byLanguage[f.Language] = c
+		}
+		c.Found++
+		if indexed[f.RelPath] {
+			c.Indexed++
+		}
+	}
+
+	coverage := make([]codeLanguageCoverage, 0, len(byLanguage))
+	for _, c := range byLanguage {
+		c.CoveragePercent = math.Round(float64(c.Indexed)/float64(c.Found)*1000) / 10
+		coverage = append(coverage, *c)
+	}
+	sort.Slice(coverage, func(i, j int) bool {
+		if coverage[i].Found != coverage[j].Found {
+			return coverage[i].Found > coverage[j].Found
+		}
+		return coverage[i].Language < coverage[j].Language
+	})
+	return coverage
+}
+
+// notIndexedFiles returns the files found in a project that are not indexed
+// and have no recorded failure.
+func notIndexedFiles(found []indexer.ScannedFile, stored []storage.CodeFile, issues []indexer.FileIssue) []indexer.FileIssue {
+	known := make(map[string]bool, len(stored)+len(issues))
+	for _, f := range stored {
+		known[f.FilePath] = true
+	}
+	for _, issue := range issues {
+		if issue.Kind == indexer.FileIssueFailed {
+			known[issue.Path] = true
+		}
+	}
+
+	var missing []indexer.FileIssue
+	for _, f := range found {
+		if !known[f.RelPath] {
+			missing = append(missing, indexer.FileIssue{
+				Path:  f.RelPath,
+				Kind:  fileIssueNotIndexed,
+				Error: "not in the index: it failed before the server started or is waiting to be indexed",
+			})
+		}
+	}
+	return missing
+}
+
+// chunkedSymbols lists the symbols of a project split in chunks, those with
+// most chunks first.
+func chunkedSymbols(projectID string, symbols []storage.CodeSymbol, chunkCounts map[string]int, limit int) codeReportSymbols {
+	var chunked []codeReportSymbol
+	for _, sym := range symbols {
+		// Chunks reference their symbol as "<project>:<file>:<name path>"
+		if n := chunkCounts[projectID+":"+sym.FilePath+":"+sym.NamePath]; n > 0 {
+			chunked = append(chunked, reportSymbol(sym, n))
+		}
+	}
+	sort.SliceStable(chunked, func(i, j int) bool { return chunked[i].Chunks > chunked[j].Chunks })
+	return codeReportSymbols{Count: len(chunkCounts), Symbols: chunked[:min(len(chunked), limit)]}
+}
+
+// reportSymbol returns the report entry of a symbol.
+func reportSymbol(sym storage.CodeSymbol, chunks int) codeReportSymbol {
+	return codeReportSymbol{
+		NamePath:   sym.NamePath,
+		FilePath:   sym.FilePath,
+		SymbolType: sym.SymbolType,
+		StartLine:  sym.StartLine,
+		EndLine:    sym.EndLine,
+		Chunks:     chunks,
+	}
+}
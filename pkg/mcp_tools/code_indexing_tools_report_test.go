@@ -0,0 +1,50 @@
+package mcp_tools
+
+import (
+	"testing"
+
+	"github.com/madeindigio/remembrances-mcp/internal/indexer"
+	"github.com/madeindigio/remembrances-mcp/internal/storage"
+	"github.com/madeindigio/remembrances-mcp/pkg/treesitter"
+)
+
+func TestIndexReportFiles(t *testing.T) {
+	found := []indexer.ScannedFile{
+		{RelPath: "a.go", Language: treesitter.LanguageGo},
+		{RelPath: "b.go", Language: treesitter.LanguageGo},
+		{RelPath: "c.go", Language: treesitter.LanguageGo},
+		{RelPath: "app.ts", Language: treesitter.LanguageTypeScript},
+	}
+	stored := []storage.CodeFile{{FilePath: "a.go"}, {FilePath: "app.ts"}, {FilePath: "deleted.go"}}
+	issues := []indexer.FileIssue{{Path: "b.go", Kind: indexer.FileIssueFailed}}
+
+	coverage := languageCoverage(found, stored)
+	if len(coverage) != 2 || coverage[0].Language != treesitter.LanguageGo || coverage[0].Indexed != 1 || coverage[0].CoveragePercent != 33.3 {
+		t.Errorf("unexpected coverage %+v", coverage)
+	}
+	if coverage[1].CoveragePercent != 100 {
+		t.Errorf("expected full typescript coverage, got %+v", coverage[1])
+	}
+
+	missing := notIndexedFiles(found, stored, issues)
+	if len(missing) != 1 || missing[0].Path != "c.go" || missing[0].Kind != fileIssueNotIndexed {
+		t.Errorf("expected c.go to be reported as not indexed, got %+v", missing)
+	}
+}
+
+func TestChunkedSymbols(t *testing.T) {
+	symbols := []storage.CodeSymbol{
+		{FilePath: "a.go", NamePath: "/Small"},
+		{FilePath: "a.go", NamePath: "/Big"},
+		{FilePath: "b.go", NamePath: "/Huge"},
+	}
+	counts := map[string]int{"app:a.go:/Big": 3, "app:b.go:/Huge": 7, "app:gone.go:/Old": 2}
+
+	chunked := chunkedSymbols("app", symbols, counts, 10)
+	if chunked.Count != 3 || len(chunked.Symbols) != 2 || chunked.Symbols[0].NamePath != "/Huge" || chunked.Symbols[1].Chunks != 3 {
+		t.Errorf("unexpected chunked symbols %+v", chunked)
+	}
+	if limited := chunkedSymbols("app", symbols, counts, 1); len(limited.Symbols) != 1 {
+		t.Errorf("expected the limit to apply, got %+v", limited.Symbols)
+	}
+}
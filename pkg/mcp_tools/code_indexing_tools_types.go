@@ -30,11 +30,23 @@ type CodeReindexFileInput struct {
 	FilePath  string `json:"file_path" description:"Relative path to the file within the project."`
 }
 
+// CodeTouchInput represents input for code_touch tool
+type CodeTouchInput struct {
+	ProjectID string   `json:"project_id" description:"The project ID (or alias) containing the files."`
+	FilePaths []string `json:"file_paths" description:"Paths of the files being opened or edited, relative to the project root or absolute."`
+}
+
 // CodeGetProjectStatsInput represents input for code_get_project_stats tool
 type CodeGetProjectStatsInput struct {
 	ProjectID string `json:"project_id" description:"The project ID to get statistics for."`
 }
 
+// CodeIndexReportInput represents input for code_index_report tool
+type CodeIndexReportInput struct {
+	ProjectID string `json:"project_id" description:"The project ID to report on."`
+	Limit     int    `json:"limit,omitempty" description:"Maximum files or symbols listed per section (default 50). Counts always cover everything."`
+}
+
 // CodeGetFileSymbolsInput represents input for code_get_file_symbols tool
 type CodeGetFileSymbolsInput struct {
 	ProjectID    string `json:"project_id" description:"The project ID containing the file."`
@@ -42,6 +54,27 @@ type CodeGetFileSymbolsInput struct {
 	IncludeBody  bool   `json:"include_body,omitempty" description:"Whether to include the source code body of each symbol."`
 }
 
+// CodeSetProjectAliasInput represents input for code_set_project_alias tool
+type CodeSetProjectAliasInput struct {
+	ProjectID string `json:"project_id" description:"The project ID (or current alias) of the project."`
+	Alias     string `json:"alias" description:"Short name the code tools accept as project_id, e.g. 'backend'. Letters, digits, '.', '_' and '-'. Empty removes the alias."`
+}
+
+// CodeRemapProjectInput represents input for code_remap_project tool
+type CodeRemapProjectInput struct {
+	ProjectID   string `json:"project_id" description:"The project ID (or alias) of the moved project."`
+	NewRootPath string `json:"new_root_path" description:"Absolute path of the project checkout in its new location."`
+}
+
+// CodeConfigureProjectInput represents input for code_configure_project tool
+type CodeConfigureProjectInput struct {
+	ProjectID     string  `json:"project_id" description:"The project ID (or alias) to configure."`
+	Embedder      *string `json:"embedder,omitempty" description:"Name of a configured embedder to index and search the project with. Empty to use the server's code embedder."`
+	ChunkSize     *int    `json:"chunk_size,omitempty" description:"Characters of the chunks long symbols are split into, between 200 and 16000. 0 for the default (800)."`
+	ChunkOverlap  *int    `json:"chunk_overlap,omitempty" description:"Characters consecutive chunks share; must be less than chunk_size. 0 for the default (100)."`
+	MaxSymbolSize *int    `json:"max_symbol_size,omitempty" description:"Characters of source code stored per symbol, at least 1000. 0 for the server's limit."`
+}
+
 // CodeActivateProjectWatchInput represents input for code_activate_project_watch tool
 type CodeActivateProjectWatchInput struct {
 	ProjectID string `json:"project_id" description:"The project ID to start monitoring for file changes."`
@@ -0,0 +1,177 @@
+// Package mcp_tools provides code manipulation MCP tools.
+// This file contains the unified diff used to report file modifications.
+package mcp_tools
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// diffContextLines is the number of unchanged lines shown around each change
+	diffContextLines = 3
+
+	// maxDiffCells bounds the LCS table built for the changed region (old lines x new lines)
+	maxDiffCells = 4_000_000
+)
+
+// diffLine is a line of a diff: ' ' unchanged, '-' removed or '+' added
+type diffLine struct {
+	op   byte
+	text string
+}
+
+// unifiedDiff returns a unified diff from oldContent to newContent, with
+// a/ and b/ headers for path, or an empty string when they are equal
+func unifiedDiff(path string, oldContent, newContent []byte) string {
+	if string(oldContent) == string(newContent) {
+		return ""
+	}
+
+	lines := diffLines(strings.SplitAfter(string(oldContent), "\n"), strings.SplitAfter(string(newContent), "\n"))
+
+	// oldPos[k] and newPos[k] count the old and new lines before lines[k]
+	oldPos := make([]int, len(lines)+1)
+	newPos := make([]int, len(lines)+1)
+	for k, l := range lines {
+		oldPos[k+1], newPos[k+1] = oldPos[k], newPos[k]
+		if l.op != '+' {
+			oldPos[k+1]++
+		}
+		if l.op != '-' {
+			newPos[k+1]++
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- a/%s\n+++ b/%s\n", path, path)
+
+	next := 0
+	for {
+		change := next
+		for change < len(lines) && lines[change].op == ' ' {
+			change++
+		}
+		if change == len(lines) {
+			break
+		}
+
+		// Extend the hunk over changes separated by little enough context
+		start := max(change-diffContextLines, next)
+		end := change
+		for {
+			for end < len(lines) && lines[end].op != ' ' {
+				end++
+			}
+			following := end
+			for following < len(lines) && lines[following].op == ' ' {
+				following++
+			}
+			if following < len(lines) && following-end <= 2*diffContextLines {
+				end = following
+				continue
+			}
+			end = min(end+diffContextLines, len(lines))
+			break
+		}
+
+		fmt.Fprintf(&b, "@@ -%s +%s @@\n",
+			hunkRange(oldPos[start], oldPos[end]-oldPos[start]),
+			hunkRange(newPos[start], newPos[end]-newPos[start]))
+		for _, l := range lines[start:end] {
+			b.WriteByte(l.op)
+			b.WriteString(l.text)
+			if !strings.HasSuffix(l.text, "\n") {
+				b.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+		next = end
+	}
+
+	return b.String()
+}
+
+// hunkRange formats the start,count of a hunk side; empty sides start at the
+// line before the hunk
+func hunkRange(before, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", before)
+	}
+	return fmt.Sprintf("%d,%d", before+1, count)
+}
+
+// diffLines returns the line edits turning oldLines into newLines. The common
+// prefix and suffix are matched first, so symbol edits only compare the
+// changed region.
+func diffLines(oldLines, newLines []string) []diffLine {
+	// SplitAfter leaves an empty element after a trailing newline
+	if n := len(oldLines); n > 0 && oldLines[n-1] == "" {
+		oldLines = oldLines[:n-1]
+	}
+	if n := len(newLines); n > 0 && newLines[n-1] == "" {
+		newLines = newLines[:n-1]
+	}
+
+	prefix := 0
+	for prefix < len(oldLines) && prefix < len(newLines) && oldLines[prefix] == newLines[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(oldLines)-prefix && suffix < len(newLines)-prefix &&
+		oldLines[len(oldLines)-1-suffix] == newLines[len(newLines)-1-suffix] {
+		suffix++
+	}
+
+	lines := make([]diffLine, 0, len(oldLines)+len(newLines)-prefix-suffix)
+	for _, l := range oldLines[:prefix] {
+		lines = append(lines, diffLine{op: ' ', text: l})
+	}
+
+	oldMid := oldLines[prefix : len(oldLines)-suffix]
+	newMid := newLines[prefix : len(newLines)-suffix]
+	if len(oldMid)*len(newMid) > maxDiffCells {
+		// Too large to align: replace the whole region
+		for _, l := range oldMid {
+			lines = append(lines, diffLine{op: '-', text: l})
+		}
+		for _, l := range newMid {
+			lines = append(lines, diffLine{op: '+', text: l})
+		}
+	} else {
+		// lcs[i][j] is the LCS length of oldMid[i:] and newMid[j:]
+		lcs := make([][]int, len(oldMid)+1)
+		for i := range lcs {
+			lcs[i] = make([]int, len(newMid)+1)
+		}
+		for i := len(oldMid) - 1; i >= 0; i-- {
+			for j := len(newMid) - 1; j >= 0; j-- {
+				if oldMid[i] == newMid[j] {
+					lcs[i][j] = lcs[i+1][j+1] + 1
+				} else {
+					lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+				}
+			}
+		}
+
+		i, j := 0, 0
+		for i < len(oldMid) || j < len(newMid) {
+			switch {
+			case i < len(oldMid) && j < len(newMid) && oldMid[i] == newMid[j]:
+				lines = append(lines, diffLine{op: ' ', text: oldMid[i]})
+				i++
+				j++
+			case i < len(oldMid) && (j == len(newMid) || lcs[i+1][j] >= lcs[i][j+1]):
+				lines = append(lines, diffLine{op: '-', text: oldMid[i]})
+				i++
+			default:
+				lines = append(lines, diffLine{op: '+', text: newMid[j]})
+				j++
+			}
+		}
+	}
+
+	for _, l := range oldLines[len(oldLines)-suffix:] {
+		lines = append(lines, diffLine{op: ' ', text: l})
+	}
+	return lines
+}
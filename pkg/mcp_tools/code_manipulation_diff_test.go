@@ -0,0 +1,60 @@
+package mcp_tools
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	lines := func(from, to int) string {
+		var b strings.Builder
+		for i := from; i <= to; i++ {
+			b.WriteString("line" + string(rune('a'+i-1)) + "\n")
+		}
+		return b.String()
+	}
+
+	cases := []struct {
+		name string
+		old  string
+		new  string
+		want string
+	}{
+		{
+			name: "unchanged",
+			old:  "a\nb\n",
+			new:  "a\nb\n",
+			want: "",
+		},
+		{
+			name: "replace in middle",
+			old:  lines(1, 10),
+			new:  lines(1, 4) + "changed\n" + lines(6, 10),
+			want: "--- a/f.go\n+++ b/f.go\n@@ -2,7 +2,7 @@\n lineb\n linec\n lined\n-linee\n+changed\n linef\n lineg\n lineh\n",
+		},
+		{
+			name: "separate hunks",
+			old:  lines(1, 12),
+			new:  "first\n" + lines(2, 11) + "last\n",
+			want: "--- a/f.go\n+++ b/f.go\n@@ -1,4 +1,4 @@\n-linea\n+first\n lineb\n linec\n lined\n@@ -9,4 +9,4 @@\n linei\n linej\n linek\n-linel\n+last\n",
+		},
+		{
+			name: "insert at start of empty side",
+			old:  "",
+			new:  "a\n",
+			want: "--- a/f.go\n+++ b/f.go\n@@ -0,0 +1,1 @@\n+a\n",
+		},
+		{
+			name: "missing final newline",
+			old:  "a\nb",
+			new:  "a\nc",
+			want: "--- a/f.go\n+++ b/f.go\n@@ -1,2 +1,2 @@\n a\n-b\n\\ No newline at end of file\n+c\n\\ No newline at end of file\n",
+		},
+	}
+
+	for _, tc := range cases {
+		if got := unifiedDiff("f.go", []byte(tc.old), []byte(tc.new)); got != tc.want {
+			t.Errorf("%s: unifiedDiff() =\n%s\nwant:\n%s", tc.name, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,460 @@
+// Package mcp_tools provides code manipulation MCP tools.
+// This file contains the patch and regex editing tools, for edits that do not
+// map onto whole symbols.
+package mcp_tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/ThinkInAIXYZ/go-mcp/protocol"
+	"github.com/madeindigio/remembrances-mcp/internal/storage"
+	"github.com/madeindigio/remembrances-mcp/pkg/treesitter"
+)
+
+// hunkHeaderPattern matches "@@ -a,b +c,d @@" hunk headers; counts are optional
+var hunkHeaderPattern = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@`)
+
+// patchHunk is a hunk of a unified diff
+type patchHunk struct {
+	oldStart int
+	lines    []diffLine
+
+	// oldNoEOL and newNoEOL are set when the last old or new line of the
+	// hunk has no trailing newline
+	oldNoEOL bool
+	newNoEOL bool
+}
+
+// filePatch holds the hunks of a unified diff for one file
+type filePatch struct {
+	path  string
+	hunks []patchHunk
+}
+
+// parsePatch splits a unified diff into per-file hunks. Hunks before any file
+// header are attributed to defaultPath.
+func parsePatch(patch, defaultPath string) ([]filePatch, error) {
+	var files []filePatch
+	var current *filePatch
+	var hunk *patchHunk
+	oldPath := ""
+
+	lines := strings.Split(strings.ReplaceAll(patch, "\r\n", "\n"), "\n")
+	for i, line := range lines {
+		switch {
+		case strings.HasPrefix(line, "--- ") && (hunk == nil || i+1 < len(lines) && strings.HasPrefix(lines[i+1], "+++ ")):
+			oldPath = patchPath(line[4:], "a/")
+			hunk = nil
+		case strings.HasPrefix(line, "+++ ") && hunk == nil:
+			newPath := patchPath(line[4:], "b/")
+			if newPath == "/dev/null" || oldPath == "/dev/null" {
+				return nil, fmt.Errorf("creating or deleting files is not supported: %s", line[4:])
+			}
+			files = append(files, filePatch{path: newPath})
+			current = &files[len(files)-1]
+		case strings.HasPrefix(line, "@@"):
+			m := hunkHeaderPattern.FindStringSubmatch(line)
+			if m == nil {
+				return nil, validationErrorf("invalid hunk header: %s", line)
+			}
+			if current == nil {
+				if defaultPath == "" {
+					return nil, validationErrorf("patch has no file headers: relative_path is required")
+				}
+				files = append(files, filePatch{path: defaultPath})
+				current = &files[len(files)-1]
+			}
+			oldStart, _ := strconv.Atoi(m[1])
+			current.hunks = append(current.hunks, patchHunk{oldStart: oldStart})
+			hunk = &current.hunks[len(current.hunks)-1]
+		case hunk == nil:
+			// "diff --git", "index" and other extended headers
+			continue
+		case strings.HasPrefix(line, `\`):
+			// "\ No newline at end of file" applies to the previous line
+			if n := len(hunk.lines); n > 0 {
+				if hunk.lines[n-1].op != '+' {
+					hunk.oldNoEOL = true
+				}
+				if hunk.lines[n-1].op != '-' {
+					hunk.newNoEOL = true
+				}
+			}
+		case line == "":
+			// Editors strip the space of empty context lines; a trailing
+			// empty line ends the patch
+			if i < len(lines)-1 {
+				hunk.lines = append(hunk.lines, diffLine{op: ' '})
+			}
+		case line[0] == ' ' || line[0] == '-' || line[0] == '+':
+			hunk.lines = append(hunk.lines, diffLine{op: line[0], text: line[1:]})
+		default:
+			return nil, validationErrorf("invalid patch line: %s", line)
+		}
+	}
+
+	if len(files) == 0 {
+		return nil, fmt.Errorf("patch contains no hunks")
+	}
+	for _, f := range files {
+		if len(f.hunks) == 0 {
+			return nil, fmt.Errorf("patch for %s contains no hunks", f.path)
+		}
+	}
+	return files, nil
+}
+
+// patchPath returns the path of a ---/+++ header without its a/ or b/ prefix
+// and trailing timestamp
+func patchPath(header, prefix string) string {
+	if tab := strings.IndexByte(header, '\t'); tab >= 0 {
+		header = header[:tab]
+	}
+	header = strings.TrimSpace(header)
+	if header == "/dev/null" {
+		return header
+	}
+	return strings.TrimPrefix(header, prefix)
+}
+
+// applyHunks applies the hunks of a file patch to content. Each hunk is
+// located at its recorded line or, if the file moved, at the nearest offset
+// where its context and removed lines match.
+func applyHunks(content []byte, hunks []patchHunk) ([]byte, error) {
+	text := string(content)
+	trailingNewline := strings.HasSuffix(text, "\n")
+	lines := strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+	if text == "" {
+		lines = nil
+	}
+
+	// next is the first line a following hunk may touch; delta is the line
+	// shift introduced by the hunks applied so far
+	next, delta := 0, 0
+	for n, hunk := range hunks {
+		var oldBlock, newBlock []string
+		for _, l := range hunk.lines {
+			if l.op != '+' {
+				oldBlock = append(oldBlock, l.text)
+			}
+			if l.op != '-' {
+				newBlock = append(newBlock, l.text)
+			}
+		}
+
+		// Pure insertions are placed after line oldStart
+		want := hunk.oldStart - 1 + delta
+		if len(oldBlock) == 0 {
+			want = hunk.oldStart + delta
+		}
+		pos := findBlock(lines, oldBlock, want, next)
+		if pos < 0 {
+			return nil, fmt.Errorf("hunk %d (line %d) does not match the file", n+1, hunk.oldStart)
+		}
+
+		end := pos + len(oldBlock)
+		updated := make([]string, 0, len(lines)-len(oldBlock)+len(newBlock))
+		updated = append(updated, lines[:pos]...)
+		updated = append(updated, newBlock...)
+		updated = append(updated, lines[end:]...)
+
+		if end == len(lines) {
+			switch {
+			case hunk.newNoEOL:
+				trailingNewline = false
+			case hunk.oldNoEOL || len(lines) == 0:
+				trailingNewline = true
+			}
+		}
+
+		lines = updated
+		next = pos + len(newBlock)
+		delta += len(newBlock) - len(oldBlock)
+	}
+
+	result := strings.Join(lines, "\n")
+	if trailingNewline && len(lines) > 0 {
+		result += "\n"
+	}
+	return []byte(result), nil
+}
+
+// findBlock returns the line where block occurs in lines, searching from
+// want outwards and never before from, or -1
+func findBlock(lines, block []string, want, from int) int {
+	matches := func(pos int) bool {
+		if pos < from || pos+len(block) > len(lines) {
+			return false
+		}
+		for i, l := range block {
+			if lines[pos+i] != l {
+				return false
+			}
+		}
+		return true
+	}
+
+	for offset := 0; offset <= len(lines); offset++ {
+		if matches(want - offset) {
+			return want - offset
+		}
+		if offset > 0 && matches(want+offset) {
+			return want + offset
+		}
+	}
+	return -1
+}
+
+// resolveFile resolves a project file for editing
+func (cmtm *CodeManipulationToolManager) resolveFile(ctx context.Context, projectID, relativePath string) (*symbolInfo, error) {
+	codeStorage, ok := cmtm.storage.(interface {
+		GetCodeProject(ctx context.Context, projectID string) (*storage.CodeProject, error)
+	})
+	if !ok {
+		return nil, fmt.Errorf("storage does not support code operations")
+	}
+
+	project, err := codeStorage.GetCodeProject(ctx, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get project: %w", err)
+	}
+	if project == nil {
+		return nil, notFoundErrorf("project not found: %s", projectID)
+	}
+
+	absPath, err := projectFilePath(project.RootPath, relativePath)
+	if err != nil {
+		return nil, err
+	}
+
+	lang, _ := treesitter.GetLanguageByExtension(strings.TrimPrefix(filepath.Ext(absPath), "."))
+	return &symbolInfo{
+		ProjectID:    projectID,
+		FilePath:     filepath.Clean(filepath.FromSlash(relativePath)),
+		Language:     lang,
+		RootPath:     project.RootPath,
+		AbsolutePath: absPath,
+	}, nil
+}
+
+// ====== Tool Handlers ======
+
+func (cmtm *CodeManipulationToolManager) codeApplyPatchHandler(ctx context.Context, req *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+	var input CodeApplyPatchInput
+	if err := json.Unmarshal(req.RawArguments, &input); err != nil {
+		return nil, fmt.Errorf("failed to parse input: %w", err)
+	}
+
+	if input.ProjectID == "" || input.Patch == "" {
+		return nil, validationErrorf("project_id and patch are required")
+	}
+
+	patches, err := parsePatch(input.Patch, input.RelativePath)
+	if err != nil {
+		return nil, validationErrorf("invalid patch: %w", err)
+	}
+	if input.RelativePath != "" && len(patches) == 1 {
+		patches[0].path = input.RelativePath
+	}
+
+	type patchedFile struct {
+		file       *symbolInfo
+		content    []byte
+		newContent []byte
+		diff       string
+	}
+
+	// Apply and validate every file before writing any, so a patch is
+	// applied entirely or not at all
+	patched := make([]patchedFile, 0, len(patches))
+	for _, p := range patches {
+		file, err := cmtm.resolveFile(ctx, input.ProjectID, p.path)
+		if err != nil {
+			return nil, err
+		}
+		content, err := os.ReadFile(file.AbsolutePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read file: %w", err)
+		}
+		newContent, err := applyHunks(content, p.hunks)
+		if err != nil {
+			return nil, validationErrorf("failed to apply patch to %s: %w", file.FilePath, err)
+		}
+		if err := cmtm.validateSyntax(ctx, file, content, newContent); err != nil {
+			var invalid *invalidEdit
+			if errors.As(err, &invalid) {
+				return invalidEditResult(invalid), nil
+			}
+			return nil, err
+		}
+		patched = append(patched, patchedFile{
+			file:       file,
+			content:    content,
+			newContent: newContent,
+			diff:       unifiedDiff(file.FilePath, content, newContent),
+		})
+	}
+
+	files := make([]map[string]interface{}, 0, len(patched))
+	for i, p := range patched {
+		files = append(files, map[string]interface{}{
+			"file_path": p.file.FilePath,
+			"hunks":     len(patches[i].hunks),
+			"diff":      p.diff,
+		})
+	}
+	result := map[string]interface{}{
+		"message": "Patch applied successfully",
+		"files":   files,
+	}
+
+	if input.DryRun {
+		return dryRunResult(result, "Patch not applied (dry run)"), nil
+	}
+
+	// restore reverts the files written so far
+	restore := func(written []patchedFile) {
+		for _, p := range written {
+			if err := os.WriteFile(p.file.AbsolutePath, p.content, 0644); err != nil {
+				slog.Error("failed to revert file after failed patch", "file", p.file.FilePath, "error", err)
+			}
+		}
+	}
+
+	for i, p := range patched {
+		if err := os.WriteFile(p.file.AbsolutePath, p.newContent, 0644); err != nil {
+			restore(patched[:i])
+			return nil, fmt.Errorf("failed to write file: %w", err)
+		}
+	}
+	for _, p := range patched {
+		if err := cmtm.runCheckCommand(ctx, p.file); err != nil {
+			restore(patched)
+			var invalid *invalidEdit
+			if errors.As(err, &invalid) {
+				return invalidEditResult(invalid), nil
+			}
+			return nil, err
+		}
+	}
+
+	// Re-index files
+	for _, p := range patched {
+		if err := cmtm.reindexFile(ctx, p.file.ProjectID, p.file.FilePath, p.file.AbsolutePath, p.file.Language); err != nil {
+			slog.Warn("failed to reindex file after patch", "file", p.file.FilePath, "error", err)
+		}
+	}
+
+	return protocol.NewCallToolResult([]protocol.Content{
+		&protocol.TextContent{Type: "text", Text: MarshalTOON(result)},
+	}, false), nil
+}
+
+func (cmtm *CodeManipulationToolManager) codeReplaceRegexHandler(ctx context.Context, req *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+	var input CodeReplaceRegexInput
+	if err := json.Unmarshal(req.RawArguments, &input); err != nil {
+		return nil, fmt.Errorf("failed to parse input: %w", err)
+	}
+
+	if input.ProjectID == "" || input.Pattern == "" {
+		return nil, validationErrorf("project_id and pattern are required")
+	}
+	if input.RelativePath == "" && input.SymbolID == "" && input.NamePath == "" {
+		return nil, validationErrorf("either relative_path, symbol_id or name_path is required")
+	}
+
+	re, err := regexp.Compile(input.Pattern)
+	if err != nil {
+		return nil, validationErrorf("invalid pattern: %w", err)
+	}
+
+	// Scope the replacement to a symbol or to the whole file
+	var target *symbolInfo
+	if input.SymbolID != "" || input.NamePath != "" {
+		target, err = cmtm.resolveSymbol(ctx, input.ProjectID, input.SymbolID, input.NamePath, input.RelativePath)
+	} else {
+		target, err = cmtm.resolveFile(ctx, input.ProjectID, input.RelativePath)
+	}
+	if err != nil {
+		return nil, err
+	}
+	scoped := input.SymbolID != "" || input.NamePath != ""
+
+	// Count bounds the replacements; FindAll takes -1 for all matches
+	count := input.Count
+	if count <= 0 {
+		count = -1
+	}
+
+	replacements := 0
+	diff, err := cmtm.modifyFile(ctx, target, input.DryRun, func(content []byte) ([]byte, error) {
+		start, end := 0, len(content)
+		if scoped {
+			if target.StartByte < 0 || target.EndByte > len(content) || target.StartByte > target.EndByte {
+				return nil, validationErrorf("invalid byte range: %d-%d (file size: %d)", target.StartByte, target.EndByte, len(content))
+			}
+			start, end = target.StartByte, target.EndByte
+		}
+
+		region := content[start:end]
+		matches := re.FindAllSubmatchIndex(region, count)
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("pattern %q matched nothing", input.Pattern)
+		}
+		replacements = len(matches)
+
+		var replaced []byte
+		last := 0
+		for _, m := range matches {
+			replaced = append(replaced, region[last:m[0]]...)
+			replaced = re.Expand(replaced, []byte(input.Replacement), region, m)
+			last = m[1]
+		}
+		replaced = append(replaced, region[last:]...)
+
+		newContent := make([]byte, 0, len(content)-len(region)+len(replaced))
+		newContent = append(newContent, content[:start]...)
+		newContent = append(newContent, replaced...)
+		newContent = append(newContent, content[end:]...)
+		return newContent, nil
+	})
+	if err != nil {
+		var invalid *invalidEdit
+		if errors.As(err, &invalid) {
+			return invalidEditResult(invalid), nil
+		}
+		return nil, fmt.Errorf("failed to modify file: %w", err)
+	}
+
+	result := map[string]interface{}{
+		"message":      "Pattern replaced successfully",
+		"file_path":    target.FilePath,
+		"replacements": replacements,
+		"diff":         diff,
+	}
+	if scoped {
+		result["name_path"] = target.NamePath
+	}
+
+	if input.DryRun {
+		return dryRunResult(result, "Replacement not applied (dry run)"), nil
+	}
+
+	// Re-index file
+	if err := cmtm.reindexFile(ctx, target.ProjectID, target.FilePath, target.AbsolutePath, target.Language); err != nil {
+		slog.Warn("failed to reindex file after regex replacement", "file", target.FilePath, "error", err)
+	}
+
+	return protocol.NewCallToolResult([]protocol.Content{
+		&protocol.TextContent{Type: "text", Text: MarshalTOON(result)},
+	}, false), nil
+}
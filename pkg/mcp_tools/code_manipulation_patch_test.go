@@ -0,0 +1,78 @@
+package mcp_tools
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestApplyPatchRoundTrip(t *testing.T) {
+	numbered := func(n int) string {
+		var b strings.Builder
+		for i := 1; i <= n; i++ {
+			b.WriteString("line " + strings.Repeat("x", i) + "\n")
+		}
+		return b.String()
+	}
+	base := numbered(20)
+
+	cases := []struct {
+		name string
+		old  string
+		new  string
+	}{
+		{"replace", base, strings.Replace(base, "line xxxxx\n", "changed\n", 1)},
+		{"insert and delete", base, "first\n" + strings.Replace(base, "line xxxxxxxxxxxxxxx\n", "", 1)},
+		{"append", base, base + "tail\n"},
+		{"drop final newline", "a\nb\n", "a\nc"},
+		{"add final newline", "a\nb", "a\nb\n"},
+		{"empty file", "", "package main\n"},
+	}
+
+	for _, tc := range cases {
+		patches, err := parsePatch(unifiedDiff("f.go", []byte(tc.old), []byte(tc.new)), "")
+		if err != nil {
+			t.Fatalf("%s: parsePatch: %v", tc.name, err)
+		}
+		if len(patches) != 1 || patches[0].path != "f.go" {
+			t.Fatalf("%s: unexpected patches %+v", tc.name, patches)
+		}
+		got, err := applyHunks([]byte(tc.old), patches[0].hunks)
+		if err != nil {
+			t.Fatalf("%s: applyHunks: %v", tc.name, err)
+		}
+		if string(got) != tc.new {
+			t.Errorf("%s: got %q; want %q", tc.name, got, tc.new)
+		}
+	}
+}
+
+func TestApplyPatchOffsetAndMismatch(t *testing.T) {
+	patch := "@@ -2,3 +2,3 @@\n b\n-c\n+C\n d\n"
+	patches, err := parsePatch(patch, "main.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if patches[0].path != "main.go" {
+		t.Fatalf("path = %q; want main.go", patches[0].path)
+	}
+
+	// Lines were added above the hunk since the patch was made
+	got, err := applyHunks([]byte("x\ny\na\nb\nc\nd\ne\n"), patches[0].hunks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "x\ny\na\nb\nC\nd\ne\n"; string(got) != want {
+		t.Errorf("got %q; want %q", got, want)
+	}
+
+	if _, err := applyHunks([]byte("a\nb\nz\nd\n"), patches[0].hunks); err == nil {
+		t.Error("mismatched hunk applied")
+	}
+
+	if _, err := parsePatch(patch, ""); err == nil {
+		t.Error("patch without headers or relative_path accepted")
+	}
+	if _, err := parsePatch("--- /dev/null\n+++ b/new.go\n@@ -0,0 +1 @@\n+x\n", ""); err == nil {
+		t.Error("file creation accepted")
+	}
+}
@@ -6,6 +6,7 @@ package mcp_tools
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
@@ -14,6 +15,7 @@ import (
 
 	"github.com/ThinkInAIXYZ/go-mcp/protocol"
 	"github.com/madeindigio/remembrances-mcp/internal/storage"
+	"github.com/madeindigio/remembrances-mcp/pkg/embedder"
 	"github.com/madeindigio/remembrances-mcp/pkg/treesitter"
 )
 
@@ -27,6 +29,8 @@ type CodeManipulationToolManager struct {
 	embedder interface {
 		EmbedDocuments(ctx context.Context, texts []string) ([][]float32, error)
 	}
+	checkCommands map[treesitter.Language]string
+	router        *embedder.Router // Named embedders projects may pick
 }
 
 // NewCodeManipulationToolManager creates a new code manipulation tool manager
@@ -47,8 +51,15 @@ func NewCodeManipulationToolManager(s storage.Storage, embedder interface {
 	}
 }
 
+// SetEmbeddingRouter configures the named embedders code projects may pick,
+// so edited symbols are embedded by the model of their project.
+func (cmtm *CodeManipulationToolManager) SetEmbeddingRouter(router *embedder.Router) {
+	cmtm.router = router
+}
+
 // RegisterCodeManipulationTools registers all code manipulation tools
 func (cmtm *CodeManipulationToolManager) RegisterCodeManipulationTools(reg func(string, *protocol.Tool, func(context.Context, *protocol.CallToolRequest) (*protocol.CallToolResult, error)) error) error {
+	reg = withProjectAliases(cmtm.storage, withErrorCodes(reg))
 	if err := reg("code_replace_symbol", cmtm.codeReplaceSymbolTool(), cmtm.codeReplaceSymbolHandler); err != nil {
 		return err
 	}
@@ -61,6 +72,12 @@ func (cmtm *CodeManipulationToolManager) RegisterCodeManipulationTools(reg func(
 	if err := reg("code_delete_symbol", cmtm.codeDeleteSymbolTool(), cmtm.codeDeleteSymbolHandler); err != nil {
 		return err
 	}
+	if err := reg("code_apply_patch", cmtm.codeApplyPatchTool(), cmtm.codeApplyPatchHandler); err != nil {
+		return err
+	}
+	if err := reg("code_replace_regex", cmtm.codeReplaceRegexTool(), cmtm.codeReplaceRegexHandler); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -102,6 +119,24 @@ func (cmtm *CodeManipulationToolManager) codeDeleteSymbolTool() *protocol.Tool {
 	return tool
 }
 
+func (cmtm *CodeManipulationToolManager) codeApplyPatchTool() *protocol.Tool {
+	tool, err := protocol.NewTool("code_apply_patch", `Apply a unified diff to project files. Use how_to_use("code_apply_patch") for details.`, CodeApplyPatchInput{})
+	if err != nil {
+		slog.Error("failed to create tool", "name", "code_apply_patch", "err", err)
+		return nil
+	}
+	return tool
+}
+
+func (cmtm *CodeManipulationToolManager) codeReplaceRegexTool() *protocol.Tool {
+	tool, err := protocol.NewTool("code_replace_regex", `Replace regex matches in a file or symbol. Use how_to_use("code_replace_regex") for details.`, CodeReplaceRegexInput{})
+	if err != nil {
+		slog.Error("failed to create tool", "name", "code_replace_regex", "err", err)
+		return nil
+	}
+	return tool
+}
+
 // ====== Helper Types and Functions ======
 
 // symbolInfo holds resolved symbol information
@@ -115,7 +150,9 @@ type symbolInfo struct {
 	StartLine    int
 	EndLine      int
 	Language     treesitter.Language
+	RootPath     string
 	AbsolutePath string
+	Revision     string
 }
 
 // resolveSymbol finds a symbol by ID or name_path
@@ -136,16 +173,16 @@ func (cmtm *CodeManipulationToolManager) resolveSymbol(ctx context.Context, proj
 		query = `SELECT * FROM $symbol_id;`
 		params["symbol_id"] = symbolID
 	} else if namePath != "" && relativePath != "" {
-		// Query by name_path and file_path
-		query = `SELECT * FROM code_symbols WHERE project_id = $project_id AND name_path = $name_path AND file_path = $file_path LIMIT 1;`
-		params["name_path"] = namePath
+		// Query by name_path and file_path, with the overloads of the symbol
+		query = `SELECT * FROM code_symbols WHERE project_id = $project_id AND ` + namePathOverloads + ` AND file_path = $file_path ORDER BY start_line;`
+		setNamePathOverloads(params, namePath)
 		params["file_path"] = relativePath
 	} else if namePath != "" {
-		// Query by name_path only (may return multiple)
-		query = `SELECT * FROM code_symbols WHERE project_id = $project_id AND name_path = $name_path LIMIT 1;`
-		params["name_path"] = namePath
+		// Query by name_path only, with the overloads of the symbol
+		query = `SELECT * FROM code_symbols WHERE project_id = $project_id AND ` + namePathOverloads + ` ORDER BY file_path, start_line;`
+		setNamePathOverloads(params, namePath)
 	} else {
-		return nil, fmt.Errorf("either symbol_id or name_path is required")
+		return nil, validationErrorf("either symbol_id or name_path is required")
 	}
 
 	results, err := codeStorage.Query(ctx, query, params)
@@ -154,7 +191,19 @@ func (cmtm *CodeManipulationToolManager) resolveSymbol(ctx context.Context, proj
 	}
 
 	if len(results) == 0 {
-		return nil, fmt.Errorf("symbol not found")
+		return nil, notFoundErrorf("symbol not found")
+	}
+	if len(results) > 1 {
+		// Editing one of several overloads by their shared name path would
+		// pick one at random
+		candidates := make([]string, 0, len(results))
+		for _, r := range results {
+			candidateFile, _ := r["file_path"].(string)
+			candidatePath, _ := r["name_path"].(string)
+			candidateLine, _ := r["start_line"].(float64)
+			candidates = append(candidates, fmt.Sprintf("%s (%s:%d)", candidatePath, candidateFile, int(candidateLine)))
+		}
+		return nil, validationErrorf("name_path %s matches %d symbols, pass one of: %s", namePath, len(results), strings.Join(candidates, ", "))
 	}
 
 	r := results[0]
@@ -165,7 +214,7 @@ func (cmtm *CodeManipulationToolManager) resolveSymbol(ctx context.Context, proj
 		return nil, fmt.Errorf("failed to get project: %w", err)
 	}
 	if project == nil {
-		return nil, fmt.Errorf("project not found: %s", projectID)
+		return nil, notFoundErrorf("project not found: %s", projectID)
 	}
 
 	filePath, _ := r["file_path"].(string)
@@ -179,6 +228,7 @@ func (cmtm *CodeManipulationToolManager) resolveSymbol(ctx context.Context, proj
 	id, _ := r["id"].(string)
 	namePathVal, _ := r["name_path"].(string)
 	lang, _ := r["language"].(string)
+	revision, _ := r["revision"].(string)
 
 	return &symbolInfo{
 		ID:           id,
@@ -190,30 +240,51 @@ func (cmtm *CodeManipulationToolManager) resolveSymbol(ctx context.Context, proj
 		StartLine:    int(startLine),
 		EndLine:      int(endLine),
 		Language:     treesitter.Language(lang),
+		RootPath:     project.RootPath,
 		AbsolutePath: absPath,
+		Revision:     revision,
 	}, nil
 }
 
-// modifyFile reads a file, applies a modification, and writes it back
-func (cmtm *CodeManipulationToolManager) modifyFile(absPath string, modifier func(content []byte) ([]byte, error)) error {
+// modifyFile reads the file of a symbol, applies a modification, and writes it
+// back unless dryRun is set. It returns the unified diff of the modification.
+// Edits that introduce syntax errors are rejected, and edits that fail the
+// language's check command are reverted, both with an *invalidEdit error.
+func (cmtm *CodeManipulationToolManager) modifyFile(ctx context.Context, sym *symbolInfo, dryRun bool, modifier func(content []byte) ([]byte, error)) (string, error) {
 	// Read file
-	content, err := os.ReadFile(absPath)
+	content, err := os.ReadFile(sym.AbsolutePath)
 	if err != nil {
-		return fmt.Errorf("failed to read file: %w", err)
+		return "", fmt.Errorf("failed to read file: %w", err)
 	}
 
 	// Apply modification
 	newContent, err := modifier(content)
 	if err != nil {
-		return err
+		return "", err
+	}
+
+	if err := cmtm.validateSyntax(ctx, sym, content, newContent); err != nil {
+		return "", err
+	}
+
+	diff := unifiedDiff(sym.FilePath, content, newContent)
+	if dryRun {
+		return diff, nil
 	}
 
 	// Write back
-	if err := os.WriteFile(absPath, newContent, 0644); err != nil {
-		return fmt.Errorf("failed to write file: %w", err)
+	if err := os.WriteFile(sym.AbsolutePath, newContent, 0644); err != nil {
+		return "", fmt.Errorf("failed to write file: %w", err)
 	}
 
-	return nil
+	if checkErr := cmtm.runCheckCommand(ctx, sym); checkErr != nil {
+		if err := os.WriteFile(sym.AbsolutePath, content, 0644); err != nil {
+			return "", fmt.Errorf("failed to revert file after failed check: %w", err)
+		}
+		return "", checkErr
+	}
+
+	return diff, nil
 }
 
 // reindexFile re-parses a file and updates symbols in the database
@@ -244,15 +315,23 @@ func (cmtm *CodeManipulationToolManager) reindexFile(ctx context.Context, projec
 		return fmt.Errorf("failed to extract symbols: %w", err)
 	}
 
-	// Generate embeddings for symbols
-	if cmtm.embedder != nil && len(symbols) > 0 {
+	// Generate embeddings for symbols, with the model of the project
+	ctx, projectEmbedder, err := projectCodeEmbedding(ctx, cmtm.storage, cmtm.router, projectID)
+	if err != nil {
+		return err
+	}
+	symbolEmbedder := cmtm.embedder
+	if projectEmbedder != nil {
+		symbolEmbedder = projectEmbedder
+	}
+	if symbolEmbedder != nil && len(symbols) > 0 {
 		texts := make([]string, len(symbols))
 		for i, sym := range symbols {
 			// Create a searchable text from symbol info
 			texts[i] = fmt.Sprintf("%s %s %s", sym.Name, sym.SymbolType, sym.SourceCode)
 		}
 
-		embeddings, err := cmtm.embedder.EmbedDocuments(ctx, texts)
+		embeddings, err := symbolEmbedder.EmbedDocuments(ctx, texts)
 		if err == nil && len(embeddings) == len(symbols) {
 			for i, emb := range embeddings {
 				symbols[i].Embedding = emb
@@ -273,6 +352,15 @@ func (cmtm *CodeManipulationToolManager) reindexFile(ctx context.Context, projec
 	return nil
 }
 
+// dryRunResult marks a modification result as not applied
+func dryRunResult(result map[string]interface{}, message string) *protocol.CallToolResult {
+	result["message"] = message
+	result["dry_run"] = true
+	return protocol.NewCallToolResult([]protocol.Content{
+		&protocol.TextContent{Type: "text", Text: MarshalTOON(result)},
+	}, false)
+}
+
 // ====== Tool Handlers ======
 
 func (cmtm *CodeManipulationToolManager) codeReplaceSymbolHandler(ctx context.Context, req *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
@@ -282,10 +370,10 @@ func (cmtm *CodeManipulationToolManager) codeReplaceSymbolHandler(ctx context.Co
 	}
 
 	if input.ProjectID == "" {
-		return nil, fmt.Errorf("project_id is required")
+		return nil, validationErrorf("project_id is required")
 	}
 	if input.NewBody == "" {
-		return nil, fmt.Errorf("new_body is required")
+		return nil, validationErrorf("new_body is required")
 	}
 
 	// Resolve symbol
@@ -295,9 +383,16 @@ func (cmtm *CodeManipulationToolManager) codeReplaceSymbolHandler(ctx context.Co
 	}
 
 	// Modify file
-	err = cmtm.modifyFile(sym.AbsolutePath, func(content []byte) ([]byte, error) {
+	diff, err := cmtm.modifyFile(ctx, sym, input.DryRun, func(content []byte) ([]byte, error) {
 		if sym.StartByte < 0 || sym.EndByte > len(content) || sym.StartByte > sym.EndByte {
-			return nil, fmt.Errorf("invalid byte range: %d-%d (file size: %d)", sym.StartByte, sym.EndByte, len(content))
+			return nil, validationErrorf("invalid byte range: %d-%d (file size: %d)", sym.StartByte, sym.EndByte, len(content))
+		}
+
+		// Reject the edit if the symbol's source no longer matches the revision the caller read
+		if input.Revision != "" {
+			if current := treesitter.SymbolRevision(content[sym.StartByte:sym.EndByte]); current != input.Revision {
+				return nil, &revisionConflict{ID: sym.NamePath, Expected: input.Revision, Current: current}
+			}
 		}
 
 		// Replace the symbol content
@@ -309,14 +404,17 @@ func (cmtm *CodeManipulationToolManager) codeReplaceSymbolHandler(ctx context.Co
 		return newContent, nil
 	})
 	if err != nil {
+		var conflict *revisionConflict
+		if errors.As(err, &conflict) {
+			return conflictResult(conflict, "The symbol changed since it was read. Call code_find_symbol with include_body to read the current source and revision, then retry."), nil
+		}
+		var invalid *invalidEdit
+		if errors.As(err, &invalid) {
+			return invalidEditResult(invalid), nil
+		}
 		return nil, fmt.Errorf("failed to modify file: %w", err)
 	}
 
-	// Re-index file
-	if err := cmtm.reindexFile(ctx, sym.ProjectID, sym.FilePath, sym.AbsolutePath, sym.Language); err != nil {
-		slog.Warn("failed to reindex file after replacement", "file", sym.FilePath, "error", err)
-	}
-
 	result := map[string]interface{}{
 		"message":   "Symbol replaced successfully",
 		"file_path": sym.FilePath,
@@ -325,6 +423,18 @@ func (cmtm *CodeManipulationToolManager) codeReplaceSymbolHandler(ctx context.Co
 			"start_line": sym.StartLine,
 			"end_line":   sym.EndLine,
 		},
+		"diff": diff,
+	}
+
+	if input.DryRun {
+		return dryRunResult(result, "Symbol replacement not applied (dry run)"), nil
+	}
+
+	// Re-index file
+	if err := cmtm.reindexFile(ctx, sym.ProjectID, sym.FilePath, sym.AbsolutePath, sym.Language); err != nil {
+		slog.Warn("failed to reindex file after replacement", "file", sym.FilePath, "error", err)
+	} else if updated, err := cmtm.resolveSymbol(ctx, sym.ProjectID, "", sym.NamePath, sym.FilePath); err == nil && updated.Revision != "" {
+		result["revision"] = updated.Revision
 	}
 
 	return protocol.NewCallToolResult([]protocol.Content{
@@ -339,10 +449,10 @@ func (cmtm *CodeManipulationToolManager) codeInsertAfterSymbolHandler(ctx contex
 	}
 
 	if input.ProjectID == "" {
-		return nil, fmt.Errorf("project_id is required")
+		return nil, validationErrorf("project_id is required")
 	}
 	if input.Body == "" {
-		return nil, fmt.Errorf("body is required")
+		return nil, validationErrorf("body is required")
 	}
 
 	// Resolve symbol
@@ -352,9 +462,9 @@ func (cmtm *CodeManipulationToolManager) codeInsertAfterSymbolHandler(ctx contex
 	}
 
 	// Modify file
-	err = cmtm.modifyFile(sym.AbsolutePath, func(content []byte) ([]byte, error) {
+	diff, err := cmtm.modifyFile(ctx, sym, input.DryRun, func(content []byte) ([]byte, error) {
 		if sym.EndByte < 0 || sym.EndByte > len(content) {
-			return nil, fmt.Errorf("invalid end byte: %d (file size: %d)", sym.EndByte, len(content))
+			return nil, validationErrorf("invalid end byte: %d (file size: %d)", sym.EndByte, len(content))
 		}
 
 		// Insert after end of symbol
@@ -366,19 +476,28 @@ func (cmtm *CodeManipulationToolManager) codeInsertAfterSymbolHandler(ctx contex
 		return newContent, nil
 	})
 	if err != nil {
+		var invalid *invalidEdit
+		if errors.As(err, &invalid) {
+			return invalidEditResult(invalid), nil
+		}
 		return nil, fmt.Errorf("failed to modify file: %w", err)
 	}
 
-	// Re-index file
-	if err := cmtm.reindexFile(ctx, sym.ProjectID, sym.FilePath, sym.AbsolutePath, sym.Language); err != nil {
-		slog.Warn("failed to reindex file after insertion", "file", sym.FilePath, "error", err)
-	}
-
 	result := map[string]interface{}{
 		"message":             "Code inserted after symbol successfully",
 		"file_path":           sym.FilePath,
 		"reference_symbol":    sym.NamePath,
 		"inserted_after_line": sym.EndLine,
+		"diff":                diff,
+	}
+
+	if input.DryRun {
+		return dryRunResult(result, "Insertion not applied (dry run)"), nil
+	}
+
+	// Re-index file
+	if err := cmtm.reindexFile(ctx, sym.ProjectID, sym.FilePath, sym.AbsolutePath, sym.Language); err != nil {
+		slog.Warn("failed to reindex file after insertion", "file", sym.FilePath, "error", err)
 	}
 
 	return protocol.NewCallToolResult([]protocol.Content{
@@ -393,10 +512,10 @@ func (cmtm *CodeManipulationToolManager) codeInsertBeforeSymbolHandler(ctx conte
 	}
 
 	if input.ProjectID == "" {
-		return nil, fmt.Errorf("project_id is required")
+		return nil, validationErrorf("project_id is required")
 	}
 	if input.Body == "" {
-		return nil, fmt.Errorf("body is required")
+		return nil, validationErrorf("body is required")
 	}
 
 	// Resolve symbol
@@ -406,9 +525,9 @@ func (cmtm *CodeManipulationToolManager) codeInsertBeforeSymbolHandler(ctx conte
 	}
 
 	// Modify file
-	err = cmtm.modifyFile(sym.AbsolutePath, func(content []byte) ([]byte, error) {
+	diff, err := cmtm.modifyFile(ctx, sym, input.DryRun, func(content []byte) ([]byte, error) {
 		if sym.StartByte < 0 || sym.StartByte > len(content) {
-			return nil, fmt.Errorf("invalid start byte: %d (file size: %d)", sym.StartByte, len(content))
+			return nil, validationErrorf("invalid start byte: %d (file size: %d)", sym.StartByte, len(content))
 		}
 
 		// Insert before start of symbol
@@ -420,19 +539,28 @@ func (cmtm *CodeManipulationToolManager) codeInsertBeforeSymbolHandler(ctx conte
 		return newContent, nil
 	})
 	if err != nil {
+		var invalid *invalidEdit
+		if errors.As(err, &invalid) {
+			return invalidEditResult(invalid), nil
+		}
 		return nil, fmt.Errorf("failed to modify file: %w", err)
 	}
 
-	// Re-index file
-	if err := cmtm.reindexFile(ctx, sym.ProjectID, sym.FilePath, sym.AbsolutePath, sym.Language); err != nil {
-		slog.Warn("failed to reindex file after insertion", "file", sym.FilePath, "error", err)
-	}
-
 	result := map[string]interface{}{
 		"message":              "Code inserted before symbol successfully",
 		"file_path":            sym.FilePath,
 		"reference_symbol":     sym.NamePath,
 		"inserted_before_line": sym.StartLine,
+		"diff":                 diff,
+	}
+
+	if input.DryRun {
+		return dryRunResult(result, "Insertion not applied (dry run)"), nil
+	}
+
+	// Re-index file
+	if err := cmtm.reindexFile(ctx, sym.ProjectID, sym.FilePath, sym.AbsolutePath, sym.Language); err != nil {
+		slog.Warn("failed to reindex file after insertion", "file", sym.FilePath, "error", err)
 	}
 
 	return protocol.NewCallToolResult([]protocol.Content{
@@ -447,7 +575,7 @@ func (cmtm *CodeManipulationToolManager) codeDeleteSymbolHandler(ctx context.Con
 	}
 
 	if input.ProjectID == "" {
-		return nil, fmt.Errorf("project_id is required")
+		return nil, validationErrorf("project_id is required")
 	}
 
 	// Resolve symbol
@@ -464,9 +592,9 @@ func (cmtm *CodeManipulationToolManager) codeDeleteSymbolHandler(ctx context.Con
 	}
 
 	// Modify file
-	err = cmtm.modifyFile(sym.AbsolutePath, func(content []byte) ([]byte, error) {
+	diff, err := cmtm.modifyFile(ctx, sym, input.DryRun, func(content []byte) ([]byte, error) {
 		if sym.StartByte < 0 || sym.EndByte > len(content) || sym.StartByte > sym.EndByte {
-			return nil, fmt.Errorf("invalid byte range: %d-%d (file size: %d)", sym.StartByte, sym.EndByte, len(content))
+			return nil, validationErrorf("invalid byte range: %d-%d (file size: %d)", sym.StartByte, sym.EndByte, len(content))
 		}
 
 		// Find the start of the line containing the symbol
@@ -496,18 +624,27 @@ func (cmtm *CodeManipulationToolManager) codeDeleteSymbolHandler(ctx context.Con
 		return newContent, nil
 	})
 	if err != nil {
+		var invalid *invalidEdit
+		if errors.As(err, &invalid) {
+			return invalidEditResult(invalid), nil
+		}
 		return nil, fmt.Errorf("failed to modify file: %w", err)
 	}
 
-	// Re-index file
-	if err := cmtm.reindexFile(ctx, sym.ProjectID, sym.FilePath, sym.AbsolutePath, sym.Language); err != nil {
-		slog.Warn("failed to reindex file after deletion", "file", sym.FilePath, "error", err)
-	}
-
 	result := map[string]interface{}{
 		"message":        "Symbol deleted successfully",
 		"file_path":      sym.FilePath,
 		"deleted_symbol": deletedInfo,
+		"diff":           diff,
+	}
+
+	if input.DryRun {
+		return dryRunResult(result, "Symbol deletion not applied (dry run)"), nil
+	}
+
+	// Re-index file
+	if err := cmtm.reindexFile(ctx, sym.ProjectID, sym.FilePath, sym.AbsolutePath, sym.Language); err != nil {
+		slog.Warn("failed to reindex file after deletion", "file", sym.FilePath, "error", err)
 	}
 
 	return protocol.NewCallToolResult([]protocol.Content{
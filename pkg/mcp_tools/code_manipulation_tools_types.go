@@ -7,33 +7,58 @@ package mcp_tools
 type CodeReplaceSymbolInput struct {
 	ProjectID    string `json:"project_id" description:"The project ID containing the symbol."`
 	SymbolID     string `json:"symbol_id,omitempty" description:"ID of the symbol to replace (from previous search)."`
-	NamePath     string `json:"name_path,omitempty" description:"Name path of symbol (alternative to symbol_id)."`
+	NamePath     string `json:"name_path,omitempty" description:"Name path of symbol (alternative to symbol_id). Overloads after the first end in #2, #3..."`
 	RelativePath string `json:"relative_path,omitempty" description:"File path (required if using name_path)."`
 	NewBody      string `json:"new_body" description:"New source code for the symbol, including its definition/signature."`
+	Revision     string `json:"revision,omitempty" description:"Revision of the symbol as last read (from code_find_symbol). When set, the replacement is rejected if the symbol changed since."`
+	DryRun       bool   `json:"dry_run,omitempty" description:"Return the diff without writing the file."`
 }
 
 // CodeInsertAfterSymbolInput represents input for code_insert_after_symbol tool
 type CodeInsertAfterSymbolInput struct {
 	ProjectID    string `json:"project_id" description:"The project ID containing the symbol."`
 	SymbolID     string `json:"symbol_id,omitempty" description:"ID of the symbol after which to insert."`
-	NamePath     string `json:"name_path,omitempty" description:"Name path of symbol (alternative to symbol_id)."`
+	NamePath     string `json:"name_path,omitempty" description:"Name path of symbol (alternative to symbol_id). Overloads after the first end in #2, #3..."`
 	RelativePath string `json:"relative_path,omitempty" description:"File path (required if using name_path)."`
 	Body         string `json:"body" description:"Code to insert after the symbol."`
+	DryRun       bool   `json:"dry_run,omitempty" description:"Return the diff without writing the file."`
 }
 
 // CodeInsertBeforeSymbolInput represents input for code_insert_before_symbol tool
 type CodeInsertBeforeSymbolInput struct {
 	ProjectID    string `json:"project_id" description:"The project ID containing the symbol."`
 	SymbolID     string `json:"symbol_id,omitempty" description:"ID of the symbol before which to insert."`
-	NamePath     string `json:"name_path,omitempty" description:"Name path of symbol (alternative to symbol_id)."`
+	NamePath     string `json:"name_path,omitempty" description:"Name path of symbol (alternative to symbol_id). Overloads after the first end in #2, #3..."`
 	RelativePath string `json:"relative_path,omitempty" description:"File path (required if using name_path)."`
 	Body         string `json:"body" description:"Code to insert before the symbol."`
+	DryRun       bool   `json:"dry_run,omitempty" description:"Return the diff without writing the file."`
 }
 
 // CodeDeleteSymbolInput represents input for code_delete_symbol tool
 type CodeDeleteSymbolInput struct {
 	ProjectID    string `json:"project_id" description:"The project ID containing the symbol."`
 	SymbolID     string `json:"symbol_id,omitempty" description:"ID of the symbol to delete."`
-	NamePath     string `json:"name_path,omitempty" description:"Name path of symbol (alternative to symbol_id)."`
+	NamePath     string `json:"name_path,omitempty" description:"Name path of symbol (alternative to symbol_id). Overloads after the first end in #2, #3..."`
 	RelativePath string `json:"relative_path,omitempty" description:"File path (required if using name_path)."`
+	DryRun       bool   `json:"dry_run,omitempty" description:"Return the diff without writing the file."`
+}
+
+// CodeApplyPatchInput represents input for code_apply_patch tool
+type CodeApplyPatchInput struct {
+	ProjectID    string `json:"project_id" description:"The project ID containing the files."`
+	Patch        string `json:"patch" description:"Unified diff to apply. File paths in ---/+++ headers are relative to the project root."`
+	RelativePath string `json:"relative_path,omitempty" description:"File to patch when the diff has no file headers, or to override the header of a single-file diff."`
+	DryRun       bool   `json:"dry_run,omitempty" description:"Return the diff without writing the files."`
+}
+
+// CodeReplaceRegexInput represents input for code_replace_regex tool
+type CodeReplaceRegexInput struct {
+	ProjectID    string `json:"project_id" description:"The project ID containing the file."`
+	RelativePath string `json:"relative_path,omitempty" description:"File to edit (required unless symbol_id is given; also narrows name_path)."`
+	SymbolID     string `json:"symbol_id,omitempty" description:"ID of a symbol to limit the replacement to."`
+	NamePath     string `json:"name_path,omitempty" description:"Name path of a symbol to limit the replacement to (alternative to symbol_id). Overloads after the first end in #2, #3..."`
+	Pattern      string `json:"pattern" description:"Regular expression (Go RE2 syntax) to search for."`
+	Replacement  string `json:"replacement" description:"Replacement text; $1 or ${name} expand to capture groups."`
+	Count        int    `json:"count,omitempty" description:"Maximum number of replacements. Default is all matches."`
+	DryRun       bool   `json:"dry_run,omitempty" description:"Return the diff without writing the file."`
 }
@@ -0,0 +1,126 @@
+// Package mcp_tools provides code manipulation MCP tools.
+// This file contains the validation applied to files after code edits.
+package mcp_tools
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ThinkInAIXYZ/go-mcp/protocol"
+	"github.com/madeindigio/remembrances-mcp/pkg/treesitter"
+)
+
+const (
+	// checkCommandTimeout bounds a configured check command
+	checkCommandTimeout = 2 * time.Minute
+
+	// maxCheckOutput bounds the check command output returned to the caller
+	maxCheckOutput = 4000
+)
+
+// invalidEdit describes an edit rejected because the modified file does not
+// parse or fails its check command. The file is left unchanged.
+type invalidEdit struct {
+	FilePath     string
+	SyntaxErrors []treesitter.SyntaxError
+	Command      string
+	Output       string
+}
+
+func (e *invalidEdit) Error() string {
+	if e.Command != "" {
+		return fmt.Sprintf("check command %q failed for %s", e.Command, e.FilePath)
+	}
+	return fmt.Sprintf("edit introduces %d syntax error(s) in %s", len(e.SyntaxErrors), e.FilePath)
+}
+
+// invalidEditResult reports a rejected edit as a structured tool error
+func invalidEditResult(invalid *invalidEdit) *protocol.CallToolResult {
+	response := map[string]interface{}{
+		"code":      ErrCodeValidation,
+		"message":   invalid.Error() + "; the file was not modified",
+		"file_path": invalid.FilePath,
+	}
+	if invalid.Command != "" {
+		response["error"] = "check_failed"
+		response["command"] = invalid.Command
+		response["output"] = invalid.Output
+	} else {
+		response["error"] = "syntax_error"
+		response["syntax_errors"] = invalid.SyntaxErrors
+	}
+	return protocol.NewCallToolResult([]protocol.Content{
+		&protocol.TextContent{Type: "text", Text: MarshalTOON(response)},
+	}, true)
+}
+
+// SetCheckCommands configures the shell command run after each edit, by
+// language. "{file}" and "{dir}" in a command are replaced by the edited
+// file and its directory, relative to the project root where it runs.
+func (cmtm *CodeManipulationToolManager) SetCheckCommands(commands map[string]string) {
+	cmtm.checkCommands = make(map[treesitter.Language]string, len(commands))
+	for lang, command := range commands {
+		if command = strings.TrimSpace(command); command != "" {
+			cmtm.checkCommands[treesitter.Language(strings.ToLower(lang))] = command
+		}
+	}
+}
+
+// validateSyntax rejects newContent when it has more syntax errors than the
+// original content, so files that already failed to parse can still be edited
+func (cmtm *CodeManipulationToolManager) validateSyntax(ctx context.Context, sym *symbolInfo, content, newContent []byte) error {
+	newTree, err := cmtm.parser.Parse(ctx, newContent, sym.Language)
+	if err != nil {
+		// Languages without a grammar cannot be validated
+		return nil
+	}
+	newErrors := treesitter.FindSyntaxErrors(newTree, newContent)
+	if len(newErrors) == 0 {
+		return nil
+	}
+
+	oldTree, err := cmtm.parser.Parse(ctx, content, sym.Language)
+	if err != nil {
+		return nil
+	}
+	if len(newErrors) <= len(treesitter.FindSyntaxErrors(oldTree, content)) {
+		return nil
+	}
+
+	return &invalidEdit{FilePath: sym.FilePath, SyntaxErrors: newErrors}
+}
+
+// runCheckCommand runs the check command configured for the language of the
+// edited file, if any, and returns an invalidEdit when it fails
+func (cmtm *CodeManipulationToolManager) runCheckCommand(ctx context.Context, sym *symbolInfo) error {
+	command, ok := cmtm.checkCommands[sym.Language]
+	if !ok {
+		return nil
+	}
+
+	dir := filepath.Dir(sym.FilePath)
+	command = strings.NewReplacer("{file}", sym.FilePath, "{dir}", dir).Replace(command)
+
+	ctx, cancel := context.WithTimeout(ctx, checkCommandTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "/bin/sh", "-c", command)
+	cmd.Dir = sym.RootPath
+	output, err := cmd.CombinedOutput()
+	if err == nil {
+		return nil
+	}
+
+	text := strings.TrimSpace(string(output))
+	if text == "" {
+		text = err.Error()
+	}
+	if len(text) > maxCheckOutput {
+		text = text[:maxCheckOutput] + "\n..."
+	}
+	return &invalidEdit{FilePath: sym.FilePath, Command: command, Output: text}
+}
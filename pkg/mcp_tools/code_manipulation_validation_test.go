@@ -0,0 +1,57 @@
+package mcp_tools
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/madeindigio/remembrances-mcp/pkg/treesitter"
+)
+
+func TestValidateSyntax(t *testing.T) {
+	cmtm := NewCodeManipulationToolManager(nil, nil)
+	sym := &symbolInfo{FilePath: "main.go", Language: treesitter.LanguageGo}
+	ctx := context.Background()
+
+	valid := []byte("package main\n\nfunc a() {}\n")
+	broken := []byte("package main\n\nfunc a() {\n")
+
+	if err := cmtm.validateSyntax(ctx, sym, valid, []byte("package main\n\nfunc b() int { return 1 }\n")); err != nil {
+		t.Fatalf("valid edit rejected: %v", err)
+	}
+
+	var invalid *invalidEdit
+	err := cmtm.validateSyntax(ctx, sym, valid, broken)
+	if !errors.As(err, &invalid) || len(invalid.SyntaxErrors) == 0 {
+		t.Fatalf("broken edit accepted: %v", err)
+	}
+	if invalid.SyntaxErrors[0].Line < 3 {
+		t.Errorf("syntax error reported at line %d; want 3 or later", invalid.SyntaxErrors[0].Line)
+	}
+
+	// Files that already fail to parse can still be edited
+	if err := cmtm.validateSyntax(ctx, sym, broken, append(broken, "// comment\n"...)); err != nil {
+		t.Errorf("edit of already broken file rejected: %v", err)
+	}
+}
+
+func TestRunCheckCommand(t *testing.T) {
+	cmtm := NewCodeManipulationToolManager(nil, nil)
+	sym := &symbolInfo{FilePath: "pkg/main.go", Language: treesitter.LanguageGo, RootPath: t.TempDir()}
+	ctx := context.Background()
+
+	if err := cmtm.runCheckCommand(ctx, sym); err != nil {
+		t.Fatalf("no command configured: %v", err)
+	}
+
+	cmtm.SetCheckCommands(map[string]string{"Go": "test {dir} = pkg && test {file} = pkg/main.go"})
+	if err := cmtm.runCheckCommand(ctx, sym); err != nil {
+		t.Fatalf("passing check failed: %v", err)
+	}
+
+	cmtm.SetCheckCommands(map[string]string{"go": "echo vet failed; exit 1"})
+	var invalid *invalidEdit
+	if err := cmtm.runCheckCommand(ctx, sym); !errors.As(err, &invalid) || invalid.Output != "vet failed" {
+		t.Fatalf("failing check = %v; want output %q", err, "vet failed")
+	}
+}
@@ -0,0 +1,364 @@
+// Package mcp_tools provides code indexing MCP tools.
+// This file contains the project alias, remap and configuration tools, and
+// the resolution of aliases given as project_id to the code tools.
+package mcp_tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+
+	"github.com/ThinkInAIXYZ/go-mcp/protocol"
+	"github.com/madeindigio/remembrances-mcp/internal/indexer"
+	"github.com/madeindigio/remembrances-mcp/internal/storage"
+	"github.com/madeindigio/remembrances-mcp/pkg/embedder"
+)
+
+// projectAliasPattern is the form of a project alias: a name without spaces
+// or path separators.
+var projectAliasPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,63}$`)
+
+// Limits of the settings of code_configure_project.
+const (
+	minProjectChunkSize     = 200
+	maxProjectChunkSize     = 16000
+	minProjectMaxSymbolSize = 1000
+)
+
+// projectAliasStorage is the storage of project aliases.
+type projectAliasStorage interface {
+	GetCodeProject(ctx context.Context, projectID string) (*storage.CodeProject, error)
+	GetCodeProjectByAlias(ctx context.Context, alias string) (*storage.CodeProject, error)
+	SetCodeProjectAlias(ctx context.Context, projectID, alias string) error
+}
+
+// withProjectAliases wraps reg so that the tools it registers accept a
+// project alias as their project_id argument, which is replaced by the ID
+// of the project before the handler runs. Values that are project IDs are
+// left as they are.
+func withProjectAliases(s storage.Storage, reg func(string, *protocol.Tool, func(context.Context, *protocol.CallToolRequest) (*protocol.CallToolResult, error)) error) func(string, *protocol.Tool, func(context.Context, *protocol.CallToolRequest) (*protocol.CallToolResult, error)) error {
+	projects, ok := s.(projectAliasStorage)
+	if !ok {
+		return reg
+	}
+	return func(name string, tool *protocol.Tool, handler func(context.Context, *protocol.CallToolRequest) (*protocol.CallToolResult, error)) error {
+		return reg(name, tool, func(ctx context.Context, request *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+			// Malformed arguments are reported by the handler itself
+			var args map[string]json.RawMessage
+			if err := json.Unmarshal(request.RawArguments, &args); err != nil {
+				return handler(ctx, request)
+			}
+			var projectID string
+			if err := json.Unmarshal(args["project_id"], &projectID); err != nil || projectID == "" {
+				return handler(ctx, request)
+			}
+			if project, err := projects.GetCodeProject(ctx, projectID); err != nil || project != nil {
+				return handler(ctx, request)
+			}
+			project, err := projects.GetCodeProjectByAlias(ctx, projectID)
+			if err != nil || project == nil {
+				return handler(ctx, request)
+			}
+			args["project_id"], _ = json.Marshal(project.ProjectID)
+			raw, err := json.Marshal(args)
+			if err != nil {
+				return nil, fmt.Errorf("failed to resolve project alias: %w", err)
+			}
+			request.RawArguments = raw
+			return handler(ctx, request)
+		})
+	}
+}
+
+// projectCodeEmbedding returns the embedder a project picked with its
+// embedder setting among the named embedders of router, or nil when it uses
+// the server's code embedder, and a context in which storage reads and
+// writes the code records of its model.
+func projectCodeEmbedding(ctx context.Context, s storage.Storage, router *embedder.Router, projectID string) (context.Context, embedder.Embedder, error) {
+	projects, ok := s.(interface {
+		GetCodeProject(ctx context.Context, projectID string) (*storage.CodeProject, error)
+	})
+	if router == nil || !ok {
+		return ctx, nil, nil
+	}
+	project, err := projects.GetCodeProject(ctx, projectID)
+	if err != nil {
+		return ctx, nil, fmt.Errorf("failed to get project: %w", err)
+	}
+	if project == nil || project.Embedder == "" {
+		return ctx, nil, nil
+	}
+	route, err := router.Named(project.Embedder)
+	if err != nil {
+		return ctx, nil, embedderErrorf("embedder of project %s: %w", projectID, err)
+	}
+	return storage.WithCodeEmbeddingModel(ctx, route.Model), route.Embedder, nil
+}
+
+// ====== Project Tool Definitions ======
+
+func (ctm *CodeToolManager) codeSetProjectAliasTool() *protocol.Tool {
+	tool, err := protocol.NewTool("code_set_project_alias", `Give a code project a short alias that the code tools accept in place of its project_id. Use how_to_use("code_set_project_alias") for details.`, CodeSetProjectAliasInput{})
+	if err != nil {
+		slog.Error("failed to create tool", "name", "code_set_project_alias", "err", err)
+		return nil
+	}
+	return tool
+}
+
+func (ctm *CodeToolManager) codeRemapProjectTool() *protocol.Tool {
+	tool, err := protocol.NewTool("code_remap_project", `Point a code project at a new root path after its checkout moved, re-indexing only the files that changed. Use how_to_use("code_remap_project") for details.`, CodeRemapProjectInput{})
+	if err != nil {
+		slog.Error("failed to create tool", "name", "code_remap_project", "err", err)
+		return nil
+	}
+	return tool
+}
+
+func (ctm *CodeToolManager) codeConfigureProjectTool() *protocol.Tool {
+	tool, err := protocol.NewTool("code_configure_project", `Show or override the embedder and chunking settings a code project is indexed with. Use how_to_use("code_configure_project") for details.`, CodeConfigureProjectInput{})
+	if err != nil {
+		slog.Error("failed to create tool", "name", "code_configure_project", "err", err)
+		return nil
+	}
+	return tool
+}
+
+// ====== Project Tool Handlers ======
+
+func (ctm *CodeToolManager) codeSetProjectAliasHandler(ctx context.Context, req *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+	var input CodeSetProjectAliasInput
+	if err := json.Unmarshal(req.RawArguments, &input); err != nil {
+		return nil, fmt.Errorf("failed to parse input: %w", err)
+	}
+
+	if input.ProjectID == "" {
+		return nil, validationErrorf("project_id is required")
+	}
+	input.Alias = strings.TrimSpace(input.Alias)
+	if input.Alias != "" && !projectAliasPattern.MatchString(input.Alias) {
+		return nil, validationErrorf("invalid alias %q: use up to 64 letters, digits, '.', '_' or '-', starting with a letter or digit", input.Alias)
+	}
+
+	codeStorage, ok := ctm.storage.(projectAliasStorage)
+	if !ok {
+		return nil, fmt.Errorf("storage does not support code operations")
+	}
+
+	project, err := codeStorage.GetCodeProject(ctx, input.ProjectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get project: %w", err)
+	}
+	if project == nil {
+		return nil, notFoundErrorf("project %s not found", input.ProjectID)
+	}
+
+	if input.Alias != "" {
+		// An alias must not shadow a project ID or the alias of another project
+		other, err := codeStorage.GetCodeProject(ctx, input.Alias)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check alias: %w", err)
+		}
+		if other == nil {
+			if other, err = codeStorage.GetCodeProjectByAlias(ctx, input.Alias); err != nil {
+				return nil, fmt.Errorf("failed to check alias: %w", err)
+			}
+		}
+		if other != nil && other.ProjectID != project.ProjectID {
+			return nil, validationErrorf("alias %q is already used by project %s", input.Alias, other.ProjectID)
+		}
+	}
+
+	if err := codeStorage.SetCodeProjectAlias(ctx, project.ProjectID, input.Alias); err != nil {
+		return nil, fmt.Errorf("failed to set project alias: %w", err)
+	}
+
+	result := map[string]interface{}{
+		"success":    true,
+		"project_id": project.ProjectID,
+		"alias":      input.Alias,
+		"message":    fmt.Sprintf("Project %s can now be referred to as %q", project.ProjectID, input.Alias),
+	}
+	if input.Alias == "" {
+		result["message"] = fmt.Sprintf("Alias of project %s removed", project.ProjectID)
+		if project.Alias != "" {
+			result["previous_alias"] = project.Alias
+		}
+	}
+	return protocol.NewCallToolResult([]protocol.Content{
+		&protocol.TextContent{Type: "text", Text: MarshalTOON(result)},
+	}, false), nil
+}
+
+func (ctm *CodeToolManager) codeRemapProjectHandler(ctx context.Context, req *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+	var input CodeRemapProjectInput
+	if err := json.Unmarshal(req.RawArguments, &input); err != nil {
+		return nil, fmt.Errorf("failed to parse input: %w", err)
+	}
+
+	if input.ProjectID == "" || input.NewRootPath == "" {
+		return nil, validationErrorf("project_id and new_root_path are required")
+	}
+
+	// The watcher watches the old root, so it is restarted on the new one
+	watched := ctm.watcherManager != nil && ctm.watcherManager.IsProjectActive(ctx, input.ProjectID)
+	if watched {
+		if _, err := ctm.watcherManager.DeactivateProject(ctx, input.ProjectID); err != nil {
+			return nil, fmt.Errorf("failed to stop project watch: %w", err)
+		}
+	}
+
+	remap, err := ctm.jobManager.GetIndexer().RemapProject(ctx, input.ProjectID, input.NewRootPath)
+	if watched {
+		if _, _, werr := ctm.watcherManager.ActivateProject(ctx, input.ProjectID); werr != nil {
+			slog.Warn("failed to restart project watch", "project_id", input.ProjectID, "error", werr)
+		}
+	}
+	if errors.Is(err, indexer.ErrInvalidRoot) {
+		return nil, validationErrorf("failed to remap project: %w", err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to remap project: %w", err)
+	}
+
+	result := map[string]interface{}{
+		"success":         len(remap.Failed) == 0,
+		"project_id":      remap.ProjectID,
+		"old_root_path":   remap.OldRoot,
+		"new_root_path":   remap.NewRoot,
+		"files_unchanged": remap.Unchanged,
+		"files_modified":  len(remap.Modified),
+		"files_added":     len(remap.Added),
+		"files_removed":   len(remap.Removed),
+		"watch_restarted": watched,
+	}
+	if len(remap.Modified) > 0 {
+		result["modified"] = remap.Modified
+	}
+	if len(remap.Added) > 0 {
+		result["added"] = remap.Added
+	}
+	if len(remap.Removed) > 0 {
+		result["removed"] = remap.Removed
+	}
+	if len(remap.Failed) > 0 {
+		result["failed"] = remap.Failed
+	}
+	return protocol.NewCallToolResult([]protocol.Content{
+		&protocol.TextContent{Type: "text", Text: MarshalTOON(result)},
+	}, false), nil
+}
+
+func (ctm *CodeToolManager) codeConfigureProjectHandler(ctx context.Context, req *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+	var input CodeConfigureProjectInput
+	if err := json.Unmarshal(req.RawArguments, &input); err != nil {
+		return nil, fmt.Errorf("failed to parse input: %w", err)
+	}
+
+	if input.ProjectID == "" {
+		return nil, validationErrorf("project_id is required")
+	}
+
+	codeStorage, ok := ctm.storage.(interface {
+		GetCodeProject(ctx context.Context, projectID string) (*storage.CodeProject, error)
+		UpdateCodeProjectSettings(ctx context.Context, projectID string, settings storage.CodeProjectSettings) error
+		ClearCodeFileHashes(ctx context.Context, projectID string) error
+	})
+	if !ok {
+		return nil, fmt.Errorf("storage does not support code operations")
+	}
+
+	project, err := codeStorage.GetCodeProject(ctx, input.ProjectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get project: %w", err)
+	}
+	if project == nil {
+		return nil, notFoundErrorf("project %s not found", input.ProjectID)
+	}
+
+	var embedders []string
+	if ctm.router != nil {
+		embedders = ctm.router.Names()
+	}
+	settings := project.CodeProjectSettings
+	if input.Embedder == nil && input.ChunkSize == nil && input.ChunkOverlap == nil && input.MaxSymbolSize == nil {
+		result := map[string]interface{}{
+			"project_id": project.ProjectID,
+			"settings":   settings,
+			"embedders":  embedders,
+		}
+		return protocol.NewCallToolResult([]protocol.Content{
+			&protocol.TextContent{Type: "text", Text: MarshalTOON(result)},
+		}, false), nil
+	}
+
+	if input.Embedder != nil {
+		settings.Embedder = strings.TrimSpace(*input.Embedder)
+	}
+	if input.ChunkSize != nil {
+		settings.ChunkSize = *input.ChunkSize
+	}
+	if input.ChunkOverlap != nil {
+		settings.ChunkOverlap = *input.ChunkOverlap
+	}
+	if input.MaxSymbolSize != nil {
+		settings.MaxSymbolSize = *input.MaxSymbolSize
+	}
+
+	if settings.Embedder != "" {
+		if ctm.router == nil {
+			return nil, validationErrorf("invalid embedder %q: no named embedders are configured", settings.Embedder)
+		}
+		if _, err := ctm.router.Named(settings.Embedder); err != nil {
+			return nil, validationErrorf("invalid embedder %q: %v; configured embedders: %s", settings.Embedder, err, strings.Join(embedders, ", "))
+		}
+	}
+	if settings.ChunkSize != 0 && (settings.ChunkSize < minProjectChunkSize || settings.ChunkSize > maxProjectChunkSize) {
+		return nil, validationErrorf("invalid chunk_size %d: must be 0 or between %d and %d", settings.ChunkSize, minProjectChunkSize, maxProjectChunkSize)
+	}
+	chunkSize := settings.ChunkSize
+	if chunkSize == 0 {
+		chunkSize = indexer.ChunkSize
+	}
+	if settings.ChunkOverlap < 0 || settings.ChunkOverlap >= chunkSize {
+		return nil, validationErrorf("invalid chunk_overlap %d: must be 0 or less than the chunk size %d", settings.ChunkOverlap, chunkSize)
+	}
+	if settings.MaxSymbolSize != 0 && settings.MaxSymbolSize < minProjectMaxSymbolSize {
+		return nil, validationErrorf("invalid max_symbol_size %d: must be 0 or at least %d", settings.MaxSymbolSize, minProjectMaxSymbolSize)
+	}
+
+	result := map[string]interface{}{
+		"success":    true,
+		"project_id": project.ProjectID,
+		"settings":   settings,
+	}
+	if settings == project.CodeProjectSettings {
+		result["message"] = "Settings unchanged"
+		return protocol.NewCallToolResult([]protocol.Content{
+			&protocol.TextContent{Type: "text", Text: MarshalTOON(result)},
+		}, false), nil
+	}
+
+	if err := codeStorage.UpdateCodeProjectSettings(ctx, project.ProjectID, settings); err != nil {
+		return nil, fmt.Errorf("failed to update project settings: %w", err)
+	}
+	// Every file has to be embedded and chunked again, so none may be
+	// skipped as unchanged by the re-index
+	if err := codeStorage.ClearCodeFileHashes(ctx, project.ProjectID); err != nil {
+		return nil, fmt.Errorf("failed to reset file hashes: %w", err)
+	}
+	job, err := ctm.jobManager.ReindexProject(ctx, project.ProjectID)
+	if err != nil {
+		return nil, fmt.Errorf("settings saved but failed to queue re-index: %w", err)
+	}
+
+	result["job_id"] = job.ID
+	result["message"] = fmt.Sprintf("Settings saved; project %s is being re-indexed with them. Use code_index_status with the job_id to follow it.", project.ProjectID)
+	return protocol.NewCallToolResult([]protocol.Content{
+		&protocol.TextContent{Type: "text", Text: MarshalTOON(result)},
+	}, false), nil
+}
@@ -0,0 +1,63 @@
+package mcp_tools
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/ThinkInAIXYZ/go-mcp/protocol"
+	"github.com/madeindigio/remembrances-mcp/internal/storage"
+)
+
+// aliasStorage is a storage with a single code project, aliased "backend".
+type aliasStorage struct {
+	storage.Storage
+}
+
+func (aliasStorage) GetCodeProject(ctx context.Context, projectID string) (*storage.CodeProject, error) {
+	if projectID == "home_user_backend" {
+		return &storage.CodeProject{ProjectID: projectID, Alias: "backend"}, nil
+	}
+	return nil, nil
+}
+
+func (aliasStorage) GetCodeProjectByAlias(ctx context.Context, alias string) (*storage.CodeProject, error) {
+	if alias == "backend" {
+		return &storage.CodeProject{ProjectID: "home_user_backend", Alias: alias}, nil
+	}
+	return nil, nil
+}
+
+func (aliasStorage) SetCodeProjectAlias(ctx context.Context, projectID, alias string) error {
+	return nil
+}
+
+func TestWithProjectAliases(t *testing.T) {
+	var got map[string]interface{}
+	reg := withProjectAliases(aliasStorage{}, func(name string, tool *protocol.Tool, handler func(context.Context, *protocol.CallToolRequest) (*protocol.CallToolResult, error)) error {
+		raw, _ := json.Marshal(map[string]interface{}{"project_id": name, "query": "retry"})
+		_, err := handler(context.Background(), &protocol.CallToolRequest{RawArguments: raw})
+		return err
+	})
+	handler := func(ctx context.Context, request *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+		got = nil
+		return nil, json.Unmarshal(request.RawArguments, &got)
+	}
+
+	tests := []struct {
+		projectID string
+		want      string
+	}{
+		{"backend", "home_user_backend"},
+		{"home_user_backend", "home_user_backend"},
+		{"unknown", "unknown"},
+	}
+	for _, tt := range tests {
+		if err := reg(tt.projectID, nil, handler); err != nil {
+			t.Fatal(err)
+		}
+		if got["project_id"] != tt.want || got["query"] != "retry" {
+			t.Errorf("project_id %q: arguments = %v, want project_id %q", tt.projectID, got, tt.want)
+		}
+	}
+}
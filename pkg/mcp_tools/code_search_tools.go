@@ -2,6 +2,10 @@
 // This file contains the CodeSearchToolManager and tool definitions.
 // Input types are in code_search_tools_types.go
 // Handler implementations are in code_search_tools_handlers.go
+// Dependency graph handlers are in code_search_tools_dependencies.go
+// Call graph handlers are in code_search_tools_calls.go
+// The documentation search handler is in code_search_tools_docs.go
+// The repository map handler is in code_search_tools_repo_map.go
 package mcp_tools
 
 import (
@@ -10,6 +14,7 @@ import (
 
 	"github.com/ThinkInAIXYZ/go-mcp/protocol"
 	"github.com/madeindigio/remembrances-mcp/internal/storage"
+	"github.com/madeindigio/remembrances-mcp/pkg/embedder"
 )
 
 // ====== CodeSearchToolManager ======
@@ -20,6 +25,9 @@ type CodeSearchToolManager struct {
 	embedder interface {
 		EmbedQuery(ctx context.Context, text string) ([]float32, error)
 	}
+	maxOutputBytes int              // Bytes of symbol bodies code_find_symbol returns per call (0 is unlimited)
+	scoring        scoring          // Minimum similarity and score normalization of search results
+	router         *embedder.Router // Named embedders projects may pick (nil uses embedder for all)
 }
 
 // NewCodeSearchToolManager creates a new code search tool manager
@@ -27,16 +35,43 @@ func NewCodeSearchToolManager(s storage.Storage, embedder interface {
 	EmbedQuery(ctx context.Context, text string) ([]float32, error)
 }) *CodeSearchToolManager {
 	return &CodeSearchToolManager{
-		storage:  s,
-		embedder: embedder,
+		storage:        s,
+		embedder:       embedder,
+		maxOutputBytes: defaultMaxOutputBytes,
+		scoring:        newScoring(0, ScoreNormalizationRaw),
 	}
 }
 
+// SetEmbeddingRouter configures the named embedders code projects may pick,
+// so their queries are embedded by the model that embedded their code.
+func (cstm *CodeSearchToolManager) SetEmbeddingRouter(router *embedder.Router) {
+	cstm.router = router
+}
+
+// queryEmbedder returns the embedder of the queries searching the code of a
+// project, and the context of the search.
+func (cstm *CodeSearchToolManager) queryEmbedder(ctx context.Context, projectID string) (context.Context, interface {
+	EmbedQuery(ctx context.Context, text string) ([]float32, error)
+}, error) {
+	ctx, emb, err := projectCodeEmbedding(ctx, cstm.storage, cstm.router, projectID)
+	if err != nil || emb == nil {
+		return ctx, cstm.embedder, err
+	}
+	return ctx, emb, nil
+}
+
 // RegisterCodeSearchTools registers all code search tools
 func (cstm *CodeSearchToolManager) RegisterCodeSearchTools(reg func(string, *protocol.Tool, func(context.Context, *protocol.CallToolRequest) (*protocol.CallToolResult, error)) error) error {
+	reg = withProjectAliases(cstm.storage, withErrorCodes(reg))
 	if err := reg("code_get_symbols_overview", cstm.codeGetSymbolsOverviewTool(), cstm.codeGetSymbolsOverviewHandler); err != nil {
 		return err
 	}
+	if err := reg("code_read_file", cstm.codeReadFileTool(), cstm.codeReadFileHandler); err != nil {
+		return err
+	}
+	if err := reg("code_get_repo_map", cstm.codeGetRepoMapTool(), cstm.codeGetRepoMapHandler); err != nil {
+		return err
+	}
 	if err := reg("code_find_symbol", cstm.codeFindSymbolTool(), cstm.codeFindSymbolHandler); err != nil {
 		return err
 	}
@@ -46,12 +81,33 @@ func (cstm *CodeSearchToolManager) RegisterCodeSearchTools(reg func(string, *pro
 	if err := reg("code_search_pattern", cstm.codeSearchPatternTool(), cstm.codeSearchPatternHandler); err != nil {
 		return err
 	}
+	if err := reg("code_grep", cstm.codeGrepTool(), cstm.codeGrepHandler); err != nil {
+		return err
+	}
 	if err := reg("code_find_references", cstm.codeFindReferencesTool(), cstm.codeFindReferencesHandler); err != nil {
 		return err
 	}
 	if err := reg("code_hybrid_search", cstm.codeHybridSearchTool(), cstm.codeHybridSearchHandler); err != nil {
 		return err
 	}
+	if err := reg("code_search_docs", cstm.codeSearchDocsTool(), cstm.codeSearchDocsHandler); err != nil {
+		return err
+	}
+	if err := reg("code_get_dependencies", cstm.codeGetDependenciesTool(), cstm.codeGetDependenciesHandler); err != nil {
+		return err
+	}
+	if err := reg("code_get_dependents", cstm.codeGetDependentsTool(), cstm.codeGetDependentsHandler); err != nil {
+		return err
+	}
+	if err := reg("code_export_dependency_graph", cstm.codeExportDependencyGraphTool(), cstm.codeExportDependencyGraphHandler); err != nil {
+		return err
+	}
+	if err := reg("code_find_callers", cstm.codeFindCallersTool(), cstm.codeFindCallersHandler); err != nil {
+		return err
+	}
+	if err := reg("code_find_callees", cstm.codeFindCalleesTool(), cstm.codeFindCalleesHandler); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -66,6 +122,24 @@ func (cstm *CodeSearchToolManager) codeGetSymbolsOverviewTool() *protocol.Tool {
 	return tool
 }
 
+func (cstm *CodeSearchToolManager) codeReadFileTool() *protocol.Tool {
+	tool, err := protocol.NewTool("code_read_file", `Read lines of a file or around a symbol. Use how_to_use("code_read_file") for details.`, CodeReadFileInput{})
+	if err != nil {
+		slog.Error("failed to create tool", "name", "code_read_file", "err", err)
+		return nil
+	}
+	return tool
+}
+
+func (cstm *CodeSearchToolManager) codeGetRepoMapTool() *protocol.Tool {
+	tool, err := protocol.NewTool("code_get_repo_map", `Get a compact outline of a project's directories, files and main symbols within a token budget. Use how_to_use("code_get_repo_map") for details.`, CodeGetRepoMapInput{})
+	if err != nil {
+		slog.Error("failed to create tool", "name", "code_get_repo_map", "err", err)
+		return nil
+	}
+	return tool
+}
+
 func (cstm *CodeSearchToolManager) codeFindSymbolTool() *protocol.Tool {
 	tool, err := protocol.NewTool("code_find_symbol", `Find symbols by name or path pattern. Use how_to_use("code_find_symbol") for details.`, CodeFindSymbolInput{})
 	if err != nil {
@@ -93,6 +167,15 @@ func (cstm *CodeSearchToolManager) codeSearchPatternTool() *protocol.Tool {
 	return tool
 }
 
+func (cstm *CodeSearchToolManager) codeGrepTool() *protocol.Tool {
+	tool, err := protocol.NewTool("code_grep", `Search text across all indexed files. Use how_to_use("code_grep") for details.`, CodeGrepInput{})
+	if err != nil {
+		slog.Error("failed to create tool", "name", "code_grep", "err", err)
+		return nil
+	}
+	return tool
+}
+
 func (cstm *CodeSearchToolManager) codeFindReferencesTool() *protocol.Tool {
 	tool, err := protocol.NewTool("code_find_references", `Find symbol usages in codebase. Use how_to_use("code_find_references") for details.`, CodeFindReferencesInput{})
 	if err != nil {
@@ -110,3 +193,57 @@ func (cstm *CodeSearchToolManager) codeHybridSearchTool() *protocol.Tool {
 	}
 	return tool
 }
+
+func (cstm *CodeSearchToolManager) codeSearchDocsTool() *protocol.Tool {
+	tool, err := protocol.NewTool("code_search_docs", `Search the doc comments and signatures of symbols for API reference answers. Use how_to_use("code_search_docs") for details.`, CodeSearchDocsInput{})
+	if err != nil {
+		slog.Error("failed to create tool", "name", "code_search_docs", "err", err)
+		return nil
+	}
+	return tool
+}
+
+func (cstm *CodeSearchToolManager) codeGetDependenciesTool() *protocol.Tool {
+	tool, err := protocol.NewTool("code_get_dependencies", `List the modules and files a file imports. Use how_to_use("code_get_dependencies") for details.`, CodeGetDependenciesInput{})
+	if err != nil {
+		slog.Error("failed to create tool", "name", "code_get_dependencies", "err", err)
+		return nil
+	}
+	return tool
+}
+
+func (cstm *CodeSearchToolManager) codeGetDependentsTool() *protocol.Tool {
+	tool, err := protocol.NewTool("code_get_dependents", `List the files that import a file or module. Use how_to_use("code_get_dependents") for details.`, CodeGetDependentsInput{})
+	if err != nil {
+		slog.Error("failed to create tool", "name", "code_get_dependents", "err", err)
+		return nil
+	}
+	return tool
+}
+
+func (cstm *CodeSearchToolManager) codeExportDependencyGraphTool() *protocol.Tool {
+	tool, err := protocol.NewTool("code_export_dependency_graph", `Export the project import graph as DOT or JSON. Use how_to_use("code_export_dependency_graph") for details.`, CodeExportDependencyGraphInput{})
+	if err != nil {
+		slog.Error("failed to create tool", "name", "code_export_dependency_graph", "err", err)
+		return nil
+	}
+	return tool
+}
+
+func (cstm *CodeSearchToolManager) codeFindCallersTool() *protocol.Tool {
+	tool, err := protocol.NewTool("code_find_callers", `Find the functions and methods that call a symbol. Use how_to_use("code_find_callers") for details.`, CodeFindCallersInput{})
+	if err != nil {
+		slog.Error("failed to create tool", "name", "code_find_callers", "err", err)
+		return nil
+	}
+	return tool
+}
+
+func (cstm *CodeSearchToolManager) codeFindCalleesTool() *protocol.Tool {
+	tool, err := protocol.NewTool("code_find_callees", `Find the functions and methods a symbol calls. Use how_to_use("code_find_callees") for details.`, CodeFindCalleesInput{})
+	if err != nil {
+		slog.Error("failed to create tool", "name", "code_find_callees", "err", err)
+		return nil
+	}
+	return tool
+}
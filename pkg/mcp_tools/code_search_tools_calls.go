@@ -0,0 +1,203 @@
+// Package mcp_tools provides code search MCP tools.
+// This file contains handler implementations for the call graph tools.
+package mcp_tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/ThinkInAIXYZ/go-mcp/protocol"
+	"github.com/madeindigio/remembrances-mcp/internal/storage"
+	"github.com/madeindigio/remembrances-mcp/pkg/treesitter"
+)
+
+// maxCalleeDefinitions bounds the definitions listed for each callee
+const maxCalleeDefinitions = 5
+
+// callerLabel names the caller of a call, which is the file itself for
+// calls made outside any symbol
+func callerLabel(call storage.CodeCall) string {
+	if call.CallerNamePath == "" {
+		return "<file>"
+	}
+	return call.CallerNamePath
+}
+
+func (cstm *CodeSearchToolManager) codeFindCallersHandler(ctx context.Context, req *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+	var input CodeFindCallersInput
+	if err := json.Unmarshal(req.RawArguments, &input); err != nil {
+		return nil, fmt.Errorf("failed to parse input: %w", err)
+	}
+
+	if input.ProjectID == "" || input.SymbolName == "" {
+		return nil, validationErrorf("project_id and symbol_name are required")
+	}
+
+	if input.Limit <= 0 {
+		input.Limit = 50
+	}
+
+	codeStorage, ok := cstm.storage.(interface {
+		FindCodeCallers(ctx context.Context, projectID, calleeName string, limit int) ([]storage.CodeCall, error)
+	})
+	if !ok {
+		return nil, fmt.Errorf("storage does not support code operations")
+	}
+
+	// Over-fetch when filtering by qualifier so the limit applies to matches
+	fetchLimit := input.Limit
+	if input.Qualifier != "" {
+		fetchLimit = input.Limit * 5
+	}
+
+	calls, err := codeStorage.FindCodeCallers(ctx, input.ProjectID, input.SymbolName, fetchLimit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find callers: %w", err)
+	}
+
+	callers := make([]map[string]interface{}, 0, len(calls))
+	uniqueCallers := make(map[string]bool)
+	for _, call := range calls {
+		if input.Qualifier != "" && !strings.Contains(call.CalleeExpr, input.Qualifier) {
+			continue
+		}
+		if len(callers) >= input.Limit {
+			break
+		}
+
+		caller := callerLabel(call)
+		uniqueCallers[call.FilePath+"#"+caller] = true
+		callers = append(callers, map[string]interface{}{
+			"caller":    caller,
+			"file_path": call.FilePath,
+			"line":      call.Line,
+			"call":      call.CalleeExpr,
+			"language":  call.Language,
+		})
+	}
+
+	if len(callers) == 0 {
+		suggestions := cstm.FindProjectAlternatives(ctx, input.ProjectID)
+		payload := CreateEmptyResultTOON(
+			fmt.Sprintf("No calls to '%s' found in project '%s'", input.SymbolName, input.ProjectID),
+			suggestions,
+		)
+		return protocol.NewCallToolResult([]protocol.Content{
+			&protocol.TextContent{Type: "text", Text: payload},
+		}, false), nil
+	}
+
+	result := map[string]interface{}{
+		"symbol":         input.SymbolName,
+		"callers":        callers,
+		"count":          len(callers),
+		"unique_callers": len(uniqueCallers),
+	}
+
+	return protocol.NewCallToolResult([]protocol.Content{
+		&protocol.TextContent{Type: "text", Text: MarshalYAML(result)},
+	}, false), nil
+}
+
+func (cstm *CodeSearchToolManager) codeFindCalleesHandler(ctx context.Context, req *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+	var input CodeFindCalleesInput
+	if err := json.Unmarshal(req.RawArguments, &input); err != nil {
+		return nil, fmt.Errorf("failed to parse input: %w", err)
+	}
+
+	if input.ProjectID == "" || input.Symbol == "" {
+		return nil, validationErrorf("project_id and symbol are required")
+	}
+
+	if input.Limit <= 0 {
+		input.Limit = 100
+	}
+
+	codeStorage, ok := cstm.storage.(interface {
+		FindCodeCallees(ctx context.Context, projectID, caller, filePath string, limit int) ([]storage.CodeCall, error)
+		FindSymbolsByName(ctx context.Context, projectID, name string, symbolTypes []treesitter.SymbolType, limit int) ([]storage.CodeSymbol, error)
+	})
+	if !ok {
+		return nil, fmt.Errorf("storage does not support code operations")
+	}
+
+	calls, err := codeStorage.FindCodeCallees(ctx, input.ProjectID, input.Symbol, input.RelativePath, input.Limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find callees: %w", err)
+	}
+
+	if len(calls) == 0 {
+		suggestions := cstm.FindProjectAlternatives(ctx, input.ProjectID)
+		payload := CreateEmptyResultTOON(
+			fmt.Sprintf("No calls made by '%s' found in project '%s'", input.Symbol, input.ProjectID),
+			suggestions,
+		)
+		return protocol.NewCallToolResult([]protocol.Content{
+			&protocol.TextContent{Type: "text", Text: payload},
+		}, false), nil
+	}
+
+	// Look up where each callee is defined in the project; callees without
+	// definitions come from libraries or the standard library
+	callableTypes := []treesitter.SymbolType{
+		treesitter.SymbolTypeFunction,
+		treesitter.SymbolTypeMethod,
+		treesitter.SymbolTypeConstructor,
+		treesitter.SymbolTypeClass,
+		treesitter.SymbolTypeStruct,
+		treesitter.SymbolTypeComponent,
+		treesitter.SymbolTypeHook,
+	}
+	definitions := make(map[string][]map[string]interface{})
+	for _, call := range calls {
+		if _, seen := definitions[call.CalleeName]; seen {
+			continue
+		}
+		// FindSymbolsByName matches substrings, so over-fetch and keep exact names
+		symbols, err := codeStorage.FindSymbolsByName(ctx, input.ProjectID, call.CalleeName, callableTypes, maxCalleeDefinitions*4)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve callee %s: %w", call.CalleeName, err)
+		}
+		defs := make([]map[string]interface{}, 0, maxCalleeDefinitions)
+		for _, sym := range symbols {
+			if sym.Name != call.CalleeName || len(defs) >= maxCalleeDefinitions {
+				continue
+			}
+			defs = append(defs, map[string]interface{}{
+				"name_path":  sym.NamePath,
+				"file_path":  sym.FilePath,
+				"start_line": sym.StartLine,
+			})
+		}
+		definitions[call.CalleeName] = defs
+	}
+
+	callees := make([]map[string]interface{}, 0, len(calls))
+	for _, call := range calls {
+		callee := map[string]interface{}{
+			"callee":    call.CalleeName,
+			"call":      call.CalleeExpr,
+			"caller":    callerLabel(call),
+			"file_path": call.FilePath,
+			"line":      call.Line,
+		}
+		if defs := definitions[call.CalleeName]; len(defs) > 0 {
+			callee["definitions"] = defs
+		} else {
+			callee["external"] = true
+		}
+		callees = append(callees, callee)
+	}
+
+	result := map[string]interface{}{
+		"symbol":  input.Symbol,
+		"callees": callees,
+		"count":   len(callees),
+	}
+
+	return protocol.NewCallToolResult([]protocol.Content{
+		&protocol.TextContent{Type: "text", Text: MarshalYAML(result)},
+	}, false), nil
+}
@@ -0,0 +1,296 @@
+// Package mcp_tools provides code search MCP tools.
+// This file contains handler implementations for the dependency graph tools.
+package mcp_tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ThinkInAIXYZ/go-mcp/protocol"
+	"github.com/madeindigio/remembrances-mcp/internal/storage"
+)
+
+// dependencyEdge is an import edge of the exported dependency graph
+type dependencyEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// dependencyNode is a file, package directory or external module of the exported graph
+type dependencyNode struct {
+	ID       string `json:"id"`
+	External bool   `json:"external,omitempty"`
+}
+
+func (cstm *CodeSearchToolManager) codeGetDependenciesHandler(ctx context.Context, req *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+	var input CodeGetDependenciesInput
+	if err := json.Unmarshal(req.RawArguments, &input); err != nil {
+		return nil, fmt.Errorf("failed to parse input: %w", err)
+	}
+
+	if input.ProjectID == "" || input.RelativePath == "" {
+		return nil, validationErrorf("project_id and relative_path are required")
+	}
+
+	codeStorage, ok := cstm.storage.(interface {
+		GetCodeDependencies(ctx context.Context, projectID, filePath string) ([]storage.CodeDependency, error)
+	})
+	if !ok {
+		return nil, fmt.Errorf("storage does not support code operations")
+	}
+
+	deps, err := codeStorage.GetCodeDependencies(ctx, input.ProjectID, input.RelativePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dependencies: %w", err)
+	}
+
+	if len(deps) == 0 {
+		suggestions := cstm.FindProjectAlternatives(ctx, input.ProjectID)
+		payload := CreateEmptyResultTOON(
+			fmt.Sprintf("No dependencies found for '%s' in project '%s'", input.RelativePath, input.ProjectID),
+			suggestions,
+		)
+		return protocol.NewCallToolResult([]protocol.Content{
+			&protocol.TextContent{Type: "text", Text: payload},
+		}, false), nil
+	}
+
+	dependencies := make([]map[string]interface{}, 0, len(deps))
+	internal := 0
+	for _, dep := range deps {
+		entry := map[string]interface{}{
+			"module": dep.Module,
+			"line":   dep.Line,
+		}
+		if dep.TargetPath != nil {
+			entry["target_path"] = *dep.TargetPath
+			internal++
+		} else {
+			entry["external"] = true
+		}
+		dependencies = append(dependencies, entry)
+	}
+
+	result := map[string]interface{}{
+		"file_path":    input.RelativePath,
+		"dependencies": dependencies,
+		"count":        len(dependencies),
+		"internal":     internal,
+		"external":     len(dependencies) - internal,
+	}
+
+	return protocol.NewCallToolResult([]protocol.Content{
+		&protocol.TextContent{Type: "text", Text: MarshalYAML(result)},
+	}, false), nil
+}
+
+func (cstm *CodeSearchToolManager) codeGetDependentsHandler(ctx context.Context, req *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+	var input CodeGetDependentsInput
+	if err := json.Unmarshal(req.RawArguments, &input); err != nil {
+		return nil, fmt.Errorf("failed to parse input: %w", err)
+	}
+
+	if input.ProjectID == "" {
+		return nil, validationErrorf("project_id is required")
+	}
+
+	if input.RelativePath == "" && input.Module == "" {
+		return nil, validationErrorf("either relative_path or module is required")
+	}
+
+	codeStorage, ok := cstm.storage.(interface {
+		GetCodeDependents(ctx context.Context, projectID string, targets []string) ([]storage.CodeDependency, error)
+	})
+	if !ok {
+		return nil, fmt.Errorf("storage does not support code operations")
+	}
+
+	// Go imports resolve to the package directory, so a file is also
+	// depended on through its directory
+	var targets []string
+	if input.RelativePath != "" {
+		targets = append(targets, input.RelativePath)
+		if dir := filepath.Dir(input.RelativePath); dir != input.RelativePath {
+			targets = append(targets, dir)
+		}
+	}
+	if input.Module != "" {
+		targets = append(targets, input.Module)
+	}
+
+	deps, err := codeStorage.GetCodeDependents(ctx, input.ProjectID, targets)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dependents: %w", err)
+	}
+
+	target := input.RelativePath
+	if target == "" {
+		target = input.Module
+	}
+
+	if len(deps) == 0 {
+		suggestions := cstm.FindProjectAlternatives(ctx, input.ProjectID)
+		payload := CreateEmptyResultTOON(
+			fmt.Sprintf("No files depend on '%s' in project '%s'", target, input.ProjectID),
+			suggestions,
+		)
+		return protocol.NewCallToolResult([]protocol.Content{
+			&protocol.TextContent{Type: "text", Text: payload},
+		}, false), nil
+	}
+
+	dependents := make([]map[string]interface{}, 0, len(deps))
+	for _, dep := range deps {
+		dependents = append(dependents, map[string]interface{}{
+			"file_path": dep.FilePath,
+			"module":    dep.Module,
+			"line":      dep.Line,
+			"language":  dep.Language,
+		})
+	}
+
+	result := map[string]interface{}{
+		"target":     target,
+		"dependents": dependents,
+		"count":      len(dependents),
+	}
+
+	return protocol.NewCallToolResult([]protocol.Content{
+		&protocol.TextContent{Type: "text", Text: MarshalYAML(result)},
+	}, false), nil
+}
+
+func (cstm *CodeSearchToolManager) codeExportDependencyGraphHandler(ctx context.Context, req *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+	var input CodeExportDependencyGraphInput
+	if err := json.Unmarshal(req.RawArguments, &input); err != nil {
+		return nil, fmt.Errorf("failed to parse input: %w", err)
+	}
+
+	if input.ProjectID == "" {
+		return nil, validationErrorf("project_id is required")
+	}
+
+	format := strings.ToLower(input.Format)
+	if format == "" {
+		format = "json"
+	}
+	if format != "json" && format != "dot" {
+		return nil, validationErrorf("invalid format %q: must be 'json' or 'dot'", input.Format)
+	}
+
+	codeStorage, ok := cstm.storage.(interface {
+		ListCodeDependencies(ctx context.Context, projectID string) ([]storage.CodeDependency, error)
+	})
+	if !ok {
+		return nil, fmt.Errorf("storage does not support code operations")
+	}
+
+	deps, err := codeStorage.ListCodeDependencies(ctx, input.ProjectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list dependencies: %w", err)
+	}
+
+	nodes, edges := buildDependencyGraph(deps, input.IncludeExternal)
+
+	if len(edges) == 0 {
+		suggestions := cstm.FindProjectAlternatives(ctx, input.ProjectID)
+		payload := CreateEmptyResultTOON(
+			fmt.Sprintf("No dependencies recorded for project '%s'", input.ProjectID),
+			suggestions,
+		)
+		return protocol.NewCallToolResult([]protocol.Content{
+			&protocol.TextContent{Type: "text", Text: payload},
+		}, false), nil
+	}
+
+	var text string
+	if format == "dot" {
+		text = dependencyGraphDOT(input.ProjectID, nodes, edges)
+	} else {
+		data, err := json.MarshalIndent(map[string]interface{}{
+			"project_id": input.ProjectID,
+			"nodes":      nodes,
+			"edges":      edges,
+		}, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal graph: %w", err)
+		}
+		text = string(data)
+	}
+
+	return protocol.NewCallToolResult([]protocol.Content{
+		&protocol.TextContent{Type: "text", Text: text},
+	}, false), nil
+}
+
+// buildDependencyGraph turns stored imports into sorted, de-duplicated nodes
+// and edges. Imports that do not resolve to a project file are kept only
+// when includeExternal is set.
+func buildDependencyGraph(deps []storage.CodeDependency, includeExternal bool) ([]dependencyNode, []dependencyEdge) {
+	nodeSet := make(map[string]bool) // id -> external
+	edgeSet := make(map[dependencyEdge]bool)
+
+	for _, dep := range deps {
+		to, external := dep.Module, true
+		if dep.TargetPath != nil {
+			to, external = *dep.TargetPath, false
+		}
+		if external && !includeExternal {
+			continue
+		}
+
+		if _, ok := nodeSet[dep.FilePath]; !ok {
+			nodeSet[dep.FilePath] = false
+		}
+		if _, ok := nodeSet[to]; !ok || !external {
+			nodeSet[to] = external
+		}
+		edgeSet[dependencyEdge{From: dep.FilePath, To: to}] = true
+	}
+
+	nodes := make([]dependencyNode, 0, len(nodeSet))
+	for id, external := range nodeSet {
+		nodes = append(nodes, dependencyNode{ID: id, External: external})
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].ID < nodes[j].ID })
+
+	edges := make([]dependencyEdge, 0, len(edgeSet))
+	for edge := range edgeSet {
+		edges = append(edges, edge)
+	}
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i].From != edges[j].From {
+			return edges[i].From < edges[j].From
+		}
+		return edges[i].To < edges[j].To
+	})
+
+	return nodes, edges
+}
+
+// dependencyGraphDOT renders the graph in Graphviz DOT format, drawing
+// external modules as dashed boxes
+func dependencyGraphDOT(projectID string, nodes []dependencyNode, edges []dependencyEdge) string {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "digraph %q {\n", projectID)
+	sb.WriteString("  rankdir=LR;\n")
+	sb.WriteString("  node [shape=ellipse];\n")
+	for _, node := range nodes {
+		if node.External {
+			fmt.Fprintf(&sb, "  %q [shape=box, style=dashed];\n", node.ID)
+		} else {
+			fmt.Fprintf(&sb, "  %q;\n", node.ID)
+		}
+	}
+	for _, edge := range edges {
+		fmt.Fprintf(&sb, "  %q -> %q;\n", edge.From, edge.To)
+	}
+	sb.WriteString("}\n")
+
+	return sb.String()
+}
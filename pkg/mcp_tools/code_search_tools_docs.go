@@ -0,0 +1,171 @@
+// Package mcp_tools provides code search MCP tools.
+// This file contains the handler of the documentation search tool.
+package mcp_tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/ThinkInAIXYZ/go-mcp/protocol"
+	"github.com/madeindigio/remembrances-mcp/internal/storage"
+	"github.com/madeindigio/remembrances-mcp/pkg/treesitter"
+)
+
+// Limits of code_search_docs.
+const (
+	defaultCodeDocResults = 10
+	maxCodeDocResults     = 50
+	// codeDocCandidates is how many candidates each search fetches per
+	// result, since language filters and undocumented symbols are dropped
+	codeDocCandidates = 3
+)
+
+// codeDocResult is a symbol as an API reference entry: how to call it and
+// what it does, without its body.
+type codeDocResult struct {
+	Name      string   `json:"name"`
+	Type      string   `json:"type"`
+	NamePath  string   `json:"name_path"`
+	Signature string   `json:"signature,omitempty"`
+	Doc       string   `json:"doc,omitempty"`
+	FilePath  string   `json:"file_path"`
+	Line      int      `json:"line"`
+	Language  string   `json:"language"`
+	MatchedBy []string `json:"matched_by"`
+}
+
+func (cstm *CodeSearchToolManager) codeSearchDocsHandler(ctx context.Context, req *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+	var input CodeSearchDocsInput
+	if err := json.Unmarshal(req.RawArguments, &input); err != nil {
+		return nil, fmt.Errorf("failed to parse input: %w", err)
+	}
+
+	if input.ProjectID == "" || input.Query == "" {
+		return nil, validationErrorf("project_id and query are required")
+	}
+
+	if input.Limit <= 0 {
+		input.Limit = defaultCodeDocResults
+	}
+	if input.Limit > maxCodeDocResults {
+		input.Limit = maxCodeDocResults
+	}
+
+	codeStorage, ok := cstm.storage.(interface {
+		SearchSymbolDocs(ctx context.Context, projectID, query string, symbolTypes []treesitter.SymbolType, limit int) ([]storage.CodeSymbolTextResult, error)
+		SearchSymbolsBySimilarity(ctx context.Context, projectID string, queryEmbedding []float32, symbolTypes []treesitter.SymbolType, limit int) ([]storage.CodeSymbolSearchResult, error)
+	})
+	if !ok {
+		return nil, fmt.Errorf("storage does not support code search operations")
+	}
+
+	var symbolTypes []treesitter.SymbolType
+	for _, t := range input.SymbolTypes {
+		symbolTypes = append(symbolTypes, treesitter.SymbolType(t))
+	}
+	fetch := input.Limit * codeDocCandidates
+
+	// Full-text matches of the doc strings and signatures
+	textResults, err := codeStorage.SearchSymbolDocs(ctx, input.ProjectID, input.Query, symbolTypes, fetch)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search symbol docs: %w", err)
+	}
+	var textMatches []storage.CodeSymbol
+	for _, r := range textResults {
+		if r.Symbol != nil && documentedSymbol(*r.Symbol, input.Languages) {
+			textMatches = append(textMatches, *r.Symbol)
+		}
+	}
+
+	// Semantic matches, among the symbols with something to show
+	var vectorMatches []storage.CodeSymbol
+	if cstm.embedder != nil {
+		ctx, queryEmbedder, err := cstm.queryEmbedder(ctx, input.ProjectID)
+		if err != nil {
+			return nil, err
+		}
+		embedding, err := queryEmbedder.EmbedQuery(ctx, input.Query)
+		if err != nil {
+			return nil, embedderErrorf("failed to generate query embedding: %w", err)
+		}
+		vectorResults, err := codeStorage.SearchSymbolsBySimilarity(ctx, input.ProjectID, embedding, symbolTypes, fetch)
+		if err != nil {
+			return nil, fmt.Errorf("failed to search symbols: %w", err)
+		}
+		vectorResults = applyScoring(cstm.scoring, vectorResults, input.MinSimilarity,
+			func(r storage.CodeSymbolSearchResult) float64 { return r.Similarity },
+			func(*storage.CodeSymbolSearchResult, float64) {})
+		for _, r := range vectorResults {
+			if r.Symbol != nil && documentedSymbol(*r.Symbol, input.Languages) {
+				vectorMatches = append(vectorMatches, *r.Symbol)
+			}
+		}
+	}
+
+	symbolKey := func(s storage.CodeSymbol) string { return s.ID }
+	fused := fuseRanked([][]storage.CodeSymbol{textMatches, vectorMatches}, symbolKey, input.Limit)
+	results := make([]codeDocResult, 0, len(fused))
+	for _, sym := range fused {
+		var matchedBy []string
+		if slices.ContainsFunc(textMatches, func(s storage.CodeSymbol) bool { return s.ID == sym.ID }) {
+			matchedBy = append(matchedBy, "text")
+		}
+		if slices.ContainsFunc(vectorMatches, func(s storage.CodeSymbol) bool { return s.ID == sym.ID }) {
+			matchedBy = append(matchedBy, "semantic")
+		}
+		results = append(results, codeDocEntry(sym, matchedBy))
+	}
+
+	if len(results) == 0 {
+		suggestions := cstm.FindProjectAlternatives(ctx, input.ProjectID)
+		payload := CreateEmptyResultTOON(
+			fmt.Sprintf("No documented symbols found for query '%s' in project '%s'", input.Query, input.ProjectID),
+			suggestions,
+		)
+		return protocol.NewCallToolResult([]protocol.Content{
+			&protocol.TextContent{Type: "text", Text: payload},
+		}, false), nil
+	}
+
+	output := map[string]interface{}{
+		"query":   input.Query,
+		"results": results,
+		"count":   len(results),
+	}
+	return protocol.NewCallToolResult([]protocol.Content{
+		&protocol.TextContent{Type: "text", Text: MarshalYAML(output)},
+	}, false), nil
+}
+
+// documentedSymbol reports whether a symbol has a doc string or signature
+// and is in one of languages, unless there are none.
+func documentedSymbol(sym storage.CodeSymbol, languages []string) bool {
+	if len(languages) > 0 && !slices.Contains(languages, string(sym.Language)) {
+		return false
+	}
+	return (sym.DocString != nil && strings.TrimSpace(*sym.DocString) != "") ||
+		(sym.Signature != nil && strings.TrimSpace(*sym.Signature) != "")
+}
+
+// codeDocEntry returns the API reference entry of a symbol.
+func codeDocEntry(sym storage.CodeSymbol, matchedBy []string) codeDocResult {
+	entry := codeDocResult{
+		Name:      sym.Name,
+		Type:      string(sym.SymbolType),
+		NamePath:  sym.NamePath,
+		FilePath:  sym.FilePath,
+		Line:      sym.StartLine,
+		Language:  string(sym.Language),
+		MatchedBy: matchedBy,
+	}
+	if sym.Signature != nil {
+		entry.Signature = strings.TrimSpace(*sym.Signature)
+	}
+	if sym.DocString != nil {
+		entry.Doc = strings.TrimSpace(*sym.DocString)
+	}
+	return entry
+}
@@ -0,0 +1,50 @@
+package mcp_tools
+
+import (
+	"testing"
+
+	"github.com/madeindigio/remembrances-mcp/internal/storage"
+)
+
+func TestDocumentedSymbol(t *testing.T) {
+	doc := "// ParseConfig reads a config file."
+	blank := "  "
+	sig := "func Run(ctx context.Context) error"
+
+	for _, tc := range []struct {
+		name      string
+		sym       storage.CodeSymbol
+		languages []string
+		want      bool
+	}{
+		{"doc", storage.CodeSymbol{Language: "go", DocString: &doc}, nil, true},
+		{"signature", storage.CodeSymbol{Language: "go", Signature: &sig}, nil, true},
+		{"blank doc", storage.CodeSymbol{Language: "go", DocString: &blank}, nil, false},
+		{"undocumented", storage.CodeSymbol{Language: "go"}, nil, false},
+		{"language kept", storage.CodeSymbol{Language: "go", DocString: &doc}, []string{"python", "go"}, true},
+		{"language dropped", storage.CodeSymbol{Language: "go", DocString: &doc}, []string{"python"}, false},
+	} {
+		if got := documentedSymbol(tc.sym, tc.languages); got != tc.want {
+			t.Errorf("documentedSymbol(%s) = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestCodeDocEntry(t *testing.T) {
+	doc := "\n// ParseConfig reads a config file.\n"
+	sig := "func ParseConfig(path string) (*Config, error)"
+	entry := codeDocEntry(storage.CodeSymbol{
+		Name:       "ParseConfig",
+		SymbolType: "function",
+		NamePath:   "/ParseConfig",
+		FilePath:   "config/config.go",
+		StartLine:  12,
+		Language:   "go",
+		Signature:  &sig,
+		DocString:  &doc,
+	}, []string{"text"})
+
+	if entry.Doc != "// ParseConfig reads a config file." || entry.Signature != sig || entry.Line != 12 {
+		t.Errorf("codeDocEntry() = %+v", entry)
+	}
+}
@@ -0,0 +1,339 @@
+// Package mcp_tools provides code search MCP tools.
+// This file contains the fuzzy and case-insensitive symbol name matching of
+// code_find_symbol.
+package mcp_tools
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"path"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/madeindigio/remembrances-mcp/internal/storage"
+	"github.com/madeindigio/remembrances-mcp/pkg/treesitter"
+)
+
+// Limits of the ranked code_find_symbol matching.
+const (
+	maxFindSymbolResults = 50
+	// maxFindSymbolCandidates caps the symbols read from storage for each
+	// kind of match, shortest names first
+	maxFindSymbolCandidates = 2000
+	// minTrigramSimilarity is the similarity under which a name is not a
+	// fuzzy match of the pattern
+	minTrigramSimilarity = 0.3
+)
+
+// Kinds of name matches, best first.
+const (
+	matchExact       = "exact"
+	matchPrefix      = "prefix"
+	matchSubstring   = "substring"
+	matchSubsequence = "subsequence"
+	matchTrigram     = "trigram"
+)
+
+// symbolKindPresets are the names accepted by include_kinds and
+// exclude_kinds for groups of symbol types.
+var symbolKindPresets = map[string][]treesitter.SymbolType{
+	"callable": {
+		treesitter.SymbolTypeFunction, treesitter.SymbolTypeMethod,
+		treesitter.SymbolTypeConstructor, treesitter.SymbolTypeHook,
+	},
+	"type": {
+		treesitter.SymbolTypeClass, treesitter.SymbolTypeStruct, treesitter.SymbolTypeInterface,
+		treesitter.SymbolTypeTrait, treesitter.SymbolTypeEnum, treesitter.SymbolTypeTypeAlias,
+		treesitter.SymbolTypeMessage,
+	},
+	"member": {
+		treesitter.SymbolTypeMethod, treesitter.SymbolTypeConstructor, treesitter.SymbolTypeProperty,
+		treesitter.SymbolTypeField, treesitter.SymbolTypeEnumMember,
+	},
+}
+
+// expandKindPresets replaces the presets in kinds with their symbol types.
+func expandKindPresets(kinds []string) []string {
+	var expanded []string
+	seen := map[string]bool{}
+	for _, kind := range kinds {
+		types := []string{kind}
+		if preset, ok := symbolKindPresets[strings.ToLower(kind)]; ok {
+			types = types[:0]
+			for _, t := range preset {
+				types = append(types, string(t))
+			}
+		}
+		for _, t := range types {
+			if !seen[t] {
+				seen[t] = true
+				expanded = append(expanded, t)
+			}
+		}
+	}
+	return expanded
+}
+
+// rankedSymbol is a symbol matched by name with the quality of the match.
+type rankedSymbol struct {
+	symbol storage.CodeSymbol
+	match  string
+	score  float64
+}
+
+// findRankedSymbols returns the symbols of a project matching the pattern of
+// input case-insensitively or fuzzily, best matches first, as rows like those
+// of a code_symbols query with their match and score. Storage only returns
+// the symbols whose names may match: names containing the pattern first, and
+// when they are too few and input is fuzzy, names that may be subsequence or
+// trigram matches, whose scores are lower.
+func (cstm *CodeSearchToolManager) findRankedSymbols(ctx context.Context, input CodeFindSymbolInput) ([]map[string]interface{}, error) {
+	codeStorage, ok := cstm.storage.(interface {
+		ListCodeSymbolsByName(ctx context.Context, projectID string, filter storage.CodeSymbolNameFilter) ([]storage.CodeSymbol, error)
+		FindSymbolsByFile(ctx context.Context, projectID, filePath string) ([]storage.CodeSymbol, error)
+	})
+	if !ok {
+		return nil, fmt.Errorf("storage does not support code operations")
+	}
+
+	// The last segment of a path pattern is matched against the name; the
+	// others must be in the name path
+	pattern := input.NamePathPattern
+	absolute := strings.HasPrefix(pattern, "/")
+	parentPath := ""
+	if i := strings.LastIndex(pattern, "/"); i >= 0 {
+		parentPath, pattern = strings.ToLower(pattern[:i]), pattern[i+1:]
+	}
+	// An ordinal, as in add#2, picks one of the symbols sharing a name path
+	pattern, ordinal := treesitter.SplitNamePathOrdinal(pattern)
+	if pattern == "" {
+		return []map[string]interface{}{}, nil
+	}
+
+	var ranked []rankedSymbol
+	seen := map[string]bool{}
+	rank := func(filter storage.CodeSymbolNameFilter) error {
+		filter.Path = input.RelativePath
+		filter.Types, filter.ExcludeTypes = input.IncludeKinds, input.ExcludeKinds
+		filter.Limit = maxFindSymbolCandidates
+		symbols, err := codeStorage.ListCodeSymbolsByName(ctx, input.ProjectID, filter)
+		if err != nil {
+			return fmt.Errorf("failed to list symbols: %w", err)
+		}
+		for _, sym := range symbols {
+			if seen[sym.ID] {
+				continue
+			}
+			seen[sym.ID] = true
+			if absolute && !namePathUnder(path.Dir(sym.NamePath), "/"+strings.TrimPrefix(parentPath, "/"), true) {
+				continue
+			}
+			if !absolute && parentPath != "" && !namePathUnder(sym.NamePath, parentPath+"/", false) {
+				continue
+			}
+			if ordinal > 0 {
+				if _, n := treesitter.SplitNamePathOrdinal(sym.NamePath); n != ordinal {
+					continue
+				}
+			}
+			score, match := nameMatch(pattern, sym.Name, input.Fuzzy, input.SubstringMatch)
+			if match == "" {
+				continue
+			}
+			ranked = append(ranked, rankedSymbol{symbol: sym, match: match, score: score})
+		}
+		return nil
+	}
+
+	lower := strings.ToLower(pattern)
+	if err := rank(storage.CodeSymbolNameFilter{Contains: lower, Exact: !input.Fuzzy && !input.SubstringMatch}); err != nil {
+		return nil, err
+	}
+	// Names containing the pattern outscore the other fuzzy matches
+	if input.Fuzzy && len(ranked) < maxFindSymbolResults {
+		allOf, anyOf := fuzzyNameTexts(lower)
+		if err := rank(storage.CodeSymbolNameFilter{AllOf: allOf, AnyOf: anyOf}); err != nil {
+			return nil, err
+		}
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if ranked[i].score != ranked[j].score {
+			return ranked[i].score > ranked[j].score
+		}
+		if ranked[i].symbol.FilePath != ranked[j].symbol.FilePath {
+			return ranked[i].symbol.FilePath < ranked[j].symbol.FilePath
+		}
+		return ranked[i].symbol.StartLine < ranked[j].symbol.StartLine
+	})
+	if len(ranked) > maxFindSymbolResults {
+		ranked = ranked[:maxFindSymbolResults]
+	}
+
+	// Listed symbols have no source code; read it from their files
+	sources := map[string]string{}
+	if input.IncludeBody {
+		read := map[string]bool{}
+		for _, r := range ranked {
+			if read[r.symbol.FilePath] {
+				continue
+			}
+			read[r.symbol.FilePath] = true
+			fileSymbols, err := codeStorage.FindSymbolsByFile(ctx, input.ProjectID, r.symbol.FilePath)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get symbols of %s: %w", r.symbol.FilePath, err)
+			}
+			for _, s := range fileSymbols {
+				if s.SourceCode != nil {
+					sources[s.ID] = *s.SourceCode
+				}
+			}
+		}
+	}
+
+	rows := make([]map[string]interface{}, 0, len(ranked))
+	for _, r := range ranked {
+		row := map[string]interface{}{
+			"id":          r.symbol.ID,
+			"name":        r.symbol.Name,
+			"symbol_type": r.symbol.SymbolType,
+			"name_path":   r.symbol.NamePath,
+			"file_path":   r.symbol.FilePath,
+			"language":    r.symbol.Language,
+			"start_line":  r.symbol.StartLine,
+			"end_line":    r.symbol.EndLine,
+			"signature":   valueOrNil(r.symbol.Signature),
+			"revision":    r.symbol.Revision,
+			"match":       r.match,
+			"score":       math.Round(r.score*100) / 100,
+		}
+		if source, ok := sources[r.symbol.ID]; ok {
+			row["source_code"] = source
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// namePathUnder reports whether a name path, with or without its ordinals,
+// is parentPath or, unless exact, contains it. Letters are compared
+// case-insensitively.
+func namePathUnder(namePath, parentPath string, exact bool) bool {
+	for _, p := range []string{namePath, treesitter.StripNamePathOrdinals(namePath)} {
+		if exact && strings.EqualFold(p, parentPath) {
+			return true
+		}
+		if !exact && strings.Contains(strings.ToLower(p), strings.ToLower(parentPath)) {
+			return true
+		}
+	}
+	return false
+}
+
+// fuzzyNameTexts returns the texts a name must contain to possibly be a
+// subsequence or trigram match of the lower case pattern p: all of its
+// letters, or one of its trigrams without their padding. Trigrams padded to
+// a single letter are left out, since sharing only one of them scores under
+// minTrigramSimilarity, and patterns shorter than three letters need none, as
+// their trigram matches contain them.
+func fuzzyNameTexts(p string) (allOf, anyOf []string) {
+	seen := map[string]bool{}
+	for _, r := range p {
+		if letter := string(r); !seen[letter] {
+			seen[letter] = true
+			allOf = append(allOf, letter)
+		}
+	}
+	if utf8.RuneCountInString(p) < 3 {
+		return allOf, nil
+	}
+	seen = map[string]bool{}
+	for trigram := range trigrams(p) {
+		if text := strings.TrimSpace(trigram); utf8.RuneCountInString(text) > 1 && !seen[text] {
+			seen[text] = true
+			anyOf = append(anyOf, text)
+		}
+	}
+	sort.Strings(anyOf)
+	return allOf, anyOf
+}
+
+// nameMatch scores how well name matches pattern, ignoring case, from 1 for
+// an exact match down, and returns the kind of match, or "" when it does not
+// match. Without fuzzy, only exact matches and, with substring, substring
+// matches count. An exact match with the same case scores highest.
+func nameMatch(pattern, name string, fuzzy, substring bool) (float64, string) {
+	p, n := strings.ToLower(pattern), strings.ToLower(name)
+	if p == "" || n == "" {
+		return 0, ""
+	}
+	// How much of the name the pattern covers, to prefer shorter names
+	coverage := float64(len(p)) / float64(max(len(n), len(p)))
+
+	switch {
+	case pattern == name:
+		return 1, matchExact
+	case p == n:
+		return 0.95, matchExact
+	case (fuzzy || substring) && strings.HasPrefix(n, p):
+		return 0.8 + 0.1*coverage, matchPrefix
+	case (fuzzy || substring) && strings.Contains(n, p):
+		return 0.6 + 0.1*coverage, matchSubstring
+	case !fuzzy:
+		return 0, ""
+	case isSubsequence(p, n):
+		return 0.4 + 0.1*coverage, matchSubsequence
+	}
+	if sim := trigramSimilarity(p, n); sim >= minTrigramSimilarity {
+		return 0.4 * sim, matchTrigram
+	}
+	return 0, ""
+}
+
+// isSubsequence reports whether the runes of p appear in s in order, as in
+// "gsu" for "getsymbolusage".
+func isSubsequence(p, s string) bool {
+	rest := []rune(p)
+	for _, r := range s {
+		if len(rest) > 0 && r == rest[0] {
+			rest = rest[1:]
+		}
+	}
+	return len(rest) == 0
+}
+
+// trigramSimilarity returns the Jaccard similarity of the trigrams of a and
+// b, padded so that short strings have trigrams, which tolerates typos.
+func trigramSimilarity(a, b string) float64 {
+	ta, tb := trigrams(a), trigrams(b)
+	if len(ta) == 0 || len(tb) == 0 {
+		return 0
+	}
+	shared := 0
+	for t := range ta {
+		if tb[t] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(ta)+len(tb)-shared)
+}
+
+// trigrams returns the set of trigrams of s padded with spaces.
+func trigrams(s string) map[string]bool {
+	runes := []rune("  " + s + " ")
+	set := make(map[string]bool, len(runes))
+	for i := 0; i+3 <= len(runes); i++ {
+		set[string(runes[i:i+3])] = true
+	}
+	return set
+}
+
+// valueOrNil returns the value of s, or nil.
+func valueOrNil(s *string) interface{} {
+	if s == nil {
+		return nil
+	}
+	return *s
+}
@@ -0,0 +1,102 @@
+package mcp_tools
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestNameMatch(t *testing.T) {
+	for _, tc := range []struct {
+		pattern, name    string
+		fuzzy, substring bool
+		wantMatch        string
+	}{
+		{"ParseConfig", "ParseConfig", false, false, matchExact},
+		{"parseconfig", "ParseConfig", false, false, matchExact},
+		{"parse", "ParseConfig", false, false, ""},
+		{"parse", "ParseConfig", false, true, matchPrefix},
+		{"config", "ParseConfig", true, false, matchSubstring},
+		{"gsu", "getSymbolUsage", true, false, matchSubsequence},
+		{"getSimbolUsage", "getSymbolUsage", true, false, matchTrigram},
+		{"render", "ParseConfig", true, false, ""},
+	} {
+		_, match := nameMatch(tc.pattern, tc.name, tc.fuzzy, tc.substring)
+		if match != tc.wantMatch {
+			t.Errorf("nameMatch(%q, %q, fuzzy=%v, substring=%v) = %q, want %q",
+				tc.pattern, tc.name, tc.fuzzy, tc.substring, match, tc.wantMatch)
+		}
+	}
+}
+
+func TestNameMatchRanksBetterMatchesFirst(t *testing.T) {
+	names := []string{"ParseConfigFile", "parseConfig", "reparseConfig", "ParseConfig", "pConfig"}
+	scores := map[string]float64{}
+	for _, name := range names {
+		scores[name], _ = nameMatch("ParseConfig", name, true, false)
+	}
+	slices.SortStableFunc(names, func(a, b string) int {
+		return cmp.Compare(scores[b], scores[a])
+	})
+	want := []string{"ParseConfig", "parseConfig", "ParseConfigFile", "reparseConfig", "pConfig"}
+	if !slices.Equal(names, want) {
+		t.Errorf("ranked %v, want %v", names, want)
+	}
+}
+
+func TestExpandKindPresets(t *testing.T) {
+	got := expandKindPresets([]string{"callable", "method", "class"})
+	want := []string{"function", "method", "constructor", "hook", "class"}
+	if !slices.Equal(got, want) {
+		t.Errorf("expandKindPresets = %v, want %v", got, want)
+	}
+}
+
+func TestNamePathUnder(t *testing.T) {
+	tests := []struct {
+		namePath, parentPath string
+		exact                bool
+		want                 bool
+	}{
+		{"/Point", "/point", true, true},
+		{"/Point/add#2", "/Point/add", true, true},
+		{"/Point/add#2", "/Point/add#2", true, true},
+		{"/Point/add", "/Point/add#2", true, false},
+		{"/pkg/Point/add#2/inner", "point/add/", false, true},
+		{"/pkg/Point/add/inner", "point/add#2/", false, false},
+	}
+	for _, tt := range tests {
+		if got := namePathUnder(tt.namePath, tt.parentPath, tt.exact); got != tt.want {
+			t.Errorf("namePathUnder(%q, %q, %v) = %v, want %v", tt.namePath, tt.parentPath, tt.exact, got, tt.want)
+		}
+	}
+}
+
+func TestFuzzyNameTexts(t *testing.T) {
+	allOf, anyOf := fuzzyNameTexts("getsimbol")
+	if want := []string{"g", "e", "t", "s", "i", "m", "b", "o", "l"}; !slices.Equal(allOf, want) {
+		t.Errorf("allOf = %v, want %v", allOf, want)
+	}
+	if want := []string{"bol", "ets", "ge", "get", "imb", "mbo", "ol", "sim", "tsi"}; !slices.Equal(anyOf, want) {
+		t.Errorf("anyOf = %v, want %v", anyOf, want)
+	}
+
+	// Every fuzzy match must be among the symbols selected by the texts
+	names := []string{"getSymbolUsage", "GetSymbol", "gs", "symbol", "bolts", "target", "Simple", "mbox", "x", "ab", "ba", "abc", "cab"}
+	for _, pattern := range []string{"getsimbol", "gsu", "symbl", "ab", "a", "cba", "tget"} {
+		allOf, anyOf := fuzzyNameTexts(pattern)
+		for _, name := range names {
+			_, match := nameMatch(pattern, name, true, false)
+			if match != matchSubsequence && match != matchTrigram {
+				continue
+			}
+			lower := strings.ToLower(name)
+			selected := !slices.ContainsFunc(allOf, func(s string) bool { return !strings.Contains(lower, s) }) ||
+				slices.ContainsFunc(anyOf, func(s string) bool { return strings.Contains(lower, s) })
+			if !selected {
+				t.Errorf("%s match of %q by %q is not selected by %v or %v", match, name, pattern, allOf, anyOf)
+			}
+		}
+	}
+}
@@ -0,0 +1,204 @@
+// Package mcp_tools provides code search MCP tools.
+// This file contains the handler implementation for code_grep.
+package mcp_tools
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/ThinkInAIXYZ/go-mcp/protocol"
+	"github.com/madeindigio/remembrances-mcp/internal/indexer"
+	"github.com/madeindigio/remembrances-mcp/internal/storage"
+)
+
+// maxGrepLineLength bounds the line text returned for a match
+const maxGrepLineLength = 300
+
+// grepLine trims a matched or context line for output
+func grepLine(line string) string {
+	line = strings.TrimRight(line, "\r")
+	if len(line) > maxGrepLineLength {
+		return line[:maxGrepLineLength] + "..."
+	}
+	return line
+}
+
+func (cstm *CodeSearchToolManager) codeGrepHandler(ctx context.Context, req *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+	var input CodeGrepInput
+	if err := json.Unmarshal(req.RawArguments, &input); err != nil {
+		return nil, fmt.Errorf("failed to parse input: %w", err)
+	}
+
+	if input.ProjectID == "" || input.Pattern == "" {
+		return nil, validationErrorf("project_id and pattern are required")
+	}
+
+	if input.Limit <= 0 {
+		input.Limit = 100
+	}
+	if input.ContextLines < 0 {
+		input.ContextLines = 0
+	}
+
+	// Literal patterns are searched as escaped regular expressions
+	expr := input.Pattern
+	if !input.IsRegex {
+		expr = regexp.QuoteMeta(expr)
+	}
+	if !input.CaseSensitive {
+		expr = "(?i)" + expr
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, validationErrorf("invalid regex pattern: %w", err)
+	}
+
+	codeStorage, ok := cstm.storage.(interface {
+		GetCodeProject(ctx context.Context, projectID string) (*storage.CodeProject, error)
+		ListCodeFiles(ctx context.Context, projectID string) ([]storage.CodeFile, error)
+	})
+	if !ok {
+		return nil, fmt.Errorf("storage does not support code operations")
+	}
+
+	project, err := codeStorage.GetCodeProject(ctx, input.ProjectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get project: %w", err)
+	}
+	if project == nil {
+		return nil, notFoundErrorf("project not found: %s", input.ProjectID)
+	}
+
+	files, err := codeStorage.ListCodeFiles(ctx, input.ProjectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files: %w", err)
+	}
+
+	// Only indexed files are searched, so the project's exclude rules apply;
+	// paths and exclude use the same pattern syntax
+	include := &indexer.FileScanner{ExcludePatterns: input.Paths}
+	exclude := &indexer.FileScanner{ExcludePatterns: input.Exclude}
+
+	matches := make([]map[string]interface{}, 0)
+	staleFiles := make([]string, 0)
+	filesSearched, filesMatched := 0, 0
+	truncated := false
+
+	for _, file := range files {
+		if len(input.Languages) > 0 && !containsFold(input.Languages, string(file.Language)) {
+			continue
+		}
+		absPath := filepath.Join(project.RootPath, file.FilePath)
+		if len(input.Paths) > 0 && !include.ShouldExclude(absPath, file.FilePath, false) {
+			continue
+		}
+		if len(input.Exclude) > 0 && exclude.ShouldExclude(absPath, file.FilePath, false) {
+			continue
+		}
+
+		content, _, err := readProjectFile(ctx, cstm.storage, project, file.FilePath)
+		if err != nil {
+			continue
+		}
+		filesSearched++
+		if bytes.IndexByte(content, 0) >= 0 || !re.Match(content) {
+			continue
+		}
+
+		// Files changed since indexing are still searched, but flagged
+		sum := sha256.Sum256(content)
+		stale := file.FileHash != "" && hex.EncodeToString(sum[:]) != file.FileHash
+		if stale {
+			staleFiles = append(staleFiles, file.FilePath)
+		}
+
+		lines := strings.Split(string(content), "\n")
+		fileMatched := false
+		for i, line := range lines {
+			loc := re.FindStringIndex(line)
+			if loc == nil {
+				continue
+			}
+			if len(matches) >= input.Limit {
+				truncated = true
+				break
+			}
+			fileMatched = true
+
+			match := map[string]interface{}{
+				"file_path": file.FilePath,
+				"line":      i + 1,
+				"column":    loc[0] + 1,
+				"text":      grepLine(line),
+			}
+			if input.ContextLines > 0 {
+				before := make([]string, 0, input.ContextLines)
+				for _, l := range lines[max(i-input.ContextLines, 0):i] {
+					before = append(before, grepLine(l))
+				}
+				after := make([]string, 0, input.ContextLines)
+				for _, l := range lines[i+1 : min(i+1+input.ContextLines, len(lines))] {
+					after = append(after, grepLine(l))
+				}
+				match["before"] = before
+				match["after"] = after
+			}
+			if stale {
+				match["stale"] = true
+			}
+			matches = append(matches, match)
+		}
+		if fileMatched {
+			filesMatched++
+		}
+		if truncated {
+			break
+		}
+	}
+
+	if len(matches) == 0 {
+		suggestions := cstm.FindProjectAlternatives(ctx, input.ProjectID)
+		payload := CreateEmptyResultTOON(
+			fmt.Sprintf("No matches for '%s' in %d files of project '%s'", input.Pattern, filesSearched, input.ProjectID),
+			suggestions,
+		)
+		return protocol.NewCallToolResult([]protocol.Content{
+			&protocol.TextContent{Type: "text", Text: payload},
+		}, false), nil
+	}
+
+	result := map[string]interface{}{
+		"pattern":        input.Pattern,
+		"matches":        matches,
+		"count":          len(matches),
+		"files_matched":  filesMatched,
+		"files_searched": filesSearched,
+	}
+	if truncated {
+		result["truncated"] = true
+	}
+	if len(staleFiles) > 0 {
+		result["stale_files"] = staleFiles
+	}
+
+	return protocol.NewCallToolResult([]protocol.Content{
+		&protocol.TextContent{Type: "text", Text: MarshalYAML(result)},
+	}, false), nil
+}
+
+// containsFold reports whether values contains s, ignoring case
+func containsFold(values []string, s string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,119 @@
+package mcp_tools
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ThinkInAIXYZ/go-mcp/protocol"
+	"github.com/madeindigio/remembrances-mcp/internal/storage"
+	"github.com/madeindigio/remembrances-mcp/pkg/treesitter"
+)
+
+// grepStorage is a code project rooted at root with the given indexed files.
+type grepStorage struct {
+	storage.Storage
+	root  string
+	files []storage.CodeFile
+}
+
+func (s *grepStorage) GetCodeProject(ctx context.Context, projectID string) (*storage.CodeProject, error) {
+	return &storage.CodeProject{ProjectID: projectID, RootPath: s.root}, nil
+}
+
+func (s *grepStorage) ListCodeFiles(ctx context.Context, projectID string) ([]storage.CodeFile, error) {
+	return s.files, nil
+}
+
+func (s *grepStorage) ListCodeProjects(ctx context.Context) ([]storage.CodeProject, error) {
+	return []storage.CodeProject{{ProjectID: "app", RootPath: s.root}}, nil
+}
+
+func TestCodeGrep(t *testing.T) {
+	root := t.TempDir()
+	st := &grepStorage{root: root}
+	for _, f := range []struct {
+		path, content string
+		lang          treesitter.Language
+		indexed       bool
+	}{
+		{"main.go", "package main\n\n// TODO retry\nfunc main() {}\n", treesitter.LanguageGo, true},
+		{"pkg/util.go", "package pkg\n\n// todo: later\n", treesitter.LanguageGo, true},
+		{"README.md", "# App\n\nTODO docs\n", treesitter.LanguageMarkdown, true},
+		{"secret.go", "// TODO not indexed\n", treesitter.LanguageGo, false},
+	} {
+		abs := filepath.Join(root, filepath.FromSlash(f.path))
+		if err := os.MkdirAll(filepath.Dir(abs), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(abs, []byte(f.content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if f.indexed {
+			sum := sha256.Sum256([]byte(f.content))
+			st.files = append(st.files, storage.CodeFile{FilePath: f.path, Language: f.lang, FileHash: hex.EncodeToString(sum[:])})
+		}
+	}
+	// util.go changed on disk after it was indexed
+	st.files[1].FileHash = "outdated"
+
+	cstm := NewCodeSearchToolManager(st, nil)
+	grep := func(args map[string]interface{}) (string, error) {
+		args["project_id"] = "app"
+		raw, _ := json.Marshal(args)
+		result, err := cstm.codeGrepHandler(context.Background(), &protocol.CallToolRequest{RawArguments: raw})
+		if err != nil {
+			return "", err
+		}
+		return result.Content[0].(*protocol.TextContent).Text, nil
+	}
+
+	tests := []struct {
+		name  string
+		args  map[string]interface{}
+		want  []string
+		files []string // the files that must match; the other indexed files must not
+	}{
+		{"literal", map[string]interface{}{"pattern": "todo"}, []string{"count: 3", "stale_files"}, []string{"main.go", "pkg/util.go", "README.md"}},
+		{"case sensitive", map[string]interface{}{"pattern": "TODO", "case_sensitive": true}, []string{"count: 2"}, []string{"main.go", "README.md"}},
+		{"languages", map[string]interface{}{"pattern": "todo", "languages": []string{"Go"}}, []string{"count: 2"}, []string{"main.go", "pkg/util.go"}},
+		{"paths", map[string]interface{}{"pattern": "todo", "paths": []string{"pkg/**"}}, []string{"count: 1"}, []string{"pkg/util.go"}},
+		{"exclude", map[string]interface{}{"pattern": "todo", "exclude": []string{"*.md"}}, []string{"count: 2"}, []string{"main.go", "pkg/util.go"}},
+		{"regex", map[string]interface{}{"pattern": `func \w+\(`, "is_regex": true}, []string{"count: 1", "func main() {}"}, []string{"main.go"}},
+		{"context", map[string]interface{}{"pattern": "func", "context_lines": 1}, []string{"// TODO retry"}, []string{"main.go"}},
+		{"no match", map[string]interface{}{"pattern": "FIXME"}, []string{"No matches for 'FIXME' in 3 files"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text, err := grep(tt.args)
+			if err != nil {
+				t.Fatalf("code_grep: %v", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(text, want) {
+					t.Errorf("result does not contain %q:\n%s", want, text)
+				}
+			}
+			for _, f := range st.files {
+				if matched := strings.Contains(text, f.FilePath); matched != containsFold(tt.files, f.FilePath) {
+					t.Errorf("%s matched = %v:\n%s", f.FilePath, matched, text)
+				}
+			}
+			if strings.Contains(text, "secret.go") {
+				t.Errorf("a file that is not indexed was searched:\n%s", text)
+			}
+		})
+	}
+
+	_, err := grep(map[string]interface{}{"pattern": "(", "is_regex": true})
+	var toolErr *ToolError
+	if !errors.As(err, &toolErr) || toolErr.Code != ErrCodeValidation {
+		t.Errorf("invalid regex = %v, want a validation error", err)
+	}
+}
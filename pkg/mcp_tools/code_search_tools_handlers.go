@@ -24,7 +24,7 @@ func (cstm *CodeSearchToolManager) codeGetSymbolsOverviewHandler(ctx context.Con
 	}
 
 	if input.ProjectID == "" || input.RelativePath == "" {
-		return nil, fmt.Errorf("project_id and relative_path are required")
+		return nil, validationErrorf("project_id and relative_path are required")
 	}
 
 	if input.MaxResults <= 0 {
@@ -46,7 +46,7 @@ func (cstm *CodeSearchToolManager) codeGetSymbolsOverviewHandler(ctx context.Con
 		return nil, fmt.Errorf("failed to get file: %w", err)
 	}
 	if file == nil {
-		return nil, fmt.Errorf("file not found: %s", input.RelativePath)
+		return nil, notFoundErrorf("file not found: %s", input.RelativePath)
 	}
 
 	// Get symbols
@@ -93,6 +93,9 @@ func (cstm *CodeSearchToolManager) codeGetSymbolsOverviewHandler(ctx context.Con
 	}, false), nil
 }
 
+// readSymbolHint tells clients how to read a symbol body that was truncated.
+const readSymbolHint = "use code_read_file with the symbol's start_line/end_line, or raise max_output_bytes"
+
 func (cstm *CodeSearchToolManager) codeFindSymbolHandler(ctx context.Context, req *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
 	var input CodeFindSymbolInput
 	if err := json.Unmarshal(req.RawArguments, &input); err != nil {
@@ -100,8 +103,15 @@ func (cstm *CodeSearchToolManager) codeFindSymbolHandler(ctx context.Context, re
 	}
 
 	if input.ProjectID == "" || input.NamePathPattern == "" {
-		return nil, fmt.Errorf("project_id and name_path_pattern are required")
+		return nil, validationErrorf("project_id and name_path_pattern are required")
+	}
+	limit, err := outputLimit(cstm.maxOutputBytes, input.MaxOutputBytes)
+	if err != nil {
+		return nil, err
 	}
+	budget := newOutputBudget(limit)
+	input.IncludeKinds = expandKindPresets(input.IncludeKinds)
+	input.ExcludeKinds = expandKindPresets(input.ExcludeKinds)
 
 	// Get storage with code capabilities
 	codeStorage, ok := cstm.storage.(interface {
@@ -112,57 +122,14 @@ func (cstm *CodeSearchToolManager) codeFindSymbolHandler(ctx context.Context, re
 		return nil, fmt.Errorf("storage does not support code operations")
 	}
 
-	// Build query based on pattern type
-	var query string
-	params := map[string]interface{}{
-		"project_id": input.ProjectID,
-	}
-
-	pattern := input.NamePathPattern
-
-	if strings.HasPrefix(pattern, "/") {
-		// Absolute match
-		query = `SELECT * FROM code_symbols WHERE project_id = $project_id AND name_path = $pattern`
-		params["pattern"] = pattern
-	} else if strings.Contains(pattern, "/") {
-		// Suffix match
-		query = `SELECT * FROM code_symbols WHERE project_id = $project_id AND name_path CONTAINS $pattern`
-		params["pattern"] = pattern
+	var results []map[string]interface{}
+	if input.Fuzzy || input.CaseInsensitive {
+		results, err = cstm.findRankedSymbols(ctx, input)
 	} else {
-		// Simple name match
-		if input.SubstringMatch {
-			query = `SELECT * FROM code_symbols WHERE project_id = $project_id AND name CONTAINS $pattern`
-		} else {
-			query = `SELECT * FROM code_symbols WHERE project_id = $project_id AND name = $pattern`
-		}
-		params["pattern"] = pattern
+		results, err = findSymbolsByPattern(ctx, codeStorage, input)
 	}
-
-	// Add file/dir filter
-	if input.RelativePath != "" {
-		if strings.HasSuffix(input.RelativePath, "/") {
-			query += ` AND file_path CONTAINS $path`
-		} else {
-			query += ` AND file_path = $path`
-		}
-		params["path"] = input.RelativePath
-	}
-
-	// Add kind filters
-	if len(input.IncludeKinds) > 0 {
-		query += ` AND symbol_type IN $include_kinds`
-		params["include_kinds"] = input.IncludeKinds
-	}
-	if len(input.ExcludeKinds) > 0 {
-		query += ` AND symbol_type NOT IN $exclude_kinds`
-		params["exclude_kinds"] = input.ExcludeKinds
-	}
-
-	query += ` ORDER BY file_path, start_line LIMIT 50;`
-
-	results, err := codeStorage.Query(ctx, query, params)
 	if err != nil {
-		return nil, fmt.Errorf("failed to search symbols: %w", err)
+		return nil, err
 	}
 
 	// Process results
@@ -177,16 +144,28 @@ func (cstm *CodeSearchToolManager) codeFindSymbolHandler(ctx context.Context, re
 			"start_line": r["start_line"],
 			"end_line":   r["end_line"],
 			"signature":  r["signature"],
+			"revision":   r["revision"],
+		}
+		if match, ok := r["match"]; ok {
+			sym["match"] = match
+			sym["score"] = r["score"]
 		}
 
 		if input.IncludeBody {
-			sym["source_code"] = r["source_code"]
+			if body, ok := r["source_code"].(string); ok {
+				if input.Summarize {
+					body = symbolSkeleton(body)
+				}
+				sym["source_code"] = budget.take(body, readSymbolHint)
+			} else {
+				sym["source_code"] = r["source_code"]
+			}
 		}
 
 		// Get children if depth > 0
 		if input.Depth > 0 {
 			if id, ok := r["id"].(string); ok {
-				children, _ := cstm.getSymbolChildren(ctx, codeStorage, input.ProjectID, id, input.Depth-1, input.IncludeBody)
+				children, _ := cstm.getSymbolChildren(ctx, codeStorage, input.ProjectID, id, input.Depth-1, input.IncludeBody, input.Summarize, budget)
 				if len(children) > 0 {
 					sym["children"] = children
 				}
@@ -201,6 +180,13 @@ func (cstm *CodeSearchToolManager) codeFindSymbolHandler(ctx context.Context, re
 		"symbols": symbols,
 		"count":   len(symbols),
 	}
+	if input.Summarize && input.IncludeBody {
+		result["summarized"] = true
+	}
+	if budget.truncated > 0 {
+		result["truncated_bodies"] = budget.truncated
+		result["hint"] = fmt.Sprintf("%d symbol bodies were truncated at max_output_bytes=%d; %s", budget.truncated, limit, readSymbolHint)
+	}
 
 	if len(symbols) == 0 {
 		suggestions := cstm.FindProjectAlternatives(ctx, input.ProjectID)
@@ -218,10 +204,86 @@ func (cstm *CodeSearchToolManager) codeFindSymbolHandler(ctx context.Context, re
 	}, false), nil
 }
 
+// namePathOverloads is the condition of the symbols whose name path is
+// $name_path or $name_path with an ordinal, such as "/Point/add#2": its
+// overloads, and the other symbols of its file sharing it.
+const namePathOverloads = `(name_path = $name_path OR (string::starts_with(name_path, $ordinal_prefix) AND string::is::numeric(string::replace(name_path, $ordinal_prefix, ""))))`
+
+// setNamePathOverloads sets the parameters of namePathOverloads.
+func setNamePathOverloads(params map[string]interface{}, namePath string) {
+	params["name_path"] = namePath
+	params["ordinal_prefix"] = namePath + treesitter.NamePathOrdinalSeparator
+}
+
+// findSymbolsByPattern returns the symbols matching the pattern of input
+// exactly, or as a substring with substring_matching, by file and line.
+func findSymbolsByPattern(ctx context.Context, codeStorage interface {
+	Query(ctx context.Context, query string, params map[string]interface{}) ([]map[string]interface{}, error)
+}, input CodeFindSymbolInput) ([]map[string]interface{}, error) {
+	// Build query based on pattern type
+	var query string
+	params := map[string]interface{}{
+		"project_id": input.ProjectID,
+	}
+
+	pattern := input.NamePathPattern
+
+	if strings.HasPrefix(pattern, "/") {
+		// Absolute match, with the overloads of the symbol
+		query = `SELECT * FROM code_symbols WHERE project_id = $project_id AND ` + namePathOverloads
+		setNamePathOverloads(params, pattern)
+	} else if strings.Contains(pattern, "/") {
+		// Suffix match
+		query = `SELECT * FROM code_symbols WHERE project_id = $project_id AND name_path CONTAINS $pattern`
+		params["pattern"] = pattern
+	} else if name, ordinal := treesitter.SplitNamePathOrdinal(pattern); ordinal > 0 {
+		// Name of one of the overloads, like add#2
+		query = `SELECT * FROM code_symbols WHERE project_id = $project_id AND name = $name AND string::ends_with(name_path, $pattern)`
+		params["name"] = name
+		params["pattern"] = pattern
+	} else {
+		// Simple name match
+		if input.SubstringMatch {
+			query = `SELECT * FROM code_symbols WHERE project_id = $project_id AND name CONTAINS $pattern`
+		} else {
+			query = `SELECT * FROM code_symbols WHERE project_id = $project_id AND name = $pattern`
+		}
+		params["pattern"] = pattern
+	}
+
+	// Add file/dir filter
+	if input.RelativePath != "" {
+		if strings.HasSuffix(input.RelativePath, "/") {
+			query += ` AND file_path CONTAINS $path`
+		} else {
+			query += ` AND file_path = $path`
+		}
+		params["path"] = input.RelativePath
+	}
+
+	// Add kind filters
+	if len(input.IncludeKinds) > 0 {
+		query += ` AND symbol_type IN $include_kinds`
+		params["include_kinds"] = input.IncludeKinds
+	}
+	if len(input.ExcludeKinds) > 0 {
+		query += ` AND symbol_type NOT IN $exclude_kinds`
+		params["exclude_kinds"] = input.ExcludeKinds
+	}
+
+	query += ` ORDER BY file_path, start_line LIMIT 50;`
+
+	results, err := codeStorage.Query(ctx, query, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search symbols: %w", err)
+	}
+	return results, nil
+}
+
 // getSymbolChildren recursively gets children of a symbol
 func (cstm *CodeSearchToolManager) getSymbolChildren(ctx context.Context, codeStorage interface {
 	FindChildSymbols(ctx context.Context, projectID, parentID string) ([]storage.CodeSymbol, error)
-}, projectID, parentID string, remainingDepth int, includeBody bool) ([]map[string]interface{}, error) {
+}, projectID, parentID string, remainingDepth int, includeBody, summarize bool, budget *outputBudget) ([]map[string]interface{}, error) {
 	children, err := codeStorage.FindChildSymbols(ctx, projectID, parentID)
 	if err != nil {
 		return nil, err
@@ -236,15 +298,20 @@ func (cstm *CodeSearchToolManager) getSymbolChildren(ctx context.Context, codeSt
 			"start_line": child.StartLine,
 			"end_line":   child.EndLine,
 			"signature":  child.Signature,
+			"revision":   child.Revision,
 		}
 
 		if includeBody && child.SourceCode != nil {
-			sym["source_code"] = *child.SourceCode
+			body := *child.SourceCode
+			if summarize {
+				body = symbolSkeleton(body)
+			}
+			sym["source_code"] = budget.take(body, readSymbolHint)
 		}
 
 		// Recurse if more depth
 		if remainingDepth > 0 {
-			grandchildren, _ := cstm.getSymbolChildren(ctx, codeStorage, projectID, child.ID, remainingDepth-1, includeBody)
+			grandchildren, _ := cstm.getSymbolChildren(ctx, codeStorage, projectID, child.ID, remainingDepth-1, includeBody, summarize, budget)
 			if len(grandchildren) > 0 {
 				sym["children"] = grandchildren
 			}
@@ -263,7 +330,7 @@ func (cstm *CodeSearchToolManager) codeSearchSymbolsSemanticHandler(ctx context.
 	}
 
 	if input.ProjectID == "" || input.Query == "" {
-		return nil, fmt.Errorf("project_id and query are required")
+		return nil, validationErrorf("project_id and query are required")
 	}
 
 	if input.Limit <= 0 {
@@ -278,10 +345,14 @@ func (cstm *CodeSearchToolManager) codeSearchSymbolsSemanticHandler(ctx context.
 		return nil, fmt.Errorf("storage does not support semantic search")
 	}
 
-	// Generate embedding for query
-	embedding, err := cstm.embedder.EmbedQuery(ctx, input.Query)
+	// Generate embedding for query, with the model of the project
+	ctx, queryEmbedder, err := cstm.queryEmbedder(ctx, input.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	embedding, err := queryEmbedder.EmbedQuery(ctx, input.Query)
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate query embedding: %w", err)
+		return nil, embedderErrorf("failed to generate query embedding: %w", err)
 	}
 
 	// Convert symbol types
@@ -296,9 +367,21 @@ func (cstm *CodeSearchToolManager) codeSearchSymbolsSemanticHandler(ctx context.
 		return nil, fmt.Errorf("failed to search: %w", err)
 	}
 
+	type scoredSymbol struct {
+		storage.CodeSymbolSearchResult
+		score float64
+	}
+	scored := make([]scoredSymbol, len(results))
+	for i, r := range results {
+		scored[i] = scoredSymbol{CodeSymbolSearchResult: r}
+	}
+	scored = applyScoring(cstm.scoring, scored, input.MinSimilarity,
+		func(s scoredSymbol) float64 { return s.Similarity },
+		func(s *scoredSymbol, score float64) { s.score = score })
+
 	// Format results
-	symbols := make([]map[string]interface{}, 0, len(results))
-	for _, r := range results {
+	symbols := make([]map[string]interface{}, 0, len(scored))
+	for _, r := range scored {
 		sym := map[string]interface{}{
 			"name":       r.Symbol.Name,
 			"type":       r.Symbol.SymbolType,
@@ -308,7 +391,9 @@ func (cstm *CodeSearchToolManager) codeSearchSymbolsSemanticHandler(ctx context.
 			"start_line": r.Symbol.StartLine,
 			"end_line":   r.Symbol.EndLine,
 			"signature":  r.Symbol.Signature,
+			"revision":   r.Symbol.Revision,
 			"similarity": fmt.Sprintf("%.4f", r.Similarity),
+			"score":      fmt.Sprintf("%.4f", r.score),
 		}
 		symbols = append(symbols, sym)
 	}
@@ -342,7 +427,7 @@ func (cstm *CodeSearchToolManager) codeSearchPatternHandler(ctx context.Context,
 	}
 
 	if input.ProjectID == "" || input.Pattern == "" {
-		return nil, fmt.Errorf("project_id and pattern are required")
+		return nil, validationErrorf("project_id and pattern are required")
 	}
 
 	if input.Limit <= 0 {
@@ -391,7 +476,7 @@ func (cstm *CodeSearchToolManager) codeSearchPatternHandler(ctx context.Context,
 		}
 		re, err = regexp.Compile(flags + input.Pattern)
 		if err != nil {
-			return nil, fmt.Errorf("invalid regex pattern: %w", err)
+			return nil, validationErrorf("invalid regex pattern: %w", err)
 		}
 	}
 
@@ -465,122 +550,6 @@ func (cstm *CodeSearchToolManager) codeSearchPatternHandler(ctx context.Context,
 	}, false), nil
 }
 
-func (cstm *CodeSearchToolManager) codeFindReferencesHandler(ctx context.Context, req *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
-	var input CodeFindReferencesInput
-	if err := json.Unmarshal(req.RawArguments, &input); err != nil {
-		return nil, fmt.Errorf("failed to parse input: %w", err)
-	}
-
-	if input.ProjectID == "" {
-		return nil, fmt.Errorf("project_id is required")
-	}
-
-	if input.SymbolID == "" && input.SymbolName == "" {
-		return nil, fmt.Errorf("either symbol_id or symbol_name is required")
-	}
-
-	if input.Limit <= 0 {
-		input.Limit = 50
-	}
-
-	// Get storage
-	codeStorage, ok := cstm.storage.(interface {
-		Query(ctx context.Context, query string, params map[string]interface{}) ([]map[string]interface{}, error)
-		GetCodeSymbol(ctx context.Context, projectID, namePath string) (*storage.CodeSymbol, error)
-	})
-	if !ok {
-		return nil, fmt.Errorf("storage does not support code operations")
-	}
-
-	// Get the target symbol name
-	targetName := input.SymbolName
-	if input.SymbolID != "" {
-		// Get symbol by ID to get its name
-		query := `SELECT * FROM $symbol_id;`
-		results, err := codeStorage.Query(ctx, query, map[string]interface{}{"symbol_id": input.SymbolID})
-		if err != nil || len(results) == 0 {
-			return nil, fmt.Errorf("symbol not found: %s", input.SymbolID)
-		}
-		if name, ok := results[0]["name"].(string); ok {
-			targetName = name
-		}
-	}
-
-	if targetName == "" {
-		return nil, fmt.Errorf("could not determine symbol name")
-	}
-
-	// Search for references in source code
-	query := `SELECT * FROM code_symbols WHERE project_id = $project_id AND source_code CONTAINS $name AND name != $name`
-	params := map[string]interface{}{
-		"project_id": input.ProjectID,
-		"name":       targetName,
-	}
-
-	if len(input.IncludeKinds) > 0 {
-		query += ` AND symbol_type IN $kinds`
-		params["kinds"] = input.IncludeKinds
-	}
-
-	query += fmt.Sprintf(` LIMIT %d;`, input.Limit)
-
-	results, err := codeStorage.Query(ctx, query, params)
-	if err != nil {
-		return nil, fmt.Errorf("failed to search references: %w", err)
-	}
-
-	// Format results
-	references := make([]map[string]interface{}, 0, len(results))
-	for _, r := range results {
-		ref := map[string]interface{}{
-			"name":       r["name"],
-			"type":       r["symbol_type"],
-			"name_path":  r["name_path"],
-			"file_path":  r["file_path"],
-			"language":   r["language"],
-			"start_line": r["start_line"],
-			"end_line":   r["end_line"],
-		}
-
-		// Try to find the exact line(s) with the reference
-		if sourceCode, ok := r["source_code"].(string); ok {
-			lines := strings.Split(sourceCode, "\n")
-			refLines := make([]int, 0)
-			for i, line := range lines {
-				if strings.Contains(line, targetName) {
-					refLines = append(refLines, i+1)
-				}
-			}
-			if len(refLines) > 0 {
-				ref["reference_lines"] = refLines
-			}
-		}
-
-		references = append(references, ref)
-	}
-
-	result := map[string]interface{}{
-		"target_symbol": targetName,
-		"references":    references,
-		"count":         len(references),
-	}
-
-	if len(references) == 0 {
-		suggestions := cstm.FindProjectAlternatives(ctx, input.ProjectID)
-		payload := CreateEmptyResultTOON(
-			fmt.Sprintf("No references found for symbol '%s' in project '%s'", targetName, input.ProjectID),
-			suggestions,
-		)
-		return protocol.NewCallToolResult([]protocol.Content{
-			&protocol.TextContent{Type: "text", Text: payload},
-		}, false), nil
-	}
-
-	return protocol.NewCallToolResult([]protocol.Content{
-		&protocol.TextContent{Type: "text", Text: MarshalYAML(result)},
-	}, false), nil
-}
-
 func (cstm *CodeSearchToolManager) codeHybridSearchHandler(ctx context.Context, req *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
 	var input CodeHybridSearchInput
 	if err := json.Unmarshal(req.RawArguments, &input); err != nil {
@@ -588,24 +557,36 @@ func (cstm *CodeSearchToolManager) codeHybridSearchHandler(ctx context.Context,
 	}
 
 	if input.ProjectID == "" || input.Query == "" {
-		return nil, fmt.Errorf("project_id and query are required")
+		return nil, validationErrorf("project_id and query are required")
 	}
 
 	limit := input.Limit
 	if limit <= 0 {
 		limit = 20
 	}
+	switch input.OrderBy {
+	case "":
+		input.OrderBy = hybridOrderScore
+	case hybridOrderScore, hybridOrderFile:
+	default:
+		return nil, validationErrorf("invalid order_by %q: use %s or %s", input.OrderBy, hybridOrderScore, hybridOrderFile)
+	}
 
-	// Generate query embedding
-	queryEmbedding, err := cstm.embedder.EmbedQuery(ctx, input.Query)
+	// Generate query embedding, with the model of the project
+	ctx, queryEmbedder, err := cstm.queryEmbedder(ctx, input.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	queryEmbedding, err := queryEmbedder.EmbedQuery(ctx, input.Query)
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate query embedding: %w", err)
+		return nil, embedderErrorf("failed to generate query embedding: %w", err)
 	}
 
 	// Get storage with code operations
 	codeStorage, ok := cstm.storage.(interface {
 		SearchSymbolsBySimilarity(ctx context.Context, projectID string, queryEmbedding []float32, symbolTypes []treesitter.SymbolType, limit int) ([]storage.CodeSymbolSearchResult, error)
 		SearchChunksBySimilarity(ctx context.Context, projectID string, queryEmbedding []float32, limit int) ([]storage.CodeChunkSearchResult, error)
+		FindSymbolsByFile(ctx context.Context, projectID, filePath string) ([]storage.CodeSymbol, error)
 	})
 	if !ok {
 		return nil, fmt.Errorf("storage does not support code search operations")
@@ -647,21 +628,7 @@ func (cstm *CodeSearchToolManager) codeHybridSearchHandler(ctx context.Context,
 	}
 
 	// Filter and format results
-	type hybridResult struct {
-		Source     string  `json:"source"` // "symbol" or "chunk"
-		Name       string  `json:"name"`
-		NamePath   string  `json:"name_path,omitempty"`
-		SymbolType string  `json:"symbol_type"`
-		FilePath   string  `json:"file_path"`
-		Language   string  `json:"language"`
-		StartLine  int     `json:"start_line"`
-		EndLine    int     `json:"end_line"`
-		Similarity float64 `json:"similarity"`
-		ChunkIndex *int    `json:"chunk_index,omitempty"`
-		Preview    string  `json:"preview,omitempty"`
-	}
-
-	var results []hybridResult
+	var results []codeHybridResult
 
 	// Process symbol results
 	for _, sr := range symbolResults {
@@ -697,7 +664,7 @@ func (cstm *CodeSearchToolManager) codeHybridSearchHandler(ctx context.Context,
 			preview = *sym.SourceCode
 		}
 
-		results = append(results, hybridResult{
+		results = append(results, codeHybridResult{
 			Source:     "symbol",
 			Name:       sym.Name,
 			NamePath:   sym.NamePath,
@@ -757,31 +724,43 @@ func (cstm *CodeSearchToolManager) codeHybridSearchHandler(ctx context.Context,
 			preview = preview[:200] + "..."
 		}
 
+		// Lines are set from the symbol of the chunk once results are merged
 		chunkIdx := chunk.ChunkIndex
-		results = append(results, hybridResult{
+		results = append(results, codeHybridResult{
 			Source:     "chunk",
 			Name:       chunk.SymbolName,
+			NamePath:   chunkNamePath(input.ProjectID, chunk),
 			SymbolType: chunk.SymbolType,
 			FilePath:   chunk.FilePath,
 			Language:   chunk.Language,
-			StartLine:  chunk.StartOffset, // Approximate
-			EndLine:    chunk.EndOffset,   // Approximate
 			Similarity: cr.Similarity,
 			ChunkIndex: &chunkIdx,
 			Preview:    preview,
+			Chunk: &codeChunkMatch{
+				Index:       chunk.ChunkIndex,
+				Count:       chunk.ChunkCount,
+				StartOffset: chunk.StartOffset,
+				EndOffset:   chunk.EndOffset,
+			},
 		})
 	}
 
-	// Sort by similarity (already sorted from DB, but chunks may need re-sorting)
-	// For simplicity, we'll just limit
-	if len(results) > limit {
-		results = results[:limit]
+	// Symbols and chunks are scored together, then merged by score
+	results = applyScoring(cstm.scoring, results, input.MinSimilarity,
+		func(r codeHybridResult) float64 { return r.Similarity },
+		func(r *codeHybridResult, score float64) { r.Score = score })
+	results, duplicates := mergeHybridResults(results, limit, !input.IncludeDuplicateChunks)
+	if err := resolveChunkResults(ctx, codeStorage, input.ProjectID, results); err != nil {
+		return nil, err
+	}
+	if input.OrderBy == hybridOrderFile {
+		sortHybridResultsByFile(results)
 	}
 
 	output := map[string]interface{}{
-		"query":   input.Query,
-		"results": results,
-		"count":   len(results),
+		"query":    input.Query,
+		"count":    len(results),
+		"order_by": input.OrderBy,
 		"filters": map[string]interface{}{
 			"languages":      input.Languages,
 			"symbol_types":   input.SymbolTypes,
@@ -789,6 +768,14 @@ func (cstm *CodeSearchToolManager) codeHybridSearchHandler(ctx context.Context,
 			"include_chunks": input.IncludeChunks,
 		},
 	}
+	if input.GroupByFile {
+		output["files"] = groupHybridResultsByFile(results)
+	} else {
+		output["results"] = results
+	}
+	if duplicates > 0 {
+		output["duplicates_dropped"] = duplicates
+	}
 
 	if len(results) == 0 {
 		suggestions := cstm.FindProjectAlternatives(ctx, input.ProjectID)
@@ -0,0 +1,187 @@
+// Package mcp_tools provides code search MCP tools.
+// This file contains the merging and grouping of code_hybrid_search results,
+// and the location of matched chunks in their symbols.
+package mcp_tools
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/madeindigio/remembrances-mcp/internal/storage"
+)
+
+// Orders of code_hybrid_search results.
+const (
+	hybridOrderScore = "score"
+	hybridOrderFile  = "file"
+)
+
+// codeHybridResult is a symbol or chunk found by code_hybrid_search.
+type codeHybridResult struct {
+	Source     string  `json:"source"` // "symbol" or "chunk"
+	Name       string  `json:"name"`
+	NamePath   string  `json:"name_path,omitempty"`
+	SymbolType string  `json:"symbol_type"`
+	FilePath   string  `json:"file_path"`
+	Language   string  `json:"language"`
+	StartLine  int     `json:"start_line"`
+	EndLine    int     `json:"end_line"`
+	Similarity float64 `json:"similarity"`
+	Score      float64 `json:"score"`
+	ChunkIndex *int    `json:"chunk_index,omitempty"`
+	Preview    string  `json:"preview,omitempty"`
+	// Chunk is the part of the symbol a chunk result matched
+	Chunk *codeChunkMatch `json:"chunk,omitempty"`
+	// ReadSymbol is the code_read_file arguments that read the whole symbol
+	ReadSymbol *codeReadSymbolHandle `json:"read_symbol,omitempty"`
+}
+
+// codeChunkMatch locates a matched chunk in the body of its symbol: by byte
+// offsets into the symbol source code and by line in the file.
+type codeChunkMatch struct {
+	Index       int `json:"index"`
+	Count       int `json:"count"`
+	StartOffset int `json:"start_offset"`
+	EndOffset   int `json:"end_offset"`
+	StartLine   int `json:"start_line,omitempty"`
+	EndLine     int `json:"end_line,omitempty"`
+}
+
+// codeReadSymbolHandle is a follow-up code_read_file call.
+type codeReadSymbolHandle struct {
+	Tool         string `json:"tool"`
+	RelativePath string `json:"relative_path"`
+	NamePath     string `json:"name_path"`
+}
+
+// codeHybridFile is the results of code_hybrid_search in one file, with
+// group_by_file.
+type codeHybridFile struct {
+	FilePath  string             `json:"file_path"`
+	Language  string             `json:"language"`
+	BestScore float64            `json:"best_score"`
+	Results   []codeHybridResult `json:"results"`
+}
+
+// chunkNamePath returns the name path of the symbol of a chunk, whose symbol
+// ID is "<project>:<file>:<name path>", or "" when it has another form.
+func chunkNamePath(projectID string, chunk *storage.CodeChunk) string {
+	namePath, ok := strings.CutPrefix(chunk.SymbolID, projectID+":"+chunk.FilePath+":")
+	if !ok {
+		return ""
+	}
+	return namePath
+}
+
+// mergeHybridResults sorts symbol and chunk results together by score, best
+// first, and returns up to limit of them. With dedupe, a symbol is returned
+// once, as its best-scoring match: its chunks are dropped when the whole
+// symbol or another of its chunks scored higher. It also returns how many
+// results were dropped as duplicates.
+func mergeHybridResults(results []codeHybridResult, limit int, dedupe bool) ([]codeHybridResult, int) {
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+
+	merged := make([]codeHybridResult, 0, min(len(results), limit))
+	seen := map[string]bool{}
+	duplicates := 0
+	for _, r := range results {
+		if len(merged) == limit {
+			break
+		}
+		if dedupe && r.NamePath != "" {
+			key := r.FilePath + "\x00" + r.NamePath
+			if seen[key] {
+				duplicates++
+				continue
+			}
+			seen[key] = true
+		}
+		merged = append(merged, r)
+	}
+	return merged, duplicates
+}
+
+// sortHybridResultsByFile orders results by file path, then by line.
+func sortHybridResultsByFile(results []codeHybridResult) {
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].FilePath != results[j].FilePath {
+			return results[i].FilePath < results[j].FilePath
+		}
+		return results[i].StartLine < results[j].StartLine
+	})
+}
+
+// groupHybridResultsByFile groups results by file, keeping their order
+// within each file. Files come in the order of their first result.
+func groupHybridResultsByFile(results []codeHybridResult) []codeHybridFile {
+	var files []codeHybridFile
+	index := map[string]int{}
+	for _, r := range results {
+		i, ok := index[r.FilePath]
+		if !ok {
+			i = len(files)
+			index[r.FilePath] = i
+			files = append(files, codeHybridFile{FilePath: r.FilePath, Language: r.Language, BestScore: r.Score})
+		}
+		files[i].BestScore = max(files[i].BestScore, r.Score)
+		files[i].Results = append(files[i].Results, r)
+	}
+	return files
+}
+
+// resolveChunkResults makes chunk results describe their symbol: the lines
+// of the whole symbol, the lines of the chunk within it, and a handle to read
+// the symbol. Chunks whose symbol is no longer indexed keep only their
+// offsets.
+func resolveChunkResults(ctx context.Context, codeStorage interface {
+	FindSymbolsByFile(ctx context.Context, projectID, filePath string) ([]storage.CodeSymbol, error)
+}, projectID string, results []codeHybridResult) error {
+	files := map[string]map[string]storage.CodeSymbol{}
+	for i := range results {
+		r := &results[i]
+		if r.Chunk == nil || r.NamePath == "" {
+			continue
+		}
+		symbols, ok := files[r.FilePath]
+		if !ok {
+			found, err := codeStorage.FindSymbolsByFile(ctx, projectID, r.FilePath)
+			if err != nil {
+				return fmt.Errorf("failed to get symbols of %s: %w", r.FilePath, err)
+			}
+			symbols = make(map[string]storage.CodeSymbol, len(found))
+			for _, sym := range found {
+				symbols[sym.NamePath] = sym
+			}
+			files[r.FilePath] = symbols
+		}
+		sym, ok := symbols[r.NamePath]
+		if !ok {
+			continue
+		}
+
+		r.StartLine, r.EndLine = sym.StartLine, sym.EndLine
+		if sym.SourceCode != nil {
+			r.Chunk.StartLine, r.Chunk.EndLine = chunkLines(*sym.SourceCode, sym.StartLine, r.Chunk.StartOffset, r.Chunk.EndOffset)
+		}
+		r.ReadSymbol = &codeReadSymbolHandle{Tool: "code_read_file", RelativePath: r.FilePath, NamePath: r.NamePath}
+	}
+	return nil
+}
+
+// chunkLines returns the file lines of the bytes start to end of the source
+// code of a symbol starting at startLine.
+func chunkLines(source string, startLine, start, end int) (int, int) {
+	start = min(max(start, 0), len(source))
+	end = min(max(end, start), len(source))
+	first := startLine + strings.Count(source[:start], "\n")
+	last := startLine + strings.Count(source[:end], "\n")
+	// A chunk ending with a newline ends on the line before
+	if end > start && source[end-1] == '\n' {
+		last--
+	}
+	return first, max(first, last)
+}
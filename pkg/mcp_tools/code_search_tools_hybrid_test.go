@@ -0,0 +1,77 @@
+package mcp_tools
+
+import (
+	"testing"
+
+	"github.com/madeindigio/remembrances-mcp/internal/storage"
+)
+
+func TestChunkNamePath(t *testing.T) {
+	chunk := &storage.CodeChunk{SymbolID: "app:src/user.go:/UserService/Create", FilePath: "src/user.go"}
+	if got := chunkNamePath("app", chunk); got != "/UserService/Create" {
+		t.Errorf("chunkNamePath = %q, want /UserService/Create", got)
+	}
+	if got := chunkNamePath("other", chunk); got != "" {
+		t.Errorf("chunkNamePath of another project = %q, want empty", got)
+	}
+}
+
+func TestMergeHybridResults(t *testing.T) {
+	idx := 1
+	results := []codeHybridResult{
+		{Source: "symbol", Name: "Create", NamePath: "/UserService/Create", FilePath: "src/user.go", Score: 0.7},
+		{Source: "symbol", Name: "Login", NamePath: "/Login", FilePath: "src/auth.go", Score: 0.6},
+		{Source: "chunk", Name: "Create", NamePath: "/UserService/Create", FilePath: "src/user.go", Score: 0.9, ChunkIndex: &idx},
+		{Source: "chunk", Name: "Create", NamePath: "/UserService/Create", FilePath: "src/user.go", Score: 0.8, ChunkIndex: &idx},
+		{Source: "chunk", Name: "legacy", FilePath: "src/old.go", Score: 0.65},
+	}
+
+	merged, duplicates := mergeHybridResults(append([]codeHybridResult(nil), results...), 10, true)
+	if duplicates != 2 {
+		t.Errorf("expected 2 duplicates dropped, got %d", duplicates)
+	}
+	wantOrder := []string{"chunk:/UserService/Create", "chunk:", "symbol:/Login"}
+	if len(merged) != len(wantOrder) {
+		t.Fatalf("expected %d results, got %+v", len(wantOrder), merged)
+	}
+	for i, want := range wantOrder {
+		if got := merged[i].Source + ":" + merged[i].NamePath; got != want {
+			t.Errorf("result %d = %s, want %s", i, got, want)
+		}
+	}
+
+	merged, duplicates = mergeHybridResults(append([]codeHybridResult(nil), results...), 3, false)
+	if duplicates != 0 || len(merged) != 3 || merged[0].Score != 0.9 || merged[2].Score != 0.7 {
+		t.Errorf("expected the 3 best results without dedupe, got %+v", merged)
+	}
+}
+
+func TestGroupHybridResultsByFile(t *testing.T) {
+	files := groupHybridResultsByFile([]codeHybridResult{
+		{FilePath: "b.go", Score: 0.9},
+		{FilePath: "a.go", Score: 0.8},
+		{FilePath: "b.go", Score: 0.5},
+	})
+	if len(files) != 2 || files[0].FilePath != "b.go" || len(files[0].Results) != 2 || files[0].BestScore != 0.9 {
+		t.Errorf("unexpected groups %+v", files)
+	}
+}
+
+func TestChunkLines(t *testing.T) {
+	source := "func Big() {\n\ta()\n\tb()\n\tc()\n}"
+	for _, tc := range []struct {
+		start, end          int
+		wantFirst, wantLast int
+	}{
+		{0, len(source), 10, 14},
+		{13, 18, 11, 11}, // "\ta()\n"
+		{18, 23, 12, 12}, // "\tb()\n" ends with its newline
+		{13, 28, 11, 13},
+		{-5, 1000, 10, 14},
+	} {
+		first, last := chunkLines(source, 10, tc.start, tc.end)
+		if first != tc.wantFirst || last != tc.wantLast {
+			t.Errorf("chunkLines(%d, %d) = %d-%d, want %d-%d", tc.start, tc.end, first, last, tc.wantFirst, tc.wantLast)
+		}
+	}
+}
@@ -0,0 +1,204 @@
+// Package mcp_tools provides code search MCP tools.
+// This file contains the handler implementation for code_read_file.
+package mcp_tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ThinkInAIXYZ/go-mcp/protocol"
+	"github.com/madeindigio/remembrances-mcp/internal/storage"
+)
+
+const (
+	// defaultReadContextLines is the number of lines shown around a symbol
+	defaultReadContextLines = 3
+
+	// defaultReadMaxLines bounds the lines returned by code_read_file
+	defaultReadMaxLines = 400
+)
+
+// projectFilePath returns the absolute path of a project file, rejecting
+// paths that leave the project root
+func projectFilePath(rootPath, relativePath string) (string, error) {
+	relPath := filepath.Clean(filepath.FromSlash(relativePath))
+	if filepath.IsAbs(relPath) || relPath == ".." || strings.HasPrefix(relPath, ".."+string(filepath.Separator)) {
+		return "", validationErrorf("path is outside the project: %s", relativePath)
+	}
+	return filepath.Join(rootPath, relPath), nil
+}
+
+// readProjectFile reads a project file from disk, falling back to the copy
+// stored at indexing time when the checkout cannot be read. stored reports
+// whether the stored copy was returned.
+func readProjectFile(ctx context.Context, store interface{}, project *storage.CodeProject, relativePath string) (content []byte, stored bool, err error) {
+	absPath, err := projectFilePath(project.RootPath, relativePath)
+	if err != nil {
+		return nil, false, err
+	}
+	content, err = os.ReadFile(absPath)
+	if err == nil {
+		return content, false, nil
+	}
+
+	contentStorage, ok := store.(interface {
+		GetCodeFileContent(ctx context.Context, projectID, filePath string) ([]byte, error)
+	})
+	if !ok {
+		return nil, false, err
+	}
+	saved, storeErr := contentStorage.GetCodeFileContent(ctx, project.ProjectID, filepath.Clean(filepath.FromSlash(relativePath)))
+	if storeErr != nil || saved == nil {
+		return nil, false, err
+	}
+	return saved, true, nil
+}
+
+// symbolLocation summarizes a symbol for navigation output
+func symbolLocation(sym storage.CodeSymbol) map[string]interface{} {
+	return map[string]interface{}{
+		"name_path":  sym.NamePath,
+		"type":       string(sym.SymbolType),
+		"start_line": sym.StartLine,
+		"end_line":   sym.EndLine,
+	}
+}
+
+func (cstm *CodeSearchToolManager) codeReadFileHandler(ctx context.Context, req *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+	var input CodeReadFileInput
+	if err := json.Unmarshal(req.RawArguments, &input); err != nil {
+		return nil, fmt.Errorf("failed to parse input: %w", err)
+	}
+
+	if input.ProjectID == "" || input.RelativePath == "" {
+		return nil, validationErrorf("project_id and relative_path are required")
+	}
+
+	if input.ContextLines < 0 {
+		input.ContextLines = 0
+	} else if input.ContextLines == 0 && input.NamePath != "" {
+		input.ContextLines = defaultReadContextLines
+	}
+	if input.MaxLines <= 0 {
+		input.MaxLines = defaultReadMaxLines
+	}
+
+	codeStorage, ok := cstm.storage.(interface {
+		GetCodeProject(ctx context.Context, projectID string) (*storage.CodeProject, error)
+		FindSymbolsByFile(ctx context.Context, projectID, filePath string) ([]storage.CodeSymbol, error)
+	})
+	if !ok {
+		return nil, fmt.Errorf("storage does not support code operations")
+	}
+
+	project, err := codeStorage.GetCodeProject(ctx, input.ProjectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get project: %w", err)
+	}
+	if project == nil {
+		return nil, notFoundErrorf("project not found: %s", input.ProjectID)
+	}
+
+	content, stored, err := readProjectFile(ctx, cstm.storage, project, input.RelativePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	lines := strings.Split(strings.TrimSuffix(string(content), "\n"), "\n")
+
+	relPath := filepath.Clean(filepath.FromSlash(input.RelativePath))
+	symbols, err := codeStorage.FindSymbolsByFile(ctx, input.ProjectID, relPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get symbols: %w", err)
+	}
+
+	// Determine the requested range, from a symbol or from line numbers
+	start, end := input.StartLine, input.EndLine
+	if input.NamePath != "" {
+		var target *storage.CodeSymbol
+		for i := range symbols {
+			if symbols[i].NamePath == input.NamePath || strings.TrimPrefix(symbols[i].NamePath, "/") == strings.TrimPrefix(input.NamePath, "/") {
+				target = &symbols[i]
+				break
+			}
+		}
+		if target == nil {
+			return nil, notFoundErrorf("symbol %s not found in %s", input.NamePath, input.RelativePath)
+		}
+		start, end = target.StartLine, target.EndLine
+	}
+	if start <= 0 {
+		start = 1
+	}
+	if end <= 0 {
+		end = start + input.MaxLines - 1
+	}
+	if end < start {
+		return nil, validationErrorf("end_line %d is before start_line %d", end, start)
+	}
+
+	start = max(start-input.ContextLines, 1)
+	end = min(end+input.ContextLines, len(lines))
+	if start > len(lines) {
+		return nil, validationErrorf("start_line %d is past the end of the file (%d lines)", start, len(lines))
+	}
+
+	truncated := false
+	if end-start+1 > input.MaxLines {
+		end = start + input.MaxLines - 1
+		truncated = true
+	}
+
+	// Number the lines so edits can refer to them
+	width := len(fmt.Sprint(end))
+	var b strings.Builder
+	for n := start; n <= end; n++ {
+		fmt.Fprintf(&b, "%*d| %s\n", width, n, lines[n-1])
+	}
+
+	// Breadcrumbs are the symbols enclosing the whole range, outermost first;
+	// symbols lists those starting inside it
+	breadcrumbs := make([]map[string]interface{}, 0)
+	inRange := make([]map[string]interface{}, 0)
+	var enclosing []storage.CodeSymbol
+	for _, sym := range symbols {
+		switch {
+		case sym.StartLine <= start && sym.EndLine >= end:
+			enclosing = append(enclosing, sym)
+		case sym.StartLine >= start && sym.StartLine <= end:
+			inRange = append(inRange, symbolLocation(sym))
+		}
+	}
+	// Wider symbols enclose narrower ones
+	sort.SliceStable(enclosing, func(i, j int) bool {
+		return enclosing[i].EndLine-enclosing[i].StartLine > enclosing[j].EndLine-enclosing[j].StartLine
+	})
+	for _, sym := range enclosing {
+		breadcrumbs = append(breadcrumbs, symbolLocation(sym))
+	}
+
+	result := map[string]interface{}{
+		"file_path":   relPath,
+		"total_lines": len(lines),
+		"start_line":  start,
+		"end_line":    end,
+		"content":     b.String(),
+		"breadcrumbs": breadcrumbs,
+		"symbols":     inRange,
+	}
+	if truncated {
+		result["truncated"] = true
+		result["next_start_line"] = end + 1
+	}
+	if stored {
+		result["stored_copy"] = true
+	}
+
+	return protocol.NewCallToolResult([]protocol.Content{
+		&protocol.TextContent{Type: "text", Text: MarshalYAML(result)},
+	}, false), nil
+}
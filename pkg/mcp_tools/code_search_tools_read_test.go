@@ -0,0 +1,107 @@
+package mcp_tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ThinkInAIXYZ/go-mcp/protocol"
+	"github.com/madeindigio/remembrances-mcp/internal/storage"
+)
+
+func TestProjectFilePath(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "app")
+	tests := []struct {
+		relativePath string
+		want         string // "" when the path is rejected
+	}{
+		{"main.go", filepath.Join(root, "main.go")},
+		{"pkg/../main.go", filepath.Join(root, "main.go")},
+		{"./pkg/server.go", filepath.Join(root, "pkg", "server.go")},
+		{"..notes.md", filepath.Join(root, "..notes.md")},
+		{"..", ""},
+		{"../secret.txt", ""},
+		{"pkg/../../secret.txt", ""},
+		{"/etc/passwd", ""},
+	}
+	for _, tt := range tests {
+		got, err := projectFilePath(root, tt.relativePath)
+		if tt.want == "" {
+			var toolErr *ToolError
+			if !errors.As(err, &toolErr) || toolErr.Code != ErrCodeValidation {
+				t.Errorf("projectFilePath(%q) = %q, %v; want a validation error", tt.relativePath, got, err)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("projectFilePath(%q) = %q, %v; want %q", tt.relativePath, got, err, tt.want)
+		}
+	}
+}
+
+// readFileStorage is a code project rooted at root with one symbol in main.go.
+type readFileStorage struct {
+	storage.Storage
+	root string
+}
+
+func (s *readFileStorage) GetCodeProject(ctx context.Context, projectID string) (*storage.CodeProject, error) {
+	return &storage.CodeProject{ProjectID: projectID, RootPath: s.root}, nil
+}
+
+func (s *readFileStorage) FindSymbolsByFile(ctx context.Context, projectID, filePath string) ([]storage.CodeSymbol, error) {
+	if filePath != "main.go" {
+		return nil, nil
+	}
+	return []storage.CodeSymbol{{NamePath: "/run", SymbolType: "function", StartLine: 5, EndLine: 7}}, nil
+}
+
+func TestCodeReadFile(t *testing.T) {
+	dir := t.TempDir()
+	root := filepath.Join(dir, "app")
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	source := "package main\n\nimport \"fmt\"\n\nfunc run() {\n\tfmt.Println(\"run\")\n}\n\nfunc main() { run() }\n"
+	if err := os.WriteFile(filepath.Join(root, "main.go"), []byte(source), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "secret.txt"), []byte("password"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cstm := NewCodeSearchToolManager(&readFileStorage{root: root}, nil)
+	read := func(args map[string]interface{}) (string, error) {
+		args["project_id"] = "app"
+		raw, _ := json.Marshal(args)
+		result, err := cstm.codeReadFileHandler(context.Background(), &protocol.CallToolRequest{RawArguments: raw})
+		if err != nil {
+			return "", err
+		}
+		return result.Content[0].(*protocol.TextContent).Text, nil
+	}
+
+	// A symbol with one line of context
+	text, err := read(map[string]interface{}{"relative_path": "main.go", "name_path": "run", "context_lines": 1})
+	if err != nil {
+		t.Fatalf("read run: %v", err)
+	}
+	for _, want := range []string{"start_line: 4", "end_line: 8", `5| func run() {`, "/run"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("read run = %q, want %q", text, want)
+		}
+	}
+	if strings.Contains(text, "package main") {
+		t.Errorf("read run = %q, want only the lines around run", text)
+	}
+
+	// Paths that leave the project are refused before anything is read
+	_, err = read(map[string]interface{}{"relative_path": "../secret.txt"})
+	var toolErr *ToolError
+	if !errors.As(err, &toolErr) || toolErr.Code != ErrCodeValidation {
+		t.Errorf("read ../secret.txt = %v, want a validation error", err)
+	}
+}
@@ -0,0 +1,368 @@
+// Package mcp_tools provides code search MCP tools.
+// This file contains the handler implementation for code_find_references.
+package mcp_tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/ThinkInAIXYZ/go-mcp/protocol"
+	"github.com/madeindigio/remembrances-mcp/internal/storage"
+	"github.com/madeindigio/remembrances-mcp/pkg/treesitter"
+)
+
+// Reference search scopes
+const (
+	referenceScopeFile    = "file"
+	referenceScopePackage = "package"
+	referenceScopeProject = "project"
+)
+
+// maxReferenceDefinitions bounds the definitions resolved for a symbol name
+const maxReferenceDefinitions = 20
+
+// languageFamily groups languages whose files can reference each other's symbols
+func languageFamily(lang treesitter.Language) string {
+	switch lang {
+	case treesitter.LanguageJavaScript, treesitter.LanguageTypeScript, treesitter.LanguageTSX,
+		treesitter.LanguageSvelte, treesitter.LanguageVue:
+		return "javascript"
+	case treesitter.LanguageC, treesitter.LanguageCPP, treesitter.LanguageObjectiveC:
+		return "c"
+	}
+	return string(lang)
+}
+
+// innermostSymbol returns the smallest stored symbol containing the byte offset
+func innermostSymbol(symbols []storage.CodeSymbol, offset int) *storage.CodeSymbol {
+	var innermost *storage.CodeSymbol
+	for i := range symbols {
+		sym := &symbols[i]
+		if sym.StartByte > offset || sym.EndByte <= offset {
+			continue
+		}
+		if innermost == nil || sym.EndByte-sym.StartByte < innermost.EndByte-innermost.StartByte {
+			innermost = sym
+		}
+	}
+	return innermost
+}
+
+// isCallableStoredSymbol reports whether a stored symbol has a body with its own local scope
+func isCallableStoredSymbol(sym *storage.CodeSymbol) bool {
+	switch sym.SymbolType {
+	case treesitter.SymbolTypeFunction, treesitter.SymbolTypeMethod, treesitter.SymbolTypeConstructor,
+		treesitter.SymbolTypeComponent, treesitter.SymbolTypeHook:
+		return true
+	}
+	return false
+}
+
+// byteRange is a half-open range of source bytes
+type byteRange struct {
+	start, end int
+}
+
+func (cstm *CodeSearchToolManager) codeFindReferencesHandler(ctx context.Context, req *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+	var input CodeFindReferencesInput
+	if err := json.Unmarshal(req.RawArguments, &input); err != nil {
+		return nil, fmt.Errorf("failed to parse input: %w", err)
+	}
+
+	if input.ProjectID == "" {
+		return nil, validationErrorf("project_id is required")
+	}
+
+	if input.SymbolID == "" && input.SymbolName == "" {
+		return nil, validationErrorf("either symbol_id or symbol_name is required")
+	}
+
+	if input.Limit <= 0 {
+		input.Limit = 50
+	}
+
+	switch input.Scope {
+	case "":
+		input.Scope = referenceScopeProject
+	case referenceScopeFile, referenceScopePackage, referenceScopeProject:
+	default:
+		return nil, validationErrorf("invalid scope %q: must be file, package or project", input.Scope)
+	}
+
+	codeStorage, ok := cstm.storage.(interface {
+		Query(ctx context.Context, query string, params map[string]interface{}) ([]map[string]interface{}, error)
+		GetCodeProject(ctx context.Context, projectID string) (*storage.CodeProject, error)
+		ListCodeFiles(ctx context.Context, projectID string) ([]storage.CodeFile, error)
+		FindSymbolsByName(ctx context.Context, projectID, name string, symbolTypes []treesitter.SymbolType, limit int) ([]storage.CodeSymbol, error)
+		FindSymbolsByFile(ctx context.Context, projectID, filePath string) ([]storage.CodeSymbol, error)
+		GetCodeDependents(ctx context.Context, projectID string, targets []string) ([]storage.CodeDependency, error)
+	})
+	if !ok {
+		return nil, fmt.Errorf("storage does not support code operations")
+	}
+
+	project, err := codeStorage.GetCodeProject(ctx, input.ProjectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get project: %w", err)
+	}
+	if project == nil {
+		return nil, notFoundErrorf("project not found: %s", input.ProjectID)
+	}
+
+	// Resolve the definitions of the target symbol
+	targetName := input.SymbolName
+	var definitions []storage.CodeSymbol
+	if input.SymbolID != "" {
+		results, err := codeStorage.Query(ctx, `SELECT * FROM $symbol_id;`, map[string]interface{}{"symbol_id": input.SymbolID})
+		if err != nil || len(results) == 0 {
+			return nil, notFoundErrorf("symbol not found: %s", input.SymbolID)
+		}
+		targetName, _ = results[0]["name"].(string)
+		filePath, _ := results[0]["file_path"].(string)
+		namePath, _ := results[0]["name_path"].(string)
+		symbols, err := codeStorage.FindSymbolsByFile(ctx, input.ProjectID, filePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get symbols for %s: %w", filePath, err)
+		}
+		for _, sym := range symbols {
+			if sym.NamePath == namePath {
+				definitions = append(definitions, sym)
+			}
+		}
+	} else {
+		// FindSymbolsByName matches substrings, so keep exact names only
+		symbols, err := codeStorage.FindSymbolsByName(ctx, input.ProjectID, targetName, nil, maxReferenceDefinitions*4)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve symbol %s: %w", targetName, err)
+		}
+		for _, sym := range symbols {
+			if sym.Name != targetName || (input.RelativePath != "" && sym.FilePath != input.RelativePath) {
+				continue
+			}
+			if len(definitions) < maxReferenceDefinitions {
+				definitions = append(definitions, sym)
+			}
+		}
+	}
+
+	if targetName == "" {
+		return nil, fmt.Errorf("could not determine symbol name")
+	}
+
+	// The anchor file is where the symbol is defined; file and package scopes
+	// are relative to it
+	anchor := input.RelativePath
+	if anchor == "" && len(definitions) > 0 {
+		anchor = definitions[0].FilePath
+		for _, def := range definitions[1:] {
+			if def.FilePath != anchor {
+				anchor = ""
+				break
+			}
+		}
+	}
+	if anchor == "" && input.Scope != referenceScopeProject {
+		return nil, fmt.Errorf("scope %q requires a single definition file: pass relative_path or symbol_id", input.Scope)
+	}
+
+	definitionFiles := make(map[string]bool)
+	families := make(map[string]bool)
+	for _, def := range definitions {
+		definitionFiles[def.FilePath] = true
+		families[languageFamily(def.Language)] = true
+	}
+
+	// Files importing the definition's file or package
+	importers := make(map[string]bool)
+	if anchor != "" {
+		dependents, err := codeStorage.GetCodeDependents(ctx, input.ProjectID, []string{anchor, filepath.Dir(anchor)})
+		if err != nil {
+			slog.Warn("failed to get dependents for reference resolution", "file", anchor, "err", err)
+		}
+		for _, dep := range dependents {
+			importers[dep.FilePath] = true
+		}
+	}
+
+	files, err := codeStorage.ListCodeFiles(ctx, input.ProjectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files: %w", err)
+	}
+
+	parser := treesitter.NewParser()
+	nameBytes := []byte(targetName)
+	references := make([]map[string]interface{}, 0)
+	truncated := false
+	filesSearched := 0
+
+	for _, file := range files {
+		if len(references) >= input.Limit {
+			truncated = true
+			break
+		}
+
+		switch input.Scope {
+		case referenceScopeFile:
+			if file.FilePath != anchor {
+				continue
+			}
+		case referenceScopePackage:
+			if filepath.Dir(file.FilePath) != filepath.Dir(anchor) {
+				continue
+			}
+		}
+		if len(families) > 0 && !families[languageFamily(file.Language)] {
+			continue
+		}
+
+		via := "name"
+		switch {
+		case definitionFiles[file.FilePath] || file.FilePath == anchor:
+			via = "same_file"
+		case anchor != "" && filepath.Dir(file.FilePath) == filepath.Dir(anchor):
+			via = "same_package"
+		case importers[file.FilePath]:
+			via = "import"
+		}
+		if input.RequireImport && via == "name" {
+			continue
+		}
+
+		source, _, err := readProjectFile(ctx, cstm.storage, project, file.FilePath)
+		if err != nil || !bytes.Contains(source, nameBytes) {
+			continue
+		}
+		tree, err := parser.Parse(ctx, source, file.Language)
+		if err != nil {
+			continue
+		}
+		filesSearched++
+
+		refs := treesitter.FindIdentifierReferences(tree, source, targetName)
+		if len(refs) == 0 {
+			continue
+		}
+
+		symbols, err := codeStorage.FindSymbolsByFile(ctx, input.ProjectID, file.FilePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get symbols for %s: %w", file.FilePath, err)
+		}
+
+		// Local declarations shadow the target for the rest of their function;
+		// other declarations outside functions make the name refer to a
+		// different symbol throughout a file that does not define the target
+		var shadows []byteRange
+		redeclared := false
+		for _, ref := range refs {
+			if ref.Kind != treesitter.ReferenceKindDeclaration {
+				continue
+			}
+			enclosing := innermostSymbol(symbols, ref.StartByte)
+			switch {
+			case enclosing != nil && enclosing.Name == targetName && enclosing.StartLine == ref.Line:
+				// The declaration names a symbol: a top-level one outside the
+				// definition files is a different symbol with the same name
+				if !definitionFiles[file.FilePath] && enclosing.ParentID == nil {
+					redeclared = true
+				}
+			case enclosing != nil && isCallableStoredSymbol(enclosing):
+				shadows = append(shadows, byteRange{start: ref.StartByte, end: enclosing.EndByte})
+			case !definitionFiles[file.FilePath]:
+				redeclared = true
+			}
+		}
+
+		lines := strings.Split(string(source), "\n")
+		for _, ref := range refs {
+			if ref.Kind == treesitter.ReferenceKindDeclaration {
+				continue
+			}
+			if !ref.Qualified {
+				if redeclared {
+					continue
+				}
+				shadowed := false
+				for _, shadow := range shadows {
+					if ref.StartByte >= shadow.start && ref.StartByte < shadow.end {
+						shadowed = true
+						break
+					}
+				}
+				if shadowed {
+					continue
+				}
+			}
+
+			enclosing := innermostSymbol(symbols, ref.StartByte)
+			if len(input.IncludeKinds) > 0 {
+				if enclosing == nil || !slices.Contains(input.IncludeKinds, string(enclosing.SymbolType)) {
+					continue
+				}
+			}
+			if len(references) >= input.Limit {
+				truncated = true
+				break
+			}
+
+			reference := map[string]interface{}{
+				"file_path": file.FilePath,
+				"line":      ref.Line,
+				"column":    ref.Column,
+				"kind":      string(ref.Kind),
+				"via":       via,
+				"symbol":    "<file>",
+			}
+			if enclosing != nil {
+				reference["symbol"] = enclosing.NamePath
+				reference["symbol_type"] = string(enclosing.SymbolType)
+			}
+			if ref.Line-1 < len(lines) {
+				reference["text"] = strings.TrimSpace(lines[ref.Line-1])
+			}
+			references = append(references, reference)
+		}
+	}
+
+	if len(references) == 0 {
+		suggestions := cstm.FindProjectAlternatives(ctx, input.ProjectID)
+		payload := CreateEmptyResultTOON(
+			fmt.Sprintf("No references found for symbol '%s' in project '%s' (scope: %s)", targetName, input.ProjectID, input.Scope),
+			suggestions,
+		)
+		return protocol.NewCallToolResult([]protocol.Content{
+			&protocol.TextContent{Type: "text", Text: payload},
+		}, false), nil
+	}
+
+	defs := make([]map[string]interface{}, 0, len(definitions))
+	for _, def := range definitions {
+		defs = append(defs, map[string]interface{}{
+			"name_path":  def.NamePath,
+			"type":       string(def.SymbolType),
+			"file_path":  def.FilePath,
+			"start_line": def.StartLine,
+		})
+	}
+
+	result := map[string]interface{}{
+		"target_symbol":  targetName,
+		"scope":          input.Scope,
+		"definitions":    defs,
+		"references":     references,
+		"count":          len(references),
+		"files_searched": filesSearched,
+	}
+	if truncated {
+		result["truncated"] = true
+	}
+
+	return protocol.NewCallToolResult([]protocol.Content{
+		&protocol.TextContent{Type: "text", Text: MarshalYAML(result)},
+	}, false), nil
+}
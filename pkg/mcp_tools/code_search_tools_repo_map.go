@@ -0,0 +1,295 @@
+// Package mcp_tools provides code search MCP tools.
+// This file contains the handler of the repository map tool.
+package mcp_tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/ThinkInAIXYZ/go-mcp/protocol"
+	"github.com/madeindigio/remembrances-mcp/internal/storage"
+)
+
+// Limits of code_get_repo_map.
+const (
+	defaultRepoMapTokens = 2048
+	maxRepoMapTokens     = 32768
+	// maxOutlineLineRunes bounds the signature shown for a symbol
+	maxOutlineLineRunes = 160
+)
+
+// repoMapStats reports how much of a project a repository map shows.
+type repoMapStats struct {
+	Files        int `json:"files"`
+	FilesShown   int `json:"files_shown"`
+	Symbols      int `json:"symbols"`
+	SymbolsShown int `json:"symbols_shown"`
+	UsedTokens   int `json:"used_tokens"`
+	MaxTokens    int `json:"max_tokens"`
+}
+
+// repoMapFile is a file of a repository map with the symbols it shows.
+type repoMapFile struct {
+	path      string
+	importers int
+	topLevel  []storage.CodeSymbol
+	members   map[string][]storage.CodeSymbol // by parent ID
+	shown     map[string]bool                 // symbol IDs
+	included  bool
+}
+
+func (cstm *CodeSearchToolManager) codeGetRepoMapHandler(ctx context.Context, req *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+	var input CodeGetRepoMapInput
+	if err := json.Unmarshal(req.RawArguments, &input); err != nil {
+		return nil, fmt.Errorf("failed to parse input: %w", err)
+	}
+
+	if input.ProjectID == "" {
+		return nil, validationErrorf("project_id is required")
+	}
+	if input.MaxTokens < 0 {
+		return nil, validationErrorf("invalid max_tokens %d: must be 0 or greater", input.MaxTokens)
+	}
+	if input.MaxTokens == 0 {
+		input.MaxTokens = defaultRepoMapTokens
+	}
+	if input.MaxTokens > maxRepoMapTokens {
+		input.MaxTokens = maxRepoMapTokens
+	}
+
+	codeStorage, ok := cstm.storage.(interface {
+		ListCodeSymbols(ctx context.Context, projectID string) ([]storage.CodeSymbol, error)
+		ListCodeDependencies(ctx context.Context, projectID string) ([]storage.CodeDependency, error)
+	})
+	if !ok {
+		return nil, fmt.Errorf("storage does not support code operations")
+	}
+
+	symbols, err := codeStorage.ListCodeSymbols(ctx, input.ProjectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list symbols: %w", err)
+	}
+	deps, err := codeStorage.ListCodeDependencies(ctx, input.ProjectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list dependencies: %w", err)
+	}
+
+	outline, stats := buildRepoMap(symbols, deps, input.RelativePath, input.MaxTokens)
+	if stats.Files == 0 {
+		suggestions := cstm.FindProjectAlternatives(ctx, input.ProjectID)
+		message := fmt.Sprintf("No indexed symbols for project '%s'", input.ProjectID)
+		if input.RelativePath != "" {
+			message = fmt.Sprintf("No indexed symbols under '%s' for project '%s'", input.RelativePath, input.ProjectID)
+		}
+		payload := CreateEmptyResultTOON(message, suggestions)
+		return protocol.NewCallToolResult([]protocol.Content{
+			&protocol.TextContent{Type: "text", Text: payload},
+		}, false), nil
+	}
+
+	text := fmt.Sprintf("Repository map of project '%s': %d of %d files, %d of %d symbols, ~%d of %d tokens.\n",
+		input.ProjectID, stats.FilesShown, stats.Files, stats.SymbolsShown, stats.Symbols, stats.UsedTokens, stats.MaxTokens)
+	if stats.FilesShown < stats.Files || stats.SymbolsShown < stats.Symbols {
+		text += "Files most imported by others come first; raise max_tokens or narrow relative_path to see more.\n"
+	}
+	text += "\n" + outline
+
+	return protocol.NewCallToolResult([]protocol.Content{
+		&protocol.TextContent{Type: "text", Text: text},
+	}, false), nil
+}
+
+// buildRepoMap renders an outline of the files under prefix, by directory,
+// with their top-level symbols and the members of those, fitting in about
+// maxTokens. Files are ranked by the number of project files importing them
+// (or their directory, for packages), then by number of symbols; the budget
+// goes first to the headers of the highest-ranked files, then to their
+// top-level symbols and last to members.
+func buildRepoMap(symbols []storage.CodeSymbol, deps []storage.CodeDependency, prefix string, maxTokens int) (string, repoMapStats) {
+	prefix = strings.TrimPrefix(prefix, "./")
+	stats := repoMapStats{MaxTokens: maxTokens}
+
+	files := map[string]*repoMapFile{}
+	topLevel := map[string]bool{}
+	for _, sym := range symbols {
+		if !strings.HasPrefix(sym.FilePath, prefix) {
+			continue
+		}
+		f := files[sym.FilePath]
+		if f == nil {
+			f = &repoMapFile{path: sym.FilePath, members: map[string][]storage.CodeSymbol{}, shown: map[string]bool{}}
+			files[sym.FilePath] = f
+		}
+		if sym.ParentID == nil {
+			f.topLevel = append(f.topLevel, sym)
+			topLevel[sym.ID] = true
+		}
+	}
+	// Members are listed only under top-level symbols, so a second pass
+	// once these are all known
+	for _, sym := range symbols {
+		if f := files[sym.FilePath]; f != nil && sym.ParentID != nil && topLevel[*sym.ParentID] {
+			f.members[*sym.ParentID] = append(f.members[*sym.ParentID], sym)
+		}
+	}
+
+	importers := map[string]map[string]bool{}
+	for _, dep := range deps {
+		if dep.TargetPath == nil || *dep.TargetPath == dep.FilePath {
+			continue
+		}
+		if importers[*dep.TargetPath] == nil {
+			importers[*dep.TargetPath] = map[string]bool{}
+		}
+		importers[*dep.TargetPath][dep.FilePath] = true
+	}
+
+	ranked := make([]*repoMapFile, 0, len(files))
+	for _, f := range files {
+		from := map[string]bool{}
+		for _, target := range []string{f.path, path.Dir(f.path)} {
+			for importer := range importers[target] {
+				if importer != f.path {
+					from[importer] = true
+				}
+			}
+		}
+		f.importers = len(from)
+		stats.Symbols += len(f.topLevel)
+		for _, members := range f.members {
+			stats.Symbols += len(members)
+		}
+		ranked = append(ranked, f)
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		a, b := ranked[i], ranked[j]
+		if a.importers != b.importers {
+			return a.importers > b.importers
+		}
+		if len(a.topLevel) != len(b.topLevel) {
+			return len(a.topLevel) > len(b.topLevel)
+		}
+		return a.path < b.path
+	})
+	stats.Files = len(ranked)
+
+	fits := func(line string) bool {
+		tokens := estimateTokens(line) + 1
+		if stats.UsedTokens+tokens > maxTokens {
+			return false
+		}
+		stats.UsedTokens += tokens
+		return true
+	}
+
+	dirs := map[string]bool{}
+	for _, f := range ranked {
+		dir := path.Dir(f.path)
+		header := repoMapFileLine(f)
+		if !dirs[dir] && dir != "." {
+			header = dir + "/\n" + header
+		}
+		if fits(header) {
+			f.included = true
+			dirs[dir] = true
+			stats.FilesShown++
+		}
+	}
+	for _, f := range ranked {
+		if !f.included {
+			continue
+		}
+		for _, sym := range f.topLevel {
+			if fits(symbolOutline(sym)) {
+				f.shown[sym.ID] = true
+				stats.SymbolsShown++
+			}
+		}
+	}
+	for _, f := range ranked {
+		if !f.included {
+			continue
+		}
+		for _, sym := range f.topLevel {
+			if !f.shown[sym.ID] {
+				continue
+			}
+			for _, member := range f.members[sym.ID] {
+				if fits(symbolOutline(member)) {
+					f.shown[member.ID] = true
+					stats.SymbolsShown++
+				}
+			}
+		}
+	}
+
+	shown := make([]*repoMapFile, 0, stats.FilesShown)
+	for _, f := range ranked {
+		if f.included {
+			shown = append(shown, f)
+		}
+	}
+	sort.Slice(shown, func(i, j int) bool {
+		di, dj := path.Dir(shown[i].path), path.Dir(shown[j].path)
+		if di != dj {
+			return di < dj
+		}
+		return shown[i].path < shown[j].path
+	})
+
+	var b strings.Builder
+	lastDir := ""
+	for _, f := range shown {
+		indent := ""
+		if dir := path.Dir(f.path); dir != "." {
+			if dir != lastDir {
+				b.WriteString(dir + "/\n")
+				lastDir = dir
+			}
+			indent = "  "
+		}
+		b.WriteString(indent + repoMapFileLine(f) + "\n")
+		for _, sym := range f.topLevel {
+			if !f.shown[sym.ID] {
+				continue
+			}
+			b.WriteString(indent + "  " + symbolOutline(sym) + "\n")
+			for _, member := range f.members[sym.ID] {
+				if f.shown[member.ID] {
+					b.WriteString(indent + "    " + symbolOutline(member) + "\n")
+				}
+			}
+		}
+	}
+	return b.String(), stats
+}
+
+// repoMapFileLine returns the line of a file in a repository map.
+func repoMapFileLine(f *repoMapFile) string {
+	line := path.Base(f.path)
+	if f.importers > 0 {
+		line += fmt.Sprintf(" (imported by %d)", f.importers)
+	}
+	return line
+}
+
+// symbolOutline returns the line of a symbol in a repository map: the first
+// line of its signature, or its type and name when it has none.
+func symbolOutline(sym storage.CodeSymbol) string {
+	line := ""
+	if sym.Signature != nil {
+		line, _, _ = strings.Cut(strings.TrimSpace(*sym.Signature), "\n")
+		line = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(line), "{"))
+	}
+	if line == "" {
+		line = fmt.Sprintf("%s %s", sym.SymbolType, sym.Name)
+	}
+	if runes := []rune(line); len(runes) > maxOutlineLineRunes {
+		line = string(runes[:maxOutlineLineRunes]) + "…"
+	}
+	return line
+}
@@ -0,0 +1,61 @@
+package mcp_tools
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/madeindigio/remembrances-mcp/internal/storage"
+)
+
+func repoMapSymbols() []storage.CodeSymbol {
+	parent := "s1"
+	sig := "type Storage interface {"
+	get := "Get(ctx context.Context, id string) (*Entity, error)"
+	return []storage.CodeSymbol{
+		{ID: "s1", FilePath: "internal/storage/storage.go", SymbolType: "interface", Name: "Storage", Signature: &sig, StartLine: 1},
+		{ID: "s2", FilePath: "internal/storage/storage.go", SymbolType: "method", Name: "Get", Signature: &get, ParentID: &parent, StartLine: 2},
+		{ID: "s3", FilePath: "cmd/app/main.go", SymbolType: "function", Name: "main", StartLine: 5},
+		{ID: "s4", FilePath: "README.go", SymbolType: "function", Name: "doc", StartLine: 1},
+	}
+}
+
+func TestBuildRepoMap(t *testing.T) {
+	target := "internal/storage"
+	deps := []storage.CodeDependency{{FilePath: "cmd/app/main.go", Module: "app/internal/storage", TargetPath: &target}}
+
+	outline, stats := buildRepoMap(repoMapSymbols(), deps, "", 1000)
+	want := "README.go\n" +
+		"  function doc\n" +
+		"cmd/app/\n" +
+		"  main.go\n" +
+		"    function main\n" +
+		"internal/storage/\n" +
+		"  storage.go (imported by 1)\n" +
+		"    type Storage interface\n" +
+		"      Get(ctx context.Context, id string) (*Entity, error)\n"
+	if outline != want {
+		t.Errorf("buildRepoMap() =\n%s\nwant\n%s", outline, want)
+	}
+	if stats.Files != 3 || stats.FilesShown != 3 || stats.Symbols != 4 || stats.SymbolsShown != 4 {
+		t.Errorf("stats = %+v, want everything shown", stats)
+	}
+}
+
+func TestBuildRepoMapBudget(t *testing.T) {
+	target := "internal/storage"
+	deps := []storage.CodeDependency{{FilePath: "cmd/app/main.go", Module: "app/internal/storage", TargetPath: &target}}
+
+	// Room for every file and top-level symbol, not for the interface method
+	outline, stats := buildRepoMap(repoMapSymbols(), deps, "", 50)
+	if !strings.Contains(outline, "storage.go (imported by 1)") || strings.Contains(outline, "Get(") {
+		t.Errorf("buildRepoMap() with a small budget =\n%s", outline)
+	}
+	if stats.UsedTokens > stats.MaxTokens || stats.SymbolsShown >= stats.Symbols {
+		t.Errorf("stats = %+v, want a partial map within budget", stats)
+	}
+
+	outline, stats = buildRepoMap(repoMapSymbols(), deps, "cmd/", 1000)
+	if stats.Files != 1 || strings.Contains(outline, "storage") {
+		t.Errorf("buildRepoMap(cmd/) = %q, %+v; want only cmd files", outline, stats)
+	}
+}
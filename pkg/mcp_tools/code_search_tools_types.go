@@ -11,25 +11,48 @@ type CodeGetSymbolsOverviewInput struct {
 	MaxResults   int    `json:"max_results,omitempty" description:"Maximum number of symbols to return. Default is 100."`
 }
 
+// CodeReadFileInput represents input for code_read_file tool
+type CodeReadFileInput struct {
+	ProjectID    string `json:"project_id" description:"The project ID containing the file."`
+	RelativePath string `json:"relative_path" description:"Relative path to the file within the project."`
+	StartLine    int    `json:"start_line,omitempty" description:"First line to read (1-based). Default is 1."`
+	EndLine      int    `json:"end_line,omitempty" description:"Last line to read. Default is start_line + max_lines - 1."`
+	NamePath     string `json:"name_path,omitempty" description:"Read the lines of this symbol instead of a line range."`
+	ContextLines int    `json:"context_lines,omitempty" description:"Extra lines before and after the range. Default is 3 around a symbol, 0 otherwise."`
+	MaxLines     int    `json:"max_lines,omitempty" description:"Maximum number of lines to return. Default is 400."`
+}
+
+// CodeGetRepoMapInput represents input for code_get_repo_map tool
+type CodeGetRepoMapInput struct {
+	ProjectID    string `json:"project_id" description:"The project ID to outline."`
+	RelativePath string `json:"relative_path,omitempty" description:"Only outline files under this directory or path prefix."`
+	MaxTokens    int    `json:"max_tokens,omitempty" description:"Approximate token budget of the outline. Default is 2048, maximum 32768."`
+}
+
 // CodeFindSymbolInput represents input for code_find_symbol tool
 type CodeFindSymbolInput struct {
 	ProjectID       string   `json:"project_id" description:"The project ID to search in."`
-	NamePathPattern string   `json:"name_path_pattern" description:"Symbol name or path pattern. Use '/ClassName/method' for exact match, 'ClassName/method' for suffix match, or 'method' for simple name match."`
+	NamePathPattern string   `json:"name_path_pattern" description:"Symbol name or path pattern. Use '/ClassName/method' for exact match, 'ClassName/method' for suffix match, or 'method' for simple name match. Overloads after the first are 'method#2', 'method#3'..."`
 	RelativePath    string   `json:"relative_path,omitempty" description:"Restrict search to this file or directory."`
 	Depth           int      `json:"depth,omitempty" description:"Include children up to this depth level (0=symbol only, 1=direct children, etc)."`
 	IncludeBody     bool     `json:"include_body,omitempty" description:"Include source code in results."`
-	IncludeKinds    []string `json:"include_kinds,omitempty" description:"Filter by symbol types (class, function, method, interface, etc)."`
-	ExcludeKinds    []string `json:"exclude_kinds,omitempty" description:"Exclude these symbol types."`
+	IncludeKinds    []string `json:"include_kinds,omitempty" description:"Filter by symbol types (class, function, method, interface, etc) or presets: callable, type, member."`
+	ExcludeKinds    []string `json:"exclude_kinds,omitempty" description:"Exclude these symbol types or presets."`
 	SubstringMatch  bool     `json:"substring_matching,omitempty" description:"Enable partial name matching."`
+	CaseInsensitive bool     `json:"case_insensitive,omitempty" description:"Match names and paths ignoring case."`
+	Fuzzy           bool     `json:"fuzzy,omitempty" description:"Match names approximately (prefix, substring, subsequence such as 'gsu' for getSymbolUsage, or similar spelling), ignoring case, best matches first."`
+	MaxOutputBytes  int      `json:"max_output_bytes,omitempty" description:"Maximum bytes of source code across all returned bodies. Default is the server max-output-bytes."`
+	Summarize       bool     `json:"summarize,omitempty" description:"With include_body, return a skeleton of each body (declaration, member and block lines) instead of the full source code."`
 }
 
 // CodeSearchSymbolsSemanticInput represents input for code_search_symbols_semantic tool
 type CodeSearchSymbolsSemanticInput struct {
-	ProjectID   string   `json:"project_id" description:"The project ID to search in."`
-	Query       string   `json:"query" description:"Natural language query describing what you're looking for."`
-	Limit       int      `json:"limit,omitempty" description:"Maximum number of results to return. Default is 10."`
-	Languages   []string `json:"languages,omitempty" description:"Filter by programming languages (go, typescript, python, etc)."`
-	SymbolTypes []string `json:"symbol_types,omitempty" description:"Filter by symbol types (class, function, method, etc)."`
+	ProjectID     string   `json:"project_id" description:"The project ID to search in."`
+	Query         string   `json:"query" description:"Natural language query describing what you're looking for."`
+	Limit         int      `json:"limit,omitempty" description:"Maximum number of results to return. Default is 10."`
+	Languages     []string `json:"languages,omitempty" description:"Filter by programming languages (go, typescript, python, etc)."`
+	SymbolTypes   []string `json:"symbol_types,omitempty" description:"Filter by symbol types (class, function, method, etc)."`
+	MinSimilarity float64  `json:"min_similarity,omitempty" description:"Minimum cosine similarity of results. Defaults to the configured min-similarity."`
 }
 
 // CodeSearchPatternInput represents input for code_search_pattern tool
@@ -43,22 +66,88 @@ type CodeSearchPatternInput struct {
 	Limit         int      `json:"limit,omitempty" description:"Maximum number of results. Default is 50."`
 }
 
+// CodeGrepInput represents input for code_grep tool
+type CodeGrepInput struct {
+	ProjectID     string   `json:"project_id" description:"The project ID to search in."`
+	Pattern       string   `json:"pattern" description:"Text or regex to search for in file contents."`
+	IsRegex       bool     `json:"is_regex,omitempty" description:"Treat pattern as regular expression."`
+	CaseSensitive bool     `json:"case_sensitive,omitempty" description:"Enable case-sensitive matching. Default is false."`
+	Paths         []string `json:"paths,omitempty" description:"Only search files matching these glob patterns (e.g. 'internal/**', '*.go')."`
+	Exclude       []string `json:"exclude,omitempty" description:"Skip files matching these glob patterns."`
+	Languages     []string `json:"languages,omitempty" description:"Filter by programming languages."`
+	ContextLines  int      `json:"context_lines,omitempty" description:"Lines of context before and after each match. Default is 0."`
+	Limit         int      `json:"limit,omitempty" description:"Maximum number of matches. Default is 100."`
+}
+
 // CodeFindReferencesInput represents input for code_find_references tool
 type CodeFindReferencesInput struct {
-	ProjectID    string   `json:"project_id" description:"The project ID to search in."`
-	SymbolID     string   `json:"symbol_id,omitempty" description:"ID of the symbol to find references for."`
-	SymbolName   string   `json:"symbol_name,omitempty" description:"Name of the symbol (alternative to symbol_id)."`
-	IncludeKinds []string `json:"include_kinds,omitempty" description:"Filter referencing symbols by type."`
-	Limit        int      `json:"limit,omitempty" description:"Maximum number of references. Default is 50."`
+	ProjectID     string   `json:"project_id" description:"The project ID to search in."`
+	SymbolID      string   `json:"symbol_id,omitempty" description:"ID of the symbol to find references for."`
+	SymbolName    string   `json:"symbol_name,omitempty" description:"Name of the symbol (alternative to symbol_id)."`
+	RelativePath  string   `json:"relative_path,omitempty" description:"File where the symbol is defined, to disambiguate symbol_name."`
+	Scope         string   `json:"scope,omitempty" description:"Where to search: file, package (same directory) or project. Default is project."`
+	RequireImport bool     `json:"require_import,omitempty" description:"Only report references in files that share the definition's package or import it."`
+	IncludeKinds  []string `json:"include_kinds,omitempty" description:"Filter referencing symbols by type."`
+	Limit         int      `json:"limit,omitempty" description:"Maximum number of references. Default is 50."`
 }
 
 // CodeHybridSearchInput represents input for code_hybrid_search tool
 type CodeHybridSearchInput struct {
+	ProjectID              string   `json:"project_id" description:"The project ID to search in."`
+	Query                  string   `json:"query" description:"Natural language query for semantic search."`
+	Languages              []string `json:"languages,omitempty" description:"Filter by programming languages (go, typescript, python, etc)."`
+	SymbolTypes            []string `json:"symbol_types,omitempty" description:"Filter by symbol types (class, function, method, interface, etc)."`
+	PathPattern            string   `json:"path_pattern,omitempty" description:"Filter by file path pattern (e.g., 'src/auth/**')."`
+	IncludeChunks          bool     `json:"include_chunks,omitempty" description:"Search in code chunks for better large-symbol coverage."`
+	Limit                  int      `json:"limit,omitempty" description:"Maximum number of results. Default is 20."`
+	MinSimilarity          float64  `json:"min_similarity,omitempty" description:"Minimum cosine similarity of results. Defaults to the configured min-similarity."`
+	OrderBy                string   `json:"order_by,omitempty" description:"Order of results: score (best first, default) or file (by file path and line)."`
+	GroupByFile            bool     `json:"group_by_file,omitempty" description:"Group results by file, files with the best match first."`
+	IncludeDuplicateChunks bool     `json:"include_duplicate_chunks,omitempty" description:"Keep chunks of symbols already in the results. By default each symbol appears once, as its best-scoring match."`
+}
+
+// CodeSearchDocsInput represents input for code_search_docs tool
+type CodeSearchDocsInput struct {
 	ProjectID     string   `json:"project_id" description:"The project ID to search in."`
-	Query         string   `json:"query" description:"Natural language query for semantic search."`
+	Query         string   `json:"query" description:"What you want to do or know, e.g. 'parse a config file' or 'retry with backoff'."`
 	Languages     []string `json:"languages,omitempty" description:"Filter by programming languages (go, typescript, python, etc)."`
 	SymbolTypes   []string `json:"symbol_types,omitempty" description:"Filter by symbol types (class, function, method, interface, etc)."`
-	PathPattern   string   `json:"path_pattern,omitempty" description:"Filter by file path pattern (e.g., 'src/auth/**')."`
-	IncludeChunks bool     `json:"include_chunks,omitempty" description:"Search in code chunks for better large-symbol coverage."`
-	Limit         int      `json:"limit,omitempty" description:"Maximum number of results. Default is 20."`
+	Limit         int      `json:"limit,omitempty" description:"Maximum number of results. Default is 10, maximum 50."`
+	MinSimilarity float64  `json:"min_similarity,omitempty" description:"Minimum cosine similarity of semantic matches. Defaults to the configured min-similarity."`
+}
+
+// CodeGetDependenciesInput represents input for code_get_dependencies tool
+type CodeGetDependenciesInput struct {
+	ProjectID    string `json:"project_id" description:"The project ID to search in."`
+	RelativePath string `json:"relative_path" description:"Relative path to the file within the project."`
+}
+
+// CodeGetDependentsInput represents input for code_get_dependents tool
+type CodeGetDependentsInput struct {
+	ProjectID    string `json:"project_id" description:"The project ID to search in."`
+	RelativePath string `json:"relative_path,omitempty" description:"Relative path of the file (or Go package directory) whose importers to list."`
+	Module       string `json:"module,omitempty" description:"Module name as written in import statements (alternative to relative_path)."`
+}
+
+// CodeExportDependencyGraphInput represents input for code_export_dependency_graph tool
+type CodeExportDependencyGraphInput struct {
+	ProjectID       string `json:"project_id" description:"The project ID to export."`
+	Format          string `json:"format,omitempty" description:"Output format: 'json' (default) or 'dot' (Graphviz)."`
+	IncludeExternal bool   `json:"include_external,omitempty" description:"Include modules outside the project as nodes. Default is false."`
+}
+
+// CodeFindCallersInput represents input for code_find_callers tool
+type CodeFindCallersInput struct {
+	ProjectID  string `json:"project_id" description:"The project ID to search in."`
+	SymbolName string `json:"symbol_name" description:"Name of the called function or method, without receiver or package."`
+	Qualifier  string `json:"qualifier,omitempty" description:"Keep only calls whose callee expression contains this text (e.g. a receiver or package name)."`
+	Limit      int    `json:"limit,omitempty" description:"Maximum number of calls. Default is 50."`
+}
+
+// CodeFindCalleesInput represents input for code_find_callees tool
+type CodeFindCalleesInput struct {
+	ProjectID    string `json:"project_id" description:"The project ID to search in."`
+	Symbol       string `json:"symbol" description:"Name or name path of the calling function or method."`
+	RelativePath string `json:"relative_path,omitempty" description:"Restrict the caller to this file."`
+	Limit        int    `json:"limit,omitempty" description:"Maximum number of calls. Default is 100."`
 }
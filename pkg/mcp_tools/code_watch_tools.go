@@ -51,11 +51,11 @@ func (ctm *CodeToolManager) codeGetWatchStatusTool() *protocol.Tool {
 func (ctm *CodeToolManager) codeActivateProjectWatchHandler(ctx context.Context, req *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
 	var input CodeActivateProjectWatchInput
 	if err := json.Unmarshal(req.RawArguments, &input); err != nil {
-		return nil, fmt.Errorf("invalid input: %w", err)
+		return nil, validationErrorf("invalid input: %w", err)
 	}
 
 	if input.ProjectID == "" {
-		return nil, fmt.Errorf("project_id is required")
+		return nil, validationErrorf("project_id is required")
 	}
 
 	if ctm.watcherManager == nil {
@@ -87,7 +87,7 @@ func (ctm *CodeToolManager) codeActivateProjectWatchHandler(ctx context.Context,
 func (ctm *CodeToolManager) codeDeactivateProjectWatchHandler(ctx context.Context, req *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
 	var input CodeDeactivateProjectWatchInput
 	if err := json.Unmarshal(req.RawArguments, &input); err != nil {
-		return nil, fmt.Errorf("invalid input: %w", err)
+		return nil, validationErrorf("invalid input: %w", err)
 	}
 
 	if ctm.watcherManager == nil {
@@ -121,7 +121,7 @@ func (ctm *CodeToolManager) codeDeactivateProjectWatchHandler(ctx context.Contex
 func (ctm *CodeToolManager) codeGetWatchStatusHandler(ctx context.Context, req *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
 	var input CodeGetWatchStatusInput
 	if err := json.Unmarshal(req.RawArguments, &input); err != nil {
-		return nil, fmt.Errorf("invalid input: %w", err)
+		return nil, validationErrorf("invalid input: %w", err)
 	}
 
 	if ctm.watcherManager == nil {
@@ -138,7 +138,7 @@ func (ctm *CodeToolManager) codeGetWatchStatusHandler(ctx context.Context, req *
 		}
 
 		result = map[string]interface{}{
-			"active_project": ctm.watcherManager.GetActiveProject(),
+			"active_project": ctm.watcherManager.GetActiveProject(ctx),
 			"project_status": status,
 		}
 	} else {
@@ -149,7 +149,7 @@ func (ctm *CodeToolManager) codeGetWatchStatusHandler(ctx context.Context, req *
 		}
 
 		result = map[string]interface{}{
-			"active_project": ctm.watcherManager.GetActiveProject(),
+			"active_project": ctm.watcherManager.GetActiveProject(ctx),
 			"projects":       statuses,
 		}
 	}
@@ -0,0 +1,36 @@
+package mcp_tools
+
+import (
+	"fmt"
+
+	"github.com/ThinkInAIXYZ/go-mcp/protocol"
+)
+
+// revisionConflict describes an update rejected because the record changed
+// after the caller read it.
+type revisionConflict struct {
+	ID       string      `json:"id"`
+	Expected interface{} `json:"expected_revision"`
+	Current  interface{} `json:"current_revision"`
+}
+
+func (c *revisionConflict) Error() string {
+	return fmt.Sprintf("revision conflict on %s: expected revision %v, current revision is %v", c.ID, c.Expected, c.Current)
+}
+
+// conflictResult reports a revision conflict as a structured tool error so the
+// caller can re-read the record and retry with the current revision.
+func conflictResult(conflict *revisionConflict, hint string) *protocol.CallToolResult {
+	response := map[string]interface{}{
+		"code":              ErrCodeConflict,
+		"error":             "revision_conflict",
+		"message":           conflict.Error(),
+		"id":                conflict.ID,
+		"expected_revision": conflict.Expected,
+		"current_revision":  conflict.Current,
+		"hint":              hint,
+	}
+	return protocol.NewCallToolResult([]protocol.Content{
+		&protocol.TextContent{Type: "text", Text: MarshalTOON(response)},
+	}, true)
+}
@@ -0,0 +1,104 @@
+package mcp_tools
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ThinkInAIXYZ/go-mcp/protocol"
+	"github.com/madeindigio/remembrances-mcp/internal/storage"
+	"github.com/madeindigio/remembrances-mcp/pkg/treesitter"
+)
+
+// revisionStorage holds one vector memory at revision 2.
+type revisionStorage struct {
+	storage.StorageWithStats
+	revision int
+}
+
+func (s *revisionStorage) UpdateVector(ctx context.Context, id, userID, content string, embedding []float32, metadata map[string]interface{}, expectedRevision int) (int, error) {
+	if expectedRevision != 0 && expectedRevision != s.revision {
+		return 0, &storage.RevisionConflictError{ID: id, Expected: expectedRevision, Current: s.revision}
+	}
+	s.revision++
+	return s.revision, nil
+}
+
+func TestUpdateVectorConflict(t *testing.T) {
+	tm := NewToolManager(&revisionStorage{revision: 2}, constEmbedder{}, "")
+	update := func(revision int) *protocol.CallToolResult {
+		t.Helper()
+		raw, _ := json.Marshal(map[string]interface{}{"id": "vector_memories:abc", "user_id": "alice", "content": "edited", "revision": revision})
+		result, err := tm.updateVectorHandler(context.Background(), &protocol.CallToolRequest{RawArguments: raw})
+		if err != nil {
+			t.Fatalf("update_vector at revision %d: %v", revision, err)
+		}
+		return result
+	}
+
+	result := update(1)
+	text := result.Content[0].(*protocol.TextContent).Text
+	if ResultErrorCode(result) != ErrCodeConflict || !strings.Contains(text, "expected_revision: 1") || !strings.Contains(text, "current_revision: 2") {
+		t.Errorf("stale update = %q, want a CONFLICT from revision 1 to 2", text)
+	}
+
+	result = update(2)
+	if text := result.Content[0].(*protocol.TextContent).Text; result.IsError || !strings.Contains(text, "revision 3") {
+		t.Errorf("current update = %q, want revision 3", text)
+	}
+}
+
+// symbolStorage resolves every symbol to the function main of main.go in root.
+type symbolStorage struct {
+	storage.Storage
+	root   string
+	source string
+}
+
+func (s *symbolStorage) Query(ctx context.Context, query string, params map[string]interface{}) ([]map[string]interface{}, error) {
+	return []map[string]interface{}{{
+		"id":         "code_symbols:main",
+		"file_path":  "main.go",
+		"name_path":  "/main",
+		"language":   "go",
+		"start_byte": float64(strings.Index(s.source, "func")),
+		"end_byte":   float64(len(s.source) - 1),
+		"start_line": float64(3),
+		"end_line":   float64(3),
+	}}, nil
+}
+
+func (s *symbolStorage) GetCodeProject(ctx context.Context, projectID string) (*storage.CodeProject, error) {
+	return &storage.CodeProject{ProjectID: projectID, RootPath: s.root}, nil
+}
+
+func TestCodeReplaceSymbolConflict(t *testing.T) {
+	root := t.TempDir()
+	source := "package main\n\nfunc main() {}\n"
+	if err := os.WriteFile(filepath.Join(root, "main.go"), []byte(source), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cmtm := NewCodeManipulationToolManager(&symbolStorage{root: root, source: source}, nil)
+	replace := func(revision string) *protocol.CallToolResult {
+		t.Helper()
+		raw, _ := json.Marshal(map[string]interface{}{
+			"project_id": "app", "name_path": "/main", "new_body": "func main() { println() }",
+			"revision": revision, "dry_run": true,
+		})
+		result, err := cmtm.codeReplaceSymbolHandler(context.Background(), &protocol.CallToolRequest{RawArguments: raw})
+		if err != nil {
+			t.Fatalf("code_replace_symbol at revision %q: %v", revision, err)
+		}
+		return result
+	}
+
+	if result := replace(treesitter.SymbolRevision([]byte("func main() { old() }"))); ResultErrorCode(result) != ErrCodeConflict {
+		t.Errorf("stale replacement = %+v, want a CONFLICT", result.Content[0])
+	}
+	if result := replace(treesitter.SymbolRevision([]byte("func main() {}"))); result.IsError {
+		t.Errorf("current replacement = %+v, want the diff", result.Content[0])
+	}
+}
@@ -0,0 +1,190 @@
+package mcp_tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/ThinkInAIXYZ/go-mcp/protocol"
+	"github.com/madeindigio/remembrances-mcp/internal/storage"
+	"github.com/madeindigio/remembrances-mcp/pkg/embedder"
+	"github.com/madeindigio/remembrances-mcp/pkg/llm"
+)
+
+const (
+	defaultConsolidationThreshold = 0.85
+	defaultConsolidationLimit     = 500
+	defaultMinClusterSize         = 2
+)
+
+// memoryCluster is a group of similar vector memories consolidated into one summary.
+type memoryCluster struct {
+	SummaryID string   `json:"summary_id,omitempty"`
+	Summary   string   `json:"summary,omitempty"`
+	MemoryIDs []string `json:"memory_ids"`
+	Contents  []string `json:"contents,omitempty"`
+	Error     string   `json:"error,omitempty"`
+}
+
+func (tm *ToolManager) consolidateTool() *protocol.Tool {
+	tool, err := protocol.NewTool("remembrance_consolidate", `Cluster similar remembrances, summarize each cluster with an LLM and archive the originals. Use how_to_use("remembrance_consolidate") for details.`, ConsolidateInput{})
+	if err != nil {
+		slog.Error("failed to create tool", "name", "remembrance_consolidate", "err", err)
+		return nil
+	}
+	return tool
+}
+
+func (tm *ToolManager) consolidateHandler(ctx context.Context, request *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+	var input ConsolidateInput
+	if err := json.Unmarshal(request.RawArguments, &input); err != nil {
+		return nil, fmt.Errorf(errParseArgs, err)
+	}
+
+	if input.UserID == "" {
+		return nil, validationErrorf("user_id is required")
+	}
+	if input.Threshold <= 0 {
+		input.Threshold = tm.consolidationThreshold
+	}
+	if input.Threshold <= 0 {
+		input.Threshold = defaultConsolidationThreshold
+	}
+	if input.Threshold > 1 {
+		return nil, validationErrorf("threshold must be between 0 and 1")
+	}
+	if input.MinClusterSize < 2 {
+		input.MinClusterSize = defaultMinClusterSize
+	}
+	if input.Limit <= 0 {
+		input.Limit = defaultConsolidationLimit
+	}
+	if !input.DryRun && tm.llm == nil {
+		return nil, fmt.Errorf("remembrance_consolidate requires an LLM: configure llm-provider and llm-model, or use dry_run to preview clusters")
+	}
+
+	memories, err := tm.storage.ListVectorMemories(ctx, input.UserID, input.Limit)
+	if err != nil {
+		return nil, err
+	}
+	pinned, err := tm.pinnedRefs(ctx, input.UserID, storage.TrashKindVector)
+	if err != nil {
+		return nil, err
+	}
+	candidates := make([]storage.VectorMemory, 0, len(memories))
+	for _, mem := range memories {
+		if !pinned[storage.TrashKindVector+"\x00"+mem.ID] {
+			candidates = append(candidates, mem)
+		}
+	}
+
+	groups := clusterMemories(candidates, input.Threshold, input.MinClusterSize)
+	if len(groups) == 0 {
+		payload := CreateEmptyResultTOON(
+			fmt.Sprintf("No clusters of similar remembrances found for user '%s' at threshold %.2f. Lower the threshold to group less similar remembrances", input.UserID, input.Threshold),
+			AlternativeSuggestions{},
+		)
+		return protocol.NewCallToolResult([]protocol.Content{
+			&protocol.TextContent{Type: "text", Text: payload},
+		}, false), nil
+	}
+
+	clusters := make([]memoryCluster, 0, len(groups))
+	archived := 0
+	for _, group := range groups {
+		cluster := memoryCluster{}
+		texts := make([]string, len(group))
+		for i, mem := range group {
+			cluster.MemoryIDs = append(cluster.MemoryIDs, mem.ID)
+			texts[i] = mem.Content
+		}
+
+		if input.DryRun {
+			cluster.Contents = texts
+			clusters = append(clusters, cluster)
+			continue
+		}
+
+		if err := tm.consolidateCluster(ctx, input.UserID, texts, &cluster); err != nil {
+			slog.Warn("failed to consolidate memory cluster", "user_id", input.UserID, "size", len(group), "error", err)
+			cluster.Error = err.Error()
+		} else {
+			archived += len(group)
+		}
+		clusters = append(clusters, cluster)
+	}
+
+	response := map[string]interface{}{
+		"user_id":        input.UserID,
+		"threshold":      input.Threshold,
+		"dry_run":        input.DryRun,
+		"scanned":        len(memories),
+		"pinned_skipped": len(memories) - len(candidates),
+		"cluster_count":  len(clusters),
+		"archived_count": archived,
+		"clusters":       clusters,
+	}
+
+	return protocol.NewCallToolResult([]protocol.Content{
+		&protocol.TextContent{Type: "text", Text: MarshalTOON(response)},
+	}, false), nil
+}
+
+// consolidateCluster summarizes texts, stores the summary and archives the cluster members.
+func (tm *ToolManager) consolidateCluster(ctx context.Context, userID string, texts []string, cluster *memoryCluster) error {
+	summary, err := llm.SummarizeMemories(ctx, tm.llm, texts)
+	if err != nil {
+		return err
+	}
+	cluster.Summary = summary
+
+	embedding, err := tm.embedderFor(embedder.RouteVectors).EmbedQuery(ctx, summary)
+	if err != nil {
+		return embedderErrorf(errGenEmbedding, err)
+	}
+
+	metadata := map[string]interface{}{
+		"consolidated":       true,
+		"consolidated_from":  cluster.MemoryIDs,
+		"consolidated_count": len(cluster.MemoryIDs),
+		"consolidated_at":    time.Now().UTC().Format(time.RFC3339),
+	}
+	summaryID, err := tm.storage.ConsolidateVectors(ctx, userID, summary, embedding, metadata, cluster.MemoryIDs)
+	cluster.SummaryID = summaryID
+	return err
+}
+
+// clusterMemories groups memories greedily: each memory not yet assigned seeds a
+// cluster and collects every later unassigned memory whose cosine similarity to
+// the seed is at least threshold. Clusters smaller than minSize are dropped.
+func clusterMemories(memories []storage.VectorMemory, threshold float64, minSize int) [][]storage.VectorMemory {
+	assigned := make([]bool, len(memories))
+	var clusters [][]storage.VectorMemory
+
+	for i := range memories {
+		if assigned[i] || len(memories[i].Embedding) == 0 {
+			continue
+		}
+		cluster := []storage.VectorMemory{memories[i]}
+		members := []int{i}
+		for j := i + 1; j < len(memories); j++ {
+			if assigned[j] {
+				continue
+			}
+			if embedder.CosineSimilarity(memories[i].Embedding, memories[j].Embedding) >= threshold {
+				cluster = append(cluster, memories[j])
+				members = append(members, j)
+			}
+		}
+		if len(cluster) < minSize {
+			continue
+		}
+		for _, m := range members {
+			assigned[m] = true
+		}
+		clusters = append(clusters, cluster)
+	}
+	return clusters
+}
@@ -0,0 +1,40 @@
+package mcp_tools
+
+import (
+	"testing"
+
+	"github.com/madeindigio/remembrances-mcp/internal/storage"
+)
+
+func TestClusterMemories(t *testing.T) {
+	memories := []storage.VectorMemory{
+		{ID: "vector_memories:a", Embedding: []float32{1, 0, 0}},
+		{ID: "vector_memories:b", Embedding: []float32{0, 1, 0}},
+		{ID: "vector_memories:c", Embedding: []float32{0.95, 0.05, 0}},
+		{ID: "vector_memories:d", Embedding: []float32{0, 0, 1}},
+		{ID: "vector_memories:e", Embedding: []float32{0.9, 0.1, 0}},
+		{ID: "vector_memories:f"},
+	}
+
+	clusters := clusterMemories(memories, 0.9, 2)
+	if len(clusters) != 1 {
+		t.Fatalf("expected 1 cluster, got %d", len(clusters))
+	}
+	var ids []string
+	for _, m := range clusters[0] {
+		ids = append(ids, m.ID)
+	}
+	want := []string{"vector_memories:a", "vector_memories:c", "vector_memories:e"}
+	if len(ids) != len(want) {
+		t.Fatalf("cluster = %v, want %v", ids, want)
+	}
+	for i := range want {
+		if ids[i] != want[i] {
+			t.Fatalf("cluster = %v, want %v", ids, want)
+		}
+	}
+
+	if got := clusterMemories(memories, 0.9, 4); len(got) != 0 {
+		t.Fatalf("expected no clusters with min size 4, got %d", len(got))
+	}
+}
@@ -0,0 +1,141 @@
+package mcp_tools
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/madeindigio/remembrances-mcp/internal/storage"
+)
+
+// maxGroupPreviewBytes caps the stitched preview of a document group.
+const maxGroupPreviewBytes = 2000
+
+// documentGroup aggregates the chunks of one document matched by
+// kb_search_documents with group_by_document.
+type documentGroup struct {
+	FilePath    string                 `json:"file_path"`
+	Version     int                    `json:"version,omitempty"`
+	Matches     int                    `json:"matches"`
+	MaxScore    float64                `json:"max_score"`
+	AvgScore    float64                `json:"avg_score"`
+	ChunkRanges []string               `json:"chunk_ranges,omitempty"`
+	ChunkCount  int                    `json:"chunk_count,omitempty"`
+	Preview     string                 `json:"preview"`
+	Metadata    map[string]interface{} `json:"metadata,omitempty"`
+
+	chunks map[int]string
+}
+
+// groupDocumentResults aggregates chunk results per source document, and per
+// version for archived matches. Groups are ordered by their best score; the
+// preview stitches the matched chunks in document order, collapsing chunk
+// overlap and marking gaps between ranges with "...".
+func groupDocumentResults(results []storage.DocumentResult) []*documentGroup {
+	var groups []*documentGroup
+	byKey := map[string]*documentGroup{}
+	for _, r := range results {
+		if r.Document == nil {
+			continue
+		}
+		path, index, chunked := splitChunkPath(r.Document.FilePath)
+		version := 0
+		if r.Document.Metadata["archived"] == true {
+			version, _ = metadataInt(r.Document.Metadata, "version")
+		}
+		key := fmt.Sprintf("%s\x00%d", path, version)
+		g, ok := byKey[key]
+		if !ok {
+			g = &documentGroup{FilePath: path, Version: version, chunks: map[int]string{}}
+			byKey[key] = g
+			groups = append(groups, g)
+		}
+		g.Matches++
+		g.AvgScore += r.Score
+		if g.Matches == 1 || r.Score > g.MaxScore {
+			g.MaxScore = r.Score
+			g.Metadata = groupMetadata(r.Document.Metadata)
+		}
+		if count, ok := metadataInt(r.Document.Metadata, "chunk_count"); ok {
+			g.ChunkCount = count
+		}
+		if !chunked {
+			index = 0
+		}
+		if _, seen := g.chunks[index]; !seen {
+			g.chunks[index] = r.Document.Content
+		}
+	}
+
+	for _, g := range groups {
+		g.AvgScore /= float64(g.Matches)
+		indexes := make([]int, 0, len(g.chunks))
+		for index := range g.chunks {
+			indexes = append(indexes, index)
+		}
+		sort.Ints(indexes)
+
+		var parts []string
+		for start := 0; start < len(indexes); {
+			end := start
+			for end+1 < len(indexes) && indexes[end+1] == indexes[end]+1 {
+				end++
+			}
+			run := make([]string, 0, end-start+1)
+			for _, index := range indexes[start : end+1] {
+				run = append(run, g.chunks[index])
+			}
+			parts = append(parts, storage.MergeChunkContents(run))
+			if g.ChunkCount > 1 || indexes[end] > 0 {
+				if start == end {
+					g.ChunkRanges = append(g.ChunkRanges, fmt.Sprint(indexes[start]))
+				} else {
+					g.ChunkRanges = append(g.ChunkRanges, fmt.Sprintf("%d-%d", indexes[start], indexes[end]))
+				}
+			}
+			start = end + 1
+		}
+		preview := strings.Join(parts, "\n...\n")
+		if text, next := truncateText(preview, 0, maxGroupPreviewBytes); next > 0 {
+			preview = text + "..."
+		}
+		g.Preview = preview
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool {
+		return groups[i].MaxScore > groups[j].MaxScore
+	})
+	return groups
+}
+
+// groupMetadata returns the metadata of a document without the fields
+// describing a single chunk.
+func groupMetadata(metadata map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(metadata))
+	for k, v := range metadata {
+		switch k {
+		case "chunk_index", "chunk_count":
+			continue
+		}
+		out[k] = v
+	}
+	return out
+}
+
+// documentGroupCitationsMarkdown renders grouped knowledge base search
+// results as markdown citing each document's matched chunk ranges and scores.
+func documentGroupCitationsMarkdown(query string, groups []*documentGroup) string {
+	citations := make([]citation, 0, len(groups))
+	for _, g := range groups {
+		c := citation{title: g.FilePath, source: "`" + g.FilePath + "`", content: g.Preview}
+		if len(g.ChunkRanges) > 0 {
+			c.details = append(c.details, "chunks "+strings.Join(g.ChunkRanges, ", "))
+		}
+		if g.Version > 0 {
+			c.details = append(c.details, fmt.Sprintf("archived version %d", g.Version))
+		}
+		c.details = append(c.details, fmt.Sprintf("max score %.3f", g.MaxScore), fmt.Sprintf("avg score %.3f", g.AvgScore))
+		citations = append(citations, c)
+	}
+	return citationsMarkdown(query, citations)
+}
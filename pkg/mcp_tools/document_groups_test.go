@@ -0,0 +1,55 @@
+package mcp_tools
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/madeindigio/remembrances-mcp/internal/storage"
+)
+
+func chunkResult(path, content string, score float64, metadata map[string]interface{}) storage.DocumentResult {
+	return storage.DocumentResult{
+		Document:   &storage.Document{FilePath: path, Content: content, Metadata: metadata},
+		Similarity: score,
+		Score:      score,
+	}
+}
+
+func TestGroupDocumentResults(t *testing.T) {
+	chunkMeta := map[string]interface{}{"chunk_count": 6, "chunk_index": 0, "title": "Auth"}
+	results := []storage.DocumentResult{
+		chunkResult("docs/auth.md#chunk3", "Then restart the server.", 0.9, chunkMeta),
+		chunkResult("notes.md", "Tokens expire daily.", 0.7, nil),
+		chunkResult("docs/auth.md#chunk2", "Set AUTH_TOKEN first.", 0.5, chunkMeta),
+		chunkResult("docs/auth.md#chunk5", "Rotate tokens monthly.", 0.4, chunkMeta),
+		chunkResult("docs/auth.md", "Old auth notes.", 0.8, map[string]interface{}{"archived": true, "version": 2}),
+	}
+	groups := groupDocumentResults(results)
+	if len(groups) != 3 {
+		t.Fatalf("got %d groups, want 3", len(groups))
+	}
+
+	auth := groups[0]
+	if auth.FilePath != "docs/auth.md" || auth.Version != 0 || auth.Matches != 3 || auth.ChunkCount != 6 {
+		t.Fatalf("unexpected first group %+v", auth)
+	}
+	if auth.MaxScore != 0.9 || auth.AvgScore < 0.599 || auth.AvgScore > 0.601 {
+		t.Errorf("scores = %v max, %v avg; want 0.9, 0.6", auth.MaxScore, auth.AvgScore)
+	}
+	if want := []string{"2-3", "5"}; !reflect.DeepEqual(auth.ChunkRanges, want) {
+		t.Errorf("chunk ranges = %v, want %v", auth.ChunkRanges, want)
+	}
+	if want := "Set AUTH_TOKEN first.\nThen restart the server.\n...\nRotate tokens monthly."; auth.Preview != want {
+		t.Errorf("preview = %q, want %q", auth.Preview, want)
+	}
+	if _, ok := auth.Metadata["chunk_index"]; ok || auth.Metadata["title"] != "Auth" {
+		t.Errorf("metadata = %v, want the document metadata without chunk fields", auth.Metadata)
+	}
+
+	if archived := groups[1]; archived.FilePath != "docs/auth.md" || archived.Version != 2 || archived.ChunkRanges != nil {
+		t.Errorf("unexpected archived group %+v", archived)
+	}
+	if notes := groups[2]; notes.FilePath != "notes.md" || notes.Preview != "Tokens expire daily." {
+		t.Errorf("unexpected notes group %+v", notes)
+	}
+}
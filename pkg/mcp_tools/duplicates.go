@@ -0,0 +1,113 @@
+package mcp_tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ThinkInAIXYZ/go-mcp/protocol"
+	"github.com/madeindigio/remembrances-mcp/internal/storage"
+)
+
+// duplicateMatch describes an existing record that a new memory or document
+// duplicates within the configured similarity threshold.
+type duplicateMatch struct {
+	ID         string                 `json:"id,omitempty"`
+	FilePath   string                 `json:"file_path,omitempty"`
+	Content    string                 `json:"content,omitempty"`
+	Similarity float64                `json:"similarity"`
+	Metadata   map[string]interface{} `json:"metadata,omitempty"`
+	Merged     bool                   `json:"merged"`
+}
+
+// findDuplicateVector returns the closest vector memory of userID when its cosine
+// similarity to embedding reaches the duplicate threshold.
+func (tm *ToolManager) findDuplicateVector(ctx context.Context, userID string, embedding []float32) (*storage.VectorResult, error) {
+	if tm.duplicateThreshold <= 0 {
+		return nil, nil
+	}
+
+	results, err := tm.storage.SearchSimilarWithOptions(ctx, userID, embedding, storage.VectorSearchOptions{
+		Limit:         1,
+		MinSimilarity: tm.duplicateThreshold,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to check for duplicates: %w", err)
+	}
+	if len(results) == 0 || results[0].Similarity < tm.duplicateThreshold {
+		return nil, nil
+	}
+	return &results[0], nil
+}
+
+// findDuplicateDocument reports another document whose chunks match every chunk
+// embedding within the duplicate threshold. Re-saving the same filePath is an
+// update, not a duplicate, so that path is ignored.
+func (tm *ToolManager) findDuplicateDocument(ctx context.Context, filePath string, embeddings [][]float32) (*duplicateMatch, error) {
+	if tm.duplicateThreshold <= 0 || len(embeddings) == 0 {
+		return nil, nil
+	}
+
+	var match *duplicateMatch
+	for _, emb := range embeddings {
+		results, err := tm.storage.SearchDocumentsWithOptions(ctx, emb, storage.VectorSearchOptions{
+			Limit:         5,
+			MinSimilarity: tm.duplicateThreshold,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to check for duplicates: %w", err)
+		}
+
+		var best *storage.DocumentResult
+		for i := range results {
+			if results[i].Document == nil || results[i].Similarity < tm.duplicateThreshold {
+				continue
+			}
+			source := documentSourcePath(results[i].Document.FilePath)
+			if source == filePath || (match != nil && source != match.FilePath) {
+				continue
+			}
+			best = &results[i]
+			break
+		}
+		if best == nil {
+			return nil, nil
+		}
+
+		if match == nil {
+			match = &duplicateMatch{
+				FilePath:   documentSourcePath(best.Document.FilePath),
+				Similarity: best.Similarity,
+			}
+		} else if best.Similarity < match.Similarity {
+			match.Similarity = best.Similarity
+		}
+	}
+	return match, nil
+}
+
+// duplicateResult builds the tool response returned instead of storing a duplicate.
+func duplicateResult(kind string, match duplicateMatch) *protocol.CallToolResult {
+	message := fmt.Sprintf("Not stored: a %s with similarity %.3f already exists", kind, match.Similarity)
+	if match.Merged {
+		message += "; metadata was merged into it"
+	}
+	message += ". Pass allow_duplicate=true to store it anyway."
+
+	response := map[string]interface{}{
+		"status":   "duplicate",
+		"message":  message,
+		"existing": match,
+	}
+	return protocol.NewCallToolResult([]protocol.Content{
+		&protocol.TextContent{Type: "text", Text: MarshalTOON(response)},
+	}, false)
+}
+
+// documentSourcePath strips the "#chunkN" suffix SaveDocumentChunks adds to chunk paths.
+func documentSourcePath(chunkPath string) string {
+	if idx := strings.LastIndex(chunkPath, "#chunk"); idx > 0 {
+		return chunkPath[:idx]
+	}
+	return chunkPath
+}
@@ -0,0 +1,132 @@
+package mcp_tools
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/ThinkInAIXYZ/go-mcp/protocol"
+	"github.com/madeindigio/remembrances-mcp/internal/storage"
+)
+
+// constEmbedder embeds every text as the same vector.
+type constEmbedder struct{}
+
+func (constEmbedder) EmbedDocuments(ctx context.Context, texts []string) ([][]float32, error) {
+	embeddings := make([][]float32, len(texts))
+	for i := range texts {
+		embeddings[i] = []float32{1, 0}
+	}
+	return embeddings, nil
+}
+
+func (constEmbedder) EmbedQuery(ctx context.Context, text string) ([]float32, error) {
+	return []float32{1, 0}, nil
+}
+
+func (constEmbedder) Dimension() int { return 2 }
+
+// duplicateStorage holds one existing memory and records what the tools store.
+type duplicateStorage struct {
+	storage.StorageWithStats
+	existing storage.VectorResult
+	docs     map[float32][]storage.DocumentResult // search results by the first embedding component
+	indexed  []string
+	merged   map[string]interface{}
+}
+
+func (s *duplicateStorage) SearchSimilarWithOptions(ctx context.Context, userID string, queryEmbedding []float32, opts storage.VectorSearchOptions) ([]storage.VectorResult, error) {
+	if s.existing.Similarity < opts.MinSimilarity {
+		return nil, nil
+	}
+	return []storage.VectorResult{s.existing}, nil
+}
+
+func (s *duplicateStorage) SearchDocumentsWithOptions(ctx context.Context, queryEmbedding []float32, opts storage.VectorSearchOptions) ([]storage.DocumentResult, error) {
+	return s.docs[queryEmbedding[0]], nil
+}
+
+func (s *duplicateStorage) IndexVector(ctx context.Context, userID, content string, embedding []float32, metadata map[string]interface{}) error {
+	s.indexed = append(s.indexed, content)
+	return nil
+}
+
+func (s *duplicateStorage) MergeVectorMetadata(ctx context.Context, id string, metadata map[string]interface{}) error {
+	s.merged = metadata
+	return nil
+}
+
+func TestAddVectorDuplicates(t *testing.T) {
+	tests := []struct {
+		name       string
+		threshold  float64
+		similarity float64
+		args       map[string]interface{}
+		stored     bool
+		merged     bool
+	}{
+		{name: "duplicate", threshold: 0.9, similarity: 0.95},
+		{name: "below threshold", threshold: 0.9, similarity: 0.5, stored: true},
+		{name: "detection disabled", similarity: 1, stored: true},
+		{name: "allow duplicate", threshold: 0.9, similarity: 0.95, args: map[string]interface{}{"allow_duplicate": true}, stored: true},
+		{name: "merge metadata", threshold: 0.9, similarity: 0.95, args: map[string]interface{}{"merge_metadata": true, "metadata": map[string]interface{}{"topic": "go"}}, merged: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st := &duplicateStorage{existing: storage.VectorResult{ID: "vector_memories:abc", Content: "Go is fun", Similarity: tt.similarity}}
+			tm := NewToolManager(st, constEmbedder{}, "")
+			tm.SetDuplicateThreshold(tt.threshold)
+
+			args := map[string]interface{}{"user_id": "alice", "content": "Go is fun!"}
+			for k, v := range tt.args {
+				args[k] = v
+			}
+			raw, _ := json.Marshal(args)
+			result, err := tm.addVectorHandler(context.Background(), &protocol.CallToolRequest{RawArguments: raw})
+			if err != nil {
+				t.Fatalf("add_vector: %v", err)
+			}
+			text := result.Content[0].(*protocol.TextContent).Text
+
+			if stored := len(st.indexed) == 1; stored != tt.stored {
+				t.Errorf("stored = %v, want %v (result %q)", stored, tt.stored, text)
+			}
+			if !tt.stored && (!strings.Contains(text, "duplicate") || !strings.Contains(text, "vector_memories:abc")) {
+				t.Errorf("result = %q, want the existing duplicate", text)
+			}
+			if merged := st.merged["topic"] == "go"; merged != tt.merged {
+				t.Errorf("merged metadata = %v, want merged %v", st.merged, tt.merged)
+			}
+		})
+	}
+}
+
+func TestFindDuplicateDocument(t *testing.T) {
+	chunk := func(path string, similarity float64) storage.DocumentResult {
+		return storage.DocumentResult{Document: &storage.Document{FilePath: path}, Similarity: similarity}
+	}
+	st := &duplicateStorage{docs: map[float32][]storage.DocumentResult{
+		1: {chunk("guide.md#chunk0", 0.99)},
+		2: {chunk("notes.md", 0.995), chunk("guide.md#chunk1", 0.98)},
+		3: {chunk("guide.md#chunk2", 0.5)},
+	}}
+	tm := NewToolManager(st, constEmbedder{}, "")
+	tm.SetDuplicateThreshold(0.97)
+	ctx := context.Background()
+
+	match, err := tm.findDuplicateDocument(ctx, "copy.md", [][]float32{{1}, {2}})
+	if err != nil || match == nil || match.FilePath != "guide.md" || match.Similarity != 0.98 {
+		t.Errorf("duplicate of copy.md = %+v, %v; want guide.md at 0.98", match, err)
+	}
+
+	// Saving the same path again is an update
+	if match, _ := tm.findDuplicateDocument(ctx, "guide.md", [][]float32{{1}, {2}}); match != nil {
+		t.Errorf("duplicate of guide.md itself = %+v, want none", match)
+	}
+
+	// Every chunk must match
+	if match, _ := tm.findDuplicateDocument(ctx, "copy.md", [][]float32{{1}, {3}}); match != nil {
+		t.Errorf("duplicate with an unmatched chunk = %+v, want none", match)
+	}
+}
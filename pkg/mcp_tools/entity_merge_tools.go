@@ -0,0 +1,213 @@
+package mcp_tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/ThinkInAIXYZ/go-mcp/protocol"
+	"github.com/madeindigio/remembrances-mcp/internal/storage"
+	"github.com/madeindigio/remembrances-mcp/pkg/embedder"
+)
+
+// Limits of remembrance_find_duplicate_entities.
+const (
+	defaultDuplicateEntityThreshold = 0.85
+	defaultDuplicateEntityPairs     = 20
+	maxDuplicateEntityPairs         = 100
+	maxDuplicateEntityScan          = 1000
+	// initialsSimilarity is the name similarity of names whose words all
+	// match, some only by their initial ("J. Smith" and "John Smith").
+	initialsSimilarity = 0.9
+)
+
+// duplicateEntityPair is a pair of entities that may be the same.
+type duplicateEntityPair struct {
+	A                   storage.PathEntity `json:"a"`
+	B                   storage.PathEntity `json:"b"`
+	NameSimilarity      float64            `json:"name_similarity"`
+	EmbeddingSimilarity float64            `json:"embedding_similarity,omitempty"`
+	Score               float64            `json:"score"`
+}
+
+func (tm *ToolManager) mergeEntitiesTool() *protocol.Tool {
+	tool, err := protocol.NewTool("remembrance_merge_entities", `Merge duplicate graph entities into one, moving their relationships and keeping their names as aliases. Use how_to_use("remembrance_merge_entities") for details.`, MergeEntitiesInput{})
+	if err != nil {
+		slog.Error("failed to create tool", "name", "remembrance_merge_entities", "err", err)
+		return nil
+	}
+	return tool
+}
+
+func (tm *ToolManager) findDuplicateEntitiesTool() *protocol.Tool {
+	tool, err := protocol.NewTool("remembrance_find_duplicate_entities", `Report pairs of graph entities that are likely duplicates, by name and embedding similarity. Use how_to_use("remembrance_find_duplicate_entities") for details.`, FindDuplicateEntitiesInput{})
+	if err != nil {
+		slog.Error("failed to create tool", "name", "remembrance_find_duplicate_entities", "err", err)
+		return nil
+	}
+	return tool
+}
+
+func (tm *ToolManager) mergeEntitiesHandler(ctx context.Context, request *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+	var input MergeEntitiesInput
+	if err := json.Unmarshal(request.RawArguments, &input); err != nil {
+		return nil, fmt.Errorf(errParseArgs, err)
+	}
+	if input.Survivor == "" || len(input.Duplicates) == 0 {
+		return nil, validationErrorf("survivor and duplicates are required")
+	}
+
+	merge, err := tm.storage.MergeEntities(ctx, input.Survivor, input.Duplicates)
+	if err != nil {
+		return nil, fmt.Errorf("failed to merge entities: %w", err)
+	}
+	return protocol.NewCallToolResult([]protocol.Content{
+		&protocol.TextContent{Type: "text", Text: MarshalTOON(merge)},
+	}, false), nil
+}
+
+func (tm *ToolManager) findDuplicateEntitiesHandler(ctx context.Context, request *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+	var input FindDuplicateEntitiesInput
+	if err := json.Unmarshal(request.RawArguments, &input); err != nil {
+		return nil, fmt.Errorf(errParseArgs, err)
+	}
+	if input.Threshold == 0 {
+		input.Threshold = defaultDuplicateEntityThreshold
+	}
+	if input.Threshold < 0 || input.Threshold > 1 {
+		return nil, validationErrorf("invalid threshold %g: must be between 0 and 1", input.Threshold)
+	}
+	if input.Limit == 0 {
+		input.Limit = defaultDuplicateEntityPairs
+	}
+	if input.Limit < 0 || input.Limit > maxDuplicateEntityPairs {
+		return nil, validationErrorf("invalid limit %d: must be between 1 and %d", input.Limit, maxDuplicateEntityPairs)
+	}
+
+	entities, err := tm.storage.ListEntities(ctx, input.EntityType, maxDuplicateEntityScan)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list entities: %w", err)
+	}
+
+	var embeddings [][]float32
+	if emb := tm.embedderFor(embedder.RouteVectors); emb != nil && len(entities) > 1 {
+		names := make([]string, len(entities))
+		for i, e := range entities {
+			names[i] = e.Name
+		}
+		if embeddings, err = emb.EmbedDocuments(ctx, names); err != nil {
+			slog.Warn("failed to embed entity names, comparing names only", "error", err)
+			embeddings = nil
+		}
+	}
+
+	pairs := duplicateEntityPairs(entities, embeddings, input.Threshold)
+	if len(pairs) == 0 {
+		payload := CreateEmptyResultTOON(
+			fmt.Sprintf("No likely duplicates among %d entities at threshold %.2f. Lower the threshold to compare less similar names", len(entities), input.Threshold),
+			AlternativeSuggestions{},
+		)
+		return protocol.NewCallToolResult([]protocol.Content{
+			&protocol.TextContent{Type: "text", Text: payload},
+		}, false), nil
+	}
+	total := len(pairs)
+	if len(pairs) > input.Limit {
+		pairs = pairs[:input.Limit]
+	}
+
+	response := map[string]interface{}{
+		"scanned":    len(entities),
+		"threshold":  input.Threshold,
+		"embeddings": embeddings != nil,
+		"count":      len(pairs),
+		"total":      total,
+		"pairs":      pairs,
+	}
+	if input.EntityType != "" {
+		response["entity_type"] = input.EntityType
+	}
+	return protocol.NewCallToolResult([]protocol.Content{
+		&protocol.TextContent{Type: "text", Text: MarshalTOON(response)},
+	}, false), nil
+}
+
+// duplicateEntityPairs returns the pairs of entities of the same type whose
+// name or embedding similarity reaches threshold, most similar first.
+// embeddings, when not nil, holds the embedding of each entity name.
+func duplicateEntityPairs(entities []storage.Entity, embeddings [][]float32, threshold float64) []duplicateEntityPair {
+	var pairs []duplicateEntityPair
+	for i := range entities {
+		for j := i + 1; j < len(entities); j++ {
+			a, b := entities[i], entities[j]
+			if a.Type != b.Type {
+				continue
+			}
+			pair := duplicateEntityPair{
+				A:              storage.PathEntity{ID: a.ID, Name: a.Name, Type: a.Type},
+				B:              storage.PathEntity{ID: b.ID, Name: b.Name, Type: b.Type},
+				NameSimilarity: nameSimilarity(a.Name, b.Name),
+			}
+			pair.Score = pair.NameSimilarity
+			if embeddings != nil {
+				pair.EmbeddingSimilarity = embedder.CosineSimilarity(embeddings[i], embeddings[j])
+				if pair.EmbeddingSimilarity > pair.Score {
+					pair.Score = pair.EmbeddingSimilarity
+				}
+			}
+			if pair.Score >= threshold {
+				pairs = append(pairs, pair)
+			}
+		}
+	}
+	sort.SliceStable(pairs, func(i, j int) bool {
+		return pairs[i].Score > pairs[j].Score
+	})
+	return pairs
+}
+
+// nameSimilarity scores how alike two entity names are, from 0 to 1: one
+// minus their edit distance over the length of the longer, raised to
+// initialsSimilarity when their words match but for initials.
+func nameSimilarity(a, b string) float64 {
+	wordsA, wordsB := nameWords(a), nameWords(b)
+	na, nb := strings.Join(wordsA, " "), strings.Join(wordsB, " ")
+	longest := len([]rune(na))
+	if n := len([]rune(nb)); n > longest {
+		longest = n
+	}
+	if longest == 0 {
+		return 0
+	}
+	similarity := 1 - float64(LevenshteinDistance(na, nb))/float64(longest)
+	if similarity < initialsSimilarity && (wordsMatch(wordsA, wordsB) || wordsMatch(wordsB, wordsA)) {
+		similarity = initialsSimilarity
+	}
+	return similarity
+}
+
+// nameWords splits a name into lowercase words, dropping punctuation.
+func nameWords(name string) []string {
+	return strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// wordsMatch reports whether words and other have as many words, at least
+// two, and each of words equals the word of other at its position or is its
+// initial.
+func wordsMatch(words, other []string) bool {
+	if len(words) < 2 || len(words) != len(other) {
+		return false
+	}
+	for i, w := range words {
+		if w != other[i] && !(len([]rune(w)) == 1 && strings.HasPrefix(other[i], w)) {
+			return false
+		}
+	}
+	return true
+}
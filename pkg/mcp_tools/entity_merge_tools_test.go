@@ -0,0 +1,50 @@
+package mcp_tools
+
+import (
+	"testing"
+
+	"github.com/madeindigio/remembrances-mcp/internal/storage"
+)
+
+func TestNameSimilarity(t *testing.T) {
+	for _, tc := range []struct {
+		a, b     string
+		min, max float64
+	}{
+		{"Alice Smith", "alice smith", 1, 1},
+		{"J. Smith", "John Smith", initialsSimilarity, initialsSimilarity},
+		{"Acme Corp", "Acme Corp.", 1, 1},
+		{"Acme Corp", "Acme Corporation", 0.5, 0.7},
+		{"Alice", "Bob", 0, 0.2},
+		{"", "", 0, 0},
+	} {
+		if got := nameSimilarity(tc.a, tc.b); got < tc.min || got > tc.max {
+			t.Errorf("nameSimilarity(%q, %q) = %.2f, want between %.2f and %.2f", tc.a, tc.b, got, tc.min, tc.max)
+		}
+	}
+}
+
+func TestDuplicateEntityPairs(t *testing.T) {
+	entities := []storage.Entity{
+		{ID: "1", Name: "John Smith", Type: "person"},
+		{ID: "2", Name: "J. Smith", Type: "person"},
+		{ID: "3", Name: "John Smith", Type: "project"},
+		{ID: "4", Name: "john smith", Type: "person"},
+	}
+	pairs := duplicateEntityPairs(entities, nil, 0.85)
+	if len(pairs) != 3 {
+		t.Fatalf("duplicateEntityPairs() = %+v, want the 3 person pairs", pairs)
+	}
+	if pairs[0].A.ID != "1" || pairs[0].B.ID != "4" {
+		t.Errorf("most similar pair = %s and %s, want 1 and 4", pairs[0].A.ID, pairs[0].B.ID)
+	}
+
+	embeddings := [][]float32{{1, 0}, {0, 1}}
+	if pairs := duplicateEntityPairs(entities[:2], embeddings, 0.95); len(pairs) != 0 {
+		t.Errorf("duplicateEntityPairs() with orthogonal embeddings = %+v, want none", pairs)
+	}
+	embeddings = [][]float32{{1, 0}, {1, 0.01}}
+	if pairs := duplicateEntityPairs(entities[:2], embeddings, 0.95); len(pairs) != 1 || pairs[0].Score < 0.95 {
+		t.Errorf("duplicateEntityPairs() with close embeddings = %+v, want the pair", pairs)
+	}
+}
@@ -0,0 +1,316 @@
+package mcp_tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sort"
+
+	"github.com/ThinkInAIXYZ/go-mcp/protocol"
+	"github.com/madeindigio/remembrances-mcp/internal/storage"
+)
+
+// Limits of the entity type tools.
+const (
+	maxEntityTypeScan      = 1000
+	maxNonconformingReport = 20
+)
+
+// nonconformingEntity is an entity whose properties do not match the schema
+// of its type.
+type nonconformingEntity struct {
+	ID       string   `json:"id"`
+	Name     string   `json:"name"`
+	Problems []string `json:"problems"`
+}
+
+// propertyUsage is how the entities of a type use one property.
+type propertyUsage struct {
+	Property string   `json:"property"`
+	Entities int      `json:"entities"`
+	Types    []string `json:"types"`
+}
+
+// entityTypeSummary is an entity type in the listing of
+// remembrance_describe_entity_type.
+type entityTypeSummary struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	HasSchema   bool   `json:"has_schema"`
+	EntityCount int    `json:"entity_count"`
+}
+
+func (tm *ToolManager) defineEntityTypeTool() *protocol.Tool {
+	tool, err := protocol.NewTool("remembrance_define_entity_type", `Define or delete the JSON schema the properties of an entity type must match. Use how_to_use("remembrance_define_entity_type") for details.`, DefineEntityTypeInput{})
+	if err != nil {
+		slog.Error("failed to create tool", "name", "remembrance_define_entity_type", "err", err)
+		return nil
+	}
+	return tool
+}
+
+func (tm *ToolManager) describeEntityTypeTool() *protocol.Tool {
+	tool, err := protocol.NewTool("remembrance_describe_entity_type", `Show the schema of an entity type and how its entities use their properties, or list the entity types. Use how_to_use("remembrance_describe_entity_type") for details.`, DescribeEntityTypeInput{})
+	if err != nil {
+		slog.Error("failed to create tool", "name", "remembrance_describe_entity_type", "err", err)
+		return nil
+	}
+	return tool
+}
+
+func (tm *ToolManager) updateEntityTool() *protocol.Tool {
+	tool, err := protocol.NewTool("update_entity", `Update the properties of an entity in the knowledge graph. Use how_to_use("update_entity") for details.`, UpdateEntityInput{})
+	if err != nil {
+		slog.Error("failed to create tool", "name", "update_entity", "err", err)
+		return nil
+	}
+	return tool
+}
+
+func (tm *ToolManager) defineEntityTypeHandler(ctx context.Context, request *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+	var input DefineEntityTypeInput
+	if err := json.Unmarshal(request.RawArguments, &input); err != nil {
+		return nil, fmt.Errorf(errParseArgs, err)
+	}
+	if input.Name == "" {
+		return nil, validationErrorf("name is required")
+	}
+
+	if input.Delete {
+		deleted, err := tm.storage.DeleteEntityType(ctx, input.Name)
+		if err != nil {
+			return nil, err
+		}
+		if !deleted {
+			return nil, notFoundErrorf("entity type %q has no schema", input.Name)
+		}
+		response := map[string]interface{}{
+			"entity_type": input.Name,
+			"deleted":     true,
+		}
+		return protocol.NewCallToolResult([]protocol.Content{
+			&protocol.TextContent{Type: "text", Text: MarshalTOON(response)},
+		}, false), nil
+	}
+
+	if len(input.Schema) == 0 {
+		return nil, validationErrorf("schema is required, unless delete is set")
+	}
+	if err := storage.CheckPropertySchema(input.Schema.AsMap()); err != nil {
+		return nil, validationErrorf("invalid schema: %v", err)
+	}
+	entityType := storage.EntityType{
+		Name:        input.Name,
+		Description: input.Description,
+		Schema:      input.Schema.AsMap(),
+	}
+	if err := tm.storage.SaveEntityType(ctx, entityType); err != nil {
+		return nil, fmt.Errorf("failed to save entity type: %w", err)
+	}
+
+	// The schema only applies to writes; report the entities it would reject
+	entities, err := tm.storage.ListEntities(ctx, input.Name, maxEntityTypeScan)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check existing entities: %w", err)
+	}
+	nonconforming := nonconformingEntities(entityType, entities)
+	response := map[string]interface{}{
+		"entity_type":         input.Name,
+		"saved":               true,
+		"entities_checked":    len(entities),
+		"nonconforming_count": len(nonconforming),
+	}
+	if len(nonconforming) > maxNonconformingReport {
+		nonconforming = nonconforming[:maxNonconformingReport]
+	}
+	if len(nonconforming) > 0 {
+		response["nonconforming"] = nonconforming
+	}
+	return protocol.NewCallToolResult([]protocol.Content{
+		&protocol.TextContent{Type: "text", Text: MarshalTOON(response)},
+	}, false), nil
+}
+
+func (tm *ToolManager) describeEntityTypeHandler(ctx context.Context, request *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+	var input DescribeEntityTypeInput
+	if err := json.Unmarshal(request.RawArguments, &input); err != nil {
+		return nil, fmt.Errorf(errParseArgs, err)
+	}
+	if input.Name == "" {
+		return tm.listEntityTypes(ctx)
+	}
+
+	entityType, err := tm.storage.GetEntityType(ctx, input.Name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get entity type: %w", err)
+	}
+	entities, err := tm.storage.ListEntities(ctx, input.Name, maxEntityTypeScan)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list entities: %w", err)
+	}
+	if entityType == nil && len(entities) == 0 {
+		payload := CreateEmptyResultTOON(fmt.Sprintf("No entity type '%s': it has no schema and no entities", input.Name), AlternativeSuggestions{})
+		return protocol.NewCallToolResult([]protocol.Content{
+			&protocol.TextContent{Type: "text", Text: payload},
+		}, false), nil
+	}
+
+	response := map[string]interface{}{
+		"entity_type":  input.Name,
+		"entity_count": len(entities),
+		"properties":   entityPropertyUsage(entities),
+	}
+	if entityType != nil {
+		response["description"] = entityType.Description
+		response["schema"] = entityType.Schema
+		response["nonconforming_count"] = len(nonconformingEntities(*entityType, entities))
+	}
+	if len(entities) == maxEntityTypeScan {
+		response["note"] = fmt.Sprintf("only the first %d entities by name were examined", maxEntityTypeScan)
+	}
+	return protocol.NewCallToolResult([]protocol.Content{
+		&protocol.TextContent{Type: "text", Text: MarshalTOON(response)},
+	}, false), nil
+}
+
+// listEntityTypes lists the entity types with a schema or with entities.
+func (tm *ToolManager) listEntityTypes(ctx context.Context) (*protocol.CallToolResult, error) {
+	defined, err := tm.storage.ListEntityTypes(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list entity types: %w", err)
+	}
+	stats, err := tm.storage.GetGraphStats(ctx, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count entities by type: %w", err)
+	}
+
+	summaries := map[string]*entityTypeSummary{}
+	for _, t := range defined {
+		summaries[t.Name] = &entityTypeSummary{Name: t.Name, Description: t.Description, HasSchema: true}
+	}
+	for name, count := range stats.EntitiesByType {
+		if summaries[name] == nil {
+			summaries[name] = &entityTypeSummary{Name: name}
+		}
+		summaries[name].EntityCount = count
+	}
+	types := make([]entityTypeSummary, 0, len(summaries))
+	for _, s := range summaries {
+		types = append(types, *s)
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i].Name < types[j].Name })
+
+	response := map[string]interface{}{
+		"count":        len(types),
+		"entity_types": types,
+	}
+	return protocol.NewCallToolResult([]protocol.Content{
+		&protocol.TextContent{Type: "text", Text: MarshalTOON(response)},
+	}, false), nil
+}
+
+func (tm *ToolManager) updateEntityHandler(ctx context.Context, request *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+	var input UpdateEntityInput
+	if err := json.Unmarshal(request.RawArguments, &input); err != nil {
+		return nil, fmt.Errorf(errParseArgs, err)
+	}
+	if input.Entity == "" {
+		return nil, validationErrorf("entity is required")
+	}
+	if len(input.Properties) == 0 && !input.Replace {
+		return nil, validationErrorf("properties is required, unless replace is set to clear them")
+	}
+
+	entity, err := tm.storage.UpdateEntity(ctx, input.Entity, input.Properties.AsMap(), input.Replace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update entity: %w", err)
+	}
+	response := map[string]interface{}{
+		"entity_id": entity.ID,
+		"entity":    entity,
+	}
+	return protocol.NewCallToolResult([]protocol.Content{
+		&protocol.TextContent{Type: "text", Text: MarshalTOON(response)},
+	}, false), nil
+}
+
+// nonconformingEntities returns the entities whose properties do not match
+// the schema of entityType.
+func nonconformingEntities(entityType storage.EntityType, entities []storage.Entity) []nonconformingEntity {
+	var nonconforming []nonconformingEntity
+	for _, e := range entities {
+		if problems := entityType.Validate(e.Properties); len(problems) > 0 {
+			nonconforming = append(nonconforming, nonconformingEntity{ID: e.ID, Name: e.Name, Problems: problems})
+		}
+	}
+	return nonconforming
+}
+
+// entityPropertyUsage returns, for each property of entities, how many of
+// them have it and the JSON types of its values, most used first. Aliases
+// are left out.
+func entityPropertyUsage(entities []storage.Entity) []propertyUsage {
+	counts := map[string]int{}
+	types := map[string]map[string]bool{}
+	for _, e := range entities {
+		for key, value := range e.Properties {
+			if key == storage.AliasesProperty {
+				continue
+			}
+			counts[key]++
+			if types[key] == nil {
+				types[key] = map[string]bool{}
+			}
+			types[key][jsonTypeOf(value)] = true
+		}
+	}
+
+	usage := make([]propertyUsage, 0, len(counts))
+	for key, count := range counts {
+		u := propertyUsage{Property: key, Entities: count}
+		for t := range types[key] {
+			u.Types = append(u.Types, t)
+		}
+		sort.Strings(u.Types)
+		usage = append(usage, u)
+	}
+	sort.Slice(usage, func(i, j int) bool {
+		if usage[i].Entities != usage[j].Entities {
+			return usage[i].Entities > usage[j].Entities
+		}
+		return usage[i].Property < usage[j].Property
+	})
+	return usage
+}
+
+// jsonTypeOf returns the JSON schema type of a decoded value.
+func jsonTypeOf(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case json.Number:
+		if _, err := v.Int64(); err == nil {
+			return "integer"
+		}
+		return "number"
+	case float64:
+		if v == float64(int64(v)) {
+			return "integer"
+		}
+		return "number"
+	case float32:
+		return "number"
+	case int, int32, int64, uint, uint32, uint64:
+		return "integer"
+	}
+	return fmt.Sprintf("%T", value)
+}
@@ -0,0 +1,65 @@
+package mcp_tools
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/madeindigio/remembrances-mcp/internal/storage"
+)
+
+func TestEntityPropertyUsage(t *testing.T) {
+	entities := []storage.Entity{
+		{Name: "Alice", Properties: map[string]interface{}{"email": "a@example.com", "age": json.Number("34"), storage.AliasesProperty: []interface{}{"Al"}}},
+		{Name: "Bob", Properties: map[string]interface{}{"email": "b@example.com", "age": "forty"}},
+		{Name: "Carol", Properties: map[string]interface{}{"team": "core"}},
+	}
+	want := []propertyUsage{
+		{Property: "age", Entities: 2, Types: []string{"integer", "string"}},
+		{Property: "email", Entities: 2, Types: []string{"string"}},
+		{Property: "team", Entities: 1, Types: []string{"string"}},
+	}
+	if got := entityPropertyUsage(entities); !reflect.DeepEqual(got, want) {
+		t.Errorf("entityPropertyUsage() = %v, want %v", got, want)
+	}
+}
+
+func TestNonconformingEntities(t *testing.T) {
+	person := storage.EntityType{Name: "person", Schema: map[string]interface{}{
+		"type":     "object",
+		"required": []interface{}{"email"},
+	}}
+	entities := []storage.Entity{
+		{ID: "1", Name: "Alice", Properties: map[string]interface{}{"email": "a@example.com"}},
+		{ID: "2", Name: "Bob"},
+	}
+	got := nonconformingEntities(person, entities)
+	if len(got) != 1 || got[0].Name != "Bob" || len(got[0].Problems) != 1 {
+		t.Errorf("nonconformingEntities() = %v, want Bob missing email", got)
+	}
+}
+
+func TestEntityTypeToolsValidation(t *testing.T) {
+	tm := NewToolManager(nil, nil, "")
+	for _, args := range []map[string]interface{}{
+		{"schema": map[string]interface{}{"type": "object"}},
+		{"name": "person"},
+		{"name": "person", "schema": map[string]interface{}{"type": "string"}},
+		{"name": "person", "schema": map[string]interface{}{"properties": map[string]interface{}{"age": map[string]interface{}{"type": "int"}}}},
+	} {
+		if _, err := callWorkingMemory(t, tm.defineEntityTypeHandler, args); errorCode(err) != ErrCodeValidation {
+			t.Errorf("define %v error = %v, want a validation error", args, err)
+		}
+	}
+	for _, args := range []map[string]interface{}{
+		{"properties": map[string]interface{}{"role": "lead"}},
+		{"entity": "Alice"},
+	} {
+		if _, err := callWorkingMemory(t, tm.updateEntityHandler, args); errorCode(err) != ErrCodeValidation {
+			t.Errorf("update %v error = %v, want a validation error", args, err)
+		}
+	}
+	if code := errorCode(&storage.PropertySchemaError{EntityType: "person", Problems: []string{"email is required"}}); code != ErrCodeValidation {
+		t.Errorf("errorCode(PropertySchemaError) = %s, want %s", code, ErrCodeValidation)
+	}
+}
@@ -0,0 +1,137 @@
+package mcp_tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/ThinkInAIXYZ/go-mcp/protocol"
+	"github.com/madeindigio/remembrances-mcp/internal/kb"
+	"github.com/madeindigio/remembrances-mcp/internal/storage"
+	"github.com/madeindigio/remembrances-mcp/pkg/redact"
+)
+
+// Error codes returned in the "code" field of every failed tool result, so
+// clients can branch on the kind of failure instead of parsing the message.
+const (
+	ErrCodeNotFound           = "NOT_FOUND"           // The referenced record, project or file does not exist
+	ErrCodeValidation         = "VALIDATION"          // The arguments are missing, malformed or rejected
+	ErrCodeStorageUnavailable = "STORAGE_UNAVAILABLE" // The database cannot be reached
+	ErrCodeEmbedderFailed     = "EMBEDDER_FAILED"     // The embedding model failed to embed the content
+	ErrCodeConflict           = "CONFLICT"            // The record changed concurrently; re-read and retry
+	ErrCodePinned             = "PINNED"              // The record is pinned; unpin it or pass force to delete it
+	ErrCodeInternal           = "INTERNAL"            // Any other failure
+)
+
+// ToolError is an error with the code reported to clients. Handlers return it
+// through validationErrorf, notFoundErrorf and embedderErrorf; errors without
+// one are classified by errorCode.
+type ToolError struct {
+	Code string
+	Err  error
+}
+
+func (e *ToolError) Error() string { return e.Err.Error() }
+
+func (e *ToolError) Unwrap() error { return e.Err }
+
+// validationErrorf returns a VALIDATION error formatted like fmt.Errorf.
+func validationErrorf(format string, args ...interface{}) error {
+	return &ToolError{Code: ErrCodeValidation, Err: fmt.Errorf(format, args...)}
+}
+
+// notFoundErrorf returns a NOT_FOUND error formatted like fmt.Errorf.
+func notFoundErrorf(format string, args ...interface{}) error {
+	return &ToolError{Code: ErrCodeNotFound, Err: fmt.Errorf(format, args...)}
+}
+
+// embedderErrorf returns an EMBEDDER_FAILED error formatted like fmt.Errorf.
+func embedderErrorf(format string, args ...interface{}) error {
+	return &ToolError{Code: ErrCodeEmbedderFailed, Err: fmt.Errorf(format, args...)}
+}
+
+// errorCode classifies err into one of the ErrCode* values.
+func errorCode(err error) string {
+	var toolErr *ToolError
+	if errors.As(err, &toolErr) {
+		return toolErr.Code
+	}
+
+	var conflict *storage.RevisionConflictError
+	if errors.As(err, &conflict) || errors.Is(err, kb.ErrConflict) {
+		return ErrCodeConflict
+	}
+
+	var dimErr *storage.EmbeddingDimensionError
+	var batchErr *storage.BatchError
+	var schemaErr *storage.PropertySchemaError
+	var rejected *redact.RejectedError
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &dimErr), errors.As(err, &batchErr), errors.As(err, &schemaErr), errors.As(err, &rejected),
+		errors.As(err, &syntaxErr), errors.As(err, &typeErr):
+		return ErrCodeValidation
+	case errors.Is(err, storage.ErrPinned):
+		return ErrCodePinned
+	case errors.Is(err, storage.ErrNotFound):
+		return ErrCodeNotFound
+	case errors.Is(err, storage.ErrUnavailable):
+		return ErrCodeStorageUnavailable
+	}
+	return ErrCodeInternal
+}
+
+// errorResult reports err as a failed tool result with its code and message.
+func errorResult(err error) *protocol.CallToolResult {
+	response := map[string]interface{}{
+		"code":    errorCode(err),
+		"message": err.Error(),
+	}
+	return protocol.NewCallToolResult([]protocol.Content{
+		&protocol.TextContent{Type: "text", Text: MarshalTOON(response)},
+	}, true)
+}
+
+// ResultErrorCode returns the code of a failed tool result built by
+// errorResult, or "" when the result succeeded or carries no code.
+func ResultErrorCode(result *protocol.CallToolResult) string {
+	if result == nil || !result.IsError {
+		return ""
+	}
+	for _, content := range result.Content {
+		text, ok := content.(*protocol.TextContent)
+		if !ok {
+			continue
+		}
+		for _, line := range strings.Split(text.Text, "\n") {
+			if code, ok := strings.CutPrefix(line, "code: "); ok {
+				return code
+			}
+		}
+	}
+	return ""
+}
+
+// withErrorCodes wraps every handler registered through reg so that errors
+// are returned as failed tool results carrying an error code, instead of
+// protocol errors that only carry a message. It also records each tool in
+// the catalog documented by how_to_use, and the provenance of the memories
+// each tool writes.
+func withErrorCodes(reg func(string, *protocol.Tool, func(context.Context, *protocol.CallToolRequest) (*protocol.CallToolResult, error)) error) func(string, *protocol.Tool, func(context.Context, *protocol.CallToolRequest) (*protocol.CallToolResult, error)) error {
+	return func(name string, tool *protocol.Tool, handler func(context.Context, *protocol.CallToolRequest) (*protocol.CallToolResult, error)) error {
+		catalogTool(name, tool)
+		handler = withProvenance(name, handler)
+		return reg(name, tool, func(ctx context.Context, request *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+			result, err := handler(ctx, request)
+			if err != nil {
+				slog.Debug("tool failed", "name", name, "code", errorCode(err), "error", err)
+				return errorResult(err), nil
+			}
+			return result, nil
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package mcp_tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/ThinkInAIXYZ/go-mcp/protocol"
+	"github.com/madeindigio/remembrances-mcp/internal/kb"
+	"github.com/madeindigio/remembrances-mcp/internal/storage"
+	"github.com/madeindigio/remembrances-mcp/pkg/redact"
+)
+
+func TestErrorCode(t *testing.T) {
+	var parsed map[string]interface{}
+	parseErr := json.Unmarshal([]byte("{"), &parsed)
+
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"validation", validationErrorf("user_id is required"), ErrCodeValidation},
+		{"wrapped validation", fmt.Errorf("outer: %w", validationErrorf("bad")), ErrCodeValidation},
+		{"not found", notFoundErrorf("project not found: %s", "p1"), ErrCodeNotFound},
+		{"embedder", embedderErrorf(errGenEmbedding, errors.New("connection refused")), ErrCodeEmbedderFailed},
+		{"parse arguments", fmt.Errorf(errParseArgs, parseErr), ErrCodeValidation},
+		{"revision conflict", fmt.Errorf("failed: %w", &storage.RevisionConflictError{ID: "v:1", Expected: 1, Current: 2}), ErrCodeConflict},
+		{"kb conflict", fmt.Errorf("sync: %w", kb.ErrConflict), ErrCodeConflict},
+		{"embedding dimension", &storage.EmbeddingDimensionError{Table: "vector_memories", Expected: 768, Got: 1024}, ErrCodeValidation},
+		{"redaction", &redact.RejectedError{}, ErrCodeValidation},
+		{"storage not found", fmt.Errorf("failed to delete fact: %w", fmt.Errorf("fact %w", storage.ErrNotFound)), ErrCodeNotFound},
+		{"pinned", fmt.Errorf("failed to delete fact: %w", fmt.Errorf("%w: fact \"k\" is pinned", storage.ErrPinned)), ErrCodePinned},
+		{"storage unavailable", fmt.Errorf("failed to save fact: %w", storage.ErrUnavailable), ErrCodeStorageUnavailable},
+		{"other", errors.New("boom"), ErrCodeInternal},
+	}
+	for _, tt := range tests {
+		if got := errorCode(tt.err); got != tt.want {
+			t.Errorf("%s: errorCode() = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestWithErrorCodes(t *testing.T) {
+	handlers := map[string]func(context.Context, *protocol.CallToolRequest) (*protocol.CallToolResult, error){}
+	reg := withErrorCodes(func(name string, tool *protocol.Tool, handler func(context.Context, *protocol.CallToolRequest) (*protocol.CallToolResult, error)) error {
+		handlers[name] = handler
+		return nil
+	})
+
+	ok := protocol.NewCallToolResult([]protocol.Content{&protocol.TextContent{Type: "text", Text: "done"}}, false)
+	_ = reg("ok", &protocol.Tool{}, func(context.Context, *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+		return ok, nil
+	})
+	_ = reg("fails", &protocol.Tool{}, func(context.Context, *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+		return nil, validationErrorf("user_id is required")
+	})
+
+	result, err := handlers["ok"](context.Background(), &protocol.CallToolRequest{})
+	if err != nil || result != ok {
+		t.Fatalf("successful handler: result = %v, err = %v", result, err)
+	}
+
+	result, err = handlers["fails"](context.Background(), &protocol.CallToolRequest{})
+	if err != nil {
+		t.Fatalf("failing handler returned a protocol error: %v", err)
+	}
+	if result == nil || !result.IsError || len(result.Content) != 1 {
+		t.Fatalf("failing handler: expected one error content, got %+v", result)
+	}
+	text := result.Content[0].(*protocol.TextContent).Text
+	if !strings.Contains(text, ErrCodeValidation) || !strings.Contains(text, "user_id is required") {
+		t.Errorf("error result does not carry the code and message: %q", text)
+	}
+	if code := ResultErrorCode(result); code != ErrCodeValidation {
+		t.Errorf("ResultErrorCode = %q, want %s", code, ErrCodeValidation)
+	}
+	if code := ResultErrorCode(ok); code != "" {
+		t.Errorf("ResultErrorCode of a successful result = %q, want none", code)
+	}
+}
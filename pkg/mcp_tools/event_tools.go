@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/madeindigio/remembrances-mcp/internal/storage"
+	"github.com/madeindigio/remembrances-mcp/pkg/embedder"
 
 	"github.com/ThinkInAIXYZ/go-mcp/protocol"
 )
@@ -41,12 +42,12 @@ func (tm *ToolManager) saveEventHandler(ctx context.Context, request *protocol.C
 	}
 
 	// Generate embedding for content
-	embedding, err := tm.embedder.EmbedDocuments(ctx, []string{input.Content})
+	embedding, err := tm.embedderFor(embedder.RouteEvents).EmbedDocuments(ctx, []string{input.Content})
 	if err != nil {
-		return nil, fmt.Errorf(errGenEmbedding, err)
+		return nil, embedderErrorf(errGenEmbedding, err)
 	}
 	if len(embedding) == 0 {
-		return nil, fmt.Errorf("failed to generate embedding: empty result")
+		return nil, embedderErrorf("failed to generate embedding: empty result")
 	}
 
 	// Save event
@@ -115,7 +116,7 @@ func (tm *ToolManager) searchEventsHandler(ctx context.Context, request *protoco
 
 	// Generate query embedding if query is provided
 	if input.Query != "" {
-		embedding, err := tm.embedder.EmbedQuery(ctx, input.Query)
+		embedding, err := tm.embedderFor(embedder.RouteEvents).EmbedQuery(ctx, input.Query)
 		if err != nil {
 			slog.Warn("failed to generate query embedding, falling back to text search", "err", err)
 		} else {
@@ -129,6 +130,8 @@ func (tm *ToolManager) searchEventsHandler(ctx context.Context, request *protoco
 		return nil, fmt.Errorf("failed to search events: %w", err)
 	}
 
+	reportSearchItems(ctx, eventSearchItems(results))
+
 	// Format results
 	output := make([]map[string]interface{}, len(results))
 	for i, r := range results {
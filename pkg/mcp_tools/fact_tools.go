@@ -5,8 +5,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"time"
 
 	"github.com/ThinkInAIXYZ/go-mcp/protocol"
+	"github.com/madeindigio/remembrances-mcp/internal/storage"
 )
 
 // Fact tool definitions
@@ -53,7 +55,16 @@ func (tm *ToolManager) saveFactHandler(ctx context.Context, request *protocol.Ca
 		return nil, fmt.Errorf(errParseArgs, err)
 	}
 
-	err := tm.storage.SaveFact(ctx, input.UserID, input.Key, input.Value)
+	ctx, err := withValidity(ctx, input.ValidityInput)
+	if err != nil {
+		return nil, err
+	}
+	value, err := tm.redactContent("save_fact", input.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tm.storage.SaveFact(ctx, input.UserID, input.Key, value)
 	if err != nil {
 		return nil, fmt.Errorf("failed to save fact: %w", err)
 	}
@@ -71,27 +82,59 @@ func (tm *ToolManager) getFactHandler(ctx context.Context, request *protocol.Cal
 	if err := json.Unmarshal(request.RawArguments, &input); err != nil {
 		return nil, fmt.Errorf(errParseArgs, err)
 	}
-
-	value, err := tm.storage.GetFact(ctx, input.UserID, input.Key)
+	asOf, err := parseTimeArg("as_of", input.AsOf)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get fact: %w", err)
+		return nil, err
+	}
+
+	var value interface{}
+	var versions []storage.FactVersion
+	if input.History || !asOf.IsZero() {
+		versions, err = tm.storage.GetFactHistory(ctx, input.UserID, input.Key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get fact history: %w", err)
+		}
+		if !asOf.IsZero() {
+			if v := storage.FactAt(versions, asOf); v != nil {
+				value = v.Value
+			}
+		} else if len(versions) > 0 && versions[0].Current {
+			value = versions[0].Value
+		}
+	} else {
+		value, err = tm.storage.GetFact(ctx, input.UserID, input.Key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get fact: %w", err)
+		}
 	}
 
-	if value == nil {
+	// The history of a deleted fact is still returned
+	if value == nil && (!input.History || len(versions) == 0) {
+		message := fmt.Sprintf("No fact found for key '%s' and user '%s'", input.Key, input.UserID)
+		if !asOf.IsZero() {
+			message += " as of " + asOf.Format(time.RFC3339)
+		}
 		suggestions := tm.FindKeyAlternatives(ctx, input.UserID, "kv_memories", input.Key)
-		payload := CreateEmptyResultTOON(
-			fmt.Sprintf("No fact found for key '%s' and user '%s'", input.Key, input.UserID),
-			suggestions,
-		)
+		payload := CreateEmptyResultTOON(message, suggestions)
 		return protocol.NewCallToolResult([]protocol.Content{
 			&protocol.TextContent{Type: "text", Text: payload},
 		}, false), nil
 	}
 
+	tm.recordHits(ctx, "get_fact", []storage.MemoryHit{{Kind: storage.TrashKindFact, Ref: input.Key, UserID: input.UserID}})
+
 	response := map[string]interface{}{
 		"user_id": input.UserID,
 		"key":     input.Key,
-		"value":   value,
+	}
+	if value != nil {
+		response["value"] = value
+	}
+	if !asOf.IsZero() {
+		response["as_of"] = asOf.Format(time.RFC3339)
+	}
+	if input.History {
+		response["versions"] = versions
 	}
 
 	return protocol.NewCallToolResult([]protocol.Content{
@@ -105,26 +148,69 @@ func (tm *ToolManager) listFactsHandler(ctx context.Context, request *protocol.C
 		return nil, fmt.Errorf(errParseArgs, err)
 	}
 
-	facts, err := tm.storage.ListFacts(ctx, input.UserID)
+	if err := checkMinConfidence(input.MinConfidence); err != nil {
+		return nil, err
+	}
+	asOf, err := parseTimeArg("as_of", input.AsOf)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list facts: %w", err)
+		return nil, err
 	}
 
+	var facts map[string]interface{}
+	var provenance map[string]storage.Provenance
+	if asOf.IsZero() {
+		facts, err = tm.storage.ListFacts(ctx, input.UserID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list facts: %w", err)
+		}
+		provenance, err = tm.storage.ListFactProvenance(ctx, input.UserID)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		versions, err := tm.storage.ListFactsAsOf(ctx, input.UserID, asOf)
+		if err != nil {
+			return nil, err
+		}
+		facts, provenance = factVersionsByKey(versions)
+	}
+	facts = confidentFacts(facts, provenance, input.MinConfidence)
+
 	if len(facts) == 0 {
+		message := fmt.Sprintf("No facts found for user '%s'", input.UserID)
+		if input.MinConfidence > 0 {
+			message = fmt.Sprintf("No facts with confidence of at least %g found for user '%s'", input.MinConfidence, input.UserID)
+		}
+		if !asOf.IsZero() {
+			message += " as of " + asOf.Format(time.RFC3339)
+		}
 		suggestions := tm.FindUserAlternatives(ctx, "kv_memories", input.UserID)
-		payload := CreateEmptyResultTOON(
-			fmt.Sprintf("No facts found for user '%s'", input.UserID),
-			suggestions,
-		)
+		payload := CreateEmptyResultTOON(message, suggestions)
 		return protocol.NewCallToolResult([]protocol.Content{
 			&protocol.TextContent{Type: "text", Text: payload},
 		}, false), nil
 	}
 
-	response := map[string]interface{}{
-		"user_id": input.UserID,
-		"count":   len(facts),
-		"facts":   facts,
+	pinned, err := tm.storage.ListPinned(ctx, input.UserID, storage.TrashKindFact)
+	if err != nil {
+		return nil, err
+	}
+	response := factList{UserID: input.UserID, Count: len(facts), Facts: facts}
+	if !asOf.IsZero() {
+		response.AsOf = asOf.Format(time.RFC3339)
+	}
+	for _, item := range pinned {
+		if value, ok := facts[item.Ref]; ok {
+			response.Pinned = append(response.Pinned, pinnedFact{Key: item.Ref, Value: value})
+		}
+	}
+	for key := range facts {
+		if p, ok := provenance[key]; ok {
+			if response.Provenance == nil {
+				response.Provenance = map[string]storage.Provenance{}
+			}
+			response.Provenance[key] = p
+		}
 	}
 
 	return protocol.NewCallToolResult([]protocol.Content{
@@ -132,6 +218,22 @@ func (tm *ToolManager) listFactsHandler(ctx context.Context, request *protocol.C
 	}, false), nil
 }
 
+// factList is the list_facts response. Pinned facts come first; they are
+// listed in facts too. Provenance holds the facts saved with one.
+type factList struct {
+	UserID     string                        `json:"user_id"`
+	AsOf       string                        `json:"as_of,omitempty"`
+	Count      int                           `json:"count"`
+	Pinned     []pinnedFact                  `json:"pinned,omitempty"`
+	Facts      map[string]interface{}        `json:"facts"`
+	Provenance map[string]storage.Provenance `json:"provenance,omitempty"`
+}
+
+type pinnedFact struct {
+	Key   string      `json:"key"`
+	Value interface{} `json:"value"`
+}
+
 func (tm *ToolManager) deleteFactHandler(ctx context.Context, request *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
 	var input DeleteFactInput
 	if err := json.Unmarshal(request.RawArguments, &input); err != nil {
@@ -155,6 +257,9 @@ func (tm *ToolManager) deleteFactHandler(ctx context.Context, request *protocol.
 		}, false), nil
 	}
 
+	if input.Force {
+		ctx = storage.WithForce(ctx)
+	}
 	err = tm.storage.DeleteFact(ctx, input.UserID, input.Key)
 	if err != nil {
 		return nil, fmt.Errorf("failed to delete fact: %w", err)
@@ -163,7 +268,7 @@ func (tm *ToolManager) deleteFactHandler(ctx context.Context, request *protocol.
 	return protocol.NewCallToolResult([]protocol.Content{
 		&protocol.TextContent{
 			Type: "text",
-			Text: fmt.Sprintf("Successfully deleted fact '%s' for user '%s'", input.Key, input.UserID),
+			Text: fmt.Sprintf("Successfully deleted fact '%s' for user '%s' (moved to trash, restorable with remembrance_restore)", input.Key, input.UserID),
 		},
 	}, false), nil
 }
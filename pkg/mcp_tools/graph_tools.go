@@ -5,8 +5,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"time"
 
 	"github.com/ThinkInAIXYZ/go-mcp/protocol"
+	"github.com/madeindigio/remembrances-mcp/internal/storage"
 )
 
 // Graph tool definitions
@@ -28,6 +30,15 @@ func (tm *ToolManager) createRelationshipTool() *protocol.Tool {
 	return tool
 }
 
+func (tm *ToolManager) endRelationshipTool() *protocol.Tool {
+	tool, err := protocol.NewTool("end_relationship", `End a relationship between two entities, keeping it as history. Use how_to_use("end_relationship") for details.`, EndRelationshipInput{})
+	if err != nil {
+		slog.Error("failed to create tool", "name", "end_relationship", "err", err)
+		return nil
+	}
+	return tool
+}
+
 func (tm *ToolManager) traverseGraphTool() *protocol.Tool {
 	tool, err := protocol.NewTool("traverse_graph", `Traverse the knowledge graph from a start entity. Use how_to_use("traverse_graph") for details.`, TraverseGraphInput{})
 	if err != nil {
@@ -71,6 +82,10 @@ func (tm *ToolManager) createRelationshipHandler(ctx context.Context, request *p
 	if err := json.Unmarshal(request.RawArguments, &input); err != nil {
 		return nil, fmt.Errorf(errParseArgs, err)
 	}
+	ctx, err := withValidity(ctx, input.ValidityInput)
+	if err != nil {
+		return nil, err
+	}
 
 	// Validate existence of source entity
 	fromEntity, err := tm.storage.GetEntity(ctx, input.FromEntity)
@@ -117,11 +132,48 @@ func (tm *ToolManager) createRelationshipHandler(ctx context.Context, request *p
 	}, false), nil
 }
 
+func (tm *ToolManager) endRelationshipHandler(ctx context.Context, request *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+	var input EndRelationshipInput
+	if err := json.Unmarshal(request.RawArguments, &input); err != nil {
+		return nil, fmt.Errorf(errParseArgs, err)
+	}
+	if input.FromEntity == "" || input.ToEntity == "" || input.RelationshipType == "" {
+		return nil, validationErrorf("from_entity, to_entity and relationship_type are required")
+	}
+	at, err := parseTimeArg("at", input.At)
+	if err != nil {
+		return nil, err
+	}
+	if at.IsZero() {
+		at = time.Now().UTC()
+	}
+
+	ended, err := tm.storage.EndRelationships(ctx, input.FromEntity, input.ToEntity, input.RelationshipType, at)
+	if err != nil {
+		return nil, fmt.Errorf("failed to end relationship: %w", err)
+	}
+
+	response := map[string]interface{}{
+		"from_entity":       input.FromEntity,
+		"to_entity":         input.ToEntity,
+		"relationship_type": input.RelationshipType,
+		"at":                at.Format(time.RFC3339),
+		"ended":             ended,
+	}
+	return protocol.NewCallToolResult([]protocol.Content{
+		&protocol.TextContent{Type: "text", Text: MarshalTOON(response)},
+	}, false), nil
+}
+
 func (tm *ToolManager) traverseGraphHandler(ctx context.Context, request *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
 	var input TraverseGraphInput
 	if err := json.Unmarshal(request.RawArguments, &input); err != nil {
 		return nil, fmt.Errorf(errParseArgs, err)
 	}
+	asOf, err := parseTimeArg("as_of", input.AsOf)
+	if err != nil {
+		return nil, err
+	}
 
 	if input.Depth == 0 {
 		input.Depth = 2
@@ -143,10 +195,16 @@ func (tm *ToolManager) traverseGraphHandler(ctx context.Context, request *protoc
 		}, false), nil
 	}
 
-	results, err := tm.storage.TraverseGraph(ctx, input.StartEntity, input.RelationshipType, input.Depth)
+	var results []storage.GraphResult
+	if asOf.IsZero() {
+		results, err = tm.storage.TraverseGraph(ctx, input.StartEntity, input.RelationshipType, input.Depth)
+	} else {
+		results, err = tm.storage.TraverseGraphAsOf(ctx, input.StartEntity, input.RelationshipType, input.Depth, asOf)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to traverse graph: %w", err)
 	}
+	reportSearchItems(ctx, graphSearchItems(results))
 
 	response := map[string]interface{}{
 		"start_entity":      input.StartEntity,
@@ -155,6 +213,9 @@ func (tm *ToolManager) traverseGraphHandler(ctx context.Context, request *protoc
 		"count":             len(results),
 		"results":           results,
 	}
+	if !asOf.IsZero() {
+		response["as_of"] = asOf.Format(time.RFC3339)
+	}
 
 	if len(results) == 0 {
 		suggestions := tm.FindEntityAlternatives(ctx, input.StartEntity)
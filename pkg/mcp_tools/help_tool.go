@@ -7,7 +7,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"sort"
 	"strings"
+	"sync"
 
 	"github.com/ThinkInAIXYZ/go-mcp/protocol"
 )
@@ -17,7 +19,57 @@ var docsFS embed.FS
 
 // HowToUseInput represents input for how_to_use tool
 type HowToUseInput struct {
-	Topic string `json:"topic,omitempty" description:"Optional topic: 'memory', 'kb', 'events', 'code', or a specific tool name. If omitted, returns overview."`
+	Topic string `json:"topic,omitempty" description:"Optional topic: 'memory', 'kb', 'events', 'code', 'errors', or a specific tool name. If omitted, returns overview."`
+}
+
+// toolCatalog holds the definition of every registered tool, so how_to_use
+// can document argument schemas from the same metadata clients see.
+var toolCatalog = struct {
+	sync.RWMutex
+	tools map[string]*protocol.Tool
+}{tools: map[string]*protocol.Tool{}}
+
+// catalogTool records a registered tool definition.
+func catalogTool(name string, tool *protocol.Tool) {
+	if tool == nil {
+		return
+	}
+	toolCatalog.Lock()
+	toolCatalog.tools[name] = tool
+	toolCatalog.Unlock()
+}
+
+// catalogedTool returns the registered definition of a tool.
+func catalogedTool(name string) (*protocol.Tool, bool) {
+	toolCatalog.RLock()
+	defer toolCatalog.RUnlock()
+	tool, ok := toolCatalog.tools[name]
+	return tool, ok
+}
+
+// catalogedToolNames returns the names of all registered tools, sorted.
+func catalogedToolNames() []string {
+	toolCatalog.RLock()
+	defer toolCatalog.RUnlock()
+	names := make([]string, 0, len(toolCatalog.tools))
+	for name := range toolCatalog.tools {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// errorRemedies describes what a client should do about each error code.
+var errorRemedies = []struct {
+	code   string
+	remedy string
+}{
+	{ErrCodeValidation, "Fix the arguments named in the message and call again."},
+	{ErrCodeNotFound, "Check the id, key or path; list the existing records first if unsure."},
+	{ErrCodeConflict, "The record changed since it was read. Read it again and retry with the new revision."},
+	{ErrCodeStorageUnavailable, "The database is not reachable. Retry later; the server logs have the cause."},
+	{ErrCodeEmbedderFailed, "The embedding model failed. Retry later or with shorter content."},
+	{ErrCodeInternal, "Unexpected failure. Retry once; report it if it persists."},
 }
 
 // howToUseTool creates the how_to_use tool definition
@@ -53,9 +105,11 @@ func (tm *ToolManager) howToUseHandler(ctx context.Context, request *protocol.Ca
 		content, err = readDocFile("docs/events_group.txt")
 	case "code", "indexing", "code_indexing":
 		content, err = readDocFile("docs/code_group.txt")
+	case "errors", "error", "error_codes":
+		content = errorCodesDoc()
 	default:
 		// Try to find a specific tool documentation
-		content, err = readDocFile(fmt.Sprintf("docs/tools/%s.txt", topic))
+		content, err = toolDoc(topic)
 		if err != nil {
 			// Tool not found, provide helpful error
 			content = fmt.Sprintf(`Unknown topic: "%s"
@@ -65,9 +119,13 @@ Available topics:
   - "kb" or "knowledge_base": Knowledge base tools
   - "events" or "logs": Events and temporal storage tools
   - "code" or "indexing": Code indexing and search tools
+  - "errors": Error codes returned by failed tool calls
   - Or specify a tool name like "save_fact", "save_event", etc.
 
 Call how_to_use() with no arguments for a complete overview.`, topic)
+			if names := catalogedToolNames(); len(names) > 0 {
+				content += "\n\nRegistered tools:\n  " + strings.Join(names, ", ")
+			}
 			err = nil
 		}
 	}
@@ -92,3 +150,186 @@ func readDocFile(path string) (string, error) {
 	}
 	return string(data), nil
 }
+
+// toolDoc returns the documentation of a tool: its doc file, or its
+// registered description when it has none, followed by the argument schema,
+// an example call when the doc file has none, and the error remedies. It
+// fails only when the tool is neither documented nor registered.
+func toolDoc(name string) (string, error) {
+	doc, docErr := readDocFile(fmt.Sprintf("docs/tools/%s.txt", name))
+	tool, registered := catalogedTool(name)
+	if !registered {
+		return doc, docErr
+	}
+
+	var b strings.Builder
+	if docErr == nil {
+		b.WriteString(strings.TrimRight(doc, "\n"))
+		b.WriteString("\n")
+	} else {
+		writeDocSection(&b, "TOOL: "+name, "=")
+		b.WriteString("\nPURPOSE\n-------\n")
+		b.WriteString(toolPurpose(tool.Description))
+		b.WriteString("\n")
+	}
+
+	schema := toolInputSchema(tool)
+	b.WriteString("\n")
+	writeDocSection(&b, "INPUT SCHEMA", "-")
+	b.WriteString(formatSchema(schema))
+
+	if docErr != nil || !strings.Contains(doc, "\nEXAMPLE") {
+		b.WriteString("\n")
+		writeDocSection(&b, "EXAMPLE", "-")
+		b.WriteString(exampleArguments(schema))
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	writeDocSection(&b, "COMMON ERRORS", "-")
+	for _, e := range errorRemedies {
+		remedy := e.remedy
+		if e.code == ErrCodeValidation && len(schema.Required) > 0 {
+			remedy = fmt.Sprintf("Pass the required arguments (%s) with the types above. %s", strings.Join(sortedCopy(schema.Required), ", "), remedy)
+		}
+		fmt.Fprintf(&b, "%s\n    %s\n", e.code, remedy)
+	}
+	return b.String(), nil
+}
+
+// errorCodesDoc documents the error codes of failed tool calls.
+func errorCodesDoc() string {
+	var b strings.Builder
+	writeDocSection(&b, "ERROR CODES", "=")
+	b.WriteString("\nA failed tool call returns an error result whose content has a \"code\" and\na \"message\". Branch on the code, not on the message text.\n\n")
+	for _, e := range errorRemedies {
+		fmt.Fprintf(&b, "%s\n    %s\n", e.code, e.remedy)
+	}
+	return b.String()
+}
+
+// writeDocSection writes a heading underlined like the doc files.
+func writeDocSection(b *strings.Builder, title, underline string) {
+	b.WriteString(title)
+	b.WriteString("\n")
+	b.WriteString(strings.Repeat(underline, len(title)))
+	b.WriteString("\n")
+}
+
+// toolPurpose strips the how_to_use pointer from a tool description.
+func toolPurpose(description string) string {
+	if i := strings.Index(description, "Use how_to_use("); i >= 0 {
+		description = description[:i]
+	}
+	return strings.TrimSpace(description)
+}
+
+// toolInputSchema returns the input schema of a tool, parsing the raw schema
+// of tools created with protocol.NewToolWithRawSchema.
+func toolInputSchema(tool *protocol.Tool) protocol.InputSchema {
+	schema := tool.InputSchema
+	if len(schema.Properties) == 0 && len(tool.RawInputSchema) > 0 {
+		if err := json.Unmarshal(tool.RawInputSchema, &schema); err != nil {
+			slog.Debug("failed to parse raw input schema", "name", tool.Name, "err", err)
+		}
+	}
+	return schema
+}
+
+// formatSchema lists the arguments of a schema, required ones first.
+func formatSchema(schema protocol.InputSchema) string {
+	if len(schema.Properties) == 0 {
+		return "No arguments.\n"
+	}
+	required := make(map[string]bool, len(schema.Required))
+	for _, name := range schema.Required {
+		required[name] = true
+	}
+	names := make([]string, 0, len(schema.Properties))
+	for name := range schema.Properties {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if required[names[i]] != required[names[j]] {
+			return required[names[i]]
+		}
+		return names[i] < names[j]
+	})
+
+	var b strings.Builder
+	for _, name := range names {
+		prop := schema.Properties[name]
+		presence := "optional"
+		if required[name] {
+			presence = "required"
+		}
+		fmt.Fprintf(&b, "%s: %s (%s)\n", name, propertyType(prop), presence)
+		if prop.Description != "" {
+			fmt.Fprintf(&b, "    %s\n", prop.Description)
+		}
+		if len(prop.Enum) > 0 {
+			fmt.Fprintf(&b, "    One of: %s\n", strings.Join(prop.Enum, ", "))
+		}
+	}
+	return b.String()
+}
+
+// propertyType describes the JSON type of a property, including the item
+// type of arrays.
+func propertyType(prop *protocol.Property) string {
+	if prop.Type == protocol.Array && prop.Items != nil {
+		return "array of " + propertyType(prop.Items)
+	}
+	if prop.Type == "" {
+		return "any"
+	}
+	return string(prop.Type)
+}
+
+// exampleArguments builds an example call with placeholder values for the
+// required arguments of a schema.
+func exampleArguments(schema protocol.InputSchema) string {
+	args := make(map[string]interface{}, len(schema.Required))
+	for _, name := range schema.Required {
+		if prop, ok := schema.Properties[name]; ok {
+			args[name] = exampleValue(name, prop)
+		}
+	}
+	var b strings.Builder
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "    ")
+	if err := enc.Encode(args); err != nil {
+		return "{}"
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// exampleValue returns a placeholder value of the type of a property.
+func exampleValue(name string, prop *protocol.Property) interface{} {
+	if len(prop.Enum) > 0 {
+		return prop.Enum[0]
+	}
+	switch prop.Type {
+	case protocol.Integer, protocol.Number:
+		return 1
+	case protocol.Boolean:
+		return true
+	case protocol.Array:
+		if prop.Items != nil {
+			return []interface{}{exampleValue(name, prop.Items)}
+		}
+		return []interface{}{}
+	case protocol.ObjectT:
+		return map[string]interface{}{}
+	default:
+		return "<" + name + ">"
+	}
+}
+
+// sortedCopy returns a sorted copy of names.
+func sortedCopy(names []string) []string {
+	sorted := append([]string(nil), names...)
+	sort.Strings(sorted)
+	return sorted
+}
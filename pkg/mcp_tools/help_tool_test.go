@@ -3,6 +3,8 @@ package mcp_tools
 import (
 	"strings"
 	"testing"
+
+	"github.com/ThinkInAIXYZ/go-mcp/protocol"
 )
 
 // TestReadDocFileOverview tests reading the overview documentation
@@ -117,14 +119,53 @@ func TestDocFilesEmbedded(t *testing.T) {
 		"docs/tools/search_vectors.txt",
 		"docs/tools/update_vector.txt",
 		"docs/tools/delete_vector.txt",
+		"docs/tools/remembrance_consolidate.txt",
+		"docs/tools/remembrance_trash_list.txt",
+		"docs/tools/remembrance_restore.txt",
+		"docs/tools/remembrance_save_search.txt",
+		"docs/tools/remembrance_run_saved_search.txt",
+		"docs/tools/remembrance_search_changes.txt",
+		"docs/tools/working_memory_set.txt",
+		"docs/tools/working_memory_get.txt",
+		"docs/tools/working_memory_promote.txt",
+		"docs/tools/working_memory_end.txt",
+		"docs/tools/remembrance_pin.txt",
+		"docs/tools/remembrance_batch.txt",
+		"docs/tools/remembrance_import_bulk.txt",
+		"docs/tools/remembrance_list_users.txt",
+		"docs/tools/remembrance_delete_user.txt",
+		"docs/tools/remembrance_rename_user.txt",
+		"docs/tools/remembrance_purge_user.txt",
+		"docs/tools/remembrance_recount_stats.txt",
+		"docs/tools/remembrance_log_level.txt",
+		"docs/tools/remembrance_system_info.txt",
+		"docs/tools/remembrance_usage_report.txt",
+		"docs/tools/remembrance_subscribe.txt",
 		"docs/tools/create_entity.txt",
 		"docs/tools/get_entity.txt",
 		"docs/tools/create_relationship.txt",
+		"docs/tools/end_relationship.txt",
 		"docs/tools/traverse_graph.txt",
+		"docs/tools/remembrance_list_relationships.txt",
+		"docs/tools/remembrance_graph_stats.txt",
+		"docs/tools/remembrance_find_path.txt",
+		"docs/tools/remembrance_find_duplicate_entities.txt",
+		"docs/tools/remembrance_merge_entities.txt",
+		"docs/tools/update_entity.txt",
+		"docs/tools/remembrance_define_entity_type.txt",
+		"docs/tools/remembrance_describe_entity_type.txt",
 		"docs/tools/kb_add_document.txt",
+		"docs/tools/kb_add_url.txt",
 		"docs/tools/kb_get_document.txt",
 		"docs/tools/kb_search_documents.txt",
 		"docs/tools/kb_delete_document.txt",
+		"docs/tools/kb_get_document_history.txt",
+		"docs/tools/kb_restore_version.txt",
+		"docs/tools/kb_watch_status.txt",
+		"docs/tools/kb_sync_now.txt",
+		"docs/tools/kb_pause_watch.txt",
+		"docs/tools/kb_conflicts.txt",
+		"docs/tools/kb_staleness_report.txt",
 		"docs/tools/to_remember.txt",
 		"docs/tools/last_to_remember.txt",
 		"docs/tools/get_stats.txt",
@@ -175,3 +216,44 @@ func TestHowToUseInputStruct(t *testing.T) {
 		t.Error("empty input should have empty topic")
 	}
 }
+
+// TestToolDocFromCatalog tests the sections generated from registered tools
+func TestToolDocFromCatalog(t *testing.T) {
+	type input struct {
+		UserID string `json:"user_id" description:"The user identifier."`
+		Limit  int    `json:"limit,omitempty" description:"Maximum results."`
+	}
+	tool, err := protocol.NewTool("test_undocumented_tool", `Do a test thing. Use how_to_use("test_undocumented_tool") for details.`, input{})
+	if err != nil {
+		t.Fatalf("failed to create tool: %v", err)
+	}
+	catalogTool("test_undocumented_tool", tool)
+
+	content, err := toolDoc("test_undocumented_tool")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{
+		"Do a test thing.",
+		"INPUT SCHEMA",
+		"user_id: string (required)",
+		"limit: integer (optional)",
+		`"user_id": "<user_id>"`,
+		"COMMON ERRORS",
+		"Pass the required arguments (user_id)",
+	} {
+		if !strings.Contains(content, want) {
+			t.Errorf("tool doc should contain %q, got:\n%s", want, content)
+		}
+	}
+	if strings.Contains(content, "how_to_use(") {
+		t.Error("generated purpose should not point back to how_to_use")
+	}
+}
+
+// TestToolDocUnknown tests that unregistered, undocumented tools fail
+func TestToolDocUnknown(t *testing.T) {
+	if _, err := toolDoc("nonexistent_tool"); err == nil {
+		t.Error("expected error for unknown tool")
+	}
+}
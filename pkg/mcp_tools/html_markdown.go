@@ -0,0 +1,111 @@
+package mcp_tools
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	htmlTitleRe     = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+	htmlCommentRe   = regexp.MustCompile(`(?s)<!--.*?-->`)
+	htmlTagRe       = regexp.MustCompile(`(?s)<[^>]+>`)
+	htmlHeadingRe   = regexp.MustCompile(`(?is)<h([1-6])[^>]*>(.*?)</h[1-6]>`)
+	htmlLinkRe      = regexp.MustCompile(`(?is)<a\s[^>]*href\s*=\s*["']([^"']+)["'][^>]*>(.*?)</a>`)
+	htmlPreRe       = regexp.MustCompile(`(?is)<pre\b[^>]*>(.*?)</pre>`)
+	htmlCodeRe      = regexp.MustCompile(`(?is)<code[^>]*>(.*?)</code>`)
+	htmlStrongRe    = regexp.MustCompile(`(?is)<(?:strong|b)(?:\s[^>]*)?>(.*?)</(?:strong|b)>`)
+	htmlEmRe        = regexp.MustCompile(`(?is)<(?:em|i)(?:\s[^>]*)?>(.*?)</(?:em|i)>`)
+	htmlListItemRe  = regexp.MustCompile(`(?is)<li\b[^>]*>`)
+	htmlBreakRe     = regexp.MustCompile(`(?is)<br\s*/?>`)
+	htmlBlockEndRe  = regexp.MustCompile(`(?is)</?(?:p|div|section|article|main|ul|ol|table|tr|blockquote|hr)\b[^>]*>`)
+	htmlBlankRunsRe = regexp.MustCompile(`\n{3,}`)
+	htmlSpaceRunsRe = regexp.MustCompile(`[ \t]+`)
+)
+
+// htmlNoiseRes match elements that are removed together with their content before extraction.
+var htmlNoiseRes = compileTagPatterns(`(?is)<%[1]s\b[^>]*>.*?</%[1]s>`, "script", "style", "noscript", "svg", "nav", "header", "footer", "aside", "form", "iframe")
+
+// htmlContentRes are tried in order to locate the main content of a page.
+var htmlContentRes = compileTagPatterns(`(?is)<%[1]s\b[^>]*>(.*)</%[1]s>`, "article", "main", "body")
+
+func compileTagPatterns(pattern string, tags ...string) []*regexp.Regexp {
+	res := make([]*regexp.Regexp, len(tags))
+	for i, tag := range tags {
+		res[i] = regexp.MustCompile(fmt.Sprintf(pattern, tag))
+	}
+	return res
+}
+
+// extractHTMLTitle returns the text of the page <title>, if any.
+func extractHTMLTitle(page string) string {
+	m := htmlTitleRe.FindStringSubmatch(page)
+	if m == nil {
+		return ""
+	}
+	return strings.TrimSpace(html.UnescapeString(htmlTagRe.ReplaceAllString(m[1], "")))
+}
+
+// htmlToMarkdown extracts the main readable content of an HTML page and converts
+// it to markdown. It drops navigation and boilerplate elements, prefers <article>
+// or <main> over the whole body, and keeps headings, links, lists, emphasis and code.
+func htmlToMarkdown(page string) string {
+	page = htmlCommentRe.ReplaceAllString(page, "")
+	for _, re := range htmlNoiseRes {
+		page = re.ReplaceAllString(page, "")
+	}
+
+	for _, re := range htmlContentRes {
+		m := re.FindStringSubmatch(page)
+		if m != nil && strings.TrimSpace(htmlTagRe.ReplaceAllString(m[1], "")) != "" {
+			page = m[1]
+			break
+		}
+	}
+
+	// Protect preformatted blocks from whitespace collapsing.
+	var blocks []string
+	page = htmlPreRe.ReplaceAllStringFunc(page, func(s string) string {
+		inner := htmlPreRe.FindStringSubmatch(s)[1]
+		code := html.UnescapeString(htmlTagRe.ReplaceAllString(inner, ""))
+		blocks = append(blocks, "```\n"+strings.Trim(code, "\n")+"\n```")
+		return "\n\n\x00" + strconv.Itoa(len(blocks)-1) + "\x00\n\n"
+	})
+
+	page = strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ").Replace(page)
+	page = htmlHeadingRe.ReplaceAllStringFunc(page, func(s string) string {
+		m := htmlHeadingRe.FindStringSubmatch(s)
+		level := int(m[1][0] - '0')
+		return "\n\n" + strings.Repeat("#", level) + " " + strings.TrimSpace(htmlTagRe.ReplaceAllString(m[2], "")) + "\n\n"
+	})
+	page = htmlLinkRe.ReplaceAllStringFunc(page, func(s string) string {
+		m := htmlLinkRe.FindStringSubmatch(s)
+		text := strings.TrimSpace(htmlTagRe.ReplaceAllString(m[2], ""))
+		if text == "" || strings.HasPrefix(m[1], "#") || strings.HasPrefix(strings.ToLower(m[1]), "javascript:") {
+			return text
+		}
+		return "[" + text + "](" + m[1] + ")"
+	})
+	page = htmlCodeRe.ReplaceAllString(page, "`$1`")
+	page = htmlStrongRe.ReplaceAllString(page, "**$1**")
+	page = htmlEmRe.ReplaceAllString(page, "_${1}_")
+	page = htmlListItemRe.ReplaceAllString(page, "\n- ")
+	page = htmlBreakRe.ReplaceAllString(page, "\n")
+	page = htmlBlockEndRe.ReplaceAllString(page, "\n\n")
+	page = htmlTagRe.ReplaceAllString(page, "")
+	page = html.UnescapeString(page)
+
+	lines := strings.Split(page, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(htmlSpaceRunsRe.ReplaceAllString(line, " "))
+	}
+	page = htmlBlankRunsRe.ReplaceAllString(strings.Join(lines, "\n"), "\n\n")
+
+	for i, block := range blocks {
+		page = strings.Replace(page, "\x00"+strconv.Itoa(i)+"\x00", block, 1)
+	}
+
+	return strings.TrimSpace(page)
+}
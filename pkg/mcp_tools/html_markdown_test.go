@@ -0,0 +1,59 @@
+package mcp_tools
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestHTMLToMarkdown(t *testing.T) {
+	page := `<html><head><title>Auth &amp; Tokens</title><script>var x = 1;</script></head>
+<body><nav><a href="/">Home</a></nav>
+<article>
+<h1>Authentication</h1>
+<p>Use a <strong>token</strong> from the <a href="https://example.com/keys">keys page</a>.</p>
+<ul><li>First</li><li>Second</li></ul>
+<pre><code>curl -H "Authorization: Bearer x"
+  https://api.example.com</code></pre>
+</article>
+<footer>Copyright</footer></body></html>`
+
+	if got := extractHTMLTitle(page); got != "Auth & Tokens" {
+		t.Errorf("extractHTMLTitle() = %q", got)
+	}
+
+	md := htmlToMarkdown(page)
+	for _, want := range []string{
+		"# Authentication",
+		"Use a **token** from the [keys page](https://example.com/keys).",
+		"- First",
+		"- Second",
+		"```\ncurl -H \"Authorization: Bearer x\"\n  https://api.example.com\n```",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("markdown missing %q:\n%s", want, md)
+		}
+	}
+	for _, unwanted := range []string{"var x", "Home", "Copyright"} {
+		if strings.Contains(md, unwanted) {
+			t.Errorf("markdown should not contain %q:\n%s", unwanted, md)
+		}
+	}
+}
+
+func TestFilePathForURL(t *testing.T) {
+	cases := map[string]string{
+		"https://example.com":                 "web/example.com/index.md",
+		"https://example.com/docs/intro.html": "web/example.com/docs/intro.md",
+		"https://example.com:8080/a b/c/?q=1": "web/example.com/a_b/c.md",
+	}
+	for raw, want := range cases {
+		u, err := url.Parse(raw)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := filePathForURL(u); got != want {
+			t.Errorf("filePathForURL(%q) = %q, want %q", raw, got, want)
+		}
+	}
+}
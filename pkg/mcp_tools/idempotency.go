@@ -0,0 +1,112 @@
+package mcp_tools
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/ThinkInAIXYZ/go-mcp/protocol"
+)
+
+// idempotencyKeyTTL is how long the result of a write tool call is returned
+// to retries carrying the same idempotency key.
+const idempotencyKeyTTL = 24 * time.Hour
+
+// maxIdempotencyKeyLength bounds the keys clients may send.
+const maxIdempotencyKeyLength = 256
+
+// idempotencyLocks serializes the calls of a tool sharing an idempotency key,
+// so a retry sent while the first call is still running waits for its result
+// instead of writing again.
+type idempotencyLocks struct {
+	mu    sync.Mutex
+	locks map[string]*idempotencyLock
+}
+
+type idempotencyLock struct {
+	mu   sync.Mutex
+	refs int
+}
+
+func (l *idempotencyLocks) lock(key string) func() {
+	l.mu.Lock()
+	if l.locks == nil {
+		l.locks = map[string]*idempotencyLock{}
+	}
+	lock, ok := l.locks[key]
+	if !ok {
+		lock = &idempotencyLock{}
+		l.locks[key] = lock
+	}
+	lock.refs++
+	l.mu.Unlock()
+
+	lock.mu.Lock()
+	return func() {
+		lock.mu.Unlock()
+		l.mu.Lock()
+		if lock.refs--; lock.refs == 0 {
+			delete(l.locks, key)
+		}
+		l.mu.Unlock()
+	}
+}
+
+// idempotent wraps the handler of a write tool so that calls with an
+// idempotency_key argument run once: the result of the first successful call
+// is stored and returned to every later call with the same key, for
+// idempotencyKeyTTL. Failed calls are not recorded, so they can be retried.
+func (tm *ToolManager) idempotent(tool string, handler func(context.Context, *protocol.CallToolRequest) (*protocol.CallToolResult, error)) func(context.Context, *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+	return func(ctx context.Context, request *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+		var args struct {
+			IdempotencyKey string `json:"idempotency_key"`
+		}
+		if err := json.Unmarshal(request.RawArguments, &args); err != nil || args.IdempotencyKey == "" {
+			return handler(ctx, request)
+		}
+		key := args.IdempotencyKey
+		if len(key) > maxIdempotencyKeyLength {
+			return nil, validationErrorf("idempotency_key must be at most %d bytes", maxIdempotencyKeyLength)
+		}
+
+		unlock := tm.idempotencyLocks.lock(tool + "\x00" + key)
+		defer unlock()
+
+		now := time.Now()
+		text, ok, err := tm.storage.GetIdempotencyResult(ctx, tool, key, now.Add(-idempotencyKeyTTL))
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			slog.Debug("replaying idempotent tool call", "name", tool, "idempotency_key", key)
+			return protocol.NewCallToolResult([]protocol.Content{
+				&protocol.TextContent{Type: "text", Text: text},
+			}, false), nil
+		}
+
+		result, err := handler(ctx, request)
+		if err != nil || result == nil || result.IsError {
+			return result, err
+		}
+		if text, ok := resultText(result); ok {
+			if err := tm.storage.SaveIdempotencyResult(ctx, tool, key, text, now.Add(-idempotencyKeyTTL)); err != nil {
+				slog.Warn("failed to save idempotency key", "name", tool, "error", err)
+			}
+		}
+		return result, nil
+	}
+}
+
+// resultText returns the text of a result made of a single text content.
+func resultText(result *protocol.CallToolResult) (string, bool) {
+	if len(result.Content) != 1 {
+		return "", false
+	}
+	text, ok := result.Content[0].(*protocol.TextContent)
+	if !ok {
+		return "", false
+	}
+	return text.Text, true
+}
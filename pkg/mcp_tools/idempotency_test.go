@@ -0,0 +1,52 @@
+package mcp_tools
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ThinkInAIXYZ/go-mcp/protocol"
+)
+
+func TestIdempotencyLocksSerializeSameKey(t *testing.T) {
+	var locks idempotencyLocks
+	unlock := locks.lock("add_vector\x00k1")
+
+	// Another key is not blocked
+	other := locks.lock("add_vector\x00k2")
+	other()
+
+	var wg sync.WaitGroup
+	acquired := make(chan struct{})
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		release := locks.lock("add_vector\x00k1")
+		close(acquired)
+		release()
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("second call with the same key ran before the first finished")
+	case <-time.After(50 * time.Millisecond):
+	}
+	unlock()
+	wg.Wait()
+
+	if len(locks.locks) != 0 {
+		t.Errorf("locks = %v, want none left", locks.locks)
+	}
+}
+
+func TestResultText(t *testing.T) {
+	result := protocol.NewCallToolResult([]protocol.Content{
+		&protocol.TextContent{Type: "text", Text: "status: saved"},
+	}, false)
+	if text, ok := resultText(result); !ok || text != "status: saved" {
+		t.Errorf("resultText = %q, %v", text, ok)
+	}
+	if _, ok := resultText(protocol.NewCallToolResult(nil, false)); ok {
+		t.Error("expected no text for an empty result")
+	}
+}
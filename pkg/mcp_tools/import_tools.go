@@ -0,0 +1,138 @@
+package mcp_tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ThinkInAIXYZ/go-mcp/protocol"
+	"github.com/madeindigio/remembrances-mcp/internal/bulkimport"
+	"github.com/madeindigio/remembrances-mcp/internal/storage"
+	"github.com/madeindigio/remembrances-mcp/pkg/embedder"
+)
+
+// maxImportBatchSize bounds the texts embedded per embedder call by
+// remembrance_import_bulk.
+const maxImportBatchSize = 256
+
+func (tm *ToolManager) importBulkTool() *protocol.Tool {
+	tool, err := protocol.NewTool("remembrance_import_bulk", `Import facts or vectors from a CSV or JSONL file, or the export of mem0, Zep or Letta, embedding texts in batches and reporting the records that failed. Use how_to_use("remembrance_import_bulk") for details.`, ImportBulkInput{})
+	if err != nil {
+		slog.Error("failed to create tool", "name", "remembrance_import_bulk", "err", err)
+		return nil
+	}
+	return tool
+}
+
+func (tm *ToolManager) importBulkHandler(ctx context.Context, request *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+	var input ImportBulkInput
+	if err := json.Unmarshal(request.RawArguments, &input); err != nil {
+		return nil, fmt.Errorf(errParseArgs, err)
+	}
+	format := strings.ToLower(input.Format)
+	if input.Source != "" {
+		if !slices.Contains(bulkimport.Sources, input.Source) {
+			return nil, validationErrorf("invalid source %q: use %s", input.Source, strings.Join(bulkimport.Sources, ", "))
+		}
+	} else {
+		if input.Kind != bulkimport.KindFacts && input.Kind != bulkimport.KindVectors {
+			return nil, validationErrorf("invalid kind %q: use facts or vectors", input.Kind)
+		}
+		if format == "" {
+			format = bulkimport.DetectFormat(input.Path)
+		}
+		if format != bulkimport.FormatCSV && format != bulkimport.FormatJSONL {
+			return nil, validationErrorf("invalid format %q: use csv or jsonl", input.Format)
+		}
+	}
+	if (input.Path == "") == (input.Data == "") {
+		return nil, validationErrorf("exactly one of path or data is required")
+	}
+	if input.BatchSize < 0 || input.BatchSize > maxImportBatchSize {
+		return nil, validationErrorf("batch_size must be between 1 and %d", maxImportBatchSize)
+	}
+
+	var r io.Reader = strings.NewReader(input.Data)
+	if input.Path != "" {
+		f, err := os.Open(input.Path)
+		if os.IsNotExist(err) {
+			return nil, notFoundErrorf("file %s does not exist", input.Path)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to open %s: %w", input.Path, err)
+		}
+		defer f.Close()
+		r = f
+	}
+
+	streaming := tm.progressNotifier != nil
+	opts := bulkimport.Options{
+		Kind:      input.Kind,
+		Format:    format,
+		UserID:    input.UserID,
+		BatchSize: input.BatchSize,
+		Redact: func(content string) (string, error) {
+			return tm.redactContent("remembrance_import_bulk", content)
+		},
+		Progress: func(p bulkimport.Progress) {
+			if !streaming {
+				return
+			}
+			err := tm.progressNotifier(ctx, &protocol.ProgressNotification{
+				Progress: float64(p.Read),
+				Message:  fmt.Sprintf("%d records read, %d imported, %d failed", p.Read, p.Imported, p.Failed),
+			})
+			if err != nil {
+				slog.Debug("import progress not delivered", "error", err)
+				streaming = false
+			}
+		},
+	}
+
+	// An import runs many queries over a large file; none is bounded by the
+	// query timeout
+	ctx = storage.WithoutQueryTimeout(ctx)
+	var report *bulkimport.Report
+	var err error
+	if input.Source != "" {
+		report, err = bulkimport.ImportExport(ctx, tm.storage, tm.embedderFor(embedder.RouteVectors), r, input.Source, opts)
+	} else {
+		report, err = bulkimport.Import(ctx, tm.storage, tm.embedderFor(embedder.RouteVectors), r, opts)
+	}
+	if report == nil {
+		return nil, validationErrorf("%v", err)
+	}
+	response := map[string]interface{}{
+		"format":   report.Format,
+		"read":     report.Read,
+		"imported": report.Imported,
+		"failed":   report.Failed,
+		"counts":   report.Counts,
+		"duration": report.Duration.Round(time.Millisecond).String(),
+	}
+	if report.Kind != "" {
+		response["kind"] = report.Kind
+	}
+	if len(report.Failures) > 0 {
+		response["failures"] = report.Failures
+		if report.Failed > len(report.Failures) {
+			response["message"] = fmt.Sprintf("Only the first %d of %d failures are listed", len(report.Failures), report.Failed)
+		}
+	}
+	if err != nil {
+		// The records handled before the file became unreadable stay imported
+		response["error"] = err.Error()
+		response["message"] = fmt.Sprintf("Import stopped after %d records: %v", report.Read, err)
+	}
+	slog.Info("bulk import finished", "format", report.Format, "read", report.Read, "imported", report.Imported, "failed", report.Failed)
+
+	return protocol.NewCallToolResult([]protocol.Content{
+		&protocol.TextContent{Type: "text", Text: MarshalTOON(response)},
+	}, err != nil), nil
+}
@@ -0,0 +1,179 @@
+package mcp_tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	"github.com/ThinkInAIXYZ/go-mcp/protocol"
+	"github.com/madeindigio/remembrances-mcp/internal/kb"
+)
+
+// Ways to resolve a conflict between a knowledge base file and its stored document
+const (
+	conflictKeepDisk = "keep-disk"
+	conflictKeepDB   = "keep-db"
+	conflictMerge    = "merge"
+)
+
+func (tm *ToolManager) kbConflictsTool() *protocol.Tool {
+	tool, err := protocol.NewTool("kb_conflicts", `List or resolve documents changed both on disk and in the database. Use how_to_use("kb_conflicts") for details.`, KBConflictsInput{})
+	if err != nil {
+		slog.Error("failed to create tool", "name", "kb_conflicts", "err", err)
+		return nil
+	}
+	return tool
+}
+
+func (tm *ToolManager) kbConflictsHandler(ctx context.Context, request *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+	var input KBConflictsInput
+	if err := json.Unmarshal(request.RawArguments, &input); err != nil {
+		return nil, fmt.Errorf(errParseArgs, err)
+	}
+
+	roots := tm.knowledgeBaseRoots()
+	if len(roots) == 0 {
+		return nil, fmt.Errorf("knowledge base directory is not configured; documents are only stored in the database")
+	}
+
+	if input.Resolution != "" {
+		return tm.resolveConflict(ctx, input)
+	}
+
+	var conflicts []kb.Conflict
+	if input.FilePath != "" {
+		conflict, err := tm.checkConflict(ctx, input.FilePath)
+		if err != nil {
+			return nil, err
+		}
+		if conflict != nil {
+			conflicts = append(conflicts, *conflict)
+		}
+	} else {
+		for _, root := range roots {
+			found, err := kb.FindConflicts(ctx, root, tm.storage)
+			if err != nil {
+				return nil, fmt.Errorf("failed to check knowledge base root %q: %w", root.LabelOrDefault(), err)
+			}
+			conflicts = append(conflicts, found...)
+		}
+	}
+
+	if len(conflicts) == 0 {
+		payload := CreateEmptyResultTOON("No conflicts between knowledge base files and stored documents", AlternativeSuggestions{})
+		return protocol.NewCallToolResult([]protocol.Content{
+			&protocol.TextContent{Type: "text", Text: payload},
+		}, false), nil
+	}
+
+	for i := range conflicts {
+		if !input.IncludeContent {
+			conflicts[i].DiskContent = ""
+			continue
+		}
+		content, err := tm.storage.GetDocumentContent(ctx, conflicts[i].FilePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get document content: %w", err)
+		}
+		conflicts[i].DBContent = content
+	}
+
+	response := map[string]interface{}{
+		"count":       len(conflicts),
+		"conflicts":   conflicts,
+		"resolutions": []string{conflictKeepDisk, conflictKeepDB, conflictMerge},
+	}
+
+	return protocol.NewCallToolResult([]protocol.Content{
+		&protocol.TextContent{Type: "text", Text: MarshalTOON(response)},
+	}, false), nil
+}
+
+// resolveConflict makes the file and the stored document match again,
+// keeping the side chosen by the caller or the merged content it provides.
+func (tm *ToolManager) resolveConflict(ctx context.Context, input KBConflictsInput) (*protocol.CallToolResult, error) {
+	if input.FilePath == "" {
+		return nil, validationErrorf("file_path is required to resolve a conflict")
+	}
+
+	conflict, err := tm.checkConflict(ctx, input.FilePath)
+	if err != nil {
+		return nil, err
+	}
+	if conflict == nil {
+		return nil, fmt.Errorf("document '%s' is not in conflict with its file", input.FilePath)
+	}
+
+	var content string
+	switch input.Resolution {
+	case conflictKeepDisk:
+		content = conflict.DiskContent
+	case conflictMerge:
+		if input.Content == "" {
+			return nil, validationErrorf("content is required for the merge resolution; use include_content to see both versions")
+		}
+		content = input.Content
+	case conflictKeepDB:
+		content, err = tm.storage.GetDocumentContent(ctx, input.FilePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get document content: %w", err)
+		}
+		// The stored document is kept as is, only the file is rewritten
+		if err := tm.saveMarkdownFile(input.FilePath, content); err != nil {
+			return nil, err
+		}
+		hash := kb.ContentHash(content)
+		if err := tm.storage.MergeDocumentMetadata(ctx, input.FilePath, map[string]interface{}{
+			kb.MetaContentHash: hash,
+			kb.MetaSyncedHash:  hash,
+		}); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, validationErrorf("invalid resolution %q; use %s, %s or %s", input.Resolution, conflictKeepDisk, conflictKeepDB, conflictMerge)
+	}
+
+	if input.Resolution != conflictKeepDB {
+		metadata := map[string]interface{}{}
+		if doc, err := tm.storage.GetDocument(ctx, input.FilePath); err == nil && doc != nil {
+			for k, v := range doc.Metadata {
+				metadata[k] = v
+			}
+		}
+		metadata["conflict_resolution"] = input.Resolution
+		strategy, _ := metadata["chunk_strategy"].(string)
+
+		if _, err := tm.storeDocument(ctx, "kb_conflicts", input.FilePath, content, metadata, storeDocumentOptions{
+			ChunkStrategy:  strategy,
+			AllowDuplicate: true,
+			OverwriteFile:  true,
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	response := map[string]interface{}{
+		"file_path":  input.FilePath,
+		"resolution": input.Resolution,
+		"message":    fmt.Sprintf("Resolved conflict for '%s'; the file and the stored document now match", input.FilePath),
+	}
+
+	return protocol.NewCallToolResult([]protocol.Content{
+		&protocol.TextContent{Type: "text", Text: MarshalTOON(response)},
+	}, false), nil
+}
+
+// checkConflict checks a document against its file in the knowledge base
+// root it belongs to.
+func (tm *ToolManager) checkConflict(ctx context.Context, filePath string) (*kb.Conflict, error) {
+	root, rel, ok := kb.ResolveRoot(tm.knowledgeBaseRoots(), filePath)
+	if !ok {
+		return nil, fmt.Errorf("no knowledge base directory holds document '%s'", filePath)
+	}
+	conflict, err := kb.CheckConflict(ctx, root, tm.storage, rel)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check document: %w", err)
+	}
+	return conflict, nil
+}
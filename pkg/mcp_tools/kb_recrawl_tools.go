@@ -0,0 +1,301 @@
+package mcp_tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/ThinkInAIXYZ/go-mcp/protocol"
+	"github.com/madeindigio/remembrances-mcp/internal/kb"
+	"github.com/madeindigio/remembrances-mcp/internal/storage"
+	"github.com/madeindigio/remembrances-mcp/pkg/embedder"
+)
+
+// Metadata keys of documents added with kb_add_url, besides source_url and fetched_at
+const (
+	// metaSourceHash is the hash of the page content as fetched, before redaction.
+	metaSourceHash = "source_hash"
+	// metaSourceCheckedAt is when the page was last fetched to check for changes.
+	metaSourceCheckedAt = "source_checked_at"
+)
+
+// Reasons a document is listed by kb_staleness_report
+const (
+	staleModelChanged      = "model_changed"
+	staleModelUnknown      = "model_unknown"
+	staleSourceChanged     = "source_changed"
+	staleSourceUnreachable = "source_unreachable"
+)
+
+// kbRecrawlState records the scheduled re-crawl of URL documents.
+type kbRecrawlState struct {
+	mu       sync.Mutex
+	interval time.Duration
+	lastRun  time.Time
+	checked  int
+	changed  int
+	failed   int
+}
+
+// urlRecrawlResult is the outcome of checking the page of a URL document.
+type urlRecrawlResult struct {
+	FilePath string `json:"file_path"`
+	URL      string `json:"url"`
+	Changed  bool   `json:"changed"`
+	Updated  bool   `json:"updated,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+func (tm *ToolManager) kbStalenessReportTool() *protocol.Tool {
+	tool, err := protocol.NewTool("kb_staleness_report", `List documents whose source page changed or whose embeddings predate the current model. Use how_to_use("kb_staleness_report") for details.`, KBStalenessReportInput{})
+	if err != nil {
+		slog.Error("failed to create tool", "name", "kb_staleness_report", "err", err)
+		return nil
+	}
+	return tool
+}
+
+// StartKBRecrawl re-fetches the pages of documents added with kb_add_url
+// every interval, re-embedding those whose content changed, until ctx is
+// done. An interval <= 0 disables it.
+func (tm *ToolManager) StartKBRecrawl(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	tm.kbRecrawl.mu.Lock()
+	tm.kbRecrawl.interval = interval
+	tm.kbRecrawl.mu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			results, err := tm.recrawlURLDocuments(ctx, true)
+			if err != nil {
+				slog.Warn("knowledge base re-crawl failed", "error", err)
+				continue
+			}
+			tm.recordRecrawl(results)
+		}
+	}()
+	slog.Info("knowledge base re-crawl scheduled", "interval", interval)
+}
+
+// recordRecrawl keeps the outcome of a scheduled re-crawl for kb_staleness_report.
+func (tm *ToolManager) recordRecrawl(results []urlRecrawlResult) {
+	tm.kbRecrawl.mu.Lock()
+	defer tm.kbRecrawl.mu.Unlock()
+	tm.kbRecrawl.lastRun = time.Now().UTC()
+	tm.kbRecrawl.checked = len(results)
+	tm.kbRecrawl.changed, tm.kbRecrawl.failed = 0, 0
+	for _, r := range results {
+		if r.Changed {
+			tm.kbRecrawl.changed++
+		}
+		if r.Error != "" {
+			tm.kbRecrawl.failed++
+		}
+	}
+	slog.Info("knowledge base re-crawl finished", "checked", tm.kbRecrawl.checked, "changed", tm.kbRecrawl.changed, "failed", tm.kbRecrawl.failed)
+}
+
+// recrawlURLDocuments fetches the page of every document added with
+// kb_add_url and reports those whose content changed, re-embedding them when
+// update is set.
+func (tm *ToolManager) recrawlURLDocuments(ctx context.Context, update bool) ([]urlRecrawlResult, error) {
+	docs, err := tm.storage.ListDocuments(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []urlRecrawlResult
+	for _, doc := range docs {
+		if ctx.Err() != nil {
+			return results, ctx.Err()
+		}
+		if sourceURL, _ := doc.Metadata["source_url"].(string); sourceURL != "" {
+			results = append(results, tm.recrawlURLDocument(ctx, doc, sourceURL, update))
+		}
+	}
+	return results, nil
+}
+
+// recrawlURLDocument fetches the page of a URL document and compares it with
+// the content stored when it was last fetched.
+func (tm *ToolManager) recrawlURLDocument(ctx context.Context, doc storage.Document, sourceURL string, update bool) urlRecrawlResult {
+	res := urlRecrawlResult{FilePath: doc.FilePath, URL: sourceURL}
+	checkedAt := time.Now().UTC().Format(time.RFC3339)
+
+	content, title, contentType, err := fetchURLAsMarkdown(ctx, tm.urlClient, sourceURL)
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+
+	// Documents added before the page hash was kept count as changed once
+	sourceHash := kb.ContentHash(content)
+	if stored, _ := doc.Metadata[metaSourceHash].(string); stored == sourceHash {
+		if update {
+			if err := tm.storage.MergeDocumentMetadata(ctx, doc.FilePath, map[string]interface{}{metaSourceCheckedAt: checkedAt}); err != nil {
+				res.Error = err.Error()
+			}
+		}
+		return res
+	}
+	res.Changed = true
+	if !update {
+		return res
+	}
+
+	metadata := map[string]interface{}{}
+	for k, v := range doc.Metadata {
+		metadata[k] = v
+	}
+	metadata["fetched_at"] = checkedAt
+	metadata["content_type"] = contentType
+	metadata[metaSourceHash] = sourceHash
+	metadata[metaSourceCheckedAt] = checkedAt
+	if title != "" {
+		metadata["title"] = title
+	}
+	strategy, _ := metadata["chunk_strategy"].(string)
+
+	stored, err := tm.storeDocument(ctx, "kb_recrawl", doc.FilePath, content, metadata, storeDocumentOptions{
+		ChunkStrategy:  strategy,
+		AllowDuplicate: true,
+	})
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+	res.Updated = true
+	if stored.Conflict {
+		res.Error = "the file was edited on disk since the last sync and was not overwritten; use kb_conflicts to resolve it"
+	}
+	slog.Info("knowledge base document re-crawled", "file_path", doc.FilePath, "url", sourceURL)
+	return res
+}
+
+func (tm *ToolManager) kbStalenessReportHandler(ctx context.Context, request *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+	var input KBStalenessReportInput
+	if err := json.Unmarshal(request.RawArguments, &input); err != nil {
+		return nil, fmt.Errorf(errParseArgs, err)
+	}
+
+	docs, err := tm.storage.ListDocuments(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := map[string]map[string]interface{}{}
+	var order []string
+	addReason := func(doc storage.Document, reason string) map[string]interface{} {
+		entry, ok := entries[doc.FilePath]
+		if !ok {
+			entry = map[string]interface{}{
+				"file_path":  doc.FilePath,
+				"updated_at": doc.UpdatedAt,
+				"reasons":    []string{},
+			}
+			if model, _ := doc.Metadata[kb.MetaEmbeddingModel].(string); model != "" {
+				entry["embedding_model"] = model
+			}
+			if sourceURL, _ := doc.Metadata["source_url"].(string); sourceURL != "" {
+				entry["source_url"] = sourceURL
+				entry["source_checked_at"] = doc.Metadata[metaSourceCheckedAt]
+			}
+			entries[doc.FilePath] = entry
+			order = append(order, doc.FilePath)
+		}
+		entry["reasons"] = append(entry["reasons"].([]string), reason)
+		return entry
+	}
+
+	currentModel := tm.modelFor(embedder.RouteKnowledgeBase)
+	if currentModel != "" {
+		for _, doc := range docs {
+			switch model, _ := doc.Metadata[kb.MetaEmbeddingModel].(string); {
+			case model == "":
+				addReason(doc, staleModelUnknown)
+			case model != currentModel:
+				addReason(doc, staleModelChanged)
+			}
+		}
+	}
+
+	if input.CheckSources || input.Recrawl {
+		byPath := make(map[string]storage.Document, len(docs))
+		for _, doc := range docs {
+			byPath[doc.FilePath] = doc
+		}
+		results, err := tm.recrawlURLDocuments(ctx, input.Recrawl)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range results {
+			switch {
+			case r.Updated && r.Error == "":
+				// Re-embedded, so no longer stale
+			case r.Changed:
+				entry := addReason(byPath[r.FilePath], staleSourceChanged)
+				if r.Error != "" {
+					entry["error"] = r.Error
+				}
+			case r.Error != "":
+				addReason(byPath[r.FilePath], staleSourceUnreachable)["error"] = r.Error
+			}
+		}
+		if input.Recrawl {
+			tm.recordRecrawl(results)
+		}
+	}
+
+	documents := make([]map[string]interface{}, 0, len(order))
+	for _, path := range order {
+		documents = append(documents, entries[path])
+	}
+
+	response := map[string]interface{}{
+		"count":     len(documents),
+		"documents": documents,
+	}
+	if currentModel != "" {
+		response["current_model"] = currentModel
+	}
+
+	tm.kbRecrawl.mu.Lock()
+	if tm.kbRecrawl.interval > 0 || !tm.kbRecrawl.lastRun.IsZero() {
+		recrawl := map[string]interface{}{}
+		if tm.kbRecrawl.interval > 0 {
+			recrawl["interval"] = tm.kbRecrawl.interval.String()
+		}
+		if !tm.kbRecrawl.lastRun.IsZero() {
+			recrawl["last_run"] = tm.kbRecrawl.lastRun
+			recrawl["checked"] = tm.kbRecrawl.checked
+			recrawl["changed"] = tm.kbRecrawl.changed
+			recrawl["failed"] = tm.kbRecrawl.failed
+		}
+		response["recrawl"] = recrawl
+	}
+	tm.kbRecrawl.mu.Unlock()
+
+	if len(documents) == 0 {
+		response["message"] = "No stale documents found"
+		if !input.CheckSources && !input.Recrawl {
+			response["message"] = "No documents embedded with another model; use check_sources to also fetch the pages of documents added with kb_add_url"
+		}
+	}
+
+	return protocol.NewCallToolResult([]protocol.Content{
+		&protocol.TextContent{Type: "text", Text: MarshalTOON(response)},
+	}, false), nil
+}
@@ -7,12 +7,23 @@ import (
 	"log/slog"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/ThinkInAIXYZ/go-mcp/protocol"
+	"github.com/madeindigio/remembrances-mcp/internal/kb"
+	"github.com/madeindigio/remembrances-mcp/internal/storage"
 	"github.com/madeindigio/remembrances-mcp/pkg/embedder"
 )
 
+// maxToolDocBytes caps documents added through kb_* tools. Keep consistent with kb watcher.
+const maxToolDocBytes = 500 * 1024
+
+// maxContextChunks caps the neighboring chunks kb_search_documents merges on
+// each side of a matching chunk.
+const maxContextChunks = 5
+
 // Knowledge Base tool definitions
 func (tm *ToolManager) addDocumentTool() *protocol.Tool {
 	tool, err := protocol.NewTool("kb_add_document", `Add a document to the knowledge base with automatic embedding. Use how_to_use("kb_add_document") for details.`, AddDocumentInput{})
@@ -50,21 +61,47 @@ func (tm *ToolManager) deleteDocumentTool() *protocol.Tool {
 	return tool
 }
 
-// Knowledge Base tool handlers
-// saveMarkdownFile saves a document as a markdown file in the knowledge base directory
-func (tm *ToolManager) saveMarkdownFile(filePath, content string) error {
+// knowledgeBaseRoots returns the knowledge base directories documents are
+// mirrored to. Without configured roots, the directory given to the
+// constructor is the default root.
+func (tm *ToolManager) knowledgeBaseRoots() []kb.Root {
+	if len(tm.kbRoots) > 0 {
+		return tm.kbRoots
+	}
 	if tm.knowledgeBasePath == "" {
-		// Knowledge base path not configured, skip filesystem storage
 		return nil
 	}
+	return []kb.Root{{
+		Path:         tm.knowledgeBasePath,
+		Label:        storage.DefaultKBRoot,
+		ChunkSize:    tm.kbChunkSize,
+		ChunkOverlap: tm.kbChunkOverlap,
+	}}
+}
+
+// markdownFilePath returns the root and the markdown file a document is
+// mirrored to. ok is false when no knowledge base directory holds it.
+func (tm *ToolManager) markdownFilePath(filePath string) (root kb.Root, fullPath string, ok bool) {
+	root, rel, ok := kb.ResolveRoot(tm.knowledgeBaseRoots(), filePath)
+	if !ok {
+		return kb.Root{}, "", false
+	}
 
 	// Ensure the file has .md extension
-	if !strings.HasSuffix(filePath, ".md") {
-		filePath = filePath + ".md"
+	if !strings.HasSuffix(rel, ".md") {
+		rel = rel + ".md"
 	}
+	return root, filepath.Join(root.Path, filepath.FromSlash(rel)), true
+}
 
-	// Create the full path
-	fullPath := filepath.Join(tm.knowledgeBasePath, filePath)
+// Knowledge Base tool handlers
+// saveMarkdownFile saves a document as a markdown file in the knowledge base directory
+func (tm *ToolManager) saveMarkdownFile(filePath, content string) error {
+	_, fullPath, ok := tm.markdownFilePath(filePath)
+	if !ok {
+		// Knowledge base path not configured, skip filesystem storage
+		return nil
+	}
 
 	// Create directories if they don't exist
 	dir := filepath.Dir(fullPath)
@@ -82,19 +119,12 @@ func (tm *ToolManager) saveMarkdownFile(filePath, content string) error {
 
 // removeMarkdownFile removes a markdown file from the knowledge base directory
 func (tm *ToolManager) removeMarkdownFile(filePath string) error {
-	if tm.knowledgeBasePath == "" {
+	_, fullPath, ok := tm.markdownFilePath(filePath)
+	if !ok {
 		// Knowledge base path not configured, skip filesystem operation
 		return nil
 	}
 
-	// Ensure the file has .md extension
-	if !strings.HasSuffix(filePath, ".md") {
-		filePath = filePath + ".md"
-	}
-
-	// Create the full path
-	fullPath := filepath.Join(tm.knowledgeBasePath, filePath)
-
 	// Log the attempted removal
 	slog.Info("Attempting to remove markdown file", "file_path", filePath, "full_path", fullPath)
 
@@ -109,19 +139,12 @@ func (tm *ToolManager) removeMarkdownFile(filePath string) error {
 
 // readMarkdownFile reads a markdown file from the knowledge base directory
 func (tm *ToolManager) readMarkdownFile(filePath string) (string, error) {
-	if tm.knowledgeBasePath == "" {
+	_, fullPath, ok := tm.markdownFilePath(filePath)
+	if !ok {
 		// Knowledge base path not configured, return empty content
 		return "", nil
 	}
 
-	// Ensure the file has .md extension
-	if !strings.HasSuffix(filePath, ".md") {
-		filePath = filePath + ".md"
-	}
-
-	// Create the full path
-	fullPath := filepath.Join(tm.knowledgeBasePath, filePath)
-
 	// Read the file
 	content, err := os.ReadFile(fullPath)
 	if err != nil {
@@ -140,21 +163,81 @@ func (tm *ToolManager) addDocumentHandler(ctx context.Context, request *protocol
 		return nil, fmt.Errorf(errParseArgs, err)
 	}
 
+	res, err := tm.storeDocument(ctx, "kb_add_document", input.FilePath, input.Content, input.Metadata.AsMap(), storeDocumentOptions{
+		ChunkStrategy:  input.ChunkStrategy,
+		AllowDuplicate: input.AllowDuplicate,
+		MergeMetadata:  input.MergeMetadata,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if res.Duplicate != nil {
+		return duplicateResult("document", *res.Duplicate), nil
+	}
+
+	var message string
+	if res.Conflict {
+		message = fmt.Sprintf("Added document '%s' to the database only: the file was edited on disk since the last sync and was not overwritten. Use kb_conflicts to resolve it.", input.FilePath)
+	} else if _, _, ok := tm.markdownFilePath(input.FilePath); ok {
+		message = fmt.Sprintf("Successfully added document '%s' to knowledge base (database and filesystem)", input.FilePath)
+	} else {
+		message = fmt.Sprintf("Successfully added document '%s' to knowledge base (database only)", input.FilePath)
+	}
+
+	return protocol.NewCallToolResult([]protocol.Content{
+		&protocol.TextContent{
+			Type: "text",
+			Text: message,
+		},
+	}, false), nil
+}
+
+// storeDocumentOptions controls chunking and duplicate handling in storeDocument.
+type storeDocumentOptions struct {
+	ChunkStrategy  string // Overrides the configured strategy when set
+	AllowDuplicate bool   // Skip near-duplicate detection
+	MergeMetadata  bool   // Merge metadata into the existing document when a duplicate is found
+	OverwriteFile  bool   // Write the file even when it changed on disk since the last sync
+}
+
+// storeDocumentResult reports what storeDocument did besides saving.
+type storeDocumentResult struct {
+	Duplicate *duplicateMatch // Set when nothing was stored because the content duplicates another document
+	Conflict  bool            // The file changed on disk since the last sync and was left untouched
+}
+
+// storeDocument chunks, embeds and saves a document on behalf of a kb_* tool,
+// mirroring it to the knowledge base directory when one is configured. When the
+// content duplicates another document, nothing is stored and the match is returned.
+// A file edited on disk since it was last synced is not overwritten; the
+// conflict is reported and left for kb_conflicts.
+func (tm *ToolManager) storeDocument(ctx context.Context, toolName, filePath, content string, metadata map[string]interface{}, opts storeDocumentOptions) (storeDocumentResult, error) {
+	var res storeDocumentResult
+
 	// Chunk content and embed chunks to avoid llama/ggml batch assertions on long inputs.
 	// This is consistent with the knowledge base watcher behavior.
-	content := input.Content
 	if len(strings.TrimSpace(content)) == 0 {
-		return nil, fmt.Errorf("document content is empty")
+		return res, fmt.Errorf("document content is empty")
 	}
 
 	// Guardrail: very large payloads can exhaust memory/time.
-	const maxToolDocBytes = 500 * 1024 // Keep consistent with kb watcher
 	if len(content) > maxToolDocBytes {
-		return nil, fmt.Errorf("document too large: %d bytes (max %d)", len(content), maxToolDocBytes)
+		return res, fmt.Errorf("document too large: %d bytes (max %d)", len(content), maxToolDocBytes)
 	}
 
+	content, err := tm.redactContent(toolName, content)
+	if err != nil {
+		return res, err
+	}
+
+	// Documents mirrored to a knowledge base root use its chunk settings
+	root, _, hasFile := tm.markdownFilePath(filePath)
 	chunkSize := tm.kbChunkSize
 	chunkOverlap := tm.kbChunkOverlap
+	if hasFile && root.ChunkSize > 0 {
+		chunkSize = root.ChunkSize
+		chunkOverlap = root.ChunkOverlap
+	}
 	if chunkSize <= 0 {
 		chunkSize = 800
 	}
@@ -162,45 +245,150 @@ func (tm *ToolManager) addDocumentHandler(ctx context.Context, request *protocol
 		chunkOverlap = 200
 	}
 
-	chunks, embeddings, err := embedder.EmbedTextChunksWithOverlap(ctx, tm.embedder, content, chunkSize, chunkOverlap)
+	strategy := tm.kbChunkStrategy
+	if opts.ChunkStrategy != "" {
+		parsed, err := embedder.ParseChunkStrategy(opts.ChunkStrategy)
+		if err != nil {
+			return res, err
+		}
+		strategy = parsed
+	}
+	if strategy == "" {
+		strategy = embedder.ChunkStrategyFixed
+	}
+
+	chunks, embeddings, err := kb.EmbedMarkdown(ctx, tm.embedderFor(embedder.RouteKnowledgeBase), content, strategy, chunkSize, chunkOverlap)
 	if err != nil {
-		return nil, fmt.Errorf(errGenEmbedding, err)
+		return res, embedderErrorf(errGenEmbedding, err)
+	}
+
+	if !opts.AllowDuplicate {
+		duplicate, err := tm.findDuplicateDocument(ctx, filePath, embeddings)
+		if err != nil {
+			return res, err
+		}
+		if duplicate != nil {
+			if opts.MergeMetadata && len(metadata) > 0 {
+				if err := tm.storage.MergeDocumentMetadata(ctx, duplicate.FilePath, metadata); err != nil {
+					return res, err
+				}
+				duplicate.Metadata = metadata
+				duplicate.Merged = true
+			}
+			res.Duplicate = duplicate
+			return res, nil
+		}
 	}
 
-	metadata := input.Metadata.AsMap()
 	if metadata == nil {
 		metadata = map[string]interface{}{}
 	}
 	// Add/override provenance fields.
 	metadata["source"] = "tool"
-	metadata["tool"] = "kb_add_document"
+	metadata["tool"] = toolName
 	metadata["total_size"] = len(content)
 	metadata["chunk_size"] = chunkSize
 	metadata["chunk_overlap"] = chunkOverlap
+	metadata["chunk_strategy"] = string(strategy)
+	if model := tm.modelFor(embedder.RouteKnowledgeBase); model != "" {
+		metadata[kb.MetaEmbeddingModel] = model
+	}
+	if hasFile {
+		metadata[kb.MetaRoot] = root.LabelOrDefault()
+	}
+	if fm, _ := kb.ParseFrontmatter(content); fm != nil {
+		for k, v := range kb.FrontmatterMetadata(fm) {
+			metadata[k] = v
+		}
+	}
 
-	if err := tm.storage.SaveDocumentChunks(ctx, input.FilePath, chunks, embeddings, metadata); err != nil {
-		return nil, fmt.Errorf("failed to add document to database: %w", err)
+	// The synced hash only moves forward when the file is written as well
+	contentHash := kb.ContentHash(content)
+	previousSynced, conflict := tm.checkFileConflict(ctx, filePath, contentHash)
+	res.Conflict = conflict && !opts.OverwriteFile
+	metadata[kb.MetaContentHash] = contentHash
+	metadata[kb.MetaSyncedHash] = previousSynced
+	if hasFile && !res.Conflict {
+		metadata[kb.MetaSyncedHash] = contentHash
+	}
+
+	if err := tm.storage.SaveDocumentChunks(ctx, filePath, chunks, embeddings, metadata); err != nil {
+		return res, fmt.Errorf("failed to add document to database: %w", err)
+	}
+
+	if hasFile && root.Graph {
+		if err := kb.SyncNoteGraph(ctx, root, tm.storage, filePath, content); err != nil {
+			slog.Warn("failed to update note graph", "file_path", filePath, "error", err)
+		}
+	}
+
+	if res.Conflict {
+		slog.Warn("document file changed on disk since the last sync, not overwriting", "file_path", filePath)
+		return res, nil
 	}
 
 	// Save to filesystem as markdown file (if knowledge base path is configured)
-	if err := tm.saveMarkdownFile(input.FilePath, input.Content); err != nil {
-		slog.Warn("failed to save document to filesystem", "file_path", input.FilePath, "error", err)
+	if err := tm.saveMarkdownFile(filePath, content); err != nil {
+		slog.Warn("failed to save document to filesystem", "file_path", filePath, "error", err)
 		// Don't fail the operation if filesystem save fails, but log it
+		if hasFile {
+			_ = tm.storage.MergeDocumentMetadata(ctx, filePath, map[string]interface{}{kb.MetaSyncedHash: previousSynced})
+		}
 	}
 
-	var message string
-	if tm.knowledgeBasePath != "" {
-		message = fmt.Sprintf("Successfully added document '%s' to knowledge base (database and filesystem)", input.FilePath)
-	} else {
-		message = fmt.Sprintf("Successfully added document '%s' to knowledge base (database only)", input.FilePath)
+	return res, nil
+}
+
+// checkFileConflict reports whether the knowledge base file of a document was
+// edited on disk, to content other than newHash, since it was last synced. It
+// also returns the synced hash of the stored document. Documents stored
+// before content hashes were kept are never in conflict.
+func (tm *ToolManager) checkFileConflict(ctx context.Context, filePath, newHash string) (string, bool) {
+	var syncedHash, dbHash string
+	existing, err := tm.storage.GetDocument(ctx, filePath)
+	if err == nil && existing != nil {
+		syncedHash, _ = existing.Metadata[kb.MetaSyncedHash].(string)
+		dbHash, _ = existing.Metadata[kb.MetaContentHash].(string)
 	}
 
-	return protocol.NewCallToolResult([]protocol.Content{
-		&protocol.TextContent{
-			Type: "text",
-			Text: message,
-		},
-	}, false), nil
+	diskContent, err := tm.readMarkdownFile(filePath)
+	if err != nil || diskContent == "" {
+		return syncedHash, false
+	}
+	diskHash := kb.ContentHash(diskContent)
+	if diskHash == newHash {
+		return syncedHash, false
+	}
+	if existing == nil {
+		// A file the watcher has not synced yet
+		return syncedHash, true
+	}
+	return syncedHash, dbHash != "" && diskHash != dbHash && diskHash != syncedHash
+}
+
+// frontmatterFilter builds the frontmatter filter of a document search,
+// normalizing tags the way they are stored.
+func frontmatterFilter(input SearchDocumentsInput) (storage.FrontmatterFilter, error) {
+	filter := storage.FrontmatterFilter{
+		Title:    strings.TrimSpace(input.Title),
+		Alias:    strings.TrimSpace(input.Alias),
+		DateFrom: strings.TrimSpace(input.DateFrom),
+		DateTo:   strings.TrimSpace(input.DateTo),
+	}
+	for _, tag := range input.Tags {
+		if tag = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(tag), "#")); tag != "" {
+			filter.Tags = append(filter.Tags, tag)
+		}
+	}
+	for _, date := range []struct{ name, value string }{{"date_from", filter.DateFrom}, {"date_to", filter.DateTo}} {
+		if date.value == "" {
+			continue
+		}
+		if _, err := time.Parse(time.DateOnly, date.value); err != nil {
+			return filter, validationErrorf("invalid %s %q; use a YYYY-MM-DD date", date.name, date.value)
+		}
+	}
+	return filter, nil
 }
 
 func (tm *ToolManager) searchDocumentsHandler(ctx context.Context, request *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
@@ -213,15 +401,64 @@ func (tm *ToolManager) searchDocumentsHandler(ctx context.Context, request *prot
 		input.Limit = 10
 	}
 
-	// Generate embedding for the query
-	queryEmbedding, err := tm.embedder.EmbedQuery(ctx, input.Query)
+	if input.ContextChunks < 0 || input.ContextChunks > maxContextChunks {
+		return nil, validationErrorf("invalid context_chunks %d: must be between 0 and %d", input.ContextChunks, maxContextChunks)
+	}
+	output, err := parseOutputMode(input.Output)
+	if err != nil {
+		return nil, err
+	}
+	frontmatter, err := frontmatterFilter(input)
 	if err != nil {
-		return nil, fmt.Errorf(errGenQueryEmbedding, err)
+		return nil, err
 	}
 
-	results, err := tm.storage.SearchDocuments(ctx, queryEmbedding, input.Limit)
+	queries, err := tm.searchQueries(ctx, input.Query, input.Expand, input.HyDE)
 	if err != nil {
-		return nil, fmt.Errorf("failed to search documents: %w", err)
+		return nil, err
+	}
+
+	opts := storage.VectorSearchOptions{
+		Limit:         input.Limit,
+		EfSearch:      input.EfSearch,
+		Accuracy:      input.Accuracy,
+		MinSimilarity: tm.scoring.cutoff(input.MinSimilarity),
+		KBRoots:       input.Roots,
+		Frontmatter:   frontmatter,
+	}
+	lists, err := multiQuerySearch(ctx, tm.embedderFor(embedder.RouteKnowledgeBase).EmbedQuery, queries, func(ctx context.Context, queryEmbedding []float32) ([]storage.DocumentResult, error) {
+		results, err := tm.storage.SearchDocumentsWithOptions(ctx, queryEmbedding, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to search documents: %w", err)
+		}
+
+		if input.IncludeArchived {
+			archived, err := tm.storage.SearchDocumentVersions(ctx, queryEmbedding, opts)
+			if err != nil {
+				return nil, fmt.Errorf("failed to search archived document versions: %w", err)
+			}
+			results = append(results, archived...)
+			sort.SliceStable(results, func(i, j int) bool {
+				return results[i].Similarity > results[j].Similarity
+			})
+			if len(results) > input.Limit {
+				results = results[:input.Limit]
+			}
+		}
+		return results, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	results := lists[0]
+	if len(lists) > 1 {
+		results = fuseRanked(lists, documentResultKey, input.Limit)
+	}
+	results = applyScoring(tm.scoring, results, input.MinSimilarity, documentSimilarity, setDocumentScore)
+	if input.ContextChunks > 0 {
+		if err := tm.expandChunkContext(ctx, results, input.ContextChunks); err != nil {
+			return nil, err
+		}
 	}
 
 	sanitizeDocumentSearchResults(results)
@@ -234,11 +471,38 @@ func (tm *ToolManager) searchDocumentsHandler(ctx context.Context, request *prot
 		}, false), nil
 	}
 
+	tm.recordHits(ctx, "kb_search_documents", documentHits("", results))
+	reportSearchItems(ctx, documentSearchItems(results))
+
+	var items interface{} = results
+	count := len(results)
+	var groups []*documentGroup
+	if input.GroupByDocument {
+		groups = groupDocumentResults(results)
+		items, count = groups, len(groups)
+	}
+
+	if output == outputMarkdown {
+		text := documentCitationsMarkdown(input.Query, results)
+		if input.GroupByDocument {
+			text = documentGroupCitationsMarkdown(input.Query, groups)
+		}
+		return protocol.NewCallToolResult([]protocol.Content{
+			&protocol.TextContent{Type: "text", Text: text},
+		}, false), nil
+	}
+
 	response := map[string]interface{}{
 		"query":   input.Query,
 		"limit":   input.Limit,
-		"count":   len(results),
-		"results": results,
+		"count":   count,
+		"results": items,
+	}
+	if input.GroupByDocument {
+		response["matched_chunks"] = len(results)
+	}
+	if len(queries) > 1 {
+		response["queries"] = queries
 	}
 
 	return protocol.NewCallToolResult([]protocol.Content{
@@ -246,11 +510,72 @@ func (tm *ToolManager) searchDocumentsHandler(ctx context.Context, request *prot
 	}, false), nil
 }
 
+// documentResultKey identifies a document search result when fusing the
+// results of several queries.
+func documentResultKey(r storage.DocumentResult) string {
+	if r.Document == nil {
+		return ""
+	}
+	if r.Document.ID != "" {
+		return r.Document.ID
+	}
+	return r.Document.FilePath
+}
+
+// expandChunkContext replaces the content of each chunk in results with the
+// chunk merged with the radius chunks before and after it, so excerpts do not
+// stop mid-paragraph. The chunks of each document are read with one query.
+// Archived versions and documents stored whole are left as they are.
+func (tm *ToolManager) expandChunkContext(ctx context.Context, results []storage.DocumentResult, radius int) error {
+	var paths []string
+	indexes := map[string][]int{}
+	for _, r := range results {
+		if r.Document == nil || r.Document.Metadata["archived"] == true {
+			continue
+		}
+		path, index, ok := splitChunkPath(r.Document.FilePath)
+		if !ok {
+			continue
+		}
+		if _, seen := indexes[path]; !seen {
+			paths = append(paths, path)
+		}
+		indexes[path] = append(indexes[path], index)
+	}
+
+	excerpts := make(map[string]map[int]string, len(paths))
+	for _, path := range paths {
+		docExcerpts, err := tm.storage.GetDocumentChunkContext(ctx, path, indexes[path], radius)
+		if err != nil {
+			return fmt.Errorf("failed to expand chunk context: %w", err)
+		}
+		excerpts[path] = docExcerpts
+	}
+	for _, r := range results {
+		if r.Document == nil || r.Document.Metadata["archived"] == true {
+			continue
+		}
+		if path, index, ok := splitChunkPath(r.Document.FilePath); ok {
+			if excerpt, ok := excerpts[path][index]; ok {
+				r.Document.Content = excerpt
+			}
+		}
+	}
+	return nil
+}
+
 func (tm *ToolManager) getDocumentHandler(ctx context.Context, request *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
 	var input GetDocumentInput
 	if err := json.Unmarshal(request.RawArguments, &input); err != nil {
 		return nil, fmt.Errorf(errParseArgs, err)
 	}
+	if input.Offset < 0 {
+		return nil, validationErrorf("invalid offset %d: must be 0 or greater", input.Offset)
+	}
+	limit, err := outputLimit(tm.maxOutputBytes, input.MaxOutputBytes)
+	if err != nil {
+		return nil, err
+	}
 
 	// First try to get from database
 	document, err := tm.storage.GetDocument(ctx, input.FilePath)
@@ -263,12 +588,14 @@ func (tm *ToolManager) getDocumentHandler(ctx context.Context, request *protocol
 		// Don't include embedding in response (too large)
 		doc := *document
 		doc.Embedding = nil
+		tm.recordHits(ctx, "kb_get_document", []storage.MemoryHit{{Kind: storage.TrashKindDocument, Ref: input.FilePath}})
 
 		response := map[string]interface{}{
-			"source":   "database",
-			"path":     input.FilePath,
-			"document": doc,
+			"source": "database",
+			"path":   input.FilePath,
 		}
+		doc.Content = limitDocumentContent(response, summarizeDocumentContent(response, doc.Content, input.Summarize), input.Offset, limit)
+		response["document"] = doc
 
 		return protocol.NewCallToolResult([]protocol.Content{
 			&protocol.TextContent{Type: "text", Text: MarshalTOON(response)},
@@ -276,17 +603,17 @@ func (tm *ToolManager) getDocumentHandler(ctx context.Context, request *protocol
 	}
 
 	// Not found in database, try to read from filesystem
-	if tm.knowledgeBasePath != "" {
+	if _, _, ok := tm.markdownFilePath(input.FilePath); ok {
 		content, err := tm.readMarkdownFile(input.FilePath)
 		if err != nil {
 			slog.Warn("failed to read document from filesystem", "file_path", input.FilePath, "error", err)
 		} else if content != "" {
 			// Found in filesystem, return it as a simple content response
 			response := map[string]interface{}{
-				"source":  "filesystem",
-				"path":    input.FilePath,
-				"content": content,
+				"source": "filesystem",
+				"path":   input.FilePath,
 			}
+			response["content"] = limitDocumentContent(response, summarizeDocumentContent(response, content, input.Summarize), input.Offset, limit)
 			return protocol.NewCallToolResult([]protocol.Content{
 				&protocol.TextContent{Type: "text", Text: MarshalTOON(response)},
 			}, false), nil
@@ -301,6 +628,35 @@ func (tm *ToolManager) getDocumentHandler(ctx context.Context, request *protocol
 	}, false), nil
 }
 
+// limitDocumentContent returns the part of a document's content a
+// kb_get_document call asked for, and records in response where it was cut.
+func limitDocumentContent(response map[string]interface{}, content string, offset, limit int) string {
+	if offset == 0 && (limit <= 0 || len(content) <= limit) {
+		return content
+	}
+	text, next := truncateText(content, offset, limit)
+	response["offset"] = offset
+	response["content_bytes"] = len(content)
+	if next == 0 {
+		return text
+	}
+	response["truncated"] = true
+	response["next_offset"] = next
+	return text + truncationMarker(next, len(content), fmt.Sprintf("call kb_get_document with offset=%d to read more, or raise max_output_bytes", next))
+}
+
+// summarizeDocumentContent returns the extractive summary of a document's
+// content when a kb_get_document call asked for one, and records the size of
+// the full content in response.
+func summarizeDocumentContent(response map[string]interface{}, content string, summarize bool) string {
+	if !summarize {
+		return content
+	}
+	response["summarized"] = true
+	response["full_content_bytes"] = len(content)
+	return summarizeDocument(content)
+}
+
 func (tm *ToolManager) deleteDocumentHandler(ctx context.Context, request *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
 	var input DeleteDocumentInput
 	if err := json.Unmarshal(request.RawArguments, &input); err != nil {
@@ -314,12 +670,8 @@ func (tm *ToolManager) deleteDocumentHandler(ctx context.Context, request *proto
 	}
 
 	fsExists := false
-	if tm.knowledgeBasePath != "" {
-		path := input.FilePath
-		if !strings.HasSuffix(path, ".md") {
-			path = path + ".md"
-		}
-		fullPath := filepath.Join(tm.knowledgeBasePath, path)
+	root, fullPath, hasFile := tm.markdownFilePath(input.FilePath)
+	if hasFile {
 		if _, statErr := os.Stat(fullPath); statErr == nil {
 			fsExists = true
 		} else if !os.IsNotExist(statErr) {
@@ -340,6 +692,9 @@ func (tm *ToolManager) deleteDocumentHandler(ctx context.Context, request *proto
 	slog.Info("Processing delete document request", "file_path", input.FilePath)
 
 	// Delete from database
+	if input.Force {
+		ctx = storage.WithForce(ctx)
+	}
 	err = tm.storage.DeleteDocument(ctx, input.FilePath)
 	if err != nil {
 		slog.Error("Failed to delete document from database", "file_path", input.FilePath, "error", err)
@@ -348,6 +703,12 @@ func (tm *ToolManager) deleteDocumentHandler(ctx context.Context, request *proto
 
 	slog.Info("Successfully deleted document from database", "file_path", input.FilePath)
 
+	if hasFile && root.Graph {
+		if err := kb.DeleteNoteGraph(ctx, root, tm.storage, input.FilePath); err != nil {
+			slog.Warn("failed to remove note from graph", "file_path", input.FilePath, "error", err)
+		}
+	}
+
 	// Remove from filesystem (if knowledge base path is configured)
 	if err := tm.removeMarkdownFile(input.FilePath); err != nil {
 		slog.Warn("failed to remove document from filesystem", "file_path", input.FilePath, "error", err)
@@ -355,11 +716,12 @@ func (tm *ToolManager) deleteDocumentHandler(ctx context.Context, request *proto
 	}
 
 	var message string
-	if tm.knowledgeBasePath != "" {
+	if hasFile {
 		message = fmt.Sprintf("Successfully deleted document '%s' from knowledge base (database and filesystem)", input.FilePath)
 	} else {
 		message = fmt.Sprintf("Successfully deleted document '%s' from knowledge base (database only)", input.FilePath)
 	}
+	message += "; it was moved to the trash and can be restored with remembrance_restore"
 
 	slog.Info("Completed delete document request", "file_path", input.FilePath, "message", message)
 
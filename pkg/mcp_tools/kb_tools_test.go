@@ -0,0 +1,27 @@
+package mcp_tools
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFrontmatterFilter(t *testing.T) {
+	filter, err := frontmatterFilter(SearchDocumentsInput{
+		Tags:     []string{" #Project ", "", "notes"},
+		Title:    " Weekly ",
+		DateFrom: "2024-01-01",
+	})
+	if err != nil {
+		t.Fatalf("frontmatterFilter failed: %v", err)
+	}
+	if want := []string{"project", "notes"}; !reflect.DeepEqual(filter.Tags, want) {
+		t.Fatalf("tags = %v, want %v", filter.Tags, want)
+	}
+	if filter.Title != "Weekly" || filter.DateFrom != "2024-01-01" || filter.IsZero() {
+		t.Fatalf("unexpected filter %+v", filter)
+	}
+
+	if _, err := frontmatterFilter(SearchDocumentsInput{DateTo: "01/02/2024"}); err == nil {
+		t.Fatal("expected an error for a date that is not YYYY-MM-DD")
+	}
+}
@@ -0,0 +1,258 @@
+package mcp_tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/ThinkInAIXYZ/go-mcp/protocol"
+	"github.com/madeindigio/remembrances-mcp/internal/kb"
+)
+
+const (
+	// urlFetchTimeout bounds the whole HTTP exchange for kb_add_url.
+	urlFetchTimeout = 30 * time.Second
+	// maxURLFetchBytes caps the raw response body read by kb_add_url.
+	maxURLFetchBytes = 5 * 1024 * 1024
+	// maxURLRedirects is how many redirects kb_add_url follows.
+	maxURLRedirects = 10
+)
+
+var urlPathUnsafeRe = regexp.MustCompile(`[^A-Za-z0-9._\-/]+`)
+
+// errNonPublicAddress is returned when a page would be fetched from an
+// address that is not on the public internet.
+var errNonPublicAddress = errors.New("address is not public")
+
+// nonPublicPrefixes are the ranges besides private, loopback and link-local
+// ones that do not reach the public internet.
+var nonPublicPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+}
+
+// isPublicAddr reports whether addr is a public unicast address.
+func isPublicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsGlobalUnicast() || addr.IsPrivate() {
+		return false
+	}
+	for _, prefix := range nonPublicPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// checkPublicHost resolves host and returns an error wrapping
+// errNonPublicAddress if any of its addresses is not public.
+func checkPublicHost(ctx context.Context, host string) error {
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return err
+	}
+	for _, addr := range addrs {
+		if !isPublicAddr(addr) {
+			return fmt.Errorf("%w: %s resolves to %s", errNonPublicAddress, host, addr)
+		}
+	}
+	return nil
+}
+
+// newURLFetchClient returns the client pages are fetched with. Unless
+// allowPrivate is set, it refuses loopback, link-local, private and other
+// non-public addresses: the dialer checks the address it connects to, after
+// DNS resolution, and every redirect is checked again before it is followed.
+// Proxies from the environment are not used then, as they would connect on
+// the client's behalf.
+func newURLFetchClient(allowPrivate bool) *http.Client {
+	dialer := &net.Dialer{Timeout: urlFetchTimeout, KeepAlive: 30 * time.Second}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if !allowPrivate {
+		dialer.Control = func(network, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return err
+			}
+			if !isPublicAddr(addrPort.Addr()) {
+				return fmt.Errorf("%w: %s", errNonPublicAddress, addrPort.Addr())
+			}
+			return nil
+		}
+		transport.Proxy = nil
+	}
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxURLRedirects {
+				return fmt.Errorf("stopped after %d redirects", maxURLRedirects)
+			}
+			if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+				return validationErrorf("redirect to %s: must be an http or https URL", req.URL)
+			}
+			if allowPrivate {
+				return nil
+			}
+			return checkPublicHost(req.Context(), req.URL.Hostname())
+		},
+	}
+}
+
+func (tm *ToolManager) addURLTool() *protocol.Tool {
+	tool, err := protocol.NewTool("kb_add_url", `Fetch a web page, convert it to markdown and add it to the knowledge base. Use how_to_use("kb_add_url") for details.`, AddURLInput{})
+	if err != nil {
+		slog.Error("failed to create tool", "name", "kb_add_url", "err", err)
+		return nil
+	}
+	return tool
+}
+
+func (tm *ToolManager) addURLHandler(ctx context.Context, request *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+	var input AddURLInput
+	if err := json.Unmarshal(request.RawArguments, &input); err != nil {
+		return nil, fmt.Errorf(errParseArgs, err)
+	}
+
+	pageURL, err := url.Parse(strings.TrimSpace(input.URL))
+	if err != nil || (pageURL.Scheme != "http" && pageURL.Scheme != "https") || pageURL.Host == "" {
+		return nil, validationErrorf("invalid url %q: must be an absolute http or https URL", input.URL)
+	}
+
+	fetchedAt := time.Now().UTC()
+	content, title, contentType, err := fetchURLAsMarkdown(ctx, tm.urlClient, pageURL.String())
+	if err != nil {
+		return nil, err
+	}
+
+	filePath := input.FilePath
+	if filePath == "" {
+		filePath = filePathForURL(pageURL)
+	}
+
+	metadata := input.Metadata.AsMap()
+	if metadata == nil {
+		metadata = map[string]interface{}{}
+	}
+	metadata["source_url"] = pageURL.String()
+	metadata["fetched_at"] = fetchedAt.Format(time.RFC3339)
+	metadata[metaSourceHash] = kb.ContentHash(content)
+	metadata[metaSourceCheckedAt] = fetchedAt.Format(time.RFC3339)
+	metadata["content_type"] = contentType
+	if title != "" {
+		metadata["title"] = title
+	}
+
+	res, err := tm.storeDocument(ctx, "kb_add_url", filePath, content, metadata, storeDocumentOptions{
+		ChunkStrategy:  input.ChunkStrategy,
+		AllowDuplicate: input.AllowDuplicate,
+		MergeMetadata:  input.MergeMetadata,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if res.Duplicate != nil {
+		return duplicateResult("document", *res.Duplicate), nil
+	}
+
+	response := map[string]interface{}{
+		"url":        pageURL.String(),
+		"file_path":  filePath,
+		"title":      title,
+		"size":       len(content),
+		"fetched_at": fetchedAt.Format(time.RFC3339),
+	}
+	if res.Conflict {
+		response["conflict"] = "The file was edited on disk since the last sync and was not overwritten; use kb_conflicts to resolve it"
+	}
+
+	return protocol.NewCallToolResult([]protocol.Content{
+		&protocol.TextContent{Type: "text", Text: MarshalTOON(response)},
+	}, false), nil
+}
+
+// fetchURLAsMarkdown downloads a page with client and returns its readable
+// content as markdown, together with the page title and the response media type.
+func fetchURLAsMarkdown(ctx context.Context, client *http.Client, pageURL string) (string, string, string, error) {
+	ctx, cancel := context.WithTimeout(ctx, urlFetchTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pageURL, nil)
+	if err != nil {
+		return "", "", "", fmt.Errorf("failed to build request: %w", err)
+	}
+	req.Header.Set("User-Agent", "remembrances-mcp (+https://github.com/madeindigio/remembrances-mcp)")
+	req.Header.Set("Accept", "text/html,application/xhtml+xml,text/markdown,text/plain;q=0.9")
+
+	resp, err := client.Do(req)
+	if errors.Is(err, errNonPublicAddress) {
+		return "", "", "", validationErrorf("refusing to fetch %s: %w", pageURL, err)
+	}
+	if err != nil {
+		return "", "", "", fmt.Errorf("failed to fetch %s: %w", pageURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return "", "", "", fmt.Errorf("failed to fetch %s: HTTP %d", pageURL, resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxURLFetchBytes+1))
+	if err != nil {
+		return "", "", "", fmt.Errorf("failed to read %s: %w", pageURL, err)
+	}
+	if len(body) > maxURLFetchBytes {
+		return "", "", "", fmt.Errorf("page too large: more than %d bytes", maxURLFetchBytes)
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType == "" {
+		mediaType = http.DetectContentType(body)
+		mediaType, _, _ = mime.ParseMediaType(mediaType)
+	}
+
+	switch {
+	case mediaType == "text/html" || mediaType == "application/xhtml+xml":
+		page := string(body)
+		title := extractHTMLTitle(page)
+		content := htmlToMarkdown(page)
+		if title != "" && !strings.HasPrefix(content, "# ") {
+			content = "# " + title + "\n\n" + content
+		}
+		return content, title, mediaType, nil
+	case strings.HasPrefix(mediaType, "text/"):
+		return string(body), "", mediaType, nil
+	default:
+		return "", "", "", validationErrorf("unsupported content type %q for %s", mediaType, pageURL)
+	}
+}
+
+// filePathForURL derives a knowledge base path such as "web/example.com/docs/intro.md".
+func filePathForURL(u *url.URL) string {
+	p := strings.Trim(path.Clean("/"+u.Path), "/")
+	if p == "" || p == "." {
+		p = "index"
+	}
+	p = strings.TrimSuffix(p, path.Ext(p))
+	p = urlPathUnsafeRe.ReplaceAllString(p, "_")
+	return path.Join("web", u.Hostname(), p) + ".md"
+}
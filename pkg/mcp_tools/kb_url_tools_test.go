@@ -0,0 +1,64 @@
+package mcp_tools
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+)
+
+func TestIsPublicAddr(t *testing.T) {
+	tests := []struct {
+		addr   string
+		public bool
+	}{
+		{"93.184.216.34", true},
+		{"2606:2800:220:1:248:1893:25c8:1946", true},
+		{"127.0.0.1", false},
+		{"::1", false},
+		{"169.254.169.254", false},
+		{"fe80::1", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"fd00::1", false},
+		{"100.64.0.1", false},
+		{"0.0.0.0", false},
+		{"::ffff:127.0.0.1", false},
+		{"224.0.0.1", false},
+	}
+	for _, tt := range tests {
+		if got := isPublicAddr(netip.MustParseAddr(tt.addr)); got != tt.public {
+			t.Errorf("isPublicAddr(%s) = %v, want %v", tt.addr, got, tt.public)
+		}
+	}
+}
+
+func TestFetchURLRefusesPrivateNetworks(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = w.Write([]byte("internal"))
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	_, _, _, err := fetchURLAsMarkdown(ctx, newURLFetchClient(false), server.URL)
+	var toolErr *ToolError
+	if !errors.As(err, &toolErr) || toolErr.Code != ErrCodeValidation || !errors.Is(err, errNonPublicAddress) {
+		t.Errorf("fetch from %s = %v, want a validation error for a non-public address", server.URL, err)
+	}
+
+	content, _, _, err := fetchURLAsMarkdown(ctx, newURLFetchClient(true), server.URL)
+	if err != nil || content != "internal" {
+		t.Errorf("fetch with private networks allowed = %q, %v; want the page", content, err)
+	}
+
+	// The redirect is checked before it is followed
+	client := newURLFetchClient(false)
+	req, _ := http.NewRequest(http.MethodGet, "http://localhost/", nil)
+	if err := client.CheckRedirect(req, []*http.Request{req}); !errors.Is(err, errNonPublicAddress) {
+		t.Errorf("CheckRedirect(localhost) = %v, want a non-public address error", err)
+	}
+}
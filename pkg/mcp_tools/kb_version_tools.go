@@ -0,0 +1,116 @@
+package mcp_tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	"github.com/ThinkInAIXYZ/go-mcp/protocol"
+)
+
+func (tm *ToolManager) getDocumentHistoryTool() *protocol.Tool {
+	tool, err := protocol.NewTool("kb_get_document_history", `List archived versions of a knowledge-base document with diffs. Use how_to_use("kb_get_document_history") for details.`, GetDocumentHistoryInput{})
+	if err != nil {
+		slog.Error("failed to create tool", "name", "kb_get_document_history", "err", err)
+		return nil
+	}
+	return tool
+}
+
+func (tm *ToolManager) restoreVersionTool() *protocol.Tool {
+	tool, err := protocol.NewTool("kb_restore_version", `Restore an archived version of a knowledge-base document. Use how_to_use("kb_restore_version") for details.`, RestoreVersionInput{})
+	if err != nil {
+		slog.Error("failed to create tool", "name", "kb_restore_version", "err", err)
+		return nil
+	}
+	return tool
+}
+
+func (tm *ToolManager) getDocumentHistoryHandler(ctx context.Context, request *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+	var input GetDocumentHistoryInput
+	if err := json.Unmarshal(request.RawArguments, &input); err != nil {
+		return nil, fmt.Errorf(errParseArgs, err)
+	}
+
+	versions, err := tm.storage.GetDocumentHistory(ctx, input.FilePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get document history: %w", err)
+	}
+
+	if len(versions) == 0 {
+		suggestions := tm.FindDocumentAlternatives(ctx, input.FilePath)
+		payload := CreateEmptyResultTOON(fmt.Sprintf("No archived versions found for document '%s'", input.FilePath), suggestions)
+		return protocol.NewCallToolResult([]protocol.Content{
+			&protocol.TextContent{Type: "text", Text: payload},
+		}, false), nil
+	}
+
+	for i := range versions {
+		// Record IDs are internal; content can be large and is opt-in
+		versions[i].ID = ""
+		if !input.IncludeContent {
+			versions[i].Content = ""
+		}
+	}
+
+	response := map[string]interface{}{
+		"path":     input.FilePath,
+		"count":    len(versions),
+		"versions": versions,
+	}
+
+	return protocol.NewCallToolResult([]protocol.Content{
+		&protocol.TextContent{Type: "text", Text: MarshalTOON(response)},
+	}, false), nil
+}
+
+func (tm *ToolManager) restoreVersionHandler(ctx context.Context, request *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+	var input RestoreVersionInput
+	if err := json.Unmarshal(request.RawArguments, &input); err != nil {
+		return nil, fmt.Errorf(errParseArgs, err)
+	}
+	if input.Version <= 0 {
+		return nil, validationErrorf("version must be a positive integer")
+	}
+
+	version, err := tm.storage.GetDocumentVersion(ctx, input.FilePath, input.Version)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get document version: %w", err)
+	}
+	if version == nil {
+		payload := CreateEmptyResultTOON(fmt.Sprintf("Version %d of document '%s' not found", input.Version, input.FilePath), AlternativeSuggestions{})
+		return protocol.NewCallToolResult([]protocol.Content{
+			&protocol.TextContent{Type: "text", Text: payload},
+		}, false), nil
+	}
+
+	metadata := version.Metadata
+	if metadata == nil {
+		metadata = map[string]interface{}{}
+	}
+	metadata["restored_from_version"] = version.Version
+	strategy, _ := metadata["chunk_strategy"].(string)
+
+	// The current revision is archived by storage before being replaced
+	// Restoring intentionally brings back earlier content, so duplicate detection is skipped
+	res, err := tm.storeDocument(ctx, "kb_restore_version", input.FilePath, version.Content, metadata, storeDocumentOptions{
+		ChunkStrategy:  strategy,
+		AllowDuplicate: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	message := fmt.Sprintf("Restored document '%s' to version %d; the replaced content was archived as a new version", input.FilePath, version.Version)
+	if res.Conflict {
+		message += ". The file was edited on disk since the last sync and was not overwritten; use kb_conflicts to resolve it"
+	}
+
+	return protocol.NewCallToolResult([]protocol.Content{
+		&protocol.TextContent{
+			Type: "text",
+			Text: message,
+		},
+	}, false), nil
+}
@@ -0,0 +1,194 @@
+package mcp_tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	"github.com/ThinkInAIXYZ/go-mcp/protocol"
+	"github.com/madeindigio/remembrances-mcp/internal/kb"
+)
+
+func (tm *ToolManager) kbWatchStatusTool() *protocol.Tool {
+	tool, err := protocol.NewTool("kb_watch_status", `Show the knowledge-base watcher state, pending files and sync errors. Use how_to_use("kb_watch_status") for details.`, KBWatchStatusInput{})
+	if err != nil {
+		slog.Error("failed to create tool", "name", "kb_watch_status", "err", err)
+		return nil
+	}
+	return tool
+}
+
+func (tm *ToolManager) kbSyncNowTool() *protocol.Tool {
+	tool, err := protocol.NewTool("kb_sync_now", `Sync knowledge-base files from disk now. Use how_to_use("kb_sync_now") for details.`, KBSyncNowInput{})
+	if err != nil {
+		slog.Error("failed to create tool", "name", "kb_sync_now", "err", err)
+		return nil
+	}
+	return tool
+}
+
+func (tm *ToolManager) kbPauseWatchTool() *protocol.Tool {
+	tool, err := protocol.NewTool("kb_pause_watch", `Pause or resume the knowledge-base watcher. Use how_to_use("kb_pause_watch") for details.`, KBPauseWatchInput{})
+	if err != nil {
+		slog.Error("failed to create tool", "name", "kb_pause_watch", "err", err)
+		return nil
+	}
+	return tool
+}
+
+// activeKBWatchers returns the running knowledge base watchers, or only the
+// one of the given root label.
+func (tm *ToolManager) activeKBWatchers(label string) ([]*kb.Watcher, error) {
+	var watchers []*kb.Watcher
+	if tm.kbWatchers != nil {
+		for _, w := range tm.kbWatchers() {
+			if label == "" || w.Root().LabelOrDefault() == label {
+				watchers = append(watchers, w)
+			}
+		}
+	}
+	if len(watchers) == 0 {
+		if label != "" {
+			return nil, fmt.Errorf("no knowledge base watcher is running for root %q", label)
+		}
+		return nil, fmt.Errorf("knowledge base watcher is not running; start the server with --knowledge-base or knowledge-base-roots")
+	}
+	return watchers, nil
+}
+
+func (tm *ToolManager) kbWatchStatusHandler(ctx context.Context, request *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+	var input KBWatchStatusInput
+	if err := json.Unmarshal(request.RawArguments, &input); err != nil {
+		return nil, fmt.Errorf(errParseArgs, err)
+	}
+
+	watchers, err := tm.activeKBWatchers(input.Root)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]kb.Status, 0, len(watchers))
+	for _, w := range watchers {
+		statuses = append(statuses, w.Status())
+	}
+
+	response := map[string]interface{}{
+		"count": len(statuses),
+		"roots": statuses,
+	}
+
+	return protocol.NewCallToolResult([]protocol.Content{
+		&protocol.TextContent{Type: "text", Text: MarshalTOON(response)},
+	}, false), nil
+}
+
+func (tm *ToolManager) kbSyncNowHandler(ctx context.Context, request *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+	var input KBSyncNowInput
+	if err := json.Unmarshal(request.RawArguments, &input); err != nil {
+		return nil, fmt.Errorf(errParseArgs, err)
+	}
+
+	watchers, err := tm.activeKBWatchers(input.Root)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.FilePath != "" {
+		roots := make([]kb.Root, 0, len(watchers))
+		for _, w := range watchers {
+			roots = append(roots, w.Root())
+		}
+		root, rel, ok := kb.ResolveRoot(roots, input.FilePath)
+		if !ok {
+			return nil, fmt.Errorf("no watched knowledge base root holds document '%s'", input.FilePath)
+		}
+		var w *kb.Watcher
+		for _, candidate := range watchers {
+			if candidate.Root().LabelOrDefault() == root.LabelOrDefault() {
+				w = candidate
+			}
+		}
+
+		// A single file is synced right away so the outcome can be reported
+		synced, err := w.SyncFile(ctx, rel, input.Force)
+		response := map[string]interface{}{
+			"file_path": input.FilePath,
+			"root":      root.LabelOrDefault(),
+			"synced":    synced,
+		}
+		if err != nil {
+			response["error"] = err.Error()
+		}
+		if w.Status().Paused {
+			response["paused"] = true
+		}
+		return protocol.NewCallToolResult([]protocol.Content{
+			&protocol.TextContent{Type: "text", Text: MarshalTOON(response)},
+		}, false), nil
+	}
+
+	total := 0
+	roots := make([]map[string]interface{}, 0, len(watchers))
+	for _, w := range watchers {
+		queued, err := w.SyncAll(input.Force)
+		if err != nil {
+			return nil, fmt.Errorf("failed to queue files of knowledge base root %q: %w", w.Root().LabelOrDefault(), err)
+		}
+		total += queued
+		entry := map[string]interface{}{
+			"root":   w.Root().LabelOrDefault(),
+			"queued": queued,
+		}
+		if w.Status().Paused {
+			entry["paused"] = true
+		}
+		roots = append(roots, entry)
+	}
+
+	response := map[string]interface{}{
+		"queued":  total,
+		"roots":   roots,
+		"message": "Files were queued; use kb_watch_status to follow progress",
+	}
+
+	return protocol.NewCallToolResult([]protocol.Content{
+		&protocol.TextContent{Type: "text", Text: MarshalTOON(response)},
+	}, false), nil
+}
+
+func (tm *ToolManager) kbPauseWatchHandler(ctx context.Context, request *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+	var input KBPauseWatchInput
+	if err := json.Unmarshal(request.RawArguments, &input); err != nil {
+		return nil, fmt.Errorf(errParseArgs, err)
+	}
+
+	watchers, err := tm.activeKBWatchers(input.Root)
+	if err != nil {
+		return nil, err
+	}
+
+	roots := make([]map[string]interface{}, 0, len(watchers))
+	for _, w := range watchers {
+		if input.Resume {
+			w.Resume()
+		} else {
+			w.Pause()
+		}
+		status := w.Status()
+		roots = append(roots, map[string]interface{}{
+			"root":          status.Root,
+			"paused":        status.Paused,
+			"pending_files": len(status.PendingFiles),
+		})
+	}
+
+	response := map[string]interface{}{
+		"paused": !input.Resume,
+		"roots":  roots,
+	}
+
+	return protocol.NewCallToolResult([]protocol.Content{
+		&protocol.TextContent{Type: "text", Text: MarshalTOON(response)},
+	}, false), nil
+}
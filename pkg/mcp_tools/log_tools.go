@@ -0,0 +1,65 @@
+package mcp_tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/ThinkInAIXYZ/go-mcp/protocol"
+
+	"github.com/madeindigio/remembrances-mcp/internal/logging"
+)
+
+// SetLogLevels sets the log levels remembrance_log_level reports and changes.
+func (tm *ToolManager) SetLogLevels(levels *logging.Levels) {
+	tm.logLevels = levels
+}
+
+func (tm *ToolManager) logLevelTool() *protocol.Tool {
+	tool, err := protocol.NewTool("remembrance_log_level", `Show or change the log level of the server and of its modules while it runs. Use how_to_use("remembrance_log_level") for details.`, LogLevelInput{})
+	if err != nil {
+		slog.Error("failed to create tool", "name", "remembrance_log_level", "err", err)
+		return nil
+	}
+	return tool
+}
+
+func (tm *ToolManager) logLevelHandler(ctx context.Context, request *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+	var input LogLevelInput
+	if err := json.Unmarshal(request.RawArguments, &input); err != nil {
+		return nil, fmt.Errorf(errParseArgs, err)
+	}
+	if tm.logLevels == nil {
+		return nil, fmt.Errorf("log levels cannot be changed in this server")
+	}
+
+	module := strings.ToLower(strings.TrimSpace(input.Module))
+	if module == "" {
+		module = "default"
+	}
+	message := "Current log levels"
+	if input.Level != "" {
+		level := input.Level
+		if strings.EqualFold(level, "default") {
+			if module == "default" {
+				return nil, fmt.Errorf("level default only applies to modules")
+			}
+			level = ""
+		}
+		if err := tm.logLevels.Set(module, level); err != nil {
+			return nil, err
+		}
+		message = fmt.Sprintf("Log level of %s set to %s", module, strings.ToLower(input.Level))
+		slog.Warn("log level changed", "module", module, "level", strings.ToLower(input.Level))
+	}
+
+	response := map[string]interface{}{
+		"message": message,
+		"levels":  tm.logLevels.Snapshot(),
+	}
+	return protocol.NewCallToolResult([]protocol.Content{
+		&protocol.TextContent{Type: "text", Text: MarshalTOON(response)},
+	}, false), nil
+}
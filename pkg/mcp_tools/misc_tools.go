@@ -5,8 +5,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"sort"
 
 	"github.com/ThinkInAIXYZ/go-mcp/protocol"
+	"github.com/madeindigio/remembrances-mcp/internal/storage"
+	"github.com/madeindigio/remembrances-mcp/pkg/embedder"
 )
 
 // Miscellaneous tool definitions
@@ -20,7 +23,7 @@ func (tm *ToolManager) hybridSearchTool() *protocol.Tool {
 }
 
 func (tm *ToolManager) getStatsTool() *protocol.Tool {
-	tool, err := protocol.NewTool("get_stats", `Get memory statistics for a user. Use how_to_use("get_stats") for details.`, GetStatsInput{})
+	tool, err := protocol.NewTool("get_stats", `Get memory statistics per layer, embedding model, database size, index health and sync status. Use how_to_use("get_stats") for details.`, GetStatsInput{})
 	if err != nil {
 		slog.Error("failed to create tool", "name", "get_stats", "err", err)
 		return nil
@@ -38,17 +41,38 @@ func (tm *ToolManager) hybridSearchHandler(ctx context.Context, request *protoco
 	if input.Limit == 0 {
 		input.Limit = 10
 	}
+	if input.MaxTokens < 0 {
+		return nil, validationErrorf("invalid max_tokens %d: must be 0 or greater", input.MaxTokens)
+	}
+	output, err := parseOutputMode(input.Output)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkMinConfidence(input.MinConfidence); err != nil {
+		return nil, err
+	}
 
 	// Generate embedding for the query
-	queryEmbedding, err := tm.embedder.EmbedQuery(ctx, input.Query)
+	queryEmbedding, err := tm.embedderFor(embedder.RouteVectors).EmbedQuery(ctx, input.Query)
 	if err != nil {
-		return nil, fmt.Errorf(errGenQueryEmbedding, err)
+		return nil, embedderErrorf(errGenQueryEmbedding, err)
 	}
 
 	results, err := tm.storage.HybridSearch(ctx, input.UserID, queryEmbedding, input.Entities, input.Limit)
 	if err != nil {
 		return nil, fmt.Errorf("failed to perform hybrid search: %w", err)
 	}
+	found := len(results.VectorResults) + len(results.Facts)
+	results.VectorResults = applyScoring(tm.scoring, results.VectorResults, 0, vectorSimilarity, setVectorScore)
+	if input.MinConfidence > 0 {
+		provenance, err := tm.storage.ListFactProvenance(ctx, input.UserID)
+		if err != nil {
+			return nil, err
+		}
+		results.VectorResults = confidentVectors(results.VectorResults, input.MinConfidence)
+		results.Facts = confidentFacts(results.Facts, provenance, input.MinConfidence)
+	}
+	results.TotalResults -= found - len(results.VectorResults) - len(results.Facts)
 
 	if results.TotalResults == 0 {
 		suggestions := tm.FindUserAlternatives(ctx, "vector_memories", input.UserID)
@@ -61,6 +85,25 @@ func (tm *ToolManager) hybridSearchHandler(ctx context.Context, request *protoco
 		}, false), nil
 	}
 
+	var budget *tokenBudget
+	if input.MaxTokens > 0 {
+		packed, packing := packHybridResults(results, input.MaxTokens)
+		results, budget = packed, &packing
+	}
+
+	tm.recordHits(ctx, "hybrid_search", vectorHits(input.UserID, results.VectorResults))
+	reportSearchItems(ctx, hybridSearchItems(results))
+
+	if output == outputMarkdown {
+		text := hybridCitationsMarkdown(input.Query, results)
+		if budget != nil {
+			text += fmt.Sprintf("\n%d of %d results fit in max_tokens %d.\n", budget.Included, budget.Included+budget.Omitted, budget.MaxTokens)
+		}
+		return protocol.NewCallToolResult([]protocol.Content{
+			&protocol.TextContent{Type: "text", Text: text},
+		}, false), nil
+	}
+
 	response := map[string]interface{}{
 		"user_id":        input.UserID,
 		"query":          input.Query,
@@ -72,12 +115,23 @@ func (tm *ToolManager) hybridSearchHandler(ctx context.Context, request *protoco
 		"graph_results":  results.GraphResults,
 		"facts":          results.Facts,
 	}
+	if budget != nil {
+		response["token_budget"] = budget
+	}
 
 	return protocol.NewCallToolResult([]protocol.Content{
 		&protocol.TextContent{Type: "text", Text: MarshalTOON(response)},
 	}, false), nil
 }
 
+// statsLayerTables are the per-user memory layers get_stats counts, by table.
+var statsLayerTables = []struct{ name, table string }{
+	{"facts", "kv_memories"},
+	{"vectors", "vector_memories"},
+	{"events", "events"},
+	{"document_chunks", "knowledge_base"},
+}
+
 func (tm *ToolManager) getStatsHandler(ctx context.Context, request *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
 	var input GetStatsInput
 	if err := json.Unmarshal(request.RawArguments, &input); err != nil {
@@ -89,7 +143,154 @@ func (tm *ToolManager) getStatsHandler(ctx context.Context, request *protocol.Ca
 		return nil, fmt.Errorf("failed to get stats: %w", err)
 	}
 
+	layers, err := tm.statsLayers(ctx, input.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stats: %w", err)
+	}
+
+	embedding := map[string]interface{}{"dimension": tm.embedder.Dimension()}
+	if tm.embeddingModel != "" {
+		embedding["model"] = tm.embeddingModel
+	}
+	if reporter, ok := tm.embedder.(embedder.BackendReporter); ok {
+		embedding["backend"] = reporter.Backend()
+	}
+	if tm.codeEmbedder != nil && tm.codeEmbedder != tm.embedder {
+		embedding["code_dimension"] = tm.codeEmbedder.Dimension()
+	}
+	if tm.router != nil {
+		routes := map[string]interface{}{}
+		for _, kind := range embedder.RouteKinds {
+			route := tm.router.Route(kind)
+			info := map[string]interface{}{"model": route.Model, "dimension": route.Embedder.Dimension()}
+			if reporter, ok := route.Embedder.(embedder.BackendReporter); ok {
+				info["backend"] = reporter.Backend()
+			}
+			routes[kind] = info
+		}
+		embedding["routes"] = routes
+	}
+	if reporter, ok := tm.storage.(storage.EmbeddingModelReporter); ok {
+		if counts, err := reporter.EmbeddingModels(ctx); err != nil {
+			slog.Warn("failed to count embedding models", "error", err)
+		} else {
+			stale := 0
+			for _, c := range counts {
+				if c.Stale {
+					stale += c.Count
+				}
+			}
+			embedding["records"] = counts
+			embedding["stale_records"] = stale
+		}
+	}
+
+	response := map[string]interface{}{
+		"totals":    stats,
+		"layers":    layers,
+		"embedding": embedding,
+	}
+
+	if info, err := tm.storage.GetDatabaseInfo(ctx); err != nil {
+		slog.Warn("failed to get database info", "error", err)
+	} else {
+		response["database"] = info
+	}
+
+	// In multi-tenant mode, report the caller's tenant and quota usage
+	if tenantStorage, ok := tm.storage.(interface {
+		TenantUsage(ctx context.Context) (map[string]interface{}, error)
+	}); ok {
+		if usage, err := tenantStorage.TenantUsage(ctx); err != nil {
+			slog.Warn("failed to get tenant usage", "error", err)
+		} else if usage != nil {
+			response["tenant"] = usage
+		}
+	}
+
+	if tm.kbWatchers != nil {
+		var roots []map[string]interface{}
+		for _, w := range tm.kbWatchers() {
+			status := w.Status()
+			root := map[string]interface{}{
+				"root":          status.Root,
+				"paused":        status.Paused,
+				"files_synced":  status.FilesSynced,
+				"pending_files": len(status.PendingFiles),
+				"errors":        len(status.Errors),
+			}
+			if status.LastSync != nil {
+				root["last_sync"] = *status.LastSync
+			}
+			roots = append(roots, root)
+		}
+		if len(roots) > 0 {
+			response["knowledge_base"] = roots
+		}
+	}
+
+	if codeStorage, ok := tm.storage.(interface {
+		ListActiveIndexingJobs(ctx context.Context) ([]storage.CodeIndexingJob, error)
+	}); ok {
+		jobs, err := codeStorage.ListActiveIndexingJobs(ctx)
+		if err != nil {
+			slog.Warn("failed to list active indexing jobs", "error", err)
+		} else {
+			active := make([]map[string]interface{}, 0, len(jobs))
+			for _, job := range jobs {
+				active = append(active, map[string]interface{}{
+					"id":            job.ID,
+					"project_id":    job.ProjectID,
+					"status":        job.Status,
+					"progress":      job.Progress,
+					"files_indexed": job.FilesIndexed,
+					"files_total":   job.FilesTotal,
+					"started_at":    job.StartedAt,
+				})
+			}
+			response["indexing_jobs"] = active
+		}
+	}
+
 	return protocol.NewCallToolResult([]protocol.Content{
-		&protocol.TextContent{Type: "text", Text: MarshalTOON(stats)},
+		&protocol.TextContent{Type: "text", Text: MarshalTOON(response)},
 	}, false), nil
 }
+
+// statsLayers returns the number of records of each memory layer per user,
+// or only for userID unless it is empty or "global".
+func (tm *ToolManager) statsLayers(ctx context.Context, userID string) ([]map[string]interface{}, error) {
+	counts := map[string]map[string]int{}
+	for _, layer := range statsLayerTables {
+		byUser, err := tm.storage.CountByUserID(ctx, layer.table)
+		if err != nil {
+			return nil, err
+		}
+		for uid, count := range byUser {
+			if counts[uid] == nil {
+				counts[uid] = map[string]int{}
+			}
+			counts[uid][layer.name] = count
+		}
+	}
+
+	users := make([]string, 0, len(counts))
+	if userID != "" && userID != "global" {
+		users = append(users, userID)
+	} else {
+		for uid := range counts {
+			users = append(users, uid)
+		}
+		sort.Strings(users)
+	}
+
+	layers := make([]map[string]interface{}, 0, len(users))
+	for _, uid := range users {
+		row := map[string]interface{}{"user_id": uid}
+		for _, layer := range statsLayerTables {
+			row[layer.name] = counts[uid][layer.name]
+		}
+		layers = append(layers, row)
+	}
+	return layers, nil
+}
@@ -0,0 +1,102 @@
+package mcp_tools
+
+import (
+	"fmt"
+	"unicode/utf8"
+)
+
+// defaultMaxOutputBytes caps document contents and symbol bodies in a single
+// tool result when no limit is configured.
+const defaultMaxOutputBytes = 65536
+
+// SetMaxOutputBytes configures how many bytes of document content
+// kb_get_document returns per call. 0 disables the limit; negative values
+// fall back to the default.
+func (tm *ToolManager) SetMaxOutputBytes(maxBytes int) {
+	if maxBytes < 0 {
+		maxBytes = defaultMaxOutputBytes
+	}
+	tm.maxOutputBytes = maxBytes
+}
+
+// SetMaxOutputBytes configures how many bytes of symbol bodies
+// code_find_symbol returns per call. 0 disables the limit; negative values
+// fall back to the default.
+func (cstm *CodeSearchToolManager) SetMaxOutputBytes(maxBytes int) {
+	if maxBytes < 0 {
+		maxBytes = defaultMaxOutputBytes
+	}
+	cstm.maxOutputBytes = maxBytes
+}
+
+// outputLimit returns the byte limit of a call: the per-call max_output_bytes
+// when set, the configured limit otherwise. 0 means unlimited.
+func outputLimit(configured, perCall int) (int, error) {
+	if perCall < 0 {
+		return 0, validationErrorf("invalid max_output_bytes %d: must be 0 or greater", perCall)
+	}
+	if perCall > 0 {
+		return perCall, nil
+	}
+	return configured, nil
+}
+
+// truncateText returns up to limit bytes of s starting at byte offset,
+// without splitting a UTF-8 character. next is the offset to continue
+// reading from, or 0 when the rest of s was returned. A limit of 0 returns
+// everything after offset.
+func truncateText(s string, offset, limit int) (text string, next int) {
+	if offset >= len(s) {
+		return "", 0
+	}
+	for offset > 0 && !utf8.RuneStart(s[offset]) {
+		offset--
+	}
+	s = s[offset:]
+	if limit <= 0 || len(s) <= limit {
+		return s, 0
+	}
+	end := limit
+	for end > 0 && !utf8.RuneStart(s[end]) {
+		end--
+	}
+	if end == 0 {
+		// A single character wider than the limit; return it whole
+		_, size := utf8.DecodeRuneInString(s)
+		end = size
+	}
+	return s[:end], offset + end
+}
+
+// truncationMarker is appended to truncated content so clients notice it
+// even when they only read the text.
+func truncationMarker(next, total int, howToContinue string) string {
+	return fmt.Sprintf("\n\n[... truncated, %d of %d bytes not shown; %s]", total-next, total, howToContinue)
+}
+
+// outputBudget spreads a byte limit across the symbol bodies of one result.
+type outputBudget struct {
+	remaining int
+	unlimited bool
+	truncated int
+}
+
+func newOutputBudget(limit int) *outputBudget {
+	return &outputBudget{remaining: limit, unlimited: limit <= 0}
+}
+
+// take returns as much of body as the budget still allows, followed by a
+// truncation marker when it was cut.
+func (b *outputBudget) take(body, howToContinue string) string {
+	if b.unlimited || len(body) <= b.remaining {
+		b.remaining -= len(body)
+		return body
+	}
+	b.truncated++
+	if b.remaining <= 0 {
+		return truncationMarker(0, len(body), howToContinue)
+	}
+	text, next := truncateText(body, 0, b.remaining)
+	b.remaining = 0
+	return text + truncationMarker(next, len(body), howToContinue)
+}
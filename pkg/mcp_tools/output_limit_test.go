@@ -0,0 +1,77 @@
+package mcp_tools
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTruncateText(t *testing.T) {
+	tests := []struct {
+		name     string
+		s        string
+		offset   int
+		limit    int
+		wantText string
+		wantNext int
+	}{
+		{"unlimited", "hello world", 0, 0, "hello world", 0},
+		{"fits", "hello", 0, 10, "hello", 0},
+		{"cut", "hello world", 0, 5, "hello", 5},
+		{"continue", "hello world", 5, 5, " worl", 10},
+		{"last part", "hello world", 10, 5, "d", 0},
+		{"past end", "hello", 9, 5, "", 0},
+		{"no split rune", "añb", 0, 2, "a", 1},
+		{"offset inside rune", "añb", 2, 5, "ñb", 0},
+		{"rune wider than limit", "ñb", 0, 1, "ñ", 2},
+	}
+	for _, tt := range tests {
+		text, next := truncateText(tt.s, tt.offset, tt.limit)
+		if text != tt.wantText || next != tt.wantNext {
+			t.Errorf("%s: truncateText() = %q, %d; want %q, %d", tt.name, text, next, tt.wantText, tt.wantNext)
+		}
+	}
+}
+
+func TestOutputLimit(t *testing.T) {
+	if got, _ := outputLimit(100, 0); got != 100 {
+		t.Errorf("default limit = %d, want 100", got)
+	}
+	if got, _ := outputLimit(100, 500); got != 500 {
+		t.Errorf("per-call limit = %d, want 500", got)
+	}
+	if _, err := outputLimit(100, -1); errorCode(err) != ErrCodeValidation {
+		t.Errorf("negative limit: got %v, want a validation error", err)
+	}
+}
+
+func TestLimitDocumentContent(t *testing.T) {
+	response := map[string]interface{}{}
+	content := limitDocumentContent(response, strings.Repeat("x", 30), 0, 10)
+	if !strings.HasPrefix(content, strings.Repeat("x", 10)+"\n\n[... truncated, 20 of 30 bytes not shown") {
+		t.Errorf("unexpected truncated content: %q", content)
+	}
+	if response["truncated"] != true || response["next_offset"] != 10 {
+		t.Errorf("response should report the next offset, got %v", response)
+	}
+
+	response = map[string]interface{}{}
+	if content := limitDocumentContent(response, "short", 0, 10); content != "short" || len(response) != 0 {
+		t.Errorf("short content should be returned unchanged, got %q %v", content, response)
+	}
+}
+
+func TestOutputBudget(t *testing.T) {
+	budget := newOutputBudget(8)
+	if got := budget.take("12345", "hint"); got != "12345" {
+		t.Errorf("first body = %q, want it whole", got)
+	}
+	if got := budget.take("67890", "hint"); !strings.HasPrefix(got, "678\n\n[... truncated, 2 of 5 bytes not shown; hint]") {
+		t.Errorf("second body = %q, want it cut at the budget", got)
+	}
+	if got := budget.take("abc", "hint"); !strings.HasPrefix(got, "\n\n[... truncated, 3 of 3 bytes not shown") {
+		t.Errorf("third body = %q, want only the marker", got)
+	}
+	if budget.truncated != 2 {
+		t.Errorf("truncated = %d, want 2", budget.truncated)
+	}
+}
@@ -0,0 +1,118 @@
+package mcp_tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/ThinkInAIXYZ/go-mcp/protocol"
+	"github.com/madeindigio/remembrances-mcp/internal/storage"
+)
+
+// Limits of remembrance_find_path.
+const (
+	defaultPathHops = 4
+	maxPathHops     = 6
+	defaultPaths    = 5
+	maxPaths        = 20
+)
+
+func (tm *ToolManager) findPathTool() *protocol.Tool {
+	tool, err := protocol.NewTool("remembrance_find_path", `Find how two entities are related: the shortest chains of relationships connecting them. Use how_to_use("remembrance_find_path") for details.`, FindPathInput{})
+	if err != nil {
+		slog.Error("failed to create tool", "name", "remembrance_find_path", "err", err)
+		return nil
+	}
+	return tool
+}
+
+func (tm *ToolManager) findPathHandler(ctx context.Context, request *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+	var input FindPathInput
+	if err := json.Unmarshal(request.RawArguments, &input); err != nil {
+		return nil, fmt.Errorf(errParseArgs, err)
+	}
+	if input.EntityA == "" || input.EntityB == "" {
+		return nil, validationErrorf("entity_a and entity_b are required")
+	}
+	if input.MaxHops == 0 {
+		input.MaxHops = defaultPathHops
+	}
+	if input.MaxHops < 0 || input.MaxHops > maxPathHops {
+		return nil, validationErrorf("invalid max_hops %d: must be between 1 and %d", input.MaxHops, maxPathHops)
+	}
+	if input.MaxPaths == 0 {
+		input.MaxPaths = defaultPaths
+	}
+	if input.MaxPaths < 0 || input.MaxPaths > maxPaths {
+		return nil, validationErrorf("invalid max_paths %d: must be between 1 and %d", input.MaxPaths, maxPaths)
+	}
+
+	for _, name := range []string{input.EntityA, input.EntityB} {
+		entity, err := tm.storage.GetEntity(ctx, name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get entity: %w", err)
+		}
+		if entity == nil {
+			suggestions := tm.FindEntityAlternatives(ctx, name)
+			payload := CreateEmptyResultTOON(fmt.Sprintf("No entity found with name or ID '%s'", name), suggestions)
+			return protocol.NewCallToolResult([]protocol.Content{
+				&protocol.TextContent{Type: "text", Text: payload},
+			}, false), nil
+		}
+	}
+
+	paths, err := tm.storage.FindPaths(ctx, input.EntityA, input.EntityB, input.MaxHops, input.MaxPaths)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find paths: %w", err)
+	}
+	if len(paths) == 0 {
+		payload := CreateEmptyResultTOON(
+			fmt.Sprintf("No path of at most %d relationships between '%s' and '%s'; try a larger max_hops", input.MaxHops, input.EntityA, input.EntityB),
+			AlternativeSuggestions{},
+		)
+		return protocol.NewCallToolResult([]protocol.Content{
+			&protocol.TextContent{Type: "text", Text: payload},
+		}, false), nil
+	}
+
+	summaries := make([]string, len(paths))
+	for i, path := range paths {
+		summaries[i] = describePath(path)
+	}
+	response := map[string]interface{}{
+		"entity_a": input.EntityA,
+		"entity_b": input.EntityB,
+		"max_hops": input.MaxHops,
+		"count":    len(paths),
+		"summary":  summaries,
+		"paths":    paths,
+	}
+	return protocol.NewCallToolResult([]protocol.Content{
+		&protocol.TextContent{Type: "text", Text: MarshalTOON(response)},
+	}, false), nil
+}
+
+// describePath writes a path on one line, with the direction of each
+// relationship: "Alice -[works_at]-> Acme <-[works_at]- Bob".
+func describePath(path storage.GraphPath) string {
+	var b strings.Builder
+	for i, entity := range path.Entities {
+		name := entity.Name
+		if name == "" {
+			name = entity.ID
+		}
+		b.WriteString(name)
+		if i >= len(path.Relationships) {
+			break
+		}
+		r := path.Relationships[i]
+		if r.From == entity.ID {
+			fmt.Fprintf(&b, " -[%s]-> ", r.Type)
+		} else {
+			fmt.Fprintf(&b, " <-[%s]- ", r.Type)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,25 @@
+package mcp_tools
+
+import (
+	"testing"
+
+	"github.com/madeindigio/remembrances-mcp/internal/storage"
+)
+
+func TestDescribePath(t *testing.T) {
+	path := storage.GraphPath{
+		Hops: 2,
+		Entities: []storage.PathEntity{
+			{ID: "e:1", Name: "Alice"},
+			{ID: "e:2", Name: "Acme"},
+			{ID: "e:3"},
+		},
+		Relationships: []storage.Relationship{
+			{From: "e:1", To: "e:2", Type: "works_at"},
+			{From: "e:3", To: "e:2", Type: "works_at"},
+		},
+	}
+	if got, want := describePath(path), "Alice -[works_at]-> Acme <-[works_at]- e:3"; got != want {
+		t.Errorf("describePath() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,119 @@
+package mcp_tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	"github.com/ThinkInAIXYZ/go-mcp/protocol"
+	"github.com/madeindigio/remembrances-mcp/internal/storage"
+)
+
+func (tm *ToolManager) pinTool() *protocol.Tool {
+	tool, err := protocol.NewTool("remembrance_pin", `Pin a fact, vector or document so it cannot be deleted or consolidated without force, unpin it, or list the pinned items. Use how_to_use("remembrance_pin") for details.`, PinInput{})
+	if err != nil {
+		slog.Error("failed to create tool", "name", "remembrance_pin", "err", err)
+		return nil
+	}
+	return tool
+}
+
+func (tm *ToolManager) pinHandler(ctx context.Context, request *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+	var input PinInput
+	if err := json.Unmarshal(request.RawArguments, &input); err != nil {
+		return nil, fmt.Errorf(errParseArgs, err)
+	}
+
+	if input.Kind == "" && input.Ref == "" {
+		items, err := tm.storage.ListPinned(ctx, input.UserID, "")
+		if err != nil {
+			return nil, err
+		}
+		response := map[string]interface{}{
+			"count":  len(items),
+			"pinned": items,
+		}
+		if input.UserID != "" {
+			response["user_id"] = input.UserID
+		}
+		return protocol.NewCallToolResult([]protocol.Content{
+			&protocol.TextContent{Type: "text", Text: MarshalTOON(response)},
+		}, false), nil
+	}
+
+	switch input.Kind {
+	case storage.TrashKindFact, storage.TrashKindVector:
+		if input.UserID == "" {
+			return nil, validationErrorf("user_id is required to pin a %s", input.Kind)
+		}
+	case storage.TrashKindDocument:
+		input.UserID = ""
+	default:
+		return nil, validationErrorf("invalid kind %q: use fact, vector or document", input.Kind)
+	}
+	if input.Ref == "" {
+		return nil, validationErrorf("ref is required: the fact key, vector ID or document file path")
+	}
+	if !input.Unpin {
+		if err := tm.checkPinTarget(ctx, input); err != nil {
+			return nil, err
+		}
+	}
+
+	changed, err := tm.storage.SetPinned(ctx, input.Kind, input.UserID, input.Ref, !input.Unpin)
+	if err != nil {
+		return nil, err
+	}
+	response := map[string]interface{}{
+		"kind":    input.Kind,
+		"ref":     input.Ref,
+		"pinned":  !input.Unpin,
+		"changed": changed,
+	}
+	if input.UserID != "" {
+		response["user_id"] = input.UserID
+	}
+	return protocol.NewCallToolResult([]protocol.Content{
+		&protocol.TextContent{Type: "text", Text: MarshalTOON(response)},
+	}, false), nil
+}
+
+// checkPinTarget fails with a not found error when the fact or document to
+// pin does not exist. Vectors cannot be looked up by ID and are pinned as
+// given.
+func (tm *ToolManager) checkPinTarget(ctx context.Context, input PinInput) error {
+	switch input.Kind {
+	case storage.TrashKindFact:
+		value, err := tm.storage.GetFact(ctx, input.UserID, input.Ref)
+		if err != nil {
+			return fmt.Errorf("failed to check fact before pinning: %w", err)
+		}
+		if value == nil {
+			return notFoundErrorf("no fact %q for user %q", input.Ref, input.UserID)
+		}
+	case storage.TrashKindDocument:
+		doc, err := tm.storage.GetDocument(ctx, input.Ref)
+		if err != nil {
+			return fmt.Errorf("failed to check document before pinning: %w", err)
+		}
+		if doc == nil {
+			return notFoundErrorf("no document at path %q", input.Ref)
+		}
+	}
+	return nil
+}
+
+// pinnedRefs returns the pinned items of kind of userID as a set of
+// "kind\x00ref" keys.
+func (tm *ToolManager) pinnedRefs(ctx context.Context, userID, kind string) (map[string]bool, error) {
+	items, err := tm.storage.ListPinned(ctx, userID, kind)
+	if err != nil {
+		return nil, err
+	}
+	refs := make(map[string]bool, len(items))
+	for _, item := range items {
+		refs[item.Kind+"\x00"+item.Ref] = true
+	}
+	return refs, nil
+}
@@ -0,0 +1,96 @@
+package mcp_tools
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+
+	"github.com/ThinkInAIXYZ/go-mcp/protocol"
+	mcpserver "github.com/ThinkInAIXYZ/go-mcp/server"
+	"github.com/madeindigio/remembrances-mcp/internal/storage"
+)
+
+// Orders of search_vectors results.
+const (
+	sortBySimilarity = "similarity"
+	sortByConfidence = "confidence"
+)
+
+// withProvenance wraps the handler of tool so that the memories it writes
+// record their provenance: the tool, the conversation, which defaults to the
+// MCP session, and the model and confidence arguments of ProvenanceInput.
+func withProvenance(tool string, handler func(context.Context, *protocol.CallToolRequest) (*protocol.CallToolResult, error)) func(context.Context, *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+	return func(ctx context.Context, request *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+		// Malformed arguments are reported by the handler itself
+		var input ProvenanceInput
+		_ = json.Unmarshal(request.RawArguments, &input)
+		if input.Confidence != nil && (*input.Confidence < 0 || *input.Confidence > 1) {
+			return nil, validationErrorf("invalid confidence %g: must be between 0 and 1", *input.Confidence)
+		}
+		if input.ConversationID == "" {
+			input.ConversationID, _ = mcpserver.GetSessionIDFromCtx(ctx)
+		}
+		ctx = storage.WithProvenance(ctx, storage.Provenance{
+			SourceTool:     tool,
+			ConversationID: input.ConversationID,
+			Model:          input.Model,
+			Confidence:     input.Confidence,
+		})
+		return handler(ctx, request)
+	}
+}
+
+// checkMinConfidence validates a min_confidence argument.
+func checkMinConfidence(minConfidence float64) error {
+	if minConfidence < 0 || minConfidence > 1 {
+		return validationErrorf("invalid min_confidence %g: must be between 0 and 1", minConfidence)
+	}
+	return nil
+}
+
+// confidentVectors returns the results whose provenance has at least
+// minConfidence. Results written without a confidence are dropped.
+func confidentVectors(results []storage.VectorResult, minConfidence float64) []storage.VectorResult {
+	if minConfidence <= 0 {
+		return results
+	}
+	kept := results[:0:0]
+	for _, r := range results {
+		if confidence, ok := storage.MetadataConfidence(r.Metadata); ok && confidence >= minConfidence {
+			kept = append(kept, r)
+		}
+	}
+	return kept
+}
+
+// sortVectorsByConfidence orders results by the confidence of their
+// provenance, highest first, then by score. Results without a confidence
+// come last.
+func sortVectorsByConfidence(results []storage.VectorResult) {
+	sort.SliceStable(results, func(i, j int) bool {
+		ci, iok := storage.MetadataConfidence(results[i].Metadata)
+		cj, jok := storage.MetadataConfidence(results[j].Metadata)
+		if iok != jok {
+			return iok
+		}
+		if ci != cj {
+			return ci > cj
+		}
+		return results[i].Score > results[j].Score
+	})
+}
+
+// confidentFacts returns the facts of provenance that have at least
+// minConfidence.
+func confidentFacts(facts map[string]interface{}, provenance map[string]storage.Provenance, minConfidence float64) map[string]interface{} {
+	if minConfidence <= 0 {
+		return facts
+	}
+	kept := make(map[string]interface{}, len(facts))
+	for key, value := range facts {
+		if p, ok := provenance[key]; ok && p.Confidence != nil && *p.Confidence >= minConfidence {
+			kept[key] = value
+		}
+	}
+	return kept
+}
@@ -0,0 +1,61 @@
+package mcp_tools
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/ThinkInAIXYZ/go-mcp/protocol"
+	"github.com/madeindigio/remembrances-mcp/internal/storage"
+)
+
+func TestWithProvenance(t *testing.T) {
+	var got storage.Provenance
+	handler := withProvenance("save_fact", func(ctx context.Context, request *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+		got, _ = storage.ProvenanceFromContext(ctx)
+		return nil, nil
+	})
+
+	raw, _ := json.Marshal(map[string]interface{}{"key": "k", "confidence": 0.75, "model": "m1", "conversation_id": "c1"})
+	if _, err := handler(context.Background(), &protocol.CallToolRequest{RawArguments: raw}); err != nil {
+		t.Fatal(err)
+	}
+	if got.SourceTool != "save_fact" || got.Model != "m1" || got.ConversationID != "c1" || got.Confidence == nil || *got.Confidence != 0.75 {
+		t.Errorf("provenance = %+v", got)
+	}
+
+	raw, _ = json.Marshal(map[string]interface{}{"confidence": 1.5})
+	if _, err := handler(context.Background(), &protocol.CallToolRequest{RawArguments: raw}); errorCode(err) != ErrCodeValidation {
+		t.Errorf("confidence 1.5 error = %v, want a validation error", err)
+	}
+}
+
+func TestConfidenceFilters(t *testing.T) {
+	withConfidence := func(c float64) map[string]interface{} {
+		return map[string]interface{}{storage.ProvenanceMetadataKey: map[string]interface{}{"confidence": c}}
+	}
+	results := []storage.VectorResult{
+		{ID: "none", Score: 0.9},
+		{ID: "low", Score: 0.8, Metadata: withConfidence(0.2)},
+		{ID: "high", Score: 0.5, Metadata: withConfidence(0.9)},
+	}
+
+	if kept := confidentVectors(results, 0.5); len(kept) != 1 || kept[0].ID != "high" {
+		t.Errorf("confidentVectors() = %+v, want only high", kept)
+	}
+	if kept := confidentVectors(results, 0); len(kept) != 3 {
+		t.Errorf("confidentVectors() without minimum dropped results: %+v", kept)
+	}
+
+	sortVectorsByConfidence(results)
+	if results[0].ID != "high" || results[1].ID != "low" || results[2].ID != "none" {
+		t.Errorf("sorted = %s, %s, %s; want high, low, none", results[0].ID, results[1].ID, results[2].ID)
+	}
+
+	high := 0.8
+	facts := map[string]interface{}{"a": "1", "b": "2"}
+	provenance := map[string]storage.Provenance{"a": {Confidence: &high}, "b": {SourceTool: "save_fact"}}
+	if kept := confidentFacts(facts, provenance, 0.8); len(kept) != 1 || kept["a"] != "1" {
+		t.Errorf("confidentFacts() = %v, want only a", kept)
+	}
+}
@@ -0,0 +1,218 @@
+package mcp_tools
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/madeindigio/remembrances-mcp/pkg/llm"
+)
+
+// rrfK dampens the weight of top ranks in reciprocal rank fusion; 60 is the
+// value from the original paper and works well without tuning.
+const rrfK = 60
+
+// querySynonyms maps common terse terms of agent queries to an alternative
+// phrasing. Lookups are by lowercase word.
+var querySynonyms = map[string]string{
+	"auth":           "authentication",
+	"authentication": "login",
+	"login":          "authentication",
+	"config":         "configuration",
+	"configuration":  "settings",
+	"settings":       "configuration",
+	"db":             "database",
+	"database":       "storage",
+	"doc":            "documentation",
+	"docs":           "documentation",
+	"repo":           "repository",
+	"func":           "function",
+	"fn":             "function",
+	"err":            "error",
+	"error":          "failure",
+	"bug":            "issue",
+	"issue":          "problem",
+	"fix":            "solution",
+	"env":            "environment",
+	"deps":           "dependencies",
+	"dependency":     "library",
+	"pref":           "preference",
+	"prefs":          "preferences",
+	"preference":     "setting",
+	"api":            "endpoint",
+	"test":           "spec",
+	"tests":          "specs",
+	"deploy":         "release",
+	"perf":           "performance",
+	"msg":            "message",
+	"user":           "person",
+	"todo":           "task",
+}
+
+// stemSuffixes are stripped from words that keep at least three characters,
+// in order, with the replacement appended.
+var stemSuffixes = []struct{ suffix, replacement string }{
+	{"ational", "ate"},
+	{"ization", "ize"},
+	{"fulness", "ful"},
+	{"ousness", "ous"},
+	{"iveness", "ive"},
+	{"ments", "ment"},
+	{"ingly", ""},
+	{"ies", "y"},
+	{"ied", "y"},
+	{"ing", ""},
+	{"ers", "er"},
+	{"edly", ""},
+	{"ly", ""},
+	{"ed", ""},
+	{"ches", "ch"},
+	{"shes", "sh"},
+	{"sses", "ss"},
+	{"xes", "x"},
+	{"s", ""},
+}
+
+// stemWord strips a common English inflection from a lowercase word.
+func stemWord(word string) string {
+	if strings.HasSuffix(word, "ss") || strings.HasSuffix(word, "us") || strings.HasSuffix(word, "is") {
+		return word
+	}
+	for _, s := range stemSuffixes {
+		if strings.HasSuffix(word, s.suffix) && len(word)-len(s.suffix) >= 3 {
+			return strings.TrimSuffix(word, s.suffix) + s.replacement
+		}
+	}
+	return word
+}
+
+// queryWords splits a query into lowercase words.
+func queryWords(query string) []string {
+	return strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// expandQuery returns query followed by its variants: the query with every
+// word stemmed, and the query with known terms replaced by a synonym.
+// Variants equal to an earlier query are dropped.
+func expandQuery(query string) []string {
+	words := queryWords(query)
+	stemmed := make([]string, len(words))
+	synonyms := make([]string, len(words))
+	for i, word := range words {
+		stemmed[i] = stemWord(word)
+		synonyms[i] = word
+		if synonym, ok := querySynonyms[word]; ok {
+			synonyms[i] = synonym
+		} else if synonym, ok := querySynonyms[stemmed[i]]; ok {
+			synonyms[i] = synonym
+		}
+	}
+
+	queries := []string{query}
+	seen := map[string]bool{strings.Join(words, " "): true}
+	for _, variant := range []string{strings.Join(stemmed, " "), strings.Join(synonyms, " ")} {
+		if variant != "" && !seen[variant] {
+			seen[variant] = true
+			queries = append(queries, variant)
+		}
+	}
+	return queries
+}
+
+// searchQueries returns the queries a search runs: the query itself, its
+// variants when expand is set, and a hypothetical answer written by the
+// configured LLM when hyde is set. A failed HyDE completion is logged and the
+// search goes on without it.
+func (tm *ToolManager) searchQueries(ctx context.Context, query string, expand, hyde bool) ([]string, error) {
+	queries := []string{query}
+	if expand {
+		queries = expandQuery(query)
+	}
+	if hyde {
+		if tm.llm == nil {
+			return nil, validationErrorf("hyde requires an LLM; configure llm-provider and llm-model")
+		}
+		passage, err := llm.HypotheticalDocument(ctx, tm.llm, query)
+		if err != nil {
+			slog.Warn("failed to generate hypothetical document, searching without it", "query", query, "error", err)
+		} else {
+			queries = append(queries, passage)
+		}
+	}
+	return queries, nil
+}
+
+// multiQuerySearch embeds every query and runs search for each embedding in
+// parallel. It returns one result list per query, in query order.
+func multiQuerySearch[T any](ctx context.Context, embed func(context.Context, string) ([]float32, error), queries []string, search func(context.Context, []float32) ([]T, error)) ([][]T, error) {
+	lists := make([][]T, len(queries))
+	errs := make([]error, len(queries))
+	var wg sync.WaitGroup
+	for i, query := range queries {
+		wg.Add(1)
+		go func(i int, query string) {
+			defer wg.Done()
+			embedding, err := embed(ctx, query)
+			if err != nil {
+				errs[i] = embedderErrorf(errGenQueryEmbedding, err)
+				return
+			}
+			lists[i], errs[i] = search(ctx, embedding)
+		}(i, query)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return lists, nil
+}
+
+// fuseRanked merges ranked result lists with reciprocal rank fusion: each
+// result scores the sum of 1/(rrfK+rank) over the lists it appears in, so
+// results found by several queries rise to the top. Results are identified by
+// key; the first occurrence is kept. At most limit results are returned.
+func fuseRanked[T any](lists [][]T, key func(T) string, limit int) []T {
+	type fused struct {
+		item  T
+		score float64
+		order int
+	}
+	byKey := map[string]*fused{}
+	for _, list := range lists {
+		for rank, item := range list {
+			k := key(item)
+			entry, ok := byKey[k]
+			if !ok {
+				entry = &fused{item: item, order: len(byKey)}
+				byKey[k] = entry
+			}
+			entry.score += 1 / float64(rrfK+rank+1)
+		}
+	}
+
+	entries := make([]*fused, 0, len(byKey))
+	for _, entry := range byKey {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].score != entries[j].score {
+			return entries[i].score > entries[j].score
+		}
+		return entries[i].order < entries[j].order
+	})
+	if limit > 0 && len(entries) > limit {
+		entries = entries[:limit]
+	}
+	items := make([]T, len(entries))
+	for i, entry := range entries {
+		items[i] = entry.item
+	}
+	return items
+}
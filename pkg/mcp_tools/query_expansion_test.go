@@ -0,0 +1,82 @@
+package mcp_tools
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestStemWord(t *testing.T) {
+	tests := map[string]string{
+		"indexing":  "index",
+		"deployed":  "deploy",
+		"queries":   "query",
+		"documents": "document",
+		"class":     "class",
+		"status":    "status",
+		"run":       "run",
+		"quickly":   "quick",
+		"matches":   "match",
+		"indexes":   "index",
+	}
+	for word, want := range tests {
+		if got := stemWord(word); got != want {
+			t.Errorf("stemWord(%q) = %q, want %q", word, got, want)
+		}
+	}
+}
+
+func TestExpandQuery(t *testing.T) {
+	got := expandQuery("Auth errors in deployed services")
+	want := []string{
+		"Auth errors in deployed services",
+		"auth error in deploy service",
+		"authentication failure in release services",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expandQuery() = %q, want %q", got, want)
+	}
+
+	if got := expandQuery("cat"); len(got) != 1 {
+		t.Errorf("a query without variants should stay alone, got %q", got)
+	}
+}
+
+func TestFuseRanked(t *testing.T) {
+	lists := [][]string{
+		{"a", "b", "c"},
+		{"c", "d"},
+		{"c", "b"},
+	}
+	got := fuseRanked(lists, func(s string) string { return s }, 3)
+	want := []string{"c", "b", "a"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("fuseRanked() = %q, want %q", got, want)
+	}
+}
+
+func TestMultiQuerySearch(t *testing.T) {
+	embed := func(_ context.Context, query string) ([]float32, error) {
+		if query == "broken" {
+			return nil, errors.New("model unavailable")
+		}
+		return []float32{float32(len(query))}, nil
+	}
+	search := func(_ context.Context, embedding []float32) ([]int, error) {
+		return []int{int(embedding[0])}, nil
+	}
+
+	lists, err := multiQuerySearch(context.Background(), embed, []string{"a", "abc"}, search)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(lists, [][]int{{1}, {3}}) {
+		t.Errorf("lists are not in query order: %v", lists)
+	}
+
+	_, err = multiQuerySearch(context.Background(), embed, []string{"a", "broken"}, search)
+	if errorCode(err) != ErrCodeEmbedderFailed {
+		t.Errorf("embedding failure: got %v, want an EMBEDDER_FAILED error", err)
+	}
+}
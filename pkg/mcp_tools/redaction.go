@@ -0,0 +1,16 @@
+package mcp_tools
+
+import (
+	"log/slog"
+)
+
+// redactContent runs content through the configured redaction filter before
+// tool stores it. Matches are logged by rule name only, never by value. In
+// reject mode a *redact.RejectedError is returned when anything matched.
+func (tm *ToolManager) redactContent(tool, content string) (string, error) {
+	redacted, findings, err := tm.redactor.Apply(content)
+	if len(findings) > 0 {
+		slog.Warn("sensitive content detected", "tool", tool, "mode", tm.redactor.Mode(), "findings", findings)
+	}
+	return redacted, err
+}
@@ -0,0 +1,141 @@
+package mcp_tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	"github.com/ThinkInAIXYZ/go-mcp/protocol"
+	"github.com/madeindigio/remembrances-mcp/internal/storage"
+)
+
+// Limits of remembrance_list_relationships and remembrance_graph_stats.
+const (
+	defaultRelationshipsLimit = 50
+	maxRelationshipsLimit     = 500
+	defaultGraphStatsTop      = 10
+	maxGraphStatsTop          = 100
+)
+
+func (tm *ToolManager) listRelationshipsTool() *protocol.Tool {
+	tool, err := protocol.NewTool("remembrance_list_relationships", `List the relationships of the knowledge graph, optionally of an entity or type, a page at a time. Use how_to_use("remembrance_list_relationships") for details.`, ListRelationshipsInput{})
+	if err != nil {
+		slog.Error("failed to create tool", "name", "remembrance_list_relationships", "err", err)
+		return nil
+	}
+	return tool
+}
+
+func (tm *ToolManager) graphStatsTool() *protocol.Tool {
+	tool, err := protocol.NewTool("remembrance_graph_stats", `Count the entities and relationships of the knowledge graph by type and list the most connected entities. Use how_to_use("remembrance_graph_stats") for details.`, GraphStatsInput{})
+	if err != nil {
+		slog.Error("failed to create tool", "name", "remembrance_graph_stats", "err", err)
+		return nil
+	}
+	return tool
+}
+
+func (tm *ToolManager) listRelationshipsHandler(ctx context.Context, request *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+	var input ListRelationshipsInput
+	if err := json.Unmarshal(request.RawArguments, &input); err != nil {
+		return nil, fmt.Errorf(errParseArgs, err)
+	}
+	filter, err := relationshipFilter(input)
+	if err != nil {
+		return nil, err
+	}
+
+	if filter.Entity != "" {
+		entity, err := tm.storage.GetEntity(ctx, filter.Entity)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get entity: %w", err)
+		}
+		if entity == nil {
+			suggestions := tm.FindEntityAlternatives(ctx, filter.Entity)
+			payload := CreateEmptyResultTOON(fmt.Sprintf("No entity found with name or ID '%s'", filter.Entity), suggestions)
+			return protocol.NewCallToolResult([]protocol.Content{
+				&protocol.TextContent{Type: "text", Text: payload},
+			}, false), nil
+		}
+	}
+
+	relationships, total, err := tm.storage.ListRelationships(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list relationships: %w", err)
+	}
+
+	response := map[string]interface{}{
+		"count":         len(relationships),
+		"total":         total,
+		"offset":        filter.Offset,
+		"relationships": relationships,
+	}
+	if filter.Entity != "" {
+		response["entity"] = filter.Entity
+		response["direction"] = filter.Direction
+	}
+	if filter.Type != "" {
+		response["relationship_type"] = filter.Type
+	}
+	if next := filter.Offset + len(relationships); next < total {
+		response["next_offset"] = next
+	}
+	return protocol.NewCallToolResult([]protocol.Content{
+		&protocol.TextContent{Type: "text", Text: MarshalTOON(response)},
+	}, false), nil
+}
+
+// relationshipFilter validates the arguments of remembrance_list_relationships
+// and fills in their defaults.
+func relationshipFilter(input ListRelationshipsInput) (storage.RelationshipFilter, error) {
+	filter := storage.RelationshipFilter{
+		Entity:       input.Entity,
+		Type:         input.RelationshipType,
+		Direction:    input.Direction,
+		IncludeEnded: input.IncludeEnded,
+		Limit:        input.Limit,
+		Offset:       input.Offset,
+	}
+	switch filter.Direction {
+	case "":
+		filter.Direction = storage.RelationshipsBoth
+	case storage.RelationshipsOutgoing, storage.RelationshipsIncoming, storage.RelationshipsBoth:
+	default:
+		return filter, validationErrorf("invalid direction %q: use out, in or both", filter.Direction)
+	}
+	if filter.Entity == "" && filter.Direction != storage.RelationshipsBoth {
+		return filter, validationErrorf("direction %q requires an entity", filter.Direction)
+	}
+	if filter.Limit == 0 {
+		filter.Limit = defaultRelationshipsLimit
+	}
+	if filter.Limit < 0 || filter.Limit > maxRelationshipsLimit {
+		return filter, validationErrorf("invalid limit %d: must be between 1 and %d", filter.Limit, maxRelationshipsLimit)
+	}
+	if filter.Offset < 0 {
+		return filter, validationErrorf("invalid offset %d: must not be negative", filter.Offset)
+	}
+	return filter, nil
+}
+
+func (tm *ToolManager) graphStatsHandler(ctx context.Context, request *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+	var input GraphStatsInput
+	if err := json.Unmarshal(request.RawArguments, &input); err != nil {
+		return nil, fmt.Errorf(errParseArgs, err)
+	}
+	if input.Top == 0 {
+		input.Top = defaultGraphStatsTop
+	}
+	if input.Top < 0 || input.Top > maxGraphStatsTop {
+		return nil, validationErrorf("invalid top %d: must be between 1 and %d", input.Top, maxGraphStatsTop)
+	}
+
+	stats, err := tm.storage.GetGraphStats(ctx, input.Top)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get graph stats: %w", err)
+	}
+	return protocol.NewCallToolResult([]protocol.Content{
+		&protocol.TextContent{Type: "text", Text: MarshalTOON(stats)},
+	}, false), nil
+}
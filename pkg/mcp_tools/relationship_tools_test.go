@@ -0,0 +1,29 @@
+package mcp_tools
+
+import (
+	"testing"
+
+	"github.com/madeindigio/remembrances-mcp/internal/storage"
+)
+
+func TestRelationshipFilter(t *testing.T) {
+	filter, err := relationshipFilter(ListRelationshipsInput{Entity: "Alice"})
+	if err != nil {
+		t.Fatalf("relationshipFilter() error = %v", err)
+	}
+	if filter.Direction != storage.RelationshipsBoth || filter.Limit != defaultRelationshipsLimit {
+		t.Errorf("relationshipFilter() = %+v, want both directions and the default limit", filter)
+	}
+
+	for _, input := range []ListRelationshipsInput{
+		{Entity: "Alice", Direction: "sideways"},
+		{Direction: storage.RelationshipsOutgoing},
+		{Limit: maxRelationshipsLimit + 1},
+		{Limit: -1},
+		{Offset: -1},
+	} {
+		if _, err := relationshipFilter(input); errorCode(err) != ErrCodeValidation {
+			t.Errorf("relationshipFilter(%+v) error = %v, want a validation error", input, err)
+		}
+	}
+}
@@ -0,0 +1,277 @@
+package mcp_tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/ThinkInAIXYZ/go-mcp/protocol"
+	"github.com/madeindigio/remembrances-mcp/internal/storage"
+)
+
+// savedSearchSchedulerTick is how often the scheduler looks for saved
+// searches due to run.
+const savedSearchSchedulerTick = time.Minute
+
+// minSavedSearchInterval is the shortest schedule a saved search may have.
+const minSavedSearchInterval = 5 * time.Minute
+
+// searchChangeRetention is how long the changes found by scheduled runs are kept.
+const searchChangeRetention = 30 * 24 * time.Hour
+
+// maxSearchChanges caps the changes remembrance_search_changes returns.
+const maxSearchChanges = 100
+
+// searchItemsKey is the context key of the searchItemCollector of a
+// scheduled saved search run.
+type searchItemsKey struct{}
+
+// searchItemCollector receives the results of the search tool a scheduled
+// run calls, so they can be compared with the previous run.
+type searchItemCollector struct {
+	items []storage.SearchChangeItem
+}
+
+func withSearchItemCollector(ctx context.Context) (context.Context, *searchItemCollector) {
+	collector := &searchItemCollector{}
+	return context.WithValue(ctx, searchItemsKey{}, collector), collector
+}
+
+// scheduledSearchRun reports whether ctx is a scheduled saved search run.
+func scheduledSearchRun(ctx context.Context) bool {
+	_, ok := ctx.Value(searchItemsKey{}).(*searchItemCollector)
+	return ok
+}
+
+// reportSearchItems hands the results of a search tool to the scheduled run
+// that called it, if any.
+func reportSearchItems(ctx context.Context, items []storage.SearchChangeItem) {
+	if collector, ok := ctx.Value(searchItemsKey{}).(*searchItemCollector); ok {
+		collector.items = append(collector.items, items...)
+	}
+}
+
+func documentSearchItems(results []storage.DocumentResult) []storage.SearchChangeItem {
+	items := make([]storage.SearchChangeItem, 0, len(results))
+	for _, r := range results {
+		if r.Document != nil {
+			items = append(items, storage.SearchChangeItem{Kind: storage.TrashKindDocument, Ref: r.Document.FilePath, Preview: usagePreview(r.Document.Content), Score: r.Score})
+		}
+	}
+	return items
+}
+
+func vectorSearchItems(results []storage.VectorResult) []storage.SearchChangeItem {
+	items := make([]storage.SearchChangeItem, 0, len(results))
+	for _, r := range results {
+		items = append(items, storage.SearchChangeItem{Kind: storage.TrashKindVector, Ref: r.ID, Preview: usagePreview(r.Content), Score: r.Score})
+	}
+	return items
+}
+
+func graphSearchItems(results []storage.GraphResult) []storage.SearchChangeItem {
+	items := make([]storage.SearchChangeItem, 0, len(results))
+	for _, r := range results {
+		if r.Entity != nil {
+			items = append(items, storage.SearchChangeItem{Kind: storage.TrashKindEntity, Ref: r.Entity.ID, Preview: fmt.Sprintf("%s (%s)", r.Entity.Name, r.Entity.Type)})
+		}
+	}
+	return items
+}
+
+func eventSearchItems(results []storage.EventSearchResult) []storage.SearchChangeItem {
+	items := make([]storage.SearchChangeItem, 0, len(results))
+	for _, r := range results {
+		items = append(items, storage.SearchChangeItem{Kind: "event", Ref: r.Event.ID, Preview: usagePreview(r.Event.Subject + ": " + r.Event.Content), Score: r.Relevance})
+	}
+	return items
+}
+
+func hybridSearchItems(results *storage.HybridSearchResult) []storage.SearchChangeItem {
+	items := append(vectorSearchItems(results.VectorResults), graphSearchItems(results.GraphResults)...)
+	for key, value := range results.Facts {
+		items = append(items, storage.SearchChangeItem{Kind: storage.TrashKindFact, Ref: key, Preview: usagePreview(fmt.Sprint(value))})
+	}
+	return items
+}
+
+// StartSavedSearchScheduler runs the saved searches that have an interval
+// when they are due, recording the results each run finds that the previous
+// run did not, until ctx is done. Changes older than searchChangeRetention
+// are dropped.
+func (tm *ToolManager) StartSavedSearchScheduler(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(savedSearchSchedulerTick)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			tm.runDueSearches(ctx, time.Now().UTC())
+		}
+	}()
+}
+
+// runDueSearches runs every scheduled saved search due at now.
+func (tm *ToolManager) runDueSearches(ctx context.Context, now time.Time) {
+	searches, err := tm.storage.ListSavedSearches(ctx, "")
+	if err != nil {
+		slog.Warn("failed to list saved searches", "error", err)
+		return
+	}
+	for _, search := range searches {
+		if !searchDue(search, now) {
+			continue
+		}
+		if err := tm.runScheduledSearch(ctx, search, now); err != nil {
+			slog.Warn("scheduled saved search failed", "user_id", search.UserID, "name", search.Name, "error", err)
+		}
+	}
+	if purged, err := tm.storage.PurgeSearchChanges(ctx, now.Add(-searchChangeRetention)); err != nil {
+		slog.Warn("failed to purge saved search changes", "error", err)
+	} else if purged > 0 {
+		slog.Debug("purged saved search changes", "count", purged)
+	}
+}
+
+// searchDue reports whether a saved search has a schedule and its interval
+// has passed since its last run.
+func searchDue(search storage.SavedSearch, now time.Time) bool {
+	if search.IntervalSeconds <= 0 {
+		return false
+	}
+	return search.LastRunAt == nil || !now.Before(search.LastRunAt.Add(time.Duration(search.IntervalSeconds)*time.Second))
+}
+
+// runScheduledSearch runs search and records its results and what is new
+// since its previous run.
+func (tm *ToolManager) runScheduledSearch(ctx context.Context, search storage.SavedSearch, now time.Time) error {
+	tool, ok := tm.savedSearchTools()[search.Tool]
+	if !ok {
+		return fmt.Errorf("unknown tool %q", search.Tool)
+	}
+	raw, err := json.Marshal(savedSearchArguments(search, tool, nil))
+	if err != nil {
+		return fmt.Errorf("failed to encode saved search arguments: %w", err)
+	}
+
+	runCtx, collector := withSearchItemCollector(ctx)
+	result, err := tool.handler(runCtx, &protocol.CallToolRequest{Name: search.Tool, RawArguments: raw})
+	if err != nil {
+		return err
+	}
+	if result != nil && result.IsError {
+		text, _ := resultText(result)
+		return fmt.Errorf("%s returned an error: %s", search.Tool, text)
+	}
+
+	refs, change := diffSearchItems(search, collector.items, now)
+	if change != nil {
+		slog.Info("scheduled saved search found new results", "user_id", search.UserID, "name", search.Name, "new", len(change.Items))
+	}
+	return tm.storage.RecordSavedSearchRun(ctx, search.UserID, search.Name, now, refs, change)
+}
+
+// diffSearchItems returns the refs of items, "kind:ref", and the change
+// listing the items the previous run of search did not return. The first run
+// of a search only records a baseline, so it has no change.
+func diffSearchItems(search storage.SavedSearch, items []storage.SearchChangeItem, now time.Time) ([]string, *storage.SearchChange) {
+	previous := make(map[string]bool, len(search.LastResults))
+	for _, ref := range search.LastResults {
+		previous[ref] = true
+	}
+
+	refs := make([]string, 0, len(items))
+	seen := make(map[string]bool, len(items))
+	var added []storage.SearchChangeItem
+	for _, item := range items {
+		ref := item.Kind + ":" + item.Ref
+		if seen[ref] {
+			continue
+		}
+		seen[ref] = true
+		refs = append(refs, ref)
+		if search.LastRunAt != nil && !previous[ref] {
+			added = append(added, item)
+		}
+	}
+	if len(added) == 0 {
+		return refs, nil
+	}
+	return refs, &storage.SearchChange{
+		UserID: search.UserID,
+		Name:   search.Name,
+		Tool:   search.Tool,
+		RunAt:  now,
+		Items:  added,
+	}
+}
+
+// parseSearchInterval parses the interval of a scheduled saved search. An
+// empty or zero interval leaves the search unscheduled.
+func parseSearchInterval(interval string) (int, error) {
+	interval = strings.TrimSpace(interval)
+	if interval == "" || interval == "0" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(interval)
+	if err != nil || d < minSavedSearchInterval {
+		return 0, validationErrorf("invalid interval %q; use a duration of at least %s, such as 1h", interval, minSavedSearchInterval)
+	}
+	return int(d / time.Second), nil
+}
+
+func (tm *ToolManager) searchChangesTool() *protocol.Tool {
+	tool, err := protocol.NewTool("remembrance_search_changes", `List what changed: the new results scheduled saved searches found since their previous run. Use how_to_use("remembrance_search_changes") for details.`, SearchChangesInput{})
+	if err != nil {
+		slog.Error("failed to create tool", "name", "remembrance_search_changes", "err", err)
+		return nil
+	}
+	return tool
+}
+
+func (tm *ToolManager) searchChangesHandler(ctx context.Context, request *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+	var input SearchChangesInput
+	if err := json.Unmarshal(request.RawArguments, &input); err != nil {
+		return nil, fmt.Errorf(errParseArgs, err)
+	}
+	if input.UserID == "" {
+		return nil, validationErrorf("user_id is required")
+	}
+	if input.Limit == 0 {
+		input.Limit = 20
+	}
+	if input.Limit < 0 || input.Limit > maxSearchChanges {
+		return nil, validationErrorf("invalid limit %d: must be between 1 and %d", input.Limit, maxSearchChanges)
+	}
+	var since time.Time
+	if input.Since != "" {
+		var err error
+		if since, err = time.Parse(time.RFC3339, input.Since); err != nil {
+			if since, err = time.Parse(time.DateOnly, input.Since); err != nil {
+				return nil, validationErrorf("invalid since %q; use an RFC 3339 time or a YYYY-MM-DD date", input.Since)
+			}
+		}
+	}
+
+	changes, err := tm.storage.ListSearchChanges(ctx, input.UserID, strings.TrimSpace(input.Name), since, input.Limit)
+	if err != nil {
+		return nil, err
+	}
+	response := map[string]interface{}{
+		"user_id": input.UserID,
+		"count":   len(changes),
+		"changes": changes,
+	}
+	if input.Name != "" {
+		response["name"] = input.Name
+	}
+	return protocol.NewCallToolResult([]protocol.Content{
+		&protocol.TextContent{Type: "text", Text: MarshalTOON(response)},
+	}, false), nil
+}
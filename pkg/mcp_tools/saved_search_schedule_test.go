@@ -0,0 +1,87 @@
+package mcp_tools
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/madeindigio/remembrances-mcp/internal/storage"
+)
+
+func TestSearchDue(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	lastRun := now.Add(-30 * time.Minute)
+	tests := []struct {
+		name   string
+		search storage.SavedSearch
+		want   bool
+	}{
+		{"unscheduled", storage.SavedSearch{}, false},
+		{"never run", storage.SavedSearch{IntervalSeconds: 3600}, true},
+		{"interval not passed", storage.SavedSearch{IntervalSeconds: 3600, LastRunAt: &lastRun}, false},
+		{"interval passed", storage.SavedSearch{IntervalSeconds: 1800, LastRunAt: &lastRun}, true},
+	}
+	for _, tt := range tests {
+		if got := searchDue(tt.search, now); got != tt.want {
+			t.Errorf("%s: searchDue() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestDiffSearchItems(t *testing.T) {
+	now := time.Now().UTC()
+	items := []storage.SearchChangeItem{
+		{Kind: "document", Ref: "a.md#chunk0"},
+		{Kind: "document", Ref: "b.md#chunk1"},
+		{Kind: "document", Ref: "a.md#chunk0"},
+	}
+
+	search := storage.SavedSearch{UserID: "u", Name: "docs", Tool: "kb_search_documents"}
+	refs, change := diffSearchItems(search, items, now)
+	if want := []string{"document:a.md#chunk0", "document:b.md#chunk1"}; !reflect.DeepEqual(refs, want) {
+		t.Errorf("refs = %v, want %v", refs, want)
+	}
+	if change != nil {
+		t.Errorf("first run recorded a change %+v, want only a baseline", change)
+	}
+
+	lastRun := now.Add(-time.Hour)
+	search.LastRunAt = &lastRun
+	search.LastResults = []string{"document:a.md#chunk0"}
+	_, change = diffSearchItems(search, items, now)
+	if change == nil || len(change.Items) != 1 || change.Items[0].Ref != "b.md#chunk1" || change.Name != "docs" {
+		t.Errorf("change = %+v, want b.md#chunk1 only", change)
+	}
+
+	search.LastResults = refs
+	if _, change = diffSearchItems(search, items, now); change != nil {
+		t.Errorf("unchanged results recorded a change %+v", change)
+	}
+}
+
+func TestParseSearchInterval(t *testing.T) {
+	for input, want := range map[string]int{"": 0, "0": 0, "1h": 3600, "5m": 300} {
+		if got, err := parseSearchInterval(input); err != nil || got != want {
+			t.Errorf("parseSearchInterval(%q) = %d, %v; want %d", input, got, err, want)
+		}
+	}
+	for _, input := range []string{"1m", "daily", "-1h"} {
+		if _, err := parseSearchInterval(input); errorCode(err) != ErrCodeValidation {
+			t.Errorf("parseSearchInterval(%q) = %v, want a validation error", input, err)
+		}
+	}
+}
+
+func TestReportSearchItems(t *testing.T) {
+	reportSearchItems(context.Background(), []storage.SearchChangeItem{{Kind: "vector", Ref: "v1"}})
+	if scheduledSearchRun(context.Background()) {
+		t.Error("a plain context is reported as a scheduled run")
+	}
+
+	ctx, collector := withSearchItemCollector(context.Background())
+	reportSearchItems(ctx, []storage.SearchChangeItem{{Kind: "vector", Ref: "v1"}})
+	if !scheduledSearchRun(ctx) || len(collector.items) != 1 {
+		t.Errorf("collector has %d items, want 1", len(collector.items))
+	}
+}
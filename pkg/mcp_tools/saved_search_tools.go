@@ -0,0 +1,225 @@
+package mcp_tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+
+	"github.com/ThinkInAIXYZ/go-mcp/protocol"
+	"github.com/madeindigio/remembrances-mcp/internal/storage"
+)
+
+// maxSavedSearchNameLength bounds the names searches are saved under.
+const maxSavedSearchNameLength = 128
+
+// savedSearchTool is a search tool a saved search can call.
+type savedSearchTool struct {
+	input   func() interface{}
+	handler func(context.Context, *protocol.CallToolRequest) (*protocol.CallToolResult, error)
+	// userScoped tools search the memories of their user_id argument, which
+	// defaults to the user the search is saved for.
+	userScoped bool
+}
+
+// savedSearchTools are the tools searches can be saved for, by name.
+func (tm *ToolManager) savedSearchTools() map[string]savedSearchTool {
+	return map[string]savedSearchTool{
+		"kb_search_documents": {func() interface{} { return &SearchDocumentsInput{} }, tm.searchDocumentsHandler, false},
+		"hybrid_search":       {func() interface{} { return &HybridSearchInput{} }, tm.hybridSearchHandler, true},
+		"search_vectors":      {func() interface{} { return &SearchVectorsInput{} }, tm.searchVectorsHandler, true},
+		"search_events":       {func() interface{} { return &SearchEventsInput{} }, tm.searchEventsHandler, true},
+		"traverse_graph":      {func() interface{} { return &TraverseGraphInput{} }, tm.traverseGraphHandler, false},
+	}
+}
+
+func (tm *ToolManager) saveSearchTool() *protocol.Tool {
+	tool, err := protocol.NewTool("remembrance_save_search", `Save the arguments of a search tool under a name to run it later with remembrance_run_saved_search. Use how_to_use("remembrance_save_search") for details.`, SaveSearchInput{})
+	if err != nil {
+		slog.Error("failed to create tool", "name", "remembrance_save_search", "err", err)
+		return nil
+	}
+	return tool
+}
+
+func (tm *ToolManager) runSavedSearchTool() *protocol.Tool {
+	tool, err := protocol.NewTool("remembrance_run_saved_search", `Run a saved search by name, or list the saved searches. Use how_to_use("remembrance_run_saved_search") for details.`, RunSavedSearchInput{})
+	if err != nil {
+		slog.Error("failed to create tool", "name", "remembrance_run_saved_search", "err", err)
+		return nil
+	}
+	return tool
+}
+
+func (tm *ToolManager) saveSearchHandler(ctx context.Context, request *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+	var input SaveSearchInput
+	if err := json.Unmarshal(request.RawArguments, &input); err != nil {
+		return nil, fmt.Errorf(errParseArgs, err)
+	}
+	input.Name = strings.TrimSpace(input.Name)
+	if input.UserID == "" {
+		return nil, validationErrorf("user_id is required")
+	}
+	if input.Name == "" || len(input.Name) > maxSavedSearchNameLength {
+		return nil, validationErrorf("name is required and must be at most %d bytes", maxSavedSearchNameLength)
+	}
+
+	if input.Delete {
+		deleted, err := tm.storage.DeleteSavedSearch(ctx, input.UserID, input.Name)
+		if err != nil {
+			return nil, err
+		}
+		if !deleted {
+			return nil, notFoundErrorf("no saved search %q for user %q", input.Name, input.UserID)
+		}
+		return protocol.NewCallToolResult([]protocol.Content{
+			&protocol.TextContent{Type: "text", Text: MarshalTOON(map[string]interface{}{
+				"user_id": input.UserID,
+				"name":    input.Name,
+				"deleted": true,
+			})},
+		}, false), nil
+	}
+
+	tool, ok := tm.savedSearchTools()[input.Tool]
+	if !ok {
+		return nil, validationErrorf("invalid tool %q; use one of %s", input.Tool, strings.Join(tm.savedSearchToolNames(), ", "))
+	}
+	interval, err := parseSearchInterval(input.Interval)
+	if err != nil {
+		return nil, err
+	}
+	arguments := input.Arguments.AsMap()
+	if arguments == nil {
+		arguments = map[string]interface{}{}
+	}
+	if err := checkToolArguments(tool, arguments); err != nil {
+		return nil, err
+	}
+
+	search := storage.SavedSearch{
+		UserID:          input.UserID,
+		Name:            input.Name,
+		Tool:            input.Tool,
+		Arguments:       arguments,
+		Description:     input.Description,
+		IntervalSeconds: interval,
+	}
+	if err := tm.storage.SaveSearch(ctx, search); err != nil {
+		return nil, err
+	}
+	response := map[string]interface{}{
+		"user_id":   search.UserID,
+		"name":      search.Name,
+		"tool":      search.Tool,
+		"arguments": search.Arguments,
+		"saved":     true,
+	}
+	if interval > 0 {
+		response["interval_seconds"] = interval
+	}
+	return protocol.NewCallToolResult([]protocol.Content{
+		&protocol.TextContent{Type: "text", Text: MarshalTOON(response)},
+	}, false), nil
+}
+
+func (tm *ToolManager) runSavedSearchHandler(ctx context.Context, request *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+	var input RunSavedSearchInput
+	if err := json.Unmarshal(request.RawArguments, &input); err != nil {
+		return nil, fmt.Errorf(errParseArgs, err)
+	}
+	if input.UserID == "" {
+		return nil, validationErrorf("user_id is required")
+	}
+
+	if input.Name == "" {
+		searches, err := tm.storage.ListSavedSearches(ctx, input.UserID)
+		if err != nil {
+			return nil, err
+		}
+		return protocol.NewCallToolResult([]protocol.Content{
+			&protocol.TextContent{Type: "text", Text: MarshalTOON(map[string]interface{}{
+				"user_id":  input.UserID,
+				"count":    len(searches),
+				"searches": searches,
+			})},
+		}, false), nil
+	}
+
+	search, err := tm.storage.GetSavedSearch(ctx, input.UserID, strings.TrimSpace(input.Name))
+	if err != nil {
+		return nil, err
+	}
+	if search == nil {
+		return nil, notFoundErrorf("no saved search %q for user %q; call remembrance_run_saved_search without a name to list them", input.Name, input.UserID)
+	}
+	tool, ok := tm.savedSearchTools()[search.Tool]
+	if !ok {
+		return nil, validationErrorf("saved search %q calls %q, which can no longer be run", search.Name, search.Tool)
+	}
+
+	arguments := savedSearchArguments(*search, tool, input.Arguments.AsMap())
+	if err := checkToolArguments(tool, arguments); err != nil {
+		return nil, err
+	}
+	raw, err := json.Marshal(arguments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode saved search arguments: %w", err)
+	}
+	slog.Debug("running saved search", "user_id", search.UserID, "name", search.Name, "tool", search.Tool)
+	return tool.handler(ctx, &protocol.CallToolRequest{Name: search.Tool, RawArguments: raw})
+}
+
+// savedSearchToolNames returns the tools searches can be saved for, sorted.
+func (tm *ToolManager) savedSearchToolNames() []string {
+	tools := tm.savedSearchTools()
+	names := make([]string, 0, len(tools))
+	for name := range tools {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// savedSearchArguments returns the arguments to call the tool of search
+// with: the saved ones with overrides applied, and the user of the search as
+// user_id of user-scoped tools that were saved without one.
+func savedSearchArguments(search storage.SavedSearch, tool savedSearchTool, overrides map[string]interface{}) map[string]interface{} {
+	arguments := mergeSearchArguments(search.Arguments, overrides)
+	if _, ok := arguments["user_id"]; !ok && tool.userScoped {
+		arguments["user_id"] = search.UserID
+	}
+	return arguments
+}
+
+// mergeSearchArguments returns the saved arguments with overrides applied.
+// A null override removes the saved argument.
+func mergeSearchArguments(saved, overrides map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(saved)+len(overrides))
+	for k, v := range saved {
+		merged[k] = v
+	}
+	for k, v := range overrides {
+		if v == nil {
+			delete(merged, k)
+			continue
+		}
+		merged[k] = v
+	}
+	return merged
+}
+
+// checkToolArguments reports arguments that do not decode into the input of
+// tool, such as a string where a number is expected.
+func checkToolArguments(tool savedSearchTool, arguments map[string]interface{}) error {
+	raw, err := json.Marshal(arguments)
+	if err != nil {
+		return validationErrorf("invalid arguments: %v", err)
+	}
+	if err := json.Unmarshal(raw, tool.input()); err != nil {
+		return validationErrorf("invalid arguments: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+package mcp_tools
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMergeSearchArguments(t *testing.T) {
+	saved := map[string]interface{}{"query": "auth", "limit": 5, "tags": []interface{}{"security"}}
+	got := mergeSearchArguments(saved, map[string]interface{}{"query": "tokens", "tags": nil})
+	want := map[string]interface{}{"query": "tokens", "limit": 5}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("mergeSearchArguments() = %v, want %v", got, want)
+	}
+	if saved["query"] != "auth" {
+		t.Error("mergeSearchArguments modified the saved arguments")
+	}
+}
+
+func TestCheckToolArguments(t *testing.T) {
+	tool := savedSearchTool{input: func() interface{} { return &SearchDocumentsInput{} }}
+	if err := checkToolArguments(tool, map[string]interface{}{"query": "auth", "limit": 3}); err != nil {
+		t.Errorf("valid arguments rejected: %v", err)
+	}
+	if err := checkToolArguments(tool, map[string]interface{}{"limit": "three"}); errorCode(err) != ErrCodeValidation {
+		t.Errorf("checkToolArguments(limit: three) = %v, want a validation error", err)
+	}
+}
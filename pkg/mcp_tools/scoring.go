@@ -0,0 +1,105 @@
+package mcp_tools
+
+import (
+	"log/slog"
+	"strings"
+
+	"github.com/madeindigio/remembrances-mcp/internal/storage"
+)
+
+// Score normalization modes for the "score" field of search results.
+const (
+	ScoreNormalizationRaw    = "raw"    // score is the cosine similarity
+	ScoreNormalizationMinMax = "minmax" // score is min-max scaled to 0-1 within each result set
+)
+
+// scoring is the similarity cutoff and score normalization shared by the
+// vector, knowledge base and code search tools.
+type scoring struct {
+	minSimilarity float64
+	normalization string
+}
+
+// newScoring validates a scoring configuration. Unknown normalization modes
+// fall back to raw, and the cutoff is clamped to 0-1.
+func newScoring(minSimilarity float64, normalization string) scoring {
+	mode := strings.ToLower(strings.TrimSpace(normalization))
+	switch mode {
+	case ScoreNormalizationRaw, ScoreNormalizationMinMax:
+	case "":
+		mode = ScoreNormalizationRaw
+	default:
+		slog.Warn("invalid score normalization, using raw", "normalization", normalization)
+		mode = ScoreNormalizationRaw
+	}
+	if minSimilarity < 0 {
+		minSimilarity = 0
+	}
+	if minSimilarity > 1 {
+		minSimilarity = 1
+	}
+	return scoring{minSimilarity: minSimilarity, normalization: mode}
+}
+
+// SetScoring configures the minimum cosine similarity of search results and
+// how their score is normalized (raw or minmax).
+func (tm *ToolManager) SetScoring(minSimilarity float64, normalization string) {
+	tm.scoring = newScoring(minSimilarity, normalization)
+}
+
+// SetScoring configures the minimum cosine similarity of code search results
+// and how their score is normalized (raw or minmax).
+func (cstm *CodeSearchToolManager) SetScoring(minSimilarity float64, normalization string) {
+	cstm.scoring = newScoring(minSimilarity, normalization)
+}
+
+// cutoff returns the minimum similarity of a search: the per-call
+// min_similarity when set, the configured one otherwise.
+func (s scoring) cutoff(perCall float64) float64 {
+	if perCall > 0 {
+		return perCall
+	}
+	return s.minSimilarity
+}
+
+// applyScoring drops the items whose raw similarity is below the cutoff and sets
+// the score of the others according to the normalization mode. Items keep
+// their order.
+func applyScoring[T any](s scoring, items []T, perCall float64, similarity func(T) float64, setScore func(*T, float64)) []T {
+	cutoff := s.cutoff(perCall)
+	kept := items[:0:0]
+	lo, hi := 0.0, 0.0
+	for _, item := range items {
+		sim := similarity(item)
+		if sim < cutoff {
+			continue
+		}
+		if len(kept) == 0 || sim < lo {
+			lo = sim
+		}
+		if len(kept) == 0 || sim > hi {
+			hi = sim
+		}
+		kept = append(kept, item)
+	}
+
+	for i := range kept {
+		score := similarity(kept[i])
+		if s.normalization == ScoreNormalizationMinMax {
+			score = 1
+			if hi > lo {
+				score = (similarity(kept[i]) - lo) / (hi - lo)
+			}
+		}
+		setScore(&kept[i], score)
+	}
+	return kept
+}
+
+func vectorSimilarity(r storage.VectorResult) float64 { return r.Similarity }
+
+func setVectorScore(r *storage.VectorResult, score float64) { r.Score = score }
+
+func documentSimilarity(r storage.DocumentResult) float64 { return r.Similarity }
+
+func setDocumentScore(r *storage.DocumentResult, score float64) { r.Score = score }
@@ -0,0 +1,59 @@
+package mcp_tools
+
+import "testing"
+
+type scoredItem struct {
+	similarity float64
+	score      float64
+}
+
+func scoreItems(s scoring, perCall float64, similarities ...float64) []scoredItem {
+	items := make([]scoredItem, len(similarities))
+	for i, sim := range similarities {
+		items[i] = scoredItem{similarity: sim}
+	}
+	return applyScoring(s, items, perCall,
+		func(it scoredItem) float64 { return it.similarity },
+		func(it *scoredItem, score float64) { it.score = score })
+}
+
+func TestApplyScoringRaw(t *testing.T) {
+	items := scoreItems(newScoring(0.5, "raw"), 0, 0.9, 0.4, 0.6)
+	if len(items) != 2 {
+		t.Fatalf("expected 2 items above the cutoff, got %d", len(items))
+	}
+	for _, it := range items {
+		if it.score != it.similarity {
+			t.Errorf("raw score %v, want similarity %v", it.score, it.similarity)
+		}
+	}
+}
+
+func TestApplyScoringMinMax(t *testing.T) {
+	items := scoreItems(newScoring(0, "MinMax"), 0, 0.9, 0.7, 0.8)
+	want := []float64{1, 0, 0.5}
+	for i, it := range items {
+		if diff := it.score - want[i]; diff > 1e-9 || diff < -1e-9 {
+			t.Errorf("item %d: score %v, want %v", i, it.score, want[i])
+		}
+	}
+
+	single := scoreItems(newScoring(0, "minmax"), 0, 0.42)
+	if single[0].score != 1 {
+		t.Errorf("single result scored %v, want 1", single[0].score)
+	}
+}
+
+func TestApplyScoringPerCallCutoff(t *testing.T) {
+	items := scoreItems(newScoring(0.8, "raw"), 0.3, 0.9, 0.5, 0.2)
+	if len(items) != 2 {
+		t.Fatalf("per-call min_similarity should override the configured one, got %d items", len(items))
+	}
+}
+
+func TestNewScoringDefaults(t *testing.T) {
+	s := newScoring(1.5, "bogus")
+	if s.normalization != ScoreNormalizationRaw || s.minSimilarity != 1 {
+		t.Errorf("got %+v, want raw normalization and cutoff clamped to 1", s)
+	}
+}
@@ -0,0 +1,137 @@
+package mcp_tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/ThinkInAIXYZ/go-mcp/protocol"
+	"github.com/madeindigio/remembrances-mcp/internal/storage"
+)
+
+const (
+	defaultSubscribeSeconds = 30
+	maxSubscribeSeconds     = 600
+	defaultSubscribeChanges = 100
+)
+
+// progressFunc sends a progress notification for the tool call in ctx.
+type progressFunc func(ctx context.Context, notify *protocol.ProgressNotification) error
+
+// subscribeLayers maps the layers remembrance_subscribe watches to their tables.
+var subscribeLayers = map[string]string{
+	"facts":     "kv_memories",
+	"vectors":   "vector_memories",
+	"documents": "knowledge_base",
+	"events":    "events",
+	"entities":  "entities",
+}
+
+func (tm *ToolManager) subscribeTool() *protocol.Tool {
+	tool, err := protocol.NewTool("remembrance_subscribe", `Watch a memory layer for changes, streaming them as progress notifications and returning them when the window ends. Use how_to_use("remembrance_subscribe") for details.`, SubscribeInput{})
+	if err != nil {
+		slog.Error("failed to create tool", "name", "remembrance_subscribe", "err", err)
+		return nil
+	}
+	return tool
+}
+
+// subscribeOptions validates the input and returns the table to watch, the
+// user to filter by, the watch window and the change limit.
+func subscribeOptions(input SubscribeInput) (table, userID string, window time.Duration, maxChanges int, err error) {
+	table, ok := subscribeLayers[input.Layer]
+	if !ok {
+		return "", "", 0, 0, validationErrorf("invalid layer %q: use facts, vectors, documents, events or entities", input.Layer)
+	}
+	userID = input.UserID
+	if input.Layer == "documents" {
+		// Documents are shared, not owned by a user
+		userID = ""
+	}
+
+	seconds := input.DurationSeconds
+	if seconds <= 0 {
+		seconds = defaultSubscribeSeconds
+	}
+	if seconds > maxSubscribeSeconds {
+		seconds = maxSubscribeSeconds
+	}
+	maxChanges = input.MaxChanges
+	if maxChanges <= 0 {
+		maxChanges = defaultSubscribeChanges
+	}
+	return table, userID, time.Duration(seconds) * time.Second, maxChanges, nil
+}
+
+func (tm *ToolManager) subscribeHandler(ctx context.Context, request *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+	var input SubscribeInput
+	if err := json.Unmarshal(request.RawArguments, &input); err != nil {
+		return nil, fmt.Errorf(errParseArgs, err)
+	}
+	table, userID, window, maxChanges, err := subscribeOptions(input)
+	if err != nil {
+		return nil, err
+	}
+
+	watchCtx, cancel := context.WithTimeout(ctx, window)
+	defer cancel()
+	changes, err := tm.storage.WatchChanges(watchCtx, table, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	started := time.Now()
+	collected := []storage.ChangeEvent{}
+	streaming := tm.progressNotifier != nil
+	for change := range changes {
+		collected = append(collected, change)
+		if streaming {
+			streaming = tm.notifyChange(ctx, change, len(collected), maxChanges)
+		}
+		if len(collected) >= maxChanges {
+			break
+		}
+	}
+
+	response := map[string]interface{}{
+		"layer":           input.Layer,
+		"watched_seconds": int(time.Since(started).Round(time.Second).Seconds()),
+		"count":           len(collected),
+		"streamed":        streaming && len(collected) > 0,
+		"changes":         collected,
+	}
+	if userID != "" {
+		response["user_id"] = userID
+	}
+	if len(collected) >= maxChanges {
+		response["message"] = fmt.Sprintf("Stopped after %d changes; subscribe again to keep watching", maxChanges)
+	}
+
+	return protocol.NewCallToolResult([]protocol.Content{
+		&protocol.TextContent{Type: "text", Text: MarshalTOON(response)},
+	}, false), nil
+}
+
+// notifyChange sends a change to the client as a progress notification whose
+// message is the change as JSON. It reports false when the client cannot
+// receive notifications, e.g. because it sent no progress token, so the
+// remaining changes are only returned with the result.
+func (tm *ToolManager) notifyChange(ctx context.Context, change storage.ChangeEvent, count, maxChanges int) bool {
+	message, err := json.Marshal(change)
+	if err != nil {
+		slog.Warn("failed to encode change notification", "id", change.ID, "error", err)
+		return true
+	}
+	err = tm.progressNotifier(ctx, &protocol.ProgressNotification{
+		Progress: float64(count),
+		Total:    float64(maxChanges),
+		Message:  string(message),
+	})
+	if err != nil {
+		slog.Debug("change notifications not delivered, returning changes with the result", "error", err)
+		return false
+	}
+	return true
+}
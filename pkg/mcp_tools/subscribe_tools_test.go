@@ -0,0 +1,34 @@
+package mcp_tools
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSubscribeOptions(t *testing.T) {
+	table, userID, window, maxChanges, err := subscribeOptions(SubscribeInput{Layer: "vectors", UserID: "u1"})
+	if err != nil {
+		t.Fatalf("subscribeOptions() error = %v", err)
+	}
+	if table != "vector_memories" || userID != "u1" || window != defaultSubscribeSeconds*time.Second || maxChanges != defaultSubscribeChanges {
+		t.Errorf("subscribeOptions() = %q, %q, %v, %d", table, userID, window, maxChanges)
+	}
+
+	_, userID, window, maxChanges, err = subscribeOptions(SubscribeInput{Layer: "documents", UserID: "u1", DurationSeconds: 3600, MaxChanges: 5})
+	if err != nil {
+		t.Fatalf("subscribeOptions() error = %v", err)
+	}
+	if userID != "" {
+		t.Errorf("documents are shared, user_id = %q, want none", userID)
+	}
+	if window != maxSubscribeSeconds*time.Second {
+		t.Errorf("window = %v, want it capped at %ds", window, maxSubscribeSeconds)
+	}
+	if maxChanges != 5 {
+		t.Errorf("maxChanges = %d, want 5", maxChanges)
+	}
+
+	if _, _, _, _, err := subscribeOptions(SubscribeInput{Layer: "trash"}); err == nil {
+		t.Error("subscribeOptions() accepted an unknown layer")
+	}
+}
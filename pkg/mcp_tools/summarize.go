@@ -0,0 +1,179 @@
+package mcp_tools
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Limits of the extractive summaries returned with summarize=true.
+const (
+	summaryLineChars   = 200 // Longer summary lines are cut at a word boundary
+	summaryParagraphs  = 20  // Paragraph openings kept from documents without headings
+	skeletonHeadLines  = 5   // Lines searched for the end of a symbol's declaration
+	skeletonMaxMembers = 40  // Member and block lines kept in a symbol skeleton
+)
+
+// summarizeDocument returns an extractive summary of a markdown document:
+// every heading followed by the first sentence of its first paragraph.
+// Documents without headings keep the first sentence of each paragraph.
+// Code blocks are left out.
+func summarizeDocument(content string) string {
+	var out []string
+	inFence := false
+	wantOpening := true
+	paragraphs := 0
+	hasHeadings := strings.Contains("\n"+content, "\n#")
+
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+		switch {
+		case trimmed == "":
+			if !hasHeadings {
+				wantOpening = true
+			}
+		case strings.HasPrefix(trimmed, "#"):
+			if len(out) > 0 {
+				out = append(out, "")
+			}
+			out = append(out, trimmed)
+			wantOpening = true
+		case wantOpening:
+			if !hasHeadings {
+				if paragraphs == summaryParagraphs {
+					out = append(out, "…")
+					return strings.Join(out, "\n")
+				}
+				paragraphs++
+			}
+			out = append(out, firstSentence(trimmed))
+			wantOpening = false
+		}
+	}
+	return strings.Join(out, "\n")
+}
+
+// firstSentence returns the first sentence of a line, cut at
+// summaryLineChars.
+func firstSentence(line string) string {
+	for i := 0; i < len(line)-1; i++ {
+		if (line[i] == '.' || line[i] == '!' || line[i] == '?') && line[i+1] == ' ' {
+			line = line[:i+1]
+			break
+		}
+	}
+	if len(line) <= summaryLineChars {
+		return line
+	}
+	cut, _ := truncateText(line, 0, summaryLineChars)
+	if i := strings.LastIndex(cut, " "); i > 0 {
+		cut = cut[:i]
+	}
+	return cut + " …"
+}
+
+// symbolSkeleton returns the outline of a symbol's source code: its
+// declaration, the lines at the first nesting level that open a block (member
+// declarations of classes, control flow of functions) and the closing line.
+// Elided lines are replaced with "…".
+func symbolSkeleton(source string) string {
+	lines := strings.Split(strings.TrimRight(source, "\n"), "\n")
+	if len(lines) <= 3 {
+		return strings.Join(lines, "\n")
+	}
+
+	// The declaration ends at the first line opening the body
+	head := 1
+	for i := 0; i < len(lines) && i < skeletonHeadLines; i++ {
+		if opensBlock(lines[i]) {
+			head = i + 1
+			break
+		}
+	}
+	last := len(lines) - 1
+	closing := isClosingLine(lines[last])
+	bodyEnd := len(lines)
+	if closing {
+		bodyEnd = last
+	}
+
+	// Members are the block-opening lines at the shallowest indentation of the body
+	indent, indentLine := -1, ""
+	for _, line := range lines[head:bodyEnd] {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if n := indentWidth(line); indent < 0 || n < indent {
+			indent, indentLine = n, line
+		}
+	}
+
+	out := append([]string{}, lines[:head]...)
+	elided, members := 0, 0
+	for _, line := range lines[head:bodyEnd] {
+		if members < skeletonMaxMembers && indentWidth(line) == indent && opensBlock(line) {
+			if elided > 0 {
+				out = append(out, elisionLine(line, elided))
+				elided = 0
+			}
+			out = append(out, line)
+			members++
+			continue
+		}
+		if strings.TrimSpace(line) != "" {
+			elided++
+		}
+	}
+	if elided > 0 {
+		out = append(out, elisionLine(indentLine, elided))
+	}
+	if closing {
+		out = append(out, lines[last])
+	}
+	return strings.Join(out, "\n")
+}
+
+// opensBlock reports whether a line starts a block: it ends with an opening
+// brace, a colon (Python, YAML) or an arrow.
+func opensBlock(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	if i := strings.Index(trimmed, "//"); i >= 0 {
+		trimmed = strings.TrimSpace(trimmed[:i])
+	}
+	return strings.HasSuffix(trimmed, "{") || strings.HasSuffix(trimmed, ":") || strings.HasSuffix(trimmed, "=>")
+}
+
+// isClosingLine reports whether a line only closes brackets.
+func isClosingLine(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	return trimmed != "" && strings.Trim(trimmed, "})];,") == ""
+}
+
+// indentWidth returns the width of a line's leading whitespace, counting a
+// tab as four spaces.
+func indentWidth(line string) int {
+	width := 0
+	for _, r := range line {
+		switch r {
+		case ' ':
+			width++
+		case '\t':
+			width += 4
+		default:
+			return width
+		}
+	}
+	return width
+}
+
+// elisionLine marks n elided lines, indented like line.
+func elisionLine(line string, n int) string {
+	indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+	return fmt.Sprintf("%s… (%d lines)", indent, n)
+}
@@ -0,0 +1,90 @@
+package mcp_tools
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSummarizeDocument(t *testing.T) {
+	doc := `# Authentication
+
+Tokens are issued by the gateway. They expire after one hour.
+
+More detail that is left out.
+
+## Refresh
+
+` + "```go\nrefresh(token)\n```" + `
+Call refresh before the token expires! Otherwise log in again.
+`
+	want := `# Authentication
+Tokens are issued by the gateway.
+
+## Refresh
+Call refresh before the token expires!`
+	if got := summarizeDocument(doc); got != want {
+		t.Errorf("summarizeDocument() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestSummarizeDocumentWithoutHeadings(t *testing.T) {
+	doc := "First paragraph. Second sentence.\ncontinued line\n\nSecond paragraph here."
+	want := "First paragraph.\nSecond paragraph here."
+	if got := summarizeDocument(doc); got != want {
+		t.Errorf("summarizeDocument() = %q, want %q", got, want)
+	}
+}
+
+func TestFirstSentenceCutsLongLines(t *testing.T) {
+	line := strings.Repeat("word ", 100)
+	got := firstSentence(line)
+	if len(got) > summaryLineChars+len(" …") || !strings.HasSuffix(got, " …") {
+		t.Errorf("firstSentence() = %q, want a line cut at %d chars", got, summaryLineChars)
+	}
+}
+
+func TestSymbolSkeleton(t *testing.T) {
+	source := `type Server struct {
+	addr string
+	port int
+}`
+	if got := symbolSkeleton(source); got != "type Server struct {\n\t… (2 lines)\n}" {
+		t.Errorf("struct skeleton = %q", got)
+	}
+
+	source = `func (s *Server) Start(ctx context.Context) error {
+	ln, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return err
+	}
+	for {
+		conn, err := ln.Accept()
+		go s.handle(conn)
+	}
+}`
+	want := `func (s *Server) Start(ctx context.Context) error {
+	… (1 lines)
+	if err != nil {
+	… (2 lines)
+	for {
+	… (3 lines)
+}`
+	if got := symbolSkeleton(source); got != want {
+		t.Errorf("function skeleton =\n%s\nwant\n%s", got, want)
+	}
+
+	source = `class Greeter:
+    def __init__(self, name):
+        self.name = name
+
+    def greet(self):
+        return "hi " + self.name`
+	want = `class Greeter:
+    def __init__(self, name):
+    … (1 lines)
+    def greet(self):
+    … (1 lines)`
+	if got := symbolSkeleton(source); got != want {
+		t.Errorf("python skeleton =\n%s\nwant\n%s", got, want)
+	}
+}
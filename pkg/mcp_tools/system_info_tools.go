@@ -0,0 +1,99 @@
+package mcp_tools
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+
+	"github.com/ThinkInAIXYZ/go-mcp/protocol"
+
+	"github.com/madeindigio/remembrances-mcp/pkg/embedder"
+	"github.com/madeindigio/remembrances-mcp/pkg/treesitter"
+	"github.com/madeindigio/remembrances-mcp/pkg/version"
+)
+
+// SetSystemInfo sets what remembrance_system_info reports beyond the tool
+// manager's own settings: the storage backend and the loaded toolsets.
+func (tm *ToolManager) SetSystemInfo(storageBackend string, toolsets func() []string) {
+	tm.storageBackend = storageBackend
+	tm.toolsets = toolsets
+}
+
+func (tm *ToolManager) systemInfoTool() *protocol.Tool {
+	tool, err := protocol.NewTool("remembrance_system_info", `Report the server version, enabled toolsets, embedder, storage and supported code languages so clients can adapt to this server. Use how_to_use("remembrance_system_info") for details.`, SystemInfoInput{})
+	if err != nil {
+		slog.Error("failed to create tool", "name", "remembrance_system_info", "err", err)
+		return nil
+	}
+	return tool
+}
+
+func (tm *ToolManager) systemInfoHandler(ctx context.Context, request *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+	server := map[string]interface{}{
+		"name":     "remembrances-mcp",
+		"version":  version.Version,
+		"commit":   version.CommitHash,
+		"lib_mode": version.LibMode,
+	}
+	if version.Variant != "" && version.Variant != "unknown" {
+		server["variant"] = version.Variant
+	}
+
+	embedding := map[string]interface{}{"dimension": tm.embedder.Dimension()}
+	if tm.embeddingModel != "" {
+		embedding["model"] = tm.embeddingModel
+	}
+	if reporter, ok := tm.embedder.(embedder.BackendReporter); ok {
+		embedding["backend"] = reporter.Backend()
+	}
+	if tm.router != nil {
+		routes := map[string]interface{}{}
+		for _, kind := range embedder.RouteKinds {
+			routes[kind] = tm.router.Route(kind).Model
+		}
+		embedding["routes"] = routes
+	}
+
+	storageInfo := map[string]interface{}{}
+	if tm.storageBackend != "" {
+		storageInfo["backend"] = tm.storageBackend
+	}
+	if info, err := tm.storage.GetDatabaseInfo(ctx); err != nil {
+		slog.Warn("failed to get database info", "error", err)
+	} else {
+		storageInfo["mode"] = info.Mode
+		storageInfo["schema_version"] = info.SchemaVersion
+		storageInfo["latest_schema_version"] = info.LatestSchemaVersion
+	}
+
+	var toolsets []string
+	if tm.toolsets != nil {
+		toolsets = tm.toolsets()
+	}
+
+	languages := make([]string, 0)
+	for _, lang := range treesitter.GetSupportedLanguages() {
+		languages = append(languages, string(lang))
+	}
+	sort.Strings(languages)
+
+	response := map[string]interface{}{
+		"server":    server,
+		"toolsets":  toolsets,
+		"embedding": embedding,
+		"storage":   storageInfo,
+		"languages": languages,
+		"features": map[string]interface{}{
+			"llm":              tm.llm != nil,
+			"redaction":        tm.redactor != nil,
+			"progress":         tm.progressNotifier != nil,
+			"log_levels":       tm.logLevels != nil,
+			"kb_watch":         tm.kbWatchers != nil && len(tm.kbWatchers()) > 0,
+			"code_embedder":    tm.codeEmbedder != nil && tm.codeEmbedder != tm.embedder,
+			"embedding_routes": tm.router != nil,
+		},
+	}
+	return protocol.NewCallToolResult([]protocol.Content{
+		&protocol.TextContent{Type: "text", Text: MarshalTOON(response)},
+	}, false), nil
+}
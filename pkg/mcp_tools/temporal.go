@@ -0,0 +1,72 @@
+package mcp_tools
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/madeindigio/remembrances-mcp/internal/storage"
+)
+
+// parseTimeArg parses a time argument given as an RFC 3339 time or a
+// YYYY-MM-DD date, which stands for its start in UTC. An empty value is the
+// zero time.
+func parseTimeArg(name, value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.UTC(), nil
+	}
+	if t, err := time.Parse(time.DateOnly, value); err == nil {
+		return t, nil
+	}
+	return time.Time{}, validationErrorf("invalid %s %q; use an RFC 3339 time or a YYYY-MM-DD date", name, value)
+}
+
+// withValidity returns a context whose fact and relationship writes hold
+// over the interval of input.
+func withValidity(ctx context.Context, input ValidityInput) (context.Context, error) {
+	from, err := parseTimeArg("valid_from", input.ValidFrom)
+	if err != nil {
+		return ctx, err
+	}
+	until, err := parseTimeArg("valid_until", input.ValidUntil)
+	if err != nil {
+		return ctx, err
+	}
+	if from.IsZero() && until.IsZero() {
+		return ctx, nil
+	}
+
+	var validity storage.Validity
+	if !from.IsZero() {
+		validity.From = &from
+	}
+	if !until.IsZero() {
+		start := from
+		if start.IsZero() {
+			start = time.Now()
+		}
+		if !until.After(start) {
+			return ctx, validationErrorf("valid_until %s must be after valid_from, which defaults to now", until.Format(time.RFC3339))
+		}
+		validity.Until = &until
+	}
+	return storage.WithValidity(ctx, validity), nil
+}
+
+// factVersionsByKey returns the values and provenance of fact versions, by
+// key.
+func factVersionsByKey(versions []storage.FactVersion) (map[string]interface{}, map[string]storage.Provenance) {
+	facts := make(map[string]interface{}, len(versions))
+	provenance := map[string]storage.Provenance{}
+	for _, v := range versions {
+		facts[v.Key] = v.Value
+		if v.Provenance != nil {
+			provenance[v.Key] = *v.Provenance
+		}
+	}
+	return facts, provenance
+}
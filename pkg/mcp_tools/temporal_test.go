@@ -0,0 +1,39 @@
+package mcp_tools
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestParseTimeArg(t *testing.T) {
+	for value, want := range map[string]time.Time{
+		"":                          {},
+		"2025-03-01":                time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC),
+		"2025-03-01T10:30:00+02:00": time.Date(2025, 3, 1, 8, 30, 0, 0, time.UTC),
+	} {
+		got, err := parseTimeArg("as_of", value)
+		if err != nil || !got.Equal(want) {
+			t.Errorf("parseTimeArg(%q) = %v, %v; want %v", value, got, err, want)
+		}
+	}
+	if _, err := parseTimeArg("as_of", "last week"); errorCode(err) != ErrCodeValidation {
+		t.Errorf("parseTimeArg(invalid) error = %v, want a validation error", err)
+	}
+}
+
+func TestWithValidity(t *testing.T) {
+	ctx := context.Background()
+	if got, err := withValidity(ctx, ValidityInput{}); err != nil || got != ctx {
+		t.Errorf("withValidity() without interval = %v, %v; want ctx unchanged", got, err)
+	}
+	if _, err := withValidity(ctx, ValidityInput{ValidFrom: "2025-01-01", ValidUntil: "2024-01-01"}); errorCode(err) != ErrCodeValidation {
+		t.Errorf("withValidity() ending before it starts error = %v, want a validation error", err)
+	}
+	if _, err := withValidity(ctx, ValidityInput{ValidUntil: "2001-01-01"}); errorCode(err) != ErrCodeValidation {
+		t.Errorf("withValidity() ending in the past from now error = %v, want a validation error", err)
+	}
+	if _, err := withValidity(ctx, ValidityInput{ValidFrom: "2024-01-01", ValidUntil: "2025-01-01"}); err != nil {
+		t.Errorf("withValidity() = %v", err)
+	}
+}
@@ -0,0 +1,107 @@
+package mcp_tools
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"unicode"
+
+	"github.com/madeindigio/remembrances-mcp/internal/storage"
+)
+
+// estimateTokens approximates the number of tokens a language model
+// tokenizer produces for s: a token per four characters of each word,
+// rounded up, and a token per punctuation or symbol character. It errs on
+// the high side for prose, like the BPE tokenizers it stands in for.
+func estimateTokens(s string) int {
+	tokens, word := 0, 0
+	flush := func() {
+		tokens += (word + 3) / 4
+		word = 0
+	}
+	for _, r := range s {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			word++
+		case unicode.IsSpace(r):
+			flush()
+		default:
+			flush()
+			tokens++
+		}
+	}
+	flush()
+	return tokens
+}
+
+// itemTokens estimates the tokens of a result item as it is serialized.
+func itemTokens(item interface{}) int {
+	data, err := json.Marshal(item)
+	if err != nil {
+		return estimateTokens(fmt.Sprint(item))
+	}
+	return estimateTokens(string(data))
+}
+
+// tokenBudget reports how a hybrid_search result was packed into max_tokens.
+type tokenBudget struct {
+	MaxTokens  int `json:"max_tokens"`
+	UsedTokens int `json:"used_tokens"`
+	Included   int `json:"included"`
+	Omitted    int `json:"omitted"`
+}
+
+// packHybridResults keeps the highest-ranked hybrid search results whose
+// estimated tokens fit within maxTokens. Vector results are ranked by
+// similarity, then graph results by depth, then facts by key; results that do
+// not fit are skipped so smaller ones further down can still be packed.
+func packHybridResults(results *storage.HybridSearchResult, maxTokens int) (*storage.HybridSearchResult, tokenBudget) {
+	budget := tokenBudget{MaxTokens: maxTokens}
+	fits := func(item interface{}) bool {
+		tokens := itemTokens(item)
+		if budget.UsedTokens+tokens > maxTokens {
+			budget.Omitted++
+			return false
+		}
+		budget.UsedTokens += tokens
+		budget.Included++
+		return true
+	}
+
+	packed := &storage.HybridSearchResult{
+		VectorResults: []storage.VectorResult{},
+		GraphResults:  []storage.GraphResult{},
+		Facts:         map[string]interface{}{},
+		QueryTime:     results.QueryTime,
+	}
+
+	vectors := append([]storage.VectorResult(nil), results.VectorResults...)
+	sort.SliceStable(vectors, func(i, j int) bool { return vectors[i].Similarity > vectors[j].Similarity })
+	for _, v := range vectors {
+		if fits(v) {
+			packed.VectorResults = append(packed.VectorResults, v)
+		}
+	}
+
+	graph := append([]storage.GraphResult(nil), results.GraphResults...)
+	sort.SliceStable(graph, func(i, j int) bool { return graph[i].Depth < graph[j].Depth })
+	for _, g := range graph {
+		if fits(g) {
+			packed.GraphResults = append(packed.GraphResults, g)
+		}
+	}
+
+	keys := make([]string, 0, len(results.Facts))
+	for key := range results.Facts {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if fits(map[string]interface{}{key: results.Facts[key]}) {
+			packed.Facts[key] = results.Facts[key]
+		}
+	}
+
+	packed.TotalResults = budget.Included
+	return packed, budget
+}
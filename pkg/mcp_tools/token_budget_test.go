@@ -0,0 +1,57 @@
+package mcp_tools
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/madeindigio/remembrances-mcp/internal/storage"
+)
+
+func TestEstimateTokens(t *testing.T) {
+	tests := []struct {
+		s    string
+		want int
+	}{
+		{"", 0},
+		{"hello", 2},
+		{"the cat sat", 3},
+		{"a, b.", 4},
+		{"internationalization", 5},
+	}
+	for _, tt := range tests {
+		if got := estimateTokens(tt.s); got != tt.want {
+			t.Errorf("estimateTokens(%q) = %d, want %d", tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestPackHybridResults(t *testing.T) {
+	results := &storage.HybridSearchResult{
+		VectorResults: []storage.VectorResult{
+			{ID: "v:low", Content: "short note", Similarity: 0.5},
+			{ID: "v:big", Content: strings.Repeat("long memory text ", 200), Similarity: 0.8},
+			{ID: "v:high", Content: "most relevant note", Similarity: 0.9},
+		},
+		Facts: map[string]interface{}{"editor": "vim"},
+	}
+	total := 0
+	for _, v := range results.VectorResults {
+		total += itemTokens(v)
+	}
+
+	packed, budget := packHybridResults(results, itemTokens(results.VectorResults[2])+itemTokens(results.VectorResults[0]))
+	if len(packed.VectorResults) != 2 || packed.VectorResults[0].ID != "v:high" || packed.VectorResults[1].ID != "v:low" {
+		t.Fatalf("expected the two small vectors by similarity, got %+v", packed.VectorResults)
+	}
+	if len(packed.Facts) != 0 || budget.Omitted != 2 || budget.Included != 2 {
+		t.Errorf("unexpected packing: facts %v, budget %+v", packed.Facts, budget)
+	}
+	if budget.UsedTokens > budget.MaxTokens {
+		t.Errorf("used %d tokens, over the budget of %d", budget.UsedTokens, budget.MaxTokens)
+	}
+
+	packed, budget = packHybridResults(results, total+100)
+	if len(packed.VectorResults) != 3 || len(packed.Facts) != 1 || budget.Omitted != 0 {
+		t.Errorf("everything should fit, got %+v", budget)
+	}
+}
@@ -5,10 +5,15 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"net/http"
 
 	"github.com/madeindigio/remembrances-mcp/internal/indexer"
+	"github.com/madeindigio/remembrances-mcp/internal/kb"
+	"github.com/madeindigio/remembrances-mcp/internal/logging"
 	"github.com/madeindigio/remembrances-mcp/internal/storage"
 	"github.com/madeindigio/remembrances-mcp/pkg/embedder"
+	"github.com/madeindigio/remembrances-mcp/pkg/llm"
+	"github.com/madeindigio/remembrances-mcp/pkg/redact"
 
 	"github.com/ThinkInAIXYZ/go-mcp/protocol"
 	mcpserver "github.com/ThinkInAIXYZ/go-mcp/server"
@@ -16,12 +21,32 @@ import (
 
 // ToolManager manages all MCP tools for the remembrances server
 type ToolManager struct {
-	storage           storage.StorageWithStats
-	embedder          embedder.Embedder
-	codeEmbedder      embedder.Embedder // Embedder for code indexing (may be same as default)
-	knowledgeBasePath string            // Path to knowledge base directory for markdown files
-	kbChunkSize       int               // Chunk size used by kb_* tools when embedding long documents
-	kbChunkOverlap    int               // Overlap used by kb_* tools when embedding long documents
+	storage                storage.StorageWithStats
+	embedder               embedder.Embedder
+	codeEmbedder           embedder.Embedder      // Embedder for code indexing (may be same as default)
+	router                 *embedder.Router       // Embedder of each record kind (nil uses embedder for all)
+	knowledgeBasePath      string                 // Path to knowledge base directory for markdown files
+	kbChunkSize            int                    // Chunk size used by kb_* tools when embedding long documents
+	kbChunkOverlap         int                    // Overlap used by kb_* tools when embedding long documents
+	kbChunkStrategy        embedder.ChunkStrategy // Default chunking strategy for kb_add_document
+	duplicateThreshold     float64                // Cosine similarity at or above which adds are treated as duplicates (0 disables)
+	llm                    llm.Client             // LLM used by remembrance_consolidate (nil when not configured)
+	consolidationThreshold float64                // Default similarity for clustering memories in remembrance_consolidate
+	purgeArchiveDir        string                 // Directory for the exports written by remembrance_purge_user
+	redactor               *redact.Redactor       // Scrubs or rejects secrets in stored content (nil stores content unchanged)
+	kbRoots                []kb.Root              // Knowledge base directories; defaults to knowledgeBasePath
+	kbWatchers             func() []*kb.Watcher   // Returns the running knowledge base watchers
+	embeddingModel         string                 // Identifies the embedder in document metadata (see embedder.ModelName)
+	kbRecrawl              kbRecrawlState         // Scheduled re-crawl of documents added with kb_add_url
+	urlClient              *http.Client           // Fetches the pages of kb_add_url and the re-crawl
+	progressNotifier       progressFunc           // Streams remembrance_subscribe changes (nil returns them with the result only)
+	maxOutputBytes         int                    // Bytes of document content kb_get_document returns per call (0 is unlimited)
+	scoring                scoring                // Minimum similarity and score normalization of search results
+	logLevels              *logging.Levels        // Log levels remembrance_log_level changes (nil when not configurable)
+	storageBackend         string                 // Storage backend reported by remembrance_system_info
+	toolsets               func() []string        // Returns the loaded toolsets reported by remembrance_system_info
+	idempotencyLocks       idempotencyLocks       // Serializes write tool calls sharing an idempotency key
+	workingMemory          workingMemory          // Session-scoped values and notes of the working_memory_* tools
 }
 
 // NewToolManager creates a new tool manager
@@ -33,6 +58,10 @@ func NewToolManager(storage storage.StorageWithStats, embedder embedder.Embedder
 		knowledgeBasePath: knowledgeBasePath,
 		kbChunkSize:       800,
 		kbChunkOverlap:    100,
+		maxOutputBytes:    defaultMaxOutputBytes,
+		scoring:           newScoring(0, ScoreNormalizationRaw),
+		workingMemory:     workingMemory{ttl: defaultWorkingMemoryTTL},
+		urlClient:         newURLFetchClient(false),
 	}
 }
 
@@ -45,6 +74,10 @@ func NewToolManagerWithCodeEmbedder(storage storage.StorageWithStats, embedder e
 		knowledgeBasePath: knowledgeBasePath,
 		kbChunkSize:       800,
 		kbChunkOverlap:    100,
+		maxOutputBytes:    defaultMaxOutputBytes,
+		scoring:           newScoring(0, ScoreNormalizationRaw),
+		workingMemory:     workingMemory{ttl: defaultWorkingMemoryTTL},
+		urlClient:         newURLFetchClient(false),
 	}
 }
 
@@ -63,6 +96,110 @@ func (tm *ToolManager) SetKBChunking(chunkSize, chunkOverlap int) {
 	}
 }
 
+// SetKBChunkStrategy configures the default chunking strategy for kb_add_document.
+// Unknown or empty strategies fall back to fixed-size chunking.
+func (tm *ToolManager) SetKBChunkStrategy(strategy string) {
+	parsed, err := embedder.ParseChunkStrategy(strategy)
+	if err != nil {
+		slog.Warn("invalid kb chunk strategy, using fixed", "strategy", strategy, "err", err)
+		parsed = embedder.ChunkStrategyFixed
+	}
+	tm.kbChunkStrategy = parsed
+}
+
+// SetDuplicateThreshold configures near-duplicate detection for add_vector and
+// kb_* document tools. Values <= 0 disable detection.
+func (tm *ToolManager) SetDuplicateThreshold(threshold float64) {
+	if threshold > 1 {
+		threshold = 1
+	}
+	tm.duplicateThreshold = threshold
+}
+
+// SetLLM configures the LLM used to summarize memories in remembrance_consolidate.
+func (tm *ToolManager) SetLLM(client llm.Client) {
+	tm.llm = client
+}
+
+// SetConsolidationThreshold configures the default clustering similarity for
+// remembrance_consolidate. Values outside (0, 1] fall back to the default.
+func (tm *ToolManager) SetConsolidationThreshold(threshold float64) {
+	if threshold <= 0 || threshold > 1 {
+		threshold = defaultConsolidationThreshold
+	}
+	tm.consolidationThreshold = threshold
+}
+
+// SetURLPrivateNetworks configures whether kb_add_url and the re-crawl may
+// fetch pages on loopback, link-local and private network addresses.
+func (tm *ToolManager) SetURLPrivateNetworks(allow bool) {
+	tm.urlClient = newURLFetchClient(allow)
+}
+
+// SetPurgeArchiveDir configures where remembrance_purge_user writes user data
+// exports. An empty dir falls back to the default.
+func (tm *ToolManager) SetPurgeArchiveDir(dir string) {
+	if dir == "" {
+		dir = defaultPurgeArchiveDir
+	}
+	tm.purgeArchiveDir = dir
+}
+
+// SetRedactor configures the filter applied to content before it is stored.
+func (tm *ToolManager) SetRedactor(redactor *redact.Redactor) {
+	tm.redactor = redactor
+}
+
+// SetKBRoots configures the knowledge base directories documents are
+// mirrored to, replacing the single directory given to the constructor.
+func (tm *ToolManager) SetKBRoots(roots []kb.Root) {
+	tm.kbRoots = roots
+}
+
+// SetKBWatchers configures how the kb_*_watch tools reach the knowledge base
+// watchers, which are started after the tools are registered.
+func (tm *ToolManager) SetKBWatchers(watchers func() []*kb.Watcher) {
+	tm.kbWatchers = watchers
+}
+
+// SetEmbeddingModel configures the model name recorded with stored documents,
+// which kb_staleness_report compares against.
+func (tm *ToolManager) SetEmbeddingModel(model string) {
+	tm.embeddingModel = model
+}
+
+// SetEmbeddingRouter configures the embedder of each record kind, so
+// vectors, knowledge base documents and events can each be embedded by
+// their own model.
+func (tm *ToolManager) SetEmbeddingRouter(router *embedder.Router) {
+	tm.router = router
+	if router != nil {
+		tm.codeEmbedder = router.For(embedder.RouteCode)
+	}
+}
+
+// embedderFor returns the embedder of a record kind.
+func (tm *ToolManager) embedderFor(kind string) embedder.Embedder {
+	if tm.router != nil {
+		return tm.router.For(kind)
+	}
+	return tm.embedder
+}
+
+// modelFor returns the model name of a record kind's embedder.
+func (tm *ToolManager) modelFor(kind string) string {
+	if tm.router != nil {
+		return tm.router.Model(kind)
+	}
+	return tm.embeddingModel
+}
+
+// SetProgressNotifier configures how remembrance_subscribe streams changes
+// to the MCP client while the call is running.
+func (tm *ToolManager) SetProgressNotifier(notifier func(context.Context, *protocol.ProgressNotification) error) {
+	tm.progressNotifier = notifier
+}
+
 // GetCodeEmbedder returns the embedder used for code indexing
 func (tm *ToolManager) GetCodeEmbedder() embedder.Embedder {
 	return tm.codeEmbedder
@@ -89,6 +226,7 @@ func (tm *ToolManager) RegisterTools(srv *mcpserver.Server) error {
 // RegisterToolsWith registers all MCP tools using a provided registration callback.
 // This allows callers (like module systems) to collect tools without a server.
 func (tm *ToolManager) RegisterToolsWith(reg func(string, *protocol.Tool, func(context.Context, *protocol.CallToolRequest) (*protocol.CallToolResult, error)) error) error {
+	reg = withErrorCodes(reg)
 	if err := tm.registerRemembranceTools(reg); err != nil {
 		return err
 	}
@@ -116,37 +254,37 @@ func (tm *ToolManager) RegisterToolsWith(reg func(string, *protocol.Tool, func(c
 
 // RegisterFactToolsWith registers fact tools (save/get/list/delete fact).
 func (tm *ToolManager) RegisterFactToolsWith(reg func(string, *protocol.Tool, func(context.Context, *protocol.CallToolRequest) (*protocol.CallToolResult, error)) error) error {
-	return tm.registerRemembranceTools(reg)
+	return tm.registerRemembranceTools(withErrorCodes(reg))
 }
 
 // RegisterVectorToolsWith registers vector tools (add/search/update/delete vector).
 func (tm *ToolManager) RegisterVectorToolsWith(reg func(string, *protocol.Tool, func(context.Context, *protocol.CallToolRequest) (*protocol.CallToolResult, error)) error) error {
-	return tm.registerVectorTools(reg)
+	return tm.registerVectorTools(withErrorCodes(reg))
 }
 
 // RegisterGraphToolsWith registers knowledge graph tools (entities/relationships/traverse/get).
 func (tm *ToolManager) RegisterGraphToolsWith(reg func(string, *protocol.Tool, func(context.Context, *protocol.CallToolRequest) (*protocol.CallToolResult, error)) error) error {
-	return tm.registerGraphTools(reg)
+	return tm.registerGraphTools(withErrorCodes(reg))
 }
 
 // RegisterKBToolsWith registers knowledge base tools.
 func (tm *ToolManager) RegisterKBToolsWith(reg func(string, *protocol.Tool, func(context.Context, *protocol.CallToolRequest) (*protocol.CallToolResult, error)) error) error {
-	return tm.registerKBTools(reg)
+	return tm.registerKBTools(withErrorCodes(reg))
 }
 
 // RegisterRememberToolsWith registers remember tools (to_remember/last_to_remember).
 func (tm *ToolManager) RegisterRememberToolsWith(reg func(string, *protocol.Tool, func(context.Context, *protocol.CallToolRequest) (*protocol.CallToolResult, error)) error) error {
-	return tm.registerRememberTools(reg)
+	return tm.registerRememberTools(withErrorCodes(reg))
 }
 
 // RegisterEventToolsWith registers event tools.
 func (tm *ToolManager) RegisterEventToolsWith(reg func(string, *protocol.Tool, func(context.Context, *protocol.CallToolRequest) (*protocol.CallToolResult, error)) error) error {
-	return tm.registerEventTools(reg)
+	return tm.registerEventTools(withErrorCodes(reg))
 }
 
-// RegisterMiscToolsWith registers misc tools (hybrid_search/get_stats/how_to_use).
+// RegisterMiscToolsWith registers misc tools (hybrid_search/get_stats/how_to_use/trash).
 func (tm *ToolManager) RegisterMiscToolsWith(reg func(string, *protocol.Tool, func(context.Context, *protocol.CallToolRequest) (*protocol.CallToolResult, error)) error) error {
-	return tm.registerMiscTools(reg)
+	return tm.registerMiscTools(withErrorCodes(reg))
 }
 
 // registration helper groups keep RegisterTools small and readable
@@ -167,7 +305,7 @@ func (tm *ToolManager) registerRemembranceTools(reg func(string, *protocol.Tool,
 }
 
 func (tm *ToolManager) registerVectorTools(reg func(string, *protocol.Tool, func(context.Context, *protocol.CallToolRequest) (*protocol.CallToolResult, error)) error) error {
-	if err := reg("add_vector", tm.addVectorTool(), tm.addVectorHandler); err != nil {
+	if err := reg("add_vector", tm.addVectorTool(), tm.idempotent("add_vector", tm.addVectorHandler)); err != nil {
 		return err
 	}
 	if err := reg("search_vectors", tm.searchVectorsTool(), tm.searchVectorsHandler); err != nil {
@@ -179,14 +317,20 @@ func (tm *ToolManager) registerVectorTools(reg func(string, *protocol.Tool, func
 	if err := reg("delete_vector", tm.deleteVectorTool(), tm.deleteVectorHandler); err != nil {
 		return err
 	}
+	if err := reg("remembrance_consolidate", tm.consolidateTool(), tm.consolidateHandler); err != nil {
+		return err
+	}
 	return nil
 }
 
 func (tm *ToolManager) registerGraphTools(reg func(string, *protocol.Tool, func(context.Context, *protocol.CallToolRequest) (*protocol.CallToolResult, error)) error) error {
-	if err := reg("create_entity", tm.createEntityTool(), tm.createEntityHandler); err != nil {
+	if err := reg("create_entity", tm.createEntityTool(), tm.idempotent("create_entity", tm.createEntityHandler)); err != nil {
+		return err
+	}
+	if err := reg("create_relationship", tm.createRelationshipTool(), tm.idempotent("create_relationship", tm.createRelationshipHandler)); err != nil {
 		return err
 	}
-	if err := reg("create_relationship", tm.createRelationshipTool(), tm.createRelationshipHandler); err != nil {
+	if err := reg("end_relationship", tm.endRelationshipTool(), tm.endRelationshipHandler); err != nil {
 		return err
 	}
 	if err := reg("traverse_graph", tm.traverseGraphTool(), tm.traverseGraphHandler); err != nil {
@@ -195,11 +339,38 @@ func (tm *ToolManager) registerGraphTools(reg func(string, *protocol.Tool, func(
 	if err := reg("get_entity", tm.getEntityTool(), tm.getEntityHandler); err != nil {
 		return err
 	}
+	if err := reg("remembrance_list_relationships", tm.listRelationshipsTool(), tm.listRelationshipsHandler); err != nil {
+		return err
+	}
+	if err := reg("remembrance_graph_stats", tm.graphStatsTool(), tm.graphStatsHandler); err != nil {
+		return err
+	}
+	if err := reg("remembrance_find_path", tm.findPathTool(), tm.findPathHandler); err != nil {
+		return err
+	}
+	if err := reg("remembrance_find_duplicate_entities", tm.findDuplicateEntitiesTool(), tm.findDuplicateEntitiesHandler); err != nil {
+		return err
+	}
+	if err := reg("remembrance_merge_entities", tm.mergeEntitiesTool(), tm.mergeEntitiesHandler); err != nil {
+		return err
+	}
+	if err := reg("update_entity", tm.updateEntityTool(), tm.updateEntityHandler); err != nil {
+		return err
+	}
+	if err := reg("remembrance_define_entity_type", tm.defineEntityTypeTool(), tm.defineEntityTypeHandler); err != nil {
+		return err
+	}
+	if err := reg("remembrance_describe_entity_type", tm.describeEntityTypeTool(), tm.describeEntityTypeHandler); err != nil {
+		return err
+	}
 	return nil
 }
 
 func (tm *ToolManager) registerKBTools(reg func(string, *protocol.Tool, func(context.Context, *protocol.CallToolRequest) (*protocol.CallToolResult, error)) error) error {
-	if err := reg("kb_add_document", tm.addDocumentTool(), tm.addDocumentHandler); err != nil {
+	if err := reg("kb_add_document", tm.addDocumentTool(), tm.idempotent("kb_add_document", tm.addDocumentHandler)); err != nil {
+		return err
+	}
+	if err := reg("kb_add_url", tm.addURLTool(), tm.idempotent("kb_add_url", tm.addURLHandler)); err != nil {
 		return err
 	}
 	if err := reg("kb_search_documents", tm.searchDocumentsTool(), tm.searchDocumentsHandler); err != nil {
@@ -211,11 +382,32 @@ func (tm *ToolManager) registerKBTools(reg func(string, *protocol.Tool, func(con
 	if err := reg("kb_delete_document", tm.deleteDocumentTool(), tm.deleteDocumentHandler); err != nil {
 		return err
 	}
+	if err := reg("kb_get_document_history", tm.getDocumentHistoryTool(), tm.getDocumentHistoryHandler); err != nil {
+		return err
+	}
+	if err := reg("kb_restore_version", tm.restoreVersionTool(), tm.restoreVersionHandler); err != nil {
+		return err
+	}
+	if err := reg("kb_watch_status", tm.kbWatchStatusTool(), tm.kbWatchStatusHandler); err != nil {
+		return err
+	}
+	if err := reg("kb_sync_now", tm.kbSyncNowTool(), tm.kbSyncNowHandler); err != nil {
+		return err
+	}
+	if err := reg("kb_pause_watch", tm.kbPauseWatchTool(), tm.kbPauseWatchHandler); err != nil {
+		return err
+	}
+	if err := reg("kb_conflicts", tm.kbConflictsTool(), tm.kbConflictsHandler); err != nil {
+		return err
+	}
+	if err := reg("kb_staleness_report", tm.kbStalenessReportTool(), tm.kbStalenessReportHandler); err != nil {
+		return err
+	}
 	return nil
 }
 
 func (tm *ToolManager) registerRememberTools(reg func(string, *protocol.Tool, func(context.Context, *protocol.CallToolRequest) (*protocol.CallToolResult, error)) error) error {
-	if err := reg("to_remember", tm.toRememberTool(), tm.toRememberHandler); err != nil {
+	if err := reg("to_remember", tm.toRememberTool(), tm.idempotent("to_remember", tm.toRememberHandler)); err != nil {
 		return err
 	}
 	if err := reg("last_to_remember", tm.lastToRememberTool(), tm.lastToRememberHandler); err != nil {
@@ -231,14 +423,77 @@ func (tm *ToolManager) registerMiscTools(reg func(string, *protocol.Tool, func(c
 	if err := reg("get_stats", tm.getStatsTool(), tm.getStatsHandler); err != nil {
 		return err
 	}
+	if err := reg("remembrance_save_search", tm.saveSearchTool(), tm.saveSearchHandler); err != nil {
+		return err
+	}
+	if err := reg("remembrance_run_saved_search", tm.runSavedSearchTool(), tm.runSavedSearchHandler); err != nil {
+		return err
+	}
+	if err := reg("remembrance_search_changes", tm.searchChangesTool(), tm.searchChangesHandler); err != nil {
+		return err
+	}
+	if err := reg("working_memory_set", tm.workingMemorySetTool(), tm.workingMemorySetHandler); err != nil {
+		return err
+	}
+	if err := reg("working_memory_get", tm.workingMemoryGetTool(), tm.workingMemoryGetHandler); err != nil {
+		return err
+	}
+	if err := reg("working_memory_promote", tm.workingMemoryPromoteTool(), tm.workingMemoryPromoteHandler); err != nil {
+		return err
+	}
+	if err := reg("working_memory_end", tm.workingMemoryEndTool(), tm.workingMemoryEndHandler); err != nil {
+		return err
+	}
 	if err := reg("how_to_use", tm.howToUseTool(), tm.howToUseHandler); err != nil {
 		return err
 	}
+	if err := reg("remembrance_trash_list", tm.trashListTool(), tm.trashListHandler); err != nil {
+		return err
+	}
+	if err := reg("remembrance_restore", tm.restoreTool(), tm.restoreHandler); err != nil {
+		return err
+	}
+	if err := reg("remembrance_pin", tm.pinTool(), tm.pinHandler); err != nil {
+		return err
+	}
+	if err := reg("remembrance_batch", tm.batchTool(), tm.idempotent("remembrance_batch", tm.batchHandler)); err != nil {
+		return err
+	}
+	if err := reg("remembrance_import_bulk", tm.importBulkTool(), tm.importBulkHandler); err != nil {
+		return err
+	}
+	if err := reg("remembrance_list_users", tm.listUsersTool(), tm.listUsersHandler); err != nil {
+		return err
+	}
+	if err := reg("remembrance_delete_user", tm.deleteUserTool(), tm.deleteUserHandler); err != nil {
+		return err
+	}
+	if err := reg("remembrance_rename_user", tm.renameUserTool(), tm.renameUserHandler); err != nil {
+		return err
+	}
+	if err := reg("remembrance_purge_user", tm.purgeUserTool(), tm.purgeUserHandler); err != nil {
+		return err
+	}
+	if err := reg("remembrance_recount_stats", tm.recountStatsTool(), tm.recountStatsHandler); err != nil {
+		return err
+	}
+	if err := reg("remembrance_usage_report", tm.usageReportTool(), tm.usageReportHandler); err != nil {
+		return err
+	}
+	if err := reg("remembrance_subscribe", tm.subscribeTool(), tm.subscribeHandler); err != nil {
+		return err
+	}
+	if err := reg("remembrance_log_level", tm.logLevelTool(), tm.logLevelHandler); err != nil {
+		return err
+	}
+	if err := reg("remembrance_system_info", tm.systemInfoTool(), tm.systemInfoHandler); err != nil {
+		return err
+	}
 	return nil
 }
 
 func (tm *ToolManager) registerEventTools(reg func(string, *protocol.Tool, func(context.Context, *protocol.CallToolRequest) (*protocol.CallToolResult, error)) error) error {
-	if err := reg("save_event", tm.saveEventTool(), tm.saveEventHandler); err != nil {
+	if err := reg("save_event", tm.saveEventTool(), tm.idempotent("save_event", tm.saveEventHandler)); err != nil {
 		return err
 	}
 	if err := reg("search_events", tm.searchEventsTool(), tm.searchEventsHandler); err != nil {
@@ -254,7 +509,10 @@ func (tm *ToolManager) registerEventTools(reg func(string, *protocol.Tool, func(
 // The returned JobManager should be used with CodeToolManager to provide
 // code indexing capabilities.
 func (tm *ToolManager) CreateJobManager(fullStorage storage.FullStorage, indexerConfig indexer.IndexerConfig, jmConfig indexer.JobManagerConfig) *indexer.JobManager {
-	return indexer.NewJobManager(fullStorage, tm.codeEmbedder, indexerConfig, jmConfig)
+	jm := indexer.NewJobManager(fullStorage, tm.codeEmbedder, indexerConfig, jmConfig)
+	// Projects may pick one of the named embedders of the router
+	jm.GetIndexer().SetEmbeddingRouter(tm.router)
+	return jm
 }
 
 // CreateWatcherManager creates a WatcherManager for code project file monitoring.
@@ -0,0 +1,107 @@
+package mcp_tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	"github.com/ThinkInAIXYZ/go-mcp/protocol"
+	"github.com/madeindigio/remembrances-mcp/internal/storage"
+)
+
+// Trash tool definitions
+func (tm *ToolManager) trashListTool() *protocol.Tool {
+	tool, err := protocol.NewTool("remembrance_trash_list", `List deleted facts, remembrances, documents and entities that can still be restored. Use how_to_use("remembrance_trash_list") for details.`, TrashListInput{})
+	if err != nil {
+		slog.Error("failed to create tool", "name", "remembrance_trash_list", "err", err)
+		return nil
+	}
+	return tool
+}
+
+func (tm *ToolManager) restoreTool() *protocol.Tool {
+	tool, err := protocol.NewTool("remembrance_restore", `Restore a deleted item from the trash. Use how_to_use("remembrance_restore") for details.`, RestoreInput{})
+	if err != nil {
+		slog.Error("failed to create tool", "name", "remembrance_restore", "err", err)
+		return nil
+	}
+	return tool
+}
+
+// Trash tool handlers
+func (tm *ToolManager) trashListHandler(ctx context.Context, request *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+	var input TrashListInput
+	if err := json.Unmarshal(request.RawArguments, &input); err != nil {
+		return nil, fmt.Errorf(errParseArgs, err)
+	}
+
+	switch input.Kind {
+	case "", storage.TrashKindFact, storage.TrashKindVector, storage.TrashKindDocument, storage.TrashKindEntity:
+	default:
+		return nil, validationErrorf("invalid kind %q: expected fact, vector, document or entity", input.Kind)
+	}
+	if input.Limit <= 0 {
+		input.Limit = 50
+	}
+
+	items, err := tm.storage.ListTrash(ctx, input.UserID, input.Kind, input.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(items) == 0 {
+		payload := CreateEmptyResultTOON("The trash is empty", AlternativeSuggestions{})
+		return protocol.NewCallToolResult([]protocol.Content{
+			&protocol.TextContent{Type: "text", Text: payload},
+		}, false), nil
+	}
+
+	response := map[string]interface{}{
+		"count": len(items),
+		"items": items,
+	}
+
+	return protocol.NewCallToolResult([]protocol.Content{
+		&protocol.TextContent{Type: "text", Text: MarshalTOON(response)},
+	}, false), nil
+}
+
+func (tm *ToolManager) restoreHandler(ctx context.Context, request *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+	var input RestoreInput
+	if err := json.Unmarshal(request.RawArguments, &input); err != nil {
+		return nil, fmt.Errorf(errParseArgs, err)
+	}
+
+	if input.ID == "" {
+		return nil, validationErrorf("id is required")
+	}
+
+	item, err := tm.storage.RestoreFromTrash(ctx, input.ID)
+	if err != nil {
+		return nil, err
+	}
+	if item == nil {
+		payload := CreateEmptyResultTOON(
+			fmt.Sprintf("No trash entry found with id '%s'. Use remembrance_trash_list to find the id of a deleted item", input.ID),
+			AlternativeSuggestions{},
+		)
+		return protocol.NewCallToolResult([]protocol.Content{
+			&protocol.TextContent{Type: "text", Text: payload},
+		}, false), nil
+	}
+
+	// Documents are mirrored to the knowledge base directory; put the file back too
+	if item.Kind == storage.TrashKindDocument {
+		if err := tm.saveMarkdownFile(item.Label, item.Content); err != nil {
+			slog.Warn("failed to restore document to filesystem", "file_path", item.Label, "error", err)
+		}
+	}
+
+	return protocol.NewCallToolResult([]protocol.Content{
+		&protocol.TextContent{
+			Type: "text",
+			Text: fmt.Sprintf("Successfully restored %s '%s'", item.Kind, item.Label),
+		},
+	}, false), nil
+}
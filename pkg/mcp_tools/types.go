@@ -44,37 +44,71 @@ func (f FlexibleObject) AsMap() map[string]interface{} {
 	return map[string]interface{}(f)
 }
 
+// ValidityInput is the interval over which the fact or relationship a tool
+// writes holds.
+type ValidityInput struct {
+	ValidFrom  string `json:"valid_from,omitempty" description:"When it became true, as an RFC 3339 time or YYYY-MM-DD date. Defaults to now."`
+	ValidUntil string `json:"valid_until,omitempty" description:"When it stops being true, as an RFC 3339 time or YYYY-MM-DD date. Omit while it holds."`
+}
+
+// ProvenanceInput is embedded in the inputs of the tools that write memories.
+// It is recorded with the tool name and the conversation as the provenance of
+// what the tool writes.
+type ProvenanceInput struct {
+	Confidence     *float64 `json:"confidence,omitempty" description:"How sure you are of what is written, from 0 (speculative) to 1 (verified). Searches can filter on it."`
+	Model          string   `json:"model,omitempty" description:"Model writing the memory, recorded in its provenance."`
+	ConversationID string   `json:"conversation_id,omitempty" description:"Conversation the memory comes from. Defaults to the MCP session."`
+}
+
 // Tool input structs
 type SaveFactInput struct {
 	UserID string `json:"user_id"`
 	Key    string `json:"key"`
 	Value  string `json:"value"`
+	ProvenanceInput
+	ValidityInput
 }
 
 type GetFactInput struct {
-	UserID string `json:"user_id"`
-	Key    string `json:"key"`
+	UserID  string `json:"user_id"`
+	Key     string `json:"key"`
+	AsOf    string `json:"as_of,omitempty" description:"Return the value the fact held at this RFC 3339 time or YYYY-MM-DD date."`
+	History bool   `json:"history,omitempty" description:"Return every value the fact held, newest first, with its validity interval."`
 }
 
 type ListFactsInput struct {
-	UserID string `json:"user_id"`
+	UserID        string  `json:"user_id"`
+	MinConfidence float64 `json:"min_confidence,omitempty" description:"Only facts saved with at least this confidence, from 0 to 1."`
+	AsOf          string  `json:"as_of,omitempty" description:"List the values the facts held at this RFC 3339 time or YYYY-MM-DD date."`
 }
 
 type DeleteFactInput struct {
 	UserID string `json:"user_id"`
 	Key    string `json:"key"`
+	Force  bool   `json:"force,omitempty" description:"Delete the fact even if it is pinned."`
 }
 
 type AddVectorInput struct {
-	UserID   string         `json:"user_id"`
-	Content  string         `json:"content"`
-	Metadata FlexibleObject `json:"metadata,omitempty"`
+	UserID         string         `json:"user_id"`
+	Content        string         `json:"content"`
+	Metadata       FlexibleObject `json:"metadata,omitempty"`
+	AllowDuplicate bool           `json:"allow_duplicate,omitempty"`
+	MergeMetadata  bool           `json:"merge_metadata,omitempty"`
+	IdempotencyKey string         `json:"idempotency_key,omitempty"`
+	ProvenanceInput
 }
 
 type SearchVectorsInput struct {
-	UserID string `json:"user_id"`
-	Query  string `json:"query"`
-	Limit  int    `json:"limit,omitempty"`
+	UserID        string  `json:"user_id"`
+	Query         string  `json:"query"`
+	Limit         int     `json:"limit,omitempty"`
+	EfSearch      int     `json:"ef_search,omitempty"`
+	Accuracy      string  `json:"accuracy,omitempty"`
+	MinSimilarity float64 `json:"min_similarity,omitempty"`
+	Expand        bool    `json:"expand,omitempty" description:"Also search stemmed and synonym variants of the query and fuse the results."`
+	HyDE          bool    `json:"hyde,omitempty" description:"Also search a hypothetical answer written by the configured LLM and fuse the results."`
+	MinConfidence float64 `json:"min_confidence,omitempty" description:"Only remembrances saved with at least this confidence, from 0 to 1."`
+	SortBy        string  `json:"sort_by,omitempty" description:"similarity (default) or confidence: highest confidence first, then similarity."`
 }
 
 type UpdateVectorInput struct {
@@ -82,17 +116,121 @@ type UpdateVectorInput struct {
 	UserID   string         `json:"user_id"`
 	Content  string         `json:"content"`
 	Metadata FlexibleObject `json:"metadata,omitempty"`
+	Revision int            `json:"revision,omitempty"`
+	ProvenanceInput
 }
 
 type DeleteVectorInput struct {
 	ID     string `json:"id"`
 	UserID string `json:"user_id"`
+	Force  bool   `json:"force,omitempty" description:"Delete the remembrance even if it is pinned."`
+}
+
+type ConsolidateInput struct {
+	UserID         string  `json:"user_id"`
+	Threshold      float64 `json:"threshold,omitempty"`
+	MinClusterSize int     `json:"min_cluster_size,omitempty"`
+	Limit          int     `json:"limit,omitempty"`
+	DryRun         bool    `json:"dry_run,omitempty"`
+}
+
+type TrashListInput struct {
+	UserID string `json:"user_id,omitempty"`
+	Kind   string `json:"kind,omitempty"`
+	Limit  int    `json:"limit,omitempty"`
+}
+
+type RestoreInput struct {
+	ID string `json:"id"`
+}
+
+type ListUsersInput struct{}
+
+type UsageReportInput struct {
+	UserID   string `json:"user_id"`
+	Kind     string `json:"kind,omitempty"`
+	LastDays int    `json:"last_days,omitempty"`
+	Limit    int    `json:"limit,omitempty"`
+}
+
+type SubscribeInput struct {
+	Layer           string `json:"layer"`
+	UserID          string `json:"user_id,omitempty"`
+	DurationSeconds int    `json:"duration_seconds,omitempty"`
+	MaxChanges      int    `json:"max_changes,omitempty"`
+}
+
+type DeleteUserInput struct {
+	UserID  string `json:"user_id"`
+	Confirm bool   `json:"confirm"`
+}
+
+type PurgeUserInput struct {
+	UserID  string `json:"user_id"`
+	Confirm bool   `json:"confirm"`
+}
+
+type RenameUserInput struct {
+	UserID    string `json:"user_id"`
+	NewUserID string `json:"new_user_id"`
+}
+
+type RecountStatsInput struct {
+	UserID string `json:"user_id,omitempty"`
+}
+
+// LogLevelInput is the input of remembrance_log_level. Without a level it
+// only reports the current levels.
+type LogLevelInput struct {
+	Module string `json:"module,omitempty" description:"Module to change: default (the default), storage, embedder, indexer, watcher or mcp."`
+	Level  string `json:"level,omitempty" description:"New level: debug, info, warn or error. default makes a module follow the default level again. Omit to only report the levels."`
+}
+
+// SystemInfoInput is the input of remembrance_system_info, which takes no
+// arguments.
+type SystemInfoInput struct{}
+
+// ImportBulkInput is the input of remembrance_import_bulk. The records are
+// read from path, a file on the server, or from data.
+type ImportBulkInput struct {
+	Kind      string `json:"kind,omitempty" description:"What the records are: facts (user_id, key, value) or vectors (user_id, content or text, metadata). Required unless source is set."`
+	Source    string `json:"source,omitempty" description:"Memory system whose JSON export path or data holds: mem0, zep or letta. Its memories, graph and core memory are converted to vectors, entities, relationships and facts; kind and format are not used."`
+	Path      string `json:"path,omitempty" description:"CSV or JSONL file (or JSON export) on the server to import."`
+	Data      string `json:"data,omitempty" description:"CSV or JSONL records (or JSON export) to import, instead of path."`
+	Format    string `json:"format,omitempty" description:"csv or jsonl. Defaults to the extension of path."`
+	UserID    string `json:"user_id,omitempty" description:"User of the records without a user_id."`
+	BatchSize int    `json:"batch_size,omitempty" description:"Texts embedded per embedder call (default 32, max 256)."`
+	ProvenanceInput
+}
+
+type BatchInput struct {
+	Operations     []BatchOperationInput `json:"operations"`
+	IdempotencyKey string                `json:"idempotency_key,omitempty"`
+	ProvenanceInput
+}
+
+// BatchOperationInput is one operation of remembrance_batch. The fields used
+// depend on op and match the arguments of the corresponding single tool.
+type BatchOperationInput struct {
+	Op               string         `json:"op"`
+	UserID           string         `json:"user_id,omitempty"`
+	Key              string         `json:"key,omitempty"`
+	Value            string         `json:"value,omitempty"`
+	Content          string         `json:"content,omitempty"`
+	Metadata         FlexibleObject `json:"metadata,omitempty"`
+	EntityType       string         `json:"entity_type,omitempty"`
+	Name             string         `json:"name,omitempty"`
+	Properties       FlexibleObject `json:"properties,omitempty"`
+	FromEntity       string         `json:"from_entity,omitempty"`
+	ToEntity         string         `json:"to_entity,omitempty"`
+	RelationshipType string         `json:"relationship_type,omitempty"`
 }
 
 type CreateEntityInput struct {
-	EntityType string         `json:"entity_type"`
-	Name       string         `json:"name"`
-	Properties FlexibleObject `json:"properties,omitempty"`
+	EntityType     string         `json:"entity_type"`
+	Name           string         `json:"name"`
+	Properties     FlexibleObject `json:"properties,omitempty"`
+	IdempotencyKey string         `json:"idempotency_key,omitempty"`
 }
 
 type CreateRelationshipInput struct {
@@ -100,12 +238,70 @@ type CreateRelationshipInput struct {
 	ToEntity         string         `json:"to_entity"`
 	RelationshipType string         `json:"relationship_type"`
 	Properties       FlexibleObject `json:"properties,omitempty"`
+	IdempotencyKey   string         `json:"idempotency_key,omitempty"`
+	ValidityInput
+}
+
+type EndRelationshipInput struct {
+	FromEntity       string `json:"from_entity"`
+	ToEntity         string `json:"to_entity"`
+	RelationshipType string `json:"relationship_type"`
+	At               string `json:"at,omitempty" description:"When the relationship stopped holding, as an RFC 3339 time or YYYY-MM-DD date. Defaults to now."`
 }
 
 type TraverseGraphInput struct {
 	StartEntity      string `json:"start_entity"`
 	RelationshipType string `json:"relationship_type,omitempty"`
 	Depth            int    `json:"depth,omitempty"`
+	AsOf             string `json:"as_of,omitempty" description:"Follow the relationships valid at this RFC 3339 time or YYYY-MM-DD date instead of the current ones."`
+}
+
+type ListRelationshipsInput struct {
+	Entity           string `json:"entity,omitempty" description:"Name or ID of an entity whose relationships to list. Lists all relationships when empty."`
+	RelationshipType string `json:"relationship_type,omitempty"`
+	Direction        string `json:"direction,omitempty" description:"With entity: out, in or both (default)."`
+	IncludeEnded     bool   `json:"include_ended,omitempty" description:"Also list relationships that were ended."`
+	Limit            int    `json:"limit,omitempty" description:"Maximum relationships to return, newest first. Default is 50, maximum 500."`
+	Offset           int    `json:"offset,omitempty" description:"Number of relationships to skip, to page through the results."`
+}
+
+type FindPathInput struct {
+	EntityA  string `json:"entity_a" description:"Name or ID of the entity the paths start from."`
+	EntityB  string `json:"entity_b" description:"Name or ID of the entity the paths lead to."`
+	MaxHops  int    `json:"max_hops,omitempty" description:"Maximum relationships on a path. Default is 4, maximum 6."`
+	MaxPaths int    `json:"max_paths,omitempty" description:"Maximum paths to return, shortest first. Default is 5, maximum 20."`
+}
+
+type MergeEntitiesInput struct {
+	Survivor   string   `json:"survivor" description:"Name, alias or ID of the entity to keep."`
+	Duplicates []string `json:"duplicates" description:"Names, aliases or IDs of the entities to merge into the survivor."`
+}
+
+type UpdateEntityInput struct {
+	Entity     string         `json:"entity" description:"Name, alias or ID of the entity to update."`
+	Properties FlexibleObject `json:"properties,omitempty" description:"Properties to set. A null value removes its property."`
+	Replace    bool           `json:"replace,omitempty" description:"Replace all the properties, but for the aliases, instead of merging into them."`
+}
+
+type DefineEntityTypeInput struct {
+	Name        string         `json:"name" description:"Entity type the schema applies to, as used in create_entity."`
+	Description string         `json:"description,omitempty" description:"What entities of this type stand for."`
+	Schema      FlexibleObject `json:"schema,omitempty" description:"JSON schema of the properties: an object schema with properties, required, type, enum, minimum, maximum, minLength, maxLength, pattern, items and additionalProperties."`
+	Delete      bool           `json:"delete,omitempty" description:"Delete the schema of the type instead. Its entities are kept."`
+}
+
+type DescribeEntityTypeInput struct {
+	Name string `json:"name,omitempty" description:"Entity type to describe. Omit to list the entity types."`
+}
+
+type FindDuplicateEntitiesInput struct {
+	EntityType string  `json:"entity_type,omitempty" description:"Only compare entities of this type. Entities of different types are never paired."`
+	Threshold  float64 `json:"threshold,omitempty" description:"Minimum similarity, between 0 and 1, of a candidate pair. Default is 0.85."`
+	Limit      int     `json:"limit,omitempty" description:"Maximum pairs to return, most similar first. Default is 20, maximum 100."`
+}
+
+type GraphStatsInput struct {
+	Top int `json:"top,omitempty" description:"Number of entities with the most relationships to return. Default is 10, maximum 100."`
 }
 
 type GetEntityInput struct {
@@ -113,29 +309,162 @@ type GetEntityInput struct {
 }
 
 type AddDocumentInput struct {
-	FilePath string         `json:"file_path"`
-	Content  string         `json:"content"`
-	Metadata FlexibleObject `json:"metadata,omitempty"`
+	FilePath       string         `json:"file_path"`
+	Content        string         `json:"content"`
+	Metadata       FlexibleObject `json:"metadata,omitempty"`
+	ChunkStrategy  string         `json:"chunk_strategy,omitempty"`
+	AllowDuplicate bool           `json:"allow_duplicate,omitempty"`
+	MergeMetadata  bool           `json:"merge_metadata,omitempty"`
+	IdempotencyKey string         `json:"idempotency_key,omitempty"`
+	ProvenanceInput
+}
+
+type AddURLInput struct {
+	URL            string         `json:"url"`
+	FilePath       string         `json:"file_path,omitempty"`
+	Metadata       FlexibleObject `json:"metadata,omitempty"`
+	ChunkStrategy  string         `json:"chunk_strategy,omitempty"`
+	AllowDuplicate bool           `json:"allow_duplicate,omitempty"`
+	MergeMetadata  bool           `json:"merge_metadata,omitempty"`
+	IdempotencyKey string         `json:"idempotency_key,omitempty"`
+	ProvenanceInput
 }
 
 type SearchDocumentsInput struct {
-	Query string `json:"query"`
-	Limit int    `json:"limit,omitempty"`
+	Query           string   `json:"query"`
+	Limit           int      `json:"limit,omitempty"`
+	EfSearch        int      `json:"ef_search,omitempty"`
+	Accuracy        string   `json:"accuracy,omitempty"`
+	MinSimilarity   float64  `json:"min_similarity,omitempty"`
+	IncludeArchived bool     `json:"include_archived,omitempty"`
+	Roots           []string `json:"roots,omitempty"`
+	Tags            []string `json:"tags,omitempty"`
+	Title           string   `json:"title,omitempty"`
+	Alias           string   `json:"alias,omitempty"`
+	DateFrom        string   `json:"date_from,omitempty"`
+	DateTo          string   `json:"date_to,omitempty"`
+	Expand          bool     `json:"expand,omitempty" description:"Also search stemmed and synonym variants of the query and fuse the results."`
+	HyDE            bool     `json:"hyde,omitempty" description:"Also search a hypothetical answer written by the configured LLM and fuse the results."`
+	GroupByDocument bool     `json:"group_by_document,omitempty" description:"Aggregate matching chunks per document with max/avg score, matched chunk ranges and a stitched preview."`
+	ContextChunks   int      `json:"context_chunks,omitempty" description:"Merge each matching chunk with this many neighboring chunks on each side (0-5) into one excerpt."`
+	Output          string   `json:"output,omitempty" description:"toon (default) or markdown: numbered excerpts with citations to file paths, chunk indexes and similarity scores."`
 }
 
 type GetDocumentInput struct {
-	FilePath string `json:"file_path"`
+	FilePath       string `json:"file_path"`
+	Offset         int    `json:"offset,omitempty" description:"Byte offset of the content to start from, to continue a truncated document. Default is 0."`
+	MaxOutputBytes int    `json:"max_output_bytes,omitempty" description:"Maximum bytes of content to return. Default is the server max-output-bytes."`
+	Summarize      bool   `json:"summarize,omitempty" description:"Return an extractive summary (headings and the first sentence under each) instead of the full content."`
 }
 
 type DeleteDocumentInput struct {
 	FilePath string `json:"file_path"`
+	Force    bool   `json:"force,omitempty" description:"Delete the document even if it is pinned."`
+}
+
+type GetDocumentHistoryInput struct {
+	FilePath       string `json:"file_path"`
+	IncludeContent bool   `json:"include_content,omitempty"`
+}
+
+type RestoreVersionInput struct {
+	FilePath string `json:"file_path"`
+	Version  int    `json:"version"`
+}
+
+type KBWatchStatusInput struct {
+	Root string `json:"root,omitempty"`
+}
+
+type KBSyncNowInput struct {
+	FilePath string `json:"file_path,omitempty"`
+	Root     string `json:"root,omitempty"`
+	Force    bool   `json:"force,omitempty"`
+}
+
+type KBPauseWatchInput struct {
+	Root   string `json:"root,omitempty"`
+	Resume bool   `json:"resume,omitempty"`
+}
+
+type KBConflictsInput struct {
+	FilePath       string `json:"file_path,omitempty"`
+	Resolution     string `json:"resolution,omitempty"`
+	Content        string `json:"content,omitempty"`
+	IncludeContent bool   `json:"include_content,omitempty"`
+}
+
+type KBStalenessReportInput struct {
+	CheckSources bool `json:"check_sources,omitempty"`
+	Recrawl      bool `json:"recrawl,omitempty"`
 }
 
 type HybridSearchInput struct {
-	UserID   string   `json:"user_id"`
-	Query    string   `json:"query"`
-	Entities []string `json:"entities,omitempty"`
-	Limit    int      `json:"limit,omitempty"`
+	UserID        string   `json:"user_id"`
+	Query         string   `json:"query"`
+	Entities      []string `json:"entities,omitempty"`
+	Limit         int      `json:"limit,omitempty"`
+	MaxTokens     int      `json:"max_tokens,omitempty"`
+	Output        string   `json:"output,omitempty" description:"toon (default) or markdown: numbered excerpts with citations to memories, entities and facts."`
+	MinConfidence float64  `json:"min_confidence,omitempty" description:"Only remembrances and facts saved with at least this confidence, from 0 to 1. Graph results are not filtered."`
+}
+
+type SaveSearchInput struct {
+	UserID      string         `json:"user_id"`
+	Name        string         `json:"name"`
+	Tool        string         `json:"tool,omitempty" description:"Search tool to call: kb_search_documents, hybrid_search, search_vectors, search_events or traverse_graph."`
+	Arguments   FlexibleObject `json:"arguments,omitempty" description:"Arguments to call the tool with, such as query, filters and limits."`
+	Description string         `json:"description,omitempty"`
+	Interval    string         `json:"interval,omitempty" description:"Run the search on this schedule, such as 1h or 24h (at least 5m), recording new results for remembrance_search_changes."`
+	Delete      bool           `json:"delete,omitempty" description:"Delete the saved search instead of saving it."`
+}
+
+type RunSavedSearchInput struct {
+	UserID    string         `json:"user_id"`
+	Name      string         `json:"name,omitempty" description:"Saved search to run. Leave empty to list the saved searches of the user."`
+	Arguments FlexibleObject `json:"arguments,omitempty" description:"Arguments overriding the saved ones for this run, such as a new query; null removes a saved argument."`
+}
+
+type SearchChangesInput struct {
+	UserID string `json:"user_id"`
+	Name   string `json:"name,omitempty" description:"Only the changes of this saved search."`
+	Since  string `json:"since,omitempty" description:"Only changes found at or after this RFC 3339 time or YYYY-MM-DD date."`
+	Limit  int    `json:"limit,omitempty" description:"Maximum changes to return, newest first. Default is 20, maximum 100."`
+}
+
+type WorkingMemorySetInput struct {
+	SessionID string `json:"session_id,omitempty" description:"Working memory session. Defaults to the MCP session of the call."`
+	Key       string `json:"key,omitempty" description:"Key of the value to set or delete. Pass either key or note."`
+	Value     string `json:"value,omitempty"`
+	Note      string `json:"note,omitempty" description:"Scratch note to append to the session."`
+	Delete    bool   `json:"delete,omitempty" description:"Delete the value of key instead of setting it."`
+}
+
+type WorkingMemoryGetInput struct {
+	SessionID string `json:"session_id,omitempty" description:"Working memory session. Defaults to the MCP session of the call."`
+	Key       string `json:"key,omitempty" description:"Only the value of this key. Leave empty to list every value and note."`
+}
+
+type WorkingMemoryPromoteInput struct {
+	SessionID string         `json:"session_id,omitempty" description:"Working memory session. Defaults to the MCP session of the call."`
+	UserID    string         `json:"user_id"`
+	Keys      []string       `json:"keys,omitempty" description:"Values to save as facts of the user."`
+	NoteIDs   []int          `json:"note_ids,omitempty" description:"Notes to add as remembrances of the user."`
+	All       bool           `json:"all,omitempty" description:"Promote every value and note of the session."`
+	Metadata  FlexibleObject `json:"metadata,omitempty" description:"Metadata added to the remembrances created from notes."`
+	Keep      bool           `json:"keep,omitempty" description:"Keep the promoted items in working memory. By default they are removed."`
+	ProvenanceInput
+}
+
+type WorkingMemoryEndInput struct {
+	SessionID string `json:"session_id,omitempty" description:"Working memory session. Defaults to the MCP session of the call."`
+}
+
+type PinInput struct {
+	UserID string `json:"user_id,omitempty" description:"Owner of the fact or vector. Required for facts; documents are shared."`
+	Kind   string `json:"kind,omitempty" description:"What to pin: fact, vector or document. Omit with ref to list the pinned items."`
+	Ref    string `json:"ref,omitempty" description:"Fact key, vector ID or document file path."`
+	Unpin  bool   `json:"unpin,omitempty" description:"Remove the pin instead of adding it."`
 }
 
 type GetStatsInput struct {
@@ -143,8 +472,10 @@ type GetStatsInput struct {
 }
 
 type ToRememberInput struct {
-	UserID  string `json:"user_id"`
-	Content string `json:"content"`
+	UserID         string `json:"user_id"`
+	Content        string `json:"content"`
+	IdempotencyKey string `json:"idempotency_key,omitempty"`
+	ProvenanceInput
 }
 
 type LastToRememberInput struct {
@@ -153,10 +484,12 @@ type LastToRememberInput struct {
 
 // Event tool input structs
 type SaveEventInput struct {
-	UserID   string         `json:"user_id" jsonschema:"required,description=Project or user identifier"`
-	Subject  string         `json:"subject" jsonschema:"required,description=Semantic subject/category for the event (e.g. 'conversation:session_1' or 'log:build')"`
-	Content  string         `json:"content" jsonschema:"required,description=Event content or message"`
-	Metadata FlexibleObject `json:"metadata,omitempty" jsonschema:"description=Optional additional metadata"`
+	UserID         string         `json:"user_id" jsonschema:"required,description=Project or user identifier"`
+	Subject        string         `json:"subject" jsonschema:"required,description=Semantic subject/category for the event (e.g. 'conversation:session_1' or 'log:build')"`
+	Content        string         `json:"content" jsonschema:"required,description=Event content or message"`
+	Metadata       FlexibleObject `json:"metadata,omitempty" jsonschema:"description=Optional additional metadata"`
+	IdempotencyKey string         `json:"idempotency_key,omitempty" jsonschema:"description=Key identifying this call; retries with the same key return the first result instead of saving again"`
+	ProvenanceInput
 }
 
 type SearchEventsInput struct {
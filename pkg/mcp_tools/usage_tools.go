@@ -0,0 +1,239 @@
+package mcp_tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ThinkInAIXYZ/go-mcp/protocol"
+	"github.com/madeindigio/remembrances-mcp/internal/storage"
+)
+
+// maxUsagePreview is the length content previews are cut to in usage reports.
+const maxUsagePreview = 80
+
+// memoryUsage is a fact, vector or document with its retrievals in the report window.
+type memoryUsage struct {
+	Kind    string     `json:"kind"`
+	Ref     string     `json:"ref"`
+	Preview string     `json:"preview"`
+	Hits    int        `json:"hits"`
+	LastHit *time.Time `json:"last_hit,omitempty"`
+	Pinned  bool       `json:"pinned,omitempty"`
+}
+
+func (tm *ToolManager) usageReportTool() *protocol.Tool {
+	tool, err := protocol.NewTool("remembrance_usage_report", `List the most and least retrieved facts, vectors and documents over a time window. Use how_to_use("remembrance_usage_report") for details.`, UsageReportInput{})
+	if err != nil {
+		slog.Error("failed to create tool", "name", "remembrance_usage_report", "err", err)
+		return nil
+	}
+	return tool
+}
+
+// recordHits stores that a retrieval tool returned the given memories.
+// Failures are logged only, so they never fail the retrieval itself.
+// Scheduled saved search runs are not retrievals by an agent and are skipped.
+func (tm *ToolManager) recordHits(ctx context.Context, tool string, hits []storage.MemoryHit) {
+	if scheduledSearchRun(ctx) {
+		return
+	}
+	for i := range hits {
+		hits[i].Tool = tool
+	}
+	if err := tm.storage.RecordMemoryHits(ctx, hits); err != nil {
+		slog.Warn("failed to record memory hits", "tool", tool, "error", err)
+	}
+}
+
+// vectorHits returns the hits of vector search results.
+func vectorHits(userID string, results []storage.VectorResult) []storage.MemoryHit {
+	hits := make([]storage.MemoryHit, 0, len(results))
+	for _, r := range results {
+		hits = append(hits, storage.MemoryHit{Kind: storage.TrashKindVector, Ref: r.ID, UserID: userID})
+	}
+	return hits
+}
+
+// documentHits returns the hits of document search results, once per
+// document however many of its chunks matched.
+func documentHits(userID string, results []storage.DocumentResult) []storage.MemoryHit {
+	var hits []storage.MemoryHit
+	seen := map[string]bool{}
+	for _, r := range results {
+		if r.Document == nil {
+			continue
+		}
+		path := documentSourcePath(r.Document.FilePath)
+		if !seen[path] {
+			seen[path] = true
+			hits = append(hits, storage.MemoryHit{Kind: storage.TrashKindDocument, Ref: path, UserID: userID})
+		}
+	}
+	return hits
+}
+
+func (tm *ToolManager) usageReportHandler(ctx context.Context, request *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+	var input UsageReportInput
+	if err := json.Unmarshal(request.RawArguments, &input); err != nil {
+		return nil, fmt.Errorf(errParseArgs, err)
+	}
+	if input.UserID == "" {
+		return nil, validationErrorf("user_id is required")
+	}
+	switch input.Kind {
+	case "", storage.TrashKindFact, storage.TrashKindVector, storage.TrashKindDocument:
+	default:
+		return nil, validationErrorf("invalid kind %q: use fact, vector or document", input.Kind)
+	}
+	if input.LastDays <= 0 {
+		input.LastDays = 30
+	}
+	if input.Limit <= 0 {
+		input.Limit = 10
+	}
+
+	since := time.Now().UTC().AddDate(0, 0, -input.LastDays)
+	counts, err := tm.storage.CountMemoryHits(ctx, input.UserID, since)
+	if err != nil {
+		return nil, err
+	}
+	memories, err := tm.usageMemories(ctx, input.UserID, input.Kind)
+	if err != nil {
+		return nil, err
+	}
+
+	pinned, err := tm.pinnedRefs(ctx, input.UserID, input.Kind)
+	if err != nil {
+		return nil, err
+	}
+	byRef := make(map[string]*memoryUsage, len(memories))
+	for i := range memories {
+		key := memories[i].Kind + "\x00" + memories[i].Ref
+		byRef[key] = &memories[i]
+		memories[i].Pinned = pinned[key]
+	}
+	for _, c := range counts {
+		if m, ok := byRef[c.Kind+"\x00"+c.Ref]; ok {
+			m.Hits = c.Hits
+			lastHit := c.LastHit
+			m.LastHit = &lastHit
+		}
+	}
+
+	most, least := rankMemoryUsage(memories, input.Limit)
+	retrieved := len(memories)
+	for _, m := range memories {
+		if m.Hits == 0 {
+			retrieved--
+		}
+	}
+
+	response := map[string]interface{}{
+		"user_id":         input.UserID,
+		"since":           since.Format(time.RFC3339),
+		"memories":        len(memories),
+		"retrieved":       retrieved,
+		"never_retrieved": len(memories) - retrieved,
+		"most_retrieved":  most,
+		"least_retrieved": least,
+	}
+	if input.Kind != "" {
+		response["kind"] = input.Kind
+	}
+	if len(memories) == 0 {
+		response["message"] = fmt.Sprintf("No memories found for user '%s'", input.UserID)
+	}
+
+	return protocol.NewCallToolResult([]protocol.Content{
+		&protocol.TextContent{Type: "text", Text: MarshalTOON(response)},
+	}, false), nil
+}
+
+// usageMemories lists the facts and vectors of userID and every document, or
+// only those of kind when set.
+func (tm *ToolManager) usageMemories(ctx context.Context, userID, kind string) ([]memoryUsage, error) {
+	var memories []memoryUsage
+	if kind == "" || kind == storage.TrashKindFact {
+		facts, err := tm.storage.ListFacts(ctx, userID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list facts: %w", err)
+		}
+		for key, value := range facts {
+			memories = append(memories, memoryUsage{Kind: storage.TrashKindFact, Ref: key, Preview: usagePreview(fmt.Sprint(value))})
+		}
+	}
+	if kind == "" || kind == storage.TrashKindVector {
+		vectors, err := tm.storage.ListVectorMemories(ctx, userID, 0)
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range vectors {
+			memories = append(memories, memoryUsage{Kind: storage.TrashKindVector, Ref: v.ID, Preview: usagePreview(v.Content)})
+		}
+	}
+	if kind == "" || kind == storage.TrashKindDocument {
+		docs, err := tm.storage.ListDocuments(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, d := range docs {
+			title, _ := d.Metadata["title"].(string)
+			memories = append(memories, memoryUsage{Kind: storage.TrashKindDocument, Ref: d.FilePath, Preview: usagePreview(title)})
+		}
+	}
+	return memories, nil
+}
+
+// rankMemoryUsage returns up to limit memories with the most hits, and up to
+// limit of the remaining unpinned ones with the fewest, never retrieved first.
+// Pinned memories are kept whatever their use, so they are never among the
+// least retrieved.
+func rankMemoryUsage(memories []memoryUsage, limit int) (most, least []memoryUsage) {
+	sorted := append([]memoryUsage(nil), memories...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Hits != sorted[j].Hits {
+			return sorted[i].Hits > sorted[j].Hits
+		}
+		if sorted[i].Kind != sorted[j].Kind {
+			return sorted[i].Kind < sorted[j].Kind
+		}
+		return sorted[i].Ref < sorted[j].Ref
+	})
+
+	most = make([]memoryUsage, 0, limit)
+	for _, m := range sorted {
+		if m.Hits == 0 || len(most) == limit {
+			break
+		}
+		most = append(most, m)
+	}
+
+	var rest []memoryUsage
+	for _, m := range sorted[len(most):] {
+		if !m.Pinned {
+			rest = append(rest, m)
+		}
+	}
+	sort.SliceStable(rest, func(i, j int) bool {
+		return rest[i].Hits < rest[j].Hits
+	})
+	if len(rest) > limit {
+		rest = rest[:limit]
+	}
+	least = append(make([]memoryUsage, 0, len(rest)), rest...)
+	return most, least
+}
+
+// usagePreview shortens content to a single line of at most maxUsagePreview characters.
+func usagePreview(content string) string {
+	preview := strings.Join(strings.Fields(content), " ")
+	if runes := []rune(preview); len(runes) > maxUsagePreview {
+		preview = string(runes[:maxUsagePreview]) + "..."
+	}
+	return preview
+}
@@ -0,0 +1,84 @@
+package mcp_tools
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/madeindigio/remembrances-mcp/internal/storage"
+)
+
+func TestRankMemoryUsage(t *testing.T) {
+	memories := []memoryUsage{
+		{Kind: "fact", Ref: "a", Hits: 0},
+		{Kind: "fact", Ref: "b", Hits: 5},
+		{Kind: "vector", Ref: "c", Hits: 2},
+		{Kind: "document", Ref: "d", Hits: 0},
+		{Kind: "vector", Ref: "e", Hits: 9},
+	}
+
+	most, least := rankMemoryUsage(memories, 2)
+	if len(most) != 2 || most[0].Ref != "e" || most[1].Ref != "b" {
+		t.Errorf("most = %+v, want e then b", most)
+	}
+	if len(least) != 2 || least[0].Ref != "d" || least[1].Ref != "a" {
+		t.Errorf("least = %+v, want d then a", least)
+	}
+
+	most, least = rankMemoryUsage(memories, 10)
+	if len(most) != 3 {
+		t.Errorf("most has %d entries, want the 3 retrieved ones", len(most))
+	}
+	if len(least) != 2 || least[0].Hits != 0 || least[1].Hits != 0 {
+		t.Errorf("least = %+v, want the 2 never retrieved ones", least)
+	}
+}
+
+func TestRankMemoryUsageKeepsPinnedOutOfLeast(t *testing.T) {
+	memories := []memoryUsage{
+		{Kind: "fact", Ref: "a", Hits: 0, Pinned: true},
+		{Kind: "fact", Ref: "b", Hits: 1},
+		{Kind: "vector", Ref: "c", Hits: 3, Pinned: true},
+	}
+
+	most, least := rankMemoryUsage(memories, 10)
+	if len(most) != 2 || most[0].Ref != "c" {
+		t.Errorf("most = %+v, want c then b", most)
+	}
+	if len(least) != 0 {
+		t.Errorf("least = %+v, want no entries", least)
+	}
+
+	_, least = rankMemoryUsage(memories, 1)
+	if len(least) != 1 || least[0].Ref != "b" {
+		t.Errorf("least = %+v, want only the unpinned b", least)
+	}
+}
+
+func TestDocumentHits(t *testing.T) {
+	results := []storage.DocumentResult{
+		{Document: &storage.Document{FilePath: "notes/a.md#chunk0"}},
+		{Document: &storage.Document{FilePath: "notes/a.md#chunk3"}},
+		{Document: &storage.Document{FilePath: "notes/b.md"}},
+		{Document: nil},
+	}
+
+	hits := documentHits("", results)
+	if len(hits) != 2 || hits[0].Ref != "notes/a.md" || hits[1].Ref != "notes/b.md" {
+		t.Errorf("documentHits() = %+v, want notes/a.md and notes/b.md once each", hits)
+	}
+	for _, hit := range hits {
+		if hit.Kind != storage.TrashKindDocument {
+			t.Errorf("hit kind = %q, want %q", hit.Kind, storage.TrashKindDocument)
+		}
+	}
+}
+
+func TestUsagePreview(t *testing.T) {
+	if got := usagePreview("  multi\nline\tcontent "); got != "multi line content" {
+		t.Errorf("usagePreview() = %q", got)
+	}
+	long := strings.Repeat("é", maxUsagePreview+10)
+	if got := usagePreview(long); got != strings.Repeat("é", maxUsagePreview)+"..." {
+		t.Errorf("usagePreview() did not cut at %d characters: %q", maxUsagePreview, got)
+	}
+}
@@ -0,0 +1,266 @@
+package mcp_tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/ThinkInAIXYZ/go-mcp/protocol"
+	"github.com/madeindigio/remembrances-mcp/internal/storage"
+)
+
+// defaultPurgeArchiveDir is used when no purge archive directory is configured.
+const defaultPurgeArchiveDir = "./purge-archives"
+
+// unsafeFileChars matches characters replaced when a user_id is used in a file name.
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// User management tool definitions
+func (tm *ToolManager) listUsersTool() *protocol.Tool {
+	tool, err := protocol.NewTool("remembrance_list_users", `List every user_id with stored facts, remembrances, events or entities. Use how_to_use("remembrance_list_users") for details.`, ListUsersInput{})
+	if err != nil {
+		slog.Error("failed to create tool", "name", "remembrance_list_users", "err", err)
+		return nil
+	}
+	return tool
+}
+
+func (tm *ToolManager) deleteUserTool() *protocol.Tool {
+	tool, err := protocol.NewTool("remembrance_delete_user", `Permanently delete all data of a user_id. Use how_to_use("remembrance_delete_user") for details.`, DeleteUserInput{})
+	if err != nil {
+		slog.Error("failed to create tool", "name", "remembrance_delete_user", "err", err)
+		return nil
+	}
+	return tool
+}
+
+func (tm *ToolManager) renameUserTool() *protocol.Tool {
+	tool, err := protocol.NewTool("remembrance_rename_user", `Move all data of a user_id to a new user_id. Use how_to_use("remembrance_rename_user") for details.`, RenameUserInput{})
+	if err != nil {
+		slog.Error("failed to create tool", "name", "remembrance_rename_user", "err", err)
+		return nil
+	}
+	return tool
+}
+
+func (tm *ToolManager) purgeUserTool() *protocol.Tool {
+	tool, err := protocol.NewTool("remembrance_purge_user", `Export all data of a user_id to an archive, then delete it and record the purge in the audit log. Use how_to_use("remembrance_purge_user") for details.`, PurgeUserInput{})
+	if err != nil {
+		slog.Error("failed to create tool", "name", "remembrance_purge_user", "err", err)
+		return nil
+	}
+	return tool
+}
+
+func (tm *ToolManager) recountStatsTool() *protocol.Tool {
+	tool, err := protocol.NewTool("remembrance_recount_stats", `Recount the records behind the user_stats counters and rewrite drifted ones. Use how_to_use("remembrance_recount_stats") for details.`, RecountStatsInput{})
+	if err != nil {
+		slog.Error("failed to create tool", "name", "remembrance_recount_stats", "err", err)
+		return nil
+	}
+	return tool
+}
+
+// User management tool handlers
+func (tm *ToolManager) listUsersHandler(ctx context.Context, request *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+	users, err := tm.storage.ListUsers(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list users: %w", err)
+	}
+
+	if len(users) == 0 {
+		payload := CreateEmptyResultTOON("No users have stored data yet", AlternativeSuggestions{})
+		return protocol.NewCallToolResult([]protocol.Content{
+			&protocol.TextContent{Type: "text", Text: payload},
+		}, false), nil
+	}
+
+	response := map[string]interface{}{
+		"count": len(users),
+		"users": users,
+	}
+	return protocol.NewCallToolResult([]protocol.Content{
+		&protocol.TextContent{Type: "text", Text: MarshalTOON(response)},
+	}, false), nil
+}
+
+func (tm *ToolManager) deleteUserHandler(ctx context.Context, request *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+	var input DeleteUserInput
+	if err := json.Unmarshal(request.RawArguments, &input); err != nil {
+		return nil, fmt.Errorf(errParseArgs, err)
+	}
+
+	if input.UserID == "" {
+		return nil, validationErrorf("user_id is required")
+	}
+	if !input.Confirm {
+		return nil, validationErrorf("deleting user '%s' is permanent and bypasses the trash; call again with confirm: true", input.UserID)
+	}
+
+	deleted, err := tm.storage.DeleteUser(ctx, input.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete user: %w", err)
+	}
+
+	total := 0
+	for _, n := range deleted {
+		total += n
+	}
+	response := map[string]interface{}{
+		"message": fmt.Sprintf("Deleted %d records of user '%s'", total, input.UserID),
+		"deleted": deleted,
+	}
+	return protocol.NewCallToolResult([]protocol.Content{
+		&protocol.TextContent{Type: "text", Text: MarshalTOON(response)},
+	}, false), nil
+}
+
+func (tm *ToolManager) renameUserHandler(ctx context.Context, request *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+	var input RenameUserInput
+	if err := json.Unmarshal(request.RawArguments, &input); err != nil {
+		return nil, fmt.Errorf(errParseArgs, err)
+	}
+
+	renamed, err := tm.storage.RenameUser(ctx, input.UserID, input.NewUserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to rename user: %w", err)
+	}
+
+	if len(renamed) == 0 {
+		suggestions := tm.FindUserAlternatives(ctx, "vector_memories", input.UserID)
+		payload := CreateEmptyResultTOON(fmt.Sprintf("No data found for user '%s'", input.UserID), suggestions)
+		return protocol.NewCallToolResult([]protocol.Content{
+			&protocol.TextContent{Type: "text", Text: payload},
+		}, false), nil
+	}
+
+	response := map[string]interface{}{
+		"message": fmt.Sprintf("Renamed user '%s' to '%s'", input.UserID, input.NewUserID),
+		"updated": renamed,
+	}
+	return protocol.NewCallToolResult([]protocol.Content{
+		&protocol.TextContent{Type: "text", Text: MarshalTOON(response)},
+	}, false), nil
+}
+
+func (tm *ToolManager) purgeUserHandler(ctx context.Context, request *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+	var input PurgeUserInput
+	if err := json.Unmarshal(request.RawArguments, &input); err != nil {
+		return nil, fmt.Errorf(errParseArgs, err)
+	}
+
+	if input.UserID == "" {
+		return nil, validationErrorf("user_id is required")
+	}
+	if !input.Confirm {
+		return nil, validationErrorf("purging user '%s' permanently deletes all of its data; call again with confirm: true", input.UserID)
+	}
+
+	export, err := tm.storage.ExportUser(ctx, input.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to export user data: %w", err)
+	}
+	if len(export.Tables) == 0 {
+		suggestions := tm.FindUserAlternatives(ctx, "vector_memories", input.UserID)
+		payload := CreateEmptyResultTOON(fmt.Sprintf("No data found for user '%s'", input.UserID), suggestions)
+		return protocol.NewCallToolResult([]protocol.Content{
+			&protocol.TextContent{Type: "text", Text: payload},
+		}, false), nil
+	}
+
+	// The archive must be on disk before anything is deleted
+	archivePath, err := tm.writePurgeArchive(export)
+	if err != nil {
+		return nil, err
+	}
+
+	deleted, err := tm.storage.PurgeUser(ctx, input.UserID, archivePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to purge user data (export kept at %s): %w", archivePath, err)
+	}
+
+	// Remove the markdown files of the knowledge base documents the user contributed
+	removed := map[string]bool{}
+	for _, row := range export.Tables["knowledge_base"] {
+		path, _ := row["source_file"].(string)
+		if path == "" {
+			path, _ = row["file_path"].(string)
+		}
+		if path == "" || removed[path] {
+			continue
+		}
+		removed[path] = true
+		if err := tm.removeMarkdownFile(path); err != nil {
+			slog.Warn("failed to remove purged document file", "file_path", path, "error", err)
+		}
+	}
+
+	total := 0
+	for _, n := range deleted {
+		total += n
+	}
+	response := map[string]interface{}{
+		"message": fmt.Sprintf("Purged %d records of user '%s'", total, input.UserID),
+		"archive": archivePath,
+		"deleted": deleted,
+	}
+	return protocol.NewCallToolResult([]protocol.Content{
+		&protocol.TextContent{Type: "text", Text: MarshalTOON(response)},
+	}, false), nil
+}
+
+func (tm *ToolManager) recountStatsHandler(ctx context.Context, request *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+	var input RecountStatsInput
+	if err := json.Unmarshal(request.RawArguments, &input); err != nil {
+		return nil, fmt.Errorf(errParseArgs, err)
+	}
+
+	changes, err := tm.storage.RecountUserStats(ctx, input.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to recount user stats: %w", err)
+	}
+
+	scope := "all users"
+	if input.UserID != "" {
+		scope = fmt.Sprintf("user '%s'", input.UserID)
+	}
+	message := fmt.Sprintf("Stats of %s are up to date", scope)
+	if len(changes) > 0 {
+		message = fmt.Sprintf("Corrected %d counters of %s", len(changes), scope)
+	}
+	response := map[string]interface{}{
+		"message": message,
+		"changes": changes,
+	}
+	return protocol.NewCallToolResult([]protocol.Content{
+		&protocol.TextContent{Type: "text", Text: MarshalTOON(response)},
+	}, false), nil
+}
+
+// writePurgeArchive writes export as JSON to the purge archive directory and
+// returns the path of the archive file.
+func (tm *ToolManager) writePurgeArchive(export *storage.UserExport) (string, error) {
+	dir := tm.purgeArchiveDir
+	if dir == "" {
+		dir = defaultPurgeArchiveDir
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", fmt.Errorf("failed to create purge archive directory %s: %w", dir, err)
+	}
+
+	data, err := json.MarshalIndent(export, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode user export: %w", err)
+	}
+
+	name := fmt.Sprintf("%s-%s.json", unsafeFileChars.ReplaceAllString(export.UserID, "_"), export.ExportedAt.Format("20060102T150405Z"))
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return "", fmt.Errorf("failed to write purge archive %s: %w", path, err)
+	}
+	return path, nil
+}
@@ -3,10 +3,13 @@ package mcp_tools
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 
 	"github.com/ThinkInAIXYZ/go-mcp/protocol"
+	"github.com/madeindigio/remembrances-mcp/internal/storage"
+	"github.com/madeindigio/remembrances-mcp/pkg/embedder"
 )
 
 // Vector tool definitions
@@ -53,10 +56,44 @@ func (tm *ToolManager) addVectorHandler(ctx context.Context, request *protocol.C
 		return nil, fmt.Errorf(errParseArgs, err)
 	}
 
+	content, err := tm.redactContent("add_vector", input.Content)
+	if err != nil {
+		return nil, err
+	}
+	input.Content = content
+
 	// Generate embedding for the content
-	embedding, err := tm.embedder.EmbedQuery(ctx, input.Content)
+	embedding, err := tm.embedderFor(embedder.RouteVectors).EmbedQuery(ctx, input.Content)
 	if err != nil {
-		return nil, fmt.Errorf(errGenEmbedding, err)
+		return nil, embedderErrorf(errGenEmbedding, err)
+	}
+
+	if !input.AllowDuplicate {
+		existing, err := tm.findDuplicateVector(ctx, input.UserID, embedding)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil {
+			match := duplicateMatch{
+				ID:         existing.ID,
+				Content:    existing.Content,
+				Similarity: existing.Similarity,
+				Metadata:   existing.Metadata,
+			}
+			if metadata := input.Metadata.AsMap(); input.MergeMetadata && len(metadata) > 0 {
+				if err := tm.storage.MergeVectorMetadata(ctx, existing.ID, metadata); err != nil {
+					return nil, err
+				}
+				if match.Metadata == nil {
+					match.Metadata = map[string]interface{}{}
+				}
+				for k, v := range metadata {
+					match.Metadata[k] = v
+				}
+				match.Merged = true
+			}
+			return duplicateResult("remembrance", match), nil
+		}
 	}
 
 	err = tm.storage.IndexVector(ctx, input.UserID, input.Content, embedding, input.Metadata.AsMap())
@@ -81,17 +118,41 @@ func (tm *ToolManager) searchVectorsHandler(ctx context.Context, request *protoc
 	if input.Limit == 0 {
 		input.Limit = 10
 	}
+	if err := checkMinConfidence(input.MinConfidence); err != nil {
+		return nil, err
+	}
+	switch input.SortBy {
+	case "", sortBySimilarity, sortByConfidence:
+	default:
+		return nil, validationErrorf("invalid sort_by %q: use similarity or confidence", input.SortBy)
+	}
 
-	// Generate embedding for the query
-	queryEmbedding, err := tm.embedder.EmbedQuery(ctx, input.Query)
+	queries, err := tm.searchQueries(ctx, input.Query, input.Expand, input.HyDE)
 	if err != nil {
-		return nil, fmt.Errorf(errGenQueryEmbedding, err)
+		return nil, err
 	}
 
-	results, err := tm.storage.SearchSimilar(ctx, input.UserID, queryEmbedding, input.Limit)
+	opts := storage.VectorSearchOptions{
+		Limit:         input.Limit,
+		EfSearch:      input.EfSearch,
+		Accuracy:      input.Accuracy,
+		MinSimilarity: tm.scoring.cutoff(input.MinSimilarity),
+		MinConfidence: input.MinConfidence,
+	}
+	lists, err := multiQuerySearch(ctx, tm.embedderFor(embedder.RouteVectors).EmbedQuery, queries, func(ctx context.Context, embedding []float32) ([]storage.VectorResult, error) {
+		return tm.storage.SearchSimilarWithOptions(ctx, input.UserID, embedding, opts)
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to search remembrances: %w", err)
 	}
+	results := lists[0]
+	if len(lists) > 1 {
+		results = fuseRanked(lists, func(r storage.VectorResult) string { return r.ID }, input.Limit)
+	}
+	results = applyScoring(tm.scoring, results, input.MinSimilarity, vectorSimilarity, setVectorScore)
+	if input.SortBy == sortByConfidence {
+		sortVectorsByConfidence(results)
+	}
 
 	if len(results) == 0 {
 		suggestions := tm.FindUserAlternatives(ctx, "vector_memories", input.UserID)
@@ -104,6 +165,9 @@ func (tm *ToolManager) searchVectorsHandler(ctx context.Context, request *protoc
 		}, false), nil
 	}
 
+	tm.recordHits(ctx, "search_vectors", vectorHits(input.UserID, results))
+	reportSearchItems(ctx, vectorSearchItems(results))
+
 	payload := map[string]interface{}{
 		"user_id": input.UserID,
 		"query":   input.Query,
@@ -111,6 +175,9 @@ func (tm *ToolManager) searchVectorsHandler(ctx context.Context, request *protoc
 		"count":   len(results),
 		"results": results,
 	}
+	if len(queries) > 1 {
+		payload["queries"] = queries
+	}
 
 	return protocol.NewCallToolResult([]protocol.Content{
 		&protocol.TextContent{
@@ -126,21 +193,35 @@ func (tm *ToolManager) updateVectorHandler(ctx context.Context, request *protoco
 		return nil, fmt.Errorf(errParseArgs, err)
 	}
 
+	content, err := tm.redactContent("update_vector", input.Content)
+	if err != nil {
+		return nil, err
+	}
+	input.Content = content
+
 	// Generate new embedding for the updated content
-	embedding, err := tm.embedder.EmbedQuery(ctx, input.Content)
+	embedding, err := tm.embedderFor(embedder.RouteVectors).EmbedQuery(ctx, input.Content)
 	if err != nil {
-		return nil, fmt.Errorf(errGenEmbedding, err)
+		return nil, embedderErrorf(errGenEmbedding, err)
 	}
 
-	err = tm.storage.UpdateVector(ctx, input.ID, input.UserID, input.Content, embedding, input.Metadata.AsMap())
+	revision, err := tm.storage.UpdateVector(ctx, input.ID, input.UserID, input.Content, embedding, input.Metadata.AsMap(), input.Revision)
 	if err != nil {
+		var conflict *storage.RevisionConflictError
+		if errors.As(err, &conflict) {
+			return conflictResult(&revisionConflict{
+				ID:       conflict.ID,
+				Expected: conflict.Expected,
+				Current:  conflict.Current,
+			}, "The remembrance was updated by someone else. Search it again to read the current content and revision, then retry."), nil
+		}
 		return nil, fmt.Errorf("failed to update remembrance: %w", err)
 	}
 
 	return protocol.NewCallToolResult([]protocol.Content{
 		&protocol.TextContent{
 			Type: "text",
-			Text: fmt.Sprintf("Successfully updated remembrance '%s' for user '%s'", input.ID, input.UserID),
+			Text: fmt.Sprintf("Successfully updated remembrance '%s' for user '%s' (revision %d)", input.ID, input.UserID, revision),
 		},
 	}, false), nil
 }
@@ -151,6 +232,9 @@ func (tm *ToolManager) deleteVectorHandler(ctx context.Context, request *protoco
 		return nil, fmt.Errorf(errParseArgs, err)
 	}
 
+	if input.Force {
+		ctx = storage.WithForce(ctx)
+	}
 	err := tm.storage.DeleteVector(ctx, input.ID, input.UserID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to delete remembrance: %w", err)
@@ -159,7 +243,7 @@ func (tm *ToolManager) deleteVectorHandler(ctx context.Context, request *protoco
 	return protocol.NewCallToolResult([]protocol.Content{
 		&protocol.TextContent{
 			Type: "text",
-			Text: fmt.Sprintf("Successfully deleted remembrance '%s' for user '%s'", input.ID, input.UserID),
+			Text: fmt.Sprintf("Successfully deleted remembrance '%s' for user '%s' (moved to trash, restorable with remembrance_restore)", input.ID, input.UserID),
 		},
 	}, false), nil
 }
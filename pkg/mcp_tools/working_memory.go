@@ -0,0 +1,476 @@
+package mcp_tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ThinkInAIXYZ/go-mcp/protocol"
+	mcpserver "github.com/ThinkInAIXYZ/go-mcp/server"
+	"github.com/madeindigio/remembrances-mcp/pkg/embedder"
+)
+
+// defaultWorkingMemoryTTL is how long an inactive session keeps its working
+// memory when no TTL is configured.
+const defaultWorkingMemoryTTL = 30 * time.Minute
+
+// workingMemoryJanitorTick is how often expired sessions are dropped.
+const workingMemoryJanitorTick = time.Minute
+
+// maxWorkingMemoryEntries caps the values and notes of one session.
+const maxWorkingMemoryEntries = 500
+
+// workingMemory holds the session-scoped scratch values and notes of the
+// working_memory_* tools. It lives in the server process only: sessions are
+// dropped after ttl of inactivity, when they are ended, and when the server
+// stops.
+type workingMemory struct {
+	mu       sync.Mutex
+	ttl      time.Duration
+	sessions map[string]*workingSession
+}
+
+type workingSession struct {
+	values     map[string]workingValue
+	notes      []workingNote
+	nextNote   int
+	lastActive time.Time
+}
+
+type workingValue struct {
+	Key       string    `json:"key"`
+	Value     string    `json:"value"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type workingNote struct {
+	ID        int       `json:"id"`
+	Text      string    `json:"text"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// SetWorkingMemoryTTL configures how long the working memory of an inactive
+// session is kept. 0 keeps it until the session is ended or the server stops;
+// negative values fall back to the default.
+func (tm *ToolManager) SetWorkingMemoryTTL(ttl time.Duration) {
+	if ttl < 0 {
+		ttl = defaultWorkingMemoryTTL
+	}
+	tm.workingMemory.mu.Lock()
+	tm.workingMemory.ttl = ttl
+	tm.workingMemory.mu.Unlock()
+}
+
+// StartWorkingMemoryJanitor drops the working memory of sessions inactive for
+// longer than the TTL until ctx is done.
+func (tm *ToolManager) StartWorkingMemoryJanitor(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(workingMemoryJanitorTick)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			if expired := tm.workingMemory.expire(time.Now()); expired > 0 {
+				slog.Debug("expired working memory sessions", "count", expired)
+			}
+		}
+	}()
+}
+
+// session returns the live session with id, creating it when create is set,
+// and marks it active at now. Expired sessions are dropped first.
+func (wm *workingMemory) session(id string, now time.Time, create bool) *workingSession {
+	s, ok := wm.sessions[id]
+	if ok && wm.expired(s, now) {
+		delete(wm.sessions, id)
+		ok = false
+	}
+	if !ok {
+		if !create {
+			return nil
+		}
+		if wm.sessions == nil {
+			wm.sessions = map[string]*workingSession{}
+		}
+		s = &workingSession{values: map[string]workingValue{}, nextNote: 1}
+		wm.sessions[id] = s
+	}
+	s.lastActive = now
+	return s
+}
+
+func (wm *workingMemory) expired(s *workingSession, now time.Time) bool {
+	return wm.ttl > 0 && now.Sub(s.lastActive) > wm.ttl
+}
+
+// expire drops the sessions inactive at now and returns how many it dropped.
+func (wm *workingMemory) expire(now time.Time) int {
+	wm.mu.Lock()
+	defer wm.mu.Unlock()
+	expired := 0
+	for id, s := range wm.sessions {
+		if wm.expired(s, now) {
+			delete(wm.sessions, id)
+			expired++
+		}
+	}
+	return expired
+}
+
+// end drops a session and reports whether it had working memory.
+func (wm *workingMemory) end(id string, now time.Time) bool {
+	wm.mu.Lock()
+	defer wm.mu.Unlock()
+	s, ok := wm.sessions[id]
+	delete(wm.sessions, id)
+	return ok && !wm.expired(s, now)
+}
+
+func (s *workingSession) entries() int {
+	return len(s.values) + len(s.notes)
+}
+
+// sortedValues returns the values of the session ordered by key.
+func (s *workingSession) sortedValues() []workingValue {
+	values := make([]workingValue, 0, len(s.values))
+	for _, v := range s.values {
+		values = append(values, v)
+	}
+	sort.Slice(values, func(i, j int) bool { return values[i].Key < values[j].Key })
+	return values
+}
+
+// workingSessionID returns the session a working memory call applies to: the
+// session_id argument, or else the MCP session of the call.
+func workingSessionID(ctx context.Context, sessionID string) (string, error) {
+	if sessionID = strings.TrimSpace(sessionID); sessionID != "" {
+		return sessionID, nil
+	}
+	if id, err := mcpserver.GetSessionIDFromCtx(ctx); err == nil && id != "" {
+		return id, nil
+	}
+	return "", validationErrorf("session_id is required when the call is not made over an MCP session")
+}
+
+func (tm *ToolManager) workingMemorySetTool() *protocol.Tool {
+	tool, err := protocol.NewTool("working_memory_set", `Keep a key-value or a scratch note in the working memory of this session, which expires when the session ends or goes idle. Use how_to_use("working_memory_set") for details.`, WorkingMemorySetInput{})
+	if err != nil {
+		slog.Error("failed to create tool", "name", "working_memory_set", "err", err)
+		return nil
+	}
+	return tool
+}
+
+func (tm *ToolManager) workingMemoryGetTool() *protocol.Tool {
+	tool, err := protocol.NewTool("working_memory_get", `Read the key-values and notes in the working memory of this session. Use how_to_use("working_memory_get") for details.`, WorkingMemoryGetInput{})
+	if err != nil {
+		slog.Error("failed to create tool", "name", "working_memory_get", "err", err)
+		return nil
+	}
+	return tool
+}
+
+func (tm *ToolManager) workingMemoryPromoteTool() *protocol.Tool {
+	tool, err := protocol.NewTool("working_memory_promote", `Promote working memory items to long-term memory: key-values become facts and notes become remembrances. Use how_to_use("working_memory_promote") for details.`, WorkingMemoryPromoteInput{})
+	if err != nil {
+		slog.Error("failed to create tool", "name", "working_memory_promote", "err", err)
+		return nil
+	}
+	return tool
+}
+
+func (tm *ToolManager) workingMemoryEndTool() *protocol.Tool {
+	tool, err := protocol.NewTool("working_memory_end", `End a working memory session, discarding the items that were not promoted. Use how_to_use("working_memory_end") for details.`, WorkingMemoryEndInput{})
+	if err != nil {
+		slog.Error("failed to create tool", "name", "working_memory_end", "err", err)
+		return nil
+	}
+	return tool
+}
+
+func (tm *ToolManager) workingMemorySetHandler(ctx context.Context, request *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+	var input WorkingMemorySetInput
+	if err := json.Unmarshal(request.RawArguments, &input); err != nil {
+		return nil, fmt.Errorf(errParseArgs, err)
+	}
+	sessionID, err := workingSessionID(ctx, input.SessionID)
+	if err != nil {
+		return nil, err
+	}
+	input.Key = strings.TrimSpace(input.Key)
+	if (input.Key == "") == (input.Note == "") {
+		return nil, validationErrorf("pass either key (with value or delete) or note")
+	}
+	if input.Delete && input.Key == "" {
+		return nil, validationErrorf("delete requires key; notes are removed by promoting them or ending the session")
+	}
+
+	wm := &tm.workingMemory
+	wm.mu.Lock()
+	defer wm.mu.Unlock()
+	now := time.Now().UTC()
+	s := wm.session(sessionID, now, !input.Delete)
+	response := map[string]interface{}{"session_id": sessionID}
+
+	switch {
+	case input.Delete:
+		if s == nil {
+			return nil, notFoundErrorf("no working memory value %q in session %q", input.Key, sessionID)
+		}
+		if _, ok := s.values[input.Key]; !ok {
+			return nil, notFoundErrorf("no working memory value %q in session %q", input.Key, sessionID)
+		}
+		delete(s.values, input.Key)
+		response["key"] = input.Key
+		response["deleted"] = true
+	case input.Key != "":
+		if _, ok := s.values[input.Key]; !ok && s.entries() >= maxWorkingMemoryEntries {
+			return nil, validationErrorf("working memory of session %q is full (%d items); promote or delete items first", sessionID, maxWorkingMemoryEntries)
+		}
+		s.values[input.Key] = workingValue{Key: input.Key, Value: input.Value, UpdatedAt: now}
+		response["key"] = input.Key
+		response["saved"] = true
+	default:
+		if s.entries() >= maxWorkingMemoryEntries {
+			return nil, validationErrorf("working memory of session %q is full (%d items); promote or delete items first", sessionID, maxWorkingMemoryEntries)
+		}
+		note := workingNote{ID: s.nextNote, Text: input.Note, CreatedAt: now}
+		s.nextNote++
+		s.notes = append(s.notes, note)
+		response["note_id"] = note.ID
+		response["saved"] = true
+	}
+	if s != nil {
+		response["items"] = s.entries()
+	}
+	if wm.ttl > 0 {
+		response["expires_after_idle"] = wm.ttl.String()
+	}
+	return protocol.NewCallToolResult([]protocol.Content{
+		&protocol.TextContent{Type: "text", Text: MarshalTOON(response)},
+	}, false), nil
+}
+
+func (tm *ToolManager) workingMemoryGetHandler(ctx context.Context, request *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+	var input WorkingMemoryGetInput
+	if err := json.Unmarshal(request.RawArguments, &input); err != nil {
+		return nil, fmt.Errorf(errParseArgs, err)
+	}
+	sessionID, err := workingSessionID(ctx, input.SessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	wm := &tm.workingMemory
+	wm.mu.Lock()
+	defer wm.mu.Unlock()
+	s := wm.session(sessionID, time.Now().UTC(), false)
+
+	key := strings.TrimSpace(input.Key)
+	if key != "" {
+		var value workingValue
+		ok := false
+		if s != nil {
+			value, ok = s.values[key]
+		}
+		if !ok {
+			return nil, notFoundErrorf("no working memory value %q in session %q", key, sessionID)
+		}
+		return protocol.NewCallToolResult([]protocol.Content{
+			&protocol.TextContent{Type: "text", Text: MarshalTOON(map[string]interface{}{
+				"session_id": sessionID,
+				"key":        value.Key,
+				"value":      value.Value,
+				"updated_at": value.UpdatedAt,
+			})},
+		}, false), nil
+	}
+
+	values := []workingValue{}
+	notes := []workingNote{}
+	if s != nil {
+		values = s.sortedValues()
+		notes = append(notes, s.notes...)
+	}
+	return protocol.NewCallToolResult([]protocol.Content{
+		&protocol.TextContent{Type: "text", Text: MarshalTOON(map[string]interface{}{
+			"session_id": sessionID,
+			"values":     values,
+			"notes":      notes,
+		})},
+	}, false), nil
+}
+
+func (tm *ToolManager) workingMemoryPromoteHandler(ctx context.Context, request *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+	var input WorkingMemoryPromoteInput
+	if err := json.Unmarshal(request.RawArguments, &input); err != nil {
+		return nil, fmt.Errorf(errParseArgs, err)
+	}
+	sessionID, err := workingSessionID(ctx, input.SessionID)
+	if err != nil {
+		return nil, err
+	}
+	if input.UserID == "" {
+		return nil, validationErrorf("user_id is required")
+	}
+	if !input.All && len(input.Keys) == 0 && len(input.NoteIDs) == 0 {
+		return nil, validationErrorf("pass keys, note_ids or all")
+	}
+
+	values, notes, err := tm.workingMemory.selectItems(sessionID, input.Keys, input.NoteIDs, input.All)
+	if err != nil {
+		return nil, err
+	}
+
+	// Items are written outside the working memory lock: embedding the notes
+	// can take a while, and a failure leaves the remaining items in place.
+	var promotedKeys []string
+	var promotedNotes []int
+	var duplicates []map[string]interface{}
+	for _, v := range values {
+		value, err := tm.redactContent("working_memory_promote", v.Value)
+		if err != nil {
+			return nil, err
+		}
+		if err := tm.storage.SaveFact(ctx, input.UserID, v.Key, value); err != nil {
+			return nil, fmt.Errorf("failed to save fact %q: %w", v.Key, err)
+		}
+		promotedKeys = append(promotedKeys, v.Key)
+	}
+	metadata := input.Metadata.AsMap()
+	for _, note := range notes {
+		content, err := tm.redactContent("working_memory_promote", note.Text)
+		if err != nil {
+			return nil, err
+		}
+		embedding, err := tm.embedderFor(embedder.RouteVectors).EmbedQuery(ctx, content)
+		if err != nil {
+			return nil, embedderErrorf(errGenEmbedding, err)
+		}
+		existing, err := tm.findDuplicateVector(ctx, input.UserID, embedding)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil {
+			duplicates = append(duplicates, map[string]interface{}{"note_id": note.ID, "existing_id": existing.ID, "similarity": existing.Similarity})
+			promotedNotes = append(promotedNotes, note.ID)
+			continue
+		}
+		noteMetadata := map[string]interface{}{"source": "working_memory", "session_id": sessionID}
+		for k, v := range metadata {
+			noteMetadata[k] = v
+		}
+		if err := tm.storage.IndexVector(ctx, input.UserID, content, embedding, noteMetadata); err != nil {
+			return nil, fmt.Errorf("failed to add remembrance for note %d: %w", note.ID, err)
+		}
+		promotedNotes = append(promotedNotes, note.ID)
+	}
+
+	if !input.Keep {
+		tm.workingMemory.removeItems(sessionID, promotedKeys, promotedNotes)
+	}
+
+	response := map[string]interface{}{
+		"session_id":   sessionID,
+		"user_id":      input.UserID,
+		"facts":        len(promotedKeys),
+		"remembrances": len(promotedNotes) - len(duplicates),
+	}
+	if len(promotedKeys) > 0 {
+		response["keys"] = promotedKeys
+	}
+	if len(duplicates) > 0 {
+		response["duplicates"] = duplicates
+	}
+	return protocol.NewCallToolResult([]protocol.Content{
+		&protocol.TextContent{Type: "text", Text: MarshalTOON(response)},
+	}, false), nil
+}
+
+func (tm *ToolManager) workingMemoryEndHandler(ctx context.Context, request *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+	var input WorkingMemoryEndInput
+	if err := json.Unmarshal(request.RawArguments, &input); err != nil {
+		return nil, fmt.Errorf(errParseArgs, err)
+	}
+	sessionID, err := workingSessionID(ctx, input.SessionID)
+	if err != nil {
+		return nil, err
+	}
+	ended := tm.workingMemory.end(sessionID, time.Now().UTC())
+	return protocol.NewCallToolResult([]protocol.Content{
+		&protocol.TextContent{Type: "text", Text: MarshalTOON(map[string]interface{}{
+			"session_id": sessionID,
+			"ended":      ended,
+		})},
+	}, false), nil
+}
+
+// selectItems returns copies of the values and notes of a session to promote:
+// all of them, or those named by keys and noteIDs, which must exist.
+func (wm *workingMemory) selectItems(sessionID string, keys []string, noteIDs []int, all bool) ([]workingValue, []workingNote, error) {
+	wm.mu.Lock()
+	defer wm.mu.Unlock()
+	s := wm.session(sessionID, time.Now().UTC(), false)
+	if s == nil {
+		return nil, nil, notFoundErrorf("no working memory in session %q", sessionID)
+	}
+	if all {
+		return s.sortedValues(), append([]workingNote(nil), s.notes...), nil
+	}
+
+	values := make([]workingValue, 0, len(keys))
+	for _, key := range keys {
+		v, ok := s.values[strings.TrimSpace(key)]
+		if !ok {
+			return nil, nil, notFoundErrorf("no working memory value %q in session %q", key, sessionID)
+		}
+		values = append(values, v)
+	}
+	notes := make([]workingNote, 0, len(noteIDs))
+	for _, id := range noteIDs {
+		found := false
+		for _, note := range s.notes {
+			if note.ID == id {
+				notes = append(notes, note)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, nil, notFoundErrorf("no working memory note %d in session %q", id, sessionID)
+		}
+	}
+	return values, notes, nil
+}
+
+// removeItems deletes promoted values and notes from a session.
+func (wm *workingMemory) removeItems(sessionID string, keys []string, noteIDs []int) {
+	wm.mu.Lock()
+	defer wm.mu.Unlock()
+	s := wm.session(sessionID, time.Now().UTC(), false)
+	if s == nil {
+		return
+	}
+	for _, key := range keys {
+		delete(s.values, key)
+	}
+	removed := make(map[int]bool, len(noteIDs))
+	for _, id := range noteIDs {
+		removed[id] = true
+	}
+	notes := s.notes[:0]
+	for _, note := range s.notes {
+		if !removed[note.ID] {
+			notes = append(notes, note)
+		}
+	}
+	s.notes = notes
+}
@@ -0,0 +1,125 @@
+package mcp_tools
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ThinkInAIXYZ/go-mcp/protocol"
+)
+
+func callWorkingMemory(t *testing.T, handler func(context.Context, *protocol.CallToolRequest) (*protocol.CallToolResult, error), args map[string]interface{}) (string, error) {
+	t.Helper()
+	raw, err := json.Marshal(args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := handler(context.Background(), &protocol.CallToolRequest{RawArguments: raw})
+	if err != nil {
+		return "", err
+	}
+	text, _ := resultText(result)
+	return text, nil
+}
+
+func TestWorkingMemorySetAndGet(t *testing.T) {
+	tm := NewToolManager(nil, nil, "")
+
+	if _, err := callWorkingMemory(t, tm.workingMemorySetHandler, map[string]interface{}{"session_id": "s1", "key": "plan", "value": "bisect"}); err != nil {
+		t.Fatalf("set value: %v", err)
+	}
+	text, err := callWorkingMemory(t, tm.workingMemorySetHandler, map[string]interface{}{"session_id": "s1", "note": "only on postgres"})
+	if err != nil {
+		t.Fatalf("add note: %v", err)
+	}
+	if !strings.Contains(text, "note_id: 1") {
+		t.Errorf("add note = %q, want note_id 1", text)
+	}
+
+	text, err = callWorkingMemory(t, tm.workingMemoryGetHandler, map[string]interface{}{"session_id": "s1"})
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	for _, want := range []string{"plan", "bisect", "only on postgres"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("get = %q, missing %q", text, want)
+		}
+	}
+
+	text, err = callWorkingMemory(t, tm.workingMemoryGetHandler, map[string]interface{}{"session_id": "s2"})
+	if err != nil || strings.Contains(text, "bisect") {
+		t.Errorf("other session get = %q, %v; want it empty", text, err)
+	}
+
+	if _, err := callWorkingMemory(t, tm.workingMemorySetHandler, map[string]interface{}{"session_id": "s1", "key": "plan", "delete": true}); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if _, err := callWorkingMemory(t, tm.workingMemoryGetHandler, map[string]interface{}{"session_id": "s1", "key": "plan"}); errorCode(err) != ErrCodeNotFound {
+		t.Errorf("get deleted key error = %v, want not found", err)
+	}
+}
+
+func TestWorkingMemorySetValidation(t *testing.T) {
+	tm := NewToolManager(nil, nil, "")
+	for _, args := range []map[string]interface{}{
+		{"key": "plan", "value": "x"},
+		{"session_id": "s1"},
+		{"session_id": "s1", "key": "plan", "note": "both"},
+		{"session_id": "s1", "note": "x", "delete": true},
+	} {
+		if _, err := callWorkingMemory(t, tm.workingMemorySetHandler, args); errorCode(err) != ErrCodeValidation {
+			t.Errorf("set %v error = %v, want a validation error", args, err)
+		}
+	}
+}
+
+func TestWorkingMemoryExpiry(t *testing.T) {
+	wm := workingMemory{ttl: time.Minute}
+	now := time.Now()
+	wm.session("idle", now.Add(-2*time.Minute), true)
+	wm.session("active", now.Add(-30*time.Second), true)
+
+	if expired := wm.expire(now); expired != 1 {
+		t.Errorf("expire() = %d, want 1", expired)
+	}
+	if wm.session("idle", now, false) != nil {
+		t.Error("idle session survived expiry")
+	}
+	if wm.session("active", now, false) == nil {
+		t.Error("active session expired")
+	}
+	if !wm.end("active", now) || wm.end("active", now) {
+		t.Error("end() should report the session once")
+	}
+
+	wm.ttl = 0
+	wm.session("kept", now.Add(-24*time.Hour), true)
+	if expired := wm.expire(now); expired != 0 {
+		t.Errorf("expire() without ttl = %d, want 0", expired)
+	}
+}
+
+func TestWorkingMemorySelectAndRemove(t *testing.T) {
+	wm := workingMemory{}
+	now := time.Now()
+	s := wm.session("s", now, true)
+	s.values["a"] = workingValue{Key: "a", Value: "1"}
+	s.values["b"] = workingValue{Key: "b", Value: "2"}
+	s.notes = []workingNote{{ID: 1, Text: "one"}, {ID: 2, Text: "two"}}
+
+	if _, _, err := wm.selectItems("s", []string{"missing"}, nil, false); errorCode(err) != ErrCodeNotFound {
+		t.Errorf("selectItems(missing) error = %v, want not found", err)
+	}
+	values, notes, err := wm.selectItems("s", []string{"b"}, []int{2}, false)
+	if err != nil || len(values) != 1 || values[0].Key != "b" || len(notes) != 1 || notes[0].ID != 2 {
+		t.Fatalf("selectItems() = %v, %v, %v", values, notes, err)
+	}
+
+	wm.removeItems("s", []string{"b"}, []int{2})
+	values, notes, _ = wm.selectItems("s", nil, nil, true)
+	if len(values) != 1 || values[0].Key != "a" || len(notes) != 1 || notes[0].ID != 1 {
+		t.Errorf("after removeItems = %v, %v; want a and note 1", values, notes)
+	}
+}
@@ -4,26 +4,53 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"sort"
 	"sync"
+	"time"
 
 	"github.com/madeindigio/remembrances-mcp/internal/indexer"
+	"github.com/madeindigio/remembrances-mcp/internal/kb"
+	"github.com/madeindigio/remembrances-mcp/internal/logging"
 	"github.com/madeindigio/remembrances-mcp/internal/storage"
 	"github.com/madeindigio/remembrances-mcp/pkg/embedder"
+	"github.com/madeindigio/remembrances-mcp/pkg/llm"
+	"github.com/madeindigio/remembrances-mcp/pkg/redact"
 )
 
 // ModuleConfig is passed to Provision().
 type ModuleConfig struct {
-	Raw               map[string]any
-	Storage           storage.FullStorage
-	Embedder          embedder.Embedder
-	CodeEmbedder      embedder.Embedder
-	KnowledgeBasePath string
-	KBChunkSize       int
-	KBChunkOverlap    int
-	DisableCodeWatch  bool
-	IndexerConfig     indexer.IndexerConfig
-	JobManagerConfig  indexer.JobManagerConfig
-	Logger            *slog.Logger
+	Raw                    map[string]any
+	Storage                storage.FullStorage
+	Embedder               embedder.Embedder
+	CodeEmbedder           embedder.Embedder
+	EmbeddingRouter        *embedder.Router
+	KnowledgeBasePath      string
+	KBChunkSize            int
+	KBChunkOverlap         int
+	KBChunkStrategy        string
+	KBRoots                []kb.Root
+	KBWatchers             func() []*kb.Watcher
+	KBRecrawlInterval      time.Duration
+	KBURLPrivateNetworks   bool
+	WorkingMemoryTTL       time.Duration
+	EmbeddingModel         string
+	DuplicateThreshold     float64
+	LLM                    llm.Client
+	ConsolidationThreshold float64
+	PurgeArchiveDir        string
+	MaxOutputBytes         int
+	MinSimilarity          float64
+	ScoreNormalization     string
+	Redactor               *redact.Redactor
+	ProgressNotifier       ProgressNotifier
+	DisableCodeWatch       bool
+	CodeCheckCommands      map[string]string
+	IndexerConfig          indexer.IndexerConfig
+	JobManagerConfig       indexer.JobManagerConfig
+	Logger                 *slog.Logger
+	LogLevels              *logging.Levels
+	StorageBackend         string
+	LoadedModules          func() []ModuleID // Set by the manager
 }
 
 // ModuleManager manages module lifecycle.
@@ -53,6 +80,7 @@ func (mm *ModuleManager) LoadModule(ctx context.Context, id ModuleID, cfg map[st
 	if prov, ok := instance.(Provisioner); ok {
 		modCfg := mm.config
 		modCfg.Raw = cfg
+		modCfg.LoadedModules = mm.LoadedModules
 		if err := prov.Provision(ctx, modCfg); err != nil {
 			return nil, fmt.Errorf("provision failed for %s: %w", id, err)
 		}
@@ -131,6 +159,46 @@ func (mm *ModuleManager) Cleanup() {
 	}
 }
 
+// Shutdown unloads all modules when the server stops: modules implementing
+// Shutdowner finish their work in progress within ctx, the others are
+// cleaned up.
+func (mm *ModuleManager) Shutdown(ctx context.Context) {
+	mm.mu.Lock()
+	instances := mm.instances
+	mm.instances = make(map[ModuleID]Module)
+	mm.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for id, instance := range instances {
+		switch m := instance.(type) {
+		case Shutdowner:
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if err := m.Shutdown(ctx); err != nil {
+					mm.config.Logger.Warn("module did not shut down cleanly", "module_id", id, "error", err)
+				}
+			}()
+		case CleanerUpper:
+			_ = m.Cleanup()
+		}
+	}
+	wg.Wait()
+}
+
+// LoadedModules returns the IDs of the loaded modules, sorted.
+func (mm *ModuleManager) LoadedModules() []ModuleID {
+	mm.mu.RLock()
+	defer mm.mu.RUnlock()
+
+	ids := make([]ModuleID, 0, len(mm.instances))
+	for id := range mm.instances {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
 // GetToolProviders returns all loaded ToolProvider modules.
 func (mm *ModuleManager) GetToolProviders() []ToolProvider {
 	mm.mu.RLock()
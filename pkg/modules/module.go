@@ -17,7 +17,11 @@ type ModuleInfo struct {
 	Version     string
 	Author      string
 	License     string
-	New         func() Module
+	// AutoLoad loads the module without listing it under modules in the
+	// config file, so building it into a binary (xremembrances build --with)
+	// is enough. It can still be disabled with disable.
+	AutoLoad bool
+	New      func() Module
 }
 
 // Module is the base interface every module must implement.
@@ -40,6 +44,13 @@ type CleanerUpper interface {
 	Cleanup() error
 }
 
+// Shutdowner finishes the work a module has in progress before the server
+// exits, within ctx. Modules implementing it are shut down instead of cleaned
+// up when the server stops.
+type Shutdowner interface {
+	Shutdown(ctx context.Context) error
+}
+
 // StorageWrapperProvider allows modules to wrap the primary storage.
 // This enables modules to intercept and enhance storage operations.
 type StorageWrapperProvider interface {
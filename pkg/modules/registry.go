@@ -2,8 +2,12 @@ package modules
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 	"sync"
+
+	"github.com/madeindigio/remembrances-mcp/pkg/embedder"
+	"github.com/madeindigio/remembrances-mcp/pkg/treesitter"
 )
 
 var (
@@ -11,7 +15,11 @@ var (
 	modules   = make(map[ModuleID]ModuleInfo)
 )
 
-// RegisterModule registers a module in the global registry.
+// RegisterModule registers a module in the global registry. Modules call it
+// from init, so importing a module package is enough to build it in. The hook
+// points that must exist before modules are loaded are registered right away:
+// embedder backends (EmbedderProvider) and code extractors
+// (ExtractorProvider), using instance.
 func RegisterModule(instance Module) {
 	info := instance.ModuleInfo()
 	if info.ID == "" {
@@ -29,6 +37,13 @@ func RegisterModule(instance Module) {
 	}
 
 	modules[info.ID] = info
+
+	if ep, ok := instance.(EmbedderProvider); ok {
+		embedder.RegisterBackend(ep.EmbedderType(), ep.NewEmbedder)
+	}
+	if xp, ok := instance.(ExtractorProvider); ok {
+		treesitter.RegisterExtractors(xp.Extractors)
+	}
 }
 
 // GetModule returns a module by ID.
@@ -55,7 +70,7 @@ func GetModulesByNamespace(namespace string) []ModuleInfo {
 	return result
 }
 
-// ListModules lists all registered modules.
+// ListModules lists all registered modules, sorted by ID.
 func ListModules() []ModuleInfo {
 	modulesMu.RLock()
 	defer modulesMu.RUnlock()
@@ -64,5 +79,29 @@ func ListModules() []ModuleInfo {
 	for _, info := range modules {
 		result = append(result, info)
 	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
 	return result
 }
+
+// Hooks returns the hook points a module implements, e.g. "tools" or
+// "embedder-backend", by inspecting a new instance.
+func Hooks(info ModuleInfo) []string {
+	instance := info.New()
+	var hooks []string
+	add := func(ok bool, name string) {
+		if ok {
+			hooks = append(hooks, name)
+		}
+	}
+	_, ok := instance.(ToolProvider)
+	add(ok, "tools")
+	_, ok = instance.(EmbedderProvider)
+	add(ok, "embedder-backend")
+	_, ok = instance.(ExtractorProvider)
+	add(ok, "extractors")
+	_, ok = instance.(StorageWrapperProvider)
+	add(ok, "storage-wrapper")
+	_, ok = instance.(HTTPEndpointProvider)
+	add(ok, "http-endpoints")
+	return hooks
+}
@@ -8,11 +8,16 @@ import (
 	mcpserver "github.com/ThinkInAIXYZ/go-mcp/server"
 	"github.com/madeindigio/remembrances-mcp/internal/storage"
 	"github.com/madeindigio/remembrances-mcp/pkg/embedder"
+	"github.com/madeindigio/remembrances-mcp/pkg/treesitter"
 )
 
 // ToolHandler is the signature for a tool handler.
 type ToolHandler = mcpserver.ToolHandlerFunc
 
+// ProgressNotifier sends a progress notification for the tool call in ctx to
+// the MCP client, failing when the client did not ask for progress.
+type ProgressNotifier func(ctx context.Context, notify *protocol.ProgressNotification) error
+
 // ToolDefinition bundles a tool definition with its handler.
 type ToolDefinition struct {
 	Tool    *protocol.Tool
@@ -32,13 +37,23 @@ type StorageProvider interface {
 	NewStorage(cfg map[string]any) (storage.FullStorage, error)
 }
 
-// EmbedderProvider adds embedding providers.
+// EmbedderProvider adds an embedding backend. RegisterModule registers it
+// with the embedder package, and named embedders select it with backend:
+// EmbedderType(); NewEmbedder receives their options.
 type EmbedderProvider interface {
 	Module
 	EmbedderType() string
 	NewEmbedder(cfg map[string]any) (embedder.Embedder, error)
 }
 
+// ExtractorProvider adds code symbol extractors, replacing the built-in
+// extractor of their language. RegisterModule registers them with the
+// treesitter package, so every code indexer and code tool uses them.
+type ExtractorProvider interface {
+	Module
+	Extractors(cfg treesitter.WalkerConfig) []treesitter.SymbolExtractor
+}
+
 // ToolMiddleware intercepts tool calls.
 type ToolMiddleware interface {
 	Module
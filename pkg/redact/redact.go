@@ -0,0 +1,174 @@
+// Package redact scrubs or rejects secrets and personal data, such as API keys,
+// private keys, credit card numbers and emails, before content is persisted.
+package redact
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Redaction modes
+const (
+	ModeOff    = "off"    // Content is stored unchanged
+	ModeRedact = "redact" // Matches are replaced with a [REDACTED:<rule>] marker
+	ModeReject = "reject" // Content with any match is refused
+)
+
+// Built-in rule names
+const (
+	RuleAPIKey     = "api_key"
+	RulePrivateKey = "private_key"
+	RuleCreditCard = "credit_card"
+	RuleEmail      = "email"
+)
+
+// DefaultRules lists the built-in rules enabled when none are configured.
+var DefaultRules = []string{RuleAPIKey, RulePrivateKey, RuleCreditCard, RuleEmail}
+
+// builtinPatterns holds the expressions behind each built-in rule.
+var builtinPatterns = map[string]string{
+	RuleAPIKey: `(?i)\b(?:sk-(?:proj-|ant-)?[A-Za-z0-9_-]{20,}|AKIA[0-9A-Z]{16}|gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,}|xox[abprs]-[A-Za-z0-9-]{10,}|AIza[0-9A-Za-z_-]{35})\b` +
+		`|(?i)\b(?:api[_-]?key|secret|token|password|passwd)\b\s*[:=]\s*["']?[^\s"']{8,}` +
+		`|(?i)\bBearer\s+[A-Za-z0-9._~+/-]{20,}=*`,
+	RulePrivateKey: `(?s)-----BEGIN [A-Z ]*PRIVATE KEY-----.*?-----END [A-Z ]*PRIVATE KEY-----`,
+	RuleCreditCard: `\b(?:\d[ -]?){12,18}\d\b`,
+	RuleEmail:      `\b[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}\b`,
+}
+
+// Rule is a named expression whose matches are treated as sensitive.
+type Rule struct {
+	Name    string
+	Pattern *regexp.Regexp
+}
+
+// Finding counts the matches of one rule in a piece of content.
+type Finding struct {
+	Rule  string `json:"rule"`
+	Count int    `json:"count"`
+}
+
+// RejectedError is returned by Apply in reject mode when content matches a rule.
+type RejectedError struct {
+	Findings []Finding
+}
+
+func (e *RejectedError) Error() string {
+	names := make([]string, len(e.Findings))
+	for i, f := range e.Findings {
+		names[i] = f.Rule
+	}
+	return fmt.Sprintf("content rejected: it looks like it contains sensitive data (%s); remove it and try again", strings.Join(names, ", "))
+}
+
+// Redactor applies a set of rules in a given mode. A nil Redactor stores
+// content unchanged.
+type Redactor struct {
+	mode  string
+	rules []Rule
+}
+
+// New builds a redactor. rules selects built-in rules by name (DefaultRules when
+// empty) and custom adds extra rules as name -> regular expression.
+func New(mode string, rules []string, custom map[string]string) (*Redactor, error) {
+	mode = strings.ToLower(strings.TrimSpace(mode))
+	switch mode {
+	case "", ModeOff:
+		return &Redactor{mode: ModeOff}, nil
+	case ModeRedact, ModeReject:
+	default:
+		return nil, fmt.Errorf("invalid redaction mode %q: expected off, redact or reject", mode)
+	}
+
+	if len(rules) == 0 {
+		rules = DefaultRules
+	}
+
+	r := &Redactor{mode: mode}
+	for _, name := range rules {
+		pattern, ok := builtinPatterns[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown redaction rule %q", name)
+		}
+		r.rules = append(r.rules, Rule{Name: name, Pattern: regexp.MustCompile(pattern)})
+	}
+
+	names := make([]string, 0, len(custom))
+	for name := range custom {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		re, err := regexp.Compile(custom[name])
+		if err != nil {
+			return nil, fmt.Errorf("invalid redaction pattern %q: %w", name, err)
+		}
+		r.rules = append(r.rules, Rule{Name: name, Pattern: re})
+	}
+
+	return r, nil
+}
+
+// Enabled reports whether the redactor inspects content at all.
+func (r *Redactor) Enabled() bool {
+	return r != nil && r.mode != ModeOff && len(r.rules) > 0
+}
+
+// Mode returns the configured mode.
+func (r *Redactor) Mode() string {
+	if r == nil {
+		return ModeOff
+	}
+	return r.mode
+}
+
+// Apply scans text with every rule. In redact mode it returns the text with the
+// matches replaced; in reject mode it returns a *RejectedError when anything
+// matched. The findings report how many matches each rule had.
+func (r *Redactor) Apply(text string) (string, []Finding, error) {
+	if !r.Enabled() || text == "" {
+		return text, nil, nil
+	}
+
+	var findings []Finding
+	for _, rule := range r.rules {
+		count := 0
+		text = rule.Pattern.ReplaceAllStringFunc(text, func(match string) string {
+			if rule.Name == RuleCreditCard && !luhnValid(match) {
+				return match
+			}
+			count++
+			return "[REDACTED:" + rule.Name + "]"
+		})
+		if count > 0 {
+			findings = append(findings, Finding{Rule: rule.Name, Count: count})
+		}
+	}
+
+	if len(findings) > 0 && r.mode == ModeReject {
+		return "", findings, &RejectedError{Findings: findings}
+	}
+	return text, findings, nil
+}
+
+// luhnValid reports whether the digits in s pass the Luhn checksum used by card numbers.
+func luhnValid(s string) bool {
+	sum, n := 0, 0
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if n%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		n++
+	}
+	return n >= 13 && sum%10 == 0
+}
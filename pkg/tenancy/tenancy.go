@@ -0,0 +1,156 @@
+// Package tenancy maps client API keys to tenants, each stored in its own
+// SurrealDB namespace and database, so several teams can share one server
+// without seeing each other's data.
+package tenancy
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Headers carrying the API key of a client
+const (
+	HeaderAuthorization = "Authorization"
+	HeaderAPIKey        = "X-API-Key"
+)
+
+// Quotas limit the records a tenant may store, by layer. Zero is unlimited.
+type Quotas struct {
+	MaxFacts          int `json:"max_facts,omitempty"`
+	MaxVectors        int `json:"max_vectors,omitempty"`
+	MaxDocumentChunks int `json:"max_document_chunks,omitempty"`
+	MaxEvents         int `json:"max_events,omitempty"`
+}
+
+// Tenant is a client identified by an API key, whose data lives in its own
+// namespace and database.
+type Tenant struct {
+	ID        string `json:"id"`
+	Key       string `json:"-"`
+	Namespace string `json:"namespace"`
+	Database  string `json:"database"`
+	Quotas    Quotas `json:"quotas"`
+}
+
+// Registry looks up tenants by API key.
+type Registry struct {
+	tenants []*Tenant
+}
+
+// NewRegistry validates the tenants and returns a registry for them. IDs,
+// keys and namespace/database pairs must be unique.
+func NewRegistry(tenants []Tenant) (*Registry, error) {
+	r := &Registry{}
+	ids := map[string]bool{}
+	keys := map[string]bool{}
+	databases := map[string]string{}
+	for i := range tenants {
+		t := tenants[i]
+		switch {
+		case t.ID == "":
+			return nil, fmt.Errorf("tenant %d: id is required", i)
+		case t.Key == "":
+			return nil, fmt.Errorf("tenant %q: key is required", t.ID)
+		case t.Namespace == "" || t.Database == "":
+			return nil, fmt.Errorf("tenant %q: namespace and database are required", t.ID)
+		case ids[t.ID]:
+			return nil, fmt.Errorf("duplicate tenant id %q", t.ID)
+		case keys[t.Key]:
+			return nil, fmt.Errorf("tenant %q: key is already used by another tenant", t.ID)
+		}
+		pair := t.Namespace + "/" + t.Database
+		if other, ok := databases[pair]; ok {
+			return nil, fmt.Errorf("tenants %q and %q share namespace/database %s", other, t.ID, pair)
+		}
+		ids[t.ID] = true
+		keys[t.Key] = true
+		databases[pair] = t.ID
+		r.tenants = append(r.tenants, &t)
+	}
+	return r, nil
+}
+
+// Enabled reports whether any tenant is configured. A nil registry is disabled.
+func (r *Registry) Enabled() bool {
+	return r != nil && len(r.tenants) > 0
+}
+
+// Tenants returns the configured tenants.
+func (r *Registry) Tenants() []*Tenant {
+	if r == nil {
+		return nil
+	}
+	return r.tenants
+}
+
+// Lookup returns the tenant owning key. Keys are compared in constant time.
+func (r *Registry) Lookup(key string) (*Tenant, bool) {
+	if r == nil || key == "" {
+		return nil, false
+	}
+	sum := sha256.Sum256([]byte(key))
+	var found *Tenant
+	for _, t := range r.tenants {
+		candidate := sha256.Sum256([]byte(t.Key))
+		if subtle.ConstantTimeCompare(sum[:], candidate[:]) == 1 {
+			found = t
+		}
+	}
+	return found, found != nil
+}
+
+// KeyFromHeader returns the API key of a request, from an
+// "Authorization: Bearer <key>" or "X-API-Key: <key>" header.
+func KeyFromHeader(h http.Header) string {
+	if auth := h.Get(HeaderAuthorization); auth != "" {
+		if scheme, key, ok := strings.Cut(auth, " "); ok && strings.EqualFold(scheme, "Bearer") {
+			return strings.TrimSpace(key)
+		}
+	}
+	return strings.TrimSpace(h.Get(HeaderAPIKey))
+}
+
+// Middleware rejects requests without a known API key with 401 and serves the
+// others with their tenant in the request context. CORS preflight requests
+// and paths in public, such as health checks, are served without a key.
+func (r *Registry) Middleware(next http.Handler, public ...string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodOptions {
+			next.ServeHTTP(w, req)
+			return
+		}
+		for _, path := range public {
+			if req.URL.Path == path {
+				next.ServeHTTP(w, req)
+				return
+			}
+		}
+		tenant, ok := r.Lookup(KeyFromHeader(req.Header))
+		if !ok {
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("WWW-Authenticate", `Bearer realm="remembrances"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": "missing or unknown API key"})
+			return
+		}
+		next.ServeHTTP(w, req.WithContext(WithTenant(req.Context(), tenant)))
+	})
+}
+
+type tenantKey struct{}
+
+// WithTenant returns a context whose storage calls use the tenant's database.
+func WithTenant(ctx context.Context, t *Tenant) context.Context {
+	return context.WithValue(ctx, tenantKey{}, t)
+}
+
+// FromContext returns the tenant of a request, if any.
+func FromContext(ctx context.Context) (*Tenant, bool) {
+	t, ok := ctx.Value(tenantKey{}).(*Tenant)
+	return t, ok && t != nil
+}
@@ -0,0 +1,122 @@
+package tenancy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func testRegistry(t *testing.T) *Registry {
+	t.Helper()
+	r, err := NewRegistry([]Tenant{
+		{ID: "team-a", Key: "key-a", Namespace: "ns", Database: "a"},
+		{ID: "team-b", Key: "key-b", Namespace: "ns", Database: "b"},
+	})
+	if err != nil {
+		t.Fatalf("NewRegistry: %v", err)
+	}
+	return r
+}
+
+func TestNewRegistryRejectsInvalidTenants(t *testing.T) {
+	tests := []struct {
+		name    string
+		tenants []Tenant
+	}{
+		{"missing id", []Tenant{{Key: "k", Namespace: "ns", Database: "db"}}},
+		{"missing key", []Tenant{{ID: "a", Namespace: "ns", Database: "db"}}},
+		{"missing database", []Tenant{{ID: "a", Key: "k", Namespace: "ns"}}},
+		{"duplicate id", []Tenant{
+			{ID: "a", Key: "k1", Namespace: "ns", Database: "db1"},
+			{ID: "a", Key: "k2", Namespace: "ns", Database: "db2"},
+		}},
+		{"shared key", []Tenant{
+			{ID: "a", Key: "k", Namespace: "ns", Database: "db1"},
+			{ID: "b", Key: "k", Namespace: "ns", Database: "db2"},
+		}},
+		{"shared database", []Tenant{
+			{ID: "a", Key: "k1", Namespace: "ns", Database: "db"},
+			{ID: "b", Key: "k2", Namespace: "ns", Database: "db"},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewRegistry(tt.tenants); err == nil {
+				t.Error("NewRegistry succeeded, want an error")
+			}
+		})
+	}
+}
+
+func TestLookup(t *testing.T) {
+	r := testRegistry(t)
+	if tenant, ok := r.Lookup("key-b"); !ok || tenant.ID != "team-b" {
+		t.Errorf("Lookup(key-b) = %v, %v, want team-b", tenant, ok)
+	}
+	for _, key := range []string{"", "key-c", "key-a "} {
+		if _, ok := r.Lookup(key); ok {
+			t.Errorf("Lookup(%q) found a tenant", key)
+		}
+	}
+
+	var disabled *Registry
+	if disabled.Enabled() {
+		t.Error("nil registry is enabled")
+	}
+}
+
+func TestKeyFromHeader(t *testing.T) {
+	tests := []struct {
+		header http.Header
+		want   string
+	}{
+		{http.Header{"Authorization": {"Bearer key-a"}}, "key-a"},
+		{http.Header{"Authorization": {"bearer  key-a"}}, "key-a"},
+		{http.Header{"X-Api-Key": {"key-b"}}, "key-b"},
+		{http.Header{"Authorization": {"Basic abc"}, "X-Api-Key": {"key-b"}}, "key-b"},
+		{http.Header{}, ""},
+	}
+	for _, tt := range tests {
+		if got := KeyFromHeader(tt.header); got != tt.want {
+			t.Errorf("KeyFromHeader(%v) = %q, want %q", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	r := testRegistry(t)
+	var served string
+	handler := r.Middleware(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		served = ""
+		if tenant, ok := FromContext(req.Context()); ok {
+			served = tenant.ID
+		}
+	}), "/health")
+
+	tests := []struct {
+		path   string
+		key    string
+		status int
+		tenant string
+	}{
+		{"/mcp", "key-a", http.StatusOK, "team-a"},
+		{"/mcp", "key-c", http.StatusUnauthorized, ""},
+		{"/mcp", "", http.StatusUnauthorized, ""},
+		{"/health", "", http.StatusOK, ""},
+	}
+	for _, tt := range tests {
+		served = "unserved"
+		req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+		if tt.key != "" {
+			req.Header.Set(HeaderAPIKey, tt.key)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != tt.status {
+			t.Errorf("%s with key %q: status %d, want %d", tt.path, tt.key, rec.Code, tt.status)
+		}
+		if tt.status == http.StatusOK && served != tt.tenant {
+			t.Errorf("%s with key %q: served tenant %q, want %q", tt.path, tt.key, served, tt.tenant)
+		}
+	}
+}
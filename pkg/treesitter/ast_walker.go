@@ -2,9 +2,16 @@
 package treesitter
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
-	"github.com/google/uuid"
 	sitter "github.com/madeindigio/go-tree-sitter"
 )
 
@@ -60,6 +67,7 @@ func NewASTWalker(config WalkerConfig) *ASTWalker {
 	// Register default extractors
 	walker.RegisterExtractor(NewGoExtractor(config))
 	walker.RegisterExtractor(NewTypeScriptExtractor(config))
+	walker.RegisterExtractor(NewTSXExtractor(config))
 	walker.RegisterExtractor(NewJavaScriptExtractor(config))
 	walker.RegisterExtractor(NewPHPExtractor(config))
 	walker.RegisterExtractor(NewRustExtractor(config))
@@ -73,10 +81,40 @@ func NewASTWalker(config WalkerConfig) *ASTWalker {
 	walker.RegisterExtractor(NewTOMLExtractor(config))
 	walker.RegisterExtractor(NewMarkdownExtractor(config))
 	walker.RegisterExtractor(NewVueExtractor(config))
+	walker.RegisterExtractor(NewSQLExtractor(config))
+	walker.RegisterExtractor(NewProtobufExtractor(config))
+	walker.RegisterExtractor(NewYAMLExtractor(config))
+	walker.RegisterExtractor(NewJSONExtractor(config))
+
+	// Extractors registered by modules replace the built-in ones
+	extractorFactoriesMu.RLock()
+	for _, factory := range extractorFactories {
+		for _, extractor := range factory(config) {
+			walker.RegisterExtractor(extractor)
+		}
+	}
+	extractorFactoriesMu.RUnlock()
+
+	// Query files loaded at startup extend or replace any of these
+	walker.registerQueryExtractors()
 
 	return walker
 }
 
+var (
+	extractorFactoriesMu sync.RWMutex
+	extractorFactories   []func(config WalkerConfig) []SymbolExtractor
+)
+
+// RegisterExtractors adds extractors to every walker created afterwards,
+// replacing the built-in extractor of their language. Modules register them
+// at init time, before any walker is created.
+func RegisterExtractors(factory func(config WalkerConfig) []SymbolExtractor) {
+	extractorFactoriesMu.Lock()
+	defer extractorFactoriesMu.Unlock()
+	extractorFactories = append(extractorFactories, factory)
+}
+
 // RegisterExtractor adds a new extractor for a language
 func (w *ASTWalker) RegisterExtractor(extractor SymbolExtractor) {
 	w.extractors[extractor.Language()] = extractor
@@ -96,7 +134,89 @@ func (w *ASTWalker) ExtractSymbols(tree *sitter.Tree, sourceCode []byte, lang La
 		extractor = NewGenericExtractor(w.config)
 	}
 
-	return extractor.ExtractSymbols(tree, sourceCode, filePath, projectID)
+	symbols, err := extractor.ExtractSymbols(tree, sourceCode, filePath, projectID)
+	if err != nil {
+		return nil, err
+	}
+	discriminateNamePaths(symbols)
+	return symbols, nil
+}
+
+// SymbolID returns the ID of a symbol, derived from its project, file and
+// name path so that it is the same every time the symbol is extracted: the
+// first 128 bits of the SHA-256 of "<project>:<file>:<name path>", in hex.
+func SymbolID(projectID, filePath, namePath string) string {
+	sum := sha256.Sum256([]byte(projectID + ":" + filePath + ":" + namePath))
+	return hex.EncodeToString(sum[:16])
+}
+
+// NamePathOrdinalSeparator separates a name path from the ordinal telling
+// apart the symbols of a file that share it, such as overloads or methods of
+// several impl blocks: "/Point/add", "/Point/add#2".
+const NamePathOrdinalSeparator = "#"
+
+// SplitNamePathOrdinal splits the ordinal off the last segment of a name
+// path: "/Point/add#2" gives "/Point/add" and 2, and a name path without one
+// gives itself and 0.
+func SplitNamePathOrdinal(namePath string) (string, int) {
+	i := strings.LastIndex(namePath, NamePathOrdinalSeparator)
+	if i < 0 || strings.Contains(namePath[i:], "/") {
+		return namePath, 0
+	}
+	n, err := strconv.Atoi(namePath[i+len(NamePathOrdinalSeparator):])
+	if err != nil || n < 2 {
+		return namePath, 0
+	}
+	return namePath[:i], n
+}
+
+// StripNamePathOrdinals removes the ordinals of every segment of a name
+// path: "/Point/add#2/inner" gives "/Point/add/inner".
+func StripNamePathOrdinals(namePath string) string {
+	if !strings.Contains(namePath, NamePathOrdinalSeparator) {
+		return namePath
+	}
+	segments := strings.Split(namePath, "/")
+	for i, segment := range segments {
+		segments[i], _ = SplitNamePathOrdinal(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// discriminateNamePaths gives the symbols of a file sharing a name path
+// distinct ones: in source order, the first keeps it and the next get the
+// ordinals 2, 3... The symbols they enclose are moved under their new name
+// path, and every renamed symbol gets the ID of its new name path. Children
+// refer to their parent's ID field, so they follow.
+func discriminateNamePaths(symbols []*CodeSymbol) {
+	// Parents come before their children, whose name paths are longer
+	ordered := slices.Clone(symbols)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		di, dj := strings.Count(ordered[i].NamePath, "/"), strings.Count(ordered[j].NamePath, "/")
+		if di != dj {
+			return di < dj
+		}
+		return ordered[i].StartByte < ordered[j].StartByte
+	})
+
+	seen := make(map[string]int, len(ordered))
+	for _, sym := range ordered {
+		seen[sym.NamePath]++
+		n := seen[sym.NamePath]
+		if n == 1 {
+			continue
+		}
+		oldPath := sym.NamePath
+		sym.NamePath = fmt.Sprintf("%s%s%d", oldPath, NamePathOrdinalSeparator, n)
+		sym.ID = SymbolID(sym.ProjectID, sym.FilePath, sym.NamePath)
+		for _, inner := range ordered {
+			if strings.HasPrefix(inner.NamePath, oldPath+"/") &&
+				inner.StartByte >= sym.StartByte && inner.EndByte <= sym.EndByte {
+				inner.NamePath = sym.NamePath + strings.TrimPrefix(inner.NamePath, oldPath)
+				inner.ID = SymbolID(inner.ProjectID, inner.FilePath, inner.NamePath)
+			}
+		}
+	}
 }
 
 // BaseExtractor provides common functionality for all extractors
@@ -132,7 +252,7 @@ func (b *BaseExtractor) CreateSymbol(
 	startLine, endLine, startByte, endByte := GetNodeLocation(node)
 
 	symbol := &CodeSymbol{
-		ID:         uuid.New().String(),
+		ID:         SymbolID(projectID, filePath, namePath),
 		ProjectID:  projectID,
 		FilePath:   filePath,
 		Language:   b.language,
@@ -148,12 +268,12 @@ func (b *BaseExtractor) CreateSymbol(
 		UpdatedAt:  time.Now(),
 	}
 
+	code := GetNodeContent(node, sourceCode)
+	symbol.Revision = SymbolRevision([]byte(code))
+
 	// Include source code if configured
-	if b.config.IncludeSourceCode {
-		code := GetNodeContent(node, sourceCode)
-		if len(code) <= b.config.MaxSymbolSize {
-			symbol.SourceCode = code
-		}
+	if b.config.IncludeSourceCode && len(code) <= b.config.MaxSymbolSize {
+		symbol.SourceCode = code
 	}
 
 	return symbol
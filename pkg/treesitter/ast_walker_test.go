@@ -0,0 +1,78 @@
+package treesitter
+
+import "testing"
+
+func TestSymbolID(t *testing.T) {
+	id := SymbolID("app", "user.go", "/UserService/Create")
+	if len(id) != 32 {
+		t.Fatalf("SymbolID = %q, want 32 hex digits", id)
+	}
+	if again := SymbolID("app", "user.go", "/UserService/Create"); again != id {
+		t.Errorf("SymbolID is not stable: %q then %q", id, again)
+	}
+	if other := SymbolID("other", "user.go", "/UserService/Create"); other == id {
+		t.Errorf("symbols of different projects share ID %q", id)
+	}
+	if other := SymbolID("app", "admin.go", "/UserService/Create"); other == id {
+		t.Errorf("symbols of different files share ID %q", id)
+	}
+}
+
+func TestDiscriminateNamePaths(t *testing.T) {
+	newSymbol := func(namePath string, start, end int) *CodeSymbol {
+		return &CodeSymbol{ID: SymbolID("app", "point.go", namePath), ProjectID: "app", FilePath: "point.go", NamePath: namePath, StartByte: start, EndByte: end}
+	}
+	class := newSymbol("/Point", 0, 100)
+	first := newSymbol("/Point/add", 10, 20)
+	overload := newSymbol("/Point/add", 30, 50)
+	inner := newSymbol("/Point/add/inner", 35, 45)
+	third := newSymbol("/Point/add", 60, 70)
+	first.ParentID, overload.ParentID, third.ParentID = &class.ID, &class.ID, &class.ID
+	inner.ParentID = &overload.ID
+
+	symbols := []*CodeSymbol{class, inner, third, overload, first}
+	discriminateNamePaths(symbols)
+
+	want := map[*CodeSymbol]string{
+		class:    "/Point",
+		first:    "/Point/add",
+		overload: "/Point/add#2",
+		inner:    "/Point/add#2/inner",
+		third:    "/Point/add#3",
+	}
+	for sym, namePath := range want {
+		if sym.NamePath != namePath {
+			t.Errorf("symbol at byte %d has name path %q, want %q", sym.StartByte, sym.NamePath, namePath)
+		}
+		if sym.ID != SymbolID("app", "point.go", namePath) {
+			t.Errorf("%s has ID %q, want the one of its name path", namePath, sym.ID)
+		}
+	}
+	if *inner.ParentID != overload.ID {
+		t.Errorf("inner parent = %q, want the overload ID %q", *inner.ParentID, overload.ID)
+	}
+}
+
+func TestNamePathOrdinals(t *testing.T) {
+	tests := []struct {
+		namePath string
+		base     string
+		ordinal  int
+		stripped string
+	}{
+		{"/Point/add", "/Point/add", 0, "/Point/add"},
+		{"/Point/add#2", "/Point/add", 2, "/Point/add"},
+		{"/Point/add#2/inner", "/Point/add#2/inner", 0, "/Point/add/inner"},
+		{"/Point#3/add#2", "/Point#3/add", 2, "/Point/add"},
+		{"/Point/add#x", "/Point/add#x", 0, "/Point/add#x"},
+	}
+	for _, tt := range tests {
+		base, ordinal := SplitNamePathOrdinal(tt.namePath)
+		if base != tt.base || ordinal != tt.ordinal {
+			t.Errorf("SplitNamePathOrdinal(%q) = %q, %d, want %q, %d", tt.namePath, base, ordinal, tt.base, tt.ordinal)
+		}
+		if stripped := StripNamePathOrdinals(tt.namePath); stripped != tt.stripped {
+			t.Errorf("StripNamePathOrdinals(%q) = %q, want %q", tt.namePath, stripped, tt.stripped)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+// Package treesitter provides call graph extraction.
+package treesitter
+
+import (
+	"regexp"
+	"strings"
+
+	sitter "github.com/madeindigio/go-tree-sitter"
+)
+
+// callNodeTypes are the call expressions of the supported grammars, mapped to
+// the fields that may hold the callee, in order of preference
+var callNodeTypes = map[string][]string{
+	"call_expression":                 {"function"},           // Go, JavaScript, TypeScript, Rust, C, C++, Kotlin, Swift
+	"call":                            {"function", "method"}, // Python, Ruby
+	"method_invocation":               {"name"},               // Java
+	"invocation_expression":           {"function"},           // C#
+	"function_call_expression":        {"function"},           // PHP
+	"member_call_expression":          {"name"},               // PHP
+	"nullsafe_member_call_expression": {"name"},               // PHP
+	"scoped_call_expression":          {"name"},               // PHP
+	"function_call":                   {"name"},               // Lua
+	"new_expression":                  {"constructor"},        // JavaScript, TypeScript
+	"object_creation_expression":      {"type"},               // Java, C#, PHP
+}
+
+// calleeNamePattern matches the identifiers of a callee expression; the last one is the callee name
+var calleeNamePattern = regexp.MustCompile(`[A-Za-z_$][A-Za-z0-9_$]*`)
+
+// typeArgumentsPattern matches innermost type argument lists such as <T> or [K, V]
+var typeArgumentsPattern = regexp.MustCompile(`<[^<>]*>|\[[^\[\]]*\]`)
+
+// maxCalleeExprLength bounds the stored callee expression
+const maxCalleeExprLength = 200
+
+// CodeCall is a call from a symbol to a function, method or constructor
+type CodeCall struct {
+	// CallerNamePath is the name path of the innermost symbol containing the
+	// call, or empty for calls at file level
+	CallerNamePath string `json:"caller_name_path"`
+
+	// CallerName is the name of the calling symbol
+	CallerName string `json:"caller_name"`
+
+	// CalleeName is the called function or method name, without receiver or qualifier
+	CalleeName string `json:"callee_name"`
+
+	// CalleeExpr is the callee as written, e.g. "s.storage.SaveCodeFile"
+	CalleeExpr string `json:"callee_expr"`
+
+	// Line of the call (1-based)
+	Line int `json:"line"`
+}
+
+// ExtractCalls returns the calls made in a parsed file, attributed to the
+// innermost function-like symbol that contains them. Resolution is syntactic:
+// callees are identified by name only.
+func ExtractCalls(tree *sitter.Tree, sourceCode []byte, symbols []*CodeSymbol) []CodeCall {
+	var calls []CodeCall
+
+	it := NewNodeIterator(tree.RootNode())
+	for node := it.Next(); node != nil; node = it.Next() {
+		fields, ok := callNodeTypes[node.Type()]
+		if !ok {
+			continue
+		}
+
+		expr := strings.Join(strings.Fields(calleeExpr(node, fields, sourceCode)), " ")
+		if expr == "" || strings.Contains(expr, "{") {
+			// Immediately invoked function literals have no name
+			continue
+		}
+		name := calleeName(expr)
+		if name == "" {
+			continue
+		}
+		if len(expr) > maxCalleeExprLength {
+			expr = expr[:maxCalleeExprLength]
+		}
+
+		call := CodeCall{
+			CalleeName: name,
+			CalleeExpr: expr,
+			Line:       int(node.StartPoint().Row) + 1,
+		}
+		if caller := enclosingSymbol(symbols, int(node.StartByte()), int(node.EndByte())); caller != nil {
+			call.CallerNamePath = caller.NamePath
+			call.CallerName = caller.Name
+		}
+		calls = append(calls, call)
+	}
+
+	return calls
+}
+
+// calleeExpr returns the source of the expression naming the called function
+func calleeExpr(node *sitter.Node, fields []string, sourceCode []byte) string {
+	for _, field := range fields {
+		if child := node.ChildByFieldName(field); child != nil {
+			return GetNodeContent(child, sourceCode)
+		}
+	}
+
+	// Lua calls split the callee over several prefix nodes: take everything
+	// before the argument list
+	if node.Type() == "function_call" {
+		for i := 0; i < int(node.ChildCount()); i++ {
+			child := node.Child(i)
+			switch child.Type() {
+			case "function_call_paren", "function_arguments", "arguments":
+				return string(sourceCode[node.StartByte():child.StartByte()])
+			}
+		}
+		return ""
+	}
+
+	// Kotlin and Swift calls have no field names: the callee comes first
+	if node.NamedChildCount() > 1 {
+		return GetNodeContent(node.NamedChild(0), sourceCode)
+	}
+	return ""
+}
+
+// calleeName returns the last identifier of a callee expression, ignoring
+// type arguments, so "Vec::<T>::new" and "pkg.New[T]" yield "new" and "New"
+func calleeName(expr string) string {
+	for {
+		stripped := typeArgumentsPattern.ReplaceAllString(expr, "")
+		if stripped == expr {
+			break
+		}
+		expr = stripped
+	}
+
+	names := calleeNamePattern.FindAllString(expr, -1)
+	if len(names) == 0 {
+		return ""
+	}
+	return names[len(names)-1]
+}
+
+// enclosingSymbol returns the innermost callable symbol containing the byte
+// range, or else the innermost symbol of any kind
+func enclosingSymbol(symbols []*CodeSymbol, start, end int) *CodeSymbol {
+	var callable, innermost *CodeSymbol
+
+	for _, symbol := range symbols {
+		if symbol.StartByte > start || symbol.EndByte < end {
+			continue
+		}
+		if innermost == nil || symbol.EndByte-symbol.StartByte < innermost.EndByte-innermost.StartByte {
+			innermost = symbol
+		}
+		if !isCallableSymbol(symbol.SymbolType) {
+			continue
+		}
+		if callable == nil || symbol.EndByte-symbol.StartByte < callable.EndByte-callable.StartByte {
+			callable = symbol
+		}
+	}
+
+	if callable != nil {
+		return callable
+	}
+	return innermost
+}
+
+// isCallableSymbol reports whether symbols of the type have a body that makes calls
+func isCallableSymbol(symbolType SymbolType) bool {
+	switch symbolType {
+	case SymbolTypeFunction, SymbolTypeMethod, SymbolTypeConstructor, SymbolTypeComponent, SymbolTypeHook:
+		return true
+	}
+	return false
+}
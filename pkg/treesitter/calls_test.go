@@ -0,0 +1,159 @@
+package treesitter
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	sitter "github.com/madeindigio/go-tree-sitter"
+)
+
+// parseSample parses source code of a language for tests
+func parseSample(t *testing.T, lang Language, source string) *sitter.Tree {
+	t.Helper()
+	parser := NewParser()
+	t.Cleanup(parser.Close)
+
+	tree, err := parser.Parse(context.Background(), []byte(source), lang)
+	if err != nil {
+		t.Fatalf("parse %s: %v", lang, err)
+	}
+	return tree
+}
+
+func TestExtractCalls(t *testing.T) {
+	tests := []struct {
+		name   string
+		lang   Language
+		source string
+		want   []CodeCall
+	}{
+		{
+			name: "go",
+			lang: LanguageGo,
+			source: `package app
+
+func helper() int { return 1 }
+
+func (s *Server) Run() {
+	s.storage.SaveCodeFile(nil)
+	helper()
+	func() {}()
+}
+
+var ready = helper()
+`,
+			want: []CodeCall{
+				{CallerNamePath: "/Server.Run", CallerName: "Run", CalleeName: "SaveCodeFile", CalleeExpr: "s.storage.SaveCodeFile", Line: 6},
+				{CallerNamePath: "/Server.Run", CallerName: "Run", CalleeName: "helper", CalleeExpr: "helper", Line: 7},
+				{CallerNamePath: "/ready", CallerName: "ready", CalleeName: "helper", CalleeExpr: "helper", Line: 11},
+			},
+		},
+		{
+			name: "python",
+			lang: LanguagePython,
+			source: `class Store:
+    def save(self, item):
+        self.db.insert(item)
+        print(len(item))
+
+
+Store().save(1)
+`,
+			want: []CodeCall{
+				{CallerNamePath: "/Store/save", CallerName: "save", CalleeName: "insert", CalleeExpr: "self.db.insert", Line: 3},
+				{CallerNamePath: "/Store/save", CallerName: "save", CalleeName: "print", CalleeExpr: "print", Line: 4},
+				{CallerNamePath: "/Store/save", CallerName: "save", CalleeName: "len", CalleeExpr: "len", Line: 4},
+				{CalleeName: "save", CalleeExpr: "Store().save", Line: 7},
+				{CalleeName: "Store", CalleeExpr: "Store", Line: 7},
+			},
+		},
+		{
+			name: "javascript",
+			lang: LanguageJavaScript,
+			source: `function main() {
+  const app = new Server(config);
+  app.listen(8080);
+  (function () {})();
+}
+`,
+			want: []CodeCall{
+				{CallerNamePath: "/main", CallerName: "main", CalleeName: "Server", CalleeExpr: "Server", Line: 2},
+				{CallerNamePath: "/main", CallerName: "main", CalleeName: "listen", CalleeExpr: "app.listen", Line: 3},
+			},
+		},
+		{
+			name: "java",
+			lang: LanguageJava,
+			source: `class Box {
+    void fill() {
+        items.add(new Item());
+    }
+}
+`,
+			want: []CodeCall{
+				{CallerNamePath: "/Box/fill", CallerName: "fill", CalleeName: "add", CalleeExpr: "add", Line: 3},
+				{CallerNamePath: "/Box/fill", CallerName: "fill", CalleeName: "Item", CalleeExpr: "Item", Line: 3},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tree := parseSample(t, tt.lang, tt.source)
+			source := []byte(tt.source)
+			symbols, err := NewASTWalker(DefaultWalkerConfig()).ExtractSymbols(tree, source, tt.lang, "sample", "test")
+			if err != nil {
+				t.Fatalf("extract symbols: %v", err)
+			}
+
+			got := ExtractCalls(tree, source, symbols)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ExtractCalls =\n%+v\nwant\n%+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCalleeName(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"helper", "helper"},
+		{"s.storage.SaveCodeFile", "SaveCodeFile"},
+		{"Vec::<T>::new", "new"},
+		{"pkg.New[T]", "New"},
+		{"Map<String, List<Item>>", "Map"},
+		{"$this->repo->find", "find"},
+		{"123", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := calleeName(tt.expr); got != tt.want {
+			t.Errorf("calleeName(%q) = %q, want %q", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestEnclosingSymbol(t *testing.T) {
+	class := &CodeSymbol{NamePath: "/Store", SymbolType: SymbolTypeClass, StartByte: 0, EndByte: 100}
+	method := &CodeSymbol{NamePath: "/Store/save", SymbolType: SymbolTypeMethod, StartByte: 10, EndByte: 50}
+	local := &CodeSymbol{NamePath: "/Store/save/item", SymbolType: SymbolTypeVariable, StartByte: 20, EndByte: 30}
+	symbols := []*CodeSymbol{class, method, local}
+
+	tests := []struct {
+		name       string
+		start, end int
+		want       *CodeSymbol
+	}{
+		{"callable preferred over innermost", 22, 25, method},
+		{"innermost when none is callable", 60, 70, class},
+		{"outside every symbol", 120, 130, nil},
+	}
+	for _, tt := range tests {
+		if got := enclosingSymbol(symbols, tt.start, tt.end); got != tt.want {
+			t.Errorf("%s: enclosingSymbol(%d, %d) = %+v, want %+v", tt.name, tt.start, tt.end, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+// Package treesitter provides golden files of extracted symbols, to test
+// extractors against sample sources.
+package treesitter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// GoldenSuffix is appended to the path of a sample source to name the golden
+// file holding the symbols expected from it.
+const GoldenSuffix = ".symbols.json"
+
+// GoldenFile is the expected extraction of a sample source.
+type GoldenFile struct {
+	Language Language       `json:"language"`
+	Symbols  []GoldenSymbol `json:"symbols"`
+}
+
+// GoldenSymbol is the part of an extracted symbol that does not change
+// between runs: no IDs, timestamps or embeddings. The parent is given by its
+// name path, and byte offsets are left out to keep golden files reviewable.
+type GoldenSymbol struct {
+	SymbolType SymbolType             `json:"symbol_type"`
+	Name       string                 `json:"name"`
+	NamePath   string                 `json:"name_path"`
+	Parent     string                 `json:"parent,omitempty"`
+	StartLine  int                    `json:"start_line"`
+	EndLine    int                    `json:"end_line"`
+	Signature  string                 `json:"signature,omitempty"`
+	DocString  string                 `json:"doc_string,omitempty"`
+	Metadata   map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// GoldenPath returns the path of the golden file of a sample source.
+func GoldenPath(samplePath string) string {
+	return samplePath + GoldenSuffix
+}
+
+// IsGoldenSample reports whether a path is a sample source with a supported
+// extension, and not a golden file.
+func IsGoldenSample(path string) bool {
+	return !strings.HasSuffix(path, GoldenSuffix) && IsSupportedFile(path)
+}
+
+// FindGoldenSamples returns the sample sources under a directory, sorted.
+func FindGoldenSamples(dir string) ([]string, error) {
+	var samples []string
+	err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && IsGoldenSample(path) {
+			samples = append(samples, path)
+		}
+		return nil
+	})
+	return samples, err
+}
+
+// ExtractGolden parses a sample source with the default walker configuration
+// and returns its symbols in source order.
+func ExtractGolden(ctx context.Context, parser *Parser, samplePath string) (*GoldenFile, error) {
+	tree, lang, err := parser.ParseFile(ctx, samplePath)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := os.ReadFile(samplePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	walker := NewASTWalker(DefaultWalkerConfig())
+	symbols, err := walker.ExtractSymbols(tree, content, lang, filepath.ToSlash(filepath.Base(samplePath)), "golden")
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract symbols: %w", err)
+	}
+
+	// Outer symbols first when several start at the same byte; otherwise
+	// the extraction order is kept
+	sort.SliceStable(symbols, func(i, j int) bool {
+		if symbols[i].StartByte != symbols[j].StartByte {
+			return symbols[i].StartByte < symbols[j].StartByte
+		}
+		return symbols[i].EndByte > symbols[j].EndByte
+	})
+
+	namePaths := make(map[string]string, len(symbols))
+	for _, sym := range symbols {
+		namePaths[sym.ID] = sym.NamePath
+	}
+
+	golden := &GoldenFile{Language: lang, Symbols: make([]GoldenSymbol, 0, len(symbols))}
+	for _, sym := range symbols {
+		g := GoldenSymbol{
+			SymbolType: sym.SymbolType,
+			Name:       sym.Name,
+			NamePath:   sym.NamePath,
+			StartLine:  sym.StartLine,
+			EndLine:    sym.EndLine,
+			Signature:  sym.Signature,
+			DocString:  sym.DocString,
+			Metadata:   sym.Metadata,
+		}
+		if sym.ParentID != nil {
+			g.Parent = namePaths[*sym.ParentID]
+		}
+		golden.Symbols = append(golden.Symbols, g)
+	}
+	return golden, nil
+}
+
+// MarshalGolden returns the content of a golden file: indented JSON, without
+// HTML escaping, ending in a newline.
+func MarshalGolden(golden *GoldenFile) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(golden); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// WriteGolden extracts the symbols of a sample source and writes its golden
+// file, returning the golden file path.
+func WriteGolden(ctx context.Context, parser *Parser, samplePath string) (string, error) {
+	golden, err := ExtractGolden(ctx, parser, samplePath)
+	if err != nil {
+		return "", err
+	}
+	data, err := MarshalGolden(golden)
+	if err != nil {
+		return "", err
+	}
+	path := GoldenPath(samplePath)
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return "", fmt.Errorf("failed to write golden file: %w", err)
+	}
+	return path, nil
+}
@@ -0,0 +1,84 @@
+package treesitter
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite the golden files of the extractor samples")
+
+// goldenDir holds one directory per language with sample sources, each
+// next to its golden file.
+const goldenDir = "testdata/golden"
+
+func TestExtractorGoldenFiles(t *testing.T) {
+	samples, err := FindGoldenSamples(goldenDir)
+	if err != nil {
+		t.Fatalf("failed to list samples: %v", err)
+	}
+	if len(samples) == 0 {
+		t.Fatalf("no samples in %s", goldenDir)
+	}
+
+	parser := NewParser()
+	defer parser.Close()
+
+	for _, sample := range samples {
+		name, _ := filepath.Rel(goldenDir, sample)
+		t.Run(filepath.ToSlash(name), func(t *testing.T) {
+			golden, err := ExtractGolden(context.Background(), parser, sample)
+			if err != nil {
+				t.Fatalf("extract: %v", err)
+			}
+			got, err := MarshalGolden(golden)
+			if err != nil {
+				t.Fatalf("marshal: %v", err)
+			}
+
+			if *updateGolden {
+				if err := os.WriteFile(GoldenPath(sample), got, 0o644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+
+			want, err := os.ReadFile(GoldenPath(sample))
+			if os.IsNotExist(err) {
+				t.Fatalf("no golden file; generate it with go run ./cmd/extractor-golden %s", filepath.ToSlash(sample))
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, want) {
+				line, gotLine, wantLine := firstDifference(string(got), string(want))
+				t.Errorf("symbols differ from %s at line %d:\n got: %s\nwant: %s\nrerun with -update if the change is intended",
+					GoldenPath(sample), line, gotLine, wantLine)
+			}
+		})
+	}
+}
+
+// firstDifference returns the first line, 1-based, where got and want differ
+// and its content in both.
+func firstDifference(got, want string) (int, string, string) {
+	gotLines := strings.Split(got, "\n")
+	wantLines := strings.Split(want, "\n")
+	for i := 0; i < max(len(gotLines), len(wantLines)); i++ {
+		var g, w string
+		if i < len(gotLines) {
+			g = gotLines[i]
+		}
+		if i < len(wantLines) {
+			w = wantLines[i]
+		}
+		if g != w {
+			return i + 1, g, w
+		}
+	}
+	return 0, "", ""
+}
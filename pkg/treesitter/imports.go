@@ -0,0 +1,278 @@
+// Package treesitter provides import and dependency extraction.
+package treesitter
+
+import (
+	"strings"
+
+	sitter "github.com/madeindigio/go-tree-sitter"
+)
+
+// CodeImport is a dependency declared by a source file through an import,
+// use, require or include statement
+type CodeImport struct {
+	// Module as written in the source: a relative path, package or namespace
+	Module string `json:"module"`
+
+	// Line of the statement (1-based)
+	Line int `json:"line"`
+}
+
+// ExtractImports returns the modules a parsed file depends on, in source
+// order and without duplicates. Languages without import support return nil.
+func ExtractImports(tree *sitter.Tree, sourceCode []byte, lang Language) []CodeImport {
+	var imports []CodeImport
+	seen := make(map[string]bool)
+
+	it := NewNodeIterator(tree.RootNode())
+	for node := it.Next(); node != nil; node = it.Next() {
+		for _, module := range importModules(node, sourceCode, lang) {
+			module = strings.TrimSpace(module)
+			if module == "" || seen[module] {
+				continue
+			}
+			seen[module] = true
+			imports = append(imports, CodeImport{Module: module, Line: int(node.StartPoint().Row) + 1})
+		}
+	}
+
+	return imports
+}
+
+// importModules returns the modules imported by node, if it is an import statement
+func importModules(node *sitter.Node, sourceCode []byte, lang Language) []string {
+	switch lang {
+	case LanguageGo:
+		if node.Type() == "import_spec" {
+			return fieldString(node, "path", sourceCode)
+		}
+
+	case LanguageTypeScript, LanguageTSX, LanguageJavaScript:
+		switch node.Type() {
+		case "import_statement", "export_statement":
+			return fieldString(node, "source", sourceCode)
+		case "call_expression":
+			// require('x') and dynamic import('x')
+			callee := node.ChildByFieldName("function")
+			if callee == nil || (callee.Type() != "import" && GetNodeContent(callee, sourceCode) != "require") {
+				return nil
+			}
+			if args := node.ChildByFieldName("arguments"); args != nil && args.NamedChildCount() > 0 {
+				if arg := args.NamedChild(0); arg != nil && arg.Type() == "string" {
+					return []string{unquoteImport(GetNodeContent(arg, sourceCode))}
+				}
+			}
+		}
+
+	case LanguagePython:
+		return pythonImports(node, sourceCode)
+
+	case LanguageRust:
+		switch node.Type() {
+		case "use_declaration":
+			arg := node.ChildByFieldName("argument")
+			if arg == nil {
+				return nil
+			}
+			if path := arg.ChildByFieldName("path"); path != nil && (arg.Type() == "scoped_use_list" || arg.Type() == "use_as_clause") {
+				arg = path
+			}
+			return []string{strings.TrimSuffix(GetNodeContent(arg, sourceCode), "::*")}
+		case "extern_crate_declaration":
+			if name := node.ChildByFieldName("name"); name != nil {
+				return []string{GetNodeContent(name, sourceCode)}
+			}
+		}
+
+	case LanguageJava:
+		if node.Type() == "import_declaration" {
+			for _, child := range IterateNamedChildren(node) {
+				if child.Type() == "scoped_identifier" || child.Type() == "identifier" {
+					return []string{GetNodeContent(child, sourceCode)}
+				}
+			}
+		}
+
+	case LanguageKotlin, LanguageSwift:
+		if node.Type() == "import_header" || node.Type() == "import_declaration" {
+			if ident := FindNamedChildByType(node, "identifier"); ident != nil {
+				return []string{GetNodeContent(ident, sourceCode)}
+			}
+		}
+
+	case LanguagePHP:
+		return phpImports(node, sourceCode)
+
+	case LanguageC, LanguageCPP, LanguageObjectiveC:
+		if node.Type() == "preproc_include" {
+			if path := node.ChildByFieldName("path"); path != nil {
+				return []string{strings.Trim(unquoteImport(GetNodeContent(path, sourceCode)), "<>")}
+			}
+		}
+
+	case LanguageCSharp:
+		if node.Type() == "using_directive" && node.NamedChildCount() > 0 {
+			// The imported namespace follows any alias
+			if last := node.NamedChild(int(node.NamedChildCount()) - 1); last != nil {
+				return []string{GetNodeContent(last, sourceCode)}
+			}
+		}
+
+	case LanguageRuby:
+		if node.Type() != "call" {
+			return nil
+		}
+		method := node.ChildByFieldName("method")
+		if method == nil {
+			return nil
+		}
+		switch name := GetNodeContent(method, sourceCode); name {
+		case "require", "require_relative", "load":
+			args := node.ChildByFieldName("arguments")
+			if args == nil || args.NamedChildCount() == 0 || args.NamedChild(0).Type() != "string" {
+				return nil
+			}
+			module := unquoteImport(GetNodeContent(args.NamedChild(0), sourceCode))
+			if name == "require_relative" && !strings.HasPrefix(module, ".") {
+				module = "./" + module
+			}
+			return []string{module}
+		}
+
+	case LanguageLua:
+		if node.Type() != "function_call" {
+			return nil
+		}
+		if prefix := node.ChildByFieldName("prefix"); prefix == nil || GetNodeContent(prefix, sourceCode) != "require" {
+			return nil
+		}
+		if args := node.ChildByFieldName("args"); args != nil {
+			if str := FindNamedChildByType(args, "string"); str != nil {
+				return []string{unquoteImport(GetNodeContent(str, sourceCode))}
+			}
+			return []string{unquoteImport(GetNodeContent(args, sourceCode))}
+		}
+
+	case LanguageProtobuf:
+		if node.Type() == "import" {
+			return fieldString(node, "path", sourceCode)
+		}
+
+	case "scala":
+		if node.Type() == "import_declaration" {
+			var parts []string
+			for i := 0; i < int(node.ChildCount()); i++ {
+				if child := node.Child(i); node.FieldNameForChild(i) == "path" && child.IsNamed() {
+					parts = append(parts, GetNodeContent(child, sourceCode))
+				}
+			}
+			return []string{strings.Join(parts, ".")}
+		}
+	}
+
+	return nil
+}
+
+// pythonImports handles `import a.b` and `from .a import b`. A bare relative
+// prefix (`from . import b`) imports the sibling modules it names.
+func pythonImports(node *sitter.Node, sourceCode []byte) []string {
+	var modules []string
+
+	switch node.Type() {
+	case "import_statement":
+		for _, child := range IterateNamedChildren(node) {
+			switch child.Type() {
+			case "dotted_name":
+				modules = append(modules, GetNodeContent(child, sourceCode))
+			case "aliased_import":
+				if name := child.ChildByFieldName("name"); name != nil {
+					modules = append(modules, GetNodeContent(name, sourceCode))
+				}
+			}
+		}
+
+	case "import_from_statement":
+		moduleNode := node.ChildByFieldName("module_name")
+		if moduleNode == nil {
+			return nil
+		}
+		module := GetNodeContent(moduleNode, sourceCode)
+		if strings.Trim(module, ".") != "" {
+			return []string{module}
+		}
+		for i := 0; i < int(node.ChildCount()); i++ {
+			if node.FieldNameForChild(i) != "name" {
+				continue
+			}
+			name := node.Child(i)
+			if name.Type() == "aliased_import" {
+				name = name.ChildByFieldName("name")
+			}
+			if name != nil {
+				modules = append(modules, module+GetNodeContent(name, sourceCode))
+			}
+		}
+	}
+
+	return modules
+}
+
+// phpImports handles `use` clauses, grouped `use` declarations and
+// require/include expressions
+func phpImports(node *sitter.Node, sourceCode []byte) []string {
+	switch node.Type() {
+	case "namespace_use_clause":
+		if node.NamedChildCount() > 0 {
+			return []string{GetNodeContent(node.NamedChild(0), sourceCode)}
+		}
+
+	case "namespace_use_declaration":
+		group := FindNamedChildByType(node, "namespace_use_group")
+		prefix := FindNamedChildByType(node, "namespace_name")
+		if group == nil || prefix == nil {
+			return nil
+		}
+		var modules []string
+		for _, clause := range IterateNamedChildren(group) {
+			if name := FindNamedChildByType(clause, "namespace_name"); name != nil {
+				modules = append(modules, GetNodeContent(prefix, sourceCode)+`\`+GetNodeContent(name, sourceCode))
+			}
+		}
+		return modules
+
+	case "require_expression", "require_once_expression", "include_expression", "include_once_expression":
+		// The path is the last string literal, so `__DIR__ . '/x.php'` yields "/x.php"
+		var path string
+		it := NewNodeIterator(node)
+		for n := it.Next(); n != nil; n = it.Next() {
+			if n.Type() == "string" || n.Type() == "encapsed_string" {
+				path = unquoteImport(GetNodeContent(n, sourceCode))
+			}
+		}
+		if path != "" {
+			return []string{path}
+		}
+	}
+
+	return nil
+}
+
+// fieldString returns the unquoted string held by a field of node
+func fieldString(node *sitter.Node, field string, sourceCode []byte) []string {
+	value := node.ChildByFieldName(field)
+	if value == nil {
+		return nil
+	}
+	return []string{unquoteImport(GetNodeContent(value, sourceCode))}
+}
+
+// unquoteImport strips the quotes around a string literal
+func unquoteImport(s string) string {
+	s = strings.TrimSpace(s)
+	if len(s) >= 2 {
+		first, last := s[0], s[len(s)-1]
+		if first == last && (first == '"' || first == '\'' || first == '`') {
+			return s[1 : len(s)-1]
+		}
+	}
+	return s
+}
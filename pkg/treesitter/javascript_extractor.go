@@ -25,6 +25,8 @@ func (j *JavaScriptExtractor) GetSymbolTypes() []SymbolType {
 		SymbolTypeMethod,
 		SymbolTypeVariable,
 		SymbolTypeConstant,
+		SymbolTypeComponent,
+		SymbolTypeHook,
 	}
 }
 
@@ -140,7 +142,9 @@ func (j *JavaScriptExtractor) extractFunction(node *sitter.Node, sourceCode []by
 	name := GetNodeContent(nameNode, sourceCode)
 	namePath := j.BuildNamePath(parentPath, name)
 
-	symbol := j.CreateSymbol(node, sourceCode, SymbolTypeFunction, name, namePath, filePath, projectID, parentID)
+	symbolType := reactFunctionType(name, node, sourceCode, SymbolTypeFunction)
+
+	symbol := j.CreateSymbol(node, sourceCode, symbolType, name, namePath, filePath, projectID, parentID)
 	symbol.DocString = j.ExtractDocString(node, sourceCode)
 
 	return symbol
@@ -180,8 +184,14 @@ func (j *JavaScriptExtractor) extractVariableDeclaration(node *sitter.Node, sour
 
 		value := declarator.ChildByFieldName("value")
 		if value != nil {
-			if value.Type() == "arrow_function" || value.Type() == "function" {
-				symbolType = SymbolTypeFunction
+			switch value.Type() {
+			case "arrow_function", "function", "function_expression":
+				symbolType = reactFunctionType(name, value, sourceCode, SymbolTypeFunction)
+			case "call_expression":
+				// memo(...) and forwardRef(...) wrap components
+				if reactWrappedComponent(name, value) {
+					symbolType = SymbolTypeComponent
+				}
 			}
 		}
 
@@ -16,10 +16,12 @@ import (
 	"github.com/madeindigio/go-tree-sitter/lua"
 	"github.com/madeindigio/go-tree-sitter/markdown"
 	"github.com/madeindigio/go-tree-sitter/php"
+	"github.com/madeindigio/go-tree-sitter/protobuf"
 	"github.com/madeindigio/go-tree-sitter/python"
 	"github.com/madeindigio/go-tree-sitter/ruby"
 	"github.com/madeindigio/go-tree-sitter/rust"
 	"github.com/madeindigio/go-tree-sitter/scala"
+	"github.com/madeindigio/go-tree-sitter/sql"
 	"github.com/madeindigio/go-tree-sitter/svelte"
 	"github.com/madeindigio/go-tree-sitter/swift"
 	"github.com/madeindigio/go-tree-sitter/toml"
@@ -100,6 +102,18 @@ var supportedLanguages = map[Language]LanguageInfo{
 		Extensions: []string{"vue"},
 		Grammar:    vue2.GetLanguage,
 	},
+	LanguageSQL: {
+		Language:   LanguageSQL,
+		Name:       "SQL",
+		Extensions: []string{"sql"},
+		Grammar:    sql.GetLanguage,
+	},
+	LanguageProtobuf: {
+		Language:   LanguageProtobuf,
+		Name:       "Protocol Buffers",
+		Extensions: []string{"proto"},
+		Grammar:    protobuf.GetLanguage,
+	},
 	LanguageRust: {
 		Language:   LanguageRust,
 		Name:       "Rust",
@@ -164,8 +178,8 @@ var supportedLanguages = map[Language]LanguageInfo{
 
 // Additional languages that can be enabled
 var additionalLanguages = map[Language]LanguageInfo{
-	"tsx": {
-		Language:   "tsx",
+	LanguageTSX: {
+		Language:   LanguageTSX,
 		Name:       "TSX",
 		Extensions: []string{"tsx"},
 		Grammar:    tsx.GetLanguage,
@@ -182,12 +196,18 @@ var additionalLanguages = map[Language]LanguageInfo{
 		Extensions: []string{"sh", "bash", "zsh"},
 		Grammar:    bash.GetLanguage,
 	},
-	"yaml": {
-		Language:   "yaml",
+	LanguageYAML: {
+		Language:   LanguageYAML,
 		Name:       "YAML",
 		Extensions: []string{"yml", "yaml"},
 		Grammar:    yaml.GetLanguage,
 	},
+	LanguageJSON: {
+		Language:   LanguageJSON,
+		Name:       "JSON",
+		Extensions: []string{"json"},
+		Grammar:    yaml.GetLanguage, // JSON is valid YAML
+	},
 	"html": {
 		Language:   "html",
 		Name:       "HTML",
@@ -29,45 +29,31 @@ func (m *MarkdownExtractor) ExtractSymbols(tree *sitter.Tree, sourceCode []byte,
 	var symbols []*CodeSymbol
 	root := tree.RootNode()
 
-	// Walk through the document extracting headings
-	symbols = m.extractHeadings(root, sourceCode, filePath, projectID, "", nil)
+	// Walk through the document extracting sections and their headings
+	symbols = m.extractHeadings(root, nil, sourceCode, filePath, projectID, "", nil)
 
 	return symbols, nil
 }
 
-// extractHeadings extracts headings from markdown
-func (m *MarkdownExtractor) extractHeadings(node *sitter.Node, sourceCode []byte, filePath string, projectID string, parentPath string, parentID *string) []*CodeSymbol {
+// extractHeadings extracts the sections and headings under node, skipping the
+// heading of the section being extracted
+func (m *MarkdownExtractor) extractHeadings(node *sitter.Node, skip *sitter.Node, sourceCode []byte, filePath string, projectID string, parentPath string, parentID *string) []*CodeSymbol {
 	var symbols []*CodeSymbol
-	var currentSymbol *CodeSymbol
-	var currentPath string
-	var currentID *string
 
 	for i := 0; i < int(node.NamedChildCount()); i++ {
 		child := node.NamedChild(i)
-		if child == nil {
+		if child == nil || child == skip {
 			continue
 		}
 
-		nodeType := child.Type()
+		switch child.Type() {
+		case "section":
+			symbols = append(symbols, m.extractSection(child, sourceCode, filePath, projectID, parentPath, parentID)...)
 
-		// Look for various heading types
-		switch nodeType {
-		case "section", "atx_heading", "setext_heading", "heading":
-			if symbol := m.extractHeading(child, sourceCode, filePath, projectID, parentPath, parentID); symbol != nil {
+		case "atx_heading", "setext_heading":
+			// Headings the grammar does not wrap in a section of their own
+			if symbol := m.extractHeading(child, child, sourceCode, filePath, projectID, parentPath, parentID); symbol != nil {
 				symbols = append(symbols, symbol)
-				currentSymbol = symbol
-				currentPath = symbol.NamePath
-				currentID = &symbol.ID
-			}
-
-		default:
-			// For nested structures, recurse
-			if currentSymbol != nil {
-				childSymbols := m.extractHeadings(child, sourceCode, filePath, projectID, currentPath, currentID)
-				if len(childSymbols) > 0 {
-					currentSymbol.Children = append(currentSymbol.Children, childSymbols...)
-					symbols = append(symbols, childSymbols...)
-				}
 			}
 		}
 	}
@@ -75,27 +61,47 @@ func (m *MarkdownExtractor) extractHeadings(node *sitter.Node, sourceCode []byte
 	return symbols
 }
 
-// extractHeading extracts a heading node
-func (m *MarkdownExtractor) extractHeading(node *sitter.Node, sourceCode []byte, filePath string, projectID string, parentPath string, parentID *string) *CodeSymbol {
-	// Try to find the heading content
-	var name string
-
-	// Look for heading_content or inline content
+// extractSection extracts a section, spanning its heading and content, and its subsections
+func (m *MarkdownExtractor) extractSection(node *sitter.Node, sourceCode []byte, filePath string, projectID string, parentPath string, parentID *string) []*CodeSymbol {
+	var heading *sitter.Node
 	for i := 0; i < int(node.NamedChildCount()); i++ {
 		child := node.NamedChild(i)
-		if child == nil {
-			continue
+		if child != nil && (child.Type() == "atx_heading" || child.Type() == "setext_heading") {
+			heading = child
+			break
 		}
+	}
 
-		if child.Type() == "heading_content" || child.Type() == "inline" {
-			name = GetNodeContent(child, sourceCode)
-			break
+	var symbol *CodeSymbol
+	if heading != nil {
+		symbol = m.extractHeading(node, heading, sourceCode, filePath, projectID, parentPath, parentID)
+	}
+	if symbol == nil {
+		return m.extractHeadings(node, nil, sourceCode, filePath, projectID, parentPath, parentID)
+	}
+
+	symbols := []*CodeSymbol{symbol}
+	children := m.extractHeadings(node, heading, sourceCode, filePath, projectID, symbol.NamePath, &symbol.ID)
+	for _, child := range children {
+		if child.ParentID != nil && *child.ParentID == symbol.ID {
+			symbol.Children = append(symbol.Children, child)
 		}
 	}
 
-	// If we couldn't find named content, use the whole node
+	return append(symbols, children...)
+}
+
+// extractHeading creates the symbol for a heading; node is the section it
+// titles, or the heading itself when it has no section
+func (m *MarkdownExtractor) extractHeading(node *sitter.Node, heading *sitter.Node, sourceCode []byte, filePath string, projectID string, parentPath string, parentID *string) *CodeSymbol {
+	var name string
+	if content := heading.ChildByFieldName("heading_content"); content != nil {
+		name = GetNodeContent(content, sourceCode)
+	}
+
+	// If we couldn't find named content, use the whole heading
 	if name == "" {
-		content := GetNodeContent(node, sourceCode)
+		content := GetNodeContent(heading, sourceCode)
 		// Clean up markdown heading markers
 		if len(content) > 0 && content[0] == '#' {
 			// Remove leading # symbols and whitespace
@@ -110,10 +116,6 @@ func (m *MarkdownExtractor) extractHeading(node *sitter.Node, sourceCode []byte,
 		}
 	}
 
-	if name == "" {
-		return nil
-	}
-
 	// Trim whitespace
 	name = trimString(name)
 	if name == "" {
@@ -123,10 +125,39 @@ func (m *MarkdownExtractor) extractHeading(node *sitter.Node, sourceCode []byte,
 	namePath := m.BuildNamePath(parentPath, name)
 
 	symbol := m.CreateSymbol(node, sourceCode, SymbolTypeNamespace, name, namePath, filePath, projectID, parentID)
+	if level := headingLevel(heading); level > 0 {
+		symbol.Metadata = map[string]interface{}{"level": level}
+	}
 
 	return symbol
 }
 
+// headingLevel returns the level (1-6) of an ATX or setext heading
+func headingLevel(heading *sitter.Node) int {
+	for i := 0; i < int(heading.ChildCount()); i++ {
+		child := heading.Child(i)
+		if child == nil {
+			continue
+		}
+
+		switch child.Type() {
+		case "atx_h1_marker", "setext_h1_underline":
+			return 1
+		case "atx_h2_marker", "setext_h2_underline":
+			return 2
+		case "atx_h3_marker":
+			return 3
+		case "atx_h4_marker":
+			return 4
+		case "atx_h5_marker":
+			return 5
+		case "atx_h6_marker":
+			return 6
+		}
+	}
+	return 0
+}
+
 // trimString removes leading and trailing whitespace
 func trimString(s string) string {
 	start := 0
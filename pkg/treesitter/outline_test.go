@@ -0,0 +1,121 @@
+package treesitter
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestOutlineSymbols(t *testing.T) {
+	// symbol is the part of an extracted symbol the outline extractors decide
+	type symbol struct {
+		Type     SymbolType
+		Parent   string
+		Metadata map[string]interface{}
+	}
+	level := func(n int) map[string]interface{} { return map[string]interface{}{"level": n} }
+	tests := []struct {
+		name   string
+		lang   Language
+		source string
+		want   map[string]symbol
+	}{
+		{
+			name: "markdown",
+			lang: LanguageMarkdown,
+			source: `# Remembrances
+
+Intro.
+
+## Architecture overview
+
+Layers.
+
+### Storage
+
+SurrealDB.
+
+## Deployment config
+
+Docker.
+`,
+			want: map[string]symbol{
+				"/Remembrances":                               {SymbolTypeNamespace, "", level(1)},
+				"/Remembrances/Architecture overview":         {SymbolTypeNamespace, "/Remembrances", level(2)},
+				"/Remembrances/Architecture overview/Storage": {SymbolTypeNamespace, "/Remembrances/Architecture overview", level(3)},
+				"/Remembrances/Deployment config":             {SymbolTypeNamespace, "/Remembrances", level(2)},
+			},
+		},
+		{
+			name: "yaml",
+			lang: LanguageYAML,
+			source: `server:
+  port: 8080
+  tls:
+    cert: server.pem
+database: postgres
+`,
+			want: map[string]symbol{
+				"/server":      {SymbolTypeConstant, "", nil},
+				"/server/port": {SymbolTypeVariable, "/server", nil},
+				"/server/tls":  {SymbolTypeConstant, "/server", nil},
+				"/database":    {SymbolTypeVariable, "", nil},
+			},
+		},
+		{
+			name:   "json",
+			lang:   LanguageJSON,
+			source: `{"server": {"port": 8080}, "name": "app"}`,
+			want: map[string]symbol{
+				"/server":      {SymbolTypeConstant, "", nil},
+				"/server/port": {SymbolTypeVariable, "/server", nil},
+				"/name":        {SymbolTypeVariable, "", nil},
+			},
+		},
+		{
+			name: "toml",
+			lang: LanguageTOML,
+			source: `title = "app"
+
+[server]
+port = 8080
+
+[[plugins]]
+name = "auth"
+`,
+			want: map[string]symbol{
+				"/title":        {SymbolTypeVariable, "", nil},
+				"/server":       {SymbolTypeConstant, "", nil},
+				"/server/port":  {SymbolTypeVariable, "/server", nil},
+				"/plugins":      {SymbolTypeConstant, "", nil},
+				"/plugins/name": {SymbolTypeVariable, "/plugins", nil},
+			},
+		},
+	}
+
+	walker := NewASTWalker(DefaultWalkerConfig())
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tree := parseSample(t, tt.lang, tt.source)
+			symbols, err := walker.ExtractSymbols(tree, []byte(tt.source), tt.lang, "sample", "test")
+			if err != nil {
+				t.Fatalf("extract symbols: %v", err)
+			}
+
+			namePaths := make(map[string]string, len(symbols))
+			for _, s := range symbols {
+				namePaths[s.ID] = s.NamePath
+			}
+			got := make(map[string]symbol, len(symbols))
+			for _, s := range symbols {
+				sym := symbol{Type: s.SymbolType, Metadata: s.Metadata}
+				if s.ParentID != nil {
+					sym.Parent = namePaths[*s.ParentID]
+				}
+				got[s.NamePath] = sym
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("symbols =\n%+v\nwant\n%+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -3,6 +3,8 @@ package treesitter
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -268,6 +270,13 @@ func GetNodeContent(node *sitter.Node, sourceCode []byte) string {
 	return node.Content(sourceCode)
 }
 
+// SymbolRevision returns a short content hash of a symbol's source text.
+// Any edit to the symbol changes its revision.
+func SymbolRevision(code []byte) string {
+	sum := sha256.Sum256(code)
+	return hex.EncodeToString(sum[:8])
+}
+
 // GetNodeLocation returns line and byte information for a node
 func GetNodeLocation(node *sitter.Node) (startLine, endLine int, startByte, endByte int) {
 	startPoint := node.StartPoint()
@@ -0,0 +1,244 @@
+// Package treesitter provides Protocol Buffers symbol extraction.
+package treesitter
+
+import (
+	"strings"
+
+	sitter "github.com/madeindigio/go-tree-sitter"
+)
+
+// ProtobufExtractor extracts packages, messages, enums and services from .proto files
+type ProtobufExtractor struct {
+	BaseExtractor
+}
+
+// NewProtobufExtractor creates a new Protocol Buffers extractor
+func NewProtobufExtractor(config WalkerConfig) *ProtobufExtractor {
+	return &ProtobufExtractor{
+		BaseExtractor: NewBaseExtractor(LanguageProtobuf, config),
+	}
+}
+
+// GetSymbolTypes returns the types of symbols the Protocol Buffers extractor can find
+func (p *ProtobufExtractor) GetSymbolTypes() []SymbolType {
+	return []SymbolType{
+		SymbolTypePackage,
+		SymbolTypeMessage,
+		SymbolTypeField,
+		SymbolTypeEnum,
+		SymbolTypeEnumMember,
+		SymbolTypeService,
+		SymbolTypeMethod,
+	}
+}
+
+// ExtractSymbols extracts all symbols from a .proto file
+func (p *ProtobufExtractor) ExtractSymbols(tree *sitter.Tree, sourceCode []byte, filePath string, projectID string) ([]*CodeSymbol, error) {
+	var symbols []*CodeSymbol
+	root := tree.RootNode()
+
+	for i := 0; i < int(root.NamedChildCount()); i++ {
+		child := root.NamedChild(i)
+		if child == nil {
+			continue
+		}
+
+		symbols = append(symbols, p.extractNode(child, sourceCode, filePath, projectID, "", nil)...)
+	}
+
+	return symbols, nil
+}
+
+// extractNode extracts symbols from a node
+func (p *ProtobufExtractor) extractNode(node *sitter.Node, sourceCode []byte, filePath string, projectID string, parentPath string, parentID *string) []*CodeSymbol {
+	var symbols []*CodeSymbol
+
+	switch node.Type() {
+	case "package":
+		if ident := FindNamedChildByType(node, "full_ident"); ident != nil {
+			name := GetNodeContent(ident, sourceCode)
+			symbols = append(symbols, p.CreateSymbol(node, sourceCode, SymbolTypePackage, name, p.BuildNamePath(parentPath, name), filePath, projectID, parentID))
+		}
+
+	case "message":
+		symbols = append(symbols, p.extractMessage(node, sourceCode, filePath, projectID, parentPath, parentID)...)
+
+	case "enum":
+		symbols = append(symbols, p.extractEnum(node, sourceCode, filePath, projectID, parentPath, parentID)...)
+
+	case "service":
+		symbols = append(symbols, p.extractService(node, sourceCode, filePath, projectID, parentPath, parentID)...)
+	}
+
+	return symbols
+}
+
+// extractMessage extracts a message, its fields and nested types
+func (p *ProtobufExtractor) extractMessage(node *sitter.Node, sourceCode []byte, filePath string, projectID string, parentPath string, parentID *string) []*CodeSymbol {
+	var symbols []*CodeSymbol
+
+	name := p.declarationName(node, "message_name", sourceCode)
+	if name == "" {
+		return symbols
+	}
+	namePath := p.BuildNamePath(parentPath, name)
+
+	symbol := p.CreateSymbol(node, sourceCode, SymbolTypeMessage, name, namePath, filePath, projectID, parentID)
+	symbol.DocString = p.ExtractDocString(node, sourceCode)
+	symbols = append(symbols, symbol)
+
+	body := FindNamedChildByType(node, "message_body")
+	if body == nil {
+		return symbols
+	}
+
+	for i := 0; i < int(body.NamedChildCount()); i++ {
+		member := body.NamedChild(i)
+		if member == nil {
+			continue
+		}
+
+		var memberSymbols []*CodeSymbol
+		switch member.Type() {
+		case "field", "map_field":
+			if field := p.extractField(member, sourceCode, filePath, projectID, namePath, &symbol.ID); field != nil {
+				memberSymbols = append(memberSymbols, field)
+			}
+
+		case "oneof":
+			// Oneof fields belong to the message; the group name is kept in metadata
+			group := ""
+			if ident := FindNamedChildByType(member, "identifier"); ident != nil {
+				group = GetNodeContent(ident, sourceCode)
+			}
+			for _, oneofField := range FindChildrenByType(member, "oneof_field") {
+				if field := p.extractField(oneofField, sourceCode, filePath, projectID, namePath, &symbol.ID); field != nil {
+					field.Metadata = map[string]interface{}{"oneof": group}
+					memberSymbols = append(memberSymbols, field)
+				}
+			}
+
+		default:
+			memberSymbols = p.extractNode(member, sourceCode, filePath, projectID, namePath, &symbol.ID)
+		}
+
+		for _, ms := range memberSymbols {
+			if ms.ParentID != nil && *ms.ParentID == symbol.ID {
+				symbol.Children = append(symbol.Children, ms)
+			}
+		}
+		symbols = append(symbols, memberSymbols...)
+	}
+
+	return symbols
+}
+
+// extractField extracts a message field
+func (p *ProtobufExtractor) extractField(node *sitter.Node, sourceCode []byte, filePath string, projectID string, parentPath string, parentID *string) *CodeSymbol {
+	ident := FindNamedChildByType(node, "identifier")
+	if ident == nil {
+		return nil
+	}
+
+	name := GetNodeContent(ident, sourceCode)
+	symbol := p.CreateSymbol(node, sourceCode, SymbolTypeField, name, p.BuildNamePath(parentPath, name), filePath, projectID, parentID)
+	symbol.Signature = strings.TrimSuffix(strings.TrimSpace(GetNodeContent(node, sourceCode)), ";")
+	symbol.DocString = p.ExtractDocString(node, sourceCode)
+
+	return symbol
+}
+
+// extractEnum extracts an enum and its values
+func (p *ProtobufExtractor) extractEnum(node *sitter.Node, sourceCode []byte, filePath string, projectID string, parentPath string, parentID *string) []*CodeSymbol {
+	var symbols []*CodeSymbol
+
+	name := p.declarationName(node, "enum_name", sourceCode)
+	if name == "" {
+		return symbols
+	}
+	namePath := p.BuildNamePath(parentPath, name)
+
+	symbol := p.CreateSymbol(node, sourceCode, SymbolTypeEnum, name, namePath, filePath, projectID, parentID)
+	symbol.DocString = p.ExtractDocString(node, sourceCode)
+	symbols = append(symbols, symbol)
+
+	body := FindNamedChildByType(node, "enum_body")
+	if body == nil {
+		return symbols
+	}
+
+	for _, value := range FindChildrenByType(body, "enum_field") {
+		ident := FindNamedChildByType(value, "identifier")
+		if ident == nil {
+			continue
+		}
+
+		valueName := GetNodeContent(ident, sourceCode)
+		valueSymbol := p.CreateSymbol(value, sourceCode, SymbolTypeEnumMember, valueName, p.BuildNamePath(namePath, valueName), filePath, projectID, &symbol.ID)
+		symbol.Children = append(symbol.Children, valueSymbol)
+		symbols = append(symbols, valueSymbol)
+	}
+
+	return symbols
+}
+
+// extractService extracts a service and its RPC methods
+func (p *ProtobufExtractor) extractService(node *sitter.Node, sourceCode []byte, filePath string, projectID string, parentPath string, parentID *string) []*CodeSymbol {
+	var symbols []*CodeSymbol
+
+	name := p.declarationName(node, "service_name", sourceCode)
+	if name == "" {
+		return symbols
+	}
+	namePath := p.BuildNamePath(parentPath, name)
+
+	symbol := p.CreateSymbol(node, sourceCode, SymbolTypeService, name, namePath, filePath, projectID, parentID)
+	symbol.DocString = p.ExtractDocString(node, sourceCode)
+	symbols = append(symbols, symbol)
+
+	for _, rpc := range FindChildrenByType(node, "rpc") {
+		rpcName := p.declarationName(rpc, "rpc_name", sourceCode)
+		if rpcName == "" {
+			continue
+		}
+
+		rpcSymbol := p.CreateSymbol(rpc, sourceCode, SymbolTypeMethod, rpcName, p.BuildNamePath(namePath, rpcName), filePath, projectID, &symbol.ID)
+		rpcSymbol.Signature = p.rpcSignature(rpc, sourceCode)
+		rpcSymbol.DocString = p.ExtractDocString(rpc, sourceCode)
+
+		// Request and response types, in declaration order
+		types := FindChildrenByType(rpc, "message_or_enum_type")
+		if len(types) == 2 {
+			rpcSymbol.Metadata = map[string]interface{}{
+				"request":  GetNodeContent(types[0], sourceCode),
+				"response": GetNodeContent(types[1], sourceCode),
+			}
+		}
+
+		symbol.Children = append(symbol.Children, rpcSymbol)
+		symbols = append(symbols, rpcSymbol)
+	}
+
+	return symbols
+}
+
+// declarationName returns the identifier inside a *_name child node
+func (p *ProtobufExtractor) declarationName(node *sitter.Node, nameType string, sourceCode []byte) string {
+	nameNode := FindNamedChildByType(node, nameType)
+	if nameNode == nil {
+		return ""
+	}
+	return GetNodeContent(nameNode, sourceCode)
+}
+
+// rpcSignature returns the rpc declaration up to its response type, without any options body
+func (p *ProtobufExtractor) rpcSignature(node *sitter.Node, sourceCode []byte) string {
+	signature := GetNodeContent(node, sourceCode)
+	if types := FindChildrenByType(node, "message_or_enum_type"); len(types) > 0 {
+		end := int(types[len(types)-1].EndByte() - node.StartByte())
+		if idx := strings.Index(signature[end:], ")"); idx >= 0 {
+			signature = signature[:end+idx+1]
+		}
+	}
+	return strings.Join(strings.Fields(signature), " ")
+}
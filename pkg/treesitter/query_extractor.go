@@ -0,0 +1,288 @@
+package treesitter
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	sitter "github.com/madeindigio/go-tree-sitter"
+)
+
+// Captures of custom query files. A pattern captures the node of a symbol as
+// @definition.<symbol type> and its name as @name; it may capture its
+// signature as @signature. Other captures, such as those used by
+// predicates, are ignored.
+const (
+	queryFileExt           = ".scm"
+	queryOverrideSuffix    = ".override"
+	queryDefinitionCapture = "definition."
+	queryNameCapture       = "name"
+	querySignatureCapture  = "signature"
+)
+
+// customQuery is a compiled query file of a language.
+type customQuery struct {
+	path  string
+	query *sitter.Query
+	// override replaces the extractor of the language instead of adding
+	// to its symbols
+	override bool
+	types    []SymbolType
+}
+
+var (
+	customQueriesMu sync.RWMutex
+	customQueries   map[Language]*customQuery
+)
+
+// LoadQueryFiles compiles the tree-sitter query files of dir for every walker
+// created afterwards, replacing those loaded before. A file named
+// <language>.scm adds the symbols it matches to those of the built-in
+// extractor; one named <language>.override.scm replaces the extractor. It
+// returns the languages loaded; files that cannot be compiled are reported
+// in the error and skipped.
+func LoadQueryFiles(dir string) ([]Language, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	loaded := map[Language]*customQuery{}
+	var errs []error
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != queryFileExt {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		name := strings.TrimSuffix(entry.Name(), queryFileExt)
+		override := strings.HasSuffix(name, queryOverrideSuffix)
+		lang := Language(strings.TrimSuffix(name, queryOverrideSuffix))
+
+		q, err := compileQueryFile(path, lang)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", path, err))
+			continue
+		}
+		q.override = override
+		if prev := loaded[lang]; prev != nil {
+			// The override file has all the patterns of the language
+			if !override {
+				q.query.Close()
+				q = prev
+			} else {
+				prev.query.Close()
+			}
+			errs = append(errs, fmt.Errorf("%s: %s has both an additions and an override query file; only %s is used", path, lang, q.path))
+		}
+		loaded[lang] = q
+	}
+
+	customQueriesMu.Lock()
+	customQueries = loaded
+	customQueriesMu.Unlock()
+
+	langs := make([]Language, 0, len(loaded))
+	for lang := range loaded {
+		langs = append(langs, lang)
+	}
+	sort.Slice(langs, func(i, j int) bool { return langs[i] < langs[j] })
+	return langs, errors.Join(errs...)
+}
+
+// compileQueryFile compiles a query file of lang and checks its captures.
+func compileQueryFile(path string, lang Language) (*customQuery, error) {
+	grammar, ok := GetGrammar(lang)
+	if !ok {
+		return nil, fmt.Errorf("unsupported language %q", lang)
+	}
+	source, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	query, err := sitter.NewQuery(source, grammar)
+	if err != nil {
+		return nil, fmt.Errorf("invalid query: %w", err)
+	}
+
+	q := &customQuery{path: path, query: query}
+	hasName := false
+	for i := uint32(0); i < query.CaptureCount(); i++ {
+		capture := query.CaptureNameForId(i)
+		if capture == queryNameCapture {
+			hasName = true
+		}
+		if t, ok := strings.CutPrefix(capture, queryDefinitionCapture); ok {
+			if !isSymbolType(SymbolType(t)) {
+				query.Close()
+				return nil, fmt.Errorf("unknown symbol type %q in capture @%s", t, capture)
+			}
+			q.types = append(q.types, SymbolType(t))
+		}
+	}
+	if !hasName || len(q.types) == 0 {
+		query.Close()
+		return nil, fmt.Errorf("patterns must capture @%s and @%s<symbol type>", queryNameCapture, queryDefinitionCapture)
+	}
+	return q, nil
+}
+
+// isSymbolType reports whether t is one of the symbol types.
+func isSymbolType(t SymbolType) bool {
+	switch t {
+	case SymbolTypeClass, SymbolTypeStruct, SymbolTypeInterface, SymbolTypeTrait,
+		SymbolTypeMethod, SymbolTypeFunction, SymbolTypeConstructor, SymbolTypeProperty,
+		SymbolTypeField, SymbolTypeVariable, SymbolTypeConstant, SymbolTypeEnum,
+		SymbolTypeEnumMember, SymbolTypeTypeAlias, SymbolTypeNamespace, SymbolTypeModule,
+		SymbolTypePackage, SymbolTypeComponent, SymbolTypeHook, SymbolTypeTable,
+		SymbolTypeView, SymbolTypeMessage, SymbolTypeService:
+		return true
+	}
+	return false
+}
+
+// registerQueryExtractors wraps the extractors of the languages with query
+// files in QueryExtractors.
+func (w *ASTWalker) registerQueryExtractors() {
+	customQueriesMu.RLock()
+	defer customQueriesMu.RUnlock()
+	for lang, q := range customQueries {
+		var base SymbolExtractor
+		if !q.override {
+			if base = w.extractors[lang]; base == nil {
+				base = NewGenericExtractor(w.config)
+			}
+		}
+		w.RegisterExtractor(&QueryExtractor{
+			BaseExtractor: NewBaseExtractor(lang, w.config),
+			base:          base,
+			query:         q,
+		})
+	}
+}
+
+// QueryExtractor extracts the symbols matched by a query file, in addition
+// to those of the extractor it wraps unless the file overrides it.
+type QueryExtractor struct {
+	BaseExtractor
+	base  SymbolExtractor
+	query *customQuery
+}
+
+// ExtractSymbols extracts the symbols of the wrapped extractor and those
+// matched by the query. A match on the node of an extracted symbol changes
+// its type; other matches are added, nested in the innermost symbol that
+// contains them.
+func (q *QueryExtractor) ExtractSymbols(tree *sitter.Tree, sourceCode []byte, filePath string, projectID string) ([]*CodeSymbol, error) {
+	var symbols []*CodeSymbol
+	if q.base != nil {
+		var err error
+		if symbols, err = q.base.ExtractSymbols(tree, sourceCode, filePath, projectID); err != nil {
+			return nil, err
+		}
+	}
+
+	byRange := make(map[[2]int]*CodeSymbol, len(symbols))
+	for _, s := range symbols {
+		byRange[[2]int{s.StartByte, s.EndByte}] = s
+	}
+	for _, match := range q.matches(tree, sourceCode, filePath, projectID) {
+		key := [2]int{match.StartByte, match.EndByte}
+		if existing := byRange[key]; existing != nil {
+			existing.SymbolType = match.SymbolType
+			continue
+		}
+		if parent := innermostSymbol(symbols, match); parent != nil {
+			match.ParentID = &parent.ID
+			match.NamePath = q.BuildNamePath(parent.NamePath, match.Name)
+			match.ID = SymbolID(match.ProjectID, match.FilePath, match.NamePath)
+		}
+		byRange[key] = match
+		symbols = append(symbols, match)
+	}
+	return symbols, nil
+}
+
+// matches returns the symbols matched by the query, top-level ones first.
+func (q *QueryExtractor) matches(tree *sitter.Tree, sourceCode []byte, filePath string, projectID string) []*CodeSymbol {
+	cursor := sitter.NewQueryCursor()
+	defer cursor.Close()
+	cursor.Exec(q.query.query, tree.RootNode())
+
+	var symbols []*CodeSymbol
+	for {
+		match, ok := cursor.NextMatch()
+		if !ok {
+			break
+		}
+		match = cursor.FilterPredicates(match, sourceCode)
+
+		var node, nameNode, signatureNode *sitter.Node
+		var symbolType SymbolType
+		for _, c := range match.Captures {
+			capture := q.query.query.CaptureNameForId(c.Index)
+			switch {
+			case capture == queryNameCapture:
+				nameNode = c.Node
+			case capture == querySignatureCapture:
+				signatureNode = c.Node
+			case strings.HasPrefix(capture, queryDefinitionCapture):
+				node = c.Node
+				symbolType = SymbolType(strings.TrimPrefix(capture, queryDefinitionCapture))
+			}
+		}
+		if node == nil || nameNode == nil {
+			continue
+		}
+		name := GetNodeContent(nameNode, sourceCode)
+		symbol := q.CreateSymbol(node, sourceCode, symbolType, name, q.BuildNamePath("", name), filePath, projectID, nil)
+		symbol.DocString = q.ExtractDocString(node, sourceCode)
+		if signatureNode != nil {
+			symbol.Signature = GetNodeContent(signatureNode, sourceCode)
+		}
+		symbols = append(symbols, symbol)
+	}
+
+	// Parents before the symbols they contain
+	sort.SliceStable(symbols, func(i, j int) bool {
+		if symbols[i].StartByte != symbols[j].StartByte {
+			return symbols[i].StartByte < symbols[j].StartByte
+		}
+		return symbols[i].EndByte > symbols[j].EndByte
+	})
+	return symbols
+}
+
+// innermostSymbol returns the smallest of symbols containing s, or nil.
+func innermostSymbol(symbols []*CodeSymbol, s *CodeSymbol) *CodeSymbol {
+	var innermost *CodeSymbol
+	for _, candidate := range symbols {
+		if candidate.StartByte > s.StartByte || candidate.EndByte < s.EndByte ||
+			(candidate.StartByte == s.StartByte && candidate.EndByte == s.EndByte) {
+			continue
+		}
+		if innermost == nil || candidate.EndByte-candidate.StartByte < innermost.EndByte-innermost.StartByte {
+			innermost = candidate
+		}
+	}
+	return innermost
+}
+
+// GetSymbolTypes returns the symbol types of the wrapped extractor and of the
+// query.
+func (q *QueryExtractor) GetSymbolTypes() []SymbolType {
+	var types []SymbolType
+	if q.base != nil {
+		types = append(types, q.base.GetSymbolTypes()...)
+	}
+	for _, t := range q.query.types {
+		if !slices.Contains(types, t) {
+			types = append(types, t)
+		}
+	}
+	return types
+}
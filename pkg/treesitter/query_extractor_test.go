@@ -0,0 +1,162 @@
+package treesitter
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// loadQueryFiles writes query files to a temporary directory and loads them,
+// unloading them when the test ends
+func loadQueryFiles(t *testing.T, files map[string]string) ([]Language, error) {
+	t.Helper()
+	dir, empty := t.TempDir(), t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Cleanup(func() {
+		if _, err := LoadQueryFiles(empty); err != nil {
+			t.Errorf("unload query files: %v", err)
+		}
+	})
+	return LoadQueryFiles(dir)
+}
+
+func TestLoadQueryFiles(t *testing.T) {
+	langs, err := loadQueryFiles(t, map[string]string{
+		"go.scm":                  `(function_declaration name: (identifier) @name) @definition.hook`,
+		"python.override.scm":     `(function_definition name: (identifier) @name) @definition.function`,
+		"rust.scm":                `(function_item`,
+		"javascript.scm":          `(function_declaration) @definition.function`,
+		"java.scm":                `(class_declaration name: (identifier) @name) @definition.widget`,
+		"cobol.scm":               `(program) @definition.module`,
+		"notes.txt":               `not a query`,
+		"typescript.scm":          `(function_declaration name: (identifier) @name) @definition.function`,
+		"typescript.override.scm": `(class_declaration name: (type_identifier) @name) @definition.class`,
+	})
+
+	want := []Language{LanguageGo, LanguagePython, LanguageTypeScript}
+	if !reflect.DeepEqual(langs, want) {
+		t.Errorf("loaded %v, want %v", langs, want)
+	}
+	if err == nil {
+		t.Fatal("expected errors for the invalid query files")
+	}
+	for _, msg := range []string{
+		"rust.scm: invalid query",
+		"javascript.scm: patterns must capture @name",
+		`java.scm: unknown symbol type "widget"`,
+		`cobol.scm: unsupported language "cobol"`,
+		"typescript has both an additions and an override query file",
+		"typescript.override.scm is used",
+	} {
+		if !strings.Contains(err.Error(), msg) {
+			t.Errorf("error %q does not mention %q", err, msg)
+		}
+	}
+	if strings.Contains(err.Error(), "notes.txt") {
+		t.Errorf("error %q mentions a file that is not a query file", err)
+	}
+}
+
+func TestQueryExtractor(t *testing.T) {
+	if _, err := loadQueryFiles(t, map[string]string{
+		"go.scm": `((function_declaration name: (identifier) @name) @definition.hook
+ (#match? @name "^use"))
+(const_spec name: (identifier) @name) @definition.constant`,
+		"python.override.scm": `(function_definition name: (identifier) @name) @definition.function`,
+	}); err != nil {
+		t.Fatalf("load query files: %v", err)
+	}
+
+	// symbol is the part of an extracted symbol the query decides
+	type symbol struct {
+		Type     SymbolType
+		NamePath string
+		Parent   string
+	}
+	tests := []struct {
+		name   string
+		lang   Language
+		source string
+		want   []symbol
+		types  []SymbolType
+	}{
+		{
+			name: "additions",
+			lang: LanguageGo,
+			source: `package app
+
+func useStore() {}
+
+func run() {
+	const limit = 10
+}
+`,
+			want: []symbol{
+				{SymbolTypePackage, "/app", ""},
+				{SymbolTypeHook, "/useStore", ""},
+				{SymbolTypeFunction, "/run", ""},
+				{SymbolTypeConstant, "/run/limit", "/run"},
+			},
+		},
+		{
+			name: "override",
+			lang: LanguagePython,
+			source: `class Store:
+    def save(self):
+        pass
+`,
+			want: []symbol{
+				{SymbolTypeFunction, "/save", ""},
+			},
+			types: []SymbolType{SymbolTypeFunction},
+		},
+	}
+
+	walker := NewASTWalker(DefaultWalkerConfig())
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tree := parseSample(t, tt.lang, tt.source)
+			symbols, err := walker.ExtractSymbols(tree, []byte(tt.source), tt.lang, "sample", "test")
+			if err != nil {
+				t.Fatalf("extract symbols: %v", err)
+			}
+
+			namePaths := make(map[string]string, len(symbols))
+			for _, s := range symbols {
+				namePaths[s.ID] = s.NamePath
+			}
+			var got []symbol
+			for _, s := range symbols {
+				if s.ID != SymbolID("test", "sample", s.NamePath) {
+					t.Errorf("%s has ID %q, want the one of its name path", s.NamePath, s.ID)
+				}
+				sym := symbol{Type: s.SymbolType, NamePath: s.NamePath}
+				if s.ParentID != nil {
+					sym.Parent = namePaths[*s.ParentID]
+				}
+				got = append(got, sym)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("symbols =\n%+v\nwant\n%+v", got, tt.want)
+			}
+
+			extractor, _ := walker.GetExtractor(tt.lang)
+			types := extractor.GetSymbolTypes()
+			if tt.types != nil && !reflect.DeepEqual(types, tt.types) {
+				t.Errorf("GetSymbolTypes = %v, want %v", types, tt.types)
+			}
+			for _, s := range got {
+				if !slices.Contains(types, s.Type) {
+					t.Errorf("GetSymbolTypes = %v, missing %s", types, s.Type)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+// Package treesitter provides React component, hook and props detection for JSX/TSX sources.
+package treesitter
+
+import (
+	"strings"
+	"unicode"
+
+	sitter "github.com/madeindigio/go-tree-sitter"
+)
+
+// jsxNodeTypes are the node types that show a function renders JSX
+var jsxNodeTypes = map[string]bool{
+	"jsx_element":              true,
+	"jsx_self_closing_element": true,
+	"jsx_fragment":             true,
+}
+
+// reactFunctionType classifies a named function. Capitalized functions that
+// render JSX are components and useXxx functions that call other hooks are
+// custom hooks; anything else keeps the fallback type.
+func reactFunctionType(name string, fn *sitter.Node, sourceCode []byte, fallback SymbolType) SymbolType {
+	switch {
+	case isComponentName(name) && containsJSX(fn):
+		return SymbolTypeComponent
+	case isHookName(name) && callsHook(fn, sourceCode):
+		return SymbolTypeHook
+	}
+	return fallback
+}
+
+// reactWrappedComponent reports whether a call such as memo(...) or
+// forwardRef(...) assigned to name wraps a component.
+func reactWrappedComponent(name string, call *sitter.Node) bool {
+	return isComponentName(name) && containsJSX(call)
+}
+
+// componentMetadata records the props type of a component, found as the first
+// *Props type referenced by its parameters or type annotation.
+func componentMetadata(node *sitter.Node, sourceCode []byte) map[string]interface{} {
+	it := NewNodeIterator(node)
+	for n := it.Next(); n != nil; n = it.Next() {
+		if n.Type() != "type_identifier" {
+			continue
+		}
+		if name := GetNodeContent(n, sourceCode); isPropsName(name) {
+			return map[string]interface{}{"props": name}
+		}
+	}
+	return nil
+}
+
+// propsMetadata marks a *Props interface or type alias and links it to the
+// component it describes (ButtonProps -> Button).
+func propsMetadata(name string) map[string]interface{} {
+	if !isPropsName(name) {
+		return nil
+	}
+	metadata := map[string]interface{}{"kind": "props"}
+	if component := strings.TrimSuffix(name, "Props"); component != "" {
+		metadata["props_for"] = component
+	}
+	return metadata
+}
+
+func isComponentName(name string) bool {
+	return name != "" && unicode.IsUpper(rune(name[0]))
+}
+
+func isHookName(name string) bool {
+	return len(name) > 3 && strings.HasPrefix(name, "use") && unicode.IsUpper(rune(name[3]))
+}
+
+func isPropsName(name string) bool {
+	return strings.HasSuffix(name, "Props")
+}
+
+// containsJSX reports whether any node under node is a JSX element or fragment.
+func containsJSX(node *sitter.Node) bool {
+	it := NewNodeIterator(node)
+	for n := it.Next(); n != nil; n = it.Next() {
+		if jsxNodeTypes[n.Type()] {
+			return true
+		}
+	}
+	return false
+}
+
+// callsHook reports whether node calls use(), useXxx() or obj.useXxx().
+func callsHook(node *sitter.Node, sourceCode []byte) bool {
+	it := NewNodeIterator(node)
+	for n := it.Next(); n != nil; n = it.Next() {
+		if n.Type() != "call_expression" {
+			continue
+		}
+		callee := n.ChildByFieldName("function")
+		if callee == nil {
+			continue
+		}
+		if callee.Type() == "member_expression" {
+			callee = callee.ChildByFieldName("property")
+			if callee == nil {
+				continue
+			}
+		}
+		if name := GetNodeContent(callee, sourceCode); name == "use" || isHookName(name) {
+			return true
+		}
+	}
+	return false
+}
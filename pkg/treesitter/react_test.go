@@ -0,0 +1,102 @@
+package treesitter
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestReactSymbols(t *testing.T) {
+	// symbol is the part of an extracted symbol React detection decides
+	type symbol struct {
+		Type     SymbolType
+		Metadata map[string]interface{}
+	}
+	tests := []struct {
+		name   string
+		lang   Language
+		source string
+		want   map[string]symbol
+	}{
+		{
+			name: "tsx",
+			lang: LanguageTSX,
+			source: `interface ButtonProps {
+  label: string;
+}
+
+export function Button({ label }: ButtonProps) {
+  return <button>{label}</button>;
+}
+
+type CardProps = { title: string };
+
+const Card = ({ title }: CardProps) => <div>{title}</div>;
+
+const Badge = memo(() => <span />);
+
+function useCounter() {
+  const [count, setCount] = useState(0);
+  return count;
+}
+
+function useless() {
+  return 1;
+}
+
+function formatLabel(label: string) {
+  return label.trim();
+}
+`,
+			want: map[string]symbol{
+				"/ButtonProps": {SymbolTypeInterface, map[string]interface{}{"kind": "props", "props_for": "Button"}},
+				"/Button":      {SymbolTypeComponent, map[string]interface{}{"props": "ButtonProps"}},
+				"/CardProps":   {SymbolTypeTypeAlias, map[string]interface{}{"kind": "props", "props_for": "Card"}},
+				"/Card":        {SymbolTypeComponent, map[string]interface{}{"props": "CardProps"}},
+				"/Badge":       {SymbolTypeComponent, nil},
+				"/useCounter":  {SymbolTypeHook, nil},
+				"/useless":     {SymbolTypeFunction, nil},
+				"/formatLabel": {SymbolTypeFunction, nil},
+			},
+		},
+		{
+			name: "jsx",
+			lang: LanguageJavaScript,
+			source: `function App() {
+  return <main />;
+}
+
+const useTheme = () => useContext(ThemeContext);
+
+function render() {
+  return null;
+}
+`,
+			want: map[string]symbol{
+				"/App":      {SymbolTypeComponent, nil},
+				"/useTheme": {SymbolTypeHook, nil},
+				"/render":   {SymbolTypeFunction, nil},
+			},
+		},
+	}
+
+	walker := NewASTWalker(DefaultWalkerConfig())
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tree := parseSample(t, tt.lang, tt.source)
+			symbols, err := walker.ExtractSymbols(tree, []byte(tt.source), tt.lang, "sample", "test")
+			if err != nil {
+				t.Fatalf("extract symbols: %v", err)
+			}
+
+			got := make(map[string]symbol, len(symbols))
+			for _, s := range symbols {
+				got[s.NamePath] = symbol{s.SymbolType, s.Metadata}
+			}
+			for namePath, want := range tt.want {
+				if sym, ok := got[namePath]; !ok || !reflect.DeepEqual(sym, want) {
+					t.Errorf("%s = %+v (found %v), want %+v", namePath, sym, ok, want)
+				}
+			}
+		})
+	}
+}
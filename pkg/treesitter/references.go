@@ -0,0 +1,207 @@
+// Package treesitter provides identifier reference matching.
+package treesitter
+
+import (
+	"strings"
+
+	sitter "github.com/madeindigio/go-tree-sitter"
+)
+
+// ReferenceKind classifies how an identifier is used
+type ReferenceKind string
+
+const (
+	ReferenceKindDeclaration ReferenceKind = "declaration"
+	ReferenceKindCall        ReferenceKind = "call"
+	ReferenceKindType        ReferenceKind = "type"
+	ReferenceKindReference   ReferenceKind = "reference"
+)
+
+// IdentifierReference is an occurrence of an identifier in a syntax tree.
+// Comments and string literals never produce occurrences.
+type IdentifierReference struct {
+	// Line and Column of the identifier (1-based)
+	Line   int `json:"line"`
+	Column int `json:"column"`
+
+	// StartByte of the identifier in the source
+	StartByte int `json:"start_byte"`
+
+	// Kind of use: declaration, call, type or plain reference
+	Kind ReferenceKind `json:"kind"`
+
+	// Qualified is set when the identifier is accessed through a receiver,
+	// package or namespace (e.g. "pkg.Name", "obj.name", "Type::name"),
+	// so local declarations cannot shadow it
+	Qualified bool `json:"qualified"`
+}
+
+// accessFields are the fields that hold the accessed member of a qualified expression
+var accessFields = []string{"field", "property", "attribute", "name"}
+
+// FindIdentifierReferences returns the occurrences of the identifier name in
+// a parsed file, in source order
+func FindIdentifierReferences(tree *sitter.Tree, sourceCode []byte, name string) []IdentifierReference {
+	var refs []IdentifierReference
+
+	it := NewNodeIterator(tree.RootNode())
+	for node := it.Next(); node != nil; node = it.Next() {
+		if node.NamedChildCount() > 0 || !isIdentifierNode(node) || GetNodeContent(node, sourceCode) != name {
+			continue
+		}
+
+		ref := IdentifierReference{
+			Line:      int(node.StartPoint().Row) + 1,
+			Column:    int(node.StartPoint().Column) + 1,
+			StartByte: int(node.StartByte()),
+			Qualified: isQualifiedAccess(node),
+		}
+
+		switch {
+		case isDeclaration(node):
+			ref.Kind = ReferenceKindDeclaration
+		case isCallee(node):
+			ref.Kind = ReferenceKindCall
+		case strings.Contains(node.Type(), "type") || (node.Parent() != nil && strings.Contains(node.Parent().Type(), "type")):
+			ref.Kind = ReferenceKindType
+		default:
+			ref.Kind = ReferenceKindReference
+		}
+
+		refs = append(refs, ref)
+	}
+
+	return refs
+}
+
+// isIdentifierNode reports whether a leaf node is an identifier in any grammar
+func isIdentifierNode(node *sitter.Node) bool {
+	nodeType := node.Type()
+	if strings.Contains(nodeType, "identifier") {
+		return true
+	}
+	switch nodeType {
+	case "name", "constant", "word":
+		// PHP names, Ruby constants, Bash words
+		return true
+	}
+	return false
+}
+
+// isQualifiedAccess reports whether node is the member of a selector,
+// member access or scoped path rather than its receiver
+func isQualifiedAccess(node *sitter.Node) bool {
+	parent := node.Parent()
+	if parent == nil || isDeclarationParent(parent) {
+		return false
+	}
+	if _, isCall := callNodeTypes[parent.Type()]; isCall {
+		// Java and PHP method calls hold the method in a "name" field
+		return parent.ChildByFieldName("object") != nil || parent.ChildByFieldName("scope") != nil
+	}
+	for _, field := range accessFields {
+		if fieldIs(parent, field, node) {
+			return true
+		}
+	}
+	return false
+}
+
+// isDeclaration reports whether node is the name being declared by a
+// definition, parameter or variable binding
+func isDeclaration(node *sitter.Node) bool {
+	parent := node.Parent()
+	if parent == nil {
+		return false
+	}
+
+	// Parameter lists hold the parameter names directly (Python, JavaScript);
+	// typed parameters hold them in a field, next to type identifiers
+	if strings.Contains(parent.Type(), "parameter") && !strings.Contains(node.Type(), "type") {
+		if parent.ChildByFieldName("type") == nil || fieldIs(parent, "name", node) || fieldIs(parent, "pattern", node) {
+			return true
+		}
+	}
+
+	if isDeclarationParent(parent) {
+		for _, field := range []string{"name", "pattern", "left"} {
+			if fieldIs(parent, field, node) {
+				return true
+			}
+		}
+	}
+
+	// Go `a, b := f()` and Python `a, b = f()` bind every name on the left
+	if strings.Contains(parent.Type(), "list") {
+		if grand := parent.Parent(); grand != nil && isDeclarationParent(grand) && fieldIs(grand, "left", parent) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isDeclarationParent reports whether nodes of the type declare or bind the
+// name they hold rather than use it
+func isDeclarationParent(node *sitter.Node) bool {
+	nodeType := node.Type()
+	if _, isCall := callNodeTypes[nodeType]; isCall {
+		return false
+	}
+	// Imports refer to a declaration made elsewhere
+	for _, use := range []string{"expression", "access", "scoped", "qualified", "selector", "navigation", "attribute", "import"} {
+		if strings.Contains(nodeType, use) {
+			return false
+		}
+	}
+	switch nodeType {
+	case "short_var_declaration", "assignment", "augmented_assignment":
+		return true
+	}
+	return strings.Contains(nodeType, "declaration") ||
+		strings.Contains(nodeType, "definition") ||
+		strings.Contains(nodeType, "declarator") ||
+		strings.Contains(nodeType, "spec") ||
+		strings.Contains(nodeType, "item") ||
+		strings.Contains(nodeType, "binding") ||
+		strings.HasSuffix(nodeType, "_statement") ||
+		nodeType == "method" || nodeType == "class" || nodeType == "module" ||
+		nodeType == "signature"
+}
+
+// isCallee reports whether node names the function of a call, directly or
+// as the member of a qualified callee
+func isCallee(node *sitter.Node) bool {
+	target := node
+	if isQualifiedAccess(node) {
+		if _, isCall := callNodeTypes[node.Parent().Type()]; !isCall {
+			target = node.Parent()
+		}
+	}
+
+	call := target.Parent()
+	if call == nil {
+		return false
+	}
+	fields, ok := callNodeTypes[call.Type()]
+	if !ok {
+		return false
+	}
+	for _, field := range fields {
+		if child := call.ChildByFieldName(field); child != nil {
+			return sameNode(child, target)
+		}
+	}
+	return call.NamedChildCount() > 1 && sameNode(call.NamedChild(0), target)
+}
+
+// fieldIs reports whether node is held in the given field of parent
+func fieldIs(parent *sitter.Node, field string, node *sitter.Node) bool {
+	child := parent.ChildByFieldName(field)
+	return child != nil && sameNode(child, node)
+}
+
+// sameNode reports whether two nodes cover the same source range and type
+func sameNode(a, b *sitter.Node) bool {
+	return a.StartByte() == b.StartByte() && a.EndByte() == b.EndByte() && a.Type() == b.Type()
+}
@@ -0,0 +1,98 @@
+package treesitter
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFindIdentifierReferences(t *testing.T) {
+	goSource := `package app
+
+// Save stores "Save" items.
+func Save(item Item) error {
+	store.Save(item)
+	return Save(item)
+}
+`
+	pythonSource := `a, b = load()
+print(a)
+obj.a = 1
+`
+
+	// ref is a reference without its start byte, which follows from the line and column
+	type ref struct {
+		Line, Column int
+		Kind         ReferenceKind
+		Qualified    bool
+	}
+	tests := []struct {
+		name   string
+		lang   Language
+		source string
+		ident  string
+		want   []ref
+	}{
+		{
+			name:   "go function",
+			lang:   LanguageGo,
+			source: goSource,
+			ident:  "Save",
+			want: []ref{
+				{4, 6, ReferenceKindDeclaration, false},
+				{5, 8, ReferenceKindCall, true},
+				{6, 9, ReferenceKindCall, false},
+			},
+		},
+		{
+			name:   "go parameter",
+			lang:   LanguageGo,
+			source: goSource,
+			ident:  "item",
+			want: []ref{
+				{4, 11, ReferenceKindDeclaration, false},
+				{5, 13, ReferenceKindReference, false},
+				{6, 14, ReferenceKindReference, false},
+			},
+		},
+		{
+			name:   "go type",
+			lang:   LanguageGo,
+			source: goSource,
+			ident:  "Item",
+			want: []ref{
+				{4, 16, ReferenceKindType, false},
+			},
+		},
+		{
+			name:   "python bindings",
+			lang:   LanguagePython,
+			source: pythonSource,
+			ident:  "a",
+			want: []ref{
+				{1, 1, ReferenceKindDeclaration, false},
+				{2, 7, ReferenceKindReference, false},
+				{3, 5, ReferenceKindReference, true},
+			},
+		},
+		{
+			name:   "no occurrence",
+			lang:   LanguageGo,
+			source: goSource,
+			ident:  "items",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tree := parseSample(t, tt.lang, tt.source)
+
+			var got []ref
+			for _, r := range FindIdentifierReferences(tree, []byte(tt.source), tt.ident) {
+				got = append(got, ref{r.Line, r.Column, r.Kind, r.Qualified})
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FindIdentifierReferences(%q) =\n%+v\nwant\n%+v", tt.ident, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package treesitter
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSchemaSymbols(t *testing.T) {
+	// symbol is the part of an extracted symbol the schema extractors decide
+	type symbol struct {
+		Type     SymbolType
+		Parent   string
+		Metadata map[string]interface{}
+	}
+	tests := []struct {
+		name   string
+		lang   Language
+		source string
+		want   map[string]symbol
+	}{
+		{
+			name: "sql",
+			lang: LanguageSQL,
+			source: `-- Registered users
+CREATE TABLE app.users (
+  id INT PRIMARY KEY,
+  email TEXT NOT NULL
+);
+
+CREATE VIEW active_users AS SELECT id FROM app.users;
+
+CREATE MATERIALIZED VIEW user_counts AS SELECT count(*) FROM app.users;
+
+INSERT INTO app.users (id, email) VALUES (1, 'ada@example.com');
+`,
+			want: map[string]symbol{
+				"/users":        {SymbolTypeTable, "", map[string]interface{}{"schema": "app"}},
+				"/users/id":     {SymbolTypeField, "/users", nil},
+				"/users/email":  {SymbolTypeField, "/users", nil},
+				"/active_users": {SymbolTypeView, "", nil},
+				"/user_counts":  {SymbolTypeView, "", map[string]interface{}{"materialized": true}},
+			},
+		},
+		{
+			name: "protobuf",
+			lang: LanguageProtobuf,
+			source: `syntax = "proto3";
+
+package shop.v1;
+
+message Order {
+  string id = 1;
+  map<string, int32> quantities = 2;
+  oneof payment {
+    string card = 3;
+    string voucher = 4;
+  }
+  enum Status {
+    PENDING = 0;
+    PAID = 1;
+  }
+}
+
+service Orders {
+  rpc GetOrder(GetOrderRequest) returns (Order);
+}
+`,
+			want: map[string]symbol{
+				"/shop.v1":              {SymbolTypePackage, "", nil},
+				"/Order":                {SymbolTypeMessage, "", nil},
+				"/Order/id":             {SymbolTypeField, "/Order", nil},
+				"/Order/quantities":     {SymbolTypeField, "/Order", nil},
+				"/Order/card":           {SymbolTypeField, "/Order", map[string]interface{}{"oneof": "payment"}},
+				"/Order/voucher":        {SymbolTypeField, "/Order", map[string]interface{}{"oneof": "payment"}},
+				"/Order/Status":         {SymbolTypeEnum, "/Order", nil},
+				"/Order/Status/PENDING": {SymbolTypeEnumMember, "/Order/Status", nil},
+				"/Order/Status/PAID":    {SymbolTypeEnumMember, "/Order/Status", nil},
+				"/Orders":               {SymbolTypeService, "", nil},
+				"/Orders/GetOrder":      {SymbolTypeMethod, "/Orders", map[string]interface{}{"request": "GetOrderRequest", "response": "Order"}},
+			},
+		},
+	}
+
+	walker := NewASTWalker(DefaultWalkerConfig())
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tree := parseSample(t, tt.lang, tt.source)
+			symbols, err := walker.ExtractSymbols(tree, []byte(tt.source), tt.lang, "sample", "test")
+			if err != nil {
+				t.Fatalf("extract symbols: %v", err)
+			}
+
+			namePaths := make(map[string]string, len(symbols))
+			for _, s := range symbols {
+				namePaths[s.ID] = s.NamePath
+			}
+			got := make(map[string]symbol, len(symbols))
+			for _, s := range symbols {
+				sym := symbol{Type: s.SymbolType, Metadata: s.Metadata}
+				if s.ParentID != nil {
+					sym.Parent = namePaths[*s.ParentID]
+				}
+				got[s.NamePath] = sym
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("symbols =\n%+v\nwant\n%+v", got, tt.want)
+			}
+
+			for _, s := range symbols {
+				if s.NamePath == "/Orders/GetOrder" && s.Signature != "rpc GetOrder(GetOrderRequest) returns (Order)" {
+					t.Errorf("GetOrder signature = %q", s.Signature)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+// Package treesitter provides SQL schema symbol extraction.
+package treesitter
+
+import (
+	sitter "github.com/madeindigio/go-tree-sitter"
+)
+
+// SQLExtractor extracts table and view definitions from SQL source code
+type SQLExtractor struct {
+	BaseExtractor
+}
+
+// NewSQLExtractor creates a new SQL extractor
+func NewSQLExtractor(config WalkerConfig) *SQLExtractor {
+	return &SQLExtractor{
+		BaseExtractor: NewBaseExtractor(LanguageSQL, config),
+	}
+}
+
+// GetSymbolTypes returns the types of symbols the SQL extractor can find
+func (q *SQLExtractor) GetSymbolTypes() []SymbolType {
+	return []SymbolType{
+		SymbolTypeTable,
+		SymbolTypeView,
+		SymbolTypeField,
+	}
+}
+
+// ExtractSymbols extracts CREATE TABLE and CREATE VIEW statements
+func (q *SQLExtractor) ExtractSymbols(tree *sitter.Tree, sourceCode []byte, filePath string, projectID string) ([]*CodeSymbol, error) {
+	var symbols []*CodeSymbol
+	root := tree.RootNode()
+
+	for i := 0; i < int(root.NamedChildCount()); i++ {
+		statement := root.NamedChild(i)
+		if statement == nil || statement.Type() != "statement" {
+			continue
+		}
+
+		for j := 0; j < int(statement.NamedChildCount()); j++ {
+			child := statement.NamedChild(j)
+			if child == nil {
+				continue
+			}
+
+			switch child.Type() {
+			case "create_table":
+				symbols = append(symbols, q.extractTable(child, statement, sourceCode, filePath, projectID)...)
+
+			case "create_view", "create_materialized_view":
+				if symbol := q.extractView(child, statement, sourceCode, filePath, projectID); symbol != nil {
+					symbols = append(symbols, symbol)
+				}
+			}
+		}
+	}
+
+	return symbols, nil
+}
+
+// extractTable extracts a table and its columns
+func (q *SQLExtractor) extractTable(node *sitter.Node, statement *sitter.Node, sourceCode []byte, filePath string, projectID string) []*CodeSymbol {
+	var symbols []*CodeSymbol
+
+	name, metadata := q.objectName(node, sourceCode)
+	if name == "" {
+		return symbols
+	}
+	namePath := q.BuildNamePath("", name)
+
+	symbol := q.CreateSymbol(node, sourceCode, SymbolTypeTable, name, namePath, filePath, projectID, nil)
+	symbol.DocString = q.ExtractDocString(statement, sourceCode)
+	symbol.Metadata = metadata
+	symbols = append(symbols, symbol)
+
+	columns := FindNamedChildByType(node, "column_definitions")
+	if columns == nil {
+		return symbols
+	}
+
+	for _, column := range FindChildrenByType(columns, "column_definition") {
+		nameNode := column.ChildByFieldName("name")
+		if nameNode == nil {
+			continue
+		}
+
+		columnName := GetNodeContent(nameNode, sourceCode)
+		columnSymbol := q.CreateSymbol(column, sourceCode, SymbolTypeField, columnName, q.BuildNamePath(namePath, columnName), filePath, projectID, &symbol.ID)
+		columnSymbol.Signature = GetNodeContent(column, sourceCode)
+		symbol.Children = append(symbol.Children, columnSymbol)
+		symbols = append(symbols, columnSymbol)
+	}
+
+	return symbols
+}
+
+// extractView extracts a view or materialized view
+func (q *SQLExtractor) extractView(node *sitter.Node, statement *sitter.Node, sourceCode []byte, filePath string, projectID string) *CodeSymbol {
+	name, metadata := q.objectName(node, sourceCode)
+	if name == "" {
+		return nil
+	}
+
+	symbol := q.CreateSymbol(node, sourceCode, SymbolTypeView, name, q.BuildNamePath("", name), filePath, projectID, nil)
+	symbol.DocString = q.ExtractDocString(statement, sourceCode)
+	if node.Type() == "create_materialized_view" {
+		if metadata == nil {
+			metadata = map[string]interface{}{}
+		}
+		metadata["materialized"] = true
+	}
+	symbol.Metadata = metadata
+
+	return symbol
+}
+
+// objectName returns the name of the object a CREATE statement defines, with
+// its schema in the metadata when it is qualified
+func (q *SQLExtractor) objectName(node *sitter.Node, sourceCode []byte) (string, map[string]interface{}) {
+	ref := FindNamedChildByType(node, "object_reference")
+	if ref == nil {
+		return "", nil
+	}
+
+	nameNode := ref.ChildByFieldName("name")
+	if nameNode == nil {
+		return "", nil
+	}
+
+	var metadata map[string]interface{}
+	if schema := ref.ChildByFieldName("schema"); schema != nil {
+		metadata = map[string]interface{}{"schema": GetNodeContent(schema, sourceCode)}
+	}
+
+	return GetNodeContent(nameNode, sourceCode), metadata
+}
@@ -2,207 +2,213 @@
 package treesitter
 
 import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
 	sitter "github.com/madeindigio/go-tree-sitter"
 )
 
-// SvelteExtractor extracts symbols from Svelte source code
+// SvelteExtractor extracts symbols from Svelte source code. The Svelte grammar
+// leaves <script> contents as raw text, so each script is parsed again with the
+// JavaScript or TypeScript grammar and handed to the matching extractor.
 type SvelteExtractor struct {
 	BaseExtractor
+	typescript *TypeScriptExtractor
+	javascript *JavaScriptExtractor
 }
 
 // NewSvelteExtractor creates a new Svelte extractor
 func NewSvelteExtractor(config WalkerConfig) *SvelteExtractor {
 	return &SvelteExtractor{
 		BaseExtractor: NewBaseExtractor(LanguageSvelte, config),
+		typescript:    NewTypeScriptExtractor(config),
+		javascript:    NewJavaScriptExtractor(config),
 	}
 }
 
 // GetSymbolTypes returns the types of symbols the Svelte extractor can find
 func (s *SvelteExtractor) GetSymbolTypes() []SymbolType {
 	return []SymbolType{
+		SymbolTypeComponent,
+		SymbolTypeProperty,
 		SymbolTypeClass,
+		SymbolTypeInterface,
 		SymbolTypeFunction,
 		SymbolTypeMethod,
 		SymbolTypeVariable,
 		SymbolTypeConstant,
+		SymbolTypeTypeAlias,
 	}
 }
 
-// ExtractSymbols extracts all symbols from Svelte source code
+// ExtractSymbols extracts the component itself, its props and the symbols
+// declared in its <script> sections
 func (s *SvelteExtractor) ExtractSymbols(tree *sitter.Tree, sourceCode []byte, filePath string, projectID string) ([]*CodeSymbol, error) {
-	var symbols []*CodeSymbol
 	root := tree.RootNode()
 
-	// Svelte files have <script>, <template>, and <style> sections
-	// We focus on extracting symbols from <script> sections
+	// Each .svelte file is one component, named after the file
+	name := strings.TrimSuffix(filepath.Base(filePath), filepath.Ext(filePath))
+	component := s.CreateSymbol(root, sourceCode, SymbolTypeComponent, name, s.BuildNamePath("", name), filePath, projectID, nil)
+	symbols := []*CodeSymbol{component}
+
 	for i := 0; i < int(root.NamedChildCount()); i++ {
 		child := root.NamedChild(i)
-		if child == nil {
+		if child == nil || child.Type() != "script_element" {
 			continue
 		}
 
-		// Look for script elements
-		if child.Type() == "script_element" {
-			childSymbols := s.extractScriptElement(child, sourceCode, filePath, projectID)
-			symbols = append(symbols, childSymbols...)
+		childSymbols, err := s.extractScriptElement(child, sourceCode, filePath, projectID, component)
+		if err != nil {
+			return nil, err
 		}
+		symbols = append(symbols, childSymbols...)
 	}
 
 	return symbols, nil
 }
 
-// extractScriptElement extracts symbols from a Svelte <script> section
-func (s *SvelteExtractor) extractScriptElement(node *sitter.Node, sourceCode []byte, filePath string, projectID string) []*CodeSymbol {
-	var symbols []*CodeSymbol
-
-	// Find the script content
-	for i := 0; i < int(node.NamedChildCount()); i++ {
-		child := node.NamedChild(i)
-		if child == nil {
-			continue
-		}
-
-		// Process child nodes (JavaScript/TypeScript code)
-		childSymbols := s.extractNode(child, sourceCode, filePath, projectID, "", nil)
-		symbols = append(symbols, childSymbols...)
+// extractScriptElement parses a <script> section and extracts its symbols
+func (s *SvelteExtractor) extractScriptElement(node *sitter.Node, sourceCode []byte, filePath string, projectID string, component *CodeSymbol) ([]*CodeSymbol, error) {
+	content := FindNamedChildByType(node, "raw_text")
+	if content == nil {
+		return nil, nil
 	}
 
-	return symbols
-}
-
-// extractNode extracts symbols from a node
-func (s *SvelteExtractor) extractNode(node *sitter.Node, sourceCode []byte, filePath string, projectID string, parentPath string, parentID *string) []*CodeSymbol {
-	var symbols []*CodeSymbol
-
-	switch node.Type() {
-	case "class_declaration":
-		symbols = append(symbols, s.extractClass(node, sourceCode, filePath, projectID, parentPath, parentID)...)
-
-	case "function_declaration":
-		if symbol := s.extractFunction(node, sourceCode, filePath, projectID, parentPath, parentID); symbol != nil {
-			symbols = append(symbols, symbol)
-		}
-
-	case "lexical_declaration", "variable_declaration":
-		symbols = append(symbols, s.extractVariableDeclaration(node, sourceCode, filePath, projectID, parentPath, parentID)...)
-
-	case "export_statement":
-		declaration := node.ChildByFieldName("declaration")
-		if declaration != nil {
-			symbols = append(symbols, s.extractNode(declaration, sourceCode, filePath, projectID, parentPath, parentID)...)
-		}
+	startTag := GetNodeContent(node, sourceCode)
+	if tag := FindNamedChildByType(node, "start_tag"); tag != nil {
+		startTag = GetNodeContent(tag, sourceCode)
 	}
 
-	return symbols
-}
-
-// extractClass extracts a class declaration
-func (s *SvelteExtractor) extractClass(node *sitter.Node, sourceCode []byte, filePath string, projectID string, parentPath string, parentID *string) []*CodeSymbol {
-	var symbols []*CodeSymbol
-
-	nameNode := node.ChildByFieldName("name")
-	if nameNode == nil {
-		return symbols
+	var extractor SymbolExtractor = s.javascript
+	if strings.Contains(startTag, `lang="ts"`) || strings.Contains(startTag, `lang='ts'`) || strings.Contains(startTag, "typescript") {
+		extractor = s.typescript
 	}
 
-	name := GetNodeContent(nameNode, sourceCode)
-	namePath := s.BuildNamePath(parentPath, name)
-
-	symbol := s.CreateSymbol(node, sourceCode, SymbolTypeClass, name, namePath, filePath, projectID, parentID)
-	symbol.DocString = s.ExtractDocString(node, sourceCode)
-	symbols = append(symbols, symbol)
-
-	// Extract class body members
-	body := node.ChildByFieldName("body")
-	if body != nil {
-		for i := 0; i < int(body.NamedChildCount()); i++ {
-			member := body.NamedChild(i)
-			if member == nil {
-				continue
-			}
-
-			if member.Type() == "method_definition" {
-				memberSymbols := s.extractMethod(member, sourceCode, filePath, projectID, namePath, &symbol.ID)
-				for _, ms := range memberSymbols {
-					symbol.Children = append(symbol.Children, ms)
-				}
-				symbols = append(symbols, memberSymbols...)
-			}
-		}
+	tree, err := s.parseScript(content, sourceCode, extractor.Language())
+	if err != nil {
+		return nil, err
 	}
 
-	return symbols
-}
-
-// extractMethod extracts a method from a class
-func (s *SvelteExtractor) extractMethod(node *sitter.Node, sourceCode []byte, filePath string, projectID string, parentPath string, parentID *string) []*CodeSymbol {
-	var symbols []*CodeSymbol
-
-	nameNode := node.ChildByFieldName("name")
-	if nameNode == nil {
-		return symbols
+	symbols, err := extractor.ExtractSymbols(tree, sourceCode, filePath, projectID)
+	if err != nil {
+		return nil, err
+	}
+	for _, symbol := range symbols {
+		symbol.Language = LanguageSvelte
 	}
 
-	name := GetNodeContent(nameNode, sourceCode)
-	namePath := s.BuildNamePath(parentPath, name)
-
-	symbol := s.CreateSymbol(node, sourceCode, SymbolTypeMethod, name, namePath, filePath, projectID, parentID)
-	symbol.DocString = s.ExtractDocString(node, sourceCode)
-	symbols = append(symbols, symbol)
+	// Module scripts are shared by every instance and declare no props
+	if strings.Contains(startTag, "context=") || strings.Contains(startTag, " module") {
+		return symbols, nil
+	}
 
-	return symbols
+	return s.extractProps(tree.RootNode(), symbols, sourceCode, filePath, projectID, component), nil
 }
 
-// extractFunction extracts a function declaration
-func (s *SvelteExtractor) extractFunction(node *sitter.Node, sourceCode []byte, filePath string, projectID string, parentPath string, parentID *string) *CodeSymbol {
-	nameNode := node.ChildByFieldName("name")
-	if nameNode == nil {
-		return nil
+// parseScript parses the script contents in place, so positions in the
+// resulting tree refer to the .svelte file
+func (s *SvelteExtractor) parseScript(content *sitter.Node, sourceCode []byte, lang Language) (*sitter.Tree, error) {
+	grammar, ok := GetGrammar(lang)
+	if !ok {
+		return nil, fmt.Errorf("unsupported language: %s", lang)
 	}
 
-	name := GetNodeContent(nameNode, sourceCode)
-	namePath := s.BuildNamePath(parentPath, name)
-
-	symbol := s.CreateSymbol(node, sourceCode, SymbolTypeFunction, name, namePath, filePath, projectID, parentID)
-	symbol.DocString = s.ExtractDocString(node, sourceCode)
-
-	return symbol
+	parser := sitter.NewParser()
+	defer parser.Close()
+	parser.SetLanguage(grammar)
+	parser.SetIncludedRanges([]sitter.Range{{
+		StartPoint: content.StartPoint(),
+		EndPoint:   content.EndPoint(),
+		StartByte:  content.StartByte(),
+		EndByte:    content.EndByte(),
+	}})
+
+	tree, err := parser.ParseCtx(context.Background(), nil, sourceCode)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse svelte script: %w", err)
+	}
+	return tree, nil
 }
 
-// extractVariableDeclaration extracts variable declarations
-func (s *SvelteExtractor) extractVariableDeclaration(node *sitter.Node, sourceCode []byte, filePath string, projectID string, parentPath string, parentID *string) []*CodeSymbol {
-	var symbols []*CodeSymbol
+// extractProps turns the component's props into property symbols under the
+// component. Svelte 4 declares them with `export let`, Svelte 5 by
+// destructuring $props(). The plain variable symbols for those declarations
+// are replaced.
+func (s *SvelteExtractor) extractProps(root *sitter.Node, symbols []*CodeSymbol, sourceCode []byte, filePath string, projectID string, component *CodeSymbol) []*CodeSymbol {
+	replaced := map[int]bool{}
+	var props []*CodeSymbol
+
+	addProp := func(node *sitter.Node, name string, docNode *sitter.Node) {
+		prop := s.CreateSymbol(node, sourceCode, SymbolTypeProperty, name, s.BuildNamePath(component.NamePath, name), filePath, projectID, &component.ID)
+		prop.DocString = s.ExtractDocString(docNode, sourceCode)
+		component.Children = append(component.Children, prop)
+		props = append(props, prop)
+	}
 
-	for i := 0; i < int(node.NamedChildCount()); i++ {
-		child := node.NamedChild(i)
-		if child == nil || child.Type() != "variable_declarator" {
+	for i := 0; i < int(root.NamedChildCount()); i++ {
+		statement := root.NamedChild(i)
+		if statement == nil {
 			continue
 		}
 
-		nameNode := child.ChildByFieldName("name")
-		if nameNode == nil {
+		declaration := statement
+		exported := statement.Type() == "export_statement"
+		if exported {
+			declaration = statement.ChildByFieldName("declaration")
+		}
+		if declaration == nil || (declaration.Type() != "lexical_declaration" && declaration.Type() != "variable_declaration") {
 			continue
 		}
+		if exported && strings.HasPrefix(GetNodeContent(declaration, sourceCode), "const") {
+			continue
+		}
+
+		for _, declarator := range FindChildrenByType(declaration, "variable_declarator") {
+			nameNode := declarator.ChildByFieldName("name")
+			if nameNode == nil {
+				continue
+			}
+
+			if exported {
+				replaced[int(declarator.StartByte())] = true
+				addProp(declarator, GetNodeContent(nameNode, sourceCode), statement)
+				continue
+			}
+
+			value := declarator.ChildByFieldName("value")
+			if value == nil || value.Type() != "call_expression" || nameNode.Type() != "object_pattern" {
+				continue
+			}
+			if callee := value.ChildByFieldName("function"); callee == nil || GetNodeContent(callee, sourceCode) != "$props" {
+				continue
+			}
 
-		name := GetNodeContent(nameNode, sourceCode)
-		namePath := s.BuildNamePath(parentPath, name)
-
-		// Determine if it's a const
-		symbolType := SymbolTypeVariable
-		if node.Type() == "lexical_declaration" {
-			// Check if it's const or let
-			for j := 0; j < int(node.ChildCount()); j++ {
-				c := node.Child(j)
-				if c != nil && c.Type() == "const" {
-					symbolType = SymbolTypeConstant
-					break
+			replaced[int(declarator.StartByte())] = true
+			for _, entry := range IterateNamedChildren(nameNode) {
+				switch entry.Type() {
+				case "shorthand_property_identifier_pattern":
+					addProp(entry, GetNodeContent(entry, sourceCode), statement)
+				case "object_assignment_pattern":
+					if left := entry.ChildByFieldName("left"); left != nil {
+						addProp(entry, GetNodeContent(left, sourceCode), statement)
+					}
+				case "pair_pattern":
+					if key := entry.ChildByFieldName("key"); key != nil {
+						addProp(entry, GetNodeContent(key, sourceCode), statement)
+					}
 				}
 			}
 		}
-
-		symbol := s.CreateSymbol(child, sourceCode, symbolType, name, namePath, filePath, projectID, parentID)
-		symbols = append(symbols, symbol)
 	}
 
-	return symbols
+	result := make([]*CodeSymbol, 0, len(symbols)+len(props))
+	for _, symbol := range symbols {
+		if symbol.ParentID == nil && replaced[symbol.StartByte] {
+			continue
+		}
+		result = append(result, symbol)
+	}
+	return append(result, props...)
 }
@@ -0,0 +1,88 @@
+package treesitter
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSvelteSymbols(t *testing.T) {
+	// symbol is the part of an extracted symbol the Svelte extractor decides
+	type symbol struct {
+		Type   SymbolType
+		Parent string
+	}
+	tests := []struct {
+		name   string
+		source string
+		want   map[string]symbol
+	}{
+		{
+			name: "svelte 4",
+			source: `<script lang="ts">
+  export let title: string;
+  export const version = 1;
+  let count = 0;
+
+  function increment() {
+    count += 1;
+  }
+</script>
+
+<h1 on:click={increment}>{title} {count}</h1>
+`,
+			want: map[string]symbol{
+				"/Counter":       {SymbolTypeComponent, ""},
+				"/Counter/title": {SymbolTypeProperty, "/Counter"},
+				"/version":       {SymbolTypeConstant, ""},
+				"/count":         {SymbolTypeVariable, ""},
+				"/increment":     {SymbolTypeFunction, ""},
+			},
+		},
+		{
+			name: "svelte 5",
+			source: `<script>
+  let { name, size = 2, label: text } = $props();
+</script>
+
+<p>{name} {size} {text}</p>
+`,
+			want: map[string]symbol{
+				"/Counter":       {SymbolTypeComponent, ""},
+				"/Counter/name":  {SymbolTypeProperty, "/Counter"},
+				"/Counter/size":  {SymbolTypeProperty, "/Counter"},
+				"/Counter/label": {SymbolTypeProperty, "/Counter"},
+			},
+		},
+	}
+
+	walker := NewASTWalker(DefaultWalkerConfig())
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tree := parseSample(t, LanguageSvelte, tt.source)
+			symbols, err := walker.ExtractSymbols(tree, []byte(tt.source), LanguageSvelte, "src/Counter.svelte", "test")
+			if err != nil {
+				t.Fatalf("extract symbols: %v", err)
+			}
+
+			namePaths := make(map[string]string, len(symbols))
+			for _, s := range symbols {
+				namePaths[s.ID] = s.NamePath
+			}
+			got := make(map[string]symbol, len(symbols))
+			for _, s := range symbols {
+				if s.Language != LanguageSvelte {
+					t.Errorf("%s has language %s, want svelte", s.NamePath, s.Language)
+				}
+				sym := symbol{Type: s.SymbolType}
+				if s.ParentID != nil {
+					sym.Parent = namePaths[*s.ParentID]
+				}
+				got[s.NamePath] = sym
+			}
+			// The props replace the plain variables they are declared with
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("symbols =\n%+v\nwant\n%+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+// Package treesitter provides syntax error detection.
+package treesitter
+
+import (
+	"strings"
+
+	sitter "github.com/madeindigio/go-tree-sitter"
+)
+
+// maxSyntaxErrorText bounds the source text reported for a syntax error
+const maxSyntaxErrorText = 80
+
+// SyntaxError is a region of a file the grammar could not parse
+type SyntaxError struct {
+	// Line and Column where the error starts (1-based)
+	Line   int `json:"line"`
+	Column int `json:"column"`
+
+	// Message describes the error, e.g. "unexpected \"}\"" or "missing \")\""
+	Message string `json:"message"`
+}
+
+// FindSyntaxErrors returns the syntax errors of a parsed file, in source order
+func FindSyntaxErrors(tree *sitter.Tree, sourceCode []byte) []SyntaxError {
+	var errs []SyntaxError
+	collectSyntaxErrors(tree.RootNode(), sourceCode, &errs)
+	return errs
+}
+
+// collectSyntaxErrors only descends into subtrees that contain errors
+func collectSyntaxErrors(node *sitter.Node, sourceCode []byte, errs *[]SyntaxError) {
+	switch {
+	case node.IsMissing():
+		*errs = append(*errs, SyntaxError{
+			Line:    int(node.StartPoint().Row) + 1,
+			Column:  int(node.StartPoint().Column) + 1,
+			Message: "missing \"" + node.Type() + "\"",
+		})
+		return
+	case node.IsError():
+		text := strings.Join(strings.Fields(GetNodeContent(node, sourceCode)), " ")
+		if len(text) > maxSyntaxErrorText {
+			text = text[:maxSyntaxErrorText] + "..."
+		}
+		*errs = append(*errs, SyntaxError{
+			Line:    int(node.StartPoint().Row) + 1,
+			Column:  int(node.StartPoint().Column) + 1,
+			Message: "unexpected \"" + text + "\"",
+		})
+		return
+	case !node.HasError():
+		return
+	}
+
+	for i := 0; i < int(node.ChildCount()); i++ {
+		if child := node.Child(i); child != nil {
+			collectSyntaxErrors(child, sourceCode, errs)
+		}
+	}
+}
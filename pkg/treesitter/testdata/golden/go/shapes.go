@@ -0,0 +1,29 @@
+package shapes
+
+import "math"
+
+// Pi is re-exported for callers.
+const Pi = math.Pi
+
+var unit, origin = 1.0, 0.0
+
+type Circle struct {
+	X, Y   float64
+	Radius float64
+}
+
+type Celsius float64
+
+// NewCircle returns a circle of the given radius.
+func NewCircle(radius float64) *Circle {
+	return &Circle{Radius: radius * unit}
+}
+
+// Area returns the area of the circle.
+func (c *Circle) Area() float64 {
+	return Pi * c.Radius * c.Radius
+}
+
+func (c Circle) Scale(factor float64) Circle {
+	return Circle{X: c.X + origin, Y: c.Y, Radius: c.Radius * factor}
+}
@@ -0,0 +1,23 @@
+package com.example.geometry;
+
+public class Vector {
+    private double x;
+    private double y;
+
+    public Vector(double x, double y) {
+        this.x = x;
+        this.y = y;
+    }
+
+    public Vector(double both) {
+        this(both, both);
+    }
+
+    public Vector add(Vector other) {
+        return new Vector(x + other.x, y + other.y);
+    }
+
+    public Vector add(double dx, double dy) {
+        return new Vector(x + dx, y + dy);
+    }
+}
@@ -0,0 +1,25 @@
+"""Inventory helpers."""
+
+MAX_ITEMS = 100
+
+
+class Item(Base):
+    """A stocked item."""
+
+    unit = "pcs"
+
+    def __init__(self, name: str, count: int = 0):
+        self.name = name
+        self.count = count
+
+    @property
+    def empty(self) -> bool:
+        return self.count == 0
+
+    def restock(self, amount):
+        """Add amount to the count."""
+        self.count += amount
+
+
+def total(items) -> int:
+    return sum(item.count for item in items)
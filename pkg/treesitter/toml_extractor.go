@@ -48,8 +48,8 @@ func (t *TOMLExtractor) extractNode(node *sitter.Node, sourceCode []byte, filePa
 	var symbols []*CodeSymbol
 
 	switch node.Type() {
-	case "table":
-		// TOML [section] headers
+	case "table", "table_array_element":
+		// TOML [section] and [[array]] headers
 		if symbol := t.extractTable(node, sourceCode, filePath, projectID, parentPath, parentID); symbol != nil {
 			symbols = append(symbols, symbol)
 			// Extract pairs within this table
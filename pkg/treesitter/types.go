@@ -26,6 +26,12 @@ const (
 	SymbolTypeNamespace   SymbolType = "namespace"
 	SymbolTypeModule      SymbolType = "module"
 	SymbolTypePackage     SymbolType = "package"
+	SymbolTypeComponent   SymbolType = "component"
+	SymbolTypeHook        SymbolType = "hook"
+	SymbolTypeTable       SymbolType = "table"
+	SymbolTypeView        SymbolType = "view"
+	SymbolTypeMessage     SymbolType = "message"
+	SymbolTypeService     SymbolType = "service"
 )
 
 // Language represents a supported programming language
@@ -34,6 +40,7 @@ type Language string
 const (
 	LanguageGo         Language = "go"
 	LanguageTypeScript Language = "typescript"
+	LanguageTSX        Language = "tsx"
 	LanguageJavaScript Language = "javascript"
 	LanguagePHP        Language = "php"
 	LanguageRust       Language = "rust"
@@ -51,6 +58,10 @@ const (
 	LanguageSvelte     Language = "svelte"
 	LanguageTOML       Language = "toml"
 	LanguageVue        Language = "vue"
+	LanguageSQL        Language = "sql"
+	LanguageProtobuf   Language = "protobuf"
+	LanguageYAML       Language = "yaml"
+	LanguageJSON       Language = "json"
 )
 
 // CodeSymbol represents a parsed code symbol from source code
@@ -85,6 +96,9 @@ type CodeSymbol struct {
 	// Source code content
 	SourceCode string `json:"source_code,omitempty"`
 
+	// Revision is a hash of the symbol's source text, used to detect concurrent edits
+	Revision string `json:"revision,omitempty"`
+
 	// Signature (for methods/functions)
 	Signature string `json:"signature,omitempty"`
 
@@ -127,6 +141,9 @@ type CodeFile struct {
 
 	// When this file was indexed
 	IndexedAt time.Time `json:"indexed_at"`
+
+	// File contents, stored compressed when set
+	Content []byte `json:"-"`
 }
 
 // CodeProject represents an indexed code project
@@ -17,6 +17,13 @@ func NewTypeScriptExtractor(config WalkerConfig) *TypeScriptExtractor {
 	}
 }
 
+// NewTSXExtractor creates a TypeScript extractor for .tsx files
+func NewTSXExtractor(config WalkerConfig) *TypeScriptExtractor {
+	return &TypeScriptExtractor{
+		BaseExtractor: NewBaseExtractor(LanguageTSX, config),
+	}
+}
+
 // GetSymbolTypes returns the types of symbols the TypeScript extractor can find
 func (t *TypeScriptExtractor) GetSymbolTypes() []SymbolType {
 	return []SymbolType{
@@ -30,6 +37,8 @@ func (t *TypeScriptExtractor) GetSymbolTypes() []SymbolType {
 		SymbolTypeEnum,
 		SymbolTypeTypeAlias,
 		SymbolTypeNamespace,
+		SymbolTypeComponent,
+		SymbolTypeHook,
 	}
 }
 
@@ -195,6 +204,7 @@ func (t *TypeScriptExtractor) extractInterface(node *sitter.Node, sourceCode []b
 
 	symbol := t.CreateSymbol(node, sourceCode, SymbolTypeInterface, name, namePath, filePath, projectID, parentID)
 	symbol.DocString = t.ExtractDocString(node, sourceCode)
+	symbol.Metadata = propsMetadata(name)
 
 	return symbol
 }
@@ -209,9 +219,14 @@ func (t *TypeScriptExtractor) extractFunction(node *sitter.Node, sourceCode []by
 	name := GetNodeContent(nameNode, sourceCode)
 	namePath := t.BuildNamePath(parentPath, name)
 
-	symbol := t.CreateSymbol(node, sourceCode, SymbolTypeFunction, name, namePath, filePath, projectID, parentID)
+	symbolType := reactFunctionType(name, node, sourceCode, SymbolTypeFunction)
+
+	symbol := t.CreateSymbol(node, sourceCode, symbolType, name, namePath, filePath, projectID, parentID)
 	symbol.Signature = t.extractFunctionSignature(node, sourceCode)
 	symbol.DocString = t.ExtractDocString(node, sourceCode)
+	if symbolType == SymbolTypeComponent {
+		symbol.Metadata = componentMetadata(node.ChildByFieldName("parameters"), sourceCode)
+	}
 
 	return symbol
 }
@@ -257,16 +272,24 @@ func (t *TypeScriptExtractor) extractVariableDeclaration(node *sitter.Node, sour
 			symbolType = SymbolTypeConstant
 		}
 
-		// Check if value is an arrow function or function expression
+		// Check if value is an arrow function or function expression, possibly a component or hook
 		value := declarator.ChildByFieldName("value")
 		if value != nil {
-			if value.Type() == "arrow_function" || value.Type() == "function" {
-				symbolType = SymbolTypeFunction
+			switch value.Type() {
+			case "arrow_function", "function", "function_expression":
+				symbolType = reactFunctionType(name, value, sourceCode, SymbolTypeFunction)
+			case "call_expression":
+				if reactWrappedComponent(name, value) {
+					symbolType = SymbolTypeComponent
+				}
 			}
 		}
 
 		symbol := t.CreateSymbol(declarator, sourceCode, symbolType, name, namePath, filePath, projectID, parentID)
 		symbol.DocString = t.ExtractDocString(node, sourceCode)
+		if symbolType == SymbolTypeComponent {
+			symbol.Metadata = componentMetadata(declarator, sourceCode)
+		}
 		symbols = append(symbols, symbol)
 	}
 
@@ -332,6 +355,7 @@ func (t *TypeScriptExtractor) extractTypeAlias(node *sitter.Node, sourceCode []b
 
 	symbol := t.CreateSymbol(node, sourceCode, SymbolTypeTypeAlias, name, namePath, filePath, projectID, parentID)
 	symbol.DocString = t.ExtractDocString(node, sourceCode)
+	symbol.Metadata = propsMetadata(name)
 
 	return symbol
 }
@@ -0,0 +1,128 @@
+// Package treesitter provides YAML and JSON config outline extraction.
+package treesitter
+
+import (
+	"strconv"
+	"strings"
+
+	sitter "github.com/madeindigio/go-tree-sitter"
+)
+
+// yamlOutlineDepth is how many levels of keys are extracted from config files:
+// the top-level keys and the keys directly under them
+const yamlOutlineDepth = 2
+
+// YAMLExtractor extracts the key outline of YAML config files. JSON is parsed
+// with the YAML grammar, which accepts it, so the same extractor serves both.
+type YAMLExtractor struct {
+	BaseExtractor
+}
+
+// NewYAMLExtractor creates a new YAML extractor
+func NewYAMLExtractor(config WalkerConfig) *YAMLExtractor {
+	return &YAMLExtractor{
+		BaseExtractor: NewBaseExtractor(LanguageYAML, config),
+	}
+}
+
+// NewJSONExtractor creates a YAML extractor for JSON files
+func NewJSONExtractor(config WalkerConfig) *YAMLExtractor {
+	return &YAMLExtractor{
+		BaseExtractor: NewBaseExtractor(LanguageJSON, config),
+	}
+}
+
+// GetSymbolTypes returns the types of symbols the YAML extractor can find
+func (y *YAMLExtractor) GetSymbolTypes() []SymbolType {
+	return []SymbolType{
+		SymbolTypeVariable,
+		SymbolTypeConstant,
+	}
+}
+
+// ExtractSymbols extracts the keys of every document in the stream. As in
+// TOML, keys holding a mapping are constants and keys holding values are variables.
+func (y *YAMLExtractor) ExtractSymbols(tree *sitter.Tree, sourceCode []byte, filePath string, projectID string) ([]*CodeSymbol, error) {
+	var symbols []*CodeSymbol
+	root := tree.RootNode()
+
+	for _, document := range FindChildrenByType(root, "document") {
+		if mapping := yamlMapping(document); mapping != nil {
+			symbols = append(symbols, y.extractMapping(mapping, sourceCode, filePath, projectID, "", nil, 1)...)
+		}
+	}
+
+	return symbols, nil
+}
+
+// extractMapping extracts the keys of a block or flow mapping
+func (y *YAMLExtractor) extractMapping(node *sitter.Node, sourceCode []byte, filePath string, projectID string, parentPath string, parentID *string, depth int) []*CodeSymbol {
+	var symbols []*CodeSymbol
+
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		pair := node.NamedChild(i)
+		if pair == nil || (pair.Type() != "block_mapping_pair" && pair.Type() != "flow_pair") {
+			continue
+		}
+
+		keyNode := pair.ChildByFieldName("key")
+		if keyNode == nil {
+			continue
+		}
+
+		name := yamlKey(keyNode, sourceCode)
+		if name == "" {
+			continue
+		}
+		namePath := y.BuildNamePath(parentPath, name)
+
+		value := yamlMapping(pair.ChildByFieldName("value"))
+		symbolType := SymbolTypeVariable
+		if value != nil {
+			symbolType = SymbolTypeConstant
+		}
+
+		symbol := y.CreateSymbol(pair, sourceCode, symbolType, name, namePath, filePath, projectID, parentID)
+		symbols = append(symbols, symbol)
+
+		if value != nil && depth < yamlOutlineDepth {
+			children := y.extractMapping(value, sourceCode, filePath, projectID, namePath, &symbol.ID, depth+1)
+			symbol.Children = append(symbol.Children, children...)
+			symbols = append(symbols, children...)
+		}
+	}
+
+	return symbols
+}
+
+// yamlMapping returns the mapping held by a document or value node, or nil
+// when it holds a scalar or a sequence
+func yamlMapping(node *sitter.Node) *sitter.Node {
+	if node == nil {
+		return nil
+	}
+
+	switch node.Type() {
+	case "block_mapping", "flow_mapping":
+		return node
+	case "document", "block_node", "flow_node":
+		for i := 0; i < int(node.NamedChildCount()); i++ {
+			if mapping := yamlMapping(node.NamedChild(i)); mapping != nil {
+				return mapping
+			}
+		}
+	}
+	return nil
+}
+
+// yamlKey returns the text of a mapping key without quotes
+func yamlKey(node *sitter.Node, sourceCode []byte) string {
+	key := strings.TrimSpace(GetNodeContent(node, sourceCode))
+	if unquoted, err := strconv.Unquote(key); err == nil {
+		return unquoted
+	}
+	if len(key) >= 2 && key[0] == '\'' && key[len(key)-1] == '\'' {
+		return strings.ReplaceAll(key[1:len(key)-1], "''", "'")
+	}
+	return key
+}